@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/mcp"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+	"github.com/jbrinkman/valkey-ai-tasks/tests/utils"
+)
+
+func TestPercentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	for fraction, expected := range map[float64]time.Duration{
+		0.5:  50 * time.Millisecond,
+		0.99: 99 * time.Millisecond,
+		1:    100 * time.Millisecond,
+		0:    time.Millisecond,
+	} {
+		if got := percentile(latencies, fraction); got != expected {
+			t.Errorf("percentile %v: expected %s, got %s", fraction, expected, got)
+		}
+	}
+	if got := percentile(nil, 0.5); got != 0 {
+		t.Errorf("expected 0 without latencies, got %s", got)
+	}
+}
+
+func TestParseMix(t *testing.T) {
+	mix, err := parseMix("get_task=3, reorder_task=1")
+	if err != nil || len(mix) != 2 || mix[1].tool != "reorder_task" || mix[1].weight != 1 {
+		t.Fatalf("unexpected mix %v: %v", mix, err)
+	}
+	for _, value := range []string{"get_task", "get_task=-1", "delete_plan=1", "get_task=0"} {
+		if _, err := parseMix(value); err == nil {
+			t.Errorf("expected mix %q to be rejected", value)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Setenv("ENABLE_SSE", "true")
+	t.Setenv("ENABLE_STREAMABLE_HTTP", "true")
+	client, err := storage.NewMemoryClient(storage.MemoryConfig{})
+	if err != nil {
+		t.Fatalf("failed to create memory client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() }) //nolint:errcheck
+
+	s := mcp.NewMCPGoServer(storage.NewPlanRepository(client), storage.NewTaskRepository(client))
+	port := utils.GetRandomPort(t)
+	go s.Start(port) //nolint:errcheck
+
+	t.Cleanup(func() { s.Shutdown(context.Background()) }) //nolint:errcheck
+	server := fmt.Sprintf("http://localhost:%d", port)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		response, err := http.Get(server + "/health")
+		if err == nil {
+			response.Body.Close() //nolint:errcheck
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not start: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	for _, transport := range []string{transportSSE, transportStreamable} {
+		t.Run(transport, func(t *testing.T) {
+			report, err := run(context.Background(), options{
+				server:    server,
+				transport: transport,
+				sessions:  4,
+				duration:  300 * time.Millisecond,
+				plans:     2,
+				tasks:     5,
+				mix:       defaultMix,
+				seed:      1,
+			})
+			if err != nil {
+				t.Fatalf("load test failed: %v", err)
+			}
+			if failures := report.failures(0); len(failures) > 0 {
+				t.Errorf("unexpected failures: %v", failures)
+			}
+			if report.tools["initialize"] == nil || len(report.tools["initialize"].latencies) != 4 {
+				t.Errorf("expected 4 sessions to be initialized")
+			}
+
+			var out bytes.Buffer
+			if err := report.write(&out); err != nil {
+				t.Fatalf("failed to write report: %v", err)
+			}
+			if !strings.Contains(out.String(), "list_tasks_by_plan") || !strings.Contains(out.String(), "total") {
+				t.Errorf("unexpected report:\n%s", out.String())
+			}
+		})
+	}
+
+	// The plans of the load test are deleted once it is done
+	plans, err := storage.NewPlanRepository(client).ListByApplication(context.Background(), loadTestApplication)
+	if err != nil || len(plans) != 0 {
+		t.Errorf("expected the plans to be deleted, got %d: %v", len(plans), err)
+	}
+}
@@ -0,0 +1,64 @@
+// Command loadtest opens concurrent MCP sessions against a running valkey-ai-tasks server and calls its tools
+// with a mix of reads and writes like agents would, then reports the latency percentiles and error rate of each
+// tool. It is meant to size deployments and to find concurrency bugs: the sessions share a few plans, and the
+// task order of each plan is checked once the load stops.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// options holds the settings of a load test
+type options struct {
+	server       string
+	transport    string
+	endpoint     string
+	token        string
+	sessions     int
+	duration     time.Duration
+	plans        int
+	tasks        int
+	mix          string
+	seed         int64
+	keep         bool
+	maxErrorRate float64
+}
+
+func main() {
+	opts := options{}
+	flag.StringVar(&opts.server, "server", "http://localhost:8080", "URL of the valkey-ai-tasks server")
+	flag.StringVar(&opts.transport, "transport", transportStreamable, "transport of the sessions: sse or streamable")
+	flag.StringVar(&opts.endpoint, "endpoint", "", "endpoint path of the transport, /sse or /mcp by default")
+	flag.StringVar(&opts.token, "token", "", "bearer token sent by the sessions, for servers scoping applications")
+	flag.IntVar(&opts.sessions, "sessions", 10, "number of concurrent sessions")
+	flag.DurationVar(&opts.duration, "duration", 30*time.Second, "how long the sessions call tools")
+	flag.IntVar(&opts.plans, "plans", 2, "number of plans shared by the sessions")
+	flag.IntVar(&opts.tasks, "tasks", 20, "number of tasks created in each plan before the load starts")
+	flag.StringVar(&opts.mix, "mix", defaultMix, "comma-separated tool=weight pairs of the tools called")
+	flag.Int64Var(&opts.seed, "seed", time.Now().UnixNano(), "seed of the random choices, to repeat a run")
+	flag.BoolVar(&opts.keep, "keep", false, "keep the plans of the load test instead of deleting them")
+	flag.Float64Var(&opts.maxErrorRate, "max-error-rate", 0, "error rate above which the load test fails")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := run(ctx, opts)
+	if err != nil {
+		log.Fatalf("Load test failed: %v", err)
+	}
+	if err := report.write(os.Stdout); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+	if failures := report.failures(opts.maxErrorRate); len(failures) > 0 {
+		for _, failure := range failures {
+			log.Printf("FAIL: %s", failure)
+		}
+		os.Exit(1)
+	}
+}
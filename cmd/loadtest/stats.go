@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// recorder collects the latency and outcome of the tool calls of all sessions
+type recorder struct {
+	mu    sync.Mutex
+	tools map[string]*toolStats
+}
+
+// toolStats holds the calls made to a tool
+type toolStats struct {
+	latencies []time.Duration
+	errors    int
+	// lastError is the latest error of the tool, shown in the report as an example
+	lastError string
+}
+
+// newRecorder creates an empty recorder
+func newRecorder() *recorder {
+	return &recorder{tools: make(map[string]*toolStats)}
+}
+
+// record adds a call to a tool
+func (r *recorder) record(tool string, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats, ok := r.tools[tool]
+	if !ok {
+		stats = &toolStats{}
+		r.tools[tool] = stats
+	}
+	stats.latencies = append(stats.latencies, latency)
+	if err != nil {
+		stats.errors++
+		stats.lastError = err.Error()
+	}
+}
+
+// percentile returns the latency below which the given fraction of the sorted latencies fall
+func percentile(sorted []time.Duration, fraction float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(fraction*float64(len(sorted))+0.5) - 1
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
+
+// report is the outcome of a load test
+type report struct {
+	sessions int
+	duration time.Duration
+	tools    map[string]*toolStats
+	// problems lists the inconsistencies found in the plans once the load stopped
+	problems []string
+}
+
+// calls returns the number of calls and errors of all tools
+func (r *report) calls() (int, int) {
+	calls, errors := 0, 0
+	for _, stats := range r.tools {
+		calls += len(stats.latencies)
+		errors += stats.errors
+	}
+	return calls, errors
+}
+
+// write prints a table of the latencies and errors of each tool, followed by the problems found
+func (r *report) write(w io.Writer) error {
+	names := make([]string, 0, len(r.tools))
+	var all []time.Duration
+	for name, stats := range r.tools {
+		names = append(names, name)
+		all = append(all, stats.latencies...)
+	}
+	sort.Strings(names)
+
+	calls, errors := r.calls()
+	fmt.Fprintf(w, "%d sessions, %d calls in %s (%.1f calls/s)\n\n", //nolint:errcheck
+		r.sessions, calls, r.duration.Round(time.Millisecond), float64(calls)/r.duration.Seconds())
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "TOOL\tCALLS\tERRORS\tERROR %\tP50\tP90\tP99\tMAX\t") //nolint:errcheck
+	row := func(name string, latencies []time.Duration, errors int) {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		fmt.Fprintf(table, "%s\t%d\t%d\t%.2f\t%s\t%s\t%s\t%s\t\n", //nolint:errcheck
+			name, len(latencies), errors, errorRate(len(latencies), errors)*100,
+			percentile(latencies, 0.5).Round(time.Microsecond),
+			percentile(latencies, 0.9).Round(time.Microsecond),
+			percentile(latencies, 0.99).Round(time.Microsecond),
+			percentile(latencies, 1).Round(time.Microsecond))
+	}
+	for _, name := range names {
+		stats := r.tools[name]
+		row(name, stats.latencies, stats.errors)
+	}
+	row("total", all, errors)
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if stats := r.tools[name]; stats.errors > 0 {
+			fmt.Fprintf(w, "\nlast error of %s: %s", name, stats.lastError) //nolint:errcheck
+		}
+	}
+	for _, problem := range r.problems {
+		fmt.Fprintf(w, "\nproblem: %s", problem) //nolint:errcheck
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// failures returns the reasons the load test failed: an error rate above the maximum, or problems in the plans
+func (r *report) failures(maxErrorRate float64) []string {
+	failures := append([]string(nil), r.problems...)
+	calls, errors := r.calls()
+	if calls == 0 {
+		failures = append(failures, "no tool was called")
+	} else if rate := errorRate(calls, errors); rate > maxErrorRate {
+		failures = append(failures, fmt.Sprintf("error rate %.2f%% is above %.2f%%", rate*100, maxErrorRate*100))
+	}
+	return failures
+}
+
+// errorRate returns the fraction of calls that failed
+func errorRate(calls, errors int) float64 {
+	if calls == 0 {
+		return 0
+	}
+	return float64(errors) / float64(calls)
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	transportSSE        = "sse"
+	transportStreamable = "streamable"
+
+	// loadTestApplication is the application of the plans created by the load test
+	loadTestApplication = "loadtest"
+)
+
+// defaultMix is the tool mix of an agent working through a plan: mostly reads, with status updates, new tasks
+// and reordering in between
+const defaultMix = "list_tasks_by_plan=30,get_task=20,update_task=15,get_plan_progress=10," +
+	"create_task=10,reorder_task=10,list_tasks_by_status=5"
+
+// taskStatuses are the statuses set by update_task calls
+var taskStatuses = []string{"pending", "in_progress", "completed"}
+
+// operation is a tool called by the sessions, with its share of the calls
+type operation struct {
+	tool   string
+	weight int
+}
+
+// parseMix parses comma-separated tool=weight pairs
+func parseMix(value string) ([]operation, error) {
+	var mix []operation
+	for _, pair := range strings.Split(value, ",") {
+		tool, weight, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		n, err := strconv.Atoi(weight)
+		if !ok || err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid mix entry '%s', expected tool=weight", pair)
+		}
+		if _, ok := toolArguments[tool]; !ok {
+			return nil, fmt.Errorf("unsupported tool '%s' in the mix", tool)
+		}
+		mix = append(mix, operation{tool: tool, weight: n})
+	}
+	total := 0
+	for _, op := range mix {
+		total += op.weight
+	}
+	if total == 0 {
+		return nil, errors.New("the weights of the mix add up to zero")
+	}
+	return mix, nil
+}
+
+// pick returns a tool of the mix at random, following the weights
+func pick(mix []operation, rng *rand.Rand) string {
+	total := 0
+	for _, op := range mix {
+		total += op.weight
+	}
+	n := rng.Intn(total)
+	for _, op := range mix {
+		if n < op.weight {
+			return op.tool
+		}
+		n -= op.weight
+	}
+	return mix[len(mix)-1].tool
+}
+
+// plan is a plan shared by the sessions, with the tasks known to be in it
+type plan struct {
+	id string
+
+	mu      sync.Mutex
+	taskIDs []string
+}
+
+// randomTask returns a task of the plan at random and the number of tasks
+func (p *plan) randomTask(rng *rand.Rand) (string, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.taskIDs[rng.Intn(len(p.taskIDs))], len(p.taskIDs)
+}
+
+// addTask adds a task created in the plan
+func (p *plan) addTask(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.taskIDs = append(p.taskIDs, id)
+}
+
+// toolArguments returns the arguments of a call to each tool the mix can hold, on a plan
+var toolArguments = map[string]func(p *plan, rng *rand.Rand) map[string]any{
+	"list_tasks_by_plan": func(p *plan, rng *rand.Rand) map[string]any {
+		return map[string]any{"plan_id": p.id}
+	},
+	"get_plan_progress": func(p *plan, rng *rand.Rand) map[string]any {
+		return map[string]any{"id": p.id}
+	},
+	"get_task": func(p *plan, rng *rand.Rand) map[string]any {
+		taskID, _ := p.randomTask(rng)
+		return map[string]any{"id": taskID}
+	},
+	"update_task": func(p *plan, rng *rand.Rand) map[string]any {
+		taskID, _ := p.randomTask(rng)
+		return map[string]any{"id": taskID, "status": taskStatuses[rng.Intn(len(taskStatuses))]}
+	},
+	"create_task": func(p *plan, rng *rand.Rand) map[string]any {
+		return map[string]any{"plan_id": p.id, "title": fmt.Sprintf("Load test task %d", rng.Int63())}
+	},
+	"reorder_task": func(p *plan, rng *rand.Rand) map[string]any {
+		taskID, count := p.randomTask(rng)
+		return map[string]any{"id": taskID, "new_order": rng.Intn(count)}
+	},
+	"list_tasks_by_status": func(p *plan, rng *rand.Rand) map[string]any {
+		return map[string]any{"status": "in_progress"}
+	},
+}
+
+// connect opens an initialized MCP session on the server
+func connect(ctx context.Context, opts options) (*client.Client, error) {
+	headers := map[string]string{}
+	if opts.token != "" {
+		headers["Authorization"] = "Bearer " + opts.token
+	}
+
+	var c *client.Client
+	var err error
+	server := strings.TrimSuffix(opts.server, "/")
+	switch opts.transport {
+	case transportSSE:
+		c, err = client.NewSSEMCPClient(server+endpoint(opts, "/sse"), transport.WithHeaders(headers))
+	case transportStreamable:
+		c, err = client.NewStreamableHttpClient(server+endpoint(opts, "/mcp"), transport.WithHTTPHeaders(headers))
+	default:
+		return nil, fmt.Errorf("unknown transport '%s', expected %s or %s", opts.transport, transportSSE, transportStreamable)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	if err := c.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start session: %w", err)
+	}
+
+	request := mcp.InitializeRequest{}
+	request.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	request.Params.ClientInfo = mcp.Implementation{Name: "valkey-ai-tasks-loadtest", Version: "1.0.0"}
+	if _, err := c.Initialize(ctx, request); err != nil {
+		c.Close() //nolint:errcheck
+		return nil, fmt.Errorf("failed to initialize session: %w", err)
+	}
+	return c, nil
+}
+
+// endpoint returns the endpoint path of the transport
+func endpoint(opts options, defaultPath string) string {
+	if opts.endpoint != "" {
+		return opts.endpoint
+	}
+	return defaultPath
+}
+
+// callTool calls a tool and returns the text of its result. Tool errors are returned as errors.
+func callTool(ctx context.Context, c *client.Client, tool string, args map[string]any) (string, error) {
+	request := mcp.CallToolRequest{}
+	request.Params.Name = tool
+	request.Params.Arguments = args
+	result, err := c.CallTool(ctx, request)
+	if err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	for _, content := range result.Content {
+		if textContent, ok := content.(mcp.TextContent); ok {
+			text.WriteString(textContent.Text)
+		}
+	}
+	if result.IsError {
+		return "", errors.New(text.String())
+	}
+	return text.String(), nil
+}
+
+// createdID returns the ID of the plan or task created by a tool call
+func createdID(text string) (string, error) {
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(text), &created); err != nil || created.ID == "" {
+		return "", fmt.Errorf("unexpected result: %s", text)
+	}
+	return created.ID, nil
+}
+
+// run creates the plans, runs the sessions for the duration of the load test and checks the plans afterwards
+func run(ctx context.Context, opts options) (*report, error) {
+	mix, err := parseMix(opts.mix)
+	if err != nil {
+		return nil, err
+	}
+	if opts.sessions <= 0 || opts.plans <= 0 || opts.tasks <= 0 {
+		return nil, errors.New("sessions, plans and tasks must be positive")
+	}
+
+	setup, err := connect(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer setup.Close() //nolint:errcheck
+
+	plans := make([]*plan, opts.plans)
+	for i := range plans {
+		text, err := callTool(ctx, setup, "create_plan", map[string]any{
+			"application_id": loadTestApplication,
+			"name":           fmt.Sprintf("Load test plan %d", i+1),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create plan: %w", err)
+		}
+		plans[i] = &plan{}
+		if plans[i].id, err = createdID(text); err != nil {
+			return nil, err
+		}
+		if !opts.keep {
+			defer func(id string) {
+				if _, err := callTool(context.WithoutCancel(ctx), setup, "delete_plan", map[string]any{
+					"id":         id,
+					"open_tasks": "delete",
+				}); err != nil {
+					log.Printf("Warning: failed to delete plan %s: %v", id, err)
+				}
+			}(plans[i].id)
+		}
+		for j := 0; j < opts.tasks; j++ {
+			text, err := callTool(ctx, setup, "create_task", map[string]any{
+				"plan_id": plans[i].id,
+				"title":   fmt.Sprintf("Load test task %d", j+1),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create task: %w", err)
+			}
+			id, err := createdID(text)
+			if err != nil {
+				return nil, err
+			}
+			plans[i].addTask(id)
+		}
+	}
+
+	recorder := newRecorder()
+	loadCtx, cancel := context.WithTimeout(ctx, opts.duration)
+	defer cancel()
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < opts.sessions; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(opts.seed + int64(i)))
+			runSession(ctx, loadCtx, opts, mix, plans[i%len(plans)], rng, recorder)
+		}(i)
+	}
+	wg.Wait()
+
+	return &report{
+		sessions: opts.sessions,
+		duration: time.Since(start),
+		tools:    recorder.tools,
+		problems: checkPlans(ctx, setup, plans),
+	}, nil
+}
+
+// runSession opens a session and calls tools on the plan until loadCtx is done. Calls use ctx, so the calls
+// in flight when the load stops complete rather than fail.
+func runSession(
+	ctx, loadCtx context.Context,
+	opts options,
+	mix []operation,
+	p *plan,
+	rng *rand.Rand,
+	recorder *recorder,
+) {
+	start := time.Now()
+	c, err := connect(ctx, opts)
+	recorder.record("initialize", time.Since(start), err)
+	if err != nil {
+		return
+	}
+	defer c.Close() //nolint:errcheck
+
+	for loadCtx.Err() == nil {
+		tool := pick(mix, rng)
+		args := toolArguments[tool](p, rng)
+
+		start := time.Now()
+		text, err := callTool(ctx, c, tool, args)
+		if err == nil && tool == "create_task" {
+			var id string
+			if id, err = createdID(text); err == nil {
+				p.addTask(id)
+			}
+		}
+		recorder.record(tool, time.Since(start), err)
+	}
+}
+
+// checkPlans lists the tasks of each plan once the load stopped, and returns the inconsistencies found: tasks
+// created by the sessions missing from their plan, or task orders that are not a sequence from 0
+func checkPlans(ctx context.Context, c *client.Client, plans []*plan) []string {
+	var problems []string
+	for _, p := range plans {
+		text, err := callTool(ctx, c, "list_tasks_by_plan", map[string]any{"plan_id": p.id})
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("failed to list the tasks of plan %s: %v", p.id, err))
+			continue
+		}
+		var tasks []struct {
+			ID    string `json:"id"`
+			Order int    `json:"order"`
+		}
+		if err := json.Unmarshal([]byte(text), &tasks); err != nil {
+			problems = append(problems, fmt.Sprintf("unexpected tasks of plan %s: %v", p.id, err))
+			continue
+		}
+
+		listed := make(map[string]bool, len(tasks))
+		orders := make([]int, 0, len(tasks))
+		for _, task := range tasks {
+			listed[task.ID] = true
+			orders = append(orders, task.Order)
+		}
+		for _, id := range p.taskIDs {
+			if !listed[id] {
+				problems = append(problems, fmt.Sprintf("task %s is missing from plan %s", id, p.id))
+			}
+		}
+		sort.Ints(orders)
+		for i, order := range orders {
+			if order != i {
+				problems = append(problems, fmt.Sprintf("the task orders of plan %s are not 0 to %d", p.id, len(orders)-1))
+				break
+			}
+		}
+	}
+	return problems
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/mcp"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// configCheckTimeout bounds the storage checks of --check-config, so an unreachable Valkey fails the check
+// instead of hanging it
+const configCheckTimeout = 30 * time.Second
+
+// configReport prints the results of --check-config and counts the failures
+type configReport struct {
+	w        io.Writer
+	failures int
+}
+
+func (r *configReport) ok(format string, args ...any) {
+	fmt.Fprintf(r.w, "  ok    "+format+"\n", args...)
+}
+
+func (r *configReport) fail(format string, args ...any) {
+	r.failures++
+	fmt.Fprintf(r.w, "  FAIL  "+format+"\n", args...)
+}
+
+// runConfigCheck checks the configuration collected in configProblems and the server settings, connects to
+// the storage and checks its schema version and the consistency of its plan and task indexes. It writes a
+// report and returns the exit code, which is 1 if anything failed.
+func runConfigCheck(
+	ctx context.Context,
+	w io.Writer,
+	storageBackend string,
+	memoryConfig storage.MemoryConfig,
+	valkeyConfig storage.ValkeyConfig,
+) int {
+	report := &configReport{w: w}
+	fmt.Fprintln(w, "Configuration")
+	for _, problem := range configProblems {
+		report.fail("%s", problem)
+	}
+	for _, problem := range mcp.CheckServerConfig() {
+		report.fail("%s", problem)
+	}
+	if report.failures == 0 {
+		report.ok("settings are valid")
+	}
+
+	fmt.Fprintln(w, "Storage")
+	ctx, cancel := context.WithTimeout(ctx, configCheckTimeout)
+	defer cancel()
+	if valkeyClient := connectForCheck(ctx, report, storageBackend, memoryConfig, valkeyConfig); valkeyClient != nil {
+		checkStoredData(ctx, report, valkeyClient)
+	}
+
+	if report.failures > 0 {
+		fmt.Fprintf(w, "Configuration check failed with %d problem(s)\n", report.failures)
+		return 1
+	}
+	fmt.Fprintln(w, "Configuration check passed")
+	return 0
+}
+
+// connectForCheck connects to the configured storage, or returns nil if it cannot
+func connectForCheck(
+	ctx context.Context,
+	report *configReport,
+	storageBackend string,
+	memoryConfig storage.MemoryConfig,
+	valkeyConfig storage.ValkeyConfig,
+) *storage.ValkeyClient {
+	switch storageBackend {
+	case "memory":
+		// Snapshots are only loaded, and the client is left open so closing it does not rewrite the snapshot
+		memoryConfig.SnapshotInterval = 0
+		valkeyClient, err := storage.NewMemoryClient(memoryConfig)
+		if err != nil {
+			report.fail("Failed to initialize in-memory storage: %v", err)
+			return nil
+		}
+		if memoryConfig.SnapshotFile != "" {
+			report.ok("in-memory storage loaded from %s", memoryConfig.SnapshotFile)
+		} else {
+			report.ok("in-memory storage starts empty, data is lost when the server stops")
+		}
+		return valkeyClient
+	case "valkey":
+		valkeyClient, err := storage.NewValkeyClientWithConfig(valkeyConfig)
+		if err != nil {
+			report.fail("Failed to initialize Valkey client: %v", err)
+			return nil
+		}
+		if err := valkeyClient.Ping(ctx); err != nil {
+			valkeyClient.Close()
+			report.fail("Failed to connect to Valkey: %v", err)
+			return nil
+		}
+		if valkeyConfig.Cluster {
+			storage.SetClusterKeyLayout(true)
+		}
+		report.ok("connected to Valkey")
+		return valkeyClient
+	default:
+		report.fail("Storage not checked, STORAGE_BACKEND is invalid")
+		return nil
+	}
+}
+
+// checkStoredData checks the schema version and the indexes of the stored plans and tasks without changing them
+func checkStoredData(ctx context.Context, report *configReport, valkeyClient *storage.ValkeyClient) {
+	version, found, err := valkeyClient.StoredSchemaVersion(ctx)
+	switch {
+	case err != nil:
+		report.fail("Failed to check schema version: %v", err)
+	case !found:
+		report.ok("no schema version recorded yet, the server records version %d when it starts", storage.SchemaVersion)
+	case version > storage.SchemaVersion:
+		report.fail("Schema version %d is newer than version %d supported by this server", version, storage.SchemaVersion)
+	default:
+		report.ok("schema version %d", version)
+	}
+
+	integrity, err := storage.NewIntegrityChecker(valkeyClient).Check(ctx, false)
+	if err != nil {
+		report.fail("Failed to check data integrity: %v", err)
+		return
+	}
+	if len(integrity.Issues) > 0 {
+		kinds := make(map[storage.IntegrityIssueKind]int)
+		for _, issue := range integrity.Issues {
+			kinds[issue.Kind]++
+		}
+		report.fail("%d integrity issue(s) in %d plan(s) and %d task(s) %v, run `valkey-tasks --direct check --repair`",
+			len(integrity.Issues), integrity.PlansChecked, integrity.TasksChecked, kinds)
+		return
+	}
+	report.ok("indexes of %d plan(s) and %d task(s) are consistent", integrity.PlansChecked, integrity.TasksChecked)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+func TestRunConfigCheck(t *testing.T) {
+	t.Cleanup(func() { configProblems = nil })
+
+	var out bytes.Buffer
+	code := runConfigCheck(context.Background(), &out, "memory", storage.MemoryConfig{}, storage.ValkeyConfig{})
+	if code != 0 {
+		t.Fatalf("exit code = %d, expected 0:\n%s", code, out.String())
+	}
+	if !strings.Contains(out.String(), "indexes of 0 plan(s) and 0 task(s) are consistent") {
+		t.Errorf("report does not cover the indexes:\n%s", out.String())
+	}
+
+	out.Reset()
+	configProblems = []string{"Invalid VALKEY_DB: x"}
+	code = runConfigCheck(context.Background(), &out, "memory", storage.MemoryConfig{}, storage.ValkeyConfig{})
+	if code != 1 {
+		t.Fatalf("exit code = %d, expected 1:\n%s", code, out.String())
+	}
+	if !strings.Contains(out.String(), "FAIL  Invalid VALKEY_DB: x") ||
+		!strings.Contains(out.String(), "failed with 1 problem(s)") {
+		t.Errorf("report does not list the problem:\n%s", out.String())
+	}
+}
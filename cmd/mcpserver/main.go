@@ -2,65 +2,537 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
+	"net"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/jbrinkman/valkey-ai-tasks/internal/mcp"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/config"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/integrations/github"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/integrations/jira"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/scheduler"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
 	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/utils/markdown"
+	"github.com/jbrinkman/valkey-ai-tasks/taskserver"
 )
 
+// shutdownTimeout bounds waiting for ongoing requests and background jobs when the server stops
+const shutdownTimeout = 10 * time.Second
+
+// checkConfig makes the server check its configuration and storage and exit instead of serving
+var checkConfig = flag.Bool("check-config", false,
+	"check the configuration, the connection to Valkey and the stored data, then exit")
+
 func main() {
+	flag.Parse()
+
+	// Settings come from the environment and the config file, the environment taking precedence
+	if err := config.Load(*configFile); err != nil {
+		invalidConfig("Invalid --config: %v", err)
+	}
+
 	// Get environment variables or use defaults
+	storageBackend := strings.ToLower(getEnv("STORAGE_BACKEND", "valkey"))
+	if storageBackend != "valkey" && storageBackend != "memory" {
+		invalidConfig("Invalid STORAGE_BACKEND: %s", storageBackend)
+	}
+	memorySnapshotFile := getEnv("MEMORY_SNAPSHOT_FILE", "")
+	memorySnapshotIntervalStr := getEnv("MEMORY_SNAPSHOT_INTERVAL", "60")
+	memorySnapshotInterval, err := strconv.Atoi(memorySnapshotIntervalStr)
+	if err != nil || memorySnapshotInterval < 0 {
+		invalidConfig("Invalid MEMORY_SNAPSHOT_INTERVAL: %s", memorySnapshotIntervalStr)
+	}
 	valkeyHost := getEnv("VALKEY_HOST", "localhost")
 	valkeyPortStr := getEnv("VALKEY_PORT", "6379")
 	valkeyPort, err := strconv.Atoi(valkeyPortStr)
 	if err != nil {
-		log.Fatalf("Invalid VALKEY_PORT: %v", err)
+		invalidConfig("Invalid VALKEY_PORT: %v", err)
 	}
 	valkeyUsername := getEnv("VALKEY_USERNAME", "")
 	valkeyPassword := getEnv("VALKEY_PASSWORD", "")
+	valkeyCluster := strings.ToLower(getEnv("VALKEY_CLUSTER", "false")) == "true"
+	// Seed nodes default to the single host and port
+	valkeyAddressesStr := getEnv("VALKEY_ADDRESSES", net.JoinHostPort(valkeyHost, strconv.Itoa(valkeyPort)))
+	valkeyAddresses, err := storage.ParseValkeyAddresses(valkeyAddressesStr, valkeyPort)
+	if err != nil {
+		invalidConfig("Invalid VALKEY_ADDRESSES: %v", err)
+	}
+	var valkeySentinelAddresses []storage.ValkeyAddress
+	if sentinelAddressesStr := getEnv("VALKEY_SENTINEL_ADDRESSES", ""); sentinelAddressesStr != "" {
+		valkeySentinelAddresses, err = storage.ParseValkeyAddresses(sentinelAddressesStr, storage.DefaultSentinelPort)
+		if err != nil {
+			invalidConfig("Invalid VALKEY_SENTINEL_ADDRESSES: %v", err)
+		}
+	}
+	valkeySentinelMaster := getEnv("VALKEY_SENTINEL_MASTER", "mymaster")
+	valkeySentinelUsername := getEnv("VALKEY_SENTINEL_USERNAME", "")
+	valkeySentinelPassword := getEnv("VALKEY_SENTINEL_PASSWORD", "")
+	valkeyReadFromReplica := strings.ToLower(getEnv("VALKEY_READ_FROM_REPLICA", "false")) == "true"
+	valkeyTLS := strings.ToLower(getEnv("VALKEY_TLS", "false")) == "true"
+	valkeyTLSCAFile := getEnv("VALKEY_TLS_CA_FILE", "")
+	valkeyTLSCertFile := getEnv("VALKEY_TLS_CERT_FILE", "")
+	valkeyTLSKeyFile := getEnv("VALKEY_TLS_KEY_FILE", "")
+	valkeyTLSInsecure := strings.ToLower(getEnv("VALKEY_TLS_INSECURE_SKIP_VERIFY", "false")) == "true"
+	valkeyDBStr := getEnv("VALKEY_DB", "0")
+	valkeyDB, err := strconv.Atoi(valkeyDBStr)
+	if err != nil || valkeyDB < 0 {
+		invalidConfig("Invalid VALKEY_DB: %s", valkeyDBStr)
+	}
+	valkeyPoolSizeStr := getEnv("VALKEY_POOL_SIZE", "1")
+	valkeyPoolSize, err := strconv.Atoi(valkeyPoolSizeStr)
+	if err != nil || valkeyPoolSize < 1 {
+		invalidConfig("Invalid VALKEY_POOL_SIZE: %s", valkeyPoolSizeStr)
+	}
+	valkeyRequestTimeoutStr := getEnv("VALKEY_REQUEST_TIMEOUT_MS", "0")
+	valkeyRequestTimeout, err := strconv.Atoi(valkeyRequestTimeoutStr)
+	if err != nil || valkeyRequestTimeout < 0 {
+		invalidConfig("Invalid VALKEY_REQUEST_TIMEOUT_MS: %s", valkeyRequestTimeoutStr)
+	}
+	valkeyConnectTimeoutStr := getEnv("VALKEY_CONNECT_TIMEOUT_MS", "0")
+	valkeyConnectTimeout, err := strconv.Atoi(valkeyConnectTimeoutStr)
+	if err != nil || valkeyConnectTimeout < 0 {
+		invalidConfig("Invalid VALKEY_CONNECT_TIMEOUT_MS: %s", valkeyConnectTimeoutStr)
+	}
+	valkeyReconnectRetriesStr := getEnv("VALKEY_RECONNECT_RETRIES", "0")
+	valkeyReconnectRetries, err := strconv.Atoi(valkeyReconnectRetriesStr)
+	if err != nil || valkeyReconnectRetries < 0 {
+		invalidConfig("Invalid VALKEY_RECONNECT_RETRIES: %s", valkeyReconnectRetriesStr)
+	}
+	valkeyReconnectDelayStr := getEnv("VALKEY_RECONNECT_DELAY_MS", "100")
+	valkeyReconnectDelay, err := strconv.Atoi(valkeyReconnectDelayStr)
+	if err != nil || valkeyReconnectDelay < 1 {
+		invalidConfig("Invalid VALKEY_RECONNECT_DELAY_MS: %s", valkeyReconnectDelayStr)
+	}
+	retryPolicy := storage.DefaultRetryPolicy()
+	retryAttemptsStr := getEnv("VALKEY_RETRY_ATTEMPTS", strconv.Itoa(retryPolicy.MaxAttempts))
+	retryPolicy.MaxAttempts, err = strconv.Atoi(retryAttemptsStr)
+	if err != nil || retryPolicy.MaxAttempts < 1 {
+		invalidConfig("Invalid VALKEY_RETRY_ATTEMPTS: %s", retryAttemptsStr)
+	}
+	retryBackoffStr := getEnv("VALKEY_RETRY_BACKOFF_MS", strconv.FormatInt(retryPolicy.InitialBackoff.Milliseconds(), 10))
+	retryBackoff, err := strconv.Atoi(retryBackoffStr)
+	if err != nil || retryBackoff < 0 {
+		invalidConfig("Invalid VALKEY_RETRY_BACKOFF_MS: %s", retryBackoffStr)
+	}
+	retryPolicy.InitialBackoff = time.Duration(retryBackoff) * time.Millisecond
+	retryMaxBackoffStr := getEnv("VALKEY_RETRY_MAX_BACKOFF_MS", strconv.FormatInt(retryPolicy.MaxBackoff.Milliseconds(), 10))
+	retryMaxBackoff, err := strconv.Atoi(retryMaxBackoffStr)
+	if err != nil || retryMaxBackoff < retryBackoff {
+		invalidConfig("Invalid VALKEY_RETRY_MAX_BACKOFF_MS: %s", retryMaxBackoffStr)
+	}
+	retryPolicy.MaxBackoff = time.Duration(retryMaxBackoff) * time.Millisecond
 	serverPortStr := getEnv("SERVER_PORT", "8080")
 	serverPort, err := strconv.Atoi(serverPortStr)
 	if err != nil {
-		log.Fatalf("Invalid SERVER_PORT: %v", err)
+		invalidConfig("Invalid SERVER_PORT: %v", err)
 	}
-
-	// Initialize Valkey client
-	valkeyClient, err := storage.NewValkeyClient(valkeyHost, valkeyPort, valkeyUsername, valkeyPassword)
+	leaseExpiryEnabled := strings.ToLower(getEnv("JOB_LEASE_EXPIRY_ENABLED", "true")) == "true"
+	leaseSweepIntervalStr := getEnv("LEASE_SWEEP_INTERVAL", "30")
+	leaseSweepInterval, err := strconv.Atoi(leaseSweepIntervalStr)
+	if err != nil || leaseSweepInterval <= 0 {
+		invalidConfig("Invalid LEASE_SWEEP_INTERVAL: %s", leaseSweepIntervalStr)
+	}
+	orphanCleanupEnabled := strings.ToLower(getEnv("JOB_ORPHAN_CLEANUP_ENABLED", "false")) == "true"
+	orphanCleanupIntervalStr := getEnv("ORPHAN_CLEANUP_INTERVAL", "3600")
+	orphanCleanupInterval, err := strconv.Atoi(orphanCleanupIntervalStr)
+	if err != nil || orphanCleanupInterval <= 0 {
+		invalidConfig("Invalid ORPHAN_CLEANUP_INTERVAL: %s", orphanCleanupIntervalStr)
+	}
+	schedulerLockingEnabled := strings.ToLower(getEnv("SCHEDULER_LOCKING_ENABLED", "true")) == "true"
+	leaderElectionEnabled := strings.ToLower(getEnv("SCHEDULER_LEADER_ELECTION_ENABLED", "true")) == "true"
+	schedulerJitterStr := getEnv("SCHEDULER_JITTER_PERCENT", strconv.Itoa(int(scheduler.DefaultJitter*100)))
+	schedulerJitter, err := strconv.Atoi(schedulerJitterStr)
+	if err != nil || schedulerJitter < 0 || schedulerJitter > 100 {
+		invalidConfig("Invalid SCHEDULER_JITTER_PERCENT: %s", schedulerJitterStr)
+	}
+	auditEnabled := strings.ToLower(getEnv("AUDIT_ENABLED", "true")) == "true"
+	auditMaxEntriesStr := getEnv("AUDIT_MAX_ENTRIES", strconv.Itoa(storage.DefaultAuditMaxEntries))
+	auditMaxEntries, err := strconv.ParseInt(auditMaxEntriesStr, 10, 64)
+	if err != nil || auditMaxEntries < 0 {
+		invalidConfig("Invalid AUDIT_MAX_ENTRIES: %s", auditMaxEntriesStr)
+	}
+	auditRetentionDaysStr := getEnv("AUDIT_RETENTION_DAYS", "0")
+	auditRetentionDays, err := strconv.Atoi(auditRetentionDaysStr)
+	if err != nil || auditRetentionDays < 0 {
+		invalidConfig("Invalid AUDIT_RETENTION_DAYS: %s", auditRetentionDaysStr)
+	}
+	auditExport := taskserver.DefaultConfig().Audit.Export
+	auditExportFormatStr := getEnv("AUDIT_EXPORT_FORMAT", string(auditExport.Format))
+	auditExport.Format, err = services.ParseAuditFormat(auditExportFormatStr)
 	if err != nil {
-		log.Fatalf("Failed to initialize Valkey client: %v", err)
+		invalidConfig("Invalid AUDIT_EXPORT_FORMAT: %s", auditExportFormatStr)
+	}
+	auditExportBatchSizeStr := getEnv("AUDIT_EXPORT_BATCH_SIZE", strconv.Itoa(auditExport.BatchSize))
+	auditExport.BatchSize, err = strconv.Atoi(auditExportBatchSizeStr)
+	if err != nil || auditExport.BatchSize <= 0 {
+		invalidConfig("Invalid AUDIT_EXPORT_BATCH_SIZE: %s", auditExportBatchSizeStr)
+	}
+	auditExportFlushIntervalStr := getEnv("AUDIT_EXPORT_FLUSH_INTERVAL_MS",
+		strconv.Itoa(int(auditExport.FlushInterval.Milliseconds())))
+	auditExportFlushInterval, err := strconv.Atoi(auditExportFlushIntervalStr)
+	if err != nil || auditExportFlushInterval <= 0 {
+		invalidConfig("Invalid AUDIT_EXPORT_FLUSH_INTERVAL_MS: %s", auditExportFlushIntervalStr)
+	}
+	auditExport.FlushInterval = time.Duration(auditExportFlushInterval) * time.Millisecond
+	auditExportQueueSizeStr := getEnv("AUDIT_EXPORT_QUEUE_SIZE", strconv.Itoa(auditExport.QueueSize))
+	auditExport.QueueSize, err = strconv.Atoi(auditExportQueueSizeStr)
+	if err != nil || auditExport.QueueSize <= 0 {
+		invalidConfig("Invalid AUDIT_EXPORT_QUEUE_SIZE: %s", auditExportQueueSizeStr)
+	}
+	auditExportBlockStr := getEnv("AUDIT_EXPORT_BLOCK_MS", strconv.Itoa(int(auditExport.BlockTimeout.Milliseconds())))
+	auditExportBlock, err := strconv.Atoi(auditExportBlockStr)
+	if err != nil || auditExportBlock < 0 {
+		invalidConfig("Invalid AUDIT_EXPORT_BLOCK_MS: %s", auditExportBlockStr)
+	}
+	auditExport.BlockTimeout = time.Duration(auditExportBlock) * time.Millisecond
+	planRetentionDaysStr := getEnv("PLAN_RETENTION_DAYS", "0")
+	planRetentionDays, err := strconv.Atoi(planRetentionDaysStr)
+	if err != nil || planRetentionDays < 0 {
+		invalidConfig("Invalid PLAN_RETENTION_DAYS: %s", planRetentionDaysStr)
+	}
+	planRetentionActionStr := getEnv("PLAN_RETENTION_ACTION", string(services.RetentionArchive))
+	planRetentionAction, err := services.ParseRetentionAction(planRetentionActionStr)
+	if err != nil {
+		invalidConfig("Invalid PLAN_RETENTION_ACTION: %s", planRetentionActionStr)
+	}
+	planArchiveDir := getEnv("PLAN_ARCHIVE_DIR", "archive")
+	retentionSweepIntervalStr := getEnv("RETENTION_SWEEP_INTERVAL", "3600")
+	retentionSweepInterval, err := strconv.Atoi(retentionSweepIntervalStr)
+	if err != nil || retentionSweepInterval <= 0 {
+		invalidConfig("Invalid RETENTION_SWEEP_INTERVAL: %s", retentionSweepIntervalStr)
+	}
+	idempotencyTTLStr := getEnv("IDEMPOTENCY_KEY_TTL", strconv.Itoa(int(storage.DefaultIdempotencyTTL.Seconds())))
+	idempotencyTTL, err := strconv.Atoi(idempotencyTTLStr)
+	if err != nil || idempotencyTTL <= 0 {
+		invalidConfig("Invalid IDEMPOTENCY_KEY_TTL: %s", idempotencyTTLStr)
+	}
+	adminToolsEnabled := strings.ToLower(getEnv("ADMIN_TOOLS_ENABLED", "false")) == "true"
+	requireKnownApplications := strings.ToLower(getEnv("REQUIRE_KNOWN_APPLICATIONS", "false")) == "true"
+	requireCompletionNotes := strings.ToLower(getEnv("REQUIRE_COMPLETION_NOTES", "false")) == "true"
+	requireAcceptanceCriteria := strings.ToLower(getEnv("REQUIRE_ACCEPTANCE_CRITERIA", "false")) == "true"
+	var reviewStatuses []models.TaskStatus
+	if strings.ToLower(getEnv("REVIEW_WORKFLOW", "false")) == "true" {
+		reviewStatusesStr := getEnv("REVIEW_STATUSES", "in_review,approved,rejected")
+		reviewStatuses, err = models.ParseReviewStatuses(reviewStatusesStr)
+		if err != nil {
+			invalidConfig("Invalid REVIEW_STATUSES: %v", err)
+		}
+	}
+	customStatuses := models.ParseCustomTaskStatuses(getEnv("CUSTOM_TASK_STATUSES", ""))
+	customPriorities, err := models.ParseCustomTaskPriorities(getEnv("CUSTOM_TASK_PRIORITIES", ""))
+	if err != nil {
+		invalidConfig("Invalid CUSTOM_TASK_PRIORITIES: %v", err)
+	}
+	// Applied right away, as the Jira mapping is checked against the statuses and priorities
+	if err := models.EnableReviewStatuses(reviewStatuses); err != nil {
+		invalidConfig("Invalid REVIEW_STATUSES: %v", err)
+	}
+	if err := models.SetCustomTaskStatuses(customStatuses); err != nil {
+		invalidConfig("Invalid CUSTOM_TASK_STATUSES: %v", err)
+	}
+	if err := models.SetCustomTaskPriorities(customPriorities); err != nil {
+		invalidConfig("Invalid CUSTOM_TASK_PRIORITIES: %v", err)
+	}
+	escalationHoursStr := getEnv("PRIORITY_ESCALATION_HOURS", "0")
+	escalationHours, err := strconv.Atoi(escalationHoursStr)
+	if err != nil || escalationHours < 0 {
+		invalidConfig("Invalid PRIORITY_ESCALATION_HOURS: %s", escalationHoursStr)
+	}
+	escalationMaxStr := getEnv("PRIORITY_ESCALATION_MAX", string(models.TaskPriorityHigh))
+	if err := models.ValidateTaskPriority(models.TaskPriority(escalationMaxStr)); err != nil {
+		invalidConfig("Invalid PRIORITY_ESCALATION_MAX: %v", err)
+	}
+	escalationSweepIntervalStr := getEnv("ESCALATION_SWEEP_INTERVAL", "3600")
+	escalationSweepInterval, err := strconv.Atoi(escalationSweepIntervalStr)
+	if err != nil || escalationSweepInterval <= 0 {
+		invalidConfig("Invalid ESCALATION_SWEEP_INTERVAL: %s", escalationSweepIntervalStr)
+	}
+	limits := storage.DefaultLimits()
+	maxTitleLengthStr := getEnv("MAX_TITLE_LENGTH", strconv.Itoa(limits.MaxTitleLength))
+	limits.MaxTitleLength, err = strconv.Atoi(maxTitleLengthStr)
+	if err != nil || limits.MaxTitleLength < 0 {
+		invalidConfig("Invalid MAX_TITLE_LENGTH: %s", maxTitleLengthStr)
+	}
+	maxDescriptionLengthStr := getEnv("MAX_DESCRIPTION_LENGTH", strconv.Itoa(limits.MaxDescriptionLength))
+	limits.MaxDescriptionLength, err = strconv.Atoi(maxDescriptionLengthStr)
+	if err != nil || limits.MaxDescriptionLength < 0 {
+		invalidConfig("Invalid MAX_DESCRIPTION_LENGTH: %s", maxDescriptionLengthStr)
+	}
+	// Notes are also validated against the markdown limit, so a higher limit would have no effect
+	maxNotesLengthStr := getEnv("MAX_NOTES_LENGTH", strconv.Itoa(limits.MaxNotesLength))
+	limits.MaxNotesLength, err = strconv.Atoi(maxNotesLengthStr)
+	if err != nil || limits.MaxNotesLength < 0 || limits.MaxNotesLength > markdown.MaxNotesLength {
+		invalidConfig("Invalid MAX_NOTES_LENGTH: %s", maxNotesLengthStr)
+	}
+	maxBulkTasksStr := getEnv("MAX_BULK_TASKS", strconv.Itoa(limits.MaxBulkTasks))
+	limits.MaxBulkTasks, err = strconv.Atoi(maxBulkTasksStr)
+	if err != nil || limits.MaxBulkTasks < 0 {
+		invalidConfig("Invalid MAX_BULK_TASKS: %s", maxBulkTasksStr)
+	}
+	maxTasksPerPlanStr := getEnv("MAX_TASKS_PER_PLAN", strconv.Itoa(limits.MaxTasksPerPlan))
+	limits.MaxTasksPerPlan, err = strconv.Atoi(maxTasksPerPlanStr)
+	if err != nil || limits.MaxTasksPerPlan < 0 {
+		invalidConfig("Invalid MAX_TASKS_PER_PLAN: %s", maxTasksPerPlanStr)
+	}
+	decomposition := storage.DefaultDecompositionGuardrails()
+	decompositionMaxDescriptionLengthStr := getEnv("DECOMPOSITION_MAX_DESCRIPTION_LENGTH",
+		strconv.Itoa(decomposition.MaxDescriptionLength))
+	decomposition.MaxDescriptionLength, err = strconv.Atoi(decompositionMaxDescriptionLengthStr)
+	if err != nil || decomposition.MaxDescriptionLength < 0 {
+		invalidConfig("Invalid DECOMPOSITION_MAX_DESCRIPTION_LENGTH: %s", decompositionMaxDescriptionLengthStr)
+	}
+	decompositionMaxPlanTasksStr := getEnv("DECOMPOSITION_MAX_PLAN_TASKS", strconv.Itoa(decomposition.MaxPlanTasks))
+	decomposition.MaxPlanTasks, err = strconv.Atoi(decompositionMaxPlanTasksStr)
+	if err != nil || decomposition.MaxPlanTasks < 0 {
+		invalidConfig("Invalid DECOMPOSITION_MAX_PLAN_TASKS: %s", decompositionMaxPlanTasksStr)
+	}
+	switch decompositionMode := strings.ToLower(getEnv("DECOMPOSITION_MODE", "warn")); decompositionMode {
+	case "warn":
+	case "block":
+		decomposition.Block = true
+	default:
+		invalidConfig("Invalid DECOMPOSITION_MODE: %s", decompositionMode)
+	}
+	sanitize := storage.DefaultSanitizePolicies()
+	for _, setting := range []struct {
+		name   string
+		policy *markdown.Policy
+	}{
+		{"SANITIZE_TITLES", &sanitize.Titles},
+		{"SANITIZE_DESCRIPTIONS", &sanitize.Descriptions},
+		{"SANITIZE_NOTES", &sanitize.Notes},
+	} {
+		policyStr := getEnv(setting.name, setting.policy.String())
+		if *setting.policy, err = markdown.ParsePolicy(policyStr); err != nil {
+			invalidConfig("Invalid %s: %s", setting.name, policyStr)
+		}
 	}
-	defer valkeyClient.Close()
+	attachmentLimits := storage.DefaultAttachmentLimits()
+	maxAttachmentSizeStr := getEnv("MAX_ATTACHMENT_SIZE", strconv.Itoa(attachmentLimits.MaxSize))
+	attachmentLimits.MaxSize, err = strconv.Atoi(maxAttachmentSizeStr)
+	if err != nil || attachmentLimits.MaxSize < 0 {
+		invalidConfig("Invalid MAX_ATTACHMENT_SIZE: %s", maxAttachmentSizeStr)
+	}
+	maxAttachmentsPerTaskStr := getEnv("MAX_ATTACHMENTS_PER_TASK", strconv.Itoa(attachmentLimits.MaxPerTask))
+	attachmentLimits.MaxPerTask, err = strconv.Atoi(maxAttachmentsPerTaskStr)
+	if err != nil || attachmentLimits.MaxPerTask < 0 {
+		invalidConfig("Invalid MAX_ATTACHMENTS_PER_TASK: %s", maxAttachmentsPerTaskStr)
+	}
+	notesHistoryLengthStr := getEnv("NOTES_HISTORY_LENGTH", strconv.Itoa(storage.DefaultNotesHistoryLength))
+	notesHistoryLength, err := strconv.Atoi(notesHistoryLengthStr)
+	if err != nil || notesHistoryLength < 0 {
+		invalidConfig("Invalid NOTES_HISTORY_LENGTH: %s", notesHistoryLengthStr)
+	}
+	agentActivityLengthStr := getEnv("AGENT_ACTIVITY_LENGTH", strconv.Itoa(storage.DefaultAgentActivityLength))
+	agentActivityLength, err := strconv.Atoi(agentActivityLengthStr)
+	if err != nil || agentActivityLength < 0 {
+		invalidConfig("Invalid AGENT_ACTIVITY_LENGTH: %s", agentActivityLengthStr)
+	}
+	// Compacted notes have to fit the notes limit, so longer notes are archived before they are rejected
+	notesCompactLengthStr := getEnv("NOTES_COMPACT_LENGTH", "0")
+	notesCompactLength, err := strconv.Atoi(notesCompactLengthStr)
+	if err != nil || notesCompactLength < 0 || (limits.MaxNotesLength > 0 && notesCompactLength > limits.MaxNotesLength) {
+		invalidConfig("Invalid NOTES_COMPACT_LENGTH: %s", notesCompactLengthStr)
+	}
+	notesSummarizerURL := getEnv("NOTES_SUMMARIZER_URL", "")
+	var chaos taskserver.ChaosConfig
+	chaosFailureRateStr := getEnv("CHAOS_FAILURE_RATE", "0")
+	chaos.FailureRate, err = strconv.ParseFloat(chaosFailureRateStr, 64)
+	if err != nil || chaos.FailureRate < 0 || chaos.FailureRate > 1 {
+		invalidConfig("Invalid CHAOS_FAILURE_RATE: %s", chaosFailureRateStr)
+	}
+	chaosLatencyStr := getEnv("CHAOS_LATENCY_MS", "0")
+	chaosLatency, err := strconv.Atoi(chaosLatencyStr)
+	if err != nil || chaosLatency < 0 {
+		invalidConfig("Invalid CHAOS_LATENCY_MS: %s", chaosLatencyStr)
+	}
+	chaos.Latency = time.Duration(chaosLatency) * time.Millisecond
+	chaosLatencyJitterStr := getEnv("CHAOS_LATENCY_JITTER_MS", "0")
+	chaosLatencyJitter, err := strconv.Atoi(chaosLatencyJitterStr)
+	if err != nil || chaosLatencyJitter < 0 {
+		invalidConfig("Invalid CHAOS_LATENCY_JITTER_MS: %s", chaosLatencyJitterStr)
+	}
+	chaos.LatencyJitter = time.Duration(chaosLatencyJitter) * time.Millisecond
+	chaos.Operations = storage.ParseChaosOperations(getEnv("CHAOS_OPERATIONS", ""))
+	chaosSeedStr := getEnv("CHAOS_SEED", "0")
+	chaos.Seed, err = strconv.ParseInt(chaosSeedStr, 10, 64)
+	if err != nil {
+		invalidConfig("Invalid CHAOS_SEED: %s", chaosSeedStr)
+	}
+	fieldEncryptionKeys, err := storage.ParseStaticKeys(getEnv("FIELD_ENCRYPTION_KEYS", ""))
+	if err != nil {
+		invalidConfig("Invalid FIELD_ENCRYPTION_KEYS: %v", err)
+	}
+	notifyWebhookURL := getEnv("NOTIFY_WEBHOOK_URL", "")
+	notifySlackWebhookURL := getEnv("NOTIFY_SLACK_WEBHOOK_URL", "")
+	notifyStream := strings.ToLower(getEnv("NOTIFY_STREAM", "false")) == "true"
+	resourceCache := taskserver.DefaultConfig().ResourceCache
+	resourceCacheTTLStr := getEnv("RESOURCE_CACHE_TTL", strconv.Itoa(int(resourceCache.TTL.Seconds())))
+	resourceCacheTTL, err := strconv.Atoi(resourceCacheTTLStr)
+	if err != nil || resourceCacheTTL < 0 {
+		invalidConfig("Invalid RESOURCE_CACHE_TTL: %s", resourceCacheTTLStr)
+	}
+	resourceCache.TTL = time.Duration(resourceCacheTTL) * time.Second
+	resourceCacheMaxEntriesStr := getEnv("RESOURCE_CACHE_MAX_ENTRIES", strconv.Itoa(resourceCache.MaxEntries))
+	resourceCache.MaxEntries, err = strconv.Atoi(resourceCacheMaxEntriesStr)
+	if err != nil || resourceCache.MaxEntries <= 0 {
+		invalidConfig("Invalid RESOURCE_CACHE_MAX_ENTRIES: %s", resourceCacheMaxEntriesStr)
+	}
+	configWatchIntervalStr := getEnv("CONFIG_WATCH_INTERVAL", "5")
+	configWatchInterval, err := strconv.Atoi(configWatchIntervalStr)
+	if err != nil || configWatchInterval < 0 {
+		invalidConfig("Invalid CONFIG_WATCH_INTERVAL: %s", configWatchIntervalStr)
+	}
+	githubToken := getEnv("GITHUB_TOKEN", "")
+	githubRepo := getEnv("GITHUB_REPO", "")
+	githubAPIURL := getEnv("GITHUB_API_URL", github.DefaultAPIURL)
+	if githubRepo != "" {
+		if err := github.ValidateRepo(githubRepo); err != nil {
+			invalidConfig("Invalid GITHUB_REPO: %v", err)
+		}
+	}
+	jiraURL := getEnv("JIRA_URL", "")
+	jiraEmail := getEnv("JIRA_EMAIL", "")
+	jiraToken := getEnv("JIRA_API_TOKEN", "")
+	jiraMapping := jira.DefaultFieldMapping()
+	if mappingFile := getEnv("JIRA_MAPPING_FILE", ""); mappingFile != "" {
+		jiraMapping, err = jira.LoadFieldMapping(mappingFile)
+		if err != nil {
+			invalidConfig("Invalid JIRA_MAPPING_FILE: %v", err)
+		}
+	}
+
+	cfg := taskserver.DefaultConfig()
+	cfg.Storage = storageBackend
+	cfg.Memory = taskserver.MemoryConfig{
+		SnapshotFile:     memorySnapshotFile,
+		SnapshotInterval: time.Duration(memorySnapshotInterval) * time.Second,
+	}
+	cfg.Valkey = taskserver.ValkeyConfig{
+		Addresses: valkeyAddresses,
+		Username:  valkeyUsername,
+		Password:  valkeyPassword,
+		Cluster:   valkeyCluster,
+
+		SentinelAddresses: valkeySentinelAddresses,
+		SentinelMaster:    valkeySentinelMaster,
+		SentinelUsername:  valkeySentinelUsername,
+		SentinelPassword:  valkeySentinelPassword,
+		ReadFromReplica:   valkeyReadFromReplica,
+
+		TLS:                   valkeyTLS,
+		TLSCAFile:             valkeyTLSCAFile,
+		TLSCertFile:           valkeyTLSCertFile,
+		TLSKeyFile:            valkeyTLSKeyFile,
+		TLSInsecureSkipVerify: valkeyTLSInsecure,
+		Database:              valkeyDB,
+
+		PoolSize:          valkeyPoolSize,
+		RequestTimeout:    time.Duration(valkeyRequestTimeout) * time.Millisecond,
+		ConnectionTimeout: time.Duration(valkeyConnectTimeout) * time.Millisecond,
+		ReconnectRetries:  valkeyReconnectRetries,
+		ReconnectDelay:    time.Duration(valkeyReconnectDelay) * time.Millisecond,
+	}
+	cfg.Retry = retryPolicy
+	cfg.Limits = limits
+	cfg.Sanitize = sanitize
+	cfg.AttachmentLimits = attachmentLimits
+	cfg.Decomposition = decomposition
+	cfg.Chaos = chaos
+	if len(fieldEncryptionKeys) > 0 {
+		cfg.FieldEncryption = fieldEncryptionKeys
+	}
+	cfg.Port = serverPort
+	cfg.NotesHistoryLength = notesHistoryLength
+	cfg.NotesCompactLength = notesCompactLength
+	cfg.NotesSummarizer = notesSummarizer(notesSummarizerURL)
+	cfg.AgentActivityLength = agentActivityLength
+	cfg.Audit = taskserver.AuditConfig{
+		Enabled: auditEnabled,
+		Retention: taskserver.AuditRetention{
+			MaxEntries: auditMaxEntries,
+			MaxAge:     time.Duration(auditRetentionDays) * 24 * time.Hour,
+		},
+		Sinks: auditSinks(getEnv("AUDIT_EXPORT_FILE", ""), getEnv("AUDIT_EXPORT_SYSLOG", ""),
+			getEnv("AUDIT_EXPORT_URL", ""), getEnv("AUDIT_EXPORT_TOKEN", "")),
+		Export: auditExport,
+	}
+	cfg.Notifications = taskserver.NotificationsConfig{
+		Notifiers: notifiers(notifyWebhookURL, notifySlackWebhookURL),
+		Stream:    notifyStream,
+	}
+	cfg.ResourceCache = resourceCache
+	cfg.Jobs = taskserver.JobsConfig{
+		LeaseExpiry:           leaseExpiryEnabled,
+		LeaseSweepInterval:    time.Duration(leaseSweepInterval) * time.Second,
+		OrphanCleanup:         orphanCleanupEnabled,
+		OrphanCleanupInterval: time.Duration(orphanCleanupInterval) * time.Second,
+		Locking:               schedulerLockingEnabled,
+		LeaderElection:        leaderElectionEnabled,
+		Jitter:                float64(schedulerJitter) / 100,
+	}
+	cfg.Retention = taskserver.RetentionPolicy{
+		MaxAge:     time.Duration(planRetentionDays) * 24 * time.Hour,
+		Action:     planRetentionAction,
+		ArchiveDir: planArchiveDir,
+	}
+	cfg.RetentionSweepInterval = time.Duration(retentionSweepInterval) * time.Second
+	cfg.Escalation = taskserver.EscalationPolicy{
+		After:       time.Duration(escalationHours) * time.Hour,
+		MaxPriority: models.TaskPriority(escalationMaxStr),
+	}
+	cfg.EscalationSweepInterval = time.Duration(escalationSweepInterval) * time.Second
+	cfg.IdempotencyTTL = time.Duration(idempotencyTTL) * time.Second
+	cfg.AdminTools = adminToolsEnabled
+	cfg.RequireKnownApplications = requireKnownApplications
+	cfg.RequireCompletionNotes = requireCompletionNotes
+	cfg.RequireAcceptanceCriteria = requireAcceptanceCriteria
+	cfg.ReviewStatuses = reviewStatuses
+	cfg.CustomStatuses = customStatuses
+	cfg.CustomPriorities = customPriorities
+	cfg.GitHub = taskserver.GitHubConfig{Token: githubToken, Repo: githubRepo, APIURL: githubAPIURL}
+	cfg.Jira = taskserver.JiraConfig{URL: jiraURL, Email: jiraEmail, Token: jiraToken, Mapping: jiraMapping}
 
-	// Ping Valkey to ensure connection
+	// Report every problem and exit when only checking the configuration
 	ctx := context.Background()
-	if err := valkeyClient.Ping(ctx); err != nil {
-		log.Fatalf("Failed to connect to Valkey: %v", err)
+	if *checkConfig {
+		os.Exit(runConfigCheck(ctx, os.Stdout, storageBackend, cfg.Memory, cfg.Valkey))
 	}
-	log.Printf("Connected to Valkey at %s:%d", valkeyHost, valkeyPort)
 
-	// Initialize repositories
-	planRepo := storage.NewPlanRepository(valkeyClient)
-	taskRepo := storage.NewTaskRepository(valkeyClient)
+	// The MCP server settings are read from the environment and the config file, so they can be reloaded
+	srv, err := taskserver.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create server: %v", err)
+	}
 
-	// Create MCP server using the mark3labs/mcp-go library
-	// Convert concrete types to interfaces
-	var planRepoInterface storage.PlanRepositoryInterface = planRepo
-	var taskRepoInterface storage.TaskRepositoryInterface = taskRepo
-	mcpServer := mcp.NewMCPGoServer(planRepoInterface, taskRepoInterface)
+	// Apply the settings that can change while the server runs again on SIGHUP or when the config file changes
+	reloadCtx, stopReloads := context.WithCancel(ctx)
+	defer stopReloads()
+	go watchReloads(reloadCtx, time.Duration(configWatchInterval)*time.Second, func() {
+		if err := config.Reload(); err != nil {
+			log.Printf("Warning: failed to reload the config file, keeping the current configuration: %v", err)
+			return
+		}
+		srv.Reload()
+		srv.SetNotesSummarizer(notesSummarizer(getEnv("NOTES_SUMMARIZER_URL", "")))
+		log.Printf("Configuration reloaded")
+	})
 
 	// Set up signal handling for graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
-	// Start the MCP server in a goroutine
+	// Start the server in a goroutine
 	go func() {
-		log.Printf("Initializing MCP server on port %d", serverPort)
-		if err := mcpServer.Start(serverPort); err != nil {
+		if err := srv.Start(); err != nil {
 			log.Fatalf("MCP server error: %v", err)
 		}
 	}()
@@ -70,14 +542,81 @@ func main() {
 	log.Println("Shutting down server...")
 
 	// Give the server some time to finish ongoing requests
-	time.Sleep(2 * time.Second)
+	shutdownCtx, cancel := context.WithTimeout(ctx, shutdownTimeout)
+	defer cancel()
+	if err := srv.Stop(shutdownCtx); err != nil {
+		log.Printf("Warning: server did not shut down cleanly: %v", err)
+	}
 
 	log.Println("Server exited properly")
 }
 
-// getEnv gets an environment variable or returns a default value
+// configProblems collects the invalid settings found by --check-config
+var configProblems []string
+
+// invalidConfig reports an invalid setting. The server exits on the first one, while --check-config collects
+// them all for its report.
+func invalidConfig(format string, args ...any) {
+	if *checkConfig {
+		configProblems = append(configProblems, fmt.Sprintf(format, args...))
+		return
+	}
+	log.Fatalf(format, args...)
+}
+
+// notesSummarizer returns the summarizer of archived notes posting to a webhook, or nil without a URL
+func notesSummarizer(url string) storage.NotesSummarizer {
+	if url == "" {
+		return nil
+	}
+	return services.NewWebhookSummarizer(url)
+}
+
+// auditSinks returns the sinks of the audit export for the configured file, syslog address and URL
+func auditSinks(file, syslogAddress, url, token string) []services.AuditSink {
+	var sinks []services.AuditSink
+	if file != "" {
+		sink, err := services.NewFileSink(file)
+		if err != nil {
+			invalidConfig("Invalid AUDIT_EXPORT_FILE: %v", err)
+		} else {
+			sinks = append(sinks, sink)
+		}
+	}
+	if syslogAddress != "" {
+		sink, err := services.NewSyslogSink(syslogAddress)
+		if err != nil {
+			invalidConfig("Invalid AUDIT_EXPORT_SYSLOG: %v", err)
+		} else {
+			sinks = append(sinks, sink)
+		}
+	}
+	if url != "" {
+		sink, err := services.NewHTTPSink(url, token)
+		if err != nil {
+			invalidConfig("Invalid AUDIT_EXPORT_URL: %v", err)
+		} else {
+			sinks = append(sinks, sink)
+		}
+	}
+	return sinks
+}
+
+// notifiers returns the notifiers of watch notifications for the configured webhook URLs
+func notifiers(webhookURL, slackWebhookURL string) []storage.Notifier {
+	var notifiers []storage.Notifier
+	if webhookURL != "" {
+		notifiers = append(notifiers, services.NewWebhookNotifier(webhookURL))
+	}
+	if slackWebhookURL != "" {
+		notifiers = append(notifiers, services.NewSlackNotifier(slackWebhookURL))
+	}
+	return notifiers
+}
+
+// getEnv gets a setting from the environment or the config file, or returns a default value
 func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
+	if value, exists := config.Lookup(key); exists {
 		return value
 	}
 	return defaultValue
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/config"
+)
+
+// configFile is a YAML or .env file of settings, which is read again on reload
+var configFile = flag.String("config", "",
+	"YAML or .env file of settings, overridden by the environment and read again on SIGHUP and when it changes")
+
+// watchReloads calls reload on SIGHUP and, with a config file and a positive interval, when the file changes.
+// It returns when the context is done.
+func watchReloads(ctx context.Context, interval time.Duration, reload func()) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	var changes <-chan time.Time
+	if config.Path() != "" && interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		changes = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			log.Printf("Received SIGHUP, reloading configuration")
+			reload()
+		case <-changes:
+			if config.Changed() {
+				log.Printf("%s changed, reloading configuration", config.Path())
+				reload()
+			}
+		}
+	}
+}
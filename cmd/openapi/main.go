@@ -0,0 +1,27 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/api"
+)
+
+// main writes the OpenAPI specification of the REST API, or of the admin API with -admin, to stdout
+func main() {
+	admin := flag.Bool("admin", false, "write the specification of the admin API")
+	flag.Parse()
+
+	spec := api.OpenAPISpec()
+	if *admin {
+		spec = api.AdminOpenAPISpec()
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(spec); err != nil {
+		log.Fatalf("Failed to write OpenAPI spec: %v", err)
+	}
+}
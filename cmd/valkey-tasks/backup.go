@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+func newExportCommand(opts *cliOptions) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "export <plan-id>",
+		Short: "Export a plan and its tasks as a JSON backup",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.connect()
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			backup, err := client.ExportPlan(cmd.Context(), args[0])
+			if err != nil {
+				return fmt.Errorf("failed to export plan: %w", err)
+			}
+
+			if output == "" || output == "-" {
+				return writeJSON(cmd.OutOrStdout(), backup)
+			}
+
+			file, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", output, err)
+			}
+
+			err = writeJSON(file, backup)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return fmt.Errorf("failed to write %s: %w", output, err)
+			}
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "Exported plan %s with %d task(s) to %s\n", backup.Plan.ID, len(backup.Tasks), output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "File to write the backup to (default stdout)")
+	return cmd
+}
+
+func newImportCommand(opts *cliOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "import <file>",
+		Short: "Restore a plan and its tasks from a JSON backup, keeping their original IDs",
+		Long: "Restore a plan and its tasks from a backup written by export. Use - to read the backup from stdin.\n" +
+			"Existing versions of the plan and tasks are overwritten.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			backup, err := readBackup(cmd.InOrStdin(), args[0])
+			if err != nil {
+				return err
+			}
+
+			client, err := opts.connect()
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			restored, err := client.ImportPlan(cmd.Context(), backup)
+			if err != nil {
+				return fmt.Errorf("failed to import plan: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Imported plan %s with %d task(s)\n", restored.Plan.ID, len(restored.Tasks))
+			return nil
+		},
+	}
+}
+
+// readBackup reads a plan backup from a file, or from stdin when the path is -
+func readBackup(stdin io.Reader, path string) (*models.PlanResource, error) {
+	reader := stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		defer file.Close() //nolint:errcheck
+		reader = file
+	}
+
+	var backup models.PlanResource
+	if err := json.NewDecoder(reader).Decode(&backup); err != nil {
+		return nil, fmt.Errorf("failed to parse backup: %w", err)
+	}
+	return &backup, nil
+}
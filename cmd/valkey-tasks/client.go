@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/api"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+	"github.com/jbrinkman/valkey-ai-tasks/taskclient"
+)
+
+// apiClient talks to the REST API, either over the network or in-process when connected directly to Valkey
+type apiClient struct {
+	*taskclient.Client
+	close func()
+}
+
+// newServerClient creates a client for the REST API of a running server
+func newServerClient(serverURL string) *apiClient {
+	return &apiClient{
+		Client: taskclient.New(serverURL),
+		close:  func() {},
+	}
+}
+
+// connectValkey connects directly to the Valkey database configured by the options
+func connectValkey(opts *cliOptions) (*storage.ValkeyClient, error) {
+	addresses := []storage.ValkeyAddress{{Host: opts.valkeyHost, Port: opts.valkeyPort}}
+	if opts.valkeyAddrs != "" {
+		var err error
+		addresses, err = storage.ParseValkeyAddresses(opts.valkeyAddrs, opts.valkeyPort)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var sentinels []storage.ValkeyAddress
+	if opts.sentinelAddrs != "" {
+		var err error
+		sentinels, err = storage.ParseValkeyAddresses(opts.sentinelAddrs, storage.DefaultSentinelPort)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	valkeyClient, err := storage.NewValkeyClientWithConfig(storage.ValkeyConfig{
+		Addresses: addresses,
+		Username:  opts.valkeyUsername,
+		Password:  opts.valkeyPassword,
+		Cluster:   opts.valkeyCluster,
+
+		SentinelAddresses: sentinels,
+		SentinelMaster:    opts.sentinelMaster,
+		SentinelPassword:  opts.sentinelPass,
+
+		TLS:                   opts.tls,
+		TLSCAFile:             opts.tlsCAFile,
+		TLSCertFile:           opts.tlsCertFile,
+		TLSKeyFile:            opts.tlsKeyFile,
+		TLSInsecureSkipVerify: opts.tlsInsecure,
+		Database:              opts.database,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Valkey: %w", err)
+	}
+	// Read and write keys in the layout the server uses
+	storage.SetClusterKeyLayout(opts.valkeyCluster)
+
+	// Encrypt and decrypt fields with the keys of the server
+	keys, err := storage.ParseStaticKeys(getEnv("FIELD_ENCRYPTION_KEYS", ""))
+	if err != nil {
+		valkeyClient.Close()
+		return nil, fmt.Errorf("invalid FIELD_ENCRYPTION_KEYS: %w", err)
+	}
+	if len(keys) > 0 {
+		fieldCipher, err := storage.NewFieldCipher(context.Background(), keys)
+		if err != nil {
+			valkeyClient.Close()
+			return nil, fmt.Errorf("invalid FIELD_ENCRYPTION_KEYS: %w", err)
+		}
+		valkeyClient.SetFieldCipher(fieldCipher)
+	}
+
+	// Accept the task statuses and priorities the server accepts
+	if err := configureTaskValues(); err != nil {
+		valkeyClient.Close()
+		return nil, err
+	}
+	return valkeyClient, nil
+}
+
+// configureTaskValues reads the review, custom status and custom priority settings of the server
+func configureTaskValues() error {
+	var reviewStatuses []models.TaskStatus
+	if strings.ToLower(getEnv("REVIEW_WORKFLOW", "false")) == "true" {
+		var err error
+		reviewStatuses, err = models.ParseReviewStatuses(getEnv("REVIEW_STATUSES", "in_review,approved,rejected"))
+		if err != nil {
+			return fmt.Errorf("invalid REVIEW_STATUSES: %w", err)
+		}
+	}
+	if err := models.EnableReviewStatuses(reviewStatuses); err != nil {
+		return fmt.Errorf("invalid REVIEW_STATUSES: %w", err)
+	}
+	if err := models.SetCustomTaskStatuses(models.ParseCustomTaskStatuses(getEnv("CUSTOM_TASK_STATUSES", ""))); err != nil {
+		return fmt.Errorf("invalid CUSTOM_TASK_STATUSES: %w", err)
+	}
+	priorities, err := models.ParseCustomTaskPriorities(getEnv("CUSTOM_TASK_PRIORITIES", ""))
+	if err != nil {
+		return fmt.Errorf("invalid CUSTOM_TASK_PRIORITIES: %w", err)
+	}
+	if err := models.SetCustomTaskPriorities(priorities); err != nil {
+		return fmt.Errorf("invalid CUSTOM_TASK_PRIORITIES: %w", err)
+	}
+	return nil
+}
+
+// newDirectClient creates a client that serves the REST API in-process on top of a direct Valkey connection,
+// so both modes share the same validation and behavior
+func newDirectClient(opts *cliOptions) (*apiClient, error) {
+	valkeyClient, err := connectValkey(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var planRepo storage.PlanRepositoryInterface = storage.NewPlanRepository(valkeyClient)
+	var taskRepo storage.TaskRepositoryInterface = storage.NewTaskRepository(valkeyClient)
+
+	// Record changes in the audit log like the server does, unless it is disabled
+	if strings.ToLower(getEnv("AUDIT_ENABLED", "true")) == "true" {
+		auditLog := storage.NewAuditLog(valkeyClient, storage.AuditRetention{MaxEntries: storage.DefaultAuditMaxEntries})
+		planRepo = storage.NewAuditedPlanRepository(planRepo, auditLog)
+		taskRepo = storage.NewAuditedTaskRepository(taskRepo, auditLog)
+	}
+
+	return &apiClient{
+		Client: taskclient.New("http://valkey", taskclient.WithHTTPClient(&http.Client{
+			Transport: handlerTransport{handler: api.NewHandler(planRepo, taskRepo)},
+		})),
+		close: func() { valkeyClient.Close() },
+	}, nil
+}
+
+// handlerTransport serves requests with an in-process handler instead of the network
+type handlerTransport struct {
+	handler http.Handler
+}
+
+// RoundTrip serves the request with the handler and returns the recorded response
+func (t handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	t.handler.ServeHTTP(recorder, req)
+	return recorder.Result(), nil
+}
+
+// Close releases the client's connection
+func (c *apiClient) Close() {
+	c.close()
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/api"
+	"github.com/jbrinkman/valkey-ai-tasks/taskclient"
+)
+
+// TestClientReportsAPIErrors checks that error responses of the REST API surface as command errors
+func TestClientReportsAPIErrors(t *testing.T) {
+	// Requests that fail validation never reach the repositories
+	server := httptest.NewServer(api.NewHandler(nil, nil))
+	defer server.Close()
+
+	client := newServerClient(server.URL + "/")
+	ctx := context.Background()
+
+	_, err := client.CreateTask(ctx, "plan-1", api.TaskCreateRequest{Title: "Task", Priority: "urgent"})
+	if err == nil || !strings.Contains(err.Error(), "invalid priority: urgent") {
+		t.Errorf("expected the API validation error, got %v", err)
+	}
+
+	_, err = client.ListPlans(ctx, taskclient.PlanFilter{Status: "done"})
+	if err == nil || !strings.Contains(err.Error(), "invalid status: done") {
+		t.Errorf("expected the API validation error, got %v", err)
+	}
+}
+
+// TestHandlerTransport checks that the in-process transport used by --direct serves requests without a network
+func TestHandlerTransport(t *testing.T) {
+	client := &apiClient{
+		Client: taskclient.New("http://valkey", taskclient.WithHTTPClient(&http.Client{
+			Transport: handlerTransport{handler: api.NewHandler(nil, nil)},
+		})),
+		close: func() {},
+	}
+
+	// Validation fails in the handler before the repositories are needed
+	_, err := client.ListTasks(context.Background(), "plan-1", taskclient.TaskFilter{Status: "done"})
+	if err == nil || !strings.Contains(err.Error(), "invalid status: done") {
+		t.Errorf("expected the handler's validation error, got %v", err)
+	}
+}
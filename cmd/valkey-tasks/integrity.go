@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+func newCheckCommand(opts *cliOptions) *cobra.Command {
+	var repair, asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check the database for inconsistencies between plans and tasks",
+		Long: "check scans Valkey for plans missing from the plan list, tasks missing from the task set of their plan,\n" +
+			"task set entries without a stored task and tasks sharing an order value, and with --repair fixes them.\n" +
+			"It reads the keys itself, so it needs --direct. It exits with an error while unrepaired issues remain.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !opts.direct {
+				return fmt.Errorf("check needs --direct, as it scans the Valkey database itself")
+			}
+
+			valkeyClient, err := connectValkey(opts)
+			if err != nil {
+				return err
+			}
+			defer valkeyClient.Close()
+
+			report, err := storage.NewIntegrityChecker(valkeyClient).Check(cmd.Context(), repair)
+			if err != nil {
+				return fmt.Errorf("failed to check data integrity: %w", err)
+			}
+
+			if asJSON {
+				err = writeJSON(cmd.OutOrStdout(), report)
+			} else {
+				err = renderIntegrityReport(cmd.OutOrStdout(), report)
+			}
+			if err != nil {
+				return err
+			}
+
+			unrepaired := 0
+			for _, issue := range report.Issues {
+				if !issue.Repaired {
+					unrepaired++
+				}
+			}
+			if unrepaired > 0 {
+				return fmt.Errorf("%d issue(s) not repaired", unrepaired)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&repair, "repair", false, "Repair the issues found")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the report as JSON")
+	return cmd
+}
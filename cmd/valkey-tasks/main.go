@@ -0,0 +1,27 @@
+// Command valkey-tasks is a command-line client for inspecting and managing the plans and tasks
+// that agents keep in a valkey-ai-tasks server.
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+func main() {
+	// Changes made directly against Valkey are attributed to the CLI in the audit log
+	ctx := storage.WithActor(context.Background(), "cli")
+
+	if err := newRootCommand().ExecuteContext(ctx); err != nil {
+		os.Exit(1)
+	}
+}
+
+// getEnv gets an environment variable or returns a default value
+func getEnv(key, defaultValue string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	return defaultValue
+}
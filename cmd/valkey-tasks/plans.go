@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbrinkman/valkey-ai-tasks/taskclient"
+)
+
+// newPlansCommand creates the commands for browsing plans
+func newPlansCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plans",
+		Short: "Browse plans",
+	}
+
+	cmd.AddCommand(newPlansListCommand(opts), newPlansShowCommand(opts))
+	return cmd
+}
+
+func newPlansListCommand(opts *cliOptions) *cobra.Command {
+	var applicationID, status string
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List plans",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.connect()
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			plans, err := client.ListPlans(cmd.Context(), taskclient.PlanFilter{
+				ApplicationID: applicationID,
+				Status:        taskclient.PlanStatus(status),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to list plans: %w", err)
+			}
+
+			if asJSON {
+				return writeJSON(cmd.OutOrStdout(), plans)
+			}
+			return renderPlans(cmd.OutOrStdout(), plans)
+		},
+	}
+
+	cmd.Flags().StringVar(&applicationID, "app", "", "Only list plans of this application")
+	cmd.Flags().StringVar(&status, "status", "", "Only list plans with this status (new, inprogress, completed, cancelled)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the plans as JSON")
+	return cmd
+}
+
+func newPlansShowCommand(opts *cliOptions) *cobra.Command {
+	var view string
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "show <plan-id>",
+		Short: "Show a plan and its tasks as a table or kanban board",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if view != viewTable && view != viewKanban {
+				return fmt.Errorf("invalid view %q: expected %s or %s", view, viewTable, viewKanban)
+			}
+
+			client, err := opts.connect()
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			plan, err := client.GetPlan(cmd.Context(), args[0])
+			if err != nil {
+				return fmt.Errorf("failed to get plan: %w", err)
+			}
+
+			tasks, err := client.ListTasks(cmd.Context(), plan.ID, taskclient.TaskFilter{})
+			if err != nil {
+				return fmt.Errorf("failed to list tasks: %w", err)
+			}
+
+			if asJSON {
+				return writeJSON(cmd.OutOrStdout(), map[string]any{"plan": plan, "tasks": tasks})
+			}
+
+			renderPlanHeader(cmd.OutOrStdout(), plan, tasks)
+			if view == viewKanban {
+				return renderKanban(cmd.OutOrStdout(), tasks)
+			}
+			return renderTaskTable(cmd.OutOrStdout(), tasks)
+		},
+	}
+
+	cmd.Flags().StringVar(&view, "view", viewTable, "How to show the tasks: table or kanban")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the plan and its tasks as JSON")
+	return cmd
+}
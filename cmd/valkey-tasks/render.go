@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// Task views supported by plans show
+const (
+	viewTable  = "table"
+	viewKanban = "kanban"
+)
+
+// kanbanCardWidth is the maximum width of a card title on the kanban board
+const kanbanCardWidth = 32
+
+// kanbanColumns are the statuses always shown on the kanban board, in board order. Other statuses, such as
+// the review and custom statuses a server may allow, get a column before completed when they have tasks, as
+// the client does not know which statuses the server allows.
+var kanbanColumns = []models.TaskStatus{
+	models.TaskStatusPending,
+	models.TaskStatusInProgress,
+	models.TaskStatusCompleted,
+	models.TaskStatusCancelled,
+}
+
+// writeJSON writes a value as indented JSON
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// newTabWriter creates a tab writer that aligns columns with two spaces of padding
+func newTabWriter(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+}
+
+// renderPlans writes a table of plans
+func renderPlans(w io.Writer, plans []*models.Plan) error {
+	if len(plans) == 0 {
+		fmt.Fprintln(w, "No plans found")
+		return nil
+	}
+
+	tw := newTabWriter(w)
+	fmt.Fprintln(tw, "ID\tAPPLICATION\tNAME\tSTATUS\tCOMPLETED\tUPDATED")
+	for _, plan := range plans {
+		// Plans whose tasks are not counted yet show no progress
+		completed := "-"
+		if plan.TaskCounts != nil {
+			completed = fmt.Sprintf("%d/%d", plan.TaskCounts.Completed, plan.TaskCounts.Total)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			plan.ID, plan.ApplicationID, plan.Name, plan.Status, completed, plan.UpdatedAt.Local().Format(time.DateTime))
+	}
+	return tw.Flush()
+}
+
+// renderPlanHeader writes the name, status and progress of a plan
+func renderPlanHeader(w io.Writer, plan *models.Plan, tasks []*models.Task) {
+	completed := 0
+	for _, task := range tasks {
+		if task.Status == models.TaskStatusCompleted {
+			completed++
+		}
+	}
+
+	fmt.Fprintf(w, "%s (%s)\n", plan.Name, plan.Status)
+	fmt.Fprintf(w, "Application: %s  ID: %s  Completed: %d/%d\n\n", plan.ApplicationID, plan.ID, completed, len(tasks))
+}
+
+// renderTaskTable writes the tasks of a plan as a table in plan order
+func renderTaskTable(w io.Writer, tasks []*models.Task) error {
+	if len(tasks) == 0 {
+		fmt.Fprintln(w, "No tasks")
+		return nil
+	}
+
+	tw := newTabWriter(w)
+	fmt.Fprintln(tw, "#\tID\tSTATUS\tPRIORITY\tTITLE")
+	for _, task := range tasks {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", task.Order, task.ID, task.Status, task.Priority, task.Title)
+	}
+	return tw.Flush()
+}
+
+// renderIntegrityReport writes the issues found by an integrity check as a table with a summary line
+func renderIntegrityReport(w io.Writer, report *storage.IntegrityReport) error {
+	fmt.Fprintf(w, "Checked %d plan(s) and %d task(s)\n", report.PlansChecked, report.TasksChecked)
+	if len(report.Issues) == 0 {
+		fmt.Fprintln(w, "No issues found")
+		return nil
+	}
+
+	tw := newTabWriter(w)
+	fmt.Fprintln(tw, "KIND\tPLAN\tTASK\tREPAIRED\tDETAIL")
+	for _, issue := range report.Issues {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\n", issue.Kind, issue.PlanID, issue.TaskID, issue.Repaired, issue.Detail)
+	}
+	return tw.Flush()
+}
+
+// renderKanban writes the tasks of a plan as a text board with one column per status
+func renderKanban(w io.Writer, tasks []*models.Task) error {
+	columns := make(map[models.TaskStatus][]*models.Task, len(kanbanColumns))
+	rows := 0
+	for _, task := range tasks {
+		columns[task.Status] = append(columns[task.Status], task)
+		rows = max(rows, len(columns[task.Status]))
+	}
+
+	statuses := slices.Clone(kanbanColumns)
+	for _, task := range tasks {
+		if !slices.Contains(statuses, task.Status) {
+			statuses = slices.Insert(statuses, slices.Index(statuses, models.TaskStatusCompleted), task.Status)
+		}
+	}
+
+	tw := newTabWriter(w)
+
+	headers := make([]string, len(statuses))
+	for i, status := range statuses {
+		headers[i] = fmt.Sprintf("%s (%d)", strings.ToUpper(strings.ReplaceAll(string(status), "_", " ")), len(columns[status]))
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+	for row := range rows {
+		cells := make([]string, len(statuses))
+		for i, status := range statuses {
+			if row < len(columns[status]) {
+				cells[i] = kanbanCard(columns[status][row])
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+// kanbanCard formats a task as a card title, marking high priority tasks and truncating long titles
+func kanbanCard(task *models.Task) string {
+	title := task.Title
+	if task.Priority == models.TaskPriorityHigh {
+		title = "! " + title
+	}
+	return truncate(title, kanbanCardWidth)
+}
+
+// truncate shortens a string to at most width runes, ending it with an ellipsis when shortened
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
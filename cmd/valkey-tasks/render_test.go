@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+func newRenderTask(id, title string, status models.TaskStatus, priority models.TaskPriority) *models.Task {
+	task := models.NewTask(id, "plan-1", title, "", priority)
+	task.Status = status
+	return task
+}
+
+func TestRenderKanban(t *testing.T) {
+	tasks := []*models.Task{
+		newRenderTask("t1", "Design schema", models.TaskStatusCompleted, models.TaskPriorityMedium),
+		newRenderTask("t2", "Write migrations", models.TaskStatusInProgress, models.TaskPriorityHigh),
+		newRenderTask("t3", "Add API endpoints", models.TaskStatusPending, models.TaskPriorityMedium),
+		newRenderTask(
+			"t4", "Document the API for external consumers and partners", models.TaskStatusPending, models.TaskPriorityLow,
+		),
+	}
+
+	var out bytes.Buffer
+	if err := renderKanban(&out, tasks); err != nil {
+		t.Fatalf("renderKanban failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and 2 rows, got %d lines:\n%s", len(lines), out.String())
+	}
+
+	for _, header := range []string{"PENDING (2)", "IN PROGRESS (1)", "COMPLETED (1)", "CANCELLED (0)"} {
+		if !strings.Contains(lines[0], header) {
+			t.Errorf("header %q missing from %q", header, lines[0])
+		}
+	}
+
+	// Cards are placed under their status column
+	if strings.Index(lines[1], "Add API endpoints") > strings.Index(lines[1], "! Write migrations") {
+		t.Errorf("pending card should come before the in progress card: %q", lines[1])
+	}
+	if !strings.Contains(lines[1], "Design schema") {
+		t.Errorf("completed card missing from first row: %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "Document the API for external c…") {
+		t.Errorf("long card title should be truncated: %q", lines[2])
+	}
+}
+
+func TestRenderTaskTable(t *testing.T) {
+	tasks := []*models.Task{
+		newRenderTask("t1", "Design schema", models.TaskStatusCompleted, models.TaskPriorityMedium),
+	}
+
+	var out bytes.Buffer
+	if err := renderTaskTable(&out, tasks); err != nil {
+		t.Fatalf("renderTaskTable failed: %v", err)
+	}
+
+	want := "#  ID  STATUS     PRIORITY  TITLE\n0  t1  completed  medium    Design schema\n"
+	if out.String() != want {
+		t.Errorf("renderTaskTable output =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if err := renderTaskTable(&out, nil); err != nil {
+		t.Fatalf("renderTaskTable failed: %v", err)
+	}
+	if out.String() != "No tasks\n" {
+		t.Errorf("expected a placeholder for an empty plan, got %q", out.String())
+	}
+}
+
+func TestRenderIntegrityReport(t *testing.T) {
+	report := &storage.IntegrityReport{
+		PlansChecked: 2,
+		TasksChecked: 3,
+		Issues: []storage.IntegrityIssue{
+			{Kind: storage.IntegrityMissingTask, PlanID: "p1", TaskID: "t9", Detail: "not stored", Repaired: true},
+		},
+	}
+
+	var out bytes.Buffer
+	if err := renderIntegrityReport(&out, report); err != nil {
+		t.Fatalf("renderIntegrityReport failed: %v", err)
+	}
+
+	want := "Checked 2 plan(s) and 3 task(s)\n" +
+		"KIND          PLAN  TASK  REPAIRED  DETAIL\n" +
+		"missing_task  p1    t9    true      not stored\n"
+	if out.String() != want {
+		t.Errorf("renderIntegrityReport output =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	report.Issues = nil
+	if err := renderIntegrityReport(&out, report); err != nil {
+		t.Fatalf("renderIntegrityReport failed: %v", err)
+	}
+	if !strings.HasSuffix(out.String(), "No issues found\n") {
+		t.Errorf("expected a placeholder for a clean database, got %q", out.String())
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		input string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"this is too long", 10, "this is t…"},
+		{"ünïcödé títle", 6, "ünïcö…"},
+	}
+
+	for _, tt := range tests {
+		if got := truncate(tt.input, tt.width); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.input, tt.width, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// cliOptions holds the connection settings shared by all commands
+type cliOptions struct {
+	serverURL      string
+	direct         bool
+	valkeyHost     string
+	valkeyPort     int
+	valkeyUsername string
+	valkeyPassword string
+	valkeyCluster  bool
+	valkeyAddrs    string
+	sentinelAddrs  string
+	sentinelMaster string
+	sentinelPass   string
+	tls            bool
+	tlsCAFile      string
+	tlsCertFile    string
+	tlsKeyFile     string
+	tlsInsecure    bool
+	database       int
+}
+
+// newRootCommand creates the valkey-tasks command and its subcommands
+func newRootCommand() *cobra.Command {
+	opts := &cliOptions{}
+
+	valkeyPort, err := strconv.Atoi(getEnv("VALKEY_PORT", "6379"))
+	if err != nil {
+		valkeyPort = 6379
+	}
+	valkeyDB, err := strconv.Atoi(getEnv("VALKEY_DB", "0"))
+	if err != nil {
+		valkeyDB = 0
+	}
+
+	root := &cobra.Command{
+		Use:   "valkey-tasks",
+		Short: "Inspect and manage the plans and tasks your agents are working on",
+		Long: "valkey-tasks talks to the REST API of a running valkey-ai-tasks server (started with ENABLE_REST_API=true),\n" +
+			"or with --direct straight to the Valkey database behind it.",
+		SilenceUsage: true,
+	}
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&opts.serverURL, "server", getEnv("VALKEY_TASKS_SERVER", "http://localhost:8080"),
+		"URL of the valkey-ai-tasks server (env VALKEY_TASKS_SERVER)")
+	flags.BoolVar(&opts.direct, "direct", false, "Connect directly to Valkey instead of the server")
+	flags.StringVar(&opts.valkeyHost, "valkey-host", getEnv("VALKEY_HOST", "localhost"),
+		"Valkey host for --direct (env VALKEY_HOST)")
+	flags.IntVar(&opts.valkeyPort, "valkey-port", valkeyPort, "Valkey port for --direct (env VALKEY_PORT)")
+	flags.StringVar(&opts.valkeyUsername, "valkey-username", getEnv("VALKEY_USERNAME", ""),
+		"Valkey username for --direct (env VALKEY_USERNAME)")
+	flags.StringVar(&opts.valkeyPassword, "valkey-password", getEnv("VALKEY_PASSWORD", ""),
+		"Valkey password for --direct (env VALKEY_PASSWORD)")
+	flags.BoolVar(&opts.valkeyCluster, "valkey-cluster", strings.ToLower(getEnv("VALKEY_CLUSTER", "false")) == "true",
+		"Connect to a Valkey Cluster for --direct (env VALKEY_CLUSTER)")
+	flags.StringVar(&opts.valkeyAddrs, "valkey-addresses", getEnv("VALKEY_ADDRESSES", ""),
+		"Comma-separated host:port seed nodes for --direct, instead of the host and port (env VALKEY_ADDRESSES)")
+	flags.StringVar(&opts.sentinelAddrs, "valkey-sentinel-addresses", getEnv("VALKEY_SENTINEL_ADDRESSES", ""),
+		"Comma-separated host:port Sentinels to discover the primary for --direct (env VALKEY_SENTINEL_ADDRESSES)")
+	flags.StringVar(&opts.sentinelMaster, "valkey-sentinel-master", getEnv("VALKEY_SENTINEL_MASTER", "mymaster"),
+		"Name of the primary monitored by Sentinel (env VALKEY_SENTINEL_MASTER)")
+	flags.StringVar(&opts.sentinelPass, "valkey-sentinel-password", getEnv("VALKEY_SENTINEL_PASSWORD", ""),
+		"Sentinel password (env VALKEY_SENTINEL_PASSWORD)")
+	flags.BoolVar(&opts.tls, "valkey-tls", strings.ToLower(getEnv("VALKEY_TLS", "false")) == "true",
+		"Connect to Valkey over TLS for --direct (env VALKEY_TLS)")
+	flags.StringVar(&opts.tlsCAFile, "valkey-tls-ca-file", getEnv("VALKEY_TLS_CA_FILE", ""),
+		"PEM file of CA certificates trusted for Valkey (env VALKEY_TLS_CA_FILE)")
+	flags.StringVar(&opts.tlsCertFile, "valkey-tls-cert-file", getEnv("VALKEY_TLS_CERT_FILE", ""),
+		"Client certificate presented to Valkey (env VALKEY_TLS_CERT_FILE)")
+	flags.StringVar(&opts.tlsKeyFile, "valkey-tls-key-file", getEnv("VALKEY_TLS_KEY_FILE", ""),
+		"Key of the client certificate (env VALKEY_TLS_KEY_FILE)")
+	flags.BoolVar(&opts.tlsInsecure, "valkey-tls-insecure-skip-verify",
+		strings.ToLower(getEnv("VALKEY_TLS_INSECURE_SKIP_VERIFY", "false")) == "true",
+		"Accept any Valkey server certificate, for testing only (env VALKEY_TLS_INSECURE_SKIP_VERIFY)")
+	flags.IntVar(&opts.database, "valkey-db", valkeyDB, "Valkey database index for --direct (env VALKEY_DB)")
+
+	root.AddCommand(
+		newPlansCommand(opts),
+		newTasksCommand(opts),
+		newExportCommand(opts),
+		newImportCommand(opts),
+		newCheckCommand(opts),
+	)
+
+	return root
+}
+
+// connect creates a client for the configured server or Valkey database
+func (o *cliOptions) connect() (*apiClient, error) {
+	if o.direct {
+		return newDirectClient(o)
+	}
+	return newServerClient(o.serverURL), nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/api"
+)
+
+// newTasksCommand creates the commands for creating and updating tasks
+func newTasksCommand(opts *cliOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tasks",
+		Short: "Create and update tasks",
+	}
+
+	cmd.AddCommand(newTasksCreateCommand(opts), newTasksUpdateCommand(opts))
+	return cmd
+}
+
+func newTasksCreateCommand(opts *cliOptions) *cobra.Command {
+	var req api.TaskCreateRequest
+
+	cmd := &cobra.Command{
+		Use:   "create <plan-id>",
+		Short: "Add a task to the end of a plan",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.connect()
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			task, err := client.CreateTask(cmd.Context(), args[0], req)
+			if err != nil {
+				return fmt.Errorf("failed to create task: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Created task %s\n", task.ID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&req.Title, "title", "", "Task title")
+	cmd.Flags().StringVar(&req.Description, "description", "", "Task description")
+	cmd.Flags().StringVar(&req.Priority, "priority", "", "Task priority: low, medium or high (default medium)")
+	cmd.Flags().StringVar(&req.Status, "status", "",
+		"Task status: pending, in_progress, completed or cancelled (default pending)")
+	cmd.Flags().StringVar(&req.Notes, "notes", "", "Markdown notes")
+	cmd.MarkFlagRequired("title") //nolint:errcheck
+	return cmd
+}
+
+func newTasksUpdateCommand(opts *cliOptions) *cobra.Command {
+	var title, description, status, priority, notes, completionNote string
+
+	cmd := &cobra.Command{
+		Use:   "update <task-id>",
+		Short: "Update the fields of a task given as flags",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Only send the fields that were given on the command line
+			var req api.TaskUpdateRequest
+			flags := cmd.Flags()
+			if flags.Changed("title") {
+				req.Title = &title
+			}
+			if flags.Changed("description") {
+				req.Description = &description
+			}
+			if flags.Changed("status") {
+				req.Status = &status
+			}
+			if flags.Changed("priority") {
+				req.Priority = &priority
+			}
+			if flags.Changed("notes") {
+				req.Notes = &notes
+			}
+			if flags.Changed("completion-note") {
+				req.CompletionNote = &completionNote
+			}
+			if req == (api.TaskUpdateRequest{}) {
+				return fmt.Errorf("nothing to update: set at least one of --title, --description, --status, --priority, " +
+					"--notes or --completion-note")
+			}
+
+			client, err := opts.connect()
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			task, err := client.UpdateTask(cmd.Context(), args[0], req)
+			if err != nil {
+				return fmt.Errorf("failed to update task: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Updated task %s (%s, %s)\n", task.ID, task.Status, task.Priority)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&title, "title", "", "New task title")
+	cmd.Flags().StringVar(&description, "description", "", "New task description")
+	cmd.Flags().StringVar(&status, "status", "", "New task status: pending, in_progress, completed or cancelled")
+	cmd.Flags().StringVar(&priority, "priority", "", "New task priority: low, medium or high")
+	cmd.Flags().StringVar(&notes, "notes", "", "New Markdown notes")
+	cmd.Flags().StringVar(&completionNote, "completion-note", "", "Summary of what was done, given when completing the task")
+	return cmd
+}
@@ -0,0 +1,301 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// AdminBasePath is the path prefix of every admin API route
+const AdminBasePath = "/admin"
+
+// AdminConfig configures the admin API
+type AdminConfig struct {
+	// Token is the bearer token every request must carry. An empty token rejects every request.
+	Token string
+	// Integrity runs the integrity check and the reindex
+	Integrity *storage.IntegrityChecker
+	// Retention runs the retention policy, nil when no retention period is configured
+	Retention *services.RetentionJanitor
+	// BackupDir is the directory backups are written to
+	BackupDir string
+}
+
+// AdminHandler serves the admin API, which lets operators automate maintenance without an MCP client
+type AdminHandler struct {
+	planRepo storage.PlanRepositoryInterface
+	backup   *services.BackupService
+	config   AdminConfig
+	started  time.Time
+	mux      *http.ServeMux
+}
+
+// NewAdminHandler creates an admin API handler for the given repositories
+func NewAdminHandler(
+	planRepo storage.PlanRepositoryInterface,
+	taskRepo storage.TaskRepositoryInterface,
+	config AdminConfig,
+) *AdminHandler {
+	h := &AdminHandler{
+		planRepo: planRepo,
+		backup:   services.NewBackupService(planRepo, taskRepo),
+		config:   config,
+		started:  time.Now(),
+		mux:      http.NewServeMux(),
+	}
+
+	for _, rt := range h.routes() {
+		h.mux.HandleFunc(rt.Method+" "+AdminBasePath+rt.Path, rt.Handler)
+	}
+
+	return h
+}
+
+// ServeHTTP checks the bearer token and dispatches a request to the matching route, which may change locked plans
+func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, "a valid admin token is required")
+		return
+	}
+	h.mux.ServeHTTP(w, r.WithContext(storage.WithPlanLockOverride(r.Context())))
+}
+
+// authorized reports whether a request carries the admin token
+func (h *AdminHandler) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || h.config.Token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(h.config.Token)) == 1
+}
+
+// RetentionRun is the result of running the retention policy
+type RetentionRun struct {
+	// Expired lists the IDs of the plans archived or deleted by the run
+	Expired []string                `json:"expired"`
+	Stats   services.RetentionStats `json:"stats"`
+}
+
+// MetricsSnapshot describes the stored data and the server at one point in time
+type MetricsSnapshot struct {
+	TakenAt       time.Time `json:"taken_at"`
+	UptimeSeconds int64     `json:"uptime_seconds"`
+
+	Applications     int                       `json:"applications"`
+	Plans            int                       `json:"plans"`
+	PlanStatusCounts map[models.PlanStatus]int `json:"plan_status_counts"`
+	// Tasks counts the tasks of plans whose tasks are counted, which plans stored by old versions are not
+	// until their tasks change or the integrity check repairs them
+	Tasks            int                       `json:"tasks"`
+	TaskStatusCounts map[models.TaskStatus]int `json:"task_status_counts"`
+
+	// Retention is what the retention policy expired since the server started, nil without a policy
+	Retention *services.RetentionStats `json:"retention,omitempty"`
+	Runtime   RuntimeMetrics           `json:"runtime"`
+}
+
+// RuntimeMetrics describes the Go runtime of the server
+type RuntimeMetrics struct {
+	Goroutines     int    `json:"goroutines"`
+	HeapAllocBytes uint64 `json:"heap_alloc_bytes"`
+	SysBytes       uint64 `json:"sys_bytes"`
+	NumGC          uint32 `json:"num_gc"`
+}
+
+// routes returns the admin API endpoints served by the handler
+func (h *AdminHandler) routes() []route {
+	return []route{
+		{
+			Method:      http.MethodPost,
+			Path:        "/integrity",
+			OperationID: "checkIntegrity",
+			Summary:     "Check plans and tasks for inconsistencies, optionally repairing them",
+			Query: []queryParam{
+				{Name: "repair", Description: "Repair the issues found", Enum: []string{"true", "false"}},
+			},
+			Response: &storage.IntegrityReport{},
+			Status:   http.StatusOK,
+			Handler:  h.checkIntegrity,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/reindex",
+			OperationID: "reindex",
+			Summary:     "Rebuild the application plan sets, application statuses and tag index",
+			Response:    &storage.ReindexReport{},
+			Status:      http.StatusOK,
+			Handler:     h.reindex,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/backups",
+			OperationID: "backupPlans",
+			Summary:     "Write a backup of every plan to the backup directory of the server",
+			Response:    &services.BackupRun{},
+			Status:      http.StatusOK,
+			Handler:     h.backupPlans,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/retention/run",
+			OperationID: "runRetention",
+			Summary:     "Archive or delete the plans past the retention period now",
+			Response:    &RetentionRun{},
+			Status:      http.StatusOK,
+			Handler:     h.runRetention,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/metrics",
+			OperationID: "getMetrics",
+			Summary:     "Get a snapshot of plan and task counts, retention stats and runtime metrics",
+			Response:    &MetricsSnapshot{},
+			Status:      http.StatusOK,
+			Handler:     h.getMetrics,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/openapi.json",
+			OperationID: "getAdminOpenAPISpec",
+			Summary:     "Get the OpenAPI specification of the admin API",
+			Response:    map[string]any{},
+			Status:      http.StatusOK,
+			Handler:     h.getOpenAPISpec,
+		},
+	}
+}
+
+func (h *AdminHandler) checkIntegrity(w http.ResponseWriter, r *http.Request) {
+	repair := false
+	if value := r.URL.Query().Get("repair"); value != "" {
+		var err error
+		if repair, err = strconv.ParseBool(value); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid repair: "+value)
+			return
+		}
+	}
+
+	report, err := h.config.Integrity.Check(r.Context(), repair)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func (h *AdminHandler) reindex(w http.ResponseWriter, r *http.Request) {
+	report, err := h.config.Integrity.Reindex(r.Context())
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func (h *AdminHandler) backupPlans(w http.ResponseWriter, r *http.Request) {
+	run, err := h.backup.BackupAll(r.Context(), h.config.BackupDir)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, run)
+}
+
+func (h *AdminHandler) runRetention(w http.ResponseWriter, r *http.Request) {
+	if h.config.Retention == nil {
+		writeError(w, http.StatusNotFound, "plan retention is not enabled")
+		return
+	}
+
+	expired, err := h.config.Retention.Run(r.Context())
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	if expired == nil {
+		expired = []string{}
+	}
+	writeJSON(w, http.StatusOK, RetentionRun{Expired: expired, Stats: h.config.Retention.Stats()})
+}
+
+func (h *AdminHandler) getMetrics(w http.ResponseWriter, r *http.Request) {
+	plans, err := h.planRepo.List(r.Context())
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	now := time.Now()
+	snapshot := MetricsSnapshot{
+		TakenAt:          now,
+		UptimeSeconds:    int64(now.Sub(h.started).Seconds()),
+		Plans:            len(plans),
+		PlanStatusCounts: map[models.PlanStatus]int{},
+		TaskStatusCounts: models.NewTaskStatusCounts(),
+	}
+	for _, status := range planStatusValues {
+		snapshot.PlanStatusCounts[models.PlanStatus(status)] = 0
+	}
+
+	applications := make(map[string]bool)
+	for _, plan := range plans {
+		applications[plan.ApplicationID] = true
+		snapshot.PlanStatusCounts[plan.Status]++
+		if plan.TaskCounts == nil {
+			continue
+		}
+		snapshot.Tasks += plan.TaskCounts.Total
+		for status := range snapshot.TaskStatusCounts {
+			snapshot.TaskStatusCounts[status] += plan.TaskCounts.Count(status)
+		}
+	}
+	snapshot.Applications = len(applications)
+
+	if h.config.Retention != nil {
+		stats := h.config.Retention.Stats()
+		snapshot.Retention = &stats
+	}
+
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	snapshot.Runtime = RuntimeMetrics{
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAllocBytes: memStats.HeapAlloc,
+		SysBytes:       memStats.Sys,
+		NumGC:          memStats.NumGC,
+	}
+
+	writeJSON(w, http.StatusOK, snapshot)
+}
+
+// The admin specification is cached like the one of the REST API
+var (
+	adminOpenAPISpecOnce sync.Once
+	adminOpenAPISpec     map[string]any
+)
+
+// AdminOpenAPISpec returns the OpenAPI specification of the admin API, generated from its route table
+func AdminOpenAPISpec() map[string]any {
+	adminOpenAPISpecOnce.Do(func() {
+		adminOpenAPISpec = buildOpenAPISpec(specInfo{
+			Title:       "Valkey AI Tasks Admin API",
+			Description: "Maintenance operations of the server for operators, protected by the admin token",
+			BasePath:    AdminBasePath,
+			BearerAuth:  true,
+		}, (&AdminHandler{}).routes())
+	})
+	return adminOpenAPISpec
+}
+
+func (h *AdminHandler) getOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, AdminOpenAPISpec())
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+func TestAdminHandlerRequiresToken(t *testing.T) {
+	handler := NewAdminHandler(nil, nil, AdminConfig{Token: "secret"})
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"wrong token", "Bearer other", http.StatusUnauthorized},
+		{"not a bearer token", "Basic secret", http.StatusUnauthorized},
+		{"valid token", "Bearer secret", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, AdminBasePath+"/openapi.json", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (body: %s)", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+
+	// An admin API without a token accepts no request
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, AdminBasePath+"/openapi.json", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	NewAdminHandler(nil, nil, AdminConfig{}).ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAdminHandlerOperations(t *testing.T) {
+	valkeyClient, err := storage.NewMemoryClient(storage.MemoryConfig{})
+	if err != nil {
+		t.Fatalf("failed to create in-memory storage: %v", err)
+	}
+	t.Cleanup(func() { valkeyClient.Close() })
+
+	ctx := context.Background()
+	planRepo := storage.NewPlanRepository(valkeyClient)
+	taskRepo := storage.NewTaskRepository(valkeyClient)
+	plan, err := planRepo.Create(ctx, "app", "Release", "Ship it")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	task, err := taskRepo.Create(ctx, plan.ID, "Tag release", "", models.TaskPriorityHigh)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := taskRepo.AddTags(ctx, task.ID, []string{"release"}); err != nil {
+		t.Fatalf("failed to tag task: %v", err)
+	}
+
+	backupDir := t.TempDir()
+	handler := NewAdminHandler(planRepo, taskRepo, AdminConfig{
+		Token:     "secret",
+		Integrity: storage.NewIntegrityChecker(valkeyClient),
+		BackupDir: backupDir,
+	})
+	call := func(method, path string, v any) int {
+		t.Helper()
+		req := httptest.NewRequest(method, AdminBasePath+path, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if v != nil && rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+				t.Fatalf("%s %s returned invalid JSON: %v", method, path, err)
+			}
+		}
+		return rec.Code
+	}
+
+	var integrity storage.IntegrityReport
+	if status := call(http.MethodPost, "/integrity?repair=true", &integrity); status != http.StatusOK {
+		t.Fatalf("integrity check status = %d", status)
+	}
+	if integrity.PlansChecked != 1 || integrity.TasksChecked != 1 || !integrity.Repair || len(integrity.Issues) != 0 {
+		t.Errorf("unexpected integrity report %+v", integrity)
+	}
+	if status := call(http.MethodPost, "/integrity?repair=maybe", nil); status != http.StatusBadRequest {
+		t.Errorf("invalid repair status = %d, want %d", status, http.StatusBadRequest)
+	}
+
+	var reindex storage.ReindexReport
+	if status := call(http.MethodPost, "/reindex", &reindex); status != http.StatusOK {
+		t.Fatalf("reindex status = %d", status)
+	}
+	want := storage.ReindexReport{PlansIndexed: 1, ApplicationsIndexed: 1, TagsIndexed: 1}
+	if reindex != want {
+		t.Errorf("reindex report = %+v, want %+v", reindex, want)
+	}
+
+	var backup services.BackupRun
+	if status := call(http.MethodPost, "/backups", &backup); status != http.StatusOK {
+		t.Fatalf("backup status = %d", status)
+	}
+	if len(backup.Files) != 1 || backup.Tasks != 1 || len(backup.Failed) != 0 {
+		t.Fatalf("unexpected backup run %+v", backup)
+	}
+	data, err := os.ReadFile(filepath.Join(backupDir, plan.ID+".json"))
+	if err != nil || !strings.Contains(string(data), "Tag release") {
+		t.Errorf("backup file does not hold the plan: %v", err)
+	}
+
+	if status := call(http.MethodPost, "/retention/run", nil); status != http.StatusNotFound {
+		t.Errorf("retention status without a policy = %d, want %d", status, http.StatusNotFound)
+	}
+
+	var metrics MetricsSnapshot
+	if status := call(http.MethodGet, "/metrics", &metrics); status != http.StatusOK {
+		t.Fatalf("metrics status = %d", status)
+	}
+	if metrics.Applications != 1 || metrics.Plans != 1 || metrics.Tasks != 1 ||
+		metrics.TaskStatusCounts[models.TaskStatusPending] != 1 || metrics.Runtime.Goroutines == 0 {
+		t.Errorf("unexpected metrics %+v", metrics)
+	}
+	if metrics.Retention != nil {
+		t.Errorf("metrics should leave out retention without a policy")
+	}
+}
+
+func TestAdminOpenAPISpec(t *testing.T) {
+	spec := AdminOpenAPISpec()
+
+	paths := spec["paths"].(map[string]any)
+	for _, rt := range (&AdminHandler{}).routes() {
+		item, ok := paths[AdminBasePath+rt.Path].(map[string]any)
+		if !ok {
+			t.Errorf("path %s is missing from the spec", rt.Path)
+			continue
+		}
+		if _, ok := item[strings.ToLower(rt.Method)]; !ok {
+			t.Errorf("operation %s %s is missing from the spec", rt.Method, rt.Path)
+		}
+	}
+
+	if _, ok := spec["security"]; !ok {
+		t.Errorf("admin spec should require the bearer token")
+	}
+	if _, ok := OpenAPISpec()["security"]; ok {
+		t.Errorf("REST API spec should not require a bearer token")
+	}
+	if _, err := json.Marshal(spec); err != nil {
+		t.Fatalf("spec is not serializable: %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+// Package api exposes plans and tasks through a plain REST API backed by the same repositories as the MCP tools,
+// so dashboards, CI jobs and scripts can work with plans without speaking MCP.
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// BasePath is the path prefix of every REST API route
+const BasePath = "/api/v1"
+
+// maxRequestBodySize limits the size of JSON request bodies, leaving room for plan backups with long notes
+const maxRequestBodySize = 10 << 20
+
+// Handler serves the REST API
+type Handler struct {
+	planRepo storage.PlanRepositoryInterface
+	taskRepo storage.TaskRepositoryInterface
+	backup   *services.BackupService
+	mux      *http.ServeMux
+	readOnly atomic.Bool
+	// authorize decides whether a request may call a route, given the MCP tool doing the same
+	authorize func(r *http.Request, tool string) error
+}
+
+// NewHandler creates a REST API handler for the given repositories
+func NewHandler(planRepo storage.PlanRepositoryInterface, taskRepo storage.TaskRepositoryInterface) *Handler {
+	h := &Handler{
+		planRepo: planRepo,
+		taskRepo: taskRepo,
+		backup:   services.NewBackupService(planRepo, taskRepo),
+		mux:      http.NewServeMux(),
+	}
+
+	for _, rt := range h.routes() {
+		h.mux.HandleFunc(rt.Method+" "+BasePath+rt.Path, h.authorized(rt))
+	}
+
+	return h
+}
+
+// SetAuthorizer makes the handler reject requests for which authorize returns an error, given the MCP tool of
+// their route. It must be called before the handler serves requests.
+func (h *Handler) SetAuthorizer(authorize func(r *http.Request, tool string) error) {
+	h.authorize = authorize
+}
+
+// authorized wraps the handler of a route to check the request with the authorizer first
+func (h *Handler) authorized(rt route) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.authorize != nil && rt.Tool != "" {
+			if err := h.authorize(r, rt.Tool); err != nil {
+				writeRepositoryError(w, err)
+				return
+			}
+		}
+		rt.Handler(w, r)
+	}
+}
+
+// SetReadOnly makes the handler reject every request that could change data. It may be called while the
+// handler serves requests.
+func (h *Handler) SetReadOnly(readOnly bool) {
+	h.readOnly.Store(readOnly)
+}
+
+// ServeHTTP dispatches a request to the matching route
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.readOnly.Load() && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, "the API is read-only")
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// ErrorResponse is the body returned for failed requests
+type ErrorResponse struct {
+	Error string `json:"error"`
+	// Code classifies errors of the storage, such as NOT_FOUND or VALIDATION
+	Code models.ErrorCode `json:"code,omitempty"`
+}
+
+// writeJSON writes a value as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to write API response: %v", err)
+	}
+}
+
+// writeError writes an error response with the given status code
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, ErrorResponse{Error: message})
+}
+
+// writeRepositoryError maps a repository error to a status code and writes it with its error code
+func writeRepositoryError(w http.ResponseWriter, err error) {
+	code := models.AsError(err, models.ErrorCodeStorage).Code
+
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, storage.ErrLimitExceeded):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, storage.ErrOutOfScope), code == models.ErrorCodeForbidden:
+		status = http.StatusForbidden
+	case code == models.ErrorCodeNotFound:
+		status = http.StatusNotFound
+	case code == models.ErrorCodeValidation:
+		status = http.StatusBadRequest
+	case code == models.ErrorCodeConflict:
+		status = http.StatusConflict
+	case code == models.ErrorCodeRateLimited:
+		status = http.StatusTooManyRequests
+	}
+	writeJSON(w, status, ErrorResponse{Error: err.Error(), Code: code})
+}
+
+// decodeJSON decodes a JSON request body, rejecting unknown fields and trailing data
+func decodeJSON(r *http.Request, v any) error {
+	decoder := json.NewDecoder(io.LimitReader(r.Body, maxRequestBodySize))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("request body is required")
+		}
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+
+	if decoder.More() {
+		return fmt.Errorf("invalid request body: unexpected data after JSON object")
+	}
+
+	return nil
+}
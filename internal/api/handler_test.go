@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestHandlerRejectsInvalidRequests covers requests that fail validation before reaching the repositories
+func TestHandlerRejectsInvalidRequests(t *testing.T) {
+	handler := NewHandler(nil, nil)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"missing body", http.MethodPost, "/plans", "", http.StatusBadRequest},
+		{"malformed body", http.MethodPost, "/plans", "{", http.StatusBadRequest},
+		{"unknown field", http.MethodPost, "/plans", `{"name": "n", "application_id": "a", "owner": "x"}`, http.StatusBadRequest},
+		{"trailing data", http.MethodPost, "/plans", `{"name": "n", "application_id": "a"} {}`, http.StatusBadRequest},
+		{"missing plan name", http.MethodPost, "/plans", `{"application_id": "a"}`, http.StatusBadRequest},
+		{"invalid plan status filter", http.MethodGet, "/plans?status=done", "", http.StatusBadRequest},
+		{"missing task title", http.MethodPost, "/plans/p1/tasks", `{"description": "d"}`, http.StatusBadRequest},
+		{"invalid task priority", http.MethodPost, "/plans/p1/tasks", `{"title": "t", "priority": "urgent"}`, http.StatusBadRequest},
+		{"empty bulk", http.MethodPost, "/plans/p1/tasks/bulk", `{"tasks": []}`, http.StatusBadRequest},
+		{"bulk task without title", http.MethodPost, "/plans/p1/tasks/bulk", `{"tasks": [{}]}`, http.StatusBadRequest},
+		{"invalid task status filter", http.MethodGet, "/plans/p1/tasks?status=done", "", http.StatusBadRequest},
+		{"unknown route", http.MethodGet, "/projects", "", http.StatusNotFound},
+		{"unsupported method", http.MethodPut, "/plans/p1", "{}", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, BasePath+tt.path, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (body: %s)", rec.Code, tt.status, rec.Body.String())
+			}
+
+			if tt.status == http.StatusBadRequest {
+				var resp ErrorResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Error == "" {
+					t.Errorf("expected an error response, got %s", rec.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestReadOnlyHandler(t *testing.T) {
+	handler := NewHandler(nil, nil)
+	handler.SetReadOnly(true)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, BasePath+"/plans", strings.NewReader(`{"name": "n"}`)))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, BasePath+"/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestOpenAPISpecEndpoint(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHandler(nil, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, BasePath+"/openapi.json", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var spec map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("response is not JSON: %v", err)
+	}
+	if spec["openapi"] != openAPIVersion {
+		t.Errorf("openapi = %v, want %s", spec["openapi"], openAPIVersion)
+	}
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/utils/jsonschema"
+)
+
+// openAPIVersion is the OpenAPI version of the generated specification
+const openAPIVersion = "3.0.3"
+
+// pathParamPattern matches path parameters such as {id} in a route path
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+// enumValues lists the allowed values of the string types used in API schemas, including the task statuses
+// and priorities configured for the deployment
+func enumValues() map[reflect.Type][]string {
+	return map[reflect.Type][]string{
+		reflect.TypeOf(models.PlanStatus("")):   planStatusValues,
+		reflect.TypeOf(models.TaskStatus("")):   models.TaskStatusNames(),
+		reflect.TypeOf(models.TaskPriority("")): models.TaskPriorityNames(),
+		reflect.TypeOf(models.ErrorCode("")):    errorCodeValues,
+	}
+}
+
+// The generated specification never changes at runtime, so it is built once and cached
+var (
+	openAPISpecOnce sync.Once
+	openAPISpec     map[string]any
+)
+
+// OpenAPISpec returns the OpenAPI specification of the REST API.
+// It is generated from the route table and the Go types of the request and response bodies,
+// so it cannot drift from what the handler actually serves.
+func OpenAPISpec() map[string]any {
+	openAPISpecOnce.Do(func() {
+		openAPISpec = buildOpenAPISpec(specInfo{
+			Title:       "Valkey AI Tasks REST API",
+			Description: "Plain HTTP access to the plans and tasks managed through the MCP server",
+			BasePath:    BasePath,
+		}, (&Handler{}).routes())
+	})
+	return openAPISpec
+}
+
+func (h *Handler) getOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, OpenAPISpec())
+}
+
+// specInfo describes the API a specification is generated for
+type specInfo struct {
+	Title       string
+	Description string
+	BasePath    string
+	// BearerAuth marks every operation as requiring a bearer token
+	BearerAuth bool
+}
+
+// buildOpenAPISpec generates the specification from a route table
+func buildOpenAPISpec(info specInfo, routes []route) map[string]any {
+	generator := jsonschema.NewGenerator("#/components/schemas/", enumValues())
+	errorSchema := generator.Schema(reflect.TypeOf(ErrorResponse{}))
+
+	paths := map[string]any{}
+	for _, rt := range routes {
+		path := info.BasePath + rt.Path
+		item, ok := paths[path].(map[string]any)
+		if !ok {
+			item = map[string]any{}
+			paths[path] = item
+		}
+
+		parameters := []any{}
+		for _, match := range pathParamPattern.FindAllStringSubmatch(rt.Path, -1) {
+			parameters = append(parameters, map[string]any{
+				"name":     match[1],
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]any{"type": "string"},
+			})
+		}
+		for _, param := range rt.Query {
+			schema := map[string]any{"type": "string"}
+			if len(param.Enum) > 0 {
+				schema["enum"] = param.Enum
+			}
+			parameters = append(parameters, map[string]any{
+				"name":        param.Name,
+				"in":          "query",
+				"required":    false,
+				"description": param.Description,
+				"schema":      schema,
+			})
+		}
+
+		success := map[string]any{"description": http.StatusText(rt.Status)}
+		if rt.Response != nil {
+			success["content"] = jsonContent(generator.Schema(reflect.TypeOf(rt.Response)))
+		}
+
+		operation := map[string]any{
+			"operationId": rt.OperationID,
+			"summary":     rt.Summary,
+			"responses": map[string]any{
+				strconv.Itoa(rt.Status): success,
+				"default": map[string]any{
+					"description": "Error",
+					"content":     jsonContent(errorSchema),
+				},
+			},
+		}
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+		if rt.Request != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content":  jsonContent(generator.Schema(reflect.TypeOf(rt.Request))),
+			}
+		}
+
+		item[strings.ToLower(rt.Method)] = operation
+	}
+
+	components := map[string]any{
+		"schemas": generator.Definitions(),
+	}
+	spec := map[string]any{
+		"openapi": openAPIVersion,
+		"info": map[string]any{
+			"title":       info.Title,
+			"description": info.Description,
+			"version":     "1.0.0",
+		},
+		"servers":    []any{map[string]any{"url": info.BasePath}},
+		"paths":      paths,
+		"components": components,
+	}
+	if info.BearerAuth {
+		components["securitySchemes"] = map[string]any{
+			"bearerAuth": map[string]any{"type": "http", "scheme": "bearer"},
+		}
+		spec["security"] = []any{map[string]any{"bearerAuth": []string{}}}
+	}
+	return spec
+}
+
+// jsonContent wraps a schema in an application/json content map
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{
+		"application/json": map[string]any{"schema": schema},
+	}
+}
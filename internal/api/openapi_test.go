@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+func TestOpenAPISpecCoversRoutes(t *testing.T) {
+	spec := OpenAPISpec()
+
+	paths, ok := spec["paths"].(map[string]any)
+	if !ok {
+		t.Fatalf("spec has no paths")
+	}
+
+	for _, rt := range (&Handler{}).routes() {
+		item, ok := paths[BasePath+rt.Path].(map[string]any)
+		if !ok {
+			t.Errorf("path %s is missing from the spec", rt.Path)
+			continue
+		}
+		operation, ok := item[strings.ToLower(rt.Method)].(map[string]any)
+		if !ok {
+			t.Errorf("operation %s %s is missing from the spec", rt.Method, rt.Path)
+			continue
+		}
+		if operation["operationId"] != rt.OperationID {
+			t.Errorf("operation %s %s has operationId %v, want %s", rt.Method, rt.Path, operation["operationId"], rt.OperationID)
+		}
+	}
+
+	if _, err := json.Marshal(spec); err != nil {
+		t.Fatalf("spec is not serializable: %v", err)
+	}
+}
+
+func TestOpenAPISpecSchemas(t *testing.T) {
+	schemas := OpenAPISpec()["components"].(map[string]any)["schemas"].(map[string]any)
+
+	tests := []struct {
+		schema   string
+		required []string
+		optional []string
+		hidden   []string
+	}{
+		{"Plan", []string{"id", "application_id", "name", "status", "created_at"}, []string{"metadata"}, nil},
+		{
+			"Task",
+			[]string{"id", "plan_id", "title", "status", "priority", "order", "time_spent"},
+			[]string{"due_date", "tags"},
+			[]string{"in_progress_since"},
+		},
+		{"PlanCreateRequest", []string{"application_id", "name"}, []string{"description", "notes"}, nil},
+		{"TaskUpdateRequest", nil, []string{"title", "status", "priority"}, nil},
+		{"ChecklistItem", []string{"id", "text", "done"}, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			schema, ok := schemas[tt.schema].(map[string]any)
+			if !ok {
+				t.Fatalf("schema %s is missing", tt.schema)
+			}
+			properties := schema["properties"].(map[string]any)
+			required, _ := schema["required"].([]string)
+
+			for _, name := range tt.required {
+				if _, ok := properties[name]; !ok || !slices.Contains(required, name) {
+					t.Errorf("%s.%s should be a required property", tt.schema, name)
+				}
+			}
+			for _, name := range tt.optional {
+				if _, ok := properties[name]; !ok || slices.Contains(required, name) {
+					t.Errorf("%s.%s should be an optional property", tt.schema, name)
+				}
+			}
+			for _, name := range tt.hidden {
+				if _, ok := properties[name]; ok {
+					t.Errorf("%s.%s should not be exposed", tt.schema, name)
+				}
+			}
+		})
+	}
+
+	status := schemas["Task"].(map[string]any)["properties"].(map[string]any)["status"].(map[string]any)
+	if !slices.Equal(status["enum"].([]string), models.TaskStatusNames()) {
+		t.Errorf("Task.status enum = %v, want %v", status["enum"], models.TaskStatusNames())
+	}
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/utils/markdown"
+)
+
+// PlanCreateRequest is the body of a plan creation request
+type PlanCreateRequest struct {
+	ApplicationID string `json:"application_id"`
+	Name          string `json:"name"`
+	Description   string `json:"description,omitempty"`
+	Notes         string `json:"notes,omitempty"`
+}
+
+// PlanUpdateRequest is the body of a plan update request. Omitted fields are left unchanged.
+type PlanUpdateRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Notes       *string `json:"notes,omitempty"`
+}
+
+func (h *Handler) listPlans(w http.ResponseWriter, r *http.Request) {
+	applicationID := r.URL.Query().Get("application_id")
+	status := r.URL.Query().Get("status")
+	if status != "" && !slices.Contains(planStatusValues, status) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid status: %s", status))
+		return
+	}
+
+	var plans []*models.Plan
+	var err error
+	switch {
+	case applicationID != "":
+		plans, err = h.planRepo.ListByApplication(r.Context(), applicationID)
+	case status != "":
+		plans, err = h.planRepo.ListByStatus(r.Context(), models.PlanStatus(status))
+	default:
+		plans, err = h.planRepo.List(r.Context())
+	}
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	// Apply the status filter on top of the application filter
+	if applicationID != "" && status != "" {
+		plans = slices.DeleteFunc(plans, func(plan *models.Plan) bool {
+			return plan.Status != models.PlanStatus(status)
+		})
+	}
+
+	if plans == nil {
+		plans = []*models.Plan{}
+	}
+	writeJSON(w, http.StatusOK, plans)
+}
+
+func (h *Handler) createPlan(w http.ResponseWriter, r *http.Request) {
+	var req PlanCreateRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.ApplicationID == "" || req.Name == "" {
+		writeError(w, http.StatusBadRequest, "application_id and name are required")
+		return
+	}
+
+	description := req.Description
+	if description == "" {
+		description = "no description provided"
+	}
+
+	notes, err := prepareNotes(req.Notes)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	plan, err := h.planRepo.Create(r.Context(), req.ApplicationID, req.Name, description)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	if notes != "" {
+		if err := h.planRepo.UpdateNotes(r.Context(), plan.ID, notes); err != nil {
+			writeRepositoryError(w, err)
+			return
+		}
+		plan.Notes = notes
+	}
+
+	writeJSON(w, http.StatusCreated, plan)
+}
+
+func (h *Handler) getPlan(w http.ResponseWriter, r *http.Request) {
+	plan, err := h.planRepo.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, plan)
+}
+
+func (h *Handler) updatePlan(w http.ResponseWriter, r *http.Request) {
+	var req PlanUpdateRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	plan, err := h.planRepo.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	if req.Name != nil {
+		if *req.Name == "" {
+			writeError(w, http.StatusBadRequest, "name cannot be empty")
+			return
+		}
+		plan.Name = *req.Name
+	}
+	if req.Description != nil {
+		plan.Description = *req.Description
+	}
+
+	if req.Notes != nil {
+		notes, err := prepareNotes(*req.Notes)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := h.planRepo.UpdateNotes(r.Context(), plan.ID, notes); err != nil {
+			writeRepositoryError(w, err)
+			return
+		}
+		plan.Notes = notes
+	}
+
+	if err := h.planRepo.Update(r.Context(), plan); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, plan)
+}
+
+func (h *Handler) deletePlan(w http.ResponseWriter, r *http.Request) {
+	if err := h.planRepo.Delete(r.Context(), r.PathValue("id")); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) exportPlan(w http.ResponseWriter, r *http.Request) {
+	backup, err := h.backup.ExportPlan(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, backup)
+}
+
+func (h *Handler) importPlan(w http.ResponseWriter, r *http.Request) {
+	var backup models.PlanResource
+	if err := decodeJSON(r, &backup); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := services.ValidatePlanBackup(&backup); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	restored, err := h.backup.ImportPlan(r.Context(), &backup)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, restored)
+}
+
+// prepareNotes validates, sanitizes and formats Markdown notes the same way the MCP tools do
+func prepareNotes(notes string) (string, error) {
+	if notes == "" {
+		return "", nil
+	}
+
+	if err := markdown.Validate(notes); err != nil {
+		return "", fmt.Errorf("invalid notes format: %w", err)
+	}
+
+	return markdown.Format(markdown.Sanitize(notes)), nil
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// route describes a REST API endpoint. The same table drives request routing and the OpenAPI spec.
+type route struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Query       []queryParam
+	// Request is a zero value of the request body type, or nil if the route takes no body
+	Request any
+	// Response is a zero value of the success response type, or nil if the route returns no body
+	Response any
+	Status   int
+	// Tool is the MCP tool doing the same, whose group decides which roles may call the route. Routes without
+	// one are open to every role.
+	Tool    string
+	Handler http.HandlerFunc
+}
+
+// queryParam describes an optional query string parameter
+type queryParam struct {
+	Name        string
+	Description string
+	Enum        []string
+}
+
+var (
+	planStatusValues = []string{
+		string(models.PlanStatusNew),
+		string(models.PlanStatusInProgress),
+		string(models.PlanStatusCompleted),
+		string(models.PlanStatusCancelled),
+	}
+	errorCodeValues = []string{
+		string(models.ErrorCodeNotFound),
+		string(models.ErrorCodeValidation),
+		string(models.ErrorCodeConflict),
+		string(models.ErrorCodeStorage),
+		string(models.ErrorCodeRateLimited),
+		string(models.ErrorCodeCancelled),
+		string(models.ErrorCodeForbidden),
+	}
+)
+
+// routes returns the REST API endpoints served by the handler
+func (h *Handler) routes() []route {
+	return []route{
+		{
+			Method:      http.MethodGet,
+			Path:        "/plans",
+			OperationID: "listPlans",
+			Summary:     "List plans, optionally filtered by application and status",
+			Query: []queryParam{
+				{Name: "application_id", Description: "Only return plans of this application"},
+				{Name: "status", Description: "Only return plans with this status", Enum: planStatusValues},
+			},
+			Response: []*models.Plan{},
+			Status:   http.StatusOK,
+			Tool:     "list_plans",
+			Handler:  h.listPlans,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/plans",
+			OperationID: "createPlan",
+			Summary:     "Create a plan",
+			Request:     PlanCreateRequest{},
+			Response:    &models.Plan{},
+			Status:      http.StatusCreated,
+			Tool:        "create_plan",
+			Handler:     h.createPlan,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/plans/{id}",
+			OperationID: "getPlan",
+			Summary:     "Get a plan",
+			Response:    &models.Plan{},
+			Status:      http.StatusOK,
+			Tool:        "get_plan",
+			Handler:     h.getPlan,
+		},
+		{
+			Method:      http.MethodPatch,
+			Path:        "/plans/{id}",
+			OperationID: "updatePlan",
+			Summary:     "Update the name, description or notes of a plan",
+			Request:     PlanUpdateRequest{},
+			Response:    &models.Plan{},
+			Status:      http.StatusOK,
+			Tool:        "update_plan",
+			Handler:     h.updatePlan,
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/plans/{id}",
+			OperationID: "deletePlan",
+			Summary:     "Delete a plan and its tasks",
+			Status:      http.StatusNoContent,
+			Tool:        "delete_plan",
+			Handler:     h.deletePlan,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/plans/{id}/export",
+			OperationID: "exportPlan",
+			Summary:     "Export a plan and its tasks as a backup",
+			Response:    &models.PlanResource{},
+			Status:      http.StatusOK,
+			Tool:        "get_plan_full",
+			Handler:     h.exportPlan,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/plans/import",
+			OperationID: "importPlan",
+			Summary:     "Restore a plan and its tasks from a backup, keeping their original IDs",
+			Request:     models.PlanResource{},
+			Response:    &models.PlanResource{},
+			Status:      http.StatusOK,
+			Tool:        "import_plan_from_markdown",
+			Handler:     h.importPlan,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/plans/{id}/tasks",
+			OperationID: "listPlanTasks",
+			Summary:     "List the tasks of a plan in order, optionally filtered by status",
+			Query: []queryParam{
+				{Name: "status", Description: "Only return tasks with this status", Enum: models.TaskStatusNames()},
+			},
+			Response: []*models.Task{},
+			Status:   http.StatusOK,
+			Tool:     "list_tasks_by_plan",
+			Handler:  h.listPlanTasks,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/plans/{id}/tasks",
+			OperationID: "createTask",
+			Summary:     "Add a task to the end of a plan",
+			Request:     TaskCreateRequest{},
+			Response:    &models.Task{},
+			Status:      http.StatusCreated,
+			Tool:        "create_task",
+			Handler:     h.createTask,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/plans/{id}/tasks/bulk",
+			OperationID: "bulkCreateTasks",
+			Summary:     "Add several tasks to the end of a plan in one operation",
+			Request:     TaskBulkCreateRequest{},
+			Response:    []*models.Task{},
+			Status:      http.StatusCreated,
+			Tool:        "bulk_create_tasks",
+			Handler:     h.bulkCreateTasks,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/tasks/{id}",
+			OperationID: "getTask",
+			Summary:     "Get a task",
+			Response:    &models.Task{},
+			Status:      http.StatusOK,
+			Tool:        "get_task",
+			Handler:     h.getTask,
+		},
+		{
+			Method:      http.MethodPatch,
+			Path:        "/tasks/{id}",
+			OperationID: "updateTask",
+			Summary:     "Update the title, description, status, priority or notes of a task",
+			Request:     TaskUpdateRequest{},
+			Response:    &models.Task{},
+			Status:      http.StatusOK,
+			Tool:        "update_task",
+			Handler:     h.updateTask,
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/tasks/{id}",
+			OperationID: "deleteTask",
+			Summary:     "Delete a task",
+			Status:      http.StatusNoContent,
+			Tool:        "delete_task",
+			Handler:     h.deleteTask,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/openapi.json",
+			OperationID: "getOpenAPISpec",
+			Summary:     "Get the OpenAPI specification of this API",
+			Response:    map[string]any{},
+			Status:      http.StatusOK,
+			Handler:     h.getOpenAPISpec,
+		},
+	}
+}
@@ -0,0 +1,261 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// TaskCreateRequest is the body of a task creation request
+type TaskCreateRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Status      string `json:"status,omitempty"`
+	Priority    string `json:"priority,omitempty"`
+	Notes       string `json:"notes,omitempty"`
+}
+
+// TaskUpdateRequest is the body of a task update request. Omitted fields are left unchanged.
+type TaskUpdateRequest struct {
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Status      *string `json:"status,omitempty"`
+	Priority    *string `json:"priority,omitempty"`
+	Notes       *string `json:"notes,omitempty"`
+	// CompletionNote summarizes what was done when the update completes the task
+	CompletionNote *string `json:"completion_note,omitempty"`
+}
+
+// TaskBulkCreateRequest is the body of a request creating several tasks at once
+type TaskBulkCreateRequest struct {
+	Tasks []BulkTask `json:"tasks"`
+}
+
+// BulkTask is one of the tasks of a bulk creation request
+type BulkTask struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Status      string `json:"status,omitempty"`
+	Priority    string `json:"priority,omitempty"`
+}
+
+func (h *Handler) listPlanTasks(w http.ResponseWriter, r *http.Request) {
+	planID := r.PathValue("id")
+	status := r.URL.Query().Get("status")
+
+	var tasks []*models.Task
+	var err error
+	switch {
+	case status == "":
+		tasks, err = h.taskRepo.ListByPlan(r.Context(), planID)
+	case models.IsValidTaskStatus(models.TaskStatus(status)):
+		tasks, err = h.taskRepo.ListByPlanAndStatus(r.Context(), planID, models.TaskStatus(status))
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid status: %s", status))
+		return
+	}
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	if tasks == nil {
+		tasks = []*models.Task{}
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+func (h *Handler) createTask(w http.ResponseWriter, r *http.Request) {
+	var req TaskCreateRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.Title == "" {
+		writeError(w, http.StatusBadRequest, "title is required")
+		return
+	}
+	if err := validateTaskFields(req.Status, req.Priority); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	description := req.Description
+	if description == "" {
+		description = "no description provided"
+	}
+
+	priority := models.TaskPriority(req.Priority)
+	if priority == "" {
+		priority = models.TaskPriorityMedium
+	}
+
+	notes, err := prepareNotes(req.Notes)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	task, err := h.taskRepo.Create(r.Context(), r.PathValue("id"), req.Title, description, priority)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	if req.Status != "" && models.TaskStatus(req.Status) != task.Status {
+		task.Status = models.TaskStatus(req.Status)
+		if err := h.taskRepo.Update(r.Context(), task); err != nil {
+			writeRepositoryError(w, err)
+			return
+		}
+	}
+
+	if notes != "" {
+		if err := h.taskRepo.UpdateNotes(r.Context(), task.ID, notes); err != nil {
+			writeRepositoryError(w, err)
+			return
+		}
+	}
+
+	// Reload the task so the response reflects everything that was stored
+	task, err = h.taskRepo.Get(r.Context(), task.ID)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, task)
+}
+
+func (h *Handler) bulkCreateTasks(w http.ResponseWriter, r *http.Request) {
+	var req TaskBulkCreateRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(req.Tasks) == 0 {
+		writeError(w, http.StatusBadRequest, "tasks is required")
+		return
+	}
+	inputs := make([]storage.TaskCreateInput, len(req.Tasks))
+	for i, task := range req.Tasks {
+		if task.Title == "" {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("task %d: title is required", i))
+			return
+		}
+		if err := validateTaskFields(task.Status, task.Priority); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("task %d: %v", i, err))
+			return
+		}
+		inputs[i] = storage.TaskCreateInput{
+			Title:       task.Title,
+			Description: task.Description,
+			Status:      models.TaskStatus(task.Status),
+			Priority:    models.TaskPriority(task.Priority),
+		}
+	}
+
+	tasks, err := h.taskRepo.CreateBulk(r.Context(), r.PathValue("id"), inputs)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, tasks)
+}
+
+func (h *Handler) getTask(w http.ResponseWriter, r *http.Request) {
+	task, err := h.taskRepo.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (h *Handler) updateTask(w http.ResponseWriter, r *http.Request) {
+	var req TaskUpdateRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	task, err := h.taskRepo.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	if req.Title != nil {
+		if *req.Title == "" {
+			writeError(w, http.StatusBadRequest, "title cannot be empty")
+			return
+		}
+		task.Title = *req.Title
+	}
+	if req.Description != nil {
+		task.Description = *req.Description
+	}
+	if req.Status != nil {
+		if err := validateTaskFields(*req.Status, ""); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		task.Status = models.TaskStatus(*req.Status)
+	}
+	if req.Priority != nil {
+		if err := validateTaskFields("", *req.Priority); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		task.Priority = models.TaskPriority(*req.Priority)
+	}
+	if req.CompletionNote != nil {
+		task.CompletionNote = *req.CompletionNote
+	}
+
+	if req.Notes != nil {
+		notes, err := prepareNotes(*req.Notes)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := h.taskRepo.UpdateNotes(r.Context(), task.ID, notes); err != nil {
+			writeRepositoryError(w, err)
+			return
+		}
+		task.Notes = notes
+	}
+
+	if err := h.taskRepo.Update(r.Context(), task); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (h *Handler) deleteTask(w http.ResponseWriter, r *http.Request) {
+	if err := h.taskRepo.Delete(r.Context(), r.PathValue("id")); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateTaskFields checks an optional status and priority against the allowed values
+func validateTaskFields(status, priority string) error {
+	if status != "" && !models.IsValidTaskStatus(models.TaskStatus(status)) {
+		return fmt.Errorf("invalid status: %s", status)
+	}
+	if priority != "" && !models.IsValidTaskPriority(models.TaskPriority(priority)) {
+		return fmt.Errorf("invalid priority: %s", priority)
+	}
+	return nil
+}
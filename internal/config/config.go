@@ -0,0 +1,213 @@
+// Package config reads the settings of the server from the environment and an optional config file.
+// Settings are named by their environment variables, and a variable set in the environment takes
+// precedence over the config file.
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// pairSettings are the settings holding comma-separated key=value pairs, which may be written as a map
+var pairSettings = map[string]bool{"APPLICATION_TOKENS": true, "ROLES": true, "TOKEN_ROLES": true}
+
+// The config file loaded last
+var (
+	mu      sync.RWMutex
+	path    string
+	values  map[string]string
+	modTime time.Time
+)
+
+// Load reads a config file, replacing the file loaded before. Files ending in .env hold KEY=VALUE lines,
+// other files are YAML. An empty path unloads the file.
+func Load(configPath string) error {
+	if configPath == "" {
+		mu.Lock()
+		defer mu.Unlock()
+		path, values, modTime = "", nil, time.Time{}
+		return nil
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var settings map[string]string
+	if filepath.Ext(configPath) == ".env" {
+		settings, err = parseEnv(data)
+	} else {
+		settings, err = parseYAML(data)
+	}
+	if err != nil {
+		mu.Lock()
+		defer mu.Unlock()
+		if configPath == path {
+			// The broken file is not reported as changed again until it is modified
+			modTime = info.ModTime()
+		}
+		return fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	path, values, modTime = configPath, settings, info.ModTime()
+	return nil
+}
+
+// Reload reads the config file again. A file that cannot be read or parsed keeps the settings loaded before.
+func Reload() error {
+	return Load(Path())
+}
+
+// Path returns the path of the loaded config file, or an empty string if there is none
+func Path() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return path
+}
+
+// Changed reports whether the config file was modified since it was loaded
+func Changed() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	if path == "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && !info.ModTime().Equal(modTime)
+}
+
+// Lookup returns the value of a setting from the environment, or else from the config file
+func Lookup(name string) (string, bool) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	value, ok := values[name]
+	return value, ok
+}
+
+// Get returns the value of a setting, or an empty string if it is not set
+func Get(name string) string {
+	value, _ := Lookup(name)
+	return value
+}
+
+// parseEnv parses lines of KEY=VALUE settings. Empty lines and lines starting with # are skipped, an export
+// prefix is allowed and values may be quoted.
+func parseEnv(data []byte) (map[string]string, error) {
+	settings := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(strings.TrimPrefix(text, "export "), "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", line)
+		}
+		if !names[name] {
+			return nil, fmt.Errorf("line %d: unknown setting %s", line, name)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		settings[name] = value
+	}
+	return settings, scanner.Err()
+}
+
+// parseYAML parses a YAML document of settings. The path of keys to a value, joined by underscores and in upper
+// case, names its setting, so valkey: {tls: {ca_file: ...}} sets VALKEY_TLS_CA_FILE. A key named enabled sets
+// the setting of its parent if there is no setting ending in _ENABLED, so valkey.tls.enabled sets VALKEY_TLS.
+// The same goes for a key named enabled under the name of a transport, so sse.enabled sets ENABLE_SSE. Lists are
+// joined with commas, and the map of application tokens is joined into token=application pairs.
+func parseYAML(data []byte) (map[string]string, error) {
+	var document map[string]any
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	settings := make(map[string]string)
+	if err := flatten(settings, "", "", document); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// flatten adds the settings in a YAML node at a key path to the settings
+func flatten(settings map[string]string, name, keyPath string, node any) error {
+	if children, ok := node.(map[string]any); ok && !pairSettings[name] {
+		for key, child := range children {
+			childName := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+			if name != "" {
+				childName = name + "_" + childName
+			}
+			if !names[childName] && strings.EqualFold(key, "enabled") {
+				if names[name] {
+					childName = name
+				} else if names["ENABLE_"+name] {
+					childName = "ENABLE_" + name
+				}
+			}
+			childPath := key
+			if keyPath != "" {
+				childPath = keyPath + "." + key
+			}
+			if err := flatten(settings, childName, childPath, child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if !names[name] {
+		return fmt.Errorf("unknown setting %s", keyPath)
+	}
+	switch value := node.(type) {
+	case nil:
+		// An empty value leaves the setting unset
+	case []any:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = fmt.Sprint(item)
+		}
+		settings[name] = strings.Join(items, ",")
+	case map[string]any:
+		pairs := make([]string, 0, len(value))
+		for key, item := range value {
+			// Lists in pairs, such as the tools of a role, are separated by spaces
+			if list, ok := item.([]any); ok {
+				words := make([]string, len(list))
+				for i, word := range list {
+					words[i] = fmt.Sprint(word)
+				}
+				item = strings.Join(words, " ")
+			}
+			pairs = append(pairs, key+"="+fmt.Sprint(item))
+		}
+		sort.Strings(pairs)
+		settings[name] = strings.Join(pairs, ",")
+	default:
+		settings[name] = fmt.Sprint(value)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a config file and loads it, unloading it again when the test ends
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	configPath := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	t.Cleanup(func() { Load("") }) //nolint:errcheck
+	return configPath
+}
+
+func TestLoadYAML(t *testing.T) {
+	configPath := writeConfig(t, "server.yaml", `
+storage_backend: valkey
+valkey:
+  host: valkey.internal
+  port: 6380
+  tls:
+    enabled: true
+    ca_file: /etc/valkey/ca.pem
+  sentinel:
+    addresses: [sentinel-1, sentinel-2:26380]
+sse:
+  enabled: false
+streamable_http:
+  enabled: true
+rate_limit:
+  per_second: 2.5
+  expensive:
+    burst: 3
+application_tokens:
+  secret-b: app-b
+  secret-a: app-a
+roles:
+  reviewer: [read, update_plan_status]
+audit:
+  enabled: false
+github:
+  repo:
+`)
+	if err := Load(configPath); err != nil {
+		t.Fatalf("failed to load config file: %v", err)
+	}
+
+	for name, expected := range map[string]string{
+		"STORAGE_BACKEND":            "valkey",
+		"VALKEY_HOST":                "valkey.internal",
+		"VALKEY_PORT":                "6380",
+		"VALKEY_TLS":                 "true",
+		"VALKEY_TLS_CA_FILE":         "/etc/valkey/ca.pem",
+		"VALKEY_SENTINEL_ADDRESSES":  "sentinel-1,sentinel-2:26380",
+		"ENABLE_SSE":                 "false",
+		"ENABLE_STREAMABLE_HTTP":     "true",
+		"RATE_LIMIT_PER_SECOND":      "2.5",
+		"RATE_LIMIT_EXPENSIVE_BURST": "3",
+		"APPLICATION_TOKENS":         "secret-a=app-a,secret-b=app-b",
+		"ROLES":                      "reviewer=read update_plan_status",
+		"AUDIT_ENABLED":              "false",
+	} {
+		if got, ok := Lookup(name); !ok || got != expected {
+			t.Errorf("%s = %q, %v, expected %q", name, got, ok, expected)
+		}
+	}
+	if _, ok := Lookup("GITHUB_REPO"); ok {
+		t.Error("an empty value should leave the setting unset")
+	}
+
+	// The environment takes precedence over the file
+	t.Setenv("VALKEY_HOST", "localhost")
+	if got := Get("VALKEY_HOST"); got != "localhost" {
+		t.Errorf("VALKEY_HOST = %q, the environment should take precedence", got)
+	}
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	configPath := writeConfig(t, "server.env", `
+# Rate limits
+RATE_LIMIT_PER_SECOND=5
+export READ_ONLY_MODE = true
+NOTES_SUMMARIZER_URL="http://localhost:9000/summarize"
+`)
+	if err := Load(configPath); err != nil {
+		t.Fatalf("failed to load env file: %v", err)
+	}
+	for name, expected := range map[string]string{
+		"RATE_LIMIT_PER_SECOND": "5",
+		"READ_ONLY_MODE":        "true",
+		"NOTES_SUMMARIZER_URL":  "http://localhost:9000/summarize",
+	} {
+		if got := Get(name); got != expected {
+			t.Errorf("%s = %q, expected %q", name, got, expected)
+		}
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"typo.yaml":     "valkey:\n  hots: localhost\n",
+		"syntax.yaml":   "valkey: [\n",
+		"unknown.env":   "VALKEY_HOTS=localhost\n",
+		"malformed.env": "VALKEY_HOST=localhost\nnot a setting\n",
+	} {
+		err := Load(writeConfig(t, name, content))
+		if err == nil {
+			t.Errorf("%s should be rejected", name)
+		}
+	}
+	if err := Load(writeConfig(t, "typo.yaml", "valkey:\n  hots: localhost\n")); err == nil ||
+		!strings.Contains(err.Error(), "unknown setting valkey.hots") {
+		t.Errorf("the error should name the unknown key, got %v", err)
+	}
+}
+
+func TestReload(t *testing.T) {
+	configPath := writeConfig(t, "server.yaml", "read_only_mode: false\n")
+	if err := Load(configPath); err != nil {
+		t.Fatalf("failed to load config file: %v", err)
+	}
+
+	if err := os.WriteFile(configPath, []byte("read_only_mode: true\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	if err := Reload(); err != nil {
+		t.Fatalf("failed to reload config file: %v", err)
+	}
+	if got := Get("READ_ONLY_MODE"); got != "true" {
+		t.Errorf("READ_ONLY_MODE = %q after the reload, expected true", got)
+	}
+
+	// A broken file keeps the settings loaded before
+	if err := os.WriteFile(configPath, []byte("read_only: true\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	if err := Reload(); err == nil {
+		t.Fatal("the broken file should be rejected")
+	}
+	if got := Get("READ_ONLY_MODE"); got != "true" || Path() != configPath {
+		t.Errorf("READ_ONLY_MODE = %q, the settings should be kept", got)
+	}
+}
@@ -0,0 +1,172 @@
+package config
+
+// names are the settings of the server, named by their environment variables. A config file may only set these.
+var names = map[string]bool{
+	// Storage
+	"STORAGE_BACKEND":          true,
+	"MEMORY_SNAPSHOT_FILE":     true,
+	"MEMORY_SNAPSHOT_INTERVAL": true,
+
+	// Valkey connection
+	"VALKEY_HOST":                     true,
+	"VALKEY_PORT":                     true,
+	"VALKEY_USERNAME":                 true,
+	"VALKEY_PASSWORD":                 true,
+	"VALKEY_CLUSTER":                  true,
+	"VALKEY_ADDRESSES":                true,
+	"VALKEY_SENTINEL_ADDRESSES":       true,
+	"VALKEY_SENTINEL_MASTER":          true,
+	"VALKEY_SENTINEL_USERNAME":        true,
+	"VALKEY_SENTINEL_PASSWORD":        true,
+	"VALKEY_READ_FROM_REPLICA":        true,
+	"VALKEY_TLS":                      true,
+	"VALKEY_TLS_CA_FILE":              true,
+	"VALKEY_TLS_CERT_FILE":            true,
+	"VALKEY_TLS_KEY_FILE":             true,
+	"VALKEY_TLS_INSECURE_SKIP_VERIFY": true,
+	"VALKEY_DB":                       true,
+	"VALKEY_POOL_SIZE":                true,
+	"VALKEY_REQUEST_TIMEOUT_MS":       true,
+	"VALKEY_CONNECT_TIMEOUT_MS":       true,
+	"VALKEY_RECONNECT_RETRIES":        true,
+	"VALKEY_RECONNECT_DELAY_MS":       true,
+	"VALKEY_RETRY_ATTEMPTS":           true,
+	"VALKEY_RETRY_BACKOFF_MS":         true,
+	"VALKEY_RETRY_MAX_BACKOFF_MS":     true,
+
+	// Transports
+	"SERVER_PORT":                        true,
+	"ENABLE_SSE":                         true,
+	"SSE_ENDPOINT":                       true,
+	"SSE_KEEP_ALIVE":                     true,
+	"SSE_KEEP_ALIVE_INTERVAL":            true,
+	"ENABLE_STREAMABLE_HTTP":             true,
+	"STREAMABLE_HTTP_ENDPOINT":           true,
+	"STREAMABLE_HTTP_HEARTBEAT_INTERVAL": true,
+	"STREAMABLE_HTTP_STATELESS":          true,
+	"ENABLE_WEBSOCKET":                   true,
+	"WEBSOCKET_ENDPOINT":                 true,
+	"ENABLE_STDIO":                       true,
+	"STDIO_ERROR_LOG":                    true,
+	"ENABLE_REST_API":                    true,
+	"ENABLE_WEB_UI":                      true,
+	"WEB_UI_POLL_INTERVAL":               true,
+	"SERVER_READ_TIMEOUT":                true,
+	"SERVER_WRITE_TIMEOUT":               true,
+	"TOOL_TIMEOUT":                       true,
+	"MAX_RESOURCE_SIZE":                  true,
+	"TLS_CERT_FILE":                      true,
+	"TLS_KEY_FILE":                       true,
+	"TLS_AUTOCERT_DOMAINS":               true,
+	"TLS_AUTOCERT_CACHE_DIR":             true,
+	"TLS_AUTOCERT_EMAIL":                 true,
+
+	// Access
+	"APPLICATION_SCOPE":               true,
+	"APPLICATION_TOKENS":              true,
+	"ROLES":                           true,
+	"TOKEN_ROLES":                     true,
+	"DEFAULT_ROLE":                    true,
+	"READ_ONLY_MODE":                  true,
+	"CONFIRM_DESTRUCTIVE_TOOLS":       true,
+	"ADMIN_TOOLS_ENABLED":             true,
+	"ADMIN_TOKEN":                     true,
+	"BACKUP_DIR":                      true,
+	"REQUIRE_KNOWN_APPLICATIONS":      true,
+	"REQUIRE_COMPLETION_NOTES":        true,
+	"REQUIRE_ACCEPTANCE_CRITERIA":     true,
+	"REVIEW_WORKFLOW":                 true,
+	"REVIEW_STATUSES":                 true,
+	"CUSTOM_TASK_STATUSES":            true,
+	"CUSTOM_TASK_PRIORITIES":          true,
+	"RATE_LIMIT_PER_SECOND":           true,
+	"RATE_LIMIT_BURST":                true,
+	"RATE_LIMIT_EXPENSIVE_PER_SECOND": true,
+	"RATE_LIMIT_EXPENSIVE_BURST":      true,
+
+	// Background jobs and retention
+	"JOB_LEASE_EXPIRY_ENABLED":          true,
+	"LEASE_SWEEP_INTERVAL":              true,
+	"JOB_ORPHAN_CLEANUP_ENABLED":        true,
+	"ORPHAN_CLEANUP_INTERVAL":           true,
+	"SCHEDULER_LOCKING_ENABLED":         true,
+	"SCHEDULER_LEADER_ELECTION_ENABLED": true,
+	"SCHEDULER_JITTER_PERCENT":          true,
+	"AUDIT_ENABLED":                     true,
+	"AUDIT_MAX_ENTRIES":                 true,
+	"AUDIT_RETENTION_DAYS":              true,
+	"AUDIT_EXPORT_FILE":                 true,
+	"AUDIT_EXPORT_SYSLOG":               true,
+	"AUDIT_EXPORT_URL":                  true,
+	"AUDIT_EXPORT_TOKEN":                true,
+	"AUDIT_EXPORT_FORMAT":               true,
+	"AUDIT_EXPORT_BATCH_SIZE":           true,
+	"AUDIT_EXPORT_FLUSH_INTERVAL_MS":    true,
+	"AUDIT_EXPORT_QUEUE_SIZE":           true,
+	"AUDIT_EXPORT_BLOCK_MS":             true,
+	"PLAN_RETENTION_DAYS":               true,
+	"PLAN_RETENTION_ACTION":             true,
+	"PLAN_ARCHIVE_DIR":                  true,
+	"RETENTION_SWEEP_INTERVAL":          true,
+	"PRIORITY_ESCALATION_HOURS":         true,
+	"PRIORITY_ESCALATION_MAX":           true,
+	"ESCALATION_SWEEP_INTERVAL":         true,
+	"IDEMPOTENCY_KEY_TTL":               true,
+
+	// Localization
+	"LANG": true,
+
+	// Limits and notes
+	"MAX_TITLE_LENGTH":         true,
+	"MAX_DESCRIPTION_LENGTH":   true,
+	"MAX_NOTES_LENGTH":         true,
+	"MAX_BULK_TASKS":           true,
+	"MAX_TASKS_PER_PLAN":       true,
+	"MAX_ATTACHMENT_SIZE":      true,
+	"MAX_ATTACHMENTS_PER_TASK": true,
+	"NOTES_HISTORY_LENGTH":     true,
+	"NOTES_COMPACT_LENGTH":     true,
+	"NOTES_SUMMARIZER_URL":     true,
+	"AGENT_ACTIVITY_LENGTH":    true,
+
+	// Decomposition guardrails
+	"DECOMPOSITION_MAX_DESCRIPTION_LENGTH": true,
+	"DECOMPOSITION_MAX_PLAN_TASKS":         true,
+	"DECOMPOSITION_MODE":                   true,
+
+	// Sanitization
+	"SANITIZE_TITLES":       true,
+	"SANITIZE_DESCRIPTIONS": true,
+	"SANITIZE_NOTES":        true,
+
+	// Failure injection
+	"CHAOS_FAILURE_RATE":      true,
+	"CHAOS_LATENCY_MS":        true,
+	"CHAOS_LATENCY_JITTER_MS": true,
+	"CHAOS_OPERATIONS":        true,
+	"CHAOS_SEED":              true,
+
+	// Field encryption
+	"FIELD_ENCRYPTION_KEYS": true,
+
+	// Watch notifications
+	"NOTIFY_WEBHOOK_URL":       true,
+	"NOTIFY_SLACK_WEBHOOK_URL": true,
+	"NOTIFY_STREAM":            true,
+
+	// Resource cache
+	"RESOURCE_CACHE_TTL":         true,
+	"RESOURCE_CACHE_MAX_ENTRIES": true,
+
+	// Integrations
+	"GITHUB_TOKEN":      true,
+	"GITHUB_REPO":       true,
+	"GITHUB_API_URL":    true,
+	"JIRA_URL":          true,
+	"JIRA_EMAIL":        true,
+	"JIRA_API_TOKEN":    true,
+	"JIRA_MAPPING_FILE": true,
+
+	// Config file
+	"CONFIG_WATCH_INTERVAL": true,
+}
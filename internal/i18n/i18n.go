@@ -0,0 +1,97 @@
+// Package i18n translates the descriptions and messages the server presents to clients. Messages are looked
+// up by their English text in the catalogs under locales, so text without a translation stays English.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// DefaultLanguage is the language the messages are written in
+const DefaultLanguage = "en"
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Localizer translates messages into one language. A nil Localizer leaves messages in English.
+type Localizer struct {
+	language string
+	messages map[string]string
+}
+
+// New returns a localizer for a language given as a code such as es or ja, or as a locale such as
+// ja_JP.UTF-8. English, an empty language and the C and POSIX locales return nil, which leaves messages as
+// they are.
+func New(language string) (*Localizer, error) {
+	language = Normalize(language)
+	if language == DefaultLanguage {
+		return nil, nil
+	}
+
+	data, err := locales.ReadFile(path.Join("locales", language+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported language %q, expected one of %s", language,
+			strings.Join(Languages(), ", "))
+	}
+
+	var messages map[string]string
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, fmt.Errorf("failed to parse the messages of %s: %w", language, err)
+	}
+	return &Localizer{language: language, messages: messages}, nil
+}
+
+// Normalize reduces a locale such as es_ES.UTF-8 or pt-BR to its lowercase language code
+func Normalize(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if i := strings.IndexAny(language, "_-.@"); i >= 0 {
+		language = language[:i]
+	}
+	switch language {
+	case "", "c", "posix":
+		return DefaultLanguage
+	}
+	return language
+}
+
+// Languages returns the supported language codes, including English
+func Languages() []string {
+	languages := []string{DefaultLanguage}
+	entries, err := locales.ReadDir("locales")
+	if err != nil {
+		return languages
+	}
+	for _, entry := range entries {
+		languages = append(languages, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	slices.Sort(languages)
+	return languages
+}
+
+// Language returns the code of the language messages are translated into
+func (l *Localizer) Language() string {
+	if l == nil {
+		return DefaultLanguage
+	}
+	return l.language
+}
+
+// Translate returns the translation of a message, or the message itself if it has none
+func (l *Localizer) Translate(message string) string {
+	if l == nil {
+		return message
+	}
+	if translated, ok := l.messages[message]; ok && translated != "" {
+		return translated
+	}
+	return message
+}
+
+// Sprintf translates a format and formats it with the arguments
+func (l *Localizer) Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(l.Translate(format), args...)
+}
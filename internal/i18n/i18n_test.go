@@ -0,0 +1,84 @@
+package i18n
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"":            "en",
+		"C":           "en",
+		"POSIX":       "en",
+		"en_US.UTF-8": "en",
+		"es":          "es",
+		"es_ES.UTF-8": "es",
+		"ja-JP":       "ja",
+		" JA ":        "ja",
+		"de@euro":     "de",
+	}
+	for language, want := range tests {
+		if got := Normalize(language); got != want {
+			t.Errorf("Normalize(%q) = %q, expected %q", language, got, want)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	localizer, err := New("en_US.UTF-8")
+	if err != nil || localizer != nil {
+		t.Fatalf("expected no localizer for English, got %v, %v", localizer, err)
+	}
+	if got := localizer.Translate("Failed to get plan"); got != "Failed to get plan" {
+		t.Errorf("expected a nil localizer to keep messages, got %q", got)
+	}
+	if got := localizer.Sprintf("%s not found: %s", "plan", "p1"); got != "plan not found: p1" {
+		t.Errorf("expected a nil localizer to format messages, got %q", got)
+	}
+
+	if _, err := New("xx"); err == nil || !strings.Contains(err.Error(), "unsupported language") {
+		t.Errorf("expected an unsupported language error, got %v", err)
+	}
+
+	localizer, err = New("ja_JP.UTF-8")
+	if err != nil {
+		t.Fatalf("failed to create localizer: %v", err)
+	}
+	if localizer.Language() != "ja" {
+		t.Errorf("expected language ja, got %q", localizer.Language())
+	}
+	if got := localizer.Translate("Failed to get plan"); got == "Failed to get plan" {
+		t.Errorf("expected a translation, got %q", got)
+	}
+	if got := localizer.Translate("A message without a translation"); got != "A message without a translation" {
+		t.Errorf("expected an untranslated message to stay English, got %q", got)
+	}
+}
+
+func TestCatalogsMatch(t *testing.T) {
+	var reference []string
+	for _, language := range Languages() {
+		if language == DefaultLanguage {
+			continue
+		}
+		localizer, err := New(language)
+		if err != nil {
+			t.Fatalf("failed to load %s: %v", language, err)
+		}
+
+		keys := slices.Sorted(maps.Keys(localizer.messages))
+		if reference == nil {
+			reference = keys
+		} else if !slices.Equal(reference, keys) {
+			t.Errorf("the messages of %s differ from the other languages", language)
+		}
+
+		for message, translated := range localizer.messages {
+			if strings.Count(message, "%") != strings.Count(translated, "%") {
+				t.Errorf("%s: translation of %q has different placeholders: %q", language, message, translated)
+			}
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// Package github syncs the tasks of a plan with the issues of a GitHub repository.
+// Tasks are pushed as issues, issue close and reopen events are pulled back as task status changes,
+// and the link between a task and its issue is kept in the task's metadata.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the base URL of the public GitHub REST API
+const DefaultAPIURL = "https://api.github.com"
+
+const (
+	// issuesPerPage is the page size used when listing issues
+	issuesPerPage = 100
+	// maxIssuePages bounds how many pages of issues are read in one import
+	maxIssuePages = 10
+	// requestTimeout bounds a single GitHub API request
+	requestTimeout = 30 * time.Second
+)
+
+// repoPattern matches repository names in owner/name form
+var repoPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+
+// Issue is the subset of a GitHub issue used for syncing
+type Issue struct {
+	Number      int     `json:"number"`
+	Title       string  `json:"title"`
+	Body        string  `json:"body"`
+	State       string  `json:"state"`
+	StateReason string  `json:"state_reason"`
+	HTMLURL     string  `json:"html_url"`
+	Labels      []Label `json:"labels"`
+	// PullRequest is set when the issue is a pull request; the issues API lists both
+	PullRequest json.RawMessage `json:"pull_request,omitempty"`
+}
+
+// Label is a GitHub issue label
+type Label struct {
+	Name string `json:"name"`
+}
+
+// IssueRequest holds the fields sent when creating or updating an issue. Empty fields are left unchanged.
+type IssueRequest struct {
+	Title       string  `json:"title,omitempty"`
+	Body        *string `json:"body,omitempty"`
+	State       string  `json:"state,omitempty"`
+	StateReason string  `json:"state_reason,omitempty"`
+}
+
+// Client is a minimal client for the GitHub issues REST API
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient creates a GitHub API client. An empty base URL selects DefaultAPIURL.
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// ValidateRepo checks that a repository name has the owner/name form
+func ValidateRepo(repo string) error {
+	if !repoPattern.MatchString(repo) {
+		return fmt.Errorf("invalid GitHub repository %q, expected owner/name", repo)
+	}
+	return nil
+}
+
+// CreateIssue creates an issue in a repository
+func (c *Client) CreateIssue(ctx context.Context, repo string, req IssueRequest) (*Issue, error) {
+	var issue Issue
+	if err := c.do(ctx, http.MethodPost, "/repos/"+repo+"/issues", req, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// GetIssue returns an issue of a repository
+func (c *Client) GetIssue(ctx context.Context, repo string, number int) (*Issue, error) {
+	var issue Issue
+	if err := c.do(ctx, http.MethodGet, "/repos/"+repo+"/issues/"+strconv.Itoa(number), nil, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// UpdateIssue updates the title, body or state of an issue
+func (c *Client) UpdateIssue(ctx context.Context, repo string, number int, req IssueRequest) (*Issue, error) {
+	var issue Issue
+	if err := c.do(ctx, http.MethodPatch, "/repos/"+repo+"/issues/"+strconv.Itoa(number), req, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// ListIssues returns the issues of a repository, oldest first, leaving out pull requests.
+// State is open, closed or all; when labels are given, only issues with all of them are returned.
+func (c *Client) ListIssues(ctx context.Context, repo, state string, labels []string) ([]*Issue, error) {
+	query := url.Values{}
+	query.Set("state", state)
+	query.Set("sort", "created")
+	query.Set("direction", "asc")
+	query.Set("per_page", strconv.Itoa(issuesPerPage))
+	if len(labels) > 0 {
+		query.Set("labels", strings.Join(labels, ","))
+	}
+
+	var issues []*Issue
+	for page := 1; page <= maxIssuePages; page++ {
+		query.Set("page", strconv.Itoa(page))
+
+		var batch []*Issue
+		if err := c.do(ctx, http.MethodGet, "/repos/"+repo+"/issues?"+query.Encode(), nil, &batch); err != nil {
+			return nil, err
+		}
+
+		for _, issue := range batch {
+			if issue.PullRequest == nil {
+				issues = append(issues, issue)
+			}
+		}
+		if len(batch) < issuesPerPage {
+			break
+		}
+	}
+
+	return issues, nil
+}
+
+// do sends a request to the GitHub API and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode GitHub request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", "valkey-ai-tasks")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("request %s %s to GitHub failed: %w", method, path, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return fmt.Errorf("request %s %s to GitHub failed with status %d: %s", method, path, resp.StatusCode, apiErr.Message)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode GitHub response: %w", err)
+	}
+	return nil
+}
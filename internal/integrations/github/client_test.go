@@ -0,0 +1,82 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestClientListIssues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/repos/owner/repo/issues" || r.URL.Query().Get("labels") != "bug,ui" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+
+		// The first page is full, so the client must ask for the second one
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		issues := []map[string]any{}
+		switch page {
+		case 1:
+			for i := 1; i <= issuesPerPage; i++ {
+				issue := map[string]any{"number": i, "title": fmt.Sprintf("Issue %d", i), "state": "open"}
+				if i%2 == 0 {
+					issue["pull_request"] = map[string]any{"url": "https://example.com"}
+				}
+				issues = append(issues, issue)
+			}
+		case 2:
+			issues = append(issues, map[string]any{"number": 101, "title": "Last", "state": "closed"})
+		}
+		json.NewEncoder(w).Encode(issues)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret")
+	issues, err := client.ListIssues(context.Background(), "owner/repo", "all", []string{"bug", "ui"})
+	if err != nil {
+		t.Fatalf("ListIssues() error = %v", err)
+	}
+
+	// Pull requests are left out
+	if len(issues) != issuesPerPage/2+1 {
+		t.Fatalf("got %d issues, expected %d", len(issues), issuesPerPage/2+1)
+	}
+	if issues[0].Number != 1 || issues[len(issues)-1].Number != 101 {
+		t.Errorf("unexpected issues: first #%d, last #%d", issues[0].Number, issues[len(issues)-1].Number)
+	}
+}
+
+func TestClientReportsAPIErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "Not Found"}`))
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL, "secret").GetIssue(context.Background(), "owner/repo", 7)
+	if err == nil || !strings.Contains(err.Error(), "404") || !strings.Contains(err.Error(), "Not Found") {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestValidateRepo(t *testing.T) {
+	for _, repo := range []string{"owner/repo", "my-org/my.repo_2"} {
+		if err := ValidateRepo(repo); err != nil {
+			t.Errorf("ValidateRepo(%q) error = %v", repo, err)
+		}
+	}
+	for _, repo := range []string{"", "repo", "owner/repo/extra", "owner/../repo?x=1"} {
+		if err := ValidateRepo(repo); err == nil {
+			t.Errorf("expected ValidateRepo(%q) to fail", repo)
+		}
+	}
+}
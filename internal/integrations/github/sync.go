@@ -0,0 +1,394 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// Metadata keys that link a task to its GitHub issue
+const (
+	MetadataRepo  = "github.repo"
+	MetadataIssue = "github.issue"
+	MetadataURL   = "github.url"
+	// MetadataState is the issue state seen at the last sync, used to tell which side changed since
+	MetadataState = "github.state"
+)
+
+// Issue states and close reasons used by GitHub
+const (
+	issueStateOpen        = "open"
+	issueStateClosed      = "closed"
+	stateReasonCompleted  = "completed"
+	stateReasonNotPlanned = "not_planned"
+)
+
+// priorityLabelPattern matches priority labels such as "priority: high", "priority/low" or "priority-medium"
+var priorityLabelPattern = regexp.MustCompile(`(?i)^priority\s*[:/-]?\s*(low|medium|high)$`)
+
+// Syncer syncs the tasks of plans with GitHub issues
+type Syncer struct {
+	planRepo storage.PlanRepositoryInterface
+	taskRepo storage.TaskRepositoryInterface
+	client   *Client
+}
+
+// NewSyncer creates a new GitHub syncer
+func NewSyncer(
+	planRepo storage.PlanRepositoryInterface,
+	taskRepo storage.TaskRepositoryInterface,
+	client *Client,
+) *Syncer {
+	return &Syncer{
+		planRepo: planRepo,
+		taskRepo: taskRepo,
+		client:   client,
+	}
+}
+
+// SyncedTask describes a task and the issue it is linked to
+type SyncedTask struct {
+	TaskID      string            `json:"task_id"`
+	Title       string            `json:"title"`
+	Status      models.TaskStatus `json:"status"`
+	Repo        string            `json:"repo"`
+	IssueNumber int               `json:"issue_number"`
+	IssueURL    string            `json:"issue_url"`
+}
+
+// SyncError describes a task that could not be synced
+type SyncError struct {
+	TaskID string `json:"task_id"`
+	Error  string `json:"error"`
+}
+
+// SyncReport lists what a plan sync changed
+type SyncReport struct {
+	PlanID string `json:"plan_id"`
+	// Created lists open tasks that were pushed as new issues
+	Created []SyncedTask `json:"created"`
+	// Pushed lists issues whose title or state was updated from their task
+	Pushed []SyncedTask `json:"pushed"`
+	// Pulled lists tasks whose status was updated from their issue
+	Pulled []SyncedTask `json:"pulled"`
+	// Unchanged counts linked tasks that were already in sync
+	Unchanged int `json:"unchanged"`
+	// SkippedClosed counts completed or cancelled tasks without an issue, which are not pushed
+	SkippedClosed int         `json:"skipped_closed"`
+	Errors        []SyncError `json:"errors,omitempty"`
+}
+
+// ImportReport lists the tasks created from GitHub issues
+type ImportReport struct {
+	PlanID   string       `json:"plan_id"`
+	Imported []SyncedTask `json:"imported"`
+	// AlreadyLinked lists the numbers of issues that are already linked to a task of the plan
+	AlreadyLinked []int       `json:"already_linked"`
+	Errors        []SyncError `json:"errors,omitempty"`
+}
+
+// SyncPlan syncs the tasks of a plan with issues in the given repository.
+// Open tasks without an issue are pushed as new issues. For linked tasks, the task title is pushed to the issue,
+// and a difference between task status and issue state is resolved in favor of whichever side changed since
+// the last sync: issues closed or reopened on GitHub update the task, tasks completed or reopened here update
+// the issue. Linked tasks keep using the repository they were linked to.
+func (s *Syncer) SyncPlan(ctx context.Context, planID, repo string) (*SyncReport, error) {
+	if err := ValidateRepo(repo); err != nil {
+		return nil, err
+	}
+
+	plan, err := s.planRepo.Get(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.taskRepo.ListByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SyncReport{
+		PlanID:  planID,
+		Created: []SyncedTask{},
+		Pushed:  []SyncedTask{},
+		Pulled:  []SyncedTask{},
+	}
+
+	for _, task := range tasks {
+		if task.Metadata[MetadataIssue] == "" {
+			if !task.IsOpen() {
+				report.SkippedClosed++
+				continue
+			}
+
+			synced, err := s.pushNewIssue(ctx, plan, task, repo)
+			if err != nil {
+				report.Errors = append(report.Errors, SyncError{TaskID: task.ID, Error: err.Error()})
+				continue
+			}
+			report.Created = append(report.Created, *synced)
+			continue
+		}
+
+		err := s.syncLinkedTask(ctx, task, repo, report)
+		if err != nil {
+			report.Errors = append(report.Errors, SyncError{TaskID: task.ID, Error: err.Error()})
+		}
+	}
+
+	return report, nil
+}
+
+// pushNewIssue creates an issue for a task and links them
+func (s *Syncer) pushNewIssue(ctx context.Context, plan *models.Plan, task *models.Task, repo string) (*SyncedTask, error) {
+	body := IssueBody(plan, task)
+	issue, err := s.client.CreateIssue(ctx, repo, IssueRequest{Title: task.Title, Body: &body})
+	if err != nil {
+		return nil, err
+	}
+
+	task, err = s.taskRepo.SetMetadata(ctx, task.ID, linkMetadata(repo, issue))
+	if err != nil {
+		return nil, fmt.Errorf("created issue #%d but failed to link it: %w", issue.Number, err)
+	}
+
+	return syncedTask(task, repo, issue), nil
+}
+
+// syncLinkedTask reconciles a task with the issue it is linked to
+func (s *Syncer) syncLinkedTask(ctx context.Context, task *models.Task, defaultRepo string, report *SyncReport) error {
+	repo := task.Metadata[MetadataRepo]
+	if repo == "" {
+		repo = defaultRepo
+	}
+	number, err := strconv.Atoi(task.Metadata[MetadataIssue])
+	if err != nil {
+		return fmt.Errorf("invalid linked issue number %q", task.Metadata[MetadataIssue])
+	}
+
+	issue, err := s.client.GetIssue(ctx, repo, number)
+	if err != nil {
+		return err
+	}
+
+	decision := DecideSync(task, issue)
+	changed := false
+
+	if decision.TaskStatus != "" {
+		task.Status = decision.TaskStatus
+		if task.Status == models.TaskStatusCompleted && task.CompletionNote == "" {
+			task.CompletionNote = fmt.Sprintf("Closed with GitHub issue %s#%d", repo, number)
+		}
+		if err := s.taskRepo.Update(ctx, task); err != nil {
+			return fmt.Errorf("failed to update task from issue #%d: %w", number, err)
+		}
+		report.Pulled = append(report.Pulled, *syncedTask(task, repo, issue))
+		changed = true
+	}
+
+	if decision.pushesIssue() {
+		issue, err = s.client.UpdateIssue(ctx, repo, number, IssueRequest{
+			Title:       decision.IssueTitle,
+			State:       decision.IssueState,
+			StateReason: decision.StateReason,
+		})
+		if err != nil {
+			return err
+		}
+		report.Pushed = append(report.Pushed, *syncedTask(task, repo, issue))
+		changed = true
+	}
+
+	if !changed {
+		report.Unchanged++
+	}
+
+	if task.Metadata[MetadataState] != issue.State || task.Metadata[MetadataURL] != issue.HTMLURL {
+		if _, err := s.taskRepo.SetMetadata(ctx, task.ID, linkMetadata(repo, issue)); err != nil {
+			return fmt.Errorf("failed to record the state of issue #%d: %w", number, err)
+		}
+	}
+
+	return nil
+}
+
+// ImportIssues creates tasks in a plan from the issues of a repository and links them.
+// State selects open, closed or all issues; issues already linked to a task of the plan are skipped.
+func (s *Syncer) ImportIssues(ctx context.Context, planID, repo, state string, labels []string) (*ImportReport, error) {
+	if err := ValidateRepo(repo); err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.taskRepo.ListByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	linked := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		if number := task.Metadata[MetadataIssue]; number != "" {
+			linked[issueKey(task.Metadata[MetadataRepo], number)] = true
+		}
+	}
+
+	issues, err := s.client.ListIssues(ctx, repo, state, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{
+		PlanID:        planID,
+		Imported:      []SyncedTask{},
+		AlreadyLinked: []int{},
+	}
+
+	var newIssues []*Issue
+	var inputs []storage.TaskCreateInput
+	for _, issue := range issues {
+		if linked[issueKey(repo, strconv.Itoa(issue.Number))] {
+			report.AlreadyLinked = append(report.AlreadyLinked, issue.Number)
+			continue
+		}
+		newIssues = append(newIssues, issue)
+		inputs = append(inputs, TaskInputFromIssue(issue))
+	}
+
+	if len(inputs) == 0 {
+		return report, nil
+	}
+
+	created, err := s.taskRepo.CreateBulk(ctx, planID, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, task := range created {
+		issue := newIssues[i]
+		linkedTask, err := s.taskRepo.SetMetadata(ctx, task.ID, linkMetadata(repo, issue))
+		if err != nil {
+			report.Errors = append(report.Errors, SyncError{TaskID: task.ID, Error: err.Error()})
+			continue
+		}
+		report.Imported = append(report.Imported, *syncedTask(linkedTask, repo, issue))
+	}
+
+	return report, nil
+}
+
+// SyncDecision describes how to reconcile a task with its issue. Empty fields mean no change.
+type SyncDecision struct {
+	TaskStatus  models.TaskStatus
+	IssueTitle  string
+	IssueState  string
+	StateReason string
+}
+
+// pushesIssue reports whether the decision updates the issue
+func (d SyncDecision) pushesIssue() bool {
+	return d.IssueTitle != "" || d.IssueState != ""
+}
+
+// DecideSync works out how to reconcile a task with its issue.
+// The task title always wins. When one side is closed and the other open, the issue state recorded at the
+// last sync tells which side changed: if the issue state differs from it, the issue was closed or reopened on
+// GitHub and the task follows; otherwise the task changed and the issue follows.
+func DecideSync(task *models.Task, issue *Issue) SyncDecision {
+	var decision SyncDecision
+	if task.Title != issue.Title {
+		decision.IssueTitle = task.Title
+	}
+
+	issueClosed := issue.State == issueStateClosed
+	if issueClosed == !task.IsOpen() {
+		return decision
+	}
+
+	if issue.State != task.Metadata[MetadataState] {
+		decision.TaskStatus = statusFromIssue(issue)
+		return decision
+	}
+
+	if task.IsOpen() {
+		decision.IssueState = issueStateOpen
+		return decision
+	}
+
+	decision.IssueState = issueStateClosed
+	decision.StateReason = stateReasonCompleted
+	if task.Status == models.TaskStatusCancelled {
+		decision.StateReason = stateReasonNotPlanned
+	}
+	return decision
+}
+
+// TaskInputFromIssue maps an issue to a new task: closed issues become completed or cancelled tasks,
+// and priority labels set the priority
+func TaskInputFromIssue(issue *Issue) storage.TaskCreateInput {
+	input := storage.TaskCreateInput{
+		Title:       issue.Title,
+		Description: strings.TrimSpace(issue.Body),
+		Status:      statusFromIssue(issue),
+	}
+
+	for _, label := range issue.Labels {
+		if match := priorityLabelPattern.FindStringSubmatch(strings.TrimSpace(label.Name)); match != nil {
+			input.Priority = models.TaskPriority(strings.ToLower(match[1]))
+			break
+		}
+	}
+
+	return input
+}
+
+// IssueBody renders the body of an issue pushed for a task
+func IssueBody(plan *models.Plan, task *models.Task) string {
+	var b strings.Builder
+	if description := strings.TrimSpace(task.Description); description != "" && description != storage.DefaultTaskDescription {
+		b.WriteString(description + "\n\n---\n")
+	}
+	fmt.Fprintf(&b, "_Synced from plan **%s** (task `%s`) by valkey-ai-tasks._", plan.Name, task.ID)
+	return b.String()
+}
+
+// statusFromIssue maps an issue state to a task status
+func statusFromIssue(issue *Issue) models.TaskStatus {
+	if issue.State != issueStateClosed {
+		return models.TaskStatusPending
+	}
+	if issue.StateReason == stateReasonNotPlanned {
+		return models.TaskStatusCancelled
+	}
+	return models.TaskStatusCompleted
+}
+
+// linkMetadata returns the metadata entries that link a task to an issue
+func linkMetadata(repo string, issue *Issue) map[string]string {
+	return map[string]string{
+		MetadataRepo:  repo,
+		MetadataIssue: strconv.Itoa(issue.Number),
+		MetadataURL:   issue.HTMLURL,
+		MetadataState: issue.State,
+	}
+}
+
+// syncedTask describes a task and its issue for reports
+func syncedTask(task *models.Task, repo string, issue *Issue) *SyncedTask {
+	return &SyncedTask{
+		TaskID:      task.ID,
+		Title:       task.Title,
+		Status:      task.Status,
+		Repo:        repo,
+		IssueNumber: issue.Number,
+		IssueURL:    issue.HTMLURL,
+	}
+}
+
+// issueKey identifies an issue across repositories
+func issueKey(repo, number string) string {
+	return strings.ToLower(repo) + "#" + number
+}
@@ -0,0 +1,130 @@
+package github
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+func TestDecideSync(t *testing.T) {
+	tests := []struct {
+		name       string
+		taskStatus models.TaskStatus
+		lastState  string
+		issue      Issue
+		expected   SyncDecision
+	}{
+		{
+			name:       "In sync",
+			taskStatus: models.TaskStatusInProgress,
+			lastState:  issueStateOpen,
+			issue:      Issue{Title: "Task", State: issueStateOpen},
+			expected:   SyncDecision{},
+		},
+		{
+			name:       "Title is pushed",
+			taskStatus: models.TaskStatusPending,
+			lastState:  issueStateOpen,
+			issue:      Issue{Title: "Old title", State: issueStateOpen},
+			expected:   SyncDecision{IssueTitle: "Task"},
+		},
+		{
+			name:       "Issue closed on GitHub completes the task",
+			taskStatus: models.TaskStatusInProgress,
+			lastState:  issueStateOpen,
+			issue:      Issue{Title: "Task", State: issueStateClosed, StateReason: stateReasonCompleted},
+			expected:   SyncDecision{TaskStatus: models.TaskStatusCompleted},
+		},
+		{
+			name:       "Issue closed as not planned cancels the task",
+			taskStatus: models.TaskStatusPending,
+			lastState:  issueStateOpen,
+			issue:      Issue{Title: "Task", State: issueStateClosed, StateReason: stateReasonNotPlanned},
+			expected:   SyncDecision{TaskStatus: models.TaskStatusCancelled},
+		},
+		{
+			name:       "Issue reopened on GitHub reopens the task",
+			taskStatus: models.TaskStatusCompleted,
+			lastState:  issueStateClosed,
+			issue:      Issue{Title: "Task", State: issueStateOpen},
+			expected:   SyncDecision{TaskStatus: models.TaskStatusPending},
+		},
+		{
+			name:       "Completed task closes the issue",
+			taskStatus: models.TaskStatusCompleted,
+			lastState:  issueStateOpen,
+			issue:      Issue{Title: "Task", State: issueStateOpen},
+			expected:   SyncDecision{IssueState: issueStateClosed, StateReason: stateReasonCompleted},
+		},
+		{
+			name:       "Cancelled task closes the issue as not planned",
+			taskStatus: models.TaskStatusCancelled,
+			lastState:  issueStateOpen,
+			issue:      Issue{Title: "Task", State: issueStateOpen},
+			expected:   SyncDecision{IssueState: issueStateClosed, StateReason: stateReasonNotPlanned},
+		},
+		{
+			name:       "Reopened task reopens the issue",
+			taskStatus: models.TaskStatusPending,
+			lastState:  issueStateClosed,
+			issue:      Issue{Title: "Task", State: issueStateClosed},
+			expected:   SyncDecision{IssueState: issueStateOpen},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := models.NewTask("task-1", "plan-1", "Task", "", models.TaskPriorityMedium)
+			task.Status = tt.taskStatus
+			task.Metadata = map[string]string{MetadataState: tt.lastState}
+
+			decision := DecideSync(task, &tt.issue)
+			if decision != tt.expected {
+				t.Errorf("DecideSync() = %+v, expected %+v", decision, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTaskInputFromIssue(t *testing.T) {
+	issue := &Issue{
+		Title:  "Fix login",
+		Body:   "  Steps to reproduce  \n",
+		State:  issueStateClosed,
+		Labels: []Label{{Name: "bug"}, {Name: "Priority: High"}},
+	}
+
+	input := TaskInputFromIssue(issue)
+
+	expected := storage.TaskCreateInput{
+		Title:       "Fix login",
+		Description: "Steps to reproduce",
+		Status:      models.TaskStatusCompleted,
+		Priority:    models.TaskPriorityHigh,
+	}
+	if input != expected {
+		t.Errorf("TaskInputFromIssue() = %+v, expected %+v", input, expected)
+	}
+
+	input = TaskInputFromIssue(&Issue{Title: "Idea", State: issueStateOpen, Labels: []Label{{Name: "priority/low"}}})
+	if input.Status != models.TaskStatusPending || input.Priority != models.TaskPriorityLow {
+		t.Errorf("unexpected status or priority: %+v", input)
+	}
+}
+
+func TestIssueBody(t *testing.T) {
+	plan := models.NewPlan("plan-1", "app-1", "Release", "")
+	task := models.NewTask("task-1", plan.ID, "Task", "Do the thing", models.TaskPriorityMedium)
+
+	body := IssueBody(plan, task)
+	if !strings.HasPrefix(body, "Do the thing\n\n---\n") || !strings.Contains(body, "task `task-1`") {
+		t.Errorf("unexpected issue body: %q", body)
+	}
+
+	task.Description = storage.DefaultTaskDescription
+	if body := IssueBody(plan, task); strings.Contains(body, storage.DefaultTaskDescription) {
+		t.Errorf("expected the placeholder description to be left out, got %q", body)
+	}
+}
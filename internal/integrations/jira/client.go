@@ -0,0 +1,178 @@
+// Package jira imports Jira issues found by a JQL query as tasks of a plan and pushes task status changes
+// back to Jira as workflow transitions. How Jira fields, statuses and priorities map to tasks is configurable.
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// searchPageSize is the page size used when searching issues
+	searchPageSize = 50
+	// DefaultMaxResults is how many issues an import reads when no limit is given
+	DefaultMaxResults = 200
+	// requestTimeout bounds a single Jira API request
+	requestTimeout = 30 * time.Second
+)
+
+// Issue is a Jira issue with its raw fields
+type Issue struct {
+	Key    string                     `json:"key"`
+	Fields map[string]json.RawMessage `json:"fields"`
+}
+
+// Transition is a workflow transition available for an issue
+type Transition struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	To   struct {
+		Name string `json:"name"`
+	} `json:"to"`
+}
+
+// Client is a minimal client for the Jira REST API version 2
+type Client struct {
+	baseURL string
+	email   string
+	token   string
+	http    *http.Client
+}
+
+// NewClient creates a Jira API client. With an email, the token is sent as a Jira Cloud API token using basic
+// authentication; without one it is sent as a bearer personal access token, as used by Jira Data Center.
+func NewClient(baseURL, email, token string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		email:   email,
+		token:   token,
+		http:    &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// BrowseURL returns the web URL of an issue
+func (c *Client) BrowseURL(key string) string {
+	return c.baseURL + "/browse/" + key
+}
+
+// Search returns up to maxResults issues matching a JQL query, with the given fields
+func (c *Client) Search(ctx context.Context, jql string, fields []string, maxResults int) ([]*Issue, error) {
+	var issues []*Issue
+	for len(issues) < maxResults {
+		query := url.Values{}
+		query.Set("jql", jql)
+		query.Set("fields", strings.Join(fields, ","))
+		query.Set("startAt", strconv.Itoa(len(issues)))
+		query.Set("maxResults", strconv.Itoa(min(searchPageSize, maxResults-len(issues))))
+
+		var page struct {
+			Issues []*Issue `json:"issues"`
+			Total  int      `json:"total"`
+		}
+		if err := c.do(ctx, http.MethodGet, "/rest/api/2/search?"+query.Encode(), nil, &page); err != nil {
+			return nil, err
+		}
+
+		issues = append(issues, page.Issues...)
+		if len(page.Issues) == 0 || len(issues) >= page.Total {
+			break
+		}
+	}
+
+	return issues, nil
+}
+
+// GetIssue returns an issue with the given fields
+func (c *Client) GetIssue(ctx context.Context, key string, fields []string) (*Issue, error) {
+	query := url.Values{}
+	query.Set("fields", strings.Join(fields, ","))
+
+	var issue Issue
+	if err := c.do(ctx, http.MethodGet, "/rest/api/2/issue/"+url.PathEscape(key)+"?"+query.Encode(), nil, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// Transitions returns the workflow transitions currently available for an issue
+func (c *Client) Transitions(ctx context.Context, key string) ([]Transition, error) {
+	var resp struct {
+		Transitions []Transition `json:"transitions"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/rest/api/2/issue/"+url.PathEscape(key)+"/transitions", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Transitions, nil
+}
+
+// DoTransition moves an issue through a workflow transition
+func (c *Client) DoTransition(ctx context.Context, key, transitionID string) error {
+	body := map[string]any{"transition": map[string]string{"id": transitionID}}
+	return c.do(ctx, http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(key)+"/transitions", body, nil)
+}
+
+// do sends a request to the Jira API and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode Jira request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create Jira request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.email != "" {
+		req.SetBasicAuth(c.email, c.token)
+	} else if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("request %s %s to Jira failed: %w", method, path, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		var apiErr struct {
+			ErrorMessages []string          `json:"errorMessages"`
+			Errors        map[string]string `json:"errors"`
+		}
+		message := http.StatusText(resp.StatusCode)
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err == nil {
+			messages := apiErr.ErrorMessages
+			for field, msg := range apiErr.Errors {
+				messages = append(messages, field+": "+msg)
+			}
+			if len(messages) > 0 {
+				message = strings.Join(messages, "; ")
+			}
+		}
+		return fmt.Errorf("request %s %s to Jira failed with status %d: %s", method, path, resp.StatusCode, message)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode Jira response: %w", err)
+	}
+	return nil
+}
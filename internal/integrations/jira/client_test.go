@@ -0,0 +1,68 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestClientSearch(t *testing.T) {
+	const total = 120
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, token, ok := r.BasicAuth(); !ok || user != "me@example.com" || token != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		query := r.URL.Query()
+		if r.URL.Path != "/rest/api/2/search" || query.Get("jql") != "project = WEB" || query.Get("fields") != "summary,status" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+
+		startAt, _ := strconv.Atoi(query.Get("startAt"))
+		maxResults, _ := strconv.Atoi(query.Get("maxResults"))
+		issues := []map[string]any{}
+		for i := startAt; i < min(startAt+maxResults, total); i++ {
+			issues = append(issues, map[string]any{"key": fmt.Sprintf("WEB-%d", i+1), "fields": map[string]any{}})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"startAt": startAt, "total": total, "issues": issues})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", "me@example.com", "secret")
+	issues, err := client.Search(context.Background(), "project = WEB", []string{"summary", "status"}, 75)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	// The limit spans two pages and is respected
+	if len(issues) != 75 {
+		t.Fatalf("got %d issues, expected 75", len(issues))
+	}
+	if issues[0].Key != "WEB-1" || issues[74].Key != "WEB-75" {
+		t.Errorf("unexpected issues: first %s, last %s", issues[0].Key, issues[74].Key)
+	}
+	if got := client.BrowseURL("WEB-1"); got != server.URL+"/browse/WEB-1" {
+		t.Errorf("BrowseURL() = %q", got)
+	}
+}
+
+func TestClientReportsAPIErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer pat" {
+			t.Errorf("expected a bearer token, got %q", r.Header.Get("Authorization"))
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"errorMessages": ["The value 'NOPE' does not exist for the field 'project'."]}`))
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL, "", "pat").Search(context.Background(), "project = NOPE", []string{"summary"}, 10)
+	if err == nil || !strings.Contains(err.Error(), "400") || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected a bad request error, got %v", err)
+	}
+}
@@ -0,0 +1,272 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// Metadata keys that link a task to its Jira issue
+const (
+	MetadataKey = "jira.key"
+	MetadataURL = "jira.url"
+	// MetadataStatus is the Jira status seen at the last import or push
+	MetadataStatus = "jira.status"
+)
+
+// Connector imports Jira issues as tasks and pushes task statuses back to Jira
+type Connector struct {
+	planRepo storage.PlanRepositoryInterface
+	taskRepo storage.TaskRepositoryInterface
+	client   *Client
+	mapping  FieldMapping
+}
+
+// NewConnector creates a new Jira connector
+func NewConnector(
+	planRepo storage.PlanRepositoryInterface,
+	taskRepo storage.TaskRepositoryInterface,
+	client *Client,
+	mapping FieldMapping,
+) *Connector {
+	return &Connector{
+		planRepo: planRepo,
+		taskRepo: taskRepo,
+		client:   client,
+		mapping:  mapping,
+	}
+}
+
+// LinkedTask describes a task and the issue it is linked to
+type LinkedTask struct {
+	TaskID     string            `json:"task_id"`
+	Title      string            `json:"title"`
+	Status     models.TaskStatus `json:"status"`
+	IssueKey   string            `json:"issue_key"`
+	IssueURL   string            `json:"issue_url"`
+	JiraStatus string            `json:"jira_status"`
+}
+
+// LinkError describes a task or issue that could not be imported or pushed
+type LinkError struct {
+	TaskID   string `json:"task_id,omitempty"`
+	IssueKey string `json:"issue_key,omitempty"`
+	Error    string `json:"error"`
+}
+
+// ImportReport lists the tasks created from Jira issues
+type ImportReport struct {
+	PlanID   string       `json:"plan_id"`
+	Imported []LinkedTask `json:"imported"`
+	// AlreadyLinked lists the keys of issues that are already linked to a task of the plan
+	AlreadyLinked []string    `json:"already_linked"`
+	Errors        []LinkError `json:"errors,omitempty"`
+}
+
+// PushReport lists the issues moved to match the status of their task
+type PushReport struct {
+	PlanID       string       `json:"plan_id"`
+	Transitioned []LinkedTask `json:"transitioned"`
+	// Unchanged counts linked issues whose status already maps to their task's status
+	Unchanged int         `json:"unchanged"`
+	Errors    []LinkError `json:"errors,omitempty"`
+}
+
+// ImportIssues creates tasks in a plan from up to maxResults issues matching a JQL query and links them.
+// Issues already linked to a task of the plan are skipped.
+func (c *Connector) ImportIssues(ctx context.Context, planID, jql string, maxResults int) (*ImportReport, error) {
+	if strings.TrimSpace(jql) == "" {
+		return nil, fmt.Errorf("jql is required")
+	}
+	if maxResults <= 0 {
+		maxResults = DefaultMaxResults
+	}
+
+	tasks, err := c.taskRepo.ListByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	linked := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		if key := task.Metadata[MetadataKey]; key != "" {
+			linked[strings.ToUpper(key)] = true
+		}
+	}
+
+	issues, err := c.client.Search(ctx, jql, c.mapping.Fields(), maxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{
+		PlanID:        planID,
+		Imported:      []LinkedTask{},
+		AlreadyLinked: []string{},
+	}
+
+	var newIssues []*Issue
+	var inputs []storage.TaskCreateInput
+	for _, issue := range issues {
+		if linked[strings.ToUpper(issue.Key)] {
+			report.AlreadyLinked = append(report.AlreadyLinked, issue.Key)
+			continue
+		}
+		input := c.mapping.TaskInput(issue)
+		if input.Title == "" {
+			report.Errors = append(report.Errors, LinkError{
+				IssueKey: issue.Key,
+				Error:    fmt.Sprintf("field %q is empty", c.mapping.TitleField),
+			})
+			continue
+		}
+		newIssues = append(newIssues, issue)
+		inputs = append(inputs, input)
+	}
+
+	if len(inputs) == 0 {
+		return report, nil
+	}
+
+	created, err := c.taskRepo.CreateBulk(ctx, planID, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, task := range created {
+		issue := newIssues[i]
+		linkedTask, err := c.taskRepo.SetMetadata(ctx, task.ID, c.linkMetadata(issue.Key, StatusName(issue)))
+		if err != nil {
+			report.Errors = append(report.Errors, LinkError{TaskID: task.ID, IssueKey: issue.Key, Error: err.Error()})
+			continue
+		}
+		report.Imported = append(report.Imported, *linkedTaskOf(linkedTask))
+	}
+
+	return report, nil
+}
+
+// PushStatuses moves the linked issues of a plan through their workflow until their status maps to the
+// status of their task. The transition is chosen by the mapping's target statuses for the task status,
+// matching either the transition name or the status it leads to.
+func (c *Connector) PushStatuses(ctx context.Context, planID string) (*PushReport, error) {
+	if _, err := c.planRepo.Get(ctx, planID); err != nil {
+		return nil, err
+	}
+
+	tasks, err := c.taskRepo.ListByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &PushReport{
+		PlanID:       planID,
+		Transitioned: []LinkedTask{},
+	}
+
+	for _, task := range tasks {
+		key := task.Metadata[MetadataKey]
+		if key == "" {
+			continue
+		}
+
+		transitioned, err := c.pushStatus(ctx, task, key)
+		if err != nil {
+			report.Errors = append(report.Errors, LinkError{TaskID: task.ID, IssueKey: key, Error: err.Error()})
+			continue
+		}
+		if transitioned == nil {
+			report.Unchanged++
+			continue
+		}
+		report.Transitioned = append(report.Transitioned, *transitioned)
+	}
+
+	return report, nil
+}
+
+// pushStatus transitions the issue of a task if its status does not map to the task status.
+// It returns nil when the issue was already in a matching status.
+func (c *Connector) pushStatus(ctx context.Context, task *models.Task, key string) (*LinkedTask, error) {
+	issue, err := c.client.GetIssue(ctx, key, []string{"status"})
+	if err != nil {
+		return nil, err
+	}
+
+	if c.mapping.TaskStatus(issue) == task.Status {
+		if status := StatusName(issue); task.Metadata[MetadataStatus] != status {
+			if _, err := c.taskRepo.SetMetadata(ctx, task.ID, c.linkMetadata(key, status)); err != nil {
+				return nil, fmt.Errorf("failed to record the status of %s: %w", key, err)
+			}
+		}
+		return nil, nil
+	}
+
+	transitions, err := c.client.Transitions(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := c.mapping.Transitions[task.Status]
+	transition := ChooseTransition(transitions, targets)
+	if transition == nil {
+		return nil, fmt.Errorf("no available transition of %s leads to any of %s", key, strings.Join(targets, ", "))
+	}
+
+	if err := c.client.DoTransition(ctx, key, transition.ID); err != nil {
+		return nil, err
+	}
+
+	status := transition.To.Name
+	if status == "" {
+		status = transition.Name
+	}
+	task, err = c.taskRepo.SetMetadata(ctx, task.ID, c.linkMetadata(key, status))
+	if err != nil {
+		return nil, fmt.Errorf("transitioned %s but failed to record its status: %w", key, err)
+	}
+
+	return linkedTaskOf(task), nil
+}
+
+// ChooseTransition returns the first transition, in order of the target names, whose destination status or
+// name matches a target case-insensitively, or nil if none does
+func ChooseTransition(transitions []Transition, targets []string) *Transition {
+	for _, target := range targets {
+		for i := range transitions {
+			if strings.EqualFold(transitions[i].To.Name, target) {
+				return &transitions[i]
+			}
+		}
+		for i := range transitions {
+			if strings.EqualFold(transitions[i].Name, target) {
+				return &transitions[i]
+			}
+		}
+	}
+	return nil
+}
+
+// linkMetadata returns the metadata entries that link a task to an issue
+func (c *Connector) linkMetadata(key, status string) map[string]string {
+	return map[string]string{
+		MetadataKey:    key,
+		MetadataURL:    c.client.BrowseURL(key),
+		MetadataStatus: status,
+	}
+}
+
+// linkedTaskOf describes a linked task for reports
+func linkedTaskOf(task *models.Task) *LinkedTask {
+	return &LinkedTask{
+		TaskID:     task.ID,
+		Title:      task.Title,
+		Status:     task.Status,
+		IssueKey:   task.Metadata[MetadataKey],
+		IssueURL:   task.Metadata[MetadataURL],
+		JiraStatus: task.Metadata[MetadataStatus],
+	}
+}
@@ -0,0 +1,217 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// FieldMapping configures how Jira issues map to tasks and how task statuses map back to Jira.
+// Status, priority and transition names are matched case-insensitively.
+type FieldMapping struct {
+	// TitleField is the Jira field used as the task title
+	TitleField string `json:"title_field"`
+	// DescriptionField is the Jira field used as the task description
+	DescriptionField string `json:"description_field"`
+	// PriorityField is the Jira field whose value is looked up in Priorities
+	PriorityField string `json:"priority_field"`
+	// Statuses maps Jira status names to task statuses
+	Statuses map[string]models.TaskStatus `json:"statuses"`
+	// Priorities maps Jira priority or option names to task priorities
+	Priorities map[string]models.TaskPriority `json:"priorities"`
+	// Transitions maps task statuses to the Jira statuses or transition names to move an issue to,
+	// in order of preference
+	Transitions map[models.TaskStatus][]string `json:"transitions"`
+}
+
+// DefaultFieldMapping returns a mapping for the default Jira workflows and priority scheme
+func DefaultFieldMapping() FieldMapping {
+	return FieldMapping{
+		TitleField:       "summary",
+		DescriptionField: "description",
+		PriorityField:    "priority",
+		Statuses: map[string]models.TaskStatus{
+			"backlog":                  models.TaskStatusPending,
+			"to do":                    models.TaskStatusPending,
+			"open":                     models.TaskStatusPending,
+			"selected for development": models.TaskStatusPending,
+			"reopened":                 models.TaskStatusPending,
+			"in progress":              models.TaskStatusInProgress,
+			"in review":                models.TaskStatusInProgress,
+			"done":                     models.TaskStatusCompleted,
+			"closed":                   models.TaskStatusCompleted,
+			"resolved":                 models.TaskStatusCompleted,
+			"won't do":                 models.TaskStatusCancelled,
+			"cancelled":                models.TaskStatusCancelled,
+			"canceled":                 models.TaskStatusCancelled,
+			"rejected":                 models.TaskStatusCancelled,
+		},
+		Priorities: map[string]models.TaskPriority{
+			"highest": models.TaskPriorityHigh,
+			"high":    models.TaskPriorityHigh,
+			"medium":  models.TaskPriorityMedium,
+			"low":     models.TaskPriorityLow,
+			"lowest":  models.TaskPriorityLow,
+		},
+		Transitions: map[models.TaskStatus][]string{
+			models.TaskStatusPending:    {"To Do", "Open", "Backlog", "Reopened"},
+			models.TaskStatusInProgress: {"In Progress"},
+			models.TaskStatusCompleted:  {"Done", "Closed", "Resolved"},
+			models.TaskStatusCancelled:  {"Won't Do", "Cancelled", "Canceled", "Rejected"},
+		},
+	}
+}
+
+// LoadFieldMapping reads a JSON mapping file and merges it over the default mapping.
+// Fields left out of the file keep their defaults, and map entries are added to or replace the default entries.
+func LoadFieldMapping(path string) (FieldMapping, error) {
+	mapping := DefaultFieldMapping()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return mapping, fmt.Errorf("failed to read Jira field mapping: %w", err)
+	}
+
+	var override FieldMapping
+	if err := json.Unmarshal(data, &override); err != nil {
+		return mapping, fmt.Errorf("failed to parse Jira field mapping %s: %w", path, err)
+	}
+
+	if err := mapping.merge(override); err != nil {
+		return mapping, fmt.Errorf("invalid Jira field mapping %s: %w", path, err)
+	}
+	return mapping, nil
+}
+
+// merge applies the non-empty parts of another mapping, validating its statuses and priorities
+func (m *FieldMapping) merge(other FieldMapping) error {
+	if other.TitleField != "" {
+		m.TitleField = other.TitleField
+	}
+	if other.DescriptionField != "" {
+		m.DescriptionField = other.DescriptionField
+	}
+	if other.PriorityField != "" {
+		m.PriorityField = other.PriorityField
+	}
+
+	for name, status := range other.Statuses {
+		if !validStatus(status) {
+			return fmt.Errorf("status %q maps to invalid task status %q", name, status)
+		}
+		m.Statuses[strings.ToLower(name)] = status
+	}
+	for name, priority := range other.Priorities {
+		if !models.IsValidTaskPriority(priority) {
+			return fmt.Errorf("priority %q maps to invalid task priority %q", name, priority)
+		}
+		m.Priorities[strings.ToLower(name)] = priority
+	}
+	for status, targets := range other.Transitions {
+		if !validStatus(status) {
+			return fmt.Errorf("transitions are configured for invalid task status %q", status)
+		}
+		m.Transitions[status] = targets
+	}
+
+	return nil
+}
+
+// Fields returns the Jira fields needed to build tasks
+func (m FieldMapping) Fields() []string {
+	return []string{m.TitleField, m.DescriptionField, m.PriorityField, "status"}
+}
+
+// TaskInput maps an issue to a new task
+func (m FieldMapping) TaskInput(issue *Issue) storage.TaskCreateInput {
+	return storage.TaskCreateInput{
+		Title:       strings.TrimSpace(m.Title(issue)),
+		Description: m.Description(issue),
+		Status:      m.TaskStatus(issue),
+		Priority:    m.TaskPriority(issue),
+	}
+}
+
+// TaskStatus maps the status of an issue to a task status.
+// Statuses that are not mapped fall back to the issue's status category.
+func (m FieldMapping) TaskStatus(issue *Issue) models.TaskStatus {
+	var status struct {
+		Name           string `json:"name"`
+		StatusCategory struct {
+			Key string `json:"key"`
+		} `json:"statusCategory"`
+	}
+	if raw, ok := issue.Fields["status"]; ok {
+		if err := json.Unmarshal(raw, &status); err != nil {
+			return models.TaskStatusPending
+		}
+	}
+
+	if mapped, ok := m.Statuses[strings.ToLower(status.Name)]; ok {
+		return mapped
+	}
+
+	switch status.StatusCategory.Key {
+	case "indeterminate":
+		return models.TaskStatusInProgress
+	case "done":
+		return models.TaskStatusCompleted
+	default:
+		return models.TaskStatusPending
+	}
+}
+
+// StatusName returns the name of the Jira status of an issue
+func StatusName(issue *Issue) string {
+	return fieldText(issue.Fields["status"])
+}
+
+// TaskPriority maps the priority field of an issue to a task priority, or returns "" if it is not mapped
+func (m FieldMapping) TaskPriority(issue *Issue) models.TaskPriority {
+	return m.Priorities[strings.ToLower(fieldText(issue.Fields[m.PriorityField]))]
+}
+
+// Title returns the task title of an issue
+func (m FieldMapping) Title(issue *Issue) string {
+	return fieldText(issue.Fields[m.TitleField])
+}
+
+// Description returns the task description of an issue
+func (m FieldMapping) Description(issue *Issue) string {
+	return strings.TrimSpace(fieldText(issue.Fields[m.DescriptionField]))
+}
+
+// fieldText returns the text of a Jira field value: a string as is, or the name or value of an object
+// such as a priority, status or select option
+func fieldText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+
+	var object struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &object); err == nil {
+		if object.Name != "" {
+			return object.Name
+		}
+		return object.Value
+	}
+
+	return ""
+}
+
+// validStatus reports whether a value is a task status enabled for the deployment
+func validStatus(status models.TaskStatus) bool {
+	return models.IsValidTaskStatus(status)
+}
@@ -0,0 +1,170 @@
+package jira
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// testIssue builds an issue from field values encoded as JSON
+func testIssue(t *testing.T, fields map[string]any) *Issue {
+	t.Helper()
+	issue := &Issue{Key: "WEB-1", Fields: map[string]json.RawMessage{}}
+	for name, value := range fields {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("failed to encode field %s: %v", name, err)
+		}
+		issue.Fields[name] = raw
+	}
+	return issue
+}
+
+func TestFieldMappingTaskStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   map[string]any
+		expected models.TaskStatus
+	}{
+		{
+			name:     "mapped name",
+			status:   map[string]any{"name": "In Review"},
+			expected: models.TaskStatusInProgress,
+		},
+		{
+			name:     "name is matched case-insensitively",
+			status:   map[string]any{"name": "WON'T DO"},
+			expected: models.TaskStatusCancelled,
+		},
+		{
+			name:     "unmapped name falls back to the done category",
+			status:   map[string]any{"name": "Shipped", "statusCategory": map[string]any{"key": "done"}},
+			expected: models.TaskStatusCompleted,
+		},
+		{
+			name:     "unmapped name falls back to the in progress category",
+			status:   map[string]any{"name": "Testing", "statusCategory": map[string]any{"key": "indeterminate"}},
+			expected: models.TaskStatusInProgress,
+		},
+		{
+			name:     "unknown status is pending",
+			status:   map[string]any{"name": "Triage"},
+			expected: models.TaskStatusPending,
+		},
+	}
+
+	mapping := DefaultFieldMapping()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issue := testIssue(t, map[string]any{"status": tt.status})
+			if got := mapping.TaskStatus(issue); got != tt.expected {
+				t.Errorf("TaskStatus() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFieldMappingTaskInput(t *testing.T) {
+	mapping := DefaultFieldMapping()
+	mapping.DescriptionField = "customfield_10001"
+	mapping.PriorityField = "customfield_10002"
+
+	issue := testIssue(t, map[string]any{
+		"summary":           "  Fix login  ",
+		"customfield_10001": "Users cannot log in\n",
+		"customfield_10002": map[string]any{"value": "Highest", "id": "1"},
+		"status":            map[string]any{"name": "Done"},
+	})
+
+	input := mapping.TaskInput(issue)
+	if input.Title != "Fix login" {
+		t.Errorf("Title = %q, expected %q", input.Title, "Fix login")
+	}
+	if input.Description != "Users cannot log in" {
+		t.Errorf("Description = %q, expected %q", input.Description, "Users cannot log in")
+	}
+	if input.Priority != models.TaskPriorityHigh {
+		t.Errorf("Priority = %q, expected %q", input.Priority, models.TaskPriorityHigh)
+	}
+	if input.Status != models.TaskStatusCompleted {
+		t.Errorf("Status = %q, expected %q", input.Status, models.TaskStatusCompleted)
+	}
+}
+
+func TestLoadFieldMapping(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "mapping.json")
+	content := `{
+		"description_field": "environment",
+		"statuses": {"Ready for QA": "in_progress"},
+		"priorities": {"P1": "high"},
+		"transitions": {"completed": ["Ship it"]}
+	}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	mapping, err := LoadFieldMapping(path)
+	if err != nil {
+		t.Fatalf("LoadFieldMapping() error = %v", err)
+	}
+	if mapping.TitleField != "summary" || mapping.DescriptionField != "environment" {
+		t.Errorf("unexpected fields: title %q, description %q", mapping.TitleField, mapping.DescriptionField)
+	}
+	if mapping.Statuses["ready for qa"] != models.TaskStatusInProgress || mapping.Statuses["done"] != models.TaskStatusCompleted {
+		t.Errorf("statuses were not merged over the defaults: %v", mapping.Statuses)
+	}
+	if mapping.Priorities["p1"] != models.TaskPriorityHigh {
+		t.Errorf("priorities were not merged over the defaults: %v", mapping.Priorities)
+	}
+	if got := mapping.Transitions[models.TaskStatusCompleted]; len(got) != 1 || got[0] != "Ship it" {
+		t.Errorf("Transitions[completed] = %v, expected [Ship it]", got)
+	}
+
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"statuses": {"Done": "finished"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFieldMapping(invalid); err == nil {
+		t.Error("expected an error for an invalid task status")
+	}
+}
+
+func TestChooseTransition(t *testing.T) {
+	transitions := []Transition{
+		{ID: "11", Name: "Start work"},
+		{ID: "21", Name: "Resolve"},
+		{ID: "31", Name: "Won't Do"},
+	}
+	transitions[0].To.Name = "In Progress"
+	transitions[1].To.Name = "Done"
+	transitions[2].To.Name = "Closed"
+
+	tests := []struct {
+		name     string
+		targets  []string
+		expected string
+	}{
+		{name: "matches the destination status", targets: []string{"in progress"}, expected: "11"},
+		{name: "matches the transition name", targets: []string{"Won't Do"}, expected: "31"},
+		{name: "prefers earlier targets", targets: []string{"Done", "Closed"}, expected: "21"},
+		{name: "no match", targets: []string{"To Do"}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ChooseTransition(transitions, tt.targets)
+			id := ""
+			if got != nil {
+				id = got.ID
+			}
+			if id != tt.expected {
+				t.Errorf("ChooseTransition() = %q, expected %q", id, tt.expected)
+			}
+		})
+	}
+}
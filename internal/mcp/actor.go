@@ -0,0 +1,20 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// actorMiddleware attributes the changes made by a tool call to the MCP session that issued it
+func actorMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if session := server.ClientSessionFromContext(ctx); session != nil && session.SessionID() != "" {
+			ctx = storage.WithActor(ctx, "session:"+session.SessionID())
+		}
+		return next(ctx, request)
+	}
+}
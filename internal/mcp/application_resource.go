@@ -0,0 +1,82 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
+)
+
+// Pattern for application summary: ai-tasks://applications/{app_id}/summary
+var appSummaryPattern = regexp.MustCompile(`ai-tasks://applications/([^/]+)/summary$`)
+
+// ApplicationResourceProvider implements the MCP resource provider for application level views
+type ApplicationResourceProvider struct {
+	planStats *services.PlanStatsService
+}
+
+// NewApplicationResourceProvider creates a new ApplicationResourceProvider
+func NewApplicationResourceProvider(planStats *services.PlanStatsService) *ApplicationResourceProvider {
+	return &ApplicationResourceProvider{
+		planStats: planStats,
+	}
+}
+
+// RegisterResource registers the application resources with the MCP server
+func (p *ApplicationResourceProvider) RegisterResource(server *MCPGoServer) {
+	// Create a resource template for the application portfolio summary
+	summaryTemplate := mcp.NewResourceTemplate(
+		"ai-tasks://applications/{app_id}/summary",
+		"Application Summary Resource",
+		mcp.WithTemplateDescription(
+			"Returns an aggregated view of all plans for an application: its rolled up status, per-plan "+
+				"progress, open tasks, status breakdown and recently updated items",
+		),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+
+	server.addResourceTemplate(summaryTemplate, p.handleSummaryRequest)
+}
+
+// handleSummaryRequest handles requests for the application summary resource
+func (p *ApplicationResourceProvider) handleSummaryRequest(
+	ctx context.Context,
+	req mcp.ReadResourceRequest,
+) ([]mcp.ResourceContents, error) {
+	matches := appSummaryPattern.FindStringSubmatch(req.Params.URI)
+	if len(matches) != 2 {
+		return nil, fmt.Errorf(
+			"%w: '%s' does not match the expected format 'ai-tasks://applications/{app_id}/summary'",
+			ErrInvalidURI,
+			req.Params.URI,
+		)
+	}
+
+	appID := matches[1]
+	if strings.TrimSpace(appID) == "" {
+		return nil, fmt.Errorf("%w: empty application ID", ErrInvalidAppID)
+	}
+
+	summary, err := p.planStats.GetApplicationSummary(ctx, appID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to summarize application '%s': %v", ErrInternalStorage, appID, err)
+	}
+
+	jsonData, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to marshal application summary: %v", ErrMarshalFailure, err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      fmt.Sprintf("ai-tasks://applications/%s/summary", appID),
+			MIMEType: "application/json",
+			Text:     string(jsonData),
+		},
+	}, nil
+}
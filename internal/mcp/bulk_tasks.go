@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// bulkTaskDefinitions returns the task definitions of a bulk_create_tasks call, passed either as a native
+// array in tasks or as a JSON encoded array in tasks_json
+func bulkTaskDefinitions(request mcp.CallToolRequest) ([]any, error) {
+	arguments := request.GetArguments()
+	tasks, hasTasks := arguments["tasks"]
+	hasTasks = hasTasks && tasks != nil
+	tasksJSON, hasTasksJSON := arguments["tasks_json"]
+	hasTasksJSON = hasTasksJSON && tasksJSON != nil
+
+	switch {
+	case hasTasks && hasTasksJSON:
+		return nil, errors.New("provide either tasks or tasks_json, not both")
+	case hasTasks:
+		definitions, ok := tasks.([]any)
+		if !ok {
+			return nil, errors.New("tasks must be an array of task definitions")
+		}
+		return definitions, nil
+	case hasTasksJSON:
+		str, ok := tasksJSON.(string)
+		if !ok {
+			return nil, errors.New("tasks_json must be a string")
+		}
+		var definitions []any
+		if err := json.Unmarshal([]byte(str), &definitions); err != nil {
+			return nil, fmt.Errorf("failed to parse tasks JSON: %v", err)
+		}
+		return definitions, nil
+	default:
+		return nil, errors.New("either tasks or tasks_json is required")
+	}
+}
+
+// parseBulkTasks converts task definitions into the inputs for creating the tasks
+func parseBulkTasks(definitions []any) ([]storage.TaskCreateInput, error) {
+	taskInputs := make([]storage.TaskCreateInput, 0, len(definitions))
+	for _, definition := range definitions {
+		taskMap, ok := definition.(map[string]any)
+		if !ok {
+			return nil, errors.New("each task definition must be an object")
+		}
+
+		// Extract title (required)
+		titleRaw, ok := taskMap["title"]
+		if !ok {
+			return nil, errors.New("task title is required")
+		}
+
+		title, ok := titleRaw.(string)
+		if !ok || title == "" {
+			return nil, errors.New("task title must be a non-empty string")
+		}
+
+		// Extract optional fields
+		description := ""
+		if desc, ok := taskMap["description"].(string); ok {
+			description = desc
+		}
+
+		statusStr := ""
+		if status, ok := taskMap["status"].(string); ok {
+			statusStr = status
+		}
+
+		priorityStr := ""
+		if priority, ok := taskMap["priority"].(string); ok {
+			priorityStr = priority
+		}
+
+		if statusStr != "" && !models.IsValidTaskStatus(models.TaskStatus(statusStr)) {
+			return nil, fmt.Errorf("invalid status: %s", statusStr)
+		}
+		if priorityStr != "" && !models.IsValidTaskPriority(models.TaskPriority(priorityStr)) {
+			return nil, fmt.Errorf("invalid priority: %s", priorityStr)
+		}
+
+		estimate := 0.0
+		if estimateRaw, ok := taskMap["estimate"]; ok {
+			estimate, ok = estimateRaw.(float64)
+			if !ok {
+				return nil, errors.New("task estimate must be a number")
+			}
+			if err := models.ValidateEstimate(estimate); err != nil {
+				return nil, err
+			}
+		}
+
+		taskInputs = append(taskInputs, storage.TaskCreateInput{
+			Title:       title,
+			Description: description,
+			Status:      models.TaskStatus(statusStr),
+			Priority:    models.TaskPriority(priorityStr),
+			Estimate:    estimate,
+		})
+	}
+	return taskInputs, nil
+}
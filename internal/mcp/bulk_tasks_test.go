@@ -0,0 +1,135 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+func TestBulkTaskDefinitions(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments map[string]any
+		wantCount int
+		wantErr   string
+	}{
+		{"Native array", map[string]any{"tasks": []any{map[string]any{"title": "a"}}}, 1, ""},
+		{"JSON string", map[string]any{"tasks_json": `[{"title":"a"},{"title":"b"}]`}, 2, ""},
+		{"Null tasks with JSON string", map[string]any{"tasks": nil, "tasks_json": `[{"title":"a"}]`}, 1, ""},
+		{"Both", map[string]any{"tasks": []any{}, "tasks_json": `[]`}, 0, "not both"},
+		{"Neither", map[string]any{}, 0, "either tasks or tasks_json is required"},
+		{"Invalid JSON", map[string]any{"tasks_json": `[{`}, 0, "failed to parse tasks JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request mcp.CallToolRequest
+			request.Params.Arguments = tt.arguments
+
+			definitions, err := bulkTaskDefinitions(request)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(definitions) != tt.wantCount {
+				t.Errorf("expected %d definitions, got %d", tt.wantCount, len(definitions))
+			}
+		})
+	}
+}
+
+func TestBulkCreateTasksWithNativeArray(t *testing.T) {
+	s := newTestServer(t)
+
+	result := callTool(t, s, "create_plan", map[string]any{"application_id": "app", "name": "Plan"})
+	var plan models.Plan
+	if err := json.Unmarshal([]byte(toolResultText(result)), &plan); err != nil {
+		t.Fatalf("failed to parse plan: %v", err)
+	}
+
+	result = callTool(t, s, "bulk_create_tasks", map[string]any{
+		"plan_id": plan.ID,
+		"tasks": []any{
+			map[string]any{"title": "First", "priority": "high", "estimate": 2},
+			map[string]any{"title": "Second", "status": "in_progress"},
+		},
+	})
+	if result.IsError {
+		t.Fatalf("bulk_create_tasks failed: %s", toolResultText(result))
+	}
+	var tasks []models.Task
+	if err := json.Unmarshal([]byte(toolResultText(result)), &tasks); err != nil {
+		t.Fatalf("failed to parse tasks: %v", err)
+	}
+	if len(tasks) != 2 || tasks[0].Priority != models.TaskPriorityHigh || tasks[0].Estimate != 2 ||
+		tasks[1].Status != models.TaskStatusInProgress {
+		t.Errorf("unexpected tasks: %+v", tasks)
+	}
+
+	result = callTool(t, s, "bulk_create_tasks", map[string]any{
+		"plan_id": plan.ID,
+		"tasks":   []any{map[string]any{"title": "Third", "priority": "urgent"}},
+	})
+	if !result.IsError || !strings.Contains(toolResultText(result), "tasks[0].priority: must be one of") {
+		t.Errorf("expected a validation error, got %s", toolResultText(result))
+	}
+}
+
+// FuzzParseBulkTasks checks that malformed tasks_json from an agent is rejected with an error rather than a
+// panic, and that the tasks it accepts are valid
+func FuzzParseBulkTasks(f *testing.F) {
+	f.Add(`[{"title":"a"},{"title":"b","description":"d","status":"pending","priority":"high","estimate":2}]`)
+	f.Add(`[{"title":1}]`)
+	f.Add(`[{"title":"a","estimate":"soon"}]`)
+	f.Add(`[null,"a",[]]`)
+	f.Add(`{"title":"a"}`)
+	f.Add(`[{`)
+
+	f.Fuzz(func(t *testing.T, tasksJSON string) {
+		for _, arguments := range []map[string]any{
+			{"tasks_json": tasksJSON},
+			{"tasks": nativeArgument(tasksJSON)},
+		} {
+			var request mcp.CallToolRequest
+			request.Params.Arguments = arguments
+
+			definitions, err := bulkTaskDefinitions(request)
+			if err != nil {
+				continue
+			}
+			inputs, err := parseBulkTasks(definitions)
+			if err != nil {
+				continue
+			}
+			if len(inputs) != len(definitions) {
+				t.Fatalf("%d definitions gave %d tasks", len(definitions), len(inputs))
+			}
+			for _, input := range inputs {
+				if input.Title == "" {
+					t.Errorf("accepted a task without a title from %q", tasksJSON)
+				}
+				if err := models.ValidateEstimate(input.Estimate); err != nil {
+					t.Errorf("accepted an invalid estimate from %q: %v", tasksJSON, err)
+				}
+			}
+		}
+	})
+}
+
+// nativeArgument decodes JSON the way a tool argument is decoded, keeping the text when it is not JSON
+func nativeArgument(text string) any {
+	var value any
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return text
+	}
+	return value
+}
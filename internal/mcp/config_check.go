@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	settings "github.com/jbrinkman/valkey-ai-tasks/internal/config"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/i18n"
+)
+
+// positiveIntSettings are the numeric server settings that are ignored unless they are positive integers
+var positiveIntSettings = []string{
+	"SSE_KEEP_ALIVE_INTERVAL",
+	"STREAMABLE_HTTP_HEARTBEAT_INTERVAL",
+	"WEB_UI_POLL_INTERVAL",
+	"SERVER_READ_TIMEOUT",
+	"SERVER_WRITE_TIMEOUT",
+	"TOOL_TIMEOUT",
+	"RATE_LIMIT_BURST",
+	"RATE_LIMIT_EXPENSIVE_BURST",
+}
+
+// nonNegativeIntSettings are the numeric server settings that are ignored unless they are integers of at least 0
+var nonNegativeIntSettings = []string{
+	"MAX_RESOURCE_SIZE",
+}
+
+// rateSettings are the numeric server settings that are ignored unless they are non-negative numbers
+var rateSettings = []string{
+	"RATE_LIMIT_PER_SECOND",
+	"RATE_LIMIT_EXPENSIVE_PER_SECOND",
+}
+
+// pairFormats are the formats of the entries of the pair settings checked here
+var pairFormats = map[string]string{
+	"ROLES":       "role=group_or_tool ...",
+	"TOKEN_ROLES": "token=role",
+}
+
+// CheckServerConfig returns the problems with the server configuration in the environment and the config file.
+// The server falls back to defaults for invalid values and only fails on TLS problems when it starts, so this
+// reports what it would silently ignore as well.
+func CheckServerConfig() []string {
+	var problems []string
+	for _, name := range positiveIntSettings {
+		if val := settings.Get(name); val != "" {
+			if n, err := strconv.Atoi(val); err != nil || n <= 0 {
+				problems = append(problems, fmt.Sprintf("Invalid %s: %s, the default is used", name, val))
+			}
+		}
+	}
+	for _, name := range nonNegativeIntSettings {
+		if val := settings.Get(name); val != "" {
+			if n, err := strconv.Atoi(val); err != nil || n < 0 {
+				problems = append(problems, fmt.Sprintf("Invalid %s: %s, the default is used", name, val))
+			}
+		}
+	}
+	for _, name := range rateSettings {
+		if val := settings.Get(name); val != "" {
+			if rate, err := strconv.ParseFloat(val, 64); err != nil || rate < 0 {
+				problems = append(problems, fmt.Sprintf("Invalid %s: %s, the default is used", name, val))
+			}
+		}
+	}
+	if val := settings.Get("APPLICATION_TOKENS"); val != "" {
+		for _, pair := range strings.Split(val, ",") {
+			token, applicationID, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || strings.TrimSpace(token) == "" || strings.TrimSpace(applicationID) == "" {
+				problems = append(problems, "Invalid APPLICATION_TOKENS: entries must be token=application_id")
+				break
+			}
+		}
+	}
+
+	for _, name := range []string{"ROLES", "TOKEN_ROLES"} {
+		if val := settings.Get(name); val != "" {
+			for _, pair := range strings.Split(val, ",") {
+				key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok || strings.TrimSpace(key) == "" || strings.TrimSpace(value) == "" {
+					problems = append(problems, fmt.Sprintf("Invalid %s: entries must be %s", name, pairFormats[name]))
+					break
+				}
+			}
+		}
+	}
+
+	config := loadServerConfig()
+	for _, role := range slices.Compact(slices.Sorted(maps.Values(config.TokenRoles))) {
+		if _, ok := config.Roles[role]; !ok {
+			problems = append(problems, fmt.Sprintf("Invalid TOKEN_ROLES: role %s is not defined, its tokens may not call any tool", role))
+		}
+	}
+	if _, ok := config.Roles[config.DefaultRole]; config.DefaultRole != "" && !ok {
+		problems = append(problems, fmt.Sprintf("Invalid DEFAULT_ROLE: role %s is not defined, no tool may be called", config.DefaultRole))
+	}
+	if _, err := i18n.New(config.Language); err != nil {
+		problems = append(problems, fmt.Sprintf("Invalid LANG: %v, English is used", err))
+	}
+	if !config.EnableSSE && !config.EnableStreamableHTTP && !config.EnableWebSocket && !config.EnableSTDIO {
+		problems = append(problems, "No transport enabled: enable at least one of SSE, Streamable HTTP, WebSocket, or STDIO")
+	}
+	if config.TLSEnabled() {
+		if _, err := tlsConfig(config); err != nil {
+			problems = append(problems, fmt.Sprintf("Invalid TLS configuration: %v", err))
+		}
+	}
+	return problems
+}
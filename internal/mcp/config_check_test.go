@@ -0,0 +1,33 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckServerConfig(t *testing.T) {
+	if problems := CheckServerConfig(); len(problems) != 0 {
+		t.Fatalf("the default configuration has problems: %v", problems)
+	}
+
+	t.Setenv("ENABLE_SSE", "false")
+	t.Setenv("SERVER_READ_TIMEOUT", "soon")
+	t.Setenv("RATE_LIMIT_PER_SECOND", "-1")
+	t.Setenv("APPLICATION_TOKENS", "secret=app,broken")
+	t.Setenv("TOKEN_ROLES", "secret=auditor")
+	t.Setenv("TLS_CERT_FILE", "missing.pem")
+
+	problems := strings.Join(CheckServerConfig(), "\n")
+	for _, expected := range []string{
+		"SERVER_READ_TIMEOUT",
+		"RATE_LIMIT_PER_SECOND",
+		"APPLICATION_TOKENS",
+		"role auditor is not defined",
+		"No transport enabled",
+		"Invalid TLS configuration",
+	} {
+		if !strings.Contains(problems, expected) {
+			t.Errorf("problems do not mention %q:\n%s", expected, problems)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// Destructive tools can ask the user before they go ahead, using MCP elicitation where the client supports
+// it. A tool asks for a choice when a call is ambiguous, such as deleting a plan that still has open tasks,
+// and with CONFIRM_DESTRUCTIVE_TOOLS set it asks for a plain confirmation of every deletion. Clients without
+// elicitation are never asked, so those calls have to state their choice in the arguments.
+
+// errElicitationUnavailable is returned when the client of a call cannot be asked for input
+var errElicitationUnavailable = errors.New("the client does not support elicitation")
+
+// supportsElicitation reports whether the client of a call can be asked for input. Sessions that do not
+// report the capabilities of their client are asked, and fail if the client does not answer.
+func supportsElicitation(ctx context.Context) bool {
+	session := server.ClientSessionFromContext(ctx)
+	if _, ok := session.(server.SessionWithElicitation); !ok {
+		return false
+	}
+	if info, ok := session.(server.SessionWithClientInfo); ok {
+		return info.GetClientCapabilities().Elicitation != nil
+	}
+	return true
+}
+
+// cancelledError returns the error of a call the user declined to confirm
+func cancelledError(entity, id string) error {
+	return &models.Error{
+		Code:    models.ErrorCodeCancelled,
+		Message: "cancelled by the user",
+		Entity:  entity,
+		ID:      id,
+	}
+}
+
+// elicit asks the user of the session for values matching the properties of a JSON Schema, returning the
+// values the user entered. It returns errElicitationUnavailable when the client cannot be asked, and the
+// cancelled error of the entity when the user declines.
+func (s *MCPGoServer) elicit(
+	ctx context.Context, entity, id, message string, properties map[string]any, required ...string,
+) (map[string]any, error) {
+	if !supportsElicitation(ctx) {
+		return nil, errElicitationUnavailable
+	}
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	result, err := s.server.RequestElicitation(ctx, mcp.ElicitationRequest{
+		Params: mcp.ElicitationParams{Message: message, RequestedSchema: schema},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to ask for confirmation: %w", err)
+	}
+	if result.Action != mcp.ElicitationResponseActionAccept {
+		return nil, cancelledError(entity, id)
+	}
+
+	content, _ := result.Content.(map[string]any)
+	if content == nil {
+		content = make(map[string]any)
+	}
+	return content, nil
+}
+
+// confirm asks the user to confirm a destructive call when CONFIRM_DESTRUCTIVE_TOOLS is set and the client
+// supports elicitation. It returns the cancelled error of the entity unless the user confirms; otherwise the
+// call goes ahead without asking. The message is translated before the arguments are filled in.
+func (s *MCPGoServer) confirm(ctx context.Context, entity, id, format string, args ...any) error {
+	if !s.confirmDestructive.Load() {
+		return nil
+	}
+
+	content, err := s.elicit(ctx, entity, id, s.localizer.Sprintf(format, args...), map[string]any{
+		"confirm": map[string]any{
+			"type":        "boolean",
+			"title":       s.localizer.Translate("Confirm"),
+			"description": s.localizer.Translate("Set to true to go ahead"),
+		},
+	}, "confirm")
+	if errors.Is(err, errElicitationUnavailable) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if confirmed, ok := content["confirm"].(bool); !ok || !confirmed {
+		return cancelledError(entity, id)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// elicitingSession is a client session that answers elicitation requests with a fixed response, running
+// answering first if it is set
+type elicitingSession struct {
+	testSession
+	response  mcp.ElicitationResponse
+	requests  []mcp.ElicitationRequest
+	answering func()
+}
+
+func (s *elicitingSession) RequestElicitation(
+	_ context.Context, request mcp.ElicitationRequest,
+) (*mcp.ElicitationResult, error) {
+	s.requests = append(s.requests, request)
+	if s.answering != nil {
+		s.answering()
+	}
+	return &mcp.ElicitationResult{ElicitationResponse: s.response}, nil
+}
+
+// callElicitingTool calls a tool in a session that answers elicitation requests
+func callElicitingTool(t *testing.T, s *MCPGoServer, session *elicitingSession, name string, args map[string]any) string {
+	t.Helper()
+	message, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params":  map[string]any{"name": name, "arguments": args},
+	})
+	if err != nil {
+		t.Fatalf("failed to encode call: %v", err)
+	}
+	response, ok := s.server.HandleMessage(s.server.WithContext(context.Background(), session), message).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("%s failed", name)
+	}
+	result, ok := response.Result.(mcp.CallToolResult)
+	if !ok {
+		t.Fatalf("%s returned %T", name, response.Result)
+	}
+	return toolResultText(&result)
+}
+
+func TestDeletePlanWithOpenTasks(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	newPlan := func() *models.Plan {
+		plan, err := s.planRepo.Create(ctx, "app-1", "Plan", "")
+		if err != nil {
+			t.Fatalf("failed to create plan: %v", err)
+		}
+		if _, err := s.taskRepo.Create(ctx, plan.ID, "Open task", "", models.TaskPriorityMedium); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+		return plan
+	}
+	planExists := func(id string) bool {
+		_, err := s.planRepo.Get(ctx, id)
+		return err == nil
+	}
+
+	// Clients that cannot be asked have to choose in the arguments
+	plan := newPlan()
+	result := callTool(t, s, "delete_plan", map[string]any{"id": plan.ID})
+	if !result.IsError || !planExists(plan.ID) {
+		t.Fatalf("expected deleting a plan with open tasks to fail, got %s", toolResultText(result))
+	}
+	var toolErr models.Error
+	if err := json.Unmarshal([]byte(toolResultText(result)), &toolErr); err != nil || toolErr.Code != models.ErrorCodeConflict {
+		t.Errorf("expected a CONFLICT error, got %s", toolResultText(result))
+	}
+
+	target := newPlan()
+	result = callTool(t, s, "delete_plan", map[string]any{"id": plan.ID, "open_tasks": "move", "target_plan_id": target.ID})
+	if result.IsError || planExists(plan.ID) {
+		t.Fatalf("expected the plan to be deleted, got %s", toolResultText(result))
+	}
+	if tasks, err := s.taskRepo.ListByPlan(ctx, target.ID); err != nil || len(tasks) != 2 {
+		t.Errorf("expected the open task to be moved to the target plan, got %d tasks (%v)", len(tasks), err)
+	}
+
+	// Clients with elicitation ask the user
+	session := &elicitingSession{response: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionDecline}}
+	callElicitingTool(t, s, session, "delete_plan", map[string]any{"id": target.ID})
+	if len(session.requests) != 1 || !planExists(target.ID) {
+		t.Fatalf("expected a declined deletion to keep the plan after %d requests", len(session.requests))
+	}
+
+	session.response = mcp.ElicitationResponse{
+		Action:  mcp.ElicitationResponseActionAccept,
+		Content: map[string]any{"open_tasks": "delete"},
+	}
+	// Open tasks added while the user is asked were not confirmed, so they are not deleted
+	session.answering = func() {
+		if _, err := s.taskRepo.Create(ctx, target.ID, "Late task", "", models.TaskPriorityMedium); err != nil {
+			t.Errorf("failed to create task: %v", err)
+		}
+	}
+	text := callElicitingTool(t, s, session, "delete_plan", map[string]any{"id": target.ID})
+	if err := json.Unmarshal([]byte(text), &toolErr); err != nil || toolErr.Code != models.ErrorCodeConflict ||
+		!planExists(target.ID) {
+		t.Fatalf("expected a CONFLICT error when the open tasks changed, got %s", text)
+	}
+	session.answering = nil
+
+	callElicitingTool(t, s, session, "delete_plan", map[string]any{"id": target.ID})
+	if planExists(target.ID) {
+		t.Error("expected the plan to be deleted with its open tasks")
+	}
+
+	// Plans without open tasks are deleted without asking unless confirmations are turned on
+	empty, err := s.planRepo.Create(ctx, "app-1", "Empty plan", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	session.requests = nil
+	s.confirmDestructive.Store(true)
+	session.response = mcp.ElicitationResponse{
+		Action:  mcp.ElicitationResponseActionAccept,
+		Content: map[string]any{"confirm": false},
+	}
+	text = callElicitingTool(t, s, session, "delete_plan", map[string]any{"id": empty.ID})
+	err = json.Unmarshal([]byte(text), &toolErr)
+	if err != nil || toolErr.Code != models.ErrorCodeCancelled || !planExists(empty.ID) {
+		t.Errorf("expected an unconfirmed deletion to be cancelled, got %s", text)
+	}
+
+	session.response.Content = map[string]any{"confirm": true}
+	callElicitingTool(t, s, session, "delete_plan", map[string]any{"id": empty.ID})
+	if len(session.requests) != 2 || planExists(empty.ID) {
+		t.Errorf("expected a confirmed deletion after %d requests", len(session.requests))
+	}
+}
@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// testRepos are the repositories of a test on a fresh in-memory store
+type testRepos struct {
+	client   *storage.ValkeyClient
+	planRepo *storage.PlanRepository
+	taskRepo *storage.TaskRepository
+}
+
+// newTestRepos returns plain repositories on a fresh in-memory store, which is closed when the test ends
+func newTestRepos(t *testing.T) *testRepos {
+	t.Helper()
+	// Keep descriptions and messages in English whatever the locale of the machine running the tests
+	t.Setenv("LANG", "")
+	client, err := storage.NewMemoryClient(storage.MemoryConfig{})
+	if err != nil {
+		t.Fatalf("failed to create memory client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() }) //nolint:errcheck
+
+	return &testRepos{
+		client:   client,
+		planRepo: storage.NewPlanRepository(client),
+		taskRepo: storage.NewTaskRepository(client),
+	}
+}
+
+// newTestServer creates a server on the plain repositories of a fresh in-memory store
+func newTestServer(t *testing.T, opts ...ServerOption) *MCPGoServer {
+	t.Helper()
+	repos := newTestRepos(t)
+	return NewMCPGoServer(repos.planRepo, repos.taskRepo, opts...)
+}
+
+// serverTools returns the tools a server lists, by name
+func serverTools(t *testing.T, s *MCPGoServer) map[string]mcp.Tool {
+	t.Helper()
+	response := s.server.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	data, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("failed to marshal tool list: %v", err)
+	}
+	var result struct {
+		Result mcp.ListToolsResult `json:"result"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("failed to parse tool list: %v", err)
+	}
+
+	tools := make(map[string]mcp.Tool, len(result.Result.Tools))
+	for _, tool := range result.Result.Tools {
+		tools[tool.Name] = tool
+	}
+	return tools
+}
+
+// callTool calls a tool of a server and returns its result
+func callTool(t *testing.T, s *MCPGoServer, name string, arguments map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	params, err := json.Marshal(map[string]any{"name": name, "arguments": arguments})
+	if err != nil {
+		t.Fatalf("failed to marshal arguments: %v", err)
+	}
+	message := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":%s}`, params)
+	response, ok := s.server.HandleMessage(context.Background(), []byte(message)).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("tool call %s failed", name)
+	}
+	result, ok := response.Result.(mcp.CallToolResult)
+	if !ok {
+		t.Fatalf("tool call %s returned %T", name, response.Result)
+	}
+	return &result
+}
+
+// toolResultText returns the text of a tool result, including errors
+func toolResultText(result *mcp.CallToolResult) string {
+	var text strings.Builder
+	for _, content := range result.Content {
+		if textContent, ok := content.(mcp.TextContent); ok {
+			text.WriteString(textContent.Text)
+		}
+	}
+	return text.String()
+}
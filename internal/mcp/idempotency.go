@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"context"
+	"log"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// idempotencyKeyParam is the tool argument carrying the idempotency key chosen by the client
+const idempotencyKeyParam = "idempotency_key"
+
+// withIdempotencyKey adds the optional idempotency key argument to a tool that creates data
+func withIdempotencyKey() mcp.ToolOption {
+	return mcp.WithString(idempotencyKeyParam,
+		mcp.Description("Unique key for this request chosen by the client (optional). Retrying a call with the same "+
+			"key returns the result of the first successful call instead of creating the data again"),
+	)
+}
+
+// idempotencyMiddleware replays the result of a successful tool call when it is retried with the same
+// idempotency key. Failed calls are not remembered, so they can be retried with the same key.
+func (s *MCPGoServer) idempotencyMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		key := request.GetString(idempotencyKeyParam, "")
+		if s.idempotency == nil || key == "" {
+			return next(ctx, request)
+		}
+
+		// Keys are chosen by clients, so clients restricted to different applications do not share them
+		scope := request.Params.Name
+		if applicationID := storage.ApplicationScopeFromContext(ctx); applicationID != "" {
+			scope = applicationID + ":" + scope
+		}
+		stored, found, err := s.idempotency.Begin(ctx, scope, key)
+		if err != nil {
+			return s.toolError("", err), nil
+		}
+		if found {
+			return mcp.NewToolResultText(stored), nil
+		}
+
+		result, err := next(ctx, request)
+		text, ok := resultText(result)
+		if err != nil || !ok {
+			if abandonErr := s.idempotency.Abandon(ctx, scope, key); abandonErr != nil {
+				log.Printf("Failed to release idempotency key: %v", abandonErr)
+			}
+			return result, err
+		}
+
+		if err := s.idempotency.Complete(ctx, scope, key, text); err != nil {
+			log.Printf("Failed to store idempotent result: %v", err)
+		}
+		return result, nil
+	}
+}
+
+// resultText returns the text of a successful tool result consisting of a single text
+func resultText(result *mcp.CallToolResult) (string, bool) {
+	if result == nil || result.IsError || len(result.Content) != 1 {
+		return "", false
+	}
+	content, ok := mcp.AsTextContent(result.Content[0])
+	if !ok {
+		return "", false
+	}
+	return content.Text, true
+}
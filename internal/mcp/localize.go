@@ -0,0 +1,31 @@
+package mcp
+
+import (
+	"maps"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// localizeTool translates the description of a tool and of its arguments into the language of the server.
+// The property maps are copied, since tools built from shared options can share them.
+func (s *MCPGoServer) localizeTool(tool mcp.Tool) mcp.Tool {
+	if s.localizer == nil {
+		return tool
+	}
+
+	tool.Description = s.localizer.Translate(tool.Description)
+
+	properties := make(map[string]any, len(tool.InputSchema.Properties))
+	for name, property := range tool.InputSchema.Properties {
+		if schema, ok := property.(map[string]any); ok {
+			if description, ok := schema["description"].(string); ok {
+				schema = maps.Clone(schema)
+				schema["description"] = s.localizer.Translate(description)
+			}
+			property = schema
+		}
+		properties[name] = property
+	}
+	tool.InputSchema.Properties = properties
+	return tool
+}
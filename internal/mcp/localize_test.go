@@ -0,0 +1,46 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+func TestLocalizedTools(t *testing.T) {
+	s := newTestServer(t, WithServerConfig(ServerConfig{Language: "es_ES.UTF-8"}))
+
+	tools := serverTools(t, s)
+	english := serverTools(t, newTestServer(t))
+
+	getPlan := tools["get_plan"]
+	if getPlan.Description == english["get_plan"].Description {
+		t.Errorf("expected a Spanish description of get_plan, got %q", getPlan.Description)
+	}
+	idProperty, ok := getPlan.InputSchema.Properties["id"].(map[string]any)
+	if !ok {
+		t.Fatalf("get_plan has no id argument")
+	}
+	englishProperty, ok := english["get_plan"].InputSchema.Properties["id"].(map[string]any)
+	if !ok {
+		t.Fatalf("get_plan has no id argument in English")
+	}
+	if idProperty["description"] == englishProperty["description"] {
+		t.Errorf("expected a Spanish description of the id argument, got %q", idProperty["description"])
+	}
+
+	result := callTool(t, s, "get_plan", map[string]any{"id": "missing"})
+	var got models.Error
+	if err := json.Unmarshal([]byte(toolResultText(result)), &got); err != nil {
+		t.Fatalf("failed to parse error result %q: %v", toolResultText(result), err)
+	}
+	want := models.Error{
+		Code:    models.ErrorCodeNotFound,
+		Message: "No se pudo obtener el plan: No se encontró plan: missing",
+		Entity:  models.EntityPlan,
+		ID:      "missing",
+	}
+	if got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,178 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// maxInlineNotesLength is the length in bytes up to which the full plan resources include notes. Longer
+// notes are left out and linked to their notes resource instead.
+const maxInlineNotesLength = 4096
+
+// URI patterns for notes resources
+var (
+	// Pattern for the notes of a plan: ai-tasks://plans/{id}/notes
+	planNotesPattern = regexp.MustCompile(`ai-tasks://plans/([^/]+)/notes$`)
+
+	// Pattern for the notes of a task: ai-tasks://tasks/{id}/notes
+	taskNotesPattern = regexp.MustCompile(`ai-tasks://tasks/([^/]+)/notes$`)
+)
+
+// NotesResourceProvider implements the MCP resource provider for the notes of plans and tasks
+type NotesResourceProvider struct {
+	planRepo storage.PlanRepositoryInterface
+	taskRepo storage.TaskRepositoryInterface
+}
+
+// NewNotesResourceProvider creates a new NotesResourceProvider
+func NewNotesResourceProvider(
+	planRepo storage.PlanRepositoryInterface,
+	taskRepo storage.TaskRepositoryInterface,
+) *NotesResourceProvider {
+	return &NotesResourceProvider{
+		planRepo: planRepo,
+		taskRepo: taskRepo,
+	}
+}
+
+// RegisterResource registers the notes resources with the MCP server
+func (p *NotesResourceProvider) RegisterResource(server *MCPGoServer) {
+	planNotesTemplate := mcp.NewResourceTemplate(
+		"ai-tasks://plans/{id}/notes",
+		"Plan Notes Resource",
+		mcp.WithTemplateDescription("Returns the markdown notes of a plan"),
+		mcp.WithTemplateMIMEType("text/markdown"),
+	)
+
+	taskNotesTemplate := mcp.NewResourceTemplate(
+		"ai-tasks://tasks/{id}/notes",
+		"Task Notes Resource",
+		mcp.WithTemplateDescription("Returns the markdown notes of a task"),
+		mcp.WithTemplateMIMEType("text/markdown"),
+	)
+
+	server.addResourceTemplate(planNotesTemplate, p.handlePlanNotesRequest)
+	server.addResourceTemplate(taskNotesTemplate, p.handleTaskNotesRequest)
+}
+
+// handlePlanNotesRequest handles requests for the notes of a plan
+func (p *NotesResourceProvider) handlePlanNotesRequest(
+	ctx context.Context,
+	req mcp.ReadResourceRequest,
+) ([]mcp.ResourceContents, error) {
+	matches := planNotesPattern.FindStringSubmatch(req.Params.URI)
+	if len(matches) != 2 {
+		return nil, fmt.Errorf(
+			"%w: '%s' does not match the expected format 'ai-tasks://plans/{id}/notes'",
+			ErrInvalidURI,
+			req.Params.URI,
+		)
+	}
+
+	planID := matches[1]
+	if strings.TrimSpace(planID) == "" {
+		return nil, fmt.Errorf("%w: empty plan ID", ErrInvalidPlanID)
+	}
+
+	notes, err := p.planRepo.GetNotes(ctx, planID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: plan with ID '%s' does not exist", ErrPlanNotFound, planID)
+		}
+		return nil, fmt.Errorf("%w: failed to get notes of plan '%s': %v", ErrInternalStorage, planID, err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      planNotesURI(planID),
+			MIMEType: "text/markdown",
+			Text:     notes,
+		},
+	}, nil
+}
+
+// handleTaskNotesRequest handles requests for the notes of a task
+func (p *NotesResourceProvider) handleTaskNotesRequest(
+	ctx context.Context,
+	req mcp.ReadResourceRequest,
+) ([]mcp.ResourceContents, error) {
+	matches := taskNotesPattern.FindStringSubmatch(req.Params.URI)
+	if len(matches) != 2 {
+		return nil, fmt.Errorf(
+			"%w: '%s' does not match the expected format 'ai-tasks://tasks/{id}/notes'",
+			ErrInvalidURI,
+			req.Params.URI,
+		)
+	}
+
+	taskID := matches[1]
+	if strings.TrimSpace(taskID) == "" {
+		return nil, fmt.Errorf("%w: empty task ID", ErrInvalidTaskID)
+	}
+
+	notes, err := p.taskRepo.GetNotes(ctx, taskID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: task with ID '%s' does not exist", ErrTaskNotFound, taskID)
+		}
+		return nil, fmt.Errorf("%w: failed to get notes of task '%s': %v", ErrInternalStorage, taskID, err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      taskNotesURI(taskID),
+			MIMEType: "text/markdown",
+			Text:     notes,
+		},
+	}, nil
+}
+
+// planNotesURI returns the URI of the notes resource of a plan
+func planNotesURI(planID string) string {
+	return fmt.Sprintf("ai-tasks://plans/%s/notes", planID)
+}
+
+// taskNotesURI returns the URI of the notes resource of a task
+func taskNotesURI(taskID string) string {
+	return fmt.Sprintf("ai-tasks://tasks/%s/notes", taskID)
+}
+
+// newPlanResource creates the full resource of a plan. Notes longer than maxInlineNotesLength are left out
+// and listed by the URI of their notes resource instead, so large notes are not sent with every read.
+func newPlanResource(plan *models.Plan, tasks []*models.Task) *models.PlanResource {
+	resource := models.NewPlanResource(plan, tasks)
+
+	if len(plan.Notes) > maxInlineNotesLength {
+		detached := *plan
+		detached.Notes = ""
+		resource.Plan = &detached
+		resource.NotesURIs = map[string]string{plan.ID: planNotesURI(plan.ID)}
+	}
+
+	cloned := false
+	for i, task := range tasks {
+		if len(task.Notes) <= maxInlineNotesLength {
+			continue
+		}
+		if !cloned {
+			resource.Tasks = slices.Clone(tasks)
+			cloned = true
+		}
+		if resource.NotesURIs == nil {
+			resource.NotesURIs = make(map[string]string)
+		}
+		detached := *task
+		detached.Notes = ""
+		resource.Tasks[i] = &detached
+		resource.NotesURIs[task.ID] = taskNotesURI(task.ID)
+	}
+	return resource
+}
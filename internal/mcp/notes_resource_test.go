@@ -0,0 +1,40 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+func TestNewPlanResourceLeavesOutLongNotes(t *testing.T) {
+	longNotes := strings.Repeat("x", maxInlineNotesLength+1)
+	plan := &models.Plan{ID: "plan-1", Notes: longNotes}
+	tasks := []*models.Task{
+		{ID: "task-1", Notes: "short"},
+		{ID: "task-2", Notes: longNotes},
+	}
+
+	resource := newPlanResource(plan, tasks)
+
+	if resource.Plan.Notes != "" || resource.Tasks[1].Notes != "" {
+		t.Error("long notes should be left out")
+	}
+	if resource.Tasks[0].Notes != "short" {
+		t.Errorf("short notes = %q, expected them to be kept", resource.Tasks[0].Notes)
+	}
+	if got := resource.NotesURIs["plan-1"]; got != "ai-tasks://plans/plan-1/notes" {
+		t.Errorf("plan notes URI = %q", got)
+	}
+	if got := resource.NotesURIs["task-2"]; got != "ai-tasks://tasks/task-2/notes" {
+		t.Errorf("task notes URI = %q", got)
+	}
+	if _, ok := resource.NotesURIs["task-1"]; ok {
+		t.Error("short notes should not be linked")
+	}
+
+	// The plan and tasks read from the repositories are left alone
+	if plan.Notes != longNotes || tasks[1].Notes != longNotes {
+		t.Error("newPlanResource should not change its arguments")
+	}
+}
@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// Pattern for the backup of a plan: ai-tasks://plans/{id}/export
+var planExportPattern = regexp.MustCompile(`ai-tasks://plans/([^/]+)/export$`)
+
+// PlanExportResourceProvider implements the MCP resource provider for plan backups. A backup is the same
+// document the REST API and the export command return, so it can be imported again as is.
+type PlanExportResourceProvider struct {
+	backup *services.BackupService
+}
+
+// NewPlanExportResourceProvider creates a new PlanExportResourceProvider
+func NewPlanExportResourceProvider(
+	planRepo storage.PlanRepositoryInterface,
+	taskRepo storage.TaskRepositoryInterface,
+) *PlanExportResourceProvider {
+	return &PlanExportResourceProvider{
+		backup: services.NewBackupService(planRepo, taskRepo),
+	}
+}
+
+// RegisterResource registers the plan export resource with the MCP server
+func (p *PlanExportResourceProvider) RegisterResource(server *MCPGoServer) {
+	exportTemplate := mcp.NewResourceTemplate(
+		"ai-tasks://plans/{id}/export",
+		"Plan Export Resource",
+		mcp.WithTemplateDescription(
+			"Returns a backup of a plan with all of its tasks and their notes, never paged or summarized, "+
+				"in the format the REST API and the valkey-tasks import command restore. Use it to keep a snapshot of a plan.",
+		),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+
+	server.addResourceTemplate(exportTemplate, p.handleExportRequest)
+}
+
+// handleExportRequest handles requests for the plan export resource
+func (p *PlanExportResourceProvider) handleExportRequest(
+	ctx context.Context,
+	req mcp.ReadResourceRequest,
+) ([]mcp.ResourceContents, error) {
+	matches := planExportPattern.FindStringSubmatch(req.Params.URI)
+	if len(matches) != 2 {
+		return nil, fmt.Errorf(
+			"%w: '%s' does not match the expected format 'ai-tasks://plans/{id}/export'",
+			ErrInvalidURI,
+			req.Params.URI,
+		)
+	}
+
+	planID := matches[1]
+	if strings.TrimSpace(planID) == "" {
+		return nil, fmt.Errorf("%w: empty plan ID", ErrInvalidPlanID)
+	}
+
+	backup, err := p.backup.ExportPlan(ctx, planID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: plan with ID '%s' does not exist", ErrPlanNotFound, planID)
+		}
+		return nil, fmt.Errorf("%w: failed to export plan '%s': %v", ErrInternalStorage, planID, err)
+	}
+
+	data, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to marshal plan backup: %v", ErrInternalStorage, err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      fmt.Sprintf("ai-tasks://plans/%s/export", planID),
+			MIMEType: "application/json",
+			Text:     string(data),
+		},
+	}, nil
+}
@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
+)
+
+func TestPlanExportResource(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	plan, err := s.planRepo.Create(ctx, "app", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	longNotes := strings.Repeat("x", maxInlineNotesLength+1)
+	if err := s.planRepo.UpdateNotes(ctx, plan.ID, longNotes); err != nil {
+		t.Fatalf("failed to update notes: %v", err)
+	}
+	for _, title := range []string{"A", "B"} {
+		if _, err := s.taskRepo.Create(ctx, plan.ID, title, "", models.TaskPriorityMedium); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+	p := NewPlanExportResourceProvider(s.planRepo, s.taskRepo)
+
+	var req mcp.ReadResourceRequest
+	req.Params.URI = "ai-tasks://plans/" + plan.ID + "/export"
+	contents, err := p.handleExportRequest(ctx, req)
+	if err != nil {
+		t.Fatalf("failed to read the export: %v", err)
+	}
+	var backup models.PlanResource
+	if err := json.Unmarshal([]byte(contents[0].(mcp.TextResourceContents).Text), &backup); err != nil {
+		t.Fatalf("failed to parse the export: %v", err)
+	}
+
+	// The backup keeps long notes inline, unlike the full plan resource, and can be imported again
+	if backup.Plan.Notes != longNotes || len(backup.NotesURIs) != 0 {
+		t.Error("expected the notes of the plan inline")
+	}
+	if len(backup.Tasks) != 2 || backup.Tasks[0].Title != "A" || backup.Tasks[1].Title != "B" {
+		t.Fatalf("expected tasks A and B in order, got %d tasks", len(backup.Tasks))
+	}
+	if err := services.ValidatePlanBackup(&backup); err != nil {
+		t.Errorf("the export is not a valid backup: %v", err)
+	}
+
+	for uri, want := range map[string]error{
+		"ai-tasks://plans/missing/export": ErrPlanNotFound,
+		"ai-tasks://plans/export":         ErrInvalidURI,
+	} {
+		req.Params.URI = uri
+		if _, err := p.handleExportRequest(ctx, req); !errors.Is(err, want) {
+			t.Errorf("reading %s: expected %v, got %v", uri, want, err)
+		}
+	}
+}
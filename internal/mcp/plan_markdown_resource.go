@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
+)
+
+// Pattern for the markdown report of a plan: ai-tasks://plans/{id}/markdown
+var planMarkdownPattern = regexp.MustCompile(`ai-tasks://plans/([^/]+)/markdown$`)
+
+// PlanMarkdownResourceProvider implements the MCP resource provider for markdown progress reports of plans
+type PlanMarkdownResourceProvider struct {
+	planStats *services.PlanStatsService
+}
+
+// NewPlanMarkdownResourceProvider creates a new PlanMarkdownResourceProvider
+func NewPlanMarkdownResourceProvider(planStats *services.PlanStatsService) *PlanMarkdownResourceProvider {
+	return &PlanMarkdownResourceProvider{
+		planStats: planStats,
+	}
+}
+
+// RegisterResource registers the plan markdown resource with the MCP server
+func (p *PlanMarkdownResourceProvider) RegisterResource(server *MCPGoServer) {
+	markdownTemplate := mcp.NewResourceTemplate(
+		"ai-tasks://plans/{id}/markdown",
+		"Plan Markdown Report Resource",
+		mcp.WithTemplateDescription(
+			"Returns a plan with its tasks, statuses, priorities and notes as a markdown progress report, "+
+				"ready to paste into a PR description or status update",
+		),
+		mcp.WithTemplateMIMEType("text/markdown"),
+	)
+
+	server.addResourceTemplate(markdownTemplate, p.handleMarkdownRequest)
+}
+
+// handleMarkdownRequest handles requests for the plan markdown resource
+func (p *PlanMarkdownResourceProvider) handleMarkdownRequest(
+	ctx context.Context,
+	req mcp.ReadResourceRequest,
+) ([]mcp.ResourceContents, error) {
+	matches := planMarkdownPattern.FindStringSubmatch(req.Params.URI)
+	if len(matches) != 2 {
+		return nil, fmt.Errorf(
+			"%w: '%s' does not match the expected format 'ai-tasks://plans/{id}/markdown'",
+			ErrInvalidURI,
+			req.Params.URI,
+		)
+	}
+
+	planID := matches[1]
+	if strings.TrimSpace(planID) == "" {
+		return nil, fmt.Errorf("%w: empty plan ID", ErrInvalidPlanID)
+	}
+
+	report, err := p.planStats.GetPlanMarkdown(ctx, planID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: plan with ID '%s' does not exist", ErrPlanNotFound, planID)
+		}
+		return nil, fmt.Errorf("%w: failed to render plan '%s': %v", ErrInternalStorage, planID, err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      fmt.Sprintf("ai-tasks://plans/%s/markdown", planID),
+			MIMEType: "text/markdown",
+			Text:     report,
+		},
+	}, nil
+}
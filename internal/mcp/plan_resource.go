@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -19,7 +20,9 @@ var (
 	ErrInvalidURI      = errors.New("invalid resource URI")
 	ErrPlanNotFound    = errors.New("plan not found")
 	ErrTasksNotFound   = errors.New("tasks not found")
+	ErrTaskNotFound    = errors.New("task not found")
 	ErrInvalidPlanID   = errors.New("invalid plan ID")
+	ErrInvalidTaskID   = errors.New("invalid task ID")
 	ErrInvalidAppID    = errors.New("invalid application ID")
 	ErrMarshalFailure  = errors.New("failed to marshal resource")
 	ErrInternalStorage = errors.New("internal storage error")
@@ -29,16 +32,20 @@ var (
 type PlanResourceProvider struct {
 	planRepo storage.PlanRepositoryInterface
 	taskRepo storage.TaskRepositoryInterface
+	// maxSize is the size in bytes past which plans are summarized, 0 never summarizes them
+	maxSize int
 }
 
 // NewPlanResourceProvider creates a new PlanResourceProvider
 func NewPlanResourceProvider(
 	planRepo storage.PlanRepositoryInterface,
 	taskRepo storage.TaskRepositoryInterface,
+	maxSize int,
 ) *PlanResourceProvider {
 	return &PlanResourceProvider{
 		planRepo: planRepo,
 		taskRepo: taskRepo,
+		maxSize:  maxSize,
 	}
 }
 
@@ -46,34 +53,41 @@ func NewPlanResourceProvider(
 func (p *PlanResourceProvider) RegisterResource(server *MCPGoServer) {
 	// Create a resource template for accessing plan details by ID
 	planTemplate := mcp.NewResourceTemplate(
-		"ai-tasks://plans/{id}/full",
+		"ai-tasks://plans/{id}/full{?cursor,limit}",
 		"Plan Resource",
-		mcp.WithTemplateDescription("Returns a complete view of a plan including its tasks and notes"),
+		mcp.WithTemplateDescription(
+			"Returns a complete view of a plan including its tasks and notes. "+
+				"Set limit to read the tasks a page at a time, and cursor to the next_cursor of a page to read the next one",
+		),
 		mcp.WithTemplateMIMEType("application/json"),
 	)
 
 	// Create a resource template for accessing all plans
 	allPlansTemplate := mcp.NewResourceTemplate(
-		"ai-tasks://plans/full",
+		"ai-tasks://plans/full{?cursor,limit}",
 		"All Plans Resource",
-		mcp.WithTemplateDescription("Returns a complete view of all plans including their tasks and notes"),
+		mcp.WithTemplateDescription(
+			"Returns a complete view of all plans including their tasks and notes. "+
+				"Set limit to read the plans a page at a time, and cursor to the next_cursor of a page to read the next one",
+		),
 		mcp.WithTemplateMIMEType("application/json"),
 	)
 
 	// Create a resource template for accessing plans by application ID
 	appPlansTemplate := mcp.NewResourceTemplate(
-		"ai-tasks://applications/{app_id}/plans/full",
+		"ai-tasks://applications/{app_id}/plans/full{?cursor,limit}",
 		"Application Plans Resource",
 		mcp.WithTemplateDescription(
-			"Returns a complete view of all plans for a specific application including their tasks and notes",
+			"Returns a complete view of all plans for a specific application including their tasks and notes. "+
+				"Set limit to read the plans a page at a time, and cursor to the next_cursor of a page to read the next one",
 		),
 		mcp.WithTemplateMIMEType("application/json"),
 	)
 
 	// Add the templates with their handlers
-	server.server.AddResourceTemplate(planTemplate, p.handleResourceRequest)
-	server.server.AddResourceTemplate(allPlansTemplate, p.handleResourceRequest)
-	server.server.AddResourceTemplate(appPlansTemplate, p.handleResourceRequest)
+	server.addResourceTemplate(planTemplate, p.handleResourceRequest)
+	server.addResourceTemplate(allPlansTemplate, p.handleResourceRequest)
+	server.addResourceTemplate(appPlansTemplate, p.handleResourceRequest)
 }
 
 // handleResourceRequest handles requests for the PlanResource
@@ -103,18 +117,22 @@ func (p *PlanResourceProvider) handleResourceRequest(
 	// Handle different URI patterns
 	switch uriInfo.requestType {
 	case singlePlanRequest:
-		return p.handleSinglePlanRequest(ctx, uriInfo.planID)
+		return p.handleSinglePlanRequest(ctx, req.Params.URI, uriInfo.planID, uriInfo.page)
 	case allPlansRequest:
-		return p.handleAllPlansRequest(ctx)
+		return p.handleAllPlansRequest(ctx, req.Params.URI, uriInfo.page)
 	case appPlansRequest:
-		return p.handleAppPlansRequest(ctx, uriInfo.appID)
+		return p.handleAppPlansRequest(ctx, req.Params.URI, uriInfo.appID, uriInfo.page)
 	default:
 		return nil, fmt.Errorf("%w: unsupported request type for URI: %s", ErrInvalidURI, req.Params.URI)
 	}
 }
 
 // handleSinglePlanRequest handles requests for a single plan
-func (p *PlanResourceProvider) handleSinglePlanRequest(ctx context.Context, planID string) ([]mcp.ResourceContents, error) {
+func (p *PlanResourceProvider) handleSinglePlanRequest(
+	ctx context.Context,
+	uri, planID string,
+	page resourcePage,
+) ([]mcp.ResourceContents, error) {
 	// Validate plan ID
 	if strings.TrimSpace(planID) == "" {
 		return nil, fmt.Errorf("%w: empty plan ID", ErrInvalidPlanID)
@@ -124,7 +142,7 @@ func (p *PlanResourceProvider) handleSinglePlanRequest(ctx context.Context, plan
 	plan, err := p.planRepo.Get(ctx, planID)
 	if err != nil {
 		// Check for not found case by examining error message
-		if strings.Contains(err.Error(), "plan not found") {
+		if models.IsNotFound(err) {
 			return nil, fmt.Errorf("%w: plan with ID '%s' does not exist", ErrPlanNotFound, planID)
 		}
 		// Handle other errors
@@ -145,8 +163,19 @@ func (p *PlanResourceProvider) handleSinglePlanRequest(ctx context.Context, plan
 
 	// Note: Empty tasks list is valid, so we don't check for nil or empty
 
-	// Create the plan resource
-	planResource := models.NewPlanResource(plan, tasks)
+	// Create the plan resource, with a page of its tasks when the URI asks for one
+	var planResource *models.PlanResource
+	if page.paged() {
+		slices.SortFunc(tasks, func(a, b *models.Task) int {
+			return strings.Compare(taskSortKey(a), taskSortKey(b))
+		})
+		pageTasks, next := paginate(tasks, taskSortKey, page)
+		planResource = newPlanResource(plan, pageTasks)
+		planResource.TotalTasks = len(tasks)
+		planResource.NextCursor = next
+	} else {
+		planResource = newPlanResource(plan, tasks)
+	}
 
 	// Convert to JSON
 	jsonData, err := json.MarshalIndent(planResource, "", "  ")
@@ -154,10 +183,18 @@ func (p *PlanResourceProvider) handleSinglePlanRequest(ctx context.Context, plan
 		return nil, fmt.Errorf("%w: failed to marshal plan resource for plan '%s': %v", ErrMarshalFailure, planID, err)
 	}
 
+	// Summarize the tasks of plans too large to return whole
+	if p.tooLarge(jsonData) {
+		summary := p.summarizePlan(uri, planResource, len(tasks), len(jsonData))
+		if jsonData, err = json.MarshalIndent(summary, "", "  "); err != nil {
+			return nil, fmt.Errorf("%w: failed to marshal plan summary for plan '%s': %v", ErrMarshalFailure, planID, err)
+		}
+	}
+
 	// Return the resource contents
 	return []mcp.ResourceContents{
 		mcp.TextResourceContents{
-			URI:      fmt.Sprintf("ai-tasks://plans/%s/full", planID),
+			URI:      uri,
 			MIMEType: "application/json",
 			Text:     string(jsonData),
 		},
@@ -165,29 +202,58 @@ func (p *PlanResourceProvider) handleSinglePlanRequest(ctx context.Context, plan
 }
 
 // handleAllPlansRequest handles requests for all plans
-func (p *PlanResourceProvider) handleAllPlansRequest(ctx context.Context) ([]mcp.ResourceContents, error) {
+func (p *PlanResourceProvider) handleAllPlansRequest(
+	ctx context.Context,
+	uri string,
+	page resourcePage,
+) ([]mcp.ResourceContents, error) {
 	// Get all plans
 	plans, err := p.planRepo.List(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("%w: failed to list plans: %v", ErrInternalStorage, err)
 	}
 
-	// Handle empty plans list
-	if len(plans) == 0 {
-		// Return empty array instead of error
-		emptyJSON := "[]"
-		return []mcp.ResourceContents{
-			mcp.TextResourceContents{
-				URI:      "ai-tasks://plans/full",
-				MIMEType: "application/json",
-				Text:     emptyJSON,
-			},
-		}, nil
+	return p.planListContents(ctx, uri, plans, page)
+}
+
+// handleAppPlansRequest handles requests for plans by application ID
+func (p *PlanResourceProvider) handleAppPlansRequest(
+	ctx context.Context,
+	uri, appID string,
+	page resourcePage,
+) ([]mcp.ResourceContents, error) {
+	// Validate application ID
+	if strings.TrimSpace(appID) == "" {
+		return nil, fmt.Errorf("%w: empty application ID", ErrInvalidAppID)
 	}
 
+	// Get plans for the application
+	plans, err := p.planRepo.ListByApplication(ctx, appID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get plans for application '%s': %v", ErrInternalStorage, appID, err)
+	}
+
+	return p.planListContents(ctx, uri, plans, page)
+}
+
+// planListContents returns plans with their tasks: all of them as an array, or a page of them when the URI
+// asks for one. Plans too large to return whole are summarized, and their tasks are no longer loaded once the
+// plans read so far exceed the size limit.
+func (p *PlanResourceProvider) planListContents(
+	ctx context.Context,
+	uri string,
+	plans []*models.Plan,
+	page resourcePage,
+) ([]mcp.ResourceContents, error) {
+	slices.SortFunc(plans, func(a, b *models.Plan) int {
+		return strings.Compare(planSortKey(a), planSortKey(b))
+	})
+	pagePlans, next := paginate(plans, planSortKey, page)
+
 	// Create a list of plan resources
-	planResources := make([]*models.PlanResource, 0, len(plans))
-	for _, plan := range plans {
+	planResources := make([]*models.PlanResource, 0, len(pagePlans))
+	size := 0
+	for _, plan := range pagePlans {
 		// Get tasks for the plan
 		tasks, err := p.taskRepo.ListByPlan(ctx, plan.ID)
 		if err != nil {
@@ -195,86 +261,153 @@ func (p *PlanResourceProvider) handleAllPlansRequest(ctx context.Context) ([]mcp
 		}
 
 		// Create the plan resource
-		planResource := models.NewPlanResource(plan, tasks)
+		planResource := newPlanResource(plan, tasks)
 		planResources = append(planResources, planResource)
+
+		if p.maxSize > 0 {
+			data, err := json.Marshal(planResource)
+			if err != nil {
+				return nil, fmt.Errorf("%w: failed to marshal plan resource for plan '%s': %v", ErrMarshalFailure, plan.ID, err)
+			}
+			if size += len(data); size > p.maxSize {
+				break
+			}
+		}
+	}
+
+	var result any = planResources
+	if page.paged() {
+		result = &models.PlanPage{Plans: planResources, Total: len(plans), NextCursor: next}
 	}
 
 	// Convert to JSON
-	jsonData, err := json.MarshalIndent(planResources, "", "  ")
+	jsonData, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
 		return nil, fmt.Errorf("%w: failed to marshal multiple plan resources: %v", ErrMarshalFailure, err)
 	}
 
+	// Summarize plans too large to return whole
+	if size > p.maxSize || p.tooLarge(jsonData) {
+		summary := p.summarizePlans(uri, pagePlans, len(plans), next, suggestedLimit(len(planResources), size, p.maxSize))
+		if jsonData, err = json.MarshalIndent(summary, "", "  "); err != nil {
+			return nil, fmt.Errorf("%w: failed to marshal plan summaries: %v", ErrMarshalFailure, err)
+		}
+	}
+
 	// Return the resource contents
 	return []mcp.ResourceContents{
 		mcp.TextResourceContents{
-			URI:      "ai-tasks://plans/full",
+			URI:      uri,
 			MIMEType: "application/json",
 			Text:     string(jsonData),
 		},
 	}, nil
 }
 
-// handleAppPlansRequest handles requests for plans by application ID
-func (p *PlanResourceProvider) handleAppPlansRequest(ctx context.Context, appID string) ([]mcp.ResourceContents, error) {
-	// Validate application ID
-	if strings.TrimSpace(appID) == "" {
-		return nil, fmt.Errorf("%w: empty application ID", ErrInvalidAppID)
-	}
+// tooLarge reports whether a resource exceeds the size limit
+func (p *PlanResourceProvider) tooLarge(data []byte) bool {
+	return p.maxSize > 0 && len(data) > p.maxSize
+}
 
-	// Get plans for the application
-	plans, err := p.planRepo.ListByApplication(ctx, appID)
-	if err != nil {
-		return nil, fmt.Errorf("%w: failed to get plans for application '%s': %v", ErrInternalStorage, appID, err)
+// summarizePlans returns the summaries of a page of plans, as many as fit within the size limit. The next
+// cursor continues after the last summary, and the hint tells how to read the plans with their tasks.
+func (p *PlanResourceProvider) summarizePlans(
+	uri string,
+	pagePlans []*models.Plan,
+	total int,
+	next string,
+	limit int,
+) *models.PlanPage {
+	summaryPage := &models.PlanPage{
+		Summaries:  make([]*models.PlanSummary, 0, len(pagePlans)),
+		Total:      total,
+		NextCursor: next,
 	}
 
-	// Handle empty plans list
-	if len(plans) == 0 {
-		// Return empty array instead of error
-		emptyJSON := "[]"
-		return []mcp.ResourceContents{
-			mcp.TextResourceContents{
-				URI:      fmt.Sprintf("ai-tasks://applications/%s/plans/full", appID),
-				MIMEType: "application/json",
-				Text:     emptyJSON,
-			},
-		}, nil
+	size := 0
+	for i, plan := range pagePlans {
+		summary := &models.PlanSummary{
+			ID:            plan.ID,
+			ApplicationID: plan.ApplicationID,
+			Name:          plan.Name,
+			Status:        plan.Status,
+			TaskCounts:    plan.TaskCounts,
+			UpdatedAt:     plan.UpdatedAt,
+			URI:           fmt.Sprintf("ai-tasks://plans/%s/full", plan.ID),
+		}
+		data, err := json.MarshalIndent(summary, "    ", "  ")
+		if err == nil {
+			size += len(data) + len(",\n    ")
+		}
+		if i > 0 && size > p.maxSize-resourceEnvelopeSize {
+			summaryPage.NextCursor = encodeCursor(planSortKey(pagePlans[i-1]))
+			break
+		}
+		summaryPage.Summaries = append(summaryPage.Summaries, summary)
 	}
 
-	// Create a list of plan resources
-	planResources := make([]*models.PlanResource, 0, len(plans))
-	for _, plan := range plans {
-		// Get tasks for the plan
-		tasks, err := p.taskRepo.ListByPlan(ctx, plan.ID)
-		if err != nil {
-			return nil, fmt.Errorf(
-				"%w: failed to get tasks for plan '%s' in application '%s': %v",
-				ErrInternalStorage,
-				plan.ID,
-				appID,
-				err,
-			)
-		}
+	summaryPage.Hint = fmt.Sprintf(
+		"The plans exceed the resource size limit of %d bytes, so only their summaries are returned. "+
+			"Read each plan from its uri, or read the plans %d at a time from %s?limit=%d",
+		p.maxSize, limit, resourceBaseURI(uri), limit,
+	)
+	return summaryPage
+}
 
-		// Create the plan resource
-		planResource := models.NewPlanResource(plan, tasks)
-		planResources = append(planResources, planResource)
+// summarizePlan returns a plan resource with the notes of the plan left out and summaries of as many of its
+// tasks as fit within the size limit. The next cursor continues after the last summary, and the hint tells how
+// to read the tasks whole.
+func (p *PlanResourceProvider) summarizePlan(
+	uri string,
+	planResource *models.PlanResource,
+	totalTasks, size int,
+) *models.PlanResource {
+	plan := *planResource.Plan
+	summary := &models.PlanResource{
+		Plan:          &plan,
+		Tasks:         []*models.Task{},
+		NotesURIs:     planResource.NotesURIs,
+		TotalTasks:    totalTasks,
+		NextCursor:    planResource.NextCursor,
+		Summarized:    true,
+		TaskSummaries: make([]*models.TaskSummary, 0, len(planResource.Tasks)),
+	}
+	if plan.Notes != "" {
+		plan.Notes = ""
+		summary.NotesURIs = map[string]string{plan.ID: planNotesURI(plan.ID)}
 	}
 
-	// Convert to JSON
-	jsonData, err := json.MarshalIndent(planResources, "", "  ")
-	if err != nil {
-		return nil, fmt.Errorf("%w: failed to marshal application plan resources: %v", ErrMarshalFailure, err)
+	used := 0
+	if data, err := json.MarshalIndent(&plan, "  ", "  "); err == nil {
+		used = len(data)
+	}
+	for i, task := range planResource.Tasks {
+		taskSummary := &models.TaskSummary{
+			ID:       task.ID,
+			Title:    task.Title,
+			Status:   task.Status,
+			Priority: task.Priority,
+			Order:    task.Order,
+			URI:      fmt.Sprintf("ai-tasks://tasks/%s", task.ID),
+		}
+		data, err := json.MarshalIndent(taskSummary, "    ", "  ")
+		if err == nil {
+			used += len(data) + len(",\n    ")
+		}
+		if i > 0 && used > p.maxSize-resourceEnvelopeSize {
+			summary.NextCursor = encodeCursor(taskSortKey(planResource.Tasks[i-1]))
+			break
+		}
+		summary.TaskSummaries = append(summary.TaskSummaries, taskSummary)
 	}
 
-	// Return the resource contents
-	return []mcp.ResourceContents{
-		mcp.TextResourceContents{
-			URI:      fmt.Sprintf("ai-tasks://applications/%s/plans/full", appID),
-			MIMEType: "application/json",
-			Text:     string(jsonData),
-		},
-	}, nil
+	limit := suggestedLimit(len(planResource.Tasks), size, p.maxSize)
+	summary.Hint = fmt.Sprintf(
+		"The plan exceeds the resource size limit of %d bytes, so only summaries of its tasks are returned. "+
+			"Read each task from its uri, or read the tasks %d at a time from %s?limit=%d",
+		p.maxSize, limit, resourceBaseURI(uri), limit,
+	)
+	return summary
 }
 
 // requestType represents the type of resource request
@@ -292,6 +425,7 @@ type uriInfo struct {
 	requestType requestType
 	planID      string
 	appID       string
+	page        resourcePage
 }
 
 // URI patterns for resource parsing
@@ -308,6 +442,22 @@ var (
 
 // parseResourceURI parses a resource URI and extracts relevant information
 func parseResourceURI(uri string) (*uriInfo, error) {
+	info, err := matchResourceURI(resourceBaseURI(uri))
+	if err != nil {
+		return nil, err
+	}
+
+	// The query selects a page
+	if _, query, ok := strings.Cut(uri, "?"); ok {
+		if info.page, err = parseResourcePage(query); err != nil {
+			return nil, err
+		}
+	}
+	return info, nil
+}
+
+// matchResourceURI matches a resource URI without its query against the supported patterns
+func matchResourceURI(uri string) (*uriInfo, error) {
 	// Check for single plan pattern
 	if matches := singlePlanPattern.FindStringSubmatch(uri); len(matches) == 2 {
 		return &uriInfo{
@@ -0,0 +1,195 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// readPlanResource reads a plan resource and decodes its JSON into v
+func readPlanResource(t *testing.T, p *PlanResourceProvider, uri string, v any) {
+	t.Helper()
+	req := mcp.ReadResourceRequest{}
+	req.Params.URI = uri
+	contents, err := p.handleResourceRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", uri, err)
+	}
+	text := contents[0].(mcp.TextResourceContents).Text
+	if err := json.Unmarshal([]byte(text), v); err != nil {
+		t.Fatalf("failed to parse %s: %v (%s)", uri, err, text)
+	}
+}
+
+func TestPlanResourcePagination(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	created := make(map[string]bool)
+	for _, name := range []string{"One", "Two", "Three"} {
+		plan, err := s.planRepo.Create(ctx, "app", name, "")
+		if err != nil {
+			t.Fatalf("failed to create plan: %v", err)
+		}
+		created[plan.ID] = true
+	}
+	planID := ""
+	for id := range created {
+		planID = id
+	}
+	for _, title := range []string{"A", "B", "C"} {
+		if _, err := s.taskRepo.Create(ctx, planID, title, "", models.TaskPriorityMedium); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+	p := NewPlanResourceProvider(s.planRepo, s.taskRepo, 0)
+
+	// Without a cursor or limit the plans are returned as an array, as before
+	var all []*models.PlanResource
+	readPlanResource(t, p, "ai-tasks://plans/full", &all)
+	if len(all) != 3 {
+		t.Fatalf("expected 3 plans, got %d", len(all))
+	}
+
+	for _, base := range []string{"ai-tasks://plans/full", "ai-tasks://applications/app/plans/full"} {
+		seen := make(map[string]bool)
+		uri := base + "?limit=2"
+		for pages := 1; ; pages++ {
+			var page models.PlanPage
+			readPlanResource(t, p, uri, &page)
+			if page.Total != 3 || len(page.Plans) > 2 {
+				t.Fatalf("unexpected page of %s: total %d, %d plans", uri, page.Total, len(page.Plans))
+			}
+			for _, resource := range page.Plans {
+				if seen[resource.Plan.ID] {
+					t.Errorf("plan %s returned twice", resource.Plan.ID)
+				}
+				seen[resource.Plan.ID] = true
+			}
+			if page.NextCursor == "" {
+				if pages != 2 {
+					t.Errorf("expected 2 pages of %s, got %d", base, pages)
+				}
+				break
+			}
+			uri = base + "?cursor=" + page.NextCursor + "&limit=2"
+		}
+		if len(seen) != 3 {
+			t.Errorf("expected the pages of %s to hold 3 plans, got %d", base, len(seen))
+		}
+	}
+
+	// The tasks of a single plan are paged too
+	var resource models.PlanResource
+	readPlanResource(t, p, "ai-tasks://plans/"+planID+"/full?limit=2", &resource)
+	if len(resource.Tasks) != 2 || resource.TotalTasks != 3 || resource.NextCursor == "" {
+		t.Fatalf("unexpected first page: %d tasks of %d, cursor %q", len(resource.Tasks), resource.TotalTasks, resource.NextCursor)
+	}
+	var last models.PlanResource
+	readPlanResource(t, p, "ai-tasks://plans/"+planID+"/full?cursor="+resource.NextCursor, &last)
+	if len(last.Tasks) != 1 || last.Tasks[0].Title != "C" || last.NextCursor != "" {
+		t.Errorf("unexpected last page: %d tasks, cursor %q", len(last.Tasks), last.NextCursor)
+	}
+
+	for _, uri := range []string{
+		"ai-tasks://plans/full?limit=0",
+		"ai-tasks://plans/full?limit=many",
+		"ai-tasks://plans/full?cursor=%%%",
+	} {
+		req := mcp.ReadResourceRequest{}
+		req.Params.URI = uri
+		if _, err := p.handleResourceRequest(ctx, req); !errors.Is(err, ErrInvalidURI) {
+			t.Errorf("expected reading %s to fail with an invalid URI, got %v", uri, err)
+		}
+	}
+}
+
+func TestPlanResourceSizeGuard(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	var planID string
+	for i := 0; i < 3; i++ {
+		plan, err := s.planRepo.Create(ctx, "app", "Plan", strings.Repeat("d", 1000))
+		if err != nil {
+			t.Fatalf("failed to create plan: %v", err)
+		}
+		planID = plan.ID
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := s.taskRepo.Create(ctx, planID, "Task", strings.Repeat("d", 1000), models.TaskPriorityMedium); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+	p := NewPlanResourceProvider(s.planRepo, s.taskRepo, 4000)
+
+	var page models.PlanPage
+	readPlanResource(t, p, "ai-tasks://plans/full", &page)
+	if len(page.Plans) != 0 || len(page.Summaries) != 3 || page.Total != 3 {
+		t.Fatalf("expected 3 plan summaries, got %d plans and %d summaries", len(page.Plans), len(page.Summaries))
+	}
+	if !strings.Contains(page.Hint, "ai-tasks://plans/full?limit=") || page.Summaries[0].URI == "" {
+		t.Errorf("expected a hint to read the plans a page at a time, got %q", page.Hint)
+	}
+
+	var resource models.PlanResource
+	readPlanResource(t, p, "ai-tasks://plans/"+planID+"/full", &resource)
+	if !resource.Summarized || len(resource.Tasks) != 0 || len(resource.TaskSummaries) != 5 || resource.TotalTasks != 5 {
+		t.Fatalf("expected a summary of 5 tasks, got %d tasks and %d summaries", len(resource.Tasks), len(resource.TaskSummaries))
+	}
+	if !strings.Contains(resource.Hint, "/full?limit=") {
+		t.Errorf("expected a hint to read the tasks a page at a time, got %q", resource.Hint)
+	}
+
+	// A page small enough is returned whole
+	var small models.PlanResource
+	readPlanResource(t, p, "ai-tasks://plans/"+planID+"/full?limit=1", &small)
+	if small.Summarized || len(small.Tasks) != 1 {
+		t.Errorf("expected a page of 1 whole task, got %d tasks", len(small.Tasks))
+	}
+}
+
+// FuzzParseResourceURI checks that any URI an agent asks for is either parsed or rejected as invalid, without
+// a panic, and that the cursor of a parsed URI reads back the same page
+func FuzzParseResourceURI(f *testing.F) {
+	f.Add("ai-tasks://plans/full")
+	f.Add("ai-tasks://plans/abc/full?limit=2")
+	f.Add("ai-tasks://applications/app/plans/full?cursor=" + encodeCursor("20250101T000000.000000000/abc") + "&limit=5")
+	f.Add("ai-tasks://plans/full?cursor=%%%")
+	f.Add("ai-tasks://plans//full?limit=-1")
+	f.Add("ai-tasks://applications/a/b/plans/full")
+
+	f.Fuzz(func(t *testing.T, uri string) {
+		info, err := parseResourceURI(uri)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidURI) {
+				t.Fatalf("parsing %q failed with %v, expected an invalid URI", uri, err)
+			}
+			return
+		}
+
+		if info.page.limit < 0 {
+			t.Errorf("parsing %q gave the negative limit %d", uri, info.page.limit)
+		}
+		switch info.requestType {
+		case singlePlanRequest:
+			if info.planID == "" || strings.Contains(info.planID, "/") {
+				t.Errorf("parsing %q gave the plan ID %q", uri, info.planID)
+			}
+		case appPlansRequest:
+			if info.appID == "" || strings.Contains(info.appID, "/") {
+				t.Errorf("parsing %q gave the application ID %q", uri, info.appID)
+			}
+		}
+		if info.page.cursor != "" {
+			page, err := parseResourcePage("cursor=" + encodeCursor(info.page.cursor))
+			if err != nil || page.cursor != info.page.cursor {
+				t.Errorf("the cursor of %q does not read back: %q, %v", uri, page.cursor, err)
+			}
+		}
+	})
+}
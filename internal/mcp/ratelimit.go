@@ -0,0 +1,223 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// ErrRateLimited is returned for tool calls and resource reads rejected by the rate limit
+var ErrRateLimited error = &models.Error{Code: models.ErrorCodeRateLimited, Message: "rate limit exceeded"}
+
+// bucketIdleTimeout is how long the bucket of a client without requests is kept
+const bucketIdleTimeout = 10 * time.Minute
+
+// RateLimit is a token bucket limit: requests are allowed at Rate per second on average,
+// with bursts of up to Burst requests
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// enabled reports whether the limit restricts anything
+func (l RateLimit) enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// tokenBucket holds the tokens left to a single client
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter keeps one token bucket per client
+type rateLimiter struct {
+	limit RateLimit
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// newRateLimiter creates a rate limiter, or returns nil if the limit is disabled
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	if !limit.enabled() {
+		return nil
+	}
+	return &rateLimiter{
+		limit:   limit,
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// allow takes a token from the bucket of a client. If none is left, it returns false and how long
+// the client has to wait for the next token. A nil limiter allows everything.
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[client]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(l.limit.Burst), last: now}
+		l.buckets[client] = bucket
+	}
+
+	elapsed := now.Sub(bucket.last).Seconds()
+	bucket.tokens = math.Min(float64(l.limit.Burst), bucket.tokens+elapsed*l.limit.Rate)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / l.limit.Rate * float64(time.Second))
+		return false, wait
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+// sweep drops the buckets of clients that have been idle long enough for their bucket to refill
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < bucketIdleTimeout {
+		return
+	}
+	l.lastSweep = now
+	for client, bucket := range l.buckets {
+		if now.Sub(bucket.last) >= bucketIdleTimeout {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// requestLimiter applies the general and the expensive operation rate limits to tool calls and resource reads.
+// Expensive operations count against both limits.
+type requestLimiter struct {
+	general   *rateLimiter
+	expensive *rateLimiter
+}
+
+// newRequestLimiter creates the request limiter of a server configuration, or returns nil if no limit is set
+func newRequestLimiter(config ServerConfig) *requestLimiter {
+	general := newRateLimiter(RateLimit{Rate: config.RateLimitPerSecond, Burst: config.RateLimitBurst})
+	expensive := newRateLimiter(RateLimit{Rate: config.ExpensiveRateLimitPerSecond, Burst: config.ExpensiveRateLimitBurst})
+	if general == nil && expensive == nil {
+		return nil
+	}
+	return &requestLimiter{general: general, expensive: expensive}
+}
+
+// check takes a token for an operation of the client in the context, returning an error if it is throttled
+func (l *requestLimiter) check(ctx context.Context, operation string, expensive bool) error {
+	if l == nil {
+		return nil
+	}
+
+	client := clientIdentityFromContext(ctx)
+	if ok, wait := l.general.allow(client); !ok {
+		return fmt.Errorf("%w for %s, retry in %s", ErrRateLimited, operation, wait.Round(time.Millisecond))
+	}
+	if expensive {
+		if ok, wait := l.expensive.allow(client); !ok {
+			return fmt.Errorf("%w for expensive operation %s, retry in %s", ErrRateLimited, operation, wait.Round(time.Millisecond))
+		}
+	}
+	return nil
+}
+
+// rateLimitMiddleware rejects tool calls of throttled clients with a tool error. The limits are looked up
+// on every call, so reloaded limits apply right away.
+func (s *MCPGoServer) rateLimitMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		name := request.Params.Name
+		if err := s.limiter.Load().check(ctx, name, isExpensiveTool(name)); err != nil {
+			return s.toolError("", err), nil
+		}
+		return next(ctx, request)
+	}
+}
+
+// rateLimitResourceHandler wraps the handler of a resource template so reads of throttled clients fail.
+// Templates returning full plans with all their tasks and notes are expensive.
+func (s *MCPGoServer) rateLimitResourceHandler(
+	uriTemplate string,
+	next server.ResourceTemplateHandlerFunc,
+) server.ResourceTemplateHandlerFunc {
+	expensive := isExpensiveResource(uriTemplate)
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		if err := s.limiter.Load().check(ctx, request.Params.URI, expensive); err != nil {
+			return nil, err
+		}
+		return next(ctx, request)
+	}
+}
+
+// isExpensiveResource reports whether a resource template returns full plans with all their tasks and notes
+func isExpensiveResource(uriTemplate string) bool {
+	path, _, _ := strings.Cut(uriTemplate, "{?")
+	return strings.HasSuffix(path, "/full")
+}
+
+// isExpensiveTool reports whether a tool reads many plans or tasks at once
+func isExpensiveTool(name string) bool {
+	return strings.HasPrefix(name, "list_") || strings.HasPrefix(name, "export_") || name == "check_data_integrity"
+}
+
+// clientIdentityKey is the context key of the client identity
+type clientIdentityKey struct{}
+
+// authenticatedTokenKey is the context key of the bearer token an HTTP request was authenticated with
+type authenticatedTokenKey struct{}
+
+// withAuthenticatedToken records in the context of a request the bearer token the scope handler validated
+func withAuthenticatedToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, authenticatedTokenKey{}, token)
+}
+
+// withClientIdentity stores the identity used to rate limit the client of an HTTP request in the context.
+// Clients authenticated with a bearer token are identified by a hash of it, others by their IP address.
+// Credentials nobody validated are ignored, so clients cannot get a fresh bucket by sending a new one.
+func withClientIdentity(ctx context.Context, r *http.Request) context.Context {
+	var identity string
+	if token, ok := r.Context().Value(authenticatedTokenKey{}).(string); ok {
+		sum := sha256.Sum256([]byte(token))
+		identity = "token:" + hex.EncodeToString(sum[:8])
+	} else {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		identity = "ip:" + host
+	}
+	return context.WithValue(ctx, clientIdentityKey{}, identity)
+}
+
+// clientIdentityFromContext returns the client identity stored in the context.
+// Without one, as for STDIO, the MCP session identifies the client.
+func clientIdentityFromContext(ctx context.Context) string {
+	if identity, ok := ctx.Value(clientIdentityKey{}).(string); ok {
+		return identity
+	}
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return "session:" + session.SessionID()
+	}
+	return "local"
+}
@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(RateLimit{Rate: 2, Burst: 3})
+	limiter.now = func() time.Time { return now }
+
+	// The burst is available at once
+	for i := 0; i < 3; i++ {
+		if ok, _ := limiter.allow("a"); !ok {
+			t.Fatalf("request %d should be allowed", i+1)
+		}
+	}
+	ok, wait := limiter.allow("a")
+	if ok {
+		t.Fatal("request beyond the burst should be throttled")
+	}
+	if wait != 500*time.Millisecond {
+		t.Errorf("wait = %s, expected 500ms", wait)
+	}
+
+	// Other clients have their own bucket
+	if ok, _ := limiter.allow("b"); !ok {
+		t.Error("another client should be allowed")
+	}
+
+	// Tokens refill at the rate
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := limiter.allow("a"); !ok {
+		t.Error("request should be allowed after a refill")
+	}
+	if ok, _ := limiter.allow("a"); ok {
+		t.Error("only one token should have been refilled")
+	}
+
+	// Idle buckets are dropped
+	now = now.Add(bucketIdleTimeout)
+	limiter.allow("c")
+	if len(limiter.buckets) != 1 {
+		t.Errorf("got %d buckets after the sweep, expected 1", len(limiter.buckets))
+	}
+}
+
+func TestRequestLimiterCheck(t *testing.T) {
+	if limiter := newRequestLimiter(ServerConfig{RateLimitBurst: 20, ExpensiveRateLimitBurst: 5}); limiter != nil {
+		t.Fatal("expected no limiter without rates")
+	}
+
+	limiter := newRequestLimiter(ServerConfig{
+		RateLimitPerSecond:          1,
+		RateLimitBurst:              3,
+		ExpensiveRateLimitPerSecond: 1,
+		ExpensiveRateLimitBurst:     1,
+	})
+	ctx := context.Background()
+
+	if err := limiter.check(ctx, "list_plans", true); err != nil {
+		t.Fatalf("first expensive request should be allowed, got %v", err)
+	}
+	if err := limiter.check(ctx, "list_plans", true); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("second expensive request should be throttled, got %v", err)
+	}
+
+	// Expensive requests also count against the general limit, which has one token left
+	if err := limiter.check(ctx, "get_task", false); err != nil {
+		t.Errorf("cheap request should be allowed, got %v", err)
+	}
+	if err := limiter.check(ctx, "get_task", false); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("general limit should be exhausted, got %v", err)
+	}
+}
+
+func TestClientIdentity(t *testing.T) {
+	if got := clientIdentityFromContext(context.Background()); got != "local" {
+		t.Errorf("identity without request = %q, expected local", got)
+	}
+
+	r := httptest.NewRequest("POST", "/mcp", nil)
+	r.RemoteAddr = "192.0.2.1:5000"
+	if got := clientIdentityFromContext(withClientIdentity(context.Background(), r)); got != "ip:192.0.2.1" {
+		t.Errorf("identity = %q, expected ip:192.0.2.1", got)
+	}
+
+	// Tokens nobody validated do not identify a client, so rotating them does not escape the limit
+	identities := make(map[string]bool)
+	for _, token := range []string{"Bearer bogus-1", "Bearer bogus-2"} {
+		r.Header.Set("Authorization", token)
+		identities[clientIdentityFromContext(withClientIdentity(context.Background(), r))] = true
+	}
+	if len(identities) != 1 || !identities["ip:192.0.2.1"] {
+		t.Errorf("clients with unvalidated tokens should share the bucket of their IP, got %v", identities)
+	}
+
+	// Tokens validated by the scope handler identify the client wherever it connects from
+	s := &MCPGoServer{config: ServerConfig{TokenRoles: TokenRoles{"secret": "executor"}}}
+	var identity string
+	handler := s.scopeHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity = clientIdentityFromContext(withClientIdentity(r.Context(), r))
+	}))
+	identify := func(remoteAddr string) string {
+		identity = ""
+		req := httptest.NewRequest("POST", "/mcp", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("Authorization", "Bearer secret")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		return identity
+	}
+	first, second := identify("192.0.2.1:5000"), identify("192.0.2.2:5000")
+	if first != second || !strings.HasPrefix(first, "token:") {
+		t.Errorf("clients with the same token should share an identity, got %q and %q", first, second)
+	}
+}
+
+func TestIsExpensiveTool(t *testing.T) {
+	for name, expected := range map[string]bool{
+		"list_plans":           true,
+		"list_tasks_by_tag":    true,
+		"export_tasks_csv":     true,
+		"check_data_integrity": true,
+		"get_plan":             false,
+		"create_task":          false,
+	} {
+		if got := isExpensiveTool(name); got != expected {
+			t.Errorf("isExpensiveTool(%q) = %v, expected %v", name, got, expected)
+		}
+	}
+}
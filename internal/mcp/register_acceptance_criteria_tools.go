@@ -0,0 +1,182 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// registerAcceptanceCriteriaTools registers the task acceptance criteria tools with the MCP server
+func (s *MCPGoServer) registerAcceptanceCriteriaTools() {
+	s.registerAddAcceptanceCriterionTool()
+	s.registerToggleAcceptanceCriterionTool()
+	s.registerRemoveAcceptanceCriterionTool()
+}
+
+func (s *MCPGoServer) registerAddAcceptanceCriterionTool() {
+	tool := mcp.NewTool("add_acceptance_criterion",
+		createTool,
+		mcp.WithDescription(
+			"Add an acceptance criterion to a task, a condition the task must meet to be done that is checked "+
+				"off on its own rather than written into the description",
+		),
+		mcp.WithString("task_id",
+			mcp.Required(),
+			mcp.Description("Task ID"),
+		),
+		mcp.WithString("text",
+			mcp.Required(),
+			mcp.Description("Text of the acceptance criterion"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskID, err := request.RequireString("task_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		text, err := request.RequireString("text")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		task, err := s.taskRepo.Get(ctx, taskID)
+		if err != nil {
+			return s.toolError("Failed to get task", err), nil
+		}
+
+		task.AcceptanceCriteria, err = addAcceptanceCriteria(task.AcceptanceCriteria, taskID, text)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		return s.updateAcceptanceCriteria(ctx, task)
+	})
+}
+
+func (s *MCPGoServer) registerToggleAcceptanceCriterionTool() {
+	tool := mcp.NewTool("toggle_acceptance_criterion",
+		changeTool,
+		mcp.WithDescription("Mark an acceptance criterion of a task as met or not met"),
+		mcp.WithString("task_id",
+			mcp.Required(),
+			mcp.Description("Task ID"),
+		),
+		mcp.WithString("criterion_id",
+			mcp.Required(),
+			mcp.Description("Acceptance criterion ID"),
+		),
+		mcp.WithBoolean("met",
+			mcp.Description("New met state (optional, flips the current state if omitted)"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskID, err := request.RequireString("task_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		criterionID, err := request.RequireString("criterion_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		task, err := s.taskRepo.Get(ctx, taskID)
+		if err != nil {
+			return s.toolError("Failed to get task", err), nil
+		}
+
+		index := models.AcceptanceCriterionIndex(task.AcceptanceCriteria, criterionID)
+		if index < 0 {
+			return s.invalidArgument(models.NewNotFoundError(models.EntityAcceptanceCriterion, criterionID)), nil
+		}
+		criterion := task.AcceptanceCriteria[index]
+		if _, ok := request.GetArguments()["met"]; ok {
+			criterion.Met = request.GetBool("met", false)
+		} else {
+			criterion.Met = !criterion.Met
+		}
+
+		return s.updateAcceptanceCriteria(ctx, task)
+	})
+}
+
+func (s *MCPGoServer) registerRemoveAcceptanceCriterionTool() {
+	tool := mcp.NewTool("remove_acceptance_criterion",
+		deleteTool,
+		mcp.WithDescription("Remove an acceptance criterion from a task"),
+		mcp.WithString("task_id",
+			mcp.Required(),
+			mcp.Description("Task ID"),
+		),
+		mcp.WithString("criterion_id",
+			mcp.Required(),
+			mcp.Description("Acceptance criterion ID"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskID, err := request.RequireString("task_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		criterionID, err := request.RequireString("criterion_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		task, err := s.taskRepo.Get(ctx, taskID)
+		if err != nil {
+			return s.toolError("Failed to get task", err), nil
+		}
+
+		index := models.AcceptanceCriterionIndex(task.AcceptanceCriteria, criterionID)
+		if index < 0 {
+			return s.invalidArgument(models.NewNotFoundError(models.EntityAcceptanceCriterion, criterionID)), nil
+		}
+		task.AcceptanceCriteria = slices.Delete(task.AcceptanceCriteria, index, index+1)
+
+		return s.updateAcceptanceCriteria(ctx, task)
+	})
+}
+
+// updateAcceptanceCriteria stores a task with changed acceptance criteria and returns it as the tool result
+func (s *MCPGoServer) updateAcceptanceCriteria(ctx context.Context, task *models.Task) (*mcp.CallToolResult, error) {
+	err := s.taskRepo.Update(ctx, task)
+	if err != nil {
+		return s.toolError("Failed to update task", err), nil
+	}
+
+	taskJson, err := json.Marshal(task)
+	if err != nil {
+		return s.toolError("Failed to marshal task", err), nil
+	}
+	return mcp.NewToolResultText(string(taskJson)), nil
+}
+
+// addAcceptanceCriteria appends new, not yet met acceptance criteria to those of a task
+func addAcceptanceCriteria(
+	criteria []*models.AcceptanceCriterion,
+	taskID string,
+	texts ...string,
+) ([]*models.AcceptanceCriterion, error) {
+	for _, text := range texts {
+		if strings.TrimSpace(text) == "" {
+			return nil, models.NewValidationError(models.EntityTask, taskID, "acceptance criterion text cannot be empty")
+		}
+		if len(criteria) >= models.MaxAcceptanceCriteria {
+			return nil, models.NewValidationError(models.EntityTask, taskID,
+				"cannot have more than %d acceptance criteria", models.MaxAcceptanceCriteria)
+		}
+		criteria = append(criteria, models.NewAcceptanceCriterion(uuid.New().String(), text))
+	}
+	return criteria, nil
+}
@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+func TestAcceptanceCriteria(t *testing.T) {
+	repos := newTestRepos(t)
+	repos.taskRepo.RequireMetAcceptanceCriteria(true)
+	s := NewMCPGoServer(repos.planRepo, repos.taskRepo)
+	ctx := context.Background()
+
+	plan, err := s.planRepo.Create(ctx, "app-1", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+
+	result := callTool(t, s, "create_task", map[string]any{
+		"plan_id":             plan.ID,
+		"title":               "Add login",
+		"acceptance_criteria": []any{"Users can log in", "Failed logins are rate limited"},
+	})
+	if result.IsError {
+		t.Fatalf("create_task failed: %s", toolResultText(result))
+	}
+	var task models.Task
+	if err := json.Unmarshal([]byte(toolResultText(result)), &task); err != nil {
+		t.Fatalf("failed to decode task: %v", err)
+	}
+	if len(task.AcceptanceCriteria) != 2 || task.AcceptanceCriteria[0].Met {
+		t.Fatalf("acceptance criteria = %+v, want two unmet criteria", task.AcceptanceCriteria)
+	}
+
+	result = callTool(t, s, "add_acceptance_criterion", map[string]any{"task_id": task.ID, "text": "Docs are updated"})
+	if result.IsError {
+		t.Fatalf("add_acceptance_criterion failed: %s", toolResultText(result))
+	}
+	if result := callTool(t, s, "add_acceptance_criterion", map[string]any{"task_id": task.ID, "text": " "}); !result.IsError {
+		t.Error("add_acceptance_criterion should reject an empty criterion")
+	}
+
+	// The task cannot be completed while a criterion is not met
+	first, second := task.AcceptanceCriteria[0].ID, task.AcceptanceCriteria[1].ID
+	for _, arguments := range []map[string]any{
+		{"task_id": task.ID, "criterion_id": first},
+		{"task_id": task.ID, "criterion_id": second, "met": true},
+	} {
+		if result := callTool(t, s, "toggle_acceptance_criterion", arguments); result.IsError {
+			t.Fatalf("toggle_acceptance_criterion(%v) failed: %s", arguments, toolResultText(result))
+		}
+	}
+	result = callTool(t, s, "update_task", map[string]any{"id": task.ID, "status": "completed"})
+	if !result.IsError || !strings.Contains(toolResultText(result), "Docs are updated") {
+		t.Errorf("completing a task with an unmet criterion should fail, got %s", toolResultText(result))
+	}
+
+	stored, err := s.taskRepo.Get(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	result = callTool(t, s, "remove_acceptance_criterion", map[string]any{
+		"task_id":      task.ID,
+		"criterion_id": stored.AcceptanceCriteria[2].ID,
+	})
+	if result.IsError {
+		t.Fatalf("remove_acceptance_criterion failed: %s", toolResultText(result))
+	}
+	result = callTool(t, s, "toggle_acceptance_criterion", map[string]any{"task_id": task.ID, "criterion_id": "missing"})
+	if !result.IsError || !strings.Contains(toolResultText(result), "not found") {
+		t.Errorf("toggling a missing criterion should fail, got %s", toolResultText(result))
+	}
+
+	if result := callTool(t, s, "update_task", map[string]any{"id": task.ID, "status": "completed"}); result.IsError {
+		t.Errorf("completing a task with all criteria met failed: %s", toolResultText(result))
+	}
+}
@@ -0,0 +1,140 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// defaultActivityLimit is the number of activity entries returned when no limit is given
+const defaultActivityLimit = 50
+
+// registerActivityTools registers the tools of the agent activity journal with the MCP server
+func (s *MCPGoServer) registerActivityTools() {
+	s.registerLogAgentActivityTool()
+	s.registerGetAgentActivityTool()
+}
+
+// registerLogAgentActivityTool registers a tool to record what an agent did in a work session
+func (s *MCPGoServer) registerLogAgentActivityTool() {
+	tool := mcp.NewTool("log_agent_activity",
+		createTool,
+		mcp.WithDescription(
+			"Record what you did in a work session on a plan, such as a decision, an action or a blocker, in the "+
+				"activity journal of the plan. The journal gives people a narrative of the work, separate from "+
+				"the notes of the plan and its tasks",
+		),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		mcp.WithString("summary",
+			mcp.Required(),
+			mcp.Description("What was done, in a sentence or a short paragraph"),
+		),
+		mcp.WithString("session",
+			mcp.Description("Name of the work session, grouping its entries (optional)"),
+		),
+		mcp.WithString("kind",
+			mcp.Description("Kind of the entry, such as decision, action or blocker (optional)"),
+		),
+		mcp.WithArray("task_ids",
+			mcp.Description("IDs of the tasks of the plan the entry is about (optional)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithObject("details",
+			mcp.Description("Free-form structured details, such as the files changed or the commands run (optional)"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		summary, err := request.RequireString("summary")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		activity := &models.AgentActivity{
+			Session: request.GetString("session", ""),
+			Kind:    request.GetString("kind", ""),
+			Summary: summary,
+			TaskIDs: request.GetStringSlice("task_ids", nil),
+		}
+		if raw, ok := request.GetArguments()["details"]; ok {
+			details, ok := raw.(map[string]any)
+			if !ok {
+				return s.validationError("details must be an object"), nil
+			}
+			activity.Details = details
+		}
+		if err := s.checkPlanTasks(ctx, planID, activity.TaskIDs); err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		activity, err = s.planRepo.LogActivity(ctx, planID, activity)
+		if err != nil {
+			return s.toolError("Failed to log agent activity", err), nil
+		}
+
+		activityJson, err := json.Marshal(activity)
+		if err != nil {
+			return s.toolError("Failed to marshal activity", err), nil
+		}
+		return mcp.NewToolResultText(string(activityJson)), nil
+	})
+}
+
+// registerGetAgentActivityTool registers a tool to read the activity journal of a plan
+func (s *MCPGoServer) registerGetAgentActivityTool() {
+	tool := mcp.NewTool("get_agent_activity",
+		readOnlyTool,
+		mcp.WithDescription(
+			"Get the activity journal of a plan, newest first, with who logged each entry and when. Page back "+
+				"through older entries by passing the ID of the last entry returned as before",
+		),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		mcp.WithString("session",
+			mcp.Description("Only return the entries of this work session (optional)"),
+		),
+		mcp.WithString("before",
+			mcp.Description("Only return entries older than the entry with this ID (optional)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of entries to return (optional, defaults to %d)", defaultActivityLimit)),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		activities, err := s.planRepo.ListActivity(ctx, planID, storage.AgentActivityQuery{
+			Session: request.GetString("session", ""),
+			Before:  request.GetString("before", ""),
+			Limit:   int64(request.GetInt("limit", defaultActivityLimit)),
+		})
+		if err != nil {
+			return s.toolError("Failed to get agent activity", err), nil
+		}
+
+		activitiesJson, err := json.Marshal(activities)
+		if err != nil {
+			return s.toolError("Failed to marshal activity", err), nil
+		}
+		return mcp.NewToolResultText(string(activitiesJson)), nil
+	})
+}
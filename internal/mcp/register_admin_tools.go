@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// registerAdminTools registers the maintenance tools with the MCP server.
+// The tools are only available when the features behind them are enabled by the operator.
+func (s *MCPGoServer) registerAdminTools() {
+	if s.integrity != nil {
+		s.registerCheckDataIntegrityTool()
+	}
+	if s.retention != nil {
+		s.registerGetRetentionStatsTool()
+	}
+}
+
+func (s *MCPGoServer) registerCheckDataIntegrityTool() {
+	tool := mcp.NewTool("check_data_integrity",
+		updateTool,
+		mcp.WithDescription(
+			"Scan the database for inconsistencies between plans and tasks: plans missing from the plan list, "+
+				"tasks missing from the task set of their plan, task set entries without a stored task, tasks "+
+				"sharing an order value and plans whose task counters are wrong. Reports each issue and can optionally repair them. "+
+				"Scans every key, so avoid running it often on large databases",
+		),
+		mcp.WithBoolean("repair",
+			mcp.Description("Repair the issues found (optional, defaults to false, which only reports them)"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		report, err := s.integrity.Check(ctx, request.GetBool("repair", false))
+		if err != nil {
+			return s.toolError("Failed to check data integrity", err), nil
+		}
+
+		reportJson, err := json.Marshal(report)
+		if err != nil {
+			return s.toolError("Failed to marshal integrity report", err), nil
+		}
+		return mcp.NewToolResultText(string(reportJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerGetRetentionStatsTool() {
+	tool := mcp.NewTool("get_retention_stats",
+		readOnlyTool,
+		mcp.WithDescription(
+			"Get how many completed and cancelled plans, and tasks within them, the retention policy has "+
+				"archived or deleted since the server started",
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		statsJson, err := json.Marshal(s.retention.Stats())
+		if err != nil {
+			return s.toolError("Failed to marshal retention stats", err), nil
+		}
+		return mcp.NewToolResultText(string(statsJson)), nil
+	})
+}
@@ -0,0 +1,171 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// registerApplicationTools registers the application tools with the MCP server. Applications can only be
+// registered when the server has an application repository; without one, the applications plans refer to
+// are listed.
+func (s *MCPGoServer) registerApplicationTools() {
+	s.registerListApplicationsTool()
+	s.registerGetApplicationStatusTool()
+	if s.applicationRepo == nil {
+		return
+	}
+
+	s.registerCreateApplicationTool()
+	s.registerGetApplicationTool()
+}
+
+func (s *MCPGoServer) registerCreateApplicationTool() {
+	tool := mcp.NewTool("create_application",
+		createTool,
+		mcp.WithDescription(
+			"Register an application, the product or workspace plans belong to. Its ID is the application_id "+
+				"that plans refer to",
+		),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Application ID, such as a repository or product name, without whitespace"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Display name of the application, defaults to its ID (optional)"),
+		),
+		mcp.WithString("description",
+			mcp.Description("Description of the application (optional)"),
+		),
+		mcp.WithObject("metadata",
+			mcp.Description("Metadata entries such as a repository URL, as an object mapping keys to string values "+
+				"(optional)"),
+			mcp.AdditionalProperties(metadataValueSchema),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		var metadata map[string]string
+		if _, ok := request.GetArguments()["metadata"]; ok {
+			metadata, err = parseMetadataArgument(request)
+			if err != nil {
+				return s.invalidArgument(err), nil
+			}
+		}
+
+		application, err := s.applicationRepo.Create(
+			ctx, id, request.GetString("name", ""), request.GetString("description", ""), metadata,
+		)
+		if err != nil {
+			return s.toolError("Failed to create application", err), nil
+		}
+
+		applicationJson, err := json.Marshal(application)
+		if err != nil {
+			return s.toolError("Failed to marshal application", err), nil
+		}
+		return mcp.NewToolResultText(string(applicationJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerListApplicationsTool() {
+	tool := mcp.NewTool("list_applications",
+		readOnlyTool,
+		mcp.WithDescription(
+			"List the applications by ID to discover the workspaces that exist: the registered applications "+
+				"and the applications plans refer to, each with its number of plans",
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		inUse, err := s.planRepo.ListApplications(ctx)
+		if err != nil {
+			return s.toolError("Failed to list applications", err), nil
+		}
+
+		var registered []*models.Application
+		if s.applicationRepo != nil {
+			registered, err = s.applicationRepo.List(ctx)
+			if err != nil {
+				return s.toolError("Failed to list applications", err), nil
+			}
+		}
+		applications := models.NewApplicationListings(registered, inUse)
+
+		applicationsJson, err := json.Marshal(applications)
+		if err != nil {
+			return s.toolError("Failed to marshal applications", err), nil
+		}
+		return mcp.NewToolResultText(string(applicationsJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerGetApplicationTool() {
+	tool := mcp.NewTool("get_application",
+		readOnlyTool,
+		mcp.WithDescription("Retrieve a registered application"),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Application ID"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		application, err := s.applicationRepo.Get(ctx, id)
+		if err != nil {
+			return s.toolError("Failed to get application", err), nil
+		}
+
+		applicationJson, err := json.Marshal(application)
+		if err != nil {
+			return s.toolError("Failed to marshal application", err), nil
+		}
+		return mcp.NewToolResultText(string(applicationJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerGetApplicationStatusTool() {
+	tool := mcp.NewTool("get_application_status",
+		readOnlyTool,
+		mcp.WithDescription(
+			"Get the status of an application rolled up from all of its plans: the number of plans by status, "+
+				"task counts across the plans and the overall percent complete. The rollup is kept up to date "+
+				"as plans and tasks change, so it is cheap to call for applications with many plans",
+		),
+		mcp.WithString("application_id",
+			mcp.Required(),
+			mcp.Description("Application ID"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		applicationID, err := request.RequireString("application_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		status, err := s.planRepo.GetApplicationStatus(ctx, applicationID)
+		if err != nil {
+			return s.toolError("Failed to get application status", err), nil
+		}
+
+		statusJson, err := json.Marshal(status)
+		if err != nil {
+			return s.toolError("Failed to marshal application status", err), nil
+		}
+		return mcp.NewToolResultText(string(statusJson)), nil
+	})
+}
@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+func TestGetApplicationStatus(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	status := func() models.ApplicationStatus {
+		t.Helper()
+		result := callTool(t, s, "get_application_status", map[string]any{"application_id": "app-1"})
+		var status models.ApplicationStatus
+		if err := json.Unmarshal([]byte(toolResultText(result)), &status); err != nil {
+			t.Fatalf("failed to decode result %s: %v", toolResultText(result), err)
+		}
+		return status
+	}
+
+	plan, err := s.planRepo.Create(ctx, "app-1", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	other, err := s.planRepo.Create(ctx, "app-1", "Other plan", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	var tasks []*models.Task
+	for _, title := range []string{"First", "Second"} {
+		task, err := s.taskRepo.Create(ctx, plan.ID, title, "", models.TaskPriorityMedium)
+		if err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+		tasks = append(tasks, task)
+	}
+	if got := status(); got.TotalPlans != 2 || got.TotalTasks != 2 || got.Status != models.PlanStatusNew {
+		t.Errorf("unexpected status of new plans: %+v", got)
+	}
+
+	// Task changes roll up through their plan
+	tasks[0].Status = models.TaskStatusInProgress
+	if err := s.taskRepo.Update(ctx, tasks[0]); err != nil {
+		t.Fatalf("failed to start task: %v", err)
+	}
+	tasks[1].Status = models.TaskStatusCompleted
+	if err := s.taskRepo.Update(ctx, tasks[1]); err != nil {
+		t.Fatalf("failed to complete task: %v", err)
+	}
+	got := status()
+	if got.PercentComplete != 50 || got.PlanStatusCounts[models.PlanStatusInProgress] != 1 ||
+		got.Status != models.PlanStatusInProgress {
+		t.Errorf("unexpected status after starting a plan: %+v", got)
+	}
+
+	// Deleted plans leave the rollup
+	if err := s.planRepo.Delete(ctx, other.ID); err != nil {
+		t.Fatalf("failed to delete plan: %v", err)
+	}
+	tasks[0].Status = models.TaskStatusCompleted
+	if err := s.taskRepo.Update(ctx, tasks[0]); err != nil {
+		t.Fatalf("failed to complete task: %v", err)
+	}
+	if got := status(); got.TotalPlans != 1 || got.PercentComplete != 100 || got.Status != models.PlanStatusCompleted {
+		t.Errorf("unexpected status after completing the plan: %+v", got)
+	}
+}
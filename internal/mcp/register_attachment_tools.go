@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// registerAttachmentTools registers the task attachment tools with the MCP server.
+// The tools are only available when an attachment store is configured.
+func (s *MCPGoServer) registerAttachmentTools() {
+	if s.attachments == nil {
+		return
+	}
+
+	s.registerAddTaskAttachmentTool()
+	s.registerListTaskAttachmentsTool()
+	s.registerGetTaskAttachmentTool()
+}
+
+func (s *MCPGoServer) registerAddTaskAttachmentTool() {
+	tool := mcp.NewTool("add_task_attachment",
+		createTool,
+		mcp.WithDescription(
+			"Attach a small text artifact to a task, such as a diff, a log excerpt or a JSON document, "+
+				"so that it can be read back later without cluttering the task notes. "+
+				"Attachments are limited in size and number per task",
+		),
+		mcp.WithString("task_id",
+			mcp.Required(),
+			mcp.Description("Task ID"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the attachment, such as a file name"),
+		),
+		mcp.WithString("content_type",
+			mcp.Description(
+				"Media type of the content (optional, defaults to text/plain). Text types such as text/x-diff and "+
+					"text/markdown, application/json, application/x-ndjson, application/xml and application/yaml "+
+					"are accepted",
+			),
+		),
+		mcp.WithString("content",
+			mcp.Required(),
+			mcp.Description("Content of the attachment as UTF-8 text"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskID, err := request.RequireString("task_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		name, err := request.RequireString("name")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		content, err := request.RequireString("content")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		contentType := request.GetString("content_type", "text/plain")
+		attachment, err := s.attachments.Add(ctx, taskID, name, contentType, content)
+		if err != nil {
+			return s.toolError("Failed to add task attachment", err), nil
+		}
+
+		attachmentJson, err := json.Marshal(attachment)
+		if err != nil {
+			return s.toolError("Failed to marshal attachment", err), nil
+		}
+		return mcp.NewToolResultText(string(attachmentJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerListTaskAttachmentsTool() {
+	tool := mcp.NewTool("list_task_attachments",
+		readOnlyTool,
+		mcp.WithDescription("List the attachments of a task, oldest first, without their content"),
+		mcp.WithString("task_id",
+			mcp.Required(),
+			mcp.Description("Task ID"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskID, err := request.RequireString("task_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		attachments, err := s.attachments.List(ctx, taskID)
+		if err != nil {
+			return s.toolError("Failed to list task attachments", err), nil
+		}
+
+		attachmentsJson, err := json.Marshal(attachments)
+		if err != nil {
+			return s.toolError("Failed to marshal attachments", err), nil
+		}
+		return mcp.NewToolResultText(string(attachmentsJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerGetTaskAttachmentTool() {
+	tool := mcp.NewTool("get_task_attachment",
+		readOnlyTool,
+		mcp.WithDescription("Get an attachment of a task with its content"),
+		mcp.WithString("task_id",
+			mcp.Required(),
+			mcp.Description("Task ID"),
+		),
+		mcp.WithString("attachment_id",
+			mcp.Required(),
+			mcp.Description("Attachment ID, as returned by add_task_attachment or list_task_attachments"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskID, err := request.RequireString("task_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		attachmentID, err := request.RequireString("attachment_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		attachment, err := s.attachments.Get(ctx, taskID, attachmentID)
+		if err != nil {
+			return s.toolError("Failed to get task attachment", err), nil
+		}
+
+		attachmentJson, err := json.Marshal(attachment)
+		if err != nil {
+			return s.toolError("Failed to marshal attachment", err), nil
+		}
+		return mcp.NewToolResultText(string(attachmentJson)), nil
+	})
+}
@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
+)
+
+// registerPlanChangeTools registers the tools changing several parts of a plan at once with the MCP server
+func (s *MCPGoServer) registerPlanChangeTools() {
+	s.registerApplyPlanChangesTool()
+}
+
+func (s *MCPGoServer) registerApplyPlanChangesTool() {
+	tool := mcp.NewTool("apply_plan_changes",
+		changeTool,
+		mcp.WithDescription(
+			"Apply a batch of changes to a plan all or nothing: create tasks, update tasks, reorder the tasks "+
+				"and update the plan itself. Every change is checked before any is made, and the changes are "+
+				"written in one transaction, so if one fails nothing is written and the plan is never left half "+
+				"edited. Changes are applied in order; give a created task a ref to update or reorder it in later "+
+				"changes of the batch.",
+		),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID to change"),
+		),
+		mcp.WithArray("changes",
+			mcp.Required(),
+			mcp.Description(
+				"Changes to apply in order, each with an op: create_task (title, and optionally ref, "+
+					"description, status, priority and estimate), update_task (task_id, which may be the ref of "+
+					"a created task, the fields to change and a completion_note when completing it), reorder_tasks "+
+					"(task_ids, listing every task of the plan in its new order) or update_plan (name, description, "+
+					"status or priority)",
+			),
+			mcp.MinItems(1),
+			mcp.Items(planChangeSchema),
+		),
+		withIdempotencyKey(),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		changes, err := parsePlanChanges(request)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		result, err := s.planChanges.ApplyChanges(ctx, planID, changes)
+		if err != nil {
+			return s.toolError("Failed to apply plan changes", err), nil
+		}
+
+		resultJson, err := json.Marshal(result)
+		if err != nil {
+			return s.toolError("Failed to marshal result", err), nil
+		}
+		return mcp.NewToolResultText(string(resultJson)), nil
+	})
+}
+
+// parsePlanChanges reads the changes of an apply_plan_changes call, which the input schema has already
+// checked
+func parsePlanChanges(request mcp.CallToolRequest) ([]services.PlanChange, error) {
+	raw, ok := request.GetArguments()["changes"]
+	if !ok {
+		return nil, errors.New("changes is required")
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read changes: %v", err)
+	}
+	var changes []services.PlanChange
+	if err := json.Unmarshal(data, &changes); err != nil {
+		return nil, fmt.Errorf("failed to read changes: %v", err)
+	}
+	return changes, nil
+}
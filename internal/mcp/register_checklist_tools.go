@@ -0,0 +1,140 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// registerChecklistTools registers the task checklist tools with the MCP server
+func (s *MCPGoServer) registerChecklistTools() {
+	s.registerAddChecklistItemTool()
+	s.registerToggleChecklistItemTool()
+	s.registerRemoveChecklistItemTool()
+}
+
+func (s *MCPGoServer) registerAddChecklistItemTool() {
+	tool := mcp.NewTool("add_checklist_item",
+		createTool,
+		mcp.WithDescription(
+			"Add a lightweight checklist item to a task to track micro-steps without creating separate tasks",
+		),
+		mcp.WithString("task_id",
+			mcp.Required(),
+			mcp.Description("Task ID"),
+		),
+		mcp.WithString("text",
+			mcp.Required(),
+			mcp.Description("Text of the checklist item"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskID, err := request.RequireString("task_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		text, err := request.RequireString("text")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		task, err := s.taskRepo.AddChecklistItem(ctx, taskID, text)
+		if err != nil {
+			return s.toolError("Failed to add checklist item", err), nil
+		}
+
+		taskJson, err := json.Marshal(task)
+		if err != nil {
+			return s.toolError("Failed to marshal task", err), nil
+		}
+		return mcp.NewToolResultText(string(taskJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerToggleChecklistItemTool() {
+	tool := mcp.NewTool("toggle_checklist_item",
+		changeTool,
+		mcp.WithDescription("Mark a checklist item as done or not done"),
+		mcp.WithString("task_id",
+			mcp.Required(),
+			mcp.Description("Task ID"),
+		),
+		mcp.WithString("item_id",
+			mcp.Required(),
+			mcp.Description("Checklist item ID"),
+		),
+		mcp.WithBoolean("done",
+			mcp.Description("New done state (optional, flips the current state if omitted)"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskID, err := request.RequireString("task_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		itemID, err := request.RequireString("item_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		var done *bool
+		if _, ok := request.GetArguments()["done"]; ok {
+			value := request.GetBool("done", false)
+			done = &value
+		}
+
+		task, err := s.taskRepo.ToggleChecklistItem(ctx, taskID, itemID, done)
+		if err != nil {
+			return s.toolError("Failed to toggle checklist item", err), nil
+		}
+
+		taskJson, err := json.Marshal(task)
+		if err != nil {
+			return s.toolError("Failed to marshal task", err), nil
+		}
+		return mcp.NewToolResultText(string(taskJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerRemoveChecklistItemTool() {
+	tool := mcp.NewTool("remove_checklist_item",
+		deleteTool,
+		mcp.WithDescription("Remove a checklist item from a task"),
+		mcp.WithString("task_id",
+			mcp.Required(),
+			mcp.Description("Task ID"),
+		),
+		mcp.WithString("item_id",
+			mcp.Required(),
+			mcp.Description("Checklist item ID"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskID, err := request.RequireString("task_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		itemID, err := request.RequireString("item_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		task, err := s.taskRepo.RemoveChecklistItem(ctx, taskID, itemID)
+		if err != nil {
+			return s.toolError("Failed to remove checklist item", err), nil
+		}
+
+		taskJson, err := json.Marshal(task)
+		if err != nil {
+			return s.toolError("Failed to marshal task", err), nil
+		}
+		return mcp.NewToolResultText(string(taskJson)), nil
+	})
+}
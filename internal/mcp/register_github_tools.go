@@ -0,0 +1,127 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// registerGitHubTools registers the GitHub issue sync tools with the MCP server.
+// The tools are only available when a GitHub token is configured.
+func (s *MCPGoServer) registerGitHubTools() {
+	if s.githubSync == nil {
+		return
+	}
+
+	s.registerSyncPlanToGitHubTool()
+	s.registerImportGitHubIssuesTool()
+}
+
+// githubRepoArgument returns the repository named in a tool call, falling back to the configured default
+func (s *MCPGoServer) githubRepoArgument(request mcp.CallToolRequest) (string, error) {
+	repo := request.GetString("repo", s.githubRepo)
+	if repo == "" {
+		return "", fmt.Errorf("repo is required because no default GitHub repository is configured")
+	}
+	return repo, nil
+}
+
+func (s *MCPGoServer) registerSyncPlanToGitHubTool() {
+	tool := mcp.NewTool("sync_plan_to_github",
+		updateTool,
+		externalTool,
+		mcp.WithDescription(
+			"Sync the tasks of a plan with GitHub issues. Open tasks without an issue are created as issues, "+
+				"task titles are pushed to their issues, issues closed or reopened on GitHub update their task's status, "+
+				"and tasks completed, cancelled or reopened here close or reopen their issue. "+
+				"The issue link is stored in the task metadata under github.repo, github.issue and github.url.",
+		),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID whose tasks to sync"),
+		),
+		mcp.WithString("repo",
+			mcp.Description("Repository in owner/name form (optional, defaults to the configured GITHUB_REPO)"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		repo, err := s.githubRepoArgument(request)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		report, err := s.githubSync.SyncPlan(ctx, planID, repo)
+		if err != nil {
+			return s.toolError("Failed to sync plan with GitHub", err), nil
+		}
+
+		reportJson, err := json.Marshal(report)
+		if err != nil {
+			return s.toolError("Failed to marshal sync report", err), nil
+		}
+		return mcp.NewToolResultText(string(reportJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerImportGitHubIssuesTool() {
+	tool := mcp.NewTool("import_github_issues_as_tasks",
+		createTool,
+		externalTool,
+		mcp.WithDescription(
+			"Create tasks in a plan from the issues of a GitHub repository, oldest first, and link them for later syncs. "+
+				"Closed issues become completed or cancelled tasks, labels such as 'priority: high' set the priority, "+
+				"and issues already linked to a task of the plan are skipped.",
+		),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID to add the tasks to"),
+		),
+		mcp.WithString("repo",
+			mcp.Description("Repository in owner/name form (optional, defaults to the configured GITHUB_REPO)"),
+		),
+		mcp.WithString("state",
+			mcp.Description("Which issues to import (optional, defaults to 'open')"),
+			mcp.Enum("open", "closed", "all"),
+		),
+		mcp.WithArray("labels",
+			mcp.Description("Only import issues carrying all of these labels (optional)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		repo, err := s.githubRepoArgument(request)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		state := request.GetString("state", "open")
+		if state != "open" && state != "closed" && state != "all" {
+			return s.validationError("Invalid state: %s", state), nil
+		}
+
+		report, err := s.githubSync.ImportIssues(ctx, planID, repo, state, request.GetStringSlice("labels", nil))
+		if err != nil {
+			return s.toolError("Failed to import GitHub issues", err), nil
+		}
+
+		reportJson, err := json.Marshal(report)
+		if err != nil {
+			return s.toolError("Failed to marshal import report", err), nil
+		}
+		return mcp.NewToolResultText(string(reportJson)), nil
+	})
+}
@@ -0,0 +1,138 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// defaultHistoryLimit is the number of history entries returned when no limit is given
+const defaultHistoryLimit = 50
+
+// registerHistoryTools registers the audit history tools with the MCP server.
+// The tools are only available when an audit log is configured.
+func (s *MCPGoServer) registerHistoryTools() {
+	if s.auditLog == nil {
+		return
+	}
+
+	s.registerGetHistoryTool("get_plan_history", models.EntityTypePlan)
+	s.registerGetHistoryTool("get_task_history", models.EntityTypeTask)
+	s.registerUndoLastChangeTool()
+}
+
+func (s *MCPGoServer) registerGetHistoryTool(name string, entityType models.EntityType) {
+	tool := mcp.NewTool(name,
+		readOnlyTool,
+		mcp.WithDescription(
+			fmt.Sprintf(
+				"Get the change history of a %s, newest first. Each entry records the action, the actor, "+
+					"the timestamp and the changed fields with their before and after values",
+				entityType,
+			),
+		),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description(fmt.Sprintf("%s ID", entityTypeTitle(entityType))),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of entries to return (optional, defaults to %d)", defaultHistoryLimit)),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		limit := int64(request.GetInt("limit", defaultHistoryLimit))
+
+		failure := s.localizer.Sprintf("Failed to get %s history", s.localizer.Translate(string(entityType)))
+		if err := s.checkEntityScope(ctx, entityType, id); err != nil {
+			return s.toolError(failure, err), nil
+		}
+
+		entries, err := s.auditLog.History(ctx, entityType, id, limit)
+		if err != nil {
+			return s.toolError(failure, err), nil
+		}
+
+		entriesJson, err := json.Marshal(entries)
+		if err != nil {
+			return s.toolError("Failed to marshal history", err), nil
+		}
+		return mcp.NewToolResultText(string(entriesJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerUndoLastChangeTool() {
+	tool := mcp.NewTool("undo_last_change",
+		changeTool,
+		mcp.WithDescription(
+			"Revert the most recent change of a plan or task to its previous snapshot. "+
+				"Undoing a create deletes the entity and undoing a delete restores it. "+
+				"Fails with a conflict if the entity changed since the last recorded change",
+		),
+		mcp.WithString("entity_type",
+			mcp.Required(),
+			mcp.Description("Type of the entity to revert"),
+			mcp.Enum(string(models.EntityTypePlan), string(models.EntityTypeTask)),
+		),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Plan or task ID"),
+		),
+		mcp.WithString("entry_id",
+			mcp.Description("ID of the history entry expected to be the last change (optional, guards against races)"),
+		),
+		mcp.WithBoolean("force",
+			mcp.Description("Undo even if the entity changed since the last recorded change (optional, defaults to false)"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		entityType, err := request.RequireString("entity_type")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		result, err := s.undo.UndoLastChange(
+			ctx,
+			models.EntityType(entityType),
+			id,
+			request.GetString("entry_id", ""),
+			request.GetBool("force", false),
+		)
+		if err != nil {
+			return s.toolError("Failed to undo last change", err), nil
+		}
+
+		resultJson, err := json.Marshal(result)
+		if err != nil {
+			return s.toolError("Failed to marshal undo result", err), nil
+		}
+		return mcp.NewToolResultText(string(resultJson)), nil
+	})
+}
+
+// entityTypeTitle returns the capitalized name of an entity type for tool descriptions
+func entityTypeTitle(entityType models.EntityType) string {
+	switch entityType {
+	case models.EntityTypePlan:
+		return "Plan"
+	case models.EntityTypeTask:
+		return "Task"
+	default:
+		return string(entityType)
+	}
+}
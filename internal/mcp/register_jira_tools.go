@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/integrations/jira"
+)
+
+// registerJiraTools registers the Jira import and status push tools with the MCP server.
+// The tools are only available when a Jira site is configured.
+func (s *MCPGoServer) registerJiraTools() {
+	if s.jiraConnector == nil {
+		return
+	}
+
+	s.registerImportJiraIssuesTool()
+	s.registerPushJiraStatusTool()
+}
+
+func (s *MCPGoServer) registerImportJiraIssuesTool() {
+	tool := mcp.NewTool("import_jira_issues",
+		createTool,
+		externalTool,
+		mcp.WithDescription(
+			"Create tasks in a plan from the Jira issues matching a JQL query and link them for later status pushes. "+
+				"Titles, descriptions, statuses and priorities are mapped using the configured field mapping, "+
+				"and issues already linked to a task of the plan are skipped. "+
+				"The issue link is stored in the task metadata under jira.key, jira.url and jira.status.",
+		),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID to add the tasks to"),
+		),
+		mcp.WithString("jql",
+			mcp.Required(),
+			mcp.Description("JQL query selecting the issues to import, e.g. 'project = WEB AND sprint in openSprints()'"),
+		),
+		mcp.WithNumber("max_results",
+			mcp.Description(fmt.Sprintf("Maximum number of issues to import (optional, defaults to %d)", jira.DefaultMaxResults)),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		jql, err := request.RequireString("jql")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		maxResults := request.GetInt("max_results", jira.DefaultMaxResults)
+		if maxResults <= 0 {
+			return s.validationError("max_results must be positive"), nil
+		}
+
+		report, err := s.jiraConnector.ImportIssues(ctx, planID, jql, maxResults)
+		if err != nil {
+			return s.toolError("Failed to import Jira issues", err), nil
+		}
+
+		reportJson, err := json.Marshal(report)
+		if err != nil {
+			return s.toolError("Failed to marshal import report", err), nil
+		}
+		return mcp.NewToolResultText(string(reportJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerPushJiraStatusTool() {
+	tool := mcp.NewTool("push_jira_status",
+		updateTool,
+		externalTool,
+		mcp.WithDescription(
+			"Push the status of a plan's tasks to their linked Jira issues. Each issue whose status does not map "+
+				"to its task's status is moved through the workflow transition leading to the status configured for "+
+				"the task status. Issues without a matching transition are reported as errors.",
+		),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID whose task statuses to push"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		report, err := s.jiraConnector.PushStatuses(ctx, planID)
+		if err != nil {
+			return s.toolError("Failed to push statuses to Jira", err), nil
+		}
+
+		reportJson, err := json.Marshal(report)
+		if err != nil {
+			return s.toolError("Failed to marshal push report", err), nil
+		}
+		return mcp.NewToolResultText(string(reportJson)), nil
+	})
+}
@@ -0,0 +1,111 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// registerLeaseTools registers the work-queue lease tools with the MCP server
+func (s *MCPGoServer) registerLeaseTools() {
+	s.registerClaimTaskTool()
+	s.registerRenewLeaseTool()
+}
+
+func (s *MCPGoServer) registerClaimTaskTool() {
+	tool := mcp.NewTool("claim_task",
+		changeTool,
+		mcp.WithDescription(
+			"Claim a task for a worker, marking it in progress with a lease that expires unless renewed. "+
+				"Expired leases automatically return the task to pending.",
+		),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Task ID"),
+		),
+		mcp.WithString("worker_id",
+			mcp.Required(),
+			mcp.Description("Identifier of the agent or worker claiming the task"),
+		),
+		mcp.WithNumber("ttl_seconds",
+			mcp.Description(
+				fmt.Sprintf("Lease duration in seconds (optional, defaults to %d)", int(storage.DefaultLeaseTTL.Seconds())),
+			),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		workerID, err := request.RequireString("worker_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		ttl := time.Duration(request.GetFloat("ttl_seconds", storage.DefaultLeaseTTL.Seconds()) * float64(time.Second))
+
+		task, err := s.taskRepo.ClaimTask(ctx, id, workerID, ttl)
+		if err != nil {
+			return s.toolError("Failed to claim task", err), nil
+		}
+
+		taskJson, err := json.Marshal(task)
+		if err != nil {
+			return s.toolError("Failed to marshal task", err), nil
+		}
+		return mcp.NewToolResultText(string(taskJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerRenewLeaseTool() {
+	tool := mcp.NewTool("renew_lease",
+		changeTool,
+		mcp.WithDescription("Extend the lease a worker holds on a claimed task"),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Task ID"),
+		),
+		mcp.WithString("worker_id",
+			mcp.Required(),
+			mcp.Description("Identifier of the agent or worker holding the lease"),
+		),
+		mcp.WithNumber("ttl_seconds",
+			mcp.Description(
+				fmt.Sprintf("New lease duration in seconds (optional, defaults to %d)", int(storage.DefaultLeaseTTL.Seconds())),
+			),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		workerID, err := request.RequireString("worker_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		ttl := time.Duration(request.GetFloat("ttl_seconds", storage.DefaultLeaseTTL.Seconds()) * float64(time.Second))
+
+		task, err := s.taskRepo.RenewLease(ctx, id, workerID, ttl)
+		if err != nil {
+			return s.toolError("Failed to renew lease", err), nil
+		}
+
+		taskJson, err := json.Marshal(task)
+		if err != nil {
+			return s.toolError("Failed to marshal task", err), nil
+		}
+		return mcp.NewToolResultText(string(taskJson)), nil
+	})
+}
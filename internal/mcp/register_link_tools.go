@@ -0,0 +1,416 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// registerLinkTools registers the plan and task link tools with the MCP server
+func (s *MCPGoServer) registerLinkTools() {
+	s.registerAddPlanLinkTool()
+	s.registerListPlanLinksTool()
+	s.registerRemovePlanLinkTool()
+	s.registerAddTaskLinkTool()
+	s.registerListTaskLinksTool()
+	s.registerRemoveTaskLinkTool()
+}
+
+func (s *MCPGoServer) registerAddPlanLinkTool() {
+	tool := mcp.NewTool("add_plan_link",
+		createTool,
+		mcp.WithDescription(
+			"Link a plan to a pull request, issue, commit, document or other URL, or to a task. "+
+				"Links to tasks use the types relates_to and duplicates with the task ID as target",
+		),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		linkTypeOption(),
+		linkTargetOption(),
+		linkTitleOption(),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		ctx, unlock, err := s.planRepo.LockPlan(ctx, planID)
+		if err != nil {
+			return s.toolError("Failed to get plan", err), nil
+		}
+		defer unlock()
+
+		plan, err := s.planRepo.Get(ctx, planID)
+		if err != nil {
+			return s.toolError("Failed to get plan", err), nil
+		}
+
+		link, err := s.parseLink(ctx, request, "")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+		plan.Links, err = addLink(plan.Links, link, models.EntityPlan, planID)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		err = s.planRepo.Update(ctx, plan)
+		if err != nil {
+			return s.toolError("Failed to update plan", err), nil
+		}
+
+		planJson, err := json.Marshal(plan)
+		if err != nil {
+			return s.toolError("Failed to marshal plan", err), nil
+		}
+		return mcp.NewToolResultText(string(planJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerListPlanLinksTool() {
+	tool := mcp.NewTool("list_plan_links",
+		readOnlyTool,
+		mcp.WithDescription("List the links of a plan, optionally only those of one type"),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		linkTypeFilterOption(),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		plan, err := s.planRepo.Get(ctx, planID)
+		if err != nil {
+			return s.toolError("Failed to get plan", err), nil
+		}
+
+		return s.marshalLinks(plan.Links, models.LinkType(request.GetString("type", "")))
+	})
+}
+
+func (s *MCPGoServer) registerRemovePlanLinkTool() {
+	tool := mcp.NewTool("remove_plan_link",
+		deleteTool,
+		mcp.WithDescription("Remove a link from a plan. The linked task or resource is kept"),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		linkTypeOption(),
+		linkTargetOption(),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		linkType, target, err := requireLinkKey(request)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		ctx, unlock, err := s.planRepo.LockPlan(ctx, planID)
+		if err != nil {
+			return s.toolError("Failed to get plan", err), nil
+		}
+		defer unlock()
+
+		plan, err := s.planRepo.Get(ctx, planID)
+		if err != nil {
+			return s.toolError("Failed to get plan", err), nil
+		}
+
+		plan.Links, err = removeLink(plan.Links, linkType, target)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		err = s.planRepo.Update(ctx, plan)
+		if err != nil {
+			return s.toolError("Failed to update plan", err), nil
+		}
+
+		planJson, err := json.Marshal(plan)
+		if err != nil {
+			return s.toolError("Failed to marshal plan", err), nil
+		}
+		return mcp.NewToolResultText(string(planJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerAddTaskLinkTool() {
+	tool := mcp.NewTool("add_task_link",
+		createTool,
+		mcp.WithDescription(
+			"Link a task to a pull request, issue, commit, document or other URL, or to another task. "+
+				"Links to tasks use the types relates_to and duplicates with the task ID as target",
+		),
+		mcp.WithString("task_id",
+			mcp.Required(),
+			mcp.Description("Task ID"),
+		),
+		linkTypeOption(),
+		linkTargetOption(),
+		linkTitleOption(),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskID, err := request.RequireString("task_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		ctx, task, unlock, err := s.lockTask(ctx, taskID)
+		if err != nil {
+			return s.toolError("Failed to get task", err), nil
+		}
+		defer unlock()
+
+		link, err := s.parseLink(ctx, request, taskID)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+		task.Links, err = addLink(task.Links, link, models.EntityTask, taskID)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		err = s.taskRepo.Update(ctx, task)
+		if err != nil {
+			return s.toolError("Failed to update task", err), nil
+		}
+
+		taskJson, err := json.Marshal(task)
+		if err != nil {
+			return s.toolError("Failed to marshal task", err), nil
+		}
+		return mcp.NewToolResultText(string(taskJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerListTaskLinksTool() {
+	tool := mcp.NewTool("list_task_links",
+		readOnlyTool,
+		mcp.WithDescription("List the links of a task, optionally only those of one type"),
+		mcp.WithString("task_id",
+			mcp.Required(),
+			mcp.Description("Task ID"),
+		),
+		linkTypeFilterOption(),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskID, err := request.RequireString("task_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		task, err := s.taskRepo.Get(ctx, taskID)
+		if err != nil {
+			return s.toolError("Failed to get task", err), nil
+		}
+
+		return s.marshalLinks(task.Links, models.LinkType(request.GetString("type", "")))
+	})
+}
+
+func (s *MCPGoServer) registerRemoveTaskLinkTool() {
+	tool := mcp.NewTool("remove_task_link",
+		deleteTool,
+		mcp.WithDescription("Remove a link from a task. The linked task or resource is kept"),
+		mcp.WithString("task_id",
+			mcp.Required(),
+			mcp.Description("Task ID"),
+		),
+		linkTypeOption(),
+		linkTargetOption(),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskID, err := request.RequireString("task_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		linkType, target, err := requireLinkKey(request)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		ctx, task, unlock, err := s.lockTask(ctx, taskID)
+		if err != nil {
+			return s.toolError("Failed to get task", err), nil
+		}
+		defer unlock()
+
+		task.Links, err = removeLink(task.Links, linkType, target)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		err = s.taskRepo.Update(ctx, task)
+		if err != nil {
+			return s.toolError("Failed to update task", err), nil
+		}
+
+		taskJson, err := json.Marshal(task)
+		if err != nil {
+			return s.toolError("Failed to marshal task", err), nil
+		}
+		return mcp.NewToolResultText(string(taskJson)), nil
+	})
+}
+
+// lockTask takes the lock of the plan of a task and reads the task under it, so a change made to the returned
+// task does not overwrite concurrent changes of it. The returned function releases the lock.
+func (s *MCPGoServer) lockTask(ctx context.Context, taskID string) (context.Context, *models.Task, func(), error) {
+	task, err := s.taskRepo.Get(ctx, taskID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	ctx, unlock, err := s.planRepo.LockPlan(ctx, task.PlanID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	planID := task.PlanID
+	task, err = s.taskRepo.Get(ctx, taskID)
+	if err == nil && task.PlanID != planID {
+		err = models.NewConflictError(models.EntityTask, taskID, "task was moved to another plan, try again")
+	}
+	if err != nil {
+		unlock()
+		return nil, nil, nil, err
+	}
+	return ctx, task, unlock, nil
+}
+
+// linkTypeValues returns the link types as enum values
+func linkTypeValues() []string {
+	values := make([]string, len(models.LinkTypes))
+	for i, linkType := range models.LinkTypes {
+		values[i] = string(linkType)
+	}
+	return values
+}
+
+// linkTypeOption adds the required link type parameter
+func linkTypeOption() mcp.ToolOption {
+	return mcp.WithString("type",
+		mcp.Required(),
+		mcp.Description("Type of the link: pr, issue, commit, doc or url for a URL, relates_to or duplicates for a task"),
+		mcp.Enum(linkTypeValues()...),
+	)
+}
+
+// linkTypeFilterOption adds the optional link type filter of the list tools
+func linkTypeFilterOption() mcp.ToolOption {
+	return mcp.WithString("type",
+		mcp.Description("Only list links of this type (optional)"),
+		mcp.Enum(linkTypeValues()...),
+	)
+}
+
+// linkTargetOption adds the required link target parameter
+func linkTargetOption() mcp.ToolOption {
+	return mcp.WithString("target",
+		mcp.Required(),
+		mcp.Description("Target of the link: an http or https URL, or the ID of a task for relates_to and duplicates"),
+	)
+}
+
+// linkTitleOption adds the optional link title parameter
+func linkTitleOption() mcp.ToolOption {
+	return mcp.WithString("title",
+		mcp.Description("Label of the link, such as the title of the pull request (optional)"),
+	)
+}
+
+// requireLinkKey reads the type and target arguments that identify a link
+func requireLinkKey(request mcp.CallToolRequest) (models.LinkType, string, error) {
+	linkType, err := request.RequireString("type")
+	if err != nil {
+		return "", "", err
+	}
+	target, err := request.RequireString("target")
+	if err != nil {
+		return "", "", err
+	}
+	return models.LinkType(linkType), target, nil
+}
+
+// parseLink reads and validates a new link. Links to tasks must point at an existing task other than the
+// task the link is added to.
+func (s *MCPGoServer) parseLink(ctx context.Context, request mcp.CallToolRequest, taskID string) (*models.Link, error) {
+	linkType, target, err := requireLinkKey(request)
+	if err != nil {
+		return nil, err
+	}
+
+	link := models.NewLink(linkType, target, request.GetString("title", ""))
+	if err := link.Validate(); err != nil {
+		return nil, err
+	}
+
+	if link.IsTaskLink() {
+		if link.Target == taskID {
+			return nil, models.NewValidationError(models.EntityTask, taskID, "a task cannot link to itself")
+		}
+		if _, err := s.taskRepo.Get(ctx, link.Target); err != nil {
+			return nil, fmt.Errorf("failed to get task %s: %w", link.Target, err)
+		}
+	}
+	return link, nil
+}
+
+// addLink appends a link to the links of a plan or task, which must not have a link of the same type to the
+// same target yet
+func addLink(links []*models.Link, link *models.Link, entity, id string) ([]*models.Link, error) {
+	if models.LinkIndex(links, link.Type, link.Target) >= 0 {
+		return nil, models.NewValidationError(entity, id, "%s link to %s already exists", link.Type, link.Target)
+	}
+	if len(links) >= models.MaxLinks {
+		return nil, models.NewValidationError(entity, id, "cannot have more than %d links", models.MaxLinks)
+	}
+	return append(links, link), nil
+}
+
+// removeLink removes the link with the given type and target from the links of a plan or task
+func removeLink(links []*models.Link, linkType models.LinkType, target string) ([]*models.Link, error) {
+	index := models.LinkIndex(links, linkType, target)
+	if index < 0 {
+		return nil, models.NewNotFoundError(models.EntityLink, fmt.Sprintf("%s:%s", linkType, target))
+	}
+	return slices.Delete(links, index, index+1), nil
+}
+
+// marshalLinks returns links as a JSON tool result, keeping only those of the given type when it is set
+func (s *MCPGoServer) marshalLinks(links []*models.Link, linkType models.LinkType) (*mcp.CallToolResult, error) {
+	filtered := make([]*models.Link, 0, len(links))
+	for _, link := range links {
+		if linkType == "" || link.Type == linkType {
+			filtered = append(filtered, link)
+		}
+	}
+
+	linksJson, err := json.Marshal(filtered)
+	if err != nil {
+		return s.toolError("Failed to marshal links", err), nil
+	}
+	return mcp.NewToolResultText(string(linksJson)), nil
+}
@@ -0,0 +1,121 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+func TestTaskLinks(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	plan, err := s.planRepo.Create(ctx, "app-1", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	task, err := s.taskRepo.Create(ctx, plan.ID, "Task", "", models.TaskPriorityMedium)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	other, err := s.taskRepo.Create(ctx, plan.ID, "Other task", "", models.TaskPriorityMedium)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	for _, arguments := range []map[string]any{
+		{"task_id": task.ID, "type": "pr", "target": "https://github.com/org/repo/pull/42", "title": "Fix login"},
+		{"task_id": task.ID, "type": "relates_to", "target": other.ID},
+	} {
+		if result := callTool(t, s, "add_task_link", arguments); result.IsError {
+			t.Fatalf("add_task_link(%v) failed: %s", arguments, toolResultText(result))
+		}
+	}
+
+	for name, arguments := range map[string]map[string]any{
+		"duplicate":    {"task_id": task.ID, "type": "relates_to", "target": other.ID},
+		"self":         {"task_id": task.ID, "type": "relates_to", "target": task.ID},
+		"missing task": {"task_id": task.ID, "type": "duplicates", "target": "missing"},
+		"not a URL":    {"task_id": task.ID, "type": "doc", "target": "design.md"},
+	} {
+		if result := callTool(t, s, "add_task_link", arguments); !result.IsError {
+			t.Errorf("add_task_link should reject a %s link", name)
+		}
+	}
+
+	result := callTool(t, s, "list_task_links", map[string]any{"task_id": task.ID, "type": "pr"})
+	var links []*models.Link
+	if err := json.Unmarshal([]byte(toolResultText(result)), &links); err != nil {
+		t.Fatalf("failed to decode links: %v", err)
+	}
+	if len(links) != 1 || links[0].Title != "Fix login" {
+		t.Errorf("links = %+v, want the pull request", links)
+	}
+
+	result = callTool(t, s, "remove_task_link", map[string]any{"task_id": task.ID, "type": "relates_to", "target": other.ID})
+	if result.IsError {
+		t.Fatalf("remove_task_link failed: %s", toolResultText(result))
+	}
+	result = callTool(t, s, "remove_task_link", map[string]any{"task_id": task.ID, "type": "relates_to", "target": other.ID})
+	if !result.IsError || !strings.Contains(toolResultText(result), "not found") {
+		t.Errorf("removing a missing link should fail, got %s", toolResultText(result))
+	}
+
+	stored, err := s.taskRepo.Get(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if len(stored.Links) != 1 || stored.Links[0].Type != models.LinkTypePR {
+		t.Errorf("stored links = %+v, want only the pull request", stored.Links)
+	}
+}
+
+func TestConcurrentLinks(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	plan, err := s.planRepo.Create(ctx, "app-1", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	task, err := s.taskRepo.Create(ctx, plan.ID, "Task", "", models.TaskPriorityMedium)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	// Links added at the same time are all kept
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			target := fmt.Sprintf("https://github.com/org/repo/pull/%d", i)
+			for tool, arguments := range map[string]map[string]any{
+				"add_plan_link": {"plan_id": plan.ID, "type": "pr", "target": target},
+				"add_task_link": {"task_id": task.ID, "type": "pr", "target": target},
+			} {
+				if result := callTool(t, s, tool, arguments); result.IsError {
+					t.Errorf("%s failed: %s", tool, toolResultText(result))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	storedPlan, err := s.planRepo.Get(ctx, plan.ID)
+	if err != nil {
+		t.Fatalf("failed to get plan: %v", err)
+	}
+	storedTask, err := s.taskRepo.Get(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if len(storedPlan.Links) != 10 || len(storedTask.Links) != 10 {
+		t.Errorf("stored %d plan links and %d task links, want 10 each", len(storedPlan.Links), len(storedTask.Links))
+	}
+}
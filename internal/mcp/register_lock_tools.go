@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// registerLockTools registers the tools that lock and unlock plans
+func (s *MCPGoServer) registerLockTools() {
+	s.registerLockPlanTool()
+	s.registerUnlockPlanTool()
+}
+
+func (s *MCPGoServer) registerLockPlanTool() {
+	tool := mcp.NewTool("lock_plan",
+		updateTool,
+		mcp.WithDescription(
+			"Lock a plan while it is reviewed or executed: until it is unlocked, only admins can change the plan "+
+				"or its tasks, and other changes are rejected with a conflict error",
+		),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		mcp.WithString("reason",
+			mcp.Description("Why the plan is locked, shown to agents whose changes are rejected"),
+		),
+		mcp.WithString("locked_by",
+			mcp.Description("Who locks the plan, such as a reviewer; defaults to the MCP session"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		lock := &models.PlanLock{
+			LockedBy: request.GetString("locked_by", storage.ActorFromContext(ctx)),
+			Reason:   request.GetString("reason", ""),
+			LockedAt: time.Now(),
+		}
+		plan, err := s.planRepo.SetLock(ctx, id, lock)
+		if err != nil {
+			return s.toolError("Failed to lock plan", err), nil
+		}
+
+		planJson, err := json.Marshal(plan)
+		if err != nil {
+			return s.toolError("Failed to marshal plan", err), nil
+		}
+		return mcp.NewToolResultText(string(planJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerUnlockPlanTool() {
+	tool := mcp.NewTool("unlock_plan",
+		updateTool,
+		mcp.WithDescription("Unlock a locked plan, so the plan and its tasks can be changed again"),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		// Only admins may unlock a locked plan; without roles, everyone may call the admin tools
+		if s.roleFromContext(ctx) == "" {
+			ctx = storage.WithPlanLockOverride(ctx)
+		}
+
+		plan, err := s.planRepo.SetLock(ctx, id, nil)
+		if err != nil {
+			return s.toolError("Failed to unlock plan", err), nil
+		}
+
+		planJson, err := json.Marshal(plan)
+		if err != nil {
+			return s.toolError("Failed to marshal plan", err), nil
+		}
+		return mcp.NewToolResultText(string(planJson)), nil
+	})
+}
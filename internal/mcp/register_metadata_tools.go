@@ -0,0 +1,273 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// registerMetadataTools registers the plan and task metadata tools with the MCP server
+func (s *MCPGoServer) registerMetadataTools() {
+	s.registerSetPlanMetadataTool()
+	s.registerGetPlanMetadataTool()
+	s.registerDeletePlanMetadataTool()
+	s.registerSetTaskMetadataTool()
+	s.registerGetTaskMetadataTool()
+	s.registerDeleteTaskMetadataTool()
+}
+
+func (s *MCPGoServer) registerSetPlanMetadataTool() {
+	tool := mcp.NewTool("set_plan_metadata",
+		updateTool,
+		mcp.WithDescription(
+			"Set custom key/value metadata on a plan (e.g. repo URL, PR number, ticket ID). "+
+				"Existing values for the same keys are overwritten",
+		),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		metadataOption(),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		metadata, err := parseMetadataArgument(request)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		plan, err := s.planRepo.SetMetadata(ctx, id, metadata)
+		if err != nil {
+			return s.toolError("Failed to set plan metadata", err), nil
+		}
+
+		planJson, err := json.Marshal(plan)
+		if err != nil {
+			return s.toolError("Failed to marshal plan", err), nil
+		}
+		return mcp.NewToolResultText(string(planJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerGetPlanMetadataTool() {
+	tool := mcp.NewTool("get_plan_metadata",
+		readOnlyTool,
+		mcp.WithDescription("Get the custom key/value metadata of a plan"),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		plan, err := s.planRepo.Get(ctx, id)
+		if err != nil {
+			return s.toolError("Failed to get plan", err), nil
+		}
+
+		return s.marshalMetadata(plan.Metadata)
+	})
+}
+
+func (s *MCPGoServer) registerDeletePlanMetadataTool() {
+	tool := mcp.NewTool("delete_plan_metadata",
+		deleteTool,
+		mcp.WithDescription("Delete custom metadata keys from a plan"),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		metadataKeysOption(),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		keys, err := request.RequireStringSlice("keys")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		plan, err := s.planRepo.DeleteMetadata(ctx, id, keys)
+		if err != nil {
+			return s.toolError("Failed to delete plan metadata", err), nil
+		}
+
+		planJson, err := json.Marshal(plan)
+		if err != nil {
+			return s.toolError("Failed to marshal plan", err), nil
+		}
+		return mcp.NewToolResultText(string(planJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerSetTaskMetadataTool() {
+	tool := mcp.NewTool("set_task_metadata",
+		updateTool,
+		mcp.WithDescription(
+			"Set custom key/value metadata on a task (e.g. repo URL, PR number, ticket ID). "+
+				"Existing values for the same keys are overwritten",
+		),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Task ID"),
+		),
+		metadataOption(),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		metadata, err := parseMetadataArgument(request)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		task, err := s.taskRepo.SetMetadata(ctx, id, metadata)
+		if err != nil {
+			return s.toolError("Failed to set task metadata", err), nil
+		}
+
+		taskJson, err := json.Marshal(task)
+		if err != nil {
+			return s.toolError("Failed to marshal task", err), nil
+		}
+		return mcp.NewToolResultText(string(taskJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerGetTaskMetadataTool() {
+	tool := mcp.NewTool("get_task_metadata",
+		readOnlyTool,
+		mcp.WithDescription("Get the custom key/value metadata of a task"),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Task ID"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		task, err := s.taskRepo.Get(ctx, id)
+		if err != nil {
+			return s.toolError("Failed to get task", err), nil
+		}
+
+		return s.marshalMetadata(task.Metadata)
+	})
+}
+
+func (s *MCPGoServer) registerDeleteTaskMetadataTool() {
+	tool := mcp.NewTool("delete_task_metadata",
+		deleteTool,
+		mcp.WithDescription("Delete custom metadata keys from a task"),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Task ID"),
+		),
+		metadataKeysOption(),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		keys, err := request.RequireStringSlice("keys")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		task, err := s.taskRepo.DeleteMetadata(ctx, id, keys)
+		if err != nil {
+			return s.toolError("Failed to delete task metadata", err), nil
+		}
+
+		taskJson, err := json.Marshal(task)
+		if err != nil {
+			return s.toolError("Failed to marshal task", err), nil
+		}
+		return mcp.NewToolResultText(string(taskJson)), nil
+	})
+}
+
+// metadataValueSchema describes the values of metadata arguments. Numbers and booleans are stored in their
+// string form.
+var metadataValueSchema = map[string]any{"type": []string{"string", "number", "boolean"}}
+
+// metadataOption adds the required metadata object parameter to the set tools
+func metadataOption() mcp.ToolOption {
+	return mcp.WithObject("metadata",
+		mcp.Required(),
+		mcp.Description("Metadata entries to set, as an object mapping keys to string values"),
+		mcp.AdditionalProperties(metadataValueSchema),
+	)
+}
+
+// metadataKeysOption adds the required keys parameter to the delete tools
+func metadataKeysOption() mcp.ToolOption {
+	return mcp.WithArray("keys",
+		mcp.Required(),
+		mcp.Description("Metadata keys to delete"),
+		mcp.Items(map[string]any{"type": "string"}),
+	)
+}
+
+// parseMetadataArgument reads the metadata object argument.
+// Numbers and booleans are accepted and stored in their string form.
+func parseMetadataArgument(request mcp.CallToolRequest) (map[string]string, error) {
+	raw, ok := request.GetArguments()["metadata"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("metadata must be an object mapping keys to string values")
+	}
+
+	metadata := make(map[string]string, len(raw))
+	for key, value := range raw {
+		switch v := value.(type) {
+		case string:
+			metadata[key] = v
+		case float64, bool:
+			metadata[key] = fmt.Sprint(v)
+		default:
+			return nil, fmt.Errorf("metadata value for %q must be a string", key)
+		}
+	}
+
+	return metadata, nil
+}
+
+// marshalMetadata returns a metadata map as a JSON tool result
+func (s *MCPGoServer) marshalMetadata(metadata map[string]string) (*mcp.CallToolResult, error) {
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	metadataJson, err := json.Marshal(metadata)
+	if err != nil {
+		return s.toolError("Failed to marshal metadata", err), nil
+	}
+	return mcp.NewToolResultText(string(metadataJson)), nil
+}
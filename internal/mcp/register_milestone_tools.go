@@ -0,0 +1,306 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// registerMilestoneTools registers the plan schedule and milestone tools with the MCP server
+func (s *MCPGoServer) registerMilestoneTools() {
+	s.registerSetPlanDatesTool()
+	s.registerAddMilestoneTool()
+	s.registerUpdateMilestoneTool()
+	s.registerRemoveMilestoneTool()
+}
+
+func (s *MCPGoServer) registerSetPlanDatesTool() {
+	tool := mcp.NewTool("set_plan_dates",
+		updateTool,
+		mcp.WithDescription(
+			"Set when work on a plan starts and when it should be done. get_plan_progress flags the plan "+
+				"as at risk when its open tasks are unlikely to be completed by the target date",
+		),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		mcp.WithString("start_date",
+			mcp.Description("Start date as RFC3339 timestamp or YYYY-MM-DD, or 'none' to clear it (optional)"),
+		),
+		mcp.WithString("target_date",
+			mcp.Description("Target date as RFC3339 timestamp or YYYY-MM-DD, or 'none' to clear it (optional)"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		plan, err := s.planRepo.Get(ctx, id)
+		if err != nil {
+			return s.toolError("Failed to get plan", err), nil
+		}
+
+		if plan.StartDate, err = parsePlanDate(request.GetString("start_date", ""), plan.StartDate); err != nil {
+			return s.invalidArgument(err), nil
+		}
+		if plan.TargetDate, err = parsePlanDate(request.GetString("target_date", ""), plan.TargetDate); err != nil {
+			return s.invalidArgument(err), nil
+		}
+		if plan.StartDate != nil && plan.TargetDate != nil && plan.TargetDate.Before(*plan.StartDate) {
+			return s.validationError("target date cannot be before the start date"), nil
+		}
+
+		err = s.planRepo.Update(ctx, plan)
+		if err != nil {
+			return s.toolError("Failed to update plan", err), nil
+		}
+
+		planJson, err := json.Marshal(plan)
+		if err != nil {
+			return s.toolError("Failed to marshal plan", err), nil
+		}
+		return mcp.NewToolResultText(string(planJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerAddMilestoneTool() {
+	tool := mcp.NewTool("add_milestone",
+		createTool,
+		mcp.WithDescription(
+			"Add a milestone to a plan: a named date by which a set of the plan's tasks must be completed",
+		),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the milestone"),
+		),
+		mcp.WithString("date",
+			mcp.Required(),
+			mcp.Description("Date of the milestone as RFC3339 timestamp or YYYY-MM-DD"),
+		),
+		mcp.WithArray("task_ids",
+			mcp.Description("IDs of the tasks of the plan that make up the milestone (optional)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		name, err := request.RequireString("name")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return s.validationError("milestone name cannot be empty"), nil
+		}
+
+		dateStr, err := request.RequireString("date")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+		date, err := models.ParseDueDate(dateStr)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		plan, err := s.planRepo.Get(ctx, planID)
+		if err != nil {
+			return s.toolError("Failed to get plan", err), nil
+		}
+
+		taskIDs := request.GetStringSlice("task_ids", nil)
+		if err := s.checkPlanTasks(ctx, planID, taskIDs); err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		plan.Milestones = append(plan.Milestones, models.NewMilestone(uuid.New().String(), name, date, taskIDs))
+		plan.SortMilestones()
+
+		err = s.planRepo.Update(ctx, plan)
+		if err != nil {
+			return s.toolError("Failed to update plan", err), nil
+		}
+
+		planJson, err := json.Marshal(plan)
+		if err != nil {
+			return s.toolError("Failed to marshal plan", err), nil
+		}
+		return mcp.NewToolResultText(string(planJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerUpdateMilestoneTool() {
+	tool := mcp.NewTool("update_milestone",
+		updateTool,
+		mcp.WithDescription("Rename or reschedule a milestone of a plan, or change the tasks linked to it"),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		mcp.WithString("milestone_id",
+			mcp.Required(),
+			mcp.Description("Milestone ID"),
+		),
+		mcp.WithString("name",
+			mcp.Description("New name of the milestone (optional)"),
+		),
+		mcp.WithString("date",
+			mcp.Description("New date of the milestone as RFC3339 timestamp or YYYY-MM-DD (optional)"),
+		),
+		mcp.WithArray("task_ids",
+			mcp.Description("New list of IDs of the linked tasks; an empty list clears it (optional)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		milestoneID, err := request.RequireString("milestone_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		plan, err := s.planRepo.Get(ctx, planID)
+		if err != nil {
+			return s.toolError("Failed to get plan", err), nil
+		}
+
+		milestone := plan.Milestone(milestoneID)
+		if milestone == nil {
+			return s.invalidArgument(models.NewNotFoundError(models.EntityMilestone, milestoneID)), nil
+		}
+
+		if name := strings.TrimSpace(request.GetString("name", "")); name != "" {
+			milestone.Name = name
+		}
+
+		if dateStr := request.GetString("date", ""); dateStr != "" {
+			date, err := models.ParseDueDate(dateStr)
+			if err != nil {
+				return s.invalidArgument(err), nil
+			}
+			milestone.Date = date
+		}
+
+		if _, ok := request.GetArguments()["task_ids"]; ok {
+			taskIDs := request.GetStringSlice("task_ids", nil)
+			if err := s.checkPlanTasks(ctx, planID, taskIDs); err != nil {
+				return s.invalidArgument(err), nil
+			}
+			milestone.TaskIDs = taskIDs
+		}
+
+		plan.SortMilestones()
+		err = s.planRepo.Update(ctx, plan)
+		if err != nil {
+			return s.toolError("Failed to update plan", err), nil
+		}
+
+		planJson, err := json.Marshal(plan)
+		if err != nil {
+			return s.toolError("Failed to marshal plan", err), nil
+		}
+		return mcp.NewToolResultText(string(planJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerRemoveMilestoneTool() {
+	tool := mcp.NewTool("remove_milestone",
+		deleteTool,
+		mcp.WithDescription("Remove a milestone from a plan. The tasks linked to it are kept"),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		mcp.WithString("milestone_id",
+			mcp.Required(),
+			mcp.Description("Milestone ID"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		milestoneID, err := request.RequireString("milestone_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		plan, err := s.planRepo.Get(ctx, planID)
+		if err != nil {
+			return s.toolError("Failed to get plan", err), nil
+		}
+
+		if plan.Milestone(milestoneID) == nil {
+			return s.invalidArgument(models.NewNotFoundError(models.EntityMilestone, milestoneID)), nil
+		}
+		plan.Milestones = slices.DeleteFunc(plan.Milestones, func(m *models.Milestone) bool { return m.ID == milestoneID })
+
+		err = s.planRepo.Update(ctx, plan)
+		if err != nil {
+			return s.toolError("Failed to update plan", err), nil
+		}
+
+		planJson, err := json.Marshal(plan)
+		if err != nil {
+			return s.toolError("Failed to marshal plan", err), nil
+		}
+		return mcp.NewToolResultText(string(planJson)), nil
+	})
+}
+
+// parsePlanDate parses an optional date argument of a plan. An empty value keeps the current date and the
+// value 'none' clears it.
+func parsePlanDate(value string, current *time.Time) (*time.Time, error) {
+	switch value {
+	case "":
+		return current, nil
+	case "none":
+		return nil, nil
+	}
+	date, err := models.ParseDueDate(value)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+// checkPlanTasks verifies that the tasks linked to a milestone or activity entry exist and belong to its plan
+func (s *MCPGoServer) checkPlanTasks(ctx context.Context, planID string, taskIDs []string) error {
+	for _, taskID := range taskIDs {
+		task, err := s.taskRepo.Get(ctx, taskID)
+		if err != nil {
+			return fmt.Errorf("failed to get task %s: %w", taskID, err)
+		}
+		if task.PlanID != planID {
+			return fmt.Errorf("task %s does not belong to plan %s", taskID, planID)
+		}
+	}
+	return nil
+}
@@ -5,14 +5,27 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
 	"github.com/jbrinkman/valkey-ai-tasks/internal/utils/markdown"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
+// defaultNotesHistoryLimit is the number of notes revisions returned when no limit is given
+const defaultNotesHistoryLimit = 10
+
 // registerNotesTools registers all notes-related tools with the MCP server
 func (s *MCPGoServer) registerNotesTools() {
 	s.registerUpdatePlanNotesTool()
 	s.registerGetPlanNotesTool()
+	s.registerAppendPlanNotesTool()
+	s.registerGetPlanNotesHistoryTool()
+	s.registerRevertPlanNotesTool()
+
+	// Archived notes are only kept when notes are compacted
+	if s.compactor != nil {
+		s.registerGetArchivedNotesTool("get_archived_plan_notes", models.EntityTypePlan)
+		s.registerGetArchivedNotesTool("get_archived_task_notes", models.EntityTypeTask)
+	}
 	s.registerUpdateTaskNotesTool()
 	s.registerGetTaskNotesTool()
 }
@@ -20,6 +33,7 @@ func (s *MCPGoServer) registerNotesTools() {
 // registerUpdatePlanNotesTool registers a tool to update notes for a plan
 func (s *MCPGoServer) registerUpdatePlanNotesTool() {
 	tool := mcp.NewTool("update_plan_notes",
+		updateTool,
 		mcp.WithDescription("Update notes for a plan"),
 		mcp.WithString("id",
 			mcp.Required(),
@@ -31,21 +45,21 @@ func (s *MCPGoServer) registerUpdatePlanNotesTool() {
 		),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		id, err := request.RequireString("id")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
 		notes, err := request.RequireString("notes")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
 		// Validate and format the markdown content
 		err = markdown.Validate(notes)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Invalid notes format: %v", err)), nil
+			return s.errorResult("Invalid notes format", err, models.ErrorCodeValidation), nil
 		}
 
 		// Sanitize and format the notes
@@ -55,7 +69,7 @@ func (s *MCPGoServer) registerUpdatePlanNotesTool() {
 		// Update the notes
 		err = s.planRepo.UpdateNotes(ctx, id, notes)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to update plan notes: %v", err)), nil
+			return s.toolError("Failed to update plan notes", err), nil
 		}
 
 		return mcp.NewToolResultText(fmt.Sprintf("Successfully updated notes for plan %s", id)), nil
@@ -65,6 +79,7 @@ func (s *MCPGoServer) registerUpdatePlanNotesTool() {
 // registerGetPlanNotesTool registers a tool to get notes for a plan
 func (s *MCPGoServer) registerGetPlanNotesTool() {
 	tool := mcp.NewTool("get_plan_notes",
+		readOnlyTool,
 		mcp.WithDescription("Retrieve the notes for a specific plan"),
 		mcp.WithString("id",
 			mcp.Required(),
@@ -72,16 +87,152 @@ func (s *MCPGoServer) registerGetPlanNotesTool() {
 		),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		id, err := request.RequireString("id")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
 		// Get the notes
 		notes, err := s.planRepo.GetNotes(ctx, id)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get plan notes: %v", err)), nil
+			return s.toolError("Failed to get plan notes", err), nil
+		}
+
+		result := map[string]string{
+			"id":    id,
+			"notes": notes,
+		}
+
+		resultJson, err := json.Marshal(result)
+		if err != nil {
+			return s.toolError("Failed to marshal result", err), nil
+		}
+		return mcp.NewToolResultText(string(resultJson)), nil
+	})
+}
+
+// registerAppendPlanNotesTool registers a tool to add to the notes of a plan without replacing them
+func (s *MCPGoServer) registerAppendPlanNotesTool() {
+	tool := mcp.NewTool("append_plan_notes",
+		createTool,
+		mcp.WithDescription(
+			"Add Markdown to the end of the notes of a plan, keeping what other agents wrote. "+
+				"Prefer this over update_plan_notes when adding context",
+		),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		mcp.WithString("notes",
+			mcp.Required(),
+			mcp.Description("Markdown-formatted notes to append, separated from the existing notes by a blank line"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		notes, err := request.RequireString("notes")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		// Validate and format the markdown content
+		err = markdown.Validate(notes)
+		if err != nil {
+			return s.errorResult("Invalid notes format", err, models.ErrorCodeValidation), nil
+		}
+
+		// Sanitize and format the notes
+		notes = markdown.Sanitize(notes)
+		notes = markdown.Format(notes)
+
+		notes, err = s.planRepo.AppendNotes(ctx, id, notes)
+		if err != nil {
+			return s.toolError("Failed to append plan notes", err), nil
+		}
+
+		result := map[string]string{
+			"id":    id,
+			"notes": notes,
+		}
+
+		resultJson, err := json.Marshal(result)
+		if err != nil {
+			return s.toolError("Failed to marshal result", err), nil
+		}
+		return mcp.NewToolResultText(string(resultJson)), nil
+	})
+}
+
+// registerGetPlanNotesHistoryTool registers a tool to list the saved revisions of the notes of a plan
+func (s *MCPGoServer) registerGetPlanNotesHistoryTool() {
+	tool := mcp.NewTool("get_plan_notes_history",
+		readOnlyTool,
+		mcp.WithDescription("Get the saved revisions of the notes of a plan, newest first, with who saved them and when"),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of revisions to return (optional, defaults to %d)", defaultNotesHistoryLimit)),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		limit := int64(request.GetInt("limit", defaultNotesHistoryLimit))
+
+		revisions, err := s.planRepo.NotesHistory(ctx, id, limit)
+		if err != nil {
+			return s.toolError("Failed to get plan notes history", err), nil
+		}
+
+		revisionsJson, err := json.Marshal(revisions)
+		if err != nil {
+			return s.toolError("Failed to marshal revisions", err), nil
+		}
+		return mcp.NewToolResultText(string(revisionsJson)), nil
+	})
+}
+
+// registerRevertPlanNotesTool registers a tool to set the notes of a plan back to a saved revision
+func (s *MCPGoServer) registerRevertPlanNotesTool() {
+	tool := mcp.NewTool("revert_plan_notes",
+		updateTool,
+		mcp.WithDescription("Set the notes of a plan back to a revision listed by get_plan_notes_history"),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		mcp.WithString("revision_id",
+			mcp.Required(),
+			mcp.Description("ID of the revision to revert to"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		revisionID, err := request.RequireString("revision_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		notes, err := s.planRepo.RevertNotes(ctx, id, revisionID)
+		if err != nil {
+			return s.toolError("Failed to revert plan notes", err), nil
 		}
 
 		result := map[string]string{
@@ -91,7 +242,7 @@ func (s *MCPGoServer) registerGetPlanNotesTool() {
 
 		resultJson, err := json.Marshal(result)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+			return s.toolError("Failed to marshal result", err), nil
 		}
 		return mcp.NewToolResultText(string(resultJson)), nil
 	})
@@ -100,6 +251,7 @@ func (s *MCPGoServer) registerGetPlanNotesTool() {
 // registerUpdateTaskNotesTool registers a tool to update notes for a task
 func (s *MCPGoServer) registerUpdateTaskNotesTool() {
 	tool := mcp.NewTool("update_task_notes",
+		updateTool,
 		mcp.WithDescription("Update the notes for a specific task"),
 		mcp.WithString("id",
 			mcp.Required(),
@@ -111,21 +263,21 @@ func (s *MCPGoServer) registerUpdateTaskNotesTool() {
 		),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		id, err := request.RequireString("id")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
 		notes, err := request.RequireString("notes")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
 		// Validate and format the markdown content
 		err = markdown.Validate(notes)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Invalid notes format: %v", err)), nil
+			return s.errorResult("Invalid notes format", err, models.ErrorCodeValidation), nil
 		}
 
 		// Sanitize and format the notes
@@ -135,18 +287,18 @@ func (s *MCPGoServer) registerUpdateTaskNotesTool() {
 		// Update the notes
 		err = s.taskRepo.UpdateNotes(ctx, id, notes)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to update task notes: %v", err)), nil
+			return s.toolError("Failed to update task notes", err), nil
 		}
 
 		// Get the updated task
 		task, err := s.taskRepo.Get(ctx, id)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get updated task: %v", err)), nil
+			return s.toolError("Failed to get updated task", err), nil
 		}
 
 		taskJson, err := json.Marshal(task)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal task: %v", err)), nil
+			return s.toolError("Failed to marshal task", err), nil
 		}
 		return mcp.NewToolResultText(string(taskJson)), nil
 	})
@@ -155,6 +307,7 @@ func (s *MCPGoServer) registerUpdateTaskNotesTool() {
 // registerGetTaskNotesTool registers a tool to get notes for a task
 func (s *MCPGoServer) registerGetTaskNotesTool() {
 	tool := mcp.NewTool("get_task_notes",
+		readOnlyTool,
 		mcp.WithDescription("Retrieve the notes for a specific task"),
 		mcp.WithString("id",
 			mcp.Required(),
@@ -162,16 +315,16 @@ func (s *MCPGoServer) registerGetTaskNotesTool() {
 		),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		id, err := request.RequireString("id")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
 		// Get the notes
 		notes, err := s.taskRepo.GetNotes(ctx, id)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get task notes: %v", err)), nil
+			return s.toolError("Failed to get task notes", err), nil
 		}
 
 		result := map[string]string{
@@ -181,8 +334,48 @@ func (s *MCPGoServer) registerGetTaskNotesTool() {
 
 		resultJson, err := json.Marshal(result)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+			return s.toolError("Failed to marshal result", err), nil
 		}
 		return mcp.NewToolResultText(string(resultJson)), nil
 	})
 }
+
+func (s *MCPGoServer) registerGetArchivedNotesTool(name string, entityType models.EntityType) {
+	tool := mcp.NewTool(name,
+		readOnlyTool,
+		mcp.WithDescription(
+			fmt.Sprintf(
+				"Get the older notes of a %s that were archived when its notes grew too long, "+
+					"with a summary of them when a summarizer is configured",
+				entityType,
+			),
+		),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description(fmt.Sprintf("%s ID", entityTypeTitle(entityType))),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		failure := s.localizer.Sprintf("Failed to get archived %s notes", s.localizer.Translate(string(entityType)))
+		if err := s.checkEntityScope(ctx, entityType, id); err != nil {
+			return s.toolError(failure, err), nil
+		}
+
+		archive, err := s.compactor.Archived(ctx, entityType, id)
+		if err != nil {
+			return s.toolError(failure, err), nil
+		}
+
+		archiveJson, err := json.Marshal(archive)
+		if err != nil {
+			return s.toolError("Failed to marshal archived notes", err), nil
+		}
+		return mcp.NewToolResultText(string(archiveJson)), nil
+	})
+}
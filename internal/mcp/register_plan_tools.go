@@ -3,12 +3,15 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"time"
+	"slices"
 
 	"github.com/mark3labs/mcp-go/mcp"
 
 	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
 	"github.com/jbrinkman/valkey-ai-tasks/internal/utils/markdown"
 )
 
@@ -16,12 +19,24 @@ import (
 func (s *MCPGoServer) registerPlanTools() {
 	s.registerCreatePlanTool()
 	s.registerGetPlanTool()
+	s.registerGetPlanFullTool()
 	s.registerListPlansTool()
 	s.registerListPlansByApplicationTool()
 	s.registerUpdatePlanTool()
 	s.registerDeletePlanTool()
 	s.registerUpdatePlanStatusTool()
 	s.registerListPlansByStatusTool()
+	s.registerGetPlanProgressTool()
+	s.registerGetPlanCapacityReportTool()
+	s.registerValidatePlanTool()
+	s.registerGetPlanCompletionsTool()
+	s.registerExportPlanMarkdownTool()
+	s.registerExportPlanMermaidTool()
+	s.registerImportPlanFromMarkdownTool()
+	s.registerClonePlanTool()
+	s.registerReorderPlanTool()
+	s.registerUpdatePlanPriorityTool()
+	s.registerDiffPlansTool()
 }
 
 // validatePlanStatus checks if the provided status is a valid plan status
@@ -35,8 +50,19 @@ func validatePlanStatus(status models.PlanStatus) error {
 	return nil
 }
 
+// validatePlanPriority checks if the provided priority is a valid plan priority
+func validatePlanPriority(priority models.PlanPriority) error {
+	if priority != models.PlanPriorityLow &&
+		priority != models.PlanPriorityMedium &&
+		priority != models.PlanPriorityHigh {
+		return fmt.Errorf("invalid priority: %s", priority)
+	}
+	return nil
+}
+
 func (s *MCPGoServer) registerCreatePlanTool() {
 	tool := mcp.NewTool("create_plan",
+		createTool,
 		mcp.WithDescription("Create a new plan for planning and organizing a feature or initiative"),
 		mcp.WithString("application_id",
 			mcp.Required(),
@@ -52,18 +78,19 @@ func (s *MCPGoServer) registerCreatePlanTool() {
 		mcp.WithString("notes",
 			mcp.Description("Initial Markdown-formatted notes for the plan (optional)"),
 		),
+		withIdempotencyKey(),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract parameters
 		applicationID, err := request.RequireString("application_id")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
 		name, err := request.RequireString("name")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
 		description := request.GetString("description", "no description provided")
@@ -72,14 +99,14 @@ func (s *MCPGoServer) registerCreatePlanTool() {
 		// Create the plan
 		plan, err := s.planRepo.Create(ctx, applicationID, name, description)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create plan: %v", err)), nil
+			return s.toolError("Failed to create plan", err), nil
 		}
 
 		// If notes were provided, validate, format and update them
 		if notes != "" {
 			// Import markdown utilities
 			if err := markdown.Validate(notes); err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Invalid notes format: %v", err)), nil
+				return s.errorResult("Invalid notes format", err, models.ErrorCodeValidation), nil
 			}
 
 			// Sanitize and format the notes
@@ -88,19 +115,19 @@ func (s *MCPGoServer) registerCreatePlanTool() {
 
 			err = s.planRepo.UpdateNotes(ctx, plan.ID, notes)
 			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Failed to set initial notes: %v", err)), nil
+				return s.toolError("Failed to set initial notes", err), nil
 			}
 
 			// Refresh plan to include notes
 			plan, err = s.planRepo.Get(ctx, plan.ID)
 			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Failed to refresh plan: %v", err)), nil
+				return s.toolError("Failed to refresh plan", err), nil
 			}
 		}
 
 		planJson, err := json.Marshal(plan)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal plan: %v", err)), nil
+			return s.toolError("Failed to marshal plan", err), nil
 		}
 		return mcp.NewToolResultText(string(planJson)), nil
 	})
@@ -108,46 +135,98 @@ func (s *MCPGoServer) registerCreatePlanTool() {
 
 func (s *MCPGoServer) registerGetPlanTool() {
 	tool := mcp.NewTool("get_plan",
+		readOnlyTool,
 		mcp.WithDescription("Retrieve details about a specific feature planning plan"),
 		mcp.WithString("id",
 			mcp.Required(),
 			mcp.Description("Plan ID"),
 		),
+		mcp.WithBoolean("include_tasks",
+			mcp.Description("Also return the tasks of the plan in their order, like get_plan_full (optional, defaults to false)"),
+		),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		id, err := request.RequireString("id")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
+		}
+
+		if request.GetBool("include_tasks", false) {
+			return s.planWithTasksResult(ctx, id), nil
 		}
 
 		plan, err := s.planRepo.Get(ctx, id)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get plan: %v", err)), nil
+			return s.toolError("Failed to get plan", err), nil
 		}
 
 		planJson, err := json.Marshal(plan)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal plan: %v", err)), nil
+			return s.toolError("Failed to marshal plan", err), nil
 		}
 		return mcp.NewToolResultText(string(planJson)), nil
 	})
 }
 
+func (s *MCPGoServer) registerGetPlanFullTool() {
+	tool := mcp.NewTool("get_plan_full",
+		readOnlyTool,
+		mcp.WithDescription(
+			"Retrieve a plan together with all of its tasks in their order in one call, the same view as the "+
+				"ai-tasks://plans/{id}/full resource. Notes too long to include are replaced by the URI of "+
+				"their notes resource",
+		),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+		return s.planWithTasksResult(ctx, id), nil
+	})
+}
+
+// planWithTasksResult returns the result of a tool returning a plan with its tasks, in the form of the plan
+// resource
+func (s *MCPGoServer) planWithTasksResult(ctx context.Context, id string) *mcp.CallToolResult {
+	plan, err := s.planRepo.Get(ctx, id)
+	if err != nil {
+		return s.toolError("Failed to get plan", err)
+	}
+
+	tasks, err := s.taskRepo.ListByPlan(ctx, id)
+	if err != nil {
+		return s.toolError("Failed to list tasks by plan", err)
+	}
+
+	planJson, err := json.Marshal(newPlanResource(plan, tasks))
+	if err != nil {
+		return s.toolError("Failed to marshal plan", err)
+	}
+	return mcp.NewToolResultText(string(planJson))
+}
+
 func (s *MCPGoServer) registerListPlansTool() {
 	tool := mcp.NewTool("list_plans",
+		readOnlyTool,
 		mcp.WithDescription("List all available feature planning plans"),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		plans, err := s.planRepo.List(ctx)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list plans: %v", err)), nil
+			return s.toolError("Failed to list plans", err), nil
 		}
 
 		plansJson, err := json.Marshal(plans)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal plans: %v", err)), nil
+			return s.toolError("Failed to marshal plans", err), nil
 		}
 		return mcp.NewToolResultText(string(plansJson)), nil
 	})
@@ -155,27 +234,30 @@ func (s *MCPGoServer) registerListPlansTool() {
 
 func (s *MCPGoServer) registerListPlansByApplicationTool() {
 	tool := mcp.NewTool("list_plans_by_application",
-		mcp.WithDescription("List all feature planning plans for a specific application"),
+		readOnlyTool,
+		mcp.WithDescription(
+			"List all feature planning plans for a specific application in their order, the plan to work on first coming first",
+		),
 		mcp.WithString("application_id",
 			mcp.Required(),
 			mcp.Description("Application ID to filter plans by"),
 		),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		applicationID, err := request.RequireString("application_id")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
 		plans, err := s.planRepo.ListByApplication(ctx, applicationID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list plans by application: %v", err)), nil
+			return s.toolError("Failed to list plans by application", err), nil
 		}
 
 		plansJson, err := json.Marshal(plans)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal plans: %v", err)), nil
+			return s.toolError("Failed to marshal plans", err), nil
 		}
 		return mcp.NewToolResultText(string(plansJson)), nil
 	})
@@ -183,6 +265,7 @@ func (s *MCPGoServer) registerListPlansByApplicationTool() {
 
 func (s *MCPGoServer) registerUpdatePlanStatusTool() {
 	tool := mcp.NewTool("update_plan_status",
+		updateTool,
 		mcp.WithDescription("Update the status of a plan"),
 		mcp.WithString("id",
 			mcp.Required(),
@@ -194,42 +277,32 @@ func (s *MCPGoServer) registerUpdatePlanStatusTool() {
 		),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		id, err := request.RequireString("id")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
 		statusStr, err := request.RequireString("status")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
 		// Validate status
 		status := models.PlanStatus(statusStr)
 		if err := validatePlanStatus(status); err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
-		}
-
-		// Get the existing plan
-		plan, err := s.planRepo.Get(ctx, id)
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get plan: %v", err)), nil
+			return s.invalidArgument(err), nil
 		}
 
 		// Update status
-		plan.Status = status
-		plan.UpdatedAt = time.Now()
-
-		// Save the updated plan
-		err = s.planRepo.Update(ctx, plan)
+		plan, err := s.planRepo.UpdateStatus(ctx, id, status)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to update plan: %v", err)), nil
+			return s.toolError("Failed to update plan", err), nil
 		}
 
 		planJson, err := json.Marshal(plan)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal plan: %v", err)), nil
+			return s.toolError("Failed to marshal plan", err), nil
 		}
 		return mcp.NewToolResultText(string(planJson)), nil
 	})
@@ -237,6 +310,7 @@ func (s *MCPGoServer) registerUpdatePlanStatusTool() {
 
 func (s *MCPGoServer) registerUpdatePlanTool() {
 	tool := mcp.NewTool("update_plan",
+		updateTool,
 		mcp.WithDescription("Update the details or scope of a feature planning plan"),
 		mcp.WithString("id",
 			mcp.Required(),
@@ -253,16 +327,16 @@ func (s *MCPGoServer) registerUpdatePlanTool() {
 		),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		id, err := request.RequireString("id")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
 		// Get the existing plan
 		plan, err := s.planRepo.Get(ctx, id)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get plan: %v", err)), nil
+			return s.toolError("Failed to get plan", err), nil
 		}
 
 		// Update fields if provided
@@ -282,7 +356,7 @@ func (s *MCPGoServer) registerUpdatePlanTool() {
 			// Validate and format the markdown content
 			err = markdown.Validate(notes)
 			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Invalid notes format: %v", err)), nil
+				return s.errorResult("Invalid notes format", err, models.ErrorCodeValidation), nil
 			}
 
 			// Sanitize and format the notes
@@ -292,7 +366,7 @@ func (s *MCPGoServer) registerUpdatePlanTool() {
 			// Update notes separately using the dedicated method
 			err = s.planRepo.UpdateNotes(ctx, id, notes)
 			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Failed to update notes: %v", err)), nil
+				return s.toolError("Failed to update notes", err), nil
 			}
 			// Update plan.Notes for the response
 			plan.Notes = notes
@@ -301,12 +375,12 @@ func (s *MCPGoServer) registerUpdatePlanTool() {
 		// Save the updated plan
 		err = s.planRepo.Update(ctx, plan)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to update plan: %v", err)), nil
+			return s.toolError("Failed to update plan", err), nil
 		}
 
 		planJson, err := json.Marshal(plan)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal plan: %v", err)), nil
+			return s.toolError("Failed to marshal plan", err), nil
 		}
 		return mcp.NewToolResultText(string(planJson)), nil
 	})
@@ -314,30 +388,154 @@ func (s *MCPGoServer) registerUpdatePlanTool() {
 
 func (s *MCPGoServer) registerDeletePlanTool() {
 	tool := mcp.NewTool("delete_plan",
-		mcp.WithDescription("Remove a completed or cancelled feature planning plan"),
+		deleteTool,
+		mcp.WithDescription(
+			"Remove a feature planning plan with its tasks. When the plan still has open tasks, open_tasks says "+
+				"whether to delete them or move them to another plan; without it, clients that support elicitation "+
+				"ask the user and other calls fail",
+		),
 		mcp.WithString("id",
 			mcp.Required(),
 			mcp.Description("Plan ID"),
 		),
+		mcp.WithString("open_tasks",
+			mcp.Description("What to do with the pending and in progress tasks of the plan: delete them with the "+
+				"plan, or move them to target_plan_id (optional)"),
+			mcp.Enum(string(openTasksDelete), string(openTasksMove)),
+		),
+		mcp.WithString("target_plan_id",
+			mcp.Description("ID of the plan the open tasks are moved to (required when moving them)"),
+		),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		id, err := request.RequireString("id")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
+		choice := openTasksChoice(request.GetString("open_tasks", ""))
+		targetPlanID := request.GetString("target_plan_id", "")
 
-		err = s.planRepo.Delete(ctx, id)
+		tasks, err := s.taskRepo.ListByPlan(ctx, id)
+		if err != nil {
+			return s.toolError("Failed to delete plan", err), nil
+		}
+		openTasks := openTaskIDs(tasks)
+
+		// Ask the user before taking the lock, since they may take their time to answer
+		switch {
+		case len(openTasks) == 0:
+			err = s.confirm(ctx, models.EntityPlan, id, "Delete plan %s?", id)
+		case choice == "":
+			choice, targetPlanID, err = s.askOpenTasks(ctx, id, len(openTasks))
+		}
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete plan: %v", err)), nil
+			return s.toolError("Failed to delete plan", err), nil
+		}
+
+		moveOpenTasks := len(openTasks) > 0 && choice == openTasksMove
+		if moveOpenTasks {
+			if targetPlanID == "" || targetPlanID == id {
+				return s.validationError("moving the open tasks requires a target_plan_id other than the plan"), nil
+			}
+			if _, err := s.planRepo.Get(ctx, targetPlanID); err != nil {
+				return s.toolError("Failed to get target plan", err), nil
+			}
+		}
+
+		ctx, unlock, err := s.planRepo.LockPlan(ctx, id)
+		if err != nil {
+			return s.toolError("Failed to delete plan", err), nil
+		}
+		defer unlock()
+
+		// The answer only covers the open tasks the user was asked about
+		tasks, err = s.taskRepo.ListByPlan(ctx, id)
+		if err != nil {
+			return s.toolError("Failed to delete plan", err), nil
+		}
+		if !slices.Equal(openTaskIDs(tasks), openTasks) {
+			return s.toolError("Failed to delete plan", models.NewConflictError(models.EntityPlan, id,
+				"the open tasks of plan %s changed while deleting it, try again", id)), nil
+		}
+
+		// The open tasks are moved and the plan is deleted all or nothing
+		err = s.planRepo.WriteAtomically(ctx, id, func(ctx context.Context) error {
+			if moveOpenTasks {
+				for _, taskID := range openTasks {
+					if _, err := s.taskRepo.MoveTask(ctx, taskID, targetPlanID, -1); err != nil {
+						return fmt.Errorf("failed to move task %s: %w", taskID, err)
+					}
+				}
+			}
+			return s.planRepo.Delete(ctx, id)
+		})
+		if err != nil {
+			return s.toolError("Failed to delete plan", err), nil
 		}
 
 		return mcp.NewToolResultText(`{"result":"Plan deleted"}`), nil
 	})
 }
 
+// openTaskIDs returns the sorted IDs of the pending and in progress tasks among tasks
+func openTaskIDs(tasks []*models.Task) []string {
+	var ids []string
+	for _, task := range tasks {
+		if task.IsOpen() {
+			ids = append(ids, task.ID)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// openTasksChoice is what delete_plan does with the open tasks of the plan
+type openTasksChoice string
+
+const (
+	openTasksDelete openTasksChoice = "delete"
+	openTasksMove   openTasksChoice = "move"
+)
+
+// askOpenTasks asks the user whether to delete the open tasks of a plan or move them to another plan. Calls
+// from clients that cannot be asked fail with a conflict, so open tasks are never deleted without a choice.
+func (s *MCPGoServer) askOpenTasks(ctx context.Context, planID string, count int) (openTasksChoice, string, error) {
+	content, err := s.elicit(ctx, models.EntityPlan, planID,
+		s.localizer.Sprintf("Plan %s has %d open tasks. Delete them with the plan or move them to another plan?",
+			planID, count),
+		map[string]any{
+			"open_tasks": map[string]any{
+				"type":        "string",
+				"title":       s.localizer.Translate("Open tasks"),
+				"description": s.localizer.Translate("Delete the open tasks with the plan, or move them"),
+				"enum":        []string{string(openTasksDelete), string(openTasksMove)},
+			},
+			"target_plan_id": map[string]any{
+				"type":        "string",
+				"title":       s.localizer.Translate("Target plan"),
+				"description": s.localizer.Translate("ID of the plan the open tasks are moved to"),
+			},
+		}, "open_tasks")
+	if errors.Is(err, errElicitationUnavailable) {
+		return "", "", models.NewConflictError(models.EntityPlan, planID,
+			"plan %s has %d open tasks; set open_tasks to delete or move them", planID, count)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	choice, _ := content["open_tasks"].(string)
+	targetPlanID, _ := content["target_plan_id"].(string)
+	if choice != string(openTasksDelete) && choice != string(openTasksMove) {
+		return "", "", cancelledError(models.EntityPlan, planID)
+	}
+	return openTasksChoice(choice), targetPlanID, nil
+}
+
 func (s *MCPGoServer) registerListPlansByStatusTool() {
 	tool := mcp.NewTool("list_plans_by_status",
+		readOnlyTool,
 		mcp.WithDescription("Find plans by their current status (new, inprogress, completed, cancelled)"),
 		mcp.WithString("status",
 			mcp.Required(),
@@ -345,28 +543,539 @@ func (s *MCPGoServer) registerListPlansByStatusTool() {
 		),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		statusStr, err := request.RequireString("status")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
 		// Validate status
 		status := models.PlanStatus(statusStr)
 		if err := validatePlanStatus(status); err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
 		// Get plans with the specified status
 		plans, err := s.planRepo.ListByStatus(ctx, status)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list plans by status: %v", err)), nil
+			return s.toolError("Failed to list plans by status", err), nil
 		}
 
 		plansJson, err := json.Marshal(plans)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal plans: %v", err)), nil
+			return s.toolError("Failed to marshal plans", err), nil
 		}
 		return mcp.NewToolResultText(string(plansJson)), nil
 	})
 }
+
+func (s *MCPGoServer) registerGetPlanProgressTool() {
+	tool := mcp.NewTool("get_plan_progress",
+		readOnlyTool,
+		mcp.WithDescription(
+			"Get computed progress metrics for a plan: task counts by status and priority, percent complete, "+
+				"blocked and overdue tasks, estimated remaining work, and the progress of its milestones with "+
+				"an at-risk flag when the open tasks are unlikely to be completed by the target date",
+		),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		progress, err := s.planStats.GetPlanProgress(ctx, id)
+		if err != nil {
+			return s.toolError("Failed to get plan progress", err), nil
+		}
+
+		progressJson, err := json.Marshal(progress)
+		if err != nil {
+			return s.toolError("Failed to marshal plan progress", err), nil
+		}
+		return mcp.NewToolResultText(string(progressJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerGetPlanCapacityReportTool() {
+	tool := mcp.NewTool("get_plan_capacity_report",
+		readOnlyTool,
+		mcp.WithDescription(
+			"Compare the estimated work of a plan with the work completed. Given the capacity left, reports whether "+
+				"the remaining work exceeds it and which pending tasks to defer to fit, to negotiate scope",
+		),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		mcp.WithNumber("capacity",
+			mcp.Description("Work that can still be done, in the unit of the task estimates (optional)"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		var capacity *float64
+		if _, ok := request.GetArguments()["capacity"]; ok {
+			value := request.GetFloat("capacity", 0)
+			if err := models.ValidateEstimate(value); err != nil {
+				return s.validationError("capacity must be a non-negative number"), nil
+			}
+			capacity = &value
+		}
+
+		report, err := s.planStats.GetPlanCapacityReport(ctx, id, capacity)
+		if err != nil {
+			return s.toolError("Failed to get plan capacity report", err), nil
+		}
+
+		reportJson, err := json.Marshal(report)
+		if err != nil {
+			return s.toolError("Failed to marshal capacity report", err), nil
+		}
+		return mcp.NewToolResultText(string(reportJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerValidatePlanTool() {
+	tool := mcp.NewTool("validate_plan",
+		readOnlyTool,
+		mcp.WithDescription(
+			"Check that a plan is ready to be worked on before execution starts: tasks have descriptions, "+
+				"dependencies form no cycles, open tasks are estimated and no dependency, link or milestone points "+
+				"at a missing task. Returns the issues found with their severity; the plan is valid when none is an error",
+		),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		mcp.WithArray("checks",
+			mcp.Description("Checks to run (optional, runs all checks if omitted)"),
+			mcp.Items(map[string]any{"type": "string", "enum": models.PlanCheckNames()}),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		checks, err := models.ParsePlanChecks(request.GetStringSlice("checks", nil))
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		report, err := s.planStats.ValidatePlan(ctx, planID, checks)
+		if err != nil {
+			return s.toolError("Failed to validate plan", err), nil
+		}
+
+		reportJson, err := json.Marshal(report)
+		if err != nil {
+			return s.toolError("Failed to marshal validation report", err), nil
+		}
+		return mcp.NewToolResultText(string(reportJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerGetPlanCompletionsTool() {
+	tool := mcp.NewTool("get_plan_completions",
+		readOnlyTool,
+		mcp.WithDescription(
+			"Get the record of delivered work of a plan: the completion notes given when its tasks were completed, "+
+				"oldest first, including tasks that were reopened or deleted since",
+		),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		completions, err := s.taskRepo.ListCompletions(ctx, planID)
+		if err != nil {
+			return s.toolError("Failed to list completion notes", err), nil
+		}
+
+		completionsJson, err := json.Marshal(completions)
+		if err != nil {
+			return s.toolError("Failed to marshal completion notes", err), nil
+		}
+		return mcp.NewToolResultText(string(completionsJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerExportPlanMarkdownTool() {
+	tool := mcp.NewTool("export_plan_markdown",
+		readOnlyTool,
+		mcp.WithDescription(
+			"Render a plan with its tasks, statuses, priorities and notes as a markdown progress report, "+
+				"ready to paste into a PR description or status update",
+		),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		report, err := s.planStats.GetPlanMarkdown(ctx, id)
+		if err != nil {
+			return s.toolError("Failed to export plan as markdown", err), nil
+		}
+		return mcp.NewToolResultText(report), nil
+	})
+}
+
+func (s *MCPGoServer) registerExportPlanMermaidTool() {
+	tool := mcp.NewTool("export_plan_mermaid",
+		readOnlyTool,
+		mcp.WithDescription(
+			"Render a plan as a Mermaid diagram to embed in documentation: a Gantt chart laying the tasks out "+
+				"from their start, completion and due dates, estimates and dependencies, or a flowchart of the "+
+				"dependencies between the tasks colored by status",
+		),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		mcp.WithString("diagram",
+			mcp.Description("Kind of diagram (optional, defaults to gantt)"),
+			mcp.Enum(string(services.MermaidGantt), string(services.MermaidFlowchart)),
+		),
+		mcp.WithString("estimate_unit",
+			mcp.Description(
+				"Time one unit of task estimate takes on the Gantt chart (optional, defaults to days). "+
+					"Tasks without an estimate take one unit",
+			),
+			mcp.Enum(string(services.EstimateUnitHours), string(services.EstimateUnitDays), string(services.EstimateUnitWeeks)),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		opts := services.MermaidOptions{
+			Diagram:      services.MermaidDiagram(request.GetString("diagram", string(services.MermaidGantt))),
+			EstimateUnit: services.EstimateUnit(request.GetString("estimate_unit", string(services.EstimateUnitDays))),
+		}
+		diagram, err := s.planStats.GetPlanMermaid(ctx, id, opts)
+		if err != nil {
+			return s.toolError("Failed to export plan as Mermaid", err), nil
+		}
+		return mcp.NewToolResultText(diagram), nil
+	})
+}
+
+func (s *MCPGoServer) registerImportPlanFromMarkdownTool() {
+	tool := mcp.NewTool("import_plan_from_markdown",
+		createTool,
+		mcp.WithDescription(
+			"Create a plan from a markdown checklist such as a TODO.md file. The level 1 heading becomes the plan "+
+				"name and the text under it the description. Every checkbox becomes a task, completed when checked, "+
+				"with the indented text under it as its description, nested checkboxes as its checklist and the "+
+				"heading it is under as a tag",
+		),
+		mcp.WithString("application_id",
+			mcp.Required(),
+			mcp.Description("Application ID for the new plan"),
+		),
+		mcp.WithString("markdown",
+			mcp.Required(),
+			mcp.Description("Markdown document with checkbox lists (- [ ] to do, - [x] done)"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Name of the plan (optional, defaults to the level 1 heading of the document)"),
+		),
+		mcp.WithString("description",
+			mcp.Description("Description of the plan (optional, defaults to the text under the level 1 heading)"),
+		),
+		withIdempotencyKey(),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		applicationID, err := request.RequireString("application_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		content, err := request.RequireString("markdown")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		opts := services.MarkdownImportOptions{
+			Name:        request.GetString("name", ""),
+			Description: request.GetString("description", ""),
+		}
+		resource, err := s.planImport.ImportMarkdown(ctx, applicationID, content, opts)
+		if err != nil {
+			return s.toolError("Failed to import plan from markdown", err), nil
+		}
+
+		resourceJson, err := json.Marshal(resource)
+		if err != nil {
+			return s.toolError("Failed to marshal plan", err), nil
+		}
+		return mcp.NewToolResultText(string(resourceJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerClonePlanTool() {
+	tool := mcp.NewTool("clone_plan",
+		createTool,
+		mcp.WithDescription(
+			"Copy a plan and all of its tasks into a new plan, e.g. to repeat a similar feature workflow. "+
+				"Dependencies between the copied tasks are preserved",
+		),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("ID of the plan to clone"),
+		),
+		mcp.WithString("application_id",
+			mcp.Description("Application ID for the new plan (optional, defaults to the source plan's application)"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Name of the new plan (optional, defaults to the source name with a '(copy)' suffix)"),
+		),
+		mcp.WithBoolean("reset_status",
+			mcp.Description("Reset all copied tasks to pending (optional, defaults to false)"),
+		),
+		mcp.WithBoolean("clear_notes",
+			mcp.Description("Do not copy the notes of the plan and its tasks (optional, defaults to false)"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		opts := storage.PlanCloneOptions{
+			ApplicationID: request.GetString("application_id", ""),
+			Name:          request.GetString("name", ""),
+			ResetStatus:   request.GetBool("reset_status", false),
+			ClearNotes:    request.GetBool("clear_notes", false),
+		}
+
+		plan, err := s.planRepo.Clone(ctx, id, opts)
+		if err != nil {
+			return s.toolError("Failed to clone plan", err), nil
+		}
+
+		planJson, err := json.Marshal(plan)
+		if err != nil {
+			return s.toolError("Failed to marshal plan", err), nil
+		}
+		return mcp.NewToolResultText(string(planJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerReorderPlanTool() {
+	tool := mcp.NewTool("reorder_plan",
+		updateTool,
+		mcp.WithDescription("Change the position of a plan among the plans of its application, which are worked on in order"),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		mcp.WithNumber("new_order",
+			mcp.Required(),
+			mcp.Description("New position of the plan, starting at 0 for the plan to work on first"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		newOrder, err := request.RequireFloat("new_order")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		plan, err := s.planRepo.ReorderPlan(ctx, id, int(newOrder))
+		if err != nil {
+			return s.toolError("Failed to reorder plan", err), nil
+		}
+
+		planJson, err := json.Marshal(plan)
+		if err != nil {
+			return s.toolError("Failed to marshal plan", err), nil
+		}
+		return mcp.NewToolResultText(string(planJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerUpdatePlanPriorityTool() {
+	tool := mcp.NewTool("update_plan_priority",
+		updateTool,
+		mcp.WithDescription("Update the priority of a plan compared to the other plans of its application"),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		mcp.WithString("priority",
+			mcp.Required(),
+			mcp.Description("New priority value"),
+			mcp.Enum(string(models.PlanPriorityLow), string(models.PlanPriorityMedium), string(models.PlanPriorityHigh)),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		priorityStr, err := request.RequireString("priority")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		priority := models.PlanPriority(priorityStr)
+		if err := validatePlanPriority(priority); err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		plan, err := s.planRepo.Get(ctx, id)
+		if err != nil {
+			return s.toolError("Failed to get plan", err), nil
+		}
+
+		plan.Priority = priority
+		err = s.planRepo.Update(ctx, plan)
+		if err != nil {
+			return s.toolError("Failed to update plan", err), nil
+		}
+
+		planJson, err := json.Marshal(plan)
+		if err != nil {
+			return s.toolError("Failed to marshal plan", err), nil
+		}
+		return mcp.NewToolResultText(string(planJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerDiffPlansTool() {
+	tool := mcp.NewTool("diff_plans",
+		readOnlyTool,
+		mcp.WithDescription(
+			"Compare a plan with a base version, either another plan or a snapshot exported earlier, and return "+
+				"the added, removed and changed tasks with the fields that differ. Tasks are matched by ID, then "+
+				"by title, so a regenerated plan can be compared with the plan it replaces",
+		),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("ID of the plan to compare"),
+		),
+		mcp.WithString("base_plan_id",
+			mcp.Description("ID of the plan to compare with. Either base_plan_id or base_snapshot is required."),
+		),
+		mcp.WithObject("base_snapshot",
+			mcp.Description(
+				"Plan to compare with, as exported with its tasks by get_plan_full or the "+
+					"ai-tasks://plans/{id}/full resource. Either base_plan_id or base_snapshot is required.",
+			),
+			mcp.Properties(planSnapshotProperties),
+			withRequiredProperties("plan"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		basePlanID := request.GetString("base_plan_id", "")
+		rawSnapshot, hasSnapshot := request.GetArguments()["base_snapshot"]
+		if (basePlanID == "") == !hasSnapshot {
+			return s.validationError("exactly one of base_plan_id or base_snapshot is required"), nil
+		}
+
+		var base *models.PlanResource
+		if hasSnapshot {
+			base, err = parsePlanSnapshot(rawSnapshot)
+			if err != nil {
+				return s.invalidArgument(err), nil
+			}
+		} else {
+			base, err = s.planWithTasks(ctx, basePlanID)
+			if err != nil {
+				return s.toolError("Failed to get base plan", err), nil
+			}
+		}
+
+		target, err := s.planWithTasks(ctx, planID)
+		if err != nil {
+			return s.toolError("Failed to get plan", err), nil
+		}
+
+		diff, err := models.DiffPlans(base, target)
+		if err != nil {
+			return s.toolError("Failed to compare plans", err), nil
+		}
+
+		diffJson, err := json.Marshal(diff)
+		if err != nil {
+			return s.toolError("Failed to marshal plan diff", err), nil
+		}
+		return mcp.NewToolResultText(string(diffJson)), nil
+	})
+}
+
+// planWithTasks gets a plan with its tasks in their order
+func (s *MCPGoServer) planWithTasks(ctx context.Context, id string) (*models.PlanResource, error) {
+	plan, err := s.planRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := s.taskRepo.ListByPlan(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewPlanResource(plan, tasks), nil
+}
+
+// parsePlanSnapshot reads an exported plan passed as an argument
+func parsePlanSnapshot(raw any) (*models.PlanResource, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read base_snapshot: %v", err)
+	}
+	var snapshot models.PlanResource
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to read base_snapshot: %v", err)
+	}
+	if snapshot.Plan == nil {
+		return nil, fmt.Errorf("base_snapshot has no plan")
+	}
+	return &snapshot, nil
+}
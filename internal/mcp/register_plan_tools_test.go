@@ -0,0 +1,104 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+func TestGetPlanWithTasks(t *testing.T) {
+	s := newTestServer(t)
+
+	var plan models.Plan
+	result := callTool(t, s, "create_plan", map[string]any{"application_id": "app", "name": "Plan"})
+	if err := json.Unmarshal([]byte(toolResultText(result)), &plan); err != nil {
+		t.Fatalf("failed to parse plan: %v", err)
+	}
+	result = callTool(t, s, "bulk_create_tasks", map[string]any{
+		"plan_id": plan.ID,
+		"tasks":   []any{map[string]any{"title": "First"}, map[string]any{"title": "Second"}},
+	})
+	if result.IsError {
+		t.Fatalf("failed to create tasks: %s", toolResultText(result))
+	}
+
+	tests := []struct {
+		name      string
+		tool      string
+		arguments map[string]any
+	}{
+		{name: "get_plan with include_tasks", tool: "get_plan", arguments: map[string]any{"id": plan.ID, "include_tasks": true}},
+		{name: "get_plan_full", tool: "get_plan_full", arguments: map[string]any{"id": plan.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := callTool(t, s, tt.tool, tt.arguments)
+			if result.IsError {
+				t.Fatalf("expected a result, got %s", toolResultText(result))
+			}
+			var resource models.PlanResource
+			if err := json.Unmarshal([]byte(toolResultText(result)), &resource); err != nil {
+				t.Fatalf("failed to parse result: %v", err)
+			}
+			if resource.Plan == nil || resource.Plan.ID != plan.ID {
+				t.Fatalf("expected plan %s, got %+v", plan.ID, resource.Plan)
+			}
+			if len(resource.Tasks) != 2 || resource.Tasks[0].Title != "First" || resource.Tasks[1].Title != "Second" {
+				t.Errorf("expected tasks First and Second in order, got %+v", resource.Tasks)
+			}
+		})
+	}
+
+	// Without the flag, get_plan keeps returning the plan alone
+	result = callTool(t, s, "get_plan", map[string]any{"id": plan.ID})
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(toolResultText(result)), &fields); err != nil {
+		t.Fatalf("failed to parse plan: %v", err)
+	}
+	if fields["id"] != plan.ID || fields["tasks"] != nil {
+		t.Errorf("expected the plan alone, got %v", fields)
+	}
+}
+
+func TestDiffPlansWithSnapshot(t *testing.T) {
+	s := newTestServer(t)
+
+	var plan models.Plan
+	result := callTool(t, s, "create_plan", map[string]any{"application_id": "app", "name": "Plan"})
+	if err := json.Unmarshal([]byte(toolResultText(result)), &plan); err != nil {
+		t.Fatalf("failed to parse plan: %v", err)
+	}
+	callTool(t, s, "bulk_create_tasks", map[string]any{
+		"plan_id": plan.ID,
+		"tasks":   []any{map[string]any{"title": "First"}, map[string]any{"title": "Second"}},
+	})
+
+	var snapshot map[string]any
+	result = callTool(t, s, "get_plan_full", map[string]any{"id": plan.ID})
+	if err := json.Unmarshal([]byte(toolResultText(result)), &snapshot); err != nil {
+		t.Fatalf("failed to parse snapshot: %v", err)
+	}
+	callTool(t, s, "create_task", map[string]any{"plan_id": plan.ID, "title": "Third"})
+
+	result = callTool(t, s, "diff_plans", map[string]any{"plan_id": plan.ID, "base_snapshot": snapshot})
+	if result.IsError {
+		t.Fatalf("expected a diff, got %s", toolResultText(result))
+	}
+	var diff models.PlanDiff
+	if err := json.Unmarshal([]byte(toolResultText(result)), &diff); err != nil {
+		t.Fatalf("failed to parse diff: %v", err)
+	}
+	if len(diff.AddedTasks) != 1 || diff.AddedTasks[0].Title != "Third" {
+		t.Errorf("expected Third to be added, got %+v", diff.AddedTasks)
+	}
+	if diff.UnchangedTasks != 2 || len(diff.RemovedTasks) != 0 || len(diff.ChangedTasks) != 0 {
+		t.Errorf("expected the other tasks to be unchanged, got %+v", diff)
+	}
+
+	result = callTool(t, s, "diff_plans", map[string]any{"plan_id": plan.ID})
+	if !result.IsError {
+		t.Errorf("expected an error without a base, got %s", toolResultText(result))
+	}
+}
@@ -3,6 +3,36 @@ package mcp
 // registerResources registers all resources with the MCP server
 func (s *MCPGoServer) registerResources() {
 	// Create and register the plan resource provider
-	planResourceProvider := NewPlanResourceProvider(s.planRepo, s.taskRepo)
+	planResourceProvider := NewPlanResourceProvider(s.planRepo, s.taskRepo, s.config.MaxResourceSize)
 	planResourceProvider.RegisterResource(s)
+
+	// Create and register the task resource provider
+	taskResourceProvider := NewTaskResourceProvider(s.planRepo, s.taskRepo)
+	taskResourceProvider.RegisterResource(s)
+
+	// Create and register the application resource provider
+	applicationResourceProvider := NewApplicationResourceProvider(s.planStats)
+	applicationResourceProvider.RegisterResource(s)
+
+	// Create and register the plan markdown report resource provider
+	planMarkdownResourceProvider := NewPlanMarkdownResourceProvider(s.planStats)
+	planMarkdownResourceProvider.RegisterResource(s)
+
+	// Create and register the plan export resource provider
+	planExportResourceProvider := NewPlanExportResourceProvider(s.planRepo, s.taskRepo)
+	planExportResourceProvider.RegisterResource(s)
+
+	// Create and register the plan and task notes resource provider
+	notesResourceProvider := NewNotesResourceProvider(s.planRepo, s.taskRepo)
+	notesResourceProvider.RegisterResource(s)
+
+	// Create and register the plan timeline resource provider, which reads the audit log
+	if s.timeline != nil {
+		timelineResourceProvider := NewTimelineResourceProvider(s.timeline)
+		timelineResourceProvider.RegisterResource(s)
+	}
+
+	// Create and register the tool schema resource provider for the tools registered so far
+	toolSchemaResourceProvider := NewToolSchemaResourceProvider(s.tools)
+	toolSchemaResourceProvider.RegisterResource(s)
 }
@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
+)
+
+// registerStaleWorkTools registers the tools detecting abandoned work with the MCP server
+func (s *MCPGoServer) registerStaleWorkTools() {
+	s.registerListStaleTasksTool()
+	s.registerListStalePlansTool()
+}
+
+// staleApplicationOption adds the optional application filter to the stale work tools
+func staleApplicationOption() mcp.ToolOption {
+	return mcp.WithString("application_id",
+		mcp.Description("Only look at the plans of this application (optional)"),
+	)
+}
+
+// staleThresholdOption adds the optional threshold to the stale work tools
+func staleThresholdOption() mcp.ToolOption {
+	return mcp.WithNumber("threshold_minutes",
+		mcp.Description(fmt.Sprintf(
+			"Minutes without any change after which work in progress is stale (optional, defaults to %d)",
+			int(services.DefaultStaleThreshold.Minutes()),
+		)),
+	)
+}
+
+// staleThreshold reads the threshold parameter of the stale work tools
+func staleThreshold(request mcp.CallToolRequest) (time.Duration, error) {
+	minutes := request.GetFloat("threshold_minutes", services.DefaultStaleThreshold.Minutes())
+	if minutes <= 0 {
+		return 0, fmt.Errorf("threshold_minutes must be positive")
+	}
+	return time.Duration(minutes * float64(time.Minute)), nil
+}
+
+func (s *MCPGoServer) registerListStaleTasksTool() {
+	tool := mcp.NewTool("list_stale_tasks",
+		readOnlyTool,
+		mcp.WithDescription(
+			"Find in-progress tasks that look abandoned, such as by a crashed agent session: claimed tasks "+
+				"whose lease expired and other tasks without any change for longer than the threshold. "+
+				"Longest idle tasks come first",
+		),
+		staleApplicationOption(),
+		staleThresholdOption(),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		threshold, err := staleThreshold(request)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		stale, err := s.planStats.ListStaleTasks(ctx, request.GetString("application_id", ""), threshold)
+		if err != nil {
+			return s.toolError("Failed to list stale tasks", err), nil
+		}
+
+		staleJson, err := json.Marshal(stale)
+		if err != nil {
+			return s.toolError("Failed to marshal stale tasks", err), nil
+		}
+		return mcp.NewToolResultText(string(staleJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerListStalePlansTool() {
+	tool := mcp.NewTool("list_stale_plans",
+		readOnlyTool,
+		mcp.WithDescription(
+			"Find in-progress plans none of whose tasks changed for longer than the threshold, with the number "+
+				"of tasks left in progress. Longest idle plans come first",
+		),
+		staleApplicationOption(),
+		staleThresholdOption(),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		threshold, err := staleThreshold(request)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		stale, err := s.planStats.ListStalePlans(ctx, request.GetString("application_id", ""), threshold)
+		if err != nil {
+			return s.toolError("Failed to list stale plans", err), nil
+		}
+
+		staleJson, err := json.Marshal(stale)
+		if err != nil {
+			return s.toolError("Failed to marshal stale plans", err), nil
+		}
+		return mcp.NewToolResultText(string(staleJson)), nil
+	})
+}
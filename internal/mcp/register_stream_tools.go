@@ -0,0 +1,136 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+const (
+	// taskChunkMethod is the method of the notifications carrying streamed tasks
+	taskChunkMethod = "notifications/tasks/chunk"
+	// defaultTaskChunkSize is the number of tasks per chunk when no chunk size is given
+	defaultTaskChunkSize = 100
+	// maxTaskChunkSize is the largest accepted chunk size
+	maxTaskChunkSize = 1000
+	// notificationPollInterval is how often a stream checks whether the client took the previous chunk
+	notificationPollInterval = 5 * time.Millisecond
+	// notificationFlushGrace is how long a stream waits after the client took the last chunk, so that it is
+	// written before the result ends the response
+	notificationFlushGrace = 50 * time.Millisecond
+)
+
+func (s *MCPGoServer) registerStreamTasksByPlanTool() {
+	tool := mcp.NewTool("stream_tasks_by_plan",
+		readOnlyTool,
+		mcp.WithDescription(
+			"List all tasks in a feature implementation plan in chunks, for plans with thousands of tasks. "+
+				"Clients with a session, such as over the Streamable HTTP transport, receive each chunk as a "+
+				"notifications/tasks/chunk notification as soon as it is read from Valkey, and the result only "+
+				"counts the tasks and chunks sent. Clients without a session get all tasks in the result",
+		),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID to stream tasks of"),
+		),
+		mcp.WithNumber("chunk_size",
+			mcp.Description(fmt.Sprintf(
+				"Number of tasks per chunk (optional, defaults to %d, at most %d)", defaultTaskChunkSize, maxTaskChunkSize,
+			)),
+		),
+		tagFilterOption(),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+		chunkSize := request.GetInt("chunk_size", defaultTaskChunkSize)
+		if chunkSize < 1 || chunkSize > maxTaskChunkSize {
+			return s.validationError("chunk_size must be between 1 and %d", maxTaskChunkSize), nil
+		}
+
+		result := models.TaskStreamResult{PlanID: planID}
+		session := server.ClientSessionFromContext(ctx)
+		streaming := session != nil && session.Initialized()
+
+		err = s.taskRepo.StreamByPlan(ctx, planID, chunkSize, func(offset int, tasks []*models.Task) error {
+			tasks = filterTasksByTags(request, tasks)
+			if len(tasks) == 0 {
+				return nil
+			}
+			result.Total += len(tasks)
+			if !streaming {
+				result.Tasks = append(result.Tasks, tasks...)
+				return nil
+			}
+			result.Chunks++
+			return s.sendTaskChunk(ctx, session, models.TaskChunk{PlanID: planID, Offset: offset, Tasks: tasks})
+		})
+		if err != nil {
+			return s.toolError("Failed to stream tasks by plan", err), nil
+		}
+		if streaming && result.Chunks > 0 {
+			if err := flushNotifications(ctx, session); err != nil {
+				return s.toolError("Failed to stream tasks by plan", err), nil
+			}
+		}
+
+		resultJson, err := json.Marshal(result)
+		if err != nil {
+			return s.toolError("Failed to marshal tasks", err), nil
+		}
+		return mcp.NewToolResultText(string(resultJson)), nil
+	})
+}
+
+// sendTaskChunk sends a chunk of tasks as a notification once the client took the previous one, so that a slow
+// client holds up reading from Valkey instead of chunks piling up in memory or being dropped
+func (s *MCPGoServer) sendTaskChunk(ctx context.Context, session server.ClientSession, chunk models.TaskChunk) error {
+	if err := waitForNotifications(ctx, session); err != nil {
+		return err
+	}
+	return s.server.SendNotificationToClient(ctx, taskChunkMethod, map[string]any{
+		"plan_id": chunk.PlanID,
+		"offset":  chunk.Offset,
+		"tasks":   chunk.Tasks,
+	})
+}
+
+// waitForNotifications waits until the client took all notifications queued for the session
+func waitForNotifications(ctx context.Context, session server.ClientSession) error {
+	ticker := time.NewTicker(notificationPollInterval)
+	defer ticker.Stop()
+	for len(session.NotificationChannel()) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// flushNotifications waits until the client took all notifications queued for the session and the last one
+// taken had time to be written. The Streamable HTTP transport drops notifications not yet written when the
+// result ends the response.
+func flushNotifications(ctx context.Context, session server.ClientSession) error {
+	if err := waitForNotifications(ctx, session); err != nil {
+		return err
+	}
+	timer := time.NewTimer(notificationFlushGrace)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
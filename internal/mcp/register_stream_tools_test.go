@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// testSession is a client session whose notifications are read by the test
+type testSession struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *testSession) SessionID() string { return "test" }
+
+func (s *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return s.notifications }
+
+func (s *testSession) Initialize() {}
+
+func (s *testSession) Initialized() bool { return true }
+
+func TestStreamTasksByPlan(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	plan, err := s.planRepo.Create(ctx, "app-1", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	for i := range 5 {
+		if _, err := s.taskRepo.Create(ctx, plan.ID, fmt.Sprintf("Task %d", i), "", models.TaskPriorityMedium); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+
+	// Without a session the tasks come in the result
+	result := callTool(t, s, "stream_tasks_by_plan", map[string]any{"plan_id": plan.ID, "chunk_size": 2})
+	var summary models.TaskStreamResult
+	if err := json.Unmarshal([]byte(toolResultText(result)), &summary); err != nil {
+		t.Fatalf("failed to decode result %s: %v", toolResultText(result), err)
+	}
+	if summary.Total != 5 || summary.Chunks != 0 || len(summary.Tasks) != 5 || summary.Tasks[4].Title != "Task 4" {
+		t.Errorf("unexpected result without a session: %+v", summary)
+	}
+
+	if result := callTool(t, s, "stream_tasks_by_plan", map[string]any{"plan_id": plan.ID, "chunk_size": 0}); !result.IsError {
+		t.Error("expected a chunk size of zero to be rejected")
+	}
+
+	// With a session each chunk is sent as a notification
+	session := &testSession{notifications: make(chan mcp.JSONRPCNotification, 1)}
+	received := make(chan []models.TaskChunk)
+	go func() {
+		var chunks []models.TaskChunk
+		for notification := range session.notifications {
+			data, err := json.Marshal(notification.Params.AdditionalFields)
+			if err != nil {
+				t.Errorf("failed to encode notification: %v", err)
+			}
+			var chunk models.TaskChunk
+			if err := json.Unmarshal(data, &chunk); err != nil {
+				t.Errorf("failed to decode chunk: %v", err)
+			}
+			chunks = append(chunks, chunk)
+		}
+		received <- chunks
+	}()
+
+	message := fmt.Sprintf(
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"stream_tasks_by_plan","arguments":`+
+			`{"plan_id":%q,"chunk_size":2}}}`,
+		plan.ID,
+	)
+	response, ok := s.server.HandleMessage(s.server.WithContext(ctx, session), []byte(message)).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatal("stream_tasks_by_plan failed")
+	}
+	close(session.notifications)
+	chunks := <-received
+
+	streamed, ok := response.Result.(mcp.CallToolResult)
+	if !ok {
+		t.Fatalf("stream_tasks_by_plan returned %T", response.Result)
+	}
+	summary = models.TaskStreamResult{}
+	if err := json.Unmarshal([]byte(toolResultText(&streamed)), &summary); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if summary.Total != 5 || summary.Chunks != 3 || summary.Tasks != nil {
+		t.Errorf("unexpected streamed result: %+v", summary)
+	}
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if chunk.Offset != i*2 || chunk.Tasks[0].Order != i*2 || chunk.PlanID != plan.ID {
+			t.Errorf("unexpected chunk %d: %+v", i, chunk)
+		}
+	}
+	if len(chunks[2].Tasks) != 1 || chunks[2].Tasks[0].Title != "Task 4" {
+		t.Errorf("unexpected last chunk: %+v", chunks[2])
+	}
+}
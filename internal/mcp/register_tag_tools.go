@@ -0,0 +1,168 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// registerTagTools registers the task tagging tools with the MCP server
+func (s *MCPGoServer) registerTagTools() {
+	s.registerAddTaskTagsTool()
+	s.registerRemoveTaskTagsTool()
+	s.registerListTasksByTagTool()
+}
+
+func (s *MCPGoServer) registerAddTaskTagsTool() {
+	tool := mcp.NewTool("add_task_tags",
+		updateTool,
+		mcp.WithDescription("Add free-form tags (e.g. 'backend', 'needs-review') to a task"),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Task ID"),
+		),
+		mcp.WithArray("tags",
+			mcp.Required(),
+			mcp.Description("Tags to add. Tags are case-insensitive and stored in lowercase"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		tags, err := request.RequireStringSlice("tags")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		task, err := s.taskRepo.AddTags(ctx, id, tags)
+		if err != nil {
+			return s.toolError("Failed to add task tags", err), nil
+		}
+
+		taskJson, err := json.Marshal(task)
+		if err != nil {
+			return s.toolError("Failed to marshal task", err), nil
+		}
+		return mcp.NewToolResultText(string(taskJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerRemoveTaskTagsTool() {
+	tool := mcp.NewTool("remove_task_tags",
+		deleteTool,
+		mcp.WithDescription("Remove tags from a task"),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Task ID"),
+		),
+		mcp.WithArray("tags",
+			mcp.Required(),
+			mcp.Description("Tags to remove"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		tags, err := request.RequireStringSlice("tags")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		task, err := s.taskRepo.RemoveTags(ctx, id, tags)
+		if err != nil {
+			return s.toolError("Failed to remove task tags", err), nil
+		}
+
+		taskJson, err := json.Marshal(task)
+		if err != nil {
+			return s.toolError("Failed to marshal task", err), nil
+		}
+		return mcp.NewToolResultText(string(taskJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerListTasksByTagTool() {
+	tool := mcp.NewTool("list_tasks_by_tag",
+		readOnlyTool,
+		mcp.WithDescription("List all tasks carrying a tag, across all plans"),
+		mcp.WithString("tag",
+			mcp.Required(),
+			mcp.Description("Tag to filter tasks by"),
+		),
+		mcp.WithString("plan_id",
+			mcp.Description("Only return tasks from this plan (optional)"),
+		),
+		sortByOption(),
+		sortDirectionOption(),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		tag, err := request.RequireString("tag")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+		sort, err := taskSortArgument(request)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		tasks, err := s.taskRepo.ListByTag(ctx, tag)
+		if err != nil {
+			return s.toolError("Failed to list tasks by tag", err), nil
+		}
+
+		if planID := request.GetString("plan_id", ""); planID != "" {
+			filtered := make([]*models.Task, 0, len(tasks))
+			for _, task := range tasks {
+				if task.PlanID == planID {
+					filtered = append(filtered, task)
+				}
+			}
+			tasks = filtered
+		}
+		sort.Sort(tasks)
+
+		tasksJson, err := json.Marshal(tasks)
+		if err != nil {
+			return s.toolError("Failed to marshal tasks", err), nil
+		}
+		return mcp.NewToolResultText(string(tasksJson)), nil
+	})
+}
+
+// tagFilterOption adds the optional tags filter parameter to list tools
+func tagFilterOption() mcp.ToolOption {
+	return mcp.WithArray("tags",
+		mcp.Description("Only return tasks carrying all of these tags (optional)"),
+		mcp.Items(map[string]any{"type": "string"}),
+	)
+}
+
+// filterTasksByTags applies the optional tags filter argument to a list of tasks
+func filterTasksByTags(request mcp.CallToolRequest, tasks []*models.Task) []*models.Task {
+	tags := models.NormalizeTags(request.GetStringSlice("tags", nil))
+	if len(tags) == 0 {
+		return tasks
+	}
+
+	filtered := make([]*models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if task.HasAllTags(tags) {
+			filtered = append(filtered, task)
+		}
+	}
+	return filtered
+}
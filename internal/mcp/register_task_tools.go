@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 
@@ -17,17 +18,30 @@ func (s *MCPGoServer) registerTaskTools() {
 	s.registerCreateTaskTool()
 	s.registerGetTaskTool()
 	s.registerListTasksByPlanTool()
+	s.registerStreamTasksByPlanTool()
 	s.registerListTasksByStatusTool()
 	s.registerListTasksByPlanAndStatusTool()
+	s.registerListTasksByApplicationTool()
+	s.registerListTasksByApplicationAndStatusTool()
 	s.registerUpdateTaskTool()
 	s.registerDeleteTaskTool()
 	s.registerBulkCreateTasksTool()
+	s.registerExportTasksCSVTool()
+	s.registerImportTasksCSVTool()
+	s.registerExportTasksTaskwarriorTool()
+	s.registerImportTasksTaskwarriorTool()
+	s.registerExportTasksTodoTxtTool()
+	s.registerImportTasksTodoTxtTool()
 	s.registerReorderTaskTool()
+	s.registerReorderTasksTool()
+	s.registerMoveTaskTool()
 	s.registerListOrphanedTasksTool()
+	s.registerQueryTasksTool()
 }
 
 func (s *MCPGoServer) registerCreateTaskTool() {
 	tool := mcp.NewTool("create_task",
+		createTool,
 		mcp.WithDescription("Create a new task as part of a feature implementation plan"),
 		mcp.WithString("plan_id",
 			mcp.Required(),
@@ -38,33 +52,55 @@ func (s *MCPGoServer) registerCreateTaskTool() {
 			mcp.Description("Concise description of this implementation step"),
 		),
 		mcp.WithString("description",
-			mcp.Description("Detailed explanation of what needs to be done, acceptance criteria, or implementation notes"),
+			mcp.Description("Detailed explanation of what needs to be done, or implementation notes"),
 		),
 		mcp.WithString("status",
 			mcp.Description("Current implementation status of this task (optional, defaults to 'pending')"),
-			mcp.Enum("pending", "in_progress", "completed", "cancelled"),
+			mcp.Enum(models.TaskStatusNames()...),
 		),
 		mcp.WithString(
 			"priority",
 			mcp.Description(
 				"Importance and urgency of this task in the overall feature implementation plan (optional, defaults to 'medium')",
 			),
-			mcp.Enum("low", "medium", "high"),
+			mcp.Enum(models.TaskPriorityNames()...),
 		),
 		mcp.WithString("notes",
 			mcp.Description("Initial Markdown-formatted notes for the task (optional)"),
 		),
+		mcp.WithString("due_date",
+			mcp.Description("Due date as RFC3339 timestamp or YYYY-MM-DD (optional)"),
+		),
+		mcp.WithString("recurrence",
+			mcp.Description(recurrenceDescription+" (optional)"),
+		),
+		mcp.WithNumber("estimate",
+			mcp.Description(estimateDescription+" (optional)"),
+		),
+		mcp.WithArray("depends_on",
+			mcp.Description("IDs of tasks that must be completed before this task (optional)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithArray("tags",
+			mcp.Description("Free-form tags for the task (optional)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithArray("acceptance_criteria",
+			mcp.Description("Conditions the task must meet to be done, each checked off on its own (optional)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		withIdempotencyKey(),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		planID, err := request.RequireString("plan_id")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
 		title, err := request.RequireString("title")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
 		description := request.GetString("description", "no description provided")
@@ -73,9 +109,47 @@ func (s *MCPGoServer) registerCreateTaskTool() {
 		priorityStr := request.GetString("priority", string(models.TaskPriorityMedium))
 		priority := models.TaskPriority(priorityStr)
 
+		// Validate the schedule before creating anything
+		schedule := &models.Task{}
+		if err := applyTaskSchedule(request, schedule); err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		dependsOn, err := s.parseTaskDependencies(ctx, request, "")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		criteria, err := addAcceptanceCriteria(nil, "", request.GetStringSlice("acceptance_criteria", nil)...)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
 		task, err := s.taskRepo.Create(ctx, planID, title, description, priority)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create task: %v", err)), nil
+			return s.toolError("Failed to create task", err), nil
+		}
+
+		// Apply the due date, recurrence, estimate, dependencies and acceptance criteria if provided
+		if schedule.DueDate != nil || schedule.Recurrence != "" || schedule.Estimate > 0 || len(dependsOn) > 0 ||
+			len(criteria) > 0 {
+			task.DueDate = schedule.DueDate
+			task.Recurrence = schedule.Recurrence
+			task.Estimate = schedule.Estimate
+			task.DependsOn = dependsOn
+			task.AcceptanceCriteria = criteria
+			err = s.taskRepo.Update(ctx, task)
+			if err != nil {
+				return s.toolError("Failed to set task schedule", err), nil
+			}
+		}
+
+		// Apply the tags if provided
+		if tags := models.NormalizeTags(request.GetStringSlice("tags", nil)); len(tags) > 0 {
+			task, err = s.taskRepo.AddTags(ctx, task.ID, tags)
+			if err != nil {
+				return s.toolError("Failed to set task tags", err), nil
+			}
 		}
 
 		// If notes were provided, validate, format and update them
@@ -83,7 +157,7 @@ func (s *MCPGoServer) registerCreateTaskTool() {
 			// Validate and format the markdown content
 			err = markdown.Validate(notes)
 			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Invalid notes format: %v", err)), nil
+				return s.errorResult("Invalid notes format", err, models.ErrorCodeValidation), nil
 			}
 
 			// Sanitize and format the notes
@@ -92,19 +166,19 @@ func (s *MCPGoServer) registerCreateTaskTool() {
 
 			err = s.taskRepo.UpdateNotes(ctx, task.ID, notes)
 			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Failed to set initial notes: %v", err)), nil
+				return s.toolError("Failed to set initial notes", err), nil
 			}
 
 			// Refresh task to include notes
 			task, err = s.taskRepo.Get(ctx, task.ID)
 			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Failed to refresh task: %v", err)), nil
+				return s.toolError("Failed to refresh task", err), nil
 			}
 		}
 
 		taskJson, err := json.Marshal(task)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal task: %v", err)), nil
+			return s.toolError("Failed to marshal task", err), nil
 		}
 		return mcp.NewToolResultText(string(taskJson)), nil
 	})
@@ -112,6 +186,7 @@ func (s *MCPGoServer) registerCreateTaskTool() {
 
 func (s *MCPGoServer) registerGetTaskTool() {
 	tool := mcp.NewTool("get_task",
+		readOnlyTool,
 		mcp.WithDescription("Retrieve details about a specific planned task"),
 		mcp.WithString("id",
 			mcp.Required(),
@@ -119,20 +194,20 @@ func (s *MCPGoServer) registerGetTaskTool() {
 		),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		id, err := request.RequireString("id")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
 		task, err := s.taskRepo.Get(ctx, id)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get task: %v", err)), nil
+			return s.toolError("Failed to get task", err), nil
 		}
 
 		taskJson, err := json.Marshal(task)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal task: %v", err)), nil
+			return s.toolError("Failed to marshal task", err), nil
 		}
 		return mcp.NewToolResultText(string(taskJson)), nil
 	})
@@ -140,27 +215,38 @@ func (s *MCPGoServer) registerGetTaskTool() {
 
 func (s *MCPGoServer) registerListTasksByPlanTool() {
 	tool := mcp.NewTool("list_tasks_by_plan",
+		readOnlyTool,
 		mcp.WithDescription("List all tasks in a feature implementation plan"),
 		mcp.WithString("plan_id",
 			mcp.Required(),
 			mcp.Description("Plan ID to filter tasks by"),
 		),
+		tagFilterOption(),
+		sortByOption(),
+		sortDirectionOption(),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		planID, err := request.RequireString("plan_id")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
+		}
+
+		sort, err := taskSortArgument(request)
+		if err != nil {
+			return s.invalidArgument(err), nil
 		}
 
 		tasks, err := s.taskRepo.ListByPlan(ctx, planID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list tasks by plan: %v", err)), nil
+			return s.toolError("Failed to list tasks by plan", err), nil
 		}
+		tasks = filterTasksByTags(request, tasks)
+		sort.Sort(tasks)
 
 		tasksJson, err := json.Marshal(tasks)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal tasks: %v", err)), nil
+			return s.toolError("Failed to marshal tasks", err), nil
 		}
 
 		return mcp.NewToolResultText(string(tasksJson)), nil
@@ -169,29 +255,40 @@ func (s *MCPGoServer) registerListTasksByPlanTool() {
 
 func (s *MCPGoServer) registerListTasksByStatusTool() {
 	tool := mcp.NewTool("list_tasks_by_status",
+		readOnlyTool,
 		mcp.WithDescription("Find tasks by their current status (pending, in progress, completed, cancelled)"),
 		mcp.WithString("status",
 			mcp.Required(),
 			mcp.Description("Task status to filter by"),
-			mcp.Enum("pending", "in_progress", "completed", "cancelled"),
+			mcp.Enum(models.TaskStatusNames()...),
 		),
+		tagFilterOption(),
+		sortByOption(),
+		sortDirectionOption(),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		statusStr, err := request.RequireString("status")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
 		status := models.TaskStatus(statusStr)
+		sort, err := taskSortArgument(request)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
 		tasks, err := s.taskRepo.ListByStatus(ctx, status)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list tasks by status: %v", err)), nil
+			return s.toolError("Failed to list tasks by status", err), nil
 		}
+		tasks = filterTasksByTags(request, tasks)
+		sort.Sort(tasks)
 
 		tasksJson, err := json.Marshal(tasks)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal tasks: %v", err)), nil
+			return s.toolError("Failed to marshal tasks", err), nil
 		}
 		return mcp.NewToolResultText(string(tasksJson)), nil
 	})
@@ -199,6 +296,7 @@ func (s *MCPGoServer) registerListTasksByStatusTool() {
 
 func (s *MCPGoServer) registerUpdateTaskTool() {
 	tool := mcp.NewTool("update_task",
+		updateTool,
 		mcp.WithDescription("Update the details, status, or priority of a planned task"),
 		mcp.WithString("id",
 			mcp.Required(),
@@ -212,27 +310,44 @@ func (s *MCPGoServer) registerUpdateTaskTool() {
 		),
 		mcp.WithString("status",
 			mcp.Description("New task status (optional)"),
-			mcp.Enum("pending", "in_progress", "completed", "cancelled"),
+			mcp.Enum(models.TaskStatusNames()...),
 		),
 		mcp.WithString("priority",
 			mcp.Description("New task priority (optional)"),
-			mcp.Enum("low", "medium", "high"),
+			mcp.Enum(models.TaskPriorityNames()...),
 		),
 		mcp.WithString("notes",
 			mcp.Description("New Markdown-formatted notes (optional)"),
 		),
+		mcp.WithString("completion_note",
+			mcp.Description("Summary of what was done, recorded in the plan when the task is completed; "+
+				"required to complete a task when the server requires completion notes (optional)"),
+		),
+		mcp.WithString("due_date",
+			mcp.Description("New due date as RFC3339 timestamp or YYYY-MM-DD, or 'none' to clear it (optional)"),
+		),
+		mcp.WithString("recurrence",
+			mcp.Description(recurrenceDescription+", or 'none' to clear it (optional)"),
+		),
+		mcp.WithNumber("estimate",
+			mcp.Description(estimateDescription+", or 0 to clear it (optional)"),
+		),
+		mcp.WithArray("depends_on",
+			mcp.Description("New list of IDs of tasks that must be completed first; an empty list clears it (optional)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		id, err := request.RequireString("id")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
 		// Get the existing task
 		task, err := s.taskRepo.Get(ctx, id)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get task: %v", err)), nil
+			return s.toolError("Failed to get task", err), nil
 		}
 
 		// Update fields if provided
@@ -251,6 +366,20 @@ func (s *MCPGoServer) registerUpdateTaskTool() {
 
 		priorityStr := request.GetString("priority", string(task.Priority))
 		task.Priority = models.TaskPriority(priorityStr)
+		task.CompletionNote = request.GetString("completion_note", task.CompletionNote)
+
+		// Update the due date, recurrence and estimate if provided
+		if err := applyTaskSchedule(request, task); err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		// Update the dependencies if provided
+		if _, ok := request.GetArguments()["depends_on"]; ok {
+			task.DependsOn, err = s.parseTaskDependencies(ctx, request, task.ID)
+			if err != nil {
+				return s.invalidArgument(err), nil
+			}
+		}
 
 		// Check if notes are provided
 		notes := request.GetString("notes", "")
@@ -258,7 +387,7 @@ func (s *MCPGoServer) registerUpdateTaskTool() {
 			// Validate and format the markdown content
 			err = markdown.Validate(notes)
 			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Invalid notes format: %v", err)), nil
+				return s.errorResult("Invalid notes format", err, models.ErrorCodeValidation), nil
 			}
 
 			// Sanitize and format the notes
@@ -268,7 +397,7 @@ func (s *MCPGoServer) registerUpdateTaskTool() {
 			// Update notes separately using the dedicated method
 			err = s.taskRepo.UpdateNotes(ctx, id, notes)
 			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Failed to update notes: %v", err)), nil
+				return s.toolError("Failed to update notes", err), nil
 			}
 			// Update task.Notes for the response
 			task.Notes = notes
@@ -277,12 +406,12 @@ func (s *MCPGoServer) registerUpdateTaskTool() {
 		// Save the updated task
 		err = s.taskRepo.Update(ctx, task)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to update task: %v", err)), nil
+			return s.toolError("Failed to update task", err), nil
 		}
 
 		taskJson, err := json.Marshal(task)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal task: %v", err)), nil
+			return s.toolError("Failed to marshal task", err), nil
 		}
 		return mcp.NewToolResultText(string(taskJson)), nil
 	})
@@ -290,6 +419,7 @@ func (s *MCPGoServer) registerUpdateTaskTool() {
 
 func (s *MCPGoServer) registerDeleteTaskTool() {
 	tool := mcp.NewTool("delete_task",
+		deleteTool,
 		mcp.WithDescription("Remove a task from a feature implementation plan"),
 		mcp.WithString("id",
 			mcp.Required(),
@@ -297,15 +427,26 @@ func (s *MCPGoServer) registerDeleteTaskTool() {
 		),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		id, err := request.RequireString("id")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
+		}
+
+		// The user is asked with the title, so they can tell which task goes
+		if s.confirmDestructive.Load() {
+			task, err := s.taskRepo.Get(ctx, id)
+			if err != nil {
+				return s.toolError("Failed to delete task", err), nil
+			}
+			if err := s.confirm(ctx, models.EntityTask, id, "Delete task %q (%s)?", task.Title, id); err != nil {
+				return s.toolError("Failed to delete task", err), nil
+			}
 		}
 
 		err = s.taskRepo.Delete(ctx, id)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete task: %v", err)), nil
+			return s.toolError("Failed to delete task", err), nil
 		}
 
 		return mcp.NewToolResultText("Task deleted"), nil
@@ -314,123 +455,88 @@ func (s *MCPGoServer) registerDeleteTaskTool() {
 
 func (s *MCPGoServer) registerBulkCreateTasksTool() {
 	tool := mcp.NewTool("bulk_create_tasks",
+		createTool,
 		mcp.WithDescription("Create multiple tasks at once for a feature implementation plan"),
 		mcp.WithString("plan_id",
 			mcp.Required(),
 			mcp.Description("Plan ID these tasks belong to"),
 		),
+		mcp.WithArray("tasks",
+			mcp.Description(
+				"Array of task definitions, each containing title (required), description (optional), status (optional), "+
+					"priority (optional), and estimate (optional). Either tasks or tasks_json is required.",
+			),
+			mcp.Items(bulkTaskSchema()),
+		),
 		mcp.WithString(
 			"tasks_json",
-			mcp.Required(),
 			mcp.Description(
-				"JSON string containing an array of task definitions, each containing title (required), description (optional), status (optional), and priority (optional)",
+				"The task definitions as a JSON encoded string, for clients that cannot pass arrays. "+
+					"Prefer tasks, which avoids escaping the JSON.",
+			),
+			withJSONContent(bulkTasksSchema()),
+		),
+		mcp.WithString("dedup",
+			mcp.Description(
+				"How to handle tasks whose titles match tasks already in the plan: none creates them anyway (default), "+
+					"skip leaves them out, merge adds their description and higher priority to the existing task. "+
+					"When set to skip or merge, the result is a report of created, skipped and merged tasks.",
+			),
+			mcp.Enum(string(storage.DedupModeNone), string(storage.DedupModeSkip), string(storage.DedupModeMerge)),
+		),
+		mcp.WithString("match",
+			mcp.Description(
+				"How titles are compared when deduplicating: normalized ignores case, punctuation and extra whitespace (default), "+
+					"exact requires identical titles",
 			),
+			mcp.Enum(string(storage.TitleMatchNormalized), string(storage.TitleMatchExact)),
 		),
+		withIdempotencyKey(),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		planID, err := request.RequireString("plan_id")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
-		// Extract tasks JSON string
-		tasksJSON, err := request.RequireString("tasks_json")
+		definitions, err := bulkTaskDefinitions(request)
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
-		// Unmarshal into a slice of maps
-		var tasksArray []map[string]interface{}
-		err = json.Unmarshal([]byte(tasksJSON), &tasksArray)
+		taskInputs, err := parseBulkTasks(definitions)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to parse tasks JSON: %v", err)), nil
+			return s.invalidArgument(err), nil
 		}
 
-		// Convert tasks array to TaskCreateInput slice
-		taskInputs := make([]storage.TaskCreateInput, 0, len(tasksArray))
-		for _, taskMap := range tasksArray {
-			// Extract title (required)
-			titleRaw, ok := taskMap["title"]
-			if !ok {
-				return mcp.NewToolResultError("Task title is required"), nil
-			}
-
-			title, ok := titleRaw.(string)
-			if !ok || title == "" {
-				return mcp.NewToolResultError("Task title must be a non-empty string"), nil
-			}
-
-			// Extract optional fields
-			description := ""
-			if descRaw, ok := taskMap["description"]; ok {
-				if desc, ok := descRaw.(string); ok {
-					description = desc
-				}
-			}
-
-			statusStr := ""
-			if statusRaw, ok := taskMap["status"]; ok {
-				if status, ok := statusRaw.(string); ok {
-					statusStr = status
-				}
-			}
-
-			priorityStr := ""
-			if priorityRaw, ok := taskMap["priority"]; ok {
-				if priority, ok := priorityRaw.(string); ok {
-					priorityStr = priority
-				}
-			}
-
-			// Validate status if provided
-			if statusStr != "" {
-				validStatus := false
-				for _, s := range []string{"pending", "in_progress", "completed", "cancelled"} {
-					if statusStr == s {
-						validStatus = true
-						break
-					}
-				}
-				if !validStatus {
-					return mcp.NewToolResultError(fmt.Sprintf("Invalid status: %s", statusStr)), nil
-				}
-			}
-
-			// Validate priority if provided
-			if priorityStr != "" {
-				validPriority := false
-				for _, p := range []string{"low", "medium", "high"} {
-					if priorityStr == p {
-						validPriority = true
-						break
-					}
-				}
-				if !validPriority {
-					return mcp.NewToolResultError(fmt.Sprintf("Invalid priority: %s", priorityStr)), nil
-				}
+		opts := storage.BulkCreateOptions{
+			Dedup: storage.DedupMode(request.GetString("dedup", string(storage.DedupModeNone))),
+			Match: storage.TitleMatch(request.GetString("match", string(storage.TitleMatchNormalized))),
+		}
+		if opts.Dedup != storage.DedupModeNone {
+			report, err := s.taskRepo.CreateBulkWithOptions(ctx, planID, taskInputs, opts)
+			if err != nil {
+				return s.toolError("Failed to create tasks", err), nil
 			}
 
-			// Create task input
-			taskInput := storage.TaskCreateInput{
-				Title:       title,
-				Description: description,
-				Status:      models.TaskStatus(statusStr),
-				Priority:    models.TaskPriority(priorityStr),
+			reportJson, err := json.Marshal(report)
+			if err != nil {
+				return s.toolError("Failed to marshal report", err), nil
 			}
-			taskInputs = append(taskInputs, taskInput)
+			return mcp.NewToolResultText(string(reportJson)), nil
 		}
 
 		// Create tasks in bulk
 		createdTasks, err := s.taskRepo.CreateBulk(ctx, planID, taskInputs)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to create tasks: %v", err)), nil
+			return s.toolError("Failed to create tasks", err), nil
 		}
 
 		// Return created tasks
 		tasksJson, err := json.Marshal(createdTasks)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal tasks: %v", err)), nil
+			return s.toolError("Failed to marshal tasks", err), nil
 		}
 		return mcp.NewToolResultText(string(tasksJson)), nil
 	})
@@ -438,6 +544,7 @@ func (s *MCPGoServer) registerBulkCreateTasksTool() {
 
 func (s *MCPGoServer) registerReorderTaskTool() {
 	tool := mcp.NewTool("reorder_task",
+		updateTool,
 		mcp.WithDescription("Change the sequence of tasks in a feature implementation plan"),
 		mcp.WithString("id",
 			mcp.Required(),
@@ -449,32 +556,114 @@ func (s *MCPGoServer) registerReorderTaskTool() {
 		),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		id, err := request.RequireString("id")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 
 		newOrderFloat, err := request.RequireFloat("new_order")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return s.invalidArgument(err), nil
 		}
 		newOrder := int(newOrderFloat)
 
 		err = s.taskRepo.ReorderTask(ctx, id, newOrder)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to reorder task: %v", err)), nil
+			return s.toolError("Failed to reorder task", err), nil
 		}
 
 		// Get the updated task
 		task, err := s.taskRepo.Get(ctx, id)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get updated task: %v", err)), nil
+			return s.toolError("Failed to get updated task", err), nil
+		}
+
+		taskJson, err := json.Marshal(task)
+		if err != nil {
+			return s.toolError("Failed to marshal task", err), nil
+		}
+		return mcp.NewToolResultText(string(taskJson)), nil
+	})
+}
+
+// registerReorderTasksTool registers a tool to put all tasks of a plan in a new order at once
+func (s *MCPGoServer) registerReorderTasksTool() {
+	tool := mcp.NewTool("reorder_tasks",
+		updateTool,
+		mcp.WithDescription("Put all tasks of a feature implementation plan in a new order in a single call"),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		mcp.WithArray("task_ids",
+			mcp.Required(),
+			mcp.Description("IDs of all tasks of the plan, in their new order. Every task of the plan has to be listed once"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		taskIDs, err := request.RequireStringSlice("task_ids")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		tasks, err := s.taskRepo.ReorderTasks(ctx, planID, taskIDs)
+		if err != nil {
+			return s.toolError("Failed to reorder tasks", err), nil
+		}
+
+		tasksJson, err := json.Marshal(tasks)
+		if err != nil {
+			return s.toolError("Failed to marshal tasks", err), nil
+		}
+		return mcp.NewToolResultText(string(tasksJson)), nil
+	})
+}
+
+// registerMoveTaskTool registers a tool to move a task to another plan
+func (s *MCPGoServer) registerMoveTaskTool() {
+	tool := mcp.NewTool("move_task",
+		updateTool,
+		mcp.WithDescription("Move a task to another feature implementation plan, keeping its notes, tags and checklist"),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Task ID"),
+		),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("ID of the plan to move the task to"),
+		),
+		mcp.WithNumber("position",
+			mcp.Description("Position of the task in the target plan, starting at 0 (optional, defaults to the end of the plan)"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		task, err := s.taskRepo.MoveTask(ctx, id, planID, request.GetInt("position", -1))
+		if err != nil {
+			return s.toolError("Failed to move task", err), nil
 		}
 
 		taskJson, err := json.Marshal(task)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal task: %v", err)), nil
+			return s.toolError("Failed to marshal task", err), nil
 		}
 		return mcp.NewToolResultText(string(taskJson)), nil
 	})
@@ -483,6 +672,7 @@ func (s *MCPGoServer) registerReorderTaskTool() {
 // registerListTasksByPlanAndStatusTool registers a tool to list tasks by both plan ID and status
 func (s *MCPGoServer) registerListTasksByPlanAndStatusTool() {
 	tool := mcp.NewTool("list_tasks_by_plan_and_status",
+		readOnlyTool,
 		mcp.WithDescription("Find tasks by both plan ID and status (pending, in progress, completed, cancelled)"),
 		mcp.WithString("plan_id",
 			mcp.Required(),
@@ -491,11 +681,14 @@ func (s *MCPGoServer) registerListTasksByPlanAndStatusTool() {
 		mcp.WithString("status",
 			mcp.Required(),
 			mcp.Description("Task status to filter by"),
-			mcp.Enum("pending", "in_progress", "completed", "cancelled"),
+			mcp.Enum(models.TaskStatusNames()...),
 		),
+		tagFilterOption(),
+		sortByOption(),
+		sortDirectionOption(),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract parameters
 		planID, err := request.RequireString("plan_id")
 		if err != nil {
@@ -510,15 +703,115 @@ func (s *MCPGoServer) registerListTasksByPlanAndStatusTool() {
 		// Convert status string to TaskStatus
 		status := models.TaskStatus(statusStr)
 
+		sort, err := taskSortArgument(request)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
 		// Get tasks by plan ID and status
 		tasks, err := s.taskRepo.ListByPlanAndStatus(ctx, planID, status)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list tasks by plan and status: %v", err)), nil
+			return s.toolError("Failed to list tasks by plan and status", err), nil
 		}
+		tasks = filterTasksByTags(request, tasks)
+		sort.Sort(tasks)
 
 		tasksJson, err := json.Marshal(tasks)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal tasks: %v", err)), nil
+			return s.toolError("Failed to marshal tasks", err), nil
+		}
+		return mcp.NewToolResultText(string(tasksJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerListTasksByApplicationTool() {
+	tool := mcp.NewTool("list_tasks_by_application",
+		readOnlyTool,
+		mcp.WithDescription(
+			"List the tasks of all plans of an application, plan by plan in the order of the plans",
+		),
+		mcp.WithString("application_id",
+			mcp.Required(),
+			mcp.Description("Application ID to list the tasks of"),
+		),
+		tagFilterOption(),
+		sortByOption(),
+		sortDirectionOption(),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		applicationID, err := request.RequireString("application_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		sort, err := taskSortArgument(request)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		tasks, err := s.taskRepo.ListByApplication(ctx, applicationID)
+		if err != nil {
+			return s.toolError("Failed to list tasks by application", err), nil
+		}
+		tasks = filterTasksByTags(request, tasks)
+		sort.Sort(tasks)
+
+		tasksJson, err := json.Marshal(tasks)
+		if err != nil {
+			return s.toolError("Failed to marshal tasks", err), nil
+		}
+		return mcp.NewToolResultText(string(tasksJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerListTasksByApplicationAndStatusTool() {
+	tool := mcp.NewTool("list_tasks_by_application_and_status",
+		readOnlyTool,
+		mcp.WithDescription(
+			"Find the tasks with a status (pending, in progress, completed, cancelled) across all plans of an "+
+				"application, such as all work in progress on a product",
+		),
+		mcp.WithString("application_id",
+			mcp.Required(),
+			mcp.Description("Application ID to list the tasks of"),
+		),
+		mcp.WithString("status",
+			mcp.Required(),
+			mcp.Description("Task status to filter by"),
+			mcp.Enum(models.TaskStatusNames()...),
+		),
+		tagFilterOption(),
+		sortByOption(),
+		sortDirectionOption(),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		applicationID, err := request.RequireString("application_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		statusStr, err := request.RequireString("status")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		sort, err := taskSortArgument(request)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		tasks, err := s.taskRepo.ListByApplicationAndStatus(ctx, applicationID, models.TaskStatus(statusStr))
+		if err != nil {
+			return s.toolError("Failed to list tasks by application and status", err), nil
+		}
+		tasks = filterTasksByTags(request, tasks)
+		sort.Sort(tasks)
+
+		tasksJson, err := json.Marshal(tasks)
+		if err != nil {
+			return s.toolError("Failed to marshal tasks", err), nil
 		}
 		return mcp.NewToolResultText(string(tasksJson)), nil
 	})
@@ -527,22 +820,476 @@ func (s *MCPGoServer) registerListTasksByPlanAndStatusTool() {
 // registerListOrphanedTasksTool registers a tool to list tasks that reference non-existent plans
 func (s *MCPGoServer) registerListOrphanedTasksTool() {
 	tool := mcp.NewTool("list_orphaned_tasks",
+		readOnlyTool,
 		mcp.WithDescription("List all tasks that reference non-existent plans"),
 	)
 
-	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Get orphaned tasks
 		tasks, err := s.taskRepo.ListOrphanedTasks(ctx)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list orphaned tasks: %v", err)), nil
+			return s.toolError("Failed to list orphaned tasks", err), nil
 		}
 
 		// Marshal tasks to JSON
 		tasksJson, err := json.Marshal(tasks)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal tasks: %v", err)), nil
+			return s.toolError("Failed to marshal tasks", err), nil
+		}
+
+		return mcp.NewToolResultText(string(tasksJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerQueryTasksTool() {
+	tool := mcp.NewTool("query_tasks",
+		readOnlyTool,
+		mcp.WithDescription(
+			"Find tasks matching a filter expression, evaluated on the server in one call instead of combining "+
+				"several list tools. Conditions are joined by AND, such as "+
+				"'status in (pending, in_progress) AND priority = high AND updated_after = 2025-01-01'. "+
+				"status, priority, plan_id, application_id and tag take =, !=, in and not in; title and description "+
+				"take ~ for a case-insensitive substring; created_after, created_before, updated_after, "+
+				"updated_before, due_after and due_before take = with an RFC3339 timestamp or YYYY-MM-DD date. "+
+				"Quote values with spaces. Tasks are ordered by plan and by their order within the plan unless sorted",
+		),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("Filter expression"),
+		),
+		sortByOption(),
+		sortDirectionOption(),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		expression, err := request.RequireString("query")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+		query, err := models.ParseTaskQuery(expression)
+		if err != nil {
+			return s.invalidArgument(err), nil
 		}
+		sort, err := taskSortArgument(request)
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		tasks, err := s.taskRepo.Query(ctx, query)
+		if err != nil {
+			return s.toolError("Failed to query tasks", err), nil
+		}
+		sort.Sort(tasks)
 
+		tasksJson, err := json.Marshal(tasks)
+		if err != nil {
+			return s.toolError("Failed to marshal tasks", err), nil
+		}
 		return mcp.NewToolResultText(string(tasksJson)), nil
 	})
 }
+
+// sortByOption adds the optional sort field parameter to list tools
+func sortByOption() mcp.ToolOption {
+	values := make([]string, len(models.TaskSortFields))
+	for i, field := range models.TaskSortFields {
+		values[i] = string(field)
+	}
+	return mcp.WithString("sort_by",
+		mcp.Description("Field to sort the tasks by (optional, defaults to their order in the plan). "+
+			"Tasks without a due date come last when sorting by due_date"),
+		mcp.Enum(values...),
+	)
+}
+
+// sortDirectionOption adds the optional sort direction parameter to list tools
+func sortDirectionOption() mcp.ToolOption {
+	return mcp.WithString("sort_direction",
+		mcp.Description("Sort direction (optional, defaults to desc for priority and updated_at and asc otherwise)"),
+		mcp.Enum(string(models.SortAscending), string(models.SortDescending)),
+	)
+}
+
+// taskSortArgument reads the optional sort_by and sort_direction arguments of list tools. Without them tasks
+// keep their order.
+func taskSortArgument(request mcp.CallToolRequest) (models.TaskSort, error) {
+	field := request.GetString("sort_by", "")
+	direction := request.GetString("sort_direction", "")
+	if field == "" && direction == "" {
+		return models.TaskSort{}, nil
+	}
+	return models.NewTaskSort(field, direction)
+}
+
+// recurrenceDescription documents the accepted recurrence rule formats
+const recurrenceDescription = "Recurrence rule, either 'hourly', 'daily', 'weekly', 'monthly', 'yearly' " +
+	"or an RRULE-like 'FREQ=WEEKLY;INTERVAL=2'. Completing a recurring task creates its next occurrence"
+
+// estimateDescription documents the estimate argument of tasks
+const estimateDescription = "Estimated size of the task in the unit the plan is estimated in, such as story points or minutes"
+
+// applyTaskSchedule applies the optional due_date, recurrence and estimate arguments to a task.
+// The value 'none' clears the due date or recurrence, and an estimate of 0 clears the estimate.
+func applyTaskSchedule(request mcp.CallToolRequest, task *models.Task) error {
+	if _, ok := request.GetArguments()["estimate"]; ok {
+		estimate := request.GetFloat("estimate", 0)
+		if err := models.ValidateEstimate(estimate); err != nil {
+			return err
+		}
+		task.Estimate = estimate
+	}
+
+	if dueDateStr := request.GetString("due_date", ""); dueDateStr != "" {
+		if dueDateStr == "none" {
+			task.DueDate = nil
+		} else {
+			dueDate, err := models.ParseDueDate(dueDateStr)
+			if err != nil {
+				return err
+			}
+			task.DueDate = &dueDate
+		}
+	}
+
+	if rule := request.GetString("recurrence", ""); rule != "" {
+		if rule == "none" {
+			task.Recurrence = ""
+		} else {
+			recurrence, err := models.ParseRecurrence(rule)
+			if err != nil {
+				return err
+			}
+			task.Recurrence = recurrence.String()
+		}
+	}
+
+	return nil
+}
+
+// parseTaskDependencies reads the optional depends_on argument and verifies that every referenced task exists.
+// taskID is the ID of the task being updated, or empty when creating a new task.
+func (s *MCPGoServer) parseTaskDependencies(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+	taskID string,
+) ([]string, error) {
+	dependsOn := request.GetStringSlice("depends_on", nil)
+	if len(dependsOn) == 0 {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool, len(dependsOn))
+	dependencies := make([]string, 0, len(dependsOn))
+	for _, dependencyID := range dependsOn {
+		if dependencyID == "" || seen[dependencyID] {
+			continue
+		}
+		if dependencyID == taskID {
+			return nil, fmt.Errorf("task cannot depend on itself")
+		}
+		if _, err := s.taskRepo.Get(ctx, dependencyID); err != nil {
+			return nil, fmt.Errorf("invalid dependency: %w", err)
+		}
+		seen[dependencyID] = true
+		dependencies = append(dependencies, dependencyID)
+	}
+
+	return dependencies, nil
+}
+
+func (s *MCPGoServer) registerExportTasksCSVTool() {
+	tool := mcp.NewTool("export_tasks_csv",
+		readOnlyTool,
+		mcp.WithDescription(
+			"Export the tasks of a plan as CSV with title, description, status, priority and order columns, "+
+				"for use in spreadsheets and other project tools",
+		),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID whose tasks to export"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		tasks, err := s.taskRepo.ListByPlan(ctx, planID)
+		if err != nil {
+			return s.toolError("Failed to export tasks", err), nil
+		}
+
+		var buf strings.Builder
+		if err := storage.WriteTasksCSV(&buf, tasks); err != nil {
+			return s.toolError("Failed to export tasks", err), nil
+		}
+		return mcp.NewToolResultText(buf.String()), nil
+	})
+}
+
+func (s *MCPGoServer) registerImportTasksCSVTool() {
+	tool := mcp.NewTool("import_tasks_csv",
+		createTool,
+		mcp.WithDescription(
+			"Add tasks to the end of a plan from CSV with a header row. Recognized columns are title (required), "+
+				"description, status, priority and order; other columns are ignored. Rows are added in the order of the "+
+				"order column, or in file order if it is missing.",
+		),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID to add the tasks to"),
+		),
+		mcp.WithString("csv",
+			mcp.Required(),
+			mcp.Description("CSV content, for example as exported by export_tasks_csv"),
+		),
+		mcp.WithString("dedup",
+			mcp.Description(
+				"How to handle rows whose titles match tasks already in the plan, as for bulk_create_tasks: "+
+					"none (default), skip or merge. When set to skip or merge, the result is a report of created, "+
+					"skipped and merged tasks.",
+			),
+			mcp.Enum(string(storage.DedupModeNone), string(storage.DedupModeSkip), string(storage.DedupModeMerge)),
+		),
+		mcp.WithString("match",
+			mcp.Description("How titles are compared when deduplicating: normalized (default) or exact"),
+			mcp.Enum(string(storage.TitleMatchNormalized), string(storage.TitleMatchExact)),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		content, err := request.RequireString("csv")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		taskInputs, err := storage.ReadTasksCSV(strings.NewReader(content))
+		if err != nil {
+			return s.errorResult("Failed to parse CSV", err, models.ErrorCodeValidation), nil
+		}
+		if len(taskInputs) == 0 {
+			return s.validationError("CSV contains no tasks"), nil
+		}
+
+		return s.importTasks(ctx, request, planID, taskInputs), nil
+	})
+}
+
+// importTasks adds tasks read from an import to the end of a plan, deduplicating them as the dedup and match
+// arguments of the import call ask
+func (s *MCPGoServer) importTasks(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+	planID string,
+	taskInputs []storage.TaskCreateInput,
+) *mcp.CallToolResult {
+	opts := storage.BulkCreateOptions{
+		Dedup: storage.DedupMode(request.GetString("dedup", string(storage.DedupModeNone))),
+		Match: storage.TitleMatch(request.GetString("match", string(storage.TitleMatchNormalized))),
+	}
+	if opts.Dedup != storage.DedupModeNone {
+		report, err := s.taskRepo.CreateBulkWithOptions(ctx, planID, taskInputs, opts)
+		if err != nil {
+			return s.toolError("Failed to import tasks", err)
+		}
+
+		reportJson, err := json.Marshal(report)
+		if err != nil {
+			return s.toolError("Failed to marshal report", err)
+		}
+		return mcp.NewToolResultText(string(reportJson))
+	}
+
+	createdTasks, err := s.taskRepo.CreateBulk(ctx, planID, taskInputs)
+	if err != nil {
+		return s.toolError("Failed to import tasks", err)
+	}
+
+	tasksJson, err := json.Marshal(createdTasks)
+	if err != nil {
+		return s.toolError("Failed to marshal tasks", err)
+	}
+	return mcp.NewToolResultText(string(tasksJson))
+}
+
+func (s *MCPGoServer) registerExportTasksTaskwarriorTool() {
+	tool := mcp.NewTool("export_tasks_taskwarrior",
+		readOnlyTool,
+		mcp.WithDescription(
+			"Export the tasks of a plan as Taskwarrior JSON that `task import` accepts, in a project named after "+
+				"the plan. Tasks in progress are exported as started and cancelled tasks as deleted.",
+		),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID whose tasks to export"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		plan, err := s.planRepo.Get(ctx, planID)
+		if err != nil {
+			return s.toolError("Failed to export tasks", err), nil
+		}
+		tasks, err := s.taskRepo.ListByPlan(ctx, planID)
+		if err != nil {
+			return s.toolError("Failed to export tasks", err), nil
+		}
+
+		var buf strings.Builder
+		if err := storage.WriteTasksTaskwarrior(&buf, plan.Name, tasks); err != nil {
+			return s.toolError("Failed to export tasks", err), nil
+		}
+		return mcp.NewToolResultText(buf.String()), nil
+	})
+}
+
+func (s *MCPGoServer) registerImportTasksTaskwarriorTool() {
+	tool := mcp.NewTool("import_tasks_taskwarrior",
+		createTool,
+		mcp.WithDescription(
+			"Add tasks to the end of a plan from Taskwarrior JSON, as written by `task export`. The description of "+
+				"each task becomes its title and its annotations its description; status and priority are kept, "+
+				"started tasks are in progress and deleted tasks cancelled. Other fields are ignored.",
+		),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID to add the tasks to"),
+		),
+		mcp.WithString("json",
+			mcp.Required(),
+			mcp.Description("Taskwarrior JSON, either an array of tasks or one task object per line"),
+		),
+		mcp.WithString("dedup",
+			mcp.Description(
+				"How to handle imported tasks whose titles match tasks already in the plan, as for bulk_create_tasks: "+
+					"none (default), skip or merge. When set to skip or merge, the result is a report of created, "+
+					"skipped and merged tasks.",
+			),
+			mcp.Enum(string(storage.DedupModeNone), string(storage.DedupModeSkip), string(storage.DedupModeMerge)),
+		),
+		mcp.WithString("match",
+			mcp.Description("How titles are compared when deduplicating: normalized (default) or exact"),
+			mcp.Enum(string(storage.TitleMatchNormalized), string(storage.TitleMatchExact)),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		content, err := request.RequireString("json")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		taskInputs, err := storage.ReadTasksTaskwarrior(strings.NewReader(content))
+		if err != nil {
+			return s.errorResult("Failed to parse Taskwarrior JSON", err, models.ErrorCodeValidation), nil
+		}
+		if len(taskInputs) == 0 {
+			return s.validationError("Taskwarrior JSON contains no tasks"), nil
+		}
+
+		return s.importTasks(ctx, request, planID, taskInputs), nil
+	})
+}
+
+func (s *MCPGoServer) registerExportTasksTodoTxtTool() {
+	tool := mcp.NewTool("export_tasks_todotxt",
+		readOnlyTool,
+		mcp.WithDescription(
+			"Export the tasks of a plan as todo.txt lines with their priority, dates, tags as +project tags and "+
+				"due date. Tasks in progress and cancelled tasks carry a status: pair, since todo.txt only knows "+
+				"open and done tasks.",
+		),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID whose tasks to export"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		tasks, err := s.taskRepo.ListByPlan(ctx, planID)
+		if err != nil {
+			return s.toolError("Failed to export tasks", err), nil
+		}
+
+		var buf strings.Builder
+		if err := storage.WriteTasksTodoTxt(&buf, tasks); err != nil {
+			return s.toolError("Failed to export tasks", err), nil
+		}
+		return mcp.NewToolResultText(buf.String()), nil
+	})
+}
+
+func (s *MCPGoServer) registerImportTasksTodoTxtTool() {
+	tool := mcp.NewTool("import_tasks_todotxt",
+		createTool,
+		mcp.WithDescription(
+			"Add tasks to the end of a plan from todo.txt lines. The text of each line becomes the title without "+
+				"its +project and @context tags and key:value pairs; done tasks are completed and priority A is "+
+				"high, B medium and the others low.",
+		),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID to add the tasks to"),
+		),
+		mcp.WithString("todotxt",
+			mcp.Required(),
+			mcp.Description("todo.txt content, one task per line"),
+		),
+		mcp.WithString("dedup",
+			mcp.Description(
+				"How to handle imported tasks whose titles match tasks already in the plan, as for bulk_create_tasks: "+
+					"none (default), skip or merge. When set to skip or merge, the result is a report of created, "+
+					"skipped and merged tasks.",
+			),
+			mcp.Enum(string(storage.DedupModeNone), string(storage.DedupModeSkip), string(storage.DedupModeMerge)),
+		),
+		mcp.WithString("match",
+			mcp.Description("How titles are compared when deduplicating: normalized (default) or exact"),
+			mcp.Enum(string(storage.TitleMatchNormalized), string(storage.TitleMatchExact)),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		content, err := request.RequireString("todotxt")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		taskInputs, err := storage.ReadTasksTodoTxt(strings.NewReader(content))
+		if err != nil {
+			return s.errorResult("Failed to parse todo.txt", err, models.ErrorCodeValidation), nil
+		}
+		if len(taskInputs) == 0 {
+			return s.validationError("todo.txt contains no tasks"), nil
+		}
+
+		return s.importTasks(ctx, request, planID, taskInputs), nil
+	})
+}
@@ -0,0 +1,85 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// registerTimeTools registers the time tracking tools with the MCP server
+func (s *MCPGoServer) registerTimeTools() {
+	s.registerLogTimeTool()
+	s.registerGetPlanTimeReportTool()
+}
+
+func (s *MCPGoServer) registerLogTimeTool() {
+	tool := mcp.NewTool("log_time",
+		createTool,
+		mcp.WithDescription(
+			"Explicitly log time spent on a task. Time in progress is also tracked automatically "+
+				"when a task moves in and out of in_progress",
+		),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Task ID"),
+		),
+		mcp.WithNumber("minutes",
+			mcp.Required(),
+			mcp.Description("Number of minutes to add to the task's time spent"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		minutes, err := request.RequireFloat("minutes")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		task, err := s.taskRepo.LogTime(ctx, id, time.Duration(minutes*float64(time.Minute)))
+		if err != nil {
+			return s.toolError("Failed to log time", err), nil
+		}
+
+		taskJson, err := json.Marshal(task)
+		if err != nil {
+			return s.toolError("Failed to marshal task", err), nil
+		}
+		return mcp.NewToolResultText(string(taskJson)), nil
+	})
+}
+
+func (s *MCPGoServer) registerGetPlanTimeReportTool() {
+	tool := mcp.NewTool("get_plan_time_report",
+		readOnlyTool,
+		mcp.WithDescription("Summarize the time spent per task and for the whole plan, in seconds"),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := request.RequireString("id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		report, err := s.planStats.GetPlanTimeReport(ctx, id)
+		if err != nil {
+			return s.toolError("Failed to get plan time report", err), nil
+		}
+
+		reportJson, err := json.Marshal(report)
+		if err != nil {
+			return s.toolError("Failed to marshal time report", err), nil
+		}
+		return mcp.NewToolResultText(string(reportJson)), nil
+	})
+}
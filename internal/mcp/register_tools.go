@@ -5,9 +5,67 @@ func (s *MCPGoServer) registerTools() {
 	// Plan tools
 	s.registerPlanTools()
 
+	// Application tools
+	s.registerApplicationTools()
+
 	// Task tools
 	s.registerTaskTools()
 
+	// Batch change tools
+	s.registerPlanChangeTools()
+
 	// Notes tools
 	s.registerNotesTools()
+
+	// Agent activity tools
+	s.registerActivityTools()
+
+	// Lease tools
+	s.registerLeaseTools()
+
+	// Stale work tools
+	s.registerStaleWorkTools()
+
+	// Tag tools
+	s.registerTagTools()
+
+	// Metadata tools
+	s.registerMetadataTools()
+
+	// Lock tools
+	s.registerLockTools()
+
+	// Link tools
+	s.registerLinkTools()
+
+	// Watch tools
+	s.registerWatchTools()
+
+	// Time tracking tools
+	s.registerTimeTools()
+
+	// Checklist tools
+	s.registerChecklistTools()
+
+	// Acceptance criteria tools
+	s.registerAcceptanceCriteriaTools()
+
+	// Attachment tools
+	s.registerAttachmentTools()
+
+	// Schedule and milestone tools
+	s.registerMilestoneTools()
+
+	// History tools
+	s.registerHistoryTools()
+
+	// Admin tools
+	s.registerAdminTools()
+
+	// Session tools
+	s.registerSessionContextTools()
+
+	// Integration tools
+	s.registerGitHubTools()
+	s.registerJiraTools()
 }
@@ -0,0 +1,140 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// registerWatchTools registers the tools watching plans with the MCP server.
+// The tools are only available when a watcher store is configured.
+func (s *MCPGoServer) registerWatchTools() {
+	if s.watchers == nil {
+		return
+	}
+
+	s.registerWatchPlanTool()
+	s.registerUnwatchPlanTool()
+	s.registerListWatchersTool()
+}
+
+func (s *MCPGoServer) registerWatchPlanTool() {
+	tool := mcp.NewTool("watch_plan",
+		updateTool,
+		mcp.WithDescription(
+			"Watch a plan: the watcher is notified when the plan or one of its tasks changes, through the "+
+				"notification channels configured on the server. Changes made by the watcher itself are not notified",
+		),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		mcp.WithString("watcher",
+			mcp.Required(),
+			mcp.Description("Identifier of the agent or user to notify, such as a name or chat handle"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		watcher, err := request.RequireString("watcher")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		if _, err := s.planRepo.Get(ctx, planID); err != nil {
+			return s.toolError("Failed to get plan", err), nil
+		}
+
+		if _, err := s.watchers.Watch(ctx, planID, watcher); err != nil {
+			return s.toolError("Failed to watch plan", err), nil
+		}
+
+		return s.marshalWatchers(ctx, planID)
+	})
+}
+
+func (s *MCPGoServer) registerUnwatchPlanTool() {
+	tool := mcp.NewTool("unwatch_plan",
+		deleteTool,
+		mcp.WithDescription("Stop notifying a watcher about the changes of a plan"),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+		mcp.WithString("watcher",
+			mcp.Required(),
+			mcp.Description("Identifier of the watcher"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		watcher, err := request.RequireString("watcher")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		if _, err := s.planRepo.Get(ctx, planID); err != nil {
+			return s.toolError("Failed to get plan", err), nil
+		}
+
+		removed, err := s.watchers.Unwatch(ctx, planID, watcher)
+		if err != nil {
+			return s.toolError("Failed to unwatch plan", err), nil
+		}
+		if !removed {
+			return s.invalidArgument(models.NewNotFoundError(models.EntityWatcher, watcher)), nil
+		}
+
+		return s.marshalWatchers(ctx, planID)
+	})
+}
+
+func (s *MCPGoServer) registerListWatchersTool() {
+	tool := mcp.NewTool("list_watchers",
+		readOnlyTool,
+		mcp.WithDescription("List the watchers notified about the changes of a plan"),
+		mcp.WithString("plan_id",
+			mcp.Required(),
+			mcp.Description("Plan ID"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		planID, err := request.RequireString("plan_id")
+		if err != nil {
+			return s.invalidArgument(err), nil
+		}
+
+		if _, err := s.planRepo.Get(ctx, planID); err != nil {
+			return s.toolError("Failed to get plan", err), nil
+		}
+
+		return s.marshalWatchers(ctx, planID)
+	})
+}
+
+// marshalWatchers returns the watchers of a plan as a JSON tool result
+func (s *MCPGoServer) marshalWatchers(ctx context.Context, planID string) (*mcp.CallToolResult, error) {
+	watchers, err := s.watchers.Watchers(ctx, planID)
+	if err != nil {
+		return s.toolError("Failed to list watchers", err), nil
+	}
+
+	watchersJson, err := json.Marshal(models.PlanWatchers{PlanID: planID, Watchers: watchers})
+	if err != nil {
+		return s.toolError("Failed to marshal watchers", err), nil
+	}
+	return mcp.NewToolResultText(string(watchersJson)), nil
+}
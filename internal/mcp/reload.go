@@ -0,0 +1,41 @@
+package mcp
+
+import "log"
+
+// Reload applies the settings that can change while the server runs: the rate limits, the tool timeout,
+// read-only mode and the confirmation of deletions. Other settings, such as the transports, need a restart.
+// Open sessions are kept, and clients are notified when the list of tools changes. A server configured with
+// WithServerConfig keeps its configuration.
+func (s *MCPGoServer) Reload() {
+	if s.configured {
+		return
+	}
+	config := loadServerConfig()
+
+	// Clients start over with full buckets under the new limits
+	s.limiter.Store(newRequestLimiter(config))
+	s.setToolTimeout(config.ToolTimeout)
+	s.confirmDestructive.Store(config.ConfirmDestructiveTools)
+	s.setReadOnly(config.ReadOnly)
+}
+
+// setReadOnly turns read-only mode on or off, removing or adding the tools that change data
+func (s *MCPGoServer) setReadOnly(readOnly bool) {
+	if s.readOnly.Swap(readOnly) == readOnly {
+		return
+	}
+
+	if readOnly {
+		names := make([]string, len(s.writeTools))
+		for i, tool := range s.writeTools {
+			names[i] = tool.Tool.Name
+		}
+		s.server.DeleteTools(names...)
+	} else {
+		s.server.AddTools(s.writeTools...)
+	}
+	if restHandler := s.restHandler.Load(); restHandler != nil {
+		restHandler.SetReadOnly(readOnly)
+	}
+	log.Printf("Read-only mode changed to %t", readOnly)
+}
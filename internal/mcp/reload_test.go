@@ -0,0 +1,48 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestReloadReadOnlyMode(t *testing.T) {
+	s := newTestServer(t)
+	if _, ok := serverTools(t, s)["create_plan"]; !ok {
+		t.Fatal("create_plan should be registered")
+	}
+
+	t.Setenv("READ_ONLY_MODE", "true")
+	s.Reload()
+	tools := serverTools(t, s)
+	if _, ok := tools["create_plan"]; ok {
+		t.Error("create_plan should be removed in read-only mode")
+	}
+	if _, ok := tools["get_plan"]; !ok {
+		t.Error("get_plan should stay registered in read-only mode")
+	}
+
+	t.Setenv("READ_ONLY_MODE", "false")
+	s.Reload()
+	if _, ok := serverTools(t, s)["create_plan"]; !ok {
+		t.Error("create_plan should be registered again")
+	}
+}
+
+func TestReloadRateLimits(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	if err := s.limiter.Load().check(ctx, "get_plan", false); err != nil {
+		t.Fatalf("requests should not be limited by default, got %v", err)
+	}
+
+	t.Setenv("RATE_LIMIT_PER_SECOND", "0.001")
+	t.Setenv("RATE_LIMIT_BURST", "1")
+	s.Reload()
+	if err := s.limiter.Load().check(ctx, "get_plan", false); err != nil {
+		t.Fatalf("first request should be allowed, got %v", err)
+	}
+	if err := s.limiter.Load().check(ctx, "get_plan", false); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("second request should be throttled after the reload, got %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// DefaultResourceCacheEntries is the number of resources a cache keeps when no other bound is given
+const DefaultResourceCacheEntries = 1000
+
+// ResourceCache keeps the contents of expensive resource reads, such as the full plan views, in memory so
+// agents reading them over and over do not scan Valkey each time. Any recorded change empties the cache, and
+// entries expire after the TTL in case a change is missed. Entries are kept by URI and application scope.
+type ResourceCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]resourceCacheEntry
+	// generation counts the times the cache was emptied, so reads started before a change are not kept
+	generation uint64
+}
+
+// resourceCacheEntry is a cached resource read
+type resourceCacheEntry struct {
+	contents []mcp.ResourceContents
+	expires  time.Time
+}
+
+// NewResourceCache creates a cache keeping at most maxEntries resources for the TTL. A non-positive
+// maxEntries falls back to DefaultResourceCacheEntries.
+func NewResourceCache(ttl time.Duration, maxEntries int) *ResourceCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultResourceCacheEntries
+	}
+	return &ResourceCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]resourceCacheEntry),
+	}
+}
+
+// Clear empties the cache
+func (c *ResourceCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	clear(c.entries)
+}
+
+// Changed empties the cache when a change is recorded in the audit log
+func (c *ResourceCache) Changed(ctx context.Context, entry *models.AuditEntry) {
+	c.Clear()
+}
+
+// Len returns the number of cached resources, including expired ones not yet dropped
+func (c *ResourceCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// get returns the cached contents of a resource and the generation of the cache, which put needs
+func (c *ResourceCache) get(key string) ([]mcp.ResourceContents, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if ok && time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		ok = false
+	}
+	return entry.contents, c.generation, ok
+}
+
+// put caches the contents of a resource read in the given generation, unless the cache was emptied since.
+// A full cache is emptied first rather than tracking which entry is the oldest.
+func (c *ResourceCache) put(key string, generation uint64, contents []mcp.ResourceContents) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	if len(c.entries) >= c.maxEntries {
+		clear(c.entries)
+	}
+	c.entries[key] = resourceCacheEntry{contents: contents, expires: time.Now().Add(c.ttl)}
+}
+
+// cacheResourceHandler wraps the handler of an expensive resource template so its reads are served from the
+// resource cache. The handler has to see the application scope, as it is part of the key.
+func (s *MCPGoServer) cacheResourceHandler(
+	uriTemplate string,
+	next server.ResourceTemplateHandlerFunc,
+) server.ResourceTemplateHandlerFunc {
+	if s.resourceCache == nil || !isExpensiveResource(uriTemplate) {
+		return next
+	}
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		key := storage.ApplicationScopeFromContext(ctx) + "\x00" + request.Params.URI
+		contents, generation, ok := s.resourceCache.get(key)
+		if ok {
+			return contents, nil
+		}
+
+		contents, err := next(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		s.resourceCache.put(key, generation, contents)
+		return contents, nil
+	}
+}
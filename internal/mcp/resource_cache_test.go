@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// readResource reads a resource through the MCP server and returns its text
+func readResource(t *testing.T, s *MCPGoServer, uri string) string {
+	t.Helper()
+	message := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":%q}}`, uri)
+	response, ok := s.server.HandleMessage(context.Background(), []byte(message)).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("reading %s failed", uri)
+	}
+	result, ok := response.Result.(mcp.ReadResourceResult)
+	if !ok || len(result.Contents) == 0 {
+		t.Fatalf("reading %s returned %T", uri, response.Result)
+	}
+	return result.Contents[0].(mcp.TextResourceContents).Text
+}
+
+func TestResourceCache(t *testing.T) {
+	repos := newTestRepos(t)
+	auditLog := storage.NewAuditLog(repos.client, storage.AuditRetention{})
+	cache := NewResourceCache(time.Minute, 0)
+	auditLog.SetChangeListener(cache)
+	s := NewMCPGoServer(
+		storage.NewAuditedPlanRepository(repos.planRepo, auditLog),
+		storage.NewAuditedTaskRepository(repos.taskRepo, auditLog),
+		WithAuditLog(auditLog),
+		WithResourceCache(cache),
+	)
+	ctx := context.Background()
+
+	plan, err := s.planRepo.Create(ctx, "app", "One", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	if text := readResource(t, s, "ai-tasks://plans/full"); !strings.Contains(text, "One") {
+		t.Fatalf("expected the plan in the resource, got %s", text)
+	}
+	readResource(t, s, "ai-tasks://plans/"+plan.ID+"/notes")
+	if cache.Len() != 1 {
+		t.Errorf("expected only the full plans resource to be cached, got %d entries", cache.Len())
+	}
+
+	// A change that is not recorded is not seen until the cache is emptied
+	if _, err := repos.planRepo.Create(ctx, "app", "Two", ""); err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	if text := readResource(t, s, "ai-tasks://plans/full"); strings.Contains(text, "Two") {
+		t.Errorf("expected the cached resource, got %s", text)
+	}
+
+	// A recorded change empties the cache
+	if _, err := s.taskRepo.Create(ctx, plan.ID, "Task", "", models.TaskPriorityMedium); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("expected the change to empty the cache, got %d entries", cache.Len())
+	}
+	if text := readResource(t, s, "ai-tasks://plans/full"); !strings.Contains(text, "Two") || !strings.Contains(text, "Task") {
+		t.Errorf("expected the resource to be read again, got %s", text)
+	}
+
+	// Changes published by other replicas empty the cache too
+	changes := storage.NewChangeStream(repos.client, 0)
+	followCtx, stopFollowing := context.WithCancel(ctx)
+	followed := make(chan struct{})
+	go func() {
+		defer close(followed)
+		changes.Follow(followCtx, 5*time.Millisecond, cache.Clear)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	readResource(t, s, "ai-tasks://plans/full")
+	changes.Changed(ctx, &models.AuditEntry{
+		EntityType: models.EntityTypePlan,
+		EntityID:   plan.ID,
+		Action:     models.AuditActionUpdate,
+	})
+	deadline := time.Now().Add(time.Second)
+	for cache.Len() != 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	stopFollowing()
+	<-followed
+	if cache.Len() != 0 {
+		t.Errorf("expected a published change to empty the cache, got %d entries", cache.Len())
+	}
+}
@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// Resources returning many plans or tasks can be read a page at a time with the cursor and limit query
+// parameters of their URI templates. A cursor holds the sort key of the last item of the previous page, so
+// plans created or deleted while a client pages through them do not shift the pages.
+
+const (
+	// defaultResourcePageSize is the page size of a resource read with a cursor but no limit
+	defaultResourcePageSize = 20
+	// resourceEnvelopeSize is the room left for the fields around the items of a summarized resource
+	resourceEnvelopeSize = 1024
+)
+
+// resourcePage is the page of a resource asked for by the query of its URI
+type resourcePage struct {
+	// cursor is the sort key of the last item of the previous page, empty for the first page
+	cursor string
+	// limit is the number of items of the page, 0 for all items
+	limit int
+}
+
+// paged reports whether the URI asked for a page rather than the whole resource
+func (p resourcePage) paged() bool {
+	return p.cursor != "" || p.limit > 0
+}
+
+// parseResourcePage parses the cursor and limit query parameters of a resource URI
+func parseResourcePage(query string) (resourcePage, error) {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return resourcePage{}, fmt.Errorf("%w: invalid query '%s': %v", ErrInvalidURI, query, err)
+	}
+
+	var page resourcePage
+	if cursor := values.Get("cursor"); cursor != "" {
+		key, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil || len(key) == 0 {
+			return resourcePage{}, fmt.Errorf("%w: invalid cursor '%s'", ErrInvalidURI, cursor)
+		}
+		page.cursor = string(key)
+		page.limit = defaultResourcePageSize
+	}
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return resourcePage{}, fmt.Errorf("%w: limit must be a positive integer, got '%s'", ErrInvalidURI, limit)
+		}
+		page.limit = n
+	}
+	return page, nil
+}
+
+// encodeCursor returns the cursor of the page following the item with the given sort key
+func encodeCursor(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+// paginate returns the items of a page and the cursor of the next page, empty on the last page. The items
+// must be sorted by their key.
+func paginate[T any](items []T, key func(T) string, page resourcePage) ([]T, string) {
+	start := 0
+	if page.cursor != "" {
+		start = sort.Search(len(items), func(i int) bool {
+			return key(items[i]) > page.cursor
+		})
+	}
+
+	end := len(items)
+	if page.limit > 0 && start+page.limit < end {
+		end = start + page.limit
+	}
+
+	next := ""
+	if end < len(items) && end > start {
+		next = encodeCursor(key(items[end-1]))
+	}
+	return items[start:end], next
+}
+
+// planSortKey orders plans by creation time, oldest first
+func planSortKey(plan *models.Plan) string {
+	return plan.CreatedAt.UTC().Format("20060102T150405.000000000") + "/" + plan.ID
+}
+
+// taskSortKey orders tasks by their position in the plan. Tasks moved while a client pages through a plan
+// may be skipped or returned twice.
+func taskSortKey(task *models.Task) string {
+	return fmt.Sprintf("%010d/%s", task.Order, task.ID)
+}
+
+// suggestedLimit returns a page size whose payload is likely to stay within maxSize, given that count items
+// took size bytes
+func suggestedLimit(count, size, maxSize int) int {
+	if size <= 0 {
+		return defaultResourcePageSize
+	}
+	return max(1, count*maxSize/size)
+}
+
+// resourceBaseURI returns a resource URI without its query
+func resourceBaseURI(uri string) string {
+	base, _, _ := strings.Cut(uri, "?")
+	return base
+}
@@ -0,0 +1,265 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// Roles limit the tools a client may call beyond the application its token is restricted to. Every tool
+// belongs to one group, and a role allows a list of groups and single tools. A client's role comes from its
+// bearer token, or from the default role for requests without one, such as over STDIO. Without a role every
+// tool is allowed.
+
+// Tool groups
+const (
+	// ToolGroupRead are the tools that only read data
+	ToolGroupRead = "read"
+	// ToolGroupExecute are the tools an agent changes tasks with while working on them
+	ToolGroupExecute = "execute"
+	// ToolGroupPlan are the other tools that create and change plans, tasks and applications
+	ToolGroupPlan = "plan"
+	// ToolGroupDelete are the tools that delete plans and tasks
+	ToolGroupDelete = "delete"
+	// ToolGroupAdmin are the maintenance tools
+	ToolGroupAdmin = "admin"
+)
+
+// allTools is the role entry allowing every tool
+const allTools = "*"
+
+// executeTools are the tools of ToolGroupExecute
+var executeTools = map[string]bool{
+	"update_task":                 true,
+	"claim_task":                  true,
+	"renew_lease":                 true,
+	"log_time":                    true,
+	"update_task_notes":           true,
+	"add_checklist_item":          true,
+	"toggle_checklist_item":       true,
+	"toggle_acceptance_criterion": true,
+	"add_task_attachment":         true,
+	"add_task_tags":               true,
+	"set_task_metadata":           true,
+	"add_task_link":               true,
+	"log_agent_activity":          true,
+	"watch_plan":                  true,
+	"unwatch_plan":                true,
+}
+
+// deleteTools are the tools of ToolGroupDelete
+var deleteTools = map[string]bool{
+	"delete_plan": true,
+	"delete_task": true,
+}
+
+// adminTools are the tools of ToolGroupAdmin
+var adminTools = map[string]bool{
+	"check_data_integrity": true,
+	"get_retention_stats":  true,
+	"lock_plan":            true,
+	"unlock_plan":          true,
+}
+
+// toolGroup returns the group of a tool
+func toolGroup(tool mcp.Tool) string {
+	switch {
+	case adminTools[tool.Name]:
+		return ToolGroupAdmin
+	case deleteTools[tool.Name]:
+		return ToolGroupDelete
+	case tool.Annotations.ReadOnlyHint != nil && *tool.Annotations.ReadOnlyHint:
+		return ToolGroupRead
+	case executeTools[tool.Name]:
+		return ToolGroupExecute
+	default:
+		return ToolGroupPlan
+	}
+}
+
+// Roles maps role names to the tool groups and tools they allow, "*" allowing every tool
+type Roles map[string][]string
+
+// DefaultRoles returns the built-in roles
+func DefaultRoles() Roles {
+	return Roles{
+		"admin":    {allTools},
+		"planner":  {ToolGroupRead, ToolGroupExecute, ToolGroupPlan},
+		"executor": {ToolGroupRead, ToolGroupExecute},
+		"viewer":   {ToolGroupRead},
+	}
+}
+
+// parseRoles parses comma-separated role=entries pairs, the entries separated by spaces, into roles added to
+// the built-in roles. Invalid pairs are skipped with a warning, so tokens of a broken role are rejected.
+func parseRoles(value string) Roles {
+	roles := DefaultRoles()
+	for _, pair := range strings.Split(value, ",") {
+		role, entries, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		role = strings.TrimSpace(role)
+		if !ok || role == "" || strings.TrimSpace(entries) == "" {
+			log.Printf("Warning: ignoring invalid entry in ROLES, expected role=group_or_tool ...")
+			continue
+		}
+		roles[role] = strings.Fields(entries)
+	}
+	return roles
+}
+
+// TokenRoles maps bearer tokens to the role of their requests
+type TokenRoles map[string]string
+
+// String keeps the tokens out of logs
+func (t TokenRoles) String() string {
+	if t == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%d tokens", len(t))
+}
+
+// parseTokenRoles parses a comma-separated list of token=role pairs. Invalid pairs are skipped with a warning.
+func parseTokenRoles(value string) TokenRoles {
+	tokens := make(TokenRoles)
+	for _, pair := range strings.Split(value, ",") {
+		token, role, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		token, role = strings.TrimSpace(token), strings.TrimSpace(role)
+		if !ok || token == "" || role == "" {
+			log.Printf("Warning: ignoring invalid entry in TOKEN_ROLES, expected token=role")
+			continue
+		}
+		tokens[token] = role
+	}
+	return tokens
+}
+
+// lookup returns the role of a token, comparing in constant time
+func (t TokenRoles) lookup(token string) (string, bool) {
+	return lookupToken(t, token)
+}
+
+// roleKey is the context key of the role of a request
+type roleKey struct{}
+
+// withRole returns a context carrying the role of a request
+func withRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, roleKey{}, role)
+}
+
+// roleFromContext returns the role of a request, the default role of the server when its token has none
+func (s *MCPGoServer) roleFromContext(ctx context.Context) string {
+	if role, ok := ctx.Value(roleKey{}).(string); ok {
+		return role
+	}
+	return s.config.DefaultRole
+}
+
+// roleAllows reports whether a role may call a tool. Unknown roles allow nothing, and no role allows all.
+func (s *MCPGoServer) roleAllows(role, toolName string) bool {
+	if role == "" {
+		return true
+	}
+	entries, ok := s.config.Roles[role]
+	if !ok {
+		return false
+	}
+	return slices.Contains(entries, allTools) ||
+		slices.Contains(entries, s.toolGroups[toolName]) ||
+		slices.Contains(entries, toolName)
+}
+
+// roleIsAdmin reports whether a role allows the admin tools. Its requests may change locked plans.
+func (s *MCPGoServer) roleIsAdmin(role string) bool {
+	entries := s.config.Roles[role]
+	return role != "" && (slices.Contains(entries, allTools) || slices.Contains(entries, ToolGroupAdmin))
+}
+
+// roleMiddleware rejects tool calls the role of the client does not allow, and lets admins change locked plans
+func (s *MCPGoServer) roleMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		role := s.roleFromContext(ctx)
+		if !s.roleAllows(role, request.Params.Name) {
+			return s.toolError("", &models.Error{
+				Code:    models.ErrorCodeForbidden,
+				Message: fmt.Sprintf("role %s may not call %s", role, request.Params.Name),
+			}), nil
+		}
+		if s.roleIsAdmin(role) {
+			ctx = storage.WithPlanLockOverride(ctx)
+		}
+		return next(ctx, request)
+	}
+}
+
+// authorizeREST rejects REST API requests the role of the client does not allow, checking the MCP tool doing
+// the same as the route like a call of that tool
+func (s *MCPGoServer) authorizeREST(r *http.Request, tool string) error {
+	role := s.roleFromContext(r.Context())
+	if s.roleAllows(role, tool) {
+		return nil
+	}
+	return &models.Error{
+		Code:    models.ErrorCodeForbidden,
+		Message: fmt.Sprintf("role %s may not call %s %s", role, r.Method, r.URL.Path),
+	}
+}
+
+// roleToolFilter lists only the tools the role of the client allows
+func (s *MCPGoServer) roleToolFilter(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	role := s.roleFromContext(ctx)
+	if role == "" {
+		return tools
+	}
+	allowed := make([]mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if s.roleAllows(role, tool.Name) {
+			allowed = append(allowed, tool)
+		}
+	}
+	return allowed
+}
+
+// checkRoles warns about roles naming groups or tools that do not exist, and about tokens and the default role
+// naming roles that do not exist, whose calls are all rejected
+func (s *MCPGoServer) checkRoles() {
+	for role, entries := range s.config.Roles {
+		for _, entry := range entries {
+			if entry != allTools && !isToolGroup(entry) && s.toolGroups[entry] == "" {
+				log.Printf("Warning: role %s allows %s, which is neither a tool group nor a registered tool", role, entry)
+			}
+		}
+	}
+
+	unknown := make(map[string]bool)
+	for _, role := range s.config.TokenRoles {
+		if _, ok := s.config.Roles[role]; !ok {
+			unknown[role] = true
+		}
+	}
+	if role := s.config.DefaultRole; role != "" {
+		if _, ok := s.config.Roles[role]; !ok {
+			unknown[role] = true
+		}
+	}
+	for _, role := range slices.Sorted(maps.Keys(unknown)) {
+		log.Printf("Warning: role %s is not defined, its requests may not call any tool", role)
+	}
+}
+
+// isToolGroup reports whether a role entry names a tool group
+func isToolGroup(entry string) bool {
+	switch entry {
+	case ToolGroupRead, ToolGroupExecute, ToolGroupPlan, ToolGroupDelete, ToolGroupAdmin:
+		return true
+	}
+	return false
+}
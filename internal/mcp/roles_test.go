@@ -0,0 +1,236 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/api"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// newRoleTestServer creates a server on an in-memory store whose requests have the given default role
+func newRoleTestServer(t *testing.T, roles Roles, defaultRole string) *MCPGoServer {
+	t.Helper()
+	return newTestServer(t, WithServerConfig(ServerConfig{Roles: roles, DefaultRole: defaultRole}))
+}
+
+func TestToolGroups(t *testing.T) {
+	repos := newTestRepos(t)
+	tools := serverTools(t, NewMCPGoServer(repos.planRepo, repos.taskRepo,
+		WithWatchers(storage.NewWatcherStore(repos.client)), WithAttachments(storage.NewValkeyAttachmentStore(repos.client))))
+	for _, names := range []map[string]bool{executeTools, deleteTools} {
+		for name := range names {
+			if _, ok := tools[name]; !ok {
+				t.Errorf("%s is in a tool group but is not registered", name)
+			}
+		}
+	}
+
+	for name, expected := range map[string]string{
+		"get_plan":    ToolGroupRead,
+		"update_task": ToolGroupExecute,
+		"create_plan": ToolGroupPlan,
+		"delete_plan": ToolGroupDelete,
+	} {
+		if group := toolGroup(tools[name]); group != expected {
+			t.Errorf("group of %s = %q, expected %q", name, group, expected)
+		}
+	}
+	for name := range executeTools {
+		if tools[name].Annotations.ReadOnlyHint != nil && *tools[name].Annotations.ReadOnlyHint {
+			t.Errorf("%s only reads data, it belongs in the read group", name)
+		}
+	}
+}
+
+func TestParseRoles(t *testing.T) {
+	roles := parseRoles("reviewer=read update_plan_status, viewer = read list_watchers,broken,=plan")
+	if got := roles["reviewer"]; len(got) != 2 || got[1] != "update_plan_status" {
+		t.Errorf("reviewer = %v", got)
+	}
+	if got := roles["viewer"]; len(got) != 2 {
+		t.Errorf("viewer = %v, the built-in role should be overridden", got)
+	}
+	if _, ok := roles["planner"]; !ok {
+		t.Error("the built-in roles should be kept")
+	}
+
+	tokens := parseTokenRoles("secret-a=executor, broken")
+	if role, ok := tokens.lookup("secret-a"); !ok || role != "executor" || len(tokens) != 1 {
+		t.Errorf("lookup(secret-a) = %q, %v with %d tokens", role, ok, len(tokens))
+	}
+	if got := tokens.String(); got != "1 tokens" {
+		t.Errorf("String() = %q, the tokens should not be printed", got)
+	}
+}
+
+func TestRoleMiddleware(t *testing.T) {
+	planner := newRoleTestServer(t, DefaultRoles(), "planner")
+	result := callTool(t, planner, "create_plan", map[string]any{"application_id": "app", "name": "Plan"})
+	if result.IsError {
+		t.Fatalf("planner failed to create a plan: %s", toolResultText(result))
+	}
+	var plan models.Plan
+	if err := json.Unmarshal([]byte(toolResultText(result)), &plan); err != nil {
+		t.Fatalf("failed to parse plan: %v", err)
+	}
+
+	result = callTool(t, planner, "delete_plan", map[string]any{"id": plan.ID})
+	var got models.Error
+	if err := json.Unmarshal([]byte(toolResultText(result)), &got); err != nil || got.Code != models.ErrorCodeForbidden {
+		t.Errorf("expected a FORBIDDEN error deleting a plan as planner, got %s", toolResultText(result))
+	}
+
+	tools := serverTools(t, planner)
+	if _, ok := tools["delete_plan"]; ok {
+		t.Error("delete_plan should not be listed for planners")
+	}
+	if _, ok := tools["update_task"]; !ok {
+		t.Error("update_task should be listed for planners")
+	}
+
+	// Single tools can be allowed besides groups, and unknown roles allow nothing
+	reviewer := newRoleTestServer(t, Roles{"reviewer": {ToolGroupRead, "update_plan_status"}}, "reviewer")
+	if !reviewer.roleAllows("reviewer", "update_plan_status") || reviewer.roleAllows("reviewer", "update_plan") {
+		t.Error("the reviewer should update plan statuses and nothing else")
+	}
+	if reviewer.roleAllows("auditor", "get_plan") {
+		t.Error("an unknown role should allow no tool")
+	}
+	if !reviewer.roleAllows("", "delete_plan") {
+		t.Error("no role should allow every tool")
+	}
+	if role := reviewer.roleFromContext(withRole(context.Background(), "viewer")); role != "viewer" {
+		t.Errorf("role of a token = %q, expected it to take precedence over the default role", role)
+	}
+}
+
+func TestScopeHandlerRoles(t *testing.T) {
+	var role string
+	var scope string
+	s := &MCPGoServer{config: ServerConfig{
+		ApplicationTokens: ApplicationTokens{"secret-a": "app-a"},
+		TokenRoles:        TokenRoles{"secret-a": "executor", "secret-v": "viewer"},
+		DefaultRole:       "planner",
+	}}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		role = s.roleFromContext(r.Context())
+		scope = storage.ApplicationScopeFromContext(r.Context())
+	})
+
+	serve := func(auth string) int {
+		role, scope = "", ""
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		s.scopeHandler(next).ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := serve("Bearer secret-a"); code != http.StatusOK || role != "executor" || scope != "app-a" {
+		t.Errorf("status = %d, role = %q, scope = %q", code, role, scope)
+	}
+	if code := serve("Bearer secret-v"); code != http.StatusOK || role != "viewer" || scope != "" {
+		t.Errorf("status = %d, role = %q, scope = %q for a token with a role only", code, role, scope)
+	}
+	if code := serve(""); code != http.StatusUnauthorized {
+		t.Errorf("status without token = %d, expected %d", code, http.StatusUnauthorized)
+	}
+
+	s.config.TokenRoles = nil
+	if code := serve("Bearer secret-a"); code != http.StatusOK || role != "planner" {
+		t.Errorf("status = %d, role = %q, expected the default role for a token without one", code, role)
+	}
+}
+
+func TestRESTRoles(t *testing.T) {
+	s := newRoleTestServer(t, DefaultRoles(), "")
+	s.config.TokenRoles = TokenRoles{"secret-v": "viewer", "secret-e": "executor", "secret-p": "planner"}
+	plan, err := s.planRepo.Create(context.Background(), "app", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+
+	rest := api.NewHandler(s.planRepo, s.taskRepo)
+	rest.SetAuthorizer(s.authorizeREST)
+	handler := s.scopeHandler(rest)
+
+	serve := func(token, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, api.BasePath+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve("secret-v", http.MethodGet, "/plans/"+plan.ID, ""); rec.Code != http.StatusOK {
+		t.Errorf("viewer GET status = %d, expected %d", rec.Code, http.StatusOK)
+	}
+	if rec := serve("secret-v", http.MethodGet, "/openapi.json", ""); rec.Code != http.StatusOK {
+		t.Errorf("viewer spec status = %d, expected %d", rec.Code, http.StatusOK)
+	}
+	for _, tt := range []struct {
+		token, method, path, body string
+	}{
+		{"secret-v", http.MethodPatch, "/plans/" + plan.ID, `{"name": "Renamed"}`},
+		{"secret-v", http.MethodDelete, "/plans/" + plan.ID, ""},
+		{"secret-e", http.MethodPost, "/plans", `{"name": "n", "application_id": "app"}`},
+		{"secret-p", http.MethodDelete, "/plans/" + plan.ID, ""},
+	} {
+		rec := serve(tt.token, tt.method, tt.path, tt.body)
+		var resp api.ErrorResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode response of %s %s: %v", tt.method, tt.path, err)
+		}
+		if rec.Code != http.StatusForbidden || resp.Code != models.ErrorCodeForbidden {
+			t.Errorf("%s %s with %s: status = %d, code = %q, expected %d %s", tt.method, tt.path, tt.token,
+				rec.Code, resp.Code, http.StatusForbidden, models.ErrorCodeForbidden)
+		}
+	}
+	if _, err := s.planRepo.Get(context.Background(), plan.ID); err != nil {
+		t.Errorf("plan should not be deleted by forbidden requests: %v", err)
+	}
+	if rec := serve("secret-p", http.MethodPatch, "/plans/"+plan.ID, `{"name": "Renamed"}`); rec.Code != http.StatusOK {
+		t.Errorf("planner PATCH status = %d, expected %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+}
+
+func TestRolePlanLockOverride(t *testing.T) {
+	repos := newTestRepos(t)
+	planRepo := storage.NewLockedPlanRepository(repos.planRepo)
+	taskRepo := storage.NewLockedTaskRepository(repos.taskRepo, planRepo)
+	newServer := func(role string) *MCPGoServer {
+		return NewMCPGoServer(planRepo, taskRepo, WithServerConfig(ServerConfig{Roles: DefaultRoles(), DefaultRole: role}))
+	}
+	admin, planner := newServer("admin"), newServer("planner")
+
+	plan, err := planRepo.Create(context.Background(), "app", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	result := callTool(t, planner, "lock_plan", map[string]any{"id": plan.ID})
+	var got models.Error
+	if err := json.Unmarshal([]byte(toolResultText(result)), &got); err != nil || got.Code != models.ErrorCodeForbidden {
+		t.Errorf("expected planners not to lock plans, got %s", toolResultText(result))
+	}
+
+	result = callTool(t, admin, "lock_plan", map[string]any{"id": plan.ID, "reason": "review"})
+	if result.IsError {
+		t.Fatalf("admin failed to lock the plan: %s", toolResultText(result))
+	}
+	result = callTool(t, planner, "update_plan", map[string]any{"id": plan.ID, "name": "Renamed"})
+	if err := json.Unmarshal([]byte(toolResultText(result)), &got); err != nil || got.Code != models.ErrorCodeConflict {
+		t.Errorf("expected a CONFLICT error updating a locked plan as planner, got %s", toolResultText(result))
+	}
+	result = callTool(t, admin, "update_plan", map[string]any{"id": plan.ID, "name": "Renamed"})
+	if result.IsError {
+		t.Errorf("admin failed to update the locked plan: %s", toolResultText(result))
+	}
+}
@@ -0,0 +1,147 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/api"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// ApplicationTokens maps bearer tokens to the application their requests are restricted to
+type ApplicationTokens map[string]string
+
+// String keeps the tokens out of logs
+func (t ApplicationTokens) String() string {
+	if t == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%d tokens", len(t))
+}
+
+// parseApplicationTokens parses a comma-separated list of token=application_id pairs.
+// Invalid pairs are skipped with a warning, so a broken list rejects requests rather than allowing them.
+func parseApplicationTokens(value string) ApplicationTokens {
+	tokens := make(ApplicationTokens)
+	for _, pair := range strings.Split(value, ",") {
+		token, applicationID, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		token, applicationID = strings.TrimSpace(token), strings.TrimSpace(applicationID)
+		if !ok || token == "" || applicationID == "" {
+			log.Printf("Warning: ignoring invalid entry in APPLICATION_TOKENS, expected token=application_id")
+			continue
+		}
+		tokens[token] = applicationID
+	}
+	return tokens
+}
+
+// lookup returns the application of a token, comparing in constant time
+func (t ApplicationTokens) lookup(token string) (string, bool) {
+	return lookupToken(t, token)
+}
+
+// lookupToken returns the value of a token in a map of tokens, comparing in constant time
+func lookupToken[M ~map[string]string](tokens M, token string) (string, bool) {
+	var value string
+	found := false
+	for candidate, v := range tokens {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			value, found = v, true
+		}
+	}
+	return value, found
+}
+
+// scopeContext restricts a context to the application scope of the server, unless the request was already
+// restricted by its token
+func (s *MCPGoServer) scopeContext(ctx context.Context) context.Context {
+	if storage.ApplicationScopeFromContext(ctx) == "" && s.config.ApplicationScope != "" {
+		return storage.WithApplicationScope(ctx, s.config.ApplicationScope)
+	}
+	return ctx
+}
+
+// scopeMiddleware restricts tool calls to the application scope, which also covers the STDIO transport
+func (s *MCPGoServer) scopeMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return next(s.scopeContext(ctx), request)
+	}
+}
+
+// scopeResourceHandler restricts resource reads to the application scope
+func (s *MCPGoServer) scopeResourceHandler(next server.ResourceTemplateHandlerFunc) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return next(s.scopeContext(ctx), request)
+	}
+}
+
+// checkEntityScope returns a not found error if a plan or task is outside the application scope of the
+// context. Tools reading stores other than the repositories, such as the audit log, check this first.
+func (s *MCPGoServer) checkEntityScope(ctx context.Context, entityType models.EntityType, id string) error {
+	if storage.ApplicationScopeFromContext(ctx) == "" {
+		return nil
+	}
+	var err error
+	if entityType == models.EntityTypeTask {
+		_, err = s.taskRepo.Get(ctx, id)
+	} else {
+		_, err = s.planRepo.Get(ctx, id)
+	}
+	return err
+}
+
+// isAdminRequest reports whether a request is for the admin API
+func (s *MCPGoServer) isAdminRequest(r *http.Request) bool {
+	return s.config.AdminToken != "" && strings.HasPrefix(r.URL.Path, api.AdminBasePath+"/")
+}
+
+// scopeHandler restricts HTTP requests to an application and a role. With application tokens or token roles
+// configured, every request except health checks needs a bearer token from either list, and is restricted to
+// the application and the role of its token; tokens of other applications than the server's scope are
+// rejected, and tokens of admin roles may change locked plans. Admin API requests
+// carry the admin token instead, which the admin API checks itself.
+func (s *MCPGoServer) scopeHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if (s.config.ApplicationTokens == nil && s.config.TokenRoles == nil) ||
+			r.URL.Path == "/health" || s.isAdminRequest(r) {
+			next.ServeHTTP(w, r.WithContext(s.scopeContext(r.Context())))
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		token = strings.TrimSpace(token)
+		applicationID, scoped := s.config.ApplicationTokens.lookup(token)
+		role, hasRole := s.config.TokenRoles.lookup(token)
+		if !ok || (!scoped && !hasRole) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "a valid bearer token is required", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := s.scopeContext(r.Context())
+		if scoped {
+			// A token may narrow an unscoped server to its application, but never widen the server's scope
+			if scope := storage.ApplicationScopeFromContext(ctx); scope != "" && scope != applicationID {
+				http.Error(w, "the token is not valid for the application of this server", http.StatusForbidden)
+				return
+			}
+			ctx = storage.WithApplicationScope(ctx, applicationID)
+		}
+		if hasRole {
+			ctx = withRole(ctx, role)
+			if s.roleIsAdmin(role) {
+				ctx = storage.WithPlanLockOverride(ctx)
+			}
+		}
+		ctx = withAuthenticatedToken(ctx, token)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,76 @@
+package mcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+func TestParseApplicationTokens(t *testing.T) {
+	tokens := parseApplicationTokens("secret-a=app-a, secret-b = app-b,broken,=app-c")
+	if len(tokens) != 2 {
+		t.Fatalf("parsed %d tokens, expected 2", len(tokens))
+	}
+	if app, ok := tokens.lookup("secret-b"); !ok || app != "app-b" {
+		t.Errorf("lookup(secret-b) = %q, %v", app, ok)
+	}
+	if _, ok := tokens.lookup("secret"); ok {
+		t.Error("a prefix of a token should not match")
+	}
+	if got := tokens.String(); got != "2 tokens" {
+		t.Errorf("String() = %q, the tokens should not be printed", got)
+	}
+}
+
+func TestScopeHandler(t *testing.T) {
+	var scope string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope = storage.ApplicationScopeFromContext(r.Context())
+	})
+
+	serve := func(s *MCPGoServer, path, auth string) int {
+		scope = ""
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		s.scopeHandler(next).ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// A server scope applies to every request
+	s := &MCPGoServer{config: ServerConfig{ApplicationScope: "app-a"}}
+	if code := serve(s, "/api/v1/plans", ""); code != http.StatusOK || scope != "app-a" {
+		t.Errorf("status = %d, scope = %q", code, scope)
+	}
+
+	// With tokens, requests need a listed token and get its application
+	s = &MCPGoServer{config: ServerConfig{ApplicationTokens: ApplicationTokens{"secret-b": "app-b"}}}
+	if code := serve(s, "/mcp", "Bearer secret-b"); code != http.StatusOK || scope != "app-b" {
+		t.Errorf("status = %d, scope = %q", code, scope)
+	}
+	if code := serve(s, "/mcp", ""); code != http.StatusUnauthorized {
+		t.Errorf("status without token = %d, expected %d", code, http.StatusUnauthorized)
+	}
+	if code := serve(s, "/mcp", "Bearer wrong"); code != http.StatusUnauthorized {
+		t.Errorf("status with unknown token = %d, expected %d", code, http.StatusUnauthorized)
+	}
+	if code := serve(s, "/health", ""); code != http.StatusOK {
+		t.Errorf("status of health check = %d, expected %d", code, http.StatusOK)
+	}
+
+	// A token cannot replace the scope of a scoped server
+	s = &MCPGoServer{config: ServerConfig{
+		ApplicationScope:  "app-a",
+		ApplicationTokens: ApplicationTokens{"secret-a": "app-a", "secret-b": "app-b"},
+	}}
+	if code := serve(s, "/mcp", "Bearer secret-a"); code != http.StatusOK || scope != "app-a" {
+		t.Errorf("status = %d, scope = %q", code, scope)
+	}
+	if code := serve(s, "/mcp", "Bearer secret-b"); code != http.StatusForbidden || scope != "" {
+		t.Errorf("status with token of another application = %d, expected %d", code, http.StatusForbidden)
+	}
+}
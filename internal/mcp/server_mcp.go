@@ -1,18 +1,28 @@
 package mcp
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 
+	"github.com/jbrinkman/valkey-ai-tasks/internal/api"
+	settings "github.com/jbrinkman/valkey-ai-tasks/internal/config"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/i18n"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/integrations/github"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/integrations/jira"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
 	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/ui"
 )
 
 // ServerConfig holds configuration for the MCP server
@@ -35,47 +45,335 @@ type ServerConfig struct {
 	// StreamableHTTPStateless controls whether the Streamable HTTP transport is stateless
 	StreamableHTTPStateless bool
 
+	// EnableWebSocket controls whether the WebSocket transport is enabled
+	EnableWebSocket bool
+	// WebSocketEndpoint is the endpoint path for WebSocket transport
+	WebSocketEndpoint string
+
 	// EnableSTDIO controls whether the STDIO transport is enabled
 	EnableSTDIO bool
 	// STDIOErrorLog controls whether to log errors to stderr
 	STDIOErrorLog bool
 
+	// EnableREST controls whether the REST API is served alongside the HTTP transports
+	EnableREST bool
+
+	// AdminToken, when set, serves the admin API and is the bearer token its requests must carry
+	AdminToken string
+	// BackupDir is the directory the admin API writes plan backups to
+	BackupDir string
+
+	// ApplicationScope restricts every request to the plans of one application, empty allows all
+	ApplicationScope string
+	// ApplicationTokens, when set, requires HTTP requests to carry one of the bearer tokens and restricts them
+	// to its application
+	ApplicationTokens ApplicationTokens
+
+	// Roles are the roles tokens and the default role can have, by name
+	Roles Roles
+	// TokenRoles, when set, requires HTTP requests to carry one of the bearer tokens or of ApplicationTokens,
+	// and restricts the tools they may call to the role of their token
+	TokenRoles TokenRoles
+	// DefaultRole is the role of requests whose token has none, such as over STDIO; empty allows every tool
+	DefaultRole string
+
+	// ReadOnly exposes only the tools that do not change data and serves the REST API read-only,
+	// for endpoints used by agents that may only look at the plans
+	ReadOnly bool
+	// ConfirmDestructiveTools asks the user to confirm deletions through MCP elicitation, where the client
+	// supports it
+	ConfirmDestructiveTools bool
+
+	// EnableWebUI controls whether the read-only web dashboard is served alongside the HTTP transports
+	EnableWebUI bool
+	// WebUIPollInterval is how often the dashboard live updates check for changes in seconds
+	WebUIPollInterval int
+
 	// ServerReadTimeout is the maximum duration for reading the entire request in seconds
 	ServerReadTimeout int
 	// ServerWriteTimeout is the maximum duration for writing the response in seconds
 	ServerWriteTimeout int
+	// ToolTimeout is the maximum duration of a tool call or resource read in seconds
+	ToolTimeout int
+	// MaxResourceSize is the size in bytes past which plan resources return summaries instead of whole plans,
+	// 0 never summarizes them
+	MaxResourceSize int
+
+	// RateLimitPerSecond is the average number of tool calls and resource reads allowed per client and second,
+	// 0 disables the limit
+	RateLimitPerSecond float64
+	// RateLimitBurst is how many requests a client can make at once before RateLimitPerSecond applies
+	RateLimitBurst int
+	// ExpensiveRateLimitPerSecond additionally limits list and export tools and full plan resources,
+	// 0 disables the limit
+	ExpensiveRateLimitPerSecond float64
+	// ExpensiveRateLimitBurst is how many expensive requests a client can make at once
+	ExpensiveRateLimitBurst int
+
+	// TLSCertFile is the path of the PEM certificate used to serve HTTPS
+	TLSCertFile string
+	// TLSKeyFile is the path of the PEM private key of TLSCertFile
+	TLSKeyFile string
+	// TLSAutocertDomains are the domains to obtain certificates for over ACME instead of using TLSCertFile
+	TLSAutocertDomains []string
+	// TLSAutocertCacheDir is where certificates obtained over ACME are stored
+	TLSAutocertCacheDir string
+	// TLSAutocertEmail is the contact email registered with the ACME certificate authority
+	TLSAutocertEmail string
+
+	// Language is the language of tool descriptions and error messages, such as es or ja_JP.UTF-8;
+	// empty or unsupported languages use English
+	Language string
 }
 
 // MCPGoServer wraps the mark3labs/mcp-go server implementation
 type MCPGoServer struct {
-	server   *server.MCPServer
-	config   ServerConfig
-	planRepo storage.PlanRepositoryInterface
-	taskRepo storage.TaskRepositoryInterface
+	server     *server.MCPServer
+	config     ServerConfig
+	configured bool
+	httpServer atomic.Pointer[http.Server]
+	limiter    atomic.Pointer[requestLimiter]
+	planRepo   storage.PlanRepositoryInterface
+	taskRepo   storage.TaskRepositoryInterface
+
+	// applicationRepo stores the registered applications, nil leaves out the application tools
+	applicationRepo storage.ApplicationRepositoryInterface
+
+	// readOnly, the limiter, the tool timeout and the confirmation of deletions can be changed by Reload while
+	// the server runs
+	readOnly           atomic.Bool
+	toolTimeout        atomic.Int64
+	confirmDestructive atomic.Bool
+	writeTools         []server.ServerTool
+	restHandler        atomic.Pointer[api.Handler]
+
+	// tools are all registered tools, including write tools left out in read-only mode
+	tools []mcp.Tool
+	// toolParams are the names of the arguments of each tool
+	toolParams map[string]map[string]bool
+	// toolGroups are the groups of the tools, by name
+	toolGroups map[string]string
+
+	// sessionContexts are the current application and plan of the client sessions
+	sessionContexts *sessionContexts
+
+	// localizer translates tool descriptions and error messages, nil leaves them in English
+	localizer *i18n.Localizer
+
+	planStats   *services.PlanStatsService
+	planChanges *services.PlanChangeService
+	planImport  *services.PlanImportService
+	auditLog    *storage.AuditLog
+	undo        *services.UndoService
+	timeline    *services.TimelineService
+	integrity   *storage.IntegrityChecker
+	retention   *services.RetentionJanitor
+
+	// adminIntegrity backs the admin API, which is served whether or not the admin tools are offered
+	adminIntegrity *storage.IntegrityChecker
+	// resourceCache serves repeated reads of the full plan resources, nil reads them from storage every time
+	resourceCache *ResourceCache
+
+	idempotency     *storage.IdempotencyStore
+	attachmentStore storage.AttachmentStore
+	attachments     *services.AttachmentService
+	watchers        *storage.WatcherStore
+	compactor       *storage.NotesCompactor
+
+	githubClient *github.Client
+	githubRepo   string
+	githubSync   *github.Syncer
+
+	jiraClient    *jira.Client
+	jiraMapping   jira.FieldMapping
+	jiraConnector *jira.Connector
+}
+
+// ServerOption configures optional dependencies of the MCP server
+type ServerOption func(*MCPGoServer)
+
+// WithServerConfig configures the server with the given configuration instead of reading it from the
+// environment and the config file
+func WithServerConfig(config ServerConfig) ServerOption {
+	return func(s *MCPGoServer) {
+		s.config = config
+		s.configured = true
+	}
+}
+
+// WithApplications enables the tools registering and listing applications backed by the given repository
+func WithApplications(repo storage.ApplicationRepositoryInterface) ServerOption {
+	return func(s *MCPGoServer) {
+		s.applicationRepo = repo
+	}
+}
+
+// WithAuditLog enables the history tools backed by the given audit log
+func WithAuditLog(auditLog *storage.AuditLog) ServerOption {
+	return func(s *MCPGoServer) {
+		s.auditLog = auditLog
+	}
+}
+
+// WithIntegrityChecker enables the data integrity admin tool backed by the given checker
+func WithIntegrityChecker(checker *storage.IntegrityChecker) ServerOption {
+	return func(s *MCPGoServer) {
+		s.integrity = checker
+	}
+}
+
+// WithRetentionJanitor enables the retention stats tool reporting on the given janitor
+func WithRetentionJanitor(janitor *services.RetentionJanitor) ServerOption {
+	return func(s *MCPGoServer) {
+		s.retention = janitor
+	}
+}
+
+// WithAdminAPI provides the integrity checker the admin API runs its integrity check and reindex with. The
+// admin API is only served when an admin token is configured.
+func WithAdminAPI(checker *storage.IntegrityChecker) ServerOption {
+	return func(s *MCPGoServer) {
+		s.adminIntegrity = checker
+	}
+}
+
+// WithResourceCache serves repeated reads of the full plan resources from the given cache. The cache has to be
+// emptied when plans or tasks change, such as by listening to the audit log.
+func WithResourceCache(cache *ResourceCache) ServerOption {
+	return func(s *MCPGoServer) {
+		s.resourceCache = cache
+	}
+}
+
+// WithIdempotency lets clients retry the tools that create data with an idempotency key,
+// remembering results in the given store
+func WithIdempotency(store *storage.IdempotencyStore) ServerOption {
+	return func(s *MCPGoServer) {
+		s.idempotency = store
+	}
+}
+
+// WithAttachments enables the task attachment tools backed by the given store
+func WithAttachments(store storage.AttachmentStore) ServerOption {
+	return func(s *MCPGoServer) {
+		s.attachmentStore = store
+	}
+}
+
+// WithWatchers enables the tools watching plans backed by the given store
+func WithWatchers(store *storage.WatcherStore) ServerOption {
+	return func(s *MCPGoServer) {
+		s.watchers = store
+	}
+}
+
+// WithNotesCompactor enables the tools reading the notes archived by the given compactor
+func WithNotesCompactor(compactor *storage.NotesCompactor) ServerOption {
+	return func(s *MCPGoServer) {
+		s.compactor = compactor
+	}
+}
+
+// WithGitHubSync enables the GitHub issue sync tools using the given API client.
+// The repository in owner/name form is used when a tool call does not name one and may be empty.
+func WithGitHubSync(client *github.Client, defaultRepo string) ServerOption {
+	return func(s *MCPGoServer) {
+		s.githubClient = client
+		s.githubRepo = defaultRepo
+	}
+}
+
+// WithJira enables the Jira import and status push tools using the given API client and field mapping
+func WithJira(client *jira.Client, mapping jira.FieldMapping) ServerOption {
+	return func(s *MCPGoServer) {
+		s.jiraClient = client
+		s.jiraMapping = mapping
+	}
 }
 
 // NewMCPGoServer creates a new MCP server using the mark3labs/mcp-go library
-func NewMCPGoServer(planRepo storage.PlanRepositoryInterface, taskRepo storage.TaskRepositoryInterface) *MCPGoServer {
+func NewMCPGoServer(
+	planRepo storage.PlanRepositoryInterface,
+	taskRepo storage.TaskRepositoryInterface,
+	opts ...ServerOption,
+) *MCPGoServer {
+	mcpServer := &MCPGoServer{
+		planRepo: planRepo,
+		taskRepo: taskRepo,
+
+		planStats:   services.NewPlanStatsService(planRepo, taskRepo),
+		planChanges: services.NewPlanChangeService(planRepo, taskRepo),
+		planImport:  services.NewPlanImportService(planRepo, taskRepo),
+
+		toolParams:      make(map[string]map[string]bool),
+		toolGroups:      make(map[string]string),
+		sessionContexts: newSessionContexts(),
+	}
+
+	for _, opt := range opts {
+		opt(mcpServer)
+	}
+
+	// Get configuration from the environment and the config file unless an option set it
+	if !mcpServer.configured {
+		mcpServer.config = loadServerConfig()
+	}
+	config := mcpServer.config
+
+	localizer, err := i18n.New(config.Language)
+	if err != nil {
+		log.Printf("Warning: %v, using English", err)
+	}
+	mcpServer.localizer = localizer
+
+	// Throttled tool calls are rejected before they are attributed to an actor, and retried calls are
+	// replayed after that, so the original call is the one recorded
+	mcpServer.limiter.Store(newRequestLimiter(config))
+	mcpServer.readOnly.Store(config.ReadOnly)
+	mcpServer.setToolTimeout(config.ToolTimeout)
+	mcpServer.confirmDestructive.Store(config.ConfirmDestructiveTools)
+	serverOptions := []server.ServerOption{
+		server.WithToolCapabilities(true),
+		server.WithRecovery(),
+		server.WithToolHandlerMiddleware(mcpServer.rateLimitMiddleware),
+		server.WithToolHandlerMiddleware(mcpServer.roleMiddleware),
+		server.WithToolHandlerMiddleware(mcpServer.timeoutMiddleware),
+		server.WithToolHandlerMiddleware(actorMiddleware),
+		server.WithToolHandlerMiddleware(mcpServer.scopeMiddleware),
+		server.WithToolHandlerMiddleware(mcpServer.sessionDefaultsMiddleware),
+		server.WithToolHandlerMiddleware(mcpServer.idempotencyMiddleware),
+		server.WithToolHandlerMiddleware(warningsMiddleware),
+		server.WithToolFilter(mcpServer.roleToolFilter),
+		server.WithHooks(mcpServer.sessionHooks()),
+	}
+
 	// Create a new MCP server
-	s := server.NewMCPServer(
+	mcpServer.server = server.NewMCPServer(
 		"Valkey Feature Planning & Task Management",
 		"1.0.0",
-		server.WithToolCapabilities(true),
-		server.WithRecovery(),
+		serverOptions...,
 	)
 
-	// Get configuration from environment variables
-	config := getServerConfigFromEnv()
+	if mcpServer.auditLog != nil {
+		mcpServer.undo = services.NewUndoService(planRepo, taskRepo, mcpServer.auditLog)
+		mcpServer.timeline = services.NewTimelineService(planRepo, taskRepo, mcpServer.auditLog)
+	}
 
-	mcpServer := &MCPGoServer{
-		server:   s,
-		config:   config,
-		planRepo: planRepo,
-		taskRepo: taskRepo,
+	if mcpServer.attachmentStore != nil {
+		mcpServer.attachments = services.NewAttachmentService(planRepo, taskRepo, mcpServer.attachmentStore)
+	}
+
+	if mcpServer.githubClient != nil {
+		mcpServer.githubSync = github.NewSyncer(planRepo, taskRepo, mcpServer.githubClient)
+	}
+
+	if mcpServer.jiraClient != nil {
+		mcpServer.jiraConnector = jira.NewConnector(planRepo, taskRepo, mcpServer.jiraClient, mcpServer.jiraMapping)
 	}
 
 	// Register all tools
 	mcpServer.registerTools()
+	mcpServer.checkRoles()
 
 	// Register all resources
 	mcpServer.registerResources()
@@ -83,10 +381,9 @@ func NewMCPGoServer(planRepo storage.PlanRepositoryInterface, taskRepo storage.T
 	return mcpServer
 }
 
-// getServerConfigFromEnv reads server configuration from environment variables
-func getServerConfigFromEnv() ServerConfig {
-	// Default configuration
-	config := ServerConfig{
+// DefaultServerConfig returns the server configuration used for the settings that are not set
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
 		// SSE configuration
 		EnableSSE:            true,
 		SSEEndpoint:          "/sse",
@@ -99,75 +396,220 @@ func getServerConfigFromEnv() ServerConfig {
 		StreamableHTTPHeartbeatInterval: 30,
 		StreamableHTTPStateless:         false,
 
+		// WebSocket configuration
+		EnableWebSocket:   false,
+		WebSocketEndpoint: "/ws",
+
 		// STDIO configuration
 		EnableSTDIO:   false,
 		STDIOErrorLog: true,
 
+		// REST API configuration
+		EnableREST: false,
+
+		// Admin API configuration
+		BackupDir: "backups",
+
+		// Role configuration
+		Roles: DefaultRoles(),
+
+		// Web UI configuration
+		EnableWebUI:       false,
+		WebUIPollInterval: 2,
+
 		// Server configuration
 		ServerReadTimeout:  60,
 		ServerWriteTimeout: 60,
+		ToolTimeout:        60,
+		MaxResourceSize:    1 << 20,
+
+		// Rate limit configuration
+		RateLimitPerSecond:          0,
+		RateLimitBurst:              20,
+		ExpensiveRateLimitPerSecond: 0,
+		ExpensiveRateLimitBurst:     5,
+
+		// TLS configuration
+		TLSAutocertCacheDir: "autocert-cache",
 	}
+}
 
-	// SSE configuration from environment variables
-	if val := os.Getenv("ENABLE_SSE"); val != "" {
+// loadServerConfig reads the server configuration from the environment and the config file
+func loadServerConfig() ServerConfig {
+	config := DefaultServerConfig()
+
+	// SSE configuration from the settings
+	if val := settings.Get("ENABLE_SSE"); val != "" {
 		config.EnableSSE = strings.ToLower(val) == "true"
 	}
 
-	if val := os.Getenv("SSE_ENDPOINT"); val != "" {
+	if val := settings.Get("SSE_ENDPOINT"); val != "" {
 		config.SSEEndpoint = val
 	}
 
-	if val := os.Getenv("SSE_KEEP_ALIVE"); val != "" {
+	if val := settings.Get("SSE_KEEP_ALIVE"); val != "" {
 		config.SSEKeepAlive = strings.ToLower(val) == "true"
 	}
 
-	if val := os.Getenv("SSE_KEEP_ALIVE_INTERVAL"); val != "" {
+	if val := settings.Get("SSE_KEEP_ALIVE_INTERVAL"); val != "" {
 		if interval, err := strconv.Atoi(val); err == nil && interval > 0 {
 			config.SSEKeepAliveInterval = interval
 		}
 	}
 
-	// Streamable HTTP configuration from environment variables
-	if val := os.Getenv("ENABLE_STREAMABLE_HTTP"); val != "" {
+	// Streamable HTTP configuration from the settings
+	if val := settings.Get("ENABLE_STREAMABLE_HTTP"); val != "" {
 		config.EnableStreamableHTTP = strings.ToLower(val) == "true"
 	}
 
-	if val := os.Getenv("STREAMABLE_HTTP_ENDPOINT"); val != "" {
+	if val := settings.Get("STREAMABLE_HTTP_ENDPOINT"); val != "" {
 		config.StreamableHTTPEndpoint = val
 	}
 
-	if val := os.Getenv("STREAMABLE_HTTP_HEARTBEAT_INTERVAL"); val != "" {
+	if val := settings.Get("STREAMABLE_HTTP_HEARTBEAT_INTERVAL"); val != "" {
 		if interval, err := strconv.Atoi(val); err == nil && interval > 0 {
 			config.StreamableHTTPHeartbeatInterval = interval
 		}
 	}
 
-	if val := os.Getenv("STREAMABLE_HTTP_STATELESS"); val != "" {
+	if val := settings.Get("STREAMABLE_HTTP_STATELESS"); val != "" {
 		config.StreamableHTTPStateless = strings.ToLower(val) == "true"
 	}
 
-	// STDIO configuration from environment variables
-	if val := os.Getenv("ENABLE_STDIO"); val != "" {
+	// WebSocket configuration from the settings
+	if val := settings.Get("ENABLE_WEBSOCKET"); val != "" {
+		config.EnableWebSocket = strings.ToLower(val) == "true"
+	}
+
+	if val := settings.Get("WEBSOCKET_ENDPOINT"); val != "" {
+		config.WebSocketEndpoint = val
+	}
+
+	// STDIO configuration from the settings
+	if val := settings.Get("ENABLE_STDIO"); val != "" {
 		config.EnableSTDIO = strings.ToLower(val) == "true"
 	}
 
-	if val := os.Getenv("STDIO_ERROR_LOG"); val != "" {
+	if val := settings.Get("STDIO_ERROR_LOG"); val != "" {
 		config.STDIOErrorLog = strings.ToLower(val) == "true"
 	}
 
-	// Server configuration from environment variables
-	if val := os.Getenv("SERVER_READ_TIMEOUT"); val != "" {
+	// REST API configuration from the settings
+	if val := settings.Get("ENABLE_REST_API"); val != "" {
+		config.EnableREST = strings.ToLower(val) == "true"
+	}
+
+	// Admin API configuration from the settings
+	config.AdminToken = strings.TrimSpace(settings.Get("ADMIN_TOKEN"))
+	if val := settings.Get("BACKUP_DIR"); val != "" {
+		config.BackupDir = val
+	}
+
+	// Application scoping from the settings
+	config.ApplicationScope = strings.TrimSpace(settings.Get("APPLICATION_SCOPE"))
+	if val := settings.Get("APPLICATION_TOKENS"); val != "" {
+		config.ApplicationTokens = parseApplicationTokens(val)
+	}
+
+	// Roles from the settings
+	if val := settings.Get("ROLES"); val != "" {
+		config.Roles = parseRoles(val)
+	}
+	if val := settings.Get("TOKEN_ROLES"); val != "" {
+		config.TokenRoles = parseTokenRoles(val)
+	}
+	config.DefaultRole = strings.TrimSpace(settings.Get("DEFAULT_ROLE"))
+
+	// Read-only mode from the settings
+	if val := settings.Get("READ_ONLY_MODE"); val != "" {
+		config.ReadOnly = strings.ToLower(val) == "true"
+	}
+	if val := settings.Get("CONFIRM_DESTRUCTIVE_TOOLS"); val != "" {
+		config.ConfirmDestructiveTools = strings.ToLower(val) == "true"
+	}
+
+	// Web UI configuration from the settings
+	if val := settings.Get("ENABLE_WEB_UI"); val != "" {
+		config.EnableWebUI = strings.ToLower(val) == "true"
+	}
+
+	if val := settings.Get("WEB_UI_POLL_INTERVAL"); val != "" {
+		if interval, err := strconv.Atoi(val); err == nil && interval > 0 {
+			config.WebUIPollInterval = interval
+		}
+	}
+
+	// Server configuration from the settings
+	if val := settings.Get("SERVER_READ_TIMEOUT"); val != "" {
 		if timeout, err := strconv.Atoi(val); err == nil && timeout > 0 {
 			config.ServerReadTimeout = timeout
 		}
 	}
 
-	if val := os.Getenv("SERVER_WRITE_TIMEOUT"); val != "" {
+	if val := settings.Get("SERVER_WRITE_TIMEOUT"); val != "" {
 		if timeout, err := strconv.Atoi(val); err == nil && timeout > 0 {
 			config.ServerWriteTimeout = timeout
 		}
 	}
 
+	if val := settings.Get("TOOL_TIMEOUT"); val != "" {
+		if timeout, err := strconv.Atoi(val); err == nil && timeout > 0 {
+			config.ToolTimeout = timeout
+		}
+	}
+
+	if val := settings.Get("MAX_RESOURCE_SIZE"); val != "" {
+		if size, err := strconv.Atoi(val); err == nil && size >= 0 {
+			config.MaxResourceSize = size
+		}
+	}
+
+	// Rate limit configuration from the settings
+	if val := settings.Get("RATE_LIMIT_PER_SECOND"); val != "" {
+		if rate, err := strconv.ParseFloat(val, 64); err == nil && rate >= 0 {
+			config.RateLimitPerSecond = rate
+		}
+	}
+
+	if val := settings.Get("RATE_LIMIT_BURST"); val != "" {
+		if burst, err := strconv.Atoi(val); err == nil && burst > 0 {
+			config.RateLimitBurst = burst
+		}
+	}
+
+	if val := settings.Get("RATE_LIMIT_EXPENSIVE_PER_SECOND"); val != "" {
+		if rate, err := strconv.ParseFloat(val, 64); err == nil && rate >= 0 {
+			config.ExpensiveRateLimitPerSecond = rate
+		}
+	}
+
+	if val := settings.Get("RATE_LIMIT_EXPENSIVE_BURST"); val != "" {
+		if burst, err := strconv.Atoi(val); err == nil && burst > 0 {
+			config.ExpensiveRateLimitBurst = burst
+		}
+	}
+
+	// TLS configuration from the settings
+	config.TLSCertFile = settings.Get("TLS_CERT_FILE")
+	config.TLSKeyFile = settings.Get("TLS_KEY_FILE")
+
+	if val := settings.Get("TLS_AUTOCERT_DOMAINS"); val != "" {
+		for _, domain := range strings.Split(val, ",") {
+			if domain = strings.TrimSpace(domain); domain != "" {
+				config.TLSAutocertDomains = append(config.TLSAutocertDomains, domain)
+			}
+		}
+	}
+
+	if val := settings.Get("TLS_AUTOCERT_CACHE_DIR"); val != "" {
+		config.TLSAutocertCacheDir = val
+	}
+
+	config.TLSAutocertEmail = settings.Get("TLS_AUTOCERT_EMAIL")
+
+	// Localization from the settings
+	config.Language = settings.Get("LANG")
+
 	log.Printf("Server configuration: %+v", config)
 
 	return config
@@ -182,6 +624,12 @@ func (s *MCPGoServer) transportSelectionHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	// WebSocket clients cannot follow redirects, so upgrades of the root path are served directly
+	if s.config.EnableWebSocket && strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		s.websocketServer().ServeHTTP(w, r)
+		return
+	}
+
 	// Check content-type header for transport selection
 	contentType := r.Header.Get("Content-Type")
 
@@ -211,6 +659,18 @@ func (s *MCPGoServer) transportSelectionHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	// If only WebSocket is enabled, show where to connect
+	if s.config.EnableWebSocket {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":     "ok",
+			"message":    fmt.Sprintf("This server accepts MCP over WebSocket at %s.", s.config.WebSocketEndpoint),
+			"transports": []string{"websocket"},
+		})
+		return
+	}
+
 	// If only STDIO is enabled, show information about it
 	if stdioEnabled {
 		w.Header().Set("Content-Type", "application/json")
@@ -229,6 +689,15 @@ func (s *MCPGoServer) transportSelectionHandler(w http.ResponseWriter, r *http.R
 	json.NewEncoder(w).Encode(map[string]string{"error": "No transport protocols are enabled on this server"})
 }
 
+// addResourceTemplate registers a resource template, applying the rate limits, the timeout, the application
+// scope and the resource cache to its reads
+func (s *MCPGoServer) addResourceTemplate(template mcp.ResourceTemplate, handler server.ResourceTemplateHandlerFunc) {
+	uriTemplate := template.URITemplate.Raw()
+	handler = s.timeoutResourceHandler(s.scopeResourceHandler(s.cacheResourceHandler(uriTemplate, handler)))
+	handler = s.rateLimitResourceHandler(uriTemplate, handler)
+	s.server.AddResourceTemplate(template, handler)
+}
+
 // GetConfig returns the current server configuration
 func (s *MCPGoServer) GetConfig() ServerConfig {
 	return s.config
@@ -239,8 +708,8 @@ func (s *MCPGoServer) Start(port int) error {
 	log.Printf("Starting MCP server on port %d", port)
 
 	// Check if at least one transport is enabled
-	if !s.config.EnableSSE && !s.config.EnableStreamableHTTP && !s.config.EnableSTDIO {
-		return fmt.Errorf("no transport protocols enabled, enable at least one of SSE, Streamable HTTP, or STDIO")
+	if !s.config.EnableSSE && !s.config.EnableStreamableHTTP && !s.config.EnableWebSocket && !s.config.EnableSTDIO {
+		return fmt.Errorf("no transport protocols enabled, enable at least one of SSE, Streamable HTTP, WebSocket, or STDIO")
 	}
 
 	// If STDIO is enabled, handle it separately as it's not compatible with HTTP server
@@ -248,7 +717,7 @@ func (s *MCPGoServer) Start(port int) error {
 		log.Printf("Enabling STDIO transport")
 
 		// Only run STDIO if it's the only transport enabled
-		if !s.config.EnableSSE && !s.config.EnableStreamableHTTP {
+		if !s.config.EnableSSE && !s.config.EnableStreamableHTTP && !s.config.EnableWebSocket {
 			// Configure STDIO options
 			var stdioOptions []server.StdioOption
 
@@ -273,6 +742,7 @@ func (s *MCPGoServer) Start(port int) error {
 		sseOptions := []server.SSEOption{
 			server.WithSSEEndpoint(s.config.SSEEndpoint),
 			server.WithKeepAlive(s.config.SSEKeepAlive),
+			server.WithSSEContextFunc(withClientIdentity),
 		}
 
 		// Add keep-alive interval if keep-alive is enabled
@@ -284,6 +754,8 @@ func (s *MCPGoServer) Start(port int) error {
 
 		sseServer := server.NewSSEServer(s.server, sseOptions...)
 		mux.Handle(s.config.SSEEndpoint, sseServer)
+		// Clients post their messages to the message endpoint announced on the event stream
+		mux.Handle(sseServer.CompleteMessagePath(), sseServer)
 	}
 
 	// Configure Streamable HTTP transport if enabled
@@ -294,6 +766,7 @@ func (s *MCPGoServer) Start(port int) error {
 		streamableOptions := []server.StreamableHTTPOption{
 			server.WithEndpointPath(s.config.StreamableHTTPEndpoint),
 			server.WithStateLess(s.config.StreamableHTTPStateless),
+			server.WithHTTPContextFunc(withClientIdentity),
 		}
 
 		// Add heartbeat interval if configured
@@ -307,6 +780,40 @@ func (s *MCPGoServer) Start(port int) error {
 		mux.Handle(s.config.StreamableHTTPEndpoint, streamableServer)
 	}
 
+	// Configure WebSocket transport if enabled
+	if s.config.EnableWebSocket {
+		log.Printf("Enabling WebSocket transport at endpoint: %s", s.config.WebSocketEndpoint)
+		mux.Handle(s.config.WebSocketEndpoint, s.websocketServer())
+	}
+
+	// Serve the REST API if enabled
+	if s.config.EnableREST {
+		log.Printf("Enabling REST API at endpoint: %s", api.BasePath)
+		restHandler := api.NewHandler(s.planRepo, s.taskRepo)
+		s.restHandler.Store(restHandler)
+		restHandler.SetReadOnly(s.readOnly.Load())
+		restHandler.SetAuthorizer(s.authorizeREST)
+		mux.Handle(api.BasePath+"/", restHandler)
+	}
+
+	// Serve the admin API if a token is configured
+	if s.config.AdminToken != "" && s.adminIntegrity != nil {
+		log.Printf("Enabling admin API at endpoint: %s", api.AdminBasePath)
+		mux.Handle(api.AdminBasePath+"/", api.NewAdminHandler(s.planRepo, s.taskRepo, api.AdminConfig{
+			Token:     s.config.AdminToken,
+			Integrity: s.adminIntegrity,
+			Retention: s.retention,
+			BackupDir: s.config.BackupDir,
+		}))
+	}
+
+	// Serve the web dashboard if enabled
+	if s.config.EnableWebUI {
+		log.Printf("Enabling web UI at endpoint: %s", ui.BasePath)
+		pollInterval := time.Duration(s.config.WebUIPollInterval) * time.Second
+		mux.Handle(ui.BasePath+"/", ui.NewHandler(s.planRepo, s.taskRepo, pollInterval))
+	}
+
 	// Add a simple health check endpoint
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -320,10 +827,44 @@ func (s *MCPGoServer) Start(port int) error {
 	// Create and start the HTTP server with timeouts
 	httpServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", port),
-		Handler:      mux,
+		Handler:      s.scopeHandler(mux),
 		ReadTimeout:  time.Duration(s.config.ServerReadTimeout) * time.Second,
 		WriteTimeout: time.Duration(s.config.ServerWriteTimeout) * time.Second,
 	}
+	s.httpServer.Store(httpServer)
+
+	// Terminate TLS directly if configured, so no reverse proxy is needed outside localhost
+	var serveErr error
+	if s.config.TLSEnabled() {
+		tlsConfig, err := tlsConfig(s.config)
+		if err != nil {
+			return err
+		}
+		httpServer.TLSConfig = tlsConfig
 
-	return httpServer.ListenAndServe()
+		// The certificate is already part of the TLS configuration
+		serveErr = httpServer.ListenAndServeTLS("", "")
+	} else {
+		serveErr = httpServer.ListenAndServe()
+	}
+
+	// Shutdown closes the server on purpose
+	if errors.Is(serveErr, http.ErrServerClosed) {
+		return nil
+	}
+	return serveErr
+}
+
+// Shutdown stops the HTTP server started by Start, waiting for active requests until the context is done.
+// Streaming connections such as SSE sessions are closed when it is. The STDIO transport stops when its input
+// is closed.
+func (s *MCPGoServer) Shutdown(ctx context.Context) error {
+	httpServer := s.httpServer.Load()
+	if httpServer == nil {
+		return nil
+	}
+	if err := httpServer.Shutdown(ctx); err != nil {
+		return httpServer.Close()
+	}
+	return nil
 }
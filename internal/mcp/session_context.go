@@ -0,0 +1,208 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// sessionContextIdleTimeout is how long the context of a session without tool calls is kept. The Streamable
+// HTTP transport does not report ended sessions, so contexts are also dropped when they are not used.
+const sessionContextIdleTimeout = 24 * time.Hour
+
+// sessionDefaultParams are the tool arguments filled in from the session context when a call leaves them out
+var sessionDefaultParams = []string{"application_id", "plan_id"}
+
+// SessionContext is the current application and plan of a client session, used by tool calls that leave out
+// their application_id or plan_id
+type SessionContext struct {
+	ApplicationID string `json:"application_id,omitempty"`
+	PlanID        string `json:"plan_id,omitempty"`
+}
+
+// sessionContextEntry is the context of a session with the time it was last used
+type sessionContextEntry struct {
+	context  SessionContext
+	lastUsed time.Time
+}
+
+// sessionContexts holds the contexts of the client sessions
+type sessionContexts struct {
+	mu        sync.Mutex
+	sessions  map[string]*sessionContextEntry
+	lastSweep time.Time
+}
+
+// newSessionContexts creates an empty store of session contexts
+func newSessionContexts() *sessionContexts {
+	return &sessionContexts{sessions: make(map[string]*sessionContextEntry)}
+}
+
+// get returns the context of a session
+func (c *sessionContexts) get(sessionID string) SessionContext {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.sessions[sessionID]
+	if !ok {
+		return SessionContext{}
+	}
+	entry.lastUsed = time.Now()
+	return entry.context
+}
+
+// set replaces the context of a session, dropping it when it is empty
+func (c *sessionContexts) set(sessionID string, current SessionContext) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastSweep) >= sessionContextIdleTimeout {
+		c.lastSweep = now
+		maps.DeleteFunc(c.sessions, func(_ string, entry *sessionContextEntry) bool {
+			return now.Sub(entry.lastUsed) >= sessionContextIdleTimeout
+		})
+	}
+
+	if current == (SessionContext{}) {
+		delete(c.sessions, sessionID)
+		return
+	}
+	c.sessions[sessionID] = &sessionContextEntry{context: current, lastUsed: now}
+}
+
+// delete drops the context of an ended session
+func (c *sessionContexts) delete(sessionID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.sessions, sessionID)
+}
+
+// sessionID returns the ID of the client session of a tool call, or an empty ID without a session
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+// sessionHooks drops the context of sessions when they end
+func (s *MCPGoServer) sessionHooks() *server.Hooks {
+	hooks := &server.Hooks{}
+	hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+		s.sessionContexts.delete(session.SessionID())
+	})
+	return hooks
+}
+
+// sessionDefaultsMiddleware fills in the application_id and plan_id arguments a tool call leaves out from the
+// context of its session, for the tools that take them other than set_session_context itself
+func (s *MCPGoServer) sessionDefaultsMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id := sessionID(ctx)
+		if id == "" || request.Params.Name == "set_session_context" {
+			return next(ctx, request)
+		}
+		current := s.sessionContexts.get(id)
+		if current == (SessionContext{}) {
+			return next(ctx, request)
+		}
+
+		// Arguments that are not an object are left for the schema validation to reject
+		arguments := maps.Clone(request.GetArguments())
+		if arguments == nil {
+			if request.Params.Arguments != nil {
+				return next(ctx, request)
+			}
+			arguments = make(map[string]any)
+		}
+		params := s.toolParams[request.Params.Name]
+		for _, name := range sessionDefaultParams {
+			value := current.PlanID
+			if name == "application_id" {
+				value = current.ApplicationID
+			}
+			if arguments[name] != nil || value == "" || !params[name] {
+				continue
+			}
+			arguments[name] = value
+		}
+		request.Params.Arguments = arguments
+		return next(ctx, request)
+	}
+}
+
+func (s *MCPGoServer) registerSessionContextTools() {
+	s.registerSetSessionContextTool()
+	s.registerGetSessionContextTool()
+}
+
+func (s *MCPGoServer) registerSetSessionContextTool() {
+	// Setting the session context does not change stored data, so it is available in read-only mode
+	tool := mcp.NewTool("set_session_context",
+		readOnlyTool,
+		mcp.WithDescription(
+			"Set the current application and plan of this session. Later tool calls in the session that leave out "+
+				"application_id or plan_id use these values. Setting a plan also sets its application; leaving "+
+				"out both clears the context",
+		),
+		mcp.WithString("application_id",
+			mcp.Description("Current application ID (optional)"),
+		),
+		mcp.WithString("plan_id",
+			mcp.Description("Current plan ID (optional)"),
+		),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id := sessionID(ctx)
+		if id == "" {
+			return s.validationError("the session context requires a client session"), nil
+		}
+
+		current := SessionContext{
+			ApplicationID: request.GetString("application_id", ""),
+			PlanID:        request.GetString("plan_id", ""),
+		}
+		if current.PlanID != "" {
+			plan, err := s.planRepo.Get(ctx, current.PlanID)
+			if err != nil {
+				return s.toolError("Failed to get plan", err), nil
+			}
+			if current.ApplicationID != "" && current.ApplicationID != plan.ApplicationID {
+				return s.invalidArgument(models.NewValidationError(models.EntityPlan, plan.ID,
+					"plan %s belongs to application %s, not %s", plan.ID, plan.ApplicationID, current.ApplicationID)), nil
+			}
+			current.ApplicationID = plan.ApplicationID
+		}
+		s.sessionContexts.set(id, current)
+
+		return s.marshalSessionContext(current)
+	})
+}
+
+func (s *MCPGoServer) registerGetSessionContextTool() {
+	tool := mcp.NewTool("get_session_context",
+		readOnlyTool,
+		mcp.WithDescription("Get the current application and plan of this session"),
+	)
+
+	s.addTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return s.marshalSessionContext(s.sessionContexts.get(sessionID(ctx)))
+	})
+}
+
+// marshalSessionContext returns a session context as the result of a tool call
+func (s *MCPGoServer) marshalSessionContext(current SessionContext) (*mcp.CallToolResult, error) {
+	contextJson, err := json.Marshal(current)
+	if err != nil {
+		return s.toolError("Failed to marshal session context", err), nil
+	}
+	return mcp.NewToolResultText(string(contextJson)), nil
+}
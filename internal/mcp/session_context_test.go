@@ -0,0 +1,104 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// callSessionTool calls a tool in a client session
+func callSessionTool(
+	t *testing.T, s *MCPGoServer, session *testSession, name string, args map[string]any,
+) *mcp.CallToolResult {
+	t.Helper()
+	message, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params":  map[string]any{"name": name, "arguments": args},
+	})
+	if err != nil {
+		t.Fatalf("failed to encode call: %v", err)
+	}
+	ctx := s.server.WithContext(context.Background(), session)
+	response, ok := s.server.HandleMessage(ctx, message).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("%s failed", name)
+	}
+	result, ok := response.Result.(mcp.CallToolResult)
+	if !ok {
+		t.Fatalf("%s returned %T", name, response.Result)
+	}
+	return &result
+}
+
+func TestSessionContext(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	plan, err := s.planRepo.Create(ctx, "app-1", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	other, err := s.planRepo.Create(ctx, "app-1", "Other plan", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	if _, err := s.taskRepo.Create(ctx, plan.ID, "Task", "", models.TaskPriorityMedium); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	// Without a session there is no context to set
+	if result := callTool(t, s, "set_session_context", map[string]any{"plan_id": plan.ID}); !result.IsError {
+		t.Error("expected set_session_context without a session to fail")
+	}
+
+	session := &testSession{notifications: make(chan mcp.JSONRPCNotification, 1)}
+	result := callSessionTool(t, s, session, "set_session_context", map[string]any{"plan_id": plan.ID})
+	var current SessionContext
+	if err := json.Unmarshal([]byte(toolResultText(result)), &current); err != nil {
+		t.Fatalf("failed to decode result %s: %v", toolResultText(result), err)
+	}
+	if current != (SessionContext{ApplicationID: "app-1", PlanID: plan.ID}) {
+		t.Errorf("unexpected session context: %+v", current)
+	}
+
+	// Calls that leave out the plan use the plan of the session
+	result = callSessionTool(t, s, session, "list_tasks_by_plan", map[string]any{})
+	var tasks []*models.Task
+	if err := json.Unmarshal([]byte(toolResultText(result)), &tasks); err != nil {
+		t.Fatalf("failed to decode result %s: %v", toolResultText(result), err)
+	}
+	if len(tasks) != 1 || tasks[0].Title != "Task" {
+		t.Errorf("unexpected tasks of the session plan: %+v", tasks)
+	}
+
+	// Arguments given in the call win over the context
+	result = callSessionTool(t, s, session, "list_tasks_by_plan", map[string]any{"plan_id": other.ID})
+	tasks = nil
+	if err := json.Unmarshal([]byte(toolResultText(result)), &tasks); err != nil {
+		t.Fatalf("failed to decode result %s: %v", toolResultText(result), err)
+	}
+	if len(tasks) != 0 {
+		t.Errorf("expected no tasks in the other plan, got %+v", tasks)
+	}
+
+	result = callSessionTool(t, s, session, "set_session_context", map[string]any{"application_id": "app-2", "plan_id": plan.ID})
+	if !result.IsError {
+		t.Error("expected a plan of another application to be rejected")
+	}
+
+	// Leaving out both arguments clears the context
+	callSessionTool(t, s, session, "set_session_context", map[string]any{})
+	result = callSessionTool(t, s, session, "get_session_context", map[string]any{})
+	if text := toolResultText(result); text != "{}" {
+		t.Errorf("expected a cleared session context, got %s", text)
+	}
+	if result := callSessionTool(t, s, session, "list_tasks_by_plan", map[string]any{}); !result.IsError {
+		t.Error("expected list_tasks_by_plan without a plan to fail")
+	}
+}
@@ -0,0 +1,151 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// URI patterns for task resources
+var (
+	// Pattern for a single task: ai-tasks://tasks/{id}
+	singleTaskPattern = regexp.MustCompile(`ai-tasks://tasks/([^/]+)$`)
+
+	// Pattern for the tasks of a plan: ai-tasks://plans/{id}/tasks
+	planTasksPattern = regexp.MustCompile(`ai-tasks://plans/([^/]+)/tasks$`)
+)
+
+// TaskResourceProvider implements the MCP resource provider for single tasks and the task lists of plans.
+// Every task has its own URI, so clients can read and follow a single task without its plan.
+type TaskResourceProvider struct {
+	planRepo storage.PlanRepositoryInterface
+	taskRepo storage.TaskRepositoryInterface
+}
+
+// NewTaskResourceProvider creates a new TaskResourceProvider
+func NewTaskResourceProvider(
+	planRepo storage.PlanRepositoryInterface,
+	taskRepo storage.TaskRepositoryInterface,
+) *TaskResourceProvider {
+	return &TaskResourceProvider{
+		planRepo: planRepo,
+		taskRepo: taskRepo,
+	}
+}
+
+// RegisterResource registers the task resources with the MCP server
+func (p *TaskResourceProvider) RegisterResource(server *MCPGoServer) {
+	taskTemplate := mcp.NewResourceTemplate(
+		"ai-tasks://tasks/{id}",
+		"Task Resource",
+		mcp.WithTemplateDescription("Returns a single task with its notes, metadata and checklist"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+
+	planTasksTemplate := mcp.NewResourceTemplate(
+		"ai-tasks://plans/{id}/tasks",
+		"Plan Tasks Resource",
+		mcp.WithTemplateDescription("Returns the tasks of a plan in their order"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+
+	server.addResourceTemplate(taskTemplate, p.handleTaskRequest)
+	server.addResourceTemplate(planTasksTemplate, p.handlePlanTasksRequest)
+}
+
+// handleTaskRequest handles requests for a single task
+func (p *TaskResourceProvider) handleTaskRequest(
+	ctx context.Context,
+	req mcp.ReadResourceRequest,
+) ([]mcp.ResourceContents, error) {
+	matches := singleTaskPattern.FindStringSubmatch(req.Params.URI)
+	if len(matches) != 2 {
+		return nil, fmt.Errorf(
+			"%w: '%s' does not match the expected format 'ai-tasks://tasks/{id}'",
+			ErrInvalidURI,
+			req.Params.URI,
+		)
+	}
+
+	taskID := matches[1]
+	if strings.TrimSpace(taskID) == "" {
+		return nil, fmt.Errorf("%w: empty task ID", ErrInvalidTaskID)
+	}
+
+	task, err := p.taskRepo.Get(ctx, taskID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: task with ID '%s' does not exist", ErrTaskNotFound, taskID)
+		}
+		return nil, fmt.Errorf("%w: failed to get task with ID '%s': %v", ErrInternalStorage, taskID, err)
+	}
+
+	jsonData, err := json.MarshalIndent(task, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to marshal task '%s': %v", ErrMarshalFailure, taskID, err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      fmt.Sprintf("ai-tasks://tasks/%s", taskID),
+			MIMEType: "application/json",
+			Text:     string(jsonData),
+		},
+	}, nil
+}
+
+// handlePlanTasksRequest handles requests for the tasks of a plan
+func (p *TaskResourceProvider) handlePlanTasksRequest(
+	ctx context.Context,
+	req mcp.ReadResourceRequest,
+) ([]mcp.ResourceContents, error) {
+	matches := planTasksPattern.FindStringSubmatch(req.Params.URI)
+	if len(matches) != 2 {
+		return nil, fmt.Errorf(
+			"%w: '%s' does not match the expected format 'ai-tasks://plans/{id}/tasks'",
+			ErrInvalidURI,
+			req.Params.URI,
+		)
+	}
+
+	planID := matches[1]
+	if strings.TrimSpace(planID) == "" {
+		return nil, fmt.Errorf("%w: empty plan ID", ErrInvalidPlanID)
+	}
+
+	// An unknown plan is an error, a plan without tasks is an empty list
+	if _, err := p.planRepo.Get(ctx, planID); err != nil {
+		if models.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: plan with ID '%s' does not exist", ErrPlanNotFound, planID)
+		}
+		return nil, fmt.Errorf("%w: failed to get plan with ID '%s': %v", ErrInternalStorage, planID, err)
+	}
+
+	tasks, err := p.taskRepo.ListByPlan(ctx, planID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get tasks for plan '%s': %v", ErrInternalStorage, planID, err)
+	}
+
+	jsonData := []byte("[]")
+	if len(tasks) > 0 {
+		jsonData, err = json.MarshalIndent(tasks, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to marshal tasks of plan '%s': %v", ErrMarshalFailure, planID, err)
+		}
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      fmt.Sprintf("ai-tasks://plans/%s/tasks", planID),
+			MIMEType: "application/json",
+			Text:     string(jsonData),
+		},
+	}, nil
+}
@@ -0,0 +1,85 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
+)
+
+// Pattern for the event timeline of a plan: ai-tasks://plans/{id}/timeline
+var planTimelinePattern = regexp.MustCompile(`ai-tasks://plans/([^/]+)/timeline$`)
+
+// TimelineResourceProvider implements the MCP resource provider for the event timelines of plans
+type TimelineResourceProvider struct {
+	timeline *services.TimelineService
+}
+
+// NewTimelineResourceProvider creates a new TimelineResourceProvider
+func NewTimelineResourceProvider(timeline *services.TimelineService) *TimelineResourceProvider {
+	return &TimelineResourceProvider{
+		timeline: timeline,
+	}
+}
+
+// RegisterResource registers the plan timeline resource with the MCP server
+func (p *TimelineResourceProvider) RegisterResource(server *MCPGoServer) {
+	timelineTemplate := mcp.NewResourceTemplate(
+		"ai-tasks://plans/{id}/timeline",
+		"Plan Timeline Resource",
+		mcp.WithTemplateDescription(
+			"Returns a chronological feed of the events of a plan and its tasks, such as tasks created, "+
+				"status changes, notes updates and comments added, to reconstruct what happened during the plan",
+		),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+
+	server.addResourceTemplate(timelineTemplate, p.handleTimelineRequest)
+}
+
+// handleTimelineRequest handles requests for the plan timeline resource
+func (p *TimelineResourceProvider) handleTimelineRequest(
+	ctx context.Context,
+	req mcp.ReadResourceRequest,
+) ([]mcp.ResourceContents, error) {
+	matches := planTimelinePattern.FindStringSubmatch(req.Params.URI)
+	if len(matches) != 2 {
+		return nil, fmt.Errorf(
+			"%w: '%s' does not match the expected format 'ai-tasks://plans/{id}/timeline'",
+			ErrInvalidURI,
+			req.Params.URI,
+		)
+	}
+
+	planID := matches[1]
+	if strings.TrimSpace(planID) == "" {
+		return nil, fmt.Errorf("%w: empty plan ID", ErrInvalidPlanID)
+	}
+
+	events, err := p.timeline.GetPlanTimeline(ctx, planID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: plan with ID '%s' does not exist", ErrPlanNotFound, planID)
+		}
+		return nil, fmt.Errorf("%w: failed to get timeline of plan '%s': %v", ErrInternalStorage, planID, err)
+	}
+
+	eventsJson, err := json.Marshal(events)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to marshal timeline of plan '%s': %v", ErrMarshalFailure, planID, err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      fmt.Sprintf("ai-tasks://plans/%s/timeline", planID),
+			MIMEType: "application/json",
+			Text:     string(eventsJson),
+		},
+	}, nil
+}
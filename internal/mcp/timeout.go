@@ -0,0 +1,66 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// Tool calls and resource reads run with the context of the request, which the SSE transport detaches from the
+// connection, so a hung Valkey call could hold a session forever. Every call gets a deadline instead; Valkey
+// calls and integrations give up when it passes and the call fails with a TIMEOUT error.
+
+// setToolTimeout sets the maximum duration of tool calls and resource reads in seconds
+func (s *MCPGoServer) setToolTimeout(seconds int) {
+	s.toolTimeout.Store(int64(time.Duration(seconds) * time.Second))
+}
+
+// withToolTimeout returns a context that is done when the tool timeout passes
+func (s *MCPGoServer) withToolTimeout(ctx context.Context) (context.Context, context.CancelFunc, time.Duration) {
+	timeout := time.Duration(s.toolTimeout.Load())
+	if timeout <= 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, 0
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, timeout
+}
+
+// timeoutError returns the error of a call that did not finish within the timeout
+func timeoutError(timeout time.Duration) error {
+	return &models.Error{Code: models.ErrorCodeTimeout, Message: fmt.Sprintf("request timed out after %s", timeout)}
+}
+
+// timeoutMiddleware fails tool calls that do not finish within the tool timeout
+func (s *MCPGoServer) timeoutMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, cancel, timeout := s.withToolTimeout(ctx)
+		defer cancel()
+
+		result, err := next(ctx, request)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && (err != nil || result == nil || result.IsError) {
+			return s.toolError("", timeoutError(timeout)), nil
+		}
+		return result, err
+	}
+}
+
+// timeoutResourceHandler wraps the handler of a resource template so reads fail after the tool timeout
+func (s *MCPGoServer) timeoutResourceHandler(next server.ResourceTemplateHandlerFunc) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		ctx, cancel, timeout := s.withToolTimeout(ctx)
+		defer cancel()
+
+		contents, err := next(ctx, request)
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, timeoutError(timeout)
+		}
+		return contents, err
+	}
+}
@@ -0,0 +1,46 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	s := newTestServer(t)
+	if got := time.Duration(s.toolTimeout.Load()); got != time.Minute {
+		t.Errorf("default tool timeout = %s, want 1m", got)
+	}
+	s.toolTimeout.Store(int64(10 * time.Millisecond))
+
+	hung := s.timeoutMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		<-ctx.Done()
+		return s.toolError("Failed to get plan", ctx.Err()), nil
+	})
+	result, err := hung(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError || !strings.Contains(toolResultText(result), `"code":"TIMEOUT"`) {
+		t.Errorf("expected a TIMEOUT error, got %s", toolResultText(result))
+	}
+
+	fast := s.timeoutMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("tool calls should have a deadline")
+		}
+		return mcp.NewToolResultText("ok"), nil
+	})
+	if result, _ := fast(context.Background(), mcp.CallToolRequest{}); result.IsError {
+		t.Errorf("calls finishing in time should succeed, got %s", toolResultText(result))
+	}
+
+	t.Setenv("TOOL_TIMEOUT", "5")
+	s.Reload()
+	if got := time.Duration(s.toolTimeout.Load()); got != 5*time.Second {
+		t.Errorf("tool timeout after reload = %s, want 5s", got)
+	}
+}
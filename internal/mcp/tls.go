@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// TLSEnabled reports whether the HTTP server terminates TLS, either with a certificate and key
+// or with certificates obtained automatically over ACME
+func (c ServerConfig) TLSEnabled() bool {
+	return c.TLSCertFile != "" || c.TLSKeyFile != "" || len(c.TLSAutocertDomains) > 0
+}
+
+// tlsConfig builds the TLS configuration of the HTTP server.
+// Only TLS 1.2 and later with forward-secret AEAD cipher suites are accepted; TLS 1.3 suites are not
+// configurable in Go and are always secure.
+func tlsConfig(config ServerConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:       tls.VersionTLS12,
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+	}
+
+	if len(config.TLSAutocertDomains) > 0 {
+		if config.TLSCertFile != "" || config.TLSKeyFile != "" {
+			return nil, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE cannot be combined with TLS_AUTOCERT_DOMAINS")
+		}
+
+		// Certificates are requested with the TLS-ALPN-01 challenge, which is answered on the TLS port itself
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(config.TLSAutocertDomains...),
+			Cache:      autocert.DirCache(config.TLSAutocertCacheDir),
+			Email:      config.TLSAutocertEmail,
+		}
+		tlsConfig.GetCertificate = manager.GetCertificate
+		tlsConfig.NextProtos = append(tlsConfig.NextProtos, "h2", "http/1.1", "acme-tls/1")
+		log.Printf("Enabling TLS with automatic certificates for: %v", config.TLSAutocertDomains)
+		return tlsConfig, nil
+	}
+
+	if config.TLSCertFile == "" || config.TLSKeyFile == "" {
+		return nil, fmt.Errorf("both TLS_CERT_FILE and TLS_KEY_FILE must be set to enable TLS")
+	}
+
+	certificate, err := tls.LoadX509KeyPair(config.TLSCertFile, config.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	tlsConfig.Certificates = []tls.Certificate{certificate}
+	log.Printf("Enabling TLS with certificate: %s", config.TLSCertFile)
+	return tlsConfig, nil
+}
@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Annotations of the tools, telling clients how a tool affects the data, so they can ask for confirmation
+// before destructive calls and retry idempotent ones. Every tool is registered with one of them.
+var (
+	// readOnlyTool marks tools that only read data
+	readOnlyTool = toolAnnotation(true, false, true)
+	// createTool marks tools that add data without changing what exists, so a repeated call adds it again
+	createTool = toolAnnotation(false, false, false)
+	// updateTool marks tools that set data to the given values, so a repeated call has no further effect
+	updateTool = toolAnnotation(false, true, true)
+	// changeTool marks tools that change data relative to its current state, such as toggling an item
+	changeTool = toolAnnotation(false, true, false)
+	// deleteTool marks tools that remove data
+	deleteTool = toolAnnotation(false, true, true)
+)
+
+// externalTool marks tools that also read or change data in an external system such as GitHub or Jira.
+// It has to follow the annotation of the tool.
+var externalTool = mcp.WithOpenWorldHintAnnotation(true)
+
+// toolAnnotation returns the annotation of tools that only work on the data of this server
+func toolAnnotation(readOnly, destructive, idempotent bool) mcp.ToolOption {
+	return mcp.WithToolAnnotation(mcp.ToolAnnotation{
+		ReadOnlyHint:    mcp.ToBoolPtr(readOnly),
+		DestructiveHint: mcp.ToBoolPtr(destructive),
+		IdempotentHint:  mcp.ToBoolPtr(idempotent),
+		OpenWorldHint:   mcp.ToBoolPtr(false),
+	})
+}
+
+// addTool registers a tool with the MCP server, validating the arguments of its calls against its input schema.
+// Descriptions are translated into the language of the server. In read-only mode, tools that change data are
+// left out until a reload turns the mode off.
+func (s *MCPGoServer) addTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	tool = s.localizeTool(tool)
+	handler = s.validateArguments(tool, handler)
+	s.tools = append(s.tools, tool)
+	s.toolGroups[tool.Name] = toolGroup(tool)
+	s.toolParams[tool.Name] = make(map[string]bool, len(tool.InputSchema.Properties))
+	for name := range tool.InputSchema.Properties {
+		s.toolParams[tool.Name][name] = true
+	}
+
+	if tool.Annotations.ReadOnlyHint == nil || !*tool.Annotations.ReadOnlyHint {
+		s.writeTools = append(s.writeTools, server.ServerTool{Tool: tool, Handler: handler})
+		if s.readOnly.Load() {
+			return
+		}
+	}
+	s.server.AddTool(tool, handler)
+}
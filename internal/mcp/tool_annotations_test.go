@@ -0,0 +1,49 @@
+package mcp
+
+import "testing"
+
+func TestToolAnnotations(t *testing.T) {
+	tools := serverTools(t, newTestServer(t))
+	if len(tools) == 0 {
+		t.Fatal("no tools registered")
+	}
+	for name, tool := range tools {
+		// Tools built without an annotation keep the open world default of the library
+		if tool.Annotations.OpenWorldHint == nil || *tool.Annotations.OpenWorldHint {
+			t.Errorf("%s has no annotation", name)
+		}
+	}
+
+	hints := func(name string) (bool, bool) {
+		tool := tools[name]
+		return *tool.Annotations.ReadOnlyHint, *tool.Annotations.DestructiveHint
+	}
+	if readOnly, destructive := hints("get_plan"); !readOnly || destructive {
+		t.Error("get_plan should be read-only")
+	}
+	if readOnly, destructive := hints("create_task"); readOnly || destructive {
+		t.Error("create_task should add data without destroying any")
+	}
+	if readOnly, destructive := hints("delete_plan"); readOnly || !destructive {
+		t.Error("delete_plan should be destructive")
+	}
+}
+
+func TestReadOnlyMode(t *testing.T) {
+	t.Setenv("READ_ONLY_MODE", "true")
+	tools := serverTools(t, newTestServer(t))
+
+	for name, tool := range tools {
+		if !*tool.Annotations.ReadOnlyHint {
+			t.Errorf("%s changes data but is registered in read-only mode", name)
+		}
+	}
+	for _, name := range []string{"get_plan", "list_tasks_by_plan", "get_plan_notes"} {
+		if _, ok := tools[name]; !ok {
+			t.Errorf("%s should be registered in read-only mode", name)
+		}
+	}
+	if _, ok := tools["create_plan"]; ok {
+		t.Error("create_plan should not be registered in read-only mode")
+	}
+}
@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// toolError returns the result of a tool call that failed with err, with message describing what failed,
+// such as "Failed to get plan". Errors without a code of their own are failures of the storage or the server
+// and are reported as STORAGE errors.
+func (s *MCPGoServer) toolError(message string, err error) *mcp.CallToolResult {
+	return s.errorResult(message, err, models.ErrorCodeStorage)
+}
+
+// invalidArgument returns the result of a tool call rejected because of its arguments. Errors without a code
+// of their own are reported as VALIDATION errors.
+func (s *MCPGoServer) invalidArgument(err error) *mcp.CallToolResult {
+	return s.errorResult("", err, models.ErrorCodeValidation)
+}
+
+// validationError returns the result of a tool call rejected for the given reason. The format is translated
+// before the arguments are filled in.
+func (s *MCPGoServer) validationError(format string, args ...any) *mcp.CallToolResult {
+	return s.invalidArgument(models.NewValidationError("", "", "%s", s.localizer.Sprintf(format, args...)))
+}
+
+// errorResult encodes an error as a JSON object with its code, message and, when the error is about one, the
+// entity and its ID, so agents can branch on the code instead of matching messages:
+//
+//	{"code":"NOT_FOUND","message":"Failed to get plan: plan not found: plan-123","entity":"plan","id":"plan-123"}
+//
+// The message is translated into the language of the server; only the code, entity and ID are stable.
+func (s *MCPGoServer) errorResult(message string, err error, fallback models.ErrorCode) *mcp.CallToolResult {
+	structured := *models.AsError(err, fallback)
+	structured.Message = s.localizeError(err, &structured)
+	if message != "" {
+		structured.Message = fmt.Sprintf("%s: %s", s.localizer.Translate(message), structured.Message)
+	}
+
+	text, marshalErr := json.Marshal(structured)
+	if marshalErr != nil {
+		return mcp.NewToolResultError(structured.Message)
+	}
+	return mcp.NewToolResultError(string(text))
+}
+
+// localizeError returns the message of an error, translating the not found errors of the storage, which are
+// the most common errors agents run into
+func (s *MCPGoServer) localizeError(err error, structured *models.Error) string {
+	if structured.Code != models.ErrorCodeNotFound || structured.Entity == "" || err.Error() != structured.Message {
+		return err.Error()
+	}
+	entity := s.localizer.Translate(strings.ReplaceAll(structured.Entity, "_", " "))
+	return s.localizer.Sprintf("%s not found: %s", entity, structured.ID)
+}
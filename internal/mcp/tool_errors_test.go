@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+func TestToolErrorCodes(t *testing.T) {
+	s := newTestServer(t)
+
+	var plan models.Plan
+	result := callTool(t, s, "create_plan", map[string]any{"application_id": "app", "name": "Plan"})
+	if err := json.Unmarshal([]byte(toolResultText(result)), &plan); err != nil {
+		t.Fatalf("failed to parse plan: %v", err)
+	}
+	var task models.Task
+	result = callTool(t, s, "create_task", map[string]any{"plan_id": plan.ID, "title": "Task"})
+	if err := json.Unmarshal([]byte(toolResultText(result)), &task); err != nil {
+		t.Fatalf("failed to parse task: %v", err)
+	}
+	if result := callTool(t, s, "claim_task", map[string]any{"id": task.ID, "worker_id": "first"}); result.IsError {
+		t.Fatalf("failed to claim task: %s", toolResultText(result))
+	}
+
+	tests := []struct {
+		name      string
+		tool      string
+		arguments map[string]any
+		want      models.Error
+	}{
+		{
+			name:      "Missing plan",
+			tool:      "get_plan",
+			arguments: map[string]any{"id": "missing"},
+			want: models.Error{
+				Code:    models.ErrorCodeNotFound,
+				Message: "Failed to get plan: plan not found: missing",
+				Entity:  models.EntityPlan,
+				ID:      "missing",
+			},
+		},
+		{
+			name:      "Missing argument",
+			tool:      "get_plan",
+			arguments: map[string]any{},
+			want:      models.Error{Code: models.ErrorCodeValidation, Message: "Invalid arguments: id: is required"},
+		},
+		{
+			name:      "Task claimed by another worker",
+			tool:      "claim_task",
+			arguments: map[string]any{"id": task.ID, "worker_id": "second"},
+			want: models.Error{
+				Code:    models.ErrorCodeConflict,
+				Message: "Failed to claim task: task " + task.ID + " is already claimed by first",
+				Entity:  models.EntityTask,
+				ID:      task.ID,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := callTool(t, s, tt.tool, tt.arguments)
+			if !result.IsError {
+				t.Fatalf("expected an error result, got %s", toolResultText(result))
+			}
+
+			var got models.Error
+			if err := json.Unmarshal([]byte(toolResultText(result)), &got); err != nil {
+				t.Fatalf("failed to parse error result %q: %v", toolResultText(result), err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
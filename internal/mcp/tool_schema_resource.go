@@ -0,0 +1,100 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ErrToolNotFound is returned for schema requests of tools the server does not have
+var ErrToolNotFound = errors.New("tool not found")
+
+// Pattern for the schemas of a tool: ai-tasks://tools/{name}/schema
+var toolSchemaPattern = regexp.MustCompile(`ai-tasks://tools/([^/]+)/schema$`)
+
+// ToolSchema holds the JSON Schemas of the arguments and the successful result of a tool
+type ToolSchema struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Input       map[string]any `json:"input_schema"`
+	// Output is left out for tools whose result is not described
+	Output map[string]any `json:"output_schema,omitempty"`
+}
+
+// ToolSchemaResourceProvider implements the MCP resource provider for the schemas of the tools
+type ToolSchemaResourceProvider struct {
+	tools map[string]mcp.Tool
+}
+
+// NewToolSchemaResourceProvider creates a new ToolSchemaResourceProvider for the given tools
+func NewToolSchemaResourceProvider(tools []mcp.Tool) *ToolSchemaResourceProvider {
+	byName := make(map[string]mcp.Tool, len(tools))
+	for _, tool := range tools {
+		byName[tool.Name] = tool
+	}
+	return &ToolSchemaResourceProvider{
+		tools: byName,
+	}
+}
+
+// RegisterResource registers the tool schema resource with the MCP server
+func (p *ToolSchemaResourceProvider) RegisterResource(server *MCPGoServer) {
+	schemaTemplate := mcp.NewResourceTemplate(
+		"ai-tasks://tools/{name}/schema",
+		"Tool Schema Resource",
+		mcp.WithTemplateDescription(
+			"Returns the complete JSON Schemas of the arguments and the result of a tool, including the "+
+				"structure of JSON passed in string arguments such as tasks_json",
+		),
+		mcp.WithTemplateMIMEType("application/schema+json"),
+	)
+
+	server.addResourceTemplate(schemaTemplate, p.handleSchemaRequest)
+}
+
+// handleSchemaRequest handles requests for the schemas of a tool
+func (p *ToolSchemaResourceProvider) handleSchemaRequest(
+	ctx context.Context,
+	req mcp.ReadResourceRequest,
+) ([]mcp.ResourceContents, error) {
+	matches := toolSchemaPattern.FindStringSubmatch(req.Params.URI)
+	if len(matches) != 2 {
+		return nil, fmt.Errorf(
+			"%w: '%s' does not match the expected format 'ai-tasks://tools/{name}/schema'",
+			ErrInvalidURI,
+			req.Params.URI,
+		)
+	}
+
+	tool, ok := p.tools[matches[1]]
+	if !ok {
+		return nil, fmt.Errorf("%w: no tool named '%s'", ErrToolNotFound, matches[1])
+	}
+
+	schemaJson, err := json.Marshal(newToolSchema(tool))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to marshal schema of tool '%s': %v", ErrMarshalFailure, tool.Name, err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      req.Params.URI,
+			MIMEType: "application/schema+json",
+			Text:     string(schemaJson),
+		},
+	}, nil
+}
+
+// newToolSchema collects the schemas of a tool
+func newToolSchema(tool mcp.Tool) *ToolSchema {
+	return &ToolSchema{
+		Name:        tool.Name,
+		Description: tool.Description,
+		Input:       toolInputSchema(tool),
+		Output:      toolOutputSchema(tool.Name),
+	}
+}
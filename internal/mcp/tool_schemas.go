@@ -0,0 +1,315 @@
+package mcp
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/integrations/github"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/integrations/jira"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/utils/jsonschema"
+)
+
+// schemaEnums lists the allowed values of the string types in output schemas, including the task statuses
+// and priorities configured for the deployment
+func schemaEnums() map[reflect.Type][]string {
+	return map[reflect.Type][]string{
+		reflect.TypeOf(models.PlanStatus("")):   planStatusValues,
+		reflect.TypeOf(models.PlanPriority("")): planPriorityValues,
+		reflect.TypeOf(models.TaskStatus("")):   models.TaskStatusNames(),
+		reflect.TypeOf(models.TaskPriority("")): models.TaskPriorityNames(),
+	}
+}
+
+var (
+	planStatusValues = []string{
+		string(models.PlanStatusNew),
+		string(models.PlanStatusInProgress),
+		string(models.PlanStatusCompleted),
+		string(models.PlanStatusCancelled),
+	}
+	planPriorityValues = []string{
+		string(models.PlanPriorityLow),
+		string(models.PlanPriorityMedium),
+		string(models.PlanPriorityHigh),
+	}
+	planChangeOperationValues = []string{
+		string(services.PlanChangeCreateTask),
+		string(services.PlanChangeUpdateTask),
+		string(services.PlanChangeReorderTasks),
+		string(services.PlanChangeUpdatePlan),
+	}
+)
+
+// bulkTaskSchema describes a task definition of bulk_create_tasks
+func bulkTaskSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"title":       map[string]any{"type": "string", "minLength": 1},
+			"description": map[string]any{"type": "string"},
+			"status":      map[string]any{"type": "string", "enum": models.TaskStatusNames()},
+			"priority":    map[string]any{"type": "string", "enum": models.TaskPriorityNames()},
+			"estimate":    map[string]any{"type": "number", "minimum": 0},
+		},
+		"required":             []string{"title"},
+		"additionalProperties": false,
+	}
+}
+
+// bulkTasksSchema describes the array of task definitions in the tasks_json argument of bulk_create_tasks
+func bulkTasksSchema() map[string]any {
+	return map[string]any{
+		"type":  "array",
+		"items": bulkTaskSchema(),
+	}
+}
+
+// planChangeSchema describes a change of apply_plan_changes
+var planChangeSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"op":              map[string]any{"type": "string", "enum": planChangeOperationValues},
+		"ref":             map[string]any{"type": "string", "minLength": 1},
+		"task_id":         map[string]any{"type": "string", "minLength": 1},
+		"task_ids":        map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		"title":           map[string]any{"type": "string"},
+		"name":            map[string]any{"type": "string"},
+		"description":     map[string]any{"type": "string"},
+		"status":          map[string]any{"type": "string"},
+		"priority":        map[string]any{"type": "string"},
+		"estimate":        map[string]any{"type": "number", "minimum": 0},
+		"completion_note": map[string]any{"type": "string"},
+	},
+	"required":             []string{"op"},
+	"additionalProperties": false,
+}
+
+// withJSONContent declares that a string argument holds JSON matching a schema, which is validated like the
+// arguments themselves
+func withJSONContent(schema map[string]any) mcp.PropertyOption {
+	return func(property map[string]any) {
+		property["contentMediaType"] = "application/json"
+		property["contentSchema"] = schema
+	}
+}
+
+// planSnapshotProperties describes a plan exported with its tasks, as returned by get_plan_full
+var planSnapshotProperties = map[string]any{
+	"plan":       map[string]any{"type": "object"},
+	"tasks":      map[string]any{"type": "array", "items": map[string]any{"type": "object"}},
+	"notes_uris": map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+}
+
+// withRequiredProperties declares the properties an object argument must have
+func withRequiredProperties(names ...string) mcp.PropertyOption {
+	return func(property map[string]any) {
+		property["required"] = names
+	}
+}
+
+// textOutput is the output of tools returning plain text, such as a report, rather than JSON
+type textOutput string
+
+// anyOfOutputs is the output of tools that return one of several result types depending on their arguments
+type anyOfOutputs []any
+
+// toolOutputs lists what each tool returns on success: a value of the result type that is encoded as JSON,
+// the media type of a text result, or the alternative result types
+var toolOutputs = map[string]any{
+	// Plans
+	"create_plan":               models.Plan{},
+	"get_plan":                  anyOfOutputs{models.Plan{}, models.PlanResource{}},
+	"diff_plans":                models.PlanDiff{},
+	"get_plan_full":             models.PlanResource{},
+	"list_plans":                []*models.Plan{},
+	"list_plans_by_application": []*models.Plan{},
+	"list_plans_by_status":      []*models.Plan{},
+	"update_plan":               models.Plan{},
+	"update_plan_status":        models.Plan{},
+	"update_plan_priority":      models.Plan{},
+	"delete_plan":               map[string]string{},
+	"clone_plan":                models.Plan{},
+	"reorder_plan":              models.Plan{},
+	"lock_plan":                 models.Plan{},
+	"unlock_plan":               models.Plan{},
+	"get_plan_progress":         models.PlanProgress{},
+	"apply_plan_changes":        services.PlanChangesResult{},
+	"get_plan_capacity_report":  models.PlanCapacityReport{},
+	"validate_plan":             models.PlanValidationReport{},
+	"get_plan_time_report":      models.PlanTimeReport{},
+	"get_plan_completions":      []*models.TaskCompletion{},
+	"export_plan_markdown":      textOutput("text/markdown"),
+	"export_plan_mermaid":       textOutput("text/vnd.mermaid"),
+	"import_plan_from_markdown": models.PlanResource{},
+	"set_plan_dates":            models.Plan{},
+	"add_milestone":             models.Plan{},
+	"update_milestone":          models.Plan{},
+	"remove_milestone":          models.Plan{},
+	"list_stale_plans":          []*models.StalePlan{},
+
+	// Tasks
+	"create_task":                          models.Task{},
+	"get_task":                             models.Task{},
+	"update_task":                          models.Task{},
+	"delete_task":                          textOutput("text/plain"),
+	"bulk_create_tasks":                    anyOfOutputs{[]*models.Task{}, storage.BulkCreateReport{}},
+	"import_tasks_csv":                     anyOfOutputs{[]*models.Task{}, storage.BulkCreateReport{}},
+	"export_tasks_csv":                     textOutput("text/csv"),
+	"import_tasks_taskwarrior":             anyOfOutputs{[]*models.Task{}, storage.BulkCreateReport{}},
+	"export_tasks_taskwarrior":             []storage.TaskwarriorTask{},
+	"import_tasks_todotxt":                 anyOfOutputs{[]*models.Task{}, storage.BulkCreateReport{}},
+	"export_tasks_todotxt":                 textOutput("text/plain"),
+	"reorder_task":                         models.Task{},
+	"reorder_tasks":                        []*models.Task{},
+	"move_task":                            models.Task{},
+	"list_tasks_by_plan":                   []*models.Task{},
+	"stream_tasks_by_plan":                 models.TaskStreamResult{},
+	"list_tasks_by_status":                 []*models.Task{},
+	"list_tasks_by_plan_and_status":        []*models.Task{},
+	"list_tasks_by_application":            []*models.Task{},
+	"list_tasks_by_application_and_status": []*models.Task{},
+	"list_tasks_by_tag":                    []*models.Task{},
+	"list_orphaned_tasks":                  []*models.Task{},
+	"query_tasks":                          []*models.Task{},
+	"list_stale_tasks":                     []*models.StaleTask{},
+	"add_task_tags":                        models.Task{},
+	"remove_task_tags":                     models.Task{},
+	"add_checklist_item":                   models.Task{},
+	"toggle_checklist_item":                models.Task{},
+	"remove_checklist_item":                models.Task{},
+	"add_acceptance_criterion":             models.Task{},
+	"toggle_acceptance_criterion":          models.Task{},
+	"remove_acceptance_criterion":          models.Task{},
+	"add_task_attachment":                  models.Attachment{},
+	"list_task_attachments":                []*models.Attachment{},
+	"get_task_attachment":                  models.Attachment{},
+	"log_time":                             models.Task{},
+	"claim_task":                           models.Task{},
+	"renew_lease":                          models.Task{},
+
+	// Notes
+	"get_plan_notes":          map[string]string{},
+	"update_plan_notes":       textOutput("text/plain"),
+	"append_plan_notes":       map[string]string{},
+	"get_plan_notes_history":  []*models.NotesRevision{},
+	"revert_plan_notes":       map[string]string{},
+	"get_task_notes":          map[string]string{},
+	"update_task_notes":       models.Task{},
+	"get_archived_plan_notes": models.ArchivedNotes{},
+	"get_archived_task_notes": models.ArchivedNotes{},
+
+	// Agent activity
+	"log_agent_activity": models.AgentActivity{},
+	"get_agent_activity": []*models.AgentActivity{},
+
+	// Metadata
+	"get_plan_metadata":    map[string]string{},
+	"set_plan_metadata":    models.Plan{},
+	"delete_plan_metadata": models.Plan{},
+	"get_task_metadata":    map[string]string{},
+	"set_task_metadata":    models.Task{},
+	"delete_task_metadata": models.Task{},
+	"add_plan_link":        models.Plan{},
+	"list_plan_links":      []*models.Link{},
+	"remove_plan_link":     models.Plan{},
+	"add_task_link":        models.Task{},
+	"list_task_links":      []*models.Link{},
+	"remove_task_link":     models.Task{},
+	"watch_plan":           models.PlanWatchers{},
+	"unwatch_plan":         models.PlanWatchers{},
+	"list_watchers":        models.PlanWatchers{},
+
+	// Applications
+	"create_application":     models.Application{},
+	"get_application":        models.Application{},
+	"list_applications":      []*models.ApplicationListing{},
+	"get_application_status": models.ApplicationStatus{},
+
+	// History
+	"get_plan_history": []*models.AuditEntry{},
+	"get_task_history": []*models.AuditEntry{},
+	"undo_last_change": services.UndoResult{},
+
+	// Administration
+	"check_data_integrity": storage.IntegrityReport{},
+	"get_retention_stats":  services.RetentionStats{},
+
+	// Sessions
+	"set_session_context": SessionContext{},
+	"get_session_context": SessionContext{},
+
+	// Integrations
+	"sync_plan_to_github":           github.SyncReport{},
+	"import_github_issues_as_tasks": github.ImportReport{},
+	"import_jira_issues":            jira.ImportReport{},
+	"push_jira_status":              jira.PushReport{},
+}
+
+// toolInputSchema returns the JSON Schema of the arguments of a tool
+func toolInputSchema(tool mcp.Tool) map[string]any {
+	schema := map[string]any{
+		"type":       "object",
+		"properties": tool.InputSchema.Properties,
+	}
+	if len(tool.InputSchema.Required) > 0 {
+		schema["required"] = tool.InputSchema.Required
+	}
+	return schema
+}
+
+// toolOutputSchema returns the JSON Schema of the successful result of a tool, or nil if it is not known
+func toolOutputSchema(name string) map[string]any {
+	output, ok := toolOutputs[name]
+	if !ok {
+		return nil
+	}
+
+	generator := jsonschema.NewGenerator("#/$defs/", schemaEnums())
+	switch output := output.(type) {
+	case textOutput:
+		return map[string]any{"type": "string", "contentMediaType": string(output)}
+	case anyOfOutputs:
+		alternatives := make([]any, len(output))
+		for i, alternative := range output {
+			alternatives[i] = generator.Schema(reflect.TypeOf(alternative))
+		}
+		return map[string]any{"anyOf": alternatives, "$defs": generator.Definitions()}
+	default:
+		return generator.Document(reflect.TypeOf(output))
+	}
+}
+
+// validateArguments checks the arguments of tool calls against the input schema of the tool before calling
+// its handler, so invalid calls fail with the path of the offending value rather than a vague type error.
+// Optional arguments set to null are treated as left out.
+func (s *MCPGoServer) validateArguments(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	schema := toolInputSchema(tool)
+
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var arguments map[string]any
+		switch raw := request.Params.Arguments.(type) {
+		case nil:
+			arguments = map[string]any{}
+		case map[string]any:
+			arguments = make(map[string]any, len(raw))
+			for name, value := range raw {
+				if value != nil {
+					arguments[name] = value
+				}
+			}
+		default:
+			return s.validationError("Invalid arguments: arguments must be an object"), nil
+		}
+
+		if err := jsonschema.Validate(schema, arguments); err != nil {
+			return s.errorResult("Invalid arguments", err, models.ErrorCodeValidation), nil
+		}
+		return next(ctx, request)
+	}
+}
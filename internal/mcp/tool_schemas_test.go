@@ -0,0 +1,46 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestToolOutputsCoverTools(t *testing.T) {
+	for name := range serverTools(t, newTestServer(t)) {
+		if toolOutputSchema(name) == nil {
+			t.Errorf("%s has no output schema", name)
+		}
+	}
+}
+
+func TestValidateArguments(t *testing.T) {
+	s := newTestServer(t)
+
+	result := callTool(t, s, "create_plan", map[string]any{"application_id": "app", "name": 42})
+	if !result.IsError || !strings.Contains(toolResultText(result), "name: must be a string, got a number") {
+		t.Errorf("create_plan with a numeric name: %s", toolResultText(result))
+	}
+
+	result = callTool(t, s, "create_plan", map[string]any{"application_id": "app", "name": "Plan", "description": nil})
+	if result.IsError {
+		t.Fatalf("optional null arguments should be left out: %s", toolResultText(result))
+	}
+	var plan struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(toolResultText(result)), &plan); err != nil {
+		t.Fatalf("failed to parse plan: %v", err)
+	}
+
+	tasksJSON := `[{"title":"First"},{"title":"Second","priority":"urgent"}]`
+	result = callTool(t, s, "bulk_create_tasks", map[string]any{"plan_id": plan.ID, "tasks_json": tasksJSON})
+	if !result.IsError || !strings.Contains(toolResultText(result), "tasks_json[1].priority: must be one of") {
+		t.Errorf("bulk_create_tasks with an invalid priority: %s", toolResultText(result))
+	}
+
+	result = callTool(t, s, "set_plan_metadata", map[string]any{"id": plan.ID, "metadata": map[string]any{"points": 3}})
+	if result.IsError {
+		t.Errorf("numeric metadata values should be accepted: %s", toolResultText(result))
+	}
+}
@@ -0,0 +1,38 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// warningsMetaField is the field of the result metadata holding the warnings raised by a tool call
+const warningsMetaField = "warnings"
+
+// warningsMiddleware collects the warnings raised while serving a tool call and returns them in the metadata of
+// a successful result, leaving its content to the tool. Replayed idempotent calls return no warnings.
+func warningsMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = storage.WithWarnings(ctx)
+		result, err := next(ctx, request)
+		if err != nil || result == nil || result.IsError {
+			return result, err
+		}
+
+		warnings := storage.WarningsFromContext(ctx)
+		if len(warnings) == 0 {
+			return result, nil
+		}
+		if result.Meta == nil {
+			result.Meta = &mcp.Meta{}
+		}
+		if result.Meta.AdditionalFields == nil {
+			result.Meta.AdditionalFields = make(map[string]any)
+		}
+		result.Meta.AdditionalFields[warningsMetaField] = warnings
+		return result, nil
+	}
+}
@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+func TestDecompositionWarnings(t *testing.T) {
+	repos := newTestRepos(t)
+	guardrails := storage.DecompositionGuardrails{MaxDescriptionLength: 40, MaxPlanTasks: 2}
+	s := NewMCPGoServer(repos.planRepo, storage.NewGuardedTaskRepository(repos.taskRepo, guardrails))
+	ctx := context.Background()
+
+	plan, err := s.planRepo.Create(ctx, "app-1", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+
+	result := callTool(t, s, "create_task", map[string]any{"plan_id": plan.ID, "title": "Small", "description": "Short"})
+	if result.IsError {
+		t.Fatalf("create_task failed: %s", toolResultText(result))
+	}
+	if result.Meta != nil {
+		t.Errorf("a task within the guardrails got warnings: %+v", result.Meta.AdditionalFields)
+	}
+
+	// Warnings accompany the result and do not stop the change
+	result = callTool(t, s, "bulk_create_tasks", map[string]any{
+		"plan_id":    plan.ID,
+		"tasks_json": `[{"title": "Everything", "description": "` + strings.Repeat("d", 41) + `"}, {"title": "More"}]`,
+	})
+	if result.IsError {
+		t.Fatalf("bulk_create_tasks failed: %s", toolResultText(result))
+	}
+	if result.Meta == nil {
+		t.Fatal("expected warnings about the long description and the plan size")
+	}
+	warnings, ok := result.Meta.AdditionalFields[warningsMetaField].([]*models.Warning)
+	if !ok || len(warnings) != 2 {
+		t.Fatalf("warnings = %+v, want two", result.Meta.AdditionalFields[warningsMetaField])
+	}
+	if warnings[0].Code != models.WarningCodeDecomposeTask || warnings[1].Code != models.WarningCodeSplitPlan ||
+		warnings[1].ID != plan.ID {
+		t.Errorf("warnings = %+v, want a decompose task and a split plan warning", warnings)
+	}
+
+	// Blocking rejects the same changes
+	guardrails.Block = true
+	s = NewMCPGoServer(s.planRepo, storage.NewGuardedTaskRepository(repos.taskRepo, guardrails))
+	result = callTool(t, s, "create_task", map[string]any{"plan_id": plan.ID, "title": "Another"})
+	if !result.IsError || !strings.Contains(toolResultText(result), "split") {
+		t.Errorf("adding a task to a plan beyond the guardrail should fail, got %s", toolResultText(result))
+	}
+	if count, err := s.taskRepo.CountByPlan(ctx, plan.ID); err != nil || count != 3 {
+		t.Errorf("plan has %d tasks (%v), want 3", count, err)
+	}
+}
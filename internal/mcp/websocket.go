@@ -0,0 +1,156 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// websocketSubprotocol is the WebSocket subprotocol for MCP, selected when the client offers it
+	websocketSubprotocol = "mcp"
+	// websocketMaxMessageBytes bounds the size of a single message received from a client
+	websocketMaxMessageBytes = 4 << 20
+	// websocketNotificationBuffer is how many notifications are queued for a slow client
+	websocketNotificationBuffer = 100
+)
+
+// websocketSession is the MCP session of a single WebSocket connection
+type websocketSession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+	initialized   atomic.Bool
+}
+
+func (s *websocketSession) SessionID() string {
+	return s.id
+}
+
+func (s *websocketSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+func (s *websocketSession) Initialize() {
+	s.initialized.Store(true)
+}
+
+func (s *websocketSession) Initialized() bool {
+	return s.initialized.Load()
+}
+
+// websocketServer returns the handler of the WebSocket transport.
+// Each text message carries exactly one JSON-RPC message in either direction, and each connection is its
+// own MCP session sharing the tools and resources of the other transports.
+func (s *MCPGoServer) websocketServer() websocket.Server {
+	return websocket.Server{
+		Handshake: websocketHandshake,
+		Handler:   s.serveWebSocket,
+	}
+}
+
+// websocketHandshake selects the MCP subprotocol and rejects browser pages from other origins.
+// Agents usually send no Origin header, so requests without one are accepted.
+func websocketHandshake(config *websocket.Config, r *http.Request) error {
+	origin, err := websocket.Origin(config, r)
+	if err != nil {
+		return err
+	}
+	if origin != nil && !strings.EqualFold(origin.Host, r.Host) {
+		return fmt.Errorf("cross-origin WebSocket connection from %s is not allowed", origin)
+	}
+
+	if slices.Contains(config.Protocol, websocketSubprotocol) {
+		config.Protocol = []string{websocketSubprotocol}
+	} else {
+		config.Protocol = nil
+	}
+	return nil
+}
+
+// serveWebSocket runs an MCP session over a WebSocket connection until the client disconnects
+func (s *MCPGoServer) serveWebSocket(conn *websocket.Conn) {
+	defer conn.Close() //nolint:errcheck
+
+	// The HTTP server's read and write timeouts must not end the long-lived connection
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		log.Printf("Failed to clear WebSocket deadline: %v", err)
+		return
+	}
+	conn.MaxPayloadBytes = websocketMaxMessageBytes
+
+	session := &websocketSession{
+		id:            uuid.New().String(),
+		notifications: make(chan mcp.JSONRPCNotification, websocketNotificationBuffer),
+	}
+
+	ctx, cancel := context.WithCancel(withClientIdentity(conn.Request().Context(), conn.Request()))
+	defer cancel()
+
+	if err := s.server.RegisterSession(ctx, session); err != nil {
+		log.Printf("Failed to register WebSocket session: %v", err)
+		return
+	}
+	defer s.server.UnregisterSession(ctx, session.id)
+	ctx = s.server.WithContext(ctx, session)
+
+	var writeMu sync.Mutex
+	write := func(message any) {
+		data, err := json.Marshal(message)
+		if err != nil {
+			log.Printf("Failed to encode WebSocket message: %v", err)
+			return
+		}
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if err := websocket.Message.Send(conn, string(data)); err != nil {
+			// The read loop notices the broken connection and ends the session
+			log.Printf("Failed to write WebSocket message: %v", err)
+		}
+	}
+
+	go func() {
+		for {
+			select {
+			case notification := <-session.notifications:
+				write(notification)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Requests are handled concurrently so a slow tool call does not hold up the others
+	var requests sync.WaitGroup
+	defer requests.Wait()
+
+	for {
+		var message []byte
+		if err := websocket.Message.Receive(conn, &message); err != nil {
+			return
+		}
+
+		if !json.Valid(message) {
+			write(mcp.NewJSONRPCError(mcp.NewRequestId(nil), mcp.PARSE_ERROR, "Parse error", nil))
+			continue
+		}
+
+		requests.Add(1)
+		go func() {
+			defer requests.Done()
+			if response := s.server.HandleMessage(ctx, message); response != nil {
+				write(response)
+			}
+		}()
+	}
+}
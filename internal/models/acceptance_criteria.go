@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MaxAcceptanceCriteria is the maximum number of acceptance criteria on a task
+const MaxAcceptanceCriteria = 50
+
+// AcceptanceCriterion is a condition a task must meet to be done, tracked apart from the description so
+// agents and reviewers can check it off one by one
+type AcceptanceCriterion struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+	Met  bool   `json:"met"`
+}
+
+// NewAcceptanceCriterion creates a new, not yet met acceptance criterion
+func NewAcceptanceCriterion(id, text string) *AcceptanceCriterion {
+	return &AcceptanceCriterion{
+		ID:   id,
+		Text: strings.TrimSpace(text),
+		Met:  false,
+	}
+}
+
+// AcceptanceCriterionIndex returns the position of the criterion with the given ID, or -1 if there is none
+func AcceptanceCriterionIndex(criteria []*AcceptanceCriterion, id string) int {
+	return slices.IndexFunc(criteria, func(c *AcceptanceCriterion) bool { return c.ID == id })
+}
+
+// UnmetAcceptanceCriteria returns the acceptance criteria of the task that are not met yet
+func (t *Task) UnmetAcceptanceCriteria() []*AcceptanceCriterion {
+	var unmet []*AcceptanceCriterion
+	for _, criterion := range t.AcceptanceCriteria {
+		if !criterion.Met {
+			unmet = append(unmet, criterion)
+		}
+	}
+	return unmet
+}
+
+// encodeAcceptanceCriteria converts acceptance criteria to their stored JSON form, or an empty string if
+// there are none
+func encodeAcceptanceCriteria(criteria []*AcceptanceCriterion) string {
+	if len(criteria) == 0 {
+		return ""
+	}
+	// Criteria only hold strings and flags, which always encode
+	data, _ := json.Marshal(criteria) //nolint:errcheck
+	return string(data)
+}
+
+// decodeAcceptanceCriteria parses acceptance criteria from their stored JSON form
+func decodeAcceptanceCriteria(data string) ([]*AcceptanceCriterion, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var criteria []*AcceptanceCriterion
+	if err := json.Unmarshal([]byte(data), &criteria); err != nil {
+		return nil, fmt.Errorf("failed to decode acceptance criteria: %w", err)
+	}
+	return criteria, nil
+}
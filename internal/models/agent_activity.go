@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// AgentActivity is an entry of the activity journal of a plan, where agents record what they did in a work
+// session. Entries are never edited, so unlike notes the journal reads as a narrative of how the work went.
+type AgentActivity struct {
+	// ID identifies the entry and orders the journal, for paging through it
+	ID     string `json:"id"`
+	PlanID string `json:"plan_id"`
+	Actor  string `json:"actor"`
+	// Session groups the entries of one work session, as named by the agent
+	Session string `json:"session,omitempty"`
+	// Kind classifies the entry, such as decision, action or blocker
+	Kind    string   `json:"kind,omitempty"`
+	Summary string   `json:"summary"`
+	TaskIDs []string `json:"task_ids,omitempty"`
+	// Details holds free-form structured data, such as the files changed or the commands run
+	Details  map[string]any `json:"details,omitempty"`
+	LoggedAt time.Time      `json:"logged_at"`
+}
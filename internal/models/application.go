@@ -0,0 +1,119 @@
+package models
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Application is a registered application, the product or workspace that plans belong to.
+// Its ID is the application ID plans refer to.
+type Application struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// Arbitrary key/value metadata such as a repository URL or team name
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// NewApplication creates a new application
+func NewApplication(id, name, description string) *Application {
+	return &Application{
+		ID:          id,
+		Name:        name,
+		Description: description,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// ValidateApplicationID checks that an application ID is non-empty and free of whitespace
+func ValidateApplicationID(id string) error {
+	if id == "" {
+		return NewValidationError("", "", "application ID cannot be empty")
+	}
+	if strings.ContainsAny(id, " \t\r\n") {
+		return NewValidationError(EntityApplication, id, "application ID %q cannot contain whitespace", id)
+	}
+	return nil
+}
+
+// ToMap converts the application to a map for storage in Valkey
+func (a *Application) ToMap() map[string]string {
+	fields := map[string]string{
+		"id":          a.ID,
+		"name":        a.Name,
+		"description": a.Description,
+		"created_at":  a.CreatedAt.Format(time.RFC3339),
+	}
+	addMetadataFields(fields, a.Metadata)
+
+	return fields
+}
+
+// FromMap populates an application from a map retrieved from Valkey
+func (a *Application) FromMap(data map[string]string) error {
+	a.ID = data["id"]
+	a.Name = data["name"]
+	a.Description = data["description"]
+
+	createdAt, err := time.Parse(time.RFC3339, data["created_at"])
+	if err != nil {
+		return err
+	}
+	a.CreatedAt = createdAt
+
+	a.Metadata = metadataFromFields(data)
+
+	return nil
+}
+
+// ApplicationPlans counts the plans of an application that plans refer to
+type ApplicationPlans struct {
+	ApplicationID string `json:"application_id"`
+	PlanCount     int    `json:"plan_count"`
+}
+
+// ApplicationListing is an application with the number of its plans. Applications only referred to by plans
+// are not registered and have no name, description or metadata.
+type ApplicationListing struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	CreatedAt   *time.Time        `json:"created_at,omitempty"`
+	Registered  bool              `json:"registered"`
+	PlanCount   int               `json:"plan_count"`
+}
+
+// NewApplicationListings lists the registered applications and the applications plans refer to together,
+// ordered by ID
+func NewApplicationListings(registered []*Application, inUse []*ApplicationPlans) []*ApplicationListing {
+	listings := make(map[string]*ApplicationListing, len(registered)+len(inUse))
+	for _, application := range registered {
+		listings[application.ID] = &ApplicationListing{
+			ID:          application.ID,
+			Name:        application.Name,
+			Description: application.Description,
+			Metadata:    application.Metadata,
+			CreatedAt:   &application.CreatedAt,
+			Registered:  true,
+		}
+	}
+	for _, plans := range inUse {
+		listing, ok := listings[plans.ApplicationID]
+		if !ok {
+			listing = &ApplicationListing{ID: plans.ApplicationID}
+			listings[plans.ApplicationID] = listing
+		}
+		listing.PlanCount = plans.PlanCount
+	}
+
+	result := make([]*ApplicationListing, 0, len(listings))
+	for _, id := range slices.Sorted(maps.Keys(listings)) {
+		result = append(result, listings[id])
+	}
+	return result
+}
@@ -0,0 +1,74 @@
+package models
+
+import "math"
+
+// PlanRollup is what the status of an application keeps of each of its plans: the plan status and its task
+// counts, which are nil for plans stored before tasks were counted
+type PlanRollup struct {
+	Status     PlanStatus  `json:"status"`
+	TaskCounts *TaskCounts `json:"task_counts,omitempty"`
+}
+
+// ApplicationStatus rolls the status and progress of the plans of an application up to the application
+type ApplicationStatus struct {
+	ApplicationID string `json:"application_id"`
+
+	// Status is derived from the plans: completed once all plans that are not cancelled are completed, in
+	// progress once any plan is in progress or completed, and new otherwise
+	Status PlanStatus `json:"status"`
+
+	TotalPlans       int                `json:"total_plans"`
+	PlanStatusCounts map[PlanStatus]int `json:"plan_status_counts"`
+
+	// Task counts across all plans
+	TotalTasks       int                `json:"total_tasks"`
+	TaskStatusCounts map[TaskStatus]int `json:"task_status_counts"`
+
+	// PercentComplete is the share of non-cancelled tasks of all plans that are completed
+	PercentComplete float64 `json:"percent_complete"`
+}
+
+// NewApplicationStatus adds up the rollups of the plans of an application
+func NewApplicationStatus(applicationID string, plans []*PlanRollup) *ApplicationStatus {
+	status := &ApplicationStatus{
+		ApplicationID: applicationID,
+		TotalPlans:    len(plans),
+		PlanStatusCounts: map[PlanStatus]int{
+			PlanStatusNew:        0,
+			PlanStatusInProgress: 0,
+			PlanStatusCompleted:  0,
+			PlanStatusCancelled:  0,
+		},
+		TaskStatusCounts: NewTaskStatusCounts(),
+	}
+
+	for _, plan := range plans {
+		status.PlanStatusCounts[plan.Status]++
+		if plan.TaskCounts == nil {
+			continue
+		}
+		status.TotalTasks += plan.TaskCounts.Total
+		for taskStatus := range status.TaskStatusCounts {
+			status.TaskStatusCounts[taskStatus] += plan.TaskCounts.Count(taskStatus)
+		}
+	}
+
+	countable := status.TotalTasks - status.TaskStatusCounts[TaskStatusCancelled]
+	if countable > 0 {
+		percent := float64(status.TaskStatusCounts[TaskStatusCompleted]) / float64(countable) * 100
+		status.PercentComplete = math.Round(percent*10) / 10
+	}
+
+	active := status.TotalPlans - status.PlanStatusCounts[PlanStatusCancelled]
+	switch {
+	case status.TotalPlans > 0 && active == 0:
+		status.Status = PlanStatusCancelled
+	case active > 0 && status.PlanStatusCounts[PlanStatusCompleted] == active:
+		status.Status = PlanStatusCompleted
+	case status.PlanStatusCounts[PlanStatusInProgress] > 0 || status.PlanStatusCounts[PlanStatusCompleted] > 0:
+		status.Status = PlanStatusInProgress
+	default:
+		status.Status = PlanStatusNew
+	}
+	return status
+}
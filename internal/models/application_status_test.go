@@ -0,0 +1,47 @@
+package models
+
+import "testing"
+
+func TestNewApplicationStatus(t *testing.T) {
+	status := NewApplicationStatus("shop", []*PlanRollup{
+		{Status: PlanStatusCompleted, TaskCounts: &TaskCounts{Total: 2, Completed: 2}},
+		{Status: PlanStatusInProgress, TaskCounts: &TaskCounts{Total: 4, InProgress: 1, Pending: 1, Cancelled: 2}},
+		// Plans stored before tasks were counted only add their status
+		{Status: PlanStatusNew},
+	})
+
+	if status.Status != PlanStatusInProgress {
+		t.Errorf("Status = %s, want in_progress", status.Status)
+	}
+	if status.TotalPlans != 3 || status.PlanStatusCounts[PlanStatusCompleted] != 1 ||
+		status.PlanStatusCounts[PlanStatusNew] != 1 {
+		t.Errorf("unexpected plan counts: %d plans, %v", status.TotalPlans, status.PlanStatusCounts)
+	}
+	if status.TotalTasks != 6 || status.TaskStatusCounts[TaskStatusCompleted] != 2 {
+		t.Errorf("unexpected task counts: %d tasks, %v", status.TotalTasks, status.TaskStatusCounts)
+	}
+	// Cancelled tasks are left out: 2 of 4 tasks are completed
+	if status.PercentComplete != 50 {
+		t.Errorf("PercentComplete = %v, want 50", status.PercentComplete)
+	}
+
+	tests := []struct {
+		plans []PlanStatus
+		want  PlanStatus
+	}{
+		{nil, PlanStatusNew},
+		{[]PlanStatus{PlanStatusNew, PlanStatusNew}, PlanStatusNew},
+		{[]PlanStatus{PlanStatusCompleted, PlanStatusNew}, PlanStatusInProgress},
+		{[]PlanStatus{PlanStatusCompleted, PlanStatusCancelled}, PlanStatusCompleted},
+		{[]PlanStatus{PlanStatusCancelled}, PlanStatusCancelled},
+	}
+	for _, test := range tests {
+		var plans []*PlanRollup
+		for _, planStatus := range test.plans {
+			plans = append(plans, &PlanRollup{Status: planStatus})
+		}
+		if got := NewApplicationStatus("shop", plans).Status; got != test.want {
+			t.Errorf("status of plans %v = %s, want %s", test.plans, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// ApplicationSummary represents an aggregated portfolio view of all plans for an application
+type ApplicationSummary struct {
+	ApplicationID string `json:"application_id"`
+
+	// Status is the stored rollup of the plans of the application, as returned by get_application_status
+	Status *ApplicationStatus `json:"status,omitempty"`
+
+	// Plan level aggregates
+	TotalPlans       int                `json:"total_plans"`
+	PlanStatusCounts map[PlanStatus]int `json:"plan_status_counts"`
+
+	// Task level aggregates across all plans
+	TotalTasks       int                `json:"total_tasks"`
+	TotalOpenTasks   int                `json:"total_open_tasks"`
+	TaskStatusCounts map[TaskStatus]int `json:"task_status_counts"`
+
+	// Per-plan progress
+	Plans []*PlanProgress `json:"plans"`
+
+	// Most recently updated plans and tasks
+	RecentlyUpdated []*RecentlyUpdatedItem `json:"recently_updated"`
+
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// RecentlyUpdatedItem identifies a plan or task that changed recently
+type RecentlyUpdatedItem struct {
+	Type      string    `json:"type"` // "plan" or "task"
+	ID        string    `json:"id"`
+	PlanID    string    `json:"plan_id"`
+	Title     string    `json:"title"`
+	Status    string    `json:"status"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "testing"
+
+func TestNewApplicationListings(t *testing.T) {
+	registered := []*Application{
+		NewApplication("shop", "Web shop", ""),
+		NewApplication("billing", "Billing", ""),
+	}
+	inUse := []*ApplicationPlans{
+		{ApplicationID: "legacy", PlanCount: 2},
+		{ApplicationID: "shop", PlanCount: 3},
+	}
+
+	listings := NewApplicationListings(registered, inUse)
+	if len(listings) != 3 {
+		t.Fatalf("got %d listings, want 3", len(listings))
+	}
+
+	want := []struct {
+		id         string
+		registered bool
+		plans      int
+	}{
+		{"billing", true, 0},
+		{"legacy", false, 2},
+		{"shop", true, 3},
+	}
+	for i, w := range want {
+		listing := listings[i]
+		if listing.ID != w.id || listing.Registered != w.registered || listing.PlanCount != w.plans {
+			t.Errorf("listing %d = %s (registered %t, %d plans), want %s (registered %t, %d plans)",
+				i, listing.ID, listing.Registered, listing.PlanCount, w.id, w.registered, w.plans)
+		}
+	}
+	if listings[1].CreatedAt != nil || listings[1].Name != "" {
+		t.Errorf("unregistered application should have no name or creation time: %+v", listings[1])
+	}
+}
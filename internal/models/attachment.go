@@ -0,0 +1,83 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// attachmentContentTypes are the media types besides text/* that attachments may have. Attachments are
+// stored as text, so binary formats are not accepted.
+var attachmentContentTypes = map[string]bool{
+	"application/json":     true,
+	"application/x-ndjson": true,
+	"application/xml":      true,
+	"application/yaml":     true,
+	"application/x-yaml":   true,
+}
+
+// Attachment is a small artifact attached to a task, such as a diff, a log excerpt or a JSON document
+type Attachment struct {
+	ID          string    `json:"id"`
+	TaskID      string    `json:"task_id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int       `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+	// Content is left out when attachments are listed
+	Content string `json:"content,omitempty"`
+}
+
+// NewAttachment creates a new attachment of a task
+func NewAttachment(id, taskID, name, contentType, content string) *Attachment {
+	return &Attachment{
+		ID:          id,
+		TaskID:      taskID,
+		Name:        name,
+		ContentType: contentType,
+		Size:        len(content),
+		CreatedAt:   time.Now(),
+		Content:     content,
+	}
+}
+
+// Validate checks that the attachment has a name and text content of a supported media type
+func (a *Attachment) Validate() error {
+	if strings.TrimSpace(a.Name) == "" {
+		return NewValidationError(EntityAttachment, a.ID, "attachment name cannot be empty")
+	}
+	mediaType, _, err := mime.ParseMediaType(a.ContentType)
+	if err != nil {
+		return NewValidationError(EntityAttachment, a.ID, "invalid content type: %s", a.ContentType)
+	}
+	if !strings.HasPrefix(mediaType, "text/") && !attachmentContentTypes[mediaType] {
+		return NewValidationError(EntityAttachment, a.ID, "unsupported content type: %s", mediaType)
+	}
+	if !utf8.ValidString(a.Content) {
+		return NewValidationError(EntityAttachment, a.ID, "attachment content must be UTF-8 text")
+	}
+	return nil
+}
+
+// Encode converts the attachment without its content to its stored JSON form
+func (a *Attachment) Encode() (string, error) {
+	info := *a
+	info.Content = ""
+	data, err := json.Marshal(info)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode attachment: %w", err)
+	}
+	return string(data), nil
+}
+
+// DecodeAttachment parses an attachment from its stored JSON form
+func DecodeAttachment(data string) (*Attachment, error) {
+	attachment := &Attachment{}
+	if err := json.Unmarshal([]byte(data), attachment); err != nil {
+		return nil, fmt.Errorf("failed to decode attachment: %w", err)
+	}
+	return attachment, nil
+}
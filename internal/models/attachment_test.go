@@ -0,0 +1,34 @@
+package models
+
+import "testing"
+
+func TestAttachmentValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		content     string
+		valid       bool
+	}{
+		{name: "Diff", contentType: "text/x-diff", content: "+added", valid: true},
+		{name: "JSON with charset", contentType: "application/json; charset=utf-8", content: "{}", valid: true},
+		{name: "Binary type", contentType: "image/png", content: "png", valid: false},
+		{name: "Invalid type", contentType: "text/", content: "text", valid: false},
+		{name: "Invalid UTF-8", contentType: "text/plain", content: "\xff\xfe", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewAttachment("a1", "t1", "artifact", tt.contentType, tt.content).Validate()
+			if (err == nil) != tt.valid {
+				t.Errorf("Validate() error = %v, want valid %v", err, tt.valid)
+			}
+			if err != nil && ErrorCodeOf(err) != ErrorCodeValidation {
+				t.Errorf("expected a validation error, got %s", ErrorCodeOf(err))
+			}
+		})
+	}
+
+	if err := NewAttachment("a1", "t1", " ", "text/plain", "text").Validate(); err == nil {
+		t.Error("expected an error for an attachment without a name")
+	}
+}
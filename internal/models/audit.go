@@ -0,0 +1,88 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// EntityType identifies the kind of entity an audit entry refers to
+type EntityType string
+
+const (
+	EntityTypePlan EntityType = "plan"
+	EntityTypeTask EntityType = "task"
+)
+
+// AuditAction describes the kind of mutation recorded in an audit entry
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+)
+
+// FieldChange holds the before and after values of a changed field
+type FieldChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditEntry is a single record in the append-only history of a plan or task.
+// Before and After are full JSON snapshots of the entity; either is empty for creates and deletes.
+type AuditEntry struct {
+	ID         string                 `json:"id"`
+	EntityType EntityType             `json:"entity_type"`
+	EntityID   string                 `json:"entity_id"`
+	Action     AuditAction            `json:"action"`
+	Operation  string                 `json:"operation"`
+	Actor      string                 `json:"actor"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Before     json.RawMessage        `json:"before,omitempty"`
+	After      json.RawMessage        `json:"after,omitempty"`
+	Changes    map[string]FieldChange `json:"changes,omitempty"`
+}
+
+// ignoredDiffFields are fields that change on every mutation and carry no information in a diff
+var ignoredDiffFields = map[string]bool{
+	"updated_at": true,
+}
+
+// DiffSnapshots compares two JSON object snapshots and returns the changed top-level fields
+func DiffSnapshots(before, after json.RawMessage) (map[string]FieldChange, error) {
+	beforeFields := map[string]any{}
+	if len(before) > 0 {
+		if err := json.Unmarshal(before, &beforeFields); err != nil {
+			return nil, fmt.Errorf("failed to parse before snapshot: %w", err)
+		}
+	}
+
+	afterFields := map[string]any{}
+	if len(after) > 0 {
+		if err := json.Unmarshal(after, &afterFields); err != nil {
+			return nil, fmt.Errorf("failed to parse after snapshot: %w", err)
+		}
+	}
+
+	changes := make(map[string]FieldChange)
+	for field, value := range afterFields {
+		if ignoredDiffFields[field] {
+			continue
+		}
+		if previous, ok := beforeFields[field]; !ok || !reflect.DeepEqual(previous, value) {
+			changes[field] = FieldChange{Before: beforeFields[field], After: value}
+		}
+	}
+	for field, value := range beforeFields {
+		if ignoredDiffFields[field] {
+			continue
+		}
+		if _, ok := afterFields[field]; !ok {
+			changes[field] = FieldChange{Before: value, After: nil}
+		}
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected map[string]FieldChange
+	}{
+		{
+			name:   "Changed and added fields",
+			before: `{"title":"Old","status":"pending","updated_at":"2025-01-01T00:00:00Z"}`,
+			after:  `{"title":"New","status":"pending","notes":"x","updated_at":"2025-01-02T00:00:00Z"}`,
+			expected: map[string]FieldChange{
+				"title": {Before: "Old", After: "New"},
+				"notes": {Before: nil, After: "x"},
+			},
+		},
+		{
+			name:   "Removed field",
+			before: `{"title":"Task","tags":["a"]}`,
+			after:  `{"title":"Task"}`,
+			expected: map[string]FieldChange{
+				"tags": {Before: []any{"a"}, After: nil},
+			},
+		},
+		{
+			name:   "Create",
+			before: ``,
+			after:  `{"title":"Task"}`,
+			expected: map[string]FieldChange{
+				"title": {Before: nil, After: "Task"},
+			},
+		},
+		{
+			name:     "No changes",
+			before:   `{"title":"Task"}`,
+			after:    `{"title":"Task"}`,
+			expected: map[string]FieldChange{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DiffSnapshots(json.RawMessage(tt.before), json.RawMessage(tt.after))
+			if err != nil {
+				t.Fatalf("DiffSnapshots() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("DiffSnapshots() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// CapacityTaskEntry summarizes an open task considered when the estimated work of a plan exceeds its capacity
+type CapacityTaskEntry struct {
+	TaskID   string       `json:"task_id"`
+	Title    string       `json:"title"`
+	Status   TaskStatus   `json:"status"`
+	Priority TaskPriority `json:"priority"`
+	Estimate float64      `json:"estimate"`
+}
+
+// PlanCapacityReport compares the estimated work of a plan with the work completed and the capacity left.
+// Estimates are in the unit the plan is estimated in; cancelled tasks are left out.
+type PlanCapacityReport struct {
+	PlanID   string `json:"plan_id"`
+	PlanName string `json:"plan_name"`
+
+	// TotalEstimate is the sum of the estimates of the plan, split into completed and remaining work
+	TotalEstimate     float64 `json:"total_estimate"`
+	CompletedEstimate float64 `json:"completed_estimate"`
+	RemainingEstimate float64 `json:"remaining_estimate"`
+	// PercentComplete is the share of the estimated work that is completed
+	PercentComplete float64 `json:"percent_complete"`
+
+	// EstimatedTasks counts the tasks with an estimate, UnestimatedOpenTasks the open tasks of unknown size
+	EstimatedTasks       int `json:"estimated_tasks"`
+	UnestimatedOpenTasks int `json:"unestimated_open_tasks"`
+
+	// Capacity is the work that can still be done, when given. The plan is over capacity when its remaining
+	// work exceeds it by Excess, and DeferCandidates lists the pending tasks to drop to fit, least important first.
+	Capacity        *float64             `json:"capacity,omitempty"`
+	OverCapacity    bool                 `json:"over_capacity"`
+	Excess          float64              `json:"excess,omitempty"`
+	DeferCandidates []*CapacityTaskEntry `json:"defer_candidates,omitempty"`
+
+	ComputedAt time.Time `json:"computed_at"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ChecklistItem is a lightweight micro-step inside a task
+type ChecklistItem struct {
+	ID        string    `json:"id"`
+	Text      string    `json:"text"`
+	Done      bool      `json:"done"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewChecklistItem creates a new, not yet done checklist item
+func NewChecklistItem(id, text string) *ChecklistItem {
+	return &ChecklistItem{
+		ID:        id,
+		Text:      text,
+		Done:      false,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Encode converts the checklist item to its stored JSON form
+func (c *ChecklistItem) Encode() (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode checklist item: %w", err)
+	}
+	return string(data), nil
+}
+
+// DecodeChecklistItem parses a checklist item from its stored JSON form
+func DecodeChecklistItem(data string) (*ChecklistItem, error) {
+	item := &ChecklistItem{}
+	if err := json.Unmarshal([]byte(data), item); err != nil {
+		return nil, fmt.Errorf("failed to decode checklist item: %w", err)
+	}
+	return item, nil
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorCode classifies errors so clients can handle them without matching messages
+type ErrorCode string
+
+const (
+	// ErrorCodeNotFound is used when a plan, task or other entity does not exist
+	ErrorCodeNotFound ErrorCode = "NOT_FOUND"
+	// ErrorCodeValidation is used for invalid arguments and changes that break a rule
+	ErrorCodeValidation ErrorCode = "VALIDATION"
+	// ErrorCodeConflict is used when a change clashes with the current state, such as a claimed task
+	ErrorCodeConflict ErrorCode = "CONFLICT"
+	// ErrorCodeStorage is used for failures of the storage itself, such as a lost connection
+	ErrorCodeStorage ErrorCode = "STORAGE"
+	// ErrorCodeRateLimited is used for requests rejected by the rate limit, which may be retried later
+	ErrorCodeRateLimited ErrorCode = "RATE_LIMITED"
+	// ErrorCodeTimeout is used for requests that did not finish in time, whose changes may still have been made
+	ErrorCodeTimeout ErrorCode = "TIMEOUT"
+	// ErrorCodeCancelled is used for changes the user declined when asked to confirm them
+	ErrorCodeCancelled ErrorCode = "CANCELLED"
+	// ErrorCodeForbidden is used for calls the role of the client does not allow
+	ErrorCodeForbidden ErrorCode = "FORBIDDEN"
+)
+
+// Entity names used in errors
+const (
+	EntityPlan                = "plan"
+	EntityTask                = "task"
+	EntityApplication         = "application"
+	EntityChecklistItem       = "checklist_item"
+	EntityNotesRevision       = "notes_revision"
+	EntityMilestone           = "milestone"
+	EntityAttachment          = "attachment"
+	EntityLink                = "link"
+	EntityWatcher             = "watcher"
+	EntityAcceptanceCriterion = "acceptance_criterion"
+	EntityRecordedChange      = "recorded_change"
+)
+
+// Error is an error with a machine-readable code and, where it is about one, the entity and its ID
+type Error struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+	Entity  string    `json:"entity,omitempty"`
+	ID      string    `json:"id,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// NewNotFoundError returns the error for an entity that does not exist, such as "plan not found: plan-123"
+func NewNotFoundError(entity, id string) *Error {
+	return &Error{
+		Code:    ErrorCodeNotFound,
+		Message: fmt.Sprintf("%s not found: %s", strings.ReplaceAll(entity, "_", " "), id),
+		Entity:  entity,
+		ID:      id,
+	}
+}
+
+// NewValidationError returns the error for an invalid value. Entity and id may be empty.
+func NewValidationError(entity, id, format string, args ...any) *Error {
+	return &Error{Code: ErrorCodeValidation, Message: fmt.Sprintf(format, args...), Entity: entity, ID: id}
+}
+
+// NewConflictError returns the error for a change that clashes with the current state of an entity
+func NewConflictError(entity, id, format string, args ...any) *Error {
+	return &Error{Code: ErrorCodeConflict, Message: fmt.Sprintf(format, args...), Entity: entity, ID: id}
+}
+
+// AsError returns the structured error in the chain of err. Errors without one are given the fallback code
+// and keep their message.
+func AsError(err error, fallback ErrorCode) *Error {
+	var structured *Error
+	if errors.As(err, &structured) {
+		return structured
+	}
+	return &Error{Code: fallback, Message: err.Error()}
+}
+
+// ErrorCodeOf returns the code of the structured error in the chain of err, or an empty code if it has none
+func ErrorCodeOf(err error) ErrorCode {
+	var structured *Error
+	if errors.As(err, &structured) {
+		return structured.Code
+	}
+	return ""
+}
+
+// IsNotFound reports whether err is about an entity that does not exist
+func IsNotFound(err error) bool {
+	return ErrorCodeOf(err) == ErrorCodeNotFound
+}
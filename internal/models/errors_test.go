@@ -0,0 +1,37 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestAsError(t *testing.T) {
+	wrapped := fmt.Errorf("failed to get plan: %w", NewNotFoundError(EntityPlan, "plan-1"))
+
+	structured := AsError(wrapped, ErrorCodeStorage)
+	if structured.Code != ErrorCodeNotFound || structured.Entity != EntityPlan || structured.ID != "plan-1" {
+		t.Errorf("unexpected structured error: %+v", structured)
+	}
+	if structured.Message != "plan not found: plan-1" {
+		t.Errorf("unexpected message: %s", structured.Message)
+	}
+	if !IsNotFound(wrapped) {
+		t.Error("expected a wrapped not found error to be reported as not found")
+	}
+
+	plain := AsError(errors.New("connection refused"), ErrorCodeStorage)
+	if plain.Code != ErrorCodeStorage || plain.Message != "connection refused" || plain.Entity != "" {
+		t.Errorf("unexpected fallback error: %+v", plain)
+	}
+	if ErrorCodeOf(errors.New("connection refused")) != "" {
+		t.Error("expected no code for an unstructured error")
+	}
+}
+
+func TestNewNotFoundErrorMessage(t *testing.T) {
+	err := NewNotFoundError(EntityChecklistItem, "item-1")
+	if err.Error() != "checklist item not found: item-1" {
+		t.Errorf("unexpected message: %s", err.Error())
+	}
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// LinkType tells what a link points at and how it relates to the plan or task it is on
+type LinkType string
+
+const (
+	// Links to code artifacts and documents, whose target is an http(s) URL
+	LinkTypePR     LinkType = "pr"
+	LinkTypeIssue  LinkType = "issue"
+	LinkTypeCommit LinkType = "commit"
+	LinkTypeDoc    LinkType = "doc"
+	LinkTypeURL    LinkType = "url"
+
+	// Links to other tasks, whose target is a task ID
+	LinkTypeRelatesTo  LinkType = "relates_to"
+	LinkTypeDuplicates LinkType = "duplicates"
+)
+
+// LinkTypes lists the link types in the order they are documented
+var LinkTypes = []LinkType{
+	LinkTypePR, LinkTypeIssue, LinkTypeCommit, LinkTypeDoc, LinkTypeURL, LinkTypeRelatesTo, LinkTypeDuplicates,
+}
+
+// MaxLinks is the maximum number of links on a plan or task
+const MaxLinks = 100
+
+// MaxLinkTargetLength is the maximum length of a link target
+const MaxLinkTargetLength = 2048
+
+// Link connects a plan or task to an external resource such as a pull request, or to another task
+type Link struct {
+	Type   LinkType `json:"type"`
+	Target string   `json:"target"`
+	// Title is an optional label, such as the title of the pull request
+	Title     string    `json:"title,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewLink creates a new link
+func NewLink(linkType LinkType, target, title string) *Link {
+	return &Link{
+		Type:      linkType,
+		Target:    strings.TrimSpace(target),
+		Title:     strings.TrimSpace(title),
+		CreatedAt: time.Now(),
+	}
+}
+
+// IsTaskLink reports whether the link points at another task rather than a URL
+func (l *Link) IsTaskLink() bool {
+	return l.Type == LinkTypeRelatesTo || l.Type == LinkTypeDuplicates
+}
+
+// Validate checks that the link has a known type and a target of the right kind: an absolute http(s) URL,
+// or a task ID for links to tasks. Whether the task exists is left to the caller.
+func (l *Link) Validate() error {
+	if !slices.Contains(LinkTypes, l.Type) {
+		return NewValidationError("", "", "invalid link type: %s", l.Type)
+	}
+	if l.Target == "" {
+		return NewValidationError("", "", "link target cannot be empty")
+	}
+	if len(l.Target) > MaxLinkTargetLength {
+		return NewValidationError("", "", "link target exceeds %d characters", MaxLinkTargetLength)
+	}
+
+	if l.IsTaskLink() {
+		if strings.ContainsAny(l.Target, " \t\r\n") {
+			return NewValidationError("", "", "invalid task ID: %q", l.Target)
+		}
+		return nil
+	}
+
+	target, err := url.Parse(l.Target)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return NewValidationError("", "", "%s links need an http or https URL: %q", l.Type, l.Target)
+	}
+	return nil
+}
+
+// LinkIndex returns the position of the link with the given type and target, or -1 if there is none
+func LinkIndex(links []*Link, linkType LinkType, target string) int {
+	return slices.IndexFunc(links, func(l *Link) bool {
+		return l.Type == linkType && l.Target == target
+	})
+}
+
+// encodeLinks converts links to their stored JSON form, or an empty string if there are none
+func encodeLinks(links []*Link) string {
+	if len(links) == 0 {
+		return ""
+	}
+	// Links only hold strings and times, which always encode
+	data, _ := json.Marshal(links) //nolint:errcheck
+	return string(data)
+}
+
+// decodeLinks parses links from their stored JSON form
+func decodeLinks(data string) ([]*Link, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var links []*Link
+	if err := json.Unmarshal([]byte(data), &links); err != nil {
+		return nil, fmt.Errorf("failed to decode links: %w", err)
+	}
+	return links, nil
+}
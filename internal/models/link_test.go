@@ -0,0 +1,63 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLinkValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		link    *Link
+		wantErr bool
+	}{
+		{"pull request", NewLink(LinkTypePR, "https://github.com/org/repo/pull/42", "Fix login"), false},
+		{"document", NewLink(LinkTypeDoc, " http://docs.example.com/design ", ""), false},
+		{"related task", NewLink(LinkTypeRelatesTo, "task-1", ""), false},
+		{"unknown type", NewLink("blocks", "task-1", ""), true},
+		{"empty target", NewLink(LinkTypeURL, "  ", ""), true},
+		{"relative URL", NewLink(LinkTypeDoc, "docs/design.md", ""), true},
+		{"other scheme", NewLink(LinkTypeCommit, "ftp://example.com/abc", ""), true},
+		{"task ID with spaces", NewLink(LinkTypeDuplicates, "task 1", ""), true},
+		{"long URL", NewLink(LinkTypeURL, "https://example.com/"+strings.Repeat("a", MaxLinkTargetLength), ""), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.link.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && ErrorCodeOf(err) != ErrorCodeValidation {
+				t.Errorf("ErrorCodeOf() = %v, want %v", ErrorCodeOf(err), ErrorCodeValidation)
+			}
+		})
+	}
+}
+
+func TestLinksRoundTrip(t *testing.T) {
+	task := NewTask("task-1", "plan-1", "Task", "", TaskPriorityMedium)
+	task.Links = []*Link{
+		NewLink(LinkTypePR, "https://github.com/org/repo/pull/42", "Fix login"),
+		NewLink(LinkTypeRelatesTo, "task-2", ""),
+	}
+
+	restored := &Task{}
+	if err := restored.FromMap(task.ToMap()); err != nil {
+		t.Fatalf("FromMap() error = %v", err)
+	}
+	if len(restored.Links) != 2 || restored.Links[0].Title != "Fix login" || restored.Links[1].Target != "task-2" {
+		t.Errorf("Links = %+v", restored.Links)
+	}
+	if LinkIndex(restored.Links, LinkTypeRelatesTo, "task-2") != 1 || LinkIndex(restored.Links, LinkTypePR, "task-2") != -1 {
+		t.Error("LinkIndex() should match links by type and target")
+	}
+
+	plan := &Plan{}
+	if err := plan.FromMap(NewPlan("plan-1", "app-1", "Plan", "").ToMap()); err != nil {
+		t.Fatalf("FromMap() error = %v", err)
+	}
+	if plan.Links != nil {
+		t.Errorf("expected no links, got %+v", plan.Links)
+	}
+}
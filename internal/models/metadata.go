@@ -0,0 +1,48 @@
+package models
+
+import (
+	"strings"
+)
+
+// MetadataPrefix is prepended to metadata keys when they are stored in a plan or task hash
+const MetadataPrefix = "meta:"
+
+// MaxMetadataKeyLength is the maximum length of a metadata key
+const MaxMetadataKeyLength = 128
+
+// ValidateMetadataKey checks that a metadata key is non-empty, reasonably short and free of whitespace
+func ValidateMetadataKey(key string) error {
+	if key == "" {
+		return NewValidationError("", "", "metadata key cannot be empty")
+	}
+	if len(key) > MaxMetadataKeyLength {
+		return NewValidationError("", "", "metadata key %q exceeds %d characters", key, MaxMetadataKeyLength)
+	}
+	if strings.ContainsAny(key, " \t\r\n") {
+		return NewValidationError("", "", "metadata key %q cannot contain whitespace", key)
+	}
+	return nil
+}
+
+// addMetadataFields stores metadata entries in a storage map under the metadata prefix
+func addMetadataFields(fields, metadata map[string]string) {
+	for key, value := range metadata {
+		fields[MetadataPrefix+key] = value
+	}
+}
+
+// metadataFromFields extracts the metadata entries from a storage map
+func metadataFromFields(data map[string]string) map[string]string {
+	var metadata map[string]string
+	for field, value := range data {
+		key, ok := strings.CutPrefix(field, MetadataPrefix)
+		if !ok {
+			continue
+		}
+		if metadata == nil {
+			metadata = make(map[string]string)
+		}
+		metadata[key] = value
+	}
+	return metadata
+}
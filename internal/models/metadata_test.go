@@ -0,0 +1,63 @@
+package models
+
+import (
+	"maps"
+	"testing"
+	"time"
+)
+
+func TestValidateMetadataKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "Simple key", key: "repo_url", wantErr: false},
+		{name: "Dotted key", key: "github.pr", wantErr: false},
+		{name: "Empty key", key: "", wantErr: true},
+		{name: "Key with whitespace", key: "pr number", wantErr: true},
+		{name: "Key too long", key: string(make([]byte, MaxMetadataKeyLength+1)), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMetadataKey(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMetadataKey(%q) error = %v, wantErr %v", tt.key, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTaskMetadataRoundTrip(t *testing.T) {
+	task := NewTask("task-1", "plan-1", "Title", "Description", TaskPriorityMedium)
+	task.CreatedAt = task.CreatedAt.Truncate(time.Second)
+	task.UpdatedAt = task.UpdatedAt.Truncate(time.Second)
+	task.Metadata = map[string]string{"repo_url": "https://example.com/repo", "pr": "42"}
+
+	data := task.ToMap()
+	if data["meta:pr"] != "42" {
+		t.Fatalf("expected metadata to be stored with the meta: prefix, got %v", data)
+	}
+
+	restored := &Task{}
+	if err := restored.FromMap(data); err != nil {
+		t.Fatalf("FromMap() error = %v", err)
+	}
+	if !maps.Equal(task.Metadata, restored.Metadata) {
+		t.Errorf("Metadata = %v, want %v", restored.Metadata, task.Metadata)
+	}
+}
+
+func TestPlanMetadataRoundTrip(t *testing.T) {
+	plan := NewPlan("plan-1", "app-1", "Plan", "Description")
+	plan.Metadata = map[string]string{"ticket": "PROJ-123"}
+
+	restored := &Plan{}
+	if err := restored.FromMap(plan.ToMap()); err != nil {
+		t.Fatalf("FromMap() error = %v", err)
+	}
+	if !maps.Equal(plan.Metadata, restored.Metadata) {
+		t.Errorf("Metadata = %v, want %v", restored.Metadata, plan.Metadata)
+	}
+}
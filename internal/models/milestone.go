@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Milestone is a named date within a plan that a set of its tasks must be completed by
+type Milestone struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Date    time.Time `json:"date"`
+	TaskIDs []string  `json:"task_ids,omitempty"`
+}
+
+// NewMilestone creates a new milestone linked to the given tasks
+func NewMilestone(id, name string, date time.Time, taskIDs []string) *Milestone {
+	return &Milestone{
+		ID:      id,
+		Name:    name,
+		Date:    date,
+		TaskIDs: taskIDs,
+	}
+}
+
+// Milestone returns the milestone of the plan with the given ID, or nil if there is none
+func (p *Plan) Milestone(id string) *Milestone {
+	index := slices.IndexFunc(p.Milestones, func(m *Milestone) bool { return m.ID == id })
+	if index < 0 {
+		return nil
+	}
+	return p.Milestones[index]
+}
+
+// SortMilestones orders the milestones of the plan by date, keeping the order of milestones on the same date
+func (p *Plan) SortMilestones() {
+	slices.SortStableFunc(p.Milestones, func(a, b *Milestone) int {
+		return a.Date.Compare(b.Date)
+	})
+}
+
+// encodeMilestones converts milestones to their stored JSON form, or an empty string if there are none
+func encodeMilestones(milestones []*Milestone) string {
+	if len(milestones) == 0 {
+		return ""
+	}
+	// Milestones only hold strings and times, which always encode
+	data, _ := json.Marshal(milestones) //nolint:errcheck
+	return string(data)
+}
+
+// decodeMilestones parses milestones from their stored JSON form
+func decodeMilestones(data string) ([]*Milestone, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var milestones []*Milestone
+	if err := json.Unmarshal([]byte(data), &milestones); err != nil {
+		return nil, fmt.Errorf("failed to decode milestones: %w", err)
+	}
+	return milestones, nil
+}
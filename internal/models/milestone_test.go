@@ -0,0 +1,52 @@
+package models
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPlanScheduleRoundTrip(t *testing.T) {
+	start := time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)
+	target := start.AddDate(0, 1, 0)
+
+	plan := NewPlan("plan-1", "app-1", "Plan", "Scheduled plan")
+	plan.StartDate = &start
+	plan.TargetDate = &target
+	plan.Milestones = []*Milestone{
+		NewMilestone("m2", "Beta", target, nil),
+		NewMilestone("m1", "Alpha", start.AddDate(0, 0, 14), []string{"task-1", "task-2"}),
+	}
+	plan.SortMilestones()
+
+	restored := &Plan{}
+	if err := restored.FromMap(plan.ToMap()); err != nil {
+		t.Fatalf("FromMap() error = %v", err)
+	}
+
+	if restored.StartDate == nil || !restored.StartDate.Equal(start) {
+		t.Errorf("StartDate = %v, want %v", restored.StartDate, start)
+	}
+	if restored.TargetDate == nil || !restored.TargetDate.Equal(target) {
+		t.Errorf("TargetDate = %v, want %v", restored.TargetDate, target)
+	}
+	if len(restored.Milestones) != 2 || restored.Milestones[0].ID != "m1" {
+		t.Fatalf("Milestones = %+v, want Alpha before Beta", restored.Milestones)
+	}
+	if !slices.Equal(restored.Milestone("m1").TaskIDs, []string{"task-1", "task-2"}) {
+		t.Errorf("TaskIDs = %v", restored.Milestone("m1").TaskIDs)
+	}
+	if restored.Milestone("missing") != nil {
+		t.Error("Milestone() should return nil for an unknown ID")
+	}
+}
+
+func TestPlanWithoutSchedule(t *testing.T) {
+	restored := &Plan{}
+	if err := restored.FromMap(NewPlan("plan-1", "app-1", "Plan", "").ToMap()); err != nil {
+		t.Fatalf("FromMap() error = %v", err)
+	}
+	if restored.StartDate != nil || restored.TargetDate != nil || restored.Milestones != nil {
+		t.Errorf("expected no schedule, got %+v", restored)
+	}
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// NotesRevision is a saved version of the notes of a plan
+type NotesRevision struct {
+	// ID identifies the revision, for reverting to it
+	ID      string    `json:"id"`
+	Notes   string    `json:"notes"`
+	Actor   string    `json:"actor"`
+	SavedAt time.Time `json:"saved_at"`
+}
+
+// ArchivedNotes holds the older notes of a plan or task that were moved out of its notes to keep them short
+type ArchivedNotes struct {
+	EntityType EntityType `json:"entity_type"`
+	EntityID   string     `json:"entity_id"`
+	// Content is everything archived, oldest first
+	Content string `json:"content"`
+	// Summary condenses the content when a summarizer is configured
+	Summary      string     `json:"summary,omitempty"`
+	ArchivedAt   *time.Time `json:"archived_at,omitempty"`
+	SummarizedAt *time.Time `json:"summarized_at,omitempty"`
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MaxWatcherLength is the maximum length of a watcher identifier
+const MaxWatcherLength = 128
+
+// mentionRegex matches @mentions such as @alice or @agent.reviewer at the start of a word
+var mentionRegex = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9][\w.\-]*[\w])`)
+
+// mentionFields are the fields of plans and tasks whose text is searched for mentions
+var mentionFields = []string{"title", "name", "description", "notes"}
+
+// ValidateWatcher checks that a watcher identifier, such as an agent name or user handle, is non-empty,
+// reasonably short and free of whitespace
+func ValidateWatcher(watcher string) error {
+	if watcher == "" {
+		return NewValidationError("", "", "watcher cannot be empty")
+	}
+	if len(watcher) > MaxWatcherLength {
+		return NewValidationError("", "", "watcher %q exceeds %d characters", watcher, MaxWatcherLength)
+	}
+	if strings.ContainsAny(watcher, " \t\r\n") {
+		return NewValidationError("", "", "watcher %q cannot contain whitespace", watcher)
+	}
+	return nil
+}
+
+// PlanWatchers lists the watchers of a plan
+type PlanWatchers struct {
+	PlanID   string   `json:"plan_id"`
+	Watchers []string `json:"watchers"`
+}
+
+// Notification tells the watchers of a plan, and anyone mentioned in the change, that a plan or one of its
+// tasks changed
+type Notification struct {
+	EntityType EntityType  `json:"entity_type"`
+	EntityID   string      `json:"entity_id"`
+	PlanID     string      `json:"plan_id"`
+	Title      string      `json:"title"`
+	Action     AuditAction `json:"action"`
+	Operation  string      `json:"operation"`
+	Actor      string      `json:"actor"`
+	Timestamp  time.Time   `json:"timestamp"`
+	// ChangedFields are the names of the changed fields, sorted
+	ChangedFields []string `json:"changed_fields,omitempty"`
+	// Watchers are the watchers of the plan, other than the actor
+	Watchers []string `json:"watchers,omitempty"`
+	// Mentions are the identifiers newly @mentioned in the title, description or notes, other than the actor
+	Mentions []string `json:"mentions,omitempty"`
+}
+
+// Recipients returns the watchers and mentioned identifiers, sorted and without duplicates
+func (n *Notification) Recipients() []string {
+	recipients := append(slices.Clone(n.Watchers), n.Mentions...)
+	slices.Sort(recipients)
+	return slices.Compact(recipients)
+}
+
+// Summary describes the notification in a line, such as for a chat message
+func (n *Notification) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %q: %s by %s", n.EntityType, n.Title, n.Operation, n.Actor)
+	if len(n.ChangedFields) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(n.ChangedFields, ", "))
+	}
+	if recipients := n.Recipients(); len(recipients) > 0 {
+		b.WriteString(" cc")
+		for _, recipient := range recipients {
+			b.WriteString(" @" + recipient)
+		}
+	}
+	return b.String()
+}
+
+// NewMentions returns the identifiers @mentioned in the text fields of the after snapshot of an audit entry
+// that were not mentioned in the same field before, sorted and without duplicates
+func NewMentions(entry *AuditEntry) []string {
+	var mentions []string
+	for _, field := range mentionFields {
+		change, ok := entry.Changes[field]
+		if !ok {
+			continue
+		}
+		after, _ := change.After.(string)
+		before, _ := change.Before.(string)
+		previous := Mentions(before)
+		for _, mention := range Mentions(after) {
+			if !slices.Contains(previous, mention) {
+				mentions = append(mentions, mention)
+			}
+		}
+	}
+	slices.Sort(mentions)
+	return slices.Compact(mentions)
+}
+
+// Mentions returns the identifiers @mentioned in a text, in order of appearance
+func Mentions(text string) []string {
+	var mentions []string
+	for _, match := range mentionRegex.FindAllStringSubmatch(text, -1) {
+		mentions = append(mentions, match[1])
+	}
+	return mentions
+}
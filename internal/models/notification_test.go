@@ -0,0 +1,24 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMentions(t *testing.T) {
+	got := Mentions("@alice, ask @agent.reviewer (cc @bob-2) but not bob@example.com or @@x or a trailing @")
+	if want := []string{"alice", "agent.reviewer", "bob-2"}; !slices.Equal(got, want) {
+		t.Errorf("Mentions() = %v, want %v", got, want)
+	}
+}
+
+func TestNewMentions(t *testing.T) {
+	entry := &AuditEntry{Changes: map[string]FieldChange{
+		"notes":  {Before: "@alice started", After: "@alice started, @carol to review"},
+		"status": {Before: "pending", After: "@dave"},
+		"title":  {Before: nil, After: "Pair with @bob and @carol"},
+	}}
+	if got, want := NewMentions(entry), []string{"bob", "carol"}; !slices.Equal(got, want) {
+		t.Errorf("NewMentions() = %v, want %v", got, want)
+	}
+}
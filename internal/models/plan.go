@@ -1,6 +1,9 @@
 package models
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -14,6 +17,15 @@ const (
 	PlanStatusCancelled  PlanStatus = "cancelled"
 )
 
+// PlanPriority represents how urgent a plan is compared to the other plans of its application
+type PlanPriority string
+
+const (
+	PlanPriorityLow    PlanPriority = "low"
+	PlanPriorityMedium PlanPriority = "medium"
+	PlanPriorityHigh   PlanPriority = "high"
+)
+
 // Plan represents a collection of related tasks
 type Plan struct {
 	ID            string     `json:"id"`
@@ -24,6 +36,229 @@ type Plan struct {
 	Status        PlanStatus `json:"status"`
 	CreatedAt     time.Time  `json:"created_at"`
 	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// Priority and Order rank the plan among the plans of its application, which are listed by their order
+	Priority PlanPriority `json:"priority"`
+	Order    int          `json:"order"`
+
+	// Schedule of the plan: when work starts, when it should be done, and the milestones in between by date
+	StartDate  *time.Time   `json:"start_date,omitempty"`
+	TargetDate *time.Time   `json:"target_date,omitempty"`
+	Milestones []*Milestone `json:"milestones,omitempty"`
+
+	// Arbitrary key/value metadata such as a repository URL or ticket ID
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Typed links to pull requests, documents and related tasks
+	Links []*Link `json:"links,omitempty"`
+
+	// TaskCounts counts the tasks of the plan by status. The counters are kept in the plan hash by the task
+	// repository and are not written by ToMap; they are nil for plans stored before counting existed.
+	TaskCounts *TaskCounts `json:"task_counts,omitempty"`
+
+	// Lock is set while the plan is locked. It is kept in the plan hash by the lock_plan and unlock_plan tools
+	// and is not written by ToMap, so updates cannot lock or unlock a plan.
+	Lock *PlanLock `json:"lock,omitempty"`
+}
+
+// PlanLock freezes a plan, such as while it is reviewed or executed: only admins may change the plan or its
+// tasks until it is unlocked
+type PlanLock struct {
+	LockedBy string    `json:"locked_by"`
+	Reason   string    `json:"reason,omitempty"`
+	LockedAt time.Time `json:"locked_at"`
+}
+
+// Plan hash fields of the lock of a plan
+const (
+	PlanLockedAtField   = "locked_at"
+	PlanLockedByField   = "locked_by"
+	PlanLockReasonField = "lock_reason"
+)
+
+// Fields returns the lock as plan hash fields
+func (l *PlanLock) Fields() map[string]string {
+	return map[string]string{
+		PlanLockedAtField:   l.LockedAt.Format(time.RFC3339),
+		PlanLockedByField:   l.LockedBy,
+		PlanLockReasonField: l.Reason,
+	}
+}
+
+// PlanLockFromFields reads the lock of a plan from a plan hash, or returns nil if the plan is not locked
+func PlanLockFromFields(data map[string]string) (*PlanLock, error) {
+	if data[PlanLockedAtField] == "" {
+		return nil, nil
+	}
+	lockedAt, err := time.Parse(time.RFC3339, data[PlanLockedAtField])
+	if err != nil {
+		return nil, fmt.Errorf("invalid lock time: %w", err)
+	}
+	return &PlanLock{LockedBy: data[PlanLockedByField], Reason: data[PlanLockReasonField], LockedAt: lockedAt}, nil
+}
+
+// TaskCounts counts the tasks of a plan in total and by status
+type TaskCounts struct {
+	Total      int `json:"total"`
+	Pending    int `json:"pending"`
+	InProgress int `json:"in_progress"`
+	Completed  int `json:"completed"`
+	Cancelled  int `json:"cancelled"`
+
+	// Tasks in the statuses of the review workflow
+	InReview int `json:"in_review,omitempty"`
+	Approved int `json:"approved,omitempty"`
+	Rejected int `json:"rejected,omitempty"`
+
+	// Tasks in the custom statuses configured by the operator
+	Custom map[TaskStatus]int `json:"custom,omitempty"`
+}
+
+// TaskCountPrefix is prepended to the names of the task counters stored in a plan hash
+const TaskCountPrefix = "task_count:"
+
+// TaskCountTotalField is the plan hash field counting all tasks of the plan
+const TaskCountTotalField = TaskCountPrefix + "total"
+
+// TaskCountField returns the plan hash field counting the tasks with the given status
+func TaskCountField(status TaskStatus) string {
+	return TaskCountPrefix + string(status)
+}
+
+// counter returns the counter of a built-in status, or nil for custom statuses
+func (c *TaskCounts) counter(status TaskStatus) *int {
+	switch status {
+	case TaskStatusPending:
+		return &c.Pending
+	case TaskStatusInProgress:
+		return &c.InProgress
+	case TaskStatusCompleted:
+		return &c.Completed
+	case TaskStatusCancelled:
+		return &c.Cancelled
+	case TaskStatusInReview:
+		return &c.InReview
+	case TaskStatusApproved:
+		return &c.Approved
+	case TaskStatusRejected:
+		return &c.Rejected
+	}
+	return nil
+}
+
+// countedStatuses are the statuses with a counter of their own, which every plan hash stores
+var countedStatuses = []TaskStatus{
+	TaskStatusPending, TaskStatusInProgress, TaskStatusCompleted, TaskStatusCancelled,
+	TaskStatusInReview, TaskStatusApproved, TaskStatusRejected,
+}
+
+// Fields returns the counters as plan hash fields
+func (c *TaskCounts) Fields() map[string]string {
+	fields := map[string]string{TaskCountTotalField: strconv.Itoa(c.Total)}
+	for _, status := range countedStatuses {
+		fields[TaskCountField(status)] = strconv.Itoa(*c.counter(status))
+	}
+	for status, count := range c.Custom {
+		fields[TaskCountField(status)] = strconv.Itoa(count)
+	}
+	return fields
+}
+
+// Add counts a task with the given status, or removes it from the counts when delta is negative
+func (c *TaskCounts) Add(status TaskStatus, delta int) {
+	c.Total += delta
+	if counter := c.counter(status); counter != nil {
+		*counter += delta
+		return
+	}
+	if c.Custom == nil {
+		c.Custom = make(map[TaskStatus]int)
+	}
+	c.Custom[status] += delta
+}
+
+// Count returns the number of tasks with a status
+func (c *TaskCounts) Count(status TaskStatus) int {
+	if counter := c.counter(status); counter != nil {
+		return *counter
+	}
+	return c.Custom[status]
+}
+
+// Equal reports whether two counts are the same, custom statuses without tasks included
+func (c *TaskCounts) Equal(other *TaskCounts) bool {
+	if c.Total != other.Total {
+		return false
+	}
+	for _, status := range countedStatuses {
+		if c.Count(status) != other.Count(status) {
+			return false
+		}
+	}
+	for _, counts := range []*TaskCounts{c, other} {
+		for status := range counts.Custom {
+			if c.Count(status) != other.Count(status) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// PlanStatus derives the status of a plan from its task counts: completed once all of its tasks are completed,
+// in progress while any task is in progress, in review or in an active custom status, and new otherwise
+func (c *TaskCounts) PlanStatus() PlanStatus {
+	switch {
+	case c.Total == 0:
+		return PlanStatusNew
+	case c.Completed == c.Total:
+		return PlanStatusCompleted
+	case c.Active() > 0:
+		return PlanStatusInProgress
+	default:
+		return PlanStatusNew
+	}
+}
+
+// Active returns the number of tasks being worked on: in a status of the active category, such as in
+// progress or in review, where approved tasks still wait to be completed and rejected ones to be reworked
+func (c *TaskCounts) Active() int {
+	active := 0
+	for _, status := range ActiveTaskStatuses() {
+		active += c.Count(status)
+	}
+	return active
+}
+
+// TaskCountsFromFields reads the task counters from a plan hash, or returns nil if they are not stored
+func TaskCountsFromFields(data map[string]string) (*TaskCounts, error) {
+	if _, ok := data[TaskCountTotalField]; !ok {
+		return nil, nil
+	}
+	counts := &TaskCounts{}
+	for field, value := range data {
+		name, ok := strings.CutPrefix(field, TaskCountPrefix)
+		if !ok || value == "" {
+			continue
+		}
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid task counter %s: %w", field, err)
+		}
+		status := TaskStatus(name)
+		switch counter := counts.counter(status); {
+		case field == TaskCountTotalField:
+			counts.Total = count
+		case counter != nil:
+			*counter = count
+		case count != 0:
+			if counts.Custom == nil {
+				counts.Custom = make(map[TaskStatus]int)
+			}
+			counts.Custom[status] = count
+		}
+	}
+	return counts, nil
 }
 
 // NewPlan creates a new plan with the given name and description
@@ -36,6 +271,7 @@ func NewPlan(id, applicationID, name, description string) *Plan {
 		Description:   description,
 		Notes:         "",
 		Status:        PlanStatusNew,
+		Priority:      PlanPriorityMedium,
 		CreatedAt:     now,
 		UpdatedAt:     now,
 	}
@@ -43,16 +279,34 @@ func NewPlan(id, applicationID, name, description string) *Plan {
 
 // ToMap converts the plan to a map for storage in Valkey
 func (p *Plan) ToMap() map[string]string {
-	return map[string]string{
+	startDate := ""
+	if p.StartDate != nil {
+		startDate = p.StartDate.Format(time.RFC3339)
+	}
+	targetDate := ""
+	if p.TargetDate != nil {
+		targetDate = p.TargetDate.Format(time.RFC3339)
+	}
+
+	fields := map[string]string{
 		"id":             p.ID,
 		"application_id": p.ApplicationID,
 		"name":           p.Name,
 		"description":    p.Description,
 		"notes":          p.Notes,
 		"status":         string(p.Status),
+		"priority":       string(p.Priority),
+		"order":          strconv.Itoa(p.Order),
+		"start_date":     startDate,
+		"target_date":    targetDate,
+		"milestones":     encodeMilestones(p.Milestones),
+		"links":          encodeLinks(p.Links),
 		"created_at":     p.CreatedAt.Format(time.RFC3339),
 		"updated_at":     p.UpdatedAt.Format(time.RFC3339),
 	}
+	addMetadataFields(fields, p.Metadata)
+
+	return fields
 }
 
 // FromMap populates a plan from a map retrieved from Valkey
@@ -77,6 +331,46 @@ func (p *Plan) FromMap(data map[string]string) error {
 		p.Status = PlanStatusNew
 	}
 
+	// Plans created before priorities and ordering existed are medium priority and first in order
+	p.Priority = PlanPriorityMedium
+	if priority, ok := data["priority"]; ok && priority != "" {
+		p.Priority = PlanPriority(priority)
+	}
+	p.Order = 0
+	if data["order"] != "" {
+		order, err := strconv.Atoi(data["order"])
+		if err != nil {
+			return err
+		}
+		p.Order = order
+	}
+
+	p.StartDate = nil
+	if data["start_date"] != "" {
+		startDate, err := time.Parse(time.RFC3339, data["start_date"])
+		if err != nil {
+			return err
+		}
+		p.StartDate = &startDate
+	}
+	p.TargetDate = nil
+	if data["target_date"] != "" {
+		targetDate, err := time.Parse(time.RFC3339, data["target_date"])
+		if err != nil {
+			return err
+		}
+		p.TargetDate = &targetDate
+	}
+	milestones, err := decodeMilestones(data["milestones"])
+	if err != nil {
+		return err
+	}
+	p.Milestones = milestones
+	p.Links, err = decodeLinks(data["links"])
+	if err != nil {
+		return err
+	}
+
 	createdAt, err := time.Parse(time.RFC3339, data["created_at"])
 	if err != nil {
 		return err
@@ -89,5 +383,16 @@ func (p *Plan) FromMap(data map[string]string) error {
 	}
 	p.UpdatedAt = updatedAt
 
+	p.Metadata = metadataFromFields(data)
+
+	p.TaskCounts, err = TaskCountsFromFields(data)
+	if err != nil {
+		return err
+	}
+	p.Lock, err = PlanLockFromFields(data)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,257 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Task matches in a plan diff
+const (
+	// DiffMatchID matches tasks with the same ID, such as a plan and an earlier snapshot of it
+	DiffMatchID = "id"
+	// DiffMatchTitle matches tasks with the same normalized title, such as a plan and a regenerated copy
+	DiffMatchTitle = "title"
+)
+
+// planDiffIgnoredFields are plan fields that identify or count rather than describe a plan
+var planDiffIgnoredFields = []string{"id", "created_at", "updated_at", "order", "task_counts"}
+
+// taskDiffIgnoredFields are task fields that identify a task or are stamped by the server. The order is
+// compared for the plan as a whole, as inserting one task shifts the order of every task after it.
+var taskDiffIgnoredFields = []string{
+	"id", "plan_id", "order", "created_at", "updated_at", "started_at", "completed_at", "time_spent",
+	"next_occurrence_id", "lease_owner", "lease_expires_at",
+}
+
+// PlanDiff describes how a plan differs from a base version, such as another plan or an exported snapshot
+type PlanDiff struct {
+	BasePlanID string `json:"base_plan_id"`
+	PlanID     string `json:"plan_id"`
+	// PlanChanges are the changed fields of the plan itself
+	PlanChanges map[string]FieldChange `json:"plan_changes,omitempty"`
+	// AddedTasks are the tasks without a match in the base, in their order in the plan
+	AddedTasks []*Task `json:"added_tasks"`
+	// RemovedTasks are the tasks of the base without a match in the plan, in their order in the base
+	RemovedTasks []*Task `json:"removed_tasks"`
+	// ChangedTasks are the matched tasks whose fields differ, in their order in the plan
+	ChangedTasks []*TaskDiff `json:"changed_tasks"`
+	// UnchangedTasks is the number of matched tasks without differences
+	UnchangedTasks int `json:"unchanged_tasks"`
+	// Reordered is set when matched tasks come in a different order than in the base
+	Reordered bool `json:"reordered"`
+}
+
+// TaskDiff describes how a task differs from the task it was matched with in the base
+type TaskDiff struct {
+	BaseID string `json:"base_id"`
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	// MatchedBy tells whether the tasks were matched by ID or by title
+	MatchedBy string                 `json:"matched_by"`
+	Changes   map[string]FieldChange `json:"changes"`
+}
+
+// DiffPlans compares a plan and its tasks with a base version. Tasks are matched by ID first, then tasks left
+// over by their normalized title, so plans regenerated with new IDs still line up. Dependencies and links to
+// tasks are compared through the matches, so a dependency on the matching task does not count as a change.
+// Notes left out of either version for their length are not compared.
+func DiffPlans(base, target *PlanResource) (*PlanDiff, error) {
+	if base == nil || base.Plan == nil || target == nil || target.Plan == nil {
+		return nil, fmt.Errorf("both versions need a plan")
+	}
+
+	diff := &PlanDiff{
+		BasePlanID:   base.Plan.ID,
+		PlanID:       target.Plan.ID,
+		AddedTasks:   []*Task{},
+		RemovedTasks: []*Task{},
+		ChangedTasks: []*TaskDiff{},
+	}
+
+	planChanges, err := diffFields(base.Plan, target.Plan, diffIgnoredFields(planDiffIgnoredFields,
+		detachedNotes(base, base.Plan.ID) || detachedNotes(target, target.Plan.ID)))
+	if err != nil {
+		return nil, err
+	}
+	if len(planChanges) > 0 {
+		diff.PlanChanges = planChanges
+	}
+
+	matches, matchedBy := matchTasks(base.Tasks, target.Tasks)
+
+	// baseIDs maps the IDs of matched base tasks to their matches, to compare dependencies
+	baseIDs := make(map[string]string, len(matches))
+	for targetIndex, baseIndex := range matches {
+		baseIDs[base.Tasks[baseIndex].ID] = target.Tasks[targetIndex].ID
+	}
+
+	matchedBase := make(map[int]bool, len(matches))
+	lastBaseIndex := -1
+	for i, task := range target.Tasks {
+		baseIndex, ok := matches[i]
+		if !ok {
+			diff.AddedTasks = append(diff.AddedTasks, task)
+			continue
+		}
+		matchedBase[baseIndex] = true
+		if baseIndex < lastBaseIndex {
+			diff.Reordered = true
+		}
+		lastBaseIndex = baseIndex
+
+		baseTask := *base.Tasks[baseIndex]
+		if len(baseTask.DependsOn) > 0 {
+			baseTask.DependsOn = make([]string, len(base.Tasks[baseIndex].DependsOn))
+			for j, id := range base.Tasks[baseIndex].DependsOn {
+				if mapped, ok := baseIDs[id]; ok {
+					id = mapped
+				}
+				baseTask.DependsOn[j] = id
+			}
+		}
+		if len(baseTask.Links) > 0 {
+			baseTask.Links = make([]*Link, len(base.Tasks[baseIndex].Links))
+			for j, link := range base.Tasks[baseIndex].Links {
+				if mapped, ok := baseIDs[link.Target]; ok && link.IsTaskLink() {
+					mappedLink := *link
+					mappedLink.Target = mapped
+					link = &mappedLink
+				}
+				baseTask.Links[j] = link
+			}
+		}
+
+		ignored := diffIgnoredFields(taskDiffIgnoredFields, detachedNotes(base, baseTask.ID) || detachedNotes(target, task.ID))
+		changes, err := diffFields(&baseTask, task, ignored)
+		if err != nil {
+			return nil, err
+		}
+		if len(changes) == 0 {
+			diff.UnchangedTasks++
+			continue
+		}
+		diff.ChangedTasks = append(diff.ChangedTasks, &TaskDiff{
+			BaseID:    baseTask.ID,
+			ID:        task.ID,
+			Title:     task.Title,
+			MatchedBy: matchedBy[i],
+			Changes:   changes,
+		})
+	}
+
+	for i, task := range base.Tasks {
+		if !matchedBase[i] {
+			diff.RemovedTasks = append(diff.RemovedTasks, task)
+		}
+	}
+	return diff, nil
+}
+
+// detachedNotes reports whether the notes of a plan or task were left out of a resource for their length
+func detachedNotes(resource *PlanResource, id string) bool {
+	_, ok := resource.NotesURIs[id]
+	return ok
+}
+
+// diffIgnoredFields returns the ignored fields, adding the notes when they cannot be compared
+func diffIgnoredFields(ignored []string, ignoreNotes bool) []string {
+	if !ignoreNotes {
+		return ignored
+	}
+	return append(append([]string{}, ignored...), "notes")
+}
+
+// matchTasks pairs the tasks of the target with tasks of the base, returning the index of the base task for
+// the index of each matched target task and how it was matched
+func matchTasks(base, target []*Task) (map[int]int, map[int]string) {
+	matches := make(map[int]int, len(target))
+	matchedBy := make(map[int]string, len(target))
+	used := make(map[int]bool, len(base))
+
+	byID := make(map[string]int, len(base))
+	for i, task := range base {
+		byID[task.ID] = i
+	}
+	for i, task := range target {
+		if baseIndex, ok := byID[task.ID]; ok {
+			matches[i] = baseIndex
+			matchedBy[i] = DiffMatchID
+			used[baseIndex] = true
+		}
+	}
+
+	// Tasks with the same title are paired in their order
+	byTitle := make(map[string][]int)
+	for i, task := range base {
+		if !used[i] {
+			title := NormalizeTitle(task.Title)
+			byTitle[title] = append(byTitle[title], i)
+		}
+	}
+	for i, task := range target {
+		if _, ok := matches[i]; ok {
+			continue
+		}
+		title := NormalizeTitle(task.Title)
+		if candidates := byTitle[title]; len(candidates) > 0 {
+			matches[i] = candidates[0]
+			matchedBy[i] = DiffMatchTitle
+			byTitle[title] = candidates[1:]
+		}
+	}
+	return matches, matchedBy
+}
+
+// diffFields compares the JSON encodings of two values, leaving out the ignored fields, the IDs and creation
+// times of checklist items, the creation times of links and the IDs of acceptance criteria, which differ
+// between copies of a task
+func diffFields(before, after any, ignored []string) (map[string]FieldChange, error) {
+	beforeJSON, err := diffSnapshot(before, ignored)
+	if err != nil {
+		return nil, err
+	}
+	afterJSON, err := diffSnapshot(after, ignored)
+	if err != nil {
+		return nil, err
+	}
+	return DiffSnapshots(beforeJSON, afterJSON)
+}
+
+// diffSnapshot encodes a value for diffFields
+func diffSnapshot(value any, ignored []string) (json.RawMessage, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	for _, field := range ignored {
+		delete(fields, field)
+	}
+	if checklist, ok := fields["checklist"].([]any); ok {
+		for _, item := range checklist {
+			if itemFields, ok := item.(map[string]any); ok {
+				delete(itemFields, "id")
+				delete(itemFields, "created_at")
+			}
+		}
+	}
+	if links, ok := fields["links"].([]any); ok {
+		for _, link := range links {
+			if linkFields, ok := link.(map[string]any); ok {
+				delete(linkFields, "created_at")
+			}
+		}
+	}
+	if criteria, ok := fields["acceptance_criteria"].([]any); ok {
+		for _, criterion := range criteria {
+			if criterionFields, ok := criterion.(map[string]any); ok {
+				delete(criterionFields, "id")
+			}
+		}
+	}
+	return json.Marshal(fields)
+}
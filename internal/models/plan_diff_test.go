@@ -0,0 +1,99 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffPlans(t *testing.T) {
+	base := &PlanResource{
+		Plan: &Plan{ID: "p1", Name: "Plan", Priority: PlanPriorityMedium},
+		Tasks: []*Task{
+			{ID: "t1", PlanID: "p1", Title: "Design", Status: TaskStatusCompleted, Order: 0},
+			{ID: "t2", PlanID: "p1", Title: "Build", Status: TaskStatusPending, Order: 1, DependsOn: []string{"t1"}},
+			{ID: "t3", PlanID: "p1", Title: "Document", Status: TaskStatusPending, Order: 2},
+		},
+	}
+	// A regenerated plan with new IDs: Design is unchanged, Build gets a priority, Document is dropped and
+	// Test is added before Build
+	target := &PlanResource{
+		Plan: &Plan{ID: "p2", Name: "Plan v2", Priority: PlanPriorityMedium},
+		Tasks: []*Task{
+			{ID: "n1", PlanID: "p2", Title: "Design", Status: TaskStatusCompleted, Order: 0},
+			{ID: "n2", PlanID: "p2", Title: "Test", Status: TaskStatusPending, Order: 1},
+			{
+				ID: "n3", PlanID: "p2", Title: "Build", Status: TaskStatusPending, Priority: TaskPriorityHigh,
+				Order: 2, DependsOn: []string{"n1"},
+			},
+		},
+	}
+
+	diff, err := DiffPlans(base, target)
+	if err != nil {
+		t.Fatalf("failed to diff plans: %v", err)
+	}
+
+	if want := map[string]FieldChange{"name": {Before: "Plan", After: "Plan v2"}}; !reflect.DeepEqual(diff.PlanChanges, want) {
+		t.Errorf("expected plan changes %v, got %v", want, diff.PlanChanges)
+	}
+	if len(diff.AddedTasks) != 1 || diff.AddedTasks[0].ID != "n2" {
+		t.Errorf("expected Test to be added, got %+v", diff.AddedTasks)
+	}
+	if len(diff.RemovedTasks) != 1 || diff.RemovedTasks[0].ID != "t3" {
+		t.Errorf("expected Document to be removed, got %+v", diff.RemovedTasks)
+	}
+	if diff.UnchangedTasks != 1 {
+		t.Errorf("expected 1 unchanged task, got %d", diff.UnchangedTasks)
+	}
+	if diff.Reordered {
+		t.Error("expected the matched tasks to keep their order")
+	}
+
+	if len(diff.ChangedTasks) != 1 {
+		t.Fatalf("expected 1 changed task, got %+v", diff.ChangedTasks)
+	}
+	changed := diff.ChangedTasks[0]
+	if changed.BaseID != "t2" || changed.ID != "n3" || changed.MatchedBy != DiffMatchTitle {
+		t.Errorf("expected Build to be matched by title, got %+v", changed)
+	}
+	// The dependency on Design points to its match, so only the priority differs
+	want := map[string]FieldChange{"priority": {Before: "", After: "high"}}
+	if !reflect.DeepEqual(changed.Changes, want) {
+		t.Errorf("expected changes %v, got %v", want, changed.Changes)
+	}
+}
+
+func TestDiffPlansSnapshot(t *testing.T) {
+	base := &PlanResource{
+		Plan: &Plan{ID: "p1", Name: "Plan"},
+		Tasks: []*Task{
+			{ID: "t1", Title: "First", Order: 0},
+			{ID: "t2", Title: "Second", Order: 1, Notes: ""},
+		},
+		NotesURIs: map[string]string{"t2": "ai-tasks://tasks/t2/notes"},
+	}
+	target := &PlanResource{
+		Plan: &Plan{ID: "p1", Name: "Plan"},
+		Tasks: []*Task{
+			{ID: "t2", Title: "Second", Order: 0, Notes: "Long notes"},
+			{ID: "t1", Title: "First renamed", Order: 1},
+		},
+	}
+
+	diff, err := DiffPlans(base, target)
+	if err != nil {
+		t.Fatalf("failed to diff plans: %v", err)
+	}
+	if !diff.Reordered {
+		t.Error("expected the tasks to be reordered")
+	}
+	if diff.PlanChanges != nil {
+		t.Errorf("expected no plan changes, got %v", diff.PlanChanges)
+	}
+	if len(diff.ChangedTasks) != 1 || diff.ChangedTasks[0].ID != "t1" || diff.ChangedTasks[0].MatchedBy != DiffMatchID {
+		t.Fatalf("expected only the renamed task to change, got %+v", diff.ChangedTasks)
+	}
+	if diff.UnchangedTasks != 1 {
+		t.Errorf("expected the task with detached notes to be unchanged, got %d unchanged", diff.UnchangedTasks)
+	}
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// PlanProgress represents computed progress metrics for a plan
+type PlanProgress struct {
+	PlanID     string     `json:"plan_id"`
+	PlanName   string     `json:"plan_name"`
+	PlanStatus PlanStatus `json:"plan_status"`
+
+	// Task counts
+	TotalTasks     int                  `json:"total_tasks"`
+	StatusCounts   map[TaskStatus]int   `json:"status_counts"`
+	PriorityCounts map[TaskPriority]int `json:"priority_counts"`
+
+	// PercentComplete is the share of non-cancelled tasks that are completed
+	PercentComplete float64 `json:"percent_complete"`
+
+	// BlockedTasks counts open tasks that depend on tasks which are not completed yet
+	BlockedTasks int `json:"blocked_tasks"`
+	// OverdueTasks counts open tasks whose due date has passed
+	OverdueTasks int `json:"overdue_tasks"`
+	// EscalatedTasks counts pending tasks whose priority was raised because they were neglected
+	EscalatedTasks int `json:"escalated_tasks"`
+	// AcceptanceCriteria and AcceptanceCriteriaMet count the acceptance criteria of tasks that are not
+	// cancelled, and how many of them are met
+	AcceptanceCriteria    int `json:"acceptance_criteria"`
+	AcceptanceCriteriaMet int `json:"acceptance_criteria_met"`
+	// UnmetCriteriaTasks counts completed tasks with acceptance criteria that are not met
+	UnmetCriteriaTasks int `json:"unmet_criteria_tasks"`
+	// EstimatedRemainingWork is the number of open tasks left in the plan
+	EstimatedRemainingWork int `json:"estimated_remaining_work"`
+
+	// Schedule of the plan. DaysRemaining is the number of days left until the target date, negative once it
+	// has passed, and AtRisk is set when the open tasks are unlikely to be completed by the target date.
+	StartDate     *time.Time          `json:"start_date,omitempty"`
+	TargetDate    *time.Time          `json:"target_date,omitempty"`
+	DaysRemaining *int                `json:"days_remaining,omitempty"`
+	AtRisk        bool                `json:"at_risk"`
+	Milestones    []MilestoneProgress `json:"milestones,omitempty"`
+
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+// MilestoneProgress represents computed progress metrics for a milestone of a plan
+type MilestoneProgress struct {
+	ID   string    `json:"id"`
+	Name string    `json:"name"`
+	Date time.Time `json:"date"`
+
+	// TotalTasks and OpenTasks count the linked tasks that still exist, cancelled tasks excluded
+	TotalTasks int `json:"total_tasks"`
+	OpenTasks  int `json:"open_tasks"`
+	// Reached is set once the milestone has linked tasks and all of them are completed
+	Reached bool `json:"reached"`
+	// AtRisk is set when the open linked tasks are unlikely to be completed by the milestone date
+	AtRisk bool `json:"at_risk"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // PlanResource represents a complete view of a plan including its tasks and notes
 // This is used as a resource for the MCP server to provide a consolidated view
 type PlanResource struct {
@@ -8,6 +10,21 @@ type PlanResource struct {
 
 	// Tasks associated with the plan
 	Tasks []*Task `json:"tasks"`
+
+	// URIs of the notes resources of the plan and tasks whose notes were left out for their length,
+	// by plan or task ID
+	NotesURIs map[string]string `json:"notes_uris,omitempty"`
+
+	// TotalTasks and NextCursor are set when the tasks are read a page at a time. NextCursor reads the next
+	// page and is empty on the last page.
+	TotalTasks int    `json:"total_tasks,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	// Summarized is set when the plan was too large to return whole: the notes of the plan are left out and
+	// TaskSummaries replace Tasks. Hint tells how to read the rest.
+	Summarized    bool           `json:"summarized,omitempty"`
+	TaskSummaries []*TaskSummary `json:"task_summaries,omitempty"`
+	Hint          string         `json:"hint,omitempty"`
 }
 
 // NewPlanResource creates a new PlanResource with the given plan and tasks
@@ -17,3 +34,41 @@ func NewPlanResource(plan *Plan, tasks []*Task) *PlanResource {
 		Tasks: tasks,
 	}
 }
+
+// PlanPage is a page of plan resources, returned when plans are read a page at a time or when they are too
+// large to return whole
+type PlanPage struct {
+	// Plans are the plans of the page with their tasks, left out when the page is summarized
+	Plans []*PlanResource `json:"plans,omitempty"`
+	// Summaries replace Plans when the plans of the page were too large to return whole
+	Summaries []*PlanSummary `json:"summaries,omitempty"`
+	// Total is the number of plans on all pages
+	Total int `json:"total"`
+	// NextCursor reads the next page, empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+	// Hint tells how to read the plans of a summarized page
+	Hint string `json:"hint,omitempty"`
+}
+
+// PlanSummary describes a plan without its description, notes and tasks
+type PlanSummary struct {
+	ID            string      `json:"id"`
+	ApplicationID string      `json:"application_id"`
+	Name          string      `json:"name"`
+	Status        PlanStatus  `json:"status"`
+	TaskCounts    *TaskCounts `json:"task_counts,omitempty"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+	// URI is the resource returning the plan with its tasks
+	URI string `json:"uri"`
+}
+
+// TaskSummary describes a task without its description and notes
+type TaskSummary struct {
+	ID       string       `json:"id"`
+	Title    string       `json:"title"`
+	Status   TaskStatus   `json:"status"`
+	Priority TaskPriority `json:"priority"`
+	Order    int          `json:"order"`
+	// URI is the resource returning the whole task
+	URI string `json:"uri"`
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PlanCheck names a check of the readiness of a plan
+type PlanCheck string
+
+const (
+	// PlanCheckDescriptions flags tasks without a description
+	PlanCheckDescriptions PlanCheck = "descriptions"
+	// PlanCheckDependencyCycles flags tasks that depend on each other in a cycle, so none of them can start
+	PlanCheckDependencyCycles PlanCheck = "dependency_cycles"
+	// PlanCheckEstimates flags open tasks without an estimate
+	PlanCheckEstimates PlanCheck = "estimates"
+	// PlanCheckOrphanedReferences flags dependencies, links and milestones pointing at tasks that do not exist
+	PlanCheckOrphanedReferences PlanCheck = "orphaned_references"
+)
+
+// PlanChecks lists the plan checks in the order they run
+var PlanChecks = []PlanCheck{
+	PlanCheckDescriptions, PlanCheckDependencyCycles, PlanCheckEstimates, PlanCheckOrphanedReferences,
+}
+
+// ValidationSeverity tells how serious a plan validation issue is. Only errors make a plan invalid.
+type ValidationSeverity string
+
+const (
+	ValidationSeverityError   ValidationSeverity = "error"
+	ValidationSeverityWarning ValidationSeverity = "warning"
+	ValidationSeverityInfo    ValidationSeverity = "info"
+)
+
+// Severity returns the severity of the issues the check finds
+func (c PlanCheck) Severity() ValidationSeverity {
+	switch c {
+	case PlanCheckDependencyCycles, PlanCheckOrphanedReferences:
+		return ValidationSeverityError
+	case PlanCheckDescriptions:
+		return ValidationSeverityWarning
+	default:
+		return ValidationSeverityInfo
+	}
+}
+
+// PlanCheckNames returns the names of the plan checks, for tool schemas and validation
+func PlanCheckNames() []string {
+	names := make([]string, len(PlanChecks))
+	for i, check := range PlanChecks {
+		names[i] = string(check)
+	}
+	return names
+}
+
+// ParsePlanChecks converts check names to plan checks in the order they run, all of them if none are named
+func ParsePlanChecks(names []string) ([]PlanCheck, error) {
+	if len(names) == 0 {
+		return PlanChecks, nil
+	}
+	for _, name := range names {
+		if !slices.Contains(PlanChecks, PlanCheck(name)) {
+			return nil, NewValidationError("", "", "invalid check: %s, expected one of %s",
+				name, strings.Join(PlanCheckNames(), ", "))
+		}
+	}
+	var checks []PlanCheck
+	for _, check := range PlanChecks {
+		if slices.Contains(names, string(check)) {
+			checks = append(checks, check)
+		}
+	}
+	return checks, nil
+}
+
+// PlanValidationIssue is a problem a plan check found, about the listed tasks
+type PlanValidationIssue struct {
+	Check    PlanCheck          `json:"check"`
+	Severity ValidationSeverity `json:"severity"`
+	Message  string             `json:"message"`
+	TaskIDs  []string           `json:"task_ids,omitempty"`
+}
+
+// PlanValidationReport reports whether a plan is ready to be worked on. The plan is valid when the checks
+// found no errors; warnings and infos point at gaps that do not stop the work.
+type PlanValidationReport struct {
+	PlanID   string      `json:"plan_id"`
+	PlanName string      `json:"plan_name"`
+	Checks   []PlanCheck `json:"checks"`
+
+	Valid    bool `json:"valid"`
+	Errors   int  `json:"errors"`
+	Warnings int  `json:"warnings"`
+	Infos    int  `json:"infos"`
+
+	Issues []*PlanValidationIssue `json:"issues"`
+
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+// AddIssue adds an issue found by a check to the report and counts it by its severity
+func (r *PlanValidationReport) AddIssue(check PlanCheck, taskIDs []string, format string, args ...any) {
+	issue := &PlanValidationIssue{
+		Check:    check,
+		Severity: check.Severity(),
+		Message:  fmt.Sprintf(format, args...),
+		TaskIDs:  taskIDs,
+	}
+	r.Issues = append(r.Issues, issue)
+
+	switch issue.Severity {
+	case ValidationSeverityError:
+		r.Errors++
+	case ValidationSeverityWarning:
+		r.Warnings++
+	default:
+		r.Infos++
+	}
+	r.Valid = r.Errors == 0
+}
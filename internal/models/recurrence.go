@@ -0,0 +1,131 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecurrenceFrequency represents how often a recurring task repeats
+type RecurrenceFrequency string
+
+const (
+	RecurrenceHourly  RecurrenceFrequency = "HOURLY"
+	RecurrenceDaily   RecurrenceFrequency = "DAILY"
+	RecurrenceWeekly  RecurrenceFrequency = "WEEKLY"
+	RecurrenceMonthly RecurrenceFrequency = "MONTHLY"
+	RecurrenceYearly  RecurrenceFrequency = "YEARLY"
+)
+
+// Recurrence describes a simple RRULE-like repetition rule, e.g. "FREQ=WEEKLY;INTERVAL=2"
+type Recurrence struct {
+	Frequency RecurrenceFrequency
+	Interval  int
+}
+
+// ParseRecurrence parses a recurrence rule.
+// It accepts the RRULE-like form "FREQ=DAILY;INTERVAL=3" as well as the shorthands
+// "hourly", "daily", "weekly", "monthly" and "yearly".
+func ParseRecurrence(rule string) (*Recurrence, error) {
+	rule = strings.TrimSpace(rule)
+	if rule == "" {
+		return nil, NewValidationError("", "", "recurrence rule is empty")
+	}
+
+	recurrence := &Recurrence{Interval: 1}
+
+	// Handle the shorthand forms first
+	if !strings.Contains(rule, "=") {
+		recurrence.Frequency = RecurrenceFrequency(strings.ToUpper(rule))
+		if err := recurrence.validate(); err != nil {
+			return nil, err
+		}
+		return recurrence, nil
+	}
+
+	rule = strings.TrimPrefix(strings.ToUpper(rule), "RRULE:")
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, NewValidationError("", "", "invalid recurrence rule part: %s", part)
+		}
+
+		switch key {
+		case "FREQ":
+			recurrence.Frequency = RecurrenceFrequency(value)
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, NewValidationError("", "", "invalid recurrence interval: %s", value)
+			}
+			recurrence.Interval = interval
+		default:
+			return nil, NewValidationError("", "", "unsupported recurrence rule part: %s", key)
+		}
+	}
+
+	if err := recurrence.validate(); err != nil {
+		return nil, err
+	}
+
+	return recurrence, nil
+}
+
+// validate checks that the recurrence has a known frequency and a positive interval
+func (r *Recurrence) validate() error {
+	switch r.Frequency {
+	case RecurrenceHourly, RecurrenceDaily, RecurrenceWeekly, RecurrenceMonthly, RecurrenceYearly:
+	default:
+		return NewValidationError("", "", "invalid recurrence frequency: %s", r.Frequency)
+	}
+
+	if r.Interval < 1 {
+		return NewValidationError("", "", "recurrence interval must be at least 1, got %d", r.Interval)
+	}
+
+	return nil
+}
+
+// String returns the canonical RRULE-like form of the recurrence
+func (r *Recurrence) String() string {
+	return fmt.Sprintf("FREQ=%s;INTERVAL=%d", r.Frequency, r.Interval)
+}
+
+// Next returns the next occurrence after the given time
+func (r *Recurrence) Next(from time.Time) time.Time {
+	switch r.Frequency {
+	case RecurrenceHourly:
+		return from.Add(time.Duration(r.Interval) * time.Hour)
+	case RecurrenceDaily:
+		return from.AddDate(0, 0, r.Interval)
+	case RecurrenceWeekly:
+		return from.AddDate(0, 0, 7*r.Interval)
+	case RecurrenceMonthly:
+		return from.AddDate(0, r.Interval, 0)
+	case RecurrenceYearly:
+		return from.AddDate(r.Interval, 0, 0)
+	default:
+		return from
+	}
+}
+
+// ParseDueDate parses a due date given either as an RFC3339 timestamp or as a plain date (YYYY-MM-DD)
+func ParseDueDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if dueDate, err := time.Parse(time.RFC3339, value); err == nil {
+		return dueDate, nil
+	}
+
+	dueDate, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, NewValidationError("", "", "invalid due date %q: expected RFC3339 or YYYY-MM-DD", value)
+	}
+
+	return dueDate, nil
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "Shorthand daily",
+			rule:     "daily",
+			expected: "FREQ=DAILY;INTERVAL=1",
+		},
+		{
+			name:     "RRULE-like with interval",
+			rule:     "FREQ=WEEKLY;INTERVAL=2",
+			expected: "FREQ=WEEKLY;INTERVAL=2",
+		},
+		{
+			name:     "RRULE prefix and lowercase",
+			rule:     "rrule:freq=monthly",
+			expected: "FREQ=MONTHLY;INTERVAL=1",
+		},
+		{
+			name:    "Empty rule",
+			rule:    "",
+			wantErr: true,
+		},
+		{
+			name:    "Unknown frequency",
+			rule:    "fortnightly",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid interval",
+			rule:    "FREQ=DAILY;INTERVAL=0",
+			wantErr: true,
+		},
+		{
+			name:    "Unsupported part",
+			rule:    "FREQ=DAILY;BYDAY=MO",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recurrence, err := ParseRecurrence(tt.rule)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRecurrence() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && recurrence.String() != tt.expected {
+				t.Errorf("ParseRecurrence() = %q, expected %q", recurrence.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	from := time.Date(2025, time.January, 31, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		rule     string
+		expected time.Time
+	}{
+		{
+			name:     "Every 6 hours",
+			rule:     "FREQ=HOURLY;INTERVAL=6",
+			expected: time.Date(2025, time.January, 31, 15, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Every 2 weeks",
+			rule:     "FREQ=WEEKLY;INTERVAL=2",
+			expected: time.Date(2025, time.February, 14, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Yearly",
+			rule:     "yearly",
+			expected: time.Date(2026, time.January, 31, 9, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recurrence, err := ParseRecurrence(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRecurrence() error = %v", err)
+			}
+			if next := recurrence.Next(from); !next.Equal(tt.expected) {
+				t.Errorf("Next() = %v, expected %v", next, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseDueDate(t *testing.T) {
+	if _, err := ParseDueDate("2025-07-01"); err != nil {
+		t.Errorf("ParseDueDate() with plain date error = %v", err)
+	}
+	if _, err := ParseDueDate("2025-07-01T12:00:00Z"); err != nil {
+		t.Errorf("ParseDueDate() with RFC3339 error = %v", err)
+	}
+	if _, err := ParseDueDate("next tuesday"); err == nil {
+		t.Error("ParseDueDate() with invalid value should fail")
+	}
+}
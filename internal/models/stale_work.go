@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Reasons why work is considered stale
+const (
+	// StaleReasonIdle marks work without any change for longer than the stale threshold
+	StaleReasonIdle = "idle"
+	// StaleReasonLeaseExpired marks a claimed task whose worker let its lease expire
+	StaleReasonLeaseExpired = "lease_expired"
+)
+
+// StaleTask is an in-progress task that looks abandoned, such as by an agent session that crashed
+type StaleTask struct {
+	Task           *Task     `json:"task"`
+	Reason         string    `json:"reason"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	// IdleSeconds is the time since the last activity
+	IdleSeconds int64 `json:"idle_seconds"`
+}
+
+// StalePlan is an in-progress plan none of whose tasks changed for longer than the stale threshold
+type StalePlan struct {
+	Plan           *Plan     `json:"plan"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	// IdleSeconds is the time since the last activity
+	IdleSeconds int64 `json:"idle_seconds"`
+	// InProgressTasks is the number of tasks of the plan left in progress
+	InProgressTasks int `json:"in_progress_tasks"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"slices"
+	"strings"
+)
+
+// NormalizeTags trims and lowercases tags, dropping empty values and duplicates
+func NormalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || slices.Contains(normalized, tag) {
+			continue
+		}
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// HasAllTags reports whether the task carries every one of the given tags
+func (t *Task) HasAllTags(tags []string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(t.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
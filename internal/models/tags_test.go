@@ -0,0 +1,37 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	got := NormalizeTags([]string{" Backend ", "frontend", "", "BACKEND", "  "})
+	expected := []string{"backend", "frontend"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("NormalizeTags() = %v, want %v", got, expected)
+	}
+}
+
+func TestHasAllTags(t *testing.T) {
+	task := &Task{Tags: []string{"backend", "urgent"}}
+
+	tests := []struct {
+		name     string
+		tags     []string
+		expected bool
+	}{
+		{name: "No tags", tags: nil, expected: true},
+		{name: "Single matching tag", tags: []string{"backend"}, expected: true},
+		{name: "All matching tags", tags: []string{"urgent", "backend"}, expected: true},
+		{name: "Missing tag", tags: []string{"backend", "frontend"}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := task.HasAllTags(tt.tags); got != tt.expected {
+				t.Errorf("HasAllTags(%v) = %v, want %v", tt.tags, got, tt.expected)
+			}
+		})
+	}
+}
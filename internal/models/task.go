@@ -2,6 +2,9 @@ package models
 
 import (
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -36,6 +39,47 @@ type Task struct {
 	Order       int          `json:"order"`
 	CreatedAt   time.Time    `json:"created_at"`
 	UpdatedAt   time.Time    `json:"updated_at"`
+
+	// Scheduling information for tasks with a due date or recurrence rule
+	DueDate          *time.Time `json:"due_date,omitempty"`
+	Recurrence       string     `json:"recurrence,omitempty"`
+	NextOccurrenceID string     `json:"next_occurrence_id,omitempty"`
+
+	// Estimated size of the task in the unit the plan is estimated in, such as story points or minutes.
+	// Zero means the task is not estimated.
+	Estimate float64 `json:"estimate,omitempty"`
+
+	// IDs of tasks that must be completed before this task can proceed
+	DependsOn []string `json:"depends_on,omitempty"`
+
+	// Free-form labels, stored in a separate set rather than in the task hash
+	Tags []string `json:"tags,omitempty"`
+
+	// Ordered micro-steps, stored in a separate list rather than in the task hash
+	Checklist []*ChecklistItem `json:"checklist,omitempty"`
+
+	// Time tracking information, stamped automatically on status transitions.
+	// TimeSpent is the accumulated time in seconds.
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+	TimeSpent       int64      `json:"time_spent"`
+	InProgressSince *time.Time `json:"-"`
+
+	// Summary of what was done, given when the task is completed and cleared when it is reopened
+	CompletionNote string `json:"completion_note,omitempty"`
+
+	// Arbitrary key/value metadata such as a repository URL or ticket ID
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Typed links to pull requests, documents and related tasks
+	Links []*Link `json:"links,omitempty"`
+
+	// Conditions the task must meet to be done, each checked off on its own
+	AcceptanceCriteria []*AcceptanceCriterion `json:"acceptance_criteria,omitempty"`
+
+	// Lease information for tasks claimed by a worker in work-queue mode
+	LeaseOwner     string     `json:"lease_owner,omitempty"`
+	LeaseExpiresAt *time.Time `json:"lease_expires_at,omitempty"`
 }
 
 // NewTask creates a new task with the given details
@@ -57,7 +101,17 @@ func NewTask(id, planID, title, description string, priority TaskPriority) *Task
 
 // ToMap converts the task to a map for storage in Valkey
 func (t *Task) ToMap() map[string]string {
-	return map[string]string{
+	dueDate := ""
+	if t.DueDate != nil {
+		dueDate = t.DueDate.Format(time.RFC3339)
+	}
+
+	leaseExpiresAt := ""
+	if t.LeaseExpiresAt != nil {
+		leaseExpiresAt = t.LeaseExpiresAt.Format(time.RFC3339Nano)
+	}
+
+	fields := map[string]string{
 		"id":          t.ID,
 		"plan_id":     t.PlanID,
 		"title":       t.Title,
@@ -68,7 +122,28 @@ func (t *Task) ToMap() map[string]string {
 		"order":       fmt.Sprintf("%d", t.Order),
 		"created_at":  t.CreatedAt.Format(time.RFC3339),
 		"updated_at":  t.UpdatedAt.Format(time.RFC3339),
+
+		"due_date":           dueDate,
+		"recurrence":         t.Recurrence,
+		"next_occurrence_id": t.NextOccurrenceID,
+		"depends_on":         strings.Join(t.DependsOn, ","),
+		"estimate":           strconv.FormatFloat(t.Estimate, 'f', -1, 64),
+		"links":              encodeLinks(t.Links),
+
+		"acceptance_criteria": encodeAcceptanceCriteria(t.AcceptanceCriteria),
+
+		"lease_owner":      t.LeaseOwner,
+		"lease_expires_at": leaseExpiresAt,
+
+		"started_at":        formatOptionalTime(t.StartedAt),
+		"completed_at":      formatOptionalTime(t.CompletedAt),
+		"time_spent":        fmt.Sprintf("%d", t.TimeSpent),
+		"in_progress_since": formatOptionalTime(t.InProgressSince),
+		"completion_note":   t.CompletionNote,
 	}
+	addMetadataFields(fields, t.Metadata)
+
+	return fields
 }
 
 // FromMap populates a task from a map retrieved from Valkey
@@ -103,5 +178,113 @@ func (t *Task) FromMap(data map[string]string) error {
 	}
 	t.UpdatedAt = updatedAt
 
+	// Scheduling fields are optional and only present for scheduled tasks
+	t.DueDate = nil
+	if data["due_date"] != "" {
+		dueDate, err := time.Parse(time.RFC3339, data["due_date"])
+		if err != nil {
+			return err
+		}
+		t.DueDate = &dueDate
+	}
+	t.Recurrence = data["recurrence"]
+	t.NextOccurrenceID = data["next_occurrence_id"]
+
+	t.DependsOn = nil
+	if data["depends_on"] != "" {
+		t.DependsOn = strings.Split(data["depends_on"], ",")
+	}
+
+	t.Estimate = 0
+	if data["estimate"] != "" {
+		t.Estimate, err = strconv.ParseFloat(data["estimate"], 64)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Lease fields are optional and only present for claimed tasks
+	t.LeaseOwner = data["lease_owner"]
+	t.LeaseExpiresAt = nil
+	if data["lease_expires_at"] != "" {
+		leaseExpiresAt, err := time.Parse(time.RFC3339Nano, data["lease_expires_at"])
+		if err != nil {
+			return err
+		}
+		t.LeaseExpiresAt = &leaseExpiresAt
+	}
+
+	// Time tracking fields are absent on tasks created before time tracking existed
+	t.StartedAt, err = parseOptionalTime(data["started_at"])
+	if err != nil {
+		return err
+	}
+	t.CompletedAt, err = parseOptionalTime(data["completed_at"])
+	if err != nil {
+		return err
+	}
+	t.InProgressSince, err = parseOptionalTime(data["in_progress_since"])
+	if err != nil {
+		return err
+	}
+	t.CompletionNote = data["completion_note"]
+	t.TimeSpent = 0
+	if data["time_spent"] != "" {
+		_, err := fmt.Sscanf(data["time_spent"], "%d", &t.TimeSpent)
+		if err != nil {
+			return err
+		}
+	}
+
+	t.Links, err = decodeLinks(data["links"])
+	if err != nil {
+		return err
+	}
+	t.AcceptanceCriteria, err = decodeAcceptanceCriteria(data["acceptance_criteria"])
+	if err != nil {
+		return err
+	}
+
+	t.Metadata = metadataFromFields(data)
+
 	return nil
 }
+
+// ClearLease removes any lease information from the task
+func (t *Task) ClearLease() {
+	t.LeaseOwner = ""
+	t.LeaseExpiresAt = nil
+}
+
+// ValidateEstimate checks that an estimate is a finite, non-negative number
+func ValidateEstimate(estimate float64) error {
+	if estimate < 0 || math.IsNaN(estimate) || math.IsInf(estimate, 0) {
+		return NewValidationError("", "", "invalid estimate: %v, expected a non-negative number", estimate)
+	}
+	return nil
+}
+
+// IsOpen reports whether the task still has work remaining
+func (t *Task) IsOpen() bool {
+	return t.Status != TaskStatusCompleted && t.Status != TaskStatusCancelled
+}
+
+// formatOptionalTime formats an optional timestamp for storage, using an empty string for nil
+func formatOptionalTime(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.Format(time.RFC3339)
+}
+
+// parseOptionalTime parses an optional stored timestamp, returning nil for an empty string
+func parseOptionalTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// TaskCompletion is an entry in the record of the work delivered in a plan, written when a task is completed
+// with a completion note
+type TaskCompletion struct {
+	TaskID      string    `json:"task_id"`
+	Title       string    `json:"title"`
+	Note        string    `json:"note"`
+	CompletedAt time.Time `json:"completed_at"`
+}
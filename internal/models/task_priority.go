@@ -0,0 +1,138 @@
+package models
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// TaskPriorityDefinition defines a task priority and its weight. Tasks with a higher weight are more urgent;
+// the built-in priorities weigh 10 (low), 20 (medium) and 30 (high), so custom ones can rank between them.
+type TaskPriorityDefinition struct {
+	Priority TaskPriority
+	Weight   int
+}
+
+// basePriorities are the priorities every task may have
+var basePriorities = []TaskPriorityDefinition{
+	{TaskPriorityLow, 10},
+	{TaskPriorityMedium, 20},
+	{TaskPriorityHigh, 30},
+}
+
+// taskPriorities holds the priorities of the deployment ordered by weight, the built-in ones unless custom
+// ones are configured
+var taskPriorities atomic.Pointer[[]TaskPriorityDefinition]
+
+// SetCustomTaskPriorities adds custom priorities to the built-in ones. It is called once at startup, before
+// tools are registered, as tool schemas list the priorities configured then. Tasks keep a custom priority
+// that is removed later, which then ranks below every other priority.
+func SetCustomTaskPriorities(definitions []TaskPriorityDefinition) error {
+	priorities := slices.Clone(basePriorities)
+	for _, definition := range definitions {
+		switch {
+		case !customValueNamePattern.MatchString(string(definition.Priority)):
+			return fmt.Errorf("invalid custom priority %q, expected lowercase letters, digits and underscores",
+				definition.Priority)
+		case slices.ContainsFunc(priorities, func(p TaskPriorityDefinition) bool { return p.Priority == definition.Priority }):
+			return fmt.Errorf("priority %q is defined more than once", definition.Priority)
+		case definition.Weight <= 0:
+			return fmt.Errorf("invalid weight %d of custom priority %q, expected a positive number",
+				definition.Weight, definition.Priority)
+		}
+		priorities = append(priorities, definition)
+	}
+	slices.SortStableFunc(priorities, func(a, b TaskPriorityDefinition) int { return cmp.Compare(a.Weight, b.Weight) })
+	taskPriorities.Store(&priorities)
+	return nil
+}
+
+// ParseCustomTaskPriorities parses a comma-separated list of custom priorities, each a name and a weight
+// separated by a colon such as "critical:40"
+func ParseCustomTaskPriorities(value string) ([]TaskPriorityDefinition, error) {
+	var definitions []TaskPriorityDefinition
+	for entry := range strings.SplitSeq(value, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		name, weight, found := strings.Cut(entry, ":")
+		if !found {
+			return nil, fmt.Errorf("custom priority %q has no weight, expected name:weight", entry)
+		}
+		parsed, err := strconv.Atoi(strings.TrimSpace(weight))
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight %q of custom priority %q", weight, name)
+		}
+		definitions = append(definitions, TaskPriorityDefinition{
+			Priority: TaskPriority(strings.TrimSpace(name)),
+			Weight:   parsed,
+		})
+	}
+	return definitions, nil
+}
+
+// priorityDefinitions returns the priorities of the deployment ordered by weight
+func priorityDefinitions() []TaskPriorityDefinition {
+	if priorities := taskPriorities.Load(); priorities != nil {
+		return *priorities
+	}
+	return basePriorities
+}
+
+// TaskPriorities returns the priorities tasks may have, from the lowest weight to the highest
+func TaskPriorities() []TaskPriority {
+	definitions := priorityDefinitions()
+	priorities := make([]TaskPriority, len(definitions))
+	for i, definition := range definitions {
+		priorities[i] = definition.Priority
+	}
+	return priorities
+}
+
+// TaskPriorityNames returns the names of the priorities tasks may have, for tool schemas and validation
+func TaskPriorityNames() []string {
+	priorities := TaskPriorities()
+	names := make([]string, len(priorities))
+	for i, priority := range priorities {
+		names[i] = string(priority)
+	}
+	return names
+}
+
+// NewTaskPriorityCounts returns counts of tasks by priority, starting at zero for every priority
+func NewTaskPriorityCounts() map[TaskPriority]int {
+	counts := make(map[TaskPriority]int)
+	for _, priority := range TaskPriorities() {
+		counts[priority] = 0
+	}
+	return counts
+}
+
+// IsValidTaskPriority reports whether tasks may have the priority
+func IsValidTaskPriority(priority TaskPriority) bool {
+	return slices.Contains(TaskPriorities(), priority)
+}
+
+// ValidateTaskPriority checks that tasks may have the priority
+func ValidateTaskPriority(priority TaskPriority) error {
+	if IsValidTaskPriority(priority) {
+		return nil
+	}
+	return NewValidationError("", "", "invalid priority: %s, expected one of %s",
+		priority, strings.Join(TaskPriorityNames(), ", "))
+}
+
+// PriorityRank returns a comparable rank for a priority, its weight, higher meaning more urgent.
+// Unknown priorities rank below every other priority.
+func PriorityRank(priority TaskPriority) int {
+	for _, definition := range priorityDefinitions() {
+		if definition.Priority == priority {
+			return definition.Weight
+		}
+	}
+	return 0
+}
@@ -0,0 +1,434 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// TaskQueryField is a field a task query can filter on
+type TaskQueryField string
+
+const (
+	TaskQueryStatus        TaskQueryField = "status"
+	TaskQueryPriority      TaskQueryField = "priority"
+	TaskQueryPlanID        TaskQueryField = "plan_id"
+	TaskQueryApplicationID TaskQueryField = "application_id"
+	TaskQueryTag           TaskQueryField = "tag"
+	TaskQueryTitle         TaskQueryField = "title"
+	TaskQueryDescription   TaskQueryField = "description"
+	TaskQueryCreatedAfter  TaskQueryField = "created_after"
+	TaskQueryCreatedBefore TaskQueryField = "created_before"
+	TaskQueryUpdatedAfter  TaskQueryField = "updated_after"
+	TaskQueryUpdatedBefore TaskQueryField = "updated_before"
+	TaskQueryDueAfter      TaskQueryField = "due_after"
+	TaskQueryDueBefore     TaskQueryField = "due_before"
+)
+
+// TaskQueryFields lists the fields a task query can filter on
+var TaskQueryFields = []TaskQueryField{
+	TaskQueryStatus, TaskQueryPriority, TaskQueryPlanID, TaskQueryApplicationID, TaskQueryTag,
+	TaskQueryTitle, TaskQueryDescription,
+	TaskQueryCreatedAfter, TaskQueryCreatedBefore, TaskQueryUpdatedAfter, TaskQueryUpdatedBefore,
+	TaskQueryDueAfter, TaskQueryDueBefore,
+}
+
+// TaskQueryOperator compares a field with the values of a condition
+type TaskQueryOperator string
+
+const (
+	TaskQueryEquals    TaskQueryOperator = "="
+	TaskQueryNotEquals TaskQueryOperator = "!="
+	TaskQueryIn        TaskQueryOperator = "in"
+	TaskQueryNotIn     TaskQueryOperator = "not in"
+	TaskQueryContains  TaskQueryOperator = "~"
+)
+
+const (
+	// maxTaskQueryLength is the maximum length of a query expression
+	maxTaskQueryLength = 4096
+	// maxTaskQueryConditions is the maximum number of conditions of a query
+	maxTaskQueryConditions = 32
+)
+
+// taskQueryOperators lists the operators each kind of field accepts
+var taskQueryOperators = map[TaskQueryField][]TaskQueryOperator{
+	TaskQueryStatus:        {TaskQueryEquals, TaskQueryNotEquals, TaskQueryIn, TaskQueryNotIn},
+	TaskQueryPriority:      {TaskQueryEquals, TaskQueryNotEquals, TaskQueryIn, TaskQueryNotIn},
+	TaskQueryPlanID:        {TaskQueryEquals, TaskQueryNotEquals, TaskQueryIn, TaskQueryNotIn},
+	TaskQueryApplicationID: {TaskQueryEquals, TaskQueryNotEquals, TaskQueryIn, TaskQueryNotIn},
+	TaskQueryTag:           {TaskQueryEquals, TaskQueryNotEquals, TaskQueryIn, TaskQueryNotIn},
+	TaskQueryTitle:         {TaskQueryContains},
+	TaskQueryDescription:   {TaskQueryContains},
+	TaskQueryCreatedAfter:  {TaskQueryEquals},
+	TaskQueryCreatedBefore: {TaskQueryEquals},
+	TaskQueryUpdatedAfter:  {TaskQueryEquals},
+	TaskQueryUpdatedBefore: {TaskQueryEquals},
+	TaskQueryDueAfter:      {TaskQueryEquals},
+	TaskQueryDueBefore:     {TaskQueryEquals},
+}
+
+// TaskCondition is a condition of a task query, such as status in (pending, in_progress)
+type TaskCondition struct {
+	Field    TaskQueryField    `json:"field"`
+	Operator TaskQueryOperator `json:"operator"`
+	Values   []string          `json:"values"`
+	// time is the parsed value of the date fields
+	time time.Time
+}
+
+// TaskQuery is a filter expression on tasks: conditions joined by AND, such as
+//
+//	status in (pending, in_progress) AND priority = high AND updated_after = 2025-01-01
+//
+// Statuses, plans, applications and tags are compared with =, !=, in and not in, the title and description
+// are searched with ~ for a case-insensitive substring, and the date fields take an RFC3339 timestamp or a
+// plain date. Values with spaces or special characters are quoted with single or double quotes.
+type TaskQuery struct {
+	Conditions []TaskCondition `json:"conditions"`
+}
+
+// ParseTaskQuery parses a task query expression
+func ParseTaskQuery(expression string) (*TaskQuery, error) {
+	if len(expression) > maxTaskQueryLength {
+		return nil, NewValidationError("", "", "query exceeds %d characters", maxTaskQueryLength)
+	}
+	tokens, err := tokenizeTaskQuery(expression)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, NewValidationError("", "", "query cannot be empty")
+	}
+
+	p := &taskQueryParser{tokens: tokens}
+	query := &TaskQuery{}
+	for {
+		condition, err := p.condition()
+		if err != nil {
+			return nil, err
+		}
+		query.Conditions = append(query.Conditions, condition)
+		if len(query.Conditions) > maxTaskQueryConditions {
+			return nil, NewValidationError("", "", "query has more than %d conditions", maxTaskQueryConditions)
+		}
+
+		token, ok := p.next()
+		if !ok {
+			return query, nil
+		}
+		if !token.keyword("and") {
+			return nil, NewValidationError("", "", "expected AND at %q", token.text)
+		}
+	}
+}
+
+// Values returns the values a field must have by the = and in conditions of the query, and whether there
+// are any such conditions. With several such conditions, a value must satisfy all of them.
+func (q *TaskQuery) Values(field TaskQueryField) ([]string, bool) {
+	var values []string
+	found := false
+	for _, condition := range q.Conditions {
+		if condition.Field != field || (condition.Operator != TaskQueryEquals && condition.Operator != TaskQueryIn) {
+			continue
+		}
+		if !found {
+			values = slices.Clone(condition.Values)
+			found = true
+			continue
+		}
+		values = slices.DeleteFunc(values, func(value string) bool { return !slices.Contains(condition.Values, value) })
+	}
+	return values, found
+}
+
+// MatchesApplication reports whether the tasks of a plan of the application can match the query
+func (q *TaskQuery) MatchesApplication(applicationID string) bool {
+	for _, condition := range q.Conditions {
+		if condition.Field == TaskQueryApplicationID && !condition.matchesValue(applicationID) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches reports whether a task of a plan of the given application matches all conditions of the query
+func (q *TaskQuery) Matches(task *Task, applicationID string) bool {
+	for _, condition := range q.Conditions {
+		if !condition.matches(task, applicationID) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether a task matches the condition
+func (c *TaskCondition) matches(task *Task, applicationID string) bool {
+	switch c.Field {
+	case TaskQueryStatus:
+		return c.matchesValue(string(task.Status))
+	case TaskQueryPriority:
+		return c.matchesValue(string(task.Priority))
+	case TaskQueryPlanID:
+		return c.matchesValue(task.PlanID)
+	case TaskQueryApplicationID:
+		return c.matchesValue(applicationID)
+	case TaskQueryTag:
+		hasTag := slices.ContainsFunc(task.Tags, func(tag string) bool { return slices.Contains(c.Values, tag) })
+		if c.Operator == TaskQueryNotEquals || c.Operator == TaskQueryNotIn {
+			return !hasTag
+		}
+		return hasTag
+	case TaskQueryTitle:
+		return containsFold(task.Title, c.Values[0])
+	case TaskQueryDescription:
+		return containsFold(task.Description, c.Values[0])
+	case TaskQueryCreatedAfter:
+		return task.CreatedAt.After(c.time)
+	case TaskQueryCreatedBefore:
+		return task.CreatedAt.Before(c.time)
+	case TaskQueryUpdatedAfter:
+		return task.UpdatedAt.After(c.time)
+	case TaskQueryUpdatedBefore:
+		return task.UpdatedAt.Before(c.time)
+	case TaskQueryDueAfter:
+		return task.DueDate != nil && task.DueDate.After(c.time)
+	case TaskQueryDueBefore:
+		return task.DueDate != nil && task.DueDate.Before(c.time)
+	default:
+		return false
+	}
+}
+
+// matchesValue compares a single valued field with the values of the condition
+func (c *TaskCondition) matchesValue(value string) bool {
+	contained := slices.Contains(c.Values, value)
+	if c.Operator == TaskQueryNotEquals || c.Operator == TaskQueryNotIn {
+		return !contained
+	}
+	return contained
+}
+
+// containsFold reports whether text contains substring, ignoring case
+func containsFold(text, substring string) bool {
+	return strings.Contains(strings.ToLower(text), strings.ToLower(substring))
+}
+
+// taskQueryToken is a token of a task query expression
+type taskQueryToken struct {
+	text string
+	// quoted tells values in quotes apart from keywords
+	quoted bool
+}
+
+// punctuation reports whether the token is punctuation or an operator rather than a value
+func (t taskQueryToken) punctuation() bool {
+	return !t.quoted && (t.text == "!=" || len(t.text) == 1 && strings.Contains(taskQueryPunctuation, t.text))
+}
+
+// keyword reports whether the token is the given unquoted keyword, in any case
+func (t taskQueryToken) keyword(word string) bool {
+	return !t.quoted && strings.EqualFold(t.text, word)
+}
+
+// taskQueryPunctuation are the characters that are tokens of their own
+const taskQueryPunctuation = "(),=~"
+
+// tokenizeTaskQuery splits a task query expression into words, quoted values, punctuation and operators
+func tokenizeTaskQuery(expression string) ([]taskQueryToken, error) {
+	var tokens []taskQueryToken
+	for i := 0; i < len(expression); {
+		c := expression[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(expression[i+1:], c)
+			if end < 0 {
+				return nil, NewValidationError("", "", "unterminated quoted value in query")
+			}
+			tokens = append(tokens, taskQueryToken{text: expression[i+1 : i+1+end], quoted: true})
+			i += end + 2
+		case strings.HasPrefix(expression[i:], "!="):
+			tokens = append(tokens, taskQueryToken{text: "!="})
+			i += 2
+		case strings.IndexByte(taskQueryPunctuation, c) >= 0:
+			tokens = append(tokens, taskQueryToken{text: string(c)})
+			i++
+		default:
+			end := i
+			for end < len(expression) && !strings.ContainsRune(" \t\r\n\"'!"+taskQueryPunctuation, rune(expression[end])) {
+				end++
+			}
+			if end == i {
+				return nil, NewValidationError("", "", "unexpected %q in query", string(c))
+			}
+			tokens = append(tokens, taskQueryToken{text: expression[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// taskQueryParser parses the conditions of a task query from its tokens
+type taskQueryParser struct {
+	tokens []taskQueryToken
+	pos    int
+}
+
+// next returns the next token, if any
+func (p *taskQueryParser) next() (taskQueryToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return taskQueryToken{}, false
+	}
+	p.pos++
+	return p.tokens[p.pos-1], true
+}
+
+// expect returns the next token, failing at the end of the query
+func (p *taskQueryParser) expect(what string) (taskQueryToken, error) {
+	token, ok := p.next()
+	if !ok {
+		return token, NewValidationError("", "", "expected %s at the end of the query", what)
+	}
+	return token, nil
+}
+
+// condition parses a condition: a field, an operator and a value or a parenthesized list of values
+func (p *taskQueryParser) condition() (TaskCondition, error) {
+	token, err := p.expect("a field")
+	if err != nil {
+		return TaskCondition{}, err
+	}
+	field := TaskQueryField(strings.ToLower(token.text))
+	operators, ok := taskQueryOperators[field]
+	if token.quoted || !ok {
+		return TaskCondition{}, NewValidationError("", "", "unknown query field %q", token.text)
+	}
+
+	operator, err := p.operator()
+	if err != nil {
+		return TaskCondition{}, err
+	}
+	if !slices.Contains(operators, operator) {
+		return TaskCondition{}, NewValidationError("", "", "operator %q cannot be used with %s", operator, field)
+	}
+
+	var values []string
+	if operator == TaskQueryIn || operator == TaskQueryNotIn {
+		values, err = p.list()
+	} else {
+		var value taskQueryToken
+		value, err = p.value()
+		values = []string{value.text}
+	}
+	if err != nil {
+		return TaskCondition{}, err
+	}
+
+	condition := TaskCondition{Field: field, Operator: operator, Values: values}
+	if err := condition.normalize(); err != nil {
+		return TaskCondition{}, err
+	}
+	return condition, nil
+}
+
+// operator parses the operator of a condition
+func (p *taskQueryParser) operator() (TaskQueryOperator, error) {
+	token, err := p.expect("an operator")
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case token.quoted:
+	case token.text == "=" || token.text == "!=" || token.text == "~":
+		return TaskQueryOperator(token.text), nil
+	case token.keyword("in"):
+		return TaskQueryIn, nil
+	case token.keyword("not"):
+		if in, ok := p.next(); ok && in.keyword("in") {
+			return TaskQueryNotIn, nil
+		}
+		return "", NewValidationError("", "", "expected IN after NOT")
+	}
+	return "", NewValidationError("", "", "expected an operator at %q", token.text)
+}
+
+// value parses a single value
+func (p *taskQueryParser) value() (taskQueryToken, error) {
+	token, err := p.expect("a value")
+	if err != nil {
+		return token, err
+	}
+	if token.punctuation() {
+		return token, NewValidationError("", "", "expected a value at %q", token.text)
+	}
+	return token, nil
+}
+
+// list parses a parenthesized, comma separated list of values
+func (p *taskQueryParser) list() ([]string, error) {
+	token, err := p.expect("(")
+	if err != nil {
+		return nil, err
+	}
+	if token.quoted || token.text != "(" {
+		return nil, NewValidationError("", "", "expected ( at %q", token.text)
+	}
+
+	var values []string
+	for {
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value.text)
+
+		token, err := p.expect(")")
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case token.quoted:
+		case token.text == ")":
+			return values, nil
+		case token.text == ",":
+			continue
+		}
+		return nil, NewValidationError("", "", "expected , or ) at %q", token.text)
+	}
+}
+
+// normalize checks the values of a condition and brings them into the form stored on tasks
+func (c *TaskCondition) normalize() error {
+	switch c.Field {
+	case TaskQueryStatus:
+		for i, value := range c.Values {
+			c.Values[i] = strings.ToLower(value)
+			if !IsValidTaskStatus(TaskStatus(c.Values[i])) {
+				return NewValidationError("", "", "invalid status %q in query", value)
+			}
+		}
+	case TaskQueryPriority:
+		for i, value := range c.Values {
+			c.Values[i] = strings.ToLower(value)
+			priority := TaskPriority(c.Values[i])
+			if !IsValidTaskPriority(priority) {
+				return NewValidationError("", "", "invalid priority %q in query", value)
+			}
+		}
+	case TaskQueryTag:
+		c.Values = NormalizeTags(c.Values)
+		if len(c.Values) == 0 {
+			return NewValidationError("", "", "tag cannot be empty in query")
+		}
+	case TaskQueryCreatedAfter, TaskQueryCreatedBefore, TaskQueryUpdatedAfter, TaskQueryUpdatedBefore,
+		TaskQueryDueAfter, TaskQueryDueBefore:
+		date, err := ParseDueDate(c.Values[0])
+		if err != nil {
+			return NewValidationError("", "", "invalid date %q for %s: expected RFC3339 or YYYY-MM-DD", c.Values[0], c.Field)
+		}
+		c.time = date
+	}
+	return nil
+}
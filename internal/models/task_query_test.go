@@ -0,0 +1,85 @@
+package models
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseTaskQuery(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		wantErr    bool
+	}{
+		{"example", "status in (pending,in_progress) AND priority = high AND updated_after = 2025-01-01", false},
+		{"keywords in any case", "Status NOT IN (completed) and tag != 'blocked'", false},
+		{"quoted substring", `title ~ "login page"`, false},
+		{"timestamp", "due_before = 2025-06-01T12:00:00Z", false},
+		{"empty", "  ", true},
+		{"unknown field", "owner = alice", true},
+		{"unknown status", "status = done", true},
+		{"operator not allowed", "updated_after != 2025-01-01", true},
+		{"invalid date", "created_after = yesterday", true},
+		{"missing AND", "status = pending priority = high", true},
+		{"OR", "status = pending OR priority = high", true},
+		{"unterminated list", "status in (pending, completed", true},
+		{"unterminated quote", `title ~ "login`, true},
+		{"missing value", "status =", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTaskQuery(tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseTaskQuery(%q) error = %v, wantErr %v", tt.expression, err, tt.wantErr)
+			}
+			if err != nil && ErrorCodeOf(err) != ErrorCodeValidation {
+				t.Errorf("ErrorCodeOf() = %v, want %v", ErrorCodeOf(err), ErrorCodeValidation)
+			}
+		})
+	}
+}
+
+func TestTaskQueryMatches(t *testing.T) {
+	task := NewTask("task-1", "plan-1", "Fix the Login page", "", TaskPriorityHigh)
+	task.Status = TaskStatusInProgress
+	task.Tags = []string{"frontend"}
+	task.UpdatedAt = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{"status in (pending, in_progress) AND priority = high AND updated_after = 2025-01-01", true},
+		{"status = pending", false},
+		{"priority not in (low, medium)", true},
+		{"tag = Frontend AND title ~ 'login'", true},
+		{"tag != frontend", false},
+		{"application_id = app-1 AND plan_id in (plan-1, plan-2)", true},
+		{"application_id != app-1", false},
+		{"updated_before = 2025-01-01", false},
+		{"due_before = 2030-01-01", false},
+	}
+
+	for _, tt := range tests {
+		query, err := ParseTaskQuery(tt.expression)
+		if err != nil {
+			t.Fatalf("ParseTaskQuery(%q) error = %v", tt.expression, err)
+		}
+		if got := query.Matches(task, "app-1"); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.expression, got, tt.want)
+		}
+	}
+
+	query, err := ParseTaskQuery("plan_id in (plan-1, plan-2) AND plan_id = plan-2 AND status = pending")
+	if err != nil {
+		t.Fatalf("ParseTaskQuery() error = %v", err)
+	}
+	if values, ok := query.Values(TaskQueryPlanID); !ok || !slices.Equal(values, []string{"plan-2"}) {
+		t.Errorf("Values(plan_id) = %v, %v, want the values allowed by both conditions", values, ok)
+	}
+	if _, ok := query.Values(TaskQueryTag); ok {
+		t.Error("Values(tag) should report that the query has no tag conditions")
+	}
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// TaskSortField is a field tasks can be sorted by
+type TaskSortField string
+
+const (
+	TaskSortOrder     TaskSortField = "order"
+	TaskSortPriority  TaskSortField = "priority"
+	TaskSortUpdatedAt TaskSortField = "updated_at"
+	TaskSortDueDate   TaskSortField = "due_date"
+	TaskSortTitle     TaskSortField = "title"
+)
+
+// TaskSortFields lists the fields tasks can be sorted by
+var TaskSortFields = []TaskSortField{TaskSortOrder, TaskSortPriority, TaskSortUpdatedAt, TaskSortDueDate, TaskSortTitle}
+
+// SortDirection is the direction of a sort
+type SortDirection string
+
+const (
+	SortAscending  SortDirection = "asc"
+	SortDescending SortDirection = "desc"
+)
+
+// TaskSort sorts lists of tasks by a field. The zero TaskSort keeps tasks in their order.
+type TaskSort struct {
+	Field     TaskSortField
+	Direction SortDirection
+}
+
+// NewTaskSort creates a task sort. Without a field tasks keep their order; without a direction priorities and
+// update times sort descending, highest and most recent first, and the other fields ascending.
+func NewTaskSort(field, direction string) (TaskSort, error) {
+	sort := TaskSort{Field: TaskSortField(field), Direction: SortDirection(direction)}
+	if sort.Field == "" {
+		sort.Field = TaskSortOrder
+	}
+	if !slices.Contains(TaskSortFields, sort.Field) {
+		return TaskSort{}, NewValidationError("", "", "invalid sort field %q", field)
+	}
+
+	switch sort.Direction {
+	case "":
+		sort.Direction = SortAscending
+		if sort.Field == TaskSortPriority || sort.Field == TaskSortUpdatedAt {
+			sort.Direction = SortDescending
+		}
+	case SortAscending, SortDescending:
+	default:
+		return TaskSort{}, NewValidationError("", "", "invalid sort direction %q", direction)
+	}
+	return sort, nil
+}
+
+// Sort sorts tasks in place. Sorting is stable, so tasks with equal values keep their relative order, and
+// tasks without a due date come last in either direction when sorting by due date. Sorting by order keeps
+// the tasks of each plan together, with the plans in the order they first appear.
+func (s TaskSort) Sort(tasks []*Task) {
+	if s.Field == "" {
+		return
+	}
+
+	plans := make(map[string]int)
+	if s.Field == TaskSortOrder {
+		for _, task := range tasks {
+			if _, ok := plans[task.PlanID]; !ok {
+				plans[task.PlanID] = len(plans)
+			}
+		}
+	}
+
+	slices.SortStableFunc(tasks, func(a, b *Task) int {
+		if s.Field == TaskSortDueDate && (a.DueDate == nil || b.DueDate == nil) {
+			switch {
+			case a.DueDate != nil:
+				return -1
+			case b.DueDate != nil:
+				return 1
+			default:
+				return 0
+			}
+		}
+
+		result := s.compare(a, b, plans)
+		if s.Direction == SortDescending {
+			return -result
+		}
+		return result
+	})
+}
+
+// compare compares two tasks by the sort field in ascending order, given the positions of the plans
+func (s TaskSort) compare(a, b *Task, plans map[string]int) int {
+	switch s.Field {
+	case TaskSortPriority:
+		return cmp.Compare(PriorityRank(a.Priority), PriorityRank(b.Priority))
+	case TaskSortUpdatedAt:
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	case TaskSortDueDate:
+		return a.DueDate.Compare(*b.DueDate)
+	case TaskSortTitle:
+		return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	default:
+		return cmp.Or(cmp.Compare(plans[a.PlanID], plans[b.PlanID]), cmp.Compare(a.Order, b.Order))
+	}
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTaskSort(t *testing.T) {
+	due := func(day int) *time.Time {
+		date := time.Date(2025, 1, day, 0, 0, 0, 0, time.UTC)
+		return &date
+	}
+	newTasks := func() []*Task {
+		return []*Task{
+			{ID: "a", PlanID: "plan-2", Order: 1, Title: "beta", Priority: TaskPriorityLow, DueDate: due(3)},
+			{ID: "b", PlanID: "plan-2", Order: 0, Title: "Alpha", Priority: TaskPriorityHigh},
+			{ID: "c", PlanID: "plan-1", Order: 0, Title: "gamma", Priority: TaskPriorityMedium, DueDate: due(1)},
+			{ID: "d", PlanID: "plan-1", Order: 1, Title: "delta", Priority: TaskPriorityHigh},
+		}
+	}
+
+	tests := []struct {
+		field     string
+		direction string
+		want      []string
+	}{
+		{"", "", []string{"a", "b", "c", "d"}},
+		{"order", "", []string{"b", "a", "c", "d"}},
+		{"priority", "", []string{"b", "d", "c", "a"}},
+		{"priority", "asc", []string{"a", "c", "b", "d"}},
+		{"title", "", []string{"b", "a", "d", "c"}},
+		{"due_date", "", []string{"c", "a", "b", "d"}},
+		{"due_date", "desc", []string{"a", "c", "b", "d"}},
+	}
+
+	for _, tt := range tests {
+		sort := TaskSort{}
+		if tt.field != "" {
+			var err error
+			if sort, err = NewTaskSort(tt.field, tt.direction); err != nil {
+				t.Fatalf("NewTaskSort(%q, %q) error = %v", tt.field, tt.direction, err)
+			}
+		}
+		tasks := newTasks()
+		sort.Sort(tasks)
+
+		ids := make([]string, len(tasks))
+		for i, task := range tasks {
+			ids[i] = task.ID
+		}
+		if !slices.Equal(ids, tt.want) {
+			t.Errorf("sorting by %q %q = %v, want %v", tt.field, tt.direction, ids, tt.want)
+		}
+	}
+
+	for _, args := range [][2]string{{"status", ""}, {"title", "up"}} {
+		if _, err := NewTaskSort(args[0], args[1]); err == nil || ErrorCodeOf(err) != ErrorCodeValidation {
+			t.Errorf("NewTaskSort(%q, %q) error = %v, want a validation error", args[0], args[1], err)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// Statuses of the optional review workflow, where finished work waits for a reviewer before it is completed.
+// They are only accepted once enabled with EnableReviewStatuses.
+const (
+	TaskStatusInReview TaskStatus = "in_review"
+	TaskStatusApproved TaskStatus = "approved"
+	TaskStatusRejected TaskStatus = "rejected"
+)
+
+// ReviewTaskStatuses are the statuses of the review workflow, in the order a task passes through them
+var ReviewTaskStatuses = []TaskStatus{TaskStatusInReview, TaskStatusApproved, TaskStatusRejected}
+
+// TaskStatusCategory is what a status means for the plan of a task: whether the task waits to be started,
+// is being worked on, or is closed as completed or cancelled
+type TaskStatusCategory string
+
+const (
+	TaskStatusCategoryOpen      TaskStatusCategory = "open"
+	TaskStatusCategoryActive    TaskStatusCategory = "active"
+	TaskStatusCategoryCompleted TaskStatusCategory = "completed"
+	TaskStatusCategoryCancelled TaskStatusCategory = "cancelled"
+)
+
+// TaskStatusDefinition defines a custom task status configured by the operator
+type TaskStatusDefinition struct {
+	Status   TaskStatus
+	Category TaskStatusCategory
+}
+
+// customValueNamePattern matches the names of custom statuses and priorities
+var customValueNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// enabledReviewStatuses holds the review statuses enabled for the deployment
+var enabledReviewStatuses atomic.Pointer[[]TaskStatus]
+
+// customTaskStatuses holds the custom statuses configured for the deployment
+var customTaskStatuses atomic.Pointer[[]TaskStatusDefinition]
+
+// EnableReviewStatuses enables the given review statuses, so tasks may be set to them. It is called once at
+// startup, before tools are registered, as tool schemas list the statuses enabled then. Tasks keep a review
+// status that is disabled later, but cannot be set to it again.
+func EnableReviewStatuses(statuses []TaskStatus) error {
+	for _, status := range statuses {
+		if !status.IsReview() {
+			return fmt.Errorf("invalid review status %q, expected one of %s", status, joinStatuses(ReviewTaskStatuses))
+		}
+	}
+	enabled := make([]TaskStatus, 0, len(statuses))
+	for _, status := range ReviewTaskStatuses {
+		if slices.Contains(statuses, status) {
+			enabled = append(enabled, status)
+		}
+	}
+	enabledReviewStatuses.Store(&enabled)
+	return nil
+}
+
+// ParseReviewStatuses parses a comma-separated list of review statuses
+func ParseReviewStatuses(value string) ([]TaskStatus, error) {
+	var statuses []TaskStatus
+	for name := range strings.SplitSeq(value, ",") {
+		status := TaskStatus(strings.ToLower(strings.TrimSpace(name)))
+		if status == "" {
+			continue
+		}
+		if !status.IsReview() {
+			return nil, fmt.Errorf("invalid review status %q, expected one of %s", name, joinStatuses(ReviewTaskStatuses))
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// SetCustomTaskStatuses configures the custom statuses tasks may be set to, ordered as listed within their
+// category. Like the review statuses they are set once at startup, and tasks keep a custom status that is
+// removed later. Custom statuses are open or active, as completed and cancelled tasks have their own
+// handling, such as completion notes and recurrence.
+func SetCustomTaskStatuses(definitions []TaskStatusDefinition) error {
+	seen := make(map[TaskStatus]bool, len(definitions))
+	for _, definition := range definitions {
+		switch {
+		case !customValueNamePattern.MatchString(string(definition.Status)):
+			return fmt.Errorf("invalid custom status %q, expected lowercase letters, digits and underscores",
+				definition.Status)
+		case slices.Contains(baseTaskStatuses, definition.Status) || definition.Status.IsReview():
+			return fmt.Errorf("custom status %q is a built-in status", definition.Status)
+		case seen[definition.Status]:
+			return fmt.Errorf("custom status %q is defined more than once", definition.Status)
+		case definition.Category != TaskStatusCategoryOpen && definition.Category != TaskStatusCategoryActive:
+			return fmt.Errorf("invalid category %q of custom status %q, expected open or active",
+				definition.Category, definition.Status)
+		}
+		seen[definition.Status] = true
+	}
+	definitions = slices.Clone(definitions)
+	customTaskStatuses.Store(&definitions)
+	return nil
+}
+
+// ParseCustomTaskStatuses parses a comma-separated list of custom statuses, each a name and a category
+// separated by a colon such as "blocked:open". The category defaults to open.
+func ParseCustomTaskStatuses(value string) []TaskStatusDefinition {
+	var definitions []TaskStatusDefinition
+	for entry := range strings.SplitSeq(value, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		name, category, found := strings.Cut(entry, ":")
+		if !found {
+			category = string(TaskStatusCategoryOpen)
+		}
+		definitions = append(definitions, TaskStatusDefinition{
+			Status:   TaskStatus(strings.TrimSpace(name)),
+			Category: TaskStatusCategory(strings.TrimSpace(category)),
+		})
+	}
+	return definitions
+}
+
+// baseTaskStatuses are the statuses every task may have
+var baseTaskStatuses = []TaskStatus{TaskStatusPending, TaskStatusInProgress, TaskStatusCompleted, TaskStatusCancelled}
+
+// customStatuses returns the custom statuses of a category
+func customStatuses(category TaskStatusCategory) []TaskStatus {
+	var statuses []TaskStatus
+	if definitions := customTaskStatuses.Load(); definitions != nil {
+		for _, definition := range *definitions {
+			if definition.Category == category {
+				statuses = append(statuses, definition.Status)
+			}
+		}
+	}
+	return statuses
+}
+
+// TaskStatuses returns the statuses tasks may be set to in the order of their lifecycle: pending and the open
+// custom statuses, in progress, the enabled review statuses and the active custom statuses, then completed
+// and cancelled
+func TaskStatuses() []TaskStatus {
+	statuses := []TaskStatus{TaskStatusPending}
+	statuses = append(statuses, customStatuses(TaskStatusCategoryOpen)...)
+	statuses = append(statuses, TaskStatusInProgress)
+	if enabled := enabledReviewStatuses.Load(); enabled != nil {
+		statuses = append(statuses, *enabled...)
+	}
+	statuses = append(statuses, customStatuses(TaskStatusCategoryActive)...)
+	return append(statuses, TaskStatusCompleted, TaskStatusCancelled)
+}
+
+// TaskStatusNames returns the names of the statuses tasks may be set to, for tool schemas and validation
+func TaskStatusNames() []string {
+	return statusNames(TaskStatuses())
+}
+
+// NewTaskStatusCounts returns counts of tasks by status, starting at zero for every status tasks may be set to
+func NewTaskStatusCounts() map[TaskStatus]int {
+	counts := make(map[TaskStatus]int)
+	for _, status := range TaskStatuses() {
+		counts[status] = 0
+	}
+	return counts
+}
+
+// Category returns the category of a status. Review statuses are active whether they are enabled or not,
+// and statuses that are not configured have no category.
+func (s TaskStatus) Category() TaskStatusCategory {
+	switch {
+	case s == TaskStatusPending:
+		return TaskStatusCategoryOpen
+	case s == TaskStatusInProgress || s.IsReview():
+		return TaskStatusCategoryActive
+	case s == TaskStatusCompleted:
+		return TaskStatusCategoryCompleted
+	case s == TaskStatusCancelled:
+		return TaskStatusCategoryCancelled
+	}
+	if definitions := customTaskStatuses.Load(); definitions != nil {
+		for _, definition := range *definitions {
+			if definition.Status == s {
+				return definition.Category
+			}
+		}
+	}
+	return ""
+}
+
+// ActiveTaskStatuses returns the statuses of tasks being worked on, which keep their plan in progress
+func ActiveTaskStatuses() []TaskStatus {
+	statuses := []TaskStatus{TaskStatusInProgress}
+	statuses = append(statuses, ReviewTaskStatuses...)
+	return append(statuses, customStatuses(TaskStatusCategoryActive)...)
+}
+
+// IsReview reports whether the status belongs to the review workflow, enabled or not
+func (s TaskStatus) IsReview() bool {
+	return slices.Contains(ReviewTaskStatuses, s)
+}
+
+// IsValidTaskStatus reports whether tasks may be set to the status
+func IsValidTaskStatus(status TaskStatus) bool {
+	return slices.Contains(TaskStatuses(), status)
+}
+
+// ValidateTaskStatus checks that tasks may be set to the status
+func ValidateTaskStatus(status TaskStatus) error {
+	if IsValidTaskStatus(status) {
+		return nil
+	}
+	if status.IsReview() {
+		return NewValidationError("", "", "status %s is not enabled, expected one of %s",
+			status, joinStatuses(TaskStatuses()))
+	}
+	return NewValidationError("", "", "invalid status: %s, expected one of %s", status, joinStatuses(TaskStatuses()))
+}
+
+// statusNames converts statuses to their names
+func statusNames(statuses []TaskStatus) []string {
+	names := make([]string, len(statuses))
+	for i, status := range statuses {
+		names[i] = string(status)
+	}
+	return names
+}
+
+// joinStatuses lists statuses for messages
+func joinStatuses(statuses []TaskStatus) string {
+	return strings.Join(statusNames(statuses), ", ")
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEnableReviewStatuses(t *testing.T) {
+	t.Cleanup(func() { EnableReviewStatuses(nil) }) //nolint:errcheck
+
+	// Review statuses are rejected until they are enabled
+	if err := ValidateTaskStatus(TaskStatusInReview); ErrorCodeOf(err) != ErrorCodeValidation {
+		t.Errorf("expected a validation error for a disabled review status, got %v", err)
+	}
+	if len(TaskStatuses()) != 4 {
+		t.Errorf("expected the 4 base statuses, got %v", TaskStatuses())
+	}
+
+	statuses, err := ParseReviewStatuses(" Rejected, in_review ")
+	if err != nil {
+		t.Fatalf("failed to parse review statuses: %v", err)
+	}
+	if err := EnableReviewStatuses(statuses); err != nil {
+		t.Fatalf("failed to enable review statuses: %v", err)
+	}
+	want := []string{"pending", "in_progress", "in_review", "rejected", "completed", "cancelled"}
+	if names := TaskStatusNames(); !slices.Equal(names, want) {
+		t.Errorf("TaskStatusNames() = %v, want %v", names, want)
+	}
+	if err := ValidateTaskStatus(TaskStatusInReview); err != nil {
+		t.Errorf("expected in_review to be valid, got %v", err)
+	}
+	if IsValidTaskStatus(TaskStatusApproved) {
+		t.Error("expected approved to stay disabled")
+	}
+
+	if _, err := ParseReviewStatuses("in_review,done"); err == nil {
+		t.Error("expected an error for a status that is not a review status")
+	}
+	if err := EnableReviewStatuses([]TaskStatus{TaskStatusPending}); err == nil {
+		t.Error("expected an error enabling a base status")
+	}
+}
+
+func TestTaskCountsReviewStatuses(t *testing.T) {
+	counts := &TaskCounts{}
+	counts.Add(TaskStatusCompleted, 1)
+	counts.Add(TaskStatusApproved, 1)
+	if counts.PlanStatus() != PlanStatusInProgress {
+		t.Errorf("expected a plan with an approved task to be in progress, got %s", counts.PlanStatus())
+	}
+
+	// The counters survive the plan hash
+	stored, err := TaskCountsFromFields(counts.Fields())
+	if err != nil {
+		t.Fatalf("failed to read the counters: %v", err)
+	}
+	if !stored.Equal(counts) || stored.Count(TaskStatusApproved) != 1 {
+		t.Errorf("got %+v, want %+v", stored, counts)
+	}
+
+	counts.Add(TaskStatusApproved, -1)
+	counts.Add(TaskStatusCompleted, 1)
+	if counts.PlanStatus() != PlanStatusCompleted {
+		t.Errorf("expected the plan to be completed, got %s", counts.PlanStatus())
+	}
+}
+
+func TestCustomTaskStatuses(t *testing.T) {
+	t.Cleanup(func() { SetCustomTaskStatuses(nil) }) //nolint:errcheck
+
+	definitions := ParseCustomTaskStatuses("blocked, testing:active")
+	if err := SetCustomTaskStatuses(definitions); err != nil {
+		t.Fatalf("failed to set custom statuses: %v", err)
+	}
+	want := []string{"pending", "blocked", "in_progress", "testing", "completed", "cancelled"}
+	if names := TaskStatusNames(); !slices.Equal(names, want) {
+		t.Errorf("TaskStatusNames() = %v, want %v", names, want)
+	}
+	if TaskStatus("testing").Category() != TaskStatusCategoryActive || TaskStatus("blocked").Category() != TaskStatusCategoryOpen {
+		t.Errorf("unexpected categories %s and %s", TaskStatus("testing").Category(), TaskStatus("blocked").Category())
+	}
+
+	// Tasks in an active custom status keep the plan in progress, those in an open one do not
+	counts := &TaskCounts{}
+	counts.Add("blocked", 1)
+	if counts.PlanStatus() != PlanStatusNew {
+		t.Errorf("expected a plan with a blocked task to be new, got %s", counts.PlanStatus())
+	}
+	counts.Add("testing", 1)
+	if counts.PlanStatus() != PlanStatusInProgress {
+		t.Errorf("expected a plan with a task in testing to be in progress, got %s", counts.PlanStatus())
+	}
+	stored, err := TaskCountsFromFields(counts.Fields())
+	if err != nil {
+		t.Fatalf("failed to read the counters: %v", err)
+	}
+	if !stored.Equal(counts) || stored.Count("testing") != 1 {
+		t.Errorf("got %+v, want %+v", stored, counts)
+	}
+
+	for _, invalid := range []string{"done:completed", "pending", "Not-A-Name", "blocked,blocked"} {
+		if err := SetCustomTaskStatuses(ParseCustomTaskStatuses(invalid)); err == nil {
+			t.Errorf("expected an error for custom statuses %q", invalid)
+		}
+	}
+}
+
+func TestCustomTaskPriorities(t *testing.T) {
+	t.Cleanup(func() { SetCustomTaskPriorities(nil) }) //nolint:errcheck
+
+	definitions, err := ParseCustomTaskPriorities("critical:40, normal_plus:25")
+	if err != nil {
+		t.Fatalf("failed to parse custom priorities: %v", err)
+	}
+	if err := SetCustomTaskPriorities(definitions); err != nil {
+		t.Fatalf("failed to set custom priorities: %v", err)
+	}
+	want := []string{"low", "medium", "normal_plus", "high", "critical"}
+	if names := TaskPriorityNames(); !slices.Equal(names, want) {
+		t.Errorf("TaskPriorityNames() = %v, want %v", names, want)
+	}
+	if !(PriorityRank("critical") > PriorityRank(TaskPriorityHigh) &&
+		PriorityRank("normal_plus") > PriorityRank(TaskPriorityMedium)) {
+		t.Error("custom priorities are not ranked by their weight")
+	}
+	if err := ValidateTaskPriority("urgent"); ErrorCodeOf(err) != ErrorCodeValidation {
+		t.Errorf("expected a validation error for an unknown priority, got %v", err)
+	}
+
+	if _, err := ParseCustomTaskPriorities("critical"); err == nil {
+		t.Error("expected an error for a priority without a weight")
+	}
+	for _, invalid := range []string{"high:50", "critical:0"} {
+		definitions, _ := ParseCustomTaskPriorities(invalid)
+		if err := SetCustomTaskPriorities(definitions); err == nil {
+			t.Errorf("expected an error for custom priorities %q", invalid)
+		}
+	}
+}
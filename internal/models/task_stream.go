@@ -0,0 +1,20 @@
+package models
+
+// TaskChunk is a chunk of the tasks of a plan sent while streaming the tasks
+type TaskChunk struct {
+	PlanID string `json:"plan_id"`
+	// Offset is the position in the plan of the first task read for the chunk
+	Offset int     `json:"offset"`
+	Tasks  []*Task `json:"tasks"`
+}
+
+// TaskStreamResult summarizes a streamed list of the tasks of a plan. Clients that cannot receive the chunks as
+// notifications get the tasks in the result instead.
+type TaskStreamResult struct {
+	PlanID string `json:"plan_id"`
+	// Total is the number of tasks sent
+	Total int `json:"total"`
+	// Chunks is the number of chunks sent as notifications
+	Chunks int     `json:"chunks"`
+	Tasks  []*Task `json:"tasks,omitempty"`
+}
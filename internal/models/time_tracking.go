@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+// RecordStatusTransition stamps the time tracking fields for a change from the previous status to the
+// task's current status. Time spent in progress is accumulated whenever the task leaves in_progress.
+func (t *Task) RecordStatusTransition(previous TaskStatus, now time.Time) {
+	if previous == t.Status {
+		return
+	}
+
+	// Leaving in_progress closes the running work interval
+	if previous == TaskStatusInProgress && t.InProgressSince != nil {
+		t.TimeSpent += int64(now.Sub(*t.InProgressSince).Seconds())
+		t.InProgressSince = nil
+	}
+
+	switch t.Status {
+	case TaskStatusInProgress:
+		if t.StartedAt == nil {
+			t.StartedAt = &now
+		}
+		t.InProgressSince = &now
+		t.CompletedAt = nil
+	case TaskStatusCompleted:
+		t.CompletedAt = &now
+	default:
+		t.CompletedAt = nil
+	}
+}
+
+// TotalTimeSpent returns the accumulated time spent on the task, including a running in-progress interval
+func (t *Task) TotalTimeSpent(now time.Time) time.Duration {
+	total := time.Duration(t.TimeSpent) * time.Second
+	if t.Status == TaskStatusInProgress && t.InProgressSince != nil {
+		total += now.Sub(*t.InProgressSince)
+	}
+	return total
+}
+
+// TaskTimeEntry summarizes the time spent on a single task
+type TaskTimeEntry struct {
+	TaskID      string     `json:"task_id"`
+	Title       string     `json:"title"`
+	Status      TaskStatus `json:"status"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	TimeSpent   int64      `json:"time_spent"`
+}
+
+// PlanTimeReport summarizes the time spent on the tasks of a plan. Durations are in seconds.
+type PlanTimeReport struct {
+	PlanID         string           `json:"plan_id"`
+	PlanName       string           `json:"plan_name"`
+	TotalTimeSpent int64            `json:"total_time_spent"`
+	Tasks          []*TaskTimeEntry `json:"tasks"`
+	ComputedAt     time.Time        `json:"computed_at"`
+}
@@ -0,0 +1,182 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TimelineEventType describes what happened in a timeline event
+type TimelineEventType string
+
+const (
+	TimelineEventPlanCreated   TimelineEventType = "plan_created"
+	TimelineEventPlanUpdated   TimelineEventType = "plan_updated"
+	TimelineEventPlanDeleted   TimelineEventType = "plan_deleted"
+	TimelineEventTaskCreated   TimelineEventType = "task_created"
+	TimelineEventTaskUpdated   TimelineEventType = "task_updated"
+	TimelineEventTaskDeleted   TimelineEventType = "task_deleted"
+	TimelineEventStatusChanged TimelineEventType = "status_changed"
+	TimelineEventNotesUpdated  TimelineEventType = "notes_updated"
+	// TimelineEventCommentAdded marks text appended to the notes, which is how agents leave comments
+	TimelineEventCommentAdded TimelineEventType = "comment_added"
+)
+
+// timelineIgnoredFields are fields that change as a side effect of other changes and are left out of
+// generic update events
+var timelineIgnoredFields = map[string]bool{
+	"task_counts": true,
+}
+
+// statusSideEffectFields are the time tracking fields every status change updates, which are left out of
+// the events of status changes
+var statusSideEffectFields = []string{"started_at", "completed_at", "time_spent"}
+
+// TimelineEvent is a single event in the chronological feed of a plan, derived from an audit entry.
+// One audit entry can yield several events, such as a status change and a notes update made together.
+type TimelineEvent struct {
+	Timestamp  time.Time         `json:"timestamp"`
+	Type       TimelineEventType `json:"type"`
+	EntityType EntityType        `json:"entity_type"`
+	EntityID   string            `json:"entity_id"`
+	// Title is the name of the plan or the title of the task at the time of the event
+	Title     string `json:"title,omitempty"`
+	Actor     string `json:"actor,omitempty"`
+	Operation string `json:"operation"`
+	// EntryID is the ID of the audit entry the event was derived from
+	EntryID string `json:"entry_id"`
+	// Summary is a short human readable description of the event
+	Summary string                 `json:"summary"`
+	Changes map[string]FieldChange `json:"changes,omitempty"`
+}
+
+// NewTimeline converts audit entries of a plan and its tasks into a chronological feed of events,
+// oldest first
+func NewTimeline(entries []*AuditEntry) []*TimelineEvent {
+	events := make([]*TimelineEvent, 0, len(entries))
+	for _, entry := range entries {
+		events = append(events, timelineEvents(entry)...)
+	}
+
+	// Stream IDs of one entity are ordered, but entries of different entities are only ordered by time
+	slices.SortStableFunc(events, func(a, b *TimelineEvent) int {
+		if c := a.Timestamp.Compare(b.Timestamp); c != 0 {
+			return c
+		}
+		return strings.Compare(a.EntryID, b.EntryID)
+	})
+	return events
+}
+
+// timelineEvents derives the events of a single audit entry
+func timelineEvents(entry *AuditEntry) []*TimelineEvent {
+	title := snapshotTitle(entry.After)
+	if title == "" {
+		title = snapshotTitle(entry.Before)
+	}
+	name := string(entry.EntityType)
+	if title != "" {
+		name = fmt.Sprintf("%s %q", entry.EntityType, title)
+	}
+
+	newEvent := func(eventType TimelineEventType, summary string, changes map[string]FieldChange) *TimelineEvent {
+		return &TimelineEvent{
+			Timestamp:  entry.Timestamp,
+			Type:       eventType,
+			EntityType: entry.EntityType,
+			EntityID:   entry.EntityID,
+			Title:      title,
+			Actor:      entry.Actor,
+			Operation:  entry.Operation,
+			EntryID:    entry.ID,
+			Summary:    summary,
+			Changes:    changes,
+		}
+	}
+
+	switch entry.Action {
+	case AuditActionCreate:
+		eventType := TimelineEventTaskCreated
+		if entry.EntityType == EntityTypePlan {
+			eventType = TimelineEventPlanCreated
+		}
+		return []*TimelineEvent{newEvent(eventType, fmt.Sprintf("Created %s", name), nil)}
+	case AuditActionDelete:
+		eventType := TimelineEventTaskDeleted
+		if entry.EntityType == EntityTypePlan {
+			eventType = TimelineEventPlanDeleted
+		}
+		return []*TimelineEvent{newEvent(eventType, fmt.Sprintf("Deleted %s", name), nil)}
+	}
+
+	var events []*TimelineEvent
+	remaining := maps.Clone(entry.Changes)
+
+	if change, ok := remaining["status"]; ok {
+		delete(remaining, "status")
+		for _, field := range statusSideEffectFields {
+			delete(remaining, field)
+		}
+		summary := fmt.Sprintf("Changed status of %s from %v to %v", name, change.Before, change.After)
+		events = append(events, newEvent(TimelineEventStatusChanged, summary,
+			map[string]FieldChange{"status": change}))
+	}
+
+	if change, ok := remaining["notes"]; ok {
+		delete(remaining, "notes")
+		eventType, summary := TimelineEventNotesUpdated, fmt.Sprintf("Updated notes of %s", name)
+		if entry.Operation == "append_notes" {
+			eventType, summary = TimelineEventCommentAdded, fmt.Sprintf("Added a comment to %s", name)
+		}
+		events = append(events, newEvent(eventType, summary, map[string]FieldChange{"notes": change}))
+	}
+
+	for field := range timelineIgnoredFields {
+		delete(remaining, field)
+	}
+	if len(remaining) > 0 {
+		eventType := TimelineEventTaskUpdated
+		if entry.EntityType == EntityTypePlan {
+			eventType = TimelineEventPlanUpdated
+		}
+		summary := fmt.Sprintf("Updated %s of %s", strings.Join(slices.Sorted(maps.Keys(remaining)), ", "), name)
+		events = append(events, newEvent(eventType, summary, remaining))
+	}
+
+	return events
+}
+
+// snapshotTitle returns the name of a plan or the title of a task from an audit snapshot
+func snapshotTitle(snapshot json.RawMessage) string {
+	if len(snapshot) == 0 {
+		return ""
+	}
+	var fields struct {
+		Name  string `json:"name"`
+		Title string `json:"title"`
+	}
+	if err := json.Unmarshal(snapshot, &fields); err != nil {
+		return ""
+	}
+	if fields.Title != "" {
+		return fields.Title
+	}
+	return fields.Name
+}
+
+// SnapshotPlanID returns the plan ID a task belonged to in an audit snapshot
+func SnapshotPlanID(snapshot json.RawMessage) string {
+	if len(snapshot) == 0 {
+		return ""
+	}
+	var fields struct {
+		PlanID string `json:"plan_id"`
+	}
+	if err := json.Unmarshal(snapshot, &fields); err != nil {
+		return ""
+	}
+	return fields.PlanID
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestNewTimeline(t *testing.T) {
+	start := time.Date(2025, time.July, 1, 12, 0, 0, 0, time.UTC)
+	entries := []*AuditEntry{
+		{
+			ID:         "3-0",
+			EntityType: EntityTypeTask,
+			EntityID:   "task-1",
+			Action:     AuditActionUpdate,
+			Operation:  "update",
+			Timestamp:  start.Add(2 * time.Minute),
+			After:      json.RawMessage(`{"title":"Write docs","plan_id":"plan-1"}`),
+			Changes: map[string]FieldChange{
+				"status":   {Before: "pending", After: "in_progress"},
+				"notes":    {Before: "", After: "Started"},
+				"priority": {Before: "low", After: "high"},
+			},
+		},
+		{
+			ID:         "1-0",
+			EntityType: EntityTypePlan,
+			EntityID:   "plan-1",
+			Action:     AuditActionCreate,
+			Operation:  "create",
+			Actor:      "session-1",
+			Timestamp:  start,
+			After:      json.RawMessage(`{"name":"Release"}`),
+		},
+		{
+			ID:         "4-0",
+			EntityType: EntityTypePlan,
+			EntityID:   "plan-1",
+			Action:     AuditActionUpdate,
+			Operation:  "append_notes",
+			Timestamp:  start.Add(3 * time.Minute),
+			Changes: map[string]FieldChange{
+				"notes":       {Before: "", After: "Looks good"},
+				"task_counts": {Before: nil, After: map[string]any{"total": 1}},
+			},
+		},
+		{
+			ID:         "2-0",
+			EntityType: EntityTypeTask,
+			EntityID:   "task-1",
+			Action:     AuditActionCreate,
+			Operation:  "create",
+			Timestamp:  start.Add(time.Minute),
+			After:      json.RawMessage(`{"title":"Write docs","plan_id":"plan-1"}`),
+		},
+	}
+
+	events := NewTimeline(entries)
+
+	want := []TimelineEventType{
+		TimelineEventPlanCreated,
+		TimelineEventTaskCreated,
+		TimelineEventStatusChanged,
+		TimelineEventNotesUpdated,
+		TimelineEventTaskUpdated,
+		TimelineEventCommentAdded,
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i, eventType := range want {
+		if events[i].Type != eventType {
+			t.Errorf("event %d is %s, want %s", i, events[i].Type, eventType)
+		}
+	}
+
+	if events[0].Summary != `Created plan "Release"` || events[0].Actor != "session-1" {
+		t.Errorf("unexpected plan creation event: %+v", events[0])
+	}
+	if events[2].Summary != `Changed status of task "Write docs" from pending to in_progress` {
+		t.Errorf("unexpected status change summary: %s", events[2].Summary)
+	}
+	if len(events[4].Changes) != 1 || events[4].Changes["priority"].After != "high" {
+		t.Errorf("generic update should only hold the remaining changes: %+v", events[4].Changes)
+	}
+}
+
+func TestSnapshotPlanID(t *testing.T) {
+	if got := SnapshotPlanID(json.RawMessage(`{"id":"task-1","plan_id":"plan-1"}`)); got != "plan-1" {
+		t.Errorf("SnapshotPlanID = %q, want plan-1", got)
+	}
+	if got := SnapshotPlanID(nil); got != "" {
+		t.Errorf("SnapshotPlanID(nil) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizeTitle reduces a task title to a canonical form for duplicate detection.
+// It lowercases the title, treats punctuation as whitespace and collapses runs of whitespace,
+// so "Add login page." and "add  login-page" normalize to the same value.
+func NormalizeTitle(title string) string {
+	fields := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
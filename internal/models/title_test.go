@@ -0,0 +1,36 @@
+package models
+
+import "testing"
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{"unchanged", "add login page", "add login page"},
+		{"case", "Add Login Page", "add login page"},
+		{"surrounding whitespace", "  add login page\t", "add login page"},
+		{"inner whitespace", "add   login \n page", "add login page"},
+		{"punctuation", "Add login-page.", "add login page"},
+		{"digits kept", "Step 2: migrate v1 API", "step 2 migrate v1 api"},
+		{"only punctuation", "...", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeTitle(tt.title); got != tt.want {
+				t.Errorf("NormalizeTitle(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPriorityRank(t *testing.T) {
+	if !(PriorityRank(TaskPriorityHigh) > PriorityRank(TaskPriorityMedium) &&
+		PriorityRank(TaskPriorityMedium) > PriorityRank(TaskPriorityLow) &&
+		PriorityRank(TaskPriorityLow) > PriorityRank("")) {
+		t.Errorf("priority ranks are not ordered high > medium > low > unknown")
+	}
+}
@@ -0,0 +1,19 @@
+package models
+
+// WarningCode classifies warnings so clients can handle them without matching messages
+type WarningCode string
+
+const (
+	// WarningCodeDecomposeTask is used for tasks whose description suggests they should be split into smaller tasks
+	WarningCodeDecomposeTask WarningCode = "DECOMPOSE_TASK"
+	// WarningCodeSplitPlan is used for plans with so many tasks that they should be split into smaller plans
+	WarningCodeSplitPlan WarningCode = "SPLIT_PLAN"
+)
+
+// Warning is a concern about a change that did not stop it, returned to the client next to the result
+type Warning struct {
+	Code    WarningCode `json:"code"`
+	Message string      `json:"message"`
+	Entity  string      `json:"entity,omitempty"`
+	ID      string      `json:"id,omitempty"`
+}
@@ -0,0 +1,176 @@
+// Package scheduler runs periodic background jobs such as lease expiry and plan retention.
+package scheduler
+
+import (
+	"context"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultJitter is the share of a job's interval added at random to each wait, so replicas started
+// together do not hit Valkey at the same moment
+const DefaultJitter = 0.1
+
+// lockShare is the share of a job's interval for which a run holds the job lock. The next run of the
+// replica holding the lock comes at least a full interval later, so it finds the lock free again.
+const lockShare = 0.9
+
+// Job is a background job run every interval
+type Job struct {
+	// Name identifies the job in logs and in its lock
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Locker grants a job run to a single server replica
+type Locker interface {
+	TryLock(ctx context.Context, job string, ttl time.Duration) (bool, error)
+}
+
+// Elector elects the server replica that runs the jobs
+type Elector interface {
+	// Campaign takes or renews leadership and reports whether this replica leads
+	Campaign(ctx context.Context) (bool, error)
+	// Resign gives up leadership
+	Resign(ctx context.Context) error
+	// TTL is how long leadership lasts without a campaign
+	TTL() time.Duration
+}
+
+// Scheduler runs jobs periodically. With an elector, only the elected replica runs jobs. With a locker,
+// each run of a job is also skipped unless this replica takes the job lock, which keeps two replicas from
+// running a job during a change of leader.
+type Scheduler struct {
+	locker  Locker
+	elector Elector
+	leader  atomic.Bool
+	jitter  float64
+	jobs    []Job
+}
+
+// New creates a scheduler. The locker may be nil when only one replica runs, and jitter is the share of
+// each interval added at random to the wait before a run.
+func New(locker Locker, jitter float64) *Scheduler {
+	return &Scheduler{locker: locker, jitter: jitter}
+}
+
+// UseLeaderElection makes the scheduler run jobs only while the elector elects this replica.
+// It has to be called before the scheduler is started.
+func (s *Scheduler) UseLeaderElection(elector Elector) {
+	s.elector = elector
+}
+
+// IsLeader reports whether this replica runs the jobs
+func (s *Scheduler) IsLeader() bool {
+	return s.elector == nil || s.leader.Load()
+}
+
+// Add adds a job to the scheduler. Jobs have to be added before the scheduler is started.
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// Jobs returns the names of the scheduled jobs
+func (s *Scheduler) Jobs() []string {
+	names := make([]string, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		names = append(names, job.Name)
+	}
+	return names
+}
+
+// Start runs every job on its own schedule until the context is cancelled, then waits for running jobs
+func (s *Scheduler) Start(ctx context.Context) {
+	var wg sync.WaitGroup
+	if s.elector != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.campaign(ctx)
+		}()
+	}
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+}
+
+// loop waits for the interval of a job plus jitter and runs it, until the context is cancelled
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	timer := time.NewTimer(s.delay(job.Interval))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			if _, err := s.RunOnce(ctx, job); err != nil {
+				log.Printf("Job %s failed: %v", job.Name, err)
+			}
+			timer.Reset(s.delay(job.Interval))
+		}
+	}
+}
+
+// campaign takes part in the leader election three times per leadership TTL until the context is
+// cancelled, then resigns so another replica can take over right away
+func (s *Scheduler) campaign(ctx context.Context) {
+	ticker := time.NewTicker(s.elector.TTL() / 3)
+	defer ticker.Stop()
+
+	for {
+		leader, err := s.elector.Campaign(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Leader election failed: %v", err)
+		}
+		if s.leader.Swap(leader) != leader {
+			if leader {
+				log.Printf("Elected to run background jobs")
+			} else {
+				log.Printf("No longer running background jobs, another server was elected")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			s.leader.Store(false)
+			if err := s.elector.Resign(context.WithoutCancel(ctx)); err != nil {
+				log.Printf("Failed to resign leadership: %v", err)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce runs a job unless another replica leads or holds its lock and reports whether it ran
+func (s *Scheduler) RunOnce(ctx context.Context, job Job) (bool, error) {
+	if !s.IsLeader() {
+		return false, nil
+	}
+	if s.locker != nil {
+		ttl := time.Duration(float64(job.Interval) * lockShare)
+		locked, err := s.locker.TryLock(ctx, job.Name, ttl)
+		if err != nil || !locked {
+			return false, err
+		}
+	}
+	return true, job.Run(ctx)
+}
+
+// delay returns the interval with a random share of up to the jitter added
+func (s *Scheduler) delay(interval time.Duration) time.Duration {
+	if s.jitter <= 0 {
+		return interval
+	}
+	return interval + time.Duration(rand.Float64()*s.jitter*float64(interval))
+}
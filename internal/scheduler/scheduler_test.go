@@ -0,0 +1,162 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// memoryLocker grants each job lock only once
+type memoryLocker struct {
+	mu   sync.Mutex
+	held map[string]time.Duration
+}
+
+func (l *memoryLocker) TryLock(ctx context.Context, job string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.held[job]; ok {
+		return false, nil
+	}
+	l.held[job] = ttl
+	return true, nil
+}
+
+func TestRunOnceTakesLock(t *testing.T) {
+	locker := &memoryLocker{held: make(map[string]time.Duration)}
+	s := New(locker, 0)
+	runs := 0
+	job := Job{Name: "sweep", Interval: time.Minute, Run: func(ctx context.Context) error {
+		runs++
+		return nil
+	}}
+
+	ran, err := s.RunOnce(context.Background(), job)
+	if err != nil || !ran {
+		t.Fatalf("first RunOnce() = %v, %v, expected the job to run", ran, err)
+	}
+	ran, err = s.RunOnce(context.Background(), job)
+	if err != nil || ran {
+		t.Fatalf("second RunOnce() = %v, %v, expected the locked job to be skipped", ran, err)
+	}
+	if runs != 1 {
+		t.Errorf("job ran %d times, expected 1", runs)
+	}
+	if ttl := locker.held["sweep"]; ttl != 54*time.Second {
+		t.Errorf("lock TTL = %v, expected 54s", ttl)
+	}
+}
+
+func TestRunOnceReturnsJobError(t *testing.T) {
+	s := New(nil, 0)
+	failure := errors.New("boom")
+	job := Job{Name: "failing", Interval: time.Minute, Run: func(ctx context.Context) error { return failure }}
+
+	ran, err := s.RunOnce(context.Background(), job)
+	if !ran || !errors.Is(err, failure) {
+		t.Errorf("RunOnce() = %v, %v, expected the job to run and fail", ran, err)
+	}
+}
+
+func TestStartRunsJobsUntilCancelled(t *testing.T) {
+	s := New(nil, DefaultJitter)
+	var runs atomic.Int32
+	s.Add(Job{Name: "tick", Interval: 5 * time.Millisecond, Run: func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Start(ctx)
+		close(done)
+	}()
+
+	deadline := time.After(time.Second)
+	for runs.Load() < 3 {
+		select {
+		case <-deadline:
+			t.Fatalf("job ran %d times within a second, expected at least 3", runs.Load())
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Start did not return after the context was cancelled")
+	}
+}
+
+func TestDelayAddsJitter(t *testing.T) {
+	s := New(nil, 0.5)
+	for i := 0; i < 100; i++ {
+		delay := s.delay(time.Second)
+		if delay < time.Second || delay > 1500*time.Millisecond {
+			t.Fatalf("delay = %v, expected between 1s and 1.5s", delay)
+		}
+	}
+	if delay := New(nil, 0).delay(time.Second); delay != time.Second {
+		t.Errorf("delay without jitter = %v, expected 1s", delay)
+	}
+}
+
+// staticElector elects this replica when leader is set
+type staticElector struct {
+	leader   atomic.Bool
+	resigned atomic.Bool
+}
+
+func (e *staticElector) Campaign(ctx context.Context) (bool, error) { return e.leader.Load(), nil }
+func (e *staticElector) Resign(ctx context.Context) error           { e.resigned.Store(true); return nil }
+func (e *staticElector) TTL() time.Duration                         { return 30 * time.Millisecond }
+
+func TestLeaderElection(t *testing.T) {
+	elector := &staticElector{}
+	s := New(nil, 0)
+	s.UseLeaderElection(elector)
+	var runs atomic.Int32
+	job := Job{Name: "sweep", Interval: time.Minute, Run: func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Start(ctx)
+		close(done)
+	}()
+
+	if ran, _ := s.RunOnce(ctx, job); ran {
+		t.Fatal("job ran before this replica was elected")
+	}
+
+	elector.leader.Store(true)
+	deadline := time.After(time.Second)
+	for !s.IsLeader() {
+		select {
+		case <-deadline:
+			t.Fatal("replica was not elected within a second")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	if ran, _ := s.RunOnce(ctx, job); !ran || runs.Load() != 1 {
+		t.Fatalf("RunOnce() = %v with %d run(s), expected the leader to run the job", ran, runs.Load())
+	}
+
+	cancel()
+	<-done
+	if !elector.resigned.Load() {
+		t.Error("scheduler did not resign when stopped")
+	}
+	if s.IsLeader() {
+		t.Error("scheduler still leads after it was stopped")
+	}
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// RecentlyUpdatedLimit is the number of recently updated items included in an application summary
+const RecentlyUpdatedLimit = 10
+
+// GetApplicationSummary loads all plans of an application with their tasks and aggregates them, along with
+// the stored status of the application
+func (s *PlanStatsService) GetApplicationSummary(
+	ctx context.Context,
+	applicationID string,
+) (*models.ApplicationSummary, error) {
+	plans, err := s.planRepo.ListByApplication(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasksByPlan := make(map[string][]*models.Task, len(plans))
+	for _, plan := range plans {
+		tasks, err := s.taskRepo.ListByPlan(ctx, plan.ID)
+		if err != nil {
+			return nil, err
+		}
+		tasksByPlan[plan.ID] = tasks
+	}
+
+	summary := ComputeApplicationSummary(applicationID, plans, tasksByPlan, time.Now())
+	summary.Status, err = s.planRepo.GetApplicationStatus(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// ComputeApplicationSummary aggregates plans and their tasks into an application summary
+func ComputeApplicationSummary(
+	applicationID string,
+	plans []*models.Plan,
+	tasksByPlan map[string][]*models.Task,
+	now time.Time,
+) *models.ApplicationSummary {
+	summary := &models.ApplicationSummary{
+		ApplicationID: applicationID,
+		TotalPlans:    len(plans),
+		PlanStatusCounts: map[models.PlanStatus]int{
+			models.PlanStatusNew:        0,
+			models.PlanStatusInProgress: 0,
+			models.PlanStatusCompleted:  0,
+			models.PlanStatusCancelled:  0,
+		},
+		TaskStatusCounts: models.NewTaskStatusCounts(),
+		Plans:            make([]*models.PlanProgress, 0, len(plans)),
+		GeneratedAt:      now,
+	}
+
+	var recent []*models.RecentlyUpdatedItem
+	for _, plan := range plans {
+		summary.PlanStatusCounts[plan.Status]++
+
+		tasks := tasksByPlan[plan.ID]
+		progress := ComputePlanProgress(plan, tasks, now)
+		summary.Plans = append(summary.Plans, progress)
+
+		summary.TotalTasks += progress.TotalTasks
+		summary.TotalOpenTasks += progress.EstimatedRemainingWork
+		for status, count := range progress.StatusCounts {
+			summary.TaskStatusCounts[status] += count
+		}
+
+		recent = append(recent, &models.RecentlyUpdatedItem{
+			Type:      "plan",
+			ID:        plan.ID,
+			PlanID:    plan.ID,
+			Title:     plan.Name,
+			Status:    string(plan.Status),
+			UpdatedAt: plan.UpdatedAt,
+		})
+		for _, task := range tasks {
+			recent = append(recent, &models.RecentlyUpdatedItem{
+				Type:      "task",
+				ID:        task.ID,
+				PlanID:    task.PlanID,
+				Title:     task.Title,
+				Status:    string(task.Status),
+				UpdatedAt: task.UpdatedAt,
+			})
+		}
+	}
+
+	// Keep plans in a stable order for readers
+	sort.Slice(summary.Plans, func(i, j int) bool {
+		return summary.Plans[i].PlanName < summary.Plans[j].PlanName
+	})
+
+	// Most recent changes first
+	sort.SliceStable(recent, func(i, j int) bool {
+		return recent[i].UpdatedAt.After(recent[j].UpdatedAt)
+	})
+	if len(recent) > RecentlyUpdatedLimit {
+		recent = recent[:RecentlyUpdatedLimit]
+	}
+	summary.RecentlyUpdated = recent
+
+	return summary
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// AttachmentService attaches small artifacts such as diffs, log excerpts and JSON documents to tasks
+type AttachmentService struct {
+	planRepo storage.PlanRepositoryInterface
+	taskRepo storage.TaskRepositoryInterface
+	store    storage.AttachmentStore
+}
+
+// NewAttachmentService creates a new attachment service.
+// The task repository should be the scoped one, so that attachments of hidden tasks cannot be read.
+func NewAttachmentService(
+	planRepo storage.PlanRepositoryInterface,
+	taskRepo storage.TaskRepositoryInterface,
+	store storage.AttachmentStore,
+) *AttachmentService {
+	return &AttachmentService{
+		planRepo: planRepo,
+		taskRepo: taskRepo,
+		store:    store,
+	}
+}
+
+// Add attaches an artifact to a task and returns the attachment without its content. Tasks of locked plans
+// only take attachments from contexts that override plan locks.
+func (s *AttachmentService) Add(
+	ctx context.Context,
+	taskID, name, contentType, content string,
+) (*models.Attachment, error) {
+	task, err := s.taskRepo.Get(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.planRepo.Get(ctx, task.PlanID)
+	if err != nil && !models.IsNotFound(err) {
+		return nil, err
+	}
+	if plan != nil {
+		if err := storage.CheckUnlocked(ctx, plan); err != nil {
+			return nil, err
+		}
+	}
+
+	attachment := models.NewAttachment("", taskID, name, contentType, content)
+	if err := attachment.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.store.Add(ctx, attachment); err != nil {
+		return nil, err
+	}
+
+	attachment.Content = ""
+	return attachment, nil
+}
+
+// List returns the attachments of a task without their content, oldest first
+func (s *AttachmentService) List(ctx context.Context, taskID string) ([]*models.Attachment, error) {
+	if _, err := s.taskRepo.Get(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return s.store.List(ctx, taskID)
+}
+
+// Get returns an attachment of a task with its content
+func (s *AttachmentService) Get(ctx context.Context, taskID, id string) (*models.Attachment, error) {
+	if _, err := s.taskRepo.Get(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return s.store.Get(ctx, taskID, id)
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+func newAttachmentTest(
+	t *testing.T,
+	limits storage.AttachmentLimits,
+) (*AttachmentService, storage.PlanRepositoryInterface, *models.Task) {
+	t.Helper()
+	repos := newTestRepos(t)
+	planRepo, taskRepo := repos.planRepo, repos.taskRepo
+	ctx := context.Background()
+	plan, err := planRepo.Create(ctx, "app", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	task, err := taskRepo.Create(ctx, plan.ID, "Task", "", models.TaskPriorityMedium)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	store := storage.NewLimitedAttachmentStore(storage.NewValkeyAttachmentStore(repos.client), limits)
+	return NewAttachmentService(planRepo, taskRepo, store), planRepo, task
+}
+
+func TestAttachments(t *testing.T) {
+	service, _, task := newAttachmentTest(t, storage.DefaultAttachmentLimits())
+	ctx := context.Background()
+
+	diff, err := service.Add(ctx, task.ID, "fix.diff", "text/x-diff", "-old\n+new\n")
+	if err != nil {
+		t.Fatalf("failed to add attachment: %v", err)
+	}
+	if diff.ID == "" || diff.Size != 10 || diff.Content != "" {
+		t.Errorf("expected an ID, the size and no content, got %+v", diff)
+	}
+	if _, err := service.Add(ctx, task.ID, "result.json", "application/json", `{"ok":true}`); err != nil {
+		t.Fatalf("failed to add attachment: %v", err)
+	}
+
+	attachments, err := service.List(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("failed to list attachments: %v", err)
+	}
+	if len(attachments) != 2 || attachments[0].Name != "fix.diff" || attachments[1].Name != "result.json" {
+		t.Fatalf("expected both attachments oldest first, got %+v", attachments)
+	}
+	if attachments[0].Content != "" {
+		t.Errorf("expected listed attachments without content, got %q", attachments[0].Content)
+	}
+
+	got, err := service.Get(ctx, task.ID, diff.ID)
+	if err != nil {
+		t.Fatalf("failed to get attachment: %v", err)
+	}
+	if got.Content != "-old\n+new\n" || got.ContentType != "text/x-diff" {
+		t.Errorf("expected the diff with its content, got %+v", got)
+	}
+
+	if _, err := service.Get(ctx, task.ID, "missing"); !models.IsNotFound(err) {
+		t.Errorf("expected a not found error for a missing attachment, got %v", err)
+	}
+	if _, err := service.List(ctx, "missing"); !models.IsNotFound(err) {
+		t.Errorf("expected a not found error for a missing task, got %v", err)
+	}
+}
+
+func TestAttachmentLimits(t *testing.T) {
+	service, _, task := newAttachmentTest(t, storage.AttachmentLimits{MaxSize: 5, MaxPerTask: 1})
+	ctx := context.Background()
+
+	if _, err := service.Add(ctx, task.ID, "log.txt", "text/plain", "too long"); !errors.Is(err, storage.ErrLimitExceeded) {
+		t.Errorf("expected the size limit to be exceeded, got %v", err)
+	}
+	_, err := service.Add(ctx, task.ID, "image.png", "image/png", "png")
+	if models.ErrorCodeOf(err) != models.ErrorCodeValidation {
+		t.Errorf("expected a validation error for a binary type, got %v", err)
+	}
+	if _, err := service.Add(ctx, task.ID, "log.txt", "text/plain", "ok"); err != nil {
+		t.Fatalf("failed to add attachment: %v", err)
+	}
+	if _, err := service.Add(ctx, task.ID, "log2.txt", "text/plain", "ok"); !errors.Is(err, storage.ErrLimitExceeded) {
+		t.Errorf("expected the attachment count limit to be exceeded, got %v", err)
+	}
+}
+
+func TestAttachmentsOfLockedPlan(t *testing.T) {
+	service, planRepo, task := newAttachmentTest(t, storage.DefaultAttachmentLimits())
+	ctx := context.Background()
+
+	lock := &models.PlanLock{LockedBy: "reviewer", LockedAt: time.Now()}
+	if _, err := planRepo.SetLock(ctx, task.PlanID, lock); err != nil {
+		t.Fatalf("failed to lock plan: %v", err)
+	}
+
+	_, err := service.Add(ctx, task.ID, "notes.txt", "text/plain", "late change")
+	if models.ErrorCodeOf(err) != models.ErrorCodeConflict {
+		t.Fatalf("expected a conflict adding an attachment to a task of a locked plan, got %v", err)
+	}
+	attachments, err := service.List(ctx, task.ID)
+	if err != nil || len(attachments) != 0 {
+		t.Errorf("expected no attachments, got %+v, %v", attachments, err)
+	}
+
+	// Admins may still change locked plans
+	if _, err := service.Add(storage.WithPlanLockOverride(ctx), task.ID, "notes.txt", "text/plain", "fix"); err != nil {
+		t.Errorf("failed to add an attachment overriding the lock: %v", err)
+	}
+}
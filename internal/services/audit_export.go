@@ -0,0 +1,505 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// The audit exporter ships the changes recorded in the audit log to a SIEM or log pipeline, one line per change
+// in JSON or CEF. Changes are queued and written in batches in the background, so a slow sink does not hold up
+// the change. A sink that fails is retried with backoff while the queue fills up; once it is full, changes wait
+// up to BlockTimeout for room and are dropped after that, with a warning.
+//
+// Exported lines carry the changed fields with their values, but not the full snapshots of the audit log.
+
+// Defaults of the audit export
+const (
+	DefaultAuditExportBatchSize     = 100
+	DefaultAuditExportFlushInterval = time.Second
+	DefaultAuditExportQueueSize     = 10000
+	DefaultAuditExportBlockTimeout  = 100 * time.Millisecond
+)
+
+// auditExportMaxBackoff bounds the wait between retries of a failed batch
+const auditExportMaxBackoff = 30 * time.Second
+
+// AuditFormat is the format audit entries are exported in
+type AuditFormat string
+
+// Audit export formats
+const (
+	// AuditFormatJSON writes a JSON object per line
+	AuditFormatJSON AuditFormat = "json"
+	// AuditFormatCEF writes ArcSight Common Event Format lines
+	AuditFormatCEF AuditFormat = "cef"
+)
+
+// ParseAuditFormat parses the name of an audit export format
+func ParseAuditFormat(name string) (AuditFormat, error) {
+	switch format := AuditFormat(strings.ToLower(strings.TrimSpace(name))); format {
+	case AuditFormatJSON, AuditFormatCEF:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown audit export format %q, expected json or cef", name)
+	}
+}
+
+// AuditSink receives batches of exported audit entries, one line each without a line break
+type AuditSink interface {
+	Write(ctx context.Context, lines [][]byte) error
+}
+
+// AuditExportConfig configures how audit entries are exported
+type AuditExportConfig struct {
+	// Format is the format of the exported lines
+	Format AuditFormat
+	// BatchSize is the most entries written at once
+	BatchSize int
+	// FlushInterval is the longest an entry waits for its batch to fill up
+	FlushInterval time.Duration
+	// QueueSize is the most entries waiting to be written
+	QueueSize int
+	// BlockTimeout is how long a change waits for room in a full queue before its entry is dropped
+	BlockTimeout time.Duration
+}
+
+// DefaultAuditExportConfig returns the default configuration, exporting JSON lines
+func DefaultAuditExportConfig() AuditExportConfig {
+	return AuditExportConfig{
+		Format:        AuditFormatJSON,
+		BatchSize:     DefaultAuditExportBatchSize,
+		FlushInterval: DefaultAuditExportFlushInterval,
+		QueueSize:     DefaultAuditExportQueueSize,
+		BlockTimeout:  DefaultAuditExportBlockTimeout,
+	}
+}
+
+// AuditExporter exports the changes recorded in the audit log to a sink
+type AuditExporter struct {
+	sink   AuditSink
+	config AuditExportConfig
+
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan *models.AuditEntry
+	done    chan struct{}
+	dropped atomic.Int64
+
+	// retryCtx is cancelled when Close gives up, so a failing sink is not retried any longer
+	retryCtx    context.Context
+	cancelRetry context.CancelFunc
+}
+
+// NewAuditExporter creates an exporter writing to a sink and starts it. Settings that are not positive
+// use the defaults, except BlockTimeout, where 0 drops entries as soon as the queue is full.
+func NewAuditExporter(sink AuditSink, config AuditExportConfig) *AuditExporter {
+	defaults := DefaultAuditExportConfig()
+	if config.Format == "" {
+		config.Format = defaults.Format
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaults.FlushInterval
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaults.QueueSize
+	}
+
+	retryCtx, cancelRetry := context.WithCancel(context.Background())
+	e := &AuditExporter{
+		sink:        sink,
+		config:      config,
+		queue:       make(chan *models.AuditEntry, config.QueueSize),
+		done:        make(chan struct{}),
+		retryCtx:    retryCtx,
+		cancelRetry: cancelRetry,
+	}
+	go e.run()
+	return e
+}
+
+// Changed queues a change for export, waiting up to BlockTimeout for room in a full queue
+func (e *AuditExporter) Changed(ctx context.Context, entry *models.AuditEntry) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		return
+	}
+
+	select {
+	case e.queue <- entry:
+		return
+	default:
+	}
+	if e.config.BlockTimeout > 0 {
+		timer := time.NewTimer(e.config.BlockTimeout)
+		defer timer.Stop()
+		select {
+		case e.queue <- entry:
+			return
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+
+	// Warn on the first drop and every thousandth after it, so a stuck sink does not flood the log
+	if dropped := e.dropped.Add(1); dropped == 1 || dropped%1000 == 0 {
+		log.Printf("Warning: audit export queue is full, %d change(s) dropped", dropped)
+	}
+}
+
+// Dropped returns the number of changes dropped because the queue was full
+func (e *AuditExporter) Dropped() int64 {
+	return e.dropped.Load()
+}
+
+// Close stops queueing changes and writes the queued ones until the context is done, then closes the sink if
+// it can be closed
+func (e *AuditExporter) Close(ctx context.Context) error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.queue)
+	}
+	e.mu.Unlock()
+
+	var err error
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		e.cancelRetry()
+		<-e.done
+		err = ctx.Err()
+	}
+	e.cancelRetry()
+
+	if closer, ok := e.sink.(io.Closer); ok {
+		if closeErr := closer.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// run writes the queued entries in batches until the queue is closed
+func (e *AuditExporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*models.AuditEntry, 0, e.config.BatchSize)
+	for {
+		select {
+		case entry, ok := <-e.queue:
+			if !ok {
+				e.write(batch)
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= e.config.BatchSize {
+				e.write(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				e.write(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// write formats a batch and writes it to the sink, retrying with backoff until it succeeds or the exporter
+// gives up on closing
+func (e *AuditExporter) write(batch []*models.AuditEntry) {
+	if len(batch) == 0 {
+		return
+	}
+	lines := make([][]byte, 0, len(batch))
+	for _, entry := range batch {
+		line, err := FormatAuditEntry(e.config.Format, entry)
+		if err != nil {
+			log.Printf("Warning: failed to export audit entry %s: %v", entry.ID, err)
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	backoff := time.Second
+	for {
+		err := e.sink.Write(e.retryCtx, lines)
+		if err == nil {
+			return
+		}
+		log.Printf("Warning: failed to export %d audit entries, retrying in %s: %v", len(lines), backoff, err)
+
+		select {
+		case <-time.After(backoff):
+		case <-e.retryCtx.Done():
+			log.Printf("Warning: %d audit entries were not exported before the server stopped", len(lines))
+			return
+		}
+		backoff = min(backoff*2, auditExportMaxBackoff)
+	}
+}
+
+// auditRecord is the JSON form of an exported audit entry
+type auditRecord struct {
+	ID         string                        `json:"id"`
+	Timestamp  time.Time                     `json:"timestamp"`
+	Source     string                        `json:"source"`
+	EntityType models.EntityType             `json:"entity_type"`
+	EntityID   string                        `json:"entity_id"`
+	Action     models.AuditAction            `json:"action"`
+	Operation  string                        `json:"operation"`
+	Actor      string                        `json:"actor"`
+	Changes    map[string]models.FieldChange `json:"changes,omitempty"`
+}
+
+// auditSource names the server in exported entries
+const auditSource = "valkey-ai-tasks"
+
+// FormatAuditEntry formats an audit entry as a line in the given format
+func FormatAuditEntry(format AuditFormat, entry *models.AuditEntry) ([]byte, error) {
+	switch format {
+	case AuditFormatCEF:
+		return formatCEF(entry), nil
+	case AuditFormatJSON, "":
+		return json.Marshal(auditRecord{
+			ID:         entry.ID,
+			Timestamp:  entry.Timestamp,
+			Source:     auditSource,
+			EntityType: entry.EntityType,
+			EntityID:   entry.EntityID,
+			Action:     entry.Action,
+			Operation:  entry.Operation,
+			Actor:      entry.Actor,
+			Changes:    entry.Changes,
+		})
+	default:
+		return nil, fmt.Errorf("unknown audit export format %q", format)
+	}
+}
+
+// formatCEF formats an audit entry as a CEF line. Deletions have a higher severity than other changes.
+func formatCEF(entry *models.AuditEntry) []byte {
+	severity := 3
+	if entry.Action == models.AuditActionDelete {
+		severity = 6
+	}
+	actor := entry.Actor
+	if actor == "" {
+		actor = "unknown"
+	}
+
+	header := []string{
+		"CEF:0",
+		cefHeader("valkey-ai-tasks"),
+		cefHeader("valkey-ai-tasks"),
+		cefHeader("1.0.0"),
+		cefHeader(string(entry.EntityType) + "." + string(entry.Action)),
+		cefHeader(entry.Operation),
+		strconv.Itoa(severity),
+	}
+	extension := []string{
+		"rt=" + strconv.FormatInt(entry.Timestamp.UnixMilli(), 10),
+		"externalId=" + cefValue(entry.ID),
+		"act=" + cefValue(entry.Operation),
+		"suser=" + cefValue(actor),
+		"cs1Label=entityType",
+		"cs1=" + cefValue(string(entry.EntityType)),
+		"cs2Label=entityId",
+		"cs2=" + cefValue(entry.EntityID),
+	}
+	if len(entry.Changes) > 0 {
+		extension = append(extension,
+			"cs3Label=changedFields",
+			"cs3="+cefValue(strings.Join(slices.Sorted(maps.Keys(entry.Changes)), ",")))
+	}
+	return []byte(strings.Join(header, "|") + "|" + strings.Join(extension, " "))
+}
+
+// cefHeader escapes a CEF header field
+func cefHeader(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "|", `\|`, "\n", " ", "\r", " ").Replace(value)
+}
+
+// cefValue escapes a CEF extension value
+func cefValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "=", `\=`, "\n", `\n`, "\r", `\r`).Replace(value)
+}
+
+// FileSink appends exported lines to a file, such as one collected by a log shipper
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens a file to append exported lines to, creating it if needed
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit export file: %w", err)
+	}
+	return &FileSink{file: file}, nil
+}
+
+// Write appends a batch of lines to the file
+func (s *FileSink) Write(_ context.Context, lines [][]byte) error {
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.file.Write(buf.Bytes())
+	return err
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// SyslogSink sends exported lines to a syslog server as RFC 5424 messages, over UDP or over TCP with octet
+// counting framing
+type SyslogSink struct {
+	network  string
+	address  string
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// syslogPriority is the priority of exported messages: facility local0, severity notice
+const syslogPriority = 16*8 + 5
+
+// NewSyslogSink creates a sink sending to a syslog server at an address such as udp://siem:514 or
+// tcp://siem:601. Addresses without a scheme use UDP.
+func NewSyslogSink(address string) (*SyslogSink, error) {
+	network := "udp"
+	if scheme, rest, ok := strings.Cut(address, "://"); ok {
+		network, address = scheme, rest
+	}
+	if network != "udp" && network != "tcp" {
+		return nil, fmt.Errorf("unsupported syslog network %q, expected udp or tcp", network)
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return nil, fmt.Errorf("invalid syslog address %q: %w", address, err)
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "-"
+	}
+	return &SyslogSink{network: network, address: address, hostname: hostname}, nil
+}
+
+// Write sends a batch of lines, one message each, connecting again after a failure
+func (s *SyslogSink) Write(ctx context.Context, lines [][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, s.network, s.address)
+		if err != nil {
+			return fmt.Errorf("failed to connect to syslog: %w", err)
+		}
+		s.conn = conn
+	}
+
+	for _, line := range lines {
+		message := fmt.Sprintf("<%d>1 %s %s %s %d - - %s",
+			syslogPriority, time.Now().UTC().Format(time.RFC3339Nano), s.hostname, auditSource, os.Getpid(), line)
+		if s.network == "tcp" {
+			message = strconv.Itoa(len(message)) + " " + message
+		}
+		if _, err := io.WriteString(s.conn, message); err != nil {
+			s.conn.Close() //nolint:errcheck
+			s.conn = nil
+			return fmt.Errorf("failed to send to syslog: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the connection to the syslog server
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// HTTPSink posts each batch of exported lines to an HTTP endpoint, separated by line breaks, such as to the
+// HTTP collector of a SIEM
+type HTTPSink struct {
+	url   string
+	token string
+	http  *http.Client
+}
+
+// NewHTTPSink creates a sink posting to a URL, with the token as bearer token when it is not empty
+func NewHTTPSink(endpoint, token string) (*HTTPSink, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid audit export URL %q", endpoint)
+	}
+	return &HTTPSink{url: endpoint, token: token, http: &http.Client{Timeout: notificationTimeout}}, nil
+}
+
+// Write posts a batch of lines
+func (s *HTTPSink) Write(ctx context.Context, lines [][]byte) error {
+	body := bytes.Join(lines, []byte("\n"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(append(body, '\n')))
+	if err != nil {
+		return fmt.Errorf("failed to create audit export request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("audit export request failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("audit export request failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+var (
+	_ storage.ChangeListener = (*AuditExporter)(nil)
+	_ AuditSink              = (*FileSink)(nil)
+	_ AuditSink              = (*SyslogSink)(nil)
+	_ AuditSink              = (*HTTPSink)(nil)
+)
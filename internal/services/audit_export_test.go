@@ -0,0 +1,221 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// blockingSink holds every write until it is released, keeping the lines written
+type blockingSink struct {
+	release chan struct{}
+	mu      sync.Mutex
+	lines   [][]byte
+}
+
+func (b *blockingSink) Write(ctx context.Context, lines [][]byte) error {
+	select {
+	case <-b.release:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lines = append(b.lines, lines...)
+	return nil
+}
+
+func TestAuditExporter(t *testing.T) {
+	repos := newTestRepos(t)
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("failed to open the export file: %v", err)
+	}
+	exporter := NewAuditExporter(sink, AuditExportConfig{Format: AuditFormatJSON, BatchSize: 2})
+	repos.auditLog.SetChangeListener(exporter)
+	planRepo, taskRepo := repos.audited()
+
+	ctx := storage.WithActor(context.Background(), "alice")
+	plan, err := planRepo.Create(ctx, "app", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	task, err := taskRepo.Create(ctx, plan.ID, "Task", "", models.TaskPriorityMedium)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if err := taskRepo.Delete(ctx, task.ID); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	// Close writes the last batch, which is not full
+	if err := exporter.Close(context.Background()); err != nil {
+		t.Fatalf("failed to close the exporter: %v", err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to read the export file: %v", err)
+	}
+	defer file.Close()
+
+	var records []auditRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record auditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("failed to parse line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 exported changes, got %d", len(records))
+	}
+	last := records[2]
+	if last.EntityType != models.EntityTypeTask || last.EntityID != task.ID || last.Action != models.AuditActionDelete ||
+		last.Actor != "alice" || last.ID == "" || last.Source != auditSource {
+		t.Errorf("unexpected record %+v", last)
+	}
+
+	// Changes after closing are ignored
+	exporter.Changed(ctx, &models.AuditEntry{ID: "late"})
+}
+
+func TestAuditExporterBackpressure(t *testing.T) {
+	sink := &blockingSink{release: make(chan struct{})}
+	exporter := NewAuditExporter(sink, AuditExportConfig{BatchSize: 1, QueueSize: 1})
+
+	for i := range 5 {
+		exporter.Changed(context.Background(), &models.AuditEntry{ID: string(rune('a' + i))})
+	}
+	if dropped := exporter.Dropped(); dropped < 3 {
+		t.Errorf("expected at least 3 changes dropped by a full queue, got %d", dropped)
+	}
+
+	close(sink.release)
+	if err := exporter.Close(context.Background()); err != nil {
+		t.Fatalf("failed to close the exporter: %v", err)
+	}
+	if written := int64(len(sink.lines)); written+exporter.Dropped() != 5 {
+		t.Errorf("expected every change written or dropped, got %d written and %d dropped", written, exporter.Dropped())
+	}
+
+	// Close gives up on a sink that does not recover
+	stuck := NewAuditExporter(&blockingSink{release: make(chan struct{})}, AuditExportConfig{BatchSize: 1})
+	stuck.Changed(context.Background(), &models.AuditEntry{ID: "stuck"})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := stuck.Close(ctx); err == nil {
+		t.Error("expected an error closing with a stuck sink")
+	}
+}
+
+func TestFormatCEF(t *testing.T) {
+	entry := &models.AuditEntry{
+		ID:         "1-0",
+		EntityType: models.EntityTypeTask,
+		EntityID:   "task-1",
+		Action:     models.AuditActionDelete,
+		Operation:  "delete|task",
+		Actor:      `bob=admin\ops` + "\nx",
+		Timestamp:  time.UnixMilli(1700000000000),
+		Changes:    map[string]models.FieldChange{"title": {}, "notes": {}},
+	}
+	line, err := FormatAuditEntry(AuditFormatCEF, entry)
+	if err != nil {
+		t.Fatalf("failed to format: %v", err)
+	}
+
+	expected := `CEF:0|valkey-ai-tasks|valkey-ai-tasks|1.0.0|task.delete|delete\|task|6|rt=1700000000000 ` +
+		`externalId=1-0 act=delete|task suser=bob\=admin\\ops\nx cs1Label=entityType cs1=task ` +
+		`cs2Label=entityId cs2=task-1 cs3Label=changedFields cs3=notes,title`
+	if string(line) != expected {
+		t.Errorf("got  %s\nwant %s", line, expected)
+	}
+
+	if _, err := ParseAuditFormat("xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+	if format, err := ParseAuditFormat(" CEF "); err != nil || format != AuditFormatCEF {
+		t.Errorf("ParseAuditFormat(CEF) = %q, %v", format, err)
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	var body, auth string
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body, auth = string(data), r.Header.Get("Authorization")
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	sink, err := NewHTTPSink(server.URL, "secret")
+	if err != nil {
+		t.Fatalf("failed to create the sink: %v", err)
+	}
+	if err := sink.Write(context.Background(), [][]byte{[]byte(`{"a":1}`), []byte(`{"b":2}`)}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if body != "{\"a\":1}\n{\"b\":2}\n" || auth != "Bearer secret" {
+		t.Errorf("got body %q with authorization %q", body, auth)
+	}
+
+	fail = true
+	if err := sink.Write(context.Background(), [][]byte{[]byte(`{}`)}); err == nil {
+		t.Error("expected an error for a failing endpoint")
+	}
+	if _, err := NewHTTPSink("ftp://siem", ""); err == nil {
+		t.Error("expected an error for a URL that is not HTTP")
+	}
+}
+
+func TestSyslogSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer conn.Close()
+
+	sink, err := NewSyslogSink("udp://" + conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("failed to create the sink: %v", err)
+	}
+	defer sink.Close()
+	if err := sink.Write(context.Background(), [][]byte{[]byte("CEF:0|test")}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second)) //nolint:errcheck
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("failed to read the message: %v", err)
+	}
+	message := string(buf[:n])
+	if !strings.HasPrefix(message, "<133>1 ") || !strings.Contains(message, " valkey-ai-tasks ") ||
+		!strings.HasSuffix(message, " - - CEF:0|test") {
+		t.Errorf("unexpected syslog message %q", message)
+	}
+
+	if _, err := NewSyslogSink("unix:///dev/log"); err == nil {
+		t.Error("expected an error for an unsupported network")
+	}
+}
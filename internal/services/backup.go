@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// BackupService exports plans with their tasks and restores them from such exports
+type BackupService struct {
+	planRepo storage.PlanRepositoryInterface
+	taskRepo storage.TaskRepositoryInterface
+}
+
+// NewBackupService creates a new backup service
+func NewBackupService(
+	planRepo storage.PlanRepositoryInterface,
+	taskRepo storage.TaskRepositoryInterface,
+) *BackupService {
+	return &BackupService{
+		planRepo: planRepo,
+		taskRepo: taskRepo,
+	}
+}
+
+// ExportPlan returns a plan and its tasks in the same format as the plan resource
+func (s *BackupService) ExportPlan(ctx context.Context, planID string) (*models.PlanResource, error) {
+	plan, err := s.planRepo.Get(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.taskRepo.ListByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.NewPlanResource(plan, tasks), nil
+}
+
+// ImportPlan restores an exported plan and its tasks with their original IDs.
+// Existing versions are overwritten; tasks added to the plan after the export are left in place.
+func (s *BackupService) ImportPlan(ctx context.Context, backup *models.PlanResource) (*models.PlanResource, error) {
+	if err := ValidatePlanBackup(backup); err != nil {
+		return nil, err
+	}
+
+	err := s.planRepo.Restore(ctx, backup.Plan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore plan %s: %w", backup.Plan.ID, err)
+	}
+
+	for _, task := range backup.Tasks {
+		err = s.taskRepo.Restore(ctx, task)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore task %s: %w", task.ID, err)
+		}
+	}
+
+	return s.ExportPlan(ctx, backup.Plan.ID)
+}
+
+// BackupRun is the result of backing up every plan
+type BackupRun struct {
+	Directory string `json:"directory"`
+	// Files are the backup files written, one per plan
+	Files []string `json:"files"`
+	Tasks int      `json:"tasks"`
+	// Failed lists the IDs of the plans that could not be backed up
+	Failed     []string  `json:"failed"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// BackupAll writes a backup of every plan to a file named after the plan in the given directory, replacing
+// earlier backups of the same plan. A plan that fails to back up is reported and the run continues with the
+// next plan.
+func (s *BackupService) BackupAll(ctx context.Context, dir string) (*BackupRun, error) {
+	run := &BackupRun{Directory: dir, Files: []string{}, Failed: []string{}, StartedAt: time.Now()}
+
+	plans, err := s.planRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plans: %w", err)
+	}
+
+	for _, plan := range plans {
+		backup, err := s.ExportPlan(ctx, plan.ID)
+		if err == nil {
+			var path string
+			if path, err = WritePlanBackup(dir, backup); err == nil {
+				run.Files = append(run.Files, path)
+				run.Tasks += len(backup.Tasks)
+				continue
+			}
+		}
+		log.Printf("Failed to back up plan %s: %v", plan.ID, err)
+		run.Failed = append(run.Failed, plan.ID)
+	}
+
+	run.FinishedAt = time.Now()
+	return run, nil
+}
+
+// WritePlanBackup writes a plan backup to a file named after the plan in the given directory, in the format
+// the import command reads, and returns its path
+func WritePlanBackup(dir string, backup *models.PlanResource) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal plan backup: %w", err)
+	}
+
+	path := filepath.Join(dir, backup.Plan.ID+".json")
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// ValidatePlanBackup checks that a backup names a plan and that every task belongs to it
+func ValidatePlanBackup(backup *models.PlanResource) error {
+	if backup == nil || backup.Plan == nil {
+		return fmt.Errorf("backup does not contain a plan")
+	}
+	if backup.Plan.ID == "" {
+		return fmt.Errorf("backup plan has no ID")
+	}
+	if backup.Plan.ApplicationID == "" {
+		return fmt.Errorf("backup plan %s has no application ID", backup.Plan.ID)
+	}
+
+	seen := make(map[string]bool, len(backup.Tasks))
+	for i, task := range backup.Tasks {
+		if task == nil || task.ID == "" {
+			return fmt.Errorf("backup task %d has no ID", i)
+		}
+		if task.PlanID != backup.Plan.ID {
+			return fmt.Errorf("backup task %s belongs to plan %s, not %s", task.ID, task.PlanID, backup.Plan.ID)
+		}
+		if seen[task.ID] {
+			return fmt.Errorf("backup contains task %s more than once", task.ID)
+		}
+		seen[task.ID] = true
+	}
+
+	return nil
+}
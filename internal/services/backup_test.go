@@ -0,0 +1,46 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+func TestValidatePlanBackup(t *testing.T) {
+	plan := models.NewPlan("plan-1", "app-1", "Plan", "Test plan")
+	task := func(id, planID string) *models.Task {
+		return models.NewTask(id, planID, "Task "+id, "", models.TaskPriorityMedium)
+	}
+
+	tests := []struct {
+		name    string
+		backup  *models.PlanResource
+		wantErr string
+	}{
+		{"valid", models.NewPlanResource(plan, []*models.Task{task("t1", plan.ID), task("t2", plan.ID)}), ""},
+		{"valid without tasks", models.NewPlanResource(plan, nil), ""},
+		{"nil backup", nil, "does not contain a plan"},
+		{"missing plan", models.NewPlanResource(nil, nil), "does not contain a plan"},
+		{"plan without ID", models.NewPlanResource(models.NewPlan("", "app-1", "Plan", ""), nil), "has no ID"},
+		{"plan without application", models.NewPlanResource(models.NewPlan("plan-1", "", "Plan", ""), nil), "no application ID"},
+		{"task without ID", models.NewPlanResource(plan, []*models.Task{task("", plan.ID)}), "task 0 has no ID"},
+		{"task of another plan", models.NewPlanResource(plan, []*models.Task{task("t1", "plan-2")}), "belongs to plan plan-2"},
+		{"duplicate task", models.NewPlanResource(plan, []*models.Task{task("t1", plan.ID), task("t1", plan.ID)}), "more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePlanBackup(tt.backup)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// GetPlanCapacityReport loads a plan and its tasks and compares their estimated work with the given capacity,
+// which may be nil
+func (s *PlanStatsService) GetPlanCapacityReport(
+	ctx context.Context,
+	planID string,
+	capacity *float64,
+) (*models.PlanCapacityReport, error) {
+	plan, err := s.planRepo.Get(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.taskRepo.ListByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	return ComputePlanCapacityReport(plan, tasks, capacity, time.Now()), nil
+}
+
+// ComputePlanCapacityReport aggregates the estimated and completed work of a plan at the given time. When the
+// remaining work exceeds the capacity, pending tasks are suggested for deferral, lowest priority and last in the
+// plan first, until the remaining work fits. Tasks in progress are never suggested.
+func ComputePlanCapacityReport(
+	plan *models.Plan,
+	tasks []*models.Task,
+	capacity *float64,
+	now time.Time,
+) *models.PlanCapacityReport {
+	report := &models.PlanCapacityReport{
+		PlanID:     plan.ID,
+		PlanName:   plan.Name,
+		Capacity:   capacity,
+		ComputedAt: now,
+	}
+
+	var pending []*models.Task
+	for _, task := range tasks {
+		if task.Status == models.TaskStatusCancelled {
+			continue
+		}
+		if task.Estimate > 0 {
+			report.EstimatedTasks++
+			report.TotalEstimate += task.Estimate
+		}
+
+		switch {
+		case task.Status == models.TaskStatusCompleted:
+			report.CompletedEstimate += task.Estimate
+		case task.Estimate == 0:
+			report.UnestimatedOpenTasks++
+		default:
+			report.RemainingEstimate += task.Estimate
+			if task.Status == models.TaskStatusPending {
+				pending = append(pending, task)
+			}
+		}
+	}
+
+	if report.TotalEstimate > 0 {
+		report.PercentComplete = math.Round(report.CompletedEstimate/report.TotalEstimate*1000) / 10
+	}
+
+	if capacity == nil || report.RemainingEstimate <= *capacity {
+		return report
+	}
+	report.OverCapacity = true
+	report.Excess = report.RemainingEstimate - *capacity
+
+	// Tasks are listed in plan order; reversed, the stable sort puts the last tasks of each priority first
+	slices.Reverse(pending)
+	slices.SortStableFunc(pending, func(a, b *models.Task) int {
+		return models.PriorityRank(a.Priority) - models.PriorityRank(b.Priority)
+	})
+
+	deferred := 0.0
+	for _, task := range pending {
+		if deferred >= report.Excess {
+			break
+		}
+		deferred += task.Estimate
+		report.DeferCandidates = append(report.DeferCandidates, &models.CapacityTaskEntry{
+			TaskID:   task.ID,
+			Title:    task.Title,
+			Status:   task.Status,
+			Priority: task.Priority,
+			Estimate: task.Estimate,
+		})
+	}
+
+	return report
+}
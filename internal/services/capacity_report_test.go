@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+func TestComputePlanCapacityReport(t *testing.T) {
+	now := time.Date(2025, time.July, 1, 12, 0, 0, 0, time.UTC)
+	plan := models.NewPlan("plan-1", "app-1", "Plan", "Test plan")
+
+	newTask := func(id string, status models.TaskStatus, priority models.TaskPriority, estimate float64) *models.Task {
+		task := models.NewTask(id, plan.ID, id, "", priority)
+		task.Status = status
+		task.Estimate = estimate
+		return task
+	}
+
+	tasks := []*models.Task{
+		newTask("done", models.TaskStatusCompleted, models.TaskPriorityHigh, 5),
+		newTask("doing", models.TaskStatusInProgress, models.TaskPriorityLow, 3),
+		newTask("low-first", models.TaskStatusPending, models.TaskPriorityLow, 2),
+		newTask("must", models.TaskStatusPending, models.TaskPriorityHigh, 8),
+		newTask("low-last", models.TaskStatusPending, models.TaskPriorityLow, 2),
+		newTask("unknown", models.TaskStatusPending, models.TaskPriorityMedium, 0),
+		newTask("dropped", models.TaskStatusCancelled, models.TaskPriorityMedium, 13),
+	}
+
+	report := ComputePlanCapacityReport(plan, tasks, nil, now)
+
+	if report.TotalEstimate != 20 || report.CompletedEstimate != 5 || report.RemainingEstimate != 15 {
+		t.Errorf("estimates = %v total, %v completed, %v remaining, expected 20, 5 and 15",
+			report.TotalEstimate, report.CompletedEstimate, report.RemainingEstimate)
+	}
+	if report.PercentComplete != 25 {
+		t.Errorf("PercentComplete = %v, expected 25", report.PercentComplete)
+	}
+	if report.EstimatedTasks != 5 || report.UnestimatedOpenTasks != 1 {
+		t.Errorf("EstimatedTasks = %d, UnestimatedOpenTasks = %d, expected 5 and 1",
+			report.EstimatedTasks, report.UnestimatedOpenTasks)
+	}
+	if report.OverCapacity || report.DeferCandidates != nil {
+		t.Error("a report without capacity should not be over capacity")
+	}
+
+	// Three points over capacity: both low priority pending tasks are deferred, the last one first
+	capacity := 12.0
+	report = ComputePlanCapacityReport(plan, tasks, &capacity, now)
+
+	if !report.OverCapacity || report.Excess != 3 {
+		t.Fatalf("OverCapacity = %v, Excess = %v, expected over capacity by 3", report.OverCapacity, report.Excess)
+	}
+	if len(report.DeferCandidates) != 2 ||
+		report.DeferCandidates[0].TaskID != "low-last" || report.DeferCandidates[1].TaskID != "low-first" {
+		t.Errorf("unexpected defer candidates %+v", report.DeferCandidates)
+	}
+
+	capacity = 15
+	if report := ComputePlanCapacityReport(plan, tasks, &capacity, now); report.OverCapacity {
+		t.Error("remaining work equal to the capacity should fit")
+	}
+}
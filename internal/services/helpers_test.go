@@ -0,0 +1,43 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// testRepos are the repositories of a test on a fresh in-memory store
+type testRepos struct {
+	client   *storage.ValkeyClient
+	auditLog *storage.AuditLog
+	planRepo storage.PlanRepositoryInterface
+	taskRepo storage.TaskRepositoryInterface
+}
+
+// newTestRepos returns plain repositories on a fresh in-memory store, which is closed when the test ends
+func newTestRepos(t *testing.T) *testRepos {
+	t.Helper()
+	client, err := storage.NewMemoryClient(storage.MemoryConfig{})
+	if err != nil {
+		t.Fatalf("failed to create memory client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() }) //nolint:errcheck
+
+	return &testRepos{
+		client:   client,
+		auditLog: storage.NewAuditLog(client, storage.AuditRetention{MaxEntries: storage.DefaultAuditMaxEntries}),
+		planRepo: storage.NewPlanRepository(client),
+		taskRepo: storage.NewTaskRepository(client),
+	}
+}
+
+// audited returns the repositories recording their changes in the audit log
+func (r *testRepos) audited() (storage.PlanRepositoryInterface, storage.TaskRepositoryInterface) {
+	return storage.NewAuditedPlanRepository(r.planRepo, r.auditLog),
+		storage.NewAuditedTaskRepository(r.taskRepo, r.auditLog)
+}
+
+// limited returns the repositories enforcing limits
+func (r *testRepos) limited(limits storage.Limits) (storage.PlanRepositoryInterface, storage.TaskRepositoryInterface) {
+	return storage.NewLimitedPlanRepository(r.planRepo, limits), storage.NewLimitedTaskRepository(r.taskRepo, limits)
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// summarizerTimeout bounds a single request to the summarizer endpoint
+const summarizerTimeout = 90 * time.Second
+
+// WebhookSummarizer summarizes archived notes by posting them to an HTTP endpoint run by the operator, which
+// can forward them to an LLM. The endpoint receives {"summary": ..., "archived": ...} with the previous summary
+// and the newly archived notes, and answers with {"summary": ...}.
+type WebhookSummarizer struct {
+	url  string
+	http *http.Client
+}
+
+// NewWebhookSummarizer creates a summarizer posting to the given URL
+func NewWebhookSummarizer(url string) *WebhookSummarizer {
+	return &WebhookSummarizer{url: url, http: &http.Client{Timeout: summarizerTimeout}}
+}
+
+// summaryRequest is the body posted to the summarizer endpoint
+type summaryRequest struct {
+	Summary  string `json:"summary"`
+	Archived string `json:"archived"`
+}
+
+// summaryResponse is the body the summarizer endpoint answers with
+type summaryResponse struct {
+	Summary string `json:"summary"`
+}
+
+// Summarize posts the previous summary and the archived notes to the endpoint and returns the new summary
+func (w *WebhookSummarizer) Summarize(ctx context.Context, summary, archived string) (string, error) {
+	data, err := json.Marshal(summaryRequest{Summary: summary, Archived: archived})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode summary request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to create summary request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("summary request failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return "", fmt.Errorf("summary request failed with status %d", resp.StatusCode)
+	}
+
+	var result summaryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode summary response: %w", err)
+	}
+	if result.Summary == "" {
+		return "", fmt.Errorf("summary response has no summary")
+	}
+	return result.Summary, nil
+}
+
+var _ storage.NotesSummarizer = (*WebhookSummarizer)(nil)
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookSummarizer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req summaryRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Archived == "fail" {
+			http.Error(w, "no summary", http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(summaryResponse{Summary: req.Summary + "+" + req.Archived}) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	summarizer := NewWebhookSummarizer(server.URL)
+	summary, err := summarizer.Summarize(context.Background(), "old", "new")
+	if err != nil {
+		t.Fatalf("Summarize failed: %v", err)
+	}
+	if summary != "old+new" {
+		t.Errorf("summary = %q, want %q", summary, "old+new")
+	}
+
+	if _, err := summarizer.Summarize(context.Background(), "old", "fail"); err == nil {
+		t.Error("Summarize should fail when the endpoint fails")
+	}
+}
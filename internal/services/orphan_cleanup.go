@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// OrphanCleaner deletes tasks left behind by plans that no longer exist, for example when deleting a
+// plan was interrupted
+type OrphanCleaner struct {
+	checker  *storage.IntegrityChecker
+	taskRepo storage.TaskRepositoryInterface
+}
+
+// NewOrphanCleaner creates an orphan cleaner that finds orphaned tasks with the integrity checker
+func NewOrphanCleaner(checker *storage.IntegrityChecker, taskRepo storage.TaskRepositoryInterface) *OrphanCleaner {
+	return &OrphanCleaner{checker: checker, taskRepo: taskRepo}
+}
+
+// Run deletes the orphaned tasks and returns their IDs. It scans the whole keyspace.
+func (c *OrphanCleaner) Run(ctx context.Context) ([]string, error) {
+	report, err := c.checker.Check(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted []string
+	for _, issue := range report.Issues {
+		if issue.Kind != storage.IntegrityOrphanedTask {
+			continue
+		}
+		if err := c.taskRepo.Delete(ctx, issue.TaskID); err != nil {
+			return deleted, fmt.Errorf("failed to delete orphaned task %s: %w", issue.TaskID, err)
+		}
+		deleted = append(deleted, issue.TaskID)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,365 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// PlanChangeOperation is the kind of a change in a batch of plan changes
+type PlanChangeOperation string
+
+const (
+	PlanChangeCreateTask   PlanChangeOperation = "create_task"
+	PlanChangeUpdateTask   PlanChangeOperation = "update_task"
+	PlanChangeReorderTasks PlanChangeOperation = "reorder_tasks"
+	PlanChangeUpdatePlan   PlanChangeOperation = "update_plan"
+)
+
+// PlanChangeOperations lists the supported kinds of changes
+var PlanChangeOperations = []PlanChangeOperation{
+	PlanChangeCreateTask,
+	PlanChangeUpdateTask,
+	PlanChangeReorderTasks,
+	PlanChangeUpdatePlan,
+}
+
+// PlanChange is one change in a batch. Which fields apply depends on the operation; fields left out keep
+// their current value.
+type PlanChange struct {
+	Op PlanChangeOperation `json:"op"`
+	// Ref names a task created by the batch, so later changes can use it in place of the task ID
+	Ref string `json:"ref,omitempty"`
+	// TaskID is the task an update_task change applies to, either an ID or the ref of a created task
+	TaskID string `json:"task_id,omitempty"`
+	// TaskIDs is the new order of all tasks of the plan for a reorder_tasks change
+	TaskIDs     []string `json:"task_ids,omitempty"`
+	Title       *string  `json:"title,omitempty"`
+	Name        *string  `json:"name,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	Status      *string  `json:"status,omitempty"`
+	Priority    *string  `json:"priority,omitempty"`
+	Estimate    *float64 `json:"estimate,omitempty"`
+	// CompletionNote summarizes what was done when an update_task change completes the task
+	CompletionNote *string `json:"completion_note,omitempty"`
+}
+
+// PlanChangesResult is the state of a plan after a batch of changes was applied
+type PlanChangesResult struct {
+	Plan  *models.Plan   `json:"plan"`
+	Tasks []*models.Task `json:"tasks"`
+	// Created maps the refs of the tasks created by the batch to their IDs
+	Created map[string]string `json:"created,omitempty"`
+}
+
+// PlanChangeService applies batches of changes to a plan all or nothing
+type PlanChangeService struct {
+	planRepo storage.PlanRepositoryInterface
+	taskRepo storage.TaskRepositoryInterface
+}
+
+// NewPlanChangeService creates a new plan change service.
+// The repositories should be the audited ones so that every change of a batch is recorded.
+func NewPlanChangeService(
+	planRepo storage.PlanRepositoryInterface,
+	taskRepo storage.TaskRepositoryInterface,
+) *PlanChangeService {
+	return &PlanChangeService{
+		planRepo: planRepo,
+		taskRepo: taskRepo,
+	}
+}
+
+// ApplyChanges applies a batch of changes to a plan all or nothing. The plan is locked for the whole batch,
+// and every change is checked against the plan before the first one is made, so a batch with an invalid
+// change fails with an error naming it. The changes are then made with their writes staged, and written in
+// one Valkey transaction once all of them succeeded; if one fails, nothing is written.
+func (s *PlanChangeService) ApplyChanges(
+	ctx context.Context,
+	planID string,
+	changes []PlanChange,
+) (*PlanChangesResult, error) {
+	if len(changes) == 0 {
+		return nil, models.NewValidationError(models.EntityPlan, planID, "no changes given")
+	}
+
+	ctx, unlock, err := s.planRepo.LockPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	plan, err := s.planRepo.Get(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := s.taskRepo.ListByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkPlanChanges(plan, tasks, changes); err != nil {
+		return nil, err
+	}
+
+	refs := map[string]string{}
+	err = s.planRepo.WriteAtomically(ctx, planID, func(ctx context.Context) error {
+		batch := &planChangeBatch{planID: planID, refs: refs}
+		for i, change := range changes {
+			if err := s.applyChange(ctx, batch, change); err != nil {
+				return fmt.Errorf("change %d (%s): %w", i+1, change.Op, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PlanChangesResult{}
+	if len(refs) > 0 {
+		result.Created = refs
+	}
+	result.Plan, err = s.planRepo.Get(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	result.Tasks, err = s.taskRepo.ListByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// planChangeBatch tracks a batch while it is applied
+type planChangeBatch struct {
+	planID string
+	// refs maps the refs of created tasks to their IDs
+	refs map[string]string
+}
+
+// taskID resolves the ref of a created task to its ID
+func (b *planChangeBatch) taskID(idOrRef string) string {
+	if id, ok := b.refs[idOrRef]; ok {
+		return id
+	}
+	return idOrRef
+}
+
+// applyChange makes a single change
+func (s *PlanChangeService) applyChange(ctx context.Context, batch *planChangeBatch, change PlanChange) error {
+	switch change.Op {
+	case PlanChangeCreateTask:
+		return s.createTask(ctx, batch, change)
+	case PlanChangeUpdateTask:
+		return s.updateTask(ctx, batch, change)
+	case PlanChangeReorderTasks:
+		return s.reorderTasks(ctx, batch, change)
+	case PlanChangeUpdatePlan:
+		return s.updatePlan(ctx, batch, change)
+	default:
+		return fmt.Errorf("unsupported operation: %s", change.Op)
+	}
+}
+
+func (s *PlanChangeService) createTask(ctx context.Context, batch *planChangeBatch, change PlanChange) error {
+	input := storage.TaskCreateInput{Title: *change.Title}
+	if change.Description != nil {
+		input.Description = *change.Description
+	}
+	if change.Status != nil {
+		input.Status = models.TaskStatus(*change.Status)
+	}
+	if change.Priority != nil {
+		input.Priority = models.TaskPriority(*change.Priority)
+	}
+	if change.Estimate != nil {
+		input.Estimate = *change.Estimate
+	}
+
+	created, err := s.taskRepo.CreateBulk(ctx, batch.planID, []storage.TaskCreateInput{input})
+	if err != nil {
+		return err
+	}
+	if len(created) != 1 {
+		return fmt.Errorf("expected 1 created task, got %d", len(created))
+	}
+
+	if change.Ref != "" {
+		batch.refs[change.Ref] = created[0].ID
+	}
+	return nil
+}
+
+func (s *PlanChangeService) updateTask(ctx context.Context, batch *planChangeBatch, change PlanChange) error {
+	task, err := s.taskRepo.Get(ctx, batch.taskID(change.TaskID))
+	if err != nil {
+		return err
+	}
+
+	if change.Title != nil {
+		task.Title = *change.Title
+	}
+	if change.Description != nil {
+		task.Description = *change.Description
+	}
+	if change.Status != nil {
+		task.Status = models.TaskStatus(*change.Status)
+	}
+	if change.Priority != nil {
+		task.Priority = models.TaskPriority(*change.Priority)
+	}
+	if change.Estimate != nil {
+		task.Estimate = *change.Estimate
+	}
+	if change.CompletionNote != nil {
+		task.CompletionNote = *change.CompletionNote
+	}
+
+	return s.taskRepo.Update(ctx, task)
+}
+
+func (s *PlanChangeService) reorderTasks(ctx context.Context, batch *planChangeBatch, change PlanChange) error {
+	taskIDs := make([]string, len(change.TaskIDs))
+	for i, id := range change.TaskIDs {
+		taskIDs[i] = batch.taskID(id)
+	}
+	_, err := s.taskRepo.ReorderTasks(ctx, batch.planID, taskIDs)
+	return err
+}
+
+func (s *PlanChangeService) updatePlan(ctx context.Context, batch *planChangeBatch, change PlanChange) error {
+	plan, err := s.planRepo.Get(ctx, batch.planID)
+	if err != nil {
+		return err
+	}
+
+	if change.Name != nil {
+		plan.Name = *change.Name
+	}
+	if change.Description != nil {
+		plan.Description = *change.Description
+	}
+	if change.Priority != nil {
+		plan.Priority = models.PlanPriority(*change.Priority)
+	}
+
+	if err := s.planRepo.Update(ctx, plan); err != nil {
+		return err
+	}
+	if change.Status != nil {
+		_, err = s.planRepo.UpdateStatus(ctx, plan.ID, models.PlanStatus(*change.Status))
+	}
+	return err
+}
+
+// checkPlanChanges checks a batch against the current tasks of a plan without changing anything, following
+// the tasks the batch creates so later changes can refer to them
+func checkPlanChanges(plan *models.Plan, tasks []*models.Task, changes []PlanChange) error {
+	taskIDs := make(map[string]bool, len(tasks)+len(changes))
+	for _, task := range tasks {
+		taskIDs[task.ID] = true
+	}
+	// Tasks created without a ref cannot be named by a later reorder, but still count as tasks of the plan
+	taskCount := len(tasks)
+
+	for i, change := range changes {
+		err := checkPlanChange(change, taskIDs, taskCount)
+		if err != nil {
+			return models.NewValidationError(models.EntityPlan, plan.ID, "change %d (%s): %s", i+1, change.Op, err.Error())
+		}
+		if change.Op == PlanChangeCreateTask {
+			taskCount++
+			if change.Ref != "" {
+				taskIDs[change.Ref] = true
+			}
+		}
+	}
+	return nil
+}
+
+// checkPlanChange checks a single change. taskIDs holds the IDs and refs of the tasks the plan has at that
+// point of the batch and taskCount their number.
+func checkPlanChange(change PlanChange, taskIDs map[string]bool, taskCount int) error {
+	switch change.Op {
+	case PlanChangeCreateTask:
+		if change.Title == nil || *change.Title == "" {
+			return errors.New("title is required")
+		}
+		if change.Ref != "" && taskIDs[change.Ref] {
+			return fmt.Errorf("ref %s is already used", change.Ref)
+		}
+		return checkTaskFields(change)
+	case PlanChangeUpdateTask:
+		if change.TaskID == "" {
+			return errors.New("task_id is required")
+		}
+		if !taskIDs[change.TaskID] {
+			return fmt.Errorf("task %s is not a task of the plan", change.TaskID)
+		}
+		if change.Title != nil && *change.Title == "" {
+			return errors.New("title cannot be empty")
+		}
+		return checkTaskFields(change)
+	case PlanChangeReorderTasks:
+		if len(change.TaskIDs) != taskCount {
+			return fmt.Errorf("task_ids has %d tasks, but the plan has %d", len(change.TaskIDs), taskCount)
+		}
+		seen := make(map[string]bool, len(change.TaskIDs))
+		for _, id := range change.TaskIDs {
+			if !taskIDs[id] {
+				return fmt.Errorf("task %s is not a task of the plan", id)
+			}
+			if seen[id] {
+				return fmt.Errorf("task %s is listed more than once", id)
+			}
+			seen[id] = true
+		}
+		return nil
+	case PlanChangeUpdatePlan:
+		if change.Name != nil && *change.Name == "" {
+			return errors.New("name cannot be empty")
+		}
+		if change.Status != nil && !slices.Contains(planStatuses, models.PlanStatus(*change.Status)) {
+			return fmt.Errorf("invalid status: %s", *change.Status)
+		}
+		if change.Priority != nil && !slices.Contains(planPriorities, models.PlanPriority(*change.Priority)) {
+			return fmt.Errorf("invalid priority: %s", *change.Priority)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported operation, expected one of %v", PlanChangeOperations)
+	}
+}
+
+// checkTaskFields checks the status, priority and estimate of a task change
+func checkTaskFields(change PlanChange) error {
+	if change.Status != nil && !models.IsValidTaskStatus(models.TaskStatus(*change.Status)) {
+		return fmt.Errorf("invalid status: %s", *change.Status)
+	}
+	if change.Priority != nil && !models.IsValidTaskPriority(models.TaskPriority(*change.Priority)) {
+		return fmt.Errorf("invalid priority: %s", *change.Priority)
+	}
+	if change.Estimate != nil {
+		return models.ValidateEstimate(*change.Estimate)
+	}
+	return nil
+}
+
+var (
+	planStatuses = []models.PlanStatus{
+		models.PlanStatusNew,
+		models.PlanStatusInProgress,
+		models.PlanStatusCompleted,
+		models.PlanStatusCancelled,
+	}
+	planPriorities = []models.PlanPriority{
+		models.PlanPriorityLow,
+		models.PlanPriorityMedium,
+		models.PlanPriorityHigh,
+	}
+)
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+func newPlanChangeTest(t *testing.T, limits storage.Limits) (*PlanChangeService, *models.Plan, []*models.Task) {
+	t.Helper()
+	planRepo, taskRepo := newTestRepos(t).limited(limits)
+
+	ctx := context.Background()
+	plan, err := planRepo.Create(ctx, "app", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	tasks, err := taskRepo.CreateBulk(ctx, plan.ID, []storage.TaskCreateInput{{Title: "First"}, {Title: "Second"}})
+	if err != nil {
+		t.Fatalf("failed to create tasks: %v", err)
+	}
+	return NewPlanChangeService(planRepo, taskRepo), plan, tasks
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestApplyChanges(t *testing.T) {
+	service, plan, tasks := newPlanChangeTest(t, storage.Limits{})
+
+	result, err := service.ApplyChanges(context.Background(), plan.ID, []PlanChange{
+		{Op: PlanChangeCreateTask, Ref: "new", Title: ptr("Third"), Priority: ptr("high")},
+		{Op: PlanChangeUpdateTask, TaskID: tasks[0].ID, Status: ptr("completed")},
+		{Op: PlanChangeUpdateTask, TaskID: "new", Description: ptr("Added by the batch")},
+		{Op: PlanChangeReorderTasks, TaskIDs: []string{"new", tasks[1].ID, tasks[0].ID}},
+		{Op: PlanChangeUpdatePlan, Name: ptr("Renamed"), Priority: ptr("low")},
+	})
+	if err != nil {
+		t.Fatalf("failed to apply changes: %v", err)
+	}
+
+	if result.Plan.Name != "Renamed" || result.Plan.Priority != models.PlanPriorityLow {
+		t.Errorf("expected the plan to be renamed with low priority, got %q, %s", result.Plan.Name, result.Plan.Priority)
+	}
+	newID := result.Created["new"]
+	if newID == "" {
+		t.Fatalf("expected the ID of the created task, got %v", result.Created)
+	}
+
+	var titles []string
+	for _, task := range result.Tasks {
+		titles = append(titles, task.Title)
+	}
+	if got := strings.Join(titles, ","); got != "Third,Second,First" {
+		t.Errorf("expected tasks Third,Second,First, got %s", got)
+	}
+	if result.Tasks[0].ID != newID || result.Tasks[0].Description != "Added by the batch" {
+		t.Errorf("expected the created task first with its description, got %+v", result.Tasks[0])
+	}
+	if result.Tasks[2].Status != models.TaskStatusCompleted {
+		t.Errorf("expected the first task to be completed, got %s", result.Tasks[2].Status)
+	}
+}
+
+func TestApplyChangesRejectsInvalidBatch(t *testing.T) {
+	service, plan, tasks := newPlanChangeTest(t, storage.Limits{})
+
+	tests := []struct {
+		name    string
+		changes []PlanChange
+		want    string
+	}{
+		{
+			name:    "No changes",
+			changes: nil,
+			want:    "no changes given",
+		},
+		{
+			name: "Unknown task",
+			changes: []PlanChange{
+				{Op: PlanChangeCreateTask, Title: ptr("Third")},
+				{Op: PlanChangeUpdateTask, TaskID: "missing", Title: ptr("Renamed")},
+			},
+			want: "change 2 (update_task): task missing is not a task of the plan",
+		},
+		{
+			name: "Incomplete order",
+			changes: []PlanChange{
+				{Op: PlanChangeCreateTask, Title: ptr("Third")},
+				{Op: PlanChangeReorderTasks, TaskIDs: []string{tasks[1].ID, tasks[0].ID}},
+			},
+			want: "change 2 (reorder_tasks): task_ids has 2 tasks, but the plan has 3",
+		},
+		{
+			name:    "Invalid plan status",
+			changes: []PlanChange{{Op: PlanChangeUpdatePlan, Status: ptr("pending")}},
+			want:    "change 1 (update_plan): invalid status: pending",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ApplyChanges(context.Background(), plan.ID, tt.changes)
+			if err == nil || err.Error() != tt.want {
+				t.Fatalf("expected error %q, got %v", tt.want, err)
+			}
+			if models.ErrorCodeOf(err) != models.ErrorCodeValidation {
+				t.Errorf("expected a validation error, got %s", models.ErrorCodeOf(err))
+			}
+			current, err := service.taskRepo.ListByPlan(context.Background(), plan.ID)
+			if err != nil {
+				t.Fatalf("failed to list tasks: %v", err)
+			}
+			if len(current) != len(tasks) {
+				t.Errorf("expected the plan to keep %d tasks, got %d", len(tasks), len(current))
+			}
+		})
+	}
+}
+
+func TestApplyChangesWritesNothingAfterFailure(t *testing.T) {
+	service, plan, tasks := newPlanChangeTest(t, storage.Limits{MaxTitleLength: 10})
+	ctx := context.Background()
+
+	// The long title passes the checks of the batch but is rejected by the limits when it is written
+	_, err := service.ApplyChanges(ctx, plan.ID, []PlanChange{
+		{Op: PlanChangeUpdatePlan, Name: ptr("Renamed")},
+		{Op: PlanChangeCreateTask, Ref: "new", Title: ptr("Third")},
+		{Op: PlanChangeReorderTasks, TaskIDs: []string{"new", tasks[1].ID, tasks[0].ID}},
+		{Op: PlanChangeUpdateTask, TaskID: tasks[0].ID, Status: ptr("completed")},
+		{Op: PlanChangeUpdateTask, TaskID: tasks[1].ID, Title: ptr("A title that is too long")},
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "change 5 (update_task): ") {
+		t.Fatalf("expected the fifth change to fail, got %v", err)
+	}
+
+	current, err := service.planRepo.Get(ctx, plan.ID)
+	if err != nil {
+		t.Fatalf("failed to get plan: %v", err)
+	}
+	if current.Name != "Plan" {
+		t.Errorf("expected the plan name to be unchanged, got %q", current.Name)
+	}
+
+	currentTasks, err := service.taskRepo.ListByPlan(ctx, plan.ID)
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	if len(currentTasks) != 2 || currentTasks[0].ID != tasks[0].ID || currentTasks[1].ID != tasks[1].ID {
+		t.Fatalf("expected the original tasks in their original order, got %+v", currentTasks)
+	}
+	if currentTasks[0].Status != models.TaskStatusPending {
+		t.Errorf("expected the status of the first task to be unchanged, got %s", currentTasks[0].Status)
+	}
+	if counts := current.TaskCounts; counts != nil && counts.Count(models.TaskStatusPending) != 2 {
+		t.Errorf("expected 2 pending tasks in the plan counters, got %d", counts.Count(models.TaskStatusPending))
+	}
+}
+
+// unreorderableTaskRepository fails to reorder tasks, like a storage failure in the middle of a batch
+type unreorderableTaskRepository struct {
+	storage.TaskRepositoryInterface
+}
+
+func (r *unreorderableTaskRepository) ReorderTasks(
+	ctx context.Context,
+	planID string,
+	taskIDs []string,
+) ([]*models.Task, error) {
+	return nil, errors.New("storage unavailable")
+}
+
+func TestApplyChangesWritesNothingAfterStorageFailure(t *testing.T) {
+	service, plan, tasks := newPlanChangeTest(t, storage.Limits{})
+	service.taskRepo = &unreorderableTaskRepository{TaskRepositoryInterface: service.taskRepo}
+	ctx := context.Background()
+
+	_, err := service.ApplyChanges(ctx, plan.ID, []PlanChange{
+		{Op: PlanChangeUpdatePlan, Name: ptr("Renamed")},
+		{Op: PlanChangeCreateTask, Ref: "new", Title: ptr("Third")},
+		{Op: PlanChangeUpdateTask, TaskID: tasks[0].ID, Status: ptr("in_progress")},
+		{Op: PlanChangeReorderTasks, TaskIDs: []string{"new", tasks[1].ID, tasks[0].ID}},
+	})
+	if err == nil || err.Error() != "change 4 (reorder_tasks): storage unavailable" {
+		t.Fatalf("expected the fourth change to fail, got %v", err)
+	}
+
+	current, err := service.planRepo.Get(ctx, plan.ID)
+	if err != nil {
+		t.Fatalf("failed to get plan: %v", err)
+	}
+	if current.Name != "Plan" || current.Status != models.PlanStatusNew {
+		t.Errorf("expected the plan to be unchanged, got %q, %s", current.Name, current.Status)
+	}
+	currentTasks, err := service.taskRepo.ListByPlan(ctx, plan.ID)
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	if len(currentTasks) != 2 || currentTasks[0].Status != models.TaskStatusPending {
+		t.Errorf("expected the original tasks unchanged, got %+v", currentTasks)
+	}
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/utils/markdown"
+)
+
+// MarkdownImportOptions override what a plan imported from markdown takes from the document
+type MarkdownImportOptions struct {
+	// Name of the plan, defaults to the title heading of the document
+	Name string
+	// Description of the plan, defaults to the text between the title and the first section or checkbox
+	Description string
+}
+
+// PlanImportService creates plans from documents written outside the server
+type PlanImportService struct {
+	planRepo storage.PlanRepositoryInterface
+	taskRepo storage.TaskRepositoryInterface
+}
+
+// NewPlanImportService creates a new plan import service
+func NewPlanImportService(
+	planRepo storage.PlanRepositoryInterface,
+	taskRepo storage.TaskRepositoryInterface,
+) *PlanImportService {
+	return &PlanImportService{
+		planRepo: planRepo,
+		taskRepo: taskRepo,
+	}
+}
+
+// ImportMarkdown creates a plan from a markdown checklist such as a TODO.md file. Every top level checkbox
+// becomes a task, completed when it is checked, with the indented text under it as its description, the
+// checkboxes nested under it as its checklist and the heading it is under as a tag. If a task cannot be
+// created, the plan is deleted again so that no partial import is left behind.
+func (s *PlanImportService) ImportMarkdown(
+	ctx context.Context,
+	applicationID, content string,
+	opts MarkdownImportOptions,
+) (*models.PlanResource, error) {
+	checklist := markdown.ParseChecklist(content)
+	if len(checklist.Items) == 0 {
+		return nil, models.NewValidationError("", "", "markdown contains no checkboxes")
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = checklist.Title
+	}
+	if name == "" {
+		return nil, models.NewValidationError("", "", "name is required when the markdown has no title heading")
+	}
+	description := opts.Description
+	if description == "" {
+		description = checklist.Description
+	}
+
+	plan, err := s.planRepo.Create(ctx, applicationID, name, description)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.createChecklistTasks(ctx, plan.ID, checklist.Items); err != nil {
+		if deleteErr := s.planRepo.Delete(context.WithoutCancel(ctx), plan.ID); deleteErr != nil {
+			return nil, fmt.Errorf("%w; deleting the imported plan %s failed: %v", err, plan.ID, deleteErr)
+		}
+		return nil, err
+	}
+
+	plan, err = s.planRepo.Get(ctx, plan.ID)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := s.taskRepo.ListByPlan(ctx, plan.ID)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewPlanResource(plan, tasks), nil
+}
+
+// createChecklistTasks adds the checkboxes of a checklist to a plan as tasks
+func (s *PlanImportService) createChecklistTasks(ctx context.Context, planID string, items []markdown.ChecklistItem) error {
+	inputs := make([]storage.TaskCreateInput, len(items))
+	for i, item := range items {
+		status := models.TaskStatusPending
+		if item.Checked {
+			status = models.TaskStatusCompleted
+		}
+		inputs[i] = storage.TaskCreateInput{
+			Title:       item.Text,
+			Description: item.Details,
+			Status:      status,
+			Priority:    models.TaskPriorityMedium,
+		}
+	}
+
+	tasks, err := s.taskRepo.CreateBulk(ctx, planID, inputs)
+	if err != nil {
+		return err
+	}
+
+	for i, item := range items {
+		if item.Section != "" {
+			if _, err := s.taskRepo.AddTags(ctx, tasks[i].ID, []string{item.Section}); err != nil {
+				return fmt.Errorf("failed to tag task %s: %w", tasks[i].ID, err)
+			}
+		}
+		for _, subitem := range item.Subitems {
+			task, err := s.taskRepo.AddChecklistItem(ctx, tasks[i].ID, subitem.Text)
+			if err != nil {
+				return fmt.Errorf("failed to add checklist item to task %s: %w", tasks[i].ID, err)
+			}
+			if !subitem.Checked {
+				continue
+			}
+			done := true
+			added := task.Checklist[len(task.Checklist)-1]
+			if _, err := s.taskRepo.ToggleChecklistItem(ctx, tasks[i].ID, added.ID, &done); err != nil {
+				return fmt.Errorf("failed to check checklist item of task %s: %w", tasks[i].ID, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+func newPlanImportTest(t *testing.T, limits storage.Limits) *PlanImportService {
+	t.Helper()
+	return NewPlanImportService(newTestRepos(t).limited(limits))
+}
+
+func TestImportMarkdown(t *testing.T) {
+	service := newPlanImportTest(t, storage.Limits{})
+
+	content := "# Release\n\nShip version 2.\n\n## Backend\n- [x] Add the endpoint\n  Keep the old one for now.\n" +
+		"## Docs\n- [ ] Update the guide\n  - [x] Screenshots\n  - [ ] Examples\n"
+	result, err := service.ImportMarkdown(context.Background(), "app", content, MarkdownImportOptions{})
+	if err != nil {
+		t.Fatalf("failed to import markdown: %v", err)
+	}
+
+	if result.Plan.Name != "Release" || result.Plan.Description != "Ship version 2." {
+		t.Errorf("expected the plan to take the title and description, got %q, %q", result.Plan.Name, result.Plan.Description)
+	}
+	if len(result.Tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(result.Tasks))
+	}
+
+	endpoint := result.Tasks[0]
+	if endpoint.Title != "Add the endpoint" || endpoint.Status != models.TaskStatusCompleted {
+		t.Errorf("expected a completed endpoint task, got %q, %s", endpoint.Title, endpoint.Status)
+	}
+	if endpoint.Description != "Keep the old one for now." {
+		t.Errorf("expected the details as description, got %q", endpoint.Description)
+	}
+	if len(endpoint.Tags) != 1 || endpoint.Tags[0] != "backend" {
+		t.Errorf("expected the backend tag, got %v", endpoint.Tags)
+	}
+
+	guide := result.Tasks[1]
+	if guide.Status != models.TaskStatusPending {
+		t.Errorf("expected the guide task to be pending, got %s", guide.Status)
+	}
+	if len(guide.Checklist) != 2 || !guide.Checklist[0].Done || guide.Checklist[1].Done {
+		t.Errorf("expected a checklist with Screenshots done and Examples open, got %+v", guide.Checklist)
+	}
+}
+
+func TestImportMarkdownErrors(t *testing.T) {
+	service := newPlanImportTest(t, storage.Limits{MaxTitleLength: 10})
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "No checkboxes", content: "# Plan\n\n- plain item", want: "markdown contains no checkboxes"},
+		{name: "No name", content: "- [ ] Task", want: "name is required when the markdown has no title heading"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ImportMarkdown(ctx, "app", tt.content, MarkdownImportOptions{})
+			if err == nil || err.Error() != tt.want {
+				t.Fatalf("expected error %q, got %v", tt.want, err)
+			}
+			if models.ErrorCodeOf(err) != models.ErrorCodeValidation {
+				t.Errorf("expected a validation error, got %s", models.ErrorCodeOf(err))
+			}
+		})
+	}
+
+	// A task title over the limit fails the import, which must not leave the plan behind
+	_, err := service.ImportMarkdown(ctx, "app", "# Plan\n- [ ] A title that is too long", MarkdownImportOptions{})
+	if err == nil {
+		t.Fatal("expected the import to fail")
+	}
+	plans, err := service.planRepo.ListByApplication(ctx, "app")
+	if err != nil {
+		t.Fatalf("failed to list plans: %v", err)
+	}
+	if len(plans) != 0 {
+		t.Errorf("expected the failed import to be deleted, got %d plans", len(plans))
+	}
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// MermaidDiagram is the kind of Mermaid diagram a plan is rendered as
+type MermaidDiagram string
+
+const (
+	// MermaidGantt lays the tasks out on a timeline from their dates, estimates and dependencies
+	MermaidGantt MermaidDiagram = "gantt"
+	// MermaidFlowchart draws the tasks as nodes with an arrow from each dependency to the tasks needing it
+	MermaidFlowchart MermaidDiagram = "flowchart"
+)
+
+// EstimateUnit is the unit of time task estimates are drawn in on a Gantt chart
+type EstimateUnit string
+
+const (
+	EstimateUnitHours EstimateUnit = "hours"
+	EstimateUnitDays  EstimateUnit = "days"
+	EstimateUnitWeeks EstimateUnit = "weeks"
+)
+
+// mermaidDurationSuffixes maps estimate units to the suffixes of Mermaid durations
+var mermaidDurationSuffixes = map[EstimateUnit]string{
+	EstimateUnitHours: "h",
+	EstimateUnitDays:  "d",
+	EstimateUnitWeeks: "w",
+}
+
+// MermaidOptions choose how a plan is rendered as a Mermaid diagram
+type MermaidOptions struct {
+	// Diagram defaults to a Gantt chart
+	Diagram MermaidDiagram
+	// EstimateUnit is how long one unit of estimate takes on a Gantt chart, defaults to days
+	EstimateUnit EstimateUnit
+}
+
+// GetPlanMermaid loads a plan and its tasks and renders them as a Mermaid diagram
+func (s *PlanStatsService) GetPlanMermaid(ctx context.Context, planID string, opts MermaidOptions) (string, error) {
+	plan, err := s.planRepo.Get(ctx, planID)
+	if err != nil {
+		return "", err
+	}
+
+	tasks, err := s.taskRepo.ListByPlan(ctx, planID)
+	if err != nil {
+		return "", err
+	}
+
+	return RenderPlanMermaid(plan, tasks, opts, time.Now())
+}
+
+// RenderPlanMermaid renders a plan and its tasks as a Mermaid Gantt chart or dependency flowchart, ready to
+// embed in documentation. Tasks are named t1, t2, ... in plan order; dependencies on tasks outside the plan
+// are left out.
+func RenderPlanMermaid(plan *models.Plan, tasks []*models.Task, opts MermaidOptions, now time.Time) (string, error) {
+	if opts.Diagram == "" {
+		opts.Diagram = MermaidGantt
+	}
+	if opts.EstimateUnit == "" {
+		opts.EstimateUnit = EstimateUnitDays
+	}
+	if _, ok := mermaidDurationSuffixes[opts.EstimateUnit]; !ok {
+		return "", models.NewValidationError(models.EntityPlan, plan.ID, "invalid estimate unit: %s", opts.EstimateUnit)
+	}
+
+	nodeIDs := make(map[string]string, len(tasks))
+	for i, task := range tasks {
+		nodeIDs[task.ID] = "t" + strconv.Itoa(i+1)
+	}
+
+	switch opts.Diagram {
+	case MermaidGantt:
+		return renderMermaidGantt(plan, tasks, nodeIDs, opts.EstimateUnit, now), nil
+	case MermaidFlowchart:
+		return renderMermaidFlowchart(tasks, nodeIDs), nil
+	default:
+		return "", models.NewValidationError(models.EntityPlan, plan.ID, "invalid diagram: %s", opts.Diagram)
+	}
+}
+
+// renderMermaidGantt draws the tasks that are not cancelled on a timeline. A task starts when it was started,
+// after its dependencies, or today if it has neither, and ends when it was completed, on its due date, or
+// after its estimate (one unit if it has none). Completed tasks are marked done, tasks in progress active and
+// overdue tasks critical.
+func renderMermaidGantt(
+	plan *models.Plan,
+	tasks []*models.Task,
+	nodeIDs map[string]string,
+	unit EstimateUnit,
+	now time.Time,
+) string {
+	var b strings.Builder
+	b.WriteString("gantt\n")
+	fmt.Fprintf(&b, "    title %s\n", mermaidText(plan.Name))
+	b.WriteString("    dateFormat YYYY-MM-DD\n")
+	b.WriteString("    section Tasks\n")
+
+	for _, task := range tasks {
+		if task.Status == models.TaskStatusCancelled {
+			continue
+		}
+
+		var tags []string
+		switch task.Status {
+		case models.TaskStatusCompleted:
+			tags = append(tags, "done")
+		case models.TaskStatusInProgress:
+			tags = append(tags, "active")
+		}
+		if task.IsOpen() && task.DueDate != nil && task.DueDate.Before(now) {
+			tags = append(tags, "crit")
+		}
+		tags = append(tags, nodeIDs[task.ID])
+
+		var start time.Time
+		var after []string
+		for _, dependencyID := range task.DependsOn {
+			if nodeID, ok := nodeIDs[dependencyID]; ok {
+				after = append(after, nodeID)
+			}
+		}
+		switch {
+		case task.StartedAt != nil:
+			start = *task.StartedAt
+		case len(after) > 0:
+		case task.Status == models.TaskStatusCompleted:
+			start = task.CreatedAt
+		default:
+			start = now
+		}
+		startField := "after " + strings.Join(after, " ")
+		if !start.IsZero() {
+			startField = start.Format(time.DateOnly)
+		}
+
+		// End dates are exclusive, so a task ending on a day runs until the start of the next one
+		var endField string
+		switch {
+		case task.CompletedAt != nil:
+			endField = endOfDay(*task.CompletedAt, start)
+		case task.DueDate != nil && !start.IsZero() && !task.DueDate.Before(start):
+			endField = endOfDay(*task.DueDate, start)
+		default:
+			estimate := task.Estimate
+			if estimate <= 0 {
+				estimate = 1
+			}
+			endField = strconv.FormatFloat(estimate, 'f', -1, 64) + mermaidDurationSuffixes[unit]
+		}
+
+		fmt.Fprintf(&b, "    %s :%s, %s, %s\n", mermaidText(task.Title), strings.Join(tags, ", "), startField, endField)
+	}
+
+	return b.String()
+}
+
+// endOfDay returns the exclusive end date of a task ending on the day of the given time, at least a day after
+// its start
+func endOfDay(end, start time.Time) string {
+	end = end.AddDate(0, 0, 1)
+	if !start.IsZero() && end.Before(start.AddDate(0, 0, 1)) {
+		end = start.AddDate(0, 0, 1)
+	}
+	return end.Format(time.DateOnly)
+}
+
+// renderMermaidFlowchart draws the tasks as nodes styled by status, with an arrow from each dependency to the
+// task depending on it
+func renderMermaidFlowchart(tasks []*models.Task, nodeIDs map[string]string) string {
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+
+	for _, task := range tasks {
+		fmt.Fprintf(&b, "    %s[\"%s\"]:::%s\n", nodeIDs[task.ID], mermaidText(task.Title), task.Status)
+	}
+	for _, task := range tasks {
+		for _, dependencyID := range task.DependsOn {
+			if nodeID, ok := nodeIDs[dependencyID]; ok {
+				fmt.Fprintf(&b, "    %s --> %s\n", nodeID, nodeIDs[task.ID])
+			}
+		}
+	}
+
+	b.WriteString("    classDef pending fill:#f5f5f5,stroke:#9e9e9e\n")
+	b.WriteString("    classDef in_progress fill:#e3f2fd,stroke:#1e88e5\n")
+	b.WriteString("    classDef completed fill:#e8f5e9,stroke:#43a047\n")
+	b.WriteString("    classDef cancelled fill:#eeeeee,stroke:#bdbdbd,stroke-dasharray:4 2,color:#9e9e9e\n")
+	return b.String()
+}
+
+// mermaidTextReplacer replaces the characters that end labels or statements in Mermaid with entity codes
+var mermaidTextReplacer = strings.NewReplacer(
+	"#", "#35;",
+	"\"", "#quot;",
+	":", "#58;",
+	";", "#59;",
+	"\n", " ",
+	"\r", " ",
+)
+
+// mermaidText makes a title safe to use as a Mermaid label
+func mermaidText(text string) string {
+	return mermaidTextReplacer.Replace(strings.TrimSpace(text))
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+func newMermaidTestPlan(now time.Time) (*models.Plan, []*models.Task) {
+	plan := models.NewPlan("plan-1", "app-1", "Release: 1.2", "")
+
+	design := models.NewTask("a", plan.ID, "Design", "", models.TaskPriorityMedium)
+	design.Status = models.TaskStatusCompleted
+	started := now.AddDate(0, 0, -5)
+	completed := now.AddDate(0, 0, -3)
+	design.StartedAt, design.CompletedAt = &started, &completed
+
+	build := models.NewTask("b", plan.ID, `Build the "core"`, "", models.TaskPriorityHigh)
+	build.Status = models.TaskStatusInProgress
+	build.DependsOn = []string{"a", "elsewhere"}
+	build.Estimate = 3
+
+	document := models.NewTask("c", plan.ID, "Document", "", models.TaskPriorityLow)
+	due := now.AddDate(0, 0, -1)
+	document.DueDate = &due
+
+	dropped := models.NewTask("d", plan.ID, "Old idea", "", models.TaskPriorityLow)
+	dropped.Status = models.TaskStatusCancelled
+	dropped.DependsOn = []string{"b"}
+
+	return plan, []*models.Task{design, build, document, dropped}
+}
+
+func TestRenderPlanMermaidGantt(t *testing.T) {
+	now := time.Date(2025, time.July, 10, 12, 0, 0, 0, time.UTC)
+	plan, tasks := newMermaidTestPlan(now)
+
+	chart, err := RenderPlanMermaid(plan, tasks, MermaidOptions{EstimateUnit: EstimateUnitHours}, now)
+	if err != nil {
+		t.Fatalf("failed to render chart: %v", err)
+	}
+
+	expected := `gantt
+    title Release#58; 1.2
+    dateFormat YYYY-MM-DD
+    section Tasks
+    Design :done, t1, 2025-07-05, 2025-07-08
+    Build the #quot;core#quot; :active, t2, after t1, 3h
+    Document :crit, t3, 2025-07-10, 1h
+`
+	if chart != expected {
+		t.Errorf("unexpected chart:\n%s\nwant:\n%s", chart, expected)
+	}
+}
+
+func TestRenderPlanMermaidFlowchart(t *testing.T) {
+	now := time.Date(2025, time.July, 10, 12, 0, 0, 0, time.UTC)
+	plan, tasks := newMermaidTestPlan(now)
+
+	chart, err := RenderPlanMermaid(plan, tasks, MermaidOptions{Diagram: MermaidFlowchart}, now)
+	if err != nil {
+		t.Fatalf("failed to render chart: %v", err)
+	}
+
+	expected := `flowchart TD
+    t1["Design"]:::completed
+    t2["Build the #quot;core#quot;"]:::in_progress
+    t3["Document"]:::pending
+    t4["Old idea"]:::cancelled
+    t1 --> t2
+    t2 --> t4
+    classDef pending fill:#f5f5f5,stroke:#9e9e9e
+    classDef in_progress fill:#e3f2fd,stroke:#1e88e5
+    classDef completed fill:#e8f5e9,stroke:#43a047
+    classDef cancelled fill:#eeeeee,stroke:#bdbdbd,stroke-dasharray:4 2,color:#9e9e9e
+`
+	if chart != expected {
+		t.Errorf("unexpected chart:\n%s\nwant:\n%s", chart, expected)
+	}
+}
+
+func TestRenderPlanMermaidInvalidOptions(t *testing.T) {
+	plan, tasks := newMermaidTestPlan(time.Now())
+
+	if _, err := RenderPlanMermaid(plan, tasks, MermaidOptions{Diagram: "pie"}, time.Now()); err == nil {
+		t.Error("expected an error for an unknown diagram")
+	}
+	if _, err := RenderPlanMermaid(plan, tasks, MermaidOptions{EstimateUnit: "points"}, time.Now()); err == nil {
+		t.Error("expected an error for an unknown estimate unit")
+	}
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/utils/markdown"
+)
+
+// reportTimeFormat is the layout of times shown in plan reports
+const reportTimeFormat = "2006-01-02 15:04 MST"
+
+// GetPlanMarkdown loads a plan and its tasks and renders them as a markdown progress report
+func (s *PlanStatsService) GetPlanMarkdown(ctx context.Context, planID string) (string, error) {
+	plan, err := s.planRepo.Get(ctx, planID)
+	if err != nil {
+		return "", err
+	}
+
+	tasks, err := s.taskRepo.ListByPlan(ctx, planID)
+	if err != nil {
+		return "", err
+	}
+
+	return RenderPlanMarkdown(plan, tasks, time.Now()), nil
+}
+
+// RenderPlanMarkdown renders a plan and its tasks as a markdown document suitable for a PR description
+// or status update. Tasks are listed as a checklist in plan order with their acceptance criteria nested
+// under them; the notes of the plan and its tasks follow with their headings demoted so they nest under the
+// report's own sections.
+func RenderPlanMarkdown(plan *models.Plan, tasks []*models.Task, now time.Time) string {
+	progress := ComputePlanProgress(plan, tasks, now)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", markdown.EscapeInline(plan.Name))
+	if description := strings.TrimSpace(plan.Description); description != "" {
+		fmt.Fprintf(&b, "%s\n\n", description)
+	}
+
+	fmt.Fprintf(&b, "- **Application:** %s\n", markdown.EscapeInline(plan.ApplicationID))
+	fmt.Fprintf(&b, "- **Status:** %s\n", statusLabel(string(plan.Status)))
+	fmt.Fprintf(&b, "- **Progress:** %s\n", progressSummary(progress))
+	b.WriteString("\n## Tasks\n\n")
+	if len(tasks) == 0 {
+		b.WriteString("_No tasks yet._\n")
+	}
+	for _, task := range tasks {
+		b.WriteString(taskChecklistLine(task) + "\n")
+		for _, criterion := range task.AcceptanceCriteria {
+			b.WriteString(acceptanceCriterionLine(criterion) + "\n")
+		}
+	}
+
+	if notes := strings.TrimSpace(plan.Notes); notes != "" {
+		fmt.Fprintf(&b, "\n## Notes\n\n%s\n", markdown.ShiftHeadings(notes, 2))
+	}
+
+	var taskNotes []*models.Task
+	for _, task := range tasks {
+		if strings.TrimSpace(task.Notes) != "" {
+			taskNotes = append(taskNotes, task)
+		}
+	}
+	if len(taskNotes) > 0 {
+		b.WriteString("\n## Task Notes\n")
+		for _, task := range taskNotes {
+			notes := markdown.ShiftHeadings(strings.TrimSpace(task.Notes), 3)
+			fmt.Fprintf(&b, "\n### %s\n\n%s\n", markdown.EscapeInline(task.Title), notes)
+		}
+	}
+
+	fmt.Fprintf(&b, "\n_Report generated %s_\n", now.UTC().Format(reportTimeFormat))
+
+	return b.String()
+}
+
+// progressSummary describes how far a plan has come in one line
+func progressSummary(progress *models.PlanProgress) string {
+	countable := progress.TotalTasks - progress.StatusCounts[models.TaskStatusCancelled]
+	summary := fmt.Sprintf(
+		"%d of %d tasks completed (%s%%)",
+		progress.StatusCounts[models.TaskStatusCompleted],
+		countable,
+		strconv.FormatFloat(progress.PercentComplete, 'f', -1, 64),
+	)
+
+	if inProgress := progress.StatusCounts[models.TaskStatusInProgress]; inProgress > 0 {
+		summary += fmt.Sprintf(", %d in progress", inProgress)
+	}
+	if progress.BlockedTasks > 0 {
+		summary += fmt.Sprintf(", %d blocked", progress.BlockedTasks)
+	}
+	if progress.OverdueTasks > 0 {
+		summary += fmt.Sprintf(", %d overdue", progress.OverdueTasks)
+	}
+	if progress.EscalatedTasks > 0 {
+		summary += fmt.Sprintf(", %d escalated", progress.EscalatedTasks)
+	}
+	if progress.AcceptanceCriteria > 0 {
+		summary += fmt.Sprintf(", %d of %d acceptance criteria met",
+			progress.AcceptanceCriteriaMet, progress.AcceptanceCriteria)
+	}
+	if progress.UnmetCriteriaTasks > 0 {
+		summary += fmt.Sprintf(", %d completed with unmet criteria", progress.UnmetCriteriaTasks)
+	}
+
+	return summary
+}
+
+// taskChecklistLine renders a task as a checklist item with its status and priority
+func taskChecklistLine(task *models.Task) string {
+	checkbox := "[ ]"
+	if task.Status == models.TaskStatusCompleted {
+		checkbox = "[x]"
+	}
+
+	title := markdown.EscapeInline(task.Title)
+	if task.Status == models.TaskStatusCancelled {
+		title = "~~" + title + "~~"
+	}
+
+	details := []string{statusLabel(string(task.Status)), string(task.Priority) + " priority"}
+	if task.DueDate != nil {
+		details = append(details, "due "+task.DueDate.Format(time.DateOnly))
+	}
+	if task.Status == models.TaskStatusPending && IsTaskEscalated(task) {
+		details = append(details, "escalated")
+	}
+
+	return fmt.Sprintf("- %s %s _(%s)_", checkbox, title, strings.Join(details, ", "))
+}
+
+// acceptanceCriterionLine renders an acceptance criterion as a checklist item nested under its task
+func acceptanceCriterionLine(criterion *models.AcceptanceCriterion) string {
+	checkbox := "[ ]"
+	if criterion.Met {
+		checkbox = "[x]"
+	}
+	return fmt.Sprintf("  - %s %s", checkbox, markdown.EscapeInline(criterion.Text))
+}
+
+// statusLabels maps plan and task status values to readable text.
+// Plans and tasks spell "in progress" differently, so both spellings are listed.
+var statusLabels = map[string]string{
+	string(models.PlanStatusInProgress): "in progress",
+	string(models.TaskStatusInProgress): "in progress",
+}
+
+// statusLabel returns the readable text of a plan or task status
+func statusLabel(status string) string {
+	if label, ok := statusLabels[status]; ok {
+		return label
+	}
+	return status
+}
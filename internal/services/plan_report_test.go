@@ -0,0 +1,110 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+func TestRenderPlanMarkdown(t *testing.T) {
+	now := time.Date(2025, time.July, 1, 12, 0, 0, 0, time.UTC)
+	due := now.AddDate(0, 0, 1)
+
+	plan := models.NewPlan("plan-1", "app-1", "Release 1.2", "Ship the release")
+	plan.Status = models.PlanStatusInProgress
+	plan.Notes = "# Decisions\n\nUse feature flags."
+
+	newTask := func(id, title string, status models.TaskStatus, priority models.TaskPriority) *models.Task {
+		task := models.NewTask(id, plan.ID, title, "", priority)
+		task.Status = status
+		return task
+	}
+
+	done := newTask("t1", "Write *docs*", models.TaskStatusCompleted, models.TaskPriorityMedium)
+	doing := newTask("t2", "Update changelog", models.TaskStatusInProgress, models.TaskPriorityHigh)
+	doing.DueDate = &due
+	doing.Notes = "## Open question\n\nWhich format?"
+	dropped := newTask("t3", "Old idea", models.TaskStatusCancelled, models.TaskPriorityLow)
+
+	report := RenderPlanMarkdown(plan, []*models.Task{done, doing, dropped}, now)
+
+	expected := `# Release 1.2
+
+Ship the release
+
+- **Application:** app-1
+- **Status:** in progress
+- **Progress:** 1 of 2 tasks completed (50%), 1 in progress
+
+## Tasks
+
+- [x] Write \*docs\* _(completed, medium priority)_
+- [ ] Update changelog _(in progress, high priority, due 2025-07-02)_
+- [ ] ~~Old idea~~ _(cancelled, low priority)_
+
+## Notes
+
+### Decisions
+
+Use feature flags.
+
+## Task Notes
+
+### Update changelog
+
+##### Open question
+
+Which format?
+
+_Report generated 2025-07-01 12:00 UTC_
+`
+	if report != expected {
+		t.Errorf("RenderPlanMarkdown() =\n%s\nexpected:\n%s", report, expected)
+	}
+}
+
+func TestRenderPlanMarkdownWithoutTasks(t *testing.T) {
+	plan := models.NewPlan("plan-1", "app-1", "Empty", "")
+
+	report := RenderPlanMarkdown(plan, nil, time.Now())
+
+	if !strings.Contains(report, "- **Progress:** 0 of 0 tasks completed (0%)\n") {
+		t.Errorf("expected an empty progress line, got:\n%s", report)
+	}
+	if !strings.Contains(report, "_No tasks yet._") {
+		t.Errorf("expected a no tasks note, got:\n%s", report)
+	}
+	if strings.Contains(report, "## Notes") || strings.Contains(report, "## Task Notes") {
+		t.Errorf("expected no notes sections, got:\n%s", report)
+	}
+}
+
+func TestRenderPlanMarkdownAcceptanceCriteria(t *testing.T) {
+	plan := models.NewPlan("plan-1", "app-1", "Login", "")
+
+	done := models.NewTask("t1", plan.ID, "Add login form", "", models.TaskPriorityMedium)
+	done.Status = models.TaskStatusCompleted
+	done.AcceptanceCriteria = []*models.AcceptanceCriterion{
+		{ID: "c1", Text: "Users can log in", Met: true},
+		{ID: "c2", Text: "Errors are *shown*", Met: false},
+	}
+	dropped := models.NewTask("t2", plan.ID, "Old idea", "", models.TaskPriorityLow)
+	dropped.Status = models.TaskStatusCancelled
+	dropped.AcceptanceCriteria = []*models.AcceptanceCriterion{{ID: "c3", Text: "Never mind"}}
+
+	report := RenderPlanMarkdown(plan, []*models.Task{done, dropped}, time.Now())
+
+	expected := "- [x] Add login form _(completed, medium priority)_\n" +
+		"  - [x] Users can log in\n" +
+		"  - [ ] Errors are \\*shown\\*\n"
+	if !strings.Contains(report, expected) {
+		t.Errorf("expected the criteria nested under their task, got:\n%s", report)
+	}
+	// Criteria of cancelled tasks are listed but not counted
+	summary := "1 of 1 tasks completed (100%), 1 of 2 acceptance criteria met, 1 completed with unmet criteria\n"
+	if !strings.Contains(report, summary) {
+		t.Errorf("expected the criteria in the progress line, got:\n%s", report)
+	}
+}
@@ -0,0 +1,174 @@
+// Package services contains higher level operations built on top of the storage repositories
+package services
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// PlanStatsService computes progress metrics for plans server-side
+type PlanStatsService struct {
+	planRepo storage.PlanRepositoryInterface
+	taskRepo storage.TaskRepositoryInterface
+}
+
+// NewPlanStatsService creates a new plan statistics service
+func NewPlanStatsService(
+	planRepo storage.PlanRepositoryInterface,
+	taskRepo storage.TaskRepositoryInterface,
+) *PlanStatsService {
+	return &PlanStatsService{
+		planRepo: planRepo,
+		taskRepo: taskRepo,
+	}
+}
+
+// GetPlanProgress loads a plan and its tasks and computes the plan's progress metrics
+func (s *PlanStatsService) GetPlanProgress(ctx context.Context, planID string) (*models.PlanProgress, error) {
+	plan, err := s.planRepo.Get(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.taskRepo.ListByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	return ComputePlanProgress(plan, tasks, time.Now()), nil
+}
+
+// ComputePlanProgress computes the progress metrics of a plan from its tasks at the given time
+func ComputePlanProgress(plan *models.Plan, tasks []*models.Task, now time.Time) *models.PlanProgress {
+	progress := &models.PlanProgress{
+		PlanID:         plan.ID,
+		PlanName:       plan.Name,
+		PlanStatus:     plan.Status,
+		TotalTasks:     len(tasks),
+		StatusCounts:   models.NewTaskStatusCounts(),
+		PriorityCounts: models.NewTaskPriorityCounts(),
+		ComputedAt:     now,
+	}
+
+	// Index task statuses to resolve dependencies
+	statusByID := make(map[string]models.TaskStatus, len(tasks))
+	for _, task := range tasks {
+		statusByID[task.ID] = task.Status
+	}
+
+	for _, task := range tasks {
+		progress.StatusCounts[task.Status]++
+		progress.PriorityCounts[task.Priority]++
+
+		if task.Status != models.TaskStatusCancelled {
+			unmet := len(task.UnmetAcceptanceCriteria())
+			progress.AcceptanceCriteria += len(task.AcceptanceCriteria)
+			progress.AcceptanceCriteriaMet += len(task.AcceptanceCriteria) - unmet
+			if task.Status == models.TaskStatusCompleted && unmet > 0 {
+				progress.UnmetCriteriaTasks++
+			}
+		}
+
+		if !task.IsOpen() {
+			continue
+		}
+
+		progress.EstimatedRemainingWork++
+
+		if task.DueDate != nil && task.DueDate.Before(now) {
+			progress.OverdueTasks++
+		}
+		if task.Status == models.TaskStatusPending && IsTaskEscalated(task) {
+			progress.EscalatedTasks++
+		}
+
+		for _, dependencyID := range task.DependsOn {
+			if status, ok := statusByID[dependencyID]; ok && status != models.TaskStatusCompleted {
+				progress.BlockedTasks++
+				break
+			}
+		}
+	}
+
+	// Cancelled tasks don't count towards completion
+	countable := progress.TotalTasks - progress.StatusCounts[models.TaskStatusCancelled]
+	if countable > 0 {
+		percent := float64(progress.StatusCounts[models.TaskStatusCompleted]) / float64(countable) * 100
+		progress.PercentComplete = math.Round(percent*10) / 10
+	}
+
+	computeSchedule(progress, plan, tasks, now)
+
+	return progress
+}
+
+// defaultTasksPerDay is the pace assumed for plans that have not completed tasks for a full day yet
+const defaultTasksPerDay = 1.0
+
+// computeSchedule fills in the schedule metrics of a plan's progress. A deadline is at risk when it has passed
+// with tasks still open, or when the open tasks exceed what the plan can complete in the remaining time at the
+// pace it has completed tasks since it started.
+func computeSchedule(progress *models.PlanProgress, plan *models.Plan, tasks []*models.Task, now time.Time) {
+	progress.StartDate = plan.StartDate
+	progress.TargetDate = plan.TargetDate
+
+	start := plan.CreatedAt
+	if plan.StartDate != nil {
+		start = *plan.StartDate
+	}
+	pace := defaultTasksPerDay
+	if elapsedDays := now.Sub(start).Hours() / 24; elapsedDays >= 1 && progress.StatusCounts[models.TaskStatusCompleted] > 0 {
+		pace = float64(progress.StatusCounts[models.TaskStatusCompleted]) / elapsedDays
+	}
+
+	if plan.TargetDate != nil {
+		daysRemaining := int(math.Ceil(plan.TargetDate.Sub(now).Hours() / 24))
+		progress.DaysRemaining = &daysRemaining
+		progress.AtRisk = deadlineAtRisk(progress.EstimatedRemainingWork, *plan.TargetDate, pace, now)
+	}
+
+	tasksByID := make(map[string]*models.Task, len(tasks))
+	for _, task := range tasks {
+		tasksByID[task.ID] = task
+	}
+
+	for _, milestone := range plan.Milestones {
+		milestoneProgress := models.MilestoneProgress{
+			ID:   milestone.ID,
+			Name: milestone.Name,
+			Date: milestone.Date,
+		}
+		for _, taskID := range milestone.TaskIDs {
+			task, ok := tasksByID[taskID]
+			if !ok || task.Status == models.TaskStatusCancelled {
+				continue
+			}
+			milestoneProgress.TotalTasks++
+			if task.IsOpen() {
+				milestoneProgress.OpenTasks++
+			}
+		}
+		milestoneProgress.Reached = milestoneProgress.TotalTasks > 0 && milestoneProgress.OpenTasks == 0
+		milestoneProgress.AtRisk = deadlineAtRisk(milestoneProgress.OpenTasks, milestone.Date, pace, now)
+
+		// A plan with a milestone at risk is at risk itself
+		progress.AtRisk = progress.AtRisk || milestoneProgress.AtRisk
+		progress.Milestones = append(progress.Milestones, milestoneProgress)
+	}
+}
+
+// deadlineAtRisk reports whether the open tasks are unlikely to be completed by the deadline at the given pace
+func deadlineAtRisk(openTasks int, deadline time.Time, tasksPerDay float64, now time.Time) bool {
+	if openTasks == 0 {
+		return false
+	}
+	remainingDays := deadline.Sub(now).Hours() / 24
+	if remainingDays <= 0 {
+		return true
+	}
+	return float64(openTasks) > tasksPerDay*remainingDays
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+func TestComputePlanProgress(t *testing.T) {
+	now := time.Date(2025, time.July, 1, 12, 0, 0, 0, time.UTC)
+	yesterday := now.AddDate(0, 0, -1)
+	tomorrow := now.AddDate(0, 0, 1)
+
+	plan := models.NewPlan("plan-1", "app-1", "Plan", "Test plan")
+
+	newTask := func(id string, status models.TaskStatus, priority models.TaskPriority) *models.Task {
+		task := models.NewTask(id, plan.ID, id, "", priority)
+		task.Status = status
+		return task
+	}
+
+	done := newTask("done", models.TaskStatusCompleted, models.TaskPriorityHigh)
+	doing := newTask("doing", models.TaskStatusInProgress, models.TaskPriorityHigh)
+	doing.DueDate = &yesterday
+	blocked := newTask("blocked", models.TaskStatusPending, models.TaskPriorityMedium)
+	blocked.DependsOn = []string{doing.ID}
+	unblocked := newTask("unblocked", models.TaskStatusPending, models.TaskPriorityLow)
+	unblocked.DependsOn = []string{done.ID}
+	unblocked.DueDate = &tomorrow
+	dropped := newTask("dropped", models.TaskStatusCancelled, models.TaskPriorityLow)
+	dropped.DueDate = &yesterday
+
+	progress := ComputePlanProgress(plan, []*models.Task{done, doing, blocked, unblocked, dropped}, now)
+
+	if progress.TotalTasks != 5 {
+		t.Errorf("TotalTasks = %d, expected 5", progress.TotalTasks)
+	}
+	if progress.StatusCounts[models.TaskStatusPending] != 2 {
+		t.Errorf("pending count = %d, expected 2", progress.StatusCounts[models.TaskStatusPending])
+	}
+	if progress.PriorityCounts[models.TaskPriorityHigh] != 2 {
+		t.Errorf("high priority count = %d, expected 2", progress.PriorityCounts[models.TaskPriorityHigh])
+	}
+	if progress.PercentComplete != 25 {
+		t.Errorf("PercentComplete = %v, expected 25", progress.PercentComplete)
+	}
+	if progress.BlockedTasks != 1 {
+		t.Errorf("BlockedTasks = %d, expected 1", progress.BlockedTasks)
+	}
+	if progress.OverdueTasks != 1 {
+		t.Errorf("OverdueTasks = %d, expected 1", progress.OverdueTasks)
+	}
+	if progress.EstimatedRemainingWork != 3 {
+		t.Errorf("EstimatedRemainingWork = %d, expected 3", progress.EstimatedRemainingWork)
+	}
+}
+
+func TestComputePlanProgressEmptyPlan(t *testing.T) {
+	plan := models.NewPlan("plan-1", "app-1", "Plan", "Empty plan")
+
+	progress := ComputePlanProgress(plan, nil, time.Now())
+
+	if progress.TotalTasks != 0 || progress.PercentComplete != 0 {
+		t.Errorf("empty plan progress = %+v, expected no tasks and 0%% complete", progress)
+	}
+}
+
+func TestComputePlanProgressSchedule(t *testing.T) {
+	now := time.Date(2025, time.July, 11, 12, 0, 0, 0, time.UTC)
+	start := now.AddDate(0, 0, -10)
+	target := now.AddDate(0, 0, 2)
+
+	plan := models.NewPlan("plan-1", "app-1", "Plan", "Scheduled plan")
+	plan.StartDate = &start
+	plan.TargetDate = &target
+
+	newTask := func(id string, status models.TaskStatus) *models.Task {
+		task := models.NewTask(id, plan.ID, id, "", models.TaskPriorityMedium)
+		task.Status = status
+		return task
+	}
+
+	// Five tasks completed in ten days is a pace of half a task per day
+	var tasks []*models.Task
+	for _, id := range []string{"c1", "c2", "c3", "c4", "c5"} {
+		tasks = append(tasks, newTask(id, models.TaskStatusCompleted))
+	}
+	tasks = append(tasks, newTask("open1", models.TaskStatusPending), newTask("open2", models.TaskStatusInProgress))
+
+	plan.Milestones = []*models.Milestone{
+		models.NewMilestone("done", "Alpha", now.AddDate(0, 0, -1), []string{"c1", "c2"}),
+		models.NewMilestone("late", "Beta", now.AddDate(0, 0, 1), []string{"c3", "open1", "deleted"}),
+	}
+
+	progress := ComputePlanProgress(plan, tasks, now)
+
+	if progress.DaysRemaining == nil || *progress.DaysRemaining != 2 {
+		t.Errorf("DaysRemaining = %v, expected 2", progress.DaysRemaining)
+	}
+	if !progress.AtRisk {
+		t.Error("two open tasks in two days at half a task per day should be at risk")
+	}
+	if len(progress.Milestones) != 2 {
+		t.Fatalf("expected 2 milestones, got %d", len(progress.Milestones))
+	}
+
+	alpha := progress.Milestones[0]
+	if !alpha.Reached || alpha.AtRisk || alpha.TotalTasks != 2 {
+		t.Errorf("unexpected progress of the reached milestone: %+v", alpha)
+	}
+	beta := progress.Milestones[1]
+	if beta.Reached || beta.TotalTasks != 2 || beta.OpenTasks != 1 {
+		t.Errorf("unexpected progress of the open milestone: %+v", beta)
+	}
+	if !beta.AtRisk {
+		t.Error("one open task in one day at half a task per day should be at risk")
+	}
+
+	// With more time left the plan is on track
+	later := now.AddDate(0, 0, 10)
+	plan.TargetDate = &later
+	plan.Milestones = nil
+	if progress := ComputePlanProgress(plan, tasks, now); progress.AtRisk {
+		t.Error("two open tasks in ten days at half a task per day should not be at risk")
+	}
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// ValidatePlan loads a plan and its tasks and runs the given checks on them, so a planning agent can verify the
+// plan before work on it starts. Tasks of other plans that the plan refers to are looked up to tell whether
+// they still exist.
+func (s *PlanStatsService) ValidatePlan(
+	ctx context.Context,
+	planID string,
+	checks []models.PlanCheck,
+) (*models.PlanValidationReport, error) {
+	plan, err := s.planRepo.Get(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.taskRepo.ListByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := make(map[string]bool)
+	if slices.Contains(checks, models.PlanCheckOrphanedReferences) {
+		inPlan := make(map[string]bool, len(tasks))
+		for _, task := range tasks {
+			inPlan[task.ID] = true
+		}
+		for _, id := range referencedTaskIDs(plan, tasks) {
+			if inPlan[id] || missing[id] {
+				continue
+			}
+			_, err := s.taskRepo.Get(ctx, id)
+			switch {
+			case models.ErrorCodeOf(err) == models.ErrorCodeNotFound:
+				missing[id] = true
+			case err != nil:
+				return nil, fmt.Errorf("failed to get referenced task %s: %w", id, err)
+			}
+		}
+	}
+
+	return ComputePlanValidationReport(plan, tasks, missing, checks, time.Now()), nil
+}
+
+// ComputePlanValidationReport runs the given checks on a plan and its tasks at the given time. Missing holds
+// the IDs of the tasks outside the plan that dependencies and links point at but which do not exist.
+func ComputePlanValidationReport(
+	plan *models.Plan,
+	tasks []*models.Task,
+	missing map[string]bool,
+	checks []models.PlanCheck,
+	now time.Time,
+) *models.PlanValidationReport {
+	report := &models.PlanValidationReport{
+		PlanID:     plan.ID,
+		PlanName:   plan.Name,
+		Checks:     checks,
+		Valid:      true,
+		Issues:     []*models.PlanValidationIssue{},
+		ComputedAt: now,
+	}
+
+	for _, check := range checks {
+		switch check {
+		case models.PlanCheckDescriptions:
+			checkDescriptions(report, tasks)
+		case models.PlanCheckDependencyCycles:
+			checkDependencyCycles(report, tasks)
+		case models.PlanCheckEstimates:
+			checkEstimates(report, tasks)
+		case models.PlanCheckOrphanedReferences:
+			checkOrphanedReferences(report, plan, tasks, missing)
+		}
+	}
+	return report
+}
+
+// checkDescriptions flags tasks that are not cancelled and have no description, or only the one bulk-created
+// tasks get by default
+func checkDescriptions(report *models.PlanValidationReport, tasks []*models.Task) {
+	for _, task := range tasks {
+		if task.Status == models.TaskStatusCancelled {
+			continue
+		}
+		description := strings.TrimSpace(task.Description)
+		if description == "" || description == storage.DefaultTaskDescription {
+			report.AddIssue(models.PlanCheckDescriptions, []string{task.ID}, "task %q has no description", task.Title)
+		}
+	}
+}
+
+// checkEstimates flags open tasks without an estimate
+func checkEstimates(report *models.PlanValidationReport, tasks []*models.Task) {
+	for _, task := range tasks {
+		if task.IsOpen() && task.Estimate == 0 {
+			report.AddIssue(models.PlanCheckEstimates, []string{task.ID}, "task %q has no estimate", task.Title)
+		}
+	}
+}
+
+// checkDependencyCycles flags every cycle of dependencies between the tasks of a plan once, listing its tasks
+// in dependency order
+func checkDependencyCycles(report *models.PlanValidationReport, tasks []*models.Task) {
+	byID := make(map[string]*models.Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(tasks))
+	reported := make(map[string]bool)
+	var path []string
+
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = visiting
+		path = append(path, id)
+		for _, dependencyID := range byID[id].DependsOn {
+			if _, ok := byID[dependencyID]; !ok {
+				continue
+			}
+			switch state[dependencyID] {
+			case unvisited:
+				visit(dependencyID)
+			case visiting:
+				cycle := slices.Clone(path[slices.Index(path, dependencyID):])
+				key := slices.Sorted(slices.Values(cycle))
+				if reported[strings.Join(key, ",")] {
+					continue
+				}
+				reported[strings.Join(key, ",")] = true
+
+				titles := make([]string, 0, len(cycle)+1)
+				for _, cycleID := range cycle {
+					titles = append(titles, fmt.Sprintf("%q", byID[cycleID].Title))
+				}
+				titles = append(titles, titles[0])
+				report.AddIssue(models.PlanCheckDependencyCycles, cycle,
+					"tasks depend on each other in a cycle: %s", strings.Join(titles, " -> "))
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+	}
+
+	for _, task := range tasks {
+		if state[task.ID] == unvisited {
+			visit(task.ID)
+		}
+	}
+}
+
+// checkOrphanedReferences flags dependencies, links to tasks and milestones of the plan that point at tasks
+// which do not exist
+func checkOrphanedReferences(
+	report *models.PlanValidationReport,
+	plan *models.Plan,
+	tasks []*models.Task,
+	missing map[string]bool,
+) {
+	inPlan := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		inPlan[task.ID] = true
+	}
+
+	for _, task := range tasks {
+		for _, dependencyID := range task.DependsOn {
+			if missing[dependencyID] {
+				report.AddIssue(models.PlanCheckOrphanedReferences, []string{task.ID},
+					"task %q depends on task %s, which does not exist", task.Title, dependencyID)
+			}
+		}
+		for _, link := range task.Links {
+			if link.IsTaskLink() && missing[link.Target] {
+				report.AddIssue(models.PlanCheckOrphanedReferences, []string{task.ID},
+					"task %q has a %s link to task %s, which does not exist", task.Title, link.Type, link.Target)
+			}
+		}
+	}
+	for _, link := range plan.Links {
+		if link.IsTaskLink() && missing[link.Target] {
+			report.AddIssue(models.PlanCheckOrphanedReferences, nil,
+				"the plan has a %s link to task %s, which does not exist", link.Type, link.Target)
+		}
+	}
+	for _, milestone := range plan.Milestones {
+		for _, taskID := range milestone.TaskIDs {
+			if !inPlan[taskID] {
+				report.AddIssue(models.PlanCheckOrphanedReferences, nil,
+					"milestone %q lists task %s, which is not a task of the plan", milestone.Name, taskID)
+			}
+		}
+	}
+}
+
+// referencedTaskIDs returns the IDs of the tasks a plan and its tasks refer to through dependencies and links
+func referencedTaskIDs(plan *models.Plan, tasks []*models.Task) []string {
+	var ids []string
+	for _, task := range tasks {
+		ids = append(ids, task.DependsOn...)
+		for _, link := range task.Links {
+			if link.IsTaskLink() {
+				ids = append(ids, link.Target)
+			}
+		}
+	}
+	for _, link := range plan.Links {
+		if link.IsTaskLink() {
+			ids = append(ids, link.Target)
+		}
+	}
+	return ids
+}
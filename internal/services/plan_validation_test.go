@@ -0,0 +1,85 @@
+package services
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+func TestComputePlanValidationReport(t *testing.T) {
+	now := time.Date(2025, time.July, 1, 12, 0, 0, 0, time.UTC)
+	plan := models.NewPlan("plan-1", "app-1", "Release", "")
+	plan.Milestones = []*models.Milestone{models.NewMilestone("m1", "Beta", now, []string{"t1", "gone"})}
+
+	newTask := func(id, title, description string, estimate float64, dependsOn ...string) *models.Task {
+		task := models.NewTask(id, plan.ID, title, description, models.TaskPriorityMedium)
+		task.Estimate = estimate
+		task.DependsOn = dependsOn
+		return task
+	}
+	design := newTask("t1", "Design", "Sketch the API", 2, "t3")
+	build := newTask("t2", "Build", storage.DefaultTaskDescription, 0, "t1", "other-plan-task")
+	review := newTask("t3", "Review", "Review the design", 1, "t2", "deleted")
+	review.Links = []*models.Link{models.NewLink(models.LinkTypeRelatesTo, "deleted", "")}
+	dropped := newTask("t4", "Dropped", "", 0)
+	dropped.Status = models.TaskStatusCancelled
+	tasks := []*models.Task{design, build, review, dropped}
+	missing := map[string]bool{"deleted": true}
+
+	report := ComputePlanValidationReport(plan, tasks, missing, models.PlanChecks, now)
+
+	if report.Valid || report.Errors != 4 || report.Warnings != 1 || report.Infos != 1 {
+		t.Fatalf("got valid=%v with %d errors, %d warnings and %d infos, want 4 errors, 1 warning and 1 info: %+v",
+			report.Valid, report.Errors, report.Warnings, report.Infos, report.Issues)
+	}
+
+	issuesOf := func(check models.PlanCheck) []*models.PlanValidationIssue {
+		var issues []*models.PlanValidationIssue
+		for _, issue := range report.Issues {
+			if issue.Check == check {
+				issues = append(issues, issue)
+			}
+		}
+		return issues
+	}
+
+	// The cancelled task needs no description, the bulk-created default does not count as one
+	if issues := issuesOf(models.PlanCheckDescriptions); len(issues) != 1 || issues[0].TaskIDs[0] != "t2" {
+		t.Errorf("description issues = %+v, want one for t2", issues)
+	}
+	cycles := issuesOf(models.PlanCheckDependencyCycles)
+	if len(cycles) != 1 || !slices.Equal(cycles[0].TaskIDs, []string{"t1", "t3", "t2"}) ||
+		cycles[0].Severity != models.ValidationSeverityError {
+		t.Errorf("cycle issues = %+v, want the cycle t1 -> t3 -> t2", cycles)
+	}
+	if issues := issuesOf(models.PlanCheckEstimates); len(issues) != 1 || issues[0].TaskIDs[0] != "t2" {
+		t.Errorf("estimate issues = %+v, want one for t2", issues)
+	}
+	// Tasks of other plans are only orphaned when they are missing
+	orphaned := issuesOf(models.PlanCheckOrphanedReferences)
+	if len(orphaned) != 3 {
+		t.Fatalf("orphaned reference issues = %+v, want the dependency, the link and the milestone", orphaned)
+	}
+	for _, issue := range orphaned {
+		if strings.Contains(issue.Message, "other-plan-task") {
+			t.Errorf("a dependency on an existing task of another plan was flagged: %s", issue.Message)
+		}
+	}
+
+	// Only the selected checks run
+	checks, err := models.ParsePlanChecks([]string{"estimates", "descriptions"})
+	if err != nil {
+		t.Fatalf("failed to parse checks: %v", err)
+	}
+	report = ComputePlanValidationReport(plan, tasks, missing, checks, now)
+	if !report.Valid || report.Warnings != 1 || report.Infos != 1 || len(report.Issues) != 2 {
+		t.Errorf("got %+v, want a valid plan with a warning and an info", report)
+	}
+	if _, err := models.ParsePlanChecks([]string{"spelling"}); models.ErrorCodeOf(err) != models.ErrorCodeValidation {
+		t.Errorf("expected a validation error for an unknown check, got %v", err)
+	}
+}
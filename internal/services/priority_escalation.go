@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+const (
+	// EscalatedAtMetadataKey is the task metadata key flagging an escalated task with the time of its last escalation
+	EscalatedAtMetadataKey = "escalated_at"
+	// EscalatedFromMetadataKey is the task metadata key holding the priority a task had before it was first escalated
+	EscalatedFromMetadataKey = "escalated_from"
+	// EscalationActor is the actor the audit log records for escalations, so watchers are notified of them
+	EscalationActor = "priority-escalation"
+)
+
+// EscalationPolicy configures when pending tasks are escalated and how far
+type EscalationPolicy struct {
+	// After is how long a pending task may go without any change before its priority is raised one step
+	After time.Duration
+	// MaxPriority is the highest priority escalation raises a task to
+	MaxPriority models.TaskPriority
+}
+
+// IsTaskEscalated reports whether a task was flagged by priority escalation
+func IsTaskEscalated(task *models.Task) bool {
+	return task.Metadata[EscalatedAtMetadataKey] != ""
+}
+
+// EscalateTask raises the priority of a pending task that did not change for longer than the policy allows
+// by one step, up to the maximum priority, and flags it as escalated. A task already at the maximum is only
+// flagged. Each escalation is a change, so a neglected task climbs one step per period. It reports whether
+// the task changed.
+func EscalateTask(task *models.Task, policy EscalationPolicy, now time.Time) bool {
+	if task.Status != models.TaskStatusPending || now.Sub(task.UpdatedAt) <= policy.After {
+		return false
+	}
+
+	priority := task.Priority
+	if models.PriorityRank(priority) < models.PriorityRank(policy.MaxPriority) {
+		for _, next := range models.TaskPriorities() {
+			if models.PriorityRank(next) > models.PriorityRank(priority) {
+				priority = next
+				break
+			}
+		}
+	}
+	if priority == task.Priority && IsTaskEscalated(task) {
+		return false
+	}
+
+	if task.Metadata == nil {
+		task.Metadata = make(map[string]string)
+	}
+	if !IsTaskEscalated(task) {
+		task.Metadata[EscalatedFromMetadataKey] = string(task.Priority)
+	}
+	task.Metadata[EscalatedAtMetadataKey] = now.UTC().Format(time.RFC3339)
+	task.Priority = priority
+	return true
+}
+
+// PriorityEscalator raises the priority of pending tasks that were neglected for too long, so they come
+// first when tasks are sorted by priority and stand out in progress reports
+type PriorityEscalator struct {
+	planRepo storage.PlanRepositoryInterface
+	taskRepo storage.TaskRepositoryInterface
+	policy   EscalationPolicy
+}
+
+// NewPriorityEscalator creates a priority escalator
+func NewPriorityEscalator(
+	planRepo storage.PlanRepositoryInterface,
+	taskRepo storage.TaskRepositoryInterface,
+	policy EscalationPolicy,
+) *PriorityEscalator {
+	return &PriorityEscalator{planRepo: planRepo, taskRepo: taskRepo, policy: policy}
+}
+
+// Run escalates the neglected pending tasks and returns them. Escalations are recorded in the audit log as
+// changes by EscalationActor. Tasks of locked plans and tasks changed since they were listed are skipped, and
+// a task that fails to update is logged and the run continues with the next task.
+func (e *PriorityEscalator) Run(ctx context.Context) ([]*models.Task, error) {
+	tasks, err := e.taskRepo.ListByStatus(ctx, models.TaskStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending tasks: %w", err)
+	}
+
+	ctx = storage.WithActor(ctx, EscalationActor)
+	now := time.Now()
+	var escalated []*models.Task
+	for _, listed := range tasks {
+		if listed.Status != models.TaskStatusPending || now.Sub(listed.UpdatedAt) <= e.policy.After {
+			continue
+		}
+
+		task, from, err := e.escalate(ctx, listed, now)
+		if err != nil {
+			if models.ErrorCodeOf(err) != models.ErrorCodeConflict {
+				log.Printf("Priority escalation failed to update task %s: %v", listed.ID, err)
+			}
+			continue
+		}
+		if task == nil {
+			continue
+		}
+		if from != task.Priority {
+			log.Printf("Priority escalation raised task %s from %s to %s", task.ID, from, task.Priority)
+		}
+		escalated = append(escalated, task)
+	}
+	return escalated, nil
+}
+
+// escalate escalates a listed task under the lock of its plan and returns it with its previous priority. The
+// task is read again under the lock, and nil is returned if it is gone, changed since it was listed, such as
+// by being claimed, or needs no escalation.
+func (e *PriorityEscalator) escalate(
+	ctx context.Context,
+	listed *models.Task,
+	now time.Time,
+) (*models.Task, models.TaskPriority, error) {
+	ctx, unlock, err := e.planRepo.LockPlan(ctx, listed.PlanID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	defer unlock()
+
+	task, err := e.taskRepo.Get(ctx, listed.ID)
+	if err != nil {
+		if models.IsNotFound(err) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	if task.PlanID != listed.PlanID || task.Status != listed.Status || !task.UpdatedAt.Equal(listed.UpdatedAt) {
+		return nil, "", nil
+	}
+
+	from := task.Priority
+	if !EscalateTask(task, e.policy, now) {
+		return nil, "", nil
+	}
+	if err := e.taskRepo.Update(ctx, task); err != nil {
+		return nil, "", err
+	}
+	return task, from, nil
+}
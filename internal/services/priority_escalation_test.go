@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+func TestEscalateTask(t *testing.T) {
+	now := time.Date(2025, time.July, 1, 12, 0, 0, 0, time.UTC)
+	policy := EscalationPolicy{After: 24 * time.Hour, MaxPriority: models.TaskPriorityHigh}
+	task := models.NewTask("task-1", "plan-1", "Task", "", models.TaskPriorityLow)
+	task.UpdatedAt = now.Add(-2 * time.Hour)
+
+	if EscalateTask(task, policy, now) {
+		t.Fatal("expected a recently changed task not to be escalated")
+	}
+
+	// A neglected task climbs one step at a time and remembers where it started
+	task.UpdatedAt = now.Add(-25 * time.Hour)
+	if !EscalateTask(task, policy, now) || task.Priority != models.TaskPriorityMedium {
+		t.Fatalf("expected the task to be escalated to medium, got %s", task.Priority)
+	}
+	task.UpdatedAt = now.Add(-25 * time.Hour)
+	if !EscalateTask(task, policy, now) || task.Priority != models.TaskPriorityHigh {
+		t.Fatalf("expected the task to be escalated to high, got %s", task.Priority)
+	}
+	if !IsTaskEscalated(task) || task.Metadata[EscalatedFromMetadataKey] != string(models.TaskPriorityLow) {
+		t.Errorf("expected the task to be flagged as escalated from low, got %v", task.Metadata)
+	}
+
+	// At the maximum priority the task is left alone
+	if EscalateTask(task, policy, now) {
+		t.Error("expected a task at the maximum priority not to change")
+	}
+
+	// Tasks already at the maximum are flagged, tasks that are not pending are never escalated
+	urgent := models.NewTask("task-2", "plan-1", "Urgent", "", models.TaskPriorityHigh)
+	urgent.UpdatedAt = now.Add(-48 * time.Hour)
+	if !EscalateTask(urgent, policy, now) || urgent.Priority != models.TaskPriorityHigh || !IsTaskEscalated(urgent) {
+		t.Errorf("expected a neglected high priority task to be flagged, got %s with %v", urgent.Priority, urgent.Metadata)
+	}
+	started := models.NewTask("task-3", "plan-1", "Started", "", models.TaskPriorityLow)
+	started.Status = models.TaskStatusInProgress
+	started.UpdatedAt = now.Add(-48 * time.Hour)
+	if EscalateTask(started, policy, now) {
+		t.Error("expected a task in progress not to be escalated")
+	}
+}
+
+// listingTaskRepository runs a change after listing tasks, like a client changing them while escalation runs
+type listingTaskRepository struct {
+	storage.TaskRepositoryInterface
+	afterList func()
+}
+
+func (r *listingTaskRepository) ListByStatus(ctx context.Context, status models.TaskStatus) ([]*models.Task, error) {
+	tasks, err := r.TaskRepositoryInterface.ListByStatus(ctx, status)
+	r.afterList()
+	return tasks, err
+}
+
+func TestPriorityEscalatorSkipsChangedTasks(t *testing.T) {
+	repos := newTestRepos(t)
+	planRepo, taskRepo := repos.planRepo, repos.taskRepo
+	ctx := context.Background()
+
+	plan, err := planRepo.Create(ctx, "app", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	claimed, err := taskRepo.Create(ctx, plan.ID, "Claimed", "", models.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	neglected, err := taskRepo.Create(ctx, plan.ID, "Neglected", "", models.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	// A task claimed after the escalator listed it keeps its claim
+	listing := &listingTaskRepository{TaskRepositoryInterface: taskRepo, afterList: func() {
+		if _, err := taskRepo.ClaimTask(ctx, claimed.ID, "worker-1", time.Hour); err != nil {
+			t.Errorf("failed to claim task: %v", err)
+		}
+	}}
+	escalator := NewPriorityEscalator(planRepo, listing,
+		EscalationPolicy{After: time.Nanosecond, MaxPriority: models.TaskPriorityHigh})
+	escalated, err := escalator.Run(ctx)
+	if err != nil {
+		t.Fatalf("failed to run escalation: %v", err)
+	}
+	if len(escalated) != 1 || escalated[0].ID != neglected.ID || escalated[0].Priority != models.TaskPriorityMedium {
+		t.Errorf("escalated %+v, want only the neglected task at medium priority", escalated)
+	}
+
+	stored, err := taskRepo.Get(ctx, claimed.ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if stored.Status != models.TaskStatusInProgress || stored.Priority != models.TaskPriorityLow {
+		t.Errorf("claimed task is %s with %s priority, want it in progress with low priority", stored.Status, stored.Priority)
+	}
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// RetentionAction is what the retention janitor does with expired plans
+type RetentionAction string
+
+const (
+	// RetentionArchive writes an expired plan to a backup file in the archive directory before deleting it
+	RetentionArchive RetentionAction = "archive"
+	// RetentionDelete deletes expired plans without keeping a copy
+	RetentionDelete RetentionAction = "delete"
+)
+
+const (
+	// RetentionMetadataKey is the plan metadata key that opts a plan out of retention
+	RetentionMetadataKey = "retention"
+	// RetentionKeep is the value of RetentionMetadataKey that keeps a plan regardless of its age
+	RetentionKeep = "keep"
+)
+
+// RetentionPolicy configures when finished plans expire and what happens to them
+type RetentionPolicy struct {
+	// MaxAge is how long a completed or cancelled plan is kept after its last update
+	MaxAge time.Duration
+	Action RetentionAction
+	// ArchiveDir is the directory archived plans are written to
+	ArchiveDir string
+}
+
+// ParseRetentionAction validates the name of a retention action
+func ParseRetentionAction(name string) (RetentionAction, error) {
+	switch action := RetentionAction(name); action {
+	case RetentionArchive, RetentionDelete:
+		return action, nil
+	default:
+		return "", fmt.Errorf("unknown retention action %q, expected %q or %q", name, RetentionArchive, RetentionDelete)
+	}
+}
+
+// RetentionStats counts what the retention janitor expired since the server started
+type RetentionStats struct {
+	Runs          int64      `json:"runs"`
+	PlansArchived int64      `json:"plans_archived"`
+	PlansDeleted  int64      `json:"plans_deleted"`
+	TasksExpired  int64      `json:"tasks_expired"`
+	Failures      int64      `json:"failures"`
+	LastRunAt     *time.Time `json:"last_run_at,omitempty"`
+}
+
+// RetentionJanitor archives or deletes completed and cancelled plans once they are older than the
+// retention policy allows. Locked plans and plans whose retention metadata is set to keep are never expired.
+type RetentionJanitor struct {
+	planRepo storage.PlanRepositoryInterface
+	taskRepo storage.TaskRepositoryInterface
+	backup   *BackupService
+	policy   RetentionPolicy
+
+	mu    sync.Mutex
+	stats RetentionStats
+}
+
+// NewRetentionJanitor creates a retention janitor
+func NewRetentionJanitor(
+	planRepo storage.PlanRepositoryInterface,
+	taskRepo storage.TaskRepositoryInterface,
+	policy RetentionPolicy,
+) *RetentionJanitor {
+	return &RetentionJanitor{
+		planRepo: planRepo,
+		taskRepo: taskRepo,
+		backup:   NewBackupService(planRepo, taskRepo),
+		policy:   policy,
+	}
+}
+
+// IsPlanExpired reports whether a plan is finished, not opted out or locked and was last updated more than maxAge before now
+func IsPlanExpired(plan *models.Plan, maxAge time.Duration, now time.Time) bool {
+	if plan.Status != models.PlanStatusCompleted && plan.Status != models.PlanStatusCancelled {
+		return false
+	}
+	if plan.Metadata[RetentionMetadataKey] == RetentionKeep || plan.Lock != nil {
+		return false
+	}
+	return plan.UpdatedAt.Before(now.Add(-maxAge))
+}
+
+// Run expires the plans that are past the retention policy and returns their IDs.
+// A plan that fails to expire is counted as a failure and the run continues with the next plan.
+func (j *RetentionJanitor) Run(ctx context.Context) ([]string, error) {
+	now := time.Now()
+
+	var candidates []*models.Plan
+	for _, status := range []models.PlanStatus{models.PlanStatusCompleted, models.PlanStatusCancelled} {
+		plans, err := j.planRepo.ListByStatus(ctx, status)
+		if err != nil {
+			j.record(now, func(stats *RetentionStats) { stats.Failures++ })
+			return nil, fmt.Errorf("failed to list %s plans: %w", status, err)
+		}
+		candidates = append(candidates, plans...)
+	}
+
+	var expired []string
+	var failures int64
+	for _, plan := range candidates {
+		if !IsPlanExpired(plan, j.policy.MaxAge, now) {
+			continue
+		}
+
+		tasks, err := j.expirePlan(ctx, plan.ID)
+		if err != nil {
+			log.Printf("Retention failed to expire plan %s: %v", plan.ID, err)
+			failures++
+			continue
+		}
+
+		expired = append(expired, plan.ID)
+		j.record(now, func(stats *RetentionStats) {
+			if j.policy.Action == RetentionArchive {
+				stats.PlansArchived++
+			} else {
+				stats.PlansDeleted++
+			}
+			stats.TasksExpired += int64(tasks)
+		})
+	}
+
+	j.record(now, func(stats *RetentionStats) {
+		stats.Runs++
+		stats.Failures += failures
+	})
+	return expired, nil
+}
+
+// expirePlan archives a plan if the policy asks for it, deletes it and returns the number of its tasks
+func (j *RetentionJanitor) expirePlan(ctx context.Context, planID string) (int, error) {
+	backup, err := j.backup.ExportPlan(ctx, planID)
+	if err != nil {
+		return 0, err
+	}
+
+	if j.policy.Action == RetentionArchive {
+		if err := j.archive(backup); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := j.planRepo.Delete(ctx, planID); err != nil {
+		return 0, err
+	}
+	return len(backup.Tasks), nil
+}
+
+// archive writes a plan backup to the archive directory, in the format the import command reads
+func (j *RetentionJanitor) archive(backup *models.PlanResource) error {
+	_, err := WritePlanBackup(j.policy.ArchiveDir, backup)
+	return err
+}
+
+// record updates the stats of the janitor
+func (j *RetentionJanitor) record(runAt time.Time, update func(stats *RetentionStats)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	update(&j.stats)
+	j.stats.LastRunAt = &runAt
+}
+
+// Stats returns what the janitor expired so far
+func (j *RetentionJanitor) Stats() RetentionStats {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	stats := j.stats
+	if stats.LastRunAt != nil {
+		lastRunAt := *stats.LastRunAt
+		stats.LastRunAt = &lastRunAt
+	}
+	return stats
+}
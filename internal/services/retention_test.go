@@ -0,0 +1,61 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+func TestIsPlanExpired(t *testing.T) {
+	now := time.Date(2025, time.July, 1, 12, 0, 0, 0, time.UTC)
+	maxAge := 30 * 24 * time.Hour
+
+	plan := func(status models.PlanStatus, updatedAt time.Time, metadata map[string]string) *models.Plan {
+		plan := models.NewPlan("plan-1", "app-1", "Plan", "")
+		plan.Status = status
+		plan.UpdatedAt = updatedAt
+		plan.Metadata = metadata
+		return plan
+	}
+	old := now.AddDate(0, 0, -31)
+	recent := now.AddDate(0, 0, -29)
+
+	tests := []struct {
+		name string
+		plan *models.Plan
+		want bool
+	}{
+		{"old completed plan", plan(models.PlanStatusCompleted, old, nil), true},
+		{"old cancelled plan", plan(models.PlanStatusCancelled, old, nil), true},
+		{"recent completed plan", plan(models.PlanStatusCompleted, recent, nil), false},
+		{"old plan in progress", plan(models.PlanStatusInProgress, old, nil), false},
+		{"old new plan", plan(models.PlanStatusNew, old, nil), false},
+		{"opted out", plan(models.PlanStatusCompleted, old, map[string]string{RetentionMetadataKey: RetentionKeep}), false},
+		{"other retention value", plan(models.PlanStatusCompleted, old, map[string]string{RetentionMetadataKey: "default"}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPlanExpired(tt.plan, maxAge, now); got != tt.want {
+				t.Errorf("IsPlanExpired() = %v, expected %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRetentionAction(t *testing.T) {
+	for _, name := range []string{"archive", "delete"} {
+		action, err := ParseRetentionAction(name)
+		if err != nil {
+			t.Fatalf("ParseRetentionAction(%q) failed: %v", name, err)
+		}
+		if string(action) != name {
+			t.Errorf("ParseRetentionAction(%q) = %q", name, action)
+		}
+	}
+
+	if _, err := ParseRetentionAction("purge"); err == nil {
+		t.Error("ParseRetentionAction(\"purge\") should fail")
+	}
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// DefaultStaleThreshold is how long work in progress may go without any change before it is considered stale
+const DefaultStaleThreshold = time.Hour
+
+// ListStaleTasks finds the in-progress tasks, of one application or all if it is empty, that look abandoned
+// at the given threshold
+func (s *PlanStatsService) ListStaleTasks(
+	ctx context.Context,
+	applicationID string,
+	threshold time.Duration,
+) ([]*models.StaleTask, error) {
+	var tasks []*models.Task
+	var err error
+	if applicationID != "" {
+		tasks, err = s.taskRepo.ListByApplicationAndStatus(ctx, applicationID, models.TaskStatusInProgress)
+	} else {
+		tasks, err = s.taskRepo.ListByStatus(ctx, models.TaskStatusInProgress)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return FindStaleTasks(tasks, threshold, time.Now()), nil
+}
+
+// FindStaleTasks returns the in-progress tasks that look abandoned at the given time, longest idle first.
+// A claimed task is stale once its lease expired, however recently it changed, and never while its lease is
+// held. Other tasks are stale when they did not change for longer than the threshold.
+func FindStaleTasks(tasks []*models.Task, threshold time.Duration, now time.Time) []*models.StaleTask {
+	stale := make([]*models.StaleTask, 0)
+	for _, task := range tasks {
+		if task.Status != models.TaskStatusInProgress {
+			continue
+		}
+
+		reason := models.StaleReasonIdle
+		switch {
+		case task.LeaseExpiresAt != nil && !task.LeaseExpiresAt.After(now):
+			reason = models.StaleReasonLeaseExpired
+		case task.LeaseExpiresAt != nil, now.Sub(task.UpdatedAt) <= threshold:
+			continue
+		}
+
+		stale = append(stale, &models.StaleTask{
+			Task:           task,
+			Reason:         reason,
+			LastActivityAt: task.UpdatedAt,
+			IdleSeconds:    int64(now.Sub(task.UpdatedAt).Seconds()),
+		})
+	}
+
+	slices.SortStableFunc(stale, func(a, b *models.StaleTask) int {
+		return a.LastActivityAt.Compare(b.LastActivityAt)
+	})
+	return stale
+}
+
+// ListStalePlans finds the in-progress plans, of one application or all if it is empty, that did not change
+// for longer than the threshold
+func (s *PlanStatsService) ListStalePlans(
+	ctx context.Context,
+	applicationID string,
+	threshold time.Duration,
+) ([]*models.StalePlan, error) {
+	plans, err := s.planRepo.ListByStatus(ctx, models.PlanStatusInProgress)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	stale := make([]*models.StalePlan, 0)
+	for _, plan := range plans {
+		if applicationID != "" && plan.ApplicationID != applicationID {
+			continue
+		}
+
+		tasks, err := s.taskRepo.ListByPlan(ctx, plan.ID)
+		if err != nil {
+			return nil, err
+		}
+		if stalePlan := FindStalePlan(plan, tasks, threshold, now); stalePlan != nil {
+			stale = append(stale, stalePlan)
+		}
+	}
+
+	slices.SortStableFunc(stale, func(a, b *models.StalePlan) int {
+		return a.LastActivityAt.Compare(b.LastActivityAt)
+	})
+	return stale, nil
+}
+
+// FindStalePlan reports an in-progress plan as stale at the given time if neither it nor any of its tasks
+// changed for longer than the threshold, and returns nil otherwise
+func FindStalePlan(plan *models.Plan, tasks []*models.Task, threshold time.Duration, now time.Time) *models.StalePlan {
+	if plan.Status != models.PlanStatusInProgress {
+		return nil
+	}
+
+	lastActivity := plan.UpdatedAt
+	inProgress := 0
+	for _, task := range tasks {
+		if task.UpdatedAt.After(lastActivity) {
+			lastActivity = task.UpdatedAt
+		}
+		if task.Status == models.TaskStatusInProgress {
+			inProgress++
+		}
+	}
+
+	if now.Sub(lastActivity) <= threshold {
+		return nil
+	}
+	return &models.StalePlan{
+		Plan:            plan,
+		LastActivityAt:  lastActivity,
+		IdleSeconds:     int64(now.Sub(lastActivity).Seconds()),
+		InProgressTasks: inProgress,
+	}
+}
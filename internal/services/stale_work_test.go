@@ -0,0 +1,88 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+func TestFindStaleTasks(t *testing.T) {
+	now := time.Date(2025, time.July, 1, 12, 0, 0, 0, time.UTC)
+	task := func(id string, status models.TaskStatus, idle time.Duration, leaseLeft *time.Duration) *models.Task {
+		task := models.NewTask(id, "plan-1", id, "", models.TaskPriorityMedium)
+		task.Status = status
+		task.UpdatedAt = now.Add(-idle)
+		if leaseLeft != nil {
+			expiresAt := now.Add(*leaseLeft)
+			task.LeaseOwner = "worker-1"
+			task.LeaseExpiresAt = &expiresAt
+		}
+		return task
+	}
+	held, expired := 5*time.Minute, -time.Minute
+
+	tasks := []*models.Task{
+		task("recent", models.TaskStatusInProgress, 10*time.Minute, nil),
+		task("idle", models.TaskStatusInProgress, 2*time.Hour, nil),
+		task("idler", models.TaskStatusInProgress, 3*time.Hour, nil),
+		task("pending", models.TaskStatusPending, 5*time.Hour, nil),
+		task("leased", models.TaskStatusInProgress, 4*time.Hour, &held),
+		task("expired", models.TaskStatusInProgress, 2*time.Minute, &expired),
+	}
+
+	stale := FindStaleTasks(tasks, time.Hour, now)
+	if len(stale) != 3 {
+		t.Fatalf("expected 3 stale tasks, got %d", len(stale))
+	}
+
+	want := []struct {
+		id     string
+		reason string
+	}{
+		{"idler", models.StaleReasonIdle},
+		{"idle", models.StaleReasonIdle},
+		{"expired", models.StaleReasonLeaseExpired},
+	}
+	for i, w := range want {
+		if stale[i].Task.ID != w.id || stale[i].Reason != w.reason {
+			t.Errorf("stale task %d = %s (%s), want %s (%s)", i, stale[i].Task.ID, stale[i].Reason, w.id, w.reason)
+		}
+	}
+	if stale[0].IdleSeconds != int64((3 * time.Hour).Seconds()) {
+		t.Errorf("IdleSeconds = %d, want %d", stale[0].IdleSeconds, int64((3 * time.Hour).Seconds()))
+	}
+}
+
+func TestFindStalePlan(t *testing.T) {
+	now := time.Date(2025, time.July, 1, 12, 0, 0, 0, time.UTC)
+	plan := models.NewPlan("plan-1", "app-1", "Plan", "")
+	plan.Status = models.PlanStatusInProgress
+	plan.UpdatedAt = now.Add(-5 * time.Hour)
+
+	running := models.NewTask("running", plan.ID, "Running", "", models.TaskPriorityMedium)
+	running.Status = models.TaskStatusInProgress
+	running.UpdatedAt = now.Add(-3 * time.Hour)
+	pending := models.NewTask("pending", plan.ID, "Pending", "", models.TaskPriorityMedium)
+	pending.UpdatedAt = now.Add(-4 * time.Hour)
+
+	stale := FindStalePlan(plan, []*models.Task{running, pending}, time.Hour, now)
+	if stale == nil {
+		t.Fatal("expected the plan to be stale")
+	}
+	if !stale.LastActivityAt.Equal(running.UpdatedAt) || stale.InProgressTasks != 1 {
+		t.Errorf("got last activity %v with %d tasks in progress, want %v with 1",
+			stale.LastActivityAt, stale.InProgressTasks, running.UpdatedAt)
+	}
+
+	// A recent change of any task keeps the plan active
+	pending.UpdatedAt = now.Add(-10 * time.Minute)
+	if FindStalePlan(plan, []*models.Task{running, pending}, time.Hour, now) != nil {
+		t.Error("a plan with a recently changed task should not be stale")
+	}
+
+	plan.Status = models.PlanStatusNew
+	if FindStalePlan(plan, nil, time.Hour, now) != nil {
+		t.Error("only plans in progress can be stale")
+	}
+}
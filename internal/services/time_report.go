@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// GetPlanTimeReport loads a plan and its tasks and summarizes the time spent on them
+func (s *PlanStatsService) GetPlanTimeReport(ctx context.Context, planID string) (*models.PlanTimeReport, error) {
+	plan, err := s.planRepo.Get(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.taskRepo.ListByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	return ComputePlanTimeReport(plan, tasks, time.Now()), nil
+}
+
+// ComputePlanTimeReport summarizes the time spent per task and for the whole plan at the given time.
+// Tasks that are currently in progress include their running interval.
+func ComputePlanTimeReport(plan *models.Plan, tasks []*models.Task, now time.Time) *models.PlanTimeReport {
+	report := &models.PlanTimeReport{
+		PlanID:     plan.ID,
+		PlanName:   plan.Name,
+		Tasks:      make([]*models.TaskTimeEntry, 0, len(tasks)),
+		ComputedAt: now,
+	}
+
+	for _, task := range tasks {
+		spent := int64(task.TotalTimeSpent(now).Seconds())
+		report.TotalTimeSpent += spent
+		report.Tasks = append(report.Tasks, &models.TaskTimeEntry{
+			TaskID:      task.ID,
+			Title:       task.Title,
+			Status:      task.Status,
+			StartedAt:   task.StartedAt,
+			CompletedAt: task.CompletedAt,
+			TimeSpent:   spent,
+		})
+	}
+
+	return report
+}
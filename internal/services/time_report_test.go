@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+func TestComputePlanTimeReport(t *testing.T) {
+	start := time.Date(2025, time.July, 1, 9, 0, 0, 0, time.UTC)
+	plan := models.NewPlan("plan-1", "app-1", "Plan", "Test plan")
+
+	// Worked on for 30 minutes, then completed
+	done := models.NewTask("done", plan.ID, "Done", "", models.TaskPriorityMedium)
+	done.Status = models.TaskStatusInProgress
+	done.RecordStatusTransition(models.TaskStatusPending, start)
+	done.Status = models.TaskStatusCompleted
+	done.RecordStatusTransition(models.TaskStatusInProgress, start.Add(30*time.Minute))
+
+	// Started 10 minutes before the report with 5 minutes logged explicitly
+	running := models.NewTask("running", plan.ID, "Running", "", models.TaskPriorityMedium)
+	running.TimeSpent = 300
+	running.Status = models.TaskStatusInProgress
+	running.RecordStatusTransition(models.TaskStatusPending, start.Add(50*time.Minute))
+
+	pending := models.NewTask("pending", plan.ID, "Pending", "", models.TaskPriorityMedium)
+
+	report := ComputePlanTimeReport(plan, []*models.Task{done, running, pending}, start.Add(time.Hour))
+
+	if len(report.Tasks) != 3 {
+		t.Fatalf("expected 3 task entries, got %d", len(report.Tasks))
+	}
+
+	expected := map[string]int64{"done": 1800, "running": 900, "pending": 0}
+	for _, entry := range report.Tasks {
+		if entry.TimeSpent != expected[entry.TaskID] {
+			t.Errorf("task %s: TimeSpent = %d, want %d", entry.TaskID, entry.TimeSpent, expected[entry.TaskID])
+		}
+	}
+
+	if report.TotalTimeSpent != 2700 {
+		t.Errorf("TotalTimeSpent = %d, want 2700", report.TotalTimeSpent)
+	}
+
+	if report.Tasks[0].StartedAt == nil || !report.Tasks[0].StartedAt.Equal(start) {
+		t.Errorf("expected completed task to keep its start time, got %v", report.Tasks[0].StartedAt)
+	}
+	if report.Tasks[0].CompletedAt == nil {
+		t.Errorf("expected completed task to have a completion time")
+	}
+}
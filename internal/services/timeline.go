@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// TimelineService assembles the event timelines of plans from the audit log
+type TimelineService struct {
+	planRepo storage.PlanRepositoryInterface
+	taskRepo storage.TaskRepositoryInterface
+	auditLog *storage.AuditLog
+}
+
+// NewTimelineService creates a new timeline service
+func NewTimelineService(
+	planRepo storage.PlanRepositoryInterface,
+	taskRepo storage.TaskRepositoryInterface,
+	auditLog *storage.AuditLog,
+) *TimelineService {
+	return &TimelineService{
+		planRepo: planRepo,
+		taskRepo: taskRepo,
+		auditLog: auditLog,
+	}
+}
+
+// GetPlanTimeline returns the events of a plan and its current tasks, oldest first. Task history from
+// before a task was moved into the plan is left out. Deleted tasks can no longer be found from the plan
+// and are left out too, as is any history the retention policy dropped.
+func (s *TimelineService) GetPlanTimeline(ctx context.Context, planID string) ([]*models.TimelineEvent, error) {
+	// Also checks that the plan exists and is within the application scope
+	if _, err := s.planRepo.Get(ctx, planID); err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.taskRepo.ListByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.auditLog.History(ctx, models.EntityTypePlan, planID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, task := range tasks {
+		taskEntries, err := s.auditLog.History(ctx, models.EntityTypeTask, task.ID, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range taskEntries {
+			if models.SnapshotPlanID(entry.Before) == planID || models.SnapshotPlanID(entry.After) == planID {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	return models.NewTimeline(entries), nil
+}
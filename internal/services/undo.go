@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// UndoResult describes a change that was reverted
+type UndoResult struct {
+	// UndoneEntry is the audit entry that was reverted
+	UndoneEntry *models.AuditEntry `json:"undone_entry"`
+	// Deleted is true when undoing a create removed the entity
+	Deleted bool `json:"deleted"`
+	// Plan or Task holds the restored state of the entity
+	Plan *models.Plan `json:"plan,omitempty"`
+	Task *models.Task `json:"task,omitempty"`
+}
+
+// UndoService reverts plans and tasks to the snapshots recorded in the audit log
+type UndoService struct {
+	planRepo storage.PlanRepositoryInterface
+	taskRepo storage.TaskRepositoryInterface
+	auditLog *storage.AuditLog
+}
+
+// NewUndoService creates a new undo service.
+// The repositories should be the audited ones so that the undo itself is recorded.
+func NewUndoService(
+	planRepo storage.PlanRepositoryInterface,
+	taskRepo storage.TaskRepositoryInterface,
+	auditLog *storage.AuditLog,
+) *UndoService {
+	return &UndoService{
+		planRepo: planRepo,
+		taskRepo: taskRepo,
+		auditLog: auditLog,
+	}
+}
+
+// UndoLastChange reverts the most recent recorded change of a plan or task.
+// If expectedEntryID is set, the most recent change must be that entry. Unless force is set, the entity's current
+// state must also match the state recorded after the change, so changes made since are never silently overwritten.
+func (s *UndoService) UndoLastChange(
+	ctx context.Context,
+	entityType models.EntityType,
+	entityID, expectedEntryID string,
+	force bool,
+) (*UndoResult, error) {
+	entry, err := s.lastChange(ctx, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check and restore the entity under the lock of its plan, so no change slips in between
+	planID, err := entryPlanID(entry)
+	if err != nil {
+		return nil, err
+	}
+	ctx, unlock, err := s.planRepo.LockPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	locked, err := s.lastChange(ctx, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if locked.ID != entry.ID {
+		return nil, models.NewConflictError(
+			string(entityType), entityID, "%s %s changed while undoing its last change, try again", entityType, entityID,
+		)
+	}
+
+	if expectedEntryID != "" && entry.ID != expectedEntryID {
+		return nil, models.NewConflictError(
+			string(entityType), entityID, "the last change of %s %s is %s, not %s",
+			entityType, entityID, entry.ID, expectedEntryID,
+		)
+	}
+
+	if !force {
+		err = s.checkConflict(ctx, entry)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &UndoResult{UndoneEntry: entry}
+
+	switch entityType {
+	case models.EntityTypePlan:
+		err = s.undoPlan(ctx, entry, result)
+	case models.EntityTypeTask:
+		err = s.undoTask(ctx, entry, result)
+	default:
+		err = fmt.Errorf("unsupported entity type: %s", entityType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// lastChange returns the most recent recorded change of a plan or task
+func (s *UndoService) lastChange(
+	ctx context.Context,
+	entityType models.EntityType,
+	entityID string,
+) (*models.AuditEntry, error) {
+	entries, err := s.auditLog.History(ctx, entityType, entityID, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, models.NewNotFoundError(models.EntityRecordedChange, fmt.Sprintf("%s:%s", entityType, entityID))
+	}
+	return entries[0], nil
+}
+
+// entryPlanID returns the ID of the plan a recorded change belongs to, taken from the snapshots of tasks
+func entryPlanID(entry *models.AuditEntry) (string, error) {
+	switch entry.EntityType {
+	case models.EntityTypePlan:
+		return entry.EntityID, nil
+	case models.EntityTypeTask:
+		snapshot := entry.Before
+		if len(snapshot) == 0 {
+			snapshot = entry.After
+		}
+		task := &models.Task{}
+		if err := json.Unmarshal(snapshot, task); err != nil {
+			return "", fmt.Errorf("failed to parse task snapshot: %w", err)
+		}
+		return task.PlanID, nil
+	default:
+		return "", fmt.Errorf("unsupported entity type: %s", entry.EntityType)
+	}
+}
+
+// undoPlan reverts a plan change
+func (s *UndoService) undoPlan(ctx context.Context, entry *models.AuditEntry, result *UndoResult) error {
+	if entry.Action == models.AuditActionCreate {
+		result.Deleted = true
+		return s.planRepo.Delete(ctx, entry.EntityID)
+	}
+
+	plan := &models.Plan{}
+	if err := json.Unmarshal(entry.Before, plan); err != nil {
+		return fmt.Errorf("failed to parse plan snapshot: %w", err)
+	}
+
+	err := s.planRepo.Restore(ctx, plan)
+	if err != nil {
+		return fmt.Errorf("failed to restore plan: %w", err)
+	}
+
+	result.Plan, err = s.planRepo.Get(ctx, plan.ID)
+	return err
+}
+
+// undoTask reverts a task change
+func (s *UndoService) undoTask(ctx context.Context, entry *models.AuditEntry, result *UndoResult) error {
+	if entry.Action == models.AuditActionCreate {
+		result.Deleted = true
+		return s.taskRepo.Delete(ctx, entry.EntityID)
+	}
+
+	task := &models.Task{}
+	if err := json.Unmarshal(entry.Before, task); err != nil {
+		return fmt.Errorf("failed to parse task snapshot: %w", err)
+	}
+
+	err := s.taskRepo.Restore(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to restore task: %w", err)
+	}
+
+	result.Task, err = s.taskRepo.Get(ctx, task.ID)
+	return err
+}
+
+// checkConflict verifies that the entity has not changed since the audit entry was recorded
+func (s *UndoService) checkConflict(ctx context.Context, entry *models.AuditEntry) error {
+	current, err := s.currentSnapshot(ctx, entry.EntityType, entry.EntityID)
+	if err != nil {
+		return err
+	}
+
+	if entry.Action == models.AuditActionDelete {
+		if current != nil {
+			return models.NewConflictError(string(entry.EntityType), entry.EntityID,
+				"%s %s was recreated after it was deleted", entry.EntityType, entry.EntityID)
+		}
+		return nil
+	}
+
+	if current == nil {
+		return models.NewConflictError(string(entry.EntityType), entry.EntityID,
+			"%s %s no longer exists", entry.EntityType, entry.EntityID)
+	}
+
+	changes, err := models.DiffSnapshots(entry.After, current)
+	if err != nil {
+		return err
+	}
+	if len(changes) > 0 {
+		fields := make([]string, 0, len(changes))
+		for field := range changes {
+			fields = append(fields, field)
+		}
+		slices.Sort(fields)
+		return models.NewConflictError(
+			string(entry.EntityType), entry.EntityID,
+			"%s %s changed since the last recorded change (fields: %s); use force to undo anyway",
+			entry.EntityType, entry.EntityID, strings.Join(fields, ", "),
+		)
+	}
+
+	return nil
+}
+
+// currentSnapshot returns the JSON snapshot of the current state of an entity, or nil if it does not exist.
+// Other failures to read the entity are returned, as they say nothing about whether it exists.
+func (s *UndoService) currentSnapshot(
+	ctx context.Context,
+	entityType models.EntityType,
+	entityID string,
+) (json.RawMessage, error) {
+	var entity any
+	switch entityType {
+	case models.EntityTypePlan:
+		plan, err := s.planRepo.Get(ctx, entityID)
+		if models.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		entity = plan
+	case models.EntityTypeTask:
+		task, err := s.taskRepo.Get(ctx, entityID)
+		if models.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		entity = task
+	default:
+		return nil, fmt.Errorf("unsupported entity type: %s", entityType)
+	}
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot %s %s: %w", entityType, entityID, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+func newUndoTest(t *testing.T) (*UndoService, storage.PlanRepositoryInterface, storage.TaskRepositoryInterface) {
+	t.Helper()
+	repos := newTestRepos(t)
+	planRepo, taskRepo := repos.audited()
+	return NewUndoService(planRepo, taskRepo, repos.auditLog), planRepo, taskRepo
+}
+
+func TestUndoCreate(t *testing.T) {
+	undo, planRepo, taskRepo := newUndoTest(t)
+	ctx := context.Background()
+
+	// The creation snapshots match the stored timestamps, so undoing a create needs no force
+	plan, err := planRepo.Create(ctx, "app", "Accidental plan", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	task, err := taskRepo.Create(ctx, plan.ID, "Accidental task", "", models.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	result, err := undo.UndoLastChange(ctx, models.EntityTypeTask, task.ID, "", false)
+	if err != nil {
+		t.Fatalf("failed to undo the task creation: %v", err)
+	}
+	if !result.Deleted {
+		t.Error("undoing a task creation should delete the task")
+	}
+
+	result, err = undo.UndoLastChange(ctx, models.EntityTypePlan, plan.ID, "", false)
+	if err != nil {
+		t.Fatalf("failed to undo the plan creation: %v", err)
+	}
+	if !result.Deleted {
+		t.Error("undoing a plan creation should delete the plan")
+	}
+}
+
+func TestUndoConflicts(t *testing.T) {
+	repos := newTestRepos(t)
+	planRepo, taskRepo := repos.audited()
+	undo := NewUndoService(planRepo, taskRepo, repos.auditLog)
+	ctx := context.Background()
+
+	plan, err := planRepo.Create(ctx, "app", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	task, err := taskRepo.Create(ctx, plan.ID, "Task", "", models.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	task.Title = "Renamed"
+	if err := taskRepo.Update(ctx, task); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	// An unexpected entry ID is a conflict
+	_, err = undo.UndoLastChange(ctx, models.EntityTypeTask, task.ID, "0-1", false)
+	if models.ErrorCodeOf(err) != models.ErrorCodeConflict {
+		t.Errorf("expected a conflict for an unexpected entry ID, got %v", err)
+	}
+
+	// So is a change made since the last recorded one, unless forced
+	unaudited := repos.taskRepo
+	task.Description = "Changed without the audit log"
+	if err := unaudited.Update(ctx, task); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	_, err = undo.UndoLastChange(ctx, models.EntityTypeTask, task.ID, "", false)
+	if models.ErrorCodeOf(err) != models.ErrorCodeConflict {
+		t.Errorf("expected a conflict for a changed task, got %v", err)
+	}
+	result, err := undo.UndoLastChange(ctx, models.EntityTypeTask, task.ID, "", true)
+	if err != nil {
+		t.Fatalf("failed to force the undo: %v", err)
+	}
+	if result.Task.Title != "Task" {
+		t.Errorf("title = %q, want the title before the update", result.Task.Title)
+	}
+
+	// And a task deleted since
+	if err := unaudited.Delete(ctx, task.ID); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+	_, err = undo.UndoLastChange(ctx, models.EntityTypeTask, task.ID, "", false)
+	if models.ErrorCodeOf(err) != models.ErrorCodeConflict {
+		t.Errorf("expected a conflict for a deleted task, got %v", err)
+	}
+}
+
+func TestUndoWithoutChanges(t *testing.T) {
+	undo, _, _ := newUndoTest(t)
+
+	_, err := undo.UndoLastChange(context.Background(), models.EntityTypeTask, "task-1", "", false)
+	if models.ErrorCodeOf(err) != models.ErrorCodeNotFound {
+		t.Fatalf("expected a NOT_FOUND error for an entity without recorded changes, got %v", err)
+	}
+	structured := models.AsError(err, "")
+	if structured.Entity != models.EntityRecordedChange || structured.ID != "task:task-1" {
+		t.Errorf("error is about %s %s, want the recorded changes of task task-1", structured.Entity, structured.ID)
+	}
+}
+
+// failingTaskRepository fails to read tasks, like a storage that lost its connection
+type failingTaskRepository struct {
+	storage.TaskRepositoryInterface
+}
+
+func (r *failingTaskRepository) Get(context.Context, string) (*models.Task, error) {
+	return nil, errors.New("connection lost")
+}
+
+func TestUndoReadFailure(t *testing.T) {
+	repos := newTestRepos(t)
+	planRepo, taskRepo := repos.audited()
+	ctx := context.Background()
+
+	plan, err := planRepo.Create(ctx, "app", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	task, err := taskRepo.Create(ctx, plan.ID, "Task", "", models.TaskPriorityLow)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	// A task that cannot be read is not taken for a deleted one
+	undo := NewUndoService(planRepo, &failingTaskRepository{taskRepo}, repos.auditLog)
+	_, err = undo.UndoLastChange(ctx, models.EntityTypeTask, task.ID, "", false)
+	if err == nil || !strings.Contains(err.Error(), "connection lost") {
+		t.Errorf("expected the read failure, got %v", err)
+	}
+}
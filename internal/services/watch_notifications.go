@@ -0,0 +1,173 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// notificationTimeout bounds the delivery of a notification to all notifiers
+const notificationTimeout = 30 * time.Second
+
+// WatchNotifier turns the changes recorded in the audit log into notifications for the watchers of the
+// changed plan and anyone @mentioned in the change. Notifications are delivered in the background, so a slow
+// webhook does not hold up the change, and are not sent to the actor who made the change.
+type WatchNotifier struct {
+	watchers  *storage.WatcherStore
+	notifiers []storage.Notifier
+	pending   sync.WaitGroup
+}
+
+// NewWatchNotifier creates a watch notifier delivering notifications to the given notifiers
+func NewWatchNotifier(watchers *storage.WatcherStore, notifiers ...storage.Notifier) *WatchNotifier {
+	return &WatchNotifier{
+		watchers:  watchers,
+		notifiers: notifiers,
+	}
+}
+
+// Changed notifies the watchers of the plan of a change in the background
+func (n *WatchNotifier) Changed(ctx context.Context, entry *models.AuditEntry) {
+	n.pending.Add(1)
+	go func() {
+		defer n.pending.Done()
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notificationTimeout)
+		defer cancel()
+
+		notification, err := n.notification(ctx, entry)
+		if err != nil {
+			log.Printf("Warning: failed to prepare notification for %s %s: %v", entry.EntityType, entry.EntityID, err)
+			return
+		}
+		if notification == nil {
+			return
+		}
+		for _, notifier := range n.notifiers {
+			if err := notifier.Notify(ctx, notification); err != nil {
+				log.Printf("Warning: failed to notify about %s %s: %v", entry.EntityType, entry.EntityID, err)
+			}
+		}
+	}()
+}
+
+// Wait waits for the notifications being delivered, such as before the server stops
+func (n *WatchNotifier) Wait() {
+	n.pending.Wait()
+}
+
+// notification builds the notification for a change, or returns nil when there is nobody to notify
+func (n *WatchNotifier) notification(ctx context.Context, entry *models.AuditEntry) (*models.Notification, error) {
+	snapshot := entry.After
+	if len(snapshot) == 0 {
+		snapshot = entry.Before
+	}
+	var entity struct {
+		PlanID string `json:"plan_id"`
+		Name   string `json:"name"`
+		Title  string `json:"title"`
+	}
+	if err := json.Unmarshal(snapshot, &entity); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	notification := &models.Notification{
+		EntityType:    entry.EntityType,
+		EntityID:      entry.EntityID,
+		PlanID:        entity.PlanID,
+		Title:         entity.Title,
+		Action:        entry.Action,
+		Operation:     entry.Operation,
+		Actor:         entry.Actor,
+		Timestamp:     entry.Timestamp,
+		ChangedFields: slices.Sorted(maps.Keys(entry.Changes)),
+	}
+	if entry.EntityType == models.EntityTypePlan {
+		notification.PlanID = entry.EntityID
+		notification.Title = entity.Name
+	}
+
+	watchers, err := n.watchers.Watchers(ctx, notification.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	notSelf := func(id string) bool { return id == entry.Actor }
+	notification.Watchers = slices.DeleteFunc(watchers, notSelf)
+	notification.Mentions = slices.DeleteFunc(models.NewMentions(entry), notSelf)
+	if len(notification.Watchers) == 0 && len(notification.Mentions) == 0 {
+		return nil, nil
+	}
+	return notification, nil
+}
+
+// WebhookNotifier posts notifications as JSON to an HTTP endpoint run by the operator
+type WebhookNotifier struct {
+	url  string
+	http *http.Client
+}
+
+// NewWebhookNotifier creates a notifier posting to the given URL
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{url: url, http: &http.Client{Timeout: notificationTimeout}}
+}
+
+// Notify posts the notification to the endpoint
+func (w *WebhookNotifier) Notify(ctx context.Context, notification *models.Notification) error {
+	return postNotification(ctx, w.http, w.url, notification)
+}
+
+// SlackNotifier posts notifications to a Slack incoming webhook as a one line message naming the recipients
+type SlackNotifier struct {
+	url  string
+	http *http.Client
+}
+
+// NewSlackNotifier creates a notifier posting to the given Slack incoming webhook URL
+func NewSlackNotifier(url string) *SlackNotifier {
+	return &SlackNotifier{url: url, http: &http.Client{Timeout: notificationTimeout}}
+}
+
+// Notify posts the summary of the notification to Slack
+func (s *SlackNotifier) Notify(ctx context.Context, notification *models.Notification) error {
+	return postNotification(ctx, s.http, s.url, map[string]string{"text": notification.Summary()})
+}
+
+// postNotification posts a JSON body to a notification endpoint
+func postNotification(ctx context.Context, client *http.Client, url string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("notification request failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("notification request failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+var (
+	_ storage.ChangeListener = (*WatchNotifier)(nil)
+	_ storage.Notifier       = (*WebhookNotifier)(nil)
+	_ storage.Notifier       = (*SlackNotifier)(nil)
+)
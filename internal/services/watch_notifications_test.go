@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// recordingNotifier keeps the notifications it is given
+type recordingNotifier struct {
+	mu            sync.Mutex
+	notifications []*models.Notification
+}
+
+func (r *recordingNotifier) Notify(_ context.Context, notification *models.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notifications = append(r.notifications, notification)
+	return nil
+}
+
+func TestWatchNotifier(t *testing.T) {
+	repos := newTestRepos(t)
+	watchers := storage.NewWatcherStore(repos.client)
+	recorder := &recordingNotifier{}
+	notifier := NewWatchNotifier(watchers, recorder)
+	repos.auditLog.SetChangeListener(notifier)
+	planRepo, taskRepo := repos.audited()
+
+	ctx := storage.WithActor(context.Background(), "alice")
+	plan, err := planRepo.Create(ctx, "app", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	notifier.Wait()
+	if len(recorder.notifications) != 0 {
+		t.Fatalf("expected no notifications without watchers, got %+v", recorder.notifications)
+	}
+
+	for _, watcher := range []string{"alice", "bob"} {
+		if _, err := watchers.Watch(ctx, plan.ID, watcher); err != nil {
+			t.Fatalf("failed to watch plan: %v", err)
+		}
+	}
+	if _, err := watchers.Watch(ctx, plan.ID, "bad watcher"); err == nil {
+		t.Error("expected watchers with whitespace to be rejected")
+	}
+
+	task, err := taskRepo.Create(ctx, plan.ID, "Write docs", "Ask @carol and @alice", models.TaskPriorityMedium)
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	notifier.Wait()
+
+	if len(recorder.notifications) != 1 {
+		t.Fatalf("expected one notification, got %d", len(recorder.notifications))
+	}
+	notification := recorder.notifications[0]
+	if notification.PlanID != plan.ID || notification.EntityID != task.ID || notification.Title != "Write docs" {
+		t.Errorf("unexpected notification %+v", notification)
+	}
+	if !slices.Equal(notification.Watchers, []string{"bob"}) || !slices.Equal(notification.Mentions, []string{"carol"}) {
+		t.Errorf("expected bob watching and carol mentioned without the actor, got %v and %v",
+			notification.Watchers, notification.Mentions)
+	}
+
+	// Mentions that were already there are not notified again
+	task.Title = "Write the docs"
+	if err := taskRepo.Update(ctx, task); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	notifier.Wait()
+	notification = recorder.notifications[1]
+	if notification.Mentions != nil || !slices.Contains(notification.ChangedFields, "title") {
+		t.Errorf("expected a title change without mentions, got %+v", notification)
+	}
+	if summary := notification.Summary(); !strings.Contains(summary, "by alice") || !strings.Contains(summary, "@bob") {
+		t.Errorf("summary = %q", summary)
+	}
+
+	// Changes by the only watcher are not notified
+	if _, err := watchers.Unwatch(ctx, plan.ID, "bob"); err != nil {
+		t.Fatalf("failed to unwatch plan: %v", err)
+	}
+	if err := planRepo.UpdateNotes(ctx, plan.ID, "Done"); err != nil {
+		t.Fatalf("failed to update notes: %v", err)
+	}
+	notifier.Wait()
+	if len(recorder.notifications) != 2 {
+		t.Errorf("expected no notification for the watcher's own change, got %+v", recorder.notifications[2:])
+	}
+}
+
+func TestSlackNotifier(t *testing.T) {
+	var body map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	err := NewSlackNotifier(server.URL).Notify(context.Background(), &models.Notification{
+		EntityType: models.EntityTypePlan,
+		Title:      "Release",
+		Operation:  "update",
+		Actor:      "alice",
+		Watchers:   []string{"bob"},
+	})
+	if err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if want := `plan "Release": update by alice cc @bob`; body["text"] != want {
+		t.Errorf("text = %q, want %q", body["text"], want)
+	}
+}
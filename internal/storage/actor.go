@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"context"
+)
+
+// DefaultActor is recorded for changes made without an actor in the context, e.g. by background jobs
+const DefaultActor = "system"
+
+type actorKey struct{}
+
+// WithActor returns a context that attributes storage changes to the given actor
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor stored in the context, or DefaultActor if there is none
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return DefaultActor
+}
@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/valkey-io/valkey-glide/go/v2/constants"
+	glidemodels "github.com/valkey-io/valkey-glide/go/v2/models"
+	"github.com/valkey-io/valkey-glide/go/v2/options"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// DefaultAgentActivityLength is the number of agent activity entries kept per plan when none is configured
+const DefaultAgentActivityLength = 1000
+
+// AgentActivityQuery selects entries of the activity journal of a plan
+type AgentActivityQuery struct {
+	// Session only returns the entries of one work session when set
+	Session string
+	// Before only returns entries older than the entry with this ID, for paging back through the journal
+	Before string
+	// Limit is the maximum number of entries returned, zero or less returning all kept entries
+	Limit int64
+}
+
+// SetAgentActivityLength sets the number of agent activity entries kept per plan. The oldest entries are
+// dropped beyond it, and zero keeps every entry.
+func (r *PlanRepository) SetAgentActivityLength(length int) {
+	r.activityLength = int64(length)
+}
+
+// LogActivity adds an entry to the activity journal of a plan and returns it with its ID, actor and time set
+func (r *PlanRepository) LogActivity(
+	ctx context.Context,
+	planID string,
+	activity *models.AgentActivity,
+) (*models.AgentActivity, error) {
+	if strings.TrimSpace(activity.Summary) == "" {
+		return nil, models.NewValidationError(models.EntityPlan, planID, "an activity entry needs a summary")
+	}
+	if _, err := r.Get(withPrimaryReads(ctx), planID); err != nil {
+		return nil, err
+	}
+
+	entry := *activity
+	entry.PlanID = planID
+	entry.Actor = ActorFromContext(ctx)
+	entry.LoggedAt = time.Now()
+
+	summary, err := r.client.seal(entry.Summary)
+	if err != nil {
+		return nil, err
+	}
+	fields := []glidemodels.FieldValue{
+		{Field: "actor", Value: entry.Actor},
+		{Field: "session", Value: entry.Session},
+		{Field: "kind", Value: entry.Kind},
+		{Field: "summary", Value: summary},
+		{Field: "task_ids", Value: strings.Join(entry.TaskIDs, ",")},
+		{Field: "logged_at", Value: entry.LoggedAt.Format(time.RFC3339Nano)},
+	}
+	if len(entry.Details) > 0 {
+		details, err := json.Marshal(entry.Details)
+		if err != nil {
+			return nil, models.NewValidationError(models.EntityPlan, planID, "invalid activity details: %v", err)
+		}
+		sealed, err := r.client.seal(string(details))
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, glidemodels.FieldValue{Field: "details", Value: sealed})
+	}
+
+	addOpts := options.NewXAddOptions()
+	if r.activityLength > 0 {
+		addOpts.SetTrimOptions(options.NewXTrimOptionsWithMaxLen(r.activityLength))
+	}
+	id, err := r.client.client.XAddWithOptions(ctx, GetPlanActivityKey(planID), fields, *addOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to log agent activity: %w", err)
+	}
+	entry.ID = id.Value()
+	return &entry, nil
+}
+
+// ListActivity returns entries of the activity journal of a plan, newest first
+func (r *PlanRepository) ListActivity(
+	ctx context.Context,
+	planID string,
+	query AgentActivityQuery,
+) ([]*models.AgentActivity, error) {
+	if _, err := r.Get(ctx, planID); err != nil {
+		return nil, err
+	}
+
+	start := options.NewInfiniteStreamBoundary(constants.PositiveInfinity)
+	if query.Before != "" {
+		start = options.NewStreamBoundary(query.Before, false)
+	}
+	// Entries of other sessions are skipped after reading, so the count only limits unfiltered reads
+	rangeOpts := options.NewXRangeOptions()
+	if query.Limit > 0 && query.Session == "" {
+		rangeOpts.SetCount(query.Limit)
+	}
+	entries, err := r.client.client.XRevRangeWithOptions(
+		ctx,
+		GetPlanActivityKey(planID),
+		start,
+		options.NewInfiniteStreamBoundary(constants.NegativeInfinity),
+		*rangeOpts,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent activity: %w", err)
+	}
+
+	activities := make([]*models.AgentActivity, 0, len(entries))
+	for _, entry := range entries {
+		activity, err := r.parseActivity(planID, entry)
+		if err != nil {
+			return nil, err
+		}
+		if query.Session != "" && activity.Session != query.Session {
+			continue
+		}
+		activities = append(activities, activity)
+		if query.Limit > 0 && int64(len(activities)) == query.Limit {
+			break
+		}
+	}
+	return activities, nil
+}
+
+// parseActivity reads an entry of the activity journal of a plan
+func (r *PlanRepository) parseActivity(planID string, entry glidemodels.StreamEntry) (*models.AgentActivity, error) {
+	activity := &models.AgentActivity{ID: entry.ID, PlanID: planID}
+	for _, field := range entry.Fields {
+		switch field.Field {
+		case "actor":
+			activity.Actor = field.Value
+		case "session":
+			activity.Session = field.Value
+		case "kind":
+			activity.Kind = field.Value
+		case "summary":
+			summary, err := r.client.open(field.Value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read activity summary: %w", err)
+			}
+			activity.Summary = summary
+		case "task_ids":
+			if field.Value != "" {
+				activity.TaskIDs = strings.Split(field.Value, ",")
+			}
+		case "details":
+			details, err := r.client.open(field.Value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read activity details: %w", err)
+			}
+			if err := json.Unmarshal([]byte(details), &activity.Details); err != nil {
+				return nil, fmt.Errorf("failed to decode activity details: %w", err)
+			}
+		case "logged_at":
+			loggedAt, err := time.Parse(time.RFC3339Nano, field.Value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse activity time: %w", err)
+			}
+			activity.LoggedAt = loggedAt
+		}
+	}
+	return activity, nil
+}
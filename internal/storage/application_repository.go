@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// ApplicationRepository handles storage operations for registered applications
+type ApplicationRepository struct {
+	client *ValkeyClient
+}
+
+// NewApplicationRepository creates a new application repository
+func NewApplicationRepository(client *ValkeyClient) *ApplicationRepository {
+	return &ApplicationRepository{client: client}
+}
+
+// Create registers an application under the given ID, which must not be registered yet
+func (r *ApplicationRepository) Create(
+	ctx context.Context,
+	id, name, description string,
+	metadata map[string]string,
+) (*models.Application, error) {
+	if err := models.ValidateApplicationID(id); err != nil {
+		return nil, err
+	}
+	for key := range metadata {
+		if err := models.ValidateMetadataKey(key); err != nil {
+			return nil, err
+		}
+	}
+
+	exists, err := r.Exists(withPrimaryReads(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, models.NewConflictError(models.EntityApplication, id, "application already exists: %s", id)
+	}
+
+	if strings.TrimSpace(name) == "" {
+		name = id
+	}
+	application := models.NewApplication(id, name, description)
+	application.Metadata = maps.Clone(metadata)
+
+	sealed, err := r.client.sealFields(application.ToMap())
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.client.client.HSet(ctx, GetApplicationKey(id), sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store application: %w", err)
+	}
+
+	_, err = r.client.client.SAdd(ctx, applicationsListKey, []string{id})
+	if err != nil {
+		// Try to clean up the application if adding to the set fails
+		r.client.client.Del(ctx, []string{GetApplicationKey(id)}) //nolint:errcheck
+		return nil, fmt.Errorf("failed to add application to list: %w", err)
+	}
+
+	return application, nil
+}
+
+// Get retrieves a registered application by ID
+func (r *ApplicationRepository) Get(ctx context.Context, id string) (*models.Application, error) {
+	result, err := r.client.client.HGetAll(ctx, GetApplicationKey(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve application: %w", err)
+	}
+
+	if len(result) == 0 {
+		return nil, models.NewNotFoundError(models.EntityApplication, id)
+	}
+
+	if err := r.client.openFields(result); err != nil {
+		return nil, fmt.Errorf("failed to read application data: %w", err)
+	}
+	application := &models.Application{}
+	err = application.FromMap(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse application data: %w", err)
+	}
+
+	return application, nil
+}
+
+// Exists reports whether an application is registered
+func (r *ApplicationRepository) Exists(ctx context.Context, id string) (bool, error) {
+	return r.client.applicationExists(ctx, id)
+}
+
+// List returns all registered applications ordered by ID
+func (r *ApplicationRepository) List(ctx context.Context) ([]*models.Application, error) {
+	ctx = withReplicaReads(ctx)
+
+	ids, err := r.client.client.SMembers(ctx, applicationsListKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve application IDs: %w", err)
+	}
+
+	applications := make([]*models.Application, 0, len(ids))
+	for _, id := range slices.Sorted(maps.Keys(ids)) {
+		application, err := r.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		applications = append(applications, application)
+	}
+
+	return applications, nil
+}
+
+// applicationExists reports whether an application is registered
+func (c *ValkeyClient) applicationExists(ctx context.Context, id string) (bool, error) {
+	exists, err := c.client.SIsMember(ctx, applicationsListKey, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if application exists: %w", err)
+	}
+	return exists, nil
+}
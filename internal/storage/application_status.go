@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// The status of an application is rolled up from its plans. Each application keeps a hash with the status and
+// task counts of every plan, and every change to a plan or its tasks refreshes the entry of the plan from its
+// hash, so the status of an application is read without loading any plan or task. Plans stored before the
+// rollup existed are added the first time the status of their application is read.
+
+// rollupPlan refreshes the entry of a plan in the status of its application and returns it, or nil if the
+// plan does not exist
+func (c *ValkeyClient) rollupPlan(ctx context.Context, planID string) (*models.PlanRollup, error) {
+	data, err := c.client.HGetAll(withPrimaryReads(ctx), GetPlanKey(planID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plan %s: %w", planID, err)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	counts, err := models.TaskCountsFromFields(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse task counts of plan %s: %w", planID, err)
+	}
+	rollup := &models.PlanRollup{Status: models.PlanStatus(data["status"]), TaskCounts: counts}
+	encoded, err := json.Marshal(rollup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode status of plan %s: %w", planID, err)
+	}
+
+	statusKey := GetApplicationStatusKey(data["application_id"])
+	if _, err := c.client.HSet(ctx, statusKey, map[string]string{planID: string(encoded)}); err != nil {
+		return nil, fmt.Errorf("failed to update application status: %w", err)
+	}
+	return rollup, nil
+}
+
+// dropPlanRollup removes the entry of a plan from the status of an application
+func (c *ValkeyClient) dropPlanRollup(ctx context.Context, applicationID, planID string) error {
+	if _, err := c.client.HDel(ctx, GetApplicationStatusKey(applicationID), []string{planID}); err != nil {
+		return fmt.Errorf("failed to update application status: %w", err)
+	}
+	return nil
+}
+
+// GetApplicationStatus returns the status and progress of an application rolled up from its plans
+func (r *PlanRepository) GetApplicationStatus(
+	ctx context.Context,
+	applicationID string,
+) (*models.ApplicationStatus, error) {
+	ctx = withReplicaReads(ctx)
+
+	planIDs, err := r.client.client.SMembers(ctx, fmt.Sprintf("app:%s:plans", applicationID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve application plan IDs: %w", err)
+	}
+	stored, err := r.client.client.HGetAll(ctx, GetApplicationStatusKey(applicationID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve application status: %w", err)
+	}
+
+	plans := make([]*models.PlanRollup, 0, len(planIDs))
+	for id := range planIDs {
+		rollup := &models.PlanRollup{}
+		if entry, ok := stored[id]; !ok || json.Unmarshal([]byte(entry), rollup) != nil || rollup.TaskCounts == nil {
+			rollup, err = r.backfillRollup(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if rollup != nil {
+			plans = append(plans, rollup)
+		}
+	}
+
+	// Entries of plans that left the application are dropped
+	for id := range stored {
+		if _, ok := planIDs[id]; !ok {
+			if err := r.client.dropPlanRollup(ctx, applicationID, id); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return models.NewApplicationStatus(applicationID, plans), nil
+}
+
+// backfillRollup adds a plan that has no entry in the status of its application, counting its tasks first if
+// it was stored before tasks were counted. It returns nil if the plan does not exist.
+func (r *PlanRepository) backfillRollup(ctx context.Context, planID string) (*models.PlanRollup, error) {
+	ctx = withPrimaryReads(ctx)
+	rollup, err := r.client.rollupPlan(ctx, planID)
+	if err != nil || rollup == nil || rollup.TaskCounts != nil {
+		return rollup, err
+	}
+
+	taskRepo := &TaskRepository{client: r.client}
+	if _, err := taskRepo.countTasks(ctx, planID); err != nil {
+		return nil, err
+	}
+	return r.client.rollupPlan(ctx, planID)
+}
@@ -0,0 +1,620 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	glide "github.com/valkey-io/valkey-glide/go/v2"
+	glidemodels "github.com/valkey-io/valkey-glide/go/v2/models"
+	"github.com/valkey-io/valkey-glide/go/v2/options"
+	"github.com/valkey-io/valkey-glide/go/v2/pipeline"
+)
+
+// Changes that must be written all or nothing run with their writes staged. The repositories work as usual,
+// but their writes are applied to an in-memory overlay of the keys they touch, so later reads of the same
+// operation see them, and queued. Once the operation succeeds, the queued writes are sent to Valkey as one
+// MULTI/EXEC transaction; if it fails, nothing was written. Stream entries, such as audit history, are only
+// written with the transaction, so reads of streams do not see the staged entries.
+//
+// A Valkey Cluster only runs transactions over keys of one hash slot, so on a cluster batches touching keys
+// in several slots are rejected, without writing anything.
+
+// stagedIDPrefix starts the placeholder IDs of staged stream entries, followed by the index of their write
+const stagedIDPrefix = "staged-"
+
+// stagedScripts are the sources of the scripts that can run in a transaction, by hash. A transaction runs
+// them with EVAL, since EVALSHA of a script the server does not know would fail after the commands before it.
+var stagedScripts = map[string]string{
+	adjustTaskCountsScript.GetHash(): adjustTaskCountsSource,
+}
+
+// writeQueue queues the writes of the repositories: in a Valkey transaction, or applied to a store right away
+type writeQueue interface {
+	Del(keys []string)
+	SetWithOptions(key, value string, options options.SetOptions)
+	InvokeScript(script options.Script, scriptOptions options.ScriptOptions)
+	HDel(key string, fields []string)
+	HIncrBy(key, field string, increment int64)
+	HSet(key string, values map[string]string)
+	LRem(key string, count int64, element string)
+	LSet(key string, index int64, element string)
+	RPush(key string, elements []string)
+	SAdd(key string, members []string)
+	SRem(key string, members []string)
+	ZAdd(key string, membersScoreMap map[string]float64)
+	ZRem(key string, members []string)
+	XAddWithOptions(key string, values []glidemodels.FieldValue, options options.XAddOptions)
+	XTrim(key string, options options.XTrimOptions)
+}
+
+// stagedWrite is a write of a staged operation and the keys it touches
+type stagedWrite struct {
+	keys  []string
+	queue func(q writeQueue)
+}
+
+// writeStageKey is the context key of the stage of the current operation
+type writeStageKey struct{}
+
+// writeStage holds the writes of an operation until they are written in one transaction
+type writeStage struct {
+	base *valkeyConnection
+	// overlay holds the keys touched by the staged writes, with the writes applied
+	overlay *memoryStore
+
+	mu     sync.Mutex
+	loaded map[string]bool
+	writes []stagedWrite
+	// hooks run once the writes are written
+	hooks []func(ctx context.Context)
+	// results are the results of the writes once they are written
+	results []any
+	done    bool
+	// locks are the plan locks taken by the operation, held until the writes are written
+	locks   []*heldPlanLock
+	unlocks []func()
+}
+
+// writeStageOf returns the stage of an operation, or nil if its writes are not staged
+func writeStageOf(ctx context.Context) *writeStage {
+	stage, _ := ctx.Value(writeStageKey{}).(*writeStage)
+	return stage
+}
+
+// unstaged returns a context whose writes are sent to Valkey right away, for the commands that must not wait
+// for a transaction, such as taking locks
+func unstaged(ctx context.Context) context.Context {
+	if writeStageOf(ctx) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, writeStageKey{}, (*writeStage)(nil))
+}
+
+// afterCommit runs a hook once the writes of the operation are written, or right away if they are not
+// staged. The hook gets a context whose writes are not staged.
+func afterCommit(ctx context.Context, hook func(ctx context.Context)) {
+	stage := writeStageOf(ctx)
+	if stage == nil {
+		hook(ctx)
+		return
+	}
+	stage.mu.Lock()
+	defer stage.mu.Unlock()
+	stage.hooks = append(stage.hooks, hook)
+}
+
+// committedStreamID returns the ID a stream entry was written with, given the ID it was added with. Staged
+// entries only get their ID once the transaction is written; other IDs are returned as they are.
+func committedStreamID(ctx context.Context, id string) string {
+	stage := writeStageOf(ctx)
+	index, err := strconv.Atoi(strings.TrimPrefix(id, stagedIDPrefix))
+	if stage == nil || !strings.HasPrefix(id, stagedIDPrefix) || err != nil {
+		return id
+	}
+
+	stage.mu.Lock()
+	defer stage.mu.Unlock()
+	if index >= len(stage.results) {
+		return id
+	}
+	switch written := stage.results[index].(type) {
+	case string:
+		return written
+	case glidemodels.Result[string]:
+		return written.Value()
+	default:
+		return id
+	}
+}
+
+// writeAtomically runs an operation with its writes staged and writes them in one transaction once it
+// succeeds. If it fails, nothing is written. An operation already staged joins the outer transaction.
+func (c *ValkeyClient) writeAtomically(ctx context.Context, operation func(ctx context.Context) error) error {
+	if writeStageOf(ctx) != nil {
+		return operation(ctx)
+	}
+
+	stage := &writeStage{
+		base:    c.client,
+		overlay: newMemoryStore(""),
+		loaded:  make(map[string]bool),
+	}
+	defer stage.release()
+	if err := operation(context.WithValue(ctx, writeStageKey{}, stage)); err != nil {
+		stage.discard()
+		return err
+	}
+	if err := stage.commit(ctx); err != nil {
+		return err
+	}
+	for _, hook := range stage.hooks {
+		hook(ctx)
+	}
+	return nil
+}
+
+// WriteAtomically takes the lock of a plan and runs a sequence of changes to it with their writes staged, so
+// they are written in one transaction once all of them succeed, and not at all if one fails. Repository calls
+// made with the context passed to write see the changes made before them.
+func (r *PlanRepository) WriteAtomically(
+	ctx context.Context,
+	id string,
+	write func(ctx context.Context) error,
+) error {
+	ctx, unlock, err := r.client.lockPlan(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return r.client.writeAtomically(ctx, write)
+}
+
+// holdLock keeps a plan lock taken by the operation until its writes are written
+func (s *writeStage) holdLock(lock *heldPlanLock, unlock func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locks = append(s.locks, lock)
+	s.unlocks = append(s.unlocks, unlock)
+}
+
+// release releases the plan locks taken by the operation, newest first
+func (s *writeStage) release() {
+	s.mu.Lock()
+	unlocks := s.unlocks
+	s.unlocks = nil
+	s.mu.Unlock()
+	for _, unlock := range slices.Backward(unlocks) {
+		unlock()
+	}
+}
+
+// reader returns the client reading a key: the overlay once a staged write touched it, Valkey before
+func (s *writeStage) reader(key string) valkeyCommands {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loaded[key] {
+		return s.overlay
+	}
+	return s.base.writer()
+}
+
+// write applies a write to the overlay, loading the keys it touches first, and queues it
+func (s *writeStage) write(ctx context.Context, keys []string, queue func(q writeQueue)) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return nil, errors.New("the batch was already written")
+	}
+
+	for _, key := range keys {
+		if err := s.load(ctx, key); err != nil {
+			return nil, err
+		}
+	}
+	applied := &storeQueue{ctx: ctx, store: s.overlay}
+	queue(applied)
+	if applied.err != nil {
+		return nil, applied.err
+	}
+	s.writes = append(s.writes, stagedWrite{keys: keys, queue: queue})
+	return applied.results[0], nil
+}
+
+// writeStream queues a write to a stream and returns the placeholder ID of its entry
+func (s *writeStage) writeStream(key string, queue func(q writeQueue)) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return "", errors.New("the batch was already written")
+	}
+
+	s.writes = append(s.writes, stagedWrite{keys: []string{key}, queue: queue})
+	return stagedIDPrefix + strconv.Itoa(len(s.writes)-1), nil
+}
+
+// load copies a key from Valkey into the overlay, unless it is there already. Streams are not copied, their
+// writes are only queued.
+func (s *writeStage) load(ctx context.Context, key string) error {
+	if s.loaded[key] {
+		return nil
+	}
+
+	client := s.base.writer()
+	keyType, err := client.Type(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to stage %s: %w", key, err)
+	}
+	// Reads of other keys may use the overlay meanwhile
+	overlay := s.overlay
+	overlay.mu.Lock()
+	defer overlay.mu.Unlock()
+	switch keyType {
+	case "string":
+		value, err := client.Get(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to stage %s: %w", key, err)
+		}
+		if !value.IsNil() {
+			overlay.strings[key] = memoryString{Value: value.Value()}
+		}
+	case "hash":
+		hash, err := client.HGetAll(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to stage %s: %w", key, err)
+		}
+		if len(hash) > 0 {
+			overlay.hashes[key] = hash
+		}
+	case "set":
+		set, err := client.SMembers(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to stage %s: %w", key, err)
+		}
+		if len(set) > 0 {
+			overlay.sets[key] = set
+		}
+	case "zset":
+		members, err := client.ZRangeWithScores(ctx, key, options.NewRangeByIndexQuery(0, -1))
+		if err != nil {
+			return fmt.Errorf("failed to stage %s: %w", key, err)
+		}
+		if len(members) > 0 {
+			zset := make(map[string]float64, len(members))
+			for _, member := range members {
+				zset[member.Member] = member.Score
+			}
+			overlay.sortedSets[key] = zset
+		}
+	case "list":
+		list, err := client.LRange(ctx, key, 0, -1)
+		if err != nil {
+			return fmt.Errorf("failed to stage %s: %w", key, err)
+		}
+		if len(list) > 0 {
+			overlay.lists[key] = list
+		}
+	case "stream":
+		return nil
+	}
+	s.loaded[key] = true
+	return nil
+}
+
+// commit writes the queued writes in one transaction
+func (s *writeStage) commit(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = true
+	if len(s.writes) == 0 {
+		return nil
+	}
+
+	if err := lostPlanLock(ctx); err != nil {
+		return err
+	}
+	for _, lock := range s.locks {
+		if err := lock.lost.Load(); err != nil {
+			return *err
+		}
+	}
+	results, err := s.base.execAtomic(unstaged(ctx), s.writes)
+	if err != nil {
+		return fmt.Errorf("failed to write the changes: %w", err)
+	}
+	s.results = results
+	return nil
+}
+
+// discard drops the queued writes of a failed operation
+func (s *writeStage) discard() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = true
+	s.writes = nil
+	s.hooks = nil
+}
+
+// execAtomic writes a list of writes in one transaction on the primary
+func (c *valkeyConnection) execAtomic(ctx context.Context, writes []stagedWrite) ([]any, error) {
+	return execClientAtomic(ctx, c.writer(), writes)
+}
+
+// atomicExecutor is implemented by clients that write a list of writes all or nothing themselves
+type atomicExecutor interface {
+	execAtomic(ctx context.Context, writes []stagedWrite) ([]any, error)
+}
+
+// execClientAtomic writes a list of writes in one transaction of a single client
+func execClientAtomic(ctx context.Context, client valkeyCommands, writes []stagedWrite) ([]any, error) {
+	switch client := client.(type) {
+	case *glide.Client:
+		batch := pipeline.NewStandaloneBatch(true)
+		queue := &batchQueue[pipeline.StandaloneBatch]{batch: &batch.BaseBatch}
+		if err := queue.queueAll(writes); err != nil {
+			return nil, err
+		}
+		return client.Exec(ctx, *batch, true)
+	case *glide.ClusterClient:
+		batch := pipeline.NewClusterBatch(true)
+		queue := &batchQueue[pipeline.ClusterBatch]{batch: &batch.BaseBatch}
+		if err := queue.queueAll(writes); err != nil {
+			return nil, err
+		}
+		return client.Exec(ctx, *batch, true)
+	case *tunneledClient:
+		return execClientAtomic(ctx, client.valkeyCommands, writes)
+	case atomicExecutor:
+		return client.execAtomic(ctx, writes)
+	default:
+		return nil, fmt.Errorf("client %T does not support transactions", client)
+	}
+}
+
+// batchQueue queues writes in a Valkey-Glide transaction
+type batchQueue[T pipeline.StandaloneBatch | pipeline.ClusterBatch] struct {
+	batch *pipeline.BaseBatch[T]
+	err   error
+}
+
+// queueAll queues a list of writes and returns the first error
+func (q *batchQueue[T]) queueAll(writes []stagedWrite) error {
+	for _, write := range writes {
+		write.queue(q)
+	}
+	return q.err
+}
+
+func (q *batchQueue[T]) Del(keys []string) {
+	q.batch.Del(keys)
+}
+
+func (q *batchQueue[T]) SetWithOptions(key, value string, options options.SetOptions) {
+	q.batch.SetWithOptions(key, value, options)
+}
+
+func (q *batchQueue[T]) InvokeScript(script options.Script, scriptOptions options.ScriptOptions) {
+	source, ok := stagedScripts[script.GetHash()]
+	if !ok {
+		q.err = errors.Join(q.err, fmt.Errorf("script %s cannot run in a transaction", script.GetHash()))
+		return
+	}
+	args := append([]string{"EVAL", source, strconv.Itoa(len(scriptOptions.Keys))}, scriptOptions.Keys...)
+	q.batch.CustomCommand(append(args, scriptOptions.Args...))
+}
+
+func (q *batchQueue[T]) HDel(key string, fields []string) {
+	q.batch.HDel(key, fields)
+}
+
+func (q *batchQueue[T]) HIncrBy(key, field string, increment int64) {
+	q.batch.HIncrBy(key, field, increment)
+}
+
+func (q *batchQueue[T]) HSet(key string, values map[string]string) {
+	q.batch.HSet(key, values)
+}
+
+func (q *batchQueue[T]) LRem(key string, count int64, element string) {
+	q.batch.LRem(key, count, element)
+}
+
+func (q *batchQueue[T]) LSet(key string, index int64, element string) {
+	q.batch.LSet(key, index, element)
+}
+
+func (q *batchQueue[T]) RPush(key string, elements []string) {
+	q.batch.RPush(key, elements)
+}
+
+func (q *batchQueue[T]) SAdd(key string, members []string) {
+	q.batch.SAdd(key, members)
+}
+
+func (q *batchQueue[T]) SRem(key string, members []string) {
+	q.batch.SRem(key, members)
+}
+
+func (q *batchQueue[T]) ZAdd(key string, membersScoreMap map[string]float64) {
+	q.batch.ZAdd(key, membersScoreMap)
+}
+
+func (q *batchQueue[T]) ZRem(key string, members []string) {
+	q.batch.ZRem(key, members)
+}
+
+func (q *batchQueue[T]) XAddWithOptions(key string, values []glidemodels.FieldValue, options options.XAddOptions) {
+	q.batch.XAddWithOptions(key, values, options)
+}
+
+func (q *batchQueue[T]) XTrim(key string, options options.XTrimOptions) {
+	q.batch.XTrim(key, options)
+}
+
+// storeQueue applies writes to a client right away, keeping their results until the first error
+type storeQueue struct {
+	ctx     context.Context
+	store   valkeyCommands
+	results []any
+	err     error
+}
+
+// record keeps the result of a write, or its error
+func (q *storeQueue) record(result any, err error) {
+	if err != nil {
+		q.err = err
+		return
+	}
+	q.results = append(q.results, result)
+}
+
+func (q *storeQueue) Del(keys []string) {
+	if q.err == nil {
+		q.record(q.store.Del(q.ctx, keys))
+	}
+}
+
+func (q *storeQueue) SetWithOptions(key, value string, options options.SetOptions) {
+	if q.err == nil {
+		q.record(q.store.SetWithOptions(q.ctx, key, value, options))
+	}
+}
+
+func (q *storeQueue) InvokeScript(script options.Script, scriptOptions options.ScriptOptions) {
+	if q.err == nil {
+		q.record(q.store.InvokeScriptWithOptions(q.ctx, script, scriptOptions))
+	}
+}
+
+func (q *storeQueue) HDel(key string, fields []string) {
+	if q.err == nil {
+		q.record(q.store.HDel(q.ctx, key, fields))
+	}
+}
+
+func (q *storeQueue) HIncrBy(key, field string, increment int64) {
+	if q.err == nil {
+		q.record(q.store.HIncrBy(q.ctx, key, field, increment))
+	}
+}
+
+func (q *storeQueue) HSet(key string, values map[string]string) {
+	if q.err == nil {
+		q.record(q.store.HSet(q.ctx, key, values))
+	}
+}
+
+func (q *storeQueue) LRem(key string, count int64, element string) {
+	if q.err == nil {
+		q.record(q.store.LRem(q.ctx, key, count, element))
+	}
+}
+
+func (q *storeQueue) LSet(key string, index int64, element string) {
+	if q.err == nil {
+		q.record(q.store.LSet(q.ctx, key, index, element))
+	}
+}
+
+func (q *storeQueue) RPush(key string, elements []string) {
+	if q.err == nil {
+		q.record(q.store.RPush(q.ctx, key, elements))
+	}
+}
+
+func (q *storeQueue) SAdd(key string, members []string) {
+	if q.err == nil {
+		q.record(q.store.SAdd(q.ctx, key, members))
+	}
+}
+
+func (q *storeQueue) SRem(key string, members []string) {
+	if q.err == nil {
+		q.record(q.store.SRem(q.ctx, key, members))
+	}
+}
+
+func (q *storeQueue) ZAdd(key string, membersScoreMap map[string]float64) {
+	if q.err == nil {
+		q.record(q.store.ZAdd(q.ctx, key, membersScoreMap))
+	}
+}
+
+func (q *storeQueue) ZRem(key string, members []string) {
+	if q.err == nil {
+		q.record(q.store.ZRem(q.ctx, key, members))
+	}
+}
+
+func (q *storeQueue) XAddWithOptions(key string, values []glidemodels.FieldValue, options options.XAddOptions) {
+	if q.err == nil {
+		q.record(q.store.XAddWithOptions(q.ctx, key, values, options))
+	}
+}
+
+func (q *storeQueue) XTrim(key string, options options.XTrimOptions) {
+	if q.err == nil {
+		q.record(q.store.XTrim(q.ctx, key, options))
+	}
+}
+
+// execAtomic applies a list of writes to copies of the keys they touch and stores the copies only if all of
+// them succeed, holding the lock throughout, so other commands see all of the writes or none
+func (m *memoryStore) execAtomic(ctx context.Context, writes []stagedWrite) ([]any, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make(map[string]bool)
+	scratch := newMemoryStore("")
+	for _, write := range writes {
+		for _, key := range write.keys {
+			if !keys[key] {
+				keys[key] = true
+				m.copyKey(scratch, key)
+			}
+		}
+	}
+
+	queue := &storeQueue{ctx: ctx, store: scratch}
+	for _, write := range writes {
+		write.queue(queue)
+	}
+	if queue.err != nil {
+		return nil, queue.err
+	}
+
+	for key := range keys {
+		m.deleteKey(key)
+		scratch.copyKey(m, key)
+	}
+	return queue.results, nil
+}
+
+// copyKey copies the value at a key to another store; the caller must hold the lock of the store and the other
+// store must not be in use
+func (m *memoryStore) copyKey(dst *memoryStore, key string) {
+	if value, ok := m.liveString(key); ok {
+		dst.strings[key] = value
+	}
+	if hash, ok := m.hashes[key]; ok {
+		dst.hashes[key] = maps.Clone(hash)
+	}
+	if set, ok := m.sets[key]; ok {
+		dst.sets[key] = maps.Clone(set)
+	}
+	if zset, ok := m.sortedSets[key]; ok {
+		dst.sortedSets[key] = maps.Clone(zset)
+	}
+	if list, ok := m.lists[key]; ok {
+		dst.lists[key] = slices.Clone(list)
+	}
+	if stream, ok := m.streams[key]; ok {
+		dst.streams[key] = &memoryStream{Entries: slices.Clone(stream.Entries), LastID: stream.LastID}
+	}
+}
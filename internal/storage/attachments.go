@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// AttachmentStore keeps the attachments of tasks. The Valkey store keeps them next to the task; an
+// implementation backed by an object store can take the content out of Valkey. Stores do not check that the
+// task exists or that the attachment is valid, which is left to the caller.
+type AttachmentStore interface {
+	// Add stores a new attachment with its content, assigning its ID
+	Add(ctx context.Context, attachment *models.Attachment) error
+	// List returns the attachments of a task without their content, oldest first
+	List(ctx context.Context, taskID string) ([]*models.Attachment, error)
+	// Get returns an attachment of a task with its content
+	Get(ctx context.Context, taskID, id string) (*models.Attachment, error)
+}
+
+// ValkeyAttachmentStore keeps attachments in two hashes per task, one with the attachment details and one with
+// their content, so listing attachments does not read their content. Both are deleted with the task.
+type ValkeyAttachmentStore struct {
+	client *ValkeyClient
+}
+
+// NewValkeyAttachmentStore creates an attachment store keeping attachments in Valkey
+func NewValkeyAttachmentStore(client *ValkeyClient) *ValkeyAttachmentStore {
+	return &ValkeyAttachmentStore{client: client}
+}
+
+// Add stores a new attachment with its content
+func (s *ValkeyAttachmentStore) Add(ctx context.Context, attachment *models.Attachment) error {
+	attachment.ID = uuid.New().String()
+	encoded, err := attachment.Encode()
+	if err != nil {
+		return err
+	}
+
+	// The content is written first, so an attachment that is listed always has its content
+	_, err = s.client.client.HSet(ctx, GetTaskAttachmentContentKey(attachment.TaskID), map[string]string{
+		attachment.ID: attachment.Content,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store attachment content: %w", err)
+	}
+	_, err = s.client.client.HSet(ctx, GetTaskAttachmentsKey(attachment.TaskID), map[string]string{
+		attachment.ID: encoded,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store attachment: %w", err)
+	}
+	return nil
+}
+
+// List returns the attachments of a task without their content, oldest first
+func (s *ValkeyAttachmentStore) List(ctx context.Context, taskID string) ([]*models.Attachment, error) {
+	entries, err := s.client.client.HGetAll(ctx, GetTaskAttachmentsKey(taskID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+
+	attachments := make([]*models.Attachment, 0, len(entries))
+	for _, entry := range entries {
+		attachment, err := models.DecodeAttachment(entry)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+	slices.SortFunc(attachments, func(a, b *models.Attachment) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return attachments, nil
+}
+
+// Get returns an attachment of a task with its content
+func (s *ValkeyAttachmentStore) Get(ctx context.Context, taskID, id string) (*models.Attachment, error) {
+	entry, err := s.client.client.HGet(ctx, GetTaskAttachmentsKey(taskID), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+	if entry.IsNil() {
+		return nil, models.NewNotFoundError(models.EntityAttachment, id)
+	}
+	attachment, err := models.DecodeAttachment(entry.Value())
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := s.client.client.HGet(ctx, GetTaskAttachmentContentKey(taskID), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment content: %w", err)
+	}
+	attachment.Content = content.Value()
+	return attachment, nil
+}
+
+// AttachmentLimits bounds the attachments clients can add, so attachments stay small artifacts.
+// A zero value disables the limit.
+type AttachmentLimits struct {
+	// MaxSize bounds the content of an attachment in bytes
+	MaxSize int
+	// MaxPerTask bounds the number of attachments of a task
+	MaxPerTask int
+}
+
+// DefaultAttachmentLimits returns the attachment limits applied when none are configured
+func DefaultAttachmentLimits() AttachmentLimits {
+	return AttachmentLimits{
+		MaxSize:    64 * 1024,
+		MaxPerTask: 20,
+	}
+}
+
+// LimitedAttachmentStore decorates an attachment store and rejects attachments that exceed the limits
+type LimitedAttachmentStore struct {
+	AttachmentStore
+	limits AttachmentLimits
+}
+
+// NewLimitedAttachmentStore wraps an attachment store with the given limits
+func NewLimitedAttachmentStore(inner AttachmentStore, limits AttachmentLimits) *LimitedAttachmentStore {
+	return &LimitedAttachmentStore{
+		AttachmentStore: inner,
+		limits:          limits,
+	}
+}
+
+// Add checks the size of the attachment and the number of attachments of the task before storing it
+func (s *LimitedAttachmentStore) Add(ctx context.Context, attachment *models.Attachment) error {
+	if err := checkLength("content", attachment.Content, s.limits.MaxSize); err != nil {
+		return err
+	}
+	if s.limits.MaxPerTask > 0 {
+		existing, err := s.List(ctx, attachment.TaskID)
+		if err != nil {
+			return err
+		}
+		if len(existing) >= s.limits.MaxPerTask {
+			return fmt.Errorf("%w: the task has %d attachments, the maximum is %d",
+				ErrLimitExceeded, len(existing), s.limits.MaxPerTask)
+		}
+	}
+	return s.AttachmentStore.Add(ctx, attachment)
+}
+
+// Ensure the concrete types implement the interface
+var (
+	_ AttachmentStore = (*ValkeyAttachmentStore)(nil)
+	_ AttachmentStore = (*LimitedAttachmentStore)(nil)
+)
@@ -0,0 +1,243 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/valkey-io/valkey-glide/go/v2/constants"
+	glidemodels "github.com/valkey-io/valkey-glide/go/v2/models"
+	"github.com/valkey-io/valkey-glide/go/v2/options"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// DefaultAuditMaxEntries is the number of history entries kept per entity when no retention is configured
+const DefaultAuditMaxEntries = 1000
+
+// AuditRetention controls how much history is kept per entity
+type AuditRetention struct {
+	// MaxEntries is the approximate maximum number of entries kept per entity; zero keeps all entries
+	MaxEntries int64
+	// MaxAge drops entries older than this duration; zero keeps entries regardless of age
+	MaxAge time.Duration
+}
+
+// AuditLog records an append-only history of changes, using one Valkey stream per entity
+type AuditLog struct {
+	client    *ValkeyClient
+	retention AuditRetention
+	listener  ChangeListener
+}
+
+// NewAuditLog creates a new audit log with the given retention policy
+func NewAuditLog(client *ValkeyClient, retention AuditRetention) *AuditLog {
+	return &AuditLog{
+		client:    client,
+		retention: retention,
+	}
+}
+
+// SetChangeListener sets the listener told about the changes recorded by the repositories, such as to notify
+// the watchers of a plan. A nil listener turns it off.
+func (a *AuditLog) SetChangeListener(listener ChangeListener) {
+	a.listener = listener
+}
+
+// Record appends an entry to the history of its entity and applies the retention policy
+func (a *AuditLog) Record(ctx context.Context, entry *models.AuditEntry) error {
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit changes: %w", err)
+	}
+	before, err := a.client.seal(string(entry.Before))
+	if err != nil {
+		return err
+	}
+	after, err := a.client.seal(string(entry.After))
+	if err != nil {
+		return err
+	}
+	sealedChanges, err := a.client.seal(string(changes))
+	if err != nil {
+		return err
+	}
+
+	fields := []glidemodels.FieldValue{
+		{Field: "entity_type", Value: string(entry.EntityType)},
+		{Field: "entity_id", Value: entry.EntityID},
+		{Field: "action", Value: string(entry.Action)},
+		{Field: "operation", Value: entry.Operation},
+		{Field: "actor", Value: entry.Actor},
+		{Field: "timestamp", Value: entry.Timestamp.Format(time.RFC3339Nano)},
+		{Field: "before", Value: before},
+		{Field: "after", Value: after},
+		{Field: "changes", Value: sealedChanges},
+	}
+
+	key := GetHistoryKey(entry.EntityType, entry.EntityID)
+	addOpts := options.NewXAddOptions()
+	if a.retention.MaxEntries > 0 {
+		addOpts.SetTrimOptions(options.NewXTrimOptionsWithMaxLen(a.retention.MaxEntries).SetNearlyExactTrimming())
+	}
+
+	id, err := a.client.client.XAddWithOptions(ctx, key, fields, *addOpts)
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	entry.ID = id.Value()
+	// Entries of staged writes get their ID once the transaction is written
+	afterCommit(ctx, func(context.Context) {
+		entry.ID = committedStreamID(ctx, entry.ID)
+	})
+
+	// Stream IDs start with a millisecond timestamp, so old entries can be trimmed by minimum ID
+	if a.retention.MaxAge > 0 {
+		minID := fmt.Sprintf("%d-0", time.Now().Add(-a.retention.MaxAge).UnixMilli())
+		_, err = a.client.client.XTrim(ctx, key, *options.NewXTrimOptionsWithMinId(minID).SetNearlyExactTrimming())
+		if err != nil {
+			return fmt.Errorf("failed to trim audit history: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// History returns the most recent history entries of an entity, newest first.
+// A limit of zero or less returns all retained entries.
+func (a *AuditLog) History(
+	ctx context.Context,
+	entityType models.EntityType,
+	entityID string,
+	limit int64,
+) ([]*models.AuditEntry, error) {
+	rangeOpts := options.NewXRangeOptions()
+	if limit > 0 {
+		rangeOpts.SetCount(limit)
+	}
+
+	streamEntries, err := a.client.client.XRevRangeWithOptions(
+		ctx,
+		GetHistoryKey(entityType, entityID),
+		options.NewInfiniteStreamBoundary(constants.PositiveInfinity),
+		options.NewInfiniteStreamBoundary(constants.NegativeInfinity),
+		*rangeOpts,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit history: %w", err)
+	}
+
+	entries := make([]*models.AuditEntry, 0, len(streamEntries))
+	for _, streamEntry := range streamEntries {
+		entry, err := a.parseAuditEntry(streamEntry)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// recordChange builds an audit entry from snapshots and records it.
+// Failures are logged rather than returned so that auditing never fails the change itself.
+func (a *AuditLog) recordChange(
+	ctx context.Context,
+	entityType models.EntityType,
+	entityID string,
+	action models.AuditAction,
+	operation string,
+	before, after json.RawMessage,
+) {
+	changes, err := models.DiffSnapshots(before, after)
+	if err != nil {
+		log.Printf("Warning: failed to diff %s %s for audit: %v", entityType, entityID, err)
+	}
+
+	entry := &models.AuditEntry{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Operation:  operation,
+		Actor:      ActorFromContext(ctx),
+		Timestamp:  time.Now(),
+		Before:     before,
+		After:      after,
+		Changes:    changes,
+	}
+
+	if err := a.Record(ctx, entry); err != nil {
+		log.Printf("Warning: failed to audit %s of %s %s: %v", operation, entityType, entityID, err)
+	}
+	if a.listener != nil {
+		afterCommit(ctx, func(ctx context.Context) {
+			a.listener.Changed(ctx, entry)
+		})
+	}
+}
+
+// parseAuditEntry converts a stream entry into an audit entry
+func (a *AuditLog) parseAuditEntry(streamEntry glidemodels.StreamEntry) (*models.AuditEntry, error) {
+	entry := &models.AuditEntry{ID: streamEntry.ID}
+
+	for _, field := range streamEntry.Fields {
+		switch field.Field {
+		case "before", "after", "changes":
+			value, err := a.client.open(field.Value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read audit %s: %w", field.Field, err)
+			}
+			field.Value = value
+		}
+
+		switch field.Field {
+		case "entity_type":
+			entry.EntityType = models.EntityType(field.Value)
+		case "entity_id":
+			entry.EntityID = field.Value
+		case "action":
+			entry.Action = models.AuditAction(field.Value)
+		case "operation":
+			entry.Operation = field.Value
+		case "actor":
+			entry.Actor = field.Value
+		case "timestamp":
+			timestamp, err := time.Parse(time.RFC3339Nano, field.Value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse audit timestamp: %w", err)
+			}
+			entry.Timestamp = timestamp
+		case "before":
+			if field.Value != "" {
+				entry.Before = json.RawMessage(field.Value)
+			}
+		case "after":
+			if field.Value != "" {
+				entry.After = json.RawMessage(field.Value)
+			}
+		case "changes":
+			if field.Value != "" && field.Value != "null" {
+				if err := json.Unmarshal([]byte(field.Value), &entry.Changes); err != nil {
+					return nil, fmt.Errorf("failed to parse audit changes: %w", err)
+				}
+			}
+		}
+	}
+
+	return entry, nil
+}
+
+// snapshot encodes an entity as a JSON snapshot, returning nil for a nil entity
+func snapshot[T any](entity *T) json.RawMessage {
+	if entity == nil {
+		return nil
+	}
+	data, err := json.Marshal(entity)
+	if err != nil {
+		log.Printf("Warning: failed to snapshot entity for audit: %v", err)
+		return nil
+	}
+	return data
+}
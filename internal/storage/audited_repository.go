@@ -0,0 +1,569 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// AuditedPlanRepository decorates a plan repository and records every mutation in the audit log
+type AuditedPlanRepository struct {
+	PlanRepositoryInterface
+	audit *AuditLog
+}
+
+// NewAuditedPlanRepository wraps a plan repository with audit logging
+func NewAuditedPlanRepository(inner PlanRepositoryInterface, audit *AuditLog) *AuditedPlanRepository {
+	return &AuditedPlanRepository{
+		PlanRepositoryInterface: inner,
+		audit:                   audit,
+	}
+}
+
+// Create creates a plan and records its creation
+func (r *AuditedPlanRepository) Create(
+	ctx context.Context,
+	applicationID, name, description string,
+) (*models.Plan, error) {
+	plan, err := r.PlanRepositoryInterface.Create(ctx, applicationID, name, description)
+	if err != nil {
+		return nil, err
+	}
+
+	r.recordCreate(ctx, plan, "create")
+	return plan, nil
+}
+
+// Update updates a plan and records the change
+func (r *AuditedPlanRepository) Update(ctx context.Context, plan *models.Plan) error {
+	before := r.current(ctx, plan.ID)
+
+	err := r.PlanRepositoryInterface.Update(ctx, plan)
+	if err != nil {
+		return err
+	}
+
+	r.recordUpdate(ctx, plan.ID, "update", before)
+	return nil
+}
+
+// UpdateStatus sets the status of a plan and records the change
+func (r *AuditedPlanRepository) UpdateStatus(
+	ctx context.Context,
+	id string,
+	status models.PlanStatus,
+) (*models.Plan, error) {
+	return r.mutate(ctx, id, "update_status", func() (*models.Plan, error) {
+		return r.PlanRepositoryInterface.UpdateStatus(ctx, id, status)
+	})
+}
+
+// Delete deletes a plan and records its final state
+func (r *AuditedPlanRepository) Delete(ctx context.Context, id string) error {
+	before := r.current(ctx, id)
+
+	err := r.PlanRepositoryInterface.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	r.audit.recordChange(ctx, models.EntityTypePlan, id, models.AuditActionDelete, "delete", snapshot(before), nil)
+	return nil
+}
+
+// Clone clones a plan and records the creation of the new plan
+func (r *AuditedPlanRepository) Clone(ctx context.Context, id string, opts PlanCloneOptions) (*models.Plan, error) {
+	plan, err := r.PlanRepositoryInterface.Clone(ctx, id, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r.recordCreate(ctx, plan, "clone")
+	return plan, nil
+}
+
+// Restore restores a plan snapshot and records the change
+func (r *AuditedPlanRepository) Restore(ctx context.Context, plan *models.Plan) error {
+	before := r.current(ctx, plan.ID)
+
+	err := r.PlanRepositoryInterface.Restore(ctx, plan)
+	if err != nil {
+		return err
+	}
+
+	if before == nil {
+		after := snapshot(r.current(ctx, plan.ID))
+		r.audit.recordChange(ctx, models.EntityTypePlan, plan.ID, models.AuditActionCreate, "restore", nil, after)
+		return nil
+	}
+	r.recordUpdate(ctx, plan.ID, "restore", before)
+	return nil
+}
+
+// UpdateNotes updates the notes of a plan and records the change
+func (r *AuditedPlanRepository) UpdateNotes(ctx context.Context, id string, notes string) error {
+	before := r.current(ctx, id)
+
+	err := r.PlanRepositoryInterface.UpdateNotes(ctx, id, notes)
+	if err != nil {
+		return err
+	}
+
+	r.recordUpdate(ctx, id, "update_notes", before)
+	return nil
+}
+
+// AppendNotes appends to the notes of a plan and records the change
+func (r *AuditedPlanRepository) AppendNotes(ctx context.Context, id string, text string) (string, error) {
+	before := r.current(ctx, id)
+
+	notes, err := r.PlanRepositoryInterface.AppendNotes(ctx, id, text)
+	if err != nil {
+		return "", err
+	}
+
+	r.recordUpdate(ctx, id, "append_notes", before)
+	return notes, nil
+}
+
+// RevertNotes reverts the notes of a plan to a revision and records the change
+func (r *AuditedPlanRepository) RevertNotes(ctx context.Context, id, revisionID string) (string, error) {
+	before := r.current(ctx, id)
+
+	notes, err := r.PlanRepositoryInterface.RevertNotes(ctx, id, revisionID)
+	if err != nil {
+		return "", err
+	}
+
+	r.recordUpdate(ctx, id, "revert_notes", before)
+	return notes, nil
+}
+
+// ReorderPlan moves a plan among the plans of its application and records the change of its order.
+// Other plans renumbered by the move are not recorded.
+func (r *AuditedPlanRepository) ReorderPlan(ctx context.Context, id string, newOrder int) (*models.Plan, error) {
+	return r.mutate(ctx, id, "reorder", func() (*models.Plan, error) {
+		return r.PlanRepositoryInterface.ReorderPlan(ctx, id, newOrder)
+	})
+}
+
+// SetMetadata sets plan metadata and records the change
+func (r *AuditedPlanRepository) SetMetadata(
+	ctx context.Context,
+	id string,
+	metadata map[string]string,
+) (*models.Plan, error) {
+	return r.mutate(ctx, id, "set_metadata", func() (*models.Plan, error) {
+		return r.PlanRepositoryInterface.SetMetadata(ctx, id, metadata)
+	})
+}
+
+// DeleteMetadata deletes plan metadata and records the change
+func (r *AuditedPlanRepository) DeleteMetadata(ctx context.Context, id string, keys []string) (*models.Plan, error) {
+	return r.mutate(ctx, id, "delete_metadata", func() (*models.Plan, error) {
+		return r.PlanRepositoryInterface.DeleteMetadata(ctx, id, keys)
+	})
+}
+
+// SetLock locks or unlocks a plan and records the change
+func (r *AuditedPlanRepository) SetLock(ctx context.Context, id string, lock *models.PlanLock) (*models.Plan, error) {
+	operation := "lock"
+	if lock == nil {
+		operation = "unlock"
+	}
+	return r.mutate(ctx, id, operation, func() (*models.Plan, error) {
+		return r.PlanRepositoryInterface.SetLock(ctx, id, lock)
+	})
+}
+
+// mutate runs a mutation that returns the updated plan and records the change
+func (r *AuditedPlanRepository) mutate(
+	ctx context.Context,
+	id, operation string,
+	fn func() (*models.Plan, error),
+) (*models.Plan, error) {
+	before := r.current(ctx, id)
+
+	plan, err := fn()
+	if err != nil {
+		return nil, err
+	}
+
+	r.recordPlanUpdate(ctx, id, operation, before, plan)
+	return plan, nil
+}
+
+// recordUpdate reloads a plan after a mutation and records the change
+func (r *AuditedPlanRepository) recordUpdate(ctx context.Context, id, operation string, before *models.Plan) {
+	r.recordPlanUpdate(ctx, id, operation, before, r.current(ctx, id))
+}
+
+// recordPlanUpdate records the change of a plan between two states
+func (r *AuditedPlanRepository) recordPlanUpdate(ctx context.Context, id, operation string, before, after *models.Plan) {
+	r.audit.recordChange(ctx, models.EntityTypePlan, id, models.AuditActionUpdate, operation, snapshot(before), snapshot(after))
+}
+
+// recordCreate records the creation of a plan. The plan is loaded again, so the snapshot has the precision of
+// the stored timestamps and matches the plan when an undo compares them.
+func (r *AuditedPlanRepository) recordCreate(ctx context.Context, plan *models.Plan, operation string) {
+	if stored := r.current(ctx, plan.ID); stored != nil {
+		plan = stored
+	}
+	r.audit.recordChange(ctx, models.EntityTypePlan, plan.ID, models.AuditActionCreate, operation, nil, snapshot(plan))
+}
+
+// current returns the current state of a plan, or nil if it cannot be loaded
+func (r *AuditedPlanRepository) current(ctx context.Context, id string) *models.Plan {
+	plan, err := r.PlanRepositoryInterface.Get(ctx, id)
+	if err != nil {
+		return nil
+	}
+	return plan
+}
+
+// AuditedTaskRepository decorates a task repository and records every mutation in the audit log.
+// Lease renewals are heartbeats rather than changes and are not recorded.
+type AuditedTaskRepository struct {
+	TaskRepositoryInterface
+	audit *AuditLog
+}
+
+// NewAuditedTaskRepository wraps a task repository with audit logging
+func NewAuditedTaskRepository(inner TaskRepositoryInterface, audit *AuditLog) *AuditedTaskRepository {
+	return &AuditedTaskRepository{
+		TaskRepositoryInterface: inner,
+		audit:                   audit,
+	}
+}
+
+// Create creates a task and records its creation
+func (r *AuditedTaskRepository) Create(
+	ctx context.Context,
+	planID, title, description string,
+	priority models.TaskPriority,
+) (*models.Task, error) {
+	task, err := r.TaskRepositoryInterface.Create(ctx, planID, title, description, priority)
+	if err != nil {
+		return nil, err
+	}
+
+	r.recordCreate(ctx, task, "create")
+	return task, nil
+}
+
+// CreateBulk creates tasks and records the creation of each one
+func (r *AuditedTaskRepository) CreateBulk(
+	ctx context.Context,
+	planID string,
+	tasks []TaskCreateInput,
+) ([]*models.Task, error) {
+	created, err := r.TaskRepositoryInterface.CreateBulk(ctx, planID, tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, task := range created {
+		r.recordCreate(ctx, task, "create_bulk")
+	}
+	return created, nil
+}
+
+// CreateBulkWithOptions creates tasks with deduplication and records each created and merged task
+func (r *AuditedTaskRepository) CreateBulkWithOptions(
+	ctx context.Context,
+	planID string,
+	tasks []TaskCreateInput,
+	opts BulkCreateOptions,
+) (*BulkCreateReport, error) {
+	// Merges update existing tasks, so capture their state first
+	before := make(map[string]*models.Task)
+	if opts.Dedup == DedupModeMerge {
+		existing, err := r.TaskRepositoryInterface.ListByPlan(ctx, planID)
+		if err == nil {
+			for _, task := range existing {
+				before[task.ID] = task
+			}
+		}
+	}
+
+	report, err := r.TaskRepositoryInterface.CreateBulkWithOptions(ctx, planID, tasks, opts)
+	if report == nil {
+		return nil, err
+	}
+
+	for _, task := range report.Created {
+		r.recordCreate(ctx, task, "create_bulk")
+	}
+
+	recorded := make(map[string]bool)
+	for _, merged := range report.Merged {
+		previous, ok := before[merged.TaskID]
+		if !ok || recorded[merged.TaskID] {
+			continue
+		}
+		recorded[merged.TaskID] = true
+
+		// Matches that added nothing new leave the task untouched
+		after := r.current(ctx, merged.TaskID)
+		if after != nil && !after.UpdatedAt.Equal(previous.UpdatedAt) {
+			r.recordTaskUpdate(ctx, merged.TaskID, "merge", previous, after)
+		}
+	}
+	return report, err
+}
+
+// Update updates a task and records the change, including any next occurrence it schedules
+func (r *AuditedTaskRepository) Update(ctx context.Context, task *models.Task) error {
+	before := r.current(ctx, task.ID)
+
+	err := r.TaskRepositoryInterface.Update(ctx, task)
+	if err != nil {
+		return err
+	}
+
+	r.recordUpdate(ctx, task.ID, "update", before)
+
+	if task.NextOccurrenceID != "" && (before == nil || before.NextOccurrenceID != task.NextOccurrenceID) {
+		if next := r.current(ctx, task.NextOccurrenceID); next != nil {
+			r.recordStoredCreate(ctx, next, "recur")
+		}
+	}
+	return nil
+}
+
+// Delete deletes a task and records its final state
+func (r *AuditedTaskRepository) Delete(ctx context.Context, id string) error {
+	before := r.current(ctx, id)
+
+	err := r.TaskRepositoryInterface.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	r.audit.recordChange(ctx, models.EntityTypeTask, id, models.AuditActionDelete, "delete", snapshot(before), nil)
+	return nil
+}
+
+// ReorderTask moves a task within its plan and records the change of the moved task
+func (r *AuditedTaskRepository) ReorderTask(ctx context.Context, taskID string, newOrder int) error {
+	before := r.current(ctx, taskID)
+
+	err := r.TaskRepositoryInterface.ReorderTask(ctx, taskID, newOrder)
+	if err != nil {
+		return err
+	}
+
+	r.recordUpdate(ctx, taskID, "reorder", before)
+	return nil
+}
+
+// ReorderTasks puts the tasks of a plan in a new order and records the change of every task that moved
+func (r *AuditedTaskRepository) ReorderTasks(ctx context.Context, planID string, taskIDs []string) ([]*models.Task, error) {
+	before := make(map[string]*models.Task)
+	if tasks, err := r.TaskRepositoryInterface.ListByPlan(ctx, planID); err == nil {
+		for _, task := range tasks {
+			before[task.ID] = task
+		}
+	}
+
+	tasks, err := r.TaskRepositoryInterface.ReorderTasks(ctx, planID, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, task := range tasks {
+		if previous, ok := before[task.ID]; ok && previous.Order == task.Order {
+			continue
+		}
+		r.recordTaskUpdate(ctx, task.ID, "reorder", before[task.ID], task)
+	}
+	return tasks, nil
+}
+
+// MoveTask moves a task to another plan and records the change of the moved task
+func (r *AuditedTaskRepository) MoveTask(ctx context.Context, taskID, planID string, position int) (*models.Task, error) {
+	before := r.current(ctx, taskID)
+
+	task, err := r.TaskRepositoryInterface.MoveTask(ctx, taskID, planID, position)
+	if err != nil {
+		return nil, err
+	}
+
+	r.recordUpdate(ctx, taskID, "move", before)
+	return task, nil
+}
+
+// Restore restores a task snapshot and records the change
+func (r *AuditedTaskRepository) Restore(ctx context.Context, task *models.Task) error {
+	before := r.current(ctx, task.ID)
+
+	err := r.TaskRepositoryInterface.Restore(ctx, task)
+	if err != nil {
+		return err
+	}
+
+	if before == nil {
+		if after := r.current(ctx, task.ID); after != nil {
+			r.recordStoredCreate(ctx, after, "restore")
+		}
+		return nil
+	}
+	r.recordUpdate(ctx, task.ID, "restore", before)
+	return nil
+}
+
+// UpdateNotes updates the notes of a task and records the change
+func (r *AuditedTaskRepository) UpdateNotes(ctx context.Context, id string, notes string) error {
+	before := r.current(ctx, id)
+
+	err := r.TaskRepositoryInterface.UpdateNotes(ctx, id, notes)
+	if err != nil {
+		return err
+	}
+
+	r.recordUpdate(ctx, id, "update_notes", before)
+	return nil
+}
+
+// ClaimTask claims a task and records the change
+func (r *AuditedTaskRepository) ClaimTask(
+	ctx context.Context,
+	taskID, workerID string,
+	ttl time.Duration,
+) (*models.Task, error) {
+	return r.mutate(ctx, taskID, "claim", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.ClaimTask(ctx, taskID, workerID, ttl)
+	})
+}
+
+// ExpireLeases releases expired leases and records the change of each released task.
+// The state before the release is no longer available, so only the resulting state is recorded.
+func (r *AuditedTaskRepository) ExpireLeases(ctx context.Context) ([]string, error) {
+	released, err := r.TaskRepositoryInterface.ExpireLeases(ctx)
+
+	for _, taskID := range released {
+		r.recordUpdate(ctx, taskID, "expire_lease", nil)
+	}
+	return released, err
+}
+
+// AddTags tags a task and records the change
+func (r *AuditedTaskRepository) AddTags(ctx context.Context, taskID string, tags []string) (*models.Task, error) {
+	return r.mutate(ctx, taskID, "add_tags", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.AddTags(ctx, taskID, tags)
+	})
+}
+
+// RemoveTags untags a task and records the change
+func (r *AuditedTaskRepository) RemoveTags(ctx context.Context, taskID string, tags []string) (*models.Task, error) {
+	return r.mutate(ctx, taskID, "remove_tags", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.RemoveTags(ctx, taskID, tags)
+	})
+}
+
+// AddChecklistItem adds a checklist item and records the change
+func (r *AuditedTaskRepository) AddChecklistItem(ctx context.Context, taskID, text string) (*models.Task, error) {
+	return r.mutate(ctx, taskID, "add_checklist_item", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.AddChecklistItem(ctx, taskID, text)
+	})
+}
+
+// ToggleChecklistItem toggles a checklist item and records the change
+func (r *AuditedTaskRepository) ToggleChecklistItem(
+	ctx context.Context,
+	taskID, itemID string,
+	done *bool,
+) (*models.Task, error) {
+	return r.mutate(ctx, taskID, "toggle_checklist_item", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.ToggleChecklistItem(ctx, taskID, itemID, done)
+	})
+}
+
+// RemoveChecklistItem removes a checklist item and records the change
+func (r *AuditedTaskRepository) RemoveChecklistItem(ctx context.Context, taskID, itemID string) (*models.Task, error) {
+	return r.mutate(ctx, taskID, "remove_checklist_item", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.RemoveChecklistItem(ctx, taskID, itemID)
+	})
+}
+
+// LogTime logs time on a task and records the change
+func (r *AuditedTaskRepository) LogTime(ctx context.Context, taskID string, duration time.Duration) (*models.Task, error) {
+	return r.mutate(ctx, taskID, "log_time", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.LogTime(ctx, taskID, duration)
+	})
+}
+
+// SetMetadata sets task metadata and records the change
+func (r *AuditedTaskRepository) SetMetadata(
+	ctx context.Context,
+	id string,
+	metadata map[string]string,
+) (*models.Task, error) {
+	return r.mutate(ctx, id, "set_metadata", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.SetMetadata(ctx, id, metadata)
+	})
+}
+
+// DeleteMetadata deletes task metadata and records the change
+func (r *AuditedTaskRepository) DeleteMetadata(ctx context.Context, id string, keys []string) (*models.Task, error) {
+	return r.mutate(ctx, id, "delete_metadata", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.DeleteMetadata(ctx, id, keys)
+	})
+}
+
+// mutate runs a mutation that returns the updated task and records the change
+func (r *AuditedTaskRepository) mutate(
+	ctx context.Context,
+	id, operation string,
+	fn func() (*models.Task, error),
+) (*models.Task, error) {
+	before := r.current(ctx, id)
+
+	task, err := fn()
+	if err != nil {
+		return nil, err
+	}
+
+	r.recordTaskUpdate(ctx, id, operation, before, task)
+	return task, nil
+}
+
+// recordCreate records the creation of a task. The task is loaded again, so the snapshot has the precision of
+// the stored timestamps and matches the task when an undo compares them.
+func (r *AuditedTaskRepository) recordCreate(ctx context.Context, task *models.Task, operation string) {
+	if stored := r.current(ctx, task.ID); stored != nil {
+		task = stored
+	}
+	r.recordStoredCreate(ctx, task, operation)
+}
+
+// recordStoredCreate records the creation of a task loaded from storage
+func (r *AuditedTaskRepository) recordStoredCreate(ctx context.Context, task *models.Task, operation string) {
+	r.audit.recordChange(ctx, models.EntityTypeTask, task.ID, models.AuditActionCreate, operation, nil, snapshot(task))
+}
+
+// recordUpdate reloads a task after a mutation and records the change
+func (r *AuditedTaskRepository) recordUpdate(ctx context.Context, id, operation string, before *models.Task) {
+	r.recordTaskUpdate(ctx, id, operation, before, r.current(ctx, id))
+}
+
+// recordTaskUpdate records the change of a task between two states
+func (r *AuditedTaskRepository) recordTaskUpdate(ctx context.Context, id, operation string, before, after *models.Task) {
+	r.audit.recordChange(ctx, models.EntityTypeTask, id, models.AuditActionUpdate, operation, snapshot(before), snapshot(after))
+}
+
+// current returns the current state of a task, or nil if it cannot be loaded
+func (r *AuditedTaskRepository) current(ctx context.Context, id string) *models.Task {
+	task, err := r.TaskRepositoryInterface.Get(ctx, id)
+	if err != nil {
+		return nil
+	}
+	return task
+}
+
+// Ensure the audited types implement the interfaces
+var (
+	_ PlanRepositoryInterface = (*AuditedPlanRepository)(nil)
+	_ TaskRepositoryInterface = (*AuditedTaskRepository)(nil)
+)
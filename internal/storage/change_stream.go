@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/valkey-io/valkey-glide/go/v2/constants"
+	glidemodels "github.com/valkey-io/valkey-glide/go/v2/models"
+	"github.com/valkey-io/valkey-glide/go/v2/options"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// DefaultChangeStreamLength is the approximate number of changes kept in the change stream
+const DefaultChangeStreamLength = 10000
+
+// ChangeStream publishes the changes recorded in the audit log to a Valkey stream shared by every replica, so
+// each replica learns about the changes made through the others. Each entry holds the entity type, entity ID
+// and action of a change.
+type ChangeStream struct {
+	client    *ValkeyClient
+	maxLength int64
+}
+
+// NewChangeStream creates a change stream trimmed to about maxLength entries; zero keeps all entries
+func NewChangeStream(client *ValkeyClient, maxLength int64) *ChangeStream {
+	return &ChangeStream{client: client, maxLength: maxLength}
+}
+
+// Changed publishes a change recorded in the audit log. Failures are logged rather than returned, like those
+// of the audit log, so publishing never fails the change itself.
+func (s *ChangeStream) Changed(ctx context.Context, entry *models.AuditEntry) {
+	addOpts := options.NewXAddOptions()
+	if s.maxLength > 0 {
+		addOpts.SetTrimOptions(options.NewXTrimOptionsWithMaxLen(s.maxLength).SetNearlyExactTrimming())
+	}
+	_, err := s.client.client.XAddWithOptions(ctx, changesKey, []glidemodels.FieldValue{
+		{Field: "entity_type", Value: string(entry.EntityType)},
+		{Field: "entity_id", Value: entry.EntityID},
+		{Field: "action", Value: string(entry.Action)},
+	}, *addOpts)
+	if err != nil {
+		log.Printf("Warning: failed to publish change of %s %s: %v", entry.EntityType, entry.EntityID, err)
+	}
+}
+
+// LastID returns the ID of the latest change in the stream, empty when no change was published
+func (s *ChangeStream) LastID(ctx context.Context) (string, error) {
+	entries, err := s.client.client.XRevRangeWithOptions(
+		ctx,
+		changesKey,
+		options.NewInfiniteStreamBoundary(constants.PositiveInfinity),
+		options.NewInfiniteStreamBoundary(constants.NegativeInfinity),
+		*options.NewXRangeOptions().SetCount(1),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to read change stream: %w", err)
+	}
+	if len(entries) == 0 {
+		return "", nil
+	}
+	return entries[0].ID, nil
+}
+
+// Follow checks the stream every interval and calls changed when changes were published since the previous
+// check, until the context is cancelled. Changed is also called when the stream cannot be read, as changes
+// may then be missed.
+func (s *ChangeStream) Follow(ctx context.Context, interval time.Duration, changed func()) {
+	lastID, err := s.LastID(ctx)
+	failing := err != nil
+	if failing {
+		log.Printf("Warning: %v", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		id, err := s.LastID(ctx)
+		if err != nil {
+			if !failing && ctx.Err() == nil {
+				log.Printf("Warning: %v", err)
+			}
+			failing = true
+			changed()
+			continue
+		}
+		if failing {
+			log.Printf("Change stream readable again")
+			failing = false
+		}
+		if id != lastID {
+			lastID = id
+			changed()
+		}
+	}
+}
+
+// Ensure the change stream implements the interface
+var _ ChangeListener = (*ChangeStream)(nil)
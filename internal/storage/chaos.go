@@ -0,0 +1,701 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	glide "github.com/valkey-io/valkey-glide/go/v2"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// Failure injection exists for tests and staging. The repositories of a server are wrapped in chaos
+// repositories, which delay calls and fail a share of them before they reach the storage, so the behavior of
+// the layers above on a slow or unreliable Valkey can be observed: retries of reads, bulk tool calls that fail
+// part way through their items, and reorders that race when calls are slowed down.
+
+// ErrInjectedFailure is the error of the calls failed by failure injection. It is a Valkey timeout, the most
+// common transient failure, so reads failing with it are retried like real timeouts.
+var ErrInjectedFailure = glide.NewTimeoutError("injected failure")
+
+// ChaosConfig configures the failures and latency injected into repository calls. The zero value injects
+// nothing.
+type ChaosConfig struct {
+	// FailureRate is the share of calls, from 0 to 1, that fail with ErrInjectedFailure
+	FailureRate float64
+	// Latency delays every call
+	Latency time.Duration
+	// LatencyJitter adds a random delay of up to this much to every call
+	LatencyJitter time.Duration
+	// Operations limits injection to these calls, named by their method such as "ReorderTask" or qualified by
+	// their repository such as "task.Create", "plan.Create" or "application.Get". All calls when empty.
+	Operations []string
+	// Seed seeds the choice of the failed calls, so a run can be repeated. A random seed when zero.
+	Seed int64
+}
+
+// Enabled reports whether the configuration injects failures or latency
+func (c ChaosConfig) Enabled() bool {
+	return c.FailureRate > 0 || c.Latency > 0 || c.LatencyJitter > 0
+}
+
+// ParseChaosOperations parses a comma separated list of operations, such as "CreateBulk,task.ReorderTask"
+func ParseChaosOperations(spec string) []string {
+	var operations []string
+	for _, operation := range strings.Split(spec, ",") {
+		if operation = strings.TrimSpace(operation); operation != "" {
+			operations = append(operations, operation)
+		}
+	}
+	return operations
+}
+
+// Chaos decides which repository calls fail and how long they are delayed. It is shared by the chaos
+// repositories of a server, so one seed gives one sequence of failures.
+type Chaos struct {
+	config     ChaosConfig
+	operations map[string]bool
+
+	mu     sync.Mutex
+	random *rand.Rand
+}
+
+// NewChaos creates the failure injection of a configuration
+func NewChaos(config ChaosConfig) *Chaos {
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	operations := make(map[string]bool, len(config.Operations))
+	for _, operation := range config.Operations {
+		operations[operation] = true
+	}
+	return &Chaos{
+		config:     config,
+		operations: operations,
+		random:     rand.New(rand.NewSource(seed)),
+	}
+}
+
+// inject delays a call and decides whether it fails. It returns the error of ctx when ctx is done first.
+func (c *Chaos) inject(ctx context.Context, repository, method string) error {
+	if len(c.operations) > 0 && !c.operations[method] && !c.operations[repository+"."+method] {
+		return nil
+	}
+
+	c.mu.Lock()
+	delay := c.config.Latency
+	if c.config.LatencyJitter > 0 {
+		delay += time.Duration(c.random.Int63n(int64(c.config.LatencyJitter) + 1))
+	}
+	fail := c.config.FailureRate > 0 && c.random.Float64() < c.config.FailureRate
+	c.mu.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if fail {
+		return fmt.Errorf("%s.%s: %w", repository, method, ErrInjectedFailure)
+	}
+	return nil
+}
+
+// chaosCall runs a call of a repository unless failure injection fails it first
+func chaosCall[T any](
+	ctx context.Context,
+	chaos *Chaos,
+	repository, method string,
+	call func() (T, error),
+) (T, error) {
+	if err := chaos.inject(ctx, repository, method); err != nil {
+		var zero T
+		return zero, err
+	}
+	return call()
+}
+
+// ChaosPlanRepository decorates a plan repository and injects failures and latency into its calls
+type ChaosPlanRepository struct {
+	PlanRepositoryInterface
+	chaos *Chaos
+}
+
+// NewChaosPlanRepository wraps a plan repository with failure injection
+func NewChaosPlanRepository(inner PlanRepositoryInterface, chaos *Chaos) *ChaosPlanRepository {
+	return &ChaosPlanRepository{
+		PlanRepositoryInterface: inner,
+		chaos:                   chaos,
+	}
+}
+
+// Create creates a plan
+func (r *ChaosPlanRepository) Create(
+	ctx context.Context,
+	applicationID, name, description string,
+) (*models.Plan, error) {
+	return chaosCall(ctx, r.chaos, "plan", "Create", func() (*models.Plan, error) {
+		return r.PlanRepositoryInterface.Create(ctx, applicationID, name, description)
+	})
+}
+
+// Get retrieves a plan by ID
+func (r *ChaosPlanRepository) Get(ctx context.Context, id string) (*models.Plan, error) {
+	return chaosCall(ctx, r.chaos, "plan", "Get", func() (*models.Plan, error) {
+		return r.PlanRepositoryInterface.Get(ctx, id)
+	})
+}
+
+// Update updates a plan
+func (r *ChaosPlanRepository) Update(ctx context.Context, plan *models.Plan) error {
+	if err := r.chaos.inject(ctx, "plan", "Update"); err != nil {
+		return err
+	}
+	return r.PlanRepositoryInterface.Update(ctx, plan)
+}
+
+// UpdateStatus sets the status of a plan
+func (r *ChaosPlanRepository) UpdateStatus(
+	ctx context.Context,
+	id string,
+	status models.PlanStatus,
+) (*models.Plan, error) {
+	return chaosCall(ctx, r.chaos, "plan", "UpdateStatus", func() (*models.Plan, error) {
+		return r.PlanRepositoryInterface.UpdateStatus(ctx, id, status)
+	})
+}
+
+// Delete deletes a plan
+func (r *ChaosPlanRepository) Delete(ctx context.Context, id string) error {
+	if err := r.chaos.inject(ctx, "plan", "Delete"); err != nil {
+		return err
+	}
+	return r.PlanRepositoryInterface.Delete(ctx, id)
+}
+
+// List returns all plans
+func (r *ChaosPlanRepository) List(ctx context.Context) ([]*models.Plan, error) {
+	return chaosCall(ctx, r.chaos, "plan", "List", func() ([]*models.Plan, error) {
+		return r.PlanRepositoryInterface.List(ctx)
+	})
+}
+
+// ListByApplication retrieves all plans for a specific application
+func (r *ChaosPlanRepository) ListByApplication(ctx context.Context, applicationID string) ([]*models.Plan, error) {
+	return chaosCall(ctx, r.chaos, "plan", "ListByApplication", func() ([]*models.Plan, error) {
+		return r.PlanRepositoryInterface.ListByApplication(ctx, applicationID)
+	})
+}
+
+// ListByStatus retrieves all plans with a specific status
+func (r *ChaosPlanRepository) ListByStatus(ctx context.Context, status models.PlanStatus) ([]*models.Plan, error) {
+	return chaosCall(ctx, r.chaos, "plan", "ListByStatus", func() ([]*models.Plan, error) {
+		return r.PlanRepositoryInterface.ListByStatus(ctx, status)
+	})
+}
+
+// ListApplications returns the applications the plans refer to with their number of plans
+func (r *ChaosPlanRepository) ListApplications(ctx context.Context) ([]*models.ApplicationPlans, error) {
+	return chaosCall(ctx, r.chaos, "plan", "ListApplications", func() ([]*models.ApplicationPlans, error) {
+		return r.PlanRepositoryInterface.ListApplications(ctx)
+	})
+}
+
+// GetApplicationStatus returns the status of an application rolled up from its plans
+func (r *ChaosPlanRepository) GetApplicationStatus(
+	ctx context.Context,
+	applicationID string,
+) (*models.ApplicationStatus, error) {
+	return chaosCall(ctx, r.chaos, "plan", "GetApplicationStatus", func() (*models.ApplicationStatus, error) {
+		return r.PlanRepositoryInterface.GetApplicationStatus(ctx, applicationID)
+	})
+}
+
+// Clone clones a plan
+func (r *ChaosPlanRepository) Clone(ctx context.Context, id string, opts PlanCloneOptions) (*models.Plan, error) {
+	return chaosCall(ctx, r.chaos, "plan", "Clone", func() (*models.Plan, error) {
+		return r.PlanRepositoryInterface.Clone(ctx, id, opts)
+	})
+}
+
+// Restore restores a plan snapshot
+func (r *ChaosPlanRepository) Restore(ctx context.Context, plan *models.Plan) error {
+	if err := r.chaos.inject(ctx, "plan", "Restore"); err != nil {
+		return err
+	}
+	return r.PlanRepositoryInterface.Restore(ctx, plan)
+}
+
+// ReorderPlan moves a plan to a new position among the plans of its application
+func (r *ChaosPlanRepository) ReorderPlan(ctx context.Context, id string, newOrder int) (*models.Plan, error) {
+	return chaosCall(ctx, r.chaos, "plan", "ReorderPlan", func() (*models.Plan, error) {
+		return r.PlanRepositoryInterface.ReorderPlan(ctx, id, newOrder)
+	})
+}
+
+// UpdateNotes updates the notes of a plan
+func (r *ChaosPlanRepository) UpdateNotes(ctx context.Context, id string, notes string) error {
+	if err := r.chaos.inject(ctx, "plan", "UpdateNotes"); err != nil {
+		return err
+	}
+	return r.PlanRepositoryInterface.UpdateNotes(ctx, id, notes)
+}
+
+// GetNotes retrieves the notes of a plan
+func (r *ChaosPlanRepository) GetNotes(ctx context.Context, id string) (string, error) {
+	return chaosCall(ctx, r.chaos, "plan", "GetNotes", func() (string, error) {
+		return r.PlanRepositoryInterface.GetNotes(ctx, id)
+	})
+}
+
+// AppendNotes appends text to the notes of a plan
+func (r *ChaosPlanRepository) AppendNotes(ctx context.Context, id string, text string) (string, error) {
+	return chaosCall(ctx, r.chaos, "plan", "AppendNotes", func() (string, error) {
+		return r.PlanRepositoryInterface.AppendNotes(ctx, id, text)
+	})
+}
+
+// NotesHistory returns the revisions of the notes of a plan
+func (r *ChaosPlanRepository) NotesHistory(ctx context.Context, id string, limit int64) ([]*models.NotesRevision, error) {
+	return chaosCall(ctx, r.chaos, "plan", "NotesHistory", func() ([]*models.NotesRevision, error) {
+		return r.PlanRepositoryInterface.NotesHistory(ctx, id, limit)
+	})
+}
+
+// LogActivity adds an entry to the agent activity journal of a plan
+func (r *ChaosPlanRepository) LogActivity(
+	ctx context.Context,
+	planID string,
+	activity *models.AgentActivity,
+) (*models.AgentActivity, error) {
+	return chaosCall(ctx, r.chaos, "plan", "LogActivity", func() (*models.AgentActivity, error) {
+		return r.PlanRepositoryInterface.LogActivity(ctx, planID, activity)
+	})
+}
+
+// ListActivity returns entries of the agent activity journal of a plan
+func (r *ChaosPlanRepository) ListActivity(
+	ctx context.Context,
+	planID string,
+	query AgentActivityQuery,
+) ([]*models.AgentActivity, error) {
+	return chaosCall(ctx, r.chaos, "plan", "ListActivity", func() ([]*models.AgentActivity, error) {
+		return r.PlanRepositoryInterface.ListActivity(ctx, planID, query)
+	})
+}
+
+// RevertNotes restores a previous revision of the notes of a plan
+func (r *ChaosPlanRepository) RevertNotes(ctx context.Context, id, revisionID string) (string, error) {
+	return chaosCall(ctx, r.chaos, "plan", "RevertNotes", func() (string, error) {
+		return r.PlanRepositoryInterface.RevertNotes(ctx, id, revisionID)
+	})
+}
+
+// SetMetadata sets metadata keys of a plan
+func (r *ChaosPlanRepository) SetMetadata(
+	ctx context.Context,
+	id string,
+	metadata map[string]string,
+) (*models.Plan, error) {
+	return chaosCall(ctx, r.chaos, "plan", "SetMetadata", func() (*models.Plan, error) {
+		return r.PlanRepositoryInterface.SetMetadata(ctx, id, metadata)
+	})
+}
+
+// DeleteMetadata removes metadata keys from a plan
+func (r *ChaosPlanRepository) DeleteMetadata(ctx context.Context, id string, keys []string) (*models.Plan, error) {
+	return chaosCall(ctx, r.chaos, "plan", "DeleteMetadata", func() (*models.Plan, error) {
+		return r.PlanRepositoryInterface.DeleteMetadata(ctx, id, keys)
+	})
+}
+
+// LockPlan locks a plan against concurrent changes
+func (r *ChaosPlanRepository) LockPlan(ctx context.Context, id string) (context.Context, func(), error) {
+	if err := r.chaos.inject(ctx, "plan", "LockPlan"); err != nil {
+		return nil, nil, err
+	}
+	return r.PlanRepositoryInterface.LockPlan(ctx, id)
+}
+
+// WriteAtomically runs changes to a plan in one transaction
+func (r *ChaosPlanRepository) WriteAtomically(
+	ctx context.Context,
+	id string,
+	write func(ctx context.Context) error,
+) error {
+	if err := r.chaos.inject(ctx, "plan", "WriteAtomically"); err != nil {
+		return err
+	}
+	return r.PlanRepositoryInterface.WriteAtomically(ctx, id, write)
+}
+
+// SetLock locks or unlocks a plan
+func (r *ChaosPlanRepository) SetLock(ctx context.Context, id string, lock *models.PlanLock) (*models.Plan, error) {
+	return chaosCall(ctx, r.chaos, "plan", "SetLock", func() (*models.Plan, error) {
+		return r.PlanRepositoryInterface.SetLock(ctx, id, lock)
+	})
+}
+
+// ChaosTaskRepository decorates a task repository and injects failures and latency into its calls
+type ChaosTaskRepository struct {
+	TaskRepositoryInterface
+	chaos *Chaos
+}
+
+// NewChaosTaskRepository wraps a task repository with failure injection
+func NewChaosTaskRepository(inner TaskRepositoryInterface, chaos *Chaos) *ChaosTaskRepository {
+	return &ChaosTaskRepository{
+		TaskRepositoryInterface: inner,
+		chaos:                   chaos,
+	}
+}
+
+// Create creates a task
+func (r *ChaosTaskRepository) Create(
+	ctx context.Context,
+	planID, title, description string,
+	priority models.TaskPriority,
+) (*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "Create", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.Create(ctx, planID, title, description, priority)
+	})
+}
+
+// CreateBulk creates tasks
+func (r *ChaosTaskRepository) CreateBulk(
+	ctx context.Context,
+	planID string,
+	tasks []TaskCreateInput,
+) ([]*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "CreateBulk", func() ([]*models.Task, error) {
+		return r.TaskRepositoryInterface.CreateBulk(ctx, planID, tasks)
+	})
+}
+
+// CreateBulkWithOptions creates tasks with deduplication
+func (r *ChaosTaskRepository) CreateBulkWithOptions(
+	ctx context.Context,
+	planID string,
+	tasks []TaskCreateInput,
+	opts BulkCreateOptions,
+) (*BulkCreateReport, error) {
+	return chaosCall(ctx, r.chaos, "task", "CreateBulkWithOptions", func() (*BulkCreateReport, error) {
+		return r.TaskRepositoryInterface.CreateBulkWithOptions(ctx, planID, tasks, opts)
+	})
+}
+
+// Get retrieves a task by ID
+func (r *ChaosTaskRepository) Get(ctx context.Context, id string) (*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "Get", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.Get(ctx, id)
+	})
+}
+
+// Update updates a task
+func (r *ChaosTaskRepository) Update(ctx context.Context, task *models.Task) error {
+	if err := r.chaos.inject(ctx, "task", "Update"); err != nil {
+		return err
+	}
+	return r.TaskRepositoryInterface.Update(ctx, task)
+}
+
+// Delete deletes a task
+func (r *ChaosTaskRepository) Delete(ctx context.Context, id string) error {
+	if err := r.chaos.inject(ctx, "task", "Delete"); err != nil {
+		return err
+	}
+	return r.TaskRepositoryInterface.Delete(ctx, id)
+}
+
+// ListByPlan returns all tasks for a plan, ordered by their sequence
+func (r *ChaosTaskRepository) ListByPlan(ctx context.Context, planID string) ([]*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "ListByPlan", func() ([]*models.Task, error) {
+		return r.TaskRepositoryInterface.ListByPlan(ctx, planID)
+	})
+}
+
+// StreamByPlan emits the tasks of a plan in chunks. Failures are injected before the first chunk.
+func (r *ChaosTaskRepository) StreamByPlan(
+	ctx context.Context,
+	planID string,
+	chunkSize int,
+	emit func(offset int, tasks []*models.Task) error,
+) error {
+	if err := r.chaos.inject(ctx, "task", "StreamByPlan"); err != nil {
+		return err
+	}
+	return r.TaskRepositoryInterface.StreamByPlan(ctx, planID, chunkSize, emit)
+}
+
+// CountByPlan returns the number of tasks in a plan
+func (r *ChaosTaskRepository) CountByPlan(ctx context.Context, planID string) (int64, error) {
+	return chaosCall(ctx, r.chaos, "task", "CountByPlan", func() (int64, error) {
+		return r.TaskRepositoryInterface.CountByPlan(ctx, planID)
+	})
+}
+
+// ListByStatus returns all tasks with the given status
+func (r *ChaosTaskRepository) ListByStatus(ctx context.Context, status models.TaskStatus) ([]*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "ListByStatus", func() ([]*models.Task, error) {
+		return r.TaskRepositoryInterface.ListByStatus(ctx, status)
+	})
+}
+
+// ListByPlanAndStatus returns all tasks for a plan with the given status
+func (r *ChaosTaskRepository) ListByPlanAndStatus(
+	ctx context.Context,
+	planID string,
+	status models.TaskStatus,
+) ([]*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "ListByPlanAndStatus", func() ([]*models.Task, error) {
+		return r.TaskRepositoryInterface.ListByPlanAndStatus(ctx, planID, status)
+	})
+}
+
+// ListByApplication returns the tasks of all plans of an application
+func (r *ChaosTaskRepository) ListByApplication(ctx context.Context, applicationID string) ([]*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "ListByApplication", func() ([]*models.Task, error) {
+		return r.TaskRepositoryInterface.ListByApplication(ctx, applicationID)
+	})
+}
+
+// ListByApplicationAndStatus returns the tasks of all plans of an application with the given status
+func (r *ChaosTaskRepository) ListByApplicationAndStatus(
+	ctx context.Context,
+	applicationID string,
+	status models.TaskStatus,
+) ([]*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "ListByApplicationAndStatus", func() ([]*models.Task, error) {
+		return r.TaskRepositoryInterface.ListByApplicationAndStatus(ctx, applicationID, status)
+	})
+}
+
+// ReorderTask moves a task to a new position in its plan
+func (r *ChaosTaskRepository) ReorderTask(ctx context.Context, taskID string, newOrder int) error {
+	if err := r.chaos.inject(ctx, "task", "ReorderTask"); err != nil {
+		return err
+	}
+	return r.TaskRepositoryInterface.ReorderTask(ctx, taskID, newOrder)
+}
+
+// ReorderTasks sets the order of the tasks of a plan
+func (r *ChaosTaskRepository) ReorderTasks(ctx context.Context, planID string, taskIDs []string) ([]*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "ReorderTasks", func() ([]*models.Task, error) {
+		return r.TaskRepositoryInterface.ReorderTasks(ctx, planID, taskIDs)
+	})
+}
+
+// MoveTask moves a task to a position in another plan
+func (r *ChaosTaskRepository) MoveTask(ctx context.Context, taskID, planID string, position int) (*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "MoveTask", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.MoveTask(ctx, taskID, planID, position)
+	})
+}
+
+// ListOrphanedTasks returns all tasks that reference a non-existent plan
+func (r *ChaosTaskRepository) ListOrphanedTasks(ctx context.Context) ([]*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "ListOrphanedTasks", func() ([]*models.Task, error) {
+		return r.TaskRepositoryInterface.ListOrphanedTasks(ctx)
+	})
+}
+
+// Restore restores a task snapshot
+func (r *ChaosTaskRepository) Restore(ctx context.Context, task *models.Task) error {
+	if err := r.chaos.inject(ctx, "task", "Restore"); err != nil {
+		return err
+	}
+	return r.TaskRepositoryInterface.Restore(ctx, task)
+}
+
+// UpdateNotes updates the notes of a task
+func (r *ChaosTaskRepository) UpdateNotes(ctx context.Context, id string, notes string) error {
+	if err := r.chaos.inject(ctx, "task", "UpdateNotes"); err != nil {
+		return err
+	}
+	return r.TaskRepositoryInterface.UpdateNotes(ctx, id, notes)
+}
+
+// GetNotes retrieves the notes of a task
+func (r *ChaosTaskRepository) GetNotes(ctx context.Context, id string) (string, error) {
+	return chaosCall(ctx, r.chaos, "task", "GetNotes", func() (string, error) {
+		return r.TaskRepositoryInterface.GetNotes(ctx, id)
+	})
+}
+
+// ClaimTask leases a task to a worker
+func (r *ChaosTaskRepository) ClaimTask(
+	ctx context.Context,
+	taskID, workerID string,
+	ttl time.Duration,
+) (*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "ClaimTask", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.ClaimTask(ctx, taskID, workerID, ttl)
+	})
+}
+
+// RenewLease extends the lease of a worker on a task
+func (r *ChaosTaskRepository) RenewLease(
+	ctx context.Context,
+	taskID, workerID string,
+	ttl time.Duration,
+) (*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "RenewLease", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.RenewLease(ctx, taskID, workerID, ttl)
+	})
+}
+
+// ExpireLeases returns the tasks with expired leases to pending
+func (r *ChaosTaskRepository) ExpireLeases(ctx context.Context) ([]string, error) {
+	return chaosCall(ctx, r.chaos, "task", "ExpireLeases", func() ([]string, error) {
+		return r.TaskRepositoryInterface.ExpireLeases(ctx)
+	})
+}
+
+// AddTags adds tags to a task
+func (r *ChaosTaskRepository) AddTags(ctx context.Context, taskID string, tags []string) (*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "AddTags", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.AddTags(ctx, taskID, tags)
+	})
+}
+
+// RemoveTags removes tags from a task
+func (r *ChaosTaskRepository) RemoveTags(ctx context.Context, taskID string, tags []string) (*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "RemoveTags", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.RemoveTags(ctx, taskID, tags)
+	})
+}
+
+// ListByTag returns all tasks carrying the given tag
+func (r *ChaosTaskRepository) ListByTag(ctx context.Context, tag string) ([]*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "ListByTag", func() ([]*models.Task, error) {
+		return r.TaskRepositoryInterface.ListByTag(ctx, tag)
+	})
+}
+
+// Query returns the tasks matching a query
+func (r *ChaosTaskRepository) Query(ctx context.Context, query *models.TaskQuery) ([]*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "Query", func() ([]*models.Task, error) {
+		return r.TaskRepositoryInterface.Query(ctx, query)
+	})
+}
+
+// AddChecklistItem adds an item to the checklist of a task
+func (r *ChaosTaskRepository) AddChecklistItem(ctx context.Context, taskID, text string) (*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "AddChecklistItem", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.AddChecklistItem(ctx, taskID, text)
+	})
+}
+
+// ToggleChecklistItem checks or unchecks an item of the checklist of a task
+func (r *ChaosTaskRepository) ToggleChecklistItem(
+	ctx context.Context,
+	taskID, itemID string,
+	done *bool,
+) (*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "ToggleChecklistItem", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.ToggleChecklistItem(ctx, taskID, itemID, done)
+	})
+}
+
+// RemoveChecklistItem removes an item from the checklist of a task
+func (r *ChaosTaskRepository) RemoveChecklistItem(ctx context.Context, taskID, itemID string) (*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "RemoveChecklistItem", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.RemoveChecklistItem(ctx, taskID, itemID)
+	})
+}
+
+// LogTime adds time spent to a task
+func (r *ChaosTaskRepository) LogTime(ctx context.Context, taskID string, duration time.Duration) (*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "LogTime", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.LogTime(ctx, taskID, duration)
+	})
+}
+
+// ListCompletions returns the completion record of a plan
+func (r *ChaosTaskRepository) ListCompletions(ctx context.Context, planID string) ([]*models.TaskCompletion, error) {
+	return chaosCall(ctx, r.chaos, "task", "ListCompletions", func() ([]*models.TaskCompletion, error) {
+		return r.TaskRepositoryInterface.ListCompletions(ctx, planID)
+	})
+}
+
+// SetMetadata sets metadata keys of a task
+func (r *ChaosTaskRepository) SetMetadata(
+	ctx context.Context,
+	id string,
+	metadata map[string]string,
+) (*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "SetMetadata", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.SetMetadata(ctx, id, metadata)
+	})
+}
+
+// DeleteMetadata removes metadata keys from a task
+func (r *ChaosTaskRepository) DeleteMetadata(ctx context.Context, id string, keys []string) (*models.Task, error) {
+	return chaosCall(ctx, r.chaos, "task", "DeleteMetadata", func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.DeleteMetadata(ctx, id, keys)
+	})
+}
+
+// ChaosApplicationRepository decorates an application repository and injects failures and latency into its
+// calls
+type ChaosApplicationRepository struct {
+	ApplicationRepositoryInterface
+	chaos *Chaos
+}
+
+// NewChaosApplicationRepository wraps an application repository with failure injection
+func NewChaosApplicationRepository(inner ApplicationRepositoryInterface, chaos *Chaos) *ChaosApplicationRepository {
+	return &ChaosApplicationRepository{
+		ApplicationRepositoryInterface: inner,
+		chaos:                          chaos,
+	}
+}
+
+// Create registers an application
+func (r *ChaosApplicationRepository) Create(
+	ctx context.Context,
+	id, name, description string,
+	metadata map[string]string,
+) (*models.Application, error) {
+	return chaosCall(ctx, r.chaos, "application", "Create", func() (*models.Application, error) {
+		return r.ApplicationRepositoryInterface.Create(ctx, id, name, description, metadata)
+	})
+}
+
+// Get retrieves a registered application
+func (r *ChaosApplicationRepository) Get(ctx context.Context, id string) (*models.Application, error) {
+	return chaosCall(ctx, r.chaos, "application", "Get", func() (*models.Application, error) {
+		return r.ApplicationRepositoryInterface.Get(ctx, id)
+	})
+}
+
+// Exists reports whether an application is registered
+func (r *ChaosApplicationRepository) Exists(ctx context.Context, id string) (bool, error) {
+	return chaosCall(ctx, r.chaos, "application", "Exists", func() (bool, error) {
+		return r.ApplicationRepositoryInterface.Exists(ctx, id)
+	})
+}
+
+// List returns the registered applications
+func (r *ChaosApplicationRepository) List(ctx context.Context) ([]*models.Application, error) {
+	return chaosCall(ctx, r.chaos, "application", "List", func() ([]*models.Application, error) {
+		return r.ApplicationRepositoryInterface.List(ctx)
+	})
+}
+
+// Ensure the chaos repositories implement the interfaces
+var (
+	_ PlanRepositoryInterface        = (*ChaosPlanRepository)(nil)
+	_ TaskRepositoryInterface        = (*ChaosTaskRepository)(nil)
+	_ ApplicationRepositoryInterface = (*ChaosApplicationRepository)(nil)
+)
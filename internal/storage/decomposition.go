@@ -0,0 +1,245 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// DecompositionGuardrails flag tasks and plans that grow too large to be worked on in one piece. Unlike the
+// limits, which bound what can be stored, they point agents at work that should be decomposed, with a
+// warning next to the result or, when Block is set, by rejecting the change.
+type DecompositionGuardrails struct {
+	// MaxDescriptionLength is the length of a task description in bytes beyond which the task should be
+	// decomposed into smaller tasks, zero for none
+	MaxDescriptionLength int
+	// MaxPlanTasks is the number of tasks beyond which a plan should be split into smaller plans, zero for none
+	MaxPlanTasks int
+	// Block rejects changes crossing a guardrail with a validation error instead of warning about them
+	Block bool
+}
+
+// DefaultDecompositionGuardrails returns the guardrails used when none are configured, which warn
+func DefaultDecompositionGuardrails() DecompositionGuardrails {
+	return DecompositionGuardrails{
+		MaxDescriptionLength: 4000,
+		MaxPlanTasks:         100,
+	}
+}
+
+// GuardedTaskRepository decorates a task repository with the decomposition guardrails
+type GuardedTaskRepository struct {
+	TaskRepositoryInterface
+	guardrails DecompositionGuardrails
+}
+
+// NewGuardedTaskRepository wraps a task repository with the given guardrails
+func NewGuardedTaskRepository(inner TaskRepositoryInterface, guardrails DecompositionGuardrails) *GuardedTaskRepository {
+	return &GuardedTaskRepository{
+		TaskRepositoryInterface: inner,
+		guardrails:              guardrails,
+	}
+}
+
+// Create creates a task, warning about a long description or a plan growing too large
+func (r *GuardedTaskRepository) Create(
+	ctx context.Context,
+	planID, title, description string,
+	priority models.TaskPriority,
+) (*models.Task, error) {
+	if err := r.checkDescription(ctx, "", title, description); err != nil {
+		return nil, err
+	}
+	if err := r.checkPlanSize(ctx, planID, 1); err != nil {
+		return nil, err
+	}
+
+	task, err := r.TaskRepositoryInterface.Create(ctx, planID, title, description, priority)
+	if err != nil {
+		return nil, err
+	}
+	r.warnAboutTasks(ctx, planID, []*models.Task{task})
+	return task, nil
+}
+
+// CreateBulk creates tasks, warning about long descriptions or a plan growing too large
+func (r *GuardedTaskRepository) CreateBulk(
+	ctx context.Context,
+	planID string,
+	tasks []TaskCreateInput,
+) ([]*models.Task, error) {
+	if err := r.checkInputs(ctx, planID, tasks); err != nil {
+		return nil, err
+	}
+
+	created, err := r.TaskRepositoryInterface.CreateBulk(ctx, planID, tasks)
+	if err != nil {
+		return nil, err
+	}
+	r.warnAboutTasks(ctx, planID, created)
+	return created, nil
+}
+
+// CreateBulkWithOptions creates tasks, warning about long descriptions or a plan growing too large.
+// When blocking, room is checked for every submitted task, including those that may turn out to be duplicates.
+func (r *GuardedTaskRepository) CreateBulkWithOptions(
+	ctx context.Context,
+	planID string,
+	tasks []TaskCreateInput,
+	opts BulkCreateOptions,
+) (*BulkCreateReport, error) {
+	if err := r.checkInputs(ctx, planID, tasks); err != nil {
+		return nil, err
+	}
+
+	report, err := r.TaskRepositoryInterface.CreateBulkWithOptions(ctx, planID, tasks, opts)
+	if err != nil {
+		return nil, err
+	}
+	r.warnAboutTasks(ctx, planID, report.Created)
+	return report, nil
+}
+
+// Update updates a task, warning about a description that grew too long or a plan the task moved to that
+// grew too large. Unchanged descriptions are not checked again, so tasks from before the guardrails were
+// configured can still be worked on.
+func (r *GuardedTaskRepository) Update(ctx context.Context, task *models.Task) error {
+	current, err := r.TaskRepositoryInterface.Get(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+	descriptionChanged := task.Description != current.Description
+	moved := task.PlanID != current.PlanID
+
+	if descriptionChanged {
+		if err := r.checkDescription(ctx, task.ID, task.Title, task.Description); err != nil {
+			return err
+		}
+	}
+	if moved {
+		if err := r.checkPlanSize(ctx, task.PlanID, 1); err != nil {
+			return err
+		}
+	}
+
+	if err := r.TaskRepositoryInterface.Update(ctx, task); err != nil {
+		return err
+	}
+	if descriptionChanged {
+		r.warnAboutDescription(ctx, task)
+	}
+	if moved {
+		r.warnAboutPlanSize(ctx, task.PlanID)
+	}
+	return nil
+}
+
+// MoveTask moves a task to another plan, warning about the plan growing too large
+func (r *GuardedTaskRepository) MoveTask(ctx context.Context, taskID, planID string, position int) (*models.Task, error) {
+	current, err := r.TaskRepositoryInterface.Get(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	moved := current.PlanID != planID
+	if moved {
+		if err := r.checkPlanSize(ctx, planID, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	task, err := r.TaskRepositoryInterface.MoveTask(ctx, taskID, planID, position)
+	if err != nil {
+		return nil, err
+	}
+	if moved {
+		r.warnAboutPlanSize(ctx, planID)
+	}
+	return task, nil
+}
+
+// checkInputs rejects task definitions crossing a guardrail when blocking
+func (r *GuardedTaskRepository) checkInputs(ctx context.Context, planID string, tasks []TaskCreateInput) error {
+	for _, task := range tasks {
+		if err := r.checkDescription(ctx, "", task.Title, task.Description); err != nil {
+			return err
+		}
+	}
+	return r.checkPlanSize(ctx, planID, len(tasks))
+}
+
+// checkDescription rejects a description beyond the guardrail when blocking
+func (r *GuardedTaskRepository) checkDescription(_ context.Context, taskID, title, description string) error {
+	if !r.guardrails.Block || !r.descriptionTooLong(description) {
+		return nil
+	}
+	return models.NewValidationError(models.EntityTask, taskID,
+		"the description of task %q is %d bytes, more than the %d bytes of a task that should be decomposed; "+
+			"split it into smaller tasks", title, len(description), r.guardrails.MaxDescriptionLength)
+}
+
+// checkPlanSize rejects adding tasks to a plan that would then have more tasks than the guardrail when blocking
+func (r *GuardedTaskRepository) checkPlanSize(ctx context.Context, planID string, adding int) error {
+	if !r.guardrails.Block || r.guardrails.MaxPlanTasks <= 0 {
+		return nil
+	}
+	count, err := r.CountByPlan(ctx, planID)
+	if err != nil {
+		return err
+	}
+	if int(count)+adding > r.guardrails.MaxPlanTasks {
+		return models.NewValidationError(models.EntityPlan, planID,
+			"plan %s has %d tasks, adding %d would exceed the %d tasks of a plan that should be split; "+
+				"split it into smaller plans", planID, count, adding, r.guardrails.MaxPlanTasks)
+	}
+	return nil
+}
+
+// warnAboutTasks warns about created tasks with long descriptions and about their plan growing too large
+func (r *GuardedTaskRepository) warnAboutTasks(ctx context.Context, planID string, tasks []*models.Task) {
+	for _, task := range tasks {
+		r.warnAboutDescription(ctx, task)
+	}
+	if len(tasks) > 0 {
+		r.warnAboutPlanSize(ctx, planID)
+	}
+}
+
+// warnAboutDescription warns about a task whose description is beyond the guardrail
+func (r *GuardedTaskRepository) warnAboutDescription(ctx context.Context, task *models.Task) {
+	if !r.descriptionTooLong(task.Description) {
+		return
+	}
+	addWarning(ctx, &models.Warning{
+		Code:   models.WarningCodeDecomposeTask,
+		Entity: models.EntityTask,
+		ID:     task.ID,
+		Message: fmt.Sprintf("the description of task %q is %d bytes, more than the %d bytes of a task that "+
+			"should be decomposed; consider splitting it into smaller tasks",
+			task.Title, len(task.Description), r.guardrails.MaxDescriptionLength),
+	})
+}
+
+// warnAboutPlanSize warns about a plan with more tasks than the guardrail. A failure to count the tasks only
+// loses the warning, as the change itself succeeded.
+func (r *GuardedTaskRepository) warnAboutPlanSize(ctx context.Context, planID string) {
+	if r.guardrails.MaxPlanTasks <= 0 {
+		return
+	}
+	count, err := r.CountByPlan(ctx, planID)
+	if err != nil || int(count) <= r.guardrails.MaxPlanTasks {
+		return
+	}
+	addWarning(ctx, &models.Warning{
+		Code:   models.WarningCodeSplitPlan,
+		Entity: models.EntityPlan,
+		ID:     planID,
+		Message: fmt.Sprintf("plan %s has %d tasks, more than the %d tasks of a plan that should be split; "+
+			"consider splitting it into smaller plans", planID, count, r.guardrails.MaxPlanTasks),
+	})
+}
+
+// descriptionTooLong reports whether a description is beyond the guardrail
+func (r *GuardedTaskRepository) descriptionTooLong(description string) bool {
+	return r.guardrails.MaxDescriptionLength > 0 && len(description) > r.guardrails.MaxDescriptionLength
+}
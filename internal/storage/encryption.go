@@ -0,0 +1,228 @@
+package storage
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Field encryption keeps the sensitive text of plans, tasks and applications unreadable to anyone else with
+// access to a shared Valkey. Descriptions, notes and completion notes are encrypted with AES-GCM before they
+// are written, together with the copies of them in notes revisions, notes archives, completion records and
+// the audit log, and decrypted when they are read, so API consumers never see the difference. Names, titles
+// and the other fields stay readable, as the server looks them up and sorts by them.
+//
+// Encrypted values carry the ID of their key, so keys can be rotated: values are written with the current key
+// and read with whichever key wrote them. Values written before encryption was turned on are read as they
+// are and encrypted the next time they are written.
+
+// encryptedPrefix starts every encrypted value, followed by the key ID and the base64 nonce and ciphertext
+const encryptedPrefix = "enc:v1:"
+
+// encryptedFields are the hash fields of plans, tasks and applications that are encrypted
+var encryptedFields = []string{"description", "notes", "completion_note"}
+
+// ErrDecryption is returned for encrypted values that cannot be decrypted, because their key is not
+// configured or they were changed
+var ErrDecryption = errors.New("failed to decrypt field")
+
+// KeySource provides the keys of field encryption. The first key encrypts, all of them decrypt.
+type KeySource interface {
+	Keys(ctx context.Context) ([][]byte, error)
+}
+
+// StaticKeys is a key source of keys held in memory, such as read from the environment
+type StaticKeys [][]byte
+
+// Keys returns the keys
+func (k StaticKeys) Keys(context.Context) ([][]byte, error) {
+	return k, nil
+}
+
+// ParseStaticKeys parses base64 encoded keys separated by commas, the current key first
+func ParseStaticKeys(spec string) (StaticKeys, error) {
+	var keys StaticKeys
+	for _, encoded := range strings.Split(spec, ",") {
+		if encoded = strings.TrimSpace(encoded); encoded == "" {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key is not base64: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// KeyDecrypter decrypts a data key with a key management service, such as AWS KMS, Google Cloud KMS or the
+// transit engine of Vault
+type KeyDecrypter interface {
+	Decrypt(ctx context.Context, wrappedKey []byte) ([]byte, error)
+}
+
+// KMSKeys is a key source of data keys wrapped by a key management service, so the keys themselves are never
+// stored with the configuration. The current key comes first.
+type KMSKeys struct {
+	KMS         KeyDecrypter
+	WrappedKeys [][]byte
+}
+
+// Keys unwraps the data keys with the key management service
+func (k KMSKeys) Keys(ctx context.Context) ([][]byte, error) {
+	keys := make([][]byte, 0, len(k.WrappedKeys))
+	for i, wrapped := range k.WrappedKeys {
+		key, err := k.KMS.Decrypt(ctx, wrapped)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unwrap key %d: %w", i+1, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// FieldCipher encrypts and decrypts field values with AES-GCM
+type FieldCipher struct {
+	currentID string
+	aeads     map[string]cipher.AEAD
+}
+
+// NewFieldCipher creates a cipher from the keys of a key source. Keys are 16, 24 or 32 bytes long, for
+// AES-128, AES-192 or AES-256.
+func NewFieldCipher(ctx context.Context, source KeySource) (*FieldCipher, error) {
+	keys, err := source.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no encryption key")
+	}
+
+	c := &FieldCipher{aeads: make(map[string]cipher.AEAD, len(keys))}
+	for i, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %d: %w", i+1, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %d: %w", i+1, err)
+		}
+
+		id := encryptionKeyID(key)
+		if i == 0 {
+			c.currentID = id
+		}
+		c.aeads[id] = aead
+	}
+	return c, nil
+}
+
+// encryptionKeyID identifies a key in the values it encrypted without revealing it
+func encryptionKeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:4])
+}
+
+// Encrypt encrypts a value with the current key. Empty values stay empty.
+func (c *FieldCipher) Encrypt(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	aead := c.aeads[c.currentID]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), nil)
+	return encryptedPrefix + c.currentID + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value encrypted with any of the keys. Values that are not encrypted are returned as is.
+func (c *FieldCipher) Decrypt(value string) (string, error) {
+	rest, ok := strings.CutPrefix(value, encryptedPrefix)
+	if !ok {
+		return value, nil
+	}
+
+	id, encoded, _ := strings.Cut(rest, ":")
+	aead, ok := c.aeads[id]
+	if !ok {
+		return "", fmt.Errorf("%w: key %s is not configured", ErrDecryption, id)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("%w: malformed value", ErrDecryption)
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrDecryption, err)
+	}
+	return string(plaintext), nil
+}
+
+// SetFieldCipher turns on field encryption with a cipher, or off with nil. Values already encrypted can only
+// be read while a cipher with their key is set.
+func (c *ValkeyClient) SetFieldCipher(fieldCipher *FieldCipher) {
+	c.cipher = fieldCipher
+}
+
+// seal encrypts a value when field encryption is on
+func (c *ValkeyClient) seal(value string) (string, error) {
+	if c.cipher == nil {
+		return value, nil
+	}
+	return c.cipher.Encrypt(value)
+}
+
+// open decrypts a value sealed by seal. Encrypted values fail to read while field encryption is off.
+func (c *ValkeyClient) open(value string) (string, error) {
+	if c.cipher == nil {
+		if strings.HasPrefix(value, encryptedPrefix) {
+			return "", fmt.Errorf("%w: field encryption is not configured", ErrDecryption)
+		}
+		return value, nil
+	}
+	return c.cipher.Decrypt(value)
+}
+
+// sealFields encrypts the sensitive fields of a plan, task or application hash in place and returns it
+func (c *ValkeyClient) sealFields(fields map[string]string) (map[string]string, error) {
+	if c.cipher == nil {
+		return fields, nil
+	}
+	for _, field := range encryptedFields {
+		if value, ok := fields[field]; ok {
+			sealed, err := c.cipher.Encrypt(value)
+			if err != nil {
+				return nil, err
+			}
+			fields[field] = sealed
+		}
+	}
+	return fields, nil
+}
+
+// openFields decrypts the sensitive fields of a plan, task or application hash in place
+func (c *ValkeyClient) openFields(data map[string]string) error {
+	for _, field := range encryptedFields {
+		value, ok := data[field]
+		if !ok {
+			continue
+		}
+		opened, err := c.open(value)
+		if err != nil {
+			return fmt.Errorf("%s of %s: %w", field, data["id"], err)
+		}
+		data[field] = opened
+	}
+	return nil
+}
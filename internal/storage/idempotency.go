@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/valkey-io/valkey-glide/go/v2/options"
+)
+
+const (
+	// DefaultIdempotencyTTL is how long the result of a request is kept for retries with the same key
+	DefaultIdempotencyTTL = 24 * time.Hour
+	// idempotencyPendingTTL bounds how long a request that never finished blocks retries with its key
+	idempotencyPendingTTL = time.Minute
+
+	idempotencyPending      = "pending"
+	idempotencyResultPrefix = "result:"
+)
+
+// ErrIdempotencyInProgress is returned when a request with the same idempotency key is still running
+var ErrIdempotencyInProgress error = models.NewConflictError(
+	"", "", "a request with this idempotency key is still in progress",
+)
+
+// IdempotencyStore remembers the results of requests by a key chosen by the client, so a retried request
+// returns the original result instead of being applied twice. The keys live in Valkey, so they are shared by
+// every server replica.
+type IdempotencyStore struct {
+	client *ValkeyClient
+	ttl    time.Duration
+}
+
+// NewIdempotencyStore creates an idempotency store keeping results for ttl
+func NewIdempotencyStore(client *ValkeyClient, ttl time.Duration) *IdempotencyStore {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	return &IdempotencyStore{client: client, ttl: ttl}
+}
+
+// Begin claims a key within a scope, such as the name of a tool. It returns the stored result and true
+// if a request with the key already finished, or false if the caller should run the request and then call
+// Complete or Abandon. It returns ErrIdempotencyInProgress while another request holds the key.
+func (s *IdempotencyStore) Begin(ctx context.Context, scope, key string) (string, bool, error) {
+	storeKey := GetIdempotencyKey(scope, key)
+	setOpts := options.NewSetOptions().
+		SetOnlyIfDoesNotExist().
+		SetExpiry(options.NewExpiryIn(idempotencyPendingTTL))
+	result, err := s.client.client.SetWithOptions(ctx, storeKey, idempotencyPending, *setOpts)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	if !result.IsNil() {
+		return "", false, nil
+	}
+
+	stored, err := s.client.client.Get(withPrimaryReads(ctx), storeKey)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	if value, ok := strings.CutPrefix(stored.Value(), idempotencyResultPrefix); ok {
+		return value, true, nil
+	}
+	return "", false, ErrIdempotencyInProgress
+}
+
+// Complete stores the result of a request claimed with Begin
+func (s *IdempotencyStore) Complete(ctx context.Context, scope, key, result string) error {
+	setOpts := options.NewSetOptions().SetExpiry(options.NewExpiryIn(s.ttl))
+	_, err := s.client.client.SetWithOptions(ctx, GetIdempotencyKey(scope, key), idempotencyResultPrefix+result, *setOpts)
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent result: %w", err)
+	}
+	return nil
+}
+
+// Abandon releases a key claimed with Begin without a result, so the request can be retried
+func (s *IdempotencyStore) Abandon(ctx context.Context, scope, key string) error {
+	_, err := s.client.client.Del(ctx, []string{GetIdempotencyKey(scope, key)})
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,388 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/valkey-io/valkey-glide/go/v2/options"
+)
+
+// IntegrityIssueKind identifies a kind of inconsistency between the keys holding plans and tasks
+type IntegrityIssueKind string
+
+const (
+	// IntegrityPlanNotListed is a plan stored without being in the list of plans, which hides it from listings
+	IntegrityPlanNotListed IntegrityIssueKind = "plan_not_listed"
+	// IntegrityTaskNotInPlan is a task stored without being in the ordered task set of its plan
+	IntegrityTaskNotInPlan IntegrityIssueKind = "task_not_in_plan"
+	// IntegrityOrphanedTask is a task stored for a plan that does not exist
+	IntegrityOrphanedTask IntegrityIssueKind = "orphaned_task"
+	// IntegrityMissingTask is a member of the ordered task set of a plan without a stored task,
+	// which makes listing the plan's tasks fail
+	IntegrityMissingTask IntegrityIssueKind = "missing_task"
+	// IntegrityDuplicateOrder is a plan whose tasks share a score in its ordered task set, which leaves their
+	// order to their IDs
+	IntegrityDuplicateOrder IntegrityIssueKind = "duplicate_order"
+	// IntegrityTaskCountMismatch is a plan whose task counters differ from the tasks stored for it, which
+	// misreports its progress and status
+	IntegrityTaskCountMismatch IntegrityIssueKind = "task_count_mismatch"
+)
+
+// IntegrityIssue is an inconsistency found by an integrity check
+type IntegrityIssue struct {
+	Kind   IntegrityIssueKind `json:"kind"`
+	PlanID string             `json:"plan_id,omitempty"`
+	TaskID string             `json:"task_id,omitempty"`
+	Detail string             `json:"detail"`
+	// Repaired reports whether the issue was fixed
+	Repaired bool `json:"repaired"`
+}
+
+// IntegrityReport is the result of an integrity check
+type IntegrityReport struct {
+	PlansChecked int              `json:"plans_checked"`
+	TasksChecked int              `json:"tasks_checked"`
+	Issues       []IntegrityIssue `json:"issues"`
+	// Repair reports whether repairs were requested
+	Repair bool `json:"repair"`
+}
+
+// IntegrityChecker finds, and optionally repairs, inconsistencies left behind by interrupted multi-key
+// writes. It scans the whole keyspace, so it is meant to be run by an operator rather than routinely.
+type IntegrityChecker struct {
+	client *ValkeyClient
+}
+
+// NewIntegrityChecker creates an integrity checker
+func NewIntegrityChecker(client *ValkeyClient) *IntegrityChecker {
+	return &IntegrityChecker{client: client}
+}
+
+// integrityTask is what the check needs to know about a stored task
+type integrityTask struct {
+	id     string
+	planID string
+	// order is the order stored in the task, which only hints at its position
+	order  int
+	score  float64
+	status models.TaskStatus
+}
+
+// Check looks for plans missing from the list of plans, tasks missing from the ordered task set of their
+// plan, task set members without a stored task, plans whose tasks share a score and plans whose task counters
+// are off. With repair, plans are listed again, tasks are added back to their plan, dangling members are removed,
+// the tasks of the affected plans are spread evenly again in their current order and the tasks are counted
+// again. Tasks of plans that no longer exist are reported but not repaired.
+func (c *IntegrityChecker) Check(ctx context.Context, repair bool) (*IntegrityReport, error) {
+	ctx = withPrimaryReads(ctx)
+	report := &IntegrityReport{Issues: []IntegrityIssue{}, Repair: repair}
+
+	// Plans
+	planKeys, err := c.client.client.scanKeys(ctx, planKeyPrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	listed, err := c.client.client.SMembers(ctx, plansListKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plan list: %w", err)
+	}
+
+	plans := make(map[string]bool, len(planKeys))
+	for _, key := range planKeys {
+		planID := idFromKey(planKeyPrefix, key)
+		plans[planID] = true
+		if _, ok := listed[planID]; ok {
+			continue
+		}
+
+		issue := IntegrityIssue{
+			Kind:   IntegrityPlanNotListed,
+			PlanID: planID,
+			Detail: "plan is stored but missing from the list of plans",
+		}
+		if repair {
+			if err := c.listPlan(ctx, planID); err != nil {
+				return nil, err
+			}
+			issue.Repaired = true
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+	for planID := range listed {
+		plans[planID] = true
+	}
+	report.PlansChecked = len(plans)
+
+	// Tasks, grouped by plan
+	taskKeys, err := c.client.client.scanKeys(ctx, taskKeyPrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	tasksByPlan := make(map[string]map[string]integrityTask)
+	for _, key := range taskKeys {
+		data, err := c.client.client.HGetAll(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get task: %w", err)
+		}
+		if len(data) == 0 {
+			continue
+		}
+
+		task := integrityTask{id: data["id"], planID: data["plan_id"], status: models.TaskStatus(data["status"])}
+		if task.id == "" {
+			task.id = idFromKey(taskKeyPrefix, key)
+		}
+		task.order, err = strconv.Atoi(data["order"])
+		if err != nil {
+			task.order = -1
+		}
+		if tasksByPlan[task.planID] == nil {
+			tasksByPlan[task.planID] = make(map[string]integrityTask)
+		}
+		tasksByPlan[task.planID][task.id] = task
+		report.TasksChecked++
+	}
+
+	// Plans that no longer exist cannot take their tasks back
+	orphanPlanIDs := make([]string, 0)
+	for planID := range tasksByPlan {
+		if !plans[planID] {
+			orphanPlanIDs = append(orphanPlanIDs, planID)
+		}
+	}
+	sort.Strings(orphanPlanIDs)
+	for _, planID := range orphanPlanIDs {
+		for _, taskID := range sortedTaskIDs(tasksByPlan[planID]) {
+			report.Issues = append(report.Issues, IntegrityIssue{
+				Kind:   IntegrityOrphanedTask,
+				PlanID: planID,
+				TaskID: taskID,
+				Detail: "task belongs to a plan that does not exist",
+			})
+		}
+	}
+
+	planIDs := make([]string, 0, len(plans))
+	for planID := range plans {
+		planIDs = append(planIDs, planID)
+	}
+	sort.Strings(planIDs)
+	for _, planID := range planIDs {
+		issues, err := c.checkPlanTasks(ctx, planID, tasksByPlan[planID], repair)
+		if err != nil {
+			return nil, err
+		}
+		report.Issues = append(report.Issues, issues...)
+	}
+
+	return report, nil
+}
+
+// checkPlanTasks compares the ordered task set of a plan with the tasks stored for it
+func (c *IntegrityChecker) checkPlanTasks(
+	ctx context.Context,
+	planID string,
+	tasks map[string]integrityTask,
+	repair bool,
+) ([]IntegrityIssue, error) {
+	planTasksKey := GetPlanTasksKey(planID)
+	members, err := c.client.client.ZRangeWithScores(ctx, planTasksKey, options.NewRangeByIndexQuery(0, -1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plan tasks: %w", err)
+	}
+
+	var issues []IntegrityIssue
+	inSet := make(map[string]bool, len(members))
+	ordered := make([]integrityTask, 0, len(tasks))
+	for _, member := range members {
+		taskID := member.Member
+		inSet[taskID] = true
+		task, ok := tasks[taskID]
+		if ok {
+			task.score = member.Score
+			ordered = append(ordered, task)
+			continue
+		}
+
+		issue := IntegrityIssue{
+			Kind:   IntegrityMissingTask,
+			PlanID: planID,
+			TaskID: taskID,
+			Detail: "plan task set references a task that is not stored",
+		}
+		if repair {
+			if _, err := c.client.client.ZRem(ctx, planTasksKey, []string{taskID}); err != nil {
+				return nil, fmt.Errorf("failed to remove missing task from plan: %w", err)
+			}
+			issue.Repaired = true
+		}
+		issues = append(issues, issue)
+	}
+
+	// Tasks sharing a score keep the order they were stored with
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].score != ordered[j].score {
+			return ordered[i].score < ordered[j].score
+		}
+		return ordered[i].order < ordered[j].order
+	})
+
+	seen := make(map[float64]string, len(ordered))
+	duplicate := false
+	for _, task := range ordered {
+		if other, ok := seen[task.score]; ok {
+			duplicate = true
+			issues = append(issues, IntegrityIssue{
+				Kind:     IntegrityDuplicateOrder,
+				PlanID:   planID,
+				TaskID:   task.id,
+				Detail:   fmt.Sprintf("task has score %g, like task %s", task.score, other),
+				Repaired: repair,
+			})
+			continue
+		}
+		seen[task.score] = task.id
+	}
+
+	// Tasks missing from the set go after the others, in the order they were stored with
+	var unlisted []integrityTask
+	for _, taskID := range sortedTaskIDs(tasks) {
+		if !inSet[taskID] {
+			unlisted = append(unlisted, tasks[taskID])
+		}
+	}
+	sort.SliceStable(unlisted, func(i, j int) bool { return unlisted[i].order < unlisted[j].order })
+	for _, task := range unlisted {
+		issues = append(issues, IntegrityIssue{
+			Kind:     IntegrityTaskNotInPlan,
+			PlanID:   planID,
+			TaskID:   task.id,
+			Detail:   "task is stored but missing from the task set of its plan",
+			Repaired: repair,
+		})
+	}
+	ordered = append(ordered, unlisted...)
+
+	// Renumbering also adds the unlisted tasks back to the set
+	if repair && (duplicate || len(unlisted) > 0) {
+		if err := c.renumber(ctx, planID, ordered); err != nil {
+			return nil, err
+		}
+	}
+
+	// Unlisted tasks are counted too, as they are only missing from the set until it is repaired
+	issue, err := c.checkTaskCounts(ctx, planID, ordered, repair)
+	if err != nil {
+		return nil, err
+	}
+	if issue != nil {
+		issues = append(issues, *issue)
+	}
+	return issues, nil
+}
+
+// checkTaskCounts compares the task counters of a plan with its tasks. Plans without counters are counted the
+// next time their status is updated, so they are not reported.
+func (c *IntegrityChecker) checkTaskCounts(
+	ctx context.Context,
+	planID string,
+	tasks []integrityTask,
+	repair bool,
+) (*IntegrityIssue, error) {
+	data, err := c.client.client.HGetAll(ctx, GetPlanKey(planID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plan: %w", err)
+	}
+	stored, err := models.TaskCountsFromFields(data)
+	if err != nil || stored == nil {
+		// Unreadable counters are left to the next recount as well
+		return nil, nil
+	}
+
+	actual := &models.TaskCounts{}
+	for _, task := range tasks {
+		actual.Add(task.status, 1)
+	}
+	if actual.Equal(stored) {
+		return nil, nil
+	}
+
+	issue := &IntegrityIssue{
+		Kind:   IntegrityTaskCountMismatch,
+		PlanID: planID,
+		Detail: fmt.Sprintf("plan counts %d tasks, but has %d", stored.Total, actual.Total),
+	}
+	if stored.Total == actual.Total {
+		issue.Detail = "plan counts its tasks with other statuses than they have"
+	}
+	if repair {
+		if _, err := c.client.client.HSet(ctx, GetPlanKey(planID), actual.Fields()); err != nil {
+			return nil, fmt.Errorf("failed to store task counts: %w", err)
+		}
+		if _, err := c.client.rollupPlan(ctx, planID); err != nil {
+			return nil, err
+		}
+		issue.Repaired = true
+	}
+	return issue, nil
+}
+
+// listPlan adds a stored plan back to the list of plans and of its application
+func (c *IntegrityChecker) listPlan(ctx context.Context, planID string) error {
+	if _, err := c.client.client.SAdd(ctx, plansListKey, []string{planID}); err != nil {
+		return fmt.Errorf("failed to add plan to list: %w", err)
+	}
+
+	data, err := c.client.client.HGetAll(ctx, GetPlanKey(planID))
+	if err != nil {
+		return fmt.Errorf("failed to get plan: %w", err)
+	}
+	if applicationID := data["application_id"]; applicationID != "" {
+		appPlansKey := fmt.Sprintf("app:%s:plans", applicationID)
+		if _, err := c.client.client.SAdd(ctx, appPlansKey, []string{planID}); err != nil {
+			return fmt.Errorf("failed to add plan to application list: %w", err)
+		}
+	}
+	return nil
+}
+
+// renumber gives the tasks of a plan evenly spread scores and sequential orders in the given sequence
+func (c *IntegrityChecker) renumber(ctx context.Context, planID string, tasks []integrityTask) error {
+	planTasksKey := GetPlanTasksKey(planID)
+	for i, task := range tasks {
+		_, err := c.client.client.HSet(ctx, GetTaskKey(task.id), map[string]string{"order": strconv.Itoa(i)})
+		if err != nil {
+			return fmt.Errorf("failed to update task order: %w", err)
+		}
+		_, err = c.client.client.ZAdd(ctx, planTasksKey, map[string]float64{task.id: float64(i) * orderSpacing})
+		if err != nil {
+			return fmt.Errorf("failed to update task order in plan: %w", err)
+		}
+	}
+	return nil
+}
+
+// idFromKey returns the ID in a key, without the hash tag of the cluster key layout
+func idFromKey(prefix, key string) string {
+	id := strings.TrimPrefix(key, prefix)
+	if strings.HasPrefix(id, "{") {
+		if end := strings.Index(id, "}"); end >= 0 {
+			id = id[end+1:]
+		}
+	}
+	return id
+}
+
+// sortedTaskIDs returns the IDs of tasks in sorted order, so reports are stable
+func sortedTaskIDs(tasks map[string]integrityTask) []string {
+	ids := make([]string, 0, len(tasks))
+	for id := range tasks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
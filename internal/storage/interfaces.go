@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
 )
@@ -11,13 +12,40 @@ type PlanRepositoryInterface interface {
 	Create(ctx context.Context, applicationID, name, description string) (*models.Plan, error)
 	Get(ctx context.Context, id string) (*models.Plan, error)
 	Update(ctx context.Context, plan *models.Plan) error
+	UpdateStatus(ctx context.Context, id string, status models.PlanStatus) (*models.Plan, error)
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context) ([]*models.Plan, error)
 	ListByApplication(ctx context.Context, applicationID string) ([]*models.Plan, error)
 	ListByStatus(ctx context.Context, status models.PlanStatus) ([]*models.Plan, error)
+	ListApplications(ctx context.Context) ([]*models.ApplicationPlans, error)
+	GetApplicationStatus(ctx context.Context, applicationID string) (*models.ApplicationStatus, error)
+	Clone(ctx context.Context, id string, opts PlanCloneOptions) (*models.Plan, error)
+	Restore(ctx context.Context, plan *models.Plan) error
+	ReorderPlan(ctx context.Context, id string, newOrder int) (*models.Plan, error)
 	// Notes related methods
 	UpdateNotes(ctx context.Context, id string, notes string) error
 	GetNotes(ctx context.Context, id string) (string, error)
+	AppendNotes(ctx context.Context, id string, text string) (string, error)
+	NotesHistory(ctx context.Context, id string, limit int64) ([]*models.NotesRevision, error)
+	RevertNotes(ctx context.Context, id, revisionID string) (string, error)
+	// Metadata related methods
+	SetMetadata(ctx context.Context, id string, metadata map[string]string) (*models.Plan, error)
+	DeleteMetadata(ctx context.Context, id string, keys []string) (*models.Plan, error)
+	// Agent activity related methods
+	LogActivity(ctx context.Context, planID string, activity *models.AgentActivity) (*models.AgentActivity, error)
+	ListActivity(ctx context.Context, planID string, query AgentActivityQuery) ([]*models.AgentActivity, error)
+	// Lock related methods
+	LockPlan(ctx context.Context, id string) (context.Context, func(), error)
+	WriteAtomically(ctx context.Context, id string, write func(ctx context.Context) error) error
+	SetLock(ctx context.Context, id string, lock *models.PlanLock) (*models.Plan, error)
+}
+
+// ApplicationRepositoryInterface defines the interface for registered application storage operations
+type ApplicationRepositoryInterface interface {
+	Create(ctx context.Context, id, name, description string, metadata map[string]string) (*models.Application, error)
+	Get(ctx context.Context, id string) (*models.Application, error)
+	Exists(ctx context.Context, id string) (bool, error)
+	List(ctx context.Context) ([]*models.Application, error)
 }
 
 // Note: ProjectRepositoryInterface has been removed as it's no longer needed
@@ -26,21 +54,56 @@ type PlanRepositoryInterface interface {
 type TaskRepositoryInterface interface {
 	Create(ctx context.Context, planID, title, description string, priority models.TaskPriority) (*models.Task, error)
 	CreateBulk(ctx context.Context, planID string, tasks []TaskCreateInput) ([]*models.Task, error)
+	CreateBulkWithOptions(
+		ctx context.Context,
+		planID string,
+		tasks []TaskCreateInput,
+		opts BulkCreateOptions,
+	) (*BulkCreateReport, error)
 	Get(ctx context.Context, id string) (*models.Task, error)
 	Update(ctx context.Context, task *models.Task) error
 	Delete(ctx context.Context, id string) error
 	ListByPlan(ctx context.Context, planID string) ([]*models.Task, error)
+	StreamByPlan(ctx context.Context, planID string, chunkSize int, emit func(offset int, tasks []*models.Task) error) error
+	CountByPlan(ctx context.Context, planID string) (int64, error)
 	ListByStatus(ctx context.Context, status models.TaskStatus) ([]*models.Task, error)
 	ListByPlanAndStatus(ctx context.Context, planID string, status models.TaskStatus) ([]*models.Task, error)
+	ListByApplication(ctx context.Context, applicationID string) ([]*models.Task, error)
+	ListByApplicationAndStatus(ctx context.Context, applicationID string, status models.TaskStatus) ([]*models.Task, error)
 	ReorderTask(ctx context.Context, taskID string, newOrder int) error
+	ReorderTasks(ctx context.Context, planID string, taskIDs []string) ([]*models.Task, error)
+	MoveTask(ctx context.Context, taskID, planID string, position int) (*models.Task, error)
 	ListOrphanedTasks(ctx context.Context) ([]*models.Task, error)
+	Restore(ctx context.Context, task *models.Task) error
 	// Notes related methods
 	UpdateNotes(ctx context.Context, id string, notes string) error
 	GetNotes(ctx context.Context, id string) (string, error)
+	// Lease related methods
+	ClaimTask(ctx context.Context, taskID, workerID string, ttl time.Duration) (*models.Task, error)
+	RenewLease(ctx context.Context, taskID, workerID string, ttl time.Duration) (*models.Task, error)
+	ExpireLeases(ctx context.Context) ([]string, error)
+	// Tag related methods
+	AddTags(ctx context.Context, taskID string, tags []string) (*models.Task, error)
+	RemoveTags(ctx context.Context, taskID string, tags []string) (*models.Task, error)
+	ListByTag(ctx context.Context, tag string) ([]*models.Task, error)
+	Query(ctx context.Context, query *models.TaskQuery) ([]*models.Task, error)
+	// Checklist related methods
+	AddChecklistItem(ctx context.Context, taskID, text string) (*models.Task, error)
+	ToggleChecklistItem(ctx context.Context, taskID, itemID string, done *bool) (*models.Task, error)
+	RemoveChecklistItem(ctx context.Context, taskID, itemID string) (*models.Task, error)
+	// Time tracking related methods
+	LogTime(ctx context.Context, taskID string, duration time.Duration) (*models.Task, error)
+	// Completion related methods
+	ListCompletions(ctx context.Context, planID string) ([]*models.TaskCompletion, error)
+	// Metadata related methods
+	SetMetadata(ctx context.Context, id string, metadata map[string]string) (*models.Task, error)
+	DeleteMetadata(ctx context.Context, id string, keys []string) (*models.Task, error)
 }
 
 // Ensure the concrete types implement the interfaces
 var (
 	_ PlanRepositoryInterface = (*PlanRepository)(nil)
 	_ TaskRepositoryInterface = (*TaskRepository)(nil)
+
+	_ ApplicationRepositoryInterface = (*ApplicationRepository)(nil)
 )
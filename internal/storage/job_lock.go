@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/valkey-io/valkey-glide/go/v2/options"
+)
+
+// JobLocker hands out the right to run a background job to one server replica at a time
+type JobLocker struct {
+	client *ValkeyClient
+	owner  string
+}
+
+// NewJobLocker creates a job locker for the server replica identified by owner
+func NewJobLocker(client *ValkeyClient, owner string) *JobLocker {
+	return &JobLocker{client: client, owner: owner}
+}
+
+// TryLock takes the lock of a job for ttl if no replica holds it and reports whether it was taken.
+// The lock is never released early: it expires on its own, so a job runs at most once per ttl across
+// all replicas even when they run it at slightly different times.
+func (l *JobLocker) TryLock(ctx context.Context, job string, ttl time.Duration) (bool, error) {
+	setOpts := options.NewSetOptions().
+		SetOnlyIfDoesNotExist().
+		SetExpiry(options.NewExpiryIn(ttl))
+	result, err := l.client.client.SetWithOptions(ctx, GetJobLockKey(job), l.owner, *setOpts)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire job lock: %w", err)
+	}
+	return !result.IsNil(), nil
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/valkey-io/valkey-glide/go/v2/options"
+)
+
+// DefaultLeaderTTL is how long leadership lasts without being renewed
+const DefaultLeaderTTL = 15 * time.Second
+
+// LeaderElection elects one server replica as the leader, which runs the background jobs. The leader
+// holds a key in Valkey that it renews; when it stops renewing, another replica takes over after the TTL.
+type LeaderElection struct {
+	client *ValkeyClient
+	owner  string
+	ttl    time.Duration
+}
+
+// NewLeaderElection creates a leader election for the server replica identified by owner
+func NewLeaderElection(client *ValkeyClient, owner string, ttl time.Duration) *LeaderElection {
+	if ttl <= 0 {
+		ttl = DefaultLeaderTTL
+	}
+	return &LeaderElection{client: client, owner: owner, ttl: ttl}
+}
+
+// TTL returns how long leadership lasts without being renewed
+func (e *LeaderElection) TTL() time.Duration {
+	return e.ttl
+}
+
+// Campaign renews leadership if this replica is the leader, or takes it if there is none,
+// and reports whether this replica leads
+func (e *LeaderElection) Campaign(ctx context.Context) (bool, error) {
+	scriptOpts := options.NewScriptOptions().
+		WithKeys([]string{leaderKey}).
+		WithArgs([]string{e.owner, strconv.FormatInt(e.ttl.Milliseconds(), 10)})
+	result, err := e.client.client.InvokeScriptWithOptions(ctx, *renewLeaseScript, *scriptOpts)
+	if err != nil {
+		return false, fmt.Errorf("failed to renew leadership: %w", err)
+	}
+	if renewed, ok := result.(int64); ok && renewed == 1 {
+		return true, nil
+	}
+
+	setOpts := options.NewSetOptions().
+		SetOnlyIfDoesNotExist().
+		SetExpiry(options.NewExpiryIn(e.ttl))
+	taken, err := e.client.client.SetWithOptions(ctx, leaderKey, e.owner, *setOpts)
+	if err != nil {
+		return false, fmt.Errorf("failed to take leadership: %w", err)
+	}
+	return !taken.IsNil(), nil
+}
+
+// Resign gives up leadership, so another replica can take over without waiting for the TTL
+func (e *LeaderElection) Resign(ctx context.Context) error {
+	scriptOpts := options.NewScriptOptions().WithKeys([]string{leaderKey}).WithArgs([]string{e.owner})
+	_, err := e.client.client.InvokeScriptWithOptions(ctx, *releaseLockScript, *scriptOpts)
+	if err != nil {
+		return fmt.Errorf("failed to resign leadership: %w", err)
+	}
+	return nil
+}
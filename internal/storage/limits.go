@@ -0,0 +1,281 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// ErrLimitExceeded is returned when a write exceeds one of the configured limits
+var ErrLimitExceeded error = models.NewValidationError("", "", "limit exceeded")
+
+// Limits bounds what clients can write, so a runaway agent cannot fill Valkey memory.
+// Lengths are in bytes. A zero value disables the limit.
+type Limits struct {
+	// MaxTitleLength bounds plan names, task titles, checklist items and acceptance criteria
+	MaxTitleLength int
+	// MaxDescriptionLength bounds plan and task descriptions
+	MaxDescriptionLength int
+	// MaxNotesLength bounds plan and task notes
+	MaxNotesLength int
+	// CompactNotes is set when older notes are archived to keep notes short. Appends then only have to fit
+	// MaxNotesLength themselves, as the notes they are appended to are compacted.
+	CompactNotes bool
+	// MaxBulkTasks bounds the number of tasks created in a single bulk operation
+	MaxBulkTasks int
+	// MaxTasksPerPlan bounds the number of tasks in a plan
+	MaxTasksPerPlan int
+}
+
+// DefaultLimits returns the limits applied when none are configured
+func DefaultLimits() Limits {
+	return Limits{
+		MaxTitleLength:       500,
+		MaxDescriptionLength: 10000,
+		MaxNotesLength:       100000,
+		MaxBulkTasks:         500,
+		MaxTasksPerPlan:      5000,
+	}
+}
+
+// checkLength returns an error if a value is longer than the limit
+func checkLength(field, value string, limit int) error {
+	if limit > 0 && len(value) > limit {
+		return fmt.Errorf("%w: %s is %d bytes, the maximum is %d", ErrLimitExceeded, field, len(value), limit)
+	}
+	return nil
+}
+
+// checkTask checks the title and description of a task
+func (l Limits) checkTask(title, description string) error {
+	if err := checkLength("title", title, l.MaxTitleLength); err != nil {
+		return err
+	}
+	return checkLength("description", description, l.MaxDescriptionLength)
+}
+
+// checkBulk checks the size of a bulk operation and each of its tasks
+func (l Limits) checkBulk(tasks []TaskCreateInput) error {
+	if l.MaxBulkTasks > 0 && len(tasks) > l.MaxBulkTasks {
+		return fmt.Errorf("%w: %d tasks submitted at once, the maximum is %d", ErrLimitExceeded, len(tasks), l.MaxBulkTasks)
+	}
+	for i, task := range tasks {
+		if err := l.checkTask(task.Title, task.Description); err != nil {
+			return fmt.Errorf("task %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// LimitedPlanRepository decorates a plan repository and rejects writes that exceed the limits
+type LimitedPlanRepository struct {
+	PlanRepositoryInterface
+	limits Limits
+}
+
+// NewLimitedPlanRepository wraps a plan repository with the given limits
+func NewLimitedPlanRepository(inner PlanRepositoryInterface, limits Limits) *LimitedPlanRepository {
+	return &LimitedPlanRepository{
+		PlanRepositoryInterface: inner,
+		limits:                  limits,
+	}
+}
+
+// Create creates a plan if its name and description are within the limits
+func (r *LimitedPlanRepository) Create(
+	ctx context.Context,
+	applicationID, name, description string,
+) (*models.Plan, error) {
+	if err := r.checkPlan(name, description); err != nil {
+		return nil, err
+	}
+	return r.PlanRepositoryInterface.Create(ctx, applicationID, name, description)
+}
+
+// Update updates a plan if its name and description are within the limits.
+// Notes are checked when they are changed through UpdateNotes.
+func (r *LimitedPlanRepository) Update(ctx context.Context, plan *models.Plan) error {
+	if err := r.checkPlan(plan.Name, plan.Description); err != nil {
+		return err
+	}
+	return r.PlanRepositoryInterface.Update(ctx, plan)
+}
+
+// UpdateNotes updates the notes of a plan if they are within the limits
+func (r *LimitedPlanRepository) UpdateNotes(ctx context.Context, id string, notes string) error {
+	if err := checkLength("notes", notes, r.limits.MaxNotesLength); err != nil {
+		return err
+	}
+	return r.PlanRepositoryInterface.UpdateNotes(ctx, id, notes)
+}
+
+// AppendNotes appends to the notes of a plan if the notes stay within the limits
+func (r *LimitedPlanRepository) AppendNotes(ctx context.Context, id string, text string) (string, error) {
+	if r.limits.CompactNotes {
+		if err := checkLength("notes", text, r.limits.MaxNotesLength); err != nil {
+			return "", err
+		}
+	} else if r.limits.MaxNotesLength > 0 {
+		notes, err := r.PlanRepositoryInterface.GetNotes(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		if err := checkLength("notes", appendNotes(notes, text), r.limits.MaxNotesLength); err != nil {
+			return "", err
+		}
+	}
+	return r.PlanRepositoryInterface.AppendNotes(ctx, id, text)
+}
+
+// SetLock locks a plan if the reason of the lock is within the title limit, or unlocks it
+func (r *LimitedPlanRepository) SetLock(ctx context.Context, id string, lock *models.PlanLock) (*models.Plan, error) {
+	if lock != nil {
+		if err := checkLength("reason", lock.Reason, r.limits.MaxTitleLength); err != nil {
+			return nil, err
+		}
+	}
+	return r.PlanRepositoryInterface.SetLock(ctx, id, lock)
+}
+
+// LogActivity logs agent activity if its summary is within the description limit and its details within
+// the notes limit
+func (r *LimitedPlanRepository) LogActivity(
+	ctx context.Context,
+	planID string,
+	activity *models.AgentActivity,
+) (*models.AgentActivity, error) {
+	if err := checkLength("summary", activity.Summary, r.limits.MaxDescriptionLength); err != nil {
+		return nil, err
+	}
+	if len(activity.Details) > 0 {
+		details, err := json.Marshal(activity.Details)
+		if err != nil {
+			return nil, models.NewValidationError(models.EntityPlan, planID, "invalid activity details: %v", err)
+		}
+		if err := checkLength("details", string(details), r.limits.MaxNotesLength); err != nil {
+			return nil, err
+		}
+	}
+	return r.PlanRepositoryInterface.LogActivity(ctx, planID, activity)
+}
+
+// checkPlan checks the name and description of a plan
+func (r *LimitedPlanRepository) checkPlan(name, description string) error {
+	if err := checkLength("name", name, r.limits.MaxTitleLength); err != nil {
+		return err
+	}
+	return checkLength("description", description, r.limits.MaxDescriptionLength)
+}
+
+// LimitedTaskRepository decorates a task repository and rejects writes that exceed the limits
+type LimitedTaskRepository struct {
+	TaskRepositoryInterface
+	limits Limits
+}
+
+// NewLimitedTaskRepository wraps a task repository with the given limits
+func NewLimitedTaskRepository(inner TaskRepositoryInterface, limits Limits) *LimitedTaskRepository {
+	return &LimitedTaskRepository{
+		TaskRepositoryInterface: inner,
+		limits:                  limits,
+	}
+}
+
+// Create creates a task if it is within the limits and the plan has room for it
+func (r *LimitedTaskRepository) Create(
+	ctx context.Context,
+	planID, title, description string,
+	priority models.TaskPriority,
+) (*models.Task, error) {
+	if err := r.limits.checkTask(title, description); err != nil {
+		return nil, err
+	}
+	if err := r.checkPlanRoom(ctx, planID, 1); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.Create(ctx, planID, title, description, priority)
+}
+
+// CreateBulk creates tasks if they are within the limits and the plan has room for all of them
+func (r *LimitedTaskRepository) CreateBulk(
+	ctx context.Context,
+	planID string,
+	tasks []TaskCreateInput,
+) ([]*models.Task, error) {
+	if err := r.limits.checkBulk(tasks); err != nil {
+		return nil, err
+	}
+	if err := r.checkPlanRoom(ctx, planID, len(tasks)); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.CreateBulk(ctx, planID, tasks)
+}
+
+// CreateBulkWithOptions creates tasks if they are within the limits and the plan has room for all of them.
+// Room is checked for every submitted task, including those that may turn out to be duplicates.
+func (r *LimitedTaskRepository) CreateBulkWithOptions(
+	ctx context.Context,
+	planID string,
+	tasks []TaskCreateInput,
+	opts BulkCreateOptions,
+) (*BulkCreateReport, error) {
+	if err := r.limits.checkBulk(tasks); err != nil {
+		return nil, err
+	}
+	if err := r.checkPlanRoom(ctx, planID, len(tasks)); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.CreateBulkWithOptions(ctx, planID, tasks, opts)
+}
+
+// Update updates a task if its title, description, completion note and acceptance criteria are within the
+// limits. Notes are checked when they are changed through UpdateNotes.
+func (r *LimitedTaskRepository) Update(ctx context.Context, task *models.Task) error {
+	if err := r.limits.checkTask(task.Title, task.Description); err != nil {
+		return err
+	}
+	if err := checkLength("completion note", task.CompletionNote, r.limits.MaxDescriptionLength); err != nil {
+		return err
+	}
+	for _, criterion := range task.AcceptanceCriteria {
+		if err := checkLength("acceptance criterion", criterion.Text, r.limits.MaxTitleLength); err != nil {
+			return err
+		}
+	}
+	return r.TaskRepositoryInterface.Update(ctx, task)
+}
+
+// UpdateNotes updates the notes of a task if they are within the limits
+func (r *LimitedTaskRepository) UpdateNotes(ctx context.Context, id string, notes string) error {
+	if err := checkLength("notes", notes, r.limits.MaxNotesLength); err != nil {
+		return err
+	}
+	return r.TaskRepositoryInterface.UpdateNotes(ctx, id, notes)
+}
+
+// AddChecklistItem adds a checklist item if its text is within the limits
+func (r *LimitedTaskRepository) AddChecklistItem(ctx context.Context, taskID, text string) (*models.Task, error) {
+	if err := checkLength("checklist item", text, r.limits.MaxTitleLength); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.AddChecklistItem(ctx, taskID, text)
+}
+
+// checkPlanRoom returns an error if adding tasks would take a plan over the task limit
+func (r *LimitedTaskRepository) checkPlanRoom(ctx context.Context, planID string, adding int) error {
+	if r.limits.MaxTasksPerPlan <= 0 {
+		return nil
+	}
+
+	count, err := r.CountByPlan(ctx, planID)
+	if err != nil {
+		return err
+	}
+	if int(count)+adding > r.limits.MaxTasksPerPlan {
+		return fmt.Errorf("%w: plan %s has %d tasks, adding %d would exceed the maximum of %d",
+			ErrLimitExceeded, planID, count, adding, r.limits.MaxTasksPerPlan)
+	}
+	return nil
+}
@@ -0,0 +1,520 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+type planLockOverrideKey struct{}
+
+// WithPlanLockOverride returns a context whose changes are not rejected by the locks of plans, for requests
+// of admins
+func WithPlanLockOverride(ctx context.Context) context.Context {
+	return context.WithValue(ctx, planLockOverrideKey{}, true)
+}
+
+// PlanLockOverrideFromContext reports whether the changes of a context may ignore the locks of plans
+func PlanLockOverrideFromContext(ctx context.Context) bool {
+	override, _ := ctx.Value(planLockOverrideKey{}).(bool)
+	return override
+}
+
+// SetLock locks a plan against changes by anyone but admins, or unlocks it when lock is nil. Unlike LockPlan,
+// which keeps concurrent changes from interleaving for a moment, the lock stays until the plan is unlocked.
+func (r *PlanRepository) SetLock(ctx context.Context, id string, lock *models.PlanLock) (*models.Plan, error) {
+	ctx, unlock, err := r.client.lockPlan(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Get the plan first to verify it exists
+	plan, err := r.Get(withPrimaryReads(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+
+	planKey := GetPlanKey(plan.ID)
+	if lock == nil {
+		_, err = r.client.client.HDel(ctx, planKey,
+			[]string{models.PlanLockedAtField, models.PlanLockedByField, models.PlanLockReasonField})
+	} else {
+		_, err = r.client.client.HSet(ctx, planKey, lock.Fields())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set plan lock: %w", err)
+	}
+
+	return r.Get(ctx, id)
+}
+
+// CheckUnlocked returns a conflict error if a plan is locked and the context may not ignore its lock
+func CheckUnlocked(ctx context.Context, plan *models.Plan) error {
+	if plan.Lock == nil || PlanLockOverrideFromContext(ctx) {
+		return nil
+	}
+	locked := fmt.Sprintf("plan %s is locked by %s", plan.ID, plan.Lock.LockedBy)
+	if plan.Lock.Reason != "" {
+		locked += " (" + plan.Lock.Reason + ")"
+	}
+	return models.NewConflictError(models.EntityPlan, plan.ID,
+		"%s, only an admin can change it or its tasks until it is unlocked", locked)
+}
+
+// lockUnlockedPlans takes the locks of plans in a fixed order and returns a conflict error if one of them is
+// locked, so a plan cannot be locked between the check and the change it guards. Plans that do not exist are
+// left to the repository.
+func lockUnlockedPlans(
+	ctx context.Context,
+	plans PlanRepositoryInterface,
+	planIDs ...string,
+) (context.Context, func(), error) {
+	if PlanLockOverrideFromContext(ctx) {
+		return ctx, func() {}, nil
+	}
+
+	planIDs = slices.Clone(planIDs)
+	slices.Sort(planIDs)
+	var unlocks []func()
+	unlock := func() {
+		for _, unlock := range slices.Backward(unlocks) {
+			unlock()
+		}
+	}
+	for _, planID := range slices.Compact(planIDs) {
+		var unlockPlan func()
+		var err error
+		ctx, unlockPlan, err = plans.LockPlan(ctx, planID)
+		if err != nil {
+			unlock()
+			return nil, nil, err
+		}
+		unlocks = append(unlocks, unlockPlan)
+
+		plan, err := plans.Get(withPrimaryReads(ctx), planID)
+		if err == nil {
+			err = CheckUnlocked(ctx, plan)
+		} else if models.IsNotFound(err) {
+			err = nil
+		}
+		if err != nil {
+			unlock()
+			return nil, nil, err
+		}
+	}
+	return ctx, unlock, nil
+}
+
+// LockedPlanRepository decorates a plan repository and rejects changes to locked plans, unless the context
+// overrides plan locks
+type LockedPlanRepository struct {
+	PlanRepositoryInterface
+}
+
+// NewLockedPlanRepository wraps a plan repository with the enforcement of plan locks
+func NewLockedPlanRepository(inner PlanRepositoryInterface) *LockedPlanRepository {
+	return &LockedPlanRepository{PlanRepositoryInterface: inner}
+}
+
+// checkPlan takes the lock of a plan and returns a conflict error if the plan is locked. The change runs with
+// the returned context, under the lock, until unlock is called.
+func (r *LockedPlanRepository) checkPlan(ctx context.Context, id string) (context.Context, func(), error) {
+	return lockUnlockedPlans(ctx, r.PlanRepositoryInterface, id)
+}
+
+// Update updates a plan that is not locked
+func (r *LockedPlanRepository) Update(ctx context.Context, plan *models.Plan) error {
+	ctx, unlock, err := r.checkPlan(ctx, plan.ID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return r.PlanRepositoryInterface.Update(ctx, plan)
+}
+
+// UpdateStatus sets the status of a plan that is not locked
+func (r *LockedPlanRepository) UpdateStatus(
+	ctx context.Context,
+	id string,
+	status models.PlanStatus,
+) (*models.Plan, error) {
+	ctx, unlock, err := r.checkPlan(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return r.PlanRepositoryInterface.UpdateStatus(ctx, id, status)
+}
+
+// Delete deletes a plan that is not locked
+func (r *LockedPlanRepository) Delete(ctx context.Context, id string) error {
+	ctx, unlock, err := r.checkPlan(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return r.PlanRepositoryInterface.Delete(ctx, id)
+}
+
+// Restore restores a plan over a plan that is not locked
+func (r *LockedPlanRepository) Restore(ctx context.Context, plan *models.Plan) error {
+	ctx, unlock, err := r.checkPlan(ctx, plan.ID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return r.PlanRepositoryInterface.Restore(ctx, plan)
+}
+
+// ReorderPlan moves a plan that is not locked among the plans of its application
+func (r *LockedPlanRepository) ReorderPlan(ctx context.Context, id string, newOrder int) (*models.Plan, error) {
+	ctx, unlock, err := r.checkPlan(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return r.PlanRepositoryInterface.ReorderPlan(ctx, id, newOrder)
+}
+
+// UpdateNotes updates the notes of a plan that is not locked
+func (r *LockedPlanRepository) UpdateNotes(ctx context.Context, id string, notes string) error {
+	ctx, unlock, err := r.checkPlan(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return r.PlanRepositoryInterface.UpdateNotes(ctx, id, notes)
+}
+
+// AppendNotes appends to the notes of a plan that is not locked
+func (r *LockedPlanRepository) AppendNotes(ctx context.Context, id string, text string) (string, error) {
+	ctx, unlock, err := r.checkPlan(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+	return r.PlanRepositoryInterface.AppendNotes(ctx, id, text)
+}
+
+// RevertNotes reverts the notes of a plan that is not locked
+func (r *LockedPlanRepository) RevertNotes(ctx context.Context, id, revisionID string) (string, error) {
+	ctx, unlock, err := r.checkPlan(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+	return r.PlanRepositoryInterface.RevertNotes(ctx, id, revisionID)
+}
+
+// SetMetadata sets metadata of a plan that is not locked
+func (r *LockedPlanRepository) SetMetadata(
+	ctx context.Context,
+	id string,
+	metadata map[string]string,
+) (*models.Plan, error) {
+	ctx, unlock, err := r.checkPlan(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return r.PlanRepositoryInterface.SetMetadata(ctx, id, metadata)
+}
+
+// DeleteMetadata deletes metadata of a plan that is not locked
+func (r *LockedPlanRepository) DeleteMetadata(ctx context.Context, id string, keys []string) (*models.Plan, error) {
+	ctx, unlock, err := r.checkPlan(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return r.PlanRepositoryInterface.DeleteMetadata(ctx, id, keys)
+}
+
+// SetLock locks a plan that is not locked yet. A locked plan can be locked again, which replaces its lock, or
+// unlocked only by admins.
+func (r *LockedPlanRepository) SetLock(ctx context.Context, id string, lock *models.PlanLock) (*models.Plan, error) {
+	ctx, unlock, err := r.checkPlan(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return r.PlanRepositoryInterface.SetLock(ctx, id, lock)
+}
+
+// LockedTaskRepository decorates a task repository and rejects changes to the tasks of locked plans, unless
+// the context overrides plan locks. Lease renewals and the expiry of leases are not changes and pass through.
+type LockedTaskRepository struct {
+	TaskRepositoryInterface
+	plans PlanRepositoryInterface
+}
+
+// NewLockedTaskRepository wraps a task repository with the enforcement of plan locks. The plan repository
+// is used to look up the lock of a task's plan.
+func NewLockedTaskRepository(inner TaskRepositoryInterface, plans PlanRepositoryInterface) *LockedTaskRepository {
+	return &LockedTaskRepository{TaskRepositoryInterface: inner, plans: plans}
+}
+
+// checkPlan takes the lock of a plan and returns a conflict error if the plan is locked. The change runs with
+// the returned context, under the lock, until unlock is called.
+func (r *LockedTaskRepository) checkPlan(ctx context.Context, planID string) (context.Context, func(), error) {
+	return lockUnlockedPlans(ctx, r.plans, planID)
+}
+
+// checkTask takes the locks of the plan of a task and of the other given plans, and returns a conflict error
+// if one of them is locked. A task moved to another plan while waiting for the locks is a conflict as well.
+func (r *LockedTaskRepository) checkTask(
+	ctx context.Context,
+	id string,
+	planIDs ...string,
+) (context.Context, func(), error) {
+	if PlanLockOverrideFromContext(ctx) {
+		return ctx, func() {}, nil
+	}
+	task, err := r.Get(withPrimaryReads(ctx), id)
+	if err != nil {
+		if models.IsNotFound(err) {
+			return lockUnlockedPlans(ctx, r.plans, planIDs...)
+		}
+		return nil, nil, err
+	}
+
+	ctx, unlock, err := lockUnlockedPlans(ctx, r.plans, append(planIDs, task.PlanID)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	current, err := r.Get(withPrimaryReads(ctx), id)
+	if err == nil && current.PlanID != task.PlanID {
+		err = models.NewConflictError(models.EntityTask, id,
+			"task %s was moved to plan %s by another change, try again", id, current.PlanID)
+	} else if models.IsNotFound(err) {
+		err = nil
+	}
+	if err != nil {
+		unlock()
+		return nil, nil, err
+	}
+	return ctx, unlock, nil
+}
+
+// Create creates a task in a plan that is not locked
+func (r *LockedTaskRepository) Create(
+	ctx context.Context,
+	planID, title, description string,
+	priority models.TaskPriority,
+) (*models.Task, error) {
+	ctx, unlock, err := r.checkPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return r.TaskRepositoryInterface.Create(ctx, planID, title, description, priority)
+}
+
+// CreateBulk creates tasks in a plan that is not locked
+func (r *LockedTaskRepository) CreateBulk(
+	ctx context.Context,
+	planID string,
+	tasks []TaskCreateInput,
+) ([]*models.Task, error) {
+	ctx, unlock, err := r.checkPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return r.TaskRepositoryInterface.CreateBulk(ctx, planID, tasks)
+}
+
+// CreateBulkWithOptions creates tasks in a plan that is not locked
+func (r *LockedTaskRepository) CreateBulkWithOptions(
+	ctx context.Context,
+	planID string,
+	tasks []TaskCreateInput,
+	opts BulkCreateOptions,
+) (*BulkCreateReport, error) {
+	ctx, unlock, err := r.checkPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return r.TaskRepositoryInterface.CreateBulkWithOptions(ctx, planID, tasks, opts)
+}
+
+// Update updates a task of a plan that is not locked, which can only move to a plan that is not locked
+func (r *LockedTaskRepository) Update(ctx context.Context, task *models.Task) error {
+	ctx, unlock, err := r.checkTask(ctx, task.ID, task.PlanID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return r.TaskRepositoryInterface.Update(ctx, task)
+}
+
+// Delete deletes a task of a plan that is not locked
+func (r *LockedTaskRepository) Delete(ctx context.Context, id string) error {
+	ctx, unlock, err := r.checkTask(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return r.TaskRepositoryInterface.Delete(ctx, id)
+}
+
+// ReorderTask moves a task within a plan that is not locked
+func (r *LockedTaskRepository) ReorderTask(ctx context.Context, taskID string, newOrder int) error {
+	ctx, unlock, err := r.checkTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return r.TaskRepositoryInterface.ReorderTask(ctx, taskID, newOrder)
+}
+
+// ReorderTasks orders the tasks of a plan that is not locked
+func (r *LockedTaskRepository) ReorderTasks(ctx context.Context, planID string, taskIDs []string) ([]*models.Task, error) {
+	ctx, unlock, err := r.checkPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return r.TaskRepositoryInterface.ReorderTasks(ctx, planID, taskIDs)
+}
+
+// MoveTask moves a task between plans that are not locked
+func (r *LockedTaskRepository) MoveTask(
+	ctx context.Context,
+	taskID, planID string,
+	position int,
+) (*models.Task, error) {
+	ctx, unlock, err := r.checkTask(ctx, taskID, planID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return r.TaskRepositoryInterface.MoveTask(ctx, taskID, planID, position)
+}
+
+// Restore restores a task into a plan that is not locked
+func (r *LockedTaskRepository) Restore(ctx context.Context, task *models.Task) error {
+	ctx, unlock, err := r.checkPlan(ctx, task.PlanID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return r.TaskRepositoryInterface.Restore(ctx, task)
+}
+
+// UpdateNotes updates the notes of a task of a plan that is not locked
+func (r *LockedTaskRepository) UpdateNotes(ctx context.Context, id string, notes string) error {
+	ctx, unlock, err := r.checkTask(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return r.TaskRepositoryInterface.UpdateNotes(ctx, id, notes)
+}
+
+// ClaimTask claims a task of a plan that is not locked
+func (r *LockedTaskRepository) ClaimTask(
+	ctx context.Context,
+	taskID, workerID string,
+	ttl time.Duration,
+) (*models.Task, error) {
+	ctx, unlock, err := r.checkTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return r.TaskRepositoryInterface.ClaimTask(ctx, taskID, workerID, ttl)
+}
+
+// AddTags adds tags to a task of a plan that is not locked
+func (r *LockedTaskRepository) AddTags(ctx context.Context, taskID string, tags []string) (*models.Task, error) {
+	ctx, unlock, err := r.checkTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return r.TaskRepositoryInterface.AddTags(ctx, taskID, tags)
+}
+
+// RemoveTags removes tags from a task of a plan that is not locked
+func (r *LockedTaskRepository) RemoveTags(ctx context.Context, taskID string, tags []string) (*models.Task, error) {
+	ctx, unlock, err := r.checkTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return r.TaskRepositoryInterface.RemoveTags(ctx, taskID, tags)
+}
+
+// AddChecklistItem adds a checklist item to a task of a plan that is not locked
+func (r *LockedTaskRepository) AddChecklistItem(ctx context.Context, taskID, text string) (*models.Task, error) {
+	ctx, unlock, err := r.checkTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return r.TaskRepositoryInterface.AddChecklistItem(ctx, taskID, text)
+}
+
+// ToggleChecklistItem toggles a checklist item of a task of a plan that is not locked
+func (r *LockedTaskRepository) ToggleChecklistItem(
+	ctx context.Context,
+	taskID, itemID string,
+	done *bool,
+) (*models.Task, error) {
+	ctx, unlock, err := r.checkTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return r.TaskRepositoryInterface.ToggleChecklistItem(ctx, taskID, itemID, done)
+}
+
+// RemoveChecklistItem removes a checklist item from a task of a plan that is not locked
+func (r *LockedTaskRepository) RemoveChecklistItem(ctx context.Context, taskID, itemID string) (*models.Task, error) {
+	ctx, unlock, err := r.checkTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return r.TaskRepositoryInterface.RemoveChecklistItem(ctx, taskID, itemID)
+}
+
+// LogTime logs time on a task of a plan that is not locked
+func (r *LockedTaskRepository) LogTime(ctx context.Context, taskID string, duration time.Duration) (*models.Task, error) {
+	ctx, unlock, err := r.checkTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return r.TaskRepositoryInterface.LogTime(ctx, taskID, duration)
+}
+
+// SetMetadata sets metadata of a task of a plan that is not locked
+func (r *LockedTaskRepository) SetMetadata(
+	ctx context.Context,
+	id string,
+	metadata map[string]string,
+) (*models.Task, error) {
+	ctx, unlock, err := r.checkTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return r.TaskRepositoryInterface.SetMetadata(ctx, id, metadata)
+}
+
+// DeleteMetadata deletes metadata of a task of a plan that is not locked
+func (r *LockedTaskRepository) DeleteMetadata(ctx context.Context, id string, keys []string) (*models.Task, error) {
+	ctx, unlock, err := r.checkTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return r.TaskRepositoryInterface.DeleteMetadata(ctx, id, keys)
+}
@@ -0,0 +1,1245 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/valkey-io/valkey-glide/go/v2/constants"
+	glidemodels "github.com/valkey-io/valkey-glide/go/v2/models"
+	"github.com/valkey-io/valkey-glide/go/v2/options"
+)
+
+// MemoryConfig holds the settings of the in-memory store
+type MemoryConfig struct {
+	// SnapshotFile is a JSON file the data is loaded from when the store is created and saved to while it runs
+	// and when it is closed. When empty, the data is lost when the process exits.
+	SnapshotFile string
+	// SnapshotInterval is how often the data is saved to SnapshotFile; zero only saves it on close
+	SnapshotInterval time.Duration
+}
+
+// NewMemoryClient creates a client backed by an in-memory store instead of a Valkey server. It is meant for
+// demos, smoke tests and offline use; the data is only as durable as its snapshots.
+func NewMemoryClient(cfg MemoryConfig) (*ValkeyClient, error) {
+	store := newMemoryStore(cfg.SnapshotFile)
+	if cfg.SnapshotFile != "" {
+		if err := store.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	vc := &ValkeyClient{
+		client:    newValkeyConnection([]valkeyCommands{store}, nil),
+		stopWatch: func() {},
+	}
+	if cfg.SnapshotFile == "" || cfg.SnapshotInterval <= 0 {
+		return vc, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	vc.stopWatch = func() {
+		cancel()
+		<-done
+	}
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(cfg.SnapshotInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := store.save(); err != nil {
+					log.Printf("Warning: failed to save in-memory snapshot: %v", err)
+				}
+			}
+		}
+	}()
+	return vc, nil
+}
+
+// memoryStore implements the commands used by the repositories on maps guarded by a mutex, with the same
+// semantics as Valkey. Like Valkey, it removes a key when its collection becomes empty.
+type memoryStore struct {
+	mu           sync.Mutex
+	snapshotFile string
+
+	strings    map[string]memoryString
+	hashes     map[string]map[string]string
+	sets       map[string]map[string]struct{}
+	sortedSets map[string]map[string]float64
+	lists      map[string][]string
+	streams    map[string]*memoryStream
+}
+
+// memoryString is a string value and the Unix time in milliseconds it expires at, or zero if it does not
+type memoryString struct {
+	Value     string `json:"value"`
+	ExpiresAt int64  `json:"expires_at,omitempty"`
+}
+
+// memoryStream is a stream and the last ID added to it, which new IDs must be greater than
+type memoryStream struct {
+	Entries []memoryStreamEntry `json:"entries"`
+	LastID  streamID            `json:"last_id"`
+}
+
+// memoryStreamEntry is a stream entry with its fields in order
+type memoryStreamEntry struct {
+	ID     streamID    `json:"id"`
+	Fields [][2]string `json:"fields"`
+}
+
+// memorySnapshot is the JSON document the store is saved as
+type memorySnapshot struct {
+	Strings    map[string]memoryString       `json:"strings"`
+	Hashes     map[string]map[string]string  `json:"hashes"`
+	Sets       map[string][]string           `json:"sets"`
+	SortedSets map[string]map[string]float64 `json:"sorted_sets"`
+	Lists      map[string][]string           `json:"lists"`
+	Streams    map[string]*memoryStream      `json:"streams"`
+}
+
+// newMemoryStore creates an empty store
+func newMemoryStore(snapshotFile string) *memoryStore {
+	return &memoryStore{
+		snapshotFile: snapshotFile,
+		strings:      make(map[string]memoryString),
+		hashes:       make(map[string]map[string]string),
+		sets:         make(map[string]map[string]struct{}),
+		sortedSets:   make(map[string]map[string]float64),
+		lists:        make(map[string][]string),
+		streams:      make(map[string]*memoryStream),
+	}
+}
+
+// load replaces the data with the snapshot file, if it exists
+func (m *memoryStore) load() error {
+	data, err := os.ReadFile(m.snapshotFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read in-memory snapshot: %w", err)
+	}
+
+	var snapshot memorySnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("failed to parse in-memory snapshot %s: %w", m.snapshotFile, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, value := range snapshot.Strings {
+		m.strings[key] = value
+	}
+	for key, hash := range snapshot.Hashes {
+		m.hashes[key] = hash
+	}
+	for key, members := range snapshot.Sets {
+		set := make(map[string]struct{}, len(members))
+		for _, member := range members {
+			set[member] = struct{}{}
+		}
+		m.sets[key] = set
+	}
+	for key, zset := range snapshot.SortedSets {
+		m.sortedSets[key] = zset
+	}
+	for key, list := range snapshot.Lists {
+		m.lists[key] = list
+	}
+	for key, stream := range snapshot.Streams {
+		m.streams[key] = stream
+	}
+	return nil
+}
+
+// save writes the data to the snapshot file. The file is replaced atomically, so a crash while saving
+// leaves the previous snapshot intact.
+func (m *memoryStore) save() error {
+	m.mu.Lock()
+	m.expireStrings()
+	snapshot := memorySnapshot{
+		Strings:    m.strings,
+		Hashes:     m.hashes,
+		Sets:       make(map[string][]string, len(m.sets)),
+		SortedSets: m.sortedSets,
+		Lists:      m.lists,
+		Streams:    m.streams,
+	}
+	for key, set := range m.sets {
+		snapshot.Sets[key] = setMembers(set)
+	}
+	data, err := json.Marshal(snapshot)
+	m.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode in-memory snapshot: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(m.snapshotFile), filepath.Base(m.snapshotFile)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write in-memory snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() //nolint:errcheck
+		return fmt.Errorf("failed to write in-memory snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write in-memory snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), m.snapshotFile); err != nil {
+		return fmt.Errorf("failed to write in-memory snapshot: %w", err)
+	}
+	return nil
+}
+
+// expireStrings removes the expired strings; the caller must hold the lock
+func (m *memoryStore) expireStrings() {
+	now := time.Now().UnixMilli()
+	for key, value := range m.strings {
+		if value.ExpiresAt != 0 && value.ExpiresAt <= now {
+			delete(m.strings, key)
+		}
+	}
+}
+
+// liveString returns a string unless it is missing or expired; the caller must hold the lock
+func (m *memoryStore) liveString(key string) (memoryString, bool) {
+	value, ok := m.strings[key]
+	if ok && value.ExpiresAt != 0 && value.ExpiresAt <= time.Now().UnixMilli() {
+		delete(m.strings, key)
+		return memoryString{}, false
+	}
+	return value, ok
+}
+
+// errWrongType is returned, like by Valkey, when a command is used on a key holding another type of value
+var errWrongType = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+
+// keyType returns the type of the value at a key as reported by the TYPE command, or an empty string if
+// there is none; the caller must hold the lock
+func (m *memoryStore) keyType(key string) string {
+	if _, ok := m.liveString(key); ok {
+		return "string"
+	}
+	if _, ok := m.hashes[key]; ok {
+		return "hash"
+	}
+	if _, ok := m.sets[key]; ok {
+		return "set"
+	}
+	if _, ok := m.sortedSets[key]; ok {
+		return "zset"
+	}
+	if _, ok := m.lists[key]; ok {
+		return "list"
+	}
+	if _, ok := m.streams[key]; ok {
+		return "stream"
+	}
+	return ""
+}
+
+// checkType returns an error if a key holds a value of another type; the caller must hold the lock
+func (m *memoryStore) checkType(key, want string) error {
+	if t := m.keyType(key); t != "" && t != want {
+		return errWrongType
+	}
+	return nil
+}
+
+// exists reports whether a key holds a value of any type; the caller must hold the lock
+func (m *memoryStore) exists(key string) bool {
+	return m.keyType(key) != ""
+}
+
+// deleteKey removes the value at a key, whatever its type; the caller must hold the lock
+func (m *memoryStore) deleteKey(key string) {
+	delete(m.strings, key)
+	delete(m.hashes, key)
+	delete(m.sets, key)
+	delete(m.sortedSets, key)
+	delete(m.lists, key)
+	delete(m.streams, key)
+}
+
+// Ping always succeeds
+func (m *memoryStore) Ping(ctx context.Context) (string, error) {
+	return "PONG", nil
+}
+
+// Close saves the data if a snapshot file is configured
+func (m *memoryStore) Close() {
+	if m.snapshotFile == "" {
+		return
+	}
+	if err := m.save(); err != nil {
+		log.Printf("Warning: failed to save in-memory snapshot: %v", err)
+	}
+}
+
+// scanKeys returns the keys matching a glob-style pattern
+func (m *memoryStore) scanKeys(ctx context.Context, match string) ([]string, error) {
+	if _, err := path.Match(match, ""); err != nil {
+		return nil, fmt.Errorf("invalid key pattern %q: %w", match, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expireStrings()
+	var keys []string
+	keys = appendMatchingKeys(keys, match, m.strings)
+	keys = appendMatchingKeys(keys, match, m.hashes)
+	keys = appendMatchingKeys(keys, match, m.sets)
+	keys = appendMatchingKeys(keys, match, m.sortedSets)
+	keys = appendMatchingKeys(keys, match, m.lists)
+	keys = appendMatchingKeys(keys, match, m.streams)
+	return keys, nil
+}
+
+func (m *memoryStore) Del(ctx context.Context, keys []string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var deleted int64
+	for _, key := range keys {
+		if !m.exists(key) {
+			continue
+		}
+		m.deleteKey(key)
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (m *memoryStore) Exists(ctx context.Context, keys []string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var count int64
+	for _, key := range keys {
+		if m.exists(key) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *memoryStore) Type(ctx context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if keyType := m.keyType(key); keyType != "" {
+		return keyType, nil
+	}
+	return "none", nil
+}
+
+func (m *memoryStore) Get(ctx context.Context, key string) (glidemodels.Result[string], error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "string"); err != nil {
+		return glidemodels.CreateNilStringResult(), err
+	}
+
+	value, ok := m.liveString(key)
+	if !ok {
+		return glidemodels.CreateNilStringResult(), nil
+	}
+	return glidemodels.CreateStringResult(value.Value), nil
+}
+
+func (m *memoryStore) SetWithOptions(
+	ctx context.Context,
+	key, value string,
+	opts options.SetOptions,
+) (glidemodels.Result[string], error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Conditions and returning the old value need a string, but a plain SET replaces a value of any type
+	if opts.ConditionalSet != "" || opts.ReturnOldValue {
+		if err := m.checkType(key, "string"); err != nil {
+			return glidemodels.CreateNilStringResult(), err
+		}
+	}
+	old, exists := m.liveString(key)
+	switch opts.ConditionalSet {
+	case constants.OnlyIfDoesNotExist:
+		if exists {
+			return glidemodels.CreateNilStringResult(), nil
+		}
+	case constants.OnlyIfExists:
+		if !exists {
+			return glidemodels.CreateNilStringResult(), nil
+		}
+	case constants.OnlyIfEquals:
+		if !exists || old.Value != opts.ComparisonValue {
+			return glidemodels.CreateNilStringResult(), nil
+		}
+	}
+
+	stored := memoryString{Value: value}
+	if opts.Expiry != nil {
+		switch opts.Expiry.Type {
+		case constants.Seconds:
+			stored.ExpiresAt = time.Now().Add(time.Duration(opts.Expiry.Duration) * time.Second).UnixMilli()
+		case constants.Milliseconds:
+			stored.ExpiresAt = time.Now().Add(time.Duration(opts.Expiry.Duration) * time.Millisecond).UnixMilli()
+		case constants.UnixSeconds, constants.UnixMilliseconds:
+			stored.ExpiresAt = opts.Expiry.Timestamp.UnixMilli()
+		case constants.KeepExisting:
+			stored.ExpiresAt = old.ExpiresAt
+		}
+	}
+	m.deleteKey(key)
+	m.strings[key] = stored
+
+	if opts.ReturnOldValue {
+		if !exists {
+			return glidemodels.CreateNilStringResult(), nil
+		}
+		return glidemodels.CreateStringResult(old.Value), nil
+	}
+	return glidemodels.CreateStringResult("OK"), nil
+}
+
+// InvokeScriptWithOptions runs the scripts of the repositories, which the store implements natively
+func (m *memoryStore) InvokeScriptWithOptions(
+	ctx context.Context,
+	script options.Script,
+	scriptOptions options.ScriptOptions,
+) (any, error) {
+	switch script.GetHash() {
+	case renewLeaseScript.GetHash():
+		if len(scriptOptions.Keys) != 1 || len(scriptOptions.Args) != 2 {
+			return nil, fmt.Errorf("lease renewal expects 1 key and 2 arguments")
+		}
+		ttl, err := strconv.ParseInt(scriptOptions.Args[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lease TTL %q", scriptOptions.Args[1])
+		}
+		return m.updateIfOwner(scriptOptions.Keys[0], scriptOptions.Args[0], func(key string, value memoryString) {
+			value.ExpiresAt = time.Now().UnixMilli() + ttl
+			m.strings[key] = value
+		})
+	case releaseLockScript.GetHash():
+		if len(scriptOptions.Keys) != 1 || len(scriptOptions.Args) != 1 {
+			return nil, fmt.Errorf("lock release expects 1 key and 1 argument")
+		}
+		return m.updateIfOwner(scriptOptions.Keys[0], scriptOptions.Args[0], func(key string, value memoryString) {
+			delete(m.strings, key)
+		})
+	case adjustTaskCountsScript.GetHash():
+		if len(scriptOptions.Keys) != 1 {
+			return nil, fmt.Errorf("task count adjustment expects 1 key")
+		}
+		return m.adjustTaskCounts(scriptOptions.Keys[0], scriptOptions.Args)
+	default:
+		return nil, fmt.Errorf("script %s is not supported by the in-memory store", script.GetHash())
+	}
+}
+
+// updateIfOwner applies update to a string key if it holds the given owner, returning 1 if it did and 0 otherwise
+func (m *memoryStore) updateIfOwner(key, owner string, update func(key string, value memoryString)) (any, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "string"); err != nil {
+		return nil, err
+	}
+	value, ok := m.liveString(key)
+	if !ok || value.Value != owner {
+		return int64(0), nil
+	}
+	update(key, value)
+	return int64(1), nil
+}
+
+// adjustTaskCounts increments the task counters of a plan hash given as pairs of fields and increments after
+// the time of the change and the counter fields of the active statuses, and derives the plan status from
+// them, if the plan is counted. It returns 1 if it is and 0 otherwise.
+func (m *memoryStore) adjustTaskCounts(key string, args []string) (any, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("task count adjustment expects a time and the active counter fields")
+	}
+	active, err := strconv.Atoi(args[1])
+	if err != nil || active < 0 || len(args) < 2+active || (len(args)-2-active)%2 != 0 {
+		return nil, fmt.Errorf("task count adjustment expects the active counter fields and pairs of fields and increments")
+	}
+	activeFields, pairs := args[2:2+active], args[2+active:]
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "hash"); err != nil {
+		return nil, err
+	}
+	hash := m.hashes[key]
+	if _, ok := hash[models.TaskCountTotalField]; !ok {
+		return int64(0), nil
+	}
+
+	for i := 0; i < len(pairs); i += 2 {
+		increment, err := strconv.ParseInt(pairs[i+1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid increment %q", pairs[i+1])
+		}
+		var current int64
+		if value, ok := hash[pairs[i]]; ok {
+			current, err = strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("hash value is not an integer")
+			}
+		}
+		hash[pairs[i]] = strconv.FormatInt(current+increment, 10)
+	}
+
+	// Derive the status like the script does, from the counters of the active statuses it was given
+	total, _ := strconv.Atoi(hash[models.TaskCountTotalField])
+	completed, _ := strconv.Atoi(hash[models.TaskCountField(models.TaskStatusCompleted)])
+	working := 0
+	for _, field := range activeFields {
+		count, _ := strconv.Atoi(hash[field])
+		working += count
+	}
+	status := string(models.PlanStatusNew)
+	switch {
+	case total > 0 && completed == total:
+		status = string(models.PlanStatusCompleted)
+	case working > 0:
+		status = string(models.PlanStatusInProgress)
+	}
+	if hash["status"] != status {
+		hash["status"] = status
+		hash["updated_at"] = args[0]
+	}
+	return int64(1), nil
+}
+
+func (m *memoryStore) HDel(ctx context.Context, key string, fields []string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "hash"); err != nil {
+		return 0, err
+	}
+
+	hash := m.hashes[key]
+	var deleted int64
+	for _, field := range fields {
+		if _, ok := hash[field]; ok {
+			delete(hash, field)
+			deleted++
+		}
+	}
+	if hash != nil && len(hash) == 0 {
+		delete(m.hashes, key)
+	}
+	return deleted, nil
+}
+
+func (m *memoryStore) HGet(ctx context.Context, key, field string) (glidemodels.Result[string], error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "hash"); err != nil {
+		return glidemodels.CreateNilStringResult(), err
+	}
+
+	value, ok := m.hashes[key][field]
+	if !ok {
+		return glidemodels.CreateNilStringResult(), nil
+	}
+	return glidemodels.CreateStringResult(value), nil
+}
+
+func (m *memoryStore) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "hash"); err != nil {
+		return nil, err
+	}
+
+	hash := make(map[string]string, len(m.hashes[key]))
+	for field, value := range m.hashes[key] {
+		hash[field] = value
+	}
+	return hash, nil
+}
+
+func (m *memoryStore) HIncrBy(ctx context.Context, key, field string, increment int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "hash"); err != nil {
+		return 0, err
+	}
+
+	hash := m.hashes[key]
+	if hash == nil {
+		hash = make(map[string]string)
+		m.hashes[key] = hash
+	}
+	var current int64
+	if value, ok := hash[field]; ok {
+		var err error
+		current, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("hash value is not an integer")
+		}
+	}
+	current += increment
+	hash[field] = strconv.FormatInt(current, 10)
+	return current, nil
+}
+
+func (m *memoryStore) HSet(ctx context.Context, key string, values map[string]string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "hash"); err != nil {
+		return 0, err
+	}
+
+	hash := m.hashes[key]
+	if hash == nil {
+		hash = make(map[string]string, len(values))
+		m.hashes[key] = hash
+	}
+	var added int64
+	for field, value := range values {
+		if _, ok := hash[field]; !ok {
+			added++
+		}
+		hash[field] = value
+	}
+	return added, nil
+}
+
+func (m *memoryStore) LRange(ctx context.Context, key string, start, end int64) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "list"); err != nil {
+		return nil, err
+	}
+
+	list := m.lists[key]
+	from, to, ok := indexRange(start, end, len(list))
+	if !ok {
+		return []string{}, nil
+	}
+	return append([]string{}, list[from:to+1]...), nil
+}
+
+func (m *memoryStore) LRem(ctx context.Context, key string, count int64, element string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "list"); err != nil {
+		return 0, err
+	}
+
+	list := m.lists[key]
+	limit := count
+	if limit < 0 {
+		limit = -limit
+	}
+
+	// A negative count removes from the tail, so walk the list backwards
+	remove := make(map[int]bool)
+	for i := range list {
+		index := i
+		if count < 0 {
+			index = len(list) - 1 - i
+		}
+		if list[index] == element {
+			remove[index] = true
+			if limit > 0 && int64(len(remove)) == limit {
+				break
+			}
+		}
+	}
+
+	kept := list[:0]
+	for i, value := range list {
+		if !remove[i] {
+			kept = append(kept, value)
+		}
+	}
+	if len(kept) == 0 {
+		delete(m.lists, key)
+	} else {
+		m.lists[key] = kept
+	}
+	return int64(len(remove)), nil
+}
+
+func (m *memoryStore) LSet(ctx context.Context, key string, index int64, element string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "list"); err != nil {
+		return "", err
+	}
+
+	list, ok := m.lists[key]
+	if !ok {
+		return "", fmt.Errorf("no such key")
+	}
+	if index < 0 {
+		index += int64(len(list))
+	}
+	if index < 0 || index >= int64(len(list)) {
+		return "", fmt.Errorf("index out of range")
+	}
+	list[index] = element
+	return "OK", nil
+}
+
+func (m *memoryStore) RPush(ctx context.Context, key string, elements []string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "list"); err != nil {
+		return 0, err
+	}
+
+	m.lists[key] = append(m.lists[key], elements...)
+	return int64(len(m.lists[key])), nil
+}
+
+func (m *memoryStore) SAdd(ctx context.Context, key string, members []string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "set"); err != nil {
+		return 0, err
+	}
+
+	set := m.sets[key]
+	if set == nil {
+		set = make(map[string]struct{}, len(members))
+		m.sets[key] = set
+	}
+	var added int64
+	for _, member := range members {
+		if _, ok := set[member]; !ok {
+			set[member] = struct{}{}
+			added++
+		}
+	}
+	return added, nil
+}
+
+func (m *memoryStore) SIsMember(ctx context.Context, key, member string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "set"); err != nil {
+		return false, err
+	}
+
+	_, ok := m.sets[key][member]
+	return ok, nil
+}
+
+func (m *memoryStore) SMembers(ctx context.Context, key string) (map[string]struct{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "set"); err != nil {
+		return nil, err
+	}
+
+	set := make(map[string]struct{}, len(m.sets[key]))
+	for member := range m.sets[key] {
+		set[member] = struct{}{}
+	}
+	return set, nil
+}
+
+func (m *memoryStore) SRem(ctx context.Context, key string, members []string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "set"); err != nil {
+		return 0, err
+	}
+
+	set := m.sets[key]
+	var removed int64
+	for _, member := range members {
+		if _, ok := set[member]; ok {
+			delete(set, member)
+			removed++
+		}
+	}
+	if set != nil && len(set) == 0 {
+		delete(m.sets, key)
+	}
+	return removed, nil
+}
+
+func (m *memoryStore) ZAdd(ctx context.Context, key string, membersScoreMap map[string]float64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "zset"); err != nil {
+		return 0, err
+	}
+
+	zset := m.sortedSets[key]
+	if zset == nil {
+		zset = make(map[string]float64, len(membersScoreMap))
+		m.sortedSets[key] = zset
+	}
+	var added int64
+	for member, score := range membersScoreMap {
+		if _, ok := zset[member]; !ok {
+			added++
+		}
+		zset[member] = score
+	}
+	return added, nil
+}
+
+func (m *memoryStore) ZCard(ctx context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "zset"); err != nil {
+		return 0, err
+	}
+
+	return int64(len(m.sortedSets[key])), nil
+}
+
+func (m *memoryStore) ZRange(ctx context.Context, key string, rangeQuery options.ZRangeQuery) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "zset"); err != nil {
+		return nil, err
+	}
+
+	zset := m.sortedSets[key]
+	members := sortedMembers(zset)
+
+	switch query := rangeQuery.(type) {
+	case *options.RangeByIndex:
+		if query.Reverse {
+			reverse(members)
+		}
+		from, to, ok := indexRange(query.Start, query.End, len(members))
+		if !ok {
+			return []string{}, nil
+		}
+		return members[from : to+1], nil
+
+	case *options.RangeByScore:
+		low, high := string(query.Start), string(query.End)
+		if query.Reverse {
+			reverse(members)
+			low, high = high, low
+		}
+		lowScore, lowExclusive, err := parseScoreBoundary(low)
+		if err != nil {
+			return nil, err
+		}
+		highScore, highExclusive, err := parseScoreBoundary(high)
+		if err != nil {
+			return nil, err
+		}
+
+		result := []string{}
+		for _, member := range members {
+			score := zset[member]
+			if score < lowScore || (lowExclusive && score == lowScore) ||
+				score > highScore || (highExclusive && score == highScore) {
+				continue
+			}
+			result = append(result, member)
+		}
+		if query.Limit != nil {
+			offset := min(max(query.Limit.Offset, 0), int64(len(result)))
+			result = result[offset:]
+			if query.Limit.Count >= 0 && query.Limit.Count < int64(len(result)) {
+				result = result[:query.Limit.Count]
+			}
+		}
+		return result, nil
+
+	default:
+		return nil, fmt.Errorf("sorted set query %T is not supported by the in-memory store", rangeQuery)
+	}
+}
+
+func (m *memoryStore) ZRangeWithScores(
+	ctx context.Context, key string, rangeQuery options.ZRangeQueryWithScores,
+) ([]glidemodels.MemberAndScore, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "zset"); err != nil {
+		return nil, err
+	}
+
+	query, ok := rangeQuery.(*options.RangeByIndex)
+	if !ok {
+		return nil, fmt.Errorf("sorted set query %T with scores is not supported by the in-memory store", rangeQuery)
+	}
+
+	zset := m.sortedSets[key]
+	members := sortedMembers(zset)
+	if query.Reverse {
+		reverse(members)
+	}
+	from, to, ok := indexRange(query.Start, query.End, len(members))
+	if !ok {
+		return []glidemodels.MemberAndScore{}, nil
+	}
+	result := make([]glidemodels.MemberAndScore, 0, to-from+1)
+	for _, member := range members[from : to+1] {
+		result = append(result, glidemodels.MemberAndScore{Member: member, Score: zset[member]})
+	}
+	return result, nil
+}
+
+func (m *memoryStore) ZRank(ctx context.Context, key, member string) (glidemodels.Result[int64], error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "zset"); err != nil {
+		return glidemodels.CreateNilInt64Result(), err
+	}
+
+	for rank, candidate := range sortedMembers(m.sortedSets[key]) {
+		if candidate == member {
+			return glidemodels.CreateInt64Result(int64(rank)), nil
+		}
+	}
+	return glidemodels.CreateNilInt64Result(), nil
+}
+
+func (m *memoryStore) ZRem(ctx context.Context, key string, members []string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "zset"); err != nil {
+		return 0, err
+	}
+
+	zset := m.sortedSets[key]
+	var removed int64
+	for _, member := range members {
+		if _, ok := zset[member]; ok {
+			delete(zset, member)
+			removed++
+		}
+	}
+	if zset != nil && len(zset) == 0 {
+		delete(m.sortedSets, key)
+	}
+	return removed, nil
+}
+
+func (m *memoryStore) XAddWithOptions(
+	ctx context.Context,
+	key string,
+	values []glidemodels.FieldValue,
+	opts options.XAddOptions,
+) (glidemodels.Result[string], error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "stream"); err != nil {
+		return glidemodels.CreateNilStringResult(), err
+	}
+
+	stream := m.streams[key]
+	if stream == nil {
+		stream = &memoryStream{}
+	}
+
+	// Generated IDs use the current time, and the sequence when the clock has not moved past the last ID
+	var id streamID
+	if opts.Id == "" || opts.Id == "*" {
+		id = streamID{Ms: uint64(time.Now().UnixMilli())}
+		if id.Ms <= stream.LastID.Ms {
+			id = streamID{Ms: stream.LastID.Ms, Seq: stream.LastID.Seq + 1}
+		}
+	} else {
+		var err error
+		id, err = parseStreamID(opts.Id, 0)
+		if err != nil {
+			return glidemodels.CreateNilStringResult(), err
+		}
+		if !stream.LastID.less(id) {
+			return glidemodels.CreateNilStringResult(),
+				fmt.Errorf("the ID specified in XADD is equal or smaller than the target stream top item")
+		}
+	}
+
+	entry := memoryStreamEntry{ID: id, Fields: make([][2]string, 0, len(values))}
+	for _, value := range values {
+		entry.Fields = append(entry.Fields, [2]string{value.Field, value.Value})
+	}
+	stream.Entries = append(stream.Entries, entry)
+	stream.LastID = id
+	m.streams[key] = stream
+
+	if opts.TrimOptions != nil {
+		if _, err := stream.trim(*opts.TrimOptions); err != nil {
+			return glidemodels.CreateNilStringResult(), err
+		}
+	}
+	return glidemodels.CreateStringResult(id.String()), nil
+}
+
+func (m *memoryStore) XRevRangeWithOptions(
+	ctx context.Context,
+	key string,
+	start, end options.StreamBoundary,
+	opts options.XRangeOptions,
+) ([]glidemodels.StreamEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "stream"); err != nil {
+		return nil, err
+	}
+
+	high, highExclusive, err := parseStreamBoundary(string(start), math.MaxUint64)
+	if err != nil {
+		return nil, err
+	}
+	low, lowExclusive, err := parseStreamBoundary(string(end), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []glidemodels.StreamEntry{}
+	stream := m.streams[key]
+	if stream == nil || opts.Count == 0 {
+		return entries, nil
+	}
+	for i := len(stream.Entries) - 1; i >= 0; i-- {
+		entry := stream.Entries[i]
+		if high.less(entry.ID) || (highExclusive && entry.ID == high) {
+			continue
+		}
+		if entry.ID.less(low) || (lowExclusive && entry.ID == low) {
+			break
+		}
+
+		fields := make([]glidemodels.FieldValue, 0, len(entry.Fields))
+		for _, field := range entry.Fields {
+			fields = append(fields, glidemodels.FieldValue{Field: field[0], Value: field[1]})
+		}
+		entries = append(entries, glidemodels.StreamEntry{ID: entry.ID.String(), Fields: fields})
+		if opts.Count > 0 && int64(len(entries)) == opts.Count {
+			break
+		}
+	}
+	return entries, nil
+}
+
+func (m *memoryStore) XTrim(ctx context.Context, key string, opts options.XTrimOptions) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkType(key, "stream"); err != nil {
+		return 0, err
+	}
+
+	stream := m.streams[key]
+	if stream == nil {
+		return 0, nil
+	}
+	return stream.trim(opts)
+}
+
+// trim removes the entries beyond the maximum length or below the minimum ID. Trimming is always exact.
+func (s *memoryStream) trim(opts options.XTrimOptions) (int64, error) {
+	keep := 0
+	switch opts.Method {
+	case constants.MaxLenKeyword:
+		maxLen, err := strconv.Atoi(opts.Threshold)
+		if err != nil || maxLen < 0 {
+			return 0, fmt.Errorf("invalid stream length %q", opts.Threshold)
+		}
+		keep = max(len(s.Entries)-maxLen, 0)
+	case constants.MinIdKeyword:
+		minID, err := parseStreamID(opts.Threshold, 0)
+		if err != nil {
+			return 0, err
+		}
+		for keep < len(s.Entries) && s.Entries[keep].ID.less(minID) {
+			keep++
+		}
+	default:
+		return 0, fmt.Errorf("stream trim method %q is not supported by the in-memory store", opts.Method)
+	}
+
+	s.Entries = append([]memoryStreamEntry{}, s.Entries[keep:]...)
+	return int64(keep), nil
+}
+
+// streamID is a stream entry ID: a millisecond timestamp and a sequence number
+type streamID struct {
+	Ms  uint64
+	Seq uint64
+}
+
+// String formats the ID as Valkey does
+func (id streamID) String() string {
+	return fmt.Sprintf("%d-%d", id.Ms, id.Seq)
+}
+
+// MarshalJSON encodes the ID in its string form
+func (id streamID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(id.String())
+}
+
+// UnmarshalJSON decodes an ID from its string form
+func (id *streamID) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	parsed, err := parseStreamID(value, 0)
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// less reports whether the ID comes before another
+func (id streamID) less(other streamID) bool {
+	return id.Ms < other.Ms || (id.Ms == other.Ms && id.Seq < other.Seq)
+}
+
+// parseStreamID parses an ID, using defaultSeq when only the timestamp is given
+func parseStreamID(value string, defaultSeq uint64) (streamID, error) {
+	msStr, seqStr, hasSeq := strings.Cut(value, "-")
+	ms, err := strconv.ParseUint(msStr, 10, 64)
+	if err != nil {
+		return streamID{}, fmt.Errorf("invalid stream ID %q", value)
+	}
+	seq := defaultSeq
+	if hasSeq {
+		seq, err = strconv.ParseUint(seqStr, 10, 64)
+		if err != nil {
+			return streamID{}, fmt.Errorf("invalid stream ID %q", value)
+		}
+	}
+	return streamID{Ms: ms, Seq: seq}, nil
+}
+
+// parseStreamBoundary parses a range boundary, which is "-", "+" or an ID optionally prefixed with "(" to
+// exclude it. defaultSeq completes IDs given as a timestamp alone.
+func parseStreamBoundary(value string, defaultSeq uint64) (streamID, bool, error) {
+	switch value {
+	case string(constants.NegativeInfinity):
+		return streamID{}, false, nil
+	case string(constants.PositiveInfinity):
+		return streamID{Ms: math.MaxUint64, Seq: math.MaxUint64}, false, nil
+	}
+	exclusive := strings.HasPrefix(value, "(")
+	id, err := parseStreamID(strings.TrimPrefix(value, "("), defaultSeq)
+	return id, exclusive, err
+}
+
+// parseScoreBoundary parses a score range boundary, which is "-inf", "+inf" or a score optionally prefixed
+// with "(" to exclude it
+func parseScoreBoundary(value string) (float64, bool, error) {
+	exclusive := strings.HasPrefix(value, "(")
+	score, err := strconv.ParseFloat(strings.TrimPrefix(value, "("), 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid score boundary %q", value)
+	}
+	return score, exclusive, nil
+}
+
+// indexRange converts inclusive start and end indexes, which may count from the end when negative, into
+// bounds within a sequence of the given length. It returns false when the range is empty.
+func indexRange(start, end int64, length int) (int, int, bool) {
+	n := int64(length)
+	if start < 0 {
+		start += n
+	}
+	if end < 0 {
+		end += n
+	}
+	start = max(start, 0)
+	end = min(end, n-1)
+	if start > end || start >= n {
+		return 0, 0, false
+	}
+	return int(start), int(end), true
+}
+
+// appendMatchingKeys appends the keys of a map matching a glob-style pattern
+func appendMatchingKeys[V any](keys []string, match string, values map[string]V) []string {
+	for key := range values {
+		if matched, err := path.Match(match, key); err == nil && matched {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// reverse reverses a slice in place
+func reverse(values []string) {
+	for i, j := 0, len(values)-1; i < j; i, j = i+1, j-1 {
+		values[i], values[j] = values[j], values[i]
+	}
+}
+
+// setMembers returns the members of a set in sorted order
+func setMembers(set map[string]struct{}) []string {
+	members := make([]string, 0, len(set))
+	for member := range set {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	return members
+}
+
+// sortedMembers returns the members of a sorted set ordered by score, then lexicographically
+func sortedMembers(zset map[string]float64) []string {
+	members := make([]string, 0, len(zset))
+	for member := range zset {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if zset[members[i]] != zset[members[j]] {
+			return zset[members[i]] < zset[members[j]]
+		}
+		return members[i] < members[j]
+	})
+	return members
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// SetMetadata sets metadata entries on a plan, overwriting existing values for the same keys
+func (r *PlanRepository) SetMetadata(ctx context.Context, id string, metadata map[string]string) (*models.Plan, error) {
+	// Get the plan first to verify it exists
+	plan, err := r.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := metadataFields(metadata)
+	if err != nil {
+		return nil, err
+	}
+	fields["updated_at"] = time.Now().Format(time.RFC3339)
+
+	_, err = r.client.client.HSet(ctx, GetPlanKey(plan.ID), fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set plan metadata: %w", err)
+	}
+
+	return r.Get(ctx, id)
+}
+
+// DeleteMetadata removes metadata entries from a plan
+func (r *PlanRepository) DeleteMetadata(ctx context.Context, id string, keys []string) (*models.Plan, error) {
+	// Get the plan first to verify it exists
+	plan, err := r.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	planKey := GetPlanKey(plan.ID)
+	_, err = r.client.client.HDel(ctx, planKey, metadataFieldNames(keys))
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete plan metadata: %w", err)
+	}
+
+	_, err = r.client.client.HSet(ctx, planKey, map[string]string{"updated_at": time.Now().Format(time.RFC3339)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update plan: %w", err)
+	}
+
+	return r.Get(ctx, id)
+}
+
+// SetMetadata sets metadata entries on a task, overwriting existing values for the same keys
+func (r *TaskRepository) SetMetadata(ctx context.Context, id string, metadata map[string]string) (*models.Task, error) {
+	// Get the task first to verify it exists
+	task, err := r.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := metadataFields(metadata)
+	if err != nil {
+		return nil, err
+	}
+	fields["updated_at"] = time.Now().Format(time.RFC3339)
+
+	_, err = r.client.client.HSet(ctx, GetTaskKey(task.ID), fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set task metadata: %w", err)
+	}
+
+	return r.Get(ctx, id)
+}
+
+// DeleteMetadata removes metadata entries from a task
+func (r *TaskRepository) DeleteMetadata(ctx context.Context, id string, keys []string) (*models.Task, error) {
+	// Get the task first to verify it exists
+	task, err := r.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	taskKey := GetTaskKey(task.ID)
+	_, err = r.client.client.HDel(ctx, taskKey, metadataFieldNames(keys))
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete task metadata: %w", err)
+	}
+
+	_, err = r.client.client.HSet(ctx, taskKey, map[string]string{"updated_at": time.Now().Format(time.RFC3339)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update task: %w", err)
+	}
+
+	return r.Get(ctx, id)
+}
+
+// metadataFields validates metadata entries and converts them to prefixed hash fields
+func metadataFields(metadata map[string]string) (map[string]string, error) {
+	if len(metadata) == 0 {
+		return nil, models.NewValidationError("", "", "at least one metadata entry is required")
+	}
+
+	fields := make(map[string]string, len(metadata)+1)
+	for key, value := range metadata {
+		if err := models.ValidateMetadataKey(key); err != nil {
+			return nil, err
+		}
+		fields[models.MetadataPrefix+key] = value
+	}
+
+	return fields, nil
+}
+
+// metadataFieldNames converts metadata keys to their prefixed hash field names
+func metadataFieldNames(keys []string) []string {
+	fields := make([]string, 0, len(keys))
+	for _, key := range keys {
+		fields = append(fields, models.MetadataPrefix+key)
+	}
+	return fields
+}
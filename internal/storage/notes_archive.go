@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+const (
+	// archivedNotesMarker starts notes whose older content was moved to the notes archive
+	archivedNotesMarker = "_Older notes were archived to keep these notes short._\n\n"
+	// notesSummaryTimeout bounds a call to the notes summarizer
+	notesSummaryTimeout = 2 * time.Minute
+)
+
+// NotesSummarizer condenses archived notes, e.g. by asking an LLM for a summary
+type NotesSummarizer interface {
+	// Summarize returns a new summary of the archived notes from the previous summary, which is empty for
+	// the first summary, and the notes archived since
+	Summarize(ctx context.Context, summary, archived string) (string, error)
+}
+
+// NotesCompactor keeps the notes of plans and tasks short. When notes grow past the maximum length, their
+// older paragraphs move to an archive key that is not read with the plan or task. With a summarizer, the
+// archive also keeps a summary of everything archived, which is updated in the background.
+type NotesCompactor struct {
+	client    *ValkeyClient
+	maxLength int
+
+	mu         sync.RWMutex
+	summarizer NotesSummarizer
+}
+
+// NewNotesCompactor creates a notes compactor for notes longer than maxLength bytes.
+// The summarizer may be nil.
+func NewNotesCompactor(client *ValkeyClient, maxLength int, summarizer NotesSummarizer) *NotesCompactor {
+	return &NotesCompactor{client: client, maxLength: maxLength, summarizer: summarizer}
+}
+
+// Compact returns notes that fit the maximum length. Older content of longer notes is added to the archive of
+// the plan or task first.
+func (c *NotesCompactor) Compact(ctx context.Context, entityType models.EntityType, id, notes string) (string, error) {
+	if c == nil || len(notes) <= c.maxLength {
+		return notes, nil
+	}
+
+	archived, kept := splitNotes(notes, c.maxLength)
+	if archived == "" {
+		return kept, nil
+	}
+	archiveKey := GetNotesArchiveKey(entityType, id)
+	existing, err := c.client.client.HGetAll(withPrimaryReads(ctx), archiveKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to get notes archive: %w", err)
+	}
+	if err := c.openArchive(existing); err != nil {
+		return "", err
+	}
+	content, err := c.client.seal(appendNotes(existing["content"], archived))
+	if err != nil {
+		return "", err
+	}
+	_, err = c.client.client.HSet(ctx, archiveKey, map[string]string{
+		"content":     content,
+		"archived_at": time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to archive notes: %w", err)
+	}
+
+	c.mu.RLock()
+	summarizer := c.summarizer
+	c.mu.RUnlock()
+	if summarizer != nil {
+		// Summaries can take long, so they do not hold up the write, which may hold the plan lock
+		go c.summarize(context.WithoutCancel(ctx), summarizer, entityType, id, existing["summary"], archived)
+	}
+	return kept, nil
+}
+
+// SetSummarizer replaces the summarizer of archived notes, which may be nil. Summaries already running finish
+// with the previous one.
+func (c *NotesCompactor) SetSummarizer(summarizer NotesSummarizer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.summarizer = summarizer
+}
+
+// Archived returns the archived notes of a plan or task
+func (c *NotesCompactor) Archived(
+	ctx context.Context,
+	entityType models.EntityType,
+	id string,
+) (*models.ArchivedNotes, error) {
+	data, err := c.client.client.HGetAll(ctx, GetNotesArchiveKey(entityType, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notes archive: %w", err)
+	}
+	if err := c.openArchive(data); err != nil {
+		return nil, err
+	}
+
+	archive := &models.ArchivedNotes{
+		EntityType: entityType,
+		EntityID:   id,
+		Content:    data["content"],
+		Summary:    data["summary"],
+	}
+	for field, target := range map[string]**time.Time{
+		"archived_at":   &archive.ArchivedAt,
+		"summarized_at": &archive.SummarizedAt,
+	} {
+		if data[field] == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, data[field])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", field, err)
+		}
+		*target = &parsed
+	}
+	return archive, nil
+}
+
+// openArchive decrypts the archived notes and summary of a notes archive in place
+func (c *NotesCompactor) openArchive(data map[string]string) error {
+	for _, field := range []string{"content", "summary"} {
+		opened, err := c.client.open(data[field])
+		if err != nil {
+			return fmt.Errorf("failed to read notes archive: %w", err)
+		}
+		data[field] = opened
+	}
+	return nil
+}
+
+// summarize updates the summary of an archive with newly archived notes. Failures are logged, the archived
+// notes are kept either way.
+func (c *NotesCompactor) summarize(
+	ctx context.Context,
+	summarizer NotesSummarizer,
+	entityType models.EntityType,
+	id, summary, archived string,
+) {
+	ctx, cancel := context.WithTimeout(ctx, notesSummaryTimeout)
+	defer cancel()
+
+	summary, err := summarizer.Summarize(ctx, summary, archived)
+	if err != nil {
+		log.Printf("Warning: failed to summarize archived notes of %s %s: %v", entityType, id, err)
+		return
+	}
+
+	summary, err = c.client.seal(summary)
+	if err == nil {
+		_, err = c.client.client.HSet(ctx, GetNotesArchiveKey(entityType, id), map[string]string{
+			"summary":       summary,
+			"summarized_at": time.Now().Format(time.RFC3339),
+		})
+	}
+	if err != nil {
+		log.Printf("Warning: failed to store summary of archived notes of %s %s: %v", entityType, id, err)
+	}
+}
+
+// splitNotes splits notes longer than maxLength into the older part to archive and the newer part to keep.
+// The kept part starts with a marker and fits maxLength. The cut is made at a paragraph break when there is
+// one in the first half of the kept part, otherwise at a character boundary.
+func splitNotes(notes string, maxLength int) (string, string) {
+	notes = strings.TrimPrefix(notes, archivedNotesMarker)
+	marker := archivedNotesMarker
+	budget := maxLength - len(marker)
+	if budget <= 0 {
+		marker, budget = "", maxLength
+	}
+	if len(notes) <= budget {
+		return "", marker + notes
+	}
+
+	cut := len(notes) - budget
+	if i := strings.Index(notes[cut:], notesSeparator); i >= 0 && i < budget/2 {
+		cut += i + len(notesSeparator)
+	}
+	for cut < len(notes) && !utf8.RuneStart(notes[cut]) {
+		cut++
+	}
+	return strings.TrimRight(notes[:cut], "\n"), marker + notes[cut:]
+}
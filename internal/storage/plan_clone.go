@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/google/uuid"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// PlanCloneOptions controls how a plan is cloned
+type PlanCloneOptions struct {
+	// ApplicationID is the application of the new plan; defaults to the source plan's application
+	ApplicationID string
+	// Name is the name of the new plan; defaults to the source plan's name with a "(copy)" suffix
+	Name string
+	// ResetStatus resets all cloned tasks to pending
+	ResetStatus bool
+	// ClearNotes drops the notes of the plan and its tasks
+	ClearNotes bool
+}
+
+// Clone deep-copies a plan and its tasks into a new plan.
+// Dependencies and links between tasks of the plan are remapped to the cloned tasks.
+func (r *PlanRepository) Clone(ctx context.Context, id string, opts PlanCloneOptions) (*models.Plan, error) {
+	ctx = withPrimaryReads(ctx)
+
+	source, err := r.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	taskRepo := &TaskRepository{client: r.client}
+	sourceTasks, err := taskRepo.ListByPlan(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plan tasks: %w", err)
+	}
+
+	applicationID := opts.ApplicationID
+	if applicationID == "" {
+		applicationID = source.ApplicationID
+	}
+	name := opts.Name
+	if name == "" {
+		name = source.Name + " (copy)"
+	}
+
+	plan, err := r.Create(ctx, applicationID, name, source.Description)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cloned plan: %w", err)
+	}
+
+	// Assign the new task IDs up front so dependencies and milestones can be remapped
+	taskIDs := make(map[string]string, len(sourceTasks))
+	for _, task := range sourceTasks {
+		taskIDs[task.ID] = newTaskID(plan.ID)
+	}
+
+	if !opts.ClearNotes {
+		plan.Notes = source.Notes
+	}
+	plan.Metadata = maps.Clone(source.Metadata)
+	plan.StartDate = source.StartDate
+	plan.TargetDate = source.TargetDate
+	for _, original := range source.Milestones {
+		milestone := models.NewMilestone(uuid.New().String(), original.Name, original.Date, nil)
+		for _, taskID := range original.TaskIDs {
+			if clonedID, ok := taskIDs[taskID]; ok {
+				milestone.TaskIDs = append(milestone.TaskIDs, clonedID)
+			}
+		}
+		plan.Milestones = append(plan.Milestones, milestone)
+	}
+	plan.Links = cloneLinks(source.Links, taskIDs)
+
+	// The clone is new, so it is stored whole, notes included
+	sealed, err := r.client.sealFields(plan.ToMap())
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.client.client.HSet(ctx, GetPlanKey(plan.ID), sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store cloned plan: %w", err)
+	}
+
+	planTasksKey := GetPlanTasksKey(plan.ID)
+	for i, original := range sourceTasks {
+		task := models.NewTask(taskIDs[original.ID], plan.ID, original.Title, original.Description, original.Priority)
+		task.Order = i
+		task.Status = original.Status
+		if opts.ResetStatus {
+			task.Status = models.TaskStatusPending
+		}
+		task.RecordStatusTransition(models.TaskStatusPending, task.CreatedAt)
+		if !opts.ClearNotes {
+			task.Notes = original.Notes
+		}
+		task.DueDate = original.DueDate
+		task.Recurrence = original.Recurrence
+		task.Estimate = original.Estimate
+		task.Metadata = maps.Clone(original.Metadata)
+		task.Links = cloneLinks(original.Links, taskIDs)
+		task.AcceptanceCriteria = copyAcceptanceCriteria(original.AcceptanceCriteria, opts.ResetStatus)
+
+		for _, dependencyID := range original.DependsOn {
+			if clonedID, ok := taskIDs[dependencyID]; ok {
+				dependencyID = clonedID
+			}
+			task.DependsOn = append(task.DependsOn, dependencyID)
+		}
+
+		sealed, err := r.client.sealFields(task.ToMap())
+		if err != nil {
+			return nil, err
+		}
+		_, err = r.client.client.HSet(ctx, GetTaskKey(task.ID), sealed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to store cloned task: %w", err)
+		}
+
+		_, err = r.client.client.ZAdd(ctx, planTasksKey, map[string]float64{task.ID: float64(i) * orderSpacing})
+		if err != nil {
+			return nil, fmt.Errorf("failed to add cloned task to plan: %w", err)
+		}
+
+		// The plan is counted from its creation, which keeps its status in step with the cloned tasks
+		_, err = r.client.adjustTaskCounts(ctx, plan.ID, "", task.Status)
+		if err != nil {
+			return nil, err
+		}
+
+		err = taskRepo.copyChecklist(ctx, original.Checklist, task.ID, opts.ResetStatus)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy cloned task checklist: %w", err)
+		}
+
+		if len(original.Tags) > 0 {
+			_, err = taskRepo.AddTags(ctx, task.ID, original.Tags)
+			if err != nil {
+				return nil, fmt.Errorf("failed to tag cloned task: %w", err)
+			}
+		}
+	}
+
+	// The cloned tasks were counted without going through the repositories, so the application is told now
+	if _, err := r.client.rollupPlan(ctx, plan.ID); err != nil {
+		return nil, err
+	}
+
+	return r.Get(ctx, plan.ID)
+}
+
+// cloneLinks copies links, pointing links to tasks of the source plan at their clones
+func cloneLinks(links []*models.Link, taskIDs map[string]string) []*models.Link {
+	var cloned []*models.Link
+	for _, original := range links {
+		link := *original
+		if clonedID, ok := taskIDs[link.Target]; ok && link.IsTaskLink() {
+			link.Target = clonedID
+		}
+		cloned = append(cloned, &link)
+	}
+	return cloned
+}
+
+// copyAcceptanceCriteria copies acceptance criteria to another task with fresh IDs, optionally resetting
+// their met flags
+func copyAcceptanceCriteria(criteria []*models.AcceptanceCriterion, resetMet bool) []*models.AcceptanceCriterion {
+	var copied []*models.AcceptanceCriterion
+	for _, original := range criteria {
+		criterion := models.NewAcceptanceCriterion(uuid.New().String(), original.Text)
+		criterion.Met = original.Met && !resetMet
+		copied = append(copied, criterion)
+	}
+	return copied
+}
@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/valkey-io/valkey-glide/go/v2/options"
+)
+
+const (
+	// DefaultPlanLockTTL bounds how long a server that dies while holding a plan lock blocks the plan. A live
+	// server renews the locks it holds every third of it, so long operations keep their locks.
+	DefaultPlanLockTTL = 10 * time.Second
+	// planLockWait is how long an operation waits for a plan lock held by another operation
+	planLockWait = 5 * time.Second
+	// planLockRetry is the base delay between attempts to take a held plan lock
+	planLockRetry = 20 * time.Millisecond
+)
+
+// releaseLockScript deletes a lock only if it is still held by the given owner.
+// KEYS[1] is the lock key and ARGV[1] the owner token.
+var releaseLockScript = options.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// heldPlanLocksKey is the context key of the plan locks held by the current operation
+type heldPlanLocksKey struct{}
+
+// heldPlanLock is a plan lock held by the current operation, renewed until it is released
+type heldPlanLock struct {
+	// lost is the error writes fail with once a renewal found the lock taken over or expired
+	lost atomic.Pointer[error]
+	stop chan struct{}
+	done chan struct{}
+}
+
+// SetPlanLockTTL sets how long a plan lock outlives a server that dies while holding it, zero for the default.
+// It must be called before the client is used.
+func (c *ValkeyClient) SetPlanLockTTL(ttl time.Duration) {
+	c.planLockTTL = ttl
+}
+
+// lockTTL returns how long a plan lock lasts without being renewed
+func (c *ValkeyClient) lockTTL() time.Duration {
+	if c.planLockTTL <= 0 {
+		return DefaultPlanLockTTL
+	}
+	return c.planLockTTL
+}
+
+// lockPlan takes the lock of a plan, so operations that rewrite the task order or the notes of a plan do not
+// interleave, even when they run on different server replicas. It returns the context to run the operation with and
+// the function releasing the lock. The lock is reentrant: operations called with the returned context do
+// not wait for it again. It is renewed while held; if a renewal finds it gone, the writes made with the returned
+// context fail with a conflict rather than interleave with the operation that took the lock over.
+func (c *ValkeyClient) lockPlan(ctx context.Context, planID string) (context.Context, func(), error) {
+	held, _ := ctx.Value(heldPlanLocksKey{}).(map[string]*heldPlanLock)
+	if held[planID] != nil {
+		return ctx, func() {}, nil
+	}
+
+	// The lock is taken right away even while the writes of the operation are staged
+	stage := writeStageOf(ctx)
+	ctx = unstaged(ctx)
+
+	lockKey := GetPlanLockKey(planID)
+	token := uuid.New().String()
+	setOpts := options.NewSetOptions().
+		SetOnlyIfDoesNotExist().
+		SetExpiry(options.NewExpiryIn(c.lockTTL()))
+
+	deadline := time.Now().Add(planLockWait)
+	for {
+		result, err := c.client.SetWithOptions(ctx, lockKey, token, *setOpts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to lock plan: %w", err)
+		}
+		if !result.IsNil() {
+			break
+		}
+		if time.Now().After(deadline) {
+			return nil, nil, models.NewConflictError(
+				models.EntityPlan, planID, "plan %s is busy with another change, try again", planID,
+			)
+		}
+
+		delay := planLockRetry + time.Duration(rand.Int64N(int64(planLockRetry)))
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+
+	// Renew and release even when the operation was cancelled or lost another lock, otherwise the plan stays
+	// locked until the TTL
+	lockCtx := context.WithValue(context.WithoutCancel(ctx), heldPlanLocksKey{}, nil)
+	lock := &heldPlanLock{stop: make(chan struct{}), done: make(chan struct{})}
+	go c.renewPlanLock(lockCtx, planID, lockKey, token, lock)
+
+	unlock := func() {
+		close(lock.stop)
+		<-lock.done
+		scriptOpts := options.NewScriptOptions().WithKeys([]string{lockKey}).WithArgs([]string{token})
+		//nolint:errcheck
+		c.client.InvokeScriptWithOptions(lockCtx, *releaseLockScript, *scriptOpts)
+	}
+
+	locks := make(map[string]*heldPlanLock, len(held)+1)
+	for id, heldLock := range held {
+		locks[id] = heldLock
+	}
+	locks[planID] = lock
+	ctx = context.WithValue(ctx, heldPlanLocksKey{}, locks)
+	if stage == nil {
+		return ctx, unlock, nil
+	}
+
+	// Staged writes are only written with the transaction, so the lock is held until then
+	stage.holdLock(lock, unlock)
+	return context.WithValue(ctx, writeStageKey{}, stage), func() {}, nil
+}
+
+// renewPlanLock extends a held plan lock every third of its TTL until it is released. A renewal that finds the
+// lock gone marks it lost; one that fails to reach Valkey is retried at the next tick, while the lock lasts.
+func (c *ValkeyClient) renewPlanLock(ctx context.Context, planID, lockKey, token string, lock *heldPlanLock) {
+	defer close(lock.done)
+
+	ttl := c.lockTTL()
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	scriptOpts := options.NewScriptOptions().
+		WithKeys([]string{lockKey}).
+		WithArgs([]string{token, strconv.FormatInt(ttl.Milliseconds(), 10)})
+	for {
+		select {
+		case <-lock.stop:
+			return
+		case <-ticker.C:
+		}
+
+		result, err := c.client.InvokeScriptWithOptions(ctx, *renewLeaseScript, *scriptOpts)
+		if err != nil {
+			log.Printf("Warning: failed to renew the lock of plan %s: %v", planID, err)
+			continue
+		}
+		if renewed, ok := result.(int64); !ok || renewed != 1 {
+			err := error(models.NewConflictError(
+				models.EntityPlan, planID, "lost the lock of plan %s to another change, try again", planID,
+			))
+			lock.lost.Store(&err)
+			return
+		}
+	}
+}
+
+// lostPlanLock returns the error of a plan lock held by the operation that was lost, or nil if it holds all
+// of them
+func lostPlanLock(ctx context.Context) error {
+	held, _ := ctx.Value(heldPlanLocksKey{}).(map[string]*heldPlanLock)
+	for _, lock := range held {
+		if err := lock.lost.Load(); err != nil {
+			return *err
+		}
+	}
+	return nil
+}
+
+// LockPlan takes the lock of a plan for a sequence of changes that must not interleave with other changes of
+// the plan. Repository calls made with the returned context do not wait for the lock again; the returned
+// function releases it.
+func (r *PlanRepository) LockPlan(ctx context.Context, id string) (context.Context, func(), error) {
+	return r.client.lockPlan(ctx, id)
+}
@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/valkey-io/valkey-glide/go/v2/constants"
+	glidemodels "github.com/valkey-io/valkey-glide/go/v2/models"
+	"github.com/valkey-io/valkey-glide/go/v2/options"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// DefaultNotesHistoryLength is the number of revisions of a plan's notes kept when none is configured
+const DefaultNotesHistoryLength = 20
+
+// notesSeparator separates appended notes from the notes before them
+const notesSeparator = "\n\n"
+
+// SetNotesHistoryLength sets the number of revisions of a plan's notes that are kept.
+// Zero keeps no history.
+func (r *PlanRepository) SetNotesHistoryLength(length int) {
+	r.notesHistory = int64(length)
+}
+
+// SetNotesCompactor makes the repository archive the older part of notes that grow too long.
+// Nil keeps notes whole.
+func (r *PlanRepository) SetNotesCompactor(compactor *NotesCompactor) {
+	r.compactor = compactor
+}
+
+// AppendNotes adds text to the end of the notes of a plan, separated by a blank line, and returns the new notes.
+// The plan is locked while the notes are read and written, so concurrent appends are all kept.
+func (r *PlanRepository) AppendNotes(ctx context.Context, id string, text string) (string, error) {
+	ctx, unlock, err := r.client.lockPlan(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	plan, err := r.Get(withPrimaryReads(ctx), id)
+	if err != nil {
+		return "", err
+	}
+
+	if err := r.saveNotes(ctx, plan, appendNotes(plan.Notes, text)); err != nil {
+		return "", err
+	}
+	return plan.Notes, nil
+}
+
+// appendNotes returns notes with text added to their end, separated by a blank line
+func appendNotes(notes, text string) string {
+	if notes == "" {
+		return text
+	}
+	return notes + notesSeparator + text
+}
+
+// NotesHistory returns the most recent revisions of a plan's notes, newest first.
+// A limit of zero or less returns all kept revisions.
+func (r *PlanRepository) NotesHistory(ctx context.Context, id string, limit int64) ([]*models.NotesRevision, error) {
+	if _, err := r.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	rangeOpts := options.NewXRangeOptions()
+	if limit > 0 {
+		rangeOpts.SetCount(limit)
+	}
+	return r.notesRevisions(
+		ctx,
+		id,
+		options.NewInfiniteStreamBoundary(constants.PositiveInfinity),
+		options.NewInfiniteStreamBoundary(constants.NegativeInfinity),
+		rangeOpts,
+	)
+}
+
+// RevertNotes sets the notes of a plan back to a revision and returns them. The reverted notes are kept as a
+// new revision, so a revert can be reverted as well.
+func (r *PlanRepository) RevertNotes(ctx context.Context, id, revisionID string) (string, error) {
+	ctx, unlock, err := r.client.lockPlan(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	plan, err := r.Get(withPrimaryReads(ctx), id)
+	if err != nil {
+		return "", err
+	}
+
+	boundary := options.NewStreamBoundary(revisionID, true)
+	revisions, err := r.notesRevisions(withPrimaryReads(ctx), id, boundary, boundary, options.NewXRangeOptions().SetCount(1))
+	if err != nil {
+		return "", err
+	}
+	if len(revisions) == 0 {
+		return "", models.NewNotFoundError(models.EntityNotesRevision, revisionID)
+	}
+
+	if err := r.saveNotes(ctx, plan, revisions[0].Notes); err != nil {
+		return "", err
+	}
+	return plan.Notes, nil
+}
+
+// saveNotes stores new notes of a plan, compacted if they are too long, and keeps them as a revision. Notes
+// written before the history was kept are saved as a revision first, so they can still be reverted to.
+func (r *PlanRepository) saveNotes(ctx context.Context, plan *models.Plan, notes string) error {
+	notes, err := r.compactor.Compact(ctx, models.EntityTypePlan, plan.ID, notes)
+	if err != nil {
+		return err
+	}
+
+	if r.notesHistory > 0 && plan.Notes != "" {
+		historyKey := GetPlanNotesHistoryKey(plan.ID)
+		existing, err := r.client.client.XRevRangeWithOptions(
+			withPrimaryReads(ctx),
+			historyKey,
+			options.NewInfiniteStreamBoundary(constants.PositiveInfinity),
+			options.NewInfiniteStreamBoundary(constants.NegativeInfinity),
+			*options.NewXRangeOptions().SetCount(1),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to read plan notes history: %w", err)
+		}
+		if len(existing) == 0 {
+			if err := r.recordNotesRevision(ctx, plan.ID, plan.Notes, plan.UpdatedAt); err != nil {
+				return err
+			}
+		}
+	}
+
+	plan.Notes = notes
+	plan.UpdatedAt = time.Now()
+	sealed, err := r.client.sealFields(plan.ToMap())
+	if err != nil {
+		return err
+	}
+	_, err = r.client.client.HSet(ctx, GetPlanKey(plan.ID), sealed)
+	if err != nil {
+		return fmt.Errorf("failed to update plan notes: %w", err)
+	}
+
+	if r.notesHistory > 0 {
+		return r.recordNotesRevision(ctx, plan.ID, notes, plan.UpdatedAt)
+	}
+	return nil
+}
+
+// recordNotesRevision adds a revision to the notes history of a plan and drops the oldest revisions beyond
+// the configured length
+func (r *PlanRepository) recordNotesRevision(ctx context.Context, planID, notes string, savedAt time.Time) error {
+	sealed, err := r.client.seal(notes)
+	if err != nil {
+		return err
+	}
+	fields := []glidemodels.FieldValue{
+		{Field: "notes", Value: sealed},
+		{Field: "actor", Value: ActorFromContext(ctx)},
+		{Field: "saved_at", Value: savedAt.Format(time.RFC3339Nano)},
+	}
+	addOpts := options.NewXAddOptions().SetTrimOptions(options.NewXTrimOptionsWithMaxLen(r.notesHistory))
+	_, err = r.client.client.XAddWithOptions(ctx, GetPlanNotesHistoryKey(planID), fields, *addOpts)
+	if err != nil {
+		return fmt.Errorf("failed to record plan notes revision: %w", err)
+	}
+	return nil
+}
+
+// notesRevisions reads the revisions of a plan's notes between two boundaries, newest first
+func (r *PlanRepository) notesRevisions(
+	ctx context.Context,
+	planID string,
+	start, end options.StreamBoundary,
+	rangeOpts *options.XRangeOptions,
+) ([]*models.NotesRevision, error) {
+	entries, err := r.client.client.XRevRangeWithOptions(ctx, GetPlanNotesHistoryKey(planID), start, end, *rangeOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan notes history: %w", err)
+	}
+
+	revisions := make([]*models.NotesRevision, 0, len(entries))
+	for _, entry := range entries {
+		revision := &models.NotesRevision{ID: entry.ID}
+		for _, field := range entry.Fields {
+			switch field.Field {
+			case "notes":
+				notes, err := r.client.open(field.Value)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read notes revision: %w", err)
+				}
+				revision.Notes = notes
+			case "actor":
+				revision.Actor = field.Value
+			case "saved_at":
+				savedAt, err := time.Parse(time.RFC3339Nano, field.Value)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse notes revision time: %w", err)
+				}
+				revision.SavedAt = savedAt
+			}
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, nil
+}
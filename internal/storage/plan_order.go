@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// The plans of an application are ranked by the order stored in their hashes. New plans are appended after
+// the last plan of their application, and reordering a plan renumbers the plans of the application from zero.
+// Plans stored before ordering existed have order zero and come first, oldest first.
+
+// sortPlans sorts plans by their order, then by their creation time
+func sortPlans(plans []*models.Plan) {
+	slices.SortStableFunc(plans, func(a, b *models.Plan) int {
+		if a.Order != b.Order {
+			return a.Order - b.Order
+		}
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+}
+
+// ListApplications returns the distinct applications the plans refer to with their number of plans,
+// ordered by application ID
+func (r *PlanRepository) ListApplications(ctx context.Context) ([]*models.ApplicationPlans, error) {
+	plans, err := r.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, plan := range plans {
+		counts[plan.ApplicationID]++
+	}
+
+	applications := make([]*models.ApplicationPlans, 0, len(counts))
+	for _, id := range slices.Sorted(maps.Keys(counts)) {
+		applications = append(applications, &models.ApplicationPlans{ApplicationID: id, PlanCount: counts[id]})
+	}
+	return applications, nil
+}
+
+// nextPlanOrder returns the order of a plan appended after the last plan of an application
+func (r *PlanRepository) nextPlanOrder(ctx context.Context, applicationID string) (int, error) {
+	plans, err := r.ListByApplication(withPrimaryReads(ctx), applicationID)
+	if err != nil {
+		return 0, err
+	}
+	if len(plans) == 0 {
+		return 0, nil
+	}
+	return plans[len(plans)-1].Order + 1, nil
+}
+
+// ReorderPlan moves a plan to a position among the plans of its application, clamped to their number,
+// and returns the moved plan
+func (r *PlanRepository) ReorderPlan(ctx context.Context, id string, newOrder int) (*models.Plan, error) {
+	ctx = withPrimaryReads(ctx)
+	plan, err := r.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	plans, err := r.ListByApplication(ctx, plan.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	plans = slices.DeleteFunc(plans, func(p *models.Plan) bool { return p.ID == id })
+	newOrder = min(max(newOrder, 0), len(plans))
+	plans = slices.Insert(plans, newOrder, plan)
+
+	// Only the plans whose position changed are rewritten
+	for i, p := range plans {
+		if p.Order == i && p.ID != id {
+			continue
+		}
+		fields := map[string]string{"order": strconv.Itoa(i)}
+		if p.ID == id {
+			plan.UpdatedAt = time.Now()
+			fields["updated_at"] = plan.UpdatedAt.Format(time.RFC3339)
+		}
+		if _, err := r.client.client.HSet(ctx, GetPlanKey(p.ID), fields); err != nil {
+			return nil, fmt.Errorf("failed to reorder plan %s: %w", p.ID, err)
+		}
+		p.Order = i
+	}
+
+	return plan, nil
+}
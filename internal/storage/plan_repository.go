@@ -3,35 +3,74 @@ package storage
 import (
 	"context"
 	"fmt"
+	"maps"
 	"time"
 
 	uuid "github.com/google/uuid"
 	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/valkey-io/valkey-glide/go/v2/options"
 )
 
 // PlanRepository handles storage operations for plans
 type PlanRepository struct {
 	client *ValkeyClient
+	// notesHistory is the number of notes revisions kept per plan
+	notesHistory int64
+	// activityLength is the number of agent activity entries kept per plan
+	activityLength int64
+	// compactor archives older notes, nil keeps notes whole
+	compactor *NotesCompactor
+	// knownApplications rejects new plans of applications that are not registered
+	knownApplications bool
 }
 
 // NewPlanRepository creates a new plan repository
 func NewPlanRepository(client *ValkeyClient) *PlanRepository {
 	return &PlanRepository{
-		client: client,
+		client:         client,
+		notesHistory:   DefaultNotesHistoryLength,
+		activityLength: DefaultAgentActivityLength,
 	}
 }
 
+// RequireKnownApplications makes the repository reject new plans, including clones, of applications that
+// are not registered. Plans stored before are left alone.
+func (r *PlanRepository) RequireKnownApplications(required bool) {
+	r.knownApplications = required
+}
+
 // Create adds a new plan to the storage
 func (r *PlanRepository) Create(ctx context.Context, applicationID, name, description string) (*models.Plan, error) {
+	if r.knownApplications {
+		known, err := r.client.applicationExists(withPrimaryReads(ctx), applicationID)
+		if err != nil {
+			return nil, err
+		}
+		if !known {
+			return nil, models.NewNotFoundError(models.EntityApplication, applicationID)
+		}
+	}
+
 	// Generate a unique ID for the plan
 	id := uuid.New().String()
+	var err error
 
-	// Create a new plan
+	// Create a new plan after the last plan of its application
 	plan := models.NewPlan(id, applicationID, name, description)
+	plan.Order, err = r.nextPlanOrder(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
 
-	// Store the plan in Valkey
+	// Store the plan in Valkey, with no tasks counted yet
+	plan.TaskCounts = &models.TaskCounts{}
+	fields, err := r.client.sealFields(plan.ToMap())
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(fields, plan.TaskCounts.Fields())
 	planKey := GetPlanKey(id)
-	_, err := r.client.client.HSet(ctx, planKey, plan.ToMap())
+	_, err = r.client.client.HSet(ctx, planKey, fields)
 	if err != nil {
 		return nil, fmt.Errorf("failed to store plan: %w", err)
 	}
@@ -54,6 +93,11 @@ func (r *PlanRepository) Create(ctx context.Context, applicationID, name, descri
 		return nil, fmt.Errorf("failed to add plan to application list: %w", err)
 	}
 
+	// Add the plan to the status of its application
+	if _, err := r.client.rollupPlan(ctx, id); err != nil {
+		return nil, err
+	}
+
 	return plan, nil
 }
 
@@ -66,9 +110,12 @@ func (r *PlanRepository) Get(ctx context.Context, id string) (*models.Plan, erro
 	}
 
 	if len(result) == 0 {
-		return nil, fmt.Errorf("plan not found: %s", id)
+		return nil, models.NewNotFoundError(models.EntityPlan, id)
 	}
 
+	if err := r.client.openFields(result); err != nil {
+		return nil, fmt.Errorf("failed to read plan data: %w", err)
+	}
 	plan := &models.Plan{}
 	err = plan.FromMap(result)
 	if err != nil {
@@ -78,40 +125,122 @@ func (r *PlanRepository) Get(ctx context.Context, id string) (*models.Plan, erro
 	return plan, nil
 }
 
-// Update updates an existing plan
+// Update updates an existing plan under its lock. Its status and notes are left alone: the status is derived
+// from the task counters or set with UpdateStatus, and the notes are changed with UpdateNotes and AppendNotes,
+// so a stale copy of the plan cannot revert them. The plan is given the stored status and notes.
 func (r *PlanRepository) Update(ctx context.Context, plan *models.Plan) error {
+	ctx, unlock, err := r.client.lockPlan(ctx, plan.ID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	current, err := r.Get(withPrimaryReads(ctx), plan.ID)
+	if err != nil {
+		return err
+	}
+
 	// Update the updated_at timestamp
 	plan.UpdatedAt = time.Now()
 
 	// Store the updated plan in Valkey
+	fields := plan.ToMap()
+	delete(fields, "status")
+	delete(fields, "notes")
 	planKey := GetPlanKey(plan.ID)
-	_, err := r.client.client.HSet(ctx, planKey, plan.ToMap())
+	sealed, err := r.client.sealFields(fields)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.client.HSet(ctx, planKey, sealed)
 	if err != nil {
 		return fmt.Errorf("failed to update plan: %w", err)
 	}
+	plan.Status = current.Status
+	plan.Notes = current.Notes
+
+	// Refresh the plan in the status of its application
+	if _, err := r.client.rollupPlan(ctx, plan.ID); err != nil {
+		return err
+	}
 
 	return nil
 }
 
-// Delete removes a plan and all its tasks
+// UpdateStatus sets the status of a plan, such as cancelling it. The next change of its tasks derives the
+// status from them again.
+func (r *PlanRepository) UpdateStatus(ctx context.Context, id string, status models.PlanStatus) (*models.Plan, error) {
+	ctx, unlock, err := r.client.lockPlan(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	plan, err := r.Get(withPrimaryReads(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+
+	plan.Status = status
+	plan.UpdatedAt = time.Now()
+	_, err = r.client.client.HSet(ctx, GetPlanKey(id), map[string]string{
+		"status":     string(plan.Status),
+		"updated_at": plan.UpdatedAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update plan status: %w", err)
+	}
+
+	// Refresh the plan in the status of its application
+	if _, err := r.client.rollupPlan(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// Delete removes a plan and all its tasks, with everything kept for them like TaskRepository.Delete
 func (r *PlanRepository) Delete(ctx context.Context, id string) error {
+	ctx, unlock, err := r.client.lockPlan(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	// Get the plan first to verify it exists
-	plan, err := r.Get(ctx, id)
+	plan, err := r.Get(withPrimaryReads(ctx), id)
 	if err != nil {
 		return err
 	}
 
 	// Get all tasks for this plan
 	planTasksKey := GetPlanTasksKey(id)
-	taskIDs, err := r.client.client.SMembers(ctx, planTasksKey)
+	taskIDs, err := r.client.client.ZRange(ctx, planTasksKey, options.NewRangeByIndexQuery(0, -1))
 	if err != nil {
 		return fmt.Errorf("failed to retrieve plan tasks: %w", err)
 	}
 
-	// Delete all tasks
-	for taskID := range taskIDs {
-		taskKey := GetTaskKey(taskID)
-		_, err := r.client.client.Del(ctx, []string{taskKey})
+	// Delete all tasks with their checklists, attachments, archived notes, leases and tags
+	taskRepo := &TaskRepository{client: r.client}
+	for _, taskID := range taskIDs {
+		tags, err := taskRepo.getTags(withPrimaryReads(ctx), taskID)
+		if err != nil {
+			return err
+		}
+		if err := taskRepo.untagTask(ctx, taskID, tags); err != nil {
+			return err
+		}
+		if err := taskRepo.releaseLease(ctx, taskID); err != nil {
+			return err
+		}
+
+		_, err = r.client.client.Del(ctx, []string{
+			GetTaskKey(taskID),
+			GetTaskChecklistKey(taskID),
+			GetTaskAttachmentsKey(taskID),
+			GetTaskAttachmentContentKey(taskID),
+			GetNotesArchiveKey(models.EntityTypeTask, taskID),
+		})
 		if err != nil {
 			return fmt.Errorf("failed to delete task %s: %w", taskID, err)
 		}
@@ -123,9 +252,13 @@ func (r *PlanRepository) Delete(ctx context.Context, id string) error {
 		return fmt.Errorf("failed to delete plan tasks set: %w", err)
 	}
 
-	// Delete the plan
+	// Delete the plan, the history of its notes, its archived notes, its watchers, its completion record and
+	// its activity journal
 	planKey := GetPlanKey(id)
-	_, err = r.client.client.Del(ctx, []string{planKey})
+	_, err = r.client.client.Del(ctx, []string{
+		planKey, GetPlanNotesHistoryKey(id), GetNotesArchiveKey(models.EntityTypePlan, id), GetPlanWatchersKey(id),
+		GetPlanCompletionsKey(id), GetPlanActivityKey(id),
+	})
 	if err != nil {
 		return fmt.Errorf("failed to delete plan: %w", err)
 	}
@@ -143,11 +276,14 @@ func (r *PlanRepository) Delete(ctx context.Context, id string) error {
 		return fmt.Errorf("failed to remove plan from application list: %w", err)
 	}
 
-	return nil
+	// Remove the plan from the status of its application
+	return r.client.dropPlanRollup(ctx, plan.ApplicationID, id)
 }
 
 // List returns all plans
 func (r *PlanRepository) List(ctx context.Context) ([]*models.Plan, error) {
+	ctx = withReplicaReads(ctx)
+
 	// Check for nil client
 	if r.client == nil {
 		return nil, fmt.Errorf("valkey client is nil")
@@ -176,6 +312,8 @@ func (r *PlanRepository) List(ctx context.Context) ([]*models.Plan, error) {
 
 // ListByStatus retrieves all plans with a specific status
 func (r *PlanRepository) ListByStatus(ctx context.Context, status models.PlanStatus) ([]*models.Plan, error) {
+	ctx = withReplicaReads(ctx)
+
 	// Get all plan IDs
 	planIDs, err := r.client.client.SMembers(ctx, plansListKey)
 	if err != nil {
@@ -210,8 +348,10 @@ func (r *PlanRepository) ListByStatus(ctx context.Context, status models.PlanSta
 	return plans, nil
 }
 
-// ListByApplication retrieves all plans for a specific application
+// ListByApplication retrieves all plans for a specific application in their order
 func (r *PlanRepository) ListByApplication(ctx context.Context, applicationID string) ([]*models.Plan, error) {
+	ctx = withReplicaReads(ctx)
+
 	// Get all plan IDs for this application
 	appPlansKey := fmt.Sprintf("app:%s:plans", applicationID)
 	planIDs, err := r.client.client.SMembers(ctx, appPlansKey)
@@ -240,6 +380,9 @@ func (r *PlanRepository) ListByApplication(ctx context.Context, applicationID st
 		}
 
 		// Parse the plan data
+		if err := r.client.openFields(result); err != nil {
+			return nil, fmt.Errorf("failed to read plan data for %s: %w", id, err)
+		}
 		plan := &models.Plan{}
 		err = plan.FromMap(result)
 		if err != nil {
@@ -249,30 +392,25 @@ func (r *PlanRepository) ListByApplication(ctx context.Context, applicationID st
 		plans = append(plans, plan)
 	}
 
+	sortPlans(plans)
 	return plans, nil
 }
 
-// UpdateNotes updates the notes for a plan
+// UpdateNotes updates the notes for a plan and keeps the new notes as a revision
 func (r *PlanRepository) UpdateNotes(ctx context.Context, id string, notes string) error {
-	// Get the plan first to verify it exists
-	plan, err := r.Get(ctx, id)
+	ctx, unlock, err := r.client.lockPlan(ctx, id)
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	// Update the notes
-	plan.Notes = notes
-	// Update the updated_at timestamp
-	plan.UpdatedAt = time.Now()
-
-	// Store the updated plan in Valkey
-	planKey := GetPlanKey(plan.ID)
-	_, err = r.client.client.HSet(ctx, planKey, plan.ToMap())
+	// Get the plan first to verify it exists
+	plan, err := r.Get(withPrimaryReads(ctx), id)
 	if err != nil {
-		return fmt.Errorf("failed to update plan notes: %w", err)
+		return err
 	}
 
-	return nil
+	return r.saveNotes(ctx, plan, notes)
 }
 
 // GetNotes retrieves the notes for a plan
@@ -285,3 +423,70 @@ func (r *PlanRepository) GetNotes(ctx context.Context, id string) (string, error
 
 	return plan.Notes, nil
 }
+
+// Restore replaces a plan with a previously captured snapshot, recreating it if it was deleted.
+// Tasks deleted together with the plan are not restored.
+func (r *PlanRepository) Restore(ctx context.Context, plan *models.Plan) error {
+	ctx, unlock, err := r.client.lockPlan(ctx, plan.ID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// A missing plan is recreated; any other failure to read it aborts the restore
+	current, err := r.Get(withPrimaryReads(ctx), plan.ID)
+	if models.IsNotFound(err) {
+		current = nil
+	} else if err != nil {
+		return err
+	}
+
+	// Replace the stored hash entirely so fields added since the snapshot disappear
+	fields := plan.ToMap()
+	if plan.Lock != nil {
+		maps.Copy(fields, plan.Lock.Fields())
+	}
+	sealed, err := r.client.sealFields(fields)
+	if err != nil {
+		return err
+	}
+	planKey := GetPlanKey(plan.ID)
+	_, err = r.client.client.Del(ctx, []string{planKey})
+	if err != nil {
+		return fmt.Errorf("failed to clear plan: %w", err)
+	}
+	_, err = r.client.client.HSet(ctx, planKey, sealed)
+	if err != nil {
+		return fmt.Errorf("failed to restore plan: %w", err)
+	}
+
+	_, err = r.client.client.SAdd(ctx, plansListKey, []string{plan.ID})
+	if err != nil {
+		return fmt.Errorf("failed to add plan to list: %w", err)
+	}
+
+	// Move the plan between application lists if the snapshot belongs to another application
+	if current != nil && current.ApplicationID != plan.ApplicationID {
+		_, err = r.client.client.SRem(ctx, fmt.Sprintf("app:%s:plans", current.ApplicationID), []string{plan.ID})
+		if err != nil {
+			return fmt.Errorf("failed to remove plan from application list: %w", err)
+		}
+		if err := r.client.dropPlanRollup(ctx, current.ApplicationID, plan.ID); err != nil {
+			return err
+		}
+	}
+	_, err = r.client.client.SAdd(ctx, fmt.Sprintf("app:%s:plans", plan.ApplicationID), []string{plan.ID})
+	if err != nil {
+		return fmt.Errorf("failed to add plan to application list: %w", err)
+	}
+
+	// The counters of the snapshot may be outdated, so the tasks the plan has now are counted again
+	taskRepo := &TaskRepository{client: r.client}
+	plan.TaskCounts, err = taskRepo.countTasks(ctx, plan.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.rollupPlan(ctx, plan.ID)
+	return err
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/valkey-io/valkey-glide/go/v2/options"
+)
+
+// The tasks of a plan are counted by status in its hash, so plans can be listed with their progress and their
+// status derived without loading their tasks. Every task mutation adjusts the counters of the plans it touches
+// and the plan status with them in one atomic step. Mutations read the task under the lock of its plan first,
+// so overlapping writes count every change once. Plans stored before counting existed, or restored from a
+// snapshot, have no counters until they are recounted from their tasks, which happens the first time their
+// status is updated.
+
+// adjustTaskCountsScript increments task counters of a plan hash and derives the plan status from them like
+// models.TaskCounts.PlanStatus, but only if the plan is already counted, so adjustments never create partial
+// counters. KEYS[1] is the plan key, ARGV[1] the time of the change and ARGV[2] the number n of counter fields
+// of active statuses that follow, which depend on the configured statuses. The remaining arguments are pairs
+// of counter fields and increments. It returns 1 if the plan is counted and 0 otherwise.
+var adjustTaskCountsScript = options.NewScript(adjustTaskCountsSource)
+
+// adjustTaskCountsSource is the source of adjustTaskCountsScript
+var adjustTaskCountsSource = fmt.Sprintf(`
+if redis.call("HEXISTS", KEYS[1], %[1]q) == 0 then
+	return 0
+end
+local n = tonumber(ARGV[2])
+for i = 3 + n, #ARGV, 2 do
+	redis.call("HINCRBY", KEYS[1], ARGV[i], ARGV[i + 1])
+end
+local counts = redis.call("HMGET", KEYS[1], %[1]q, %[2]q)
+local total, completed, active = tonumber(counts[1]), tonumber(counts[2]) or 0, 0
+for i = 3, 2 + n do
+	active = active + (tonumber(redis.call("HGET", KEYS[1], ARGV[i])) or 0)
+end
+local status = %[3]q
+if total > 0 and completed == total then
+	status = %[4]q
+elseif active > 0 then
+	status = %[5]q
+end
+if redis.call("HGET", KEYS[1], "status") ~= status then
+	redis.call("HSET", KEYS[1], "status", status, "updated_at", ARGV[1])
+end
+return 1
+`, models.TaskCountTotalField, models.TaskCountField(models.TaskStatusCompleted),
+	models.PlanStatusNew, models.PlanStatusCompleted, models.PlanStatusInProgress)
+
+// adjustTaskCounts moves a task from one status to another in the counters of a plan and updates the plan
+// status to match. An empty from status counts a new task and an empty to status a removed one. It reports
+// whether the plan is counted; the counters and status of plans that are not are left alone.
+func (c *ValkeyClient) adjustTaskCounts(ctx context.Context, planID string, from, to models.TaskStatus) (bool, error) {
+	counts := models.TaskCounts{}
+	if from != "" {
+		counts.Add(from, -1)
+	}
+	if to != "" {
+		counts.Add(to, 1)
+	}
+
+	active := models.ActiveTaskStatuses()
+	args := []string{time.Now().Format(time.RFC3339), strconv.Itoa(len(active))}
+	for _, status := range active {
+		args = append(args, models.TaskCountField(status))
+	}
+	for field, value := range counts.Fields() {
+		if value != "0" {
+			args = append(args, field, value)
+		}
+	}
+
+	scriptOpts := options.NewScriptOptions().WithKeys([]string{GetPlanKey(planID)}).WithArgs(args)
+	result, err := c.client.InvokeScriptWithOptions(ctx, *adjustTaskCountsScript, *scriptOpts)
+	if err != nil {
+		return false, fmt.Errorf("failed to update task counts of plan %s: %w", planID, err)
+	}
+	counted, ok := result.(int64)
+	return ok && counted == 1, nil
+}
+
+// recordTaskChange records that a task of a plan moved from one status to another, with the same meaning of
+// empty statuses as adjustTaskCounts. Counted plans are updated in one step, the others are counted from their
+// tasks to derive their status.
+func (r *TaskRepository) recordTaskChange(ctx context.Context, planID string, from, to models.TaskStatus) error {
+	if from == to {
+		return nil
+	}
+
+	counted, err := r.client.adjustTaskCounts(ctx, planID, from, to)
+	if err != nil {
+		return err
+	}
+	if !counted {
+		if err := r.UpdatePlanStatus(ctx, planID); err != nil {
+			return err
+		}
+	}
+
+	// Refresh the plan in the status of its application
+	_, err = r.client.rollupPlan(ctx, planID)
+	return err
+}
+
+// countTasks counts the tasks of a plan from scratch and stores the counters in the plan hash
+func (r *TaskRepository) countTasks(ctx context.Context, planID string) (*models.TaskCounts, error) {
+	ctx, unlock, err := r.client.lockPlan(withPrimaryReads(ctx), planID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	tasks, err := r.ListByPlan(ctx, planID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	counts := &models.TaskCounts{}
+	for _, task := range tasks {
+		counts.Add(task.Status, 1)
+	}
+
+	_, err = r.client.client.HSet(ctx, GetPlanKey(planID), counts.Fields())
+	if err != nil {
+		return nil, fmt.Errorf("failed to store task counts of plan %s: %w", planID, err)
+	}
+	return counts, nil
+}
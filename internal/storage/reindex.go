@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// appPlansKeyPattern matches the sets of plans of every application
+const appPlansKeyPattern = "app:*:plans"
+
+// ReindexReport is the result of rebuilding the indexes
+type ReindexReport struct {
+	PlansIndexed        int `json:"plans_indexed"`
+	ApplicationsIndexed int `json:"applications_indexed"`
+	TagsIndexed         int `json:"tags_indexed"`
+	// EntriesAdded and EntriesRemoved count the index entries that were missing or stale
+	EntriesAdded   int `json:"entries_added"`
+	EntriesRemoved int `json:"entries_removed"`
+}
+
+// Reindex rebuilds the keys derived from plans and tasks: the sets of plans of each application, the status
+// of each application and the sets of tasks carrying each tag. Entries are added and removed one by one
+// rather than replacing whole keys, so writes made while it runs are not lost. Like Check it scans the whole
+// keyspace; the list of plans and the task sets of plans are repaired by Check instead.
+func (c *IntegrityChecker) Reindex(ctx context.Context) (*ReindexReport, error) {
+	ctx = withPrimaryReads(ctx)
+	report := &ReindexReport{}
+
+	// Plans by application
+	planKeys, err := c.client.client.scanKeys(ctx, planKeyPrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	appPlans := make(map[string]map[string]struct{})
+	for _, key := range planKeys {
+		planID := idFromKey(planKeyPrefix, key)
+		applicationID, err := c.client.client.HGet(ctx, key, "application_id")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get plan %s: %w", planID, err)
+		}
+		if applicationID.IsNil() || applicationID.Value() == "" {
+			continue
+		}
+		if appPlans[applicationID.Value()] == nil {
+			appPlans[applicationID.Value()] = make(map[string]struct{})
+		}
+		appPlans[applicationID.Value()][planID] = struct{}{}
+		report.PlansIndexed++
+	}
+
+	appKeys, err := c.client.client.scanKeys(ctx, appPlansKeyPattern)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range appKeys {
+		applicationID := strings.TrimSuffix(strings.TrimPrefix(key, "app:"), ":plans")
+		if _, ok := appPlans[applicationID]; !ok {
+			appPlans[applicationID] = make(map[string]struct{})
+		}
+	}
+	for applicationID, planIDs := range appPlans {
+		if err := c.syncSet(ctx, fmt.Sprintf("app:%s:plans", applicationID), planIDs, report); err != nil {
+			return nil, err
+		}
+		if err := c.reindexApplicationStatus(ctx, applicationID, planIDs, report); err != nil {
+			return nil, err
+		}
+		if len(planIDs) > 0 {
+			report.ApplicationsIndexed++
+		}
+	}
+
+	// Tasks by tag, from the tags of tasks that still exist
+	tagKeys, err := c.client.client.scanKeys(ctx, taskTagsPrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	tagTasks := make(map[string]map[string]struct{})
+	for _, key := range tagKeys {
+		taskID := idFromKey(taskTagsPrefix, key)
+		exists, err := c.client.client.Exists(ctx, []string{GetTaskKey(taskID)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to check if task %s exists: %w", taskID, err)
+		}
+		if exists == 0 {
+			continue
+		}
+		tags, err := c.client.client.SMembers(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tags of task %s: %w", taskID, err)
+		}
+		for tag := range tags {
+			if tagTasks[tag] == nil {
+				tagTasks[tag] = make(map[string]struct{})
+			}
+			tagTasks[tag][taskID] = struct{}{}
+		}
+	}
+
+	indexKeys, err := c.client.client.scanKeys(ctx, tagTasksPrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range indexKeys {
+		tag := strings.TrimPrefix(key, tagTasksPrefix)
+		if _, ok := tagTasks[tag]; !ok {
+			tagTasks[tag] = make(map[string]struct{})
+		}
+	}
+	for tag, taskIDs := range tagTasks {
+		if err := c.syncSet(ctx, GetTagTasksKey(tag), taskIDs, report); err != nil {
+			return nil, err
+		}
+		if len(taskIDs) > 0 {
+			report.TagsIndexed++
+		}
+	}
+
+	return report, nil
+}
+
+// syncSet makes a set hold exactly the given members
+func (c *IntegrityChecker) syncSet(
+	ctx context.Context,
+	key string,
+	members map[string]struct{},
+	report *ReindexReport,
+) error {
+	current, err := c.client.client.SMembers(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to get index %s: %w", key, err)
+	}
+
+	var missing, stale []string
+	for member := range members {
+		if _, ok := current[member]; !ok {
+			missing = append(missing, member)
+		}
+	}
+	for member := range current {
+		if _, ok := members[member]; !ok {
+			stale = append(stale, member)
+		}
+	}
+
+	if len(missing) > 0 {
+		if _, err := c.client.client.SAdd(ctx, key, missing); err != nil {
+			return fmt.Errorf("failed to update index %s: %w", key, err)
+		}
+	}
+	if len(stale) > 0 {
+		if _, err := c.client.client.SRem(ctx, key, stale); err != nil {
+			return fmt.Errorf("failed to update index %s: %w", key, err)
+		}
+	}
+	report.EntriesAdded += len(missing)
+	report.EntriesRemoved += len(stale)
+	return nil
+}
+
+// reindexApplicationStatus refreshes the entry of every plan in the status of an application and drops the
+// entries of plans that left it
+func (c *IntegrityChecker) reindexApplicationStatus(
+	ctx context.Context,
+	applicationID string,
+	planIDs map[string]struct{},
+	report *ReindexReport,
+) error {
+	stored, err := c.client.client.HGetAll(ctx, GetApplicationStatusKey(applicationID))
+	if err != nil {
+		return fmt.Errorf("failed to retrieve application status: %w", err)
+	}
+
+	for planID := range stored {
+		if _, ok := planIDs[planID]; ok {
+			continue
+		}
+		if err := c.client.dropPlanRollup(ctx, applicationID, planID); err != nil {
+			return err
+		}
+		report.EntriesRemoved++
+	}
+	for planID := range planIDs {
+		if _, ok := stored[planID]; !ok {
+			report.EntriesAdded++
+		}
+		if _, err := c.client.rollupPlan(ctx, planID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
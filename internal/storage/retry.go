@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	glide "github.com/valkey-io/valkey-glide/go/v2"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// RetryPolicy controls how reads are retried after transient Valkey errors.
+// Writes are never retried, since a write that timed out may still have been applied.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts including the first; 1 disables retries
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry, doubling for each further retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between retries
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 50 * time.Millisecond,
+		MaxBackoff:     time.Second,
+	}
+}
+
+// IsTransient reports whether an error is a connection problem or timeout that may go away on retry.
+// Closed clients count as transient because the client is replaced after a Sentinel failover.
+func IsTransient(err error) bool {
+	var connectionErr *glide.ConnectionError
+	var timeoutErr *glide.TimeoutError
+	var disconnectErr *glide.DisconnectError
+	var closingErr *glide.ClosingError
+	return errors.As(err, &connectionErr) || errors.As(err, &timeoutErr) ||
+		errors.As(err, &disconnectErr) || errors.As(err, &closingErr)
+}
+
+// retry runs a read until it succeeds, fails with a permanent error, runs out of attempts or ctx is done
+func retry[T any](ctx context.Context, policy RetryPolicy, read func() (T, error)) (T, error) {
+	backoff := policy.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		result, err := read()
+		if err == nil || attempt >= policy.MaxAttempts || !IsTransient(err) {
+			return result, err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, policy.MaxBackoff)
+	}
+}
+
+// RetryingPlanRepository wraps a plan repository and retries its reads after transient errors
+type RetryingPlanRepository struct {
+	PlanRepositoryInterface
+	policy RetryPolicy
+}
+
+// NewRetryingPlanRepository creates a plan repository that retries reads with the given policy
+func NewRetryingPlanRepository(repo PlanRepositoryInterface, policy RetryPolicy) *RetryingPlanRepository {
+	return &RetryingPlanRepository{PlanRepositoryInterface: repo, policy: policy}
+}
+
+// Get retrieves a plan by ID
+func (r *RetryingPlanRepository) Get(ctx context.Context, id string) (*models.Plan, error) {
+	return retry(ctx, r.policy, func() (*models.Plan, error) {
+		return r.PlanRepositoryInterface.Get(ctx, id)
+	})
+}
+
+// List returns all plans
+func (r *RetryingPlanRepository) List(ctx context.Context) ([]*models.Plan, error) {
+	return retry(ctx, r.policy, func() ([]*models.Plan, error) {
+		return r.PlanRepositoryInterface.List(ctx)
+	})
+}
+
+// ListByApplication retrieves all plans for a specific application
+func (r *RetryingPlanRepository) ListByApplication(ctx context.Context, applicationID string) ([]*models.Plan, error) {
+	return retry(ctx, r.policy, func() ([]*models.Plan, error) {
+		return r.PlanRepositoryInterface.ListByApplication(ctx, applicationID)
+	})
+}
+
+// ListApplications returns the applications the plans refer to with their number of plans
+func (r *RetryingPlanRepository) ListApplications(ctx context.Context) ([]*models.ApplicationPlans, error) {
+	return retry(ctx, r.policy, func() ([]*models.ApplicationPlans, error) {
+		return r.PlanRepositoryInterface.ListApplications(ctx)
+	})
+}
+
+// GetApplicationStatus returns the status of an application rolled up from its plans
+func (r *RetryingPlanRepository) GetApplicationStatus(
+	ctx context.Context,
+	applicationID string,
+) (*models.ApplicationStatus, error) {
+	return retry(ctx, r.policy, func() (*models.ApplicationStatus, error) {
+		return r.PlanRepositoryInterface.GetApplicationStatus(ctx, applicationID)
+	})
+}
+
+// ListByStatus retrieves all plans with a specific status
+func (r *RetryingPlanRepository) ListByStatus(ctx context.Context, status models.PlanStatus) ([]*models.Plan, error) {
+	return retry(ctx, r.policy, func() ([]*models.Plan, error) {
+		return r.PlanRepositoryInterface.ListByStatus(ctx, status)
+	})
+}
+
+// GetNotes retrieves the notes of a plan
+func (r *RetryingPlanRepository) GetNotes(ctx context.Context, id string) (string, error) {
+	return retry(ctx, r.policy, func() (string, error) {
+		return r.PlanRepositoryInterface.GetNotes(ctx, id)
+	})
+}
+
+// NotesHistory returns the revisions of the notes of a plan
+func (r *RetryingPlanRepository) NotesHistory(ctx context.Context, id string, limit int64) ([]*models.NotesRevision, error) {
+	return retry(ctx, r.policy, func() ([]*models.NotesRevision, error) {
+		return r.PlanRepositoryInterface.NotesHistory(ctx, id, limit)
+	})
+}
+
+// ListActivity returns entries of the agent activity journal of a plan
+func (r *RetryingPlanRepository) ListActivity(
+	ctx context.Context,
+	planID string,
+	query AgentActivityQuery,
+) ([]*models.AgentActivity, error) {
+	return retry(ctx, r.policy, func() ([]*models.AgentActivity, error) {
+		return r.PlanRepositoryInterface.ListActivity(ctx, planID, query)
+	})
+}
+
+// RetryingTaskRepository wraps a task repository and retries its reads after transient errors
+type RetryingTaskRepository struct {
+	TaskRepositoryInterface
+	policy RetryPolicy
+}
+
+// NewRetryingTaskRepository creates a task repository that retries reads with the given policy
+func NewRetryingTaskRepository(repo TaskRepositoryInterface, policy RetryPolicy) *RetryingTaskRepository {
+	return &RetryingTaskRepository{TaskRepositoryInterface: repo, policy: policy}
+}
+
+// Get retrieves a task by ID
+func (r *RetryingTaskRepository) Get(ctx context.Context, id string) (*models.Task, error) {
+	return retry(ctx, r.policy, func() (*models.Task, error) {
+		return r.TaskRepositoryInterface.Get(ctx, id)
+	})
+}
+
+// ListByPlan returns all tasks for a plan, ordered by their sequence
+func (r *RetryingTaskRepository) ListByPlan(ctx context.Context, planID string) ([]*models.Task, error) {
+	return retry(ctx, r.policy, func() ([]*models.Task, error) {
+		return r.TaskRepositoryInterface.ListByPlan(ctx, planID)
+	})
+}
+
+// CountByPlan returns the number of tasks in a plan
+func (r *RetryingTaskRepository) CountByPlan(ctx context.Context, planID string) (int64, error) {
+	return retry(ctx, r.policy, func() (int64, error) {
+		return r.TaskRepositoryInterface.CountByPlan(ctx, planID)
+	})
+}
+
+// ListCompletions returns the completion record of a plan
+func (r *RetryingTaskRepository) ListCompletions(ctx context.Context, planID string) ([]*models.TaskCompletion, error) {
+	return retry(ctx, r.policy, func() ([]*models.TaskCompletion, error) {
+		return r.TaskRepositoryInterface.ListCompletions(ctx, planID)
+	})
+}
+
+// ListByStatus returns all tasks with the given status
+func (r *RetryingTaskRepository) ListByStatus(ctx context.Context, status models.TaskStatus) ([]*models.Task, error) {
+	return retry(ctx, r.policy, func() ([]*models.Task, error) {
+		return r.TaskRepositoryInterface.ListByStatus(ctx, status)
+	})
+}
+
+// ListByPlanAndStatus returns all tasks for a plan with the given status
+func (r *RetryingTaskRepository) ListByPlanAndStatus(
+	ctx context.Context,
+	planID string,
+	status models.TaskStatus,
+) ([]*models.Task, error) {
+	return retry(ctx, r.policy, func() ([]*models.Task, error) {
+		return r.TaskRepositoryInterface.ListByPlanAndStatus(ctx, planID, status)
+	})
+}
+
+// ListByApplication returns the tasks of all plans of an application
+func (r *RetryingTaskRepository) ListByApplication(ctx context.Context, applicationID string) ([]*models.Task, error) {
+	return retry(ctx, r.policy, func() ([]*models.Task, error) {
+		return r.TaskRepositoryInterface.ListByApplication(ctx, applicationID)
+	})
+}
+
+// ListByApplicationAndStatus returns the tasks of all plans of an application with the given status
+func (r *RetryingTaskRepository) ListByApplicationAndStatus(
+	ctx context.Context,
+	applicationID string,
+	status models.TaskStatus,
+) ([]*models.Task, error) {
+	return retry(ctx, r.policy, func() ([]*models.Task, error) {
+		return r.TaskRepositoryInterface.ListByApplicationAndStatus(ctx, applicationID, status)
+	})
+}
+
+// ListOrphanedTasks returns all tasks that reference a non-existent plan
+func (r *RetryingTaskRepository) ListOrphanedTasks(ctx context.Context) ([]*models.Task, error) {
+	return retry(ctx, r.policy, func() ([]*models.Task, error) {
+		return r.TaskRepositoryInterface.ListOrphanedTasks(ctx)
+	})
+}
+
+// ListByTag returns all tasks carrying the given tag
+func (r *RetryingTaskRepository) ListByTag(ctx context.Context, tag string) ([]*models.Task, error) {
+	return retry(ctx, r.policy, func() ([]*models.Task, error) {
+		return r.TaskRepositoryInterface.ListByTag(ctx, tag)
+	})
+}
+
+// Query returns the tasks matching a query
+func (r *RetryingTaskRepository) Query(ctx context.Context, query *models.TaskQuery) ([]*models.Task, error) {
+	return retry(ctx, r.policy, func() ([]*models.Task, error) {
+		return r.TaskRepositoryInterface.Query(ctx, query)
+	})
+}
+
+// GetNotes retrieves the notes of a task
+func (r *RetryingTaskRepository) GetNotes(ctx context.Context, id string) (string, error) {
+	return retry(ctx, r.policy, func() (string, error) {
+		return r.TaskRepositoryInterface.GetNotes(ctx, id)
+	})
+}
@@ -0,0 +1,234 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/utils/markdown"
+)
+
+// SanitizePolicies are the sanitization policies of the kinds of stored text
+type SanitizePolicies struct {
+	// Titles applies to plan names, task titles, checklist items and acceptance criteria
+	Titles markdown.Policy
+	// Descriptions applies to plan and task descriptions and completion notes
+	Descriptions markdown.Policy
+	// Notes applies to plan and task notes
+	Notes markdown.Policy
+}
+
+// DefaultSanitizePolicies returns the policies applied when none are configured: unicode normalization and
+// the removal of active HTML for every kind of text
+func DefaultSanitizePolicies() SanitizePolicies {
+	policy := markdown.Policy{Unicode: true, HTML: true}
+	return SanitizePolicies{Titles: policy, Descriptions: policy, Notes: policy}
+}
+
+// SanitizedPlanRepository decorates a plan repository and sanitizes the text of plans before it is stored
+type SanitizedPlanRepository struct {
+	PlanRepositoryInterface
+	policies SanitizePolicies
+}
+
+// NewSanitizedPlanRepository wraps a plan repository with the given policies
+func NewSanitizedPlanRepository(inner PlanRepositoryInterface, policies SanitizePolicies) *SanitizedPlanRepository {
+	return &SanitizedPlanRepository{
+		PlanRepositoryInterface: inner,
+		policies:                policies,
+	}
+}
+
+// Create creates a plan with a sanitized name and description
+func (r *SanitizedPlanRepository) Create(
+	ctx context.Context,
+	applicationID, name, description string,
+) (*models.Plan, error) {
+	return r.PlanRepositoryInterface.Create(ctx, applicationID,
+		r.policies.Titles.Apply(name), r.policies.Descriptions.Apply(description))
+}
+
+// Update sanitizes the name and description of a plan and updates it
+func (r *SanitizedPlanRepository) Update(ctx context.Context, plan *models.Plan) error {
+	plan.Name = r.policies.Titles.Apply(plan.Name)
+	plan.Description = r.policies.Descriptions.Apply(plan.Description)
+	return r.PlanRepositoryInterface.Update(ctx, plan)
+}
+
+// UpdateNotes updates the notes of a plan with sanitized notes
+func (r *SanitizedPlanRepository) UpdateNotes(ctx context.Context, id string, notes string) error {
+	return r.PlanRepositoryInterface.UpdateNotes(ctx, id, r.policies.Notes.Apply(notes))
+}
+
+// AppendNotes appends sanitized text to the notes of a plan
+func (r *SanitizedPlanRepository) AppendNotes(ctx context.Context, id string, text string) (string, error) {
+	return r.PlanRepositoryInterface.AppendNotes(ctx, id, r.policies.Notes.Apply(text))
+}
+
+// Restore sanitizes the text of a plan from a backup and restores it, as backups may come from anywhere
+func (r *SanitizedPlanRepository) Restore(ctx context.Context, plan *models.Plan) error {
+	plan.Name = r.policies.Titles.Apply(plan.Name)
+	plan.Description = r.policies.Descriptions.Apply(plan.Description)
+	plan.Notes = r.policies.Notes.Apply(plan.Notes)
+	if plan.Lock != nil {
+		plan.Lock.Reason = r.policies.Titles.Apply(plan.Lock.Reason)
+	}
+	return r.PlanRepositoryInterface.Restore(ctx, plan)
+}
+
+// SetLock locks a plan with a sanitized reason, or unlocks it
+func (r *SanitizedPlanRepository) SetLock(ctx context.Context, id string, lock *models.PlanLock) (*models.Plan, error) {
+	if lock != nil {
+		lock.Reason = r.policies.Titles.Apply(lock.Reason)
+	}
+	return r.PlanRepositoryInterface.SetLock(ctx, id, lock)
+}
+
+// LogActivity logs agent activity with a sanitized summary and sanitized text in its details
+func (r *SanitizedPlanRepository) LogActivity(
+	ctx context.Context,
+	planID string,
+	activity *models.AgentActivity,
+) (*models.AgentActivity, error) {
+	activity.Summary = r.policies.Descriptions.Apply(activity.Summary)
+	for key, value := range activity.Details {
+		activity.Details[key] = r.sanitizeDetail(value)
+	}
+	return r.PlanRepositoryInterface.LogActivity(ctx, planID, activity)
+}
+
+// sanitizeDetail applies the notes policy to the text in a value of activity details, however deeply nested
+func (r *SanitizedPlanRepository) sanitizeDetail(value any) any {
+	switch value := value.(type) {
+	case string:
+		return r.policies.Notes.Apply(value)
+	case []any:
+		for i, item := range value {
+			value[i] = r.sanitizeDetail(item)
+		}
+	case map[string]any:
+		for key, item := range value {
+			value[key] = r.sanitizeDetail(item)
+		}
+	}
+	return value
+}
+
+// SanitizedTaskRepository decorates a task repository and sanitizes the text of tasks before it is stored
+type SanitizedTaskRepository struct {
+	TaskRepositoryInterface
+	policies SanitizePolicies
+}
+
+// NewSanitizedTaskRepository wraps a task repository with the given policies
+func NewSanitizedTaskRepository(inner TaskRepositoryInterface, policies SanitizePolicies) *SanitizedTaskRepository {
+	return &SanitizedTaskRepository{
+		TaskRepositoryInterface: inner,
+		policies:                policies,
+	}
+}
+
+// Create creates a task with a sanitized title and description
+func (r *SanitizedTaskRepository) Create(
+	ctx context.Context,
+	planID, title, description string,
+	priority models.TaskPriority,
+) (*models.Task, error) {
+	return r.TaskRepositoryInterface.Create(ctx, planID,
+		r.policies.Titles.Apply(title), r.policies.Descriptions.Apply(description), priority)
+}
+
+// CreateBulk creates tasks with sanitized titles and descriptions
+func (r *SanitizedTaskRepository) CreateBulk(
+	ctx context.Context,
+	planID string,
+	tasks []TaskCreateInput,
+) ([]*models.Task, error) {
+	return r.TaskRepositoryInterface.CreateBulk(ctx, planID, r.sanitizeInputs(tasks))
+}
+
+// CreateBulkWithOptions creates tasks with sanitized titles and descriptions. Duplicates are detected on the
+// sanitized titles.
+func (r *SanitizedTaskRepository) CreateBulkWithOptions(
+	ctx context.Context,
+	planID string,
+	tasks []TaskCreateInput,
+	opts BulkCreateOptions,
+) (*BulkCreateReport, error) {
+	return r.TaskRepositoryInterface.CreateBulkWithOptions(ctx, planID, r.sanitizeInputs(tasks), opts)
+}
+
+// Update sanitizes the title, description, completion note and acceptance criteria of a task and updates it
+func (r *SanitizedTaskRepository) Update(ctx context.Context, task *models.Task) error {
+	task.Title = r.policies.Titles.Apply(task.Title)
+	task.Description = r.policies.Descriptions.Apply(task.Description)
+	task.CompletionNote = r.policies.Descriptions.Apply(task.CompletionNote)
+	for _, criterion := range task.AcceptanceCriteria {
+		criterion.Text = r.policies.Titles.Apply(criterion.Text)
+	}
+	return r.TaskRepositoryInterface.Update(ctx, task)
+}
+
+// UpdateNotes updates the notes of a task with sanitized notes
+func (r *SanitizedTaskRepository) UpdateNotes(ctx context.Context, id string, notes string) error {
+	return r.TaskRepositoryInterface.UpdateNotes(ctx, id, r.policies.Notes.Apply(notes))
+}
+
+// AddChecklistItem adds a checklist item with sanitized text
+func (r *SanitizedTaskRepository) AddChecklistItem(ctx context.Context, taskID, text string) (*models.Task, error) {
+	return r.TaskRepositoryInterface.AddChecklistItem(ctx, taskID, r.policies.Titles.Apply(text))
+}
+
+// Restore sanitizes the text of a task from a backup and restores it, as backups may come from anywhere
+func (r *SanitizedTaskRepository) Restore(ctx context.Context, task *models.Task) error {
+	task.Title = r.policies.Titles.Apply(task.Title)
+	task.Description = r.policies.Descriptions.Apply(task.Description)
+	task.CompletionNote = r.policies.Descriptions.Apply(task.CompletionNote)
+	task.Notes = r.policies.Notes.Apply(task.Notes)
+	for _, item := range task.Checklist {
+		item.Text = r.policies.Titles.Apply(item.Text)
+	}
+	for _, criterion := range task.AcceptanceCriteria {
+		criterion.Text = r.policies.Titles.Apply(criterion.Text)
+	}
+	return r.TaskRepositoryInterface.Restore(ctx, task)
+}
+
+// sanitizeInputs returns a copy of task definitions with sanitized titles and descriptions, leaving the
+// caller's definitions unchanged
+func (r *SanitizedTaskRepository) sanitizeInputs(tasks []TaskCreateInput) []TaskCreateInput {
+	sanitized := make([]TaskCreateInput, len(tasks))
+	for i, task := range tasks {
+		task.Title = r.policies.Titles.Apply(task.Title)
+		task.Description = r.policies.Descriptions.Apply(task.Description)
+		sanitized[i] = task
+	}
+	return sanitized
+}
+
+// SanitizedApplicationRepository decorates an application repository and sanitizes the name and description of
+// applications before they are stored
+type SanitizedApplicationRepository struct {
+	ApplicationRepositoryInterface
+	policies SanitizePolicies
+}
+
+// NewSanitizedApplicationRepository wraps an application repository with the given policies
+func NewSanitizedApplicationRepository(
+	inner ApplicationRepositoryInterface,
+	policies SanitizePolicies,
+) *SanitizedApplicationRepository {
+	return &SanitizedApplicationRepository{
+		ApplicationRepositoryInterface: inner,
+		policies:                       policies,
+	}
+}
+
+// Create registers an application with a sanitized name and description
+func (r *SanitizedApplicationRepository) Create(
+	ctx context.Context,
+	id, name, description string,
+	metadata map[string]string,
+) (*models.Application, error) {
+	return r.ApplicationRepositoryInterface.Create(ctx, id,
+		r.policies.Titles.Apply(name), r.policies.Descriptions.Apply(description), metadata)
+}
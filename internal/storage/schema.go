@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/valkey-io/valkey-glide/go/v2/options"
+)
+
+// SchemaVersion is the version of the key layout and encoding written by this build. It is raised when a
+// change needs existing data to be migrated, so older servers do not misread data written by newer ones.
+const SchemaVersion = 1
+
+// StoredSchemaVersion returns the schema version recorded in the database, or false if none is recorded.
+// Data written before versions were recorded has version 1.
+func (vc *ValkeyClient) StoredSchemaVersion(ctx context.Context) (int, bool, error) {
+	result, err := vc.client.Get(withPrimaryReads(ctx), schemaVersionKey)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get schema version: %w", err)
+	}
+	if result.IsNil() {
+		return 0, false, nil
+	}
+	version, err := strconv.Atoi(result.Value())
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid schema version %q", result.Value())
+	}
+	return version, true, nil
+}
+
+// EnsureSchemaVersion records the schema version of this build if the database has none yet, and returns an
+// error if the database was written by a newer schema version
+func (vc *ValkeyClient) EnsureSchemaVersion(ctx context.Context) error {
+	setOpts := options.NewSetOptions().SetOnlyIfDoesNotExist()
+	_, err := vc.client.SetWithOptions(ctx, schemaVersionKey, strconv.Itoa(SchemaVersion), *setOpts)
+	if err != nil {
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
+	version, _, err := vc.StoredSchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if version > SchemaVersion {
+		return fmt.Errorf("the database has schema version %d, but this server supports up to version %d",
+			version, SchemaVersion)
+	}
+	return nil
+}
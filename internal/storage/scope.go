@@ -0,0 +1,758 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// ErrOutOfScope is returned for writes into an application outside the scope of the request
+var ErrOutOfScope error = models.NewValidationError("", "", "outside the application scope")
+
+type applicationScopeKey struct{}
+
+// WithApplicationScope returns a context that restricts the scoped repositories to the plans of one
+// application. Plans and tasks of other applications look as if they did not exist.
+func WithApplicationScope(ctx context.Context, applicationID string) context.Context {
+	return context.WithValue(ctx, applicationScopeKey{}, applicationID)
+}
+
+// ApplicationScopeFromContext returns the application the context is restricted to, or an empty string if
+// it may access every application
+func ApplicationScopeFromContext(ctx context.Context) string {
+	scope, _ := ctx.Value(applicationScopeKey{}).(string)
+	return scope
+}
+
+// checkApplication returns an error if an application is outside the scope of the context
+func checkApplication(ctx context.Context, applicationID string) error {
+	if scope := ApplicationScopeFromContext(ctx); scope != "" && applicationID != scope {
+		return fmt.Errorf("%w: application %s is not accessible, only %s is", ErrOutOfScope, applicationID, scope)
+	}
+	return nil
+}
+
+// inScope reports whether a plan is within the scope of the context
+func inScope(ctx context.Context, plan *models.Plan) bool {
+	scope := ApplicationScopeFromContext(ctx)
+	return scope == "" || plan.ApplicationID == scope
+}
+
+// ScopedPlanRepository decorates a plan repository and hides the plans outside the application scope of
+// the context. Without a scope in the context, every call passes through.
+type ScopedPlanRepository struct {
+	PlanRepositoryInterface
+}
+
+// NewScopedPlanRepository wraps a plan repository with application scoping
+func NewScopedPlanRepository(inner PlanRepositoryInterface) *ScopedPlanRepository {
+	return &ScopedPlanRepository{PlanRepositoryInterface: inner}
+}
+
+// checkPlan returns a not found error if a plan is outside the scope of the context
+func (r *ScopedPlanRepository) checkPlan(ctx context.Context, id string) error {
+	if ApplicationScopeFromContext(ctx) == "" {
+		return nil
+	}
+	_, err := r.Get(ctx, id)
+	return err
+}
+
+// Create creates a plan if its application is within the scope
+func (r *ScopedPlanRepository) Create(
+	ctx context.Context,
+	applicationID, name, description string,
+) (*models.Plan, error) {
+	if err := checkApplication(ctx, applicationID); err != nil {
+		return nil, err
+	}
+	return r.PlanRepositoryInterface.Create(ctx, applicationID, name, description)
+}
+
+// Get retrieves a plan within the scope
+func (r *ScopedPlanRepository) Get(ctx context.Context, id string) (*models.Plan, error) {
+	plan, err := r.PlanRepositoryInterface.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !inScope(ctx, plan) {
+		return nil, models.NewNotFoundError(models.EntityPlan, id)
+	}
+	return plan, nil
+}
+
+// Update updates a plan within the scope, which cannot move to another application
+func (r *ScopedPlanRepository) Update(ctx context.Context, plan *models.Plan) error {
+	if err := r.checkPlan(ctx, plan.ID); err != nil {
+		return err
+	}
+	if err := checkApplication(ctx, plan.ApplicationID); err != nil {
+		return err
+	}
+	return r.PlanRepositoryInterface.Update(ctx, plan)
+}
+
+// UpdateStatus sets the status of a plan within the scope
+func (r *ScopedPlanRepository) UpdateStatus(
+	ctx context.Context,
+	id string,
+	status models.PlanStatus,
+) (*models.Plan, error) {
+	if err := r.checkPlan(ctx, id); err != nil {
+		return nil, err
+	}
+	return r.PlanRepositoryInterface.UpdateStatus(ctx, id, status)
+}
+
+// Delete deletes a plan within the scope
+func (r *ScopedPlanRepository) Delete(ctx context.Context, id string) error {
+	if err := r.checkPlan(ctx, id); err != nil {
+		return err
+	}
+	return r.PlanRepositoryInterface.Delete(ctx, id)
+}
+
+// List lists the plans within the scope
+func (r *ScopedPlanRepository) List(ctx context.Context) ([]*models.Plan, error) {
+	if scope := ApplicationScopeFromContext(ctx); scope != "" {
+		return r.PlanRepositoryInterface.ListByApplication(ctx, scope)
+	}
+	return r.PlanRepositoryInterface.List(ctx)
+}
+
+// ListByApplication lists the plans of an application, which has none outside the scope
+func (r *ScopedPlanRepository) ListByApplication(ctx context.Context, applicationID string) ([]*models.Plan, error) {
+	if checkApplication(ctx, applicationID) != nil {
+		return []*models.Plan{}, nil
+	}
+	return r.PlanRepositoryInterface.ListByApplication(ctx, applicationID)
+}
+
+// ListApplications lists the application within the scope, if it has plans
+func (r *ScopedPlanRepository) ListApplications(ctx context.Context) ([]*models.ApplicationPlans, error) {
+	scope := ApplicationScopeFromContext(ctx)
+	if scope == "" {
+		return r.PlanRepositoryInterface.ListApplications(ctx)
+	}
+
+	plans, err := r.PlanRepositoryInterface.ListByApplication(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	if len(plans) == 0 {
+		return []*models.ApplicationPlans{}, nil
+	}
+	return []*models.ApplicationPlans{{ApplicationID: scope, PlanCount: len(plans)}}, nil
+}
+
+// GetApplicationStatus returns the status of an application within the scope
+func (r *ScopedPlanRepository) GetApplicationStatus(
+	ctx context.Context,
+	applicationID string,
+) (*models.ApplicationStatus, error) {
+	if err := checkApplication(ctx, applicationID); err != nil {
+		return nil, err
+	}
+	return r.PlanRepositoryInterface.GetApplicationStatus(ctx, applicationID)
+}
+
+// ListByStatus lists the plans with a status within the scope
+func (r *ScopedPlanRepository) ListByStatus(ctx context.Context, status models.PlanStatus) ([]*models.Plan, error) {
+	plans, err := r.PlanRepositoryInterface.ListByStatus(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	scoped := make([]*models.Plan, 0, len(plans))
+	for _, plan := range plans {
+		if inScope(ctx, plan) {
+			scoped = append(scoped, plan)
+		}
+	}
+	return scoped, nil
+}
+
+// Clone copies a plan within the scope into an application within the scope
+func (r *ScopedPlanRepository) Clone(ctx context.Context, id string, opts PlanCloneOptions) (*models.Plan, error) {
+	if err := r.checkPlan(ctx, id); err != nil {
+		return nil, err
+	}
+	if opts.ApplicationID != "" {
+		if err := checkApplication(ctx, opts.ApplicationID); err != nil {
+			return nil, err
+		}
+	}
+	return r.PlanRepositoryInterface.Clone(ctx, id, opts)
+}
+
+// Restore restores a plan of an application within the scope
+func (r *ScopedPlanRepository) Restore(ctx context.Context, plan *models.Plan) error {
+	if err := checkApplication(ctx, plan.ApplicationID); err != nil {
+		return err
+	}
+	return r.PlanRepositoryInterface.Restore(ctx, plan)
+}
+
+// ReorderPlan moves a plan within the scope among the plans of its application
+func (r *ScopedPlanRepository) ReorderPlan(ctx context.Context, id string, newOrder int) (*models.Plan, error) {
+	if err := r.checkPlan(ctx, id); err != nil {
+		return nil, err
+	}
+	return r.PlanRepositoryInterface.ReorderPlan(ctx, id, newOrder)
+}
+
+// UpdateNotes updates the notes of a plan within the scope
+func (r *ScopedPlanRepository) UpdateNotes(ctx context.Context, id string, notes string) error {
+	if err := r.checkPlan(ctx, id); err != nil {
+		return err
+	}
+	return r.PlanRepositoryInterface.UpdateNotes(ctx, id, notes)
+}
+
+// GetNotes retrieves the notes of a plan within the scope
+func (r *ScopedPlanRepository) GetNotes(ctx context.Context, id string) (string, error) {
+	if err := r.checkPlan(ctx, id); err != nil {
+		return "", err
+	}
+	return r.PlanRepositoryInterface.GetNotes(ctx, id)
+}
+
+// AppendNotes appends to the notes of a plan within the scope
+func (r *ScopedPlanRepository) AppendNotes(ctx context.Context, id string, text string) (string, error) {
+	if err := r.checkPlan(ctx, id); err != nil {
+		return "", err
+	}
+	return r.PlanRepositoryInterface.AppendNotes(ctx, id, text)
+}
+
+// NotesHistory returns the notes revisions of a plan within the scope
+func (r *ScopedPlanRepository) NotesHistory(
+	ctx context.Context,
+	id string,
+	limit int64,
+) ([]*models.NotesRevision, error) {
+	if err := r.checkPlan(ctx, id); err != nil {
+		return nil, err
+	}
+	return r.PlanRepositoryInterface.NotesHistory(ctx, id, limit)
+}
+
+// LogActivity logs agent activity on a plan within the scope
+func (r *ScopedPlanRepository) LogActivity(
+	ctx context.Context,
+	planID string,
+	activity *models.AgentActivity,
+) (*models.AgentActivity, error) {
+	if err := r.checkPlan(ctx, planID); err != nil {
+		return nil, err
+	}
+	return r.PlanRepositoryInterface.LogActivity(ctx, planID, activity)
+}
+
+// ListActivity returns the agent activity of a plan within the scope
+func (r *ScopedPlanRepository) ListActivity(
+	ctx context.Context,
+	planID string,
+	query AgentActivityQuery,
+) ([]*models.AgentActivity, error) {
+	if err := r.checkPlan(ctx, planID); err != nil {
+		return nil, err
+	}
+	return r.PlanRepositoryInterface.ListActivity(ctx, planID, query)
+}
+
+// RevertNotes reverts the notes of a plan within the scope
+func (r *ScopedPlanRepository) RevertNotes(ctx context.Context, id, revisionID string) (string, error) {
+	if err := r.checkPlan(ctx, id); err != nil {
+		return "", err
+	}
+	return r.PlanRepositoryInterface.RevertNotes(ctx, id, revisionID)
+}
+
+// SetMetadata sets metadata of a plan within the scope
+func (r *ScopedPlanRepository) SetMetadata(
+	ctx context.Context,
+	id string,
+	metadata map[string]string,
+) (*models.Plan, error) {
+	if err := r.checkPlan(ctx, id); err != nil {
+		return nil, err
+	}
+	return r.PlanRepositoryInterface.SetMetadata(ctx, id, metadata)
+}
+
+// DeleteMetadata deletes metadata of a plan within the scope
+func (r *ScopedPlanRepository) DeleteMetadata(ctx context.Context, id string, keys []string) (*models.Plan, error) {
+	if err := r.checkPlan(ctx, id); err != nil {
+		return nil, err
+	}
+	return r.PlanRepositoryInterface.DeleteMetadata(ctx, id, keys)
+}
+
+// LockPlan takes the lock of a plan within the scope
+func (r *ScopedPlanRepository) LockPlan(ctx context.Context, id string) (context.Context, func(), error) {
+	if err := r.checkPlan(ctx, id); err != nil {
+		return nil, nil, err
+	}
+	return r.PlanRepositoryInterface.LockPlan(ctx, id)
+}
+
+// WriteAtomically runs changes to a plan within the scope in one transaction
+func (r *ScopedPlanRepository) WriteAtomically(
+	ctx context.Context,
+	id string,
+	write func(ctx context.Context) error,
+) error {
+	if err := r.checkPlan(ctx, id); err != nil {
+		return err
+	}
+	return r.PlanRepositoryInterface.WriteAtomically(ctx, id, write)
+}
+
+// SetLock locks or unlocks a plan within the scope
+func (r *ScopedPlanRepository) SetLock(ctx context.Context, id string, lock *models.PlanLock) (*models.Plan, error) {
+	if err := r.checkPlan(ctx, id); err != nil {
+		return nil, err
+	}
+	return r.PlanRepositoryInterface.SetLock(ctx, id, lock)
+}
+
+// ScopedTaskRepository decorates a task repository and hides the tasks of plans outside the application
+// scope of the context. Without a scope in the context, every call passes through.
+type ScopedTaskRepository struct {
+	TaskRepositoryInterface
+	plans PlanRepositoryInterface
+}
+
+// NewScopedTaskRepository wraps a task repository with application scoping. The plan repository is used
+// to look up the application of a task's plan.
+func NewScopedTaskRepository(inner TaskRepositoryInterface, plans PlanRepositoryInterface) *ScopedTaskRepository {
+	return &ScopedTaskRepository{TaskRepositoryInterface: inner, plans: plans}
+}
+
+// checkPlan returns a not found error if a plan is outside the scope of the context
+func (r *ScopedTaskRepository) checkPlan(ctx context.Context, planID string) error {
+	if ApplicationScopeFromContext(ctx) == "" {
+		return nil
+	}
+	plan, err := r.plans.Get(ctx, planID)
+	if err != nil {
+		return err
+	}
+	if !inScope(ctx, plan) {
+		return models.NewNotFoundError(models.EntityPlan, planID)
+	}
+	return nil
+}
+
+// checkTask returns a not found error if a task belongs to a plan outside the scope of the context
+func (r *ScopedTaskRepository) checkTask(ctx context.Context, id string) error {
+	if ApplicationScopeFromContext(ctx) == "" {
+		return nil
+	}
+	_, err := r.Get(ctx, id)
+	return err
+}
+
+// filter returns the tasks of plans within the scope of the context
+func (r *ScopedTaskRepository) filter(ctx context.Context, tasks []*models.Task) ([]*models.Task, error) {
+	if ApplicationScopeFromContext(ctx) == "" {
+		return tasks, nil
+	}
+
+	plans := make(map[string]bool)
+	scoped := make([]*models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		visible, ok := plans[task.PlanID]
+		if !ok {
+			plan, err := r.plans.Get(ctx, task.PlanID)
+			if err != nil && !models.IsNotFound(err) {
+				return nil, err
+			}
+			visible = err == nil && inScope(ctx, plan)
+			plans[task.PlanID] = visible
+		}
+		if visible {
+			scoped = append(scoped, task)
+		}
+	}
+	return scoped, nil
+}
+
+// Create creates a task in a plan within the scope
+func (r *ScopedTaskRepository) Create(
+	ctx context.Context,
+	planID, title, description string,
+	priority models.TaskPriority,
+) (*models.Task, error) {
+	if err := r.checkPlan(ctx, planID); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.Create(ctx, planID, title, description, priority)
+}
+
+// CreateBulk creates tasks in a plan within the scope
+func (r *ScopedTaskRepository) CreateBulk(
+	ctx context.Context,
+	planID string,
+	tasks []TaskCreateInput,
+) ([]*models.Task, error) {
+	if err := r.checkPlan(ctx, planID); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.CreateBulk(ctx, planID, tasks)
+}
+
+// CreateBulkWithOptions creates tasks in a plan within the scope
+func (r *ScopedTaskRepository) CreateBulkWithOptions(
+	ctx context.Context,
+	planID string,
+	tasks []TaskCreateInput,
+	opts BulkCreateOptions,
+) (*BulkCreateReport, error) {
+	if err := r.checkPlan(ctx, planID); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.CreateBulkWithOptions(ctx, planID, tasks, opts)
+}
+
+// Get retrieves a task of a plan within the scope
+func (r *ScopedTaskRepository) Get(ctx context.Context, id string) (*models.Task, error) {
+	task, err := r.TaskRepositoryInterface.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.checkPlan(ctx, task.PlanID); err != nil {
+		if models.IsNotFound(err) {
+			return nil, models.NewNotFoundError(models.EntityTask, id)
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+// Update updates a task within the scope, which can only move to plans within the scope
+func (r *ScopedTaskRepository) Update(ctx context.Context, task *models.Task) error {
+	if err := r.checkTask(ctx, task.ID); err != nil {
+		return err
+	}
+	if err := r.checkPlan(ctx, task.PlanID); err != nil {
+		return err
+	}
+	return r.TaskRepositoryInterface.Update(ctx, task)
+}
+
+// Delete deletes a task within the scope
+func (r *ScopedTaskRepository) Delete(ctx context.Context, id string) error {
+	if err := r.checkTask(ctx, id); err != nil {
+		return err
+	}
+	return r.TaskRepositoryInterface.Delete(ctx, id)
+}
+
+// ListByPlan lists the tasks of a plan within the scope
+func (r *ScopedTaskRepository) ListByPlan(ctx context.Context, planID string) ([]*models.Task, error) {
+	if err := r.checkPlan(ctx, planID); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.ListByPlan(ctx, planID)
+}
+
+// StreamByPlan streams the tasks of a plan within the scope
+func (r *ScopedTaskRepository) StreamByPlan(
+	ctx context.Context,
+	planID string,
+	chunkSize int,
+	emit func(offset int, tasks []*models.Task) error,
+) error {
+	if err := r.checkPlan(ctx, planID); err != nil {
+		return err
+	}
+	return r.TaskRepositoryInterface.StreamByPlan(ctx, planID, chunkSize, emit)
+}
+
+// CountByPlan counts the tasks of a plan within the scope
+func (r *ScopedTaskRepository) CountByPlan(ctx context.Context, planID string) (int64, error) {
+	if err := r.checkPlan(ctx, planID); err != nil {
+		return 0, err
+	}
+	return r.TaskRepositoryInterface.CountByPlan(ctx, planID)
+}
+
+// ListCompletions returns the completion record of a plan within the scope
+func (r *ScopedTaskRepository) ListCompletions(ctx context.Context, planID string) ([]*models.TaskCompletion, error) {
+	if err := r.checkPlan(ctx, planID); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.ListCompletions(ctx, planID)
+}
+
+// ListByStatus lists the tasks with a status in plans within the scope
+func (r *ScopedTaskRepository) ListByStatus(ctx context.Context, status models.TaskStatus) ([]*models.Task, error) {
+	tasks, err := r.TaskRepositoryInterface.ListByStatus(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	return r.filter(ctx, tasks)
+}
+
+// ListByPlanAndStatus lists the tasks with a status of a plan within the scope
+func (r *ScopedTaskRepository) ListByPlanAndStatus(
+	ctx context.Context,
+	planID string,
+	status models.TaskStatus,
+) ([]*models.Task, error) {
+	if err := r.checkPlan(ctx, planID); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.ListByPlanAndStatus(ctx, planID, status)
+}
+
+// ListByApplication lists the tasks of an application, which has none outside the scope
+func (r *ScopedTaskRepository) ListByApplication(ctx context.Context, applicationID string) ([]*models.Task, error) {
+	if checkApplication(ctx, applicationID) != nil {
+		return []*models.Task{}, nil
+	}
+	return r.TaskRepositoryInterface.ListByApplication(ctx, applicationID)
+}
+
+// ListByApplicationAndStatus lists the tasks with a status of an application, which has none outside the scope
+func (r *ScopedTaskRepository) ListByApplicationAndStatus(
+	ctx context.Context,
+	applicationID string,
+	status models.TaskStatus,
+) ([]*models.Task, error) {
+	if checkApplication(ctx, applicationID) != nil {
+		return []*models.Task{}, nil
+	}
+	return r.TaskRepositoryInterface.ListByApplicationAndStatus(ctx, applicationID, status)
+}
+
+// ReorderTask moves a task within the scope in its plan
+func (r *ScopedTaskRepository) ReorderTask(ctx context.Context, taskID string, newOrder int) error {
+	if err := r.checkTask(ctx, taskID); err != nil {
+		return err
+	}
+	return r.TaskRepositoryInterface.ReorderTask(ctx, taskID, newOrder)
+}
+
+// ReorderTasks orders the tasks of a plan within the scope
+func (r *ScopedTaskRepository) ReorderTasks(ctx context.Context, planID string, taskIDs []string) ([]*models.Task, error) {
+	if err := r.checkPlan(ctx, planID); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.ReorderTasks(ctx, planID, taskIDs)
+}
+
+// MoveTask moves a task within the scope to a plan within the scope
+func (r *ScopedTaskRepository) MoveTask(
+	ctx context.Context,
+	taskID, planID string,
+	position int,
+) (*models.Task, error) {
+	if err := r.checkTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	if err := r.checkPlan(ctx, planID); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.MoveTask(ctx, taskID, planID, position)
+}
+
+// ListOrphanedTasks lists tasks without a plan, which are outside every scope
+func (r *ScopedTaskRepository) ListOrphanedTasks(ctx context.Context) ([]*models.Task, error) {
+	if ApplicationScopeFromContext(ctx) != "" {
+		return []*models.Task{}, nil
+	}
+	return r.TaskRepositoryInterface.ListOrphanedTasks(ctx)
+}
+
+// Restore restores a task into a plan within the scope
+func (r *ScopedTaskRepository) Restore(ctx context.Context, task *models.Task) error {
+	if err := r.checkPlan(ctx, task.PlanID); err != nil {
+		return err
+	}
+	return r.TaskRepositoryInterface.Restore(ctx, task)
+}
+
+// UpdateNotes updates the notes of a task within the scope
+func (r *ScopedTaskRepository) UpdateNotes(ctx context.Context, id string, notes string) error {
+	if err := r.checkTask(ctx, id); err != nil {
+		return err
+	}
+	return r.TaskRepositoryInterface.UpdateNotes(ctx, id, notes)
+}
+
+// GetNotes retrieves the notes of a task within the scope
+func (r *ScopedTaskRepository) GetNotes(ctx context.Context, id string) (string, error) {
+	if err := r.checkTask(ctx, id); err != nil {
+		return "", err
+	}
+	return r.TaskRepositoryInterface.GetNotes(ctx, id)
+}
+
+// ClaimTask claims a task within the scope
+func (r *ScopedTaskRepository) ClaimTask(
+	ctx context.Context,
+	taskID, workerID string,
+	ttl time.Duration,
+) (*models.Task, error) {
+	if err := r.checkTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.ClaimTask(ctx, taskID, workerID, ttl)
+}
+
+// RenewLease renews the lease of a task within the scope
+func (r *ScopedTaskRepository) RenewLease(
+	ctx context.Context,
+	taskID, workerID string,
+	ttl time.Duration,
+) (*models.Task, error) {
+	if err := r.checkTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.RenewLease(ctx, taskID, workerID, ttl)
+}
+
+// AddTags adds tags to a task within the scope
+func (r *ScopedTaskRepository) AddTags(ctx context.Context, taskID string, tags []string) (*models.Task, error) {
+	if err := r.checkTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.AddTags(ctx, taskID, tags)
+}
+
+// RemoveTags removes tags from a task within the scope
+func (r *ScopedTaskRepository) RemoveTags(ctx context.Context, taskID string, tags []string) (*models.Task, error) {
+	if err := r.checkTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.RemoveTags(ctx, taskID, tags)
+}
+
+// ListByTag lists the tasks with a tag in plans within the scope
+func (r *ScopedTaskRepository) ListByTag(ctx context.Context, tag string) ([]*models.Task, error) {
+	tasks, err := r.TaskRepositoryInterface.ListByTag(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+	return r.filter(ctx, tasks)
+}
+
+// Query returns the tasks matching a query in plans within the scope
+func (r *ScopedTaskRepository) Query(ctx context.Context, query *models.TaskQuery) ([]*models.Task, error) {
+	tasks, err := r.TaskRepositoryInterface.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return r.filter(ctx, tasks)
+}
+
+// AddChecklistItem adds a checklist item to a task within the scope
+func (r *ScopedTaskRepository) AddChecklistItem(ctx context.Context, taskID, text string) (*models.Task, error) {
+	if err := r.checkTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.AddChecklistItem(ctx, taskID, text)
+}
+
+// ToggleChecklistItem toggles a checklist item of a task within the scope
+func (r *ScopedTaskRepository) ToggleChecklistItem(
+	ctx context.Context,
+	taskID, itemID string,
+	done *bool,
+) (*models.Task, error) {
+	if err := r.checkTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.ToggleChecklistItem(ctx, taskID, itemID, done)
+}
+
+// RemoveChecklistItem removes a checklist item from a task within the scope
+func (r *ScopedTaskRepository) RemoveChecklistItem(ctx context.Context, taskID, itemID string) (*models.Task, error) {
+	if err := r.checkTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.RemoveChecklistItem(ctx, taskID, itemID)
+}
+
+// LogTime logs time on a task within the scope
+func (r *ScopedTaskRepository) LogTime(ctx context.Context, taskID string, duration time.Duration) (*models.Task, error) {
+	if err := r.checkTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.LogTime(ctx, taskID, duration)
+}
+
+// SetMetadata sets metadata of a task within the scope
+func (r *ScopedTaskRepository) SetMetadata(
+	ctx context.Context,
+	id string,
+	metadata map[string]string,
+) (*models.Task, error) {
+	if err := r.checkTask(ctx, id); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.SetMetadata(ctx, id, metadata)
+}
+
+// DeleteMetadata deletes metadata of a task within the scope
+func (r *ScopedTaskRepository) DeleteMetadata(ctx context.Context, id string, keys []string) (*models.Task, error) {
+	if err := r.checkTask(ctx, id); err != nil {
+		return nil, err
+	}
+	return r.TaskRepositoryInterface.DeleteMetadata(ctx, id, keys)
+}
+
+// ScopedApplicationRepository decorates an application repository and hides the applications outside the
+// application scope of the context. Without a scope in the context, every call passes through.
+type ScopedApplicationRepository struct {
+	ApplicationRepositoryInterface
+}
+
+// NewScopedApplicationRepository wraps an application repository with application scoping
+func NewScopedApplicationRepository(inner ApplicationRepositoryInterface) *ScopedApplicationRepository {
+	return &ScopedApplicationRepository{ApplicationRepositoryInterface: inner}
+}
+
+// Create registers an application within the scope
+func (r *ScopedApplicationRepository) Create(
+	ctx context.Context,
+	id, name, description string,
+	metadata map[string]string,
+) (*models.Application, error) {
+	if err := checkApplication(ctx, id); err != nil {
+		return nil, err
+	}
+	return r.ApplicationRepositoryInterface.Create(ctx, id, name, description, metadata)
+}
+
+// Get retrieves an application within the scope
+func (r *ScopedApplicationRepository) Get(ctx context.Context, id string) (*models.Application, error) {
+	if checkApplication(ctx, id) != nil {
+		return nil, models.NewNotFoundError(models.EntityApplication, id)
+	}
+	return r.ApplicationRepositoryInterface.Get(ctx, id)
+}
+
+// Exists reports whether an application within the scope is registered
+func (r *ScopedApplicationRepository) Exists(ctx context.Context, id string) (bool, error) {
+	if checkApplication(ctx, id) != nil {
+		return false, nil
+	}
+	return r.ApplicationRepositoryInterface.Exists(ctx, id)
+}
+
+// List lists the applications within the scope
+func (r *ScopedApplicationRepository) List(ctx context.Context) ([]*models.Application, error) {
+	applications, err := r.ApplicationRepositoryInterface.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(applications, func(a *models.Application) bool {
+		return checkApplication(ctx, a.ID) != nil
+	}), nil
+}
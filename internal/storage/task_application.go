@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// The tasks of an application are found through the set of its plans and the task list of each plan. The
+// task counters of the plans tell which plans have tasks with a status, so listing the tasks with a status
+// only reads the plans that have some.
+
+// ListByApplication returns the tasks of all plans of an application, plan by plan in the order of the plans
+// and in order within each plan
+func (r *TaskRepository) ListByApplication(ctx context.Context, applicationID string) ([]*models.Task, error) {
+	return r.listByApplication(ctx, applicationID, "")
+}
+
+// ListByApplicationAndStatus returns the tasks with a status of all plans of an application, ordered like
+// ListByApplication
+func (r *TaskRepository) ListByApplicationAndStatus(
+	ctx context.Context,
+	applicationID string,
+	status models.TaskStatus,
+) ([]*models.Task, error) {
+	return r.listByApplication(ctx, applicationID, status)
+}
+
+// listByApplication lists the tasks of an application, all of them or only those with a status
+func (r *TaskRepository) listByApplication(
+	ctx context.Context,
+	applicationID string,
+	status models.TaskStatus,
+) ([]*models.Task, error) {
+	ctx = withReplicaReads(ctx)
+
+	plans, err := (&PlanRepository{client: r.client}).ListByApplication(ctx, applicationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plans of application %s: %w", applicationID, err)
+	}
+
+	tasks := make([]*models.Task, 0)
+	for _, plan := range plans {
+		// Plans without tasks with the status are skipped, unless they are not counted yet
+		if status != "" && plan.TaskCounts != nil && plan.TaskCounts.Count(status) == 0 {
+			continue
+		}
+
+		planTasks, err := r.ListByPlan(ctx, plan.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tasks for plan %s: %w", plan.ID, err)
+		}
+		for _, task := range planTasks {
+			if status == "" || task.Status == status {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+
+	return tasks, nil
+}
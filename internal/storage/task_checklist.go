@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// AddChecklistItem appends a checklist item to a task
+func (r *TaskRepository) AddChecklistItem(ctx context.Context, taskID, text string) (*models.Task, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, models.NewValidationError(models.EntityTask, taskID, "checklist item text cannot be empty")
+	}
+
+	// Get the task to verify it exists
+	_, err := r.Get(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	item := models.NewChecklistItem(uuid.New().String(), text)
+	encoded, err := item.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.client.client.RPush(ctx, GetTaskChecklistKey(taskID), []string{encoded})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add checklist item: %w", err)
+	}
+
+	return r.Get(ctx, taskID)
+}
+
+// ToggleChecklistItem flips the done flag of a checklist item, or sets it to the given value when done is not nil
+func (r *TaskRepository) ToggleChecklistItem(ctx context.Context, taskID, itemID string, done *bool) (*models.Task, error) {
+	task, err := r.Get(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	index := checklistIndex(task.Checklist, itemID)
+	if index < 0 {
+		return nil, models.NewNotFoundError(models.EntityChecklistItem, itemID)
+	}
+
+	item := task.Checklist[index]
+	if done != nil {
+		item.Done = *done
+	} else {
+		item.Done = !item.Done
+	}
+
+	encoded, err := item.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.client.client.LSet(ctx, GetTaskChecklistKey(taskID), int64(index), encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update checklist item: %w", err)
+	}
+
+	return task, nil
+}
+
+// RemoveChecklistItem removes a checklist item from a task
+func (r *TaskRepository) RemoveChecklistItem(ctx context.Context, taskID, itemID string) (*models.Task, error) {
+	task, err := r.Get(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	index := checklistIndex(task.Checklist, itemID)
+	if index < 0 {
+		return nil, models.NewNotFoundError(models.EntityChecklistItem, itemID)
+	}
+
+	// Remove by the stored value since list indexes shift on removal
+	encoded, err := task.Checklist[index].Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.client.client.LRem(ctx, GetTaskChecklistKey(taskID), 1, encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove checklist item: %w", err)
+	}
+
+	return r.Get(ctx, taskID)
+}
+
+// getChecklist returns the ordered checklist items of a task
+func (r *TaskRepository) getChecklist(ctx context.Context, taskID string) ([]*models.ChecklistItem, error) {
+	entries, err := r.client.client.LRange(ctx, GetTaskChecklistKey(taskID), 0, -1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task checklist: %w", err)
+	}
+
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	items := make([]*models.ChecklistItem, 0, len(entries))
+	for _, entry := range entries {
+		item, err := models.DecodeChecklistItem(entry)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// copyChecklist copies checklist items to another task with fresh IDs, optionally resetting their done flags
+func (r *TaskRepository) copyChecklist(
+	ctx context.Context,
+	items []*models.ChecklistItem,
+	taskID string,
+	resetDone bool,
+) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	encoded := make([]string, 0, len(items))
+	for _, item := range items {
+		copied := models.NewChecklistItem(uuid.New().String(), item.Text)
+		copied.Done = item.Done && !resetDone
+
+		entry, err := copied.Encode()
+		if err != nil {
+			return err
+		}
+		encoded = append(encoded, entry)
+	}
+
+	_, err := r.client.client.RPush(ctx, GetTaskChecklistKey(taskID), encoded)
+	if err != nil {
+		return fmt.Errorf("failed to copy checklist: %w", err)
+	}
+
+	return nil
+}
+
+// checklistIndex returns the position of a checklist item, or -1 if it is not found
+func checklistIndex(items []*models.ChecklistItem, itemID string) int {
+	for i, item := range items {
+		if item.ID == itemID {
+			return i
+		}
+	}
+	return -1
+}
+
+// restoreChecklist replaces the checklist of a task with the given items, keeping their IDs
+func (r *TaskRepository) restoreChecklist(ctx context.Context, taskID string, items []*models.ChecklistItem) error {
+	checklistKey := GetTaskChecklistKey(taskID)
+	_, err := r.client.client.Del(ctx, []string{checklistKey})
+	if err != nil {
+		return fmt.Errorf("failed to clear checklist: %w", err)
+	}
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	encoded := make([]string, 0, len(items))
+	for _, item := range items {
+		entry, err := item.Encode()
+		if err != nil {
+			return err
+		}
+		encoded = append(encoded, entry)
+	}
+
+	_, err = r.client.client.RPush(ctx, checklistKey, encoded)
+	if err != nil {
+		return fmt.Errorf("failed to restore checklist: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// A completion note summarizes what was done when a task is completed. It is kept on the task and appended
+// to the completion record of its plan, so the plan keeps a record of the delivered work even when a task
+// is reopened, moved or deleted later.
+
+// RequireCompletionNotes makes the repository reject completing a task without a completion note. Tasks
+// created as completed, such as by imports, are not affected.
+func (r *TaskRepository) RequireCompletionNotes(required bool) {
+	r.requireCompletionNotes = required
+}
+
+// checkCompletionNote applies the completion note policy to a task changing from the previous status. The
+// note of a task that is not completed is cleared, so completing it again needs a new note.
+func (r *TaskRepository) checkCompletionNote(previous models.TaskStatus, task *models.Task) error {
+	task.CompletionNote = strings.TrimSpace(task.CompletionNote)
+	if task.Status != models.TaskStatusCompleted {
+		task.CompletionNote = ""
+		return nil
+	}
+	if previous != models.TaskStatusCompleted && task.CompletionNote == "" && r.requireCompletionNotes {
+		return models.NewValidationError(models.EntityTask, task.ID,
+			"completing task %s requires a completion note summarizing what was done", task.ID)
+	}
+	return nil
+}
+
+// RequireMetAcceptanceCriteria makes the repository reject completing a task with acceptance criteria that
+// are not met yet. Tasks without acceptance criteria are not affected.
+func (r *TaskRepository) RequireMetAcceptanceCriteria(required bool) {
+	r.requireMetCriteria = required
+}
+
+// checkAcceptanceCriteria applies the acceptance criteria policy to a task changing from the previous status
+func (r *TaskRepository) checkAcceptanceCriteria(previous models.TaskStatus, task *models.Task) error {
+	if !r.requireMetCriteria || previous == models.TaskStatusCompleted || task.Status != models.TaskStatusCompleted {
+		return nil
+	}
+	unmet := task.UnmetAcceptanceCriteria()
+	if len(unmet) == 0 {
+		return nil
+	}
+	texts := make([]string, len(unmet))
+	for i, criterion := range unmet {
+		texts[i] = fmt.Sprintf("%q", criterion.Text)
+	}
+	return models.NewValidationError(models.EntityTask, task.ID,
+		"completing task %s requires its acceptance criteria to be met, %d are not: %s",
+		task.ID, len(unmet), strings.Join(texts, ", "))
+}
+
+// recordCompletion appends the completion note of a task to the completion record of its plan
+func (r *TaskRepository) recordCompletion(ctx context.Context, task *models.Task) error {
+	completion := models.TaskCompletion{
+		TaskID: task.ID,
+		Title:  task.Title,
+		Note:   task.CompletionNote,
+	}
+	if task.CompletedAt != nil {
+		completion.CompletedAt = *task.CompletedAt
+	}
+	data, err := json.Marshal(completion)
+	if err != nil {
+		return fmt.Errorf("failed to encode completion note: %w", err)
+	}
+
+	sealed, err := r.client.seal(string(data))
+	if err != nil {
+		return err
+	}
+	_, err = r.client.client.RPush(ctx, GetPlanCompletionsKey(task.PlanID), []string{sealed})
+	if err != nil {
+		return fmt.Errorf("failed to record completion note: %w", err)
+	}
+	return nil
+}
+
+// ListCompletions returns the completion record of a plan, oldest first
+func (r *TaskRepository) ListCompletions(ctx context.Context, planID string) ([]*models.TaskCompletion, error) {
+	ctx = withReplicaReads(ctx)
+
+	exists, err := r.client.client.SIsMember(ctx, plansListKey, planID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if plan exists: %w", err)
+	}
+	if !exists {
+		return nil, models.NewNotFoundError(models.EntityPlan, planID)
+	}
+
+	entries, err := r.client.client.LRange(ctx, GetPlanCompletionsKey(planID), 0, -1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get completion notes: %w", err)
+	}
+	completions := make([]*models.TaskCompletion, 0, len(entries))
+	for _, entry := range entries {
+		entry, err := r.client.open(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read completion note: %w", err)
+		}
+		var completion models.TaskCompletion
+		if err := json.Unmarshal([]byte(entry), &completion); err != nil {
+			return nil, fmt.Errorf("failed to decode completion note: %w", err)
+		}
+		completions = append(completions, &completion)
+	}
+	return completions, nil
+}
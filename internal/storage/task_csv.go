@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"cmp"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// TaskCSVColumns are the columns written by WriteTasksCSV, in order.
+// ReadTasksCSV accepts them in any order and ignores other columns; only title is required.
+var TaskCSVColumns = []string{"title", "description", "status", "priority", "order"}
+
+// utf8BOM is written by some spreadsheet applications at the start of CSV files
+const utf8BOM = "\ufeff"
+
+// csvFormulaPrefixes are the characters spreadsheet applications treat as the start of a formula
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// csvEscapedPrefixes are the first characters of text escapeCSVFormula prefixes with a quote: the formula
+// prefixes and the quote itself, so text already starting with a quote survives unescaping unchanged
+const csvEscapedPrefixes = csvFormulaPrefixes + "'"
+
+// escapeCSVFormula prefixes text that a spreadsheet application would run as a formula with a quote, so
+// exported titles and descriptions are shown as text. Text starting with a quote is prefixed too.
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune(csvEscapedPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// unescapeCSVFormula removes the quote escapeCSVFormula adds
+func unescapeCSVFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(csvEscapedPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// WriteTasksCSV writes tasks as CSV with a header row.
+// The placeholder description of tasks created without one is written as an empty cell. Titles and descriptions
+// starting like a formula are prefixed with a quote, which ReadTasksCSV removes again.
+func WriteTasksCSV(w io.Writer, tasks []*models.Task) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(TaskCSVColumns); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, task := range tasks {
+		description := task.Description
+		if description == DefaultTaskDescription {
+			description = ""
+		}
+
+		record := []string{
+			escapeCSVFormula(task.Title),
+			escapeCSVFormula(description),
+			string(task.Status),
+			string(task.Priority),
+			strconv.Itoa(task.Order),
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write task %s: %w", task.ID, err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ReadTasksCSV reads task definitions from CSV with a header row.
+// Status and priority values are matched case-insensitively and may use spaces instead of underscores.
+// Titles and descriptions escaped against formulas by WriteTasksCSV are unescaped.
+// Rows are returned sorted by the order column; rows without an order keep their position after the ordered ones.
+func ReadTasksCSV(r io.Reader) ([]TaskCreateInput, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("CSV is empty, expected a header row")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, utf8BOM)))
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, fmt.Errorf("CSV header has no title column, expected columns: %s", strings.Join(TaskCSVColumns, ", "))
+	}
+
+	type orderedInput struct {
+		input TaskCreateInput
+		order int
+	}
+
+	var rows []orderedInput
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV row %d: %w", row, err)
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		input := TaskCreateInput{
+			Title:       unescapeCSVFormula(field("title")),
+			Description: unescapeCSVFormula(field("description")),
+			Status:      models.TaskStatus(normalizeCSVValue(field("status"))),
+			Priority:    models.TaskPriority(normalizeCSVValue(field("priority"))),
+		}
+		if input.Title == "" {
+			return nil, fmt.Errorf("CSV row %d has no title", row)
+		}
+		if input.Status != "" && !models.IsValidTaskStatus(input.Status) {
+			return nil, fmt.Errorf("CSV row %d has an invalid status: %s", row, field("status"))
+		}
+		if input.Priority != "" && !models.IsValidTaskPriority(input.Priority) {
+			return nil, fmt.Errorf("CSV row %d has an invalid priority: %s", row, field("priority"))
+		}
+
+		order := math.MaxInt
+		if value := field("order"); value != "" {
+			order, err = strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("CSV row %d has an invalid order: %s", row, value)
+			}
+		}
+
+		rows = append(rows, orderedInput{input: input, order: order})
+	}
+
+	slices.SortStableFunc(rows, func(a, b orderedInput) int {
+		return cmp.Compare(a.order, b.order)
+	})
+
+	inputs := make([]TaskCreateInput, len(rows))
+	for i, row := range rows {
+		inputs[i] = row.input
+	}
+	return inputs, nil
+}
+
+// normalizeCSVValue turns spreadsheet friendly values such as "In Progress" into status and priority values
+func normalizeCSVValue(value string) string {
+	return strings.ReplaceAll(strings.ToLower(value), " ", "_")
+}
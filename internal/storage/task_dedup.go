@@ -0,0 +1,249 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// DedupMode controls how bulk creation handles tasks whose titles match tasks already in the plan
+type DedupMode string
+
+const (
+	// DedupModeNone creates every task, even when its title is already used in the plan
+	DedupModeNone DedupMode = "none"
+	// DedupModeSkip leaves duplicates out and keeps the existing task unchanged
+	DedupModeSkip DedupMode = "skip"
+	// DedupModeMerge folds the description and priority of duplicates into the existing task
+	DedupModeMerge DedupMode = "merge"
+)
+
+// TitleMatch controls how task titles are compared when deduplicating
+type TitleMatch string
+
+const (
+	// TitleMatchExact treats titles as duplicates when they are identical apart from surrounding whitespace
+	TitleMatchExact TitleMatch = "exact"
+	// TitleMatchNormalized compares titles ignoring case, punctuation and repeated whitespace
+	TitleMatchNormalized TitleMatch = "normalized"
+)
+
+// BulkCreateOptions configures CreateBulkWithOptions.
+// The zero value creates every task, exactly like CreateBulk.
+type BulkCreateOptions struct {
+	Dedup DedupMode
+	// Match defaults to TitleMatchNormalized when empty
+	Match TitleMatch
+}
+
+// BulkDuplicate describes an input task that matched a task already in the plan or earlier in the same batch
+type BulkDuplicate struct {
+	Index  int    `json:"index"`
+	Title  string `json:"title"`
+	TaskID string `json:"task_id"`
+}
+
+// BulkCreateReport describes the outcome of a bulk creation with deduplication
+type BulkCreateReport struct {
+	Created []*models.Task  `json:"created"`
+	Skipped []BulkDuplicate `json:"skipped"`
+	Merged  []BulkDuplicate `json:"merged"`
+}
+
+// batchDuplicate is an input that duplicates another input of the same batch
+type batchDuplicate struct {
+	duplicate BulkDuplicate
+	target    int
+}
+
+// CreateBulkWithOptions adds multiple tasks to a plan, optionally skipping or merging tasks whose titles
+// match tasks already in the plan. Duplicates within the batch itself are collapsed into their first occurrence.
+// Merging never changes the status of an existing task; it only adds a missing description and raises the priority.
+func (r *TaskRepository) CreateBulkWithOptions(
+	ctx context.Context,
+	planID string,
+	taskInputs []TaskCreateInput,
+	opts BulkCreateOptions,
+) (*BulkCreateReport, error) {
+	ctx = withPrimaryReads(ctx)
+
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	// Hold the plan lock from reading the existing titles until the new tasks are created
+	ctx, unlock, err := r.client.lockPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	report := &BulkCreateReport{
+		Created: []*models.Task{},
+		Skipped: []BulkDuplicate{},
+		Merged:  []BulkDuplicate{},
+	}
+
+	if opts.Dedup == "" || opts.Dedup == DedupModeNone {
+		created, err := r.CreateBulk(ctx, planID, taskInputs)
+		if err != nil {
+			return nil, err
+		}
+		report.Created = created
+		return report, nil
+	}
+
+	existing, err := r.ListByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Index existing tasks by title, keeping the first task in plan order for each title
+	existingByTitle := make(map[string]*models.Task, len(existing))
+	for _, task := range existing {
+		key := opts.titleKey(task.Title)
+		if _, ok := existingByTitle[key]; !ok {
+			existingByTitle[key] = task
+		}
+	}
+
+	toCreate := make([]TaskCreateInput, 0, len(taskInputs))
+	pendingByTitle := make(map[string]int)
+	var batchDuplicates []batchDuplicate
+	var changed []*models.Task
+
+	for i, input := range taskInputs {
+		key := opts.titleKey(input.Title)
+
+		if task, ok := existingByTitle[key]; ok {
+			duplicate := BulkDuplicate{Index: i, Title: input.Title, TaskID: task.ID}
+			if opts.Dedup == DedupModeSkip {
+				report.Skipped = append(report.Skipped, duplicate)
+				continue
+			}
+
+			if mergeIntoTask(task, input) && !slices.Contains(changed, task) {
+				changed = append(changed, task)
+			}
+			report.Merged = append(report.Merged, duplicate)
+			continue
+		}
+
+		if target, ok := pendingByTitle[key]; ok {
+			if opts.Dedup == DedupModeMerge {
+				toCreate[target] = mergeInputs(toCreate[target], input)
+			}
+			batchDuplicates = append(batchDuplicates, batchDuplicate{
+				duplicate: BulkDuplicate{Index: i, Title: input.Title},
+				target:    target,
+			})
+			continue
+		}
+
+		pendingByTitle[key] = len(toCreate)
+		toCreate = append(toCreate, input)
+	}
+
+	// Create the new tasks before touching existing ones so a failure leaves the plan unchanged
+	if len(toCreate) > 0 {
+		report.Created, err = r.CreateBulk(ctx, planID, toCreate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, batchDup := range batchDuplicates {
+		batchDup.duplicate.TaskID = report.Created[batchDup.target].ID
+		if opts.Dedup == DedupModeSkip {
+			report.Skipped = append(report.Skipped, batchDup.duplicate)
+		} else {
+			report.Merged = append(report.Merged, batchDup.duplicate)
+		}
+	}
+
+	byIndex := func(a, b BulkDuplicate) int { return a.Index - b.Index }
+	slices.SortFunc(report.Skipped, byIndex)
+	slices.SortFunc(report.Merged, byIndex)
+
+	for _, task := range changed {
+		err = r.Update(ctx, task)
+		if err != nil {
+			return report, fmt.Errorf("failed to merge into task %s: %w", task.ID, err)
+		}
+	}
+
+	return report, nil
+}
+
+// validate checks that the options name a known dedup mode and title match
+func (o BulkCreateOptions) validate() error {
+	switch o.Dedup {
+	case "", DedupModeNone, DedupModeSkip, DedupModeMerge:
+	default:
+		return models.NewValidationError("", "", "invalid dedup mode: %s", o.Dedup)
+	}
+
+	switch o.Match {
+	case "", TitleMatchExact, TitleMatchNormalized:
+	default:
+		return models.NewValidationError("", "", "invalid title match: %s", o.Match)
+	}
+
+	return nil
+}
+
+// titleKey returns the value used to compare a title under the configured match
+func (o BulkCreateOptions) titleKey(title string) string {
+	if o.Match == TitleMatchExact {
+		return strings.TrimSpace(title)
+	}
+	return models.NormalizeTitle(title)
+}
+
+// mergeIntoTask folds a duplicate input into an existing task and reports whether the task changed
+func mergeIntoTask(task *models.Task, input TaskCreateInput) bool {
+	changed := false
+
+	if description, ok := mergeDescription(task.Description, input.Description); ok {
+		task.Description = description
+		changed = true
+	}
+
+	if models.PriorityRank(input.Priority) > models.PriorityRank(task.Priority) {
+		task.Priority = input.Priority
+		changed = true
+	}
+
+	return changed
+}
+
+// mergeInputs folds a duplicate input into an earlier input of the same batch
+func mergeInputs(target, input TaskCreateInput) TaskCreateInput {
+	if description, ok := mergeDescription(target.Description, input.Description); ok {
+		target.Description = description
+	}
+
+	if models.PriorityRank(input.Priority) > models.PriorityRank(target.Priority) {
+		target.Priority = input.Priority
+	}
+
+	return target
+}
+
+// mergeDescription appends an incoming description to an existing one unless it adds nothing new.
+// A missing or placeholder description is replaced outright.
+func mergeDescription(existing, incoming string) (string, bool) {
+	incoming = strings.TrimSpace(incoming)
+	if incoming == "" || strings.Contains(existing, incoming) {
+		return existing, false
+	}
+
+	if strings.TrimSpace(existing) == "" || existing == DefaultTaskDescription {
+		return incoming, true
+	}
+
+	return existing + "\n\n" + incoming, true
+}
@@ -0,0 +1,249 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/valkey-io/valkey-glide/go/v2/constants"
+	"github.com/valkey-io/valkey-glide/go/v2/options"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// DefaultLeaseTTL is the lease duration used when a caller does not provide one
+const DefaultLeaseTTL = 5 * time.Minute
+
+// renewLeaseScript extends a lease only if it is still held by the given worker.
+// KEYS[1] is the lease key, ARGV[1] the worker ID and ARGV[2] the new TTL in milliseconds.
+var renewLeaseScript = options.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// ClaimTask claims a task for a worker, marking it in progress and creating a lease with the given TTL.
+// A task can only be claimed when it is not completed or cancelled and no other worker holds its lease.
+func (r *TaskRepository) ClaimTask(ctx context.Context, taskID, workerID string, ttl time.Duration) (*models.Task, error) {
+	if workerID == "" {
+		return nil, models.NewValidationError("", "", "worker ID is required")
+	}
+	if ttl <= 0 {
+		ttl = DefaultLeaseTTL
+	}
+
+	// Get the task under the lock of its plan to verify it exists and can be claimed
+	ctx, task, unlock, err := r.lockTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if task.Status == models.TaskStatusCompleted || task.Status == models.TaskStatusCancelled {
+		return nil, models.NewConflictError(models.EntityTask, taskID, "cannot claim task %s with status %s", taskID, task.Status)
+	}
+
+	// Acquire the lease key only if nobody else holds it
+	leaseKey := GetTaskLeaseKey(taskID)
+	setOpts := options.NewSetOptions().
+		SetOnlyIfDoesNotExist().
+		SetExpiry(options.NewExpiryIn(ttl))
+	result, err := r.client.client.SetWithOptions(ctx, leaseKey, workerID, *setOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire task lease: %w", err)
+	}
+
+	if result.IsNil() {
+		owner, err := r.client.client.Get(ctx, leaseKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get task lease owner: %w", err)
+		}
+		return nil, models.NewConflictError(models.EntityTask, taskID, "task %s is already claimed by %s", taskID, owner.Value())
+	}
+
+	// Record the lease on the task and move it to in progress
+	previousStatus := task.Status
+	expiresAt := time.Now().Add(ttl)
+	task.Status = models.TaskStatusInProgress
+	task.LeaseOwner = workerID
+	task.LeaseExpiresAt = &expiresAt
+	task.UpdatedAt = time.Now()
+	task.RecordStatusTransition(previousStatus, task.UpdatedAt)
+
+	sealed, err := r.client.sealFields(task.ToMap())
+	if err == nil {
+		_, err = r.client.client.HSet(ctx, GetTaskKey(taskID), sealed)
+	}
+	if err != nil {
+		r.client.client.Del(ctx, []string{leaseKey}) //nolint:errcheck
+		return nil, fmt.Errorf("failed to update claimed task: %w", err)
+	}
+
+	// Track the lease expiry so the sweeper can find it later
+	_, err = r.client.client.ZAdd(ctx, taskLeasesKey, map[string]float64{taskID: float64(expiresAt.UnixMilli())})
+	if err != nil {
+		return nil, fmt.Errorf("failed to track task lease: %w", err)
+	}
+
+	err = r.recordTaskChange(ctx, task.PlanID, previousStatus, task.Status)
+	if err != nil {
+		// Log the error but don't fail the claim
+		fmt.Printf("Warning: failed to update plan status: %v\n", err)
+	}
+
+	return task, nil
+}
+
+// RenewLease extends the lease held by a worker on a task
+func (r *TaskRepository) RenewLease(ctx context.Context, taskID, workerID string, ttl time.Duration) (*models.Task, error) {
+	if workerID == "" {
+		return nil, models.NewValidationError("", "", "worker ID is required")
+	}
+	if ttl <= 0 {
+		ttl = DefaultLeaseTTL
+	}
+
+	task, err := r.Get(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extend the lease atomically, only if the worker still holds it
+	scriptOpts := options.NewScriptOptions().
+		WithKeys([]string{GetTaskLeaseKey(taskID)}).
+		WithArgs([]string{workerID, strconv.FormatInt(ttl.Milliseconds(), 10)})
+	result, err := r.client.client.InvokeScriptWithOptions(ctx, *renewLeaseScript, *scriptOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to renew task lease: %w", err)
+	}
+
+	if renewed, ok := result.(int64); !ok || renewed == 0 {
+		return nil, models.NewConflictError(
+			models.EntityTask, taskID, "task %s is not leased by %s or the lease has expired", taskID, workerID,
+		)
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	task.LeaseOwner = workerID
+	task.LeaseExpiresAt = &expiresAt
+
+	_, err = r.client.client.HSet(ctx, GetTaskKey(taskID), map[string]string{
+		"lease_owner":      task.LeaseOwner,
+		"lease_expires_at": expiresAt.Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update task lease: %w", err)
+	}
+
+	_, err = r.client.client.ZAdd(ctx, taskLeasesKey, map[string]float64{taskID: float64(expiresAt.UnixMilli())})
+	if err != nil {
+		return nil, fmt.Errorf("failed to track task lease: %w", err)
+	}
+
+	return task, nil
+}
+
+// ExpireLeases returns tasks whose leases have expired to the pending state.
+// It returns the IDs of the tasks that were released.
+func (r *TaskRepository) ExpireLeases(ctx context.Context) ([]string, error) {
+	// Find all leases whose tracked expiry is in the past
+	query := options.NewRangeByScoreQuery(
+		options.NewInfiniteScoreBoundary(constants.NegativeInfinity),
+		options.NewScoreBoundary(float64(time.Now().UnixMilli()), true),
+	)
+	taskIDs, err := r.client.client.ZRange(ctx, taskLeasesKey, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired leases: %w", err)
+	}
+
+	released := make([]string, 0, len(taskIDs))
+	for _, taskID := range taskIDs {
+		expired, err := r.expireLease(ctx, taskID)
+		if err != nil {
+			return released, err
+		}
+		if expired {
+			released = append(released, taskID)
+		}
+	}
+
+	return released, nil
+}
+
+// expireLease returns a task whose lease has expired to the pending state under the lock of its plan. It
+// reports whether the task was released.
+func (r *TaskRepository) expireLease(ctx context.Context, taskID string) (bool, error) {
+	// The lease key carries the authoritative TTL, so skip leases that are still alive
+	exists, err := r.client.client.Exists(ctx, []string{GetTaskLeaseKey(taskID)})
+	if err != nil {
+		return false, fmt.Errorf("failed to check task lease: %w", err)
+	}
+	if exists > 0 {
+		return false, nil
+	}
+
+	lockedCtx, task, unlock, err := r.lockTask(ctx, taskID)
+	switch {
+	case models.ErrorCodeOf(err) == models.ErrorCodeNotFound:
+		// The task was deleted in the meantime
+	case err != nil:
+		// Keep tracking the lease, so a later sweep releases the task
+		fmt.Printf("Warning: failed to lock task %s to release it: %v\n", taskID, err)
+		return false, nil
+	default:
+		ctx = lockedCtx
+		defer unlock()
+	}
+
+	// Stop tracking the lease regardless of the task state
+	_, err = r.client.client.ZRem(ctx, taskLeasesKey, []string{taskID})
+	if err != nil {
+		return false, fmt.Errorf("failed to remove expired lease: %w", err)
+	}
+
+	if task == nil || task.LeaseOwner == "" {
+		return false, nil
+	}
+
+	// Return the task to the queue
+	task.ClearLease()
+	previousStatus := task.Status
+	if task.Status == models.TaskStatusInProgress {
+		task.Status = models.TaskStatusPending
+	}
+	task.UpdatedAt = time.Now()
+	task.RecordStatusTransition(previousStatus, task.UpdatedAt)
+
+	sealed, err := r.client.sealFields(task.ToMap())
+	if err != nil {
+		return false, err
+	}
+	_, err = r.client.client.HSet(ctx, GetTaskKey(taskID), sealed)
+	if err != nil {
+		return false, fmt.Errorf("failed to release task %s: %w", taskID, err)
+	}
+
+	err = r.recordTaskChange(ctx, task.PlanID, previousStatus, task.Status)
+	if err != nil {
+		// Log the error but keep sweeping
+		fmt.Printf("Warning: failed to update plan status: %v\n", err)
+	}
+	return true, nil
+}
+
+// releaseLease removes the lease key and its expiry tracking for a task
+func (r *TaskRepository) releaseLease(ctx context.Context, taskID string) error {
+	_, err := r.client.client.Del(ctx, []string{GetTaskLeaseKey(taskID)})
+	if err != nil {
+		return fmt.Errorf("failed to delete task lease: %w", err)
+	}
+
+	_, err = r.client.client.ZRem(ctx, taskLeasesKey, []string{taskID})
+	if err != nil {
+		return fmt.Errorf("failed to remove task lease tracking: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	glidemodels "github.com/valkey-io/valkey-glide/go/v2/models"
+	"github.com/valkey-io/valkey-glide/go/v2/options"
+)
+
+const (
+	// orderSpacing is the gap between the scores of tasks appended to a plan. A task moved between two
+	// others gets the score halfway between theirs, so about thirty moves fit between two neighbors before
+	// the plan has to be renormalized.
+	orderSpacing = 1024.0
+	// minOrderGap is the smallest gap between neighboring scores a moved task is placed in. Smaller gaps
+	// get close to the precision of a float, so the plan is renormalized first.
+	minOrderGap = 1e-6
+)
+
+// The order of the tasks in a plan is kept only by their scores in the plan's sorted set. Scores are sparse,
+// so moving a task is a single ZADD between its new neighbors and other tasks keep their scores. The order of
+// a task is its rank in the set, the order stored in the task hash is only a hint for integrity repairs.
+
+// planScores returns the members of a plan's task set with their scores, in order
+func (r *TaskRepository) planScores(ctx context.Context, planID string) ([]glidemodels.MemberAndScore, error) {
+	scores, err := r.client.client.ZRangeWithScores(ctx, GetPlanTasksKey(planID), options.NewRangeByIndexQuery(0, -1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plan task scores: %w", err)
+	}
+	return scores, nil
+}
+
+// appendScore returns the score of a task appended after the last task of a plan
+func (r *TaskRepository) appendScore(ctx context.Context, planID string) (float64, error) {
+	query := options.NewRangeByIndexQuery(-1, -1)
+	last, err := r.client.client.ZRangeWithScores(withPrimaryReads(ctx), GetPlanTasksKey(planID), query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last task of plan: %w", err)
+	}
+	if len(last) == 0 {
+		return 0, nil
+	}
+	return last[0].Score + orderSpacing, nil
+}
+
+// scoreAt returns the score that puts a task at the given position among the other tasks of a plan.
+// The position is clamped to the plan. If the neighbors at that position are too close, the plan is
+// renormalized first.
+func (r *TaskRepository) scoreAt(ctx context.Context, planID, taskID string, position int) (float64, error) {
+	ctx = withPrimaryReads(ctx)
+	scores, err := r.planScores(ctx, planID)
+	if err != nil {
+		return 0, err
+	}
+
+	others := make([]glidemodels.MemberAndScore, 0, len(scores))
+	for _, member := range scores {
+		if member.Member != taskID {
+			others = append(others, member)
+		}
+	}
+
+	score, ok := scoreBetween(others, position)
+	if ok {
+		return score, nil
+	}
+
+	others, err = r.renormalize(ctx, planID, others)
+	if err != nil {
+		return 0, err
+	}
+	score, _ = scoreBetween(others, position)
+	return score, nil
+}
+
+// scoreBetween returns the score halfway between the neighbors of a position in the ordered scores, or
+// one spacing before the first or after the last. It returns false if the neighbors are too close.
+func scoreBetween(scores []glidemodels.MemberAndScore, position int) (float64, bool) {
+	position = min(max(position, 0), len(scores))
+	switch {
+	case len(scores) == 0:
+		return 0, true
+	case position == 0:
+		return scores[0].Score - orderSpacing, true
+	case position == len(scores):
+		return scores[len(scores)-1].Score + orderSpacing, true
+	}
+
+	before, after := scores[position-1].Score, scores[position].Score
+	if after-before < minOrderGap {
+		return 0, false
+	}
+	return before + (after-before)/2, true
+}
+
+// renormalize spreads the given tasks of a plan evenly again, keeping their order, in a single ZADD, and
+// returns their new scores
+func (r *TaskRepository) renormalize(
+	ctx context.Context,
+	planID string,
+	scores []glidemodels.MemberAndScore,
+) ([]glidemodels.MemberAndScore, error) {
+	if len(scores) == 0 {
+		return scores, nil
+	}
+
+	spread := make([]glidemodels.MemberAndScore, len(scores))
+	members := make(map[string]float64, len(scores))
+	for i, member := range scores {
+		spread[i] = glidemodels.MemberAndScore{Member: member.Member, Score: float64(i) * orderSpacing}
+		members[member.Member] = spread[i].Score
+	}
+	if _, err := r.client.client.ZAdd(ctx, GetPlanTasksKey(planID), members); err != nil {
+		return nil, fmt.Errorf("failed to renormalize task order: %w", err)
+	}
+	return spread, nil
+}
+
+// taskPosition returns the rank of a task in its plan, or false if the task is not in the plan's set
+func (r *TaskRepository) taskPosition(ctx context.Context, planID, taskID string) (int, bool, error) {
+	rank, err := r.client.client.ZRank(ctx, GetPlanTasksKey(planID), taskID)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get task position: %w", err)
+	}
+	if rank.IsNil() {
+		return 0, false, nil
+	}
+	return int(rank.Value()), true, nil
+}
@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// Queries read the candidate tasks through the most selective index the query allows: the task lists of the
+// plans it names, the plans of the applications it names, or the task sets of the tags it names, and
+// otherwise the task lists of all plans. The task counters of the plans skip plans without tasks with the
+// queried statuses. The remaining conditions are checked on the tasks read.
+
+// Query returns the tasks matching a query, ordered by plan and by their order within the plan
+func (r *TaskRepository) Query(ctx context.Context, query *models.TaskQuery) ([]*models.Task, error) {
+	ctx = withReplicaReads(ctx)
+
+	var tasks []*models.Task
+	var err error
+	_, byPlan := query.Values(models.TaskQueryPlanID)
+	_, byApplication := query.Values(models.TaskQueryApplicationID)
+	tags, byTag := query.Values(models.TaskQueryTag)
+	if byTag && !byPlan && !byApplication {
+		tasks, err = r.queryByTags(ctx, query, tags)
+	} else {
+		tasks, err = r.queryByPlans(ctx, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].PlanID != tasks[j].PlanID {
+			return tasks[i].PlanID < tasks[j].PlanID
+		}
+		return tasks[i].Order < tasks[j].Order
+	})
+	return tasks, nil
+}
+
+// queryByPlans reads the tasks of the plans the query can match
+func (r *TaskRepository) queryByPlans(ctx context.Context, query *models.TaskQuery) ([]*models.Task, error) {
+	plans, err := r.queryPlans(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	statuses, byStatus := query.Values(models.TaskQueryStatus)
+
+	tasks := make([]*models.Task, 0)
+	for _, plan := range plans {
+		if !query.MatchesApplication(plan.ApplicationID) {
+			continue
+		}
+		// Plans without tasks with the statuses are skipped, unless they are not counted yet
+		if byStatus && plan.TaskCounts != nil && !slices.ContainsFunc(statuses, func(status string) bool {
+			return plan.TaskCounts.Count(models.TaskStatus(status)) > 0
+		}) {
+			continue
+		}
+
+		planTasks, err := r.ListByPlan(ctx, plan.ID)
+		if models.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tasks for plan %s: %w", plan.ID, err)
+		}
+		for _, task := range planTasks {
+			if query.Matches(task, plan.ApplicationID) {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+	return tasks, nil
+}
+
+// queryPlans returns the plans named by the query, the plans of the applications it names, or all plans
+func (r *TaskRepository) queryPlans(ctx context.Context, query *models.TaskQuery) ([]*models.Plan, error) {
+	planRepo := &PlanRepository{client: r.client}
+
+	if planIDs, ok := query.Values(models.TaskQueryPlanID); ok {
+		plans := make([]*models.Plan, 0, len(planIDs))
+		for _, planID := range planIDs {
+			plan, err := planRepo.Get(ctx, planID)
+			if models.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get plan %s: %w", planID, err)
+			}
+			plans = append(plans, plan)
+		}
+		return plans, nil
+	}
+
+	if applicationIDs, ok := query.Values(models.TaskQueryApplicationID); ok {
+		var plans []*models.Plan
+		for _, applicationID := range applicationIDs {
+			applicationPlans, err := planRepo.ListByApplication(ctx, applicationID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get plans of application %s: %w", applicationID, err)
+			}
+			plans = append(plans, applicationPlans...)
+		}
+		return plans, nil
+	}
+
+	plans, err := planRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plan list: %w", err)
+	}
+	return plans, nil
+}
+
+// queryByTags reads the tasks carrying any of the tags
+func (r *TaskRepository) queryByTags(ctx context.Context, query *models.TaskQuery, tags []string) ([]*models.Task, error) {
+	planRepo := &PlanRepository{client: r.client}
+	applications := make(map[string]string)
+
+	seen := make(map[string]bool)
+	tasks := make([]*models.Task, 0)
+	for _, tag := range tags {
+		taskIDs, err := r.client.client.SMembers(ctx, GetTagTasksKey(tag))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tagged tasks: %w", err)
+		}
+		for taskID := range taskIDs {
+			if seen[taskID] {
+				continue
+			}
+			seen[taskID] = true
+
+			task, err := r.Get(ctx, taskID)
+			if err != nil {
+				continue // Skip tasks that can't be retrieved
+			}
+			applicationID, ok := applications[task.PlanID]
+			if !ok {
+				plan, err := planRepo.Get(ctx, task.PlanID)
+				if err != nil && !models.IsNotFound(err) {
+					return nil, fmt.Errorf("failed to get plan %s: %w", task.PlanID, err)
+				}
+				if plan != nil {
+					applicationID = plan.ApplicationID
+				}
+				applications[task.PlanID] = applicationID
+			}
+			if query.Matches(task, applicationID) {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+	return tasks, nil
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// createNextOccurrence creates the next occurrence of a recurring task with a fresh ID and due date
+func (r *TaskRepository) createNextOccurrence(ctx context.Context, task *models.Task) (*models.Task, error) {
+	recurrence, err := models.ParseRecurrence(task.Recurrence)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse recurrence for task %s: %w", task.ID, err)
+	}
+
+	// The next due date is based on the current due date, or on now if the task had none
+	from := time.Now()
+	if task.DueDate != nil {
+		from = *task.DueDate
+	}
+	nextDueDate := recurrence.Next(from)
+
+	next, err := r.Create(ctx, task.PlanID, task.Title, task.Description, task.Priority)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create next occurrence of task %s: %w", task.ID, err)
+	}
+
+	// Carry the schedule, the estimate and the acceptance criteria, none of them met yet, over to the new occurrence
+	next.DueDate = &nextDueDate
+	next.Recurrence = recurrence.String()
+	next.Estimate = task.Estimate
+	next.AcceptanceCriteria = copyAcceptanceCriteria(task.AcceptanceCriteria, true)
+
+	sealed, err := r.client.sealFields(next.ToMap())
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.client.client.HSet(ctx, GetTaskKey(next.ID), sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store next occurrence of task %s: %w", task.ID, err)
+	}
+
+	// Carry the tags and a fresh copy of the checklist over as well
+	if len(task.Tags) > 0 {
+		next, err = r.AddTags(ctx, next.ID, task.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("failed to tag next occurrence of task %s: %w", task.ID, err)
+		}
+	}
+
+	err = r.copyChecklist(ctx, task.Checklist, next.ID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy checklist to next occurrence of task %s: %w", task.ID, err)
+	}
+	next.Checklist, err = r.getChecklist(ctx, next.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return next, nil
+}
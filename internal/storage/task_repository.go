@@ -3,9 +3,11 @@ package storage
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strconv"
+	"sync"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
 	"github.com/valkey-io/valkey-glide/go/v2/options"
 )
@@ -13,14 +15,28 @@ import (
 // TaskRepository handles storage operations for tasks
 type TaskRepository struct {
 	client *ValkeyClient
+	// compactor archives older notes, nil keeps notes whole
+	compactor *NotesCompactor
+	// requireCompletionNotes rejects completing tasks without a completion note
+	requireCompletionNotes bool
+	// requireMetCriteria rejects completing tasks with acceptance criteria that are not met
+	requireMetCriteria bool
 }
 
+// taskFetchConcurrency bounds the number of tasks list operations fetch at once
+const taskFetchConcurrency = 16
+
+// DefaultTaskDescription is stored when a bulk-created task has no description.
+// Exports and integrations treat it as an empty description.
+const DefaultTaskDescription = "no description provided"
+
 // TaskCreateInput represents the input data for creating a task
 type TaskCreateInput struct {
 	Title       string              `json:"title"`
 	Description string              `json:"description"`
 	Status      models.TaskStatus   `json:"status"`
 	Priority    models.TaskPriority `json:"priority"`
+	Estimate    float64             `json:"estimate,omitempty"`
 }
 
 // NewTaskRepository creates a new task repository
@@ -36,6 +52,16 @@ func (r *TaskRepository) Create(
 	planID, title, description string,
 	priority models.TaskPriority,
 ) (*models.Task, error) {
+	if err := models.ValidateTaskPriority(priority); err != nil {
+		return nil, err
+	}
+
+	ctx, unlock, err := r.client.lockPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	// Check if the plan exists
 	exists, err := r.client.client.SIsMember(ctx, plansListKey, planID)
 	if err != nil {
@@ -43,34 +69,40 @@ func (r *TaskRepository) Create(
 	}
 
 	if !exists {
-		return nil, fmt.Errorf("plan not found: %s", planID)
+		return nil, models.NewNotFoundError(models.EntityPlan, planID)
 	}
 
 	// Generate a unique ID for the task
-	id := uuid.New().String()
+	id := newTaskID(planID)
 
 	// Create a new task
 	task := models.NewTask(id, planID, title, description, priority)
 
-	// Get the next order value for the task
+	// Append the task after the last task of the plan
 	planTasksKey := GetPlanTasksKey(planID)
 	count, err := r.client.client.ZCard(ctx, planTasksKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get task count: %w", err)
 	}
-
-	// Set the order to be the last task in the list
+	score, err := r.appendScore(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
 	task.Order = int(count)
 
 	// Store the task in Valkey
 	taskKey := GetTaskKey(id)
-	_, err = r.client.client.HSet(ctx, taskKey, task.ToMap())
+	sealed, err := r.client.sealFields(task.ToMap())
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.client.client.HSet(ctx, taskKey, sealed)
 	if err != nil {
 		return nil, fmt.Errorf("failed to store task: %w", err)
 	}
 
-	// Add task to the plan's tasks list with its order as the score
-	_, err = r.client.client.ZAdd(ctx, planTasksKey, map[string]float64{id: float64(task.Order)})
+	// Add task to the plan's tasks list
+	_, err = r.client.client.ZAdd(ctx, planTasksKey, map[string]float64{id: score})
 	if err != nil {
 		// Try to clean up the task if adding to the set fails
 		_, err2 := r.client.client.Del(ctx, []string{taskKey})
@@ -80,8 +112,8 @@ func (r *TaskRepository) Create(
 		return nil, fmt.Errorf("failed to add task to plan: %w", err)
 	}
 
-	// Update the plan status based on the new task
-	err = r.UpdatePlanStatus(ctx, planID)
+	// Count the task and update the plan status based on it
+	err = r.recordTaskChange(ctx, planID, "", task.Status)
 	if err != nil {
 		// Log the error but don't fail the task creation
 		fmt.Printf("Warning: failed to update plan status: %v\n", err)
@@ -92,6 +124,26 @@ func (r *TaskRepository) Create(
 
 // Get retrieves a task by ID
 func (r *TaskRepository) Get(ctx context.Context, id string) (*models.Task, error) {
+	task, err := r.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// The order of a task is its position in the plan
+	position, ok, err := r.taskPosition(ctx, task.PlanID, id)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		task.Order = position
+	}
+
+	return task, nil
+}
+
+// get retrieves a task by ID with the order stored in the task, which lists that know the position of the
+// task replace
+func (r *TaskRepository) get(ctx context.Context, id string) (*models.Task, error) {
 	// Get the task from Valkey
 	taskKey := GetTaskKey(id)
 	data, err := r.client.client.HGetAll(ctx, taskKey)
@@ -101,47 +153,182 @@ func (r *TaskRepository) Get(ctx context.Context, id string) (*models.Task, erro
 
 	// Check if the task exists
 	if len(data) == 0 {
-		return nil, fmt.Errorf("task not found: %s", id)
+		return nil, models.NewNotFoundError(models.EntityTask, id)
 	}
 
 	// Convert data to task
+	if err := r.client.openFields(data); err != nil {
+		return nil, fmt.Errorf("failed to read task data: %w", err)
+	}
 	task := &models.Task{}
 	err = task.FromMap(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse task data: %w", err)
 	}
 
+	// Tags and checklist items live in their own keys
+	task.Tags, err = r.getTags(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	task.Checklist, err = r.getChecklist(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
 	return task, nil
 }
 
-// Update updates an existing task
-func (r *TaskRepository) Update(ctx context.Context, task *models.Task) error {
-	// Check if the task exists
-	taskKey := GetTaskKey(task.ID)
-	exists, err := r.client.client.Exists(ctx, []string{taskKey})
+// getMany fetches tasks by ID in the order of the IDs. The client multiplexes commands over its connections,
+// so fetching the tasks in parallel pipelines their commands instead of waiting for each reply in turn.
+func (r *TaskRepository) getMany(ctx context.Context, ids []string) ([]*models.Task, error) {
+	tasks := make([]*models.Task, len(ids))
+	errs := make([]error, len(ids))
+
+	slots := make(chan struct{}, taskFetchConcurrency)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			tasks[i], errs[i] = r.get(ctx, id)
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("failed to get task %s: %w", ids[i], err)
+		}
+	}
+	return tasks, nil
+}
+
+// lockTask takes the locks of the plan of a task and of the other given plans, in a fixed order, and reads
+// the task again under them. Changes based on the task then see its current status and plan, and their
+// counter updates do not interleave with other changes of the plan. A task moved to another plan while
+// waiting for the locks is a conflict, since the locks taken no longer cover it.
+func (r *TaskRepository) lockTask(
+	ctx context.Context,
+	taskID string,
+	planIDs ...string,
+) (context.Context, *models.Task, func(), error) {
+	ctx = withPrimaryReads(ctx)
+	task, err := r.Get(ctx, taskID)
 	if err != nil {
-		return fmt.Errorf("failed to check if task exists: %w", err)
+		return nil, nil, nil, err
+	}
+
+	planIDs = append(planIDs, task.PlanID)
+	slices.Sort(planIDs)
+	var unlocks []func()
+	unlock := func() {
+		for _, unlock := range slices.Backward(unlocks) {
+			unlock()
+		}
+	}
+	for _, planID := range slices.Compact(planIDs) {
+		var unlockPlan func()
+		ctx, unlockPlan, err = r.client.lockPlan(ctx, planID)
+		if err != nil {
+			unlock()
+			return nil, nil, nil, err
+		}
+		unlocks = append(unlocks, unlockPlan)
 	}
 
-	if exists == 0 {
-		return fmt.Errorf("task not found: %s", task.ID)
+	current, err := r.Get(ctx, taskID)
+	if err != nil {
+		unlock()
+		return nil, nil, nil, err
 	}
+	if current.PlanID != task.PlanID {
+		unlock()
+		return nil, nil, nil, models.NewConflictError(models.EntityTask, taskID,
+			"task %s was moved to plan %s by another change, try again", taskID, current.PlanID)
+	}
+	return ctx, current, unlock, nil
+}
+
+// Update updates an existing task. The task is read again under the lock of its plan, and of the plan it
+// moves to, so concurrent updates count every status change once.
+func (r *TaskRepository) Update(ctx context.Context, task *models.Task) error {
+	taskKey := GetTaskKey(task.ID)
 
-	// Get the current task to check if the plan ID has changed
-	currentTask, err := r.Get(ctx, task.ID)
+	// Get the current task to check if the status or the plan ID has changed
+	ctx, currentTask, unlock, err := r.lockTask(ctx, task.ID, task.PlanID)
 	if err != nil {
+		if models.ErrorCodeOf(err) == models.ErrorCodeNotFound {
+			return err
+		}
 		return fmt.Errorf("failed to get current task: %w", err)
 	}
+	defer unlock()
+
+	// A new status or priority must be configured, while tasks keep one that was removed since
+	if task.Status != currentTask.Status {
+		if err := models.ValidateTaskStatus(task.Status); err != nil {
+			return err
+		}
+	}
+	if task.Priority != currentTask.Priority {
+		if err := models.ValidateTaskPriority(task.Priority); err != nil {
+			return err
+		}
+	}
+
+	// Completing a task may need a completion note, which reopening it clears, and its acceptance criteria met
+	completing := currentTask.Status != models.TaskStatusCompleted && task.Status == models.TaskStatusCompleted
+	if err := r.checkCompletionNote(currentTask.Status, task); err != nil {
+		return err
+	}
+	if err := r.checkAcceptanceCriteria(currentTask.Status, task); err != nil {
+		return err
+	}
 
 	// Update the task's updated_at timestamp
 	task.UpdatedAt = time.Now()
 
+	// Stamp the time tracking fields when the status changes
+	task.RecordStatusTransition(currentTask.Status, task.UpdatedAt)
+
+	// Completing a recurring task schedules its next occurrence
+	if completing && task.Recurrence != "" {
+		next, err := r.createNextOccurrence(ctx, task)
+		if err != nil {
+			return err
+		}
+		task.NextOccurrenceID = next.ID
+	}
+
+	// A lease only applies while the task is in progress
+	if task.LeaseOwner != "" && task.Status != models.TaskStatusInProgress {
+		task.ClearLease()
+		err = r.releaseLease(ctx, task.ID)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Store the updated task
-	_, err = r.client.client.HSet(ctx, taskKey, task.ToMap())
+	sealed, err := r.client.sealFields(task.ToMap())
+	if err != nil {
+		return err
+	}
+	_, err = r.client.client.HSet(ctx, taskKey, sealed)
 	if err != nil {
 		return fmt.Errorf("failed to update task: %w", err)
 	}
 
+	if completing && task.CompletionNote != "" {
+		if err := r.recordCompletion(ctx, task); err != nil {
+			return err
+		}
+	}
+
 	// If the plan ID has changed, move the task to the new plan
 	if currentTask.PlanID != task.PlanID {
 		// Remove from the old plan's tasks list
@@ -151,26 +338,33 @@ func (r *TaskRepository) Update(ctx context.Context, task *models.Task) error {
 			return fmt.Errorf("failed to remove task from old plan: %w", err)
 		}
 
-		// Add to the new plan's tasks list
+		// Add to the end of the new plan's tasks list
+		score, err := r.appendScore(ctx, task.PlanID)
+		if err != nil {
+			return err
+		}
 		newPlanTasksKey := GetPlanTasksKey(task.PlanID)
-		_, err = r.client.client.ZAdd(ctx, newPlanTasksKey, map[string]float64{task.ID: float64(task.Order)})
+		_, err = r.client.client.ZAdd(ctx, newPlanTasksKey, map[string]float64{task.ID: score})
 		if err != nil {
 			return fmt.Errorf("failed to add task to new plan: %w", err)
 		}
 
-		// Update status for both plans
-		err = r.UpdatePlanStatus(ctx, currentTask.PlanID)
+		// Move the task between the counters and update the status of both plans
+		err = r.recordTaskChange(ctx, currentTask.PlanID, currentTask.Status, "")
 		if err != nil {
 			return fmt.Errorf("failed to update old plan status: %w", err)
 		}
-	}
-
-	// If the status has changed, update the plan status
-	if currentTask.Status != task.Status {
-		err = r.UpdatePlanStatus(ctx, task.PlanID)
+		err = r.recordTaskChange(ctx, task.PlanID, "", task.Status)
 		if err != nil {
 			return fmt.Errorf("failed to update plan status: %w", err)
 		}
+		return nil
+	}
+
+	// If the status has changed, update the plan status
+	err = r.recordTaskChange(ctx, task.PlanID, currentTask.Status, task.Status)
+	if err != nil {
+		return fmt.Errorf("failed to update plan status: %w", err)
 	}
 
 	return nil
@@ -184,31 +378,63 @@ func (r *TaskRepository) Delete(ctx context.Context, id string) error {
 		return err
 	}
 
+	// Read the task again under the plan lock, it may have changed while waiting for the lock
+	ctx, unlock, err := r.client.lockPlan(ctx, task.PlanID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	task, err = r.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
 	// Store the plan ID for later use
 	planID := task.PlanID
 
-	// Remove the task from the plan's tasks list
+	// Remove the task from the plan's tasks list, the tasks after it move up without being rewritten
 	planTasksKey := GetPlanTasksKey(planID)
 	_, err = r.client.client.ZRem(ctx, planTasksKey, []string{id})
 	if err != nil {
 		return fmt.Errorf("failed to remove task from plan list: %w", err)
 	}
 
-	// Delete the task
+	// Delete the task, its checklist, its attachments and its archived notes
 	taskKey := GetTaskKey(id)
-	_, err = r.client.client.Del(ctx, []string{taskKey})
+	_, err = r.client.client.Del(ctx, []string{
+		taskKey,
+		GetTaskChecklistKey(id),
+		GetTaskAttachmentsKey(id),
+		GetTaskAttachmentContentKey(id),
+		GetNotesArchiveKey(models.EntityTypeTask, id),
+	})
 	if err != nil {
 		return fmt.Errorf("failed to delete task: %w", err)
 	}
 
-	// Reorder the remaining tasks in the plan
-	err = r.reorderPlanTasks(ctx, planID)
+	// Drop any lease held on the task
+	err = r.releaseLease(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Remove the task from the tag index
+	err = r.untagTask(ctx, id, task.Tags)
 	if err != nil {
-		return fmt.Errorf("failed to reorder tasks: %w", err)
+		return err
+	}
+
+	// An orphaned task outlived its plan, so there is no plan status to update
+	planExists, err := r.client.client.SIsMember(withPrimaryReads(ctx), plansListKey, planID)
+	if err != nil {
+		return fmt.Errorf("failed to check if plan exists: %w", err)
+	}
+	if !planExists {
+		return nil
 	}
 
-	// Update the plan status based on the remaining tasks
-	err = r.UpdatePlanStatus(ctx, planID)
+	// Uncount the task and update the plan status based on the remaining tasks
+	err = r.recordTaskChange(ctx, planID, task.Status, "")
 	if err != nil {
 		// Log the error but don't fail the task deletion
 		fmt.Printf("Warning: failed to update plan status: %v\n", err)
@@ -219,6 +445,8 @@ func (r *TaskRepository) Delete(ctx context.Context, id string) error {
 
 // ListByPlan returns all tasks for a plan, ordered by their sequence
 func (r *TaskRepository) ListByPlan(ctx context.Context, planID string) ([]*models.Task, error) {
+	ctx = withReplicaReads(ctx)
+
 	// Check if the plan exists
 	exists, err := r.client.client.SIsMember(ctx, plansListKey, planID)
 	if err != nil {
@@ -226,7 +454,7 @@ func (r *TaskRepository) ListByPlan(ctx context.Context, planID string) ([]*mode
 	}
 
 	if !exists {
-		return nil, fmt.Errorf("plan not found: %s", planID)
+		return nil, models.NewNotFoundError(models.EntityPlan, planID)
 	}
 
 	// Get all task IDs for this plan
@@ -237,22 +465,74 @@ func (r *TaskRepository) ListByPlan(ctx context.Context, planID string) ([]*mode
 		return nil, fmt.Errorf("failed to get plan tasks: %w", err)
 	}
 
-	tasks := make([]*models.Task, 0, len(taskIDs))
+	tasks, err := r.getMany(ctx, taskIDs)
+	if err != nil {
+		return nil, err
+	}
 
-	// Get each task
-	for _, id := range taskIDs {
-		task, err := r.Get(ctx, id)
+	// The order of each task is its position in the plan
+	for i, task := range tasks {
+		task.Order = i
+	}
+
+	return tasks, nil
+}
+
+// StreamByPlan reads the tasks of a plan in plan order, chunkSize tasks at a time, and passes each chunk with
+// the position of its first task to emit, so a large plan is never held in memory as a whole. The task IDs are
+// read up front, so tasks added while streaming are left out. An error returned by emit stops the stream.
+func (r *TaskRepository) StreamByPlan(
+	ctx context.Context,
+	planID string,
+	chunkSize int,
+	emit func(offset int, tasks []*models.Task) error,
+) error {
+	ctx = withReplicaReads(ctx)
+	if chunkSize <= 0 {
+		return models.NewValidationError("", "", "invalid chunk size: %d", chunkSize)
+	}
+
+	exists, err := r.client.client.SIsMember(ctx, plansListKey, planID)
+	if err != nil {
+		return fmt.Errorf("failed to check if plan exists: %w", err)
+	}
+	if !exists {
+		return models.NewNotFoundError(models.EntityPlan, planID)
+	}
+
+	taskIDs, err := r.client.client.ZRange(ctx, GetPlanTasksKey(planID), options.NewRangeByIndexQuery(0, -1))
+	if err != nil {
+		return fmt.Errorf("failed to get plan tasks: %w", err)
+	}
+
+	for offset := 0; offset < len(taskIDs); offset += chunkSize {
+		tasks, err := r.getMany(ctx, taskIDs[offset:min(offset+chunkSize, len(taskIDs))])
 		if err != nil {
-			return nil, fmt.Errorf("failed to get task %s: %w", id, err)
+			return err
+		}
+		for i, task := range tasks {
+			task.Order = offset + i
+		}
+		if err := emit(offset, tasks); err != nil {
+			return err
 		}
-		tasks = append(tasks, task)
 	}
+	return nil
+}
 
-	return tasks, nil
+// CountByPlan returns the number of tasks in a plan
+func (r *TaskRepository) CountByPlan(ctx context.Context, planID string) (int64, error) {
+	count, err := r.client.client.ZCard(ctx, GetPlanTasksKey(planID))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get task count: %w", err)
+	}
+	return count, nil
 }
 
 // ListByStatus returns all tasks with the given status
 func (r *TaskRepository) ListByStatus(ctx context.Context, status models.TaskStatus) ([]*models.Task, error) {
+	ctx = withReplicaReads(ctx)
+
 	// Get all plan IDs
 	planIDs, err := r.client.client.SMembers(ctx, plansListKey)
 	if err != nil {
@@ -279,23 +559,36 @@ func (r *TaskRepository) ListByStatus(ctx context.Context, status models.TaskSta
 	return allTasks, nil
 }
 
-// ReorderTask changes the order of a task within its plan
+// ReorderTask changes the order of a task within its plan. The task gets a score between its new neighbors,
+// so the move is a single ZADD and the other tasks are not rewritten.
 func (r *TaskRepository) ReorderTask(ctx context.Context, taskID string, newOrder int) error {
+	ctx = withPrimaryReads(ctx)
+
 	// Get the task
 	task, err := r.Get(ctx, taskID)
 	if err != nil {
 		return fmt.Errorf("failed to get task: %w", err)
 	}
 
-	// Get all tasks for this plan to reorder them
-	tasks, err := r.ListByPlan(ctx, task.PlanID)
+	// Read the task again under the plan lock, it may have moved while waiting for the lock
+	ctx, unlock, err := r.client.lockPlan(ctx, task.PlanID)
 	if err != nil {
-		return fmt.Errorf("failed to list plan tasks: %w", err)
+		return err
+	}
+	defer unlock()
+	task, err = r.Get(ctx, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to get task: %w", err)
 	}
 
 	// Validate the new order
-	if newOrder < 0 || newOrder >= len(tasks) {
-		return fmt.Errorf("invalid order: %d (must be between 0 and %d)", newOrder, len(tasks)-1)
+	count, err := r.CountByPlan(ctx, task.PlanID)
+	if err != nil {
+		return err
+	}
+	if newOrder < 0 || newOrder >= int(count) {
+		return models.NewValidationError(models.EntityTask, taskID,
+			"invalid order: %d (must be between 0 and %d)", newOrder, count-1)
 	}
 
 	// If the order hasn't changed, do nothing
@@ -303,74 +596,199 @@ func (r *TaskRepository) ReorderTask(ctx context.Context, taskID string, newOrde
 		return nil
 	}
 
-	// Create a new slice of tasks with the reordered task at the new position
-	var reorderedTasks []*models.Task
-	oldOrder := task.Order
+	score, err := r.scoreAt(ctx, task.PlanID, task.ID, newOrder)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.client.ZAdd(ctx, GetPlanTasksKey(task.PlanID), map[string]float64{task.ID: score})
+	if err != nil {
+		return fmt.Errorf("failed to update task order in plan: %w", err)
+	}
 
-	// Handle moving a task to a later position
-	if newOrder > oldOrder {
-		// Add tasks before the old position
-		for i := 0; i < oldOrder; i++ {
-			reorderedTasks = append(reorderedTasks, tasks[i])
-		}
+	// Keep the stored order as a hint for integrity repairs
+	task.Order = newOrder
+	task.UpdatedAt = time.Now()
+	_, err = r.client.client.HSet(ctx, GetTaskKey(task.ID), map[string]string{
+		"order":      strconv.Itoa(task.Order),
+		"updated_at": task.UpdatedAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update task order: %w", err)
+	}
 
-		// Add tasks between old position and new position
-		for i := oldOrder + 1; i <= newOrder; i++ {
-			reorderedTasks = append(reorderedTasks, tasks[i])
-		}
+	return nil
+}
 
-		// Add the task being moved
-		reorderedTasks = append(reorderedTasks, task)
+// ReorderTasks puts the tasks of a plan in the given order. The IDs have to list every task of the plan exactly
+// once. The new order is written in a single ZADD, so readers see either the old or the new order.
+func (r *TaskRepository) ReorderTasks(ctx context.Context, planID string, taskIDs []string) ([]*models.Task, error) {
+	ctx = withPrimaryReads(ctx)
 
-		// Add remaining tasks
-		for i := newOrder + 1; i < len(tasks); i++ {
-			reorderedTasks = append(reorderedTasks, tasks[i])
-		}
-	} else { // Handle moving a task to an earlier position
-		// Add tasks before the new position
-		for i := 0; i < newOrder; i++ {
-			reorderedTasks = append(reorderedTasks, tasks[i])
-		}
+	ctx, unlock, err := r.client.lockPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-		// Add the task being moved
-		reorderedTasks = append(reorderedTasks, task)
+	exists, err := r.client.client.SIsMember(ctx, plansListKey, planID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if plan exists: %w", err)
+	}
+	if !exists {
+		return nil, models.NewNotFoundError(models.EntityPlan, planID)
+	}
 
-		// Add tasks between new position and old position
-		for i := newOrder; i < oldOrder; i++ {
-			reorderedTasks = append(reorderedTasks, tasks[i])
-		}
+	scores, err := r.planScores(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	inPlan := make(map[string]bool, len(scores))
+	for _, member := range scores {
+		inPlan[member.Member] = true
+	}
 
-		// Add remaining tasks
-		for i := oldOrder + 1; i < len(tasks); i++ {
-			reorderedTasks = append(reorderedTasks, tasks[i])
+	members := make(map[string]float64, len(taskIDs))
+	for i, taskID := range taskIDs {
+		if !inPlan[taskID] {
+			return nil, models.NewValidationError(models.EntityPlan, planID, "task %s is not in plan %s", taskID, planID)
+		}
+		if _, ok := members[taskID]; ok {
+			return nil, models.NewValidationError(models.EntityPlan, planID, "task %s is listed more than once", taskID)
 		}
+		members[taskID] = float64(i) * orderSpacing
+	}
+	if len(members) != len(scores) {
+		return nil, models.NewValidationError(models.EntityPlan, planID,
+			"the order lists %d of the %d tasks of the plan, it has to list all of them", len(members), len(scores))
 	}
 
-	// Update the orders of all tasks based on their new positions
-	for i, t := range reorderedTasks {
-		t.Order = i
-		t.UpdatedAt = time.Now()
-
-		// Store the updated task
-		taskKey := GetTaskKey(t.ID)
-		_, err = r.client.client.HSet(ctx, taskKey, t.ToMap())
+	if len(members) > 0 {
+		_, err = r.client.client.ZAdd(ctx, GetPlanTasksKey(planID), members)
 		if err != nil {
-			return fmt.Errorf("failed to update task order: %w", err)
+			return nil, fmt.Errorf("failed to update task order in plan: %w", err)
 		}
+	}
+
+	return r.ListByPlan(ctx, planID)
+}
+
+// MoveTask moves a task to the given position of another plan, or of its own plan. A negative position appends
+// the task. Both plans are locked while the task moves, the tasks after it in the source plan move up and
+// the tasks after its new position move down without being rewritten. The status of both plans is updated.
+// In the cluster key layout the task keeps its ID, so its keys stay in the slot of the plan it was created in.
+func (r *TaskRepository) MoveTask(ctx context.Context, taskID, planID string, position int) (*models.Task, error) {
+	ctx = withPrimaryReads(ctx)
+
+	task, err := r.Get(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	sourcePlanID := task.PlanID
 
-		// Update the task's score in the sorted set
-		planTasksKey := GetPlanTasksKey(task.PlanID)
-		_, err = r.client.client.ZAdd(ctx, planTasksKey, map[string]float64{t.ID: float64(t.Order)})
+	if sourcePlanID == planID {
+		count, err := r.CountByPlan(ctx, planID)
 		if err != nil {
-			return fmt.Errorf("failed to update task order in plan: %w", err)
+			return nil, err
 		}
+		if position < 0 {
+			position = int(count) - 1
+		}
+		if err := r.ReorderTask(ctx, taskID, position); err != nil {
+			return nil, err
+		}
+		return r.Get(ctx, taskID)
 	}
 
-	return nil
+	// Lock both plans in a fixed order, so two moves in opposite directions cannot wait for each other, and
+	// read the task again under the locks, it may have moved while waiting for them
+	ctx, task, unlock, err := r.lockTask(ctx, taskID, planID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if task.PlanID != sourcePlanID {
+		return nil, models.NewConflictError(models.EntityTask, taskID,
+			"task %s was moved to plan %s by another change, try again", taskID, task.PlanID)
+	}
+
+	exists, err := r.client.client.SIsMember(ctx, plansListKey, planID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if plan exists: %w", err)
+	}
+	if !exists {
+		return nil, models.NewNotFoundError(models.EntityPlan, planID)
+	}
+
+	count, err := r.CountByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	if position < 0 {
+		position = int(count)
+	}
+	if position > int(count) {
+		return nil, models.NewValidationError(models.EntityTask, taskID,
+			"invalid position: %d (must be between 0 and %d)", position, count)
+	}
+
+	score, err := r.scoreAt(ctx, planID, taskID, position)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the task to the target plan before removing it from the source plan, so a failure never leaves
+	// it out of both
+	_, err = r.client.client.ZAdd(ctx, GetPlanTasksKey(planID), map[string]float64{taskID: score})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add task to target plan: %w", err)
+	}
+	_, err = r.client.client.ZRem(ctx, GetPlanTasksKey(sourcePlanID), []string{taskID})
+	if err != nil {
+		r.client.client.ZRem(ctx, GetPlanTasksKey(planID), []string{taskID}) //nolint:errcheck
+		return nil, fmt.Errorf("failed to remove task from source plan: %w", err)
+	}
+
+	task.PlanID = planID
+	task.Order = position
+	task.UpdatedAt = time.Now()
+	_, err = r.client.client.HSet(ctx, GetTaskKey(taskID), map[string]string{
+		"plan_id":    task.PlanID,
+		"order":      strconv.Itoa(task.Order),
+		"updated_at": task.UpdatedAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update task plan: %w", err)
+	}
+
+	err = r.recordTaskChange(ctx, sourcePlanID, task.Status, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to update plan status: %w", err)
+	}
+	err = r.recordTaskChange(ctx, planID, "", task.Status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update plan status: %w", err)
+	}
+
+	return task, nil
 }
 
 // CreateBulk adds multiple tasks to a plan in a single operation
 func (r *TaskRepository) CreateBulk(ctx context.Context, planID string, taskInputs []TaskCreateInput) ([]*models.Task, error) {
+	for _, input := range taskInputs {
+		if input.Status != "" && !models.IsValidTaskStatus(input.Status) {
+			return nil, models.ValidateTaskStatus(input.Status)
+		}
+		if input.Priority != "" && !models.IsValidTaskPriority(input.Priority) {
+			return nil, models.ValidateTaskPriority(input.Priority)
+		}
+	}
+
+	ctx, unlock, err := r.client.lockPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	// Check if the plan exists
 	exists, err := r.client.client.SIsMember(ctx, plansListKey, planID)
 	if err != nil {
@@ -378,21 +796,25 @@ func (r *TaskRepository) CreateBulk(ctx context.Context, planID string, taskInpu
 	}
 
 	if !exists {
-		return nil, fmt.Errorf("plan not found: %s", planID)
+		return nil, models.NewNotFoundError(models.EntityPlan, planID)
 	}
 
-	// Get the next order value for the first task
+	// Append the tasks after the last task of the plan
 	planTasksKey := GetPlanTasksKey(planID)
 	count, err := r.client.client.ZCard(ctx, planTasksKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get task count: %w", err)
 	}
+	firstScore, err := r.appendScore(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create all tasks
 	createdTasks := make([]*models.Task, 0, len(taskInputs))
 	for i, input := range taskInputs {
 		// Generate a unique ID for the task
-		id := uuid.New().String()
+		id := newTaskID(planID)
 
 		// Set default values if not provided
 		priority := input.Priority
@@ -407,17 +829,22 @@ func (r *TaskRepository) CreateBulk(ctx context.Context, planID string, taskInpu
 
 		description := input.Description
 		if description == "" {
-			description = "no description provided"
+			description = DefaultTaskDescription
 		}
 
 		// Create a new task
 		task := models.NewTask(id, planID, input.Title, description, priority)
 		task.Status = status
+		task.Estimate = input.Estimate
 		task.Order = int(count) + i
+		task.RecordStatusTransition(models.TaskStatusPending, task.CreatedAt)
 
 		// Store the task in Valkey
 		taskKey := GetTaskKey(id)
-		_, err = r.client.client.HSet(ctx, taskKey, task.ToMap())
+		sealed, err := r.client.sealFields(task.ToMap())
+		if err == nil {
+			_, err = r.client.client.HSet(ctx, taskKey, sealed)
+		}
 		if err != nil {
 			// Try to clean up already created tasks
 			//nolint:errcheck
@@ -428,8 +855,9 @@ func (r *TaskRepository) CreateBulk(ctx context.Context, planID string, taskInpu
 			return nil, fmt.Errorf("failed to store task: %w", err)
 		}
 
-		// Add task to the plan's tasks list with its order as the score
-		_, err = r.client.client.ZAdd(ctx, planTasksKey, map[string]float64{id: float64(task.Order)})
+		// Add task to the plan's tasks list
+		score := firstScore + float64(i)*orderSpacing
+		_, err = r.client.client.ZAdd(ctx, planTasksKey, map[string]float64{id: score})
 		if err != nil {
 			// Try to clean up the task if adding to the sorted set fails
 			r.client.client.Del(ctx, []string{taskKey}) //nolint:errcheck
@@ -442,58 +870,23 @@ func (r *TaskRepository) CreateBulk(ctx context.Context, planID string, taskInpu
 			return nil, fmt.Errorf("failed to add task to plan: %w", err)
 		}
 
-		createdTasks = append(createdTasks, task)
-	}
-
-	// Update the plan status based on the new tasks
-	err = r.UpdatePlanStatus(ctx, planID)
-	if err != nil {
-		// Log the error but don't fail the task creation
-		fmt.Printf("Warning: failed to update plan status: %v\n", err)
-	}
-
-	return createdTasks, nil
-}
-
-// reorderPlanTasks updates the order of all tasks in a plan to ensure they are sequential
-func (r *TaskRepository) reorderPlanTasks(ctx context.Context, planID string) error {
-	// Get all tasks for the plan
-	tasks, err := r.ListByPlan(ctx, planID)
-	if err != nil {
-		return fmt.Errorf("failed to list tasks: %w", err)
-	}
-
-	// If there are no tasks, nothing to do
-	if len(tasks) == 0 {
-		return nil
-	}
-
-	// Update the order of each task
-	planTasksKey := GetPlanTasksKey(planID)
-	for i, task := range tasks {
-		// Update the task's order to match its position in the list (0-based)
-		task.Order = i
-		task.UpdatedAt = time.Now()
-
-		// Update the task in storage
-		taskKey := GetTaskKey(task.ID)
-		_, err := r.client.client.HSet(ctx, taskKey, task.ToMap())
+		// Count the task and update the plan status based on it
+		err = r.recordTaskChange(ctx, planID, "", task.Status)
 		if err != nil {
-			return fmt.Errorf("failed to update task order: %w", err)
+			// Log the error but don't fail the task creation
+			fmt.Printf("Warning: failed to update plan status: %v\n", err)
 		}
 
-		// Update the task's score in the sorted set
-		_, err = r.client.client.ZAdd(ctx, planTasksKey, map[string]float64{task.ID: float64(task.Order)})
-		if err != nil {
-			return fmt.Errorf("failed to update task order in plan: %w", err)
-		}
+		createdTasks = append(createdTasks, task)
 	}
 
-	return nil
+	return createdTasks, nil
 }
 
 // ListOrphanedTasks returns all tasks that reference a non-existent plan
 func (r *TaskRepository) ListOrphanedTasks(ctx context.Context) ([]*models.Task, error) {
+	ctx = withReplicaReads(ctx)
+
 	var orphanedTasks []*models.Task
 
 	// Get all task IDs
@@ -589,13 +982,10 @@ func (r *TaskRepository) ListByPlanAndStatus(
 	return filteredTasks, nil
 }
 
-// UpdatePlanStatus automatically updates a plan's status based on its tasks
+// UpdatePlanStatus automatically updates a plan's status based on its tasks. Task changes keep the status of
+// counted plans up to date themselves; this recounts the tasks of plans that are not counted yet.
 func (r *TaskRepository) UpdatePlanStatus(ctx context.Context, planID string) error {
-	// Get all tasks for the plan
-	tasks, err := r.ListByPlan(ctx, planID)
-	if err != nil {
-		return fmt.Errorf("failed to list tasks: %w", err)
-	}
+	ctx = withPrimaryReads(ctx)
 
 	// Get the plan repository
 	planRepo := &PlanRepository{client: r.client}
@@ -606,45 +996,20 @@ func (r *TaskRepository) UpdatePlanStatus(ctx context.Context, planID string) er
 		return fmt.Errorf("failed to get plan: %w", err)
 	}
 
-	var newStatus models.PlanStatus
-
-	// If there are no tasks, keep as "new"
-	if len(tasks) == 0 {
-		newStatus = models.PlanStatusNew
-	} else {
-		// Check if all tasks are completed
-		allCompleted := true
-		hasInProgress := false
-
-		for _, task := range tasks {
-			if task.Status == models.TaskStatusCompleted {
-				continue
-			} else if task.Status == models.TaskStatusInProgress {
-				allCompleted = false
-				hasInProgress = true
-			} else {
-				allCompleted = false
-			}
-		}
-
-		if allCompleted {
-			newStatus = models.PlanStatusCompleted
-		} else if hasInProgress {
-			newStatus = models.PlanStatusInProgress
-		} else {
-			// Has tasks but none are in progress, keep as "new"
-			newStatus = models.PlanStatusNew
+	// The status is derived from the task counters, which plans stored before counting existed get now
+	counts := plan.TaskCounts
+	if counts == nil {
+		counts, err = r.countTasks(ctx, planID)
+		if err != nil {
+			return err
 		}
 	}
 
+	newStatus := counts.PlanStatus()
+
 	// Only update if the status has changed
 	if plan.Status != newStatus {
-		plan.Status = newStatus
-		plan.UpdatedAt = time.Now()
-
-		// Save the updated plan
-		err = planRepo.Update(ctx, plan)
-		if err != nil {
+		if _, err := planRepo.UpdateStatus(ctx, planID, newStatus); err != nil {
 			return fmt.Errorf("failed to update plan status: %w", err)
 		}
 	}
@@ -652,22 +1017,35 @@ func (r *TaskRepository) UpdatePlanStatus(ctx context.Context, planID string) er
 	return nil
 }
 
-// UpdateNotes updates the notes for a task
+// SetNotesCompactor makes the repository archive the older part of notes that grow too long.
+// Nil keeps notes whole.
+func (r *TaskRepository) SetNotesCompactor(compactor *NotesCompactor) {
+	r.compactor = compactor
+}
+
+// UpdateNotes updates the notes for a task, archiving their older part if they are too long
 func (r *TaskRepository) UpdateNotes(ctx context.Context, id string, notes string) error {
-	// Get the task first to verify it exists
-	task, err := r.Get(ctx, id)
+	// Lock the task's plan; this also verifies the task exists
+	ctx, _, unlock, err := r.lockTask(ctx, id)
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	// Update the notes
-	task.Notes = notes
-	// Update the updated_at timestamp
-	task.UpdatedAt = time.Now()
+	notes, err = r.compactor.Compact(ctx, models.EntityTypeTask, id, notes)
+	if err != nil {
+		return err
+	}
 
-	// Store the updated task in Valkey
-	taskKey := GetTaskKey(task.ID)
-	_, err = r.client.client.HSet(ctx, taskKey, task.ToMap())
+	// Only the notes and the updated_at timestamp are written, so concurrent changes of the task are kept
+	sealed, err := r.client.sealFields(map[string]string{
+		"notes":      notes,
+		"updated_at": time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.client.client.HSet(ctx, GetTaskKey(id), sealed)
 	if err != nil {
 		return fmt.Errorf("failed to update task notes: %w", err)
 	}
@@ -685,3 +1063,104 @@ func (r *TaskRepository) GetNotes(ctx context.Context, id string) (string, error
 
 	return task.Notes, nil
 }
+
+// Restore replaces a task with a previously captured snapshot, recreating it if it was deleted.
+// Restored tasks never hold a lease.
+func (r *TaskRepository) Restore(ctx context.Context, task *models.Task) error {
+	ctx, unlock, err := r.client.lockPlan(ctx, task.PlanID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// The task can only be restored into an existing plan
+	exists, err := r.client.client.SIsMember(ctx, plansListKey, task.PlanID)
+	if err != nil {
+		return fmt.Errorf("failed to check if plan exists: %w", err)
+	}
+	if !exists {
+		return models.NewNotFoundError(models.EntityPlan, task.PlanID)
+	}
+
+	// A missing task is recreated; any other failure to read it aborts the restore
+	current, err := r.Get(withPrimaryReads(ctx), task.ID)
+	if models.IsNotFound(err) {
+		current = nil
+	} else if err != nil {
+		return err
+	}
+
+	task.ClearLease()
+	err = r.releaseLease(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+
+	// Replace the stored hash entirely so fields added since the snapshot disappear
+	sealed, err := r.client.sealFields(task.ToMap())
+	if err != nil {
+		return err
+	}
+	taskKey := GetTaskKey(task.ID)
+	_, err = r.client.client.Del(ctx, []string{taskKey})
+	if err != nil {
+		return fmt.Errorf("failed to clear task: %w", err)
+	}
+	_, err = r.client.client.HSet(ctx, taskKey, sealed)
+	if err != nil {
+		return fmt.Errorf("failed to restore task: %w", err)
+	}
+
+	if current != nil {
+		err = r.untagTask(ctx, task.ID, current.Tags)
+		if err != nil {
+			return err
+		}
+
+		// Take the task out of its current plan if the snapshot belongs to another one
+		if current.PlanID != task.PlanID {
+			_, err = r.client.client.ZRem(ctx, GetPlanTasksKey(current.PlanID), []string{task.ID})
+			if err != nil {
+				return fmt.Errorf("failed to remove task from current plan: %w", err)
+			}
+			err = r.recordTaskChange(ctx, current.PlanID, current.Status, "")
+			if err != nil {
+				return fmt.Errorf("failed to update plan status: %w", err)
+			}
+		}
+	}
+
+	if len(task.Tags) > 0 {
+		_, err = r.AddTags(ctx, task.ID, task.Tags)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = r.restoreChecklist(ctx, task.ID, task.Checklist)
+	if err != nil {
+		return err
+	}
+
+	// Put the task back at its position
+	score, err := r.scoreAt(ctx, task.PlanID, task.ID, task.Order)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.client.ZAdd(ctx, GetPlanTasksKey(task.PlanID), map[string]float64{task.ID: score})
+	if err != nil {
+		return fmt.Errorf("failed to add task to plan: %w", err)
+	}
+
+	// Count the task with the plan and status of the snapshot instead of its current ones
+	var from models.TaskStatus
+	if current != nil && current.PlanID == task.PlanID {
+		from = current.Status
+	}
+	err = r.recordTaskChange(ctx, task.PlanID, from, task.Status)
+	if err != nil {
+		return fmt.Errorf("failed to update plan status: %w", err)
+	}
+
+	return nil
+}
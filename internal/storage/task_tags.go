@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// AddTags adds tags to a task and indexes the task under each tag
+func (r *TaskRepository) AddTags(ctx context.Context, taskID string, tags []string) (*models.Task, error) {
+	// Get the task to verify it exists
+	_, err := r.Get(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags = models.NormalizeTags(tags)
+	if len(tags) == 0 {
+		return nil, models.NewValidationError(models.EntityTask, taskID, "at least one non-empty tag is required")
+	}
+
+	_, err = r.client.client.SAdd(ctx, GetTaskTagsKey(taskID), tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add task tags: %w", err)
+	}
+
+	// Maintain the reverse index so tasks can be looked up by tag
+	for _, tag := range tags {
+		_, err = r.client.client.SAdd(ctx, GetTagTasksKey(tag), []string{taskID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to index tag %s: %w", tag, err)
+		}
+	}
+
+	return r.Get(ctx, taskID)
+}
+
+// RemoveTags removes tags from a task and drops the task from each tag's index
+func (r *TaskRepository) RemoveTags(ctx context.Context, taskID string, tags []string) (*models.Task, error) {
+	// Get the task to verify it exists
+	_, err := r.Get(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.untagTask(ctx, taskID, models.NormalizeTags(tags))
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Get(ctx, taskID)
+}
+
+// ListByTag returns all tasks carrying the given tag, grouped by plan and ordered by their sequence
+func (r *TaskRepository) ListByTag(ctx context.Context, tag string) ([]*models.Task, error) {
+	ctx = withReplicaReads(ctx)
+
+	tags := models.NormalizeTags([]string{tag})
+	if len(tags) == 0 {
+		return nil, models.NewValidationError("", "", "tag is required")
+	}
+
+	taskIDs, err := r.client.client.SMembers(ctx, GetTagTasksKey(tags[0]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tagged tasks: %w", err)
+	}
+
+	tasks := make([]*models.Task, 0, len(taskIDs))
+	for taskID := range taskIDs {
+		task, err := r.Get(ctx, taskID)
+		if err != nil {
+			if models.IsNotFound(err) {
+				continue // Skip tasks deleted since they were indexed
+			}
+			return nil, fmt.Errorf("failed to get tagged task %s: %w", taskID, err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].PlanID != tasks[j].PlanID {
+			return tasks[i].PlanID < tasks[j].PlanID
+		}
+		return tasks[i].Order < tasks[j].Order
+	})
+
+	return tasks, nil
+}
+
+// getTags returns the sorted tags of a task
+func (r *TaskRepository) getTags(ctx context.Context, taskID string) ([]string, error) {
+	members, err := r.client.client.SMembers(ctx, GetTaskTagsKey(taskID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task tags: %w", err)
+	}
+
+	if len(members) == 0 {
+		return nil, nil
+	}
+
+	tags := make([]string, 0, len(members))
+	for tag := range members {
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+
+	return tags, nil
+}
+
+// untagTask removes already normalized tags from a task and from the reverse index
+func (r *TaskRepository) untagTask(ctx context.Context, taskID string, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	_, err := r.client.client.SRem(ctx, GetTaskTagsKey(taskID), tags)
+	if err != nil {
+		return fmt.Errorf("failed to remove task tags: %w", err)
+	}
+
+	for _, tag := range tags {
+		_, err = r.client.client.SRem(ctx, GetTagTasksKey(tag), []string{taskID})
+		if err != nil {
+			return fmt.Errorf("failed to unindex tag %s: %w", tag, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// taskwarriorTimeLayout is the UTC timestamp format of Taskwarrior JSON
+const taskwarriorTimeLayout = "20060102T150405Z"
+
+// TaskwarriorTask is a task in the JSON format of Taskwarrior's export and import commands
+type TaskwarriorTask struct {
+	UUID        string                  `json:"uuid,omitempty"`
+	Description string                  `json:"description"`
+	Status      string                  `json:"status"`
+	Entry       string                  `json:"entry,omitempty"`
+	Modified    string                  `json:"modified,omitempty"`
+	Start       string                  `json:"start,omitempty"`
+	End         string                  `json:"end,omitempty"`
+	Due         string                  `json:"due,omitempty"`
+	Priority    string                  `json:"priority,omitempty"`
+	Project     string                  `json:"project,omitempty"`
+	Tags        []string                `json:"tags,omitempty"`
+	Depends     TaskwarriorDepends      `json:"depends,omitempty"`
+	Annotations []TaskwarriorAnnotation `json:"annotations,omitempty"`
+}
+
+// TaskwarriorAnnotation is a timestamped note of a Taskwarrior task
+type TaskwarriorAnnotation struct {
+	Entry       string `json:"entry,omitempty"`
+	Description string `json:"description"`
+}
+
+// TaskwarriorDepends lists the UUIDs of the tasks a Taskwarrior task depends on. Taskwarrior 2.6 and later
+// write an array, older versions a comma separated string; both are read.
+type TaskwarriorDepends []string
+
+// UnmarshalJSON reads dependencies as an array or a comma separated string
+func (d *TaskwarriorDepends) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*d = list
+		return nil
+	}
+	var joined string
+	if err := json.Unmarshal(data, &joined); err != nil {
+		return errors.New("depends must be an array or a comma separated string of UUIDs")
+	}
+	*d = nil
+	for _, id := range strings.Split(joined, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			*d = append(*d, id)
+		}
+	}
+	return nil
+}
+
+var (
+	taskwarriorPriorities = map[models.TaskPriority]string{
+		models.TaskPriorityHigh:   "H",
+		models.TaskPriorityMedium: "M",
+		models.TaskPriorityLow:    "L",
+	}
+	taskwarriorPriorityValues = map[string]models.TaskPriority{
+		"H": models.TaskPriorityHigh,
+		"M": models.TaskPriorityMedium,
+		"L": models.TaskPriorityLow,
+	}
+)
+
+// NewTaskwarriorTasks converts tasks of a plan to Taskwarrior tasks of a project named after the plan.
+// Tasks in progress are pending tasks that were started, and cancelled tasks are deleted ones. The
+// description of a task becomes an annotation, leaving out the placeholder of tasks created without one.
+func NewTaskwarriorTasks(project string, tasks []*models.Task) []TaskwarriorTask {
+	converted := make([]TaskwarriorTask, 0, len(tasks))
+	for _, task := range tasks {
+		tw := TaskwarriorTask{
+			UUID:        task.ID,
+			Description: task.Title,
+			Status:      "pending",
+			Entry:       formatTaskwarriorTime(&task.CreatedAt),
+			Modified:    formatTaskwarriorTime(&task.UpdatedAt),
+			Due:         formatTaskwarriorTime(task.DueDate),
+			Priority:    taskwarriorPriorities[task.Priority],
+			Project:     project,
+			Tags:        task.Tags,
+			Depends:     task.DependsOn,
+		}
+
+		switch task.Status {
+		case models.TaskStatusInProgress:
+			tw.Start = formatTaskwarriorTime(task.StartedAt)
+			if tw.Start == "" {
+				tw.Start = tw.Modified
+			}
+		case models.TaskStatusCompleted:
+			tw.Status = "completed"
+			tw.End = formatTaskwarriorTime(task.CompletedAt)
+		case models.TaskStatusCancelled:
+			tw.Status = "deleted"
+		}
+		if tw.End == "" && tw.Status != "pending" {
+			tw.End = tw.Modified
+		}
+
+		if task.Description != "" && task.Description != DefaultTaskDescription {
+			tw.Annotations = []TaskwarriorAnnotation{{Entry: tw.Entry, Description: task.Description}}
+		}
+		converted = append(converted, tw)
+	}
+	return converted
+}
+
+// WriteTasksTaskwarrior writes tasks as a Taskwarrior JSON array that `task import` accepts
+func WriteTasksTaskwarrior(w io.Writer, project string, tasks []*models.Task) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(NewTaskwarriorTasks(project, tasks)); err != nil {
+		return fmt.Errorf("failed to write Taskwarrior tasks: %w", err)
+	}
+	return nil
+}
+
+// ReadTasksTaskwarrior reads task definitions from Taskwarrior JSON, either an array as written by
+// `task export` or one task object per line. The description becomes the title and the annotations the
+// description. Pending and waiting tasks are pending unless they were started, completed tasks are completed,
+// and deleted tasks are cancelled; recurring templates are skipped since their occurrences are tasks of their
+// own. Other fields are ignored.
+func ReadTasksTaskwarrior(r io.Reader) ([]TaskCreateInput, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Taskwarrior JSON: %w", err)
+	}
+
+	var tasks []TaskwarriorTask
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		if err := json.Unmarshal(data, &tasks); err != nil {
+			return nil, fmt.Errorf("failed to parse Taskwarrior JSON: %w", err)
+		}
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var task TaskwarriorTask
+			err := decoder.Decode(&task)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse task %d of the Taskwarrior JSON: %w", len(tasks)+1, err)
+			}
+			tasks = append(tasks, task)
+		}
+	}
+
+	inputs := make([]TaskCreateInput, 0, len(tasks))
+	for i, task := range tasks {
+		input := TaskCreateInput{Title: strings.TrimSpace(task.Description)}
+		if input.Title == "" {
+			return nil, fmt.Errorf("task %d of the Taskwarrior JSON has no description", i+1)
+		}
+
+		switch task.Status {
+		case "", "pending", "waiting":
+			input.Status = models.TaskStatusPending
+			if task.Start != "" {
+				input.Status = models.TaskStatusInProgress
+			}
+		case "completed":
+			input.Status = models.TaskStatusCompleted
+		case "deleted":
+			input.Status = models.TaskStatusCancelled
+		case "recurring":
+			continue
+		default:
+			return nil, fmt.Errorf("task %d of the Taskwarrior JSON has an invalid status: %s", i+1, task.Status)
+		}
+
+		if task.Priority != "" {
+			priority, ok := taskwarriorPriorityValues[strings.ToUpper(task.Priority)]
+			if !ok {
+				return nil, fmt.Errorf("task %d of the Taskwarrior JSON has an invalid priority: %s", i+1, task.Priority)
+			}
+			input.Priority = priority
+		}
+
+		annotations := make([]string, 0, len(task.Annotations))
+		for _, annotation := range task.Annotations {
+			if text := strings.TrimSpace(annotation.Description); text != "" {
+				annotations = append(annotations, text)
+			}
+		}
+		input.Description = strings.Join(annotations, "\n")
+
+		inputs = append(inputs, input)
+	}
+	return inputs, nil
+}
+
+// formatTaskwarriorTime formats an optional time as a Taskwarrior timestamp, or returns an empty string
+func formatTaskwarriorTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(taskwarriorTimeLayout)
+}
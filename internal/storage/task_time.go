@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// LogTime adds explicitly tracked time to a task's accumulated time spent
+func (r *TaskRepository) LogTime(ctx context.Context, taskID string, duration time.Duration) (*models.Task, error) {
+	seconds := int64(duration.Seconds())
+	if seconds <= 0 {
+		return nil, models.NewValidationError(models.EntityTask, taskID, "logged time must be at least one second, got %s", duration)
+	}
+
+	// Get the task to verify it exists
+	_, err := r.Get(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Increment atomically so concurrent workers can log time on the same task
+	taskKey := GetTaskKey(taskID)
+	_, err = r.client.client.HIncrBy(ctx, taskKey, "time_spent", seconds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to log task time: %w", err)
+	}
+
+	_, err = r.client.client.HSet(ctx, taskKey, map[string]string{"updated_at": time.Now().Format(time.RFC3339)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update task: %w", err)
+	}
+
+	return r.Get(ctx, taskID)
+}
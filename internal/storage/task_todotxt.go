@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// todo.txt keeps one task per line: an x for completed tasks, an optional (A) to (Z) priority, the completion
+// and creation dates, and the text with +project and @context tags and key:value pairs. todo.txt has no
+// states between open and done, so tasks in progress and cancelled tasks carry a status:in_progress or
+// status:cancelled pair. Completed tasks keep their priority in a pri: pair, as the priority is dropped when
+// a task is done.
+
+// todoTxtDateLayout is the date format of todo.txt
+const todoTxtDateLayout = "2006-01-02"
+
+var (
+	todoTxtPriorityPattern = regexp.MustCompile(`^\([A-Z]\)$`)
+	todoTxtKeyValuePattern = regexp.MustCompile(`^[^\s:]+:[^\s:/][^\s]*$`)
+
+	todoTxtPriorities = map[models.TaskPriority]string{
+		models.TaskPriorityHigh:   "A",
+		models.TaskPriorityMedium: "B",
+		models.TaskPriorityLow:    "C",
+	}
+)
+
+// WriteTasksTodoTxt writes tasks as todo.txt lines. Tags become +project tags and a due date a due: pair;
+// descriptions have no place in todo.txt and are left out.
+func WriteTasksTodoTxt(w io.Writer, tasks []*models.Task) error {
+	for _, task := range tasks {
+		var parts []string
+		done := task.Status == models.TaskStatusCompleted || task.Status == models.TaskStatusCancelled
+		if done {
+			end := task.UpdatedAt
+			if task.CompletedAt != nil {
+				end = *task.CompletedAt
+			}
+			parts = append(parts, "x", end.Format(todoTxtDateLayout))
+		} else if priority, ok := todoTxtPriorities[task.Priority]; ok {
+			parts = append(parts, "("+priority+")")
+		}
+		parts = append(parts, task.CreatedAt.Format(todoTxtDateLayout), strings.Join(strings.Fields(task.Title), " "))
+
+		for _, tag := range task.Tags {
+			parts = append(parts, "+"+strings.Join(strings.Fields(tag), "_"))
+		}
+		if task.DueDate != nil {
+			parts = append(parts, "due:"+task.DueDate.Format(todoTxtDateLayout))
+		}
+		if task.Status == models.TaskStatusInProgress || task.Status == models.TaskStatusCancelled {
+			parts = append(parts, "status:"+string(task.Status))
+		}
+		if priority, ok := todoTxtPriorities[task.Priority]; ok && done {
+			parts = append(parts, "pri:"+priority)
+		}
+
+		if _, err := fmt.Fprintln(w, strings.Join(parts, " ")); err != nil {
+			return fmt.Errorf("failed to write task %s: %w", task.ID, err)
+		}
+	}
+	return nil
+}
+
+// ReadTasksTodoTxt reads task definitions from todo.txt lines, skipping blank lines. The text becomes the
+// title without its +project and @context tags and key:value pairs. Priority A is high, B medium and the
+// others low; the status:, pri: pairs written by WriteTasksTodoTxt are read back and other pairs are
+// ignored.
+func ReadTasksTodoTxt(r io.Reader) ([]TaskCreateInput, error) {
+	var inputs []TaskCreateInput
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(strings.TrimPrefix(scanner.Text(), utf8BOM))
+		if len(fields) == 0 {
+			continue
+		}
+
+		input := TaskCreateInput{Status: models.TaskStatusPending}
+		if fields[0] == "x" {
+			input.Status = models.TaskStatusCompleted
+			fields = fields[1:]
+		} else if todoTxtPriorityPattern.MatchString(fields[0]) {
+			input.Priority = todoTxtPriority(fields[0][1:2])
+			fields = fields[1:]
+		}
+		// Up to two dates follow: the completion date of completed tasks and the creation date
+		for range 2 {
+			if len(fields) == 0 {
+				break
+			}
+			if _, err := time.Parse(todoTxtDateLayout, fields[0]); err != nil {
+				break
+			}
+			fields = fields[1:]
+		}
+
+		var words []string
+		for _, field := range fields {
+			switch {
+			case len(field) > 1 && (field[0] == '+' || field[0] == '@'):
+				continue
+			case todoTxtKeyValuePattern.MatchString(field):
+				key, value, _ := strings.Cut(field, ":")
+				switch key {
+				case "status":
+					status := models.TaskStatus(normalizeCSVValue(value))
+					if !models.IsValidTaskStatus(status) {
+						return nil, fmt.Errorf("todo.txt line %d has an invalid status: %s", line, value)
+					}
+					input.Status = status
+				case "pri":
+					input.Priority = todoTxtPriority(strings.ToUpper(value))
+				}
+			default:
+				words = append(words, field)
+			}
+		}
+
+		input.Title = strings.Join(words, " ")
+		if input.Title == "" {
+			return nil, fmt.Errorf("todo.txt line %d has no text", line)
+		}
+		inputs = append(inputs, input)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read todo.txt: %w", err)
+	}
+	return inputs, nil
+}
+
+// todoTxtPriority maps a todo.txt priority letter to a task priority
+func todoTxtPriority(letter string) models.TaskPriority {
+	switch letter {
+	case "A":
+		return models.TaskPriorityHigh
+	case "B":
+		return models.TaskPriorityMedium
+	default:
+		return models.TaskPriorityLow
+	}
+}
@@ -3,35 +3,367 @@ package storage
 import (
 	"context"
 	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/google/uuid"
 	glide "github.com/valkey-io/valkey-glide/go/v2"
 	"github.com/valkey-io/valkey-glide/go/v2/config"
+	glidemodels "github.com/valkey-io/valkey-glide/go/v2/models"
+	"github.com/valkey-io/valkey-glide/go/v2/options"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
 )
 
+// valkeyCommands is the set of commands used by the repositories. Both the standalone and the cluster
+// Valkey-Glide clients implement it; the cluster client routes each command to the node owning its key.
+type valkeyCommands interface {
+	Ping(ctx context.Context) (string, error)
+	Close()
+
+	Del(ctx context.Context, keys []string) (int64, error)
+	Exists(ctx context.Context, keys []string) (int64, error)
+	Type(ctx context.Context, key string) (string, error)
+	Get(ctx context.Context, key string) (glidemodels.Result[string], error)
+	SetWithOptions(ctx context.Context, key, value string, options options.SetOptions) (glidemodels.Result[string], error)
+	InvokeScriptWithOptions(ctx context.Context, script options.Script, scriptOptions options.ScriptOptions) (any, error)
+
+	HDel(ctx context.Context, key string, fields []string) (int64, error)
+	HGet(ctx context.Context, key, field string) (glidemodels.Result[string], error)
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	HIncrBy(ctx context.Context, key, field string, increment int64) (int64, error)
+	HSet(ctx context.Context, key string, values map[string]string) (int64, error)
+
+	LRange(ctx context.Context, key string, start, end int64) ([]string, error)
+	LRem(ctx context.Context, key string, count int64, element string) (int64, error)
+	LSet(ctx context.Context, key string, index int64, element string) (string, error)
+	RPush(ctx context.Context, key string, elements []string) (int64, error)
+
+	SAdd(ctx context.Context, key string, members []string) (int64, error)
+	SIsMember(ctx context.Context, key, member string) (bool, error)
+	SMembers(ctx context.Context, key string) (map[string]struct{}, error)
+	SRem(ctx context.Context, key string, members []string) (int64, error)
+
+	ZAdd(ctx context.Context, key string, membersScoreMap map[string]float64) (int64, error)
+	ZCard(ctx context.Context, key string) (int64, error)
+	ZRange(ctx context.Context, key string, rangeQuery options.ZRangeQuery) ([]string, error)
+	ZRangeWithScores(
+		ctx context.Context, key string, rangeQuery options.ZRangeQueryWithScores,
+	) ([]glidemodels.MemberAndScore, error)
+	ZRank(ctx context.Context, key, member string) (glidemodels.Result[int64], error)
+	ZRem(ctx context.Context, key string, members []string) (int64, error)
+
+	XAddWithOptions(
+		ctx context.Context, key string, values []glidemodels.FieldValue, options options.XAddOptions,
+	) (glidemodels.Result[string], error)
+	XRevRangeWithOptions(
+		ctx context.Context, key string, start, end options.StreamBoundary, opts options.XRangeOptions,
+	) ([]glidemodels.StreamEntry, error)
+	XTrim(ctx context.Context, key string, options options.XTrimOptions) (int64, error)
+}
+
 // ValkeyClient wraps the Valkey-Glide client for our application
 type ValkeyClient struct {
-	client *glide.Client
+	client *valkeyConnection
+	// stopWatch stops following Sentinel failovers
+	stopWatch context.CancelFunc
+	// cipher encrypts sensitive fields when field encryption is on
+	cipher *FieldCipher
+	// planLockTTL is how long a plan lock lasts without being renewed, zero for DefaultPlanLockTTL
+	planLockTTL time.Duration
+}
+
+// ValkeyAddress is the host and port of a Valkey node
+type ValkeyAddress struct {
+	Host string
+	Port int
+}
+
+// ValkeyConfig holds the settings used to connect to Valkey
+type ValkeyConfig struct {
+	// Addresses are the nodes to connect to. In cluster mode they are seed nodes used to discover the cluster;
+	// otherwise they are the primary and optionally its replicas, and the client works out which is which.
+	Addresses []ValkeyAddress
+	Username  string
+	Password  string
+	// Cluster connects to a Valkey Cluster instead of a standalone server
+	Cluster bool
+
+	// SentinelAddresses are Sentinels monitoring the primary named SentinelMaster. When set, the primary and
+	// its replicas are discovered through Sentinel instead of Addresses, and failovers are followed.
+	SentinelAddresses []ValkeyAddress
+	SentinelMaster    string
+	SentinelUsername  string
+	SentinelPassword  string
+
+	// ReadFromReplica sends the reads of list operations to replicas when there are any. Lists may then
+	// briefly miss the latest writes.
+	ReadFromReplica bool
+
+	// TLS connects to Valkey, and to Sentinel, over TLS
+	TLS bool
+	// TLSCAFile is a PEM file of CA certificates trusted instead of the system roots
+	TLSCAFile string
+	// TLSCertFile and TLSKeyFile are a client certificate presented to Valkey
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSInsecureSkipVerify accepts any server certificate; only meant for testing
+	TLSInsecureSkipVerify bool
+
+	// Database is the index of the logical database used on standalone servers
+	Database int
+
+	// PoolSize is the number of clients commands are spread over. Each client multiplexes commands over one
+	// connection per node, so more than one only helps under heavy load. Zero means one.
+	PoolSize int
+	// RequestTimeout bounds a single command, including reconnecting; zero uses the library default of 250ms
+	RequestTimeout time.Duration
+	// ConnectionTimeout bounds connecting to a node; zero uses the library default of 2s
+	ConnectionTimeout time.Duration
+	// ReconnectRetries is how many times a lost connection is retried with a growing delay before the delay
+	// stops growing; zero uses the library default
+	ReconnectRetries int
+	// ReconnectDelay is the delay before the first reconnect attempt, doubling on each further attempt
+	ReconnectDelay time.Duration
 }
 
 // NewValkeyClient creates a new Valkey client with the given connection options
 func NewValkeyClient(address string, port int, username, password string) (*ValkeyClient, error) {
-	clientConfig := config.NewClientConfiguration().
-		WithAddress(&config.NodeAddress{Host: address, Port: port})
+	return NewValkeyClientWithConfig(ValkeyConfig{
+		Addresses: []ValkeyAddress{{Host: address, Port: port}},
+		Username:  username,
+		Password:  password,
+	})
+}
 
-	if username != "" && password != "" {
-		clientConfig.WithCredentials(config.NewServerCredentials(username, password))
+// NewValkeyClientWithConfig creates a new Valkey client for a standalone, replicated, Sentinel-managed or
+// clustered deployment
+func NewValkeyClientWithConfig(cfg ValkeyConfig) (*ValkeyClient, error) {
+	if !cfg.TLS && cfg.customTLS() {
+		return nil, fmt.Errorf("failed to create Valkey client: TLS certificate settings require TLS to be enabled")
+	}
+	if len(cfg.SentinelAddresses) > 0 {
+		return newSentinelClient(cfg)
+	}
+	if len(cfg.Addresses) == 0 {
+		return nil, fmt.Errorf("failed to create Valkey client: no address given")
 	}
 
-	client, err := glide.NewClient(clientConfig)
+	primary, err := cfg.connectPool(cfg.Addresses, false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Valkey client: %w", err)
+		return nil, err
+	}
+
+	var replica []valkeyCommands
+	if cfg.ReadFromReplica {
+		replica, err = cfg.connectPool(cfg.Addresses, true)
+		if err != nil {
+			(&clientPair{primary: primary}).close()
+			return nil, err
+		}
 	}
 
 	return &ValkeyClient{
-		client: client,
+		client:    newValkeyConnection(primary, replica),
+		stopWatch: func() {},
 	}, nil
 }
 
+// newSentinelClient creates a client for the primary currently reported by Sentinel and follows its failovers
+func newSentinelClient(cfg ValkeyConfig) (*ValkeyClient, error) {
+	if cfg.Cluster {
+		return nil, fmt.Errorf("failed to create Valkey client: Sentinel cannot be used with cluster mode")
+	}
+	if cfg.SentinelMaster == "" {
+		return nil, fmt.Errorf("failed to create Valkey client: no Sentinel primary name given")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	primary, err := cfg.sentinelPrimary(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	primaryClient, replicaClient, err := cfg.connectSentinel(ctx, primary)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	vc := &ValkeyClient{
+		client: newValkeyConnection(primaryClient, replicaClient),
+		stopWatch: func() {
+			cancel()
+			<-done
+		},
+	}
+	go func() {
+		defer close(done)
+		vc.watchSentinel(ctx, cfg, primary)
+	}()
+	return vc, nil
+}
+
+// connectPool creates the pool of clients for the given nodes
+func (cfg ValkeyConfig) connectPool(addresses []ValkeyAddress, preferReplica bool) ([]valkeyCommands, error) {
+	pool := make([]valkeyCommands, 0, max(cfg.PoolSize, 1))
+	for len(pool) < cap(pool) {
+		client, err := cfg.connect(addresses, preferReplica)
+		if err != nil {
+			(&clientPair{primary: pool}).close()
+			return nil, err
+		}
+		pool = append(pool, client)
+	}
+	return pool, nil
+}
+
+// connect creates a Valkey-Glide client for the given nodes, reading from replicas if asked to
+func (cfg ValkeyConfig) connect(addresses []ValkeyAddress, preferReplica bool) (valkeyCommands, error) {
+	readFrom := config.Primary
+	if preferReplica {
+		readFrom = config.PreferReplica
+	}
+
+	// A password alone authenticates the default user
+	var credentials *config.ServerCredentials
+	if cfg.Username != "" && cfg.Password != "" {
+		credentials = config.NewServerCredentials(cfg.Username, cfg.Password)
+	} else if cfg.Password != "" {
+		credentials = config.NewServerCredentialsWithDefaultUsername(cfg.Password)
+	}
+
+	if cfg.Cluster {
+		if cfg.Database != 0 {
+			return nil, fmt.Errorf("failed to create Valkey client: cluster mode only supports database 0")
+		}
+		if cfg.TLS && cfg.customTLS() {
+			return nil, fmt.Errorf("failed to create Valkey client: " +
+				"cluster mode only supports TLS with certificates trusted by the system roots")
+		}
+
+		clusterConfig := config.NewClusterClientConfiguration().WithReadFrom(readFrom).WithUseTLS(cfg.TLS)
+		if cfg.RequestTimeout > 0 {
+			clusterConfig.WithRequestTimeout(cfg.RequestTimeout)
+		}
+		if cfg.ConnectionTimeout > 0 {
+			clusterConfig.WithAdvancedConfiguration(
+				config.NewAdvancedClusterClientConfiguration().WithConnectionTimeout(cfg.ConnectionTimeout))
+		}
+		if strategy := cfg.reconnectStrategy(); strategy != nil {
+			clusterConfig.WithReconnectStrategy(strategy)
+		}
+		for _, address := range addresses {
+			clusterConfig.WithAddress(&config.NodeAddress{Host: address.Host, Port: address.Port})
+		}
+		if credentials != nil {
+			clusterConfig.WithCredentials(credentials)
+		}
+		client, err := glide.NewClusterClient(clusterConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Valkey client: %w", err)
+		}
+		return client, nil
+	}
+
+	// The client library handles TLS with the system roots itself; anything else goes through tunnels
+	var tunnels []*tlsTunnel
+	closeTunnels := func() {
+		for _, tunnel := range tunnels {
+			tunnel.close()
+		}
+	}
+	if cfg.TLS && cfg.customTLS() {
+		tlsConfig, err := cfg.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		tunneled := make([]ValkeyAddress, 0, len(addresses))
+		for _, address := range addresses {
+			tunnel, err := startTLSTunnel(address, tlsConfig)
+			if err != nil {
+				closeTunnels()
+				return nil, err
+			}
+			tunnels = append(tunnels, tunnel)
+			tunneled = append(tunneled, tunnel.local)
+		}
+		addresses = tunneled
+	}
+
+	clientConfig := config.NewClientConfiguration().
+		WithReadFrom(readFrom).
+		WithUseTLS(cfg.TLS && len(tunnels) == 0).
+		WithDatabaseId(cfg.Database)
+	if cfg.RequestTimeout > 0 {
+		clientConfig.WithRequestTimeout(cfg.RequestTimeout)
+	}
+	if cfg.ConnectionTimeout > 0 {
+		clientConfig.WithAdvancedConfiguration(
+			config.NewAdvancedClientConfiguration().WithConnectionTimeout(cfg.ConnectionTimeout))
+	}
+	if strategy := cfg.reconnectStrategy(); strategy != nil {
+		clientConfig.WithReconnectStrategy(strategy)
+	}
+	for _, address := range addresses {
+		clientConfig.WithAddress(&config.NodeAddress{Host: address.Host, Port: address.Port})
+	}
+	if credentials != nil {
+		clientConfig.WithCredentials(credentials)
+	}
+	client, err := glide.NewClient(clientConfig)
+	if err != nil {
+		closeTunnels()
+		return nil, fmt.Errorf("failed to create Valkey client: %w", err)
+	}
+
+	if len(tunnels) > 0 {
+		return &tunneledClient{valkeyCommands: client, tunnels: tunnels}, nil
+	}
+	return client, nil
+}
+
+// reconnectStrategy returns the backoff used to reconnect lost connections, or nil for the library default.
+// The delay of attempt n is ReconnectDelay * 2^n.
+func (cfg ValkeyConfig) reconnectStrategy() *config.BackoffStrategy {
+	if cfg.ReconnectRetries <= 0 {
+		return nil
+	}
+	factor := max(int(cfg.ReconnectDelay.Milliseconds()), 1)
+	return config.NewBackoffStrategy(cfg.ReconnectRetries, factor, 2)
+}
+
+// ParseValkeyAddresses parses a comma-separated list of host:port node addresses.
+// Entries without a port use defaultPort.
+func ParseValkeyAddresses(list string, defaultPort int) ([]ValkeyAddress, error) {
+	var addresses []ValkeyAddress
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		host, portStr, err := net.SplitHostPort(entry)
+		if err != nil {
+			host, portStr = entry, strconv.Itoa(defaultPort)
+		}
+		port, err := strconv.Atoi(portStr)
+		if err != nil || port <= 0 || port > 65535 || host == "" {
+			return nil, fmt.Errorf("invalid Valkey address %q", entry)
+		}
+		addresses = append(addresses, ValkeyAddress{Host: host, Port: port})
+	}
+
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("no Valkey address given")
+	}
+	return addresses, nil
+}
+
 // Ping checks the connection to the Valkey server
 func (vc *ValkeyClient) Ping(ctx context.Context) error {
 	_, err := vc.client.Ping(ctx)
@@ -40,6 +372,7 @@ func (vc *ValkeyClient) Ping(ctx context.Context) error {
 
 // Close closes the Valkey client connection
 func (vc *ValkeyClient) Close() error {
+	vc.stopWatch()
 	vc.client.Close()
 	return nil
 }
@@ -53,16 +386,114 @@ const (
 	projectKeyPrefix = "project:"
 	projectsListKey  = "projects"
 
+	// Application keys
+	applicationKeyPrefix = "application:"
+	applicationsListKey  = "applications"
+
 	// Task keys
 	taskKeyPrefix   = "task:"
 	planTasksPrefix = "plan_tasks:"
 	// Legacy project tasks keys (kept for backward compatibility)
 	projectTasksPrefix = "project_tasks:"
+
+	// Task lease keys
+	taskLeasePrefix = "task_lease:"
+	taskLeasesKey   = "task_leases"
+
+	// Task tag keys
+	taskTagsPrefix = "task_tags:"
+	tagTasksPrefix = "tag_tasks:"
+
+	// Task checklist keys
+	taskChecklistPrefix = "task_checklist:"
+
+	// Task attachment keys
+	taskAttachmentsPrefix       = "task_attachments:"
+	taskAttachmentContentPrefix = "task_attachment_content:"
+
+	// Audit history keys
+	historyPrefix = "history:"
+
+	// Watch keys
+	planWatchersPrefix = "plan_watchers:"
+	notificationsKey   = "notifications"
+
+	// Change stream keys
+	changesKey = "changes"
+
+	// Notes history keys
+	planNotesHistoryPrefix = "plan_notes_history:"
+	notesArchivePrefix     = "notes_archive:"
+
+	// Completion record keys
+	planCompletionsPrefix = "plan_completions:"
+
+	// Agent activity keys
+	planActivityPrefix = "plan_activity:"
+
+	// Application status keys
+	applicationStatusPrefix = "application_status:"
+
+	// Idempotency keys
+	idempotencyPrefix = "idempotency:"
+
+	// Lock keys
+	planLockPrefix = "plan_lock:"
+	jobLockPrefix  = "job_lock:"
+	leaderKey      = "scheduler_leader"
+
+	// Schema keys
+	schemaVersionKey = "schema_version"
 )
 
+// hashTagLength is how many leading characters of an ID form its hash tag in the cluster key layout
+const hashTagLength = 8
+
+// clusterKeyLayout reports whether keys are laid out for Valkey Cluster
+var clusterKeyLayout atomic.Bool
+
+// SetClusterKeyLayout switches keys to the layout used with Valkey Cluster. Keys of a plan, its tasks and
+// their leases, tags, checklists and history carry a hash tag taken from the plan ID, so they all hash to
+// the same slot and live on the same node. The layout changes the key names, so it has to be chosen before
+// any data is written and cannot be switched on an existing keyspace.
+func SetClusterKeyLayout(enabled bool) {
+	clusterKeyLayout.Store(enabled)
+}
+
+// hashTag returns the hash tag of an ID in the cluster key layout
+func hashTag(id string) string {
+	if len(id) < hashTagLength {
+		return id
+	}
+	return id[:hashTagLength]
+}
+
+// keyID returns the ID part of a key, prefixed with the hash tag of the ID in the cluster key layout
+func keyID(id string) string {
+	if !clusterKeyLayout.Load() {
+		return id
+	}
+	return "{" + hashTag(id) + "}" + id
+}
+
+// newTaskID generates the ID of a new task of a plan. In the cluster key layout the ID starts with the
+// hash tag of the plan ID, so the keys of the task share the slot of its plan.
+func newTaskID(planID string) string {
+	id := uuid.New().String()
+	if !clusterKeyLayout.Load() || len(planID) < hashTagLength {
+		return id
+	}
+	return hashTag(planID) + id[hashTagLength:]
+}
+
 // GetPlanKey returns the key for a specific plan
 func GetPlanKey(planID string) string {
-	return planKeyPrefix + planID
+	return planKeyPrefix + keyID(planID)
+}
+
+// GetApplicationKey returns the key for a specific registered application
+func GetApplicationKey(applicationID string) string {
+	return applicationKeyPrefix + applicationID
 }
 
 // GetProjectKey returns the key for a specific project (legacy)
@@ -72,15 +503,95 @@ func GetProjectKey(projectID string) string {
 
 // GetTaskKey returns the key for a specific task
 func GetTaskKey(taskID string) string {
-	return taskKeyPrefix + taskID
+	return taskKeyPrefix + keyID(taskID)
 }
 
 // GetPlanTasksKey returns the key for a plan's tasks list
 func GetPlanTasksKey(planID string) string {
-	return planTasksPrefix + planID
+	return planTasksPrefix + keyID(planID)
 }
 
 // GetProjectTasksKey returns the key for a project's tasks list (legacy)
 func GetProjectTasksKey(projectID string) string {
 	return projectTasksPrefix + projectID
 }
+
+// GetTaskLeaseKey returns the key holding the lease for a claimed task
+func GetTaskLeaseKey(taskID string) string {
+	return taskLeasePrefix + keyID(taskID)
+}
+
+// GetTaskTagsKey returns the key for the set of tags on a task
+func GetTaskTagsKey(taskID string) string {
+	return taskTagsPrefix + keyID(taskID)
+}
+
+// GetTagTasksKey returns the key for the set of tasks carrying a tag
+func GetTagTasksKey(tag string) string {
+	return tagTasksPrefix + tag
+}
+
+// GetTaskChecklistKey returns the key for the ordered checklist of a task
+func GetTaskChecklistKey(taskID string) string {
+	return taskChecklistPrefix + keyID(taskID)
+}
+
+// GetTaskAttachmentsKey returns the key for the attachments of a task, without their content
+func GetTaskAttachmentsKey(taskID string) string {
+	return taskAttachmentsPrefix + keyID(taskID)
+}
+
+// GetTaskAttachmentContentKey returns the key for the content of the attachments of a task
+func GetTaskAttachmentContentKey(taskID string) string {
+	return taskAttachmentContentPrefix + keyID(taskID)
+}
+
+// GetHistoryKey returns the key for the audit history stream of a plan or task
+func GetHistoryKey(entityType models.EntityType, entityID string) string {
+	return historyPrefix + string(entityType) + ":" + keyID(entityID)
+}
+
+// GetPlanWatchersKey returns the Valkey key for the set of watchers of a plan
+func GetPlanWatchersKey(planID string) string {
+	return planWatchersPrefix + keyID(planID)
+}
+
+// GetPlanNotesHistoryKey returns the key of the stream holding the revisions of a plan's notes
+func GetPlanNotesHistoryKey(planID string) string {
+	return planNotesHistoryPrefix + keyID(planID)
+}
+
+// GetNotesArchiveKey returns the key holding the archived notes of a plan or task
+func GetNotesArchiveKey(entityType models.EntityType, entityID string) string {
+	return notesArchivePrefix + string(entityType) + ":" + keyID(entityID)
+}
+
+// GetPlanCompletionsKey returns the key of the list recording the completion notes of the tasks of a plan
+func GetPlanCompletionsKey(planID string) string {
+	return planCompletionsPrefix + keyID(planID)
+}
+
+// GetPlanActivityKey returns the key of the stream holding the agent activity journal of a plan
+func GetPlanActivityKey(planID string) string {
+	return planActivityPrefix + keyID(planID)
+}
+
+// GetApplicationStatusKey returns the key of the hash rolling the plans of an application up to its status
+func GetApplicationStatusKey(applicationID string) string {
+	return applicationStatusPrefix + applicationID
+}
+
+// GetIdempotencyKey returns the key remembering the result of a request with a client-chosen key
+func GetIdempotencyKey(scope, key string) string {
+	return idempotencyPrefix + scope + ":" + key
+}
+
+// GetPlanLockKey returns the key held while an operation rewrites the task order of a plan
+func GetPlanLockKey(planID string) string {
+	return planLockPrefix + keyID(planID)
+}
+
+// GetJobLockKey returns the key held by the server replica running a background job
+func GetJobLockKey(job string) string {
+	return jobLockPrefix + job
+}
@@ -0,0 +1,348 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	glidemodels "github.com/valkey-io/valkey-glide/go/v2/models"
+	"github.com/valkey-io/valkey-glide/go/v2/options"
+)
+
+// clientPair holds the clients commands are routed to
+type clientPair struct {
+	primary []valkeyCommands
+	// replica serves the reads of list operations; empty sends them to the primary
+	replica []valkeyCommands
+}
+
+// close closes all clients
+func (p *clientPair) close() {
+	for _, client := range p.primary {
+		client.Close()
+	}
+	for _, client := range p.replica {
+		client.Close()
+	}
+}
+
+// valkeyConnection routes commands to the primary, or for reads of list operations to a replica when one is
+// configured, spreading them over the clients of the pool. The clients can be replaced while commands are
+// running, which is how a Sentinel failover is followed.
+type valkeyConnection struct {
+	clients atomic.Pointer[clientPair]
+	next    atomic.Uint64
+}
+
+// newValkeyConnection creates a connection routing commands to the given clients
+func newValkeyConnection(primary, replica []valkeyCommands) *valkeyConnection {
+	conn := &valkeyConnection{}
+	conn.clients.Store(&clientPair{primary: primary, replica: replica})
+	return conn
+}
+
+// swap replaces the clients and returns the previous ones
+func (c *valkeyConnection) swap(primary, replica []valkeyCommands) *clientPair {
+	return c.clients.Swap(&clientPair{primary: primary, replica: replica})
+}
+
+// pick returns the next client of a pool
+func (c *valkeyConnection) pick(pool []valkeyCommands) valkeyCommands {
+	if len(pool) == 1 {
+		return pool[0]
+	}
+	return pool[c.next.Add(1)%uint64(len(pool))]
+}
+
+// writer returns the client for write commands
+func (c *valkeyConnection) writer() valkeyCommands {
+	return c.pick(c.clients.Load().primary)
+}
+
+// reader returns the client for read commands
+func (c *valkeyConnection) reader(ctx context.Context) valkeyCommands {
+	clients := c.clients.Load()
+	if len(clients.replica) > 0 && replicaReads(ctx) {
+		return c.pick(clients.replica)
+	}
+	return c.pick(clients.primary)
+}
+
+// readerOf returns the client for reads of a key, which are served by the stage of the operation while its
+// writes are staged
+func (c *valkeyConnection) readerOf(ctx context.Context, key string) valkeyCommands {
+	if stage := writeStageOf(ctx); stage != nil {
+		return stage.reader(key)
+	}
+	return c.reader(ctx)
+}
+
+// stagedResult converts the result of a staged write to the result type of its command
+func stagedResult[T any](result any, err error) (T, error) {
+	var zero T
+	if err != nil || result == nil {
+		return zero, err
+	}
+	typed, ok := result.(T)
+	if !ok {
+		return zero, fmt.Errorf("unexpected result %T of a staged write", result)
+	}
+	return typed, nil
+}
+
+// readPreferenceKey is the context key for the read preference of a repository operation
+type readPreferenceKey struct{}
+
+// withReplicaReads lets the reads of a list operation go to a replica, unless the caller already pinned
+// them to the primary
+func withReplicaReads(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(readPreferenceKey{}).(bool); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, readPreferenceKey{}, true)
+}
+
+// withPrimaryReads pins reads to the primary, for operations that write based on what they read
+func withPrimaryReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readPreferenceKey{}, false)
+}
+
+// replicaReads reports whether reads may go to a replica
+func replicaReads(ctx context.Context) bool {
+	replica, ok := ctx.Value(readPreferenceKey{}).(bool)
+	return ok && replica
+}
+
+// Ping checks the connection to the primary
+func (c *valkeyConnection) Ping(ctx context.Context) (string, error) {
+	return c.writer().Ping(ctx)
+}
+
+// Close closes the clients
+func (c *valkeyConnection) Close() {
+	c.clients.Load().close()
+}
+
+func (c *valkeyConnection) Del(ctx context.Context, keys []string) (int64, error) {
+	if stage := writeStageOf(ctx); stage != nil {
+		return stagedResult[int64](stage.write(ctx, keys, func(q writeQueue) { q.Del(keys) }))
+	}
+	if err := lostPlanLock(ctx); err != nil {
+		return 0, err
+	}
+	return c.writer().Del(ctx, keys)
+}
+
+func (c *valkeyConnection) Exists(ctx context.Context, keys []string) (int64, error) {
+	if writeStageOf(ctx) == nil {
+		return c.reader(ctx).Exists(ctx, keys)
+	}
+	var count int64
+	for _, key := range keys {
+		exists, err := c.readerOf(ctx, key).Exists(ctx, []string{key})
+		if err != nil {
+			return 0, err
+		}
+		count += exists
+	}
+	return count, nil
+}
+
+func (c *valkeyConnection) Get(ctx context.Context, key string) (glidemodels.Result[string], error) {
+	return c.readerOf(ctx, key).Get(ctx, key)
+}
+
+func (c *valkeyConnection) SetWithOptions(
+	ctx context.Context, key, value string, options options.SetOptions,
+) (glidemodels.Result[string], error) {
+	if stage := writeStageOf(ctx); stage != nil {
+		return stagedResult[glidemodels.Result[string]](stage.write(ctx, []string{key}, func(q writeQueue) { q.SetWithOptions(key, value, options) }))
+	}
+	if err := lostPlanLock(ctx); err != nil {
+		return glidemodels.CreateNilStringResult(), err
+	}
+	return c.writer().SetWithOptions(ctx, key, value, options)
+}
+
+func (c *valkeyConnection) InvokeScriptWithOptions(
+	ctx context.Context, script options.Script, scriptOptions options.ScriptOptions,
+) (any, error) {
+	if stage := writeStageOf(ctx); stage != nil {
+		return stagedResult[any](stage.write(ctx, scriptOptions.Keys, func(q writeQueue) { q.InvokeScript(script, scriptOptions) }))
+	}
+	if err := lostPlanLock(ctx); err != nil {
+		return nil, err
+	}
+	return c.writer().InvokeScriptWithOptions(ctx, script, scriptOptions)
+}
+
+func (c *valkeyConnection) HDel(ctx context.Context, key string, fields []string) (int64, error) {
+	if stage := writeStageOf(ctx); stage != nil {
+		return stagedResult[int64](stage.write(ctx, []string{key}, func(q writeQueue) { q.HDel(key, fields) }))
+	}
+	if err := lostPlanLock(ctx); err != nil {
+		return 0, err
+	}
+	return c.writer().HDel(ctx, key, fields)
+}
+
+func (c *valkeyConnection) HGet(ctx context.Context, key, field string) (glidemodels.Result[string], error) {
+	return c.readerOf(ctx, key).HGet(ctx, key, field)
+}
+
+func (c *valkeyConnection) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return c.readerOf(ctx, key).HGetAll(ctx, key)
+}
+
+func (c *valkeyConnection) HIncrBy(ctx context.Context, key, field string, increment int64) (int64, error) {
+	if stage := writeStageOf(ctx); stage != nil {
+		return stagedResult[int64](stage.write(ctx, []string{key}, func(q writeQueue) { q.HIncrBy(key, field, increment) }))
+	}
+	if err := lostPlanLock(ctx); err != nil {
+		return 0, err
+	}
+	return c.writer().HIncrBy(ctx, key, field, increment)
+}
+
+func (c *valkeyConnection) HSet(ctx context.Context, key string, values map[string]string) (int64, error) {
+	if stage := writeStageOf(ctx); stage != nil {
+		return stagedResult[int64](stage.write(ctx, []string{key}, func(q writeQueue) { q.HSet(key, values) }))
+	}
+	if err := lostPlanLock(ctx); err != nil {
+		return 0, err
+	}
+	return c.writer().HSet(ctx, key, values)
+}
+
+func (c *valkeyConnection) LRange(ctx context.Context, key string, start, end int64) ([]string, error) {
+	return c.readerOf(ctx, key).LRange(ctx, key, start, end)
+}
+
+func (c *valkeyConnection) LRem(ctx context.Context, key string, count int64, element string) (int64, error) {
+	if stage := writeStageOf(ctx); stage != nil {
+		return stagedResult[int64](stage.write(ctx, []string{key}, func(q writeQueue) { q.LRem(key, count, element) }))
+	}
+	if err := lostPlanLock(ctx); err != nil {
+		return 0, err
+	}
+	return c.writer().LRem(ctx, key, count, element)
+}
+
+func (c *valkeyConnection) LSet(ctx context.Context, key string, index int64, element string) (string, error) {
+	if stage := writeStageOf(ctx); stage != nil {
+		return stagedResult[string](stage.write(ctx, []string{key}, func(q writeQueue) { q.LSet(key, index, element) }))
+	}
+	if err := lostPlanLock(ctx); err != nil {
+		return "", err
+	}
+	return c.writer().LSet(ctx, key, index, element)
+}
+
+func (c *valkeyConnection) RPush(ctx context.Context, key string, elements []string) (int64, error) {
+	if stage := writeStageOf(ctx); stage != nil {
+		return stagedResult[int64](stage.write(ctx, []string{key}, func(q writeQueue) { q.RPush(key, elements) }))
+	}
+	if err := lostPlanLock(ctx); err != nil {
+		return 0, err
+	}
+	return c.writer().RPush(ctx, key, elements)
+}
+
+func (c *valkeyConnection) SAdd(ctx context.Context, key string, members []string) (int64, error) {
+	if stage := writeStageOf(ctx); stage != nil {
+		return stagedResult[int64](stage.write(ctx, []string{key}, func(q writeQueue) { q.SAdd(key, members) }))
+	}
+	if err := lostPlanLock(ctx); err != nil {
+		return 0, err
+	}
+	return c.writer().SAdd(ctx, key, members)
+}
+
+func (c *valkeyConnection) SIsMember(ctx context.Context, key, member string) (bool, error) {
+	return c.readerOf(ctx, key).SIsMember(ctx, key, member)
+}
+
+func (c *valkeyConnection) SMembers(ctx context.Context, key string) (map[string]struct{}, error) {
+	return c.readerOf(ctx, key).SMembers(ctx, key)
+}
+
+func (c *valkeyConnection) SRem(ctx context.Context, key string, members []string) (int64, error) {
+	if stage := writeStageOf(ctx); stage != nil {
+		return stagedResult[int64](stage.write(ctx, []string{key}, func(q writeQueue) { q.SRem(key, members) }))
+	}
+	if err := lostPlanLock(ctx); err != nil {
+		return 0, err
+	}
+	return c.writer().SRem(ctx, key, members)
+}
+
+func (c *valkeyConnection) ZAdd(ctx context.Context, key string, membersScoreMap map[string]float64) (int64, error) {
+	if stage := writeStageOf(ctx); stage != nil {
+		return stagedResult[int64](stage.write(ctx, []string{key}, func(q writeQueue) { q.ZAdd(key, membersScoreMap) }))
+	}
+	if err := lostPlanLock(ctx); err != nil {
+		return 0, err
+	}
+	return c.writer().ZAdd(ctx, key, membersScoreMap)
+}
+
+func (c *valkeyConnection) ZCard(ctx context.Context, key string) (int64, error) {
+	return c.readerOf(ctx, key).ZCard(ctx, key)
+}
+
+func (c *valkeyConnection) ZRange(ctx context.Context, key string, rangeQuery options.ZRangeQuery) ([]string, error) {
+	return c.readerOf(ctx, key).ZRange(ctx, key, rangeQuery)
+}
+
+func (c *valkeyConnection) ZRangeWithScores(
+	ctx context.Context, key string, rangeQuery options.ZRangeQueryWithScores,
+) ([]glidemodels.MemberAndScore, error) {
+	return c.readerOf(ctx, key).ZRangeWithScores(ctx, key, rangeQuery)
+}
+
+func (c *valkeyConnection) ZRank(ctx context.Context, key, member string) (glidemodels.Result[int64], error) {
+	return c.readerOf(ctx, key).ZRank(ctx, key, member)
+}
+
+func (c *valkeyConnection) ZRem(ctx context.Context, key string, members []string) (int64, error) {
+	if stage := writeStageOf(ctx); stage != nil {
+		return stagedResult[int64](stage.write(ctx, []string{key}, func(q writeQueue) { q.ZRem(key, members) }))
+	}
+	if err := lostPlanLock(ctx); err != nil {
+		return 0, err
+	}
+	return c.writer().ZRem(ctx, key, members)
+}
+
+func (c *valkeyConnection) XAddWithOptions(
+	ctx context.Context, key string, values []glidemodels.FieldValue, options options.XAddOptions,
+) (glidemodels.Result[string], error) {
+	if stage := writeStageOf(ctx); stage != nil {
+		id, err := stage.writeStream(key, func(q writeQueue) { q.XAddWithOptions(key, values, options) })
+		if err != nil {
+			return glidemodels.CreateNilStringResult(), err
+		}
+		return glidemodels.CreateStringResult(id), nil
+	}
+	if err := lostPlanLock(ctx); err != nil {
+		return glidemodels.CreateNilStringResult(), err
+	}
+	return c.writer().XAddWithOptions(ctx, key, values, options)
+}
+
+func (c *valkeyConnection) XRevRangeWithOptions(
+	ctx context.Context, key string, start, end options.StreamBoundary, opts options.XRangeOptions,
+) ([]glidemodels.StreamEntry, error) {
+	return c.readerOf(ctx, key).XRevRangeWithOptions(ctx, key, start, end, opts)
+}
+
+func (c *valkeyConnection) XTrim(ctx context.Context, key string, options options.XTrimOptions) (int64, error) {
+	if stage := writeStageOf(ctx); stage != nil {
+		_, err := stage.writeStream(key, func(q writeQueue) { q.XTrim(key, options) })
+		return 0, err
+	}
+	if err := lostPlanLock(ctx); err != nil {
+		return 0, err
+	}
+	return c.writer().XTrim(ctx, key, options)
+}
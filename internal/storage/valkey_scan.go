@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	glide "github.com/valkey-io/valkey-glide/go/v2"
+	glidemodels "github.com/valkey-io/valkey-glide/go/v2/models"
+	"github.com/valkey-io/valkey-glide/go/v2/options"
+)
+
+// scanBatchSize is the number of keys asked for in each SCAN call
+const scanBatchSize = 1000
+
+// keyScanner is implemented by clients that list their keys themselves, like the in-memory store
+type keyScanner interface {
+	scanKeys(ctx context.Context, match string) ([]string, error)
+}
+
+// scanKeys returns the keys matching a glob-style pattern on the primary, or on every primary of a cluster.
+// Scanning walks the whole keyspace, so it is meant for maintenance rather than regular operations.
+func (c *valkeyConnection) scanKeys(ctx context.Context, match string) ([]string, error) {
+	return scanClientKeys(ctx, c.writer(), match)
+}
+
+// scanClientKeys scans the keys of a single client. SCAN may return a key more than once, so the keys are
+// deduplicated.
+func scanClientKeys(ctx context.Context, client valkeyCommands, match string) ([]string, error) {
+	seen := make(map[string]struct{})
+	var keys []string
+	add := func(batch []string) {
+		for _, key := range batch {
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	switch client := client.(type) {
+	case *glide.Client:
+		opts := options.NewScanOptions().SetMatch(match).SetCount(scanBatchSize)
+		cursor := glidemodels.NewCursor()
+		for !cursor.IsFinished() {
+			result, err := client.ScanWithOptions(ctx, cursor, *opts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to scan keys: %w", err)
+			}
+			add(result.Data)
+			cursor = result.Cursor
+		}
+	case *glide.ClusterClient:
+		opts := options.NewClusterScanOptions().SetMatch(match).SetCount(scanBatchSize)
+		cursor := glidemodels.NewClusterScanCursor()
+		for !cursor.IsFinished() {
+			result, err := client.ScanWithOptions(ctx, cursor, *opts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to scan keys: %w", err)
+			}
+			add(result.Keys)
+			cursor = result.Cursor
+		}
+	case *tunneledClient:
+		return scanClientKeys(ctx, client.valkeyCommands, match)
+	case keyScanner:
+		batch, err := client.scanKeys(ctx, match)
+		if err != nil {
+			return nil, err
+		}
+		add(batch)
+	default:
+		return nil, fmt.Errorf("failed to scan keys: %T does not support scanning", client)
+	}
+	return keys, nil
+}
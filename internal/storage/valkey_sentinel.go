@@ -0,0 +1,271 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultSentinelPort is the port used for Sentinel addresses without one
+	DefaultSentinelPort = 26379
+	// sentinelCheckInterval is how often Sentinel is asked for the current primary
+	sentinelCheckInterval = 2 * time.Second
+	// sentinelTimeout bounds a single request to a Sentinel
+	sentinelTimeout = 2 * time.Second
+)
+
+// sentinelPrimary asks the configured Sentinels for the address of the primary, trying each in turn
+func (cfg ValkeyConfig) sentinelPrimary(ctx context.Context) (ValkeyAddress, error) {
+	var errs []error
+	for _, sentinel := range cfg.SentinelAddresses {
+		reply, err := cfg.sentinelCommand(ctx, sentinel, "SENTINEL", "GET-MASTER-ADDR-BY-NAME", cfg.SentinelMaster)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		fields, ok := reply.([]any)
+		if !ok || len(fields) != 2 {
+			errs = append(errs, fmt.Errorf("sentinel %s does not know primary %q", sentinel.Host, cfg.SentinelMaster))
+			continue
+		}
+		host, ok := fields[0].(string)
+		port, err := strconv.Atoi(fmt.Sprint(fields[1]))
+		if !ok || host == "" || err != nil {
+			errs = append(errs, fmt.Errorf("sentinel %s returned an invalid primary address", sentinel.Host))
+			continue
+		}
+		return ValkeyAddress{Host: host, Port: port}, nil
+	}
+
+	return ValkeyAddress{}, fmt.Errorf(
+		"failed to discover primary %q through Sentinel: %w", cfg.SentinelMaster, errors.Join(errs...))
+}
+
+// sentinelReplicas asks the configured Sentinels for the healthy replicas of the primary
+func (cfg ValkeyConfig) sentinelReplicas(ctx context.Context) ([]ValkeyAddress, error) {
+	var errs []error
+	for _, sentinel := range cfg.SentinelAddresses {
+		reply, err := cfg.sentinelCommand(ctx, sentinel, "SENTINEL", "REPLICAS", cfg.SentinelMaster)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		entries, ok := reply.([]any)
+		if !ok {
+			errs = append(errs, fmt.Errorf("sentinel %s does not know primary %q", sentinel.Host, cfg.SentinelMaster))
+			continue
+		}
+		var replicas []ValkeyAddress
+		for _, entry := range entries {
+			// Each replica is a flat list of field names and values
+			fieldList, ok := entry.([]any)
+			if !ok {
+				continue
+			}
+			fields := make(map[string]string, len(fieldList)/2)
+			for i := 0; i+1 < len(fieldList); i += 2 {
+				fields[fmt.Sprint(fieldList[i])] = fmt.Sprint(fieldList[i+1])
+			}
+
+			flags := fields["flags"]
+			if strings.Contains(flags, "s_down") || strings.Contains(flags, "o_down") ||
+				strings.Contains(flags, "disconnected") {
+				continue
+			}
+			port, err := strconv.Atoi(fields["port"])
+			if fields["ip"] == "" || err != nil {
+				continue
+			}
+			replicas = append(replicas, ValkeyAddress{Host: fields["ip"], Port: port})
+		}
+		return replicas, nil
+	}
+
+	return nil, fmt.Errorf("failed to discover replicas of %q through Sentinel: %w", cfg.SentinelMaster, errors.Join(errs...))
+}
+
+// sentinelCommand sends a single command to a Sentinel and returns its decoded reply
+func (cfg ValkeyConfig) sentinelCommand(ctx context.Context, sentinel ValkeyAddress, args ...string) (any, error) {
+	ctx, cancel := context.WithTimeout(ctx, sentinelTimeout)
+	defer cancel()
+
+	address := net.JoinHostPort(sentinel.Host, strconv.Itoa(sentinel.Port))
+	var conn net.Conn
+	var err error
+	if cfg.TLS {
+		var tlsConfig *tls.Config
+		tlsConfig, err = cfg.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ServerName = sentinel.Host
+		dialer := &tls.Dialer{Config: tlsConfig}
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to sentinel %s: %w", address, err)
+	}
+	defer conn.Close() //nolint:errcheck
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	reader := bufio.NewReader(conn)
+	if cfg.SentinelPassword != "" {
+		auth := []string{"AUTH", cfg.SentinelPassword}
+		if cfg.SentinelUsername != "" {
+			auth = []string{"AUTH", cfg.SentinelUsername, cfg.SentinelPassword}
+		}
+		if _, err := exchangeRESP(conn, reader, auth); err != nil {
+			return nil, fmt.Errorf("failed to authenticate with sentinel %s: %w", address, err)
+		}
+	}
+
+	reply, err := exchangeRESP(conn, reader, args)
+	if err != nil {
+		return nil, fmt.Errorf("sentinel %s: %w", address, err)
+	}
+	return reply, nil
+}
+
+// exchangeRESP writes a command in the RESP protocol and reads its reply
+func exchangeRESP(w io.Writer, r *bufio.Reader, args []string) (any, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return nil, err
+	}
+	return readRESP(r)
+}
+
+// readRESP reads a RESP2 reply. Nil replies are returned as nil, arrays as []any and all scalars as strings.
+func readRESP(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("empty reply")
+	}
+
+	switch line[0] {
+	case '+', ':':
+		return line[1:], nil
+	case '-':
+		return nil, errors.New(line[1:])
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid bulk string length %q", line[1:])
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid array length %q", line[1:])
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]any, count)
+		for i := range items {
+			if items[i], err = readRESP(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unexpected reply %q", line)
+	}
+}
+
+// watchSentinel follows failovers until ctx is cancelled. When Sentinel reports a new primary, clients for
+// it are created and swapped in; commands still running on the old clients fail and can be retried.
+func (vc *ValkeyClient) watchSentinel(ctx context.Context, cfg ValkeyConfig, primary ValkeyAddress) {
+	ticker := time.NewTicker(sentinelCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current, err := cfg.sentinelPrimary(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Sentinel check failed: %v", err)
+			}
+			continue
+		}
+		if current == primary {
+			continue
+		}
+
+		primaryClient, replicaClient, err := cfg.connectSentinel(ctx, current)
+		if err != nil {
+			log.Printf("Failed to connect to new primary %s:%d: %v", current.Host, current.Port, err)
+			continue
+		}
+		vc.client.swap(primaryClient, replicaClient).close()
+		log.Printf("Valkey primary %q failed over from %s:%d to %s:%d",
+			cfg.SentinelMaster, primary.Host, primary.Port, current.Host, current.Port)
+		primary = current
+	}
+}
+
+// connectSentinel creates the clients for a primary discovered through Sentinel, plus clients spreading
+// reads over its replicas when reading from replicas is enabled
+func (cfg ValkeyConfig) connectSentinel(
+	ctx context.Context,
+	primary ValkeyAddress,
+) ([]valkeyCommands, []valkeyCommands, error) {
+	primaryClients, err := cfg.connectPool([]ValkeyAddress{primary}, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !cfg.ReadFromReplica {
+		return primaryClients, nil, nil
+	}
+
+	replicas, err := cfg.sentinelReplicas(ctx)
+	if err != nil {
+		// Reads still work from the primary
+		log.Printf("Reading from the primary only: %v", err)
+		return primaryClients, nil, nil
+	}
+	replicaClients, err := cfg.connectPool(append([]ValkeyAddress{primary}, replicas...), true)
+	if err != nil {
+		(&clientPair{primary: primaryClients}).close()
+		return nil, nil, err
+	}
+	return primaryClients, replicaClients, nil
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// tunnelDialTimeout bounds connecting a tunnel to its Valkey node
+const tunnelDialTimeout = 5 * time.Second
+
+// customTLS reports whether TLS needs settings the client library does not support itself. It only verifies
+// server certificates against the system roots and cannot present a client certificate.
+func (cfg ValkeyConfig) customTLS() bool {
+	return cfg.TLSCAFile != "" || cfg.TLSCertFile != "" || cfg.TLSKeyFile != "" || cfg.TLSInsecureSkipVerify
+}
+
+// tlsConfig builds the TLS configuration for connections to Valkey and Sentinel
+func (cfg ValkeyConfig) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.TLSInsecureSkipVerify, //nolint:gosec // explicitly requested for testing setups
+	}
+
+	if cfg.TLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Valkey CA file: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in Valkey CA file %s", cfg.TLSCAFile)
+		}
+	}
+
+	if cfg.TLSCertFile != "" || cfg.TLSKeyFile != "" {
+		if cfg.TLSCertFile == "" || cfg.TLSKeyFile == "" {
+			return nil, fmt.Errorf("both a client certificate and key are required for Valkey TLS")
+		}
+		certificate, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load Valkey client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
+
+// tlsTunnel accepts plain connections on a loopback port and forwards each one over TLS to a Valkey node.
+// It lets the client library connect with a custom CA, a client certificate or without verification.
+type tlsTunnel struct {
+	listener net.Listener
+	local    ValkeyAddress
+	target   string
+	config   *tls.Config
+}
+
+// startTLSTunnel starts a tunnel to a Valkey node
+func startTLSTunnel(target ValkeyAddress, config *tls.Config) (*tlsTunnel, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start TLS tunnel: %w", err)
+	}
+	local, ok := listener.Addr().(*net.TCPAddr)
+	if !ok {
+		listener.Close()
+		return nil, fmt.Errorf("failed to start TLS tunnel: unexpected address %s", listener.Addr())
+	}
+
+	config = config.Clone()
+	config.ServerName = target.Host
+	tunnel := &tlsTunnel{
+		listener: listener,
+		local:    ValkeyAddress{Host: local.IP.String(), Port: local.Port},
+		target:   net.JoinHostPort(target.Host, strconv.Itoa(target.Port)),
+		config:   config,
+	}
+	go tunnel.serve()
+	return tunnel, nil
+}
+
+// close stops accepting connections; open connections end when the client closes them
+func (t *tlsTunnel) close() {
+	t.listener.Close()
+}
+
+func (t *tlsTunnel) serve() {
+	for {
+		conn, err := t.listener.Accept()
+		if err != nil {
+			return
+		}
+		go t.forward(conn)
+	}
+}
+
+// forward copies data between a local connection and a new TLS connection to the node
+func (t *tlsTunnel) forward(local net.Conn) {
+	defer local.Close() //nolint:errcheck
+
+	dialer := &tls.Dialer{NetDialer: &net.Dialer{Timeout: tunnelDialTimeout}, Config: t.config}
+	remote, err := dialer.Dial("tcp", t.target)
+	if err != nil {
+		log.Printf("Failed to connect to Valkey at %s over TLS: %v", t.target, err)
+		return
+	}
+	defer remote.Close() //nolint:errcheck
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(remote, local) //nolint:errcheck
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(local, remote) //nolint:errcheck
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// tunneledClient is a client connected through TLS tunnels, which are closed with it
+type tunneledClient struct {
+	valkeyCommands
+	tunnels []*tlsTunnel
+}
+
+// Close closes the client and its tunnels
+func (c *tunneledClient) Close() {
+	c.valkeyCommands.Close()
+	for _, tunnel := range c.tunnels {
+		tunnel.close()
+	}
+}
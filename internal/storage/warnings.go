@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+type warningsKey struct{}
+
+// warningCollector gathers the warnings raised while serving one request
+type warningCollector struct {
+	mu       sync.Mutex
+	warnings []*models.Warning
+}
+
+// WithWarnings returns a context that collects the warnings raised by storage calls made with it, which
+// WarningsFromContext returns afterwards. Warnings raised without a collector are dropped.
+func WithWarnings(ctx context.Context) context.Context {
+	return context.WithValue(ctx, warningsKey{}, &warningCollector{})
+}
+
+// WarningsFromContext returns the warnings collected in the context, in the order they were raised
+func WarningsFromContext(ctx context.Context) []*models.Warning {
+	collector, ok := ctx.Value(warningsKey{}).(*warningCollector)
+	if !ok {
+		return nil
+	}
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	return append([]*models.Warning(nil), collector.warnings...)
+}
+
+// addWarning adds a warning to the collector of the context, if there is one
+func addWarning(ctx context.Context, warning *models.Warning) {
+	collector, ok := ctx.Value(warningsKey{}).(*warningCollector)
+	if !ok {
+		return
+	}
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	collector.warnings = append(collector.warnings, warning)
+}
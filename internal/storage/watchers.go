@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	glidemodels "github.com/valkey-io/valkey-glide/go/v2/models"
+	"github.com/valkey-io/valkey-glide/go/v2/options"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// DefaultNotificationStreamLength is the approximate number of notifications kept in the notification stream
+const DefaultNotificationStreamLength = 10000
+
+// WatcherStore keeps the watchers of plans, the agents and users notified when a plan or its tasks change.
+// Watchers are deleted with their plan.
+type WatcherStore struct {
+	client *ValkeyClient
+}
+
+// NewWatcherStore creates a watcher store
+func NewWatcherStore(client *ValkeyClient) *WatcherStore {
+	return &WatcherStore{client: client}
+}
+
+// Watch adds a watcher to a plan, reporting whether it was not watching the plan yet. The caller checks that
+// the plan exists.
+func (s *WatcherStore) Watch(ctx context.Context, planID, watcher string) (bool, error) {
+	if err := models.ValidateWatcher(watcher); err != nil {
+		return false, err
+	}
+	added, err := s.client.client.SAdd(ctx, GetPlanWatchersKey(planID), []string{watcher})
+	if err != nil {
+		return false, fmt.Errorf("failed to add watcher: %w", err)
+	}
+	return added > 0, nil
+}
+
+// Unwatch removes a watcher from a plan, reporting whether it was watching the plan
+func (s *WatcherStore) Unwatch(ctx context.Context, planID, watcher string) (bool, error) {
+	removed, err := s.client.client.SRem(ctx, GetPlanWatchersKey(planID), []string{watcher})
+	if err != nil {
+		return false, fmt.Errorf("failed to remove watcher: %w", err)
+	}
+	return removed > 0, nil
+}
+
+// Watchers returns the watchers of a plan, sorted
+func (s *WatcherStore) Watchers(ctx context.Context, planID string) ([]string, error) {
+	members, err := s.client.client.SMembers(ctx, GetPlanWatchersKey(planID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watchers: %w", err)
+	}
+	watchers := make([]string, 0, len(members))
+	for watcher := range members {
+		watchers = append(watchers, watcher)
+	}
+	slices.Sort(watchers)
+	return watchers, nil
+}
+
+// ChangeListener is told about every change recorded in the audit log, after it is recorded
+type ChangeListener interface {
+	Changed(ctx context.Context, entry *models.AuditEntry)
+}
+
+// ChangeListeners tells several listeners about every change, in turn
+type ChangeListeners []ChangeListener
+
+// Changed tells every listener about a change
+func (l ChangeListeners) Changed(ctx context.Context, entry *models.AuditEntry) {
+	for _, listener := range l {
+		listener.Changed(ctx, entry)
+	}
+}
+
+// Notifier delivers notifications to the watchers of a plan, such as through a webhook or a chat integration
+type Notifier interface {
+	Notify(ctx context.Context, notification *models.Notification) error
+}
+
+// NotificationStream publishes notifications to a Valkey stream, which consumers follow with XREAD or a
+// consumer group. Each entry holds the notification as JSON in its notification field.
+type NotificationStream struct {
+	client    *ValkeyClient
+	maxLength int64
+}
+
+// NewNotificationStream creates a notifier publishing to the notification stream, trimmed to about
+// maxLength entries; zero keeps all entries
+func NewNotificationStream(client *ValkeyClient, maxLength int64) *NotificationStream {
+	return &NotificationStream{client: client, maxLength: maxLength}
+}
+
+// Notify appends a notification to the stream
+func (s *NotificationStream) Notify(ctx context.Context, notification *models.Notification) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	addOpts := options.NewXAddOptions()
+	if s.maxLength > 0 {
+		addOpts.SetTrimOptions(options.NewXTrimOptionsWithMaxLen(s.maxLength).SetNearlyExactTrimming())
+	}
+	_, err = s.client.client.XAddWithOptions(ctx, notificationsKey, []glidemodels.FieldValue{
+		{Field: "plan_id", Value: notification.PlanID},
+		{Field: "notification", Value: string(data)},
+	}, *addOpts)
+	if err != nil {
+		return fmt.Errorf("failed to publish notification: %w", err)
+	}
+	return nil
+}
+
+// Ensure the notification stream implements the interface
+var _ Notifier = (*NotificationStream)(nil)
@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// keepAliveInterval is how often an idle event stream sends a comment so proxies keep the connection open
+const keepAliveInterval = 15 * time.Second
+
+// events streams the plan list, or the board of the plan given by plan_id, as server-sent events.
+// The data is polled from the repositories and an update event is only sent when it has changed.
+func (h *Handler) events(w http.ResponseWriter, r *http.Request) {
+	planID := r.URL.Query().Get("plan_id")
+	load := func(ctx context.Context) (any, error) {
+		if planID != "" {
+			return h.planView(ctx, planID)
+		}
+		return h.planSummaries(ctx, r.URL.Query().Get("application_id"))
+	}
+
+	controller := http.NewResponseController(w)
+	// The stream outlives the server write timeout, so the deadline is lifted for this response
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Printf("Failed to clear write deadline for dashboard events: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	poll := time.NewTicker(h.pollInterval)
+	defer poll.Stop()
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	var last []byte
+	for {
+		message, err := eventMessage(r.Context(), load)
+		if err != nil {
+			log.Printf("Failed to encode dashboard event: %v", err)
+			return
+		}
+
+		if !bytes.Equal(message, last) {
+			last = message
+			if !writeEvent(w, controller, message) {
+				return
+			}
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-poll.C:
+		case <-keepAlive.C:
+			if !writeEvent(w, controller, []byte(": keep-alive\n\n")) {
+				return
+			}
+		}
+	}
+}
+
+// eventMessage loads the streamed data and encodes it as an update event, or as an error event if loading failed
+func eventMessage(ctx context.Context, load func(context.Context) (any, error)) ([]byte, error) {
+	event := "update"
+	value, err := load(ctx)
+	if err != nil {
+		event = "error"
+		value = map[string]string{"error": err.Error()}
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", event, data)), nil
+}
+
+// writeEvent writes and flushes a raw event stream message, reporting whether the client is still connected
+func writeEvent(w http.ResponseWriter, controller *http.ResponseController, message []byte) bool {
+	if _, err := w.Write(message); err != nil {
+		return false
+	}
+	return controller.Flush() == nil
+}
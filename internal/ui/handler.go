@@ -0,0 +1,150 @@
+// Package ui serves a read-only web dashboard that shows plans, task boards grouped by status and rendered notes,
+// and pushes live updates over server-sent events so the team can watch agents make progress.
+package ui
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// BasePath is the path prefix the dashboard is served under
+const BasePath = "/ui"
+
+// DefaultPollInterval is how often the live update stream checks for changes
+const DefaultPollInterval = 2 * time.Second
+
+//go:embed static
+var staticFiles embed.FS
+
+// Handler serves the dashboard page, its read-only JSON endpoints and the live update stream
+type Handler struct {
+	planRepo     storage.PlanRepositoryInterface
+	taskRepo     storage.TaskRepositoryInterface
+	pollInterval time.Duration
+	mux          *http.ServeMux
+}
+
+// NewHandler creates a dashboard handler for the given repositories.
+// A non-positive poll interval falls back to DefaultPollInterval.
+func NewHandler(
+	planRepo storage.PlanRepositoryInterface,
+	taskRepo storage.TaskRepositoryInterface,
+	pollInterval time.Duration,
+) *Handler {
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+
+	h := &Handler{
+		planRepo:     planRepo,
+		taskRepo:     taskRepo,
+		pollInterval: pollInterval,
+		mux:          http.NewServeMux(),
+	}
+
+	static, err := fs.Sub(staticFiles, "static")
+	if err != nil {
+		// The embedded directory is part of the binary, so this can only be a build problem
+		panic(err)
+	}
+
+	h.mux.Handle("GET "+BasePath+"/", http.StripPrefix(BasePath, http.FileServerFS(static)))
+	h.mux.HandleFunc("GET "+BasePath+"/api/plans", h.listPlans)
+	h.mux.HandleFunc("GET "+BasePath+"/api/plans/{id}", h.getPlan)
+	h.mux.HandleFunc("GET "+BasePath+"/events", h.events)
+
+	return h
+}
+
+// ServeHTTP dispatches a request to the matching route
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// listPlans returns summaries of all plans, optionally filtered by application
+func (h *Handler) listPlans(w http.ResponseWriter, r *http.Request) {
+	summaries, err := h.planSummaries(r.Context(), r.URL.Query().Get("application_id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+// getPlan returns a plan with its task board
+func (h *Handler) getPlan(w http.ResponseWriter, r *http.Request) {
+	view, err := h.planView(r.Context(), r.PathValue("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if models.IsNotFound(err) {
+			status = http.StatusNotFound
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, view)
+}
+
+// planSummaries loads the summaries of all plans, or of the plans of one application
+func (h *Handler) planSummaries(ctx context.Context, applicationID string) ([]PlanSummary, error) {
+	var plans []*models.Plan
+	var err error
+	if applicationID != "" {
+		plans, err = h.planRepo.ListByApplication(ctx, applicationID)
+	} else {
+		plans, err = h.planRepo.List(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]PlanSummary, 0, len(plans))
+	for _, plan := range plans {
+		tasks, err := h.taskRepo.ListByPlan(ctx, plan.ID)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, BuildPlanSummary(plan, tasks))
+	}
+	sortSummaries(summaries)
+
+	return summaries, nil
+}
+
+// planView loads a plan and its tasks and builds the board
+func (h *Handler) planView(ctx context.Context, planID string) (*PlanView, error) {
+	plan, err := h.planRepo.Get(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := h.taskRepo.ListByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	view := BuildPlanView(plan, tasks)
+	return &view, nil
+}
+
+// writeJSON writes a value as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to write dashboard response: %v", err)
+	}
+}
+
+// writeError writes an error response with the given status code
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
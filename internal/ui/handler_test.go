@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestHandlerServesStaticFiles checks that the embedded dashboard assets are served under the base path
+func TestHandlerServesStaticFiles(t *testing.T) {
+	handler := NewHandler(nil, nil, 0)
+
+	tests := []struct {
+		path        string
+		status      int
+		contentType string
+	}{
+		{BasePath + "/", http.StatusOK, "text/html"},
+		{BasePath + "/app.js", http.StatusOK, "javascript"},
+		{BasePath + "/style.css", http.StatusOK, "text/css"},
+		{BasePath + "/missing.js", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if contentType := rec.Header().Get("Content-Type"); !strings.Contains(contentType, tt.contentType) {
+				t.Errorf("Content-Type = %q, want it to contain %q", contentType, tt.contentType)
+			}
+		})
+	}
+}
+
+func TestEventMessage(t *testing.T) {
+	message, err := eventMessage(context.Background(), func(context.Context) (any, error) {
+		return map[string]int{"total": 1}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(message) != "event: update\ndata: {\"total\":1}\n\n" {
+		t.Errorf("message = %q", message)
+	}
+
+	message, err = eventMessage(context.Background(), func(context.Context) (any, error) {
+		return nil, errors.New("plan not found: p1")
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(message) != "event: error\ndata: {\"error\":\"plan not found: p1\"}\n\n" {
+		t.Errorf("message = %q", message)
+	}
+}
@@ -0,0 +1,128 @@
+// Read-only dashboard for Valkey AI Tasks.
+// The page renders from the update events streamed by the server, so it only reloads data when it changes.
+(function () {
+  "use strict";
+
+  const content = document.getElementById("content");
+  const connection = document.getElementById("connection");
+  let source = null;
+
+  function el(tag, attrs, children) {
+    const node = document.createElement(tag);
+    Object.entries(attrs || {}).forEach(([key, value]) => {
+      if (key === "text") {
+        node.textContent = value;
+      } else if (key === "html") {
+        // Only used for notes, which the server renders from markdown with all text escaped
+        node.innerHTML = value;
+      } else {
+        node.setAttribute(key, value);
+      }
+    });
+    (children || []).forEach((child) => node.appendChild(child));
+    return node;
+  }
+
+  function formatTime(value) {
+    return new Date(value).toLocaleString();
+  }
+
+  function renderPlans(plans) {
+    if (plans.length === 0) {
+      content.replaceChildren(el("p", { class: "muted", text: "No plans yet." }));
+      return;
+    }
+
+    const rows = plans.map((plan) => {
+      const done = plan.task_counts.completed + plan.task_counts.cancelled;
+      const percent = plan.total_tasks === 0 ? 0 : Math.round((done / plan.total_tasks) * 100);
+      return el("tr", {}, [
+        el("td", {}, [el("a", { href: "#/plans/" + encodeURIComponent(plan.id), text: plan.name })]),
+        el("td", { text: plan.application_id }),
+        el("td", {}, [el("span", { class: "status", text: plan.status })]),
+        el("td", {}, [
+          el("div", { class: "progress", title: done + " of " + plan.total_tasks + " tasks done" }, [
+            el("span", { style: "width: " + percent + "%" }),
+          ]),
+        ]),
+        el("td", { text: plan.task_counts.in_progress + " in progress, " + plan.task_counts.pending + " pending" }),
+        el("td", { class: "muted", text: formatTime(plan.updated_at) }),
+      ]);
+    });
+
+    const header = el("tr", {}, ["Plan", "Application", "Status", "Progress", "Open tasks", "Updated"].map(
+      (title) => el("th", { text: title })
+    ));
+
+    content.replaceChildren(el("table", {}, [el("thead", {}, [header]), el("tbody", {}, rows)]));
+  }
+
+  function renderCard(task) {
+    const children = [el("div", { class: "title", text: task.title })];
+    if (task.description) {
+      children.push(el("div", { class: "description", text: task.description }));
+    }
+    (task.tags || []).forEach((tag) => children.push(el("span", { class: "tag", text: "#" + tag })));
+    if (task.notes_html) {
+      children.push(el("details", {}, [el("summary", { text: "Notes" }), el("div", { html: task.notes_html })]));
+    }
+    return el("div", { class: "card priority-" + task.priority, title: task.priority + " priority" }, children);
+  }
+
+  function renderPlan(view) {
+    const plan = view.plan;
+    const children = [
+      el("h1", { text: plan.name }),
+      el("div", { class: "muted", text: plan.application_id + " · " + plan.status + " · updated " + formatTime(plan.updated_at) }),
+    ];
+    if (plan.description) {
+      children.push(el("p", { text: plan.description }));
+    }
+    if (view.notes_html) {
+      children.push(el("section", { class: "notes", html: view.notes_html }));
+    }
+
+    const columns = view.columns.map((column) =>
+      el("div", { class: "column" }, [
+        el("h2", { text: column.title + " (" + column.tasks.length + ")" }),
+      ].concat(column.tasks.map(renderCard)))
+    );
+    children.push(el("div", { class: "board" }, columns));
+
+    content.replaceChildren(...children);
+  }
+
+  function renderError(message) {
+    content.replaceChildren(el("p", { class: "error", text: message }));
+  }
+
+  function setConnection(state, text) {
+    connection.className = "connection " + state;
+    connection.textContent = text;
+  }
+
+  function route() {
+    if (source) {
+      source.close();
+    }
+
+    const match = location.hash.match(/^#\/plans\/(.+)$/);
+    const planID = match ? decodeURIComponent(match[1]) : "";
+    const render = planID ? renderPlan : renderPlans;
+
+    content.replaceChildren(el("p", { class: "muted", text: "Loading…" }));
+    source = new EventSource(planID ? "events?plan_id=" + encodeURIComponent(planID) : "events");
+    source.addEventListener("open", () => setConnection("live", "live"));
+    source.addEventListener("update", (event) => render(JSON.parse(event.data)));
+    source.addEventListener("error", (event) => {
+      if (event.data) {
+        renderError(JSON.parse(event.data).error);
+      } else {
+        setConnection("offline", "reconnecting…");
+      }
+    });
+  }
+
+  window.addEventListener("hashchange", route);
+  route();
+})();
@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/utils/markdown"
+)
+
+// boardStatuses returns the statuses of the task board columns in display order: the statuses tasks may be
+// set to, with statuses that are no longer configured but still have tasks of the plan before completed
+func boardStatuses(tasks []*models.Task) []models.TaskStatus {
+	statuses := models.TaskStatuses()
+	for _, task := range tasks {
+		if !slices.Contains(statuses, task.Status) {
+			at := slices.Index(statuses, models.TaskStatusCompleted)
+			statuses = slices.Insert(statuses, at, task.Status)
+		}
+	}
+	return statuses
+}
+
+// columnTitle returns the title of the column of a status, such as "In Progress" for in_progress
+func columnTitle(status models.TaskStatus) string {
+	words := strings.Split(string(status), "_")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// PlanSummary is a plan as shown in the dashboard plan list
+type PlanSummary struct {
+	ID            string                    `json:"id"`
+	ApplicationID string                    `json:"application_id"`
+	Name          string                    `json:"name"`
+	Status        models.PlanStatus         `json:"status"`
+	UpdatedAt     time.Time                 `json:"updated_at"`
+	TotalTasks    int                       `json:"total_tasks"`
+	TaskCounts    map[models.TaskStatus]int `json:"task_counts"`
+}
+
+// PlanView is a plan with its notes rendered and its tasks grouped into board columns
+type PlanView struct {
+	Plan      *models.Plan  `json:"plan"`
+	NotesHTML string        `json:"notes_html"`
+	Columns   []BoardColumn `json:"columns"`
+}
+
+// BoardColumn holds the tasks of one status
+type BoardColumn struct {
+	Status models.TaskStatus `json:"status"`
+	Title  string            `json:"title"`
+	Tasks  []TaskCard        `json:"tasks"`
+}
+
+// TaskCard is a task as shown on the board
+type TaskCard struct {
+	ID          string              `json:"id"`
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	Priority    models.TaskPriority `json:"priority"`
+	Tags        []string            `json:"tags,omitempty"`
+	NotesHTML   string              `json:"notes_html,omitempty"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+// BuildPlanSummary counts the tasks of a plan by status
+func BuildPlanSummary(plan *models.Plan, tasks []*models.Task) PlanSummary {
+	statuses := boardStatuses(tasks)
+	counts := make(map[models.TaskStatus]int, len(statuses))
+	for _, status := range statuses {
+		counts[status] = 0
+	}
+	for _, task := range tasks {
+		counts[task.Status]++
+	}
+
+	return PlanSummary{
+		ID:            plan.ID,
+		ApplicationID: plan.ApplicationID,
+		Name:          plan.Name,
+		Status:        plan.Status,
+		UpdatedAt:     plan.UpdatedAt,
+		TotalTasks:    len(tasks),
+		TaskCounts:    counts,
+	}
+}
+
+// BuildPlanView groups the tasks of a plan by status, keeping their plan order within each column
+func BuildPlanView(plan *models.Plan, tasks []*models.Task) PlanView {
+	statuses := boardStatuses(tasks)
+	columns := make([]BoardColumn, len(statuses))
+	index := make(map[models.TaskStatus]int, len(statuses))
+	for i, status := range statuses {
+		columns[i] = BoardColumn{Status: status, Title: columnTitle(status), Tasks: []TaskCard{}}
+		index[status] = i
+	}
+
+	for _, task := range tasks {
+		i, ok := index[task.Status]
+		if !ok {
+			continue
+		}
+		columns[i].Tasks = append(columns[i].Tasks, TaskCard{
+			ID:          task.ID,
+			Title:       task.Title,
+			Description: task.Description,
+			Priority:    task.Priority,
+			Tags:        task.Tags,
+			NotesHTML:   markdown.ToHTML(task.Notes),
+			UpdatedAt:   task.UpdatedAt,
+		})
+	}
+
+	return PlanView{
+		Plan:      plan,
+		NotesHTML: markdown.ToHTML(plan.Notes),
+		Columns:   columns,
+	}
+}
+
+// sortSummaries orders plan summaries with the most recently updated first
+func sortSummaries(summaries []PlanSummary) {
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].UpdatedAt.After(summaries[j].UpdatedAt)
+	})
+}
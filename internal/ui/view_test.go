@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+func TestBuildPlanView(t *testing.T) {
+	plan := &models.Plan{ID: "p1", Name: "Plan", Notes: "**Goal**"}
+	tasks := []*models.Task{
+		{ID: "t1", Title: "First", Status: models.TaskStatusCompleted, Order: 0},
+		{ID: "t2", Title: "Second", Status: models.TaskStatusPending, Order: 1, Notes: "`code`"},
+		{ID: "t3", Title: "Third", Status: models.TaskStatusPending, Order: 2},
+		{ID: "t4", Title: "Fourth", Status: models.TaskStatusInProgress, Order: 3},
+	}
+
+	view := BuildPlanView(plan, tasks)
+
+	if view.NotesHTML != "<p><strong>Goal</strong></p>\n" {
+		t.Errorf("NotesHTML = %q", view.NotesHTML)
+	}
+
+	want := map[models.TaskStatus][]string{
+		models.TaskStatusPending:    {"t2", "t3"},
+		models.TaskStatusInProgress: {"t4"},
+		models.TaskStatusCompleted:  {"t1"},
+		models.TaskStatusCancelled:  {},
+	}
+	if len(view.Columns) != len(want) {
+		t.Fatalf("got %d columns, want %d", len(view.Columns), len(want))
+	}
+	if view.Columns[0].Status != models.TaskStatusPending || view.Columns[3].Status != models.TaskStatusCancelled {
+		t.Errorf("unexpected column order: %s ... %s", view.Columns[0].Status, view.Columns[3].Status)
+	}
+
+	for _, column := range view.Columns {
+		ids := make([]string, 0, len(column.Tasks))
+		for _, card := range column.Tasks {
+			ids = append(ids, card.ID)
+		}
+		if len(ids) != len(want[column.Status]) {
+			t.Errorf("column %s has tasks %v, want %v", column.Status, ids, want[column.Status])
+			continue
+		}
+		for i := range ids {
+			if ids[i] != want[column.Status][i] {
+				t.Errorf("column %s has tasks %v, want %v", column.Status, ids, want[column.Status])
+				break
+			}
+		}
+	}
+
+	if notes := view.Columns[0].Tasks[0].NotesHTML; notes != "<p><code>code</code></p>\n" {
+		t.Errorf("task NotesHTML = %q", notes)
+	}
+}
+
+func TestBuildPlanSummary(t *testing.T) {
+	plan := &models.Plan{ID: "p1", ApplicationID: "app", Name: "Plan", Status: models.PlanStatusInProgress}
+	tasks := []*models.Task{
+		{ID: "t1", Status: models.TaskStatusCompleted},
+		{ID: "t2", Status: models.TaskStatusCompleted},
+		{ID: "t3", Status: models.TaskStatusPending},
+	}
+
+	summary := BuildPlanSummary(plan, tasks)
+
+	if summary.TotalTasks != 3 {
+		t.Errorf("TotalTasks = %d, want 3", summary.TotalTasks)
+	}
+	if summary.TaskCounts[models.TaskStatusCompleted] != 2 || summary.TaskCounts[models.TaskStatusPending] != 1 {
+		t.Errorf("unexpected TaskCounts: %v", summary.TaskCounts)
+	}
+	// Every status is present so the dashboard does not have to handle missing counts
+	if count, ok := summary.TaskCounts[models.TaskStatusCancelled]; !ok || count != 0 {
+		t.Errorf("expected a zero cancelled count, got %v", summary.TaskCounts)
+	}
+}
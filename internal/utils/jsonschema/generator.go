@@ -0,0 +1,130 @@
+// Package jsonschema derives JSON Schemas from Go types and validates decoded JSON values against them
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// Generator derives JSON Schemas from Go types, registering named structs as reusable definitions
+type Generator struct {
+	refPrefix   string
+	enums       map[reflect.Type][]string
+	definitions map[string]any
+}
+
+// NewGenerator creates a generator whose struct references point to refPrefix, such as "#/$defs/" or
+// "#/components/schemas/". Enums lists the allowed values of string types.
+func NewGenerator(refPrefix string, enums map[reflect.Type][]string) *Generator {
+	return &Generator{
+		refPrefix:   refPrefix,
+		enums:       enums,
+		definitions: map[string]any{},
+	}
+}
+
+// Definitions returns the schemas of the structs registered so far, by name
+func (g *Generator) Definitions() map[string]any {
+	return g.definitions
+}
+
+// Schema returns the JSON Schema of a Go type.
+// Struct fields without omitempty are always present in the JSON encoding and are therefore marked required.
+func (g *Generator) Schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == rawMessageType:
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return g.structSchema(t)
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.Schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.Schema(t.Elem())}
+	case reflect.String:
+		schema := map[string]any{"type": "string"}
+		if values, ok := g.enums[t]; ok {
+			schema["enum"] = values
+		}
+		return schema
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{}
+	}
+}
+
+// Document returns the schema of a Go type as a standalone document, with the definitions it refers to
+// under $defs. The generator has to be created with the "#/$defs/" prefix.
+func (g *Generator) Document(t reflect.Type) map[string]any {
+	schema := map[string]any{"$schema": "https://json-schema.org/draft/2020-12/schema"}
+	for key, value := range g.Schema(t) {
+		schema[key] = value
+	}
+	if len(g.definitions) > 0 {
+		schema["$defs"] = g.definitions
+	}
+	return schema
+}
+
+// structSchema registers a struct as a definition and returns a reference to it
+func (g *Generator) structSchema(t reflect.Type) map[string]any {
+	ref := map[string]any{"$ref": g.refPrefix + t.Name()}
+	if _, ok := g.definitions[t.Name()]; ok {
+		return ref
+	}
+
+	// Register a placeholder first so self-referencing types terminate
+	schema := map[string]any{"type": "object"}
+	g.definitions[t.Name()] = schema
+
+	properties := map[string]any{}
+	required := []string{}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = g.Schema(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	schema["properties"] = properties
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return ref
+}
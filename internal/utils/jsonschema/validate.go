@@ -0,0 +1,254 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ValidationError describes the first value that does not match a schema
+type ValidationError struct {
+	// Path locates the value, such as tasks_json[2].priority; it is empty for the value itself
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Validate checks a decoded JSON value against a schema and returns a *ValidationError for the first
+// mismatch. It supports the keywords the generated and tool schemas use: type, enum, properties, required,
+// additionalProperties, items, minItems, minLength, minimum, maximum and, for strings holding JSON,
+// contentMediaType with contentSchema.
+func Validate(schema map[string]any, value any) error {
+	return validate("", schema, value)
+}
+
+func validate(path string, schema map[string]any, value any) error {
+	if types := stringList(schema["type"]); len(types) > 0 && !slices.ContainsFunc(types, func(t string) bool {
+		return hasType(value, t)
+	}) {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("must be %s, got %s", typeList(types), typeName(value))}
+	}
+
+	if enum := stringList(schema["enum"]); len(enum) > 0 {
+		str, ok := value.(string)
+		if !ok || !slices.Contains(enum, str) {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("must be one of %s", strings.Join(enum, ", "))}
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		return validateObject(path, schema, v)
+	case []any:
+		return validateArray(path, schema, v)
+	case string:
+		return validateString(path, schema, v)
+	case float64:
+		return validateNumber(path, schema, v)
+	case int:
+		return validateNumber(path, schema, float64(v))
+	}
+	return nil
+}
+
+func validateObject(path string, schema map[string]any, object map[string]any) error {
+	for _, name := range stringList(schema["required"]) {
+		if _, ok := object[name]; !ok {
+			return &ValidationError{Path: join(path, name), Message: "is required"}
+		}
+	}
+
+	properties, ok := schema["properties"].(map[string]any)
+	if !ok {
+		properties = map[string]any{}
+	}
+	additional := schema["additionalProperties"]
+
+	// Check in a fixed order so the same input always reports the same error
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if propertySchema, ok := properties[name].(map[string]any); ok {
+			if err := validate(join(path, name), propertySchema, object[name]); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, ok := properties[name]; ok {
+			continue
+		}
+
+		switch extra := additional.(type) {
+		case bool:
+			if !extra {
+				return &ValidationError{Path: join(path, name), Message: "is not a known property"}
+			}
+		case map[string]any:
+			if err := validate(join(path, name), extra, object[name]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateArray(path string, schema map[string]any, items []any) error {
+	if minItems, ok := number(schema["minItems"]); ok && float64(len(items)) < minItems {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("must have at least %v items", minItems)}
+	}
+
+	itemSchema, ok := schema["items"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	for i, item := range items {
+		if err := validate(fmt.Sprintf("%s[%d]", path, i), itemSchema, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateString(path string, schema map[string]any, str string) error {
+	if minLength, ok := number(schema["minLength"]); ok && float64(len([]rune(str))) < minLength {
+		if minLength == 1 {
+			return &ValidationError{Path: path, Message: "must not be empty"}
+		}
+		return &ValidationError{Path: path, Message: fmt.Sprintf("must be at least %v characters long", minLength)}
+	}
+
+	if schema["contentMediaType"] != "application/json" {
+		return nil
+	}
+	var content any
+	if err := json.Unmarshal([]byte(str), &content); err != nil {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("must contain valid JSON: %v", err)}
+	}
+	if contentSchema, ok := schema["contentSchema"].(map[string]any); ok {
+		return validate(path, contentSchema, content)
+	}
+	return nil
+}
+
+func validateNumber(path string, schema map[string]any, n float64) error {
+	if minimum, ok := number(schema["minimum"]); ok && n < minimum {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("must be at least %v", minimum)}
+	}
+	if maximum, ok := number(schema["maximum"]); ok && n > maximum {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("must be at most %v", maximum)}
+	}
+	return nil
+}
+
+// hasType reports whether a decoded JSON value is of a JSON Schema type
+func hasType(value any, schemaType string) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := number(value)
+		return ok
+	case "integer":
+		n, ok := number(value)
+		return ok && n == math.Trunc(n)
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+// typeName returns the JSON type of a decoded value for error messages
+func typeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "an object"
+	case []any:
+		return "an array"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case float64, int:
+		return "a number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// typeList describes the allowed types of a schema for error messages
+func typeList(types []string) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		switch t {
+		case "object", "array", "integer":
+			names[i] = "an " + t
+		case "null":
+			names[i] = t
+		default:
+			names[i] = "a " + t
+		}
+	}
+	return strings.Join(names, " or ")
+}
+
+// stringList reads a keyword holding a string or a list of strings, such as type, enum or required
+func stringList(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				list = append(list, str)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// number reads a numeric keyword or value
+func number(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// join appends a property name to a path
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
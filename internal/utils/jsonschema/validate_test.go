@@ -0,0 +1,75 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":  map[string]any{"type": "string", "minLength": 1},
+			"count": map[string]any{"type": "integer", "minimum": 0},
+			"tags":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			"tasks_json": map[string]any{
+				"type":             "string",
+				"contentMediaType": "application/json",
+				"contentSchema": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"priority": map[string]any{"type": "string", "enum": []string{"low", "high"}},
+						},
+						"additionalProperties": false,
+					},
+				},
+			},
+		},
+		"required": []string{"name"},
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		wantPath string
+	}{
+		{"Valid", `{"name":"a","count":2,"tags":["x"],"tasks_json":"[{\"priority\":\"low\"}]"}`, ""},
+		{"Missing required", `{"count":1}`, "name"},
+		{"Wrong type", `{"name":5}`, "name"},
+		{"Empty string", `{"name":""}`, "name"},
+		{"Fraction for integer", `{"name":"a","count":1.5}`, "count"},
+		{"Below minimum", `{"name":"a","count":-1}`, "count"},
+		{"Wrong item type", `{"name":"a","tags":["x",3]}`, "tags[1]"},
+		{"Invalid JSON content", `{"name":"a","tasks_json":"[{"}`, "tasks_json"},
+		{"Invalid enum in content", `{"name":"a","tasks_json":"[{},{\"priority\":\"urgent\"}]"}`, "tasks_json[1].priority"},
+		{"Unknown property in content", `{"name":"a","tasks_json":"[{\"priorty\":\"low\"}]"}`, "tasks_json[0].priorty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value any
+			if err := json.Unmarshal([]byte(tt.value), &value); err != nil {
+				t.Fatalf("invalid test value: %v", err)
+			}
+
+			err := Validate(schema, value)
+			if tt.wantPath == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want none", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Validate() error = %v, want a ValidationError", err)
+			}
+			if validationErr.Path != tt.wantPath {
+				t.Errorf("Validate() path = %q, want %q (%v)", validationErr.Path, tt.wantPath, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+var checkboxItemRegex = regexp.MustCompile(`^[-*+]\s+\[([ xX])\]\s+(.*)$`)
+
+// Checklist is a markdown document of headings and checkbox lists, such as a TODO.md file
+type Checklist struct {
+	// Title is the text of the level 1 heading starting the document
+	Title string
+	// Description is the text before the first section heading or checkbox
+	Description string
+	// Items are the checkboxes that are not nested under another checkbox, in document order
+	Items []ChecklistItem
+}
+
+// ChecklistItem is a checkbox of a checklist
+type ChecklistItem struct {
+	Text    string
+	Checked bool
+	// Section is the heading the checkbox is under, other than the title
+	Section string
+	// Details are the indented lines under the checkbox that are not checkboxes themselves
+	Details string
+	// Subitems are the checkboxes nested under the checkbox. They have no sections, details or subitems of
+	// their own; deeper checkboxes are added to the same list.
+	Subitems []ChecklistItem
+}
+
+// ParseChecklist reads the headings and checkboxes ("- [ ] todo", "- [x] done") of a markdown document.
+// Lines inside fenced code blocks are only kept as details or description, never read as checkboxes or
+// headings, and text that is neither is ignored once the first section or checkbox has started.
+func ParseChecklist(content string) *Checklist {
+	parser := &checklistParser{checklist: &Checklist{}, inDescription: true}
+	for _, line := range strings.Split(normalizeLineEndings(content), "\n") {
+		parser.line(line)
+	}
+	parser.closeItem()
+	parser.checklist.Description = strings.TrimSpace(strings.Join(parser.description, "\n"))
+	return parser.checklist
+}
+
+// checklistParser reads a checklist one line at a time
+type checklistParser struct {
+	checklist *Checklist
+	section   string
+	inCode    bool
+
+	description   []string
+	inDescription bool
+
+	// item is the open top level checkbox, with the indentation of its marker and of its text
+	item          *ChecklistItem
+	itemIndent    int
+	contentIndent int
+	details       []string
+}
+
+// line reads a single line of markdown
+func (p *checklistParser) line(line string) {
+	trimmed := strings.TrimSpace(line)
+	indent := indentation(line)
+
+	if strings.HasPrefix(trimmed, "```") {
+		p.inCode = !p.inCode
+		p.text(line, indent)
+		return
+	}
+	if p.inCode || trimmed == "" {
+		p.text(line, indent)
+		return
+	}
+
+	if match := checkboxItemRegex.FindStringSubmatch(trimmed); match != nil {
+		text := strings.TrimSpace(match[2])
+		checked := match[1] != " "
+		if p.item != nil && indent > p.itemIndent {
+			p.item.Subitems = append(p.item.Subitems, ChecklistItem{Text: text, Checked: checked})
+			return
+		}
+		p.closeItem()
+		p.inDescription = false
+		p.item = &ChecklistItem{Text: text, Checked: checked, Section: p.section}
+		p.itemIndent = indent
+		p.contentIndent = indent + len(trimmed) - len(match[2])
+		return
+	}
+
+	if indent == 0 {
+		if match := headingLineRegex.FindStringSubmatch(trimmed); match != nil {
+			p.closeItem()
+			if len(match[1]) == 1 && p.checklist.Title == "" && p.inDescription && p.section == "" {
+				p.checklist.Title = match[2]
+				return
+			}
+			p.section = match[2]
+			p.inDescription = false
+			return
+		}
+	}
+
+	p.text(line, indent)
+}
+
+// text adds a line that is neither a heading nor a checkbox to the open checkbox or the description
+func (p *checklistParser) text(line string, indent int) {
+	switch {
+	case p.item != nil && (indent > p.itemIndent || p.inCode || strings.TrimSpace(line) == ""):
+		p.details = append(p.details, strings.TrimRight(dedent(line, p.contentIndent), " \t"))
+	case p.item != nil:
+		p.closeItem()
+	case p.inDescription:
+		p.description = append(p.description, strings.TrimRight(line, " \t"))
+	}
+}
+
+// closeItem adds the open checkbox to the checklist
+func (p *checklistParser) closeItem() {
+	if p.item == nil {
+		return
+	}
+	p.item.Details = strings.TrimSpace(strings.Join(p.details, "\n"))
+	if p.item.Text != "" {
+		p.checklist.Items = append(p.checklist.Items, *p.item)
+	}
+	p.item = nil
+	p.details = nil
+}
+
+// indentation returns the width of the leading whitespace of a line, counting a tab as 4 spaces
+func indentation(line string) int {
+	width := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// dedent removes up to width columns of leading whitespace from a line
+func dedent(line string, width int) string {
+	removed := 0
+	for i, r := range line {
+		if removed >= width || (r != ' ' && r != '\t') {
+			return line[i:]
+		}
+		if r == '\t' {
+			removed += 4
+		} else {
+			removed++
+		}
+	}
+	return ""
+}
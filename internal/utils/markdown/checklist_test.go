@@ -0,0 +1,79 @@
+package markdown
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseChecklist(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected *Checklist
+	}{
+		{
+			name:     "Empty content",
+			content:  "",
+			expected: &Checklist{},
+		},
+		{
+			name:    "Title, description and checkboxes",
+			content: "# Launch\n\nEverything for the launch.\n\n- [x] Write docs\n- [ ] Ship it\n* [X] Tell people",
+			expected: &Checklist{
+				Title:       "Launch",
+				Description: "Everything for the launch.",
+				Items: []ChecklistItem{
+					{Text: "Write docs", Checked: true},
+					{Text: "Ship it"},
+					{Text: "Tell people", Checked: true},
+				},
+			},
+		},
+		{
+			name:    "Sections",
+			content: "# TODO\n## Backend\n- [ ] API\n## Frontend\n- [ ] Form\n",
+			expected: &Checklist{
+				Title: "TODO",
+				Items: []ChecklistItem{
+					{Text: "API", Section: "Backend"},
+					{Text: "Form", Section: "Frontend"},
+				},
+			},
+		},
+		{
+			name: "Details and subitems",
+			content: "- [ ] Migrate the database\n  Run it at night.\n\n  ```sh\n  - [ ] not a checkbox\n  ```\n" +
+				"  - [x] Back up\n    - [ ] Check the backup\n- [ ] Clean up\n",
+			expected: &Checklist{
+				Items: []ChecklistItem{
+					{
+						Text:    "Migrate the database",
+						Details: "Run it at night.\n\n```sh\n- [ ] not a checkbox\n```",
+						Subitems: []ChecklistItem{
+							{Text: "Back up", Checked: true},
+							{Text: "Check the backup"},
+						},
+					},
+					{Text: "Clean up"},
+				},
+			},
+		},
+		{
+			name:    "Text outside checkboxes is ignored after the description",
+			content: "Intro\n- [ ] One\nLoose text\n- plain item\n### Notes\nMore text",
+			expected: &Checklist{
+				Description: "Intro",
+				Items:       []ChecklistItem{{Text: "One"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ParseChecklist(tt.content)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ParseChecklist() = %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+}
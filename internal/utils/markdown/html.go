@@ -0,0 +1,175 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	headingLineRegex     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	unorderedItemRegex   = regexp.MustCompile(`^[-*+]\s+(.*)$`)
+	orderedItemRegex     = regexp.MustCompile(`^\d+[.)]\s+(.*)$`)
+	blockquoteLineRegex  = regexp.MustCompile(`^>\s?(.*)$`)
+	horizontalRuleRegex  = regexp.MustCompile(`^(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	inlineLinkRegex      = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	inlineStrongRegex    = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	inlineEmphasisRegex  = regexp.MustCompile(`\*([^*\s](?:[^*]*[^*\s])?)\*`)
+	safeLinkSchemesRegex = regexp.MustCompile(`(?i)^(?:https?://|mailto:)`)
+)
+
+// ToHTML renders markdown notes as HTML for display.
+// It supports the subset of markdown used in notes: ATX headings, paragraphs, unordered and ordered lists,
+// fenced code blocks, blockquotes, horizontal rules, inline code, bold, italic and links.
+// All text is HTML-escaped and only http, https and mailto links are kept, so the output is safe to embed.
+func ToHTML(content string) string {
+	renderer := &htmlRenderer{}
+	for _, line := range strings.Split(normalizeLineEndings(content), "\n") {
+		renderer.line(line)
+	}
+	renderer.finish()
+	return renderer.out.String()
+}
+
+// htmlRenderer converts markdown to HTML one line at a time
+type htmlRenderer struct {
+	out       strings.Builder
+	paragraph []string
+	list      string
+	inCode    bool
+}
+
+// line renders a single line of markdown
+func (r *htmlRenderer) line(line string) {
+	trimmed := strings.TrimSpace(line)
+
+	if r.inCode {
+		if strings.HasPrefix(trimmed, "```") {
+			r.out.WriteString("</code></pre>\n")
+			r.inCode = false
+			return
+		}
+		r.out.WriteString(html.EscapeString(line) + "\n")
+		return
+	}
+
+	if strings.HasPrefix(trimmed, "```") {
+		r.closeBlocks()
+		language := strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+		if language != "" {
+			fmt.Fprintf(&r.out, `<pre><code class="language-%s">`, html.EscapeString(language))
+		} else {
+			r.out.WriteString("<pre><code>")
+		}
+		r.inCode = true
+		return
+	}
+
+	switch {
+	case trimmed == "":
+		r.closeBlocks()
+	case headingLineRegex.MatchString(trimmed):
+		r.closeBlocks()
+		match := headingLineRegex.FindStringSubmatch(trimmed)
+		level := len(match[1])
+		fmt.Fprintf(&r.out, "<h%d>%s</h%d>\n", level, renderInline(match[2]), level)
+	case horizontalRuleRegex.MatchString(trimmed):
+		r.closeBlocks()
+		r.out.WriteString("<hr>\n")
+	case unorderedItemRegex.MatchString(trimmed):
+		r.listItem("ul", unorderedItemRegex.FindStringSubmatch(trimmed)[1])
+	case orderedItemRegex.MatchString(trimmed):
+		r.listItem("ol", orderedItemRegex.FindStringSubmatch(trimmed)[1])
+	case blockquoteLineRegex.MatchString(trimmed):
+		r.closeBlocks()
+		fmt.Fprintf(&r.out, "<blockquote>%s</blockquote>\n", renderInline(blockquoteLineRegex.FindStringSubmatch(trimmed)[1]))
+	default:
+		r.closeList()
+		r.paragraph = append(r.paragraph, trimmed)
+	}
+}
+
+// listItem renders a list item, opening a new list when the list type changes
+func (r *htmlRenderer) listItem(list, text string) {
+	r.closeParagraph()
+	if r.list != list {
+		r.closeList()
+		fmt.Fprintf(&r.out, "<%s>\n", list)
+		r.list = list
+	}
+	fmt.Fprintf(&r.out, "<li>%s</li>\n", renderInline(text))
+}
+
+// closeBlocks closes any open paragraph or list
+func (r *htmlRenderer) closeBlocks() {
+	r.closeParagraph()
+	r.closeList()
+}
+
+// closeParagraph writes the pending paragraph lines
+func (r *htmlRenderer) closeParagraph() {
+	if len(r.paragraph) == 0 {
+		return
+	}
+	fmt.Fprintf(&r.out, "<p>%s</p>\n", renderInline(strings.Join(r.paragraph, "\n")))
+	r.paragraph = nil
+}
+
+// closeList closes the open list
+func (r *htmlRenderer) closeList() {
+	if r.list == "" {
+		return
+	}
+	fmt.Fprintf(&r.out, "</%s>\n", r.list)
+	r.list = ""
+}
+
+// finish closes everything still open at the end of the content
+func (r *htmlRenderer) finish() {
+	if r.inCode {
+		r.out.WriteString("</code></pre>\n")
+		r.inCode = false
+	}
+	r.closeBlocks()
+}
+
+// renderInline escapes text and renders inline code, links, bold and italic
+func renderInline(text string) string {
+	// Code spans are split out first so their contents are not formatted
+	parts := strings.Split(text, "`")
+	var b strings.Builder
+	for i, part := range parts {
+		switch {
+		case i%2 == 1 && i < len(parts)-1:
+			b.WriteString("<code>" + html.EscapeString(part) + "</code>")
+		case i%2 == 1:
+			// An unmatched backtick is kept as text
+			b.WriteString("`" + formatInline(part))
+		default:
+			b.WriteString(formatInline(part))
+		}
+	}
+	return b.String()
+}
+
+// formatInline escapes text and renders links, bold and italic
+func formatInline(text string) string {
+	text = html.EscapeString(text)
+
+	text = inlineLinkRegex.ReplaceAllStringFunc(text, func(link string) string {
+		match := inlineLinkRegex.FindStringSubmatch(link)
+		label, target := match[1], match[2]
+		if !safeLinkSchemesRegex.MatchString(html.UnescapeString(target)) {
+			return label
+		}
+		return fmt.Sprintf(`<a href="%s" rel="noopener noreferrer" target="_blank">%s</a>`, target, label)
+	})
+
+	text = inlineStrongRegex.ReplaceAllStringFunc(text, func(strong string) string {
+		match := inlineStrongRegex.FindStringSubmatch(strong)
+		return "<strong>" + match[1] + match[2] + "</strong>"
+	})
+
+	return inlineEmphasisRegex.ReplaceAllString(text, "<em>$1</em>")
+}
@@ -0,0 +1,71 @@
+package markdown
+
+import "testing"
+
+func TestToHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "Empty content",
+			content:  "",
+			expected: "",
+		},
+		{
+			name:     "Heading and paragraph",
+			content:  "# Title\n\nFirst line\nsecond line",
+			expected: "<h1>Title</h1>\n<p>First line\nsecond line</p>\n",
+		},
+		{
+			name:     "Unordered and ordered lists",
+			content:  "- one\n- two\n1. first\n2. second",
+			expected: "<ul>\n<li>one</li>\n<li>two</li>\n</ul>\n<ol>\n<li>first</li>\n<li>second</li>\n</ol>\n",
+		},
+		{
+			name:     "Fenced code block is escaped but not formatted",
+			content:  "```go\nif a < b && **c** {\n```",
+			expected: "<pre><code class=\"language-go\">if a &lt; b &amp;&amp; **c** {\n</code></pre>\n",
+		},
+		{
+			name:     "Unclosed code block is closed",
+			content:  "```\ncode",
+			expected: "<pre><code>code\n</code></pre>\n",
+		},
+		{
+			name:     "Inline formatting",
+			content:  "**bold**, *italic* and `a*b*c`",
+			expected: "<p><strong>bold</strong>, <em>italic</em> and <code>a*b*c</code></p>\n",
+		},
+		{
+			name:     "Raw HTML is escaped",
+			content:  "<script>alert('x')</script>",
+			expected: "<p>&lt;script&gt;alert(&#39;x&#39;)&lt;/script&gt;</p>\n",
+		},
+		{
+			name:     "Safe link",
+			content:  "[docs](https://example.com/a?b=1&c=2)",
+			expected: "<p><a href=\"https://example.com/a?b=1&amp;c=2\" rel=\"noopener noreferrer\" target=\"_blank\">docs</a></p>\n",
+		},
+		{
+			name:     "Unsafe link keeps only the label",
+			content:  "[click](javascript:alert(1))",
+			expected: "<p>click)</p>\n",
+		},
+		{
+			name:     "Blockquote and horizontal rule",
+			content:  "> quoted\n\n---",
+			expected: "<blockquote>quoted</blockquote>\n<hr>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ToHTML(tt.content)
+			if result != tt.expected {
+				t.Errorf("ToHTML() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
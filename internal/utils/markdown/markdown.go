@@ -96,6 +96,43 @@ func sanitizeHTML(content string) string {
 	return content
 }
 
+// ShiftHeadings demotes every ATX heading outside code blocks by the given number of levels, capped at level 6.
+// It is used to nest notes under a heading of a larger document.
+func ShiftHeadings(content string, levels int) string {
+	lines := strings.Split(content, "\n")
+	inCode := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+
+		match := headingLineRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		level := min(len(match[1])+levels, 6)
+		lines[i] = strings.Repeat("#", level) + " " + match[2]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// EscapeInline escapes the characters that would otherwise start inline formatting, links or table cells,
+// so plain text such as a task title can be embedded in a markdown document as is
+func EscapeInline(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if strings.ContainsRune("\\`*_[]<>|", r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
 // normalizeLineEndings ensures consistent line endings (LF)
 func normalizeLineEndings(content string) string {
 	// Replace CRLF with LF
@@ -186,3 +186,48 @@ func TestValidateBalancedElements(t *testing.T) {
 		})
 	}
 }
+
+func TestShiftHeadings(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		levels   int
+		expected string
+	}{
+		{
+			name:     "Headings are demoted",
+			content:  "# Title\n\nText\n\n## Section",
+			levels:   2,
+			expected: "### Title\n\nText\n\n#### Section",
+		},
+		{
+			name:     "Level is capped at six",
+			content:  "##### Deep",
+			levels:   2,
+			expected: "###### Deep",
+		},
+		{
+			name:     "Code blocks are left alone",
+			content:  "```bash\n# comment\n```\n# Title",
+			levels:   1,
+			expected: "```bash\n# comment\n```\n## Title",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ShiftHeadings(tt.content, tt.levels)
+			if result != tt.expected {
+				t.Errorf("ShiftHeadings() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEscapeInline(t *testing.T) {
+	result := EscapeInline("Fix *all* the [bugs] in snake_case | `code`")
+	expected := "Fix \\*all\\* the \\[bugs\\] in snake\\_case \\| \\`code\\`"
+	if result != expected {
+		t.Errorf("EscapeInline() = %q, want %q", result, expected)
+	}
+}
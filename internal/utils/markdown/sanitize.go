@@ -0,0 +1,182 @@
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Stored text ends up in the context of other agents, which read it as instructions as readily as a human reads
+// it as content. A sanitization policy removes what a reader cannot see or what could change how the text is
+// rendered around it: invisible characters, HTML, links and code fences.
+
+// Steps of a sanitization policy, by their names in policy settings
+const (
+	// StepUnicode normalizes text to NFKC and removes invisible format and control characters such as zero-width
+	// spaces, bidirectional overrides and tag characters
+	StepUnicode = "unicode"
+	// StepHTML removes script, iframe and other active HTML elements, HTML comments, event handler attributes
+	// and javascript: URLs
+	StepHTML = "html"
+	// StepLinks replaces markdown links and images with their text followed by the URL, so a link cannot hide
+	// where it points
+	StepLinks = "links"
+	// StepFences escapes code fence markers, so text cannot close a code block it is embedded in
+	StepFences = "fences"
+)
+
+// PolicyNone is the policy setting that turns sanitization off
+const PolicyNone = "none"
+
+// activeTags are the HTML elements that run code, load content or take input
+const activeTags = "script|iframe|object|embed|form|input|button|style|link|meta|base|frame|frameset|applet"
+
+var (
+	htmlCommentRegex      = regexp.MustCompile(`<!--[\s\S]*?(?:-->|$)`)
+	activeTagRegex        = regexp.MustCompile(`(?i)</?(?:` + activeTags + `)[\s/][^>]*>`)
+	eventHandlerRegex     = regexp.MustCompile(`(?i)(<[a-z][^>]*?)\s+on[a-z]+\s*=\s*(?:"[^"]*"|'[^']*'|[^\s>]+)`)
+	scriptURLRegex        = regexp.MustCompile(`(?i)([("'=<]\s*)(?:javascript|vbscript)\s*:`)
+	markdownImageRegex    = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+	markdownLinkRegex     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+	markdownAutolinkRegex = regexp.MustCompile(`<((?:https?|mailto|ftp):[^>\s]+)>`)
+	fenceLineRegex        = regexp.MustCompile("^( {0,3})(```|~~~)")
+	codeBlockLineRegex    = regexp.MustCompile("^ {0,3}\\\\?(?:```|~~~)")
+)
+
+// Policy selects the steps applied to a kind of stored text
+type Policy struct {
+	Unicode bool
+	HTML    bool
+	Links   bool
+	Fences  bool
+}
+
+// ParsePolicy parses a comma separated list of steps, such as "unicode,html". An empty list or "none" turns
+// sanitization off.
+func ParsePolicy(spec string) (Policy, error) {
+	var policy Policy
+	for _, step := range strings.Split(spec, ",") {
+		switch strings.ToLower(strings.TrimSpace(step)) {
+		case "", PolicyNone:
+		case StepUnicode:
+			policy.Unicode = true
+		case StepHTML:
+			policy.HTML = true
+		case StepLinks:
+			policy.Links = true
+		case StepFences:
+			policy.Fences = true
+		default:
+			return Policy{}, fmt.Errorf("unknown sanitization step %q, expected %s, %s, %s, %s or %s",
+				step, StepUnicode, StepHTML, StepLinks, StepFences, PolicyNone)
+		}
+	}
+	return policy, nil
+}
+
+// String returns the policy in the format ParsePolicy reads
+func (p Policy) String() string {
+	var steps []string
+	if p.Unicode {
+		steps = append(steps, StepUnicode)
+	}
+	if p.HTML {
+		steps = append(steps, StepHTML)
+	}
+	if p.Links {
+		steps = append(steps, StepLinks)
+	}
+	if p.Fences {
+		steps = append(steps, StepFences)
+	}
+	if len(steps) == 0 {
+		return PolicyNone
+	}
+	return strings.Join(steps, ",")
+}
+
+// Apply sanitizes text with the steps of the policy. Unicode is normalized first, so characters that only look
+// like markup after normalization, such as fullwidth angle brackets, are caught by the other steps. Applying a
+// policy to text it already sanitized leaves the text unchanged.
+func (p Policy) Apply(text string) string {
+	if p.Unicode {
+		text = normalizeUnicode(text)
+	}
+	if p.HTML {
+		text = stripActiveHTML(text)
+	}
+	if p.Links {
+		text = outsideCodeBlocks(text, neutralizeLinks)
+	}
+	if p.Fences {
+		text = escapeFences(text)
+	}
+	return text
+}
+
+// normalizeUnicode normalizes text to NFKC and removes format and control characters other than line breaks
+// and tabs
+func normalizeUnicode(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' || r == '\t' {
+			return r
+		}
+		if unicode.Is(unicode.Cf, r) || unicode.Is(unicode.Cc, r) {
+			return -1
+		}
+		return r
+	}, norm.NFKC.String(text))
+}
+
+// stripActiveHTML removes active HTML elements with their content, comments, the opening and closing tags of
+// active elements that carry attributes, event handler attributes and the scheme of script URLs. Tags without
+// attributes, such as a <script> mentioned in prose, are left alone when they do not enclose anything.
+func stripActiveHTML(text string) string {
+	text = sanitizeHTML(text)
+	text = htmlCommentRegex.ReplaceAllString(text, "")
+	text = activeTagRegex.ReplaceAllString(text, "")
+	for {
+		stripped := eventHandlerRegex.ReplaceAllString(text, "$1")
+		if stripped == text {
+			break
+		}
+		text = stripped
+	}
+	return scriptURLRegex.ReplaceAllString(text, "${1}blocked:")
+}
+
+// neutralizeLinks replaces images and links with their text and URL, and autolinks with their URL
+func neutralizeLinks(line string) string {
+	line = markdownImageRegex.ReplaceAllString(line, "$1 ($2)")
+	line = markdownLinkRegex.ReplaceAllString(line, "$1 ($2)")
+	return markdownAutolinkRegex.ReplaceAllString(line, "$1")
+}
+
+// escapeFences escapes the first character of every code fence marker, which makes it literal text
+func escapeFences(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = fenceLineRegex.ReplaceAllString(line, `$1\$2`)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// outsideCodeBlocks applies a function to every line outside fenced code blocks. Fences escaped by an earlier
+// pass still delimit the blocks they used to, so sanitizing the text again leaves those blocks alone.
+func outsideCodeBlocks(text string, apply func(line string) string) string {
+	lines := strings.Split(text, "\n")
+	inCode := false
+	for i, line := range lines {
+		if codeBlockLineRegex.MatchString(line) {
+			inCode = !inCode
+			continue
+		}
+		if !inCode {
+			lines[i] = apply(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,298 @@
+// Package taskclient is a Go client for the REST API of the task management server, which is served when the
+// server runs with ENABLE_REST_API=true. It offers typed methods for the plan and task operations, so Go
+// programs such as agent frameworks and test harnesses need not build requests by hand.
+package taskclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/api"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// Types of the requests and responses, shared with the server
+type (
+	// Plan is a plan of tasks for a feature of an application
+	Plan = models.Plan
+	// PlanStatus is the status of a plan
+	PlanStatus = models.PlanStatus
+	// PlanPriority is the priority of a plan among the plans of its application
+	PlanPriority = models.PlanPriority
+	// Task is a task of a plan
+	Task = models.Task
+	// TaskStatus is the status of a task
+	TaskStatus = models.TaskStatus
+	// TaskPriority is the priority of a task
+	TaskPriority = models.TaskPriority
+	// ErrorCode classifies the errors of the server, such as NOT_FOUND or VALIDATION
+	ErrorCode = models.ErrorCode
+	// PlanBackup is a plan with its tasks, as exported and imported
+	PlanBackup = models.PlanResource
+
+	// PlanCreateRequest holds the fields of a new plan
+	PlanCreateRequest = api.PlanCreateRequest
+	// PlanUpdateRequest holds the fields of a plan to change; nil fields are left unchanged
+	PlanUpdateRequest = api.PlanUpdateRequest
+	// TaskCreateRequest holds the fields of a new task
+	TaskCreateRequest = api.TaskCreateRequest
+	// TaskUpdateRequest holds the fields of a task to change; nil fields are left unchanged
+	TaskUpdateRequest = api.TaskUpdateRequest
+	// BulkTask holds the fields of one of the tasks created by BulkCreateTasks
+	BulkTask = api.BulkTask
+)
+
+// defaultTimeout bounds requests sent with the default HTTP client
+const defaultTimeout = 30 * time.Second
+
+// Error is an error returned by the REST API
+type Error struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+	// Message is the error message of the server, or the status if there is none
+	Message string
+	// Code classifies errors of the storage, such as NOT_FOUND; it is empty if the server did not send one
+	Code ErrorCode
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Client sends requests to the REST API of a server
+type Client struct {
+	baseURL string
+	http    *http.Client
+	token   string
+}
+
+// Option configures a client
+type Option func(*Client)
+
+// WithHTTPClient sends the requests with the given HTTP client instead of one with a 30 second timeout
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.http = httpClient
+	}
+}
+
+// WithToken sends a bearer token with every request, for servers that require APPLICATION_TOKENS
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// New creates a client for the server at the given URL, such as http://localhost:8080
+func New(serverURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimSuffix(serverURL, "/") + api.BasePath,
+		http:    &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// PlanFilter selects the plans returned by ListPlans; empty fields match every plan
+type PlanFilter struct {
+	ApplicationID string
+	Status        PlanStatus
+}
+
+// ListPlans lists the plans matching the filter
+func (c *Client) ListPlans(ctx context.Context, filter PlanFilter) ([]*Plan, error) {
+	query := url.Values{}
+	if filter.ApplicationID != "" {
+		query.Set("application_id", filter.ApplicationID)
+	}
+	if filter.Status != "" {
+		query.Set("status", string(filter.Status))
+	}
+
+	var plans []*Plan
+	err := c.do(ctx, http.MethodGet, withQuery("/plans", query), nil, &plans)
+	return plans, err
+}
+
+// CreatePlan creates a plan
+func (c *Client) CreatePlan(ctx context.Context, req PlanCreateRequest) (*Plan, error) {
+	var plan Plan
+	if err := c.do(ctx, http.MethodPost, "/plans", req, &plan); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// GetPlan gets a plan
+func (c *Client) GetPlan(ctx context.Context, id string) (*Plan, error) {
+	var plan Plan
+	if err := c.do(ctx, http.MethodGet, planPath(id), nil, &plan); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// UpdatePlan updates the fields of a plan set in the request
+func (c *Client) UpdatePlan(ctx context.Context, id string, req PlanUpdateRequest) (*Plan, error) {
+	var plan Plan
+	if err := c.do(ctx, http.MethodPatch, planPath(id), req, &plan); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// DeletePlan deletes a plan and its tasks
+func (c *Client) DeletePlan(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, planPath(id), nil, nil)
+}
+
+// ExportPlan exports a plan and its tasks as a backup
+func (c *Client) ExportPlan(ctx context.Context, id string) (*PlanBackup, error) {
+	var backup PlanBackup
+	if err := c.do(ctx, http.MethodGet, planPath(id)+"/export", nil, &backup); err != nil {
+		return nil, err
+	}
+	return &backup, nil
+}
+
+// ImportPlan restores a plan and its tasks from a backup, keeping their IDs
+func (c *Client) ImportPlan(ctx context.Context, backup *PlanBackup) (*PlanBackup, error) {
+	var restored PlanBackup
+	if err := c.do(ctx, http.MethodPost, "/plans/import", backup, &restored); err != nil {
+		return nil, err
+	}
+	return &restored, nil
+}
+
+// TaskFilter selects the tasks returned by ListTasks; empty fields match every task
+type TaskFilter struct {
+	Status TaskStatus
+}
+
+// ListTasks lists the tasks of a plan matching the filter in order
+func (c *Client) ListTasks(ctx context.Context, planID string, filter TaskFilter) ([]*Task, error) {
+	query := url.Values{}
+	if filter.Status != "" {
+		query.Set("status", string(filter.Status))
+	}
+
+	var tasks []*Task
+	err := c.do(ctx, http.MethodGet, withQuery(planPath(planID)+"/tasks", query), nil, &tasks)
+	return tasks, err
+}
+
+// CreateTask adds a task to the end of a plan
+func (c *Client) CreateTask(ctx context.Context, planID string, req TaskCreateRequest) (*Task, error) {
+	var task Task
+	if err := c.do(ctx, http.MethodPost, planPath(planID)+"/tasks", req, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// BulkCreateTasks adds several tasks to the end of a plan in one operation
+func (c *Client) BulkCreateTasks(ctx context.Context, planID string, tasks []BulkTask) ([]*Task, error) {
+	var created []*Task
+	req := api.TaskBulkCreateRequest{Tasks: tasks}
+	if err := c.do(ctx, http.MethodPost, planPath(planID)+"/tasks/bulk", req, &created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// GetTask gets a task
+func (c *Client) GetTask(ctx context.Context, id string) (*Task, error) {
+	var task Task
+	if err := c.do(ctx, http.MethodGet, taskPath(id), nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// UpdateTask updates the fields of a task set in the request
+func (c *Client) UpdateTask(ctx context.Context, id string, req TaskUpdateRequest) (*Task, error) {
+	var task Task
+	if err := c.do(ctx, http.MethodPatch, taskPath(id), req, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// DeleteTask deletes a task
+func (c *Client) DeleteTask(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, taskPath(id), nil, nil)
+}
+
+// do sends a request with an optional JSON body and decodes the JSON response into out if it is not nil
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := &Error{StatusCode: resp.StatusCode, Message: fmt.Sprintf("server returned %s", resp.Status)}
+		var errResp api.ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errResp); err == nil && errResp.Error != "" {
+			apiErr.Message = errResp.Error
+			apiErr.Code = errResp.Code
+		}
+		return apiErr
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// planPath returns the path of a plan
+func planPath(id string) string {
+	return "/plans/" + url.PathEscape(id)
+}
+
+// taskPath returns the path of a task
+func taskPath(id string) string {
+	return "/tasks/" + url.PathEscape(id)
+}
+
+// withQuery appends a query string to a path if there is one
+func withQuery(path string, query url.Values) string {
+	if len(query) == 0 {
+		return path
+	}
+	return path + "?" + query.Encode()
+}
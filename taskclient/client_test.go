@@ -0,0 +1,115 @@
+package taskclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/api"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// newTestClient serves the REST API on top of in-memory storage and returns a client for it
+func newTestClient(t *testing.T, opts ...Option) *Client {
+	t.Helper()
+	valkeyClient, err := storage.NewMemoryClient(storage.MemoryConfig{})
+	if err != nil {
+		t.Fatalf("failed to create in-memory storage: %v", err)
+	}
+	t.Cleanup(func() { valkeyClient.Close() })
+
+	server := httptest.NewServer(api.NewHandler(
+		storage.NewPlanRepository(valkeyClient),
+		storage.NewTaskRepository(valkeyClient),
+	))
+	t.Cleanup(server.Close)
+	return New(server.URL+"/", opts...)
+}
+
+func TestClientPlansAndTasks(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	plan, err := client.CreatePlan(ctx, PlanCreateRequest{ApplicationID: "app", Name: "Release"})
+	if err != nil {
+		t.Fatalf("CreatePlan failed: %v", err)
+	}
+
+	tasks, err := client.BulkCreateTasks(ctx, plan.ID, []BulkTask{
+		{Title: "Write notes", Priority: "high"},
+		{Title: "Tag release", Status: "completed"},
+	})
+	if err != nil {
+		t.Fatalf("BulkCreateTasks failed: %v", err)
+	}
+	if len(tasks) != 2 || tasks[0].Priority != models.TaskPriorityHigh || tasks[1].Status != models.TaskStatusCompleted {
+		t.Fatalf("unexpected tasks %+v", tasks)
+	}
+
+	pending, err := client.ListTasks(ctx, plan.ID, TaskFilter{Status: models.TaskStatusPending})
+	if err != nil {
+		t.Fatalf("ListTasks failed: %v", err)
+	}
+	if len(pending) != 1 || pending[0].Title != "Write notes" {
+		t.Errorf("expected the pending task, got %+v", pending)
+	}
+
+	status := string(models.TaskStatusInProgress)
+	task, err := client.UpdateTask(ctx, tasks[0].ID, TaskUpdateRequest{Status: &status})
+	if err != nil {
+		t.Fatalf("UpdateTask failed: %v", err)
+	}
+	if task.Status != models.TaskStatusInProgress {
+		t.Errorf("status = %s, want %s", task.Status, models.TaskStatusInProgress)
+	}
+
+	plans, err := client.ListPlans(ctx, PlanFilter{ApplicationID: "app"})
+	if err != nil {
+		t.Fatalf("ListPlans failed: %v", err)
+	}
+	if len(plans) != 1 || plans[0].ID != plan.ID {
+		t.Errorf("expected the created plan, got %+v", plans)
+	}
+
+	if err := client.DeletePlan(ctx, plan.ID); err != nil {
+		t.Fatalf("DeletePlan failed: %v", err)
+	}
+	_, err = client.GetPlan(ctx, plan.ID)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Code != models.ErrorCodeNotFound {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestClientReportsValidationErrors(t *testing.T) {
+	client := newTestClient(t)
+
+	_, err := client.BulkCreateTasks(context.Background(), "plan-1", []BulkTask{{Title: "Task", Priority: "urgent"}})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected a bad request error, got %v", err)
+	}
+	if apiErr.Message != "task 0: invalid priority: urgent" {
+		t.Errorf("unexpected message %q", apiErr.Message)
+	}
+}
+
+func TestClientSendsToken(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := New(server.URL, WithToken("secret"))
+	if err := client.DeleteTask(context.Background(), "task-1"); err != nil {
+		t.Fatalf("DeleteTask failed: %v", err)
+	}
+	if authorization != "Bearer secret" {
+		t.Errorf("Authorization = %q, want the bearer token", authorization)
+	}
+}
@@ -0,0 +1,304 @@
+package taskserver
+
+import (
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/integrations/github"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/integrations/jira"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/mcp"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/scheduler"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// Types of the configuration, shared with the standalone server
+type (
+	// MemoryConfig holds the settings of the in-memory store
+	MemoryConfig = storage.MemoryConfig
+	// ValkeyConfig holds the settings of the connection to Valkey
+	ValkeyConfig = storage.ValkeyConfig
+	// ValkeyAddress is the host and port of a Valkey node
+	ValkeyAddress = storage.ValkeyAddress
+	// RetryPolicy controls how reads failing on transient errors are retried
+	RetryPolicy = storage.RetryPolicy
+	// Limits are the size limits of plans and tasks
+	Limits = storage.Limits
+	// SanitizePolicies are the sanitization policies of the kinds of stored text
+	SanitizePolicies = storage.SanitizePolicies
+	// AttachmentLimits bound the size and number of task attachments
+	AttachmentLimits = storage.AttachmentLimits
+	// DecompositionGuardrails flag tasks and plans that should be split into smaller ones
+	DecompositionGuardrails = storage.DecompositionGuardrails
+	// ChaosConfig configures the failures and latency injected into repository calls
+	ChaosConfig = storage.ChaosConfig
+	// KeySource provides the keys descriptions and notes are encrypted with
+	KeySource = storage.KeySource
+	// StaticKeys is a key source of keys held in memory
+	StaticKeys = storage.StaticKeys
+	// KMSKeys is a key source of keys wrapped by a key management service
+	KMSKeys = storage.KMSKeys
+	// KeyDecrypter unwraps keys with a key management service
+	KeyDecrypter = storage.KeyDecrypter
+	// AuditRetention bounds the audit log
+	AuditRetention = storage.AuditRetention
+	// AuditSink receives the audit entries exported to a SIEM or log pipeline
+	AuditSink = services.AuditSink
+	// AuditExportConfig configures the batching and format of exported audit entries
+	AuditExportConfig = services.AuditExportConfig
+	// AuditFormat is the format audit entries are exported in
+	AuditFormat = services.AuditFormat
+	// NotesSummarizer summarizes the notes archived by notes compaction
+	NotesSummarizer = storage.NotesSummarizer
+	// Notifier delivers the notifications for the watchers of plans
+	Notifier = storage.Notifier
+	// RetentionPolicy controls when and how old completed and cancelled plans are expired
+	RetentionPolicy = services.RetentionPolicy
+	// RetentionAction is what happens to expired plans
+	RetentionAction = services.RetentionAction
+	// EscalationPolicy controls when neglected pending tasks have their priority raised
+	EscalationPolicy = services.EscalationPolicy
+	// FieldMapping maps Jira issue fields to task fields
+	FieldMapping = jira.FieldMapping
+	// ServerConfig configures the transports, access and rate limits of the MCP server
+	ServerConfig = mcp.ServerConfig
+	// TaskStatusDefinition defines a custom task status and whether it is open or active
+	TaskStatusDefinition = models.TaskStatusDefinition
+	// TaskPriorityDefinition defines a custom task priority and its weight
+	TaskPriorityDefinition = models.TaskPriorityDefinition
+)
+
+// Retention actions
+const (
+	RetentionArchive = services.RetentionArchive
+	RetentionDelete  = services.RetentionDelete
+)
+
+// Statuses of the review workflow, enabled with Config.ReviewStatuses
+const (
+	TaskStatusInReview = models.TaskStatusInReview
+	TaskStatusApproved = models.TaskStatusApproved
+	TaskStatusRejected = models.TaskStatusRejected
+)
+
+// Categories of custom task statuses
+const (
+	TaskStatusCategoryOpen   = models.TaskStatusCategoryOpen
+	TaskStatusCategoryActive = models.TaskStatusCategoryActive
+)
+
+// Storage backends
+const (
+	StorageValkey = "valkey"
+	StorageMemory = "memory"
+)
+
+// Config holds the settings of an embedded server. Start from DefaultConfig, which matches the defaults of
+// the standalone server.
+type Config struct {
+	// Storage is the storage backend, StorageValkey or StorageMemory
+	Storage string
+	// Memory configures the in-memory store
+	Memory MemoryConfig
+	// Valkey configures the connection to Valkey
+	Valkey ValkeyConfig
+	// Retry controls how reads failing on transient errors are retried
+	Retry RetryPolicy
+	// Limits are the size limits enforced on writes
+	Limits Limits
+	// Sanitize are the policies text is sanitized with before it is stored
+	Sanitize SanitizePolicies
+	// AttachmentLimits bound the attachments added to tasks
+	AttachmentLimits AttachmentLimits
+	// Decomposition warns about, or rejects, tasks and plans that should be split into smaller ones
+	Decomposition DecompositionGuardrails
+	// Chaos injects failures and latency into repository calls, for tests and staging only
+	Chaos ChaosConfig
+	// FieldEncryption encrypts descriptions and notes at rest with its keys when set
+	FieldEncryption KeySource
+
+	// Port is the port the HTTP transports listen on
+	Port int
+	// Server configures the MCP server. When nil it is read from the environment and the config file like the
+	// standalone server does, and Reload reads it again.
+	Server *ServerConfig
+
+	// NotesHistoryLength is how many previous versions of plan notes are kept
+	NotesHistoryLength int
+	// NotesCompactLength archives the older part of notes longer than this many bytes, zero turns it off
+	NotesCompactLength int
+	// NotesSummarizer optionally summarizes archived notes
+	NotesSummarizer NotesSummarizer
+	// AgentActivityLength is how many agent activity entries are kept per plan, zero keeping every entry
+	AgentActivityLength int
+
+	// Audit configures the audit log
+	Audit AuditConfig
+	// Notifications configures how the watchers of plans are notified of changes
+	Notifications NotificationsConfig
+	// ResourceCache configures the cache of the full plan resources
+	ResourceCache ResourceCacheConfig
+	// Jobs configures the background jobs
+	Jobs JobsConfig
+	// Retention expires old completed and cancelled plans when its MaxAge is positive
+	Retention RetentionPolicy
+	// RetentionSweepInterval is how often the retention job runs
+	RetentionSweepInterval time.Duration
+	// Escalation raises the priority of neglected pending tasks when its After is positive
+	Escalation EscalationPolicy
+	// EscalationSweepInterval is how often the escalation job runs
+	EscalationSweepInterval time.Duration
+
+	// IdempotencyTTL is how long the results of create calls with an idempotency key are remembered
+	IdempotencyTTL time.Duration
+	// AdminTools offers the maintenance tools to MCP clients
+	AdminTools bool
+	// RequireKnownApplications rejects new plans of applications that are not registered
+	RequireKnownApplications bool
+	// RequireCompletionNotes rejects completing tasks without a completion note
+	RequireCompletionNotes bool
+	// RequireAcceptanceCriteria rejects completing tasks with acceptance criteria that are not met
+	RequireAcceptanceCriteria bool
+	// ReviewStatuses are the statuses of the review workflow tasks may be set to, none by default
+	ReviewStatuses []TaskStatus
+	// CustomStatuses are further statuses tasks may be set to, each open or active
+	CustomStatuses []TaskStatusDefinition
+	// CustomPriorities are further priorities tasks may have, ranked by their weight
+	CustomPriorities []TaskPriorityDefinition
+
+	// GitHub configures the GitHub issue sync tools
+	GitHub GitHubConfig
+	// Jira configures the Jira import and status push tools
+	Jira JiraConfig
+}
+
+// AuditConfig configures the audit log
+type AuditConfig struct {
+	// Enabled records every change in the audit log
+	Enabled bool
+	// Retention bounds the number and age of entries
+	Retention AuditRetention
+	// Sinks receive every change exported as it is recorded, such as a file, syslog or a SIEM endpoint
+	Sinks []AuditSink
+	// Export configures the batching and format of the exported changes
+	Export AuditExportConfig
+}
+
+// NotificationsConfig configures the notifications sent to the watchers of plans when a plan or one of its
+// tasks changes. Changes are seen through the audit log, so notifications need it enabled.
+type NotificationsConfig struct {
+	// Notifiers deliver the notifications, such as to a webhook or Slack
+	Notifiers []Notifier
+	// Stream publishes the notifications to the notifications stream in the storage
+	Stream bool
+}
+
+// ResourceCacheConfig configures the cache of the full plan resources, which is emptied whenever a plan or
+// task changes. Changes are seen through the audit log, so the cache needs it enabled.
+type ResourceCacheConfig struct {
+	// TTL is how long a resource is kept at most, in case a change is missed; zero turns the cache off
+	TTL time.Duration
+	// MaxEntries bounds the number of resources kept
+	MaxEntries int
+	// PollInterval is how often the change stream is checked for changes made through other replicas
+	PollInterval time.Duration
+}
+
+// JobsConfig configures the background jobs
+type JobsConfig struct {
+	// LeaseExpiry returns tasks with expired leases to pending every LeaseSweepInterval
+	LeaseExpiry        bool
+	LeaseSweepInterval time.Duration
+	// OrphanCleanup deletes tasks left behind by deleted plans every OrphanCleanupInterval
+	OrphanCleanup         bool
+	OrphanCleanupInterval time.Duration
+	// Locking runs each job on one replica at a time
+	Locking bool
+	// LeaderElection runs the jobs only on the elected replica
+	LeaderElection bool
+	// Jitter spreads the runs of a job by up to this fraction of its interval
+	Jitter float64
+	// ReplicaID identifies this server in the job locks, the host name and process ID when empty
+	ReplicaID string
+}
+
+// GitHubConfig configures the GitHub issue sync tools, which are enabled when Token is set
+type GitHubConfig struct {
+	Token string
+	// Repo is the default owner/name repository
+	Repo   string
+	APIURL string
+}
+
+// JiraConfig configures the Jira connector, which is enabled when URL is set
+type JiraConfig struct {
+	URL     string
+	Email   string
+	Token   string
+	Mapping FieldMapping
+}
+
+// DefaultConfig returns the configuration of the standalone server without any settings: Valkey on
+// localhost:6379 and the MCP server on port 8080
+func DefaultConfig() Config {
+	return Config{
+		Storage: StorageValkey,
+		Memory: MemoryConfig{
+			SnapshotInterval: time.Minute,
+		},
+		Valkey: ValkeyConfig{
+			Addresses:      []ValkeyAddress{{Host: "localhost", Port: 6379}},
+			SentinelMaster: "mymaster",
+			PoolSize:       1,
+			ReconnectDelay: 100 * time.Millisecond,
+		},
+		Retry:            storage.DefaultRetryPolicy(),
+		Limits:           storage.DefaultLimits(),
+		Sanitize:         storage.DefaultSanitizePolicies(),
+		AttachmentLimits: storage.DefaultAttachmentLimits(),
+		Decomposition:    storage.DefaultDecompositionGuardrails(),
+
+		Port: 8080,
+
+		NotesHistoryLength:  storage.DefaultNotesHistoryLength,
+		AgentActivityLength: storage.DefaultAgentActivityLength,
+
+		Audit: AuditConfig{
+			Enabled:   true,
+			Retention: AuditRetention{MaxEntries: storage.DefaultAuditMaxEntries},
+			Export:    services.DefaultAuditExportConfig(),
+		},
+		ResourceCache: ResourceCacheConfig{
+			TTL:          time.Minute,
+			MaxEntries:   mcp.DefaultResourceCacheEntries,
+			PollInterval: time.Second,
+		},
+		Jobs: JobsConfig{
+			LeaseExpiry:           true,
+			LeaseSweepInterval:    30 * time.Second,
+			OrphanCleanupInterval: time.Hour,
+			Locking:               true,
+			LeaderElection:        true,
+			Jitter:                scheduler.DefaultJitter,
+		},
+		Retention: RetentionPolicy{
+			Action:     RetentionArchive,
+			ArchiveDir: "archive",
+		},
+		RetentionSweepInterval: time.Hour,
+		Escalation: EscalationPolicy{
+			MaxPriority: models.TaskPriorityHigh,
+		},
+		EscalationSweepInterval: time.Hour,
+
+		IdempotencyTTL: storage.DefaultIdempotencyTTL,
+
+		GitHub: GitHubConfig{APIURL: github.DefaultAPIURL},
+		Jira:   JiraConfig{Mapping: jira.DefaultFieldMapping()},
+	}
+}
+
+// DefaultServerConfig returns the MCP server configuration of the standalone server without any settings
+func DefaultServerConfig() ServerConfig {
+	return mcp.DefaultServerConfig()
+}
@@ -0,0 +1,520 @@
+// Package taskserver embeds the task management server in another Go program. New connects to the storage
+// and wires the repositories, background jobs and MCP server like the standalone server does; Start serves
+// the MCP server and Stop shuts everything down. The repositories are available directly, so the program can
+// read and change plans and tasks without going through MCP.
+package taskserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/integrations/github"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/integrations/jira"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/mcp"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/scheduler"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// Types of the repositories and the plans and tasks they store
+type (
+	// PlanRepository stores plans
+	PlanRepository = storage.PlanRepositoryInterface
+	// TaskRepository stores tasks
+	TaskRepository = storage.TaskRepositoryInterface
+	// ApplicationRepository stores registered applications
+	ApplicationRepository = storage.ApplicationRepositoryInterface
+	// Application is a registered application that plans belong to
+	Application = models.Application
+	// Plan is a plan of tasks for a feature of an application
+	Plan = models.Plan
+	// PlanStatus is the status of a plan
+	PlanStatus = models.PlanStatus
+	// PlanPriority is the priority of a plan among the plans of its application
+	PlanPriority = models.PlanPriority
+	// Task is a task of a plan
+	Task = models.Task
+	// TaskStatus is the status of a task
+	TaskStatus = models.TaskStatus
+	// TaskPriority is the priority of a task
+	TaskPriority = models.TaskPriority
+)
+
+// Server is an embedded task management server
+type Server struct {
+	config         Config
+	valkeyClient   *storage.ValkeyClient
+	planRepo       PlanRepository
+	taskRepo       TaskRepository
+	appRepo        ApplicationRepository
+	notesCompactor *storage.NotesCompactor
+	watchNotifier  *services.WatchNotifier
+	auditExporters []*services.AuditExporter
+	jobScheduler   *scheduler.Scheduler
+	mcpServer      *mcp.MCPGoServer
+	// followChanges empties the resource cache on the changes of other replicas until its context is done
+	followChanges func(ctx context.Context)
+
+	stopJobs context.CancelFunc
+	jobsDone chan struct{}
+}
+
+// New connects to the configured storage and creates a server. The storage is closed by Stop, or right away
+// if New fails.
+func New(cfg Config) (*Server, error) {
+	ctx := context.Background()
+
+	// Tool schemas list the task statuses and priorities, so they are configured before the tools are registered
+	if err := models.EnableReviewStatuses(cfg.ReviewStatuses); err != nil {
+		return nil, err
+	}
+	if err := models.SetCustomTaskStatuses(cfg.CustomStatuses); err != nil {
+		return nil, err
+	}
+	if err := models.SetCustomTaskPriorities(cfg.CustomPriorities); err != nil {
+		return nil, err
+	}
+	if len(cfg.ReviewStatuses) > 0 {
+		log.Printf("Review workflow enabled (statuses: %v)", cfg.ReviewStatuses)
+	}
+	if len(cfg.CustomStatuses) > 0 || len(cfg.CustomPriorities) > 0 {
+		log.Printf("Task statuses: %v, priorities: %v", models.TaskStatuses(), models.TaskPriorities())
+	}
+
+	var fieldCipher *storage.FieldCipher
+	if cfg.FieldEncryption != nil {
+		var err error
+		fieldCipher, err = storage.NewFieldCipher(ctx, cfg.FieldEncryption)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up field encryption: %w", err)
+		}
+	}
+
+	valkeyClient, err := connect(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if fieldCipher != nil {
+		valkeyClient.SetFieldCipher(fieldCipher)
+		log.Printf("Descriptions and notes are encrypted at rest")
+	}
+
+	// Refuse data written by a newer server, which this one could misread
+	if err := valkeyClient.EnsureSchemaVersion(ctx); err != nil {
+		valkeyClient.Close()
+		return nil, fmt.Errorf("failed to check schema version: %w", err)
+	}
+
+	s := &Server{config: cfg, valkeyClient: valkeyClient}
+	s.wire()
+	return s, nil
+}
+
+// connect creates the client of the configured storage backend
+func connect(ctx context.Context, cfg Config) (*storage.ValkeyClient, error) {
+	switch cfg.Storage {
+	case StorageMemory:
+		valkeyClient, err := storage.NewMemoryClient(cfg.Memory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize in-memory storage: %w", err)
+		}
+		if cfg.Memory.SnapshotFile != "" {
+			log.Printf("Using in-memory storage with snapshots in %s", cfg.Memory.SnapshotFile)
+		} else {
+			log.Printf("Using in-memory storage; data is lost when the server stops")
+		}
+		return valkeyClient, nil
+	case StorageValkey:
+		valkeyClient, err := storage.NewValkeyClientWithConfig(cfg.Valkey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Valkey client: %w", err)
+		}
+
+		// Ping Valkey to ensure connection
+		if err := valkeyClient.Ping(ctx); err != nil {
+			valkeyClient.Close()
+			return nil, fmt.Errorf("failed to connect to Valkey: %w", err)
+		}
+		switch {
+		case cfg.Valkey.Cluster:
+			// Keep a plan and its tasks in one hash slot
+			storage.SetClusterKeyLayout(true)
+			log.Printf("Connected to Valkey Cluster through %d seed node(s)", len(cfg.Valkey.Addresses))
+		case len(cfg.Valkey.SentinelAddresses) > 0:
+			log.Printf("Connected to Valkey primary %q through Sentinel", cfg.Valkey.SentinelMaster)
+		default:
+			log.Printf("Connected to Valkey at %s:%d", cfg.Valkey.Addresses[0].Host, cfg.Valkey.Addresses[0].Port)
+		}
+		return valkeyClient, nil
+	default:
+		return nil, fmt.Errorf("invalid storage backend %q", cfg.Storage)
+	}
+}
+
+// wire creates the repositories, the background jobs and the MCP server on top of the storage client
+func (s *Server) wire() {
+	cfg := s.config
+	valkeyClient := s.valkeyClient
+	limits := cfg.Limits
+
+	// Initialize repositories
+	planRepo := storage.NewPlanRepository(valkeyClient)
+	planRepo.SetNotesHistoryLength(cfg.NotesHistoryLength)
+	planRepo.SetAgentActivityLength(cfg.AgentActivityLength)
+	planRepo.RequireKnownApplications(cfg.RequireKnownApplications)
+	taskRepo := storage.NewTaskRepository(valkeyClient)
+	taskRepo.RequireCompletionNotes(cfg.RequireCompletionNotes)
+	taskRepo.RequireMetAcceptanceCriteria(cfg.RequireAcceptanceCriteria)
+
+	// Archive the older part of notes past the compact length, optionally summarized by a webhook
+	if cfg.NotesCompactLength > 0 {
+		s.notesCompactor = storage.NewNotesCompactor(valkeyClient, cfg.NotesCompactLength, cfg.NotesSummarizer)
+		planRepo.SetNotesCompactor(s.notesCompactor)
+		taskRepo.SetNotesCompactor(s.notesCompactor)
+		limits.CompactNotes = true
+		log.Printf("Notes longer than %d bytes are archived", cfg.NotesCompactLength)
+	}
+
+	// Convert concrete types to interfaces
+	var planRepoInterface storage.PlanRepositoryInterface = planRepo
+	var taskRepoInterface storage.TaskRepositoryInterface = taskRepo
+	var serverOptions []mcp.ServerOption
+	if cfg.Server != nil {
+		serverOptions = append(serverOptions, mcp.WithServerConfig(*cfg.Server))
+	}
+	if s.notesCompactor != nil {
+		serverOptions = append(serverOptions, mcp.WithNotesCompactor(s.notesCompactor))
+	}
+
+	// Fail and slow down repository calls on purpose, to see how the layers above cope
+	var chaos *storage.Chaos
+	if cfg.Chaos.Enabled() {
+		chaos = storage.NewChaos(cfg.Chaos)
+		planRepoInterface = storage.NewChaosPlanRepository(planRepoInterface, chaos)
+		taskRepoInterface = storage.NewChaosTaskRepository(taskRepoInterface, chaos)
+		log.Printf("Warning: failure injection enabled (failure rate: %g, latency: %s, jitter: %s)",
+			cfg.Chaos.FailureRate, cfg.Chaos.Latency, cfg.Chaos.LatencyJitter)
+	}
+
+	// Retry reads that fail on a network blip before the error reaches an agent
+	planRepoInterface = storage.NewRetryingPlanRepository(planRepoInterface, cfg.Retry)
+	taskRepoInterface = storage.NewRetryingTaskRepository(taskRepoInterface, cfg.Retry)
+
+	// Reject writes beyond the size limits before they reach Valkey or the audit log
+	planRepoInterface = storage.NewLimitedPlanRepository(planRepoInterface, limits)
+	taskRepoInterface = storage.NewLimitedTaskRepository(taskRepoInterface, limits)
+
+	// Point agents at tasks and plans grown too large to work on in one piece
+	taskRepoInterface = storage.NewGuardedTaskRepository(taskRepoInterface, cfg.Decomposition)
+	if cfg.Decomposition.Block {
+		log.Printf("Tasks and plans beyond the decomposition guardrails are rejected")
+	}
+
+	// Sanitize text before it is stored, so agents reading it later do not see hidden content
+	planRepoInterface = storage.NewSanitizedPlanRepository(planRepoInterface, cfg.Sanitize)
+	taskRepoInterface = storage.NewSanitizedTaskRepository(taskRepoInterface, cfg.Sanitize)
+
+	// Record every change in the audit log unless it is disabled
+	var auditLog *storage.AuditLog
+	if cfg.Audit.Enabled {
+		auditLog = storage.NewAuditLog(valkeyClient, cfg.Audit.Retention)
+		planRepoInterface = storage.NewAuditedPlanRepository(planRepoInterface, auditLog)
+		taskRepoInterface = storage.NewAuditedTaskRepository(taskRepoInterface, auditLog)
+		serverOptions = append(serverOptions, mcp.WithAuditLog(auditLog))
+		log.Printf("Audit log enabled (max entries: %d, max age: %s)",
+			cfg.Audit.Retention.MaxEntries, cfg.Audit.Retention.MaxAge)
+	}
+
+	// Reject changes to locked plans and their tasks, except by admins
+	planRepoInterface = storage.NewLockedPlanRepository(planRepoInterface)
+	taskRepoInterface = storage.NewLockedTaskRepository(taskRepoInterface, planRepoInterface)
+
+	// Hide the plans of other applications from requests restricted to one application
+	taskRepoInterface = storage.NewScopedTaskRepository(taskRepoInterface, planRepoInterface)
+	planRepoInterface = storage.NewScopedPlanRepository(planRepoInterface)
+	s.planRepo = planRepoInterface
+	s.taskRepo = taskRepoInterface
+	var appRepo storage.ApplicationRepositoryInterface = storage.NewApplicationRepository(valkeyClient)
+	if chaos != nil {
+		appRepo = storage.NewChaosApplicationRepository(appRepo, chaos)
+	}
+	s.appRepo = storage.NewScopedApplicationRepository(storage.NewSanitizedApplicationRepository(appRepo, cfg.Sanitize))
+	serverOptions = append(serverOptions, mcp.WithApplications(s.appRepo))
+	if cfg.RequireKnownApplications {
+		log.Printf("Plans can only be created for registered applications")
+	}
+	if cfg.RequireCompletionNotes {
+		log.Printf("Tasks can only be completed with a completion note")
+	}
+	if cfg.RequireAcceptanceCriteria {
+		log.Printf("Tasks can only be completed once their acceptance criteria are met")
+	}
+
+	// Keep task attachments next to their tasks, bounded by the attachment limits
+	attachmentStore := storage.NewValkeyAttachmentStore(valkeyClient)
+	serverOptions = append(serverOptions,
+		mcp.WithAttachments(storage.NewLimitedAttachmentStore(attachmentStore, cfg.AttachmentLimits)))
+
+	// Notify the watchers of plans of the changes recorded in the audit log
+	watchers := storage.NewWatcherStore(valkeyClient)
+	serverOptions = append(serverOptions, mcp.WithWatchers(watchers))
+	notifiers := cfg.Notifications.Notifiers
+	if cfg.Notifications.Stream {
+		notifiers = append(notifiers, storage.NewNotificationStream(valkeyClient, storage.DefaultNotificationStreamLength))
+	}
+	var changeListeners storage.ChangeListeners
+	switch {
+	case len(notifiers) == 0:
+	case auditLog == nil:
+		log.Printf("Warning: watch notifications need the audit log and are disabled")
+	default:
+		s.watchNotifier = services.NewWatchNotifier(watchers, notifiers...)
+		changeListeners = append(changeListeners, s.watchNotifier)
+		log.Printf("Watch notifications enabled (%d notifier(s))", len(notifiers))
+	}
+
+	// Serve repeated reads of the full plan resources from memory, emptied by the changes of every replica
+	switch {
+	case cfg.ResourceCache.TTL <= 0:
+	case auditLog == nil:
+		log.Printf("Warning: the resource cache needs the audit log and is disabled")
+	default:
+		resourceCache := mcp.NewResourceCache(cfg.ResourceCache.TTL, cfg.ResourceCache.MaxEntries)
+		changeStream := storage.NewChangeStream(valkeyClient, storage.DefaultChangeStreamLength)
+		changeListeners = append(changeListeners, resourceCache, changeStream)
+		pollInterval := cfg.ResourceCache.PollInterval
+		s.followChanges = func(ctx context.Context) {
+			changeStream.Follow(ctx, pollInterval, resourceCache.Clear)
+		}
+		serverOptions = append(serverOptions, mcp.WithResourceCache(resourceCache))
+		log.Printf("Resource cache enabled (TTL: %s, max entries: %d)", cfg.ResourceCache.TTL, cfg.ResourceCache.MaxEntries)
+	}
+	// Export the changes recorded in the audit log to the configured sinks, each with its own queue
+	switch {
+	case len(cfg.Audit.Sinks) == 0:
+	case auditLog == nil:
+		log.Printf("Warning: the audit export needs the audit log and is disabled")
+	default:
+		for _, sink := range cfg.Audit.Sinks {
+			exporter := services.NewAuditExporter(sink, cfg.Audit.Export)
+			s.auditExporters = append(s.auditExporters, exporter)
+			changeListeners = append(changeListeners, exporter)
+		}
+		log.Printf("Audit export enabled (%d sink(s), format: %s)", len(cfg.Audit.Sinks), cfg.Audit.Export.Format)
+	}
+	if len(changeListeners) > 0 {
+		auditLog.SetChangeListener(changeListeners)
+	}
+
+	// Remember the results of create calls retried with an idempotency key, shared by all replicas
+	serverOptions = append(serverOptions, mcp.WithIdempotency(storage.NewIdempotencyStore(valkeyClient, cfg.IdempotencyTTL)))
+
+	// Run background jobs on the elected replica, one run at a time, unless this is turned off
+	replica := cfg.Jobs.ReplicaID
+	if replica == "" {
+		replica = replicaID()
+	}
+	var jobLocker scheduler.Locker
+	if cfg.Jobs.Locking {
+		jobLocker = storage.NewJobLocker(valkeyClient, replica)
+	}
+	s.jobScheduler = scheduler.New(jobLocker, cfg.Jobs.Jitter)
+	if cfg.Jobs.LeaderElection {
+		s.jobScheduler.UseLeaderElection(storage.NewLeaderElection(valkeyClient, replica, storage.DefaultLeaderTTL))
+	}
+
+	// Return tasks with expired leases to pending
+	if cfg.Jobs.LeaseExpiry {
+		s.jobScheduler.Add(scheduler.Job{
+			Name:     "lease-expiry",
+			Interval: cfg.Jobs.LeaseSweepInterval,
+			Run: func(ctx context.Context) error {
+				released, err := taskRepoInterface.ExpireLeases(ctx)
+				if len(released) > 0 {
+					log.Printf("Lease sweep returned %d task(s) to pending", len(released))
+				}
+				return err
+			},
+		})
+	}
+
+	// Expire old completed and cancelled plans when a retention period is configured
+	if cfg.Retention.MaxAge > 0 {
+		retentionJanitor := services.NewRetentionJanitor(planRepoInterface, taskRepoInterface, cfg.Retention)
+		s.jobScheduler.Add(scheduler.Job{
+			Name:     "retention",
+			Interval: cfg.RetentionSweepInterval,
+			Run: func(ctx context.Context) error {
+				expired, err := retentionJanitor.Run(ctx)
+				if len(expired) > 0 {
+					log.Printf("Retention expired %d plan(s)", len(expired))
+				}
+				return err
+			},
+		})
+		serverOptions = append(serverOptions, mcp.WithRetentionJanitor(retentionJanitor))
+		log.Printf("Plan retention enabled (max age: %s, action: %s)", cfg.Retention.MaxAge, cfg.Retention.Action)
+	}
+
+	// Raise the priority of neglected pending tasks when an escalation period is configured
+	if cfg.Escalation.After > 0 {
+		escalator := services.NewPriorityEscalator(planRepoInterface, taskRepoInterface, cfg.Escalation)
+		s.jobScheduler.Add(scheduler.Job{
+			Name:     "priority-escalation",
+			Interval: cfg.EscalationSweepInterval,
+			Run: func(ctx context.Context) error {
+				escalated, err := escalator.Run(ctx)
+				if len(escalated) > 0 {
+					log.Printf("Priority escalation escalated %d task(s)", len(escalated))
+				}
+				return err
+			},
+		})
+		log.Printf("Priority escalation enabled (after: %s, max priority: %s)",
+			cfg.Escalation.After, cfg.Escalation.MaxPriority)
+	}
+
+	// Delete tasks left behind by plans that no longer exist
+	if cfg.Jobs.OrphanCleanup {
+		orphanCleaner := services.NewOrphanCleaner(storage.NewIntegrityChecker(valkeyClient), taskRepoInterface)
+		s.jobScheduler.Add(scheduler.Job{
+			Name:     "orphan-cleanup",
+			Interval: cfg.Jobs.OrphanCleanupInterval,
+			Run: func(ctx context.Context) error {
+				deleted, err := orphanCleaner.Run(ctx)
+				if len(deleted) > 0 {
+					log.Printf("Orphan cleanup deleted %d task(s)", len(deleted))
+				}
+				return err
+			},
+		})
+	}
+
+	// The admin API is served when the server configuration sets an admin token
+	serverOptions = append(serverOptions, mcp.WithAdminAPI(storage.NewIntegrityChecker(valkeyClient)))
+
+	// Offer the maintenance tools to MCP clients only when the operator asks for them
+	if cfg.AdminTools {
+		serverOptions = append(serverOptions, mcp.WithIntegrityChecker(storage.NewIntegrityChecker(valkeyClient)))
+		log.Printf("Admin tools enabled")
+	}
+
+	// Enable the GitHub issue sync tools when a token is configured
+	if cfg.GitHub.Token != "" {
+		serverOptions = append(serverOptions,
+			mcp.WithGitHubSync(github.NewClient(cfg.GitHub.APIURL, cfg.GitHub.Token), cfg.GitHub.Repo))
+		log.Printf("GitHub sync enabled (API: %s, default repository: %q)", cfg.GitHub.APIURL, cfg.GitHub.Repo)
+	}
+
+	// Enable the Jira import and status push tools when a Jira site is configured
+	if cfg.Jira.URL != "" {
+		serverOptions = append(serverOptions,
+			mcp.WithJira(jira.NewClient(cfg.Jira.URL, cfg.Jira.Email, cfg.Jira.Token), cfg.Jira.Mapping))
+		log.Printf("Jira connector enabled (site: %s)", cfg.Jira.URL)
+	}
+
+	s.mcpServer = mcp.NewMCPGoServer(planRepoInterface, taskRepoInterface, serverOptions...)
+	log.Printf("Background jobs: %s (replica: %s, leader election: %t, locking: %t)",
+		strings.Join(s.jobScheduler.Jobs(), ", "), replica, cfg.Jobs.LeaderElection, cfg.Jobs.Locking)
+}
+
+// Plans returns the plan repository, with the same limits, retries and audit log as the MCP tools
+func (s *Server) Plans() PlanRepository {
+	return s.planRepo
+}
+
+// Tasks returns the task repository, with the same limits, retries and audit log as the MCP tools
+func (s *Server) Tasks() TaskRepository {
+	return s.taskRepo
+}
+
+// Applications returns the repository of registered applications
+func (s *Server) Applications() ApplicationRepository {
+	return s.appRepo
+}
+
+// Start starts the background jobs and serves the MCP server until Stop is called, returning nil then.
+// It returns an error if the server cannot be started.
+func (s *Server) Start() error {
+	if s.stopJobs != nil {
+		return errors.New("server already started")
+	}
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	s.stopJobs = stopJobs
+	s.jobsDone = make(chan struct{})
+	go func() {
+		defer close(s.jobsDone)
+		followed := make(chan struct{})
+		go func() {
+			defer close(followed)
+			if s.followChanges != nil {
+				s.followChanges(jobsCtx)
+			}
+		}()
+		s.jobScheduler.Start(jobsCtx)
+		<-followed
+	}()
+
+	log.Printf("Initializing MCP server on port %d", s.config.Port)
+	return s.mcpServer.Start(s.config.Port)
+}
+
+// Stop stops the MCP server, waiting for active requests, and the background jobs, notifications being
+// delivered and audit entries being exported until the context is done, then closes the storage. In-memory
+// storage writes its snapshot when it is closed.
+func (s *Server) Stop(ctx context.Context) error {
+	err := s.mcpServer.Shutdown(ctx)
+	if s.stopJobs != nil {
+		s.stopJobs()
+		select {
+		case <-s.jobsDone:
+		case <-ctx.Done():
+		}
+	}
+	if s.watchNotifier != nil {
+		notified := make(chan struct{})
+		go func() {
+			s.watchNotifier.Wait()
+			close(notified)
+		}()
+		select {
+		case <-notified:
+		case <-ctx.Done():
+		}
+	}
+	for _, exporter := range s.auditExporters {
+		if exportErr := exporter.Close(ctx); exportErr != nil {
+			log.Printf("Warning: failed to export the remaining audit entries: %v", exportErr)
+		}
+	}
+	s.valkeyClient.Close()
+	return err
+}
+
+// Reload applies the rate limits and read-only mode in the environment and the config file again when
+// Config.Server is nil. The other settings need a new server.
+func (s *Server) Reload() {
+	s.mcpServer.Reload()
+}
+
+// SetNotesSummarizer replaces the summarizer of archived notes, nil archives them without a summary. It has
+// no effect unless notes compaction is enabled.
+func (s *Server) SetNotesSummarizer(summarizer NotesSummarizer) {
+	if s.notesCompactor != nil {
+		s.notesCompactor.SetSummarizer(summarizer)
+	}
+}
+
+// replicaID identifies this server process in the locks of background jobs
+func replicaID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
@@ -0,0 +1,82 @@
+package taskserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// freePort returns a port nothing listens on
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestEmbeddedServer(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Storage = StorageMemory
+	cfg.Memory.SnapshotInterval = 0
+	cfg.Port = freePort(t)
+	serverConfig := DefaultServerConfig()
+	cfg.Server = &serverConfig
+
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	// The repositories work without starting the server
+	ctx := context.Background()
+	plan, err := srv.Plans().Create(ctx, "app", "Embedded", "Created through the repository")
+	if err != nil {
+		t.Fatalf("creating a plan failed: %v", err)
+	}
+	if _, err := srv.Tasks().Create(ctx, plan.ID, "Task", "", "high"); err != nil {
+		t.Fatalf("creating a task failed: %v", err)
+	}
+
+	started := make(chan error, 1)
+	go func() {
+		started <- srv.Start()
+	}()
+
+	healthURL := fmt.Sprintf("http://127.0.0.1:%d/health", cfg.Port)
+	var resp *http.Response
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if resp, err = http.Get(healthURL); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("server did not start: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("health check returned %d", resp.StatusCode)
+	}
+
+	stopCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := srv.Stop(stopCtx); err != nil {
+		t.Errorf("Stop failed: %v", err)
+	}
+	if err := <-started; err != nil {
+		t.Errorf("Start should return nil after Stop, got %v", err)
+	}
+}
+
+func TestNewRejectsUnknownStorage(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Storage = "sqlite"
+	if _, err := New(cfg); err == nil {
+		t.Error("New should reject an unknown storage backend")
+	}
+}
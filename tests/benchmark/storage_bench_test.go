@@ -0,0 +1,178 @@
+// Package benchmark holds the benchmarks of the storage hot paths. They run on the in-memory store unless
+// BENCH_VALKEY_ADDR is set to the host:port of a Valkey server, which should be a scratch database as the
+// benchmarks write to it. Run them with make bench, which records the results for comparison.
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+	"github.com/jbrinkman/valkey-ai-tasks/tests/utils"
+)
+
+// newRepositories returns the repositories of the store the benchmarks run on
+func newRepositories(b *testing.B) (*storage.PlanRepository, *storage.TaskRepository) {
+	b.Helper()
+
+	var client *storage.ValkeyClient
+	var err error
+	if addr := os.Getenv("BENCH_VALKEY_ADDR"); addr != "" {
+		host, port, parseErr := utils.ParseEndpoint(addr)
+		if parseErr != nil {
+			b.Fatalf("invalid BENCH_VALKEY_ADDR: %v", parseErr)
+		}
+		client, err = storage.NewValkeyClient(host, port, "", "")
+	} else {
+		client, err = storage.NewMemoryClient(storage.MemoryConfig{})
+	}
+	if err != nil {
+		b.Fatalf("failed to create client: %v", err)
+	}
+	b.Cleanup(func() { client.Close() }) //nolint:errcheck
+
+	return storage.NewPlanRepository(client), storage.NewTaskRepository(client)
+}
+
+// taskInputs returns the inputs of n tasks
+func taskInputs(n int) []storage.TaskCreateInput {
+	inputs := make([]storage.TaskCreateInput, n)
+	for i := range inputs {
+		inputs[i] = storage.TaskCreateInput{
+			Title:       fmt.Sprintf("Task %d", i),
+			Description: "A task created by the benchmarks",
+			Priority:    models.TaskPriorityMedium,
+		}
+	}
+	return inputs
+}
+
+// createPlan creates a plan holding n tasks and deletes it when the benchmark ends
+func createPlan(
+	b *testing.B,
+	planRepo *storage.PlanRepository,
+	taskRepo *storage.TaskRepository,
+	n int,
+) (*models.Plan, []*models.Task) {
+	b.Helper()
+	ctx := context.Background()
+
+	plan, err := planRepo.Create(ctx, "bench-app", "Benchmark plan", "")
+	if err != nil {
+		b.Fatalf("failed to create plan: %v", err)
+	}
+	b.Cleanup(func() {
+		if err := planRepo.Delete(ctx, plan.ID); err != nil {
+			b.Logf("failed to delete plan %s: %v", plan.ID, err)
+		}
+	})
+
+	tasks, err := taskRepo.CreateBulk(ctx, plan.ID, taskInputs(n))
+	if err != nil {
+		b.Fatalf("failed to create tasks: %v", err)
+	}
+	return plan, tasks
+}
+
+// BenchmarkCreateBulk creates 1000 tasks at once in a new plan
+func BenchmarkCreateBulk(b *testing.B) {
+	planRepo, taskRepo := newRepositories(b)
+	ctx := context.Background()
+	inputs := taskInputs(1000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		plan, err := planRepo.Create(ctx, "bench-app", "Benchmark plan", "")
+		if err != nil {
+			b.Fatalf("failed to create plan: %v", err)
+		}
+		b.StartTimer()
+
+		if _, err := taskRepo.CreateBulk(ctx, plan.ID, inputs); err != nil {
+			b.Fatalf("failed to create tasks: %v", err)
+		}
+
+		b.StopTimer()
+		if err := planRepo.Delete(ctx, plan.ID); err != nil {
+			b.Fatalf("failed to delete plan: %v", err)
+		}
+		b.StartTimer()
+	}
+}
+
+// BenchmarkListByPlan lists the tasks of plans of growing size
+func BenchmarkListByPlan(b *testing.B) {
+	for _, size := range []int{100, 1000, 5000} {
+		b.Run(fmt.Sprintf("tasks=%d", size), func(b *testing.B) {
+			planRepo, taskRepo := newRepositories(b)
+			plan, _ := createPlan(b, planRepo, taskRepo, size)
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				tasks, err := taskRepo.ListByPlan(ctx, plan.ID)
+				if err != nil {
+					b.Fatalf("failed to list tasks: %v", err)
+				}
+				if len(tasks) != size {
+					b.Fatalf("expected %d tasks, got %d", size, len(tasks))
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkReorderTask moves tasks around a plan of 1000 tasks
+func BenchmarkReorderTask(b *testing.B) {
+	planRepo, taskRepo := newRepositories(b)
+	_, tasks := createPlan(b, planRepo, taskRepo, 1000)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Move tasks spread over the whole plan to positions in its first half
+		task := tasks[(i*7919)%len(tasks)]
+		if err := taskRepo.ReorderTask(ctx, task.ID, (i*104729)%(len(tasks)/2)); err != nil {
+			b.Fatalf("failed to reorder task: %v", err)
+		}
+	}
+}
+
+// BenchmarkListByStatus lists the tasks of a status across many plans
+func BenchmarkListByStatus(b *testing.B) {
+	for _, plans := range []int{10, 100} {
+		b.Run(fmt.Sprintf("plans=%d", plans), func(b *testing.B) {
+			planRepo, taskRepo := newRepositories(b)
+			ctx := context.Background()
+			for p := 0; p < plans; p++ {
+				_, tasks := createPlan(b, planRepo, taskRepo, 50)
+				// A fifth of the tasks of each plan are in progress
+				for _, task := range tasks[:10] {
+					task.Status = models.TaskStatusInProgress
+					if err := taskRepo.Update(ctx, task); err != nil {
+						b.Fatalf("failed to update task: %v", err)
+					}
+				}
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				tasks, err := taskRepo.ListByStatus(ctx, models.TaskStatusInProgress)
+				if err != nil {
+					b.Fatalf("failed to list tasks: %v", err)
+				}
+				if len(tasks) < plans*10 {
+					b.Fatalf("expected at least %d tasks, got %d", plans*10, len(tasks))
+				}
+			}
+		})
+	}
+}
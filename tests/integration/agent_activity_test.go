@@ -0,0 +1,67 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// TestAgentActivity tests that the activity journal of a plan keeps its latest entries, newest first, can be
+// filtered by session and paged through, and is deleted with its plan
+func TestAgentActivity(t *testing.T) {
+	ctx := context.Background()
+	repos := newTestRepos(t)
+	planRepo := repos.planRepo
+	planRepo.SetAgentActivityLength(3)
+
+	plan, err := planRepo.Create(ctx, "activity-app", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create the plan: %v", err)
+	}
+
+	agentCtx := storage.WithActor(ctx, "agent-1")
+	for _, entry := range []models.AgentActivity{
+		{Session: "first", Summary: "Read the code"},
+		{Session: "first", Summary: "Chose an approach", Kind: "decision"},
+		{Session: "second", Summary: "Wrote the parser", Details: map[string]any{"files": []any{"parser.go"}}},
+		{Session: "second", Summary: "Ran the tests"},
+	} {
+		if _, err := planRepo.LogActivity(agentCtx, plan.ID, &entry); err != nil {
+			t.Fatalf("failed to log activity: %v", err)
+		}
+	}
+	if _, err := planRepo.LogActivity(ctx, plan.ID, &models.AgentActivity{}); models.ErrorCodeOf(err) != models.ErrorCodeValidation {
+		t.Errorf("expected a validation error for an entry without a summary, got %v", err)
+	}
+
+	// The oldest entry was dropped beyond the configured length
+	activities, err := planRepo.ListActivity(ctx, plan.ID, storage.AgentActivityQuery{})
+	if err != nil {
+		t.Fatalf("failed to list activity: %v", err)
+	}
+	if len(activities) != 3 || activities[0].Summary != "Ran the tests" || activities[2].Summary != "Chose an approach" {
+		t.Fatalf("expected the 3 latest entries newest first, got %+v", activities)
+	}
+	if activities[0].Actor != "agent-1" || activities[1].Details["files"] == nil || activities[2].Kind != "decision" {
+		t.Errorf("entries lost their fields: %+v", activities)
+	}
+
+	// Paging back from the newest entry, and filtering by session
+	older, err := planRepo.ListActivity(ctx, plan.ID, storage.AgentActivityQuery{Before: activities[0].ID, Limit: 1})
+	if err != nil || len(older) != 1 || older[0].ID != activities[1].ID {
+		t.Errorf("expected the entry before the newest, got %+v, %v", older, err)
+	}
+	first, err := planRepo.ListActivity(ctx, plan.ID, storage.AgentActivityQuery{Session: "first"})
+	if err != nil || len(first) != 1 || first[0].Summary != "Chose an approach" {
+		t.Errorf("expected the kept entry of the first session, got %+v, %v", first, err)
+	}
+
+	if err := planRepo.Delete(ctx, plan.ID); err != nil {
+		t.Fatalf("failed to delete the plan: %v", err)
+	}
+	if _, err := planRepo.ListActivity(ctx, plan.ID, storage.AgentActivityQuery{}); models.ErrorCodeOf(err) != models.ErrorCodeNotFound {
+		t.Errorf("expected a not found error for the activity of a deleted plan, got %v", err)
+	}
+}
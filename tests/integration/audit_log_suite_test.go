@@ -0,0 +1,146 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+	"github.com/jbrinkman/valkey-ai-tasks/tests/utils"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// AuditLogSuite is a test suite for the audit log and the audited repositories
+type AuditLogSuite struct {
+	utils.RepositoryTestSuite
+	AuditLog *storage.AuditLog
+	PlanRepo storage.PlanRepositoryInterface
+	TaskRepo storage.TaskRepositoryInterface
+}
+
+// SetupTest sets up each test
+func (s *AuditLogSuite) SetupTest() {
+	// Call the base SetupTest to initialize the container and client
+	s.RepositoryTestSuite.SetupTest()
+
+	s.AuditLog = storage.NewAuditLog(s.ValkeyClient, storage.AuditRetention{MaxEntries: storage.DefaultAuditMaxEntries})
+	s.PlanRepo = storage.NewAuditedPlanRepository(s.GetPlanRepository(), s.AuditLog)
+	s.TaskRepo = storage.NewAuditedTaskRepository(s.GetTaskRepository(), s.AuditLog)
+}
+
+// TestTaskHistory tests that task mutations are recorded with diffs and actors
+func (s *AuditLogSuite) TestTaskHistory() {
+	ctx := storage.WithActor(s.Context, "session:test")
+
+	plan, err := s.PlanRepo.Create(ctx, "test-app-"+uuid.New().String(), "Test Plan", "Test plan description")
+	require.NoError(s.T(), err, "Failed to create plan")
+
+	task, err := s.TaskRepo.Create(ctx, plan.ID, "Original title", "Description", models.TaskPriorityMedium)
+	require.NoError(s.T(), err, "Failed to create task")
+
+	task.Title = "Updated title"
+	err = s.TaskRepo.Update(ctx, task)
+	require.NoError(s.T(), err, "Failed to update task")
+
+	err = s.TaskRepo.Delete(ctx, task.ID)
+	require.NoError(s.T(), err, "Failed to delete task")
+
+	// History survives the deletion of the task and is returned newest first
+	entries, err := s.AuditLog.History(s.Context, models.EntityTypeTask, task.ID, 0)
+	require.NoError(s.T(), err, "Failed to get task history")
+	require.Len(s.T(), entries, 3, "Expected create, update and delete entries")
+
+	s.Equal(models.AuditActionDelete, entries[0].Action)
+	s.Empty(entries[0].After, "Delete entries should have no after snapshot")
+
+	s.Equal(models.AuditActionUpdate, entries[1].Action)
+	s.Equal("session:test", entries[1].Actor, "Entries should record the actor from the context")
+	s.Equal(models.FieldChange{Before: "Original title", After: "Updated title"}, entries[1].Changes["title"])
+
+	s.Equal(models.AuditActionCreate, entries[2].Action)
+	s.Empty(entries[2].Before, "Create entries should have no before snapshot")
+
+	// The limit returns only the most recent entries
+	entries, err = s.AuditLog.History(s.Context, models.EntityTypeTask, task.ID, 1)
+	require.NoError(s.T(), err, "Failed to get limited task history")
+	s.Len(entries, 1, "Expected a single entry")
+}
+
+// TestPlanHistoryDefaultActor tests that changes without an actor are attributed to the default actor
+func (s *AuditLogSuite) TestPlanHistoryDefaultActor() {
+	plan, err := s.PlanRepo.Create(s.Context, "test-app-"+uuid.New().String(), "Test Plan", "Test plan description")
+	require.NoError(s.T(), err, "Failed to create plan")
+
+	for _, notes := range []string{"one", "two"} {
+		err = s.PlanRepo.UpdateNotes(s.Context, plan.ID, notes)
+		require.NoError(s.T(), err, "Failed to update notes")
+	}
+
+	entries, err := s.AuditLog.History(s.Context, models.EntityTypePlan, plan.ID, 0)
+	require.NoError(s.T(), err, "Failed to get plan history")
+	require.Len(s.T(), entries, 3, "Expected one create and two update entries")
+	s.Equal(storage.DefaultActor, entries[0].Actor, "Changes without an actor should use the default actor")
+	s.Equal("update_notes", entries[0].Operation)
+	s.Equal(models.FieldChange{Before: "one", After: "two"}, entries[0].Changes["notes"])
+}
+
+// TestUndoLastChange tests reverting updates, deletes and creates of a task
+func (s *AuditLogSuite) TestUndoLastChange() {
+	undo := services.NewUndoService(s.PlanRepo, s.TaskRepo, s.AuditLog)
+
+	plan, err := s.PlanRepo.Create(s.Context, "test-app-"+uuid.New().String(), "Test Plan", "Test plan description")
+	require.NoError(s.T(), err, "Failed to create plan")
+	task, err := s.TaskRepo.Create(s.Context, plan.ID, "Original title", "Description", models.TaskPriorityMedium)
+	require.NoError(s.T(), err, "Failed to create task")
+	_, err = s.TaskRepo.AddTags(s.Context, task.ID, []string{"backend"})
+	require.NoError(s.T(), err, "Failed to tag task")
+
+	// Undo an update
+	task, err = s.TaskRepo.Get(s.Context, task.ID)
+	require.NoError(s.T(), err, "Failed to get task")
+	task.Title = "Mistaken title"
+	err = s.TaskRepo.Update(s.Context, task)
+	require.NoError(s.T(), err, "Failed to update task")
+
+	result, err := undo.UndoLastChange(s.Context, models.EntityTypeTask, task.ID, "", false)
+	require.NoError(s.T(), err, "Failed to undo update")
+	s.Equal("Original title", result.Task.Title, "Title should be reverted")
+	s.Equal([]string{"backend"}, result.Task.Tags, "Tags should be kept")
+
+	// Undo a delete
+	err = s.TaskRepo.Delete(s.Context, task.ID)
+	require.NoError(s.T(), err, "Failed to delete task")
+
+	result, err = undo.UndoLastChange(s.Context, models.EntityTypeTask, task.ID, "", false)
+	require.NoError(s.T(), err, "Failed to undo delete")
+	s.Equal(task.ID, result.Task.ID, "Task should be restored with its ID")
+
+	tasks, err := s.TaskRepo.ListByTag(s.Context, "backend")
+	require.NoError(s.T(), err, "Failed to list tasks by tag")
+	s.Len(tasks, 1, "Restored task should be indexed by its tags again")
+
+	// An unexpected entry ID is a conflict
+	_, err = undo.UndoLastChange(s.Context, models.EntityTypeTask, task.ID, "0-1", false)
+	s.Equal(models.ErrorCodeConflict, models.ErrorCodeOf(err), "Expected a conflict for an unexpected entry ID")
+
+	// Undo a create
+	created, err := s.TaskRepo.Create(s.Context, plan.ID, "Accidental task", "Description", models.TaskPriorityLow)
+	require.NoError(s.T(), err, "Failed to create task")
+
+	result, err = undo.UndoLastChange(s.Context, models.EntityTypeTask, created.ID, "", false)
+	require.NoError(s.T(), err, "Failed to undo create")
+	s.True(result.Deleted, "Undoing a create should delete the task")
+
+	_, err = s.TaskRepo.Get(s.Context, created.ID)
+	s.Error(err, "Task should no longer exist")
+}
+
+// TestAuditLogSuite runs the audit log test suite
+func TestAuditLogSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+	suite.Run(t, new(AuditLogSuite))
+}
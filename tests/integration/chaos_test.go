@@ -0,0 +1,149 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// newChaosRepositories wraps repositories on a new in-memory store with failure injection
+func newChaosRepositories(
+	t *testing.T,
+	config storage.ChaosConfig,
+) (storage.PlanRepositoryInterface, storage.TaskRepositoryInterface) {
+	t.Helper()
+	repos := newTestRepos(t)
+
+	chaos := storage.NewChaos(config)
+	return storage.NewChaosPlanRepository(repos.planRepo, chaos),
+		storage.NewChaosTaskRepository(repos.taskRepo, chaos)
+}
+
+// TestChaosFailures tests that injected failures are transient and fail calls before they are applied
+func TestChaosFailures(t *testing.T) {
+	ctx := context.Background()
+	planRepo, taskRepo := newChaosRepositories(t, storage.ChaosConfig{
+		FailureRate: 1,
+		Operations:  []string{"task.Create", "CreateBulk"},
+	})
+
+	plan, err := planRepo.Create(ctx, "chaos-app", "Plan", "")
+	if err != nil {
+		t.Fatalf("plan creation is not an injected operation, got %v", err)
+	}
+
+	_, err = taskRepo.Create(ctx, plan.ID, "Task", "", models.TaskPriorityMedium)
+	if !errors.Is(err, storage.ErrInjectedFailure) || !storage.IsTransient(err) {
+		t.Errorf("expected a transient injected failure, got %v", err)
+	}
+	_, err = taskRepo.CreateBulk(ctx, plan.ID, []storage.TaskCreateInput{{Title: "A"}, {Title: "B"}})
+	if !errors.Is(err, storage.ErrInjectedFailure) {
+		t.Errorf("expected an injected failure, got %v", err)
+	}
+
+	tasks, err := taskRepo.ListByPlan(ctx, plan.ID)
+	if err != nil {
+		t.Fatalf("listing is not an injected operation, got %v", err)
+	}
+	if len(tasks) != 0 {
+		t.Errorf("failed calls created %d tasks", len(tasks))
+	}
+}
+
+// TestChaosRetriedReads tests that reads failed by failure injection are retried like Valkey timeouts
+func TestChaosRetriedReads(t *testing.T) {
+	ctx := context.Background()
+	planRepo, _ := newChaosRepositories(t, storage.ChaosConfig{
+		FailureRate: 0.5,
+		Operations:  []string{"plan.Get"},
+		Seed:        1,
+	})
+	plan, err := planRepo.Create(ctx, "chaos-app", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create the plan: %v", err)
+	}
+
+	retrying := storage.NewRetryingPlanRepository(planRepo, storage.RetryPolicy{MaxAttempts: 10})
+	for i := 0; i < 20; i++ {
+		if _, err := retrying.Get(ctx, plan.ID); err != nil {
+			t.Fatalf("read %d failed despite retries: %v", i, err)
+		}
+	}
+}
+
+// TestChaosLatency tests that injected latency delays calls and gives way to a done context
+func TestChaosLatency(t *testing.T) {
+	planRepo, _ := newChaosRepositories(t, storage.ChaosConfig{Latency: 20 * time.Millisecond})
+
+	start := time.Now()
+	if _, err := planRepo.List(context.Background()); err != nil {
+		t.Fatalf("failed to list plans: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("the call took %s, expected at least the latency", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if _, err := planRepo.List(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the context deadline, got %v", err)
+	}
+}
+
+// TestChaosConcurrentReorders tests that reorders interleaved by injected latency and failures leave every
+// task of the plan at a distinct position
+func TestChaosConcurrentReorders(t *testing.T) {
+	ctx := context.Background()
+	planRepo, taskRepo := newChaosRepositories(t, storage.ChaosConfig{
+		FailureRate:   0.2,
+		LatencyJitter: 2 * time.Millisecond,
+		Operations:    []string{"ReorderTask"},
+		Seed:          1,
+	})
+	plan, err := planRepo.Create(ctx, "chaos-app", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create the plan: %v", err)
+	}
+	inputs := make([]storage.TaskCreateInput, 10)
+	for i := range inputs {
+		inputs[i] = storage.TaskCreateInput{Title: fmt.Sprintf("Task %d", i)}
+	}
+	tasks, err := taskRepo.CreateBulk(ctx, plan.ID, inputs)
+	if err != nil {
+		t.Fatalf("failed to create the tasks: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		wg.Add(1)
+		go func(taskID string, position int) {
+			defer wg.Done()
+			err := taskRepo.ReorderTask(ctx, taskID, position)
+			if err != nil && !errors.Is(err, storage.ErrInjectedFailure) {
+				t.Errorf("failed to reorder task %s: %v", taskID, err)
+			}
+		}(task.ID, len(tasks)-1-i)
+	}
+	wg.Wait()
+
+	reordered, err := taskRepo.ListByPlan(ctx, plan.ID)
+	if err != nil {
+		t.Fatalf("failed to list the tasks: %v", err)
+	}
+	if len(reordered) != len(tasks) {
+		t.Fatalf("got %d tasks, want %d", len(reordered), len(tasks))
+	}
+	positions := make(map[int]string)
+	for _, task := range reordered {
+		if other, ok := positions[task.Order]; ok {
+			t.Errorf("tasks %s and %s share position %d", other, task.ID, task.Order)
+		}
+		positions[task.Order] = task.ID
+	}
+}
@@ -0,0 +1,135 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+	"github.com/jbrinkman/valkey-ai-tasks/tests/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+// ClusterKeyLayoutTestSuite is a test suite for the hash-tagged key layout used with Valkey Cluster
+type ClusterKeyLayoutTestSuite struct {
+	utils.RepositoryTestSuite
+}
+
+// SetupTest sets up each test
+func (s *ClusterKeyLayoutTestSuite) SetupTest() {
+	s.RepositoryTestSuite.SetupTest()
+	storage.SetClusterKeyLayout(true)
+}
+
+// TearDownTest cleans up after each test
+func (s *ClusterKeyLayoutTestSuite) TearDownTest() {
+	storage.SetClusterKeyLayout(false)
+	s.RepositoryTestSuite.TearDownTest()
+}
+
+// requireKeys checks that the given keys exist
+func (s *ClusterKeyLayoutTestSuite) requireKeys(keys ...string) {
+	container := s.Containers[len(s.Containers)-1]
+	count, err := container.Client.Exists(s.Context, keys)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(len(keys)), count, "expected keys %v", keys)
+}
+
+// TestPlanAndTasksShareHashTag tests that the keys of a plan and its tasks share a hash tag
+func (s *ClusterKeyLayoutTestSuite) TestPlanAndTasksShareHashTag() {
+	planRepo := s.GetPlanRepository()
+	taskRepo := s.GetTaskRepository()
+
+	plan, err := planRepo.Create(s.Context, "cluster-app-"+uuid.New().String(), "Cluster Plan", "desc")
+	s.Require().NoError(err)
+	tag := "{" + plan.ID[:8] + "}"
+	s.Equal("plan:"+tag+plan.ID, storage.GetPlanKey(plan.ID))
+
+	task, err := taskRepo.Create(s.Context, plan.ID, "Single", "", models.TaskPriorityMedium)
+	s.Require().NoError(err)
+	bulk, err := taskRepo.CreateBulk(s.Context, plan.ID, []storage.TaskCreateInput{{Title: "Bulk one"}, {Title: "Bulk two"}})
+	s.Require().NoError(err)
+	s.Require().Len(bulk, 2)
+
+	for _, t := range append(bulk, task) {
+		s.Equal(plan.ID[:8], t.ID[:8], "task %s should share the hash tag of its plan", t.ID)
+		s.NotEqual(plan.ID, t.ID)
+		s.Require().NoError(uuid.Validate(t.ID))
+		s.requireKeys("task:" + tag + t.ID)
+	}
+	s.requireKeys("plan:"+tag+plan.ID, "plan_tasks:"+tag+plan.ID)
+
+	// Task-scoped keys follow the task
+	_, err = taskRepo.AddTags(s.Context, task.ID, []string{"infra"})
+	s.Require().NoError(err)
+	_, err = taskRepo.ClaimTask(s.Context, task.ID, "worker-1", time.Minute)
+	s.Require().NoError(err)
+	s.requireKeys("task_tags:"+tag+task.ID, "task_lease:"+tag+task.ID)
+
+	tasks, err := taskRepo.ListByPlan(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.Len(tasks, 3)
+
+	// Cloned tasks take the hash tag of the new plan
+	clone, err := planRepo.Clone(s.Context, plan.ID, storage.PlanCloneOptions{})
+	s.Require().NoError(err)
+	cloned, err := taskRepo.ListByPlan(s.Context, clone.ID)
+	s.Require().NoError(err)
+	s.Require().Len(cloned, 3)
+	for _, t := range cloned {
+		s.Equal(clone.ID[:8], t.ID[:8])
+	}
+}
+
+// TestClusterKeyLayoutSuite runs the cluster key layout test suite
+func TestClusterKeyLayoutSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	suite.Run(t, new(ClusterKeyLayoutTestSuite))
+}
+
+// TestParseValkeyAddresses tests parsing lists of Valkey node addresses
+func TestParseValkeyAddresses(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		want    []storage.ValkeyAddress
+		wantErr bool
+	}{
+		{name: "single", list: "valkey:7000", want: []storage.ValkeyAddress{{Host: "valkey", Port: 7000}}},
+		{
+			name: "several with default port",
+			list: "node-1:7000, node-2 ,,node-3:7002",
+			want: []storage.ValkeyAddress{{Host: "node-1", Port: 7000}, {Host: "node-2", Port: 6379}, {Host: "node-3", Port: 7002}},
+		},
+		{name: "ipv6", list: "[::1]:7000", want: []storage.ValkeyAddress{{Host: "::1", Port: 7000}}},
+		{name: "invalid port", list: "node-1:port", wantErr: true},
+		{name: "empty", list: " , ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := storage.ParseValkeyAddresses(tt.list, 6379)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("address %d: got %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
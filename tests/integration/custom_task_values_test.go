@@ -0,0 +1,71 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// TestCustomTaskValues tests that tasks take the configured custom statuses and priorities, and that the
+// counters of their plan derive its status from the categories of the custom statuses
+func TestCustomTaskValues(t *testing.T) {
+	ctx := context.Background()
+	repos := newTestRepos(t)
+	t.Cleanup(func() {
+		models.SetCustomTaskStatuses(nil)   //nolint:errcheck
+		models.SetCustomTaskPriorities(nil) //nolint:errcheck
+	})
+	planRepo := repos.planRepo
+	taskRepo := repos.taskRepo
+
+	statuses := []models.TaskStatusDefinition{
+		{Status: "blocked", Category: models.TaskStatusCategoryOpen},
+		{Status: "testing", Category: models.TaskStatusCategoryActive},
+	}
+	if err := models.SetCustomTaskStatuses(statuses); err != nil {
+		t.Fatalf("failed to set the custom statuses: %v", err)
+	}
+	if err := models.SetCustomTaskPriorities([]models.TaskPriorityDefinition{{Priority: "critical", Weight: 40}}); err != nil {
+		t.Fatalf("failed to set the custom priorities: %v", err)
+	}
+
+	plan, err := planRepo.Create(ctx, "custom-app", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create the plan: %v", err)
+	}
+	task, err := taskRepo.Create(ctx, plan.ID, "Task", "", "critical")
+	if err != nil {
+		t.Fatalf("failed to create a task with a custom priority: %v", err)
+	}
+	if _, err := taskRepo.Create(ctx, plan.ID, "Task", "", "urgent"); models.ErrorCodeOf(err) != models.ErrorCodeValidation {
+		t.Errorf("expected a validation error for an unknown priority, got %v", err)
+	}
+
+	// An open custom status leaves the plan new, an active one puts it in progress
+	for _, test := range []struct {
+		status models.TaskStatus
+		want   models.PlanStatus
+	}{
+		{"blocked", models.PlanStatusNew},
+		{"testing", models.PlanStatusInProgress},
+		{models.TaskStatusCompleted, models.PlanStatusCompleted},
+	} {
+		task.Status = test.status
+		if err := taskRepo.Update(ctx, task); err != nil {
+			t.Fatalf("failed to move the task to %s: %v", test.status, err)
+		}
+		got, err := planRepo.Get(ctx, plan.ID)
+		if err != nil {
+			t.Fatalf("failed to get the plan: %v", err)
+		}
+		if got.Status != test.want || got.TaskCounts.Count(test.status) != 1 {
+			t.Errorf("with a task %s expected the plan %s, got %s with %+v", test.status, test.want, got.Status, got.TaskCounts)
+		}
+	}
+
+	task.Status = "waiting"
+	if err := taskRepo.Update(ctx, task); models.ErrorCodeOf(err) != models.ErrorCodeValidation {
+		t.Errorf("expected a validation error for an unknown status, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package integration
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// newEncryptionKey returns a random AES-256 key
+func newEncryptionKey(t *testing.T) []byte {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("failed to generate a key: %v", err)
+	}
+	return key
+}
+
+// newFieldCipher creates a cipher from keys, the current key first
+func newFieldCipher(t *testing.T, keys ...[]byte) *storage.FieldCipher {
+	t.Helper()
+	fieldCipher, err := storage.NewFieldCipher(context.Background(), storage.StaticKeys(keys))
+	if err != nil {
+		t.Fatalf("failed to create the cipher: %v", err)
+	}
+	return fieldCipher
+}
+
+// TestFieldEncryptionRoundTrip tests that encrypted descriptions and notes read back as written, and that
+// neither they nor their copies in notes history and the audit log are stored in plaintext
+func TestFieldEncryptionRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	snapshotFile := filepath.Join(t.TempDir(), "tasks.json")
+	key := newEncryptionKey(t)
+
+	client, err := storage.NewMemoryClient(storage.MemoryConfig{SnapshotFile: snapshotFile})
+	if err != nil {
+		t.Fatalf("failed to create the store: %v", err)
+	}
+	client.SetFieldCipher(newFieldCipher(t, key))
+	auditLog := storage.NewAuditLog(client, storage.AuditRetention{})
+	plans := storage.NewPlanRepository(client)
+	plans.SetNotesHistoryLength(5)
+	planRepo := storage.NewAuditedPlanRepository(plans, auditLog)
+	taskRepo := storage.NewAuditedTaskRepository(storage.NewTaskRepository(client), auditLog)
+
+	plan, err := planRepo.Create(ctx, "encrypted-app", "Plan", "secret plan description")
+	if err != nil {
+		t.Fatalf("failed to create the plan: %v", err)
+	}
+	if err := planRepo.UpdateNotes(ctx, plan.ID, "secret plan notes"); err != nil {
+		t.Fatalf("failed to update the notes: %v", err)
+	}
+	if err := planRepo.UpdateNotes(ctx, plan.ID, "secret plan notes, revised"); err != nil {
+		t.Fatalf("failed to update the notes: %v", err)
+	}
+	task, err := taskRepo.Create(ctx, plan.ID, "Task", "secret task description", models.TaskPriorityMedium)
+	if err != nil {
+		t.Fatalf("failed to create the task: %v", err)
+	}
+	task.Notes = "secret task notes"
+	if err := taskRepo.Update(ctx, task); err != nil {
+		t.Fatalf("failed to update the task: %v", err)
+	}
+
+	gotPlan, err := planRepo.Get(ctx, plan.ID)
+	if err != nil {
+		t.Fatalf("failed to get the plan: %v", err)
+	}
+	if gotPlan.Description != "secret plan description" || gotPlan.Notes != "secret plan notes, revised" {
+		t.Errorf("got description %q and notes %q", gotPlan.Description, gotPlan.Notes)
+	}
+	gotTask, err := taskRepo.Get(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("failed to get the task: %v", err)
+	}
+	if gotTask.Description != "secret task description" || gotTask.Notes != "secret task notes" {
+		t.Errorf("got description %q and notes %q", gotTask.Description, gotTask.Notes)
+	}
+	revisions, err := plans.NotesHistory(ctx, plan.ID, 0)
+	if err != nil {
+		t.Fatalf("failed to get the notes history: %v", err)
+	}
+	if len(revisions) == 0 || revisions[len(revisions)-1].Notes != "secret plan notes" {
+		t.Errorf("got notes history %+v", revisions)
+	}
+	entries, err := auditLog.History(ctx, models.EntityTypeTask, task.ID, 1)
+	if err != nil {
+		t.Fatalf("failed to get the task history: %v", err)
+	}
+	if len(entries) != 1 || !strings.Contains(string(entries[0].After), "secret task notes") {
+		t.Errorf("got task history %+v", entries)
+	}
+
+	// Encrypted values are unreadable at rest and without the key
+	if err := client.Close(); err != nil {
+		t.Fatalf("failed to write the snapshot: %v", err)
+	}
+	snapshot, err := os.ReadFile(snapshotFile)
+	if err != nil {
+		t.Fatalf("failed to read the snapshot: %v", err)
+	}
+	if strings.Contains(string(snapshot), "secret") {
+		t.Error("the snapshot contains plaintext descriptions or notes")
+	}
+
+	restarted, err := storage.NewMemoryClient(storage.MemoryConfig{SnapshotFile: snapshotFile})
+	if err != nil {
+		t.Fatalf("failed to restart the store: %v", err)
+	}
+	defer restarted.Close()
+	if _, err := storage.NewTaskRepository(restarted).Get(ctx, task.ID); !errors.Is(err, storage.ErrDecryption) {
+		t.Errorf("expected a decryption error without the key, got %v", err)
+	}
+	restarted.SetFieldCipher(newFieldCipher(t, key))
+	if gotTask, err = storage.NewTaskRepository(restarted).Get(ctx, task.ID); err != nil {
+		t.Fatalf("failed to get the task after a restart: %v", err)
+	}
+	if gotTask.Notes != "secret task notes" {
+		t.Errorf("got notes %q after a restart", gotTask.Notes)
+	}
+}
+
+// TestFieldEncryptionMigrationAndRotation tests that plaintext values stay readable once encryption is turned
+// on, and that values stay readable with an old key once the key is rotated
+func TestFieldEncryptionMigrationAndRotation(t *testing.T) {
+	ctx := context.Background()
+	repos := newTestRepos(t)
+	client, planRepo := repos.client, repos.planRepo
+
+	plan, err := planRepo.Create(ctx, "encrypted-app", "Plan", "written in plaintext")
+	if err != nil {
+		t.Fatalf("failed to create the plan: %v", err)
+	}
+
+	oldKey, newKey := newEncryptionKey(t), newEncryptionKey(t)
+	client.SetFieldCipher(newFieldCipher(t, oldKey))
+	got, err := planRepo.Get(ctx, plan.ID)
+	if err != nil || got.Description != "written in plaintext" {
+		t.Fatalf("expected the plaintext description, got %+v, %v", got, err)
+	}
+	got.Description = "written with the old key"
+	if err := planRepo.Update(ctx, got); err != nil {
+		t.Fatalf("failed to update the plan: %v", err)
+	}
+
+	client.SetFieldCipher(newFieldCipher(t, newKey, oldKey))
+	got, err = planRepo.Get(ctx, plan.ID)
+	if err != nil || got.Description != "written with the old key" {
+		t.Fatalf("expected the description written with the old key, got %+v, %v", got, err)
+	}
+	got.Description = "written with the new key"
+	if err := planRepo.Update(ctx, got); err != nil {
+		t.Fatalf("failed to update the plan: %v", err)
+	}
+
+	client.SetFieldCipher(newFieldCipher(t, newKey))
+	got, err = planRepo.Get(ctx, plan.ID)
+	if err != nil || got.Description != "written with the new key" {
+		t.Fatalf("expected the description written with the new key, got %+v, %v", got, err)
+	}
+	client.SetFieldCipher(newFieldCipher(t, oldKey))
+	if _, err := planRepo.Get(ctx, plan.ID); !errors.Is(err, storage.ErrDecryption) {
+		t.Errorf("expected a decryption error with a retired key, got %v", err)
+	}
+}
+
+// TestParseStaticKeys tests parsing keys from the environment setting
+func TestParseStaticKeys(t *testing.T) {
+	key := newEncryptionKey(t)
+	keys, err := storage.ParseStaticKeys(" " + base64.StdEncoding.EncodeToString(key) + ", ,")
+	if err != nil || len(keys) != 1 {
+		t.Fatalf("expected one key, got %d, %v", len(keys), err)
+	}
+	if _, err := storage.ParseStaticKeys("not base64!"); err == nil {
+		t.Error("expected an error for a key that is not base64")
+	}
+	if _, err := storage.NewFieldCipher(context.Background(), storage.StaticKeys{[]byte("short")}); err == nil {
+		t.Error("expected an error for a key of the wrong length")
+	}
+	if _, err := storage.NewFieldCipher(context.Background(), storage.StaticKeys{}); err == nil {
+		t.Error("expected an error without keys")
+	}
+}
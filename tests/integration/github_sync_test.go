@@ -0,0 +1,180 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/integrations/github"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/tests/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeGitHub is an in-memory stand-in for the GitHub issues API of a single repository
+type fakeGitHub struct {
+	mu     sync.Mutex
+	issues []*github.Issue
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	const base = "/repos/owner/repo/issues"
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == base:
+		json.NewEncoder(w).Encode(f.issues)
+	case r.Method == http.MethodPost && r.URL.Path == base:
+		var req github.IssueRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		issue := &github.Issue{Number: len(f.issues) + 1, Title: req.Title, Body: *req.Body, State: "open"}
+		issue.HTMLURL = "https://github.com/owner/repo/issues/" + strconv.Itoa(issue.Number)
+		f.issues = append(f.issues, issue)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(issue)
+	default:
+		number, err := strconv.Atoi(r.URL.Path[len(base)+1:])
+		if err != nil || number < 1 || number > len(f.issues) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		issue := f.issues[number-1]
+		if r.Method == http.MethodPatch {
+			var req github.IssueRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.Title != "" {
+				issue.Title = req.Title
+			}
+			if req.State != "" {
+				issue.State, issue.StateReason = req.State, req.StateReason
+			}
+		}
+		json.NewEncoder(w).Encode(issue)
+	}
+}
+
+// issue returns an issue of the fake repository
+func (f *fakeGitHub) issue(number int) *github.Issue {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.issues[number-1]
+}
+
+// GitHubSyncTestSuite is a test suite for the GitHub issue sync
+type GitHubSyncTestSuite struct {
+	utils.RepositoryTestSuite
+	fake   *fakeGitHub
+	server *httptest.Server
+	syncer *github.Syncer
+}
+
+// SetupTest sets up each test
+func (s *GitHubSyncTestSuite) SetupTest() {
+	s.RepositoryTestSuite.SetupTest()
+	s.fake = &fakeGitHub{}
+	s.server = httptest.NewServer(s.fake)
+	s.syncer = github.NewSyncer(s.GetPlanRepository(), s.GetTaskRepository(), github.NewClient(s.server.URL, "token"))
+}
+
+// TearDownTest cleans up after each test
+func (s *GitHubSyncTestSuite) TearDownTest() {
+	s.server.Close()
+	s.RepositoryTestSuite.TearDownTest()
+}
+
+// TestSyncPlan tests pushing tasks as issues and syncing status changes in both directions
+func (s *GitHubSyncTestSuite) TestSyncPlan() {
+	taskRepo := s.GetTaskRepository()
+	plan, err := s.GetPlanRepository().Create(s.Context, "github-app-"+uuid.New().String(), "GitHub Plan", "desc")
+	s.Require().NoError(err)
+
+	first, err := taskRepo.Create(s.Context, plan.ID, "First", "Do first", models.TaskPriorityHigh)
+	s.Require().NoError(err)
+	second, err := taskRepo.Create(s.Context, plan.ID, "Second", "Do second", models.TaskPriorityLow)
+	s.Require().NoError(err)
+	done, err := taskRepo.Create(s.Context, plan.ID, "Already done", "", models.TaskPriorityLow)
+	s.Require().NoError(err)
+	done.Status = models.TaskStatusCompleted
+	s.Require().NoError(taskRepo.Update(s.Context, done))
+
+	report, err := s.syncer.SyncPlan(s.Context, plan.ID, "owner/repo")
+	s.Require().NoError(err)
+	s.Require().Len(report.Created, 2)
+	s.Equal(1, report.SkippedClosed)
+	s.Empty(report.Errors)
+
+	linked, err := taskRepo.Get(s.Context, first.ID)
+	s.Require().NoError(err)
+	s.Equal("owner/repo", linked.Metadata[github.MetadataRepo])
+	s.Equal("1", linked.Metadata[github.MetadataIssue])
+
+	// The first task is completed here and the second issue is closed on GitHub
+	linked.Status = models.TaskStatusCompleted
+	s.Require().NoError(taskRepo.Update(s.Context, linked))
+	s.fake.issue(2).State = "closed"
+	s.fake.issue(2).StateReason = "not_planned"
+
+	report, err = s.syncer.SyncPlan(s.Context, plan.ID, "owner/repo")
+	s.Require().NoError(err)
+	s.Empty(report.Created)
+	s.Require().Len(report.Pushed, 1)
+	s.Equal(1, report.Pushed[0].IssueNumber)
+	s.Require().Len(report.Pulled, 1)
+	s.Equal(second.ID, report.Pulled[0].TaskID)
+
+	s.Equal("closed", s.fake.issue(1).State)
+	s.Equal("completed", s.fake.issue(1).StateReason)
+	pulled, err := taskRepo.Get(s.Context, second.ID)
+	s.Require().NoError(err)
+	s.Equal(models.TaskStatusCancelled, pulled.Status)
+
+	// A third sync finds nothing to do
+	report, err = s.syncer.SyncPlan(s.Context, plan.ID, "owner/repo")
+	s.Require().NoError(err)
+	s.Empty(report.Pushed)
+	s.Empty(report.Pulled)
+	s.Equal(2, report.Unchanged)
+}
+
+// TestImportIssues tests creating linked tasks from issues without importing them twice
+func (s *GitHubSyncTestSuite) TestImportIssues() {
+	plan, err := s.GetPlanRepository().Create(s.Context, "github-app-"+uuid.New().String(), "GitHub Plan", "desc")
+	s.Require().NoError(err)
+
+	s.fake.issues = []*github.Issue{
+		{Number: 1, Title: "Bug", Body: "Broken", State: "open", Labels: []github.Label{{Name: "priority: high"}}},
+		{Number: 2, Title: "Shipped", State: "closed", StateReason: "completed"},
+	}
+
+	report, err := s.syncer.ImportIssues(s.Context, plan.ID, "owner/repo", "all", nil)
+	s.Require().NoError(err)
+	s.Require().Len(report.Imported, 2)
+	s.Empty(report.AlreadyLinked)
+
+	tasks, err := s.GetTaskRepository().ListByPlan(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.Require().Len(tasks, 2)
+	s.Equal("Bug", tasks[0].Title)
+	s.Equal(models.TaskPriorityHigh, tasks[0].Priority)
+	s.Equal("1", tasks[0].Metadata[github.MetadataIssue])
+	s.Equal(models.TaskStatusCompleted, tasks[1].Status)
+
+	report, err = s.syncer.ImportIssues(s.Context, plan.ID, "owner/repo", "all", nil)
+	s.Require().NoError(err)
+	s.Empty(report.Imported)
+	s.Equal([]int{1, 2}, report.AlreadyLinked)
+}
+
+// TestGitHubSyncSuite runs the GitHub sync test suite
+func TestGitHubSyncSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	suite.Run(t, new(GitHubSyncTestSuite))
+}
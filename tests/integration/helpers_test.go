@@ -0,0 +1,30 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// testRepos are the repositories of a test on a fresh in-memory store
+type testRepos struct {
+	client   *storage.ValkeyClient
+	planRepo *storage.PlanRepository
+	taskRepo *storage.TaskRepository
+}
+
+// newTestRepos returns plain repositories on a fresh in-memory store, which is closed when the test ends
+func newTestRepos(t *testing.T) *testRepos {
+	t.Helper()
+	client, err := storage.NewMemoryClient(storage.MemoryConfig{})
+	if err != nil {
+		t.Fatalf("failed to create the store: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return &testRepos{
+		client:   client,
+		planRepo: storage.NewPlanRepository(client),
+		taskRepo: storage.NewTaskRepository(client),
+	}
+}
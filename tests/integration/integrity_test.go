@@ -0,0 +1,208 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+	"github.com/jbrinkman/valkey-ai-tasks/tests/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+// IntegrityCheckerSuite is a test suite for the data integrity checker
+type IntegrityCheckerSuite struct {
+	utils.RepositoryTestSuite
+}
+
+// issueKinds returns the kinds of the issues in a report keyed by task or plan ID
+func issueKinds(report *storage.IntegrityReport) map[string]storage.IntegrityIssueKind {
+	kinds := make(map[string]storage.IntegrityIssueKind, len(report.Issues))
+	for _, issue := range report.Issues {
+		id := issue.TaskID
+		if id == "" {
+			id = issue.PlanID
+		}
+		kinds[id] = issue.Kind
+	}
+	return kinds
+}
+
+// TestCheckAndRepair tests that each kind of inconsistency is found and repaired
+func (s *IntegrityCheckerSuite) TestCheckAndRepair() {
+	planRepo := s.GetPlanRepository()
+	taskRepo := s.GetTaskRepository()
+	raw := s.Containers[len(s.Containers)-1].Client
+
+	plan, err := planRepo.Create(s.Context, "test-app-"+uuid.New().String(), "Test Plan", "")
+	s.Require().NoError(err)
+	hidden, err := planRepo.Create(s.Context, "test-app-"+uuid.New().String(), "Hidden Plan", "")
+	s.Require().NoError(err)
+	tasks, err := taskRepo.CreateBulk(s.Context, plan.ID, []storage.TaskCreateInput{
+		{Title: "First"}, {Title: "Second"}, {Title: "Third"},
+	})
+	s.Require().NoError(err)
+
+	checker := storage.NewIntegrityChecker(s.ValkeyClient)
+	report, err := checker.Check(s.Context, false)
+	s.Require().NoError(err)
+	s.Empty(report.Issues, "A consistent database should have no issues")
+	s.Equal(2, report.PlansChecked)
+	s.Equal(3, report.TasksChecked)
+
+	// Break the database the way interrupted writes would
+	_, err = raw.SRem(s.Context, "plans", []string{hidden.ID})
+	s.Require().NoError(err)
+	_, err = raw.ZRem(s.Context, storage.GetPlanTasksKey(plan.ID), []string{tasks[2].ID})
+	s.Require().NoError(err)
+	_, err = raw.ZAdd(s.Context, storage.GetPlanTasksKey(plan.ID), map[string]float64{"ghost": 5})
+	s.Require().NoError(err)
+	_, err = raw.ZAdd(s.Context, storage.GetPlanTasksKey(plan.ID), map[string]float64{tasks[1].ID: 0})
+	s.Require().NoError(err)
+
+	report, err = checker.Check(s.Context, false)
+	s.Require().NoError(err)
+	s.Equal(map[string]storage.IntegrityIssueKind{
+		hidden.ID:   storage.IntegrityPlanNotListed,
+		tasks[2].ID: storage.IntegrityTaskNotInPlan,
+		"ghost":     storage.IntegrityMissingTask,
+		tasks[1].ID: storage.IntegrityDuplicateOrder,
+	}, issueKinds(report))
+	for _, issue := range report.Issues {
+		s.False(issue.Repaired, "Issues should only be reported without repair")
+	}
+
+	report, err = checker.Check(s.Context, true)
+	s.Require().NoError(err)
+	s.Len(report.Issues, 4)
+	for _, issue := range report.Issues {
+		s.True(issue.Repaired, "Issue %s should be repaired", issue.Kind)
+	}
+
+	report, err = checker.Check(s.Context, false)
+	s.Require().NoError(err)
+	s.Empty(report.Issues, "Repairs should leave no issues")
+
+	// The tasks are listed again in their original order with sequential orders
+	listed, err := taskRepo.ListByPlan(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.Require().Len(listed, 3)
+	for i, task := range listed {
+		s.Equal(tasks[i].ID, task.ID)
+		s.Equal(i, task.Order)
+	}
+	plans, err := planRepo.List(s.Context)
+	s.Require().NoError(err)
+	s.Len(plans, 2, "The hidden plan should be listed again")
+}
+
+// TestTaskCounts tests that wrong task counters are found and repaired, and that missing ones are recounted
+func (s *IntegrityCheckerSuite) TestTaskCounts() {
+	planRepo := s.GetPlanRepository()
+	taskRepo := s.GetTaskRepository()
+	raw := s.Containers[len(s.Containers)-1].Client
+
+	plan, err := planRepo.Create(s.Context, "test-app-"+uuid.New().String(), "Test Plan", "")
+	s.Require().NoError(err)
+	_, err = taskRepo.CreateBulk(s.Context, plan.ID, []storage.TaskCreateInput{
+		{Title: "First", Status: models.TaskStatusInProgress}, {Title: "Second"},
+	})
+	s.Require().NoError(err)
+
+	_, err = raw.HSet(s.Context, storage.GetPlanKey(plan.ID), map[string]string{models.TaskCountTotalField: "5"})
+	s.Require().NoError(err)
+
+	checker := storage.NewIntegrityChecker(s.ValkeyClient)
+	report, err := checker.Check(s.Context, true)
+	s.Require().NoError(err)
+	s.Equal(map[string]storage.IntegrityIssueKind{plan.ID: storage.IntegrityTaskCountMismatch}, issueKinds(report))
+	s.True(report.Issues[0].Repaired)
+
+	stored, err := planRepo.Get(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.Equal(&models.TaskCounts{Total: 2, Pending: 1, InProgress: 1}, stored.TaskCounts)
+
+	// Plans stored before counting existed are counted when their status is next updated
+	fields := make([]string, 0, len(stored.TaskCounts.Fields()))
+	for field := range stored.TaskCounts.Fields() {
+		fields = append(fields, field)
+	}
+	_, err = raw.HDel(s.Context, storage.GetPlanKey(plan.ID), fields)
+	s.Require().NoError(err)
+
+	report, err = checker.Check(s.Context, false)
+	s.Require().NoError(err)
+	s.Empty(report.Issues, "Plans without counters are not inconsistent")
+
+	s.Require().NoError(taskRepo.UpdatePlanStatus(s.Context, plan.ID))
+	stored, err = planRepo.Get(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.Equal(&models.TaskCounts{Total: 2, Pending: 1, InProgress: 1}, stored.TaskCounts)
+	s.Equal(models.PlanStatusInProgress, stored.Status)
+}
+
+// TestOrphanedTasks tests that tasks of deleted plans are reported but left alone
+func (s *IntegrityCheckerSuite) TestOrphanedTasks() {
+	planRepo := s.GetPlanRepository()
+	taskRepo := s.GetTaskRepository()
+	raw := s.Containers[len(s.Containers)-1].Client
+
+	plan, err := planRepo.Create(s.Context, "test-app-"+uuid.New().String(), "Test Plan", "")
+	s.Require().NoError(err)
+	task, err := taskRepo.Create(s.Context, plan.ID, "Orphan", "", models.TaskPriorityMedium)
+	s.Require().NoError(err)
+
+	_, err = raw.Del(s.Context, []string{storage.GetPlanKey(plan.ID), storage.GetPlanTasksKey(plan.ID)})
+	s.Require().NoError(err)
+	_, err = raw.SRem(s.Context, "plans", []string{plan.ID})
+	s.Require().NoError(err)
+
+	report, err := storage.NewIntegrityChecker(s.ValkeyClient).Check(s.Context, true)
+	s.Require().NoError(err)
+	s.Require().Len(report.Issues, 1)
+	s.Equal(storage.IntegrityOrphanedTask, report.Issues[0].Kind)
+	s.Equal(task.ID, report.Issues[0].TaskID)
+	s.False(report.Issues[0].Repaired)
+}
+
+// TestOrphanCleanup tests that the orphan cleaner deletes tasks of deleted plans and nothing else
+func (s *IntegrityCheckerSuite) TestOrphanCleanup() {
+	planRepo := s.GetPlanRepository()
+	taskRepo := s.GetTaskRepository()
+	raw := s.Containers[len(s.Containers)-1].Client
+
+	appID := "test-app-" + uuid.New().String()
+	kept, err := planRepo.Create(s.Context, appID, "Kept Plan", "")
+	s.Require().NoError(err)
+	keptTask, err := taskRepo.Create(s.Context, kept.ID, "Kept", "", models.TaskPriorityMedium)
+	s.Require().NoError(err)
+	deleted, err := planRepo.Create(s.Context, appID, "Deleted Plan", "")
+	s.Require().NoError(err)
+	orphan, err := taskRepo.Create(s.Context, deleted.ID, "Orphan", "", models.TaskPriorityMedium)
+	s.Require().NoError(err)
+
+	_, err = raw.Del(s.Context, []string{storage.GetPlanKey(deleted.ID), storage.GetPlanTasksKey(deleted.ID)})
+	s.Require().NoError(err)
+	_, err = raw.SRem(s.Context, "plans", []string{deleted.ID})
+	s.Require().NoError(err)
+
+	cleaner := services.NewOrphanCleaner(storage.NewIntegrityChecker(s.ValkeyClient), taskRepo)
+	removed, err := cleaner.Run(s.Context)
+	s.Require().NoError(err)
+	s.Equal([]string{orphan.ID}, removed)
+
+	_, err = taskRepo.Get(s.Context, orphan.ID)
+	s.Error(err, "The orphaned task should be deleted")
+	_, err = taskRepo.Get(s.Context, keptTask.ID)
+	s.NoError(err, "Tasks of existing plans should be kept")
+}
+
+// TestIntegrityCheckerSuite runs the integrity checker test suite
+func TestIntegrityCheckerSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	suite.Run(t, new(IntegrityCheckerSuite))
+}
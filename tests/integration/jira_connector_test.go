@@ -0,0 +1,210 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/integrations/jira"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/tests/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeJiraIssue is an issue of the fake Jira site
+type fakeJiraIssue struct {
+	key      string
+	summary  string
+	status   string
+	priority string
+}
+
+// fakeJiraWorkflow maps transition IDs to the statuses they lead to
+var fakeJiraWorkflow = map[string]string{"11": "To Do", "21": "In Progress", "31": "Done"}
+
+// fakeJira is an in-memory stand-in for the Jira search, issue and transitions APIs.
+// Every issue can move to any status of a simple To Do, In Progress, Done workflow.
+type fakeJira struct {
+	mu     sync.Mutex
+	issues []*fakeJiraIssue
+}
+
+func (f *fakeJira) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	const issuePath = "/rest/api/2/issue/"
+	switch {
+	case r.URL.Path == "/rest/api/2/search":
+		issues := []map[string]any{}
+		for _, issue := range f.issues {
+			issues = append(issues, issue.json())
+		}
+		json.NewEncoder(w).Encode(map[string]any{"startAt": 0, "total": len(issues), "issues": issues})
+	case strings.HasPrefix(r.URL.Path, issuePath):
+		key, transitions := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, issuePath), "/transitions")
+		issue := f.find(key)
+		if issue == nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]any{"errorMessages": []string{"Issue does not exist"}})
+			return
+		}
+		switch {
+		case !transitions:
+			json.NewEncoder(w).Encode(issue.json())
+		case r.Method == http.MethodGet:
+			available := []map[string]any{}
+			for id, status := range fakeJiraWorkflow {
+				available = append(available, map[string]any{"id": id, "name": status, "to": map[string]any{"name": status}})
+			}
+			json.NewEncoder(w).Encode(map[string]any{"transitions": available})
+		default:
+			var req struct {
+				Transition struct {
+					ID string `json:"id"`
+				} `json:"transition"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			issue.status = fakeJiraWorkflow[req.Transition.ID]
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// find returns the issue with the given key
+func (f *fakeJira) find(key string) *fakeJiraIssue {
+	for _, issue := range f.issues {
+		if issue.key == key {
+			return issue
+		}
+	}
+	return nil
+}
+
+// statusOf returns the current status of an issue
+func (f *fakeJira) statusOf(key string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.find(key).status
+}
+
+// json renders an issue as returned by the Jira API
+func (i *fakeJiraIssue) json() map[string]any {
+	return map[string]any{
+		"key": i.key,
+		"fields": map[string]any{
+			"summary":  i.summary,
+			"status":   map[string]any{"name": i.status},
+			"priority": map[string]any{"name": i.priority},
+		},
+	}
+}
+
+// JiraConnectorTestSuite is a test suite for the Jira connector
+type JiraConnectorTestSuite struct {
+	utils.RepositoryTestSuite
+	fake      *fakeJira
+	server    *httptest.Server
+	connector *jira.Connector
+}
+
+// SetupTest sets up each test
+func (s *JiraConnectorTestSuite) SetupTest() {
+	s.RepositoryTestSuite.SetupTest()
+	s.fake = &fakeJira{issues: []*fakeJiraIssue{
+		{key: "WEB-1", summary: "Login page", status: "To Do", priority: "Highest"},
+		{key: "WEB-2", summary: "Signup page", status: "In Progress", priority: "Low"},
+		{key: "WEB-3", summary: "Landing page", status: "Done", priority: "Medium"},
+	}}
+	s.server = httptest.NewServer(s.fake)
+	client := jira.NewClient(s.server.URL, "me@example.com", "token")
+	s.connector = jira.NewConnector(s.GetPlanRepository(), s.GetTaskRepository(), client, jira.DefaultFieldMapping())
+}
+
+// TearDownTest cleans up after each test
+func (s *JiraConnectorTestSuite) TearDownTest() {
+	s.server.Close()
+	s.RepositoryTestSuite.TearDownTest()
+}
+
+// TestImportIssues tests creating linked tasks from a JQL query without importing them twice
+func (s *JiraConnectorTestSuite) TestImportIssues() {
+	plan, err := s.GetPlanRepository().Create(s.Context, "jira-app-"+uuid.New().String(), "Jira Plan", "desc")
+	s.Require().NoError(err)
+
+	report, err := s.connector.ImportIssues(s.Context, plan.ID, "project = WEB", 0)
+	s.Require().NoError(err)
+	s.Require().Len(report.Imported, 3)
+	s.Empty(report.Errors)
+
+	tasks, err := s.GetTaskRepository().ListByPlan(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.Require().Len(tasks, 3)
+	s.Equal("Login page", tasks[0].Title)
+	s.Equal(models.TaskPriorityHigh, tasks[0].Priority)
+	s.Equal("WEB-1", tasks[0].Metadata[jira.MetadataKey])
+	s.Equal(s.server.URL+"/browse/WEB-1", tasks[0].Metadata[jira.MetadataURL])
+	s.Equal(models.TaskStatusInProgress, tasks[1].Status)
+	s.Equal(models.TaskStatusCompleted, tasks[2].Status)
+
+	report, err = s.connector.ImportIssues(s.Context, plan.ID, "project = WEB", 0)
+	s.Require().NoError(err)
+	s.Empty(report.Imported)
+	s.Equal([]string{"WEB-1", "WEB-2", "WEB-3"}, report.AlreadyLinked)
+}
+
+// TestPushStatuses tests transitioning issues to match the status of their tasks
+func (s *JiraConnectorTestSuite) TestPushStatuses() {
+	taskRepo := s.GetTaskRepository()
+	plan, err := s.GetPlanRepository().Create(s.Context, "jira-app-"+uuid.New().String(), "Jira Plan", "desc")
+	s.Require().NoError(err)
+
+	_, err = s.connector.ImportIssues(s.Context, plan.ID, "project = WEB", 0)
+	s.Require().NoError(err)
+
+	// Nothing changed since the import
+	report, err := s.connector.PushStatuses(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.Empty(report.Transitioned)
+	s.Equal(3, report.Unchanged)
+
+	tasks, err := taskRepo.ListByPlan(s.Context, plan.ID)
+	s.Require().NoError(err)
+	tasks[0].Status = models.TaskStatusCompleted
+	s.Require().NoError(taskRepo.Update(s.Context, tasks[0]))
+
+	report, err = s.connector.PushStatuses(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.Require().Len(report.Transitioned, 1)
+	s.Equal("WEB-1", report.Transitioned[0].IssueKey)
+	s.Equal("Done", report.Transitioned[0].JiraStatus)
+	s.Equal(2, report.Unchanged)
+	s.Empty(report.Errors)
+	s.Equal("Done", s.fake.statusOf("WEB-1"))
+
+	// Cancelled has no transition in this workflow, which is reported per task
+	tasks[1].Status = models.TaskStatusCancelled
+	s.Require().NoError(taskRepo.Update(s.Context, tasks[1]))
+
+	report, err = s.connector.PushStatuses(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.Empty(report.Transitioned)
+	s.Require().Len(report.Errors, 1)
+	s.Equal("WEB-2", report.Errors[0].IssueKey)
+	s.Equal("In Progress", s.fake.statusOf("WEB-2"))
+}
+
+// TestJiraConnectorSuite runs the Jira connector test suite
+func TestJiraConnectorSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	suite.Run(t, new(JiraConnectorTestSuite))
+}
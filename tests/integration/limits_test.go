@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+	"github.com/jbrinkman/valkey-ai-tasks/tests/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+// LimitsTestSuite is a test suite for the repository size limits
+type LimitsTestSuite struct {
+	utils.RepositoryTestSuite
+	planRepo storage.PlanRepositoryInterface
+	taskRepo storage.TaskRepositoryInterface
+}
+
+// SetupTest sets up each test
+func (s *LimitsTestSuite) SetupTest() {
+	s.RepositoryTestSuite.SetupTest()
+	limits := storage.Limits{
+		MaxTitleLength:       20,
+		MaxDescriptionLength: 50,
+		MaxNotesLength:       100,
+		MaxBulkTasks:         3,
+		MaxTasksPerPlan:      4,
+	}
+	s.planRepo = storage.NewLimitedPlanRepository(s.GetPlanRepository(), limits)
+	s.taskRepo = storage.NewLimitedTaskRepository(s.GetTaskRepository(), limits)
+}
+
+// TestTextLimits tests rejecting names, titles, descriptions and notes that are too long
+func (s *LimitsTestSuite) TestTextLimits() {
+	_, err := s.planRepo.Create(s.Context, "limits-app", strings.Repeat("n", 21), "desc")
+	s.ErrorIs(err, storage.ErrLimitExceeded)
+
+	plan, err := s.planRepo.Create(s.Context, "limits-app-"+uuid.New().String(), "Plan", "desc")
+	s.Require().NoError(err)
+
+	s.ErrorIs(s.planRepo.UpdateNotes(s.Context, plan.ID, strings.Repeat("x", 101)), storage.ErrLimitExceeded)
+	s.NoError(s.planRepo.UpdateNotes(s.Context, plan.ID, strings.Repeat("x", 100)))
+
+	_, err = s.taskRepo.Create(s.Context, plan.ID, "Task", strings.Repeat("d", 51), models.TaskPriorityLow)
+	s.ErrorIs(err, storage.ErrLimitExceeded)
+
+	task, err := s.taskRepo.Create(s.Context, plan.ID, "Task", "desc", models.TaskPriorityLow)
+	s.Require().NoError(err)
+
+	task.Title = strings.Repeat("t", 21)
+	s.ErrorIs(s.taskRepo.Update(s.Context, task), storage.ErrLimitExceeded)
+
+	stored, err := s.taskRepo.Get(s.Context, task.ID)
+	s.Require().NoError(err)
+	s.Equal("Task", stored.Title)
+}
+
+// TestTaskCountLimits tests the bulk size and tasks per plan limits
+func (s *LimitsTestSuite) TestTaskCountLimits() {
+	plan, err := s.planRepo.Create(s.Context, "limits-app-"+uuid.New().String(), "Plan", "desc")
+	s.Require().NoError(err)
+
+	tooMany := []storage.TaskCreateInput{{Title: "1"}, {Title: "2"}, {Title: "3"}, {Title: "4"}}
+	_, err = s.taskRepo.CreateBulk(s.Context, plan.ID, tooMany)
+	s.ErrorIs(err, storage.ErrLimitExceeded)
+
+	_, err = s.taskRepo.CreateBulk(s.Context, plan.ID, tooMany[:3])
+	s.Require().NoError(err)
+
+	// The plan has room for one more task
+	_, err = s.taskRepo.CreateBulkWithOptions(s.Context, plan.ID, tooMany[:2], storage.BulkCreateOptions{})
+	s.ErrorIs(err, storage.ErrLimitExceeded)
+
+	_, err = s.taskRepo.Create(s.Context, plan.ID, "4", "", models.TaskPriorityLow)
+	s.Require().NoError(err)
+
+	_, err = s.taskRepo.Create(s.Context, plan.ID, "5", "", models.TaskPriorityLow)
+	s.ErrorIs(err, storage.ErrLimitExceeded)
+
+	count, err := s.taskRepo.CountByPlan(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.Equal(int64(4), count)
+}
+
+// TestLimitsSuite runs the limits test suite
+func TestLimitsSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	suite.Run(t, new(LimitsTestSuite))
+}
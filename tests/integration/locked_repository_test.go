@@ -0,0 +1,111 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// TestLockedRepositories tests that locked plans and their tasks can only be changed with the override of
+// admins, and that the lock survives restores but not clones
+func TestLockedRepositories(t *testing.T) {
+	ctx := context.Background()
+	repos := newTestRepos(t)
+	planRepo := storage.NewLockedPlanRepository(repos.planRepo)
+	taskRepo := storage.NewLockedTaskRepository(repos.taskRepo, planRepo)
+
+	plan, err := planRepo.Create(ctx, "locked-app", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create the plan: %v", err)
+	}
+	task, err := taskRepo.Create(ctx, plan.ID, "Task", "", models.TaskPriorityMedium)
+	if err != nil {
+		t.Fatalf("failed to create the task: %v", err)
+	}
+
+	lock := &models.PlanLock{LockedBy: "reviewer", Reason: "under review", LockedAt: time.Now()}
+	locked, err := planRepo.SetLock(ctx, plan.ID, lock)
+	if err != nil {
+		t.Fatalf("failed to lock the plan: %v", err)
+	}
+	if locked.Lock == nil || locked.Lock.LockedBy != "reviewer" || locked.Lock.Reason != "under review" {
+		t.Fatalf("expected the plan to be locked, got %+v", locked.Lock)
+	}
+
+	// Every change is rejected with a conflict error
+	task.Status = models.TaskStatusInProgress
+	for name, change := range map[string]func(ctx context.Context) error{
+		"update task": func(ctx context.Context) error { return taskRepo.Update(ctx, task) },
+		"create task": func(ctx context.Context) error {
+			_, err := taskRepo.Create(ctx, plan.ID, "Another", "", models.TaskPriorityLow)
+			return err
+		},
+		"claim task": func(ctx context.Context) error {
+			_, err := taskRepo.ClaimTask(ctx, task.ID, "worker", time.Minute)
+			return err
+		},
+		"tag task":     func(ctx context.Context) error { _, err := taskRepo.AddTags(ctx, task.ID, []string{"x"}); return err },
+		"plan notes":   func(ctx context.Context) error { return planRepo.UpdateNotes(ctx, plan.ID, "notes") },
+		"update plan":  func(ctx context.Context) error { return planRepo.Update(ctx, locked) },
+		"delete plan":  func(ctx context.Context) error { return planRepo.Delete(ctx, plan.ID) },
+		"lock again":   func(ctx context.Context) error { _, err := planRepo.SetLock(ctx, plan.ID, lock); return err },
+		"delete task":  func(ctx context.Context) error { return taskRepo.Delete(ctx, task.ID) },
+		"reorder plan": func(ctx context.Context) error { _, err := planRepo.ReorderPlan(ctx, plan.ID, 0); return err },
+	} {
+		if err := change(ctx); models.ErrorCodeOf(err) != models.ErrorCodeConflict {
+			t.Errorf("%s: expected a conflict error on a locked plan, got %v", name, err)
+		}
+	}
+
+	// Admins may change a locked plan, which keeps its lock
+	adminCtx := storage.WithPlanLockOverride(ctx)
+	if err := taskRepo.Update(adminCtx, task); err != nil {
+		t.Fatalf("failed to update the task as admin: %v", err)
+	}
+	if err := planRepo.Update(adminCtx, locked); err != nil {
+		t.Fatalf("failed to update the plan as admin: %v", err)
+	}
+	if got, err := planRepo.Get(ctx, plan.ID); err != nil || got.Lock == nil {
+		t.Fatalf("expected the plan to stay locked after an update, got %+v, %v", got, err)
+	}
+
+	// A restored plan keeps the lock of its snapshot, while a clone is not locked
+	if err := planRepo.Restore(adminCtx, locked); err != nil {
+		t.Fatalf("failed to restore the plan: %v", err)
+	}
+	if got, err := planRepo.Get(ctx, plan.ID); err != nil || got.Lock == nil || got.Lock.Reason != "under review" {
+		t.Errorf("expected the restored plan to be locked, got %+v, %v", got, err)
+	}
+	clone, err := planRepo.Clone(ctx, plan.ID, storage.PlanCloneOptions{})
+	if err != nil {
+		t.Fatalf("failed to clone the plan: %v", err)
+	}
+	if clone.Lock != nil {
+		t.Errorf("expected the clone not to be locked, got %+v", clone.Lock)
+	}
+
+	// Locked plans are never expired by retention
+	locked.Status = models.PlanStatusCompleted
+	if services.IsPlanExpired(locked, time.Hour, time.Now().Add(48*time.Hour)) {
+		t.Error("a locked plan should not expire")
+	}
+
+	// Only admins may unlock a plan, after which it can be changed again
+	if _, err := planRepo.SetLock(ctx, plan.ID, nil); models.ErrorCodeOf(err) != models.ErrorCodeConflict {
+		t.Errorf("expected a conflict error unlocking a locked plan without the override, got %v", err)
+	}
+	unlocked, err := planRepo.SetLock(adminCtx, plan.ID, nil)
+	if err != nil {
+		t.Fatalf("failed to unlock the plan: %v", err)
+	}
+	if unlocked.Lock != nil {
+		t.Errorf("expected the plan to be unlocked, got %+v", unlocked.Lock)
+	}
+	if err := taskRepo.Delete(ctx, task.ID); err != nil {
+		t.Errorf("failed to delete the task of an unlocked plan: %v", err)
+	}
+}
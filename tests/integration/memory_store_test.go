@@ -0,0 +1,833 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+	"github.com/stretchr/testify/suite"
+)
+
+// MemoryStoreTestSuite tests the repositories on the in-memory store. It needs no Valkey container.
+type MemoryStoreTestSuite struct {
+	suite.Suite
+	Context  context.Context
+	Client   *storage.ValkeyClient
+	PlanRepo *storage.PlanRepository
+	TaskRepo *storage.TaskRepository
+}
+
+// SetupTest creates an empty store for each test
+func (s *MemoryStoreTestSuite) SetupTest() {
+	s.Context = context.Background()
+	repos := newTestRepos(s.T())
+	s.Client, s.PlanRepo, s.TaskRepo = repos.client, repos.planRepo, repos.taskRepo
+}
+
+// TestPlansAndTasks tests creating, ordering, updating and deleting plans and tasks
+func (s *MemoryStoreTestSuite) TestPlansAndTasks() {
+	s.Require().NoError(s.Client.Ping(s.Context))
+
+	plan, err := s.PlanRepo.Create(s.Context, "memory-app", "Demo plan", "Runs without Valkey")
+	s.Require().NoError(err)
+
+	first, err := s.TaskRepo.Create(s.Context, plan.ID, "First", "", models.TaskPriorityHigh)
+	s.Require().NoError(err)
+	second, err := s.TaskRepo.Create(s.Context, plan.ID, "Second", "", models.TaskPriorityLow)
+	s.Require().NoError(err)
+	third, err := s.TaskRepo.Create(s.Context, plan.ID, "Third", "", models.TaskPriorityMedium)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.TaskRepo.ReorderTask(s.Context, third.ID, 0))
+	s.Equal(models.ErrorCodeValidation, models.ErrorCodeOf(s.TaskRepo.ReorderTask(s.Context, third.ID, 3)),
+		"An order past the end of the plan should be rejected")
+	tasks, err := s.TaskRepo.ListByPlan(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.Require().Len(tasks, 3)
+	s.Equal([]string{third.ID, first.ID, second.ID}, []string{tasks[0].ID, tasks[1].ID, tasks[2].ID})
+
+	_, err = s.TaskRepo.AddTags(s.Context, first.ID, []string{"demo"})
+	s.Require().NoError(err)
+	tagged, err := s.TaskRepo.ListByTag(s.Context, "demo")
+	s.Require().NoError(err)
+	s.Require().Len(tagged, 1)
+	s.Equal(first.ID, tagged[0].ID)
+
+	first.Status = models.TaskStatusCompleted
+	s.Require().NoError(s.TaskRepo.Update(s.Context, first))
+	completed, err := s.TaskRepo.ListByStatus(s.Context, models.TaskStatusCompleted)
+	s.Require().NoError(err)
+	s.Require().Len(completed, 1)
+
+	s.Require().NoError(s.TaskRepo.Delete(s.Context, second.ID))
+	count, err := s.TaskRepo.CountByPlan(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.Equal(int64(2), count)
+
+	s.Require().NoError(s.PlanRepo.Delete(s.Context, plan.ID))
+	plans, err := s.PlanRepo.List(s.Context)
+	s.Require().NoError(err)
+	s.Empty(plans)
+	_, err = s.TaskRepo.Get(s.Context, third.ID)
+	s.Error(err, "Deleting a plan should delete its tasks")
+}
+
+// TestLeases tests that leases are exclusive, renewable only by their holder and expire
+func (s *MemoryStoreTestSuite) TestLeases() {
+	plan, err := s.PlanRepo.Create(s.Context, "memory-app", "Lease plan", "")
+	s.Require().NoError(err)
+	task, err := s.TaskRepo.Create(s.Context, plan.ID, "Leased", "", models.TaskPriorityMedium)
+	s.Require().NoError(err)
+
+	_, err = s.TaskRepo.ClaimTask(s.Context, task.ID, "worker-1", 50*time.Millisecond)
+	s.Require().NoError(err)
+	_, err = s.TaskRepo.ClaimTask(s.Context, task.ID, "worker-2", time.Minute)
+	s.Require().Error(err)
+	s.Contains(err.Error(), "worker-1")
+
+	_, err = s.TaskRepo.RenewLease(s.Context, task.ID, "worker-2", time.Minute)
+	s.Require().Error(err)
+	_, err = s.TaskRepo.RenewLease(s.Context, task.ID, "worker-1", 50*time.Millisecond)
+	s.Require().NoError(err)
+
+	time.Sleep(100 * time.Millisecond)
+	expired, err := s.TaskRepo.ExpireLeases(s.Context)
+	s.Require().NoError(err)
+	s.Equal([]string{task.ID}, expired)
+
+	_, err = s.TaskRepo.ClaimTask(s.Context, task.ID, "worker-2", time.Minute)
+	s.Require().NoError(err)
+}
+
+// TestAuditLog tests that history is recorded newest first and trimmed to the retention
+func (s *MemoryStoreTestSuite) TestAuditLog() {
+	auditLog := storage.NewAuditLog(s.Client, storage.AuditRetention{MaxEntries: 2})
+	planRepo := storage.NewAuditedPlanRepository(s.PlanRepo, auditLog)
+
+	plan, err := planRepo.Create(s.Context, "memory-app", "Audited", "")
+	s.Require().NoError(err)
+	for _, name := range []string{"Renamed once", "Renamed twice"} {
+		plan.Name = name
+		s.Require().NoError(planRepo.Update(s.Context, plan))
+	}
+
+	entries, err := auditLog.History(s.Context, models.EntityTypePlan, plan.ID, 0)
+	s.Require().NoError(err)
+	s.Require().Len(entries, 2)
+	s.Equal("Renamed twice", entries[0].Changes["name"].After)
+	s.Equal("Renamed once", entries[1].Changes["name"].After)
+	s.Less(entries[1].ID, entries[0].ID)
+}
+
+// TestPlanTimeline tests that the timeline of a plan collects the events of the plan and its tasks in order
+func (s *MemoryStoreTestSuite) TestPlanTimeline() {
+	auditLog := storage.NewAuditLog(s.Client, storage.AuditRetention{})
+	planRepo := storage.NewAuditedPlanRepository(s.PlanRepo, auditLog)
+	taskRepo := storage.NewAuditedTaskRepository(s.TaskRepo, auditLog)
+	timeline := services.NewTimelineService(planRepo, taskRepo, auditLog)
+
+	plan, err := planRepo.Create(s.Context, "memory-app", "Timeline", "")
+	s.Require().NoError(err)
+	task, err := taskRepo.Create(s.Context, plan.ID, "Tracked", "", models.TaskPriorityMedium)
+	s.Require().NoError(err)
+	task.Status = models.TaskStatusInProgress
+	s.Require().NoError(taskRepo.Update(s.Context, task))
+	_, err = planRepo.AppendNotes(s.Context, plan.ID, "Halfway there")
+	s.Require().NoError(err)
+
+	// History of a task from before it moved into the plan is left out
+	other, err := planRepo.Create(s.Context, "memory-app", "Other", "")
+	s.Require().NoError(err)
+	moved, err := taskRepo.Create(s.Context, other.ID, "Moved", "", models.TaskPriorityMedium)
+	s.Require().NoError(err)
+	_, err = taskRepo.MoveTask(s.Context, moved.ID, plan.ID, -1)
+	s.Require().NoError(err)
+
+	events, err := timeline.GetPlanTimeline(s.Context, plan.ID)
+	s.Require().NoError(err)
+
+	types := make([]models.TimelineEventType, 0, len(events))
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	s.Equal([]models.TimelineEventType{
+		models.TimelineEventPlanCreated,
+		models.TimelineEventTaskCreated,
+		models.TimelineEventStatusChanged,
+		models.TimelineEventCommentAdded,
+		models.TimelineEventTaskUpdated,
+	}, types)
+	s.Equal(moved.ID, events[4].EntityID)
+
+	_, err = timeline.GetPlanTimeline(s.Context, "missing")
+	s.Error(err)
+}
+
+// TestSnapshot tests that data survives a restart through the snapshot file
+func (s *MemoryStoreTestSuite) TestSnapshot() {
+	snapshotFile := filepath.Join(s.T().TempDir(), "tasks.json")
+
+	client, err := storage.NewMemoryClient(storage.MemoryConfig{SnapshotFile: snapshotFile})
+	s.Require().NoError(err)
+	planRepo := storage.NewPlanRepository(client)
+	taskRepo := storage.NewTaskRepository(client)
+	plan, err := planRepo.Create(s.Context, "memory-app", "Persisted", "")
+	s.Require().NoError(err)
+	task, err := taskRepo.Create(s.Context, plan.ID, "Persisted task", "", models.TaskPriorityMedium)
+	s.Require().NoError(err)
+	_, err = taskRepo.AddTags(s.Context, task.ID, []string{"kept"})
+	s.Require().NoError(err)
+	client.Close()
+
+	restarted, err := storage.NewMemoryClient(storage.MemoryConfig{SnapshotFile: snapshotFile})
+	s.Require().NoError(err)
+	defer restarted.Close()
+	taskRepo = storage.NewTaskRepository(restarted)
+
+	tasks, err := taskRepo.ListByPlan(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.Require().Len(tasks, 1)
+	s.Equal("Persisted task", tasks[0].Title)
+	tagged, err := taskRepo.ListByTag(s.Context, "kept")
+	s.Require().NoError(err)
+	s.Len(tagged, 1)
+}
+
+// TestJobLocker tests that a job lock is taken by one replica until it expires
+func (s *MemoryStoreTestSuite) TestJobLocker() {
+	first := storage.NewJobLocker(s.Client, "replica-1")
+	second := storage.NewJobLocker(s.Client, "replica-2")
+
+	locked, err := first.TryLock(s.Context, "retention", 50*time.Millisecond)
+	s.Require().NoError(err)
+	s.True(locked)
+	locked, err = second.TryLock(s.Context, "retention", 50*time.Millisecond)
+	s.Require().NoError(err)
+	s.False(locked, "Another replica should not take a held lock")
+	locked, err = second.TryLock(s.Context, "lease-expiry", 50*time.Millisecond)
+	s.Require().NoError(err)
+	s.True(locked, "Locks of other jobs should be independent")
+
+	time.Sleep(100 * time.Millisecond)
+	locked, err = second.TryLock(s.Context, "retention", 50*time.Millisecond)
+	s.Require().NoError(err)
+	s.True(locked, "An expired lock should be free again")
+}
+
+// TestIdempotencyStore tests that a finished request is replayed and a running one is rejected
+func (s *MemoryStoreTestSuite) TestIdempotencyStore() {
+	store := storage.NewIdempotencyStore(s.Client, time.Minute)
+
+	_, found, err := store.Begin(s.Context, "create_plan", "request-1")
+	s.Require().NoError(err)
+	s.False(found)
+	_, _, err = store.Begin(s.Context, "create_plan", "request-1")
+	s.ErrorIs(err, storage.ErrIdempotencyInProgress)
+
+	s.Require().NoError(store.Complete(s.Context, "create_plan", "request-1", `{"id":"plan-1"}`))
+	result, found, err := store.Begin(s.Context, "create_plan", "request-1")
+	s.Require().NoError(err)
+	s.True(found)
+	s.Equal(`{"id":"plan-1"}`, result)
+
+	_, found, err = store.Begin(s.Context, "create_task", "request-1")
+	s.Require().NoError(err)
+	s.False(found, "Keys should be scoped per tool")
+	s.Require().NoError(store.Abandon(s.Context, "create_task", "request-1"))
+	_, found, err = store.Begin(s.Context, "create_task", "request-1")
+	s.Require().NoError(err)
+	s.False(found, "An abandoned key should be free again")
+}
+
+// TestLeaderElection tests that one replica leads until it resigns
+func (s *MemoryStoreTestSuite) TestLeaderElection() {
+	first := storage.NewLeaderElection(s.Client, "replica-1", time.Minute)
+	second := storage.NewLeaderElection(s.Client, "replica-2", time.Minute)
+
+	leader, err := first.Campaign(s.Context)
+	s.Require().NoError(err)
+	s.True(leader)
+	leader, err = second.Campaign(s.Context)
+	s.Require().NoError(err)
+	s.False(leader)
+	leader, err = first.Campaign(s.Context)
+	s.Require().NoError(err)
+	s.True(leader, "The leader should renew its leadership")
+
+	s.Require().NoError(first.Resign(s.Context))
+	leader, err = second.Campaign(s.Context)
+	s.Require().NoError(err)
+	s.True(leader, "Another replica should take over after the leader resigned")
+}
+
+// TestRepeatedReorders tests that moving tasks into the same gap again and again keeps the order intact,
+// including when the plan has to be renormalized
+func (s *MemoryStoreTestSuite) TestRepeatedReorders() {
+	plan, err := s.PlanRepo.Create(s.Context, "memory-app", "Reorder plan", "")
+	s.Require().NoError(err)
+	created, err := s.TaskRepo.CreateBulk(s.Context, plan.ID, []storage.TaskCreateInput{
+		{Title: "A"}, {Title: "B"}, {Title: "C"}, {Title: "D"}, {Title: "E"},
+	})
+	s.Require().NoError(err)
+
+	expected := make([]string, 0, len(created))
+	for _, task := range created {
+		expected = append(expected, task.ID)
+	}
+
+	// Each move halves the gap after the first task, so the plan runs out of room more than once
+	for range 100 {
+		last := expected[len(expected)-1]
+		s.Require().NoError(s.TaskRepo.ReorderTask(s.Context, last, 1))
+		expected = append([]string{expected[0], last}, expected[1:len(expected)-1]...)
+	}
+
+	tasks, err := s.TaskRepo.ListByPlan(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.Require().Len(tasks, len(expected))
+	for i, task := range tasks {
+		s.Equal(expected[i], task.ID)
+		s.Equal(i, task.Order)
+
+		stored, err := s.TaskRepo.Get(s.Context, task.ID)
+		s.Require().NoError(err)
+		s.Equal(i, stored.Order, "Get should report the position of the task")
+	}
+
+	// Deleting a task moves the tasks after it up
+	s.Require().NoError(s.TaskRepo.Delete(s.Context, expected[1]))
+	moved, err := s.TaskRepo.Get(s.Context, expected[2])
+	s.Require().NoError(err)
+	s.Equal(1, moved.Order)
+}
+
+// TestReorderTasks tests applying a full ordering of a plan's tasks
+func (s *MemoryStoreTestSuite) TestReorderTasks() {
+	plan, err := s.PlanRepo.Create(s.Context, "memory-app", "Reorder plan", "")
+	s.Require().NoError(err)
+	created, err := s.TaskRepo.CreateBulk(s.Context, plan.ID, []storage.TaskCreateInput{
+		{Title: "A"}, {Title: "B"}, {Title: "C"},
+	})
+	s.Require().NoError(err)
+
+	order := []string{created[2].ID, created[0].ID, created[1].ID}
+	tasks, err := s.TaskRepo.ReorderTasks(s.Context, plan.ID, order)
+	s.Require().NoError(err)
+	s.Require().Len(tasks, 3)
+	for i, task := range tasks {
+		s.Equal(order[i], task.ID)
+		s.Equal(i, task.Order)
+	}
+
+	_, err = s.TaskRepo.ReorderTasks(s.Context, plan.ID, order[:2])
+	s.Equal(models.ErrorCodeValidation, models.ErrorCodeOf(err), "An order missing a task should be rejected")
+	_, err = s.TaskRepo.ReorderTasks(s.Context, plan.ID, []string{order[0], order[0], order[1]})
+	s.Equal(models.ErrorCodeValidation, models.ErrorCodeOf(err), "An order listing a task twice should be rejected")
+	_, err = s.TaskRepo.ReorderTasks(s.Context, plan.ID, []string{order[0], order[1], "other-task"})
+	s.Equal(models.ErrorCodeValidation, models.ErrorCodeOf(err), "An order with a task of another plan should be rejected")
+
+	tasks, err = s.TaskRepo.ListByPlan(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.Equal(order, []string{tasks[0].ID, tasks[1].ID, tasks[2].ID}, "Rejected orders should change nothing")
+}
+
+// TestMoveTask tests moving a task to a position of another plan
+func (s *MemoryStoreTestSuite) TestMoveTask() {
+	source, err := s.PlanRepo.Create(s.Context, "memory-app", "Source plan", "")
+	s.Require().NoError(err)
+	target, err := s.PlanRepo.Create(s.Context, "memory-app", "Target plan", "")
+	s.Require().NoError(err)
+
+	sourceTasks, err := s.TaskRepo.CreateBulk(s.Context, source.ID, []storage.TaskCreateInput{
+		{Title: "Done", Status: models.TaskStatusCompleted}, {Title: "Moving", Status: models.TaskStatusInProgress},
+	})
+	s.Require().NoError(err)
+	targetTasks, err := s.TaskRepo.CreateBulk(s.Context, target.ID, []storage.TaskCreateInput{
+		{Title: "First"}, {Title: "Second"},
+	})
+	s.Require().NoError(err)
+
+	moving := sourceTasks[1]
+	moved, err := s.TaskRepo.MoveTask(s.Context, moving.ID, target.ID, 1)
+	s.Require().NoError(err)
+	s.Equal(target.ID, moved.PlanID)
+	s.Equal(1, moved.Order)
+
+	tasks, err := s.TaskRepo.ListByPlan(s.Context, target.ID)
+	s.Require().NoError(err)
+	s.Require().Len(tasks, 3)
+	s.Equal([]string{targetTasks[0].ID, moving.ID, targetTasks[1].ID}, []string{tasks[0].ID, tasks[1].ID, tasks[2].ID})
+	tasks, err = s.TaskRepo.ListByPlan(s.Context, source.ID)
+	s.Require().NoError(err)
+	s.Require().Len(tasks, 1)
+
+	// The source plan only has its completed task left, the target plan has a task in progress now
+	plan, err := s.PlanRepo.Get(s.Context, source.ID)
+	s.Require().NoError(err)
+	s.Equal(models.PlanStatusCompleted, plan.Status)
+	plan, err = s.PlanRepo.Get(s.Context, target.ID)
+	s.Require().NoError(err)
+	s.Equal(models.PlanStatusInProgress, plan.Status)
+
+	// Without a position the task goes to the end
+	moved, err = s.TaskRepo.MoveTask(s.Context, moving.ID, source.ID, -1)
+	s.Require().NoError(err)
+	s.Equal(1, moved.Order)
+
+	_, err = s.TaskRepo.MoveTask(s.Context, moving.ID, target.ID, 5)
+	s.Equal(models.ErrorCodeValidation, models.ErrorCodeOf(err), "A position past the end of the plan should be rejected")
+	_, err = s.TaskRepo.MoveTask(s.Context, moving.ID, "missing-plan", -1)
+	s.Error(err, "Moving to a missing plan should fail")
+}
+
+// TestPlanOrder tests that the plans of an application are listed in their order and can be reordered
+func (s *MemoryStoreTestSuite) TestPlanOrder() {
+	first, err := s.PlanRepo.Create(s.Context, "memory-app", "First", "")
+	s.Require().NoError(err)
+	second, err := s.PlanRepo.Create(s.Context, "memory-app", "Second", "")
+	s.Require().NoError(err)
+	third, err := s.PlanRepo.Create(s.Context, "memory-app", "Third", "")
+	s.Require().NoError(err)
+	other, err := s.PlanRepo.Create(s.Context, "other-app", "Other", "")
+	s.Require().NoError(err)
+	s.Equal(models.PlanPriorityMedium, first.Priority)
+	s.Equal([]int{0, 1, 2}, []int{first.Order, second.Order, third.Order})
+	s.Equal(0, other.Order, "Plans are ordered within their application")
+
+	moved, err := s.PlanRepo.ReorderPlan(s.Context, third.ID, 0)
+	s.Require().NoError(err)
+	s.Equal(0, moved.Order)
+
+	plans, err := s.PlanRepo.ListByApplication(s.Context, "memory-app")
+	s.Require().NoError(err)
+	s.Require().Len(plans, 3)
+	s.Equal([]string{third.ID, first.ID, second.ID}, []string{plans[0].ID, plans[1].ID, plans[2].ID})
+	s.Equal([]int{0, 1, 2}, []int{plans[0].Order, plans[1].Order, plans[2].Order})
+
+	// Positions past the end are clamped
+	moved, err = s.PlanRepo.ReorderPlan(s.Context, third.ID, 10)
+	s.Require().NoError(err)
+	s.Equal(2, moved.Order)
+
+	first.Priority = models.PlanPriorityHigh
+	s.Require().NoError(s.PlanRepo.Update(s.Context, first))
+	plan, err := s.PlanRepo.Get(s.Context, first.ID)
+	s.Require().NoError(err)
+	s.Equal(models.PlanPriorityHigh, plan.Priority)
+	s.Equal(0, plan.Order)
+
+	_, err = s.PlanRepo.ReorderPlan(s.Context, "missing-plan", 0)
+	s.Error(err, "Reordering a missing plan should fail")
+}
+
+// TestPlanMilestones tests that plan dates and milestones are stored and carried over to clones with remapped tasks
+func (s *MemoryStoreTestSuite) TestPlanMilestones() {
+	plan, err := s.PlanRepo.Create(s.Context, "memory-app", "Scheduled", "")
+	s.Require().NoError(err)
+	task, err := s.TaskRepo.Create(s.Context, plan.ID, "Ship beta", "", models.TaskPriorityHigh)
+	s.Require().NoError(err)
+
+	target := time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC)
+	plan.TargetDate = &target
+	plan.Milestones = []*models.Milestone{models.NewMilestone("beta", "Beta", target.AddDate(0, 0, -14), []string{task.ID})}
+	s.Require().NoError(s.PlanRepo.Update(s.Context, plan))
+
+	stored, err := s.PlanRepo.Get(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.Require().NotNil(stored.TargetDate)
+	s.True(stored.TargetDate.Equal(target))
+	s.Require().Len(stored.Milestones, 1)
+	s.Equal([]string{task.ID}, stored.Milestones[0].TaskIDs)
+
+	clone, err := s.PlanRepo.Clone(s.Context, plan.ID, storage.PlanCloneOptions{})
+	s.Require().NoError(err)
+	s.Require().Len(clone.Milestones, 1)
+	clonedTasks, err := s.TaskRepo.ListByPlan(s.Context, clone.ID)
+	s.Require().NoError(err)
+	s.Require().Len(clonedTasks, 1)
+	s.Equal([]string{clonedTasks[0].ID}, clone.Milestones[0].TaskIDs, "Milestones of a clone link the cloned tasks")
+	s.NotEqual("beta", clone.Milestones[0].ID)
+}
+
+// TestTaskCounts tests that the task counters of plans follow task mutations and drive the plan status
+func (s *MemoryStoreTestSuite) TestTaskCounts() {
+	plan, err := s.PlanRepo.Create(s.Context, "memory-app", "Counted", "")
+	s.Require().NoError(err)
+	s.Equal(&models.TaskCounts{}, plan.TaskCounts)
+	other, err := s.PlanRepo.Create(s.Context, "memory-app", "Other", "")
+	s.Require().NoError(err)
+
+	tasks, err := s.TaskRepo.CreateBulk(s.Context, plan.ID, []storage.TaskCreateInput{
+		{Title: "First"}, {Title: "Second", Status: models.TaskStatusCompleted}, {Title: "Third"},
+	})
+	s.Require().NoError(err)
+
+	tasks[0].Status = models.TaskStatusInProgress
+	s.Require().NoError(s.TaskRepo.Update(s.Context, tasks[0]))
+	_, err = s.TaskRepo.MoveTask(s.Context, tasks[2].ID, other.ID, -1)
+	s.Require().NoError(err)
+
+	stored, err := s.PlanRepo.Get(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.Equal(&models.TaskCounts{Total: 2, InProgress: 1, Completed: 1}, stored.TaskCounts)
+	s.Equal(models.PlanStatusInProgress, stored.Status)
+
+	stored, err = s.PlanRepo.Get(s.Context, other.ID)
+	s.Require().NoError(err)
+	s.Equal(&models.TaskCounts{Total: 1, Pending: 1}, stored.TaskCounts)
+	s.Equal(models.PlanStatusNew, stored.Status)
+
+	// Task changes update the status together with the counters
+	moved, err := s.TaskRepo.Get(s.Context, tasks[2].ID)
+	s.Require().NoError(err)
+	moved.Status = models.TaskStatusCompleted
+	s.Require().NoError(s.TaskRepo.Update(s.Context, moved))
+	stored, err = s.PlanRepo.Get(s.Context, other.ID)
+	s.Require().NoError(err)
+	s.Equal(models.PlanStatusCompleted, stored.Status)
+
+	s.Require().NoError(s.TaskRepo.Delete(s.Context, tasks[0].ID))
+	stored, err = s.PlanRepo.Get(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.Equal(&models.TaskCounts{Total: 1, Completed: 1}, stored.TaskCounts)
+	s.Equal(models.PlanStatusCompleted, stored.Status, "A plan whose remaining tasks are completed is completed")
+
+	// Restoring a plan snapshot counts the tasks it has now
+	plan.Status = models.PlanStatusNew
+	s.Require().NoError(s.PlanRepo.Restore(s.Context, plan))
+	stored, err = s.PlanRepo.Get(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.Equal(&models.TaskCounts{Total: 1, Completed: 1}, stored.TaskCounts)
+}
+
+// TestPlanNotesHistory tests appending to plan notes, their revisions and reverting to a revision
+func (s *MemoryStoreTestSuite) TestPlanNotesHistory() {
+	plan, err := s.PlanRepo.Create(s.Context, "memory-app", "Notes plan", "")
+	s.Require().NoError(err)
+
+	// Notes written before the history was kept become its first revision
+	s.PlanRepo.SetNotesHistoryLength(0)
+	s.Require().NoError(s.PlanRepo.UpdateNotes(s.Context, plan.ID, "Legacy"))
+	s.PlanRepo.SetNotesHistoryLength(storage.DefaultNotesHistoryLength)
+	s.Require().NoError(s.PlanRepo.UpdateNotes(s.Context, plan.ID, "First"))
+	notes, err := s.PlanRepo.AppendNotes(storage.WithActor(s.Context, "agent-2"), plan.ID, "Second")
+	s.Require().NoError(err)
+	s.Equal("First\n\nSecond", notes)
+
+	revisions, err := s.PlanRepo.NotesHistory(s.Context, plan.ID, 0)
+	s.Require().NoError(err)
+	s.Require().Len(revisions, 3)
+	s.Equal([]string{"First\n\nSecond", "First", "Legacy"},
+		[]string{revisions[0].Notes, revisions[1].Notes, revisions[2].Notes})
+	s.Equal("agent-2", revisions[0].Actor)
+
+	notes, err = s.PlanRepo.RevertNotes(s.Context, plan.ID, revisions[2].ID)
+	s.Require().NoError(err)
+	s.Equal("Legacy", notes)
+	stored, err := s.PlanRepo.GetNotes(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.Equal("Legacy", stored)
+	_, err = s.PlanRepo.RevertNotes(s.Context, plan.ID, "1-0")
+	s.Error(err, "Reverting to a missing revision should fail")
+
+	// Only the configured number of revisions is kept
+	s.PlanRepo.SetNotesHistoryLength(2)
+	s.Require().NoError(s.PlanRepo.UpdateNotes(s.Context, plan.ID, "Latest"))
+	revisions, err = s.PlanRepo.NotesHistory(s.Context, plan.ID, 0)
+	s.Require().NoError(err)
+	s.Require().Len(revisions, 2)
+	s.Equal("Latest", revisions[0].Notes)
+}
+
+// TestConcurrentNoteAppends tests that concurrent appends to plan notes are all kept
+func (s *MemoryStoreTestSuite) TestConcurrentNoteAppends() {
+	plan, err := s.PlanRepo.Create(s.Context, "memory-app", "Notes plan", "")
+	s.Require().NoError(err)
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.PlanRepo.AppendNotes(s.Context, plan.ID, fmt.Sprintf("note-%d", i))
+			s.NoError(err)
+		}()
+	}
+	wg.Wait()
+
+	notes, err := s.PlanRepo.GetNotes(s.Context, plan.ID)
+	s.Require().NoError(err)
+	for i := range 10 {
+		s.Contains(notes, fmt.Sprintf("note-%d", i))
+	}
+}
+
+// TestStalePlanUpdate tests that updating a stale copy of a plan keeps the status and notes stored since
+func (s *MemoryStoreTestSuite) TestStalePlanUpdate() {
+	plan, err := s.PlanRepo.Create(s.Context, "memory-app", "Stale plan", "")
+	s.Require().NoError(err)
+	stale, err := s.PlanRepo.Get(s.Context, plan.ID)
+	s.Require().NoError(err)
+
+	_, err = s.TaskRepo.Create(s.Context, plan.ID, "Task", "", models.TaskPriorityMedium)
+	s.Require().NoError(err)
+	_, err = s.PlanRepo.UpdateStatus(s.Context, plan.ID, models.PlanStatusCancelled)
+	s.Require().NoError(err)
+	_, err = s.PlanRepo.AppendNotes(s.Context, plan.ID, "Appended")
+	s.Require().NoError(err)
+
+	stale.Name = "Renamed"
+	s.Require().NoError(s.PlanRepo.Update(s.Context, stale))
+	s.Equal(models.PlanStatusCancelled, stale.Status, "The updated plan should carry the stored status")
+
+	stored, err := s.PlanRepo.Get(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.Equal("Renamed", stored.Name)
+	s.Equal(models.PlanStatusCancelled, stored.Status)
+	s.Equal("Appended", stored.Notes)
+	s.Equal(&models.TaskCounts{Total: 1, Pending: 1}, stored.TaskCounts)
+}
+
+// fakeSummarizer summarizes archived notes by their length
+type fakeSummarizer struct {
+	calls chan string
+}
+
+func (f *fakeSummarizer) Summarize(_ context.Context, summary, archived string) (string, error) {
+	f.calls <- archived
+	return fmt.Sprintf("%s[%d bytes]", summary, len(archived)), nil
+}
+
+// TestNotesCompaction tests that notes past the compact length move to the archive and get summarized
+func (s *MemoryStoreTestSuite) TestNotesCompaction() {
+	summarizer := &fakeSummarizer{calls: make(chan string, 100)}
+	compactor := storage.NewNotesCompactor(s.Client, 200, summarizer)
+	s.PlanRepo.SetNotesCompactor(compactor)
+	s.TaskRepo.SetNotesCompactor(compactor)
+
+	plan, err := s.PlanRepo.Create(s.Context, "memory-app", "Notes plan", "")
+	s.Require().NoError(err)
+	for i := range 10 {
+		_, err := s.PlanRepo.AppendNotes(s.Context, plan.ID, fmt.Sprintf("Paragraph %d %s", i, strings.Repeat("x", 30)))
+		s.Require().NoError(err)
+	}
+
+	notes, err := s.PlanRepo.GetNotes(s.Context, plan.ID)
+	s.Require().NoError(err)
+	s.LessOrEqual(len(notes), 200)
+	s.True(strings.HasPrefix(notes, "_Older notes were archived"))
+	s.Contains(notes, "Paragraph 9")
+	s.NotContains(notes, "Paragraph 0")
+
+	archive, err := compactor.Archived(s.Context, models.EntityTypePlan, plan.ID)
+	s.Require().NoError(err)
+	s.True(strings.HasPrefix(archive.Content, "Paragraph 0"))
+	s.NotContains(archive.Content, "_Older notes")
+	s.NotNil(archive.ArchivedAt)
+
+	// Every paragraph is either kept or archived, in order
+	for i := range 10 {
+		paragraph := fmt.Sprintf("Paragraph %d", i)
+		s.True(strings.Contains(notes, paragraph) != strings.Contains(archive.Content, paragraph), paragraph)
+	}
+
+	<-summarizer.calls
+	s.Eventually(func() bool {
+		archive, err := compactor.Archived(s.Context, models.EntityTypePlan, plan.ID)
+		return err == nil && archive.Summary != "" && archive.SummarizedAt != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Task notes are compacted the same way, and the archive is deleted with the task
+	task, err := s.TaskRepo.Create(s.Context, plan.ID, "Task", "", models.TaskPriorityLow)
+	s.Require().NoError(err)
+	s.Require().NoError(s.TaskRepo.UpdateNotes(s.Context, task.ID, strings.Repeat("y", 500)))
+	taskNotes, err := s.TaskRepo.GetNotes(s.Context, task.ID)
+	s.Require().NoError(err)
+	s.LessOrEqual(len(taskNotes), 200)
+
+	s.Require().NoError(s.TaskRepo.Delete(s.Context, task.ID))
+	taskArchive, err := compactor.Archived(s.Context, models.EntityTypeTask, task.ID)
+	s.Require().NoError(err)
+	s.Empty(taskArchive.Content)
+}
+
+// TestApplicationScope tests that scoped repositories hide the plans and tasks of other applications
+func (s *MemoryStoreTestSuite) TestApplicationScope() {
+	planRepo := storage.NewScopedPlanRepository(s.PlanRepo)
+	taskRepo := storage.NewScopedTaskRepository(s.TaskRepo, planRepo)
+
+	own, err := planRepo.Create(s.Context, "app-a", "Own plan", "")
+	s.Require().NoError(err)
+	other, err := planRepo.Create(s.Context, "app-b", "Other plan", "")
+	s.Require().NoError(err)
+	ownTask, err := taskRepo.Create(s.Context, own.ID, "Own task", "", models.TaskPriorityLow)
+	s.Require().NoError(err)
+	otherTask, err := taskRepo.Create(s.Context, other.ID, "Other task", "", models.TaskPriorityLow)
+	s.Require().NoError(err)
+
+	ctx := storage.WithApplicationScope(s.Context, "app-a")
+
+	plans, err := planRepo.List(ctx)
+	s.Require().NoError(err)
+	s.Require().Len(plans, 1)
+	s.Equal(own.ID, plans[0].ID)
+
+	plans, err = planRepo.ListByApplication(ctx, "app-b")
+	s.Require().NoError(err)
+	s.Empty(plans)
+
+	_, err = planRepo.Get(ctx, other.ID)
+	s.ErrorContains(err, "plan not found")
+	_, err = planRepo.Create(ctx, "app-b", "Sneaky plan", "")
+	s.ErrorIs(err, storage.ErrOutOfScope)
+	other.ApplicationID = "app-a"
+	s.Error(planRepo.Update(ctx, other))
+	own.ApplicationID = "app-b"
+	s.ErrorIs(planRepo.Update(ctx, own), storage.ErrOutOfScope)
+
+	// Plans of other applications cannot be locked or unlocked either
+	_, err = planRepo.SetLock(ctx, other.ID, &models.PlanLock{LockedBy: "session:a", LockedAt: time.Now()})
+	s.Equal(models.ErrorCodeNotFound, models.ErrorCodeOf(err))
+	_, err = planRepo.SetLock(ctx, other.ID, nil)
+	s.Equal(models.ErrorCodeNotFound, models.ErrorCodeOf(err))
+	_, _, err = planRepo.LockPlan(ctx, other.ID)
+	s.Equal(models.ErrorCodeNotFound, models.ErrorCodeOf(err))
+	stored, err := planRepo.Get(s.Context, other.ID)
+	s.Require().NoError(err)
+	s.Nil(stored.Lock)
+
+	_, err = taskRepo.Get(ctx, otherTask.ID)
+	s.ErrorContains(err, "task not found")
+	_, err = taskRepo.Create(ctx, other.ID, "Sneaky task", "", models.TaskPriorityLow)
+	s.ErrorContains(err, "plan not found")
+	_, err = taskRepo.MoveTask(ctx, ownTask.ID, other.ID, -1)
+	s.ErrorContains(err, "plan not found")
+
+	tasks, err := taskRepo.ListByStatus(ctx, models.TaskStatusPending)
+	s.Require().NoError(err)
+	s.Require().Len(tasks, 1)
+	s.Equal(ownTask.ID, tasks[0].ID)
+
+	// Without a scope, everything is visible
+	plans, err = planRepo.List(s.Context)
+	s.Require().NoError(err)
+	s.Len(plans, 2)
+}
+
+// TestApplications tests registering applications and requiring plans to belong to one
+func (s *MemoryStoreTestSuite) TestApplications() {
+	appRepo := storage.NewApplicationRepository(s.Client)
+
+	app, err := appRepo.Create(s.Context, "shop", "", "Web shop", map[string]string{"repo": "github.com/acme/shop"})
+	s.Require().NoError(err)
+	s.Equal("shop", app.Name, "The name defaults to the ID")
+	_, err = appRepo.Create(s.Context, "billing", "Billing", "", nil)
+	s.Require().NoError(err)
+	_, err = appRepo.Create(s.Context, "shop", "Shop", "", nil)
+	s.ErrorContains(err, "already exists")
+	_, err = appRepo.Create(s.Context, "bad id", "", "", nil)
+	s.Error(err, "Application IDs cannot contain whitespace")
+
+	stored, err := appRepo.Get(s.Context, "shop")
+	s.Require().NoError(err)
+	s.Equal("Web shop", stored.Description)
+	s.Equal(map[string]string{"repo": "github.com/acme/shop"}, stored.Metadata)
+	_, err = appRepo.Get(s.Context, "missing")
+	s.ErrorContains(err, "application not found")
+
+	applications, err := appRepo.List(s.Context)
+	s.Require().NoError(err)
+	s.Require().Len(applications, 2)
+	s.Equal([]string{"billing", "shop"}, []string{applications[0].ID, applications[1].ID})
+
+	// Plans of unknown applications are accepted until registration is required
+	plan, err := s.PlanRepo.Create(s.Context, "legacy", "Legacy plan", "")
+	s.Require().NoError(err)
+	s.PlanRepo.RequireKnownApplications(true)
+	_, err = s.PlanRepo.Create(s.Context, "unknown", "Plan", "")
+	s.ErrorContains(err, "application not found")
+	_, err = s.PlanRepo.Clone(s.Context, plan.ID, storage.PlanCloneOptions{})
+	s.ErrorContains(err, "application not found")
+	_, err = s.PlanRepo.Create(s.Context, "shop", "Plan", "")
+	s.NoError(err)
+
+	// The applications plans refer to are listed whether they are registered or not
+	inUse, err := s.PlanRepo.ListApplications(s.Context)
+	s.Require().NoError(err)
+	s.Equal([]*models.ApplicationPlans{
+		{ApplicationID: "legacy", PlanCount: 1}, {ApplicationID: "shop", PlanCount: 1},
+	}, inUse)
+
+	// A scoped request only sees its own application
+	scoped := storage.NewScopedApplicationRepository(appRepo)
+	ctx := storage.WithApplicationScope(s.Context, "shop")
+	applications, err = scoped.List(ctx)
+	s.Require().NoError(err)
+	s.Require().Len(applications, 1)
+	s.Equal("shop", applications[0].ID)
+	_, err = scoped.Get(ctx, "billing")
+	s.ErrorContains(err, "application not found")
+	_, err = scoped.Create(ctx, "payments", "", "", nil)
+	s.ErrorIs(err, storage.ErrOutOfScope)
+}
+
+// taskIDs returns the IDs of tasks in order
+func taskIDs(tasks []*models.Task) []string {
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+// TestTasksByApplication tests listing the tasks across the plans of an application
+func (s *MemoryStoreTestSuite) TestTasksByApplication() {
+	first, err := s.PlanRepo.Create(s.Context, "product", "First", "")
+	s.Require().NoError(err)
+	second, err := s.PlanRepo.Create(s.Context, "product", "Second", "")
+	s.Require().NoError(err)
+	other, err := s.PlanRepo.Create(s.Context, "other", "Other", "")
+	s.Require().NoError(err)
+
+	a, err := s.TaskRepo.Create(s.Context, first.ID, "A", "", models.TaskPriorityLow)
+	s.Require().NoError(err)
+	b, err := s.TaskRepo.Create(s.Context, second.ID, "B", "", models.TaskPriorityLow)
+	s.Require().NoError(err)
+	c, err := s.TaskRepo.Create(s.Context, second.ID, "C", "", models.TaskPriorityLow)
+	s.Require().NoError(err)
+	_, err = s.TaskRepo.Create(s.Context, other.ID, "D", "", models.TaskPriorityLow)
+	s.Require().NoError(err)
+
+	c.Status = models.TaskStatusInProgress
+	s.Require().NoError(s.TaskRepo.Update(s.Context, c))
+
+	tasks, err := s.TaskRepo.ListByApplication(s.Context, "product")
+	s.Require().NoError(err)
+	s.Equal([]string{a.ID, b.ID, c.ID}, taskIDs(tasks), "Tasks are listed plan by plan in order")
+
+	tasks, err = s.TaskRepo.ListByApplicationAndStatus(s.Context, "product", models.TaskStatusInProgress)
+	s.Require().NoError(err)
+	s.Equal([]string{c.ID}, taskIDs(tasks))
+
+	tasks, err = s.TaskRepo.ListByApplicationAndStatus(s.Context, "missing", models.TaskStatusPending)
+	s.Require().NoError(err)
+	s.Empty(tasks)
+
+	// A scoped request sees no tasks of other applications
+	scoped := storage.NewScopedTaskRepository(s.TaskRepo, storage.NewScopedPlanRepository(s.PlanRepo))
+	tasks, err = scoped.ListByApplication(storage.WithApplicationScope(s.Context, "other"), "product")
+	s.Require().NoError(err)
+	s.Empty(tasks)
+}
+
+// TestMemoryStoreSuite runs the in-memory store test suite
+func TestMemoryStoreSuite(t *testing.T) {
+	suite.Run(t, new(MemoryStoreTestSuite))
+}
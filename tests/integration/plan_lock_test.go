@@ -0,0 +1,47 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+)
+
+// TestPlanLockRenewedWhileHeld tests that a plan lock held longer than its TTL keeps other changes waiting
+func TestPlanLockRenewedWhileHeld(t *testing.T) {
+	repos := newTestRepos(t)
+	repos.client.SetPlanLockTTL(60 * time.Millisecond)
+	planRepo := repos.planRepo
+	taskRepo := repos.taskRepo
+
+	ctx := context.Background()
+	plan, err := planRepo.Create(ctx, "lock-app", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create the plan: %v", err)
+	}
+
+	lockedCtx, unlock, err := planRepo.LockPlan(ctx, plan.ID)
+	if err != nil {
+		t.Fatalf("failed to lock the plan: %v", err)
+	}
+	const held = 300 * time.Millisecond
+	start := time.Now()
+	waited := make(chan time.Duration, 1)
+	go func() {
+		if _, err := taskRepo.Create(ctx, plan.ID, "Waiting", "", models.TaskPriorityMedium); err != nil {
+			t.Errorf("failed to create a task after the lock was released: %v", err)
+		}
+		waited <- time.Since(start)
+	}()
+
+	time.Sleep(held)
+	if _, err := taskRepo.Create(lockedCtx, plan.ID, "Holding", "", models.TaskPriorityMedium); err != nil {
+		t.Fatalf("failed to create a task under a lock held past its TTL: %v", err)
+	}
+	unlock()
+
+	if elapsed := <-waited; elapsed < held {
+		t.Errorf("a change waited %v for a lock held %v, the lock expired while held", elapsed, held)
+	}
+}
@@ -3,9 +3,11 @@ package integration
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
 	"github.com/jbrinkman/valkey-ai-tasks/tests/utils"
 	"github.com/stretchr/testify/suite"
 )
@@ -77,12 +79,15 @@ func (s *PlanRepositorySuite) TestUpdatePlan() {
 	// Update plan properties
 	plan.Name = "Updated Plan Name"
 	plan.Description = "Updated plan description"
-	plan.Status = models.PlanStatusInProgress
 
 	// Perform the update
 	err = planRepo.Update(s.Context, plan)
 	s.NoError(err, "Failed to update plan")
 
+	// The status is set on its own
+	_, err = planRepo.UpdateStatus(s.Context, plan.ID, models.PlanStatusInProgress)
+	s.NoError(err, "Failed to update plan status")
+
 	// Retrieve the plan again to verify updates
 	updatedPlan, err := planRepo.Get(s.Context, plan.ID)
 	s.NoError(err, "Failed to get updated plan")
@@ -110,6 +115,55 @@ func (s *PlanRepositorySuite) TestDeletePlan() {
 	s.Contains(err.Error(), "plan not found", "Error should indicate plan not found")
 }
 
+// TestDeletePlanWithTasks tests that deleting a plan deletes its tasks
+func (s *PlanRepositorySuite) TestDeletePlanWithTasks() {
+	planRepo := s.GetPlanRepository()
+	taskRepo := s.GetTaskRepository()
+
+	appID := "test-app-" + uuid.New().String()
+	plan, err := planRepo.Create(s.Context, appID, "Test Plan", "Test plan description")
+	s.Require().NoError(err, "Failed to create plan")
+	task, err := taskRepo.Create(s.Context, plan.ID, "Test Task", "Test task description", models.TaskPriorityMedium)
+	s.Require().NoError(err, "Failed to create task")
+
+	err = planRepo.Delete(s.Context, plan.ID)
+	s.Require().NoError(err, "Failed to delete plan with tasks")
+
+	_, err = taskRepo.Get(s.Context, task.ID)
+	s.Error(err, "Getting a task of a deleted plan should return error")
+}
+
+// TestDeletePlanCleansTaskKeys tests that deleting a plan removes the tags, checklists and leases of its tasks
+func (s *PlanRepositorySuite) TestDeletePlanCleansTaskKeys() {
+	planRepo := s.GetPlanRepository()
+	taskRepo := s.GetTaskRepository()
+	raw := s.Containers[len(s.Containers)-1].Client
+
+	plan, err := planRepo.Create(s.Context, "test-app-"+uuid.New().String(), "Test Plan", "")
+	s.Require().NoError(err)
+	task, err := taskRepo.Create(s.Context, plan.ID, "Test Task", "", models.TaskPriorityMedium)
+	s.Require().NoError(err)
+	tag := "cleanup-" + uuid.New().String()
+	_, err = taskRepo.AddTags(s.Context, task.ID, []string{tag})
+	s.Require().NoError(err)
+	_, err = taskRepo.AddChecklistItem(s.Context, task.ID, "Check")
+	s.Require().NoError(err)
+	_, err = taskRepo.ClaimTask(s.Context, task.ID, "worker-1", time.Minute)
+	s.Require().NoError(err)
+
+	s.Require().NoError(planRepo.Delete(s.Context, plan.ID))
+
+	exists, err := raw.Exists(s.Context, []string{
+		storage.GetTaskTagsKey(task.ID), storage.GetTagTasksKey(tag),
+		storage.GetTaskChecklistKey(task.ID), storage.GetTaskLeaseKey(task.ID),
+	})
+	s.Require().NoError(err)
+	s.Zero(exists, "The keys of the deleted tasks should be removed")
+	leased, err := raw.ZScore(s.Context, "task_leases", task.ID)
+	s.Require().NoError(err)
+	s.True(leased.IsNil(), "The lease of a deleted task should not be tracked")
+}
+
 // TestDeleteNonExistentPlan tests deleting a non-existent plan
 func (s *PlanRepositorySuite) TestDeleteNonExistentPlan() {
 	planRepo := s.GetPlanRepository()
@@ -168,14 +222,12 @@ func (s *PlanRepositorySuite) TestListPlansByStatus() {
 
 	planNew, err := planRepo.Create(s.Context, appID, "New Plan", "A new plan")
 	s.NoError(err, "Failed to create new plan")
-	planNew.Status = models.PlanStatusNew
-	err = planRepo.Update(s.Context, planNew)
+	_, err = planRepo.UpdateStatus(s.Context, planNew.ID, models.PlanStatusNew)
 	s.NoError(err, "Failed to update new plan status")
 
 	planInProgress, err := planRepo.Create(s.Context, appID, "In Progress Plan", "An in-progress plan")
 	s.NoError(err, "Failed to create in-progress plan")
-	planInProgress.Status = models.PlanStatusInProgress
-	err = planRepo.Update(s.Context, planInProgress)
+	_, err = planRepo.UpdateStatus(s.Context, planInProgress.ID, models.PlanStatusInProgress)
 	s.NoError(err, "Failed to update in-progress plan status")
 
 	// List new plans
@@ -349,12 +401,11 @@ func (s *PlanRepositorySuite) TestUpdateNonExistentPlan() {
 		Description: "This plan doesn't exist",
 	}
 	err := planRepo.Update(s.Context, nonExistentPlan)
-	s.NoError(err, "Update should not fail for non-existent plan as it just creates it")
+	s.True(models.IsNotFound(err), "Updating a non-existent plan should fail with not found, got %v", err)
 
-	// Verify the plan was actually created
-	retrievedPlan, err := planRepo.Get(s.Context, nonExistentPlan.ID)
-	s.NoError(err, "Should be able to retrieve the newly created plan")
-	s.Equal(nonExistentPlan.Name, retrievedPlan.Name, "Plan name should match")
+	// Verify the plan was not created
+	_, err = planRepo.Get(s.Context, nonExistentPlan.ID)
+	s.Error(err, "Updating a non-existent plan should not create it")
 }
 
 // TestUpdatePlanNotes tests updating notes for a plan
@@ -420,6 +471,83 @@ func (s *PlanRepositorySuite) TestUpdateNonExistentPlanNotes() {
 	s.Contains(err.Error(), "not found", "Error should indicate plan not found")
 }
 
+// TestPlanMetadata tests setting and deleting plan metadata
+func (s *PlanRepositorySuite) TestPlanMetadata() {
+	planRepo := s.GetPlanRepository()
+
+	appID := "test-app-" + uuid.New().String()
+	plan, err := planRepo.Create(s.Context, appID, "Test Plan", "Test plan description")
+	s.NoError(err, "Failed to create plan")
+
+	updated, err := planRepo.SetMetadata(s.Context, plan.ID, map[string]string{
+		"repo_url": "https://github.com/example/repo",
+		"ticket":   "PROJ-123",
+	})
+	s.NoError(err, "Failed to set plan metadata")
+	s.Equal("PROJ-123", updated.Metadata["ticket"], "Ticket metadata should be set")
+
+	// Metadata survives a regular update
+	updated.Description = "Updated description"
+	err = planRepo.Update(s.Context, updated)
+	s.NoError(err, "Failed to update plan")
+
+	updated, err = planRepo.DeleteMetadata(s.Context, plan.ID, []string{"ticket"})
+	s.NoError(err, "Failed to delete plan metadata")
+	s.Equal(map[string]string{"repo_url": "https://github.com/example/repo"}, updated.Metadata)
+
+	// Invalid keys are rejected
+	_, err = planRepo.SetMetadata(s.Context, plan.ID, map[string]string{"bad key": "value"})
+	s.Error(err, "Expected error for a key with whitespace")
+}
+
+// TestClonePlan tests deep-copying a plan and its tasks
+func (s *PlanRepositorySuite) TestClonePlan() {
+	planRepo := s.GetPlanRepository()
+	taskRepo := s.GetTaskRepository()
+
+	appID := "test-app-" + uuid.New().String()
+	source, err := planRepo.Create(s.Context, appID, "Source Plan", "Source description")
+	s.NoError(err, "Failed to create plan")
+
+	task1, err := taskRepo.Create(s.Context, source.ID, "Task 1", "Description 1", models.TaskPriorityHigh)
+	s.NoError(err, "Failed to create task 1")
+	task2, err := taskRepo.Create(s.Context, source.ID, "Task 2", "Description 2", models.TaskPriorityLow)
+	s.NoError(err, "Failed to create task 2")
+
+	task1.Status = models.TaskStatusCompleted
+	err = taskRepo.Update(s.Context, task1)
+	s.NoError(err, "Failed to complete task 1")
+	task2.DependsOn = []string{task1.ID}
+	err = taskRepo.Update(s.Context, task2)
+	s.NoError(err, "Failed to set dependency")
+	err = taskRepo.UpdateNotes(s.Context, task2.ID, "Some notes")
+	s.NoError(err, "Failed to set notes")
+
+	otherAppID := "test-app-" + uuid.New().String()
+	clone, err := planRepo.Clone(s.Context, source.ID, storage.PlanCloneOptions{
+		ApplicationID: otherAppID,
+		ResetStatus:   true,
+		ClearNotes:    true,
+	})
+	s.NoError(err, "Failed to clone plan")
+	s.NotEqual(source.ID, clone.ID, "Clone should have a new ID")
+	s.Equal(otherAppID, clone.ApplicationID, "Clone should belong to the target application")
+	s.Equal("Source Plan (copy)", clone.Name, "Clone should get a default name")
+	s.Equal(models.PlanStatusNew, clone.Status, "Clone with reset tasks should be new")
+
+	tasks, err := taskRepo.ListByPlan(s.Context, clone.ID)
+	s.NoError(err, "Failed to list cloned tasks")
+	s.Len(tasks, 2, "Clone should have both tasks")
+	s.Equal("Task 1", tasks[0].Title)
+	s.Equal(models.TaskStatusPending, tasks[0].Status, "Cloned task status should be reset")
+	s.Equal("Task 2", tasks[1].Title)
+	s.Empty(tasks[1].Notes, "Cloned task notes should be cleared")
+	s.Equal([]string{tasks[0].ID}, tasks[1].DependsOn, "Dependencies should point at the cloned tasks")
+
+	_, err = planRepo.Clone(s.Context, "non-existent-id", storage.PlanCloneOptions{})
+	s.Error(err, "Expected error when cloning a non-existent plan")
+}
+
 // TestPlanRepositorySuite runs the plan repository test suite
 func TestPlanRepositorySuite(t *testing.T) {
 	if testing.Short() {
@@ -332,6 +332,36 @@ func (s *PlanResourceTestSuite) TestAppPlansResource() {
 	assert.True(s.T(), found, "Expected to find the created plan")
 }
 
+// TestApplicationSummaryResource tests the application summary resource
+func (s *PlanResourceTestSuite) TestApplicationSummaryResource() {
+	// Create a test plan with tasks
+	plan := s.createTestPlan()
+
+	// Create an MCP client
+	url := fmt.Sprintf("http://localhost:%d", s.port)
+	mcpClient, err := createMCPClient(url)
+	require.NoError(s.T(), err, "Failed to create MCP client")
+
+	uri := fmt.Sprintf("ai-tasks://applications/%s/summary", plan.ApplicationID)
+	result, err := readPlanResource(context.Background(), mcpClient, uri)
+	require.NoError(s.T(), err, "Failed to read resource")
+	require.NotEmpty(s.T(), result.Contents, "Expected non-empty contents")
+
+	textContent, ok := result.Contents[0].(mcp.TextResourceContents)
+	require.True(s.T(), ok, "Expected TextResourceContents")
+
+	var summary models.ApplicationSummary
+	err = json.Unmarshal([]byte(textContent.Text), &summary)
+	require.NoError(s.T(), err, "Failed to parse resource content")
+
+	assert.Equal(s.T(), plan.ApplicationID, summary.ApplicationID)
+	assert.Equal(s.T(), 1, summary.TotalPlans, "Expected one plan")
+	assert.Equal(s.T(), 2, summary.TotalOpenTasks, "Expected two open tasks")
+	require.Len(s.T(), summary.Plans, 1, "Expected progress for one plan")
+	assert.Equal(s.T(), plan.ID, summary.Plans[0].PlanID)
+	assert.NotEmpty(s.T(), summary.RecentlyUpdated, "Expected recently updated items")
+}
+
 // TestInvalidResourceURI tests handling of invalid resource URIs
 func (s *PlanResourceTestSuite) TestInvalidResourceURI() {
 	// Create an HTTP client
@@ -448,6 +478,83 @@ func (s *PlanResourceTestSuite) TestPlanNotFound() {
 	}
 }
 
+// TestNotesResources tests reading the notes of a plan and a task as markdown resources
+func (s *PlanResourceTestSuite) TestNotesResources() {
+	plan := s.createTestPlan()
+	require.NoError(s.T(), s.GetPlanRepository().UpdateNotes(s.Context, plan.ID, "# Plan notes"))
+	tasks, err := s.GetTaskRepository().ListByPlan(s.Context, plan.ID)
+	require.NoError(s.T(), err)
+	longNotes := strings.Repeat("Long task notes. ", 500)
+	require.NoError(s.T(), s.GetTaskRepository().UpdateNotes(s.Context, tasks[0].ID, longNotes))
+
+	url := fmt.Sprintf("http://localhost:%d", s.port)
+	mcpClient, err := createMCPClient(url)
+	require.NoError(s.T(), err, "Failed to create MCP client")
+
+	for uri, expected := range map[string]string{
+		fmt.Sprintf("ai-tasks://plans/%s/notes", plan.ID):     "# Plan notes",
+		fmt.Sprintf("ai-tasks://tasks/%s/notes", tasks[0].ID): longNotes,
+	} {
+		result, err := readPlanResource(context.Background(), mcpClient, uri)
+		require.NoError(s.T(), err, "Failed to read %s", uri)
+		require.Len(s.T(), result.Contents, 1)
+		textContent, ok := result.Contents[0].(mcp.TextResourceContents)
+		require.True(s.T(), ok, "Expected TextResourceContents")
+		assert.Equal(s.T(), "text/markdown", textContent.MIMEType)
+		assert.Equal(s.T(), expected, textContent.Text)
+	}
+
+	// The full plan resource links to the long task notes instead of including them
+	result, err := readPlanResource(context.Background(), mcpClient, fmt.Sprintf("ai-tasks://plans/%s/full", plan.ID))
+	require.NoError(s.T(), err)
+	textContent, ok := result.Contents[0].(mcp.TextResourceContents)
+	require.True(s.T(), ok, "Expected TextResourceContents")
+	var planResource models.PlanResource
+	require.NoError(s.T(), json.Unmarshal([]byte(textContent.Text), &planResource))
+	assert.Equal(s.T(), "# Plan notes", planResource.Plan.Notes)
+	assert.Empty(s.T(), planResource.Tasks[0].Notes)
+	assert.Equal(s.T(), fmt.Sprintf("ai-tasks://tasks/%s/notes", tasks[0].ID), planResource.NotesURIs[tasks[0].ID])
+
+	_, err = readPlanResource(context.Background(), mcpClient, "ai-tasks://tasks/non-existent-id/notes")
+	assert.Error(s.T(), err, "Expected error for non-existent task")
+}
+
+// TestTaskResources tests reading a single task and the tasks of a plan as resources
+func (s *PlanResourceTestSuite) TestTaskResources() {
+	plan := s.createTestPlan()
+	tasks, err := s.GetTaskRepository().ListByPlan(s.Context, plan.ID)
+	require.NoError(s.T(), err)
+
+	url := fmt.Sprintf("http://localhost:%d", s.port)
+	mcpClient, err := createMCPClient(url)
+	require.NoError(s.T(), err, "Failed to create MCP client")
+
+	result, err := readPlanResource(context.Background(), mcpClient, fmt.Sprintf("ai-tasks://tasks/%s", tasks[1].ID))
+	require.NoError(s.T(), err, "Failed to read task resource")
+	textContent, ok := result.Contents[0].(mcp.TextResourceContents)
+	require.True(s.T(), ok, "Expected TextResourceContents")
+	var task models.Task
+	require.NoError(s.T(), json.Unmarshal([]byte(textContent.Text), &task))
+	assert.Equal(s.T(), tasks[1].ID, task.ID)
+	assert.Equal(s.T(), "Task 2", task.Title)
+	assert.Equal(s.T(), 1, task.Order)
+
+	result, err = readPlanResource(context.Background(), mcpClient, fmt.Sprintf("ai-tasks://plans/%s/tasks", plan.ID))
+	require.NoError(s.T(), err, "Failed to read plan tasks resource")
+	textContent, ok = result.Contents[0].(mcp.TextResourceContents)
+	require.True(s.T(), ok, "Expected TextResourceContents")
+	var planTasks []models.Task
+	require.NoError(s.T(), json.Unmarshal([]byte(textContent.Text), &planTasks))
+	require.Len(s.T(), planTasks, 2)
+	assert.Equal(s.T(), "Task 1", planTasks[0].Title)
+	assert.Equal(s.T(), "Task 2", planTasks[1].Title)
+
+	_, err = readPlanResource(context.Background(), mcpClient, "ai-tasks://tasks/non-existent-id")
+	assert.ErrorContains(s.T(), err, "task not found")
+	_, err = readPlanResource(context.Background(), mcpClient, "ai-tasks://plans/non-existent-id/tasks")
+	assert.ErrorContains(s.T(), err, "plan not found")
+}
+
 // TestPlanResourceSuite runs the Plan resource test suite
 func TestPlanResourceSuite(t *testing.T) {
 	suite.Run(t, new(PlanResourceTestSuite))
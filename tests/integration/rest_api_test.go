@@ -0,0 +1,164 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/api"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/tests/utils"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// RESTAPITestSuite is a test suite for the REST API
+type RESTAPITestSuite struct {
+	utils.RepositoryTestSuite
+	server *httptest.Server
+}
+
+// SetupTest sets up each test
+func (s *RESTAPITestSuite) SetupTest() {
+	s.RepositoryTestSuite.SetupTest()
+	s.server = httptest.NewServer(api.NewHandler(s.GetPlanRepository(), s.GetTaskRepository()))
+}
+
+// TearDownTest cleans up after each test
+func (s *RESTAPITestSuite) TearDownTest() {
+	s.server.Close()
+	s.RepositoryTestSuite.TearDownTest()
+}
+
+// do sends a JSON request to the API and decodes the response into out if it is not nil
+func (s *RESTAPITestSuite) do(method, path string, body any, out any) int {
+	var reader bytes.Buffer
+	if body != nil {
+		require.NoError(s.T(), json.NewEncoder(&reader).Encode(body))
+	}
+
+	req, err := http.NewRequestWithContext(s.Context, method, s.server.URL+api.BasePath+path, &reader)
+	require.NoError(s.T(), err)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	if out != nil {
+		require.NoError(s.T(), json.NewDecoder(resp.Body).Decode(out))
+	}
+	return resp.StatusCode
+}
+
+// TestPlanAndTaskLifecycle tests creating, reading, updating and deleting plans and tasks over HTTP
+func (s *RESTAPITestSuite) TestPlanAndTaskLifecycle() {
+	appID := "rest-app-" + uuid.New().String()
+
+	var plan models.Plan
+	status := s.do(http.MethodPost, "/plans", map[string]string{
+		"application_id": appID,
+		"name":           "REST Plan",
+		"notes":          "# Plan notes",
+	}, &plan)
+	s.Require().Equal(http.StatusCreated, status)
+	s.NotEmpty(plan.ID)
+	s.Equal("no description provided", plan.Description)
+	s.Contains(plan.Notes, "# Plan notes")
+
+	var plans []*models.Plan
+	s.Equal(http.StatusOK, s.do(http.MethodGet, "/plans?application_id="+appID, nil, &plans))
+	s.Require().Len(plans, 1)
+	s.Equal(plan.ID, plans[0].ID)
+
+	s.Equal(http.StatusOK, s.do(http.MethodGet, "/plans?application_id="+appID+"&status=completed", nil, &plans))
+	s.Empty(plans)
+
+	var updatedPlan models.Plan
+	s.Equal(http.StatusOK, s.do(http.MethodPatch, "/plans/"+plan.ID, map[string]string{"name": "Renamed"}, &updatedPlan))
+	s.Equal("Renamed", updatedPlan.Name)
+	s.Contains(updatedPlan.Notes, "# Plan notes", "Omitted fields should be unchanged")
+
+	var task models.Task
+	status = s.do(http.MethodPost, "/plans/"+plan.ID+"/tasks", map[string]string{
+		"title":    "First task",
+		"priority": "high",
+		"status":   "in_progress",
+	}, &task)
+	s.Require().Equal(http.StatusCreated, status)
+	s.Equal(plan.ID, task.PlanID)
+	s.Equal(models.TaskPriorityHigh, task.Priority)
+	s.Equal(models.TaskStatusInProgress, task.Status)
+
+	var tasks []*models.Task
+	s.Equal(http.StatusOK, s.do(http.MethodGet, "/plans/"+plan.ID+"/tasks?status=in_progress", nil, &tasks))
+	s.Require().Len(tasks, 1)
+	s.Equal(task.ID, tasks[0].ID)
+
+	var updatedTask models.Task
+	s.Equal(http.StatusOK, s.do(http.MethodPatch, "/tasks/"+task.ID, map[string]string{"status": "completed"}, &updatedTask))
+	s.Equal(models.TaskStatusCompleted, updatedTask.Status)
+
+	var fetched models.Task
+	s.Equal(http.StatusOK, s.do(http.MethodGet, "/tasks/"+task.ID, nil, &fetched))
+	s.Equal(models.TaskStatusCompleted, fetched.Status)
+	s.Equal("First task", fetched.Title)
+
+	s.Equal(http.StatusNoContent, s.do(http.MethodDelete, "/tasks/"+task.ID, nil, nil))
+
+	var apiErr api.ErrorResponse
+	s.Equal(http.StatusNotFound, s.do(http.MethodGet, "/tasks/"+task.ID, nil, &apiErr))
+	s.Contains(apiErr.Error, "not found")
+
+	s.Equal(http.StatusNoContent, s.do(http.MethodDelete, "/plans/"+plan.ID, nil, nil))
+	s.Equal(http.StatusNotFound, s.do(http.MethodGet, "/plans/"+plan.ID, nil, &apiErr))
+}
+
+// TestExportImportPlan tests that an exported plan can be restored with its original IDs
+func (s *RESTAPITestSuite) TestExportImportPlan() {
+	plan, err := s.GetPlanRepository().Create(s.Context, "rest-app-"+uuid.New().String(), "Backup Plan", "Description")
+	s.Require().NoError(err)
+	task, err := s.GetTaskRepository().Create(s.Context, plan.ID, "Backed up task", "Description", models.TaskPriorityHigh)
+	s.Require().NoError(err)
+
+	var backup models.PlanResource
+	s.Require().Equal(http.StatusOK, s.do(http.MethodGet, "/plans/"+plan.ID+"/export", nil, &backup))
+	s.Equal(plan.ID, backup.Plan.ID)
+	s.Require().Len(backup.Tasks, 1)
+
+	// Change the task after the export, then restore the backup
+	task.Title = "Changed after export"
+	s.Require().NoError(s.GetTaskRepository().Update(s.Context, task))
+
+	var restored models.PlanResource
+	s.Require().Equal(http.StatusOK, s.do(http.MethodPost, "/plans/import", backup, &restored))
+	s.Require().Len(restored.Tasks, 1)
+	s.Equal(task.ID, restored.Tasks[0].ID)
+	s.Equal("Backed up task", restored.Tasks[0].Title)
+
+	// A backup with a task of another plan is rejected
+	backup.Tasks[0].PlanID = "other-plan"
+	var apiErr api.ErrorResponse
+	s.Equal(http.StatusBadRequest, s.do(http.MethodPost, "/plans/import", backup, &apiErr))
+	s.Contains(apiErr.Error, "belongs to plan other-plan")
+}
+
+// TestCreateTaskForMissingPlan tests that creating a task in an unknown plan returns 404
+func (s *RESTAPITestSuite) TestCreateTaskForMissingPlan() {
+	var apiErr api.ErrorResponse
+	status := s.do(http.MethodPost, "/plans/missing/tasks", map[string]string{"title": "Orphan"}, &apiErr)
+	s.Equal(http.StatusNotFound, status)
+	s.Contains(apiErr.Error, "plan not found")
+}
+
+// TestRESTAPISuite runs the REST API test suite
+func TestRESTAPISuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	suite.Run(t, new(RESTAPITestSuite))
+}
@@ -0,0 +1,120 @@
+package integration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/services"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+	"github.com/stretchr/testify/suite"
+)
+
+// RetentionTestSuite tests the retention janitor on the in-memory store
+type RetentionTestSuite struct {
+	suite.Suite
+	Context  context.Context
+	Client   *storage.ValkeyClient
+	PlanRepo *storage.PlanRepository
+	TaskRepo *storage.TaskRepository
+}
+
+// SetupTest creates an empty store for each test
+func (s *RetentionTestSuite) SetupTest() {
+	s.Context = context.Background()
+	repos := newTestRepos(s.T())
+	s.Client, s.PlanRepo, s.TaskRepo = repos.client, repos.planRepo, repos.taskRepo
+}
+
+// createPlan creates a plan with one task and backdates it to the given status and age
+func (s *RetentionTestSuite) createPlan(
+	name string,
+	status models.PlanStatus,
+	age time.Duration,
+) (*models.Plan, *models.Task) {
+	plan, err := s.PlanRepo.Create(s.Context, "retention-app", name, "")
+	s.Require().NoError(err)
+	task, err := s.TaskRepo.Create(s.Context, plan.ID, name+" task", "", models.TaskPriorityMedium)
+	s.Require().NoError(err)
+
+	plan, err = s.PlanRepo.Get(s.Context, plan.ID)
+	s.Require().NoError(err)
+	plan.Status = status
+	plan.UpdatedAt = time.Now().Add(-age)
+	s.Require().NoError(s.PlanRepo.Restore(s.Context, plan))
+	return plan, task
+}
+
+// TestArchive tests that only old finished plans are archived and that the stats count them
+func (s *RetentionTestSuite) TestArchive() {
+	archiveDir := filepath.Join(s.T().TempDir(), "archive")
+	day := 24 * time.Hour
+
+	expired, _ := s.createPlan("Expired", models.PlanStatusCompleted, 10*day)
+	recent, _ := s.createPlan("Recent", models.PlanStatusCompleted, day)
+	active, _ := s.createPlan("Active", models.PlanStatusInProgress, 10*day)
+	kept, _ := s.createPlan("Kept", models.PlanStatusCancelled, 10*day)
+	_, err := s.PlanRepo.SetMetadata(s.Context, kept.ID, map[string]string{services.RetentionMetadataKey: services.RetentionKeep})
+	s.Require().NoError(err)
+	// Setting metadata updates the plan, so backdate it again
+	kept.Metadata = map[string]string{services.RetentionMetadataKey: services.RetentionKeep}
+	s.Require().NoError(s.PlanRepo.Restore(s.Context, kept))
+
+	janitor := services.NewRetentionJanitor(s.PlanRepo, s.TaskRepo, services.RetentionPolicy{
+		MaxAge:     7 * day,
+		Action:     services.RetentionArchive,
+		ArchiveDir: archiveDir,
+	})
+	expiredIDs, err := janitor.Run(s.Context)
+	s.Require().NoError(err)
+	s.Equal([]string{expired.ID}, expiredIDs)
+
+	_, err = s.PlanRepo.Get(s.Context, expired.ID)
+	s.Error(err, "Expired plans should be deleted")
+	for _, plan := range []*models.Plan{recent, active, kept} {
+		_, err = s.PlanRepo.Get(s.Context, plan.ID)
+		s.NoError(err, "Plan %s should be kept", plan.Name)
+	}
+
+	data, err := os.ReadFile(filepath.Join(archiveDir, expired.ID+".json"))
+	s.Require().NoError(err)
+	s.Contains(string(data), "Expired task")
+
+	stats := janitor.Stats()
+	s.Equal(int64(1), stats.Runs)
+	s.Equal(int64(1), stats.PlansArchived)
+	s.Equal(int64(0), stats.PlansDeleted)
+	s.Equal(int64(1), stats.TasksExpired)
+	s.NotNil(stats.LastRunAt)
+}
+
+// TestDelete tests that the delete action removes plans without writing an archive
+func (s *RetentionTestSuite) TestDelete() {
+	archiveDir := filepath.Join(s.T().TempDir(), "archive")
+	expired, task := s.createPlan("Expired", models.PlanStatusCancelled, 48*time.Hour)
+
+	janitor := services.NewRetentionJanitor(s.PlanRepo, s.TaskRepo, services.RetentionPolicy{
+		MaxAge:     time.Hour,
+		Action:     services.RetentionDelete,
+		ArchiveDir: archiveDir,
+	})
+	expiredIDs, err := janitor.Run(s.Context)
+	s.Require().NoError(err)
+	s.Equal([]string{expired.ID}, expiredIDs)
+
+	_, err = s.TaskRepo.Get(s.Context, task.ID)
+	s.Error(err, "The tasks of expired plans should be deleted")
+	s.NoDirExists(archiveDir)
+
+	stats := janitor.Stats()
+	s.Equal(int64(1), stats.PlansDeleted)
+	s.Equal(int64(1), stats.TasksExpired)
+}
+
+// TestRetentionSuite runs the retention test suite
+func TestRetentionSuite(t *testing.T) {
+	suite.Run(t, new(RetentionTestSuite))
+}
@@ -0,0 +1,100 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+	glide "github.com/valkey-io/valkey-glide/go/v2"
+)
+
+// flakyPlanRepository fails a number of calls with a given error before succeeding
+type flakyPlanRepository struct {
+	storage.PlanRepositoryInterface
+	failures int
+	err      error
+	calls    int
+}
+
+func (r *flakyPlanRepository) Get(ctx context.Context, id string) (*models.Plan, error) {
+	r.calls++
+	if r.calls <= r.failures {
+		return nil, fmt.Errorf("failed to retrieve plan: %w", r.err)
+	}
+	return &models.Plan{ID: id}, nil
+}
+
+func (r *flakyPlanRepository) Update(ctx context.Context, plan *models.Plan) error {
+	r.calls++
+	if r.calls <= r.failures {
+		return r.err
+	}
+	return nil
+}
+
+// TestRetryingRepository tests which errors and operations are retried
+func TestRetryingRepository(t *testing.T) {
+	policy := storage.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+	notFound := errors.New("plan not found")
+
+	tests := []struct {
+		name      string
+		failures  int
+		err       error
+		wantErr   bool
+		wantCalls int
+	}{
+		{name: "timeout then success", failures: 2, err: glide.NewTimeoutError("timed out"), wantCalls: 3},
+		{name: "disconnect then success", failures: 1, err: glide.NewDisconnectError("broken pipe"), wantCalls: 2},
+		{name: "attempts used up", failures: 5, err: glide.NewTimeoutError("timed out"), wantErr: true, wantCalls: 3},
+		{name: "permanent error", failures: 1, err: notFound, wantErr: true, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flaky := &flakyPlanRepository{failures: tt.failures, err: tt.err}
+			repo := storage.NewRetryingPlanRepository(flaky, policy)
+
+			plan, err := repo.Get(context.Background(), "plan-1")
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("got error %v, want error: %v", err, tt.wantErr)
+			}
+			if err == nil && plan.ID != "plan-1" {
+				t.Errorf("got plan %q", plan.ID)
+			}
+			if flaky.calls != tt.wantCalls {
+				t.Errorf("got %d calls, want %d", flaky.calls, tt.wantCalls)
+			}
+		})
+	}
+
+	t.Run("writes are not retried", func(t *testing.T) {
+		flaky := &flakyPlanRepository{failures: 1, err: glide.NewTimeoutError("timed out")}
+		repo := storage.NewRetryingPlanRepository(flaky, policy)
+
+		if err := repo.Update(context.Background(), &models.Plan{ID: "plan-1"}); err == nil {
+			t.Fatal("expected the write to fail")
+		}
+		if flaky.calls != 1 {
+			t.Errorf("got %d calls, want 1", flaky.calls)
+		}
+	})
+
+	t.Run("cancelled context stops retrying", func(t *testing.T) {
+		flaky := &flakyPlanRepository{failures: 5, err: glide.NewTimeoutError("timed out")}
+		repo := storage.NewRetryingPlanRepository(flaky, storage.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := repo.Get(ctx, "plan-1"); err == nil {
+			t.Fatal("expected an error")
+		}
+		if flaky.calls != 1 {
+			t.Errorf("got %d calls, want 1", flaky.calls)
+		}
+	})
+}
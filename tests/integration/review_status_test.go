@@ -0,0 +1,66 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// TestReviewStatuses tests that tasks only move to the review statuses that are enabled, and that tasks in
+// review keep their plan in progress
+func TestReviewStatuses(t *testing.T) {
+	ctx := context.Background()
+	repos := newTestRepos(t)
+	t.Cleanup(func() { models.EnableReviewStatuses(nil) }) //nolint:errcheck
+	planRepo := repos.planRepo
+	taskRepo := repos.taskRepo
+
+	plan, err := planRepo.Create(ctx, "review-app", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create the plan: %v", err)
+	}
+	task, err := taskRepo.Create(ctx, plan.ID, "Task", "", models.TaskPriorityMedium)
+	if err != nil {
+		t.Fatalf("failed to create the task: %v", err)
+	}
+
+	// Disabled review statuses are rejected
+	task.Status = models.TaskStatusInReview
+	if err := taskRepo.Update(ctx, task); models.ErrorCodeOf(err) != models.ErrorCodeValidation {
+		t.Fatalf("expected a validation error for a disabled status, got %v", err)
+	}
+	_, err = taskRepo.CreateBulk(ctx, plan.ID, []storage.TaskCreateInput{{Title: "Bulk", Status: models.TaskStatusApproved}})
+	if models.ErrorCodeOf(err) != models.ErrorCodeValidation {
+		t.Fatalf("expected a validation error creating a task in a disabled status, got %v", err)
+	}
+
+	// Once enabled, a task in review keeps its plan in progress
+	if err := models.EnableReviewStatuses(models.ReviewTaskStatuses); err != nil {
+		t.Fatalf("failed to enable the review statuses: %v", err)
+	}
+	if err := taskRepo.Update(ctx, task); err != nil {
+		t.Fatalf("failed to move the task to review: %v", err)
+	}
+	got, err := planRepo.Get(ctx, plan.ID)
+	if err != nil {
+		t.Fatalf("failed to get the plan: %v", err)
+	}
+	if got.Status != models.PlanStatusInProgress || got.TaskCounts.InReview != 1 {
+		t.Errorf("expected the plan in progress with a task in review, got %s with %+v", got.Status, got.TaskCounts)
+	}
+
+	// A task keeps a review status that is disabled later, but cannot be moved to it again
+	if err := models.EnableReviewStatuses(nil); err != nil {
+		t.Fatalf("failed to disable the review statuses: %v", err)
+	}
+	task.Title = "Renamed"
+	if err := taskRepo.Update(ctx, task); err != nil {
+		t.Errorf("failed to update a task in a disabled status: %v", err)
+	}
+	task.Status = models.TaskStatusApproved
+	if err := taskRepo.Update(ctx, task); models.ErrorCodeOf(err) != models.ErrorCodeValidation {
+		t.Errorf("expected a validation error for a disabled status, got %v", err)
+	}
+}
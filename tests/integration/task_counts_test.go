@@ -0,0 +1,158 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// newTaskCountsTest creates a plan with tasks in a fresh in-memory store
+func newTaskCountsTest(
+	t *testing.T,
+	tasks int,
+) (*storage.PlanRepository, *storage.TaskRepository, *models.Plan, []*models.Task) {
+	t.Helper()
+	repos := newTestRepos(t)
+	planRepo := repos.planRepo
+	taskRepo := repos.taskRepo
+
+	ctx := context.Background()
+	plan, err := planRepo.Create(ctx, "counts-app", "Plan", "")
+	if err != nil {
+		t.Fatalf("failed to create the plan: %v", err)
+	}
+	inputs := make([]storage.TaskCreateInput, tasks)
+	for i := range inputs {
+		inputs[i] = storage.TaskCreateInput{Title: fmt.Sprintf("Task %d", i)}
+	}
+	created, err := taskRepo.CreateBulk(ctx, plan.ID, inputs)
+	if err != nil {
+		t.Fatalf("failed to create the tasks: %v", err)
+	}
+	return planRepo, taskRepo, plan, created
+}
+
+// requireTaskCounts fails the test unless the counters of a plan match its tasks
+func requireTaskCounts(t *testing.T, planRepo *storage.PlanRepository, taskRepo *storage.TaskRepository, planID string) {
+	t.Helper()
+	ctx := context.Background()
+	tasks, err := taskRepo.ListByPlan(ctx, planID)
+	if err != nil {
+		t.Fatalf("failed to list the tasks: %v", err)
+	}
+	want := &models.TaskCounts{}
+	for _, task := range tasks {
+		want.Add(task.Status, 1)
+	}
+
+	plan, err := planRepo.Get(ctx, planID)
+	if err != nil {
+		t.Fatalf("failed to get the plan: %v", err)
+	}
+	if !want.Equal(plan.TaskCounts) {
+		t.Errorf("plan %s counts %+v, its tasks %+v", planID, plan.TaskCounts, want)
+	}
+	if plan.Status != want.PlanStatus() {
+		t.Errorf("plan %s has status %s, its tasks derive %s", planID, plan.Status, want.PlanStatus())
+	}
+}
+
+// TestTaskCountsConcurrentStatusChanges tests that status changes made at the same time by updates, claims
+// and expiring leases are each counted once
+func TestTaskCountsConcurrentStatusChanges(t *testing.T) {
+	// Run the workers on several threads even on a single CPU, so their repository calls interleave
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(8))
+	planRepo, taskRepo, plan, tasks := newTaskCountsTest(t, 4)
+	ctx := context.Background()
+	statuses := []models.TaskStatus{
+		models.TaskStatusPending, models.TaskStatusInProgress, models.TaskStatusCompleted, models.TaskStatusCancelled,
+	}
+
+	var wg sync.WaitGroup
+	for worker := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 200 {
+				id := tasks[rand.IntN(len(tasks))].ID
+				switch rand.IntN(3) {
+				case 0:
+					taskRepo.ClaimTask(ctx, id, fmt.Sprintf("worker-%d", worker), time.Millisecond) //nolint:errcheck
+				case 1:
+					taskRepo.ExpireLeases(ctx) //nolint:errcheck
+				default:
+					task, err := taskRepo.Get(ctx, id)
+					if err != nil {
+						t.Errorf("failed to get task: %v", err)
+						return
+					}
+					task.Status = statuses[rand.IntN(len(statuses))]
+					taskRepo.Update(ctx, task) //nolint:errcheck
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	requireTaskCounts(t, planRepo, taskRepo, plan.ID)
+}
+
+// TestTaskCountsConcurrentMoves tests that the counters of two plans stay in step with their tasks while
+// tasks are updated, claimed and moved between the plans at the same time
+func TestTaskCountsConcurrentMoves(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(8))
+	planRepo, taskRepo, plan, tasks := newTaskCountsTest(t, 6)
+	ctx := context.Background()
+	other, err := planRepo.Create(ctx, "counts-app", "Other plan", "")
+	if err != nil {
+		t.Fatalf("failed to create the other plan: %v", err)
+	}
+	planIDs := []string{plan.ID, other.ID}
+	statuses := []models.TaskStatus{models.TaskStatusPending, models.TaskStatusInProgress, models.TaskStatusCompleted}
+
+	var wg sync.WaitGroup
+	for worker := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				id := tasks[rand.IntN(len(tasks))].ID
+				switch rand.IntN(3) {
+				case 0:
+					taskRepo.ClaimTask(ctx, id, fmt.Sprintf("worker-%d", worker), time.Minute) //nolint:errcheck
+				case 1:
+					taskRepo.MoveTask(ctx, id, planIDs[rand.IntN(len(planIDs))], -1) //nolint:errcheck
+				default:
+					task, err := taskRepo.Get(ctx, id)
+					if err != nil {
+						t.Errorf("failed to get task: %v", err)
+						return
+					}
+					task.Status = statuses[rand.IntN(len(statuses))]
+					taskRepo.Update(ctx, task) //nolint:errcheck
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	for _, planID := range planIDs {
+		requireTaskCounts(t, planRepo, taskRepo, planID)
+		count, err := taskRepo.CountByPlan(ctx, planID)
+		if err != nil {
+			t.Fatalf("failed to count the tasks: %v", err)
+		}
+		total += int(count)
+	}
+	if total != len(tasks) {
+		t.Errorf("the plans have %d tasks together, want %d", total, len(tasks))
+	}
+}
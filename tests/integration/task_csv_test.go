@@ -0,0 +1,52 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+)
+
+// TestTasksCSVFormulaEscaping tests that exported titles and descriptions cannot inject spreadsheet formulas
+// and survive a round trip unchanged
+func TestTasksCSVFormulaEscaping(t *testing.T) {
+	tasks := []*models.Task{
+		models.NewTask("t1", "plan-1", "=HYPERLINK(\"http://evil.example\",\"click\")", "+1", models.TaskPriorityLow),
+		models.NewTask("t2", "plan-1", "@SUM(A1:A2)", "-2", models.TaskPriorityLow),
+		models.NewTask("t3", "plan-1", "\tTabbed", "\rReturned", models.TaskPriorityLow),
+		models.NewTask("t4", "plan-1", "'Quoted", "Plain - text", models.TaskPriorityLow),
+		models.NewTask("t5", "plan-1", "'=1+1", "''", models.TaskPriorityLow),
+	}
+
+	var buf strings.Builder
+	if err := storage.WriteTasksCSV(&buf, tasks); err != nil {
+		t.Fatalf("failed to write CSV: %v", err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	for _, line := range lines[1 : len(lines)-1] {
+		if strings.ContainsRune("=+-@\t\r", rune(line[0])) {
+			t.Errorf("row starts like a formula: %q", line)
+		}
+	}
+	if !strings.Contains(buf.String(), "'+1") || !strings.Contains(buf.String(), "'-2") {
+		t.Errorf("descriptions starting like a formula should be escaped:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "''=1+1") {
+		t.Errorf("titles starting with a quote should be escaped too:\n%s", buf.String())
+	}
+
+	inputs, err := storage.ReadTasksCSV(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if len(inputs) != len(tasks) {
+		t.Fatalf("read %d tasks, want %d", len(inputs), len(tasks))
+	}
+	for i, input := range inputs {
+		if input.Title != tasks[i].Title || input.Description != tasks[i].Description {
+			t.Errorf("task %d = %q, %q, want %q, %q",
+				i, input.Title, input.Description, tasks[i].Title, tasks[i].Description)
+		}
+	}
+}
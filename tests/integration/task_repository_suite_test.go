@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
@@ -354,6 +356,7 @@ func (s *TaskRepositorySuite) TestReorderTaskWithInvalidOrder() {
 	// Try to reorder with invalid negative order
 	err = taskRepo.ReorderTask(s.Context, task.ID, -1)
 	s.Error(err, "Reordering task with negative order should fail")
+	s.Equal(models.ErrorCodeValidation, models.ErrorCodeOf(err), "Error should be a validation error")
 	s.Contains(err.Error(), "invalid order", "Error should indicate invalid order")
 
 	// Try to reorder with too large order
@@ -538,6 +541,130 @@ func (s *TaskRepositorySuite) TestCreateBulkTasks() {
 	s.Equal(3, len(tasks), "Should have 3 tasks in the plan")
 }
 
+// TestCreateBulkTasksWithDedup tests skipping and merging duplicate tasks during bulk creation
+func (s *TaskRepositorySuite) TestCreateBulkTasksWithDedup() {
+	taskRepo := s.GetTaskRepository()
+
+	existing, err := taskRepo.CreateBulk(s.Context, s.TestPlan.ID, []storage.TaskCreateInput{
+		{Title: "Add login page", Priority: models.TaskPriorityLow},
+		{Title: "Write tests", Description: "Unit tests"},
+	})
+	s.Require().NoError(err, "Failed to create existing tasks")
+
+	// Skip mode leaves duplicates out, including duplicates within the batch
+	report, err := taskRepo.CreateBulkWithOptions(s.Context, s.TestPlan.ID, []storage.TaskCreateInput{
+		{Title: "add login-page."},
+		{Title: "Deploy"},
+		{Title: "deploy"},
+	}, storage.BulkCreateOptions{Dedup: storage.DedupModeSkip})
+	s.Require().NoError(err, "Failed to create tasks with skip dedup")
+	s.Require().Len(report.Created, 1)
+	s.Equal("Deploy", report.Created[0].Title)
+	s.Equal([]storage.BulkDuplicate{
+		{Index: 0, Title: "add login-page.", TaskID: existing[0].ID},
+		{Index: 2, Title: "deploy", TaskID: report.Created[0].ID},
+	}, report.Skipped)
+	s.Empty(report.Merged)
+
+	// Exact matching treats differently formatted titles as new tasks
+	report, err = taskRepo.CreateBulkWithOptions(s.Context, s.TestPlan.ID, []storage.TaskCreateInput{
+		{Title: "write tests"},
+		{Title: "Write tests"},
+	}, storage.BulkCreateOptions{Dedup: storage.DedupModeSkip, Match: storage.TitleMatchExact})
+	s.Require().NoError(err, "Failed to create tasks with exact matching")
+	s.Require().Len(report.Created, 1)
+	s.Equal("write tests", report.Created[0].Title)
+	s.Require().Len(report.Skipped, 1)
+	s.Equal(existing[1].ID, report.Skipped[0].TaskID)
+
+	// Merge mode folds descriptions and higher priorities into the existing task
+	report, err = taskRepo.CreateBulkWithOptions(s.Context, s.TestPlan.ID, []storage.TaskCreateInput{
+		{Title: "Add Login Page", Description: "Use the shared form component", Priority: models.TaskPriorityHigh},
+		{Title: "Write Tests", Description: "Integration tests", Priority: models.TaskPriorityLow},
+	}, storage.BulkCreateOptions{Dedup: storage.DedupModeMerge})
+	s.Require().NoError(err, "Failed to create tasks with merge dedup")
+	s.Empty(report.Created)
+	s.Empty(report.Skipped)
+	s.Len(report.Merged, 2)
+
+	login, err := taskRepo.Get(s.Context, existing[0].ID)
+	s.Require().NoError(err)
+	s.Equal("Use the shared form component", login.Description)
+	s.Equal(models.TaskPriorityHigh, login.Priority)
+
+	tests, err := taskRepo.Get(s.Context, existing[1].ID)
+	s.Require().NoError(err)
+	s.Equal("Unit tests\n\nIntegration tests", tests.Description)
+	s.Equal(models.TaskPriorityMedium, tests.Priority)
+
+	tasks, err := taskRepo.ListByPlan(s.Context, s.TestPlan.ID)
+	s.NoError(err)
+	s.Len(tasks, 4, "Only Deploy and the exact-match task should have been added")
+
+	_, err = taskRepo.CreateBulkWithOptions(s.Context, s.TestPlan.ID, nil, storage.BulkCreateOptions{Dedup: "replace"})
+	s.Error(err, "Unknown dedup modes should be rejected")
+}
+
+// TestTasksCSVRoundTrip tests exporting tasks as CSV and importing them into another plan
+func (s *TaskRepositorySuite) TestTasksCSVRoundTrip() {
+	taskRepo := s.GetTaskRepository()
+
+	_, err := taskRepo.CreateBulk(s.Context, s.TestPlan.ID, []storage.TaskCreateInput{
+		{Title: "Design, review", Description: "Line one\nline two", Priority: models.TaskPriorityHigh},
+		{Title: "Build", Status: models.TaskStatusInProgress},
+	})
+	s.Require().NoError(err, "Failed to create tasks")
+
+	tasks, err := taskRepo.ListByPlan(s.Context, s.TestPlan.ID)
+	s.Require().NoError(err, "Failed to list tasks")
+
+	var buf strings.Builder
+	s.Require().NoError(storage.WriteTasksCSV(&buf, tasks))
+	s.Equal(
+		"title,description,status,priority,order\n"+
+			"\"Design, review\",\"Line one\nline two\",pending,high,0\n"+
+			"Build,,in_progress,medium,1\n",
+		buf.String(),
+	)
+
+	target, err := s.GetPlanRepository().Create(s.Context, s.TestPlan.ApplicationID, "CSV target", "desc")
+	s.Require().NoError(err, "Failed to create target plan")
+
+	inputs, err := storage.ReadTasksCSV(strings.NewReader(buf.String()))
+	s.Require().NoError(err, "Failed to read CSV")
+	imported, err := taskRepo.CreateBulk(s.Context, target.ID, inputs)
+	s.Require().NoError(err, "Failed to import tasks")
+	s.Require().Len(imported, 2)
+	s.Equal("Design, review", imported[0].Title)
+	s.Equal("Line one\nline two", imported[0].Description)
+	s.Equal(models.TaskPriorityHigh, imported[0].Priority)
+	s.Equal(models.TaskStatusInProgress, imported[1].Status)
+
+	// Spreadsheet exports may reorder columns, add extra ones and use display values
+	inputs, err = storage.ReadTasksCSV(strings.NewReader(
+		"\ufeffOrder,Title,Owner,Status,Priority\n2,Second,ann,In Progress,LOW\n1,First,bob,,\n,Last,,completed,\n",
+	))
+	s.Require().NoError(err, "Failed to read spreadsheet CSV")
+	s.Equal([]storage.TaskCreateInput{
+		{Title: "First"},
+		{Title: "Second", Status: models.TaskStatusInProgress, Priority: models.TaskPriorityLow},
+		{Title: "Last", Status: models.TaskStatusCompleted},
+	}, inputs)
+
+	invalid := []string{
+		"",
+		"description\nno title column\n",
+		"title,status\nTask,done\n",
+		"title,priority\nTask,urgent\n",
+		"title,order\nTask,first\n",
+		"title,description\n,missing title\n",
+	}
+	for _, content := range invalid {
+		_, err := storage.ReadTasksCSV(strings.NewReader(content))
+		s.Error(err, "Expected an error for CSV %q", content)
+	}
+}
+
 // TestListTasksByPlanAndStatus tests listing tasks by both plan ID and status
 func (s *TaskRepositorySuite) TestListTasksByPlanAndStatus() {
 	taskRepo := s.GetTaskRepository()
@@ -652,8 +779,13 @@ func (s *TaskRepositorySuite) TestMCPBulkCreateTasks() {
 			// Convert to TaskCreateInput
 			var inputs []storage.TaskCreateInput
 			for _, task := range taskInputs {
+				title, ok := task["title"].(string)
+				if !ok || title == "" {
+					http.Error(w, "Each task needs a title", http.StatusBadRequest)
+					return
+				}
 				input := storage.TaskCreateInput{
-					Title:       task["title"].(string),
+					Title:       title,
 					Description: "",
 					Status:      models.TaskStatusPending,
 					Priority:    models.TaskPriorityMedium,
@@ -848,6 +980,327 @@ func (s *TaskRepositorySuite) TestTaskNotesWithSpecialCharacters() {
 	s.Equal(specialNotes, retrievedNotes, "Task notes with special characters should be preserved")
 }
 
+// TestClaimTask tests claiming a task with a lease
+func (s *TaskRepositorySuite) TestClaimTask() {
+	taskRepo := s.GetTaskRepository()
+
+	task, err := taskRepo.Create(s.Context, s.TestPlan.ID, "Queue Task", "Task to claim", models.TaskPriorityMedium)
+	s.NoError(err, "Failed to create task")
+
+	// Claim the task
+	claimed, err := taskRepo.ClaimTask(s.Context, task.ID, "worker-1", time.Minute)
+	s.NoError(err, "Failed to claim task")
+	s.Equal(models.TaskStatusInProgress, claimed.Status, "Claimed task should be in progress")
+	s.Equal("worker-1", claimed.LeaseOwner, "Lease owner should match the worker")
+	s.NotNil(claimed.LeaseExpiresAt, "Lease expiry should be set")
+
+	// A second worker cannot claim the same task
+	_, err = taskRepo.ClaimTask(s.Context, task.ID, "worker-2", time.Minute)
+	s.Error(err, "Claiming an already claimed task should fail")
+	s.Contains(err.Error(), "already claimed by worker-1", "Error should name the current lease owner")
+
+	// Completing the task releases the lease
+	claimed.Status = models.TaskStatusCompleted
+	err = taskRepo.Update(s.Context, claimed)
+	s.NoError(err, "Failed to complete task")
+
+	completed, err := taskRepo.Get(s.Context, task.ID)
+	s.NoError(err, "Failed to get completed task")
+	s.Empty(completed.LeaseOwner, "Completed task should not keep a lease")
+	s.Nil(completed.LeaseExpiresAt, "Completed task should not keep a lease expiry")
+}
+
+// TestRenewLease tests renewing a task lease
+func (s *TaskRepositorySuite) TestRenewLease() {
+	taskRepo := s.GetTaskRepository()
+
+	task, err := taskRepo.Create(s.Context, s.TestPlan.ID, "Queue Task", "Task to renew", models.TaskPriorityMedium)
+	s.NoError(err, "Failed to create task")
+
+	claimed, err := taskRepo.ClaimTask(s.Context, task.ID, "worker-1", time.Minute)
+	s.NoError(err, "Failed to claim task")
+
+	// Only the lease owner can renew
+	_, err = taskRepo.RenewLease(s.Context, task.ID, "worker-2", time.Minute)
+	s.Error(err, "Renewing another worker's lease should fail")
+
+	renewed, err := taskRepo.RenewLease(s.Context, task.ID, "worker-1", 10*time.Minute)
+	s.NoError(err, "Failed to renew lease")
+	s.True(renewed.LeaseExpiresAt.After(*claimed.LeaseExpiresAt), "Renewed lease should expire later")
+}
+
+// TestExpireLeases tests that expired leases return tasks to pending
+func (s *TaskRepositorySuite) TestExpireLeases() {
+	taskRepo := s.GetTaskRepository()
+
+	task, err := taskRepo.Create(s.Context, s.TestPlan.ID, "Queue Task", "Task to expire", models.TaskPriorityMedium)
+	s.NoError(err, "Failed to create task")
+
+	_, err = taskRepo.ClaimTask(s.Context, task.ID, "worker-1", 100*time.Millisecond)
+	s.NoError(err, "Failed to claim task")
+
+	// Wait for the lease to expire
+	time.Sleep(300 * time.Millisecond)
+
+	released, err := taskRepo.ExpireLeases(s.Context)
+	s.NoError(err, "Failed to expire leases")
+	s.Equal([]string{task.ID}, released, "Expired task should be released")
+
+	expired, err := taskRepo.Get(s.Context, task.ID)
+	s.NoError(err, "Failed to get expired task")
+	s.Equal(models.TaskStatusPending, expired.Status, "Expired task should be pending again")
+	s.Empty(expired.LeaseOwner, "Expired task should not keep a lease")
+
+	// The task can be claimed again by another worker
+	_, err = taskRepo.ClaimTask(s.Context, task.ID, "worker-2", time.Minute)
+	s.NoError(err, "Expired task should be claimable again")
+}
+
+// TestCompleteRecurringTask tests that completing a recurring task creates its next occurrence
+func (s *TaskRepositorySuite) TestCompleteRecurringTask() {
+	taskRepo := s.GetTaskRepository()
+
+	task, err := taskRepo.Create(s.Context, s.TestPlan.ID, "Weekly Review", "Review progress", models.TaskPriorityMedium)
+	s.NoError(err, "Failed to create task")
+
+	dueDate := time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)
+	task.DueDate = &dueDate
+	task.Recurrence = "FREQ=WEEKLY;INTERVAL=1"
+	err = taskRepo.Update(s.Context, task)
+	s.NoError(err, "Failed to set task schedule")
+
+	// Complete the recurring task
+	task.Status = models.TaskStatusCompleted
+	err = taskRepo.Update(s.Context, task)
+	s.NoError(err, "Failed to complete task")
+	s.NotEmpty(task.NextOccurrenceID, "Completed recurring task should reference its next occurrence")
+
+	next, err := taskRepo.Get(s.Context, task.NextOccurrenceID)
+	s.NoError(err, "Failed to get next occurrence")
+	s.NotEqual(task.ID, next.ID, "Next occurrence should have a fresh ID")
+	s.Equal(task.Title, next.Title, "Next occurrence should keep the title")
+	s.Equal(models.TaskStatusPending, next.Status, "Next occurrence should be pending")
+	s.Equal(task.Recurrence, next.Recurrence, "Next occurrence should keep the recurrence")
+	s.True(next.DueDate.Equal(dueDate.AddDate(0, 0, 7)), "Next occurrence should be due a week later")
+}
+
+// TestTaskTags tests adding, removing and querying task tags
+func (s *TaskRepositorySuite) TestTaskTags() {
+	taskRepo := s.GetTaskRepository()
+
+	task1, err := taskRepo.Create(s.Context, s.TestPlan.ID, "Task 1", "Description 1", models.TaskPriorityMedium)
+	s.NoError(err, "Failed to create task 1")
+	task2, err := taskRepo.Create(s.Context, s.TestPlan.ID, "Task 2", "Description 2", models.TaskPriorityMedium)
+	s.NoError(err, "Failed to create task 2")
+
+	// Tags are normalized and deduplicated
+	tagged, err := taskRepo.AddTags(s.Context, task1.ID, []string{"Backend", "urgent", "backend"})
+	s.NoError(err, "Failed to add tags")
+	s.Equal([]string{"backend", "urgent"}, tagged.Tags, "Tags should be normalized")
+
+	_, err = taskRepo.AddTags(s.Context, task2.ID, []string{"backend"})
+	s.NoError(err, "Failed to add tags")
+
+	tasks, err := taskRepo.ListByTag(s.Context, "BACKEND")
+	s.NoError(err, "Failed to list tasks by tag")
+	s.Len(tasks, 2, "Both tasks should carry the backend tag")
+
+	// Removing a tag updates the task and the reverse index
+	untagged, err := taskRepo.RemoveTags(s.Context, task1.ID, []string{"backend"})
+	s.NoError(err, "Failed to remove tags")
+	s.Equal([]string{"urgent"}, untagged.Tags, "Only the urgent tag should remain")
+
+	tasks, err = taskRepo.ListByTag(s.Context, "backend")
+	s.NoError(err, "Failed to list tasks by tag")
+	s.Len(tasks, 1, "Only task 2 should carry the backend tag")
+	s.Equal(task2.ID, tasks[0].ID)
+
+	// Deleting a task removes it from the tag index
+	err = taskRepo.Delete(s.Context, task2.ID)
+	s.NoError(err, "Failed to delete task")
+
+	tasks, err = taskRepo.ListByTag(s.Context, "backend")
+	s.NoError(err, "Failed to list tasks by tag")
+	s.Empty(tasks, "Deleted task should no longer be tagged")
+}
+
+// TestTaskMetadata tests setting and deleting task metadata
+func (s *TaskRepositorySuite) TestTaskMetadata() {
+	taskRepo := s.GetTaskRepository()
+
+	task, err := taskRepo.Create(s.Context, s.TestPlan.ID, "Test Task", "Test task description", models.TaskPriorityMedium)
+	s.NoError(err, "Failed to create task")
+
+	updated, err := taskRepo.SetMetadata(s.Context, task.ID, map[string]string{"pr": "42", "branch": "feature/x"})
+	s.NoError(err, "Failed to set task metadata")
+	s.Equal("42", updated.Metadata["pr"], "PR metadata should be set")
+
+	updated, err = taskRepo.DeleteMetadata(s.Context, task.ID, []string{"pr", "missing"})
+	s.NoError(err, "Failed to delete task metadata")
+	s.Equal(map[string]string{"branch": "feature/x"}, updated.Metadata)
+}
+
+// TestTaskTimeTracking tests automatic time stamps and explicit time logging
+func (s *TaskRepositorySuite) TestTaskTimeTracking() {
+	taskRepo := s.GetTaskRepository()
+
+	task, err := taskRepo.Create(s.Context, s.TestPlan.ID, "Test Task", "Test task description", models.TaskPriorityMedium)
+	s.NoError(err, "Failed to create task")
+	s.Nil(task.StartedAt, "New task should not be started")
+
+	task.Status = models.TaskStatusInProgress
+	err = taskRepo.Update(s.Context, task)
+	s.NoError(err, "Failed to start task")
+	s.NotNil(task.StartedAt, "Started task should have a start time")
+
+	task, err = taskRepo.LogTime(s.Context, task.ID, 15*time.Minute)
+	s.NoError(err, "Failed to log time")
+	s.Equal(int64(900), task.TimeSpent, "Logged time should be added")
+
+	task.Status = models.TaskStatusCompleted
+	err = taskRepo.Update(s.Context, task)
+	s.NoError(err, "Failed to complete task")
+
+	task, err = taskRepo.Get(s.Context, task.ID)
+	s.NoError(err, "Failed to get task")
+	s.NotNil(task.CompletedAt, "Completed task should have a completion time")
+	s.GreaterOrEqual(task.TimeSpent, int64(900), "Time spent should include the logged time")
+
+	_, err = taskRepo.LogTime(s.Context, task.ID, 0)
+	s.Error(err, "Expected error when logging no time")
+}
+
+// TestTaskChecklist tests adding, toggling and removing checklist items
+func (s *TaskRepositorySuite) TestTaskChecklist() {
+	taskRepo := s.GetTaskRepository()
+
+	task, err := taskRepo.Create(s.Context, s.TestPlan.ID, "Test Task", "Test task description", models.TaskPriorityMedium)
+	s.NoError(err, "Failed to create task")
+
+	_, err = taskRepo.AddChecklistItem(s.Context, task.ID, "Write the migration")
+	s.NoError(err, "Failed to add first checklist item")
+	task, err = taskRepo.AddChecklistItem(s.Context, task.ID, "Update the docs")
+	s.NoError(err, "Failed to add second checklist item")
+	s.Len(task.Checklist, 2, "Task should have two checklist items")
+	s.Equal("Write the migration", task.Checklist[0].Text, "Checklist order should be preserved")
+
+	firstID := task.Checklist[0].ID
+	task, err = taskRepo.ToggleChecklistItem(s.Context, task.ID, firstID, nil)
+	s.NoError(err, "Failed to toggle checklist item")
+	s.True(task.Checklist[0].Done, "Toggled item should be done")
+
+	done := false
+	task, err = taskRepo.ToggleChecklistItem(s.Context, task.ID, firstID, &done)
+	s.NoError(err, "Failed to set checklist item")
+	s.False(task.Checklist[0].Done, "Item should be explicitly marked not done")
+
+	task, err = taskRepo.RemoveChecklistItem(s.Context, task.ID, firstID)
+	s.NoError(err, "Failed to remove checklist item")
+	s.Len(task.Checklist, 1, "One checklist item should remain")
+	s.Equal("Update the docs", task.Checklist[0].Text)
+
+	_, err = taskRepo.RemoveChecklistItem(s.Context, task.ID, "missing")
+	s.Error(err, "Expected error for a non-existent checklist item")
+}
+
+// TestConcurrentCreates tests that tasks created at the same time get distinct orders
+func (s *TaskRepositorySuite) TestConcurrentCreates() {
+	taskRepo := s.GetTaskRepository()
+
+	const count = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, count)
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := taskRepo.Create(s.Context, s.TestPlan.ID, "Parallel", "", models.TaskPriorityMedium)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		s.Require().NoError(err)
+	}
+
+	tasks, err := taskRepo.ListByPlan(s.Context, s.TestPlan.ID)
+	s.Require().NoError(err)
+	s.Require().Len(tasks, count)
+	for i, task := range tasks {
+		s.Equal(i, task.Order, "Each task should get its own order")
+	}
+}
+
+// TestPlanLockWaits tests that changes to a plan wait while another operation holds its lock
+func (s *TaskRepositorySuite) TestPlanLockWaits() {
+	taskRepo := s.GetTaskRepository()
+	raw := s.Containers[len(s.Containers)-1].Client
+	lockKey := storage.GetPlanLockKey(s.TestPlan.ID)
+
+	_, err := raw.Set(s.Context, lockKey, "another-replica")
+	s.Require().NoError(err)
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		raw.Del(s.Context, []string{lockKey}) //nolint:errcheck
+	}()
+
+	start := time.Now()
+	_, err = taskRepo.Create(s.Context, s.TestPlan.ID, "Waiting", "", models.TaskPriorityMedium)
+	s.Require().NoError(err)
+	s.GreaterOrEqual(time.Since(start), 200*time.Millisecond, "Create should wait for the lock")
+
+	exists, err := raw.Exists(s.Context, []string{lockKey})
+	s.Require().NoError(err)
+	s.Equal(int64(0), exists, "The lock should be released after the change")
+}
+
+// TestPlanLockRenewed tests that a plan lock held longer than its TTL is renewed and released afterwards
+func (s *TaskRepositorySuite) TestPlanLockRenewed() {
+	s.ValkeyClient.SetPlanLockTTL(300 * time.Millisecond)
+	planRepo := s.GetPlanRepository()
+	raw := s.Containers[len(s.Containers)-1].Client
+	lockKey := storage.GetPlanLockKey(s.TestPlan.ID)
+
+	_, unlock, err := planRepo.LockPlan(s.Context, s.TestPlan.ID)
+	s.Require().NoError(err)
+	time.Sleep(time.Second)
+
+	exists, err := raw.Exists(s.Context, []string{lockKey})
+	s.Require().NoError(err)
+	s.Equal(int64(1), exists, "The lock should be renewed while it is held")
+
+	unlock()
+	exists, err = raw.Exists(s.Context, []string{lockKey})
+	s.Require().NoError(err)
+	s.Equal(int64(0), exists, "The lock should be released")
+}
+
+// TestPlanLockLost tests that changes made under a plan lock that was taken over fail instead of interleaving
+func (s *TaskRepositorySuite) TestPlanLockLost() {
+	s.ValkeyClient.SetPlanLockTTL(300 * time.Millisecond)
+	planRepo := s.GetPlanRepository()
+	taskRepo := s.GetTaskRepository()
+	raw := s.Containers[len(s.Containers)-1].Client
+	lockKey := storage.GetPlanLockKey(s.TestPlan.ID)
+
+	lockedCtx, unlock, err := planRepo.LockPlan(s.Context, s.TestPlan.ID)
+	s.Require().NoError(err)
+	defer unlock()
+
+	_, err = raw.Set(s.Context, lockKey, "another-replica")
+	s.Require().NoError(err)
+	time.Sleep(300 * time.Millisecond)
+
+	_, err = taskRepo.Create(lockedCtx, s.TestPlan.ID, "Interleaving", "", models.TaskPriorityMedium)
+	s.Require().Error(err)
+	s.Equal(models.ErrorCodeConflict, models.ErrorCodeOf(err), "Changes under a lost lock should conflict")
+
+	value, err := raw.Get(s.Context, lockKey)
+	s.Require().NoError(err)
+	s.Equal("another-replica", value.Value(), "The lock taken over should not be released")
+}
+
 // TestTaskRepositorySuite runs the task repository test suite
 func TestTaskRepositorySuite(t *testing.T) {
 	if testing.Short() {
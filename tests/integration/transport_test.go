@@ -2,11 +2,20 @@ package integration
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io"
+	"math/big"
 	"net/http"
 	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -16,6 +25,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/websocket"
 )
 
 // TransportTestSuite is a test suite for testing different MCP transport protocols
@@ -266,6 +276,51 @@ func (s *TransportTestSuite) TestStreamableHTTPTransport() {
 	assert.NotEmpty(s.T(), result, "Response should not be empty")
 }
 
+// TestWebSocketTransport tests the WebSocket transport functionality
+func (s *TransportTestSuite) TestWebSocketTransport() {
+	// Setup server with only WebSocket enabled
+	s.T().Setenv("ENABLE_WEBSOCKET", "true")
+	_, port, cleanup := s.setupTestServer(false, false, false)
+	defer cleanup()
+
+	config, err := websocket.NewConfig(fmt.Sprintf("ws://localhost:%d/ws", port), fmt.Sprintf("http://localhost:%d", port))
+	require.NoError(s.T(), err)
+	config.Protocol = []string{"mcp"}
+	conn, err := websocket.DialConfig(config)
+	require.NoError(s.T(), err, "Should connect to WebSocket endpoint without error")
+	defer conn.Close()
+
+	// Each message carries one JSON-RPC message
+	messages := []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26",` +
+			`"capabilities":{},"clientInfo":{"name":"test","version":"1.0.0"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+	}
+	for _, message := range messages {
+		require.NoError(s.T(), websocket.Message.Send(conn, message))
+	}
+
+	// Requests are handled concurrently, so responses may arrive in any order
+	responses := map[float64]map[string]interface{}{}
+	for len(responses) < 2 {
+		var response map[string]interface{}
+		require.NoError(s.T(), websocket.JSON.Receive(conn, &response), "Should receive a response")
+		id, ok := response["id"].(float64)
+		require.True(s.T(), ok, "Response should carry a request ID")
+		responses[id] = response
+	}
+
+	assert.Contains(s.T(), responses[1], "result", "Initialize should succeed")
+	result, ok := responses[2]["result"].(map[string]interface{})
+	require.True(s.T(), ok, "Tools list should succeed")
+	assert.NotEmpty(s.T(), result["tools"], "Tools should be listed over WebSocket")
+
+	// Browser pages from other origins are refused
+	_, err = websocket.Dial(fmt.Sprintf("ws://localhost:%d/ws", port), "", "http://example.com")
+	assert.Error(s.T(), err, "Cross-origin WebSocket connections should be refused")
+}
+
 // TestConcurrentConnections tests both transports with concurrent connections
 func (s *TransportTestSuite) TestConcurrentConnections() {
 	// Setup server with both transports enabled
@@ -372,6 +427,70 @@ func (s *TransportTestSuite) TestSTDIOTransport() {
 	// library will handle the STDIO transport correctly
 }
 
+// writeSelfSignedCertificate writes a self-signed certificate for localhost and its key to a temporary directory
+func writeSelfSignedCertificate(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+// TestTLSTransport tests serving the HTTP transports over TLS with a configured certificate
+func (s *TransportTestSuite) TestTLSTransport() {
+	certFile, keyFile := writeSelfSignedCertificate(s.T())
+	s.T().Setenv("TLS_CERT_FILE", certFile)
+	s.T().Setenv("TLS_KEY_FILE", keyFile)
+
+	_, port, cleanup := s.setupTestServer(true, true, false)
+	defer cleanup()
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true, MaxVersion: tls.VersionTLS12},
+	}}
+
+	resp, err := client.Get(fmt.Sprintf("https://localhost:%d/health", port))
+	s.Require().NoError(err, "Health endpoint should be accessible over TLS")
+	defer resp.Body.Close()
+	s.Equal(http.StatusOK, resp.StatusCode)
+	s.Require().NotNil(resp.TLS)
+	s.Equal(uint16(tls.VersionTLS12), resp.TLS.Version)
+
+	// Plain HTTP is not served on the TLS port
+	plainResp, err := http.Get(fmt.Sprintf("http://localhost:%d/health", port))
+	if err == nil {
+		defer plainResp.Body.Close()
+		s.Equal(http.StatusBadRequest, plainResp.StatusCode)
+	}
+
+	// Clients offering only legacy protocol versions are refused
+	legacyClient := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS10, MaxVersion: tls.VersionTLS11},
+	}}
+	_, err = legacyClient.Get(fmt.Sprintf("https://localhost:%d/health", port))
+	s.Error(err, "TLS 1.1 should be refused")
+}
+
 // TestTransportSuite runs the transport test suite
 func TestTransportSuite(t *testing.T) {
 	if testing.Short() {
@@ -0,0 +1,144 @@
+package integration
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+	"github.com/jbrinkman/valkey-ai-tasks/tests/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+// ValkeyConnectionTestSuite is a test suite for the Valkey connection options
+type ValkeyConnectionTestSuite struct {
+	utils.RepositoryTestSuite
+	address storage.ValkeyAddress
+}
+
+// SetupTest sets up each test
+func (s *ValkeyConnectionTestSuite) SetupTest() {
+	s.RepositoryTestSuite.SetupTest()
+
+	endpoint, err := s.Containers[len(s.Containers)-1].Container.Endpoint(s.Context, "")
+	s.Require().NoError(err)
+	host, port, err := utils.ParseEndpoint(endpoint)
+	s.Require().NoError(err)
+	s.address = storage.ValkeyAddress{Host: host, Port: port}
+}
+
+// connect creates a client for the test container with the given options and checks it can write
+func (s *ValkeyConnectionTestSuite) connect(cfg storage.ValkeyConfig) (*storage.ValkeyClient, error) {
+	if len(cfg.Addresses) == 0 {
+		cfg.Addresses = []storage.ValkeyAddress{s.address}
+	}
+	client, err := storage.NewValkeyClientWithConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.T().Cleanup(func() { client.Close() })
+
+	_, err = storage.NewPlanRepository(client).Create(s.Context, "conn-app-"+uuid.New().String(), "Plan", "")
+	return client, err
+}
+
+// TestDatabaseIndex tests that data is written to the selected logical database
+func (s *ValkeyConnectionTestSuite) TestDatabaseIndex() {
+	client, err := s.connect(storage.ValkeyConfig{Database: 3})
+	s.Require().NoError(err)
+
+	plans, err := storage.NewPlanRepository(client).List(s.Context)
+	s.Require().NoError(err)
+	s.Len(plans, 1)
+
+	// The default database stays empty
+	plans, err = s.GetPlanRepository().List(s.Context)
+	s.Require().NoError(err)
+	s.Empty(plans)
+}
+
+// TestPasswordWithoutUsername tests authenticating the default user with only a password
+func (s *ValkeyConnectionTestSuite) TestPasswordWithoutUsername() {
+	container := s.Containers[len(s.Containers)-1]
+	_, err := container.Client.CustomCommand(s.Context, []string{"CONFIG", "SET", "requirepass", "secret"})
+	s.Require().NoError(err)
+
+	_, err = s.connect(storage.ValkeyConfig{Password: "wrong"})
+	s.Require().Error(err)
+
+	_, err = s.connect(storage.ValkeyConfig{Password: "secret"})
+	s.Require().NoError(err)
+}
+
+// TestTLSWithCustomCA tests connecting over TLS to a server whose certificate is signed by a private CA
+func (s *ValkeyConnectionTestSuite) TestTLSWithCustomCA() {
+	certFile, keyFile := writeSelfSignedCertificate(s.T())
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	s.Require().NoError(err)
+
+	// Terminate TLS in front of the container, like a managed Valkey service does
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{certificate}})
+	s.Require().NoError(err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				backend, err := net.Dial("tcp", net.JoinHostPort(s.address.Host, strconv.Itoa(s.address.Port)))
+				if err != nil {
+					return
+				}
+				defer backend.Close()
+				go io.Copy(backend, conn)
+				io.Copy(conn, backend)
+			}()
+		}
+	}()
+	tlsAddress := storage.ValkeyAddress{Host: "localhost", Port: listener.Addr().(*net.TCPAddr).Port}
+
+	// The certificate is not trusted by the system roots
+	_, err = s.connect(storage.ValkeyConfig{Addresses: []storage.ValkeyAddress{tlsAddress}, TLS: true})
+	s.Require().Error(err)
+
+	_, err = s.connect(storage.ValkeyConfig{Addresses: []storage.ValkeyAddress{tlsAddress}, TLS: true, TLSCAFile: certFile})
+	s.Require().NoError(err)
+
+	_, err = s.connect(storage.ValkeyConfig{
+		Addresses:             []storage.ValkeyAddress{tlsAddress},
+		TLS:                   true,
+		TLSInsecureSkipVerify: true,
+	})
+	s.Require().NoError(err)
+
+	plans, err := s.GetPlanRepository().List(s.Context)
+	s.Require().NoError(err)
+	s.Len(plans, 2)
+}
+
+// TestInvalidOptions tests that conflicting options are rejected before connecting
+func (s *ValkeyConnectionTestSuite) TestInvalidOptions() {
+	_, err := s.connect(storage.ValkeyConfig{TLSCAFile: "ca.pem"})
+	s.Require().ErrorContains(err, "require TLS")
+
+	_, err = s.connect(storage.ValkeyConfig{Cluster: true, Database: 1})
+	s.Require().ErrorContains(err, "database 0")
+
+	_, err = s.connect(storage.ValkeyConfig{TLS: true, TLSCertFile: "cert.pem"})
+	s.Require().ErrorContains(err, "certificate and key")
+}
+
+// TestValkeyConnectionSuite runs the Valkey connection test suite
+func TestValkeyConnectionSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	suite.Run(t, new(ValkeyConnectionTestSuite))
+}
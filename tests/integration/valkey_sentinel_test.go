@@ -0,0 +1,176 @@
+package integration
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jbrinkman/valkey-ai-tasks/internal/storage"
+	"github.com/jbrinkman/valkey-ai-tasks/tests/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeSentinel answers primary lookups like a Sentinel monitoring a single primary
+type fakeSentinel struct {
+	mu       sync.Mutex
+	listener net.Listener
+	primary  storage.ValkeyAddress
+}
+
+// newFakeSentinel starts a fake Sentinel reporting the given primary
+func newFakeSentinel(primary storage.ValkeyAddress) (*fakeSentinel, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	f := &fakeSentinel{listener: listener, primary: primary}
+	go f.serve()
+	return f, nil
+}
+
+// address returns the address the fake Sentinel listens on
+func (f *fakeSentinel) address() storage.ValkeyAddress {
+	addr := f.listener.Addr().(*net.TCPAddr)
+	return storage.ValkeyAddress{Host: addr.IP.String(), Port: addr.Port}
+}
+
+// failover makes the fake Sentinel report a new primary
+func (f *fakeSentinel) failover(primary storage.ValkeyAddress) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.primary = primary
+}
+
+func (f *fakeSentinel) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeSentinel) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readSentinelCommand(reader)
+		if err != nil {
+			return
+		}
+
+		if len(args) == 3 && strings.EqualFold(args[1], "GET-MASTER-ADDR-BY-NAME") && args[2] == "mymaster" {
+			f.mu.Lock()
+			host, port := f.primary.Host, strconv.Itoa(f.primary.Port)
+			f.mu.Unlock()
+			fmt.Fprintf(conn, "*2\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(host), host, len(port), port)
+		} else {
+			fmt.Fprint(conn, "*-1\r\n")
+		}
+	}
+}
+
+// readSentinelCommand reads a command sent as a RESP array of bulk strings
+func readSentinelCommand(reader *bufio.Reader) ([]string, error) {
+	var count int
+	if _, err := fmt.Fscanf(reader, "*%d\r\n", &count); err != nil {
+		return nil, err
+	}
+	args := make([]string, count)
+	for i := range args {
+		var size int
+		if _, err := fmt.Fscanf(reader, "$%d\r\n", &size); err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
+
+// ValkeySentinelTestSuite is a test suite for connecting to Valkey through Sentinel
+type ValkeySentinelTestSuite struct {
+	utils.RepositoryTestSuite
+}
+
+// containerAddress starts a Valkey container and returns its address
+func (s *ValkeySentinelTestSuite) containerAddress() (*utils.ValkeyContainer, storage.ValkeyAddress) {
+	container, err := utils.StartValkeyContainer(s.Context, s.T())
+	s.Require().NoError(err)
+	s.Containers = append(s.Containers, container)
+
+	endpoint, err := container.Container.Endpoint(s.Context, "")
+	s.Require().NoError(err)
+	host, port, err := utils.ParseEndpoint(endpoint)
+	s.Require().NoError(err)
+	return container, storage.ValkeyAddress{Host: host, Port: port}
+}
+
+// TestFollowsFailover tests that the client discovers the primary and follows it when it changes
+func (s *ValkeySentinelTestSuite) TestFollowsFailover() {
+	first, firstAddress := s.containerAddress()
+	second, secondAddress := s.containerAddress()
+
+	sentinel, err := newFakeSentinel(firstAddress)
+	s.Require().NoError(err)
+	defer sentinel.listener.Close()
+
+	client, err := storage.NewValkeyClientWithConfig(storage.ValkeyConfig{
+		SentinelAddresses: []storage.ValkeyAddress{sentinel.address()},
+		SentinelMaster:    "mymaster",
+	})
+	s.Require().NoError(err)
+	defer client.Close()
+	planRepo := storage.NewPlanRepository(client)
+
+	_, err = planRepo.Create(s.Context, "sentinel-app", "Before failover", "")
+	s.Require().NoError(err)
+	count, err := first.Client.Exists(s.Context, []string{"plans"})
+	s.Require().NoError(err)
+	s.Equal(int64(1), count)
+
+	sentinel.failover(secondAddress)
+	s.Eventually(func() bool {
+		plans, err := planRepo.List(s.Context)
+		return err == nil && len(plans) == 0
+	}, 10*time.Second, 100*time.Millisecond, "client should switch to the new primary")
+
+	_, err = planRepo.Create(s.Context, "sentinel-app", "After failover", "")
+	s.Require().NoError(err)
+	count, err = second.Client.Exists(s.Context, []string{"plans"})
+	s.Require().NoError(err)
+	s.Equal(int64(1), count)
+}
+
+// TestUnknownPrimary tests that connecting fails when Sentinel does not know the primary
+func (s *ValkeySentinelTestSuite) TestUnknownPrimary() {
+	sentinel, err := newFakeSentinel(storage.ValkeyAddress{Host: "127.0.0.1", Port: 6379})
+	s.Require().NoError(err)
+	defer sentinel.listener.Close()
+
+	_, err = storage.NewValkeyClientWithConfig(storage.ValkeyConfig{
+		SentinelAddresses: []storage.ValkeyAddress{sentinel.address()},
+		SentinelMaster:    "unknown",
+	})
+	s.Require().Error(err)
+	s.Contains(err.Error(), "unknown")
+}
+
+// TestValkeySentinelSuite runs the Valkey Sentinel test suite
+func TestValkeySentinelSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	suite.Run(t, new(ValkeySentinelTestSuite))
+}
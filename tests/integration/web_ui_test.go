@@ -0,0 +1,122 @@
+package integration
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/models"
+	"github.com/jbrinkman/valkey-ai-tasks/internal/ui"
+	"github.com/jbrinkman/valkey-ai-tasks/tests/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+// WebUITestSuite is a test suite for the web dashboard
+type WebUITestSuite struct {
+	utils.RepositoryTestSuite
+	server *httptest.Server
+}
+
+// SetupTest sets up each test
+func (s *WebUITestSuite) SetupTest() {
+	s.RepositoryTestSuite.SetupTest()
+	s.server = httptest.NewServer(ui.NewHandler(s.GetPlanRepository(), s.GetTaskRepository(), 100*time.Millisecond))
+}
+
+// TearDownTest cleans up after each test
+func (s *WebUITestSuite) TearDownTest() {
+	s.server.Close()
+	s.RepositoryTestSuite.TearDownTest()
+}
+
+// TestPlanBoard tests that the plan endpoint groups tasks by status and renders notes
+func (s *WebUITestSuite) TestPlanBoard() {
+	plan, err := s.GetPlanRepository().Create(s.Context, "ui-app-"+uuid.New().String(), "UI Plan", "desc")
+	s.Require().NoError(err)
+	s.Require().NoError(s.GetPlanRepository().UpdateNotes(s.Context, plan.ID, "**Important**"))
+
+	task, err := s.GetTaskRepository().Create(s.Context, plan.ID, "Started", "desc", models.TaskPriorityHigh)
+	s.Require().NoError(err)
+	task.Status = models.TaskStatusInProgress
+	s.Require().NoError(s.GetTaskRepository().Update(s.Context, task))
+	_, err = s.GetTaskRepository().Create(s.Context, plan.ID, "Waiting", "desc", models.TaskPriorityLow)
+	s.Require().NoError(err)
+
+	resp, err := http.Get(s.server.URL + ui.BasePath + "/api/plans/" + plan.ID)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var view ui.PlanView
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&view))
+	s.Contains(view.NotesHTML, "<strong>Important</strong>")
+	s.Require().Len(view.Columns, 4)
+	s.Require().Len(view.Columns[0].Tasks, 1)
+	s.Equal("Waiting", view.Columns[0].Tasks[0].Title)
+	s.Require().Len(view.Columns[1].Tasks, 1)
+	s.Equal("Started", view.Columns[1].Tasks[0].Title)
+
+	missing, err := http.Get(s.server.URL + ui.BasePath + "/api/plans/" + uuid.New().String())
+	s.Require().NoError(err)
+	defer missing.Body.Close()
+	s.Equal(http.StatusNotFound, missing.StatusCode)
+}
+
+// TestEventsStreamChanges tests that the event stream sends the board again after a task changes
+func (s *WebUITestSuite) TestEventsStreamChanges() {
+	plan, err := s.GetPlanRepository().Create(s.Context, "ui-app-"+uuid.New().String(), "UI Plan", "desc")
+	s.Require().NoError(err)
+
+	ctx, cancel := context.WithTimeout(s.Context, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.server.URL+ui.BasePath+"/events?plan_id="+plan.ID, nil)
+	s.Require().NoError(err)
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	s.Equal("text/event-stream", resp.Header.Get("Content-Type"))
+
+	updates := make(chan ui.PlanView)
+	go func() {
+		defer close(updates)
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+			if !ok {
+				continue
+			}
+			var view ui.PlanView
+			if json.Unmarshal([]byte(data), &view) == nil {
+				updates <- view
+			}
+		}
+	}()
+
+	initial, ok := <-updates
+	s.Require().True(ok, "expected an initial update")
+	s.Empty(initial.Columns[0].Tasks)
+
+	_, err = s.GetTaskRepository().Create(s.Context, plan.ID, "New task", "desc", models.TaskPriorityMedium)
+	s.Require().NoError(err)
+
+	changed, ok := <-updates
+	s.Require().True(ok, "expected an update after creating a task")
+	s.Require().Len(changed.Columns[0].Tasks, 1)
+	s.Equal("New task", changed.Columns[0].Tasks[0].Title)
+}
+
+// TestWebUISuite runs the web UI test suite
+func TestWebUISuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	suite.Run(t, new(WebUITestSuite))
+}